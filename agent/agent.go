@@ -18,11 +18,13 @@ import (
 	"context"
 	"fmt"
 	"iter"
+	"time"
 
 	"google.golang.org/genai"
 
 	"google.golang.org/adk/artifact"
 	agentinternal "google.golang.org/adk/internal/agent"
+	"google.golang.org/adk/internal/callbackinternal"
 	"google.golang.org/adk/internal/plugininternal/plugincontext"
 	"google.golang.org/adk/memory"
 	"google.golang.org/adk/model"
@@ -111,6 +113,30 @@ type Artifacts interface {
 	LoadVersion(ctx context.Context, name string, version int) (*artifact.LoadResponse, error)
 }
 
+// ArtifactStreamer is implemented by the [Artifacts] which can load the
+// content of an artifact while it is read, see [artifact.LoadStream]. The
+// implementations wrapping other Artifacts return an error wrapping
+// [errors.ErrUnsupported] if the wrapped ones can't.
+type ArtifactStreamer interface {
+	LoadStream(ctx context.Context, name string) (*artifact.LoadStreamResponse, error)
+}
+
+// ArtifactURLSigner is implemented by the [Artifacts] which can mint
+// time-limited URLs downloading the latest version of an artifact, see
+// [artifact.URLSigner]. The implementations wrapping other Artifacts return
+// an error wrapping [errors.ErrUnsupported] if the wrapped ones can't.
+type ArtifactURLSigner interface {
+	SignURL(ctx context.Context, name string, ttl time.Duration) (*artifact.SignURLResponse, error)
+}
+
+// ArtifactVersioner is implemented by the [Artifacts] which can list the
+// versions of an artifact, see [artifact.Service]. The implementations
+// wrapping other Artifacts return an error wrapping [errors.ErrUnsupported]
+// if the wrapped ones can't.
+type ArtifactVersioner interface {
+	Versions(ctx context.Context, name string) (*artifact.VersionsResponse, error)
+}
+
 // Memory interface provides methods to access agent memory across the
 // sessions of the current user_id.
 type Memory interface {
@@ -245,7 +271,9 @@ func runBeforeAgentCallbacks(ctx InvocationContext) (*session.Event, error) {
 	}
 
 	for _, callback := range ctx.Agent().internal().beforeAgentCallbacks {
-		content, err := callback(callbackCtx)
+		content, err := callbackinternal.Call(callback, func() (*genai.Content, error) {
+			return callback(callbackCtx)
+		})
 		if err != nil {
 			return nil, fmt.Errorf("failed to run before agent callback: %w", err)
 		}
@@ -306,7 +334,9 @@ func runAfterAgentCallbacks(ctx InvocationContext) (*session.Event, error) {
 	}
 
 	for _, callback := range agent.internal().afterAgentCallbacks {
-		newContent, err := callback(callbackCtx)
+		newContent, err := callbackinternal.Call(callback, func() (*genai.Content, error) {
+			return callback(callbackCtx)
+		})
 		if err != nil {
 			return nil, fmt.Errorf("failed to run after agent callback: %w", err)
 		}
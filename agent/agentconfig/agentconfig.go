@@ -0,0 +1,382 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package agentconfig loads agent trees configured declaratively in YAML
+// files, e.g.:
+//
+//	agent_class: SequentialAgent
+//	name: pipeline
+//	sub_agents:
+//	  - config_path: researcher.yaml
+//	  - config_path: writer.yaml
+//
+// with researcher.yaml:
+//
+//	name: researcher
+//	model: gemini-2.5-flash
+//	instruction: Research the topic of the user.
+//	output_key: research
+//	tools:
+//	  - name: search
+//	    args:
+//	      max_results: 5
+//	generate_content_config:
+//	  temperature: 0.2
+//
+// The agent_class is one of LlmAgent, the default, SequentialAgent,
+// ParallelAgent and LoopAgent. The models are resolved with a
+// [model.Registry], and the tools with a [ToolRegistry] of the tool
+// factories of the host program.
+//
+// The configurations are validated when they are loaded: all the errors,
+// e.g. the unknown agent classes, models and tools, are reported with their
+// file and line.
+package agentconfig
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"google.golang.org/genai"
+	"gopkg.in/yaml.v3"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/agent/workflowagents/loopagent"
+	"google.golang.org/adk/agent/workflowagents/parallelagent"
+	"google.golang.org/adk/agent/workflowagents/sequentialagent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/tool"
+)
+
+// Agent classes of the configurations.
+const (
+	ClassLLMAgent        = "LlmAgent"
+	ClassSequentialAgent = "SequentialAgent"
+	ClassParallelAgent   = "ParallelAgent"
+	ClassLoopAgent       = "LoopAgent"
+)
+
+// Config of the loading of agent configurations.
+type Config struct {
+	// Tools resolves the tool references of the configurations.
+	// Optional: if nil, the configurations can't reference tools.
+	Tools *ToolRegistry
+	// ModelRegistry resolves the model names of the configurations.
+	// Defaults to [model.DefaultRegistry].
+	ModelRegistry *model.Registry
+}
+
+// Load loads the agent configured in the YAML file with the name in fsys,
+// with its sub-agents configured in the files it references by their path
+// relative to its directory.
+func Load(fsys fs.FS, name string, cfg Config) (agent.Agent, error) {
+	if cfg.ModelRegistry == nil {
+		cfg.ModelRegistry = model.DefaultRegistry
+	}
+	l := &loader{fsys: fsys, cfg: cfg, names: make(map[string]*node)}
+	root := l.parse(name, nil, "")
+	if len(l.errs) > 0 {
+		return nil, errors.Join(l.errs...)
+	}
+	return l.build(root)
+}
+
+// LoadFile loads the agent configured in the YAML file at path, see [Load].
+func LoadFile(path string, cfg Config) (agent.Agent, error) {
+	return Load(os.DirFS(filepath.Dir(path)), filepath.Base(path), cfg)
+}
+
+// agentConfig is the configuration of an agent in a YAML file.
+type agentConfig struct {
+	AgentClass               string                 `yaml:"agent_class"`
+	Name                     string                 `yaml:"name"`
+	Description              string                 `yaml:"description"`
+	Model                    string                 `yaml:"model"`
+	Instruction              string                 `yaml:"instruction"`
+	OutputKey                string                 `yaml:"output_key"`
+	DisallowTransferToParent bool                   `yaml:"disallow_transfer_to_parent"`
+	DisallowTransferToPeers  bool                   `yaml:"disallow_transfer_to_peers"`
+	GenerateContentConfig    *generateContentConfig `yaml:"generate_content_config"`
+	Tools                    []toolConfig           `yaml:"tools"`
+	MaxIterations            uint                   `yaml:"max_iterations"`
+	SubAgents                []subAgentConfig       `yaml:"sub_agents"`
+}
+
+// generateContentConfig is the subset of [genai.GenerateContentConfig]
+// which can be configured.
+type generateContentConfig struct {
+	Temperature      *float32 `yaml:"temperature"`
+	TopP             *float32 `yaml:"top_p"`
+	TopK             *float32 `yaml:"top_k"`
+	MaxOutputTokens  int32    `yaml:"max_output_tokens"`
+	StopSequences    []string `yaml:"stop_sequences"`
+	Seed             *int32   `yaml:"seed"`
+	ResponseMIMEType string   `yaml:"response_mime_type"`
+}
+
+// toolConfig is a reference to a tool of a [ToolRegistry].
+type toolConfig struct {
+	Name string         `yaml:"name"`
+	Args map[string]any `yaml:"args"`
+}
+
+// subAgentConfig is a reference to the configuration of a sub-agent.
+type subAgentConfig struct {
+	ConfigPath string `yaml:"config_path"`
+}
+
+// llmFields are the fields only valid for the LLM agents.
+var llmFields = []string{"model", "instruction", "output_key", "disallow_transfer_to_parent", "disallow_transfer_to_peers", "generate_content_config", "tools"}
+
+// node is a parsed agent configuration, with its resolved tools and
+// sub-agents.
+type node struct {
+	file      string
+	line      int
+	config    agentConfig
+	tools     []tool.Tool
+	subAgents []*node
+}
+
+type loader struct {
+	fsys fs.FS
+	cfg  Config
+	// names are the nodes by agent name, to report the duplicates.
+	names map[string]*node
+	errs  []error
+}
+
+// errorf records an error at the line of the file.
+func (l *loader) errorf(file string, line int, format string, args ...any) {
+	l.errs = append(l.errs, fmt.Errorf("%s:%d: %s", file, line, fmt.Sprintf(format, args...)))
+}
+
+// parse parses and validates the configuration in the file, referenced by
+// the files of the stack, the last one at the position ref, recording its
+// errors. It returns nil if the file can't be parsed.
+func (l *loader) parse(file string, stack []string, ref string) *node {
+	data, err := fs.ReadFile(l.fsys, file)
+	if err != nil {
+		if ref != "" {
+			err = fmt.Errorf("%s: %w", ref, err)
+		}
+		l.errs = append(l.errs, fmt.Errorf("failed to read agent config: %w", err))
+		return nil
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		l.errs = append(l.errs, fmt.Errorf("%s: %w", file, err))
+		return nil
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		l.errorf(file, max(doc.Line, 1), "agent config must be a mapping")
+		return nil
+	}
+	root := doc.Content[0]
+
+	n := &node{file: file, line: root.Line}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&n.config); err != nil {
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) {
+			l.errs = append(l.errs, fmt.Errorf("%s: %w", file, err))
+			return nil
+		}
+		for _, msg := range typeErr.Errors {
+			// The messages start with their line, e.g. "line 3: ...".
+			var line int
+			if _, err := fmt.Sscanf(msg, "line %d:", &line); err == nil {
+				_, msg, _ = strings.Cut(msg, ": ")
+			}
+			l.errorf(file, line, "%s", msg)
+		}
+	}
+	l.validate(n, root)
+
+	stack = append(stack, file)
+	for i, ref := range n.config.SubAgents {
+		line := itemLine(root, "sub_agents", i)
+		if ref.ConfigPath == "" {
+			l.errorf(file, line, "sub-agent config_path is empty")
+			continue
+		}
+		subFile := path.Join(path.Dir(file), ref.ConfigPath)
+		if slices.Contains(stack, subFile) {
+			l.errorf(file, line, "sub-agent config %s references itself", subFile)
+			continue
+		}
+		if sub := l.parse(subFile, stack, fmt.Sprintf("%s:%d", file, line)); sub != nil {
+			n.subAgents = append(n.subAgents, sub)
+		}
+	}
+	return n
+}
+
+// validate validates the configuration of the node, and resolves its tools.
+func (l *loader) validate(n *node, root *yaml.Node) {
+	cfg := &n.config
+	if cfg.Name == "" {
+		l.errorf(n.file, n.line, "agent name is empty")
+	} else if other, ok := l.names[cfg.Name]; ok {
+		l.errorf(n.file, keyLine(root, "name"), "duplicate agent name %q, also configured at %s:%d", cfg.Name, other.file, other.line)
+	} else {
+		l.names[cfg.Name] = n
+	}
+
+	class := cfg.AgentClass
+	if class == "" {
+		class = ClassLLMAgent
+	}
+	switch class {
+	case ClassLLMAgent:
+		if cfg.Model == "" {
+			l.errorf(n.file, n.line, "model of LLM agent %q is empty", cfg.Name)
+		} else if _, err := l.cfg.ModelRegistry.Resolve(cfg.Model); err != nil {
+			l.errorf(n.file, keyLine(root, "model"), "%v", err)
+		}
+		for i, ref := range cfg.Tools {
+			line := itemLine(root, "tools", i)
+			if ref.Name == "" {
+				l.errorf(n.file, line, "tool name is empty")
+				continue
+			}
+			if l.cfg.Tools == nil {
+				l.errorf(n.file, line, "no tool is registered for name %q", ref.Name)
+				continue
+			}
+			t, err := l.cfg.Tools.Resolve(ref.Name, ref.Args)
+			if err != nil {
+				l.errorf(n.file, line, "%v", err)
+				continue
+			}
+			n.tools = append(n.tools, t)
+		}
+	case ClassSequentialAgent, ClassParallelAgent, ClassLoopAgent:
+		for _, field := range llmFields {
+			if hasKey(root, field) {
+				l.errorf(n.file, keyLine(root, field), "field %s is only valid for agent class %s", field, ClassLLMAgent)
+			}
+		}
+	default:
+		l.errorf(n.file, keyLine(root, "agent_class"), "unknown agent class %q", cfg.AgentClass)
+	}
+	if class != ClassLoopAgent && hasKey(root, "max_iterations") {
+		l.errorf(n.file, keyLine(root, "max_iterations"), "field max_iterations is only valid for agent class %s", ClassLoopAgent)
+	}
+}
+
+// build creates the agent of the validated node.
+func (l *loader) build(n *node) (agent.Agent, error) {
+	var subAgents []agent.Agent
+	for _, sub := range n.subAgents {
+		a, err := l.build(sub)
+		if err != nil {
+			return nil, err
+		}
+		subAgents = append(subAgents, a)
+	}
+
+	cfg := n.config
+	base := agent.Config{
+		Name:        cfg.Name,
+		Description: cfg.Description,
+		SubAgents:   subAgents,
+	}
+	var (
+		a   agent.Agent
+		err error
+	)
+	switch cfg.AgentClass {
+	case ClassSequentialAgent:
+		a, err = sequentialagent.New(sequentialagent.Config{AgentConfig: base})
+	case ClassParallelAgent:
+		a, err = parallelagent.New(parallelagent.Config{AgentConfig: base})
+	case ClassLoopAgent:
+		a, err = loopagent.New(loopagent.Config{AgentConfig: base, MaxIterations: cfg.MaxIterations})
+	default:
+		a, err = llmagent.New(llmagent.Config{
+			Name:                     cfg.Name,
+			Description:              cfg.Description,
+			SubAgents:                subAgents,
+			ModelName:                cfg.Model,
+			ModelRegistry:            l.cfg.ModelRegistry,
+			Instruction:              cfg.Instruction,
+			OutputKey:                cfg.OutputKey,
+			DisallowTransferToParent: cfg.DisallowTransferToParent,
+			DisallowTransferToPeers:  cfg.DisallowTransferToPeers,
+			GenerateContentConfig:    cfg.GenerateContentConfig.toGenAI(),
+			Tools:                    n.tools,
+		})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s:%d: failed to create agent %q: %w", n.file, n.line, cfg.Name, err)
+	}
+	return a, nil
+}
+
+func (c *generateContentConfig) toGenAI() *genai.GenerateContentConfig {
+	if c == nil {
+		return nil
+	}
+	return &genai.GenerateContentConfig{
+		Temperature:      c.Temperature,
+		TopP:             c.TopP,
+		TopK:             c.TopK,
+		MaxOutputTokens:  c.MaxOutputTokens,
+		StopSequences:    c.StopSequences,
+		Seed:             c.Seed,
+		ResponseMIMEType: c.ResponseMIMEType,
+	}
+}
+
+// value returns the value of the key in the mapping, nil if it has none.
+func value(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func hasKey(mapping *yaml.Node, key string) bool {
+	return value(mapping, key) != nil
+}
+
+// keyLine returns the line of the value of the key in the mapping, or of
+// the mapping if it has no such key.
+func keyLine(mapping *yaml.Node, key string) int {
+	if v := value(mapping, key); v != nil {
+		return v.Line
+	}
+	return mapping.Line
+}
+
+// itemLine returns the line of the i-th item of the sequence of the key in
+// the mapping.
+func itemLine(mapping *yaml.Node, key string, i int) int {
+	if v := value(mapping, key); v != nil && v.Kind == yaml.SequenceNode && i < len(v.Content) {
+		return v.Content[i].Line
+	}
+	return keyLine(mapping, key)
+}
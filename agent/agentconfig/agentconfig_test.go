@@ -0,0 +1,203 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentconfig_test
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/agentconfig"
+	"google.golang.org/adk/internal/testutil"
+	"google.golang.org/adk/internal/utils"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/exitlooptool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+func TestLoad(t *testing.T) {
+	fake := &fakeModel{}
+	models := model.NewRegistry()
+	if err := models.Register("fake-*", func(ctx context.Context, name string) (model.LLM, error) {
+		return fake, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	var searchArgs map[string]any
+	tools := agentconfig.NewToolRegistry()
+	if err := tools.Register("search", func(args map[string]any) (tool.Tool, error) {
+		searchArgs = args
+		return functiontool.New(functiontool.Config{Name: "search", Description: "searches the web"}, func(tool.Context, struct{}) (string, error) {
+			return "results", nil
+		})
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tools.Register("exit_loop", func(map[string]any) (tool.Tool, error) {
+		return exitlooptool.New()
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := agentconfig.LoadFile("testdata/pipeline/root.yaml", agentconfig.Config{
+		Tools:         tools,
+		ModelRegistry: models,
+	})
+	if err != nil {
+		t.Fatalf("LoadFile() failed: %v", err)
+	}
+
+	want := "pipeline(researcher, writers(formal_writer, casual_writer), refine_loop(critic))"
+	if got := tree(a); got != want {
+		t.Errorf("agent tree = %s, want %s", got, want)
+	}
+	if diff := cmp.Diff(map[string]any{"max_results": 5}, searchArgs); diff != "" {
+		t.Errorf("search tool args mismatch (-want +got):\n%s", diff)
+	}
+
+	for _, err := range testutil.NewTestAgentRunner(t, a).Run(t, "session_id", "Write about Go.") {
+		if err != nil {
+			t.Fatalf("Run() failed: %v", err)
+		}
+	}
+	researcher := fake.request(t, "Research the topic")
+	if got := utils.FunctionDecls(researcher.Config); !slices.ContainsFunc(got, func(f *genai.FunctionDeclaration) bool { return f.Name == "search" }) {
+		t.Errorf("researcher request function declarations = %v, want search", got)
+	}
+	if got := researcher.Config.Temperature; got == nil || *got != 0.2 {
+		t.Errorf("researcher request temperature = %v, want 0.2", got)
+	}
+	if got := researcher.Config.MaxOutputTokens; got != 1024 {
+		t.Errorf("researcher request max output tokens = %d, want 1024", got)
+	}
+	// The formal writer gets the output of the researcher.
+	fake.request(t, "Write a formal article about answer 1.")
+}
+
+func TestLoad_Errors(t *testing.T) {
+	models := model.NewRegistry()
+	if err := models.Register("fake-*", func(ctx context.Context, name string) (model.LLM, error) {
+		return &fakeModel{}, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	tools := agentconfig.NewToolRegistry()
+	if err := tools.Register("search", func(map[string]any) (tool.Tool, error) {
+		return exitlooptool.New()
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := agentconfig.Load(os.DirFS("testdata/invalid"), "root.yaml", agentconfig.Config{
+		Tools:         tools,
+		ModelRegistry: models,
+	})
+	if err == nil {
+		t.Fatal("Load() succeeded, want error")
+	}
+	for _, want := range []string{
+		`root.yaml:3: field model is only valid for agent class LlmAgent`,
+		`root.yaml:6: open missing.yaml`,
+		`root.yaml:7: sub-agent config root.yaml references itself`,
+		`unknown_tool.yaml:2: no model is registered for name "unknown-model"`,
+		`unknown_tool.yaml:3: field temperature not found`,
+		`unknown_tool.yaml:6: no tool is registered for name "unknown_tool"`,
+		`unknown_class.yaml:1: unknown agent class "GraphAgent"`,
+		`unknown_class.yaml:2: duplicate agent name "helper", also configured at unknown_tool.yaml:1`,
+		"unknown_class.yaml:3: cannot unmarshal !!str `many` into uint",
+		`unknown_class.yaml:3: field max_iterations is only valid for agent class LoopAgent`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Load() error does not include %q, got:\n%v", want, err)
+		}
+	}
+}
+
+func TestToolRegistry(t *testing.T) {
+	r := agentconfig.NewToolRegistry()
+	factory := func(map[string]any) (tool.Tool, error) { return exitlooptool.New() }
+	if err := r.Register("exit_loop", factory); err != nil {
+		t.Fatalf("Register() failed: %v", err)
+	}
+	if err := r.Register("exit_loop", factory); err == nil {
+		t.Error("Register() of a registered name succeeded, want error")
+	}
+	if err := r.Register("nil", nil); err == nil {
+		t.Error("Register() of a nil factory succeeded, want error")
+	}
+	if _, err := r.Resolve("exit_loop", nil); err != nil {
+		t.Errorf("Resolve() failed: %v", err)
+	}
+	if _, err := r.Resolve("unknown", nil); err == nil {
+		t.Error("Resolve() of an unknown name succeeded, want error")
+	}
+}
+
+// tree returns the names of the agents of the tree.
+func tree(a agent.Agent) string {
+	if len(a.SubAgents()) == 0 {
+		return a.Name()
+	}
+	var subAgents []string
+	for _, sub := range a.SubAgents() {
+		subAgents = append(subAgents, tree(sub))
+	}
+	return fmt.Sprintf("%s(%s)", a.Name(), strings.Join(subAgents, ", "))
+}
+
+// fakeModel records its requests, and answers them in order with
+// "answer 1", "answer 2", etc.
+type fakeModel struct {
+	mu       sync.Mutex
+	requests []*model.LLMRequest
+}
+
+func (m *fakeModel) Name() string { return "fake" }
+
+func (m *fakeModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	m.mu.Lock()
+	m.requests = append(m.requests, req)
+	n := len(m.requests)
+	m.mu.Unlock()
+	return func(yield func(*model.LLMResponse, error) bool) {
+		yield(&model.LLMResponse{
+			Content: genai.NewContentFromText(fmt.Sprintf("answer %d", n), genai.RoleModel),
+		}, nil)
+	}
+}
+
+// request returns the request whose system instruction includes the text.
+func (m *fakeModel) request(t *testing.T, instruction string) *model.LLMRequest {
+	t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, req := range m.requests {
+		if slices.ContainsFunc(utils.TextParts(req.Config.SystemInstruction), func(s string) bool { return strings.Contains(s, instruction) }) {
+			return req
+		}
+	}
+	t.Fatalf("no request with instruction %q", instruction)
+	return nil
+}
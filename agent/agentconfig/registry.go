@@ -0,0 +1,73 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentconfig
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"google.golang.org/adk/tool"
+)
+
+// ToolFactory creates the tool referenced by an agent configuration, given
+// the args of the reference, nil if it has none.
+type ToolFactory func(args map[string]any) (tool.Tool, error)
+
+// ToolRegistry resolves the tool references of the agent configurations
+// with the tool factories registered by the host program, e.g.:
+//
+//	r.Register("get_weather", func(args map[string]any) (tool.Tool, error) {
+//		return functiontool.New(functiontool.Config{Name: "get_weather"}, getWeather)
+//	})
+//
+// ToolRegistry is safe for concurrent use.
+type ToolRegistry struct {
+	mu        sync.RWMutex
+	factories map[string]ToolFactory
+}
+
+// NewToolRegistry returns an empty registry.
+func NewToolRegistry() *ToolRegistry {
+	return &ToolRegistry{factories: make(map[string]ToolFactory)}
+}
+
+// Register registers the factory of the tools referenced by the name.
+func (r *ToolRegistry) Register(name string, factory ToolFactory) error {
+	if name == "" {
+		return errors.New("tool name is empty")
+	}
+	if factory == nil {
+		return errors.New("tool factory is nil")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.factories[name]; ok {
+		return fmt.Errorf("tool %q is already registered", name)
+	}
+	r.factories[name] = factory
+	return nil
+}
+
+// Resolve returns the tool referenced by the name with the args.
+func (r *ToolRegistry) Resolve(name string, args map[string]any) (tool.Tool, error) {
+	r.mu.RLock()
+	factory, ok := r.factories[name]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("no tool is registered for name %q", name)
+	}
+	return factory(args)
+}
@@ -0,0 +1,81 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"google.golang.org/genai"
+)
+
+// ErrLiveRequestQueueClosed is the error of the requests sent to a closed
+// [LiveRequestQueue].
+var ErrLiveRequestQueueClosed = errors.New("live request queue is closed")
+
+// LiveRequest is a request of the user to a live run, see
+// [LiveRequestQueue].
+type LiveRequest struct {
+	// Content is a turn of the user.
+	Content *genai.Content
+	// Blob is a chunk of realtime media, e.g. audio.
+	Blob *genai.Blob
+}
+
+// LiveRequestQueue carries the requests of the user to a live run, see
+// [google.golang.org/adk/runner.Runner.RunLive]. Closing it ends the run. It
+// is safe for concurrent use.
+type LiveRequestQueue struct {
+	requests chan LiveRequest
+	done     chan struct{}
+	close    sync.Once
+}
+
+// NewLiveRequestQueue returns an open queue.
+func NewLiveRequestQueue() *LiveRequestQueue {
+	return &LiveRequestQueue{
+		requests: make(chan LiveRequest),
+		done:     make(chan struct{}),
+	}
+}
+
+// Send sends the request to the run, and waits until the run takes it, ctx
+// is done or the queue is closed.
+func (q *LiveRequestQueue) Send(ctx context.Context, req LiveRequest) error {
+	select {
+	case q.requests <- req:
+		return nil
+	case <-q.done:
+		return ErrLiveRequestQueueClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close closes the queue, which ends the run.
+func (q *LiveRequestQueue) Close() {
+	q.close.Do(func() { close(q.done) })
+}
+
+// Requests returns the channel of the requests, read by the run.
+func (q *LiveRequestQueue) Requests() <-chan LiveRequest {
+	return q.requests
+}
+
+// Done returns a channel closed once the queue is closed.
+func (q *LiveRequestQueue) Done() <-chan struct{} {
+	return q.done
+}
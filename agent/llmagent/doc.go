@@ -16,4 +16,9 @@
 // LLM agents use large language models to perform tasks based on instructions, user input,
 // deciding on actions to take, and executing actions using available tools or
 // delegating to sub agents.
+//
+// The callbacks of each kind, e.g. the BeforeModelCallbacks of the [Config],
+// run in order until one of them returns a non-nil result or error, which
+// replaces the result or error of the step. The panics of the callbacks are
+// recovered, and fail the step with an error naming the callback.
 package llmagent
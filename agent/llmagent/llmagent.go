@@ -15,17 +15,23 @@
 package llmagent
 
 import (
+	"encoding/json"
 	"fmt"
 	"iter"
+	"log"
 	"strings"
+	"time"
 
 	"google.golang.org/genai"
 
 	"google.golang.org/adk/agent"
+	"google.golang.org/adk/codeexecutor"
+	"google.golang.org/adk/example"
 	agentinternal "google.golang.org/adk/internal/agent"
 	icontext "google.golang.org/adk/internal/context"
 	"google.golang.org/adk/internal/llminternal"
 	"google.golang.org/adk/model"
+	"google.golang.org/adk/planner"
 	"google.golang.org/adk/session"
 	"google.golang.org/adk/tool"
 )
@@ -62,6 +68,107 @@ func New(cfg Config) (agent.Agent, error) {
 		onToolErrorCallback = append(onToolErrorCallback, llminternal.OnToolErrorCallback(c))
 	}
 
+	repairAttempts := cfg.OutputRepairAttempts
+	if repairAttempts == 0 {
+		repairAttempts = DefaultOutputRepairAttempts
+	}
+	toolOptions := llminternal.ToolOptions{Timeout: cfg.ToolTimeout, Parallelism: cfg.ToolParallelism}
+	if toolOptions.Parallelism == 0 {
+		toolOptions.Parallelism = DefaultToolParallelism
+	}
+	switch cfg.ToolErrorPolicy {
+	case ToolErrorPolicyReturnToModel, "":
+	case ToolErrorPolicyFailInvocation:
+		toolOptions.FailInvocation = true
+	case ToolErrorPolicyRetry:
+		toolOptions.Retries = cfg.ToolRetries
+		if toolOptions.Retries == 0 {
+			toolOptions.Retries = DefaultToolRetries
+		}
+	default:
+		return nil, fmt.Errorf("invalid tool error policy %q of agent %q", cfg.ToolErrorPolicy, cfg.Name)
+	}
+	switch cfg.ToolCacheScope {
+	case tool.CacheScopeSession, tool.CacheScopeApp, "":
+		toolOptions.Cache, toolOptions.CacheScope = cfg.ToolCache, cfg.ToolCacheScope
+	default:
+		return nil, fmt.Errorf("invalid tool cache scope %q of agent %q", cfg.ToolCacheScope, cfg.Name)
+	}
+	switch cfg.IncludeContents {
+	case IncludeContentsDefault, IncludeContentsNone, "":
+		if cfg.ContentsFilter != nil {
+			return nil, fmt.Errorf("agent %q sets a ContentsFilter without IncludeContentsFiltered", cfg.Name)
+		}
+	case IncludeContentsFiltered:
+		if cfg.ContentsFilter == nil {
+			return nil, fmt.Errorf("agent %q with IncludeContentsFiltered requires a ContentsFilter", cfg.Name)
+		}
+	default:
+		return nil, fmt.Errorf("invalid include contents mode %q of agent %q", cfg.IncludeContents, cfg.Name)
+	}
+	transferLoopThreshold := cfg.TransferLoopThreshold
+	if transferLoopThreshold == 0 {
+		transferLoopThreshold = DefaultTransferLoopThreshold
+	}
+
+	if cfg.Examples != nil && cfg.ExampleProvider != nil {
+		return nil, fmt.Errorf("agent %q sets both Examples and ExampleProvider", cfg.Name)
+	}
+	switch cfg.ExampleFormat {
+	case ExampleFormatInstruction, ExampleFormatHistory, "":
+	default:
+		return nil, fmt.Errorf("invalid example format %q of agent %q", cfg.ExampleFormat, cfg.Name)
+	}
+	switch cfg.OutputValidation {
+	case OutputValidationStrict, OutputValidationLenient, "":
+	default:
+		return nil, fmt.Errorf("invalid output validation mode %q of agent %q", cfg.OutputValidation, cfg.Name)
+	}
+
+	if cfg.ThinkingConfig != nil && cfg.GenerateContentConfig != nil && cfg.GenerateContentConfig.ThinkingConfig != nil {
+		return nil, fmt.Errorf("agent %q sets both ThinkingConfig and GenerateContentConfig.ThinkingConfig", cfg.Name)
+	}
+
+	llm, err := resolveModel(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve model of agent %q: %w", cfg.Name, err)
+	}
+	cfg.Model = llm
+
+	if builtIn, ok := cfg.Planner.(*planner.BuiltInPlanner); ok {
+		if builtIn.ThinkingConfig != nil && (cfg.ThinkingConfig != nil || cfg.GenerateContentConfig != nil && cfg.GenerateContentConfig.ThinkingConfig != nil) {
+			return nil, fmt.Errorf("agent %q sets both a ThinkingConfig and a BuiltInPlanner with a ThinkingConfig", cfg.Name)
+		}
+		// The agents without a model use the one of their ancestors, which
+		// isn't known yet.
+		if cfg.Model != nil && !model.SupportsThinking(cfg.Model) {
+			if !builtIn.Lenient {
+				return nil, fmt.Errorf("model %s of agent %q doesn't support the thinking of its BuiltInPlanner", cfg.Model.Name(), cfg.Name)
+			}
+			log.Printf("Model %s of agent %s doesn't support thinking, ignoring its BuiltInPlanner", cfg.Model.Name(), cfg.Name)
+			cfg.Planner = nil
+		}
+	}
+
+	var historyMaxTokens int
+	var historySummaryModel model.LLM
+	if b := cfg.HistoryBudget; b != nil {
+		if b.MaxTokens <= 0 {
+			return nil, fmt.Errorf("history budget of agent %q must be positive, got %d", cfg.Name, b.MaxTokens)
+		}
+		historyMaxTokens = b.MaxTokens
+		switch b.Strategy {
+		case HistoryStrategyDrop, "":
+		case HistoryStrategySummarize:
+			historySummaryModel = b.SummaryModel
+			if historySummaryModel == nil {
+				historySummaryModel = cfg.Model
+			}
+		default:
+			return nil, fmt.Errorf("invalid history strategy %q of agent %q", b.Strategy, cfg.Name)
+		}
+	}
+
 	a := &llmAgent{
 		model:                 cfg.Model,
 		beforeModelCallbacks:  beforeModelCallbacks,
@@ -70,6 +177,7 @@ func New(cfg Config) (agent.Agent, error) {
 		beforeToolCallbacks:   beforeToolCallbacks,
 		afterToolCallbacks:    afterToolCallbacks,
 		onToolErrorCallbacks:  onToolErrorCallback,
+		toolOptions:           toolOptions,
 		instruction:           cfg.Instruction,
 		inputSchema:           cfg.InputSchema,
 		outputSchema:          cfg.OutputSchema,
@@ -77,19 +185,35 @@ func New(cfg Config) (agent.Agent, error) {
 		State: llminternal.State{
 			Model:                    cfg.Model,
 			GenerateContentConfig:    cfg.GenerateContentConfig,
+			ThinkingConfig:           cfg.ThinkingConfig,
+			ModelTimeout:             cfg.ModelTimeout,
+			ModelIdleTimeout:         cfg.ModelIdleTimeout,
 			Tools:                    cfg.Tools,
 			Toolsets:                 cfg.Toolsets,
 			DisallowTransferToParent: cfg.DisallowTransferToParent,
 			DisallowTransferToPeers:  cfg.DisallowTransferToPeers,
+			AllowedTransferTargets:   cfg.AllowedTransferTargets,
+			TransferLoopThreshold:    transferLoopThreshold,
 			InputSchema:              cfg.InputSchema,
 			OutputSchema:             cfg.OutputSchema,
+			OutputRepairAttempts:     max(repairAttempts, 0),
+			OutputValidationLenient:  cfg.OutputValidation == OutputValidationLenient,
 			// TODO: internal type for includeContents
 			IncludeContents:           string(cfg.IncludeContents),
+			ContentsFilter:            cfg.ContentsFilter,
 			Instruction:               cfg.Instruction,
 			InstructionProvider:       llminternal.InstructionProvider(cfg.InstructionProvider),
 			GlobalInstruction:         cfg.GlobalInstruction,
 			GlobalInstructionProvider: llminternal.InstructionProvider(cfg.GlobalInstructionProvider),
 			OutputKey:                 cfg.OutputKey,
+			HistoryMaxTokens:          historyMaxTokens,
+			HistorySummaryModel:       historySummaryModel,
+			CodeExecutor:              cfg.CodeExecutor,
+			Planner:                   cfg.Planner,
+			Examples:                  cfg.Examples,
+			ExampleProvider:           cfg.ExampleProvider,
+			ExamplesInHistory:         cfg.ExampleFormat == ExampleFormatHistory,
+			ExamplesMaxTokens:         max(cfg.ExamplesMaxTokens, 0),
 		},
 	}
 
@@ -112,6 +236,35 @@ func New(cfg Config) (agent.Agent, error) {
 	return a, nil
 }
 
+// resolveModel returns the model of the agent, with its fallback models.
+func resolveModel(cfg Config) (model.LLM, error) {
+	if cfg.ModelName == "" && len(cfg.FallbackModelNames) == 0 {
+		return cfg.Model, nil
+	}
+	if cfg.Model != nil && cfg.ModelName != "" {
+		return nil, fmt.Errorf("only one of Model and ModelName can be set")
+	}
+	registry := cfg.ModelRegistry
+	if registry == nil {
+		registry = model.DefaultRegistry
+	}
+	if cfg.ModelName != "" {
+		return registry.Resolve(cfg.ModelName, cfg.FallbackModelNames...)
+	}
+	if cfg.Model == nil {
+		return nil, fmt.Errorf("fallback models require Model or ModelName to be set")
+	}
+	models := []model.LLM{cfg.Model}
+	for _, name := range cfg.FallbackModelNames {
+		llm, err := registry.Resolve(name)
+		if err != nil {
+			return nil, err
+		}
+		models = append(models, llm)
+	}
+	return model.NewFallback(models...), nil
+}
+
 // Config of the LLMAgent.
 type Config struct {
 	// Name must be a non-empty string, unique within the agent tree.
@@ -152,6 +305,26 @@ type Config struct {
 	// safety settings, etc.
 	GenerateContentConfig *genai.GenerateContentConfig
 
+	// ThinkingConfig configures the thinking of the model, i.e. its budget of
+	// thinking tokens and whether its thoughts are returned. The returned
+	// thoughts are the parts of the event contents with their Thought field
+	// set. It can't be set together with the ThinkingConfig of the
+	// GenerateContentConfig.
+	ThinkingConfig *genai.ThinkingConfig
+
+	// ModelTimeout bounds the duration of each model call, or for streaming
+	// calls the time to their first response. Zero means no timeout. The
+	// request processors and the BeforeModelCallbacks may override it with
+	// the Timeout of the request.
+	//
+	// The calls which time out end with an event with the
+	// [model.ErrorCodeTimeout] error code, and are retried by the models
+	// created with [model.WithRetry].
+	ModelTimeout time.Duration
+	// ModelIdleTimeout bounds the time between the responses of streaming
+	// model calls. Zero means no timeout.
+	ModelIdleTimeout time.Duration
+
 	// BeforeModelCallbacks will be called in the order they are provided until
 	// there's a callback that returns a non-nil LLMResponse or error. Then
 	// actual LLM call is skipped, and the returned response/error is used.
@@ -162,6 +335,17 @@ type Config struct {
 	BeforeModelCallbacks []BeforeModelCallback
 	// Model that is used by the agent.
 	Model model.LLM
+	// ModelName is the name of the model used by the agent, instead of
+	// Model. It is resolved with the ModelRegistry when the agent is
+	// created, and the model created at its first use.
+	ModelName string
+	// FallbackModelNames are the names of the models tried in order when
+	// the model of the agent fails with a retryable error, see
+	// [model.NewFallback]. They are resolved with the ModelRegistry.
+	FallbackModelNames []string
+	// ModelRegistry resolves the model names. Defaults to
+	// [model.DefaultRegistry].
+	ModelRegistry *model.Registry
 	// AfterModelCallbacks will be called in the order they are provided until
 	// there's a callback that returns a non-nil LLMResponse or error. Then
 	// actual LLM response is replaced with the returned response/error.
@@ -181,6 +365,8 @@ type Config struct {
 	//    treated as a literal.
 	//  - {artifact.key_name} can be used to insert the text content of the
 	//    artifact named key_name.
+	//  - Placeholders in double braces, like {{key_name}}, are escaped: they
+	//    are replaced with the literal {key_name}.
 	//
 	// If the state variable or artifact does not exist, the agent will raise an
 	// error. If you want to ignore the error, you can append a ? to the
@@ -210,12 +396,16 @@ type Config struct {
 	//    treated as a literal.
 	//  - {artifact.key_name} can be used to insert the text content of the
 	//    artifact named key_name.
+	//  - Placeholders in double braces, like {{key_name}}, are escaped: they
+	//    are replaced with the literal {key_name}.
 	//
 	// If the state variable or artifact does not exist, the agent will raise an
 	// error. If you want to ignore the error, you can append a ? to the
 	// variable name as in {var?} to make it optional.
 	//
-	// ONLY the GlobalInstruction in the root agent will take effect.
+	// ONLY the GlobalInstruction in the root agent will take effect: it is
+	// prepended to the instructions of the requests of all the LLM agents of
+	// the tree. The runners log a warning for the sub-agents setting it.
 	//
 	// For example: GlobalInstruction can make all agents have a stable identity
 	// or personality.
@@ -231,9 +421,34 @@ type Config struct {
 	DisallowTransferToParent bool
 	// DisallowTransferToPeers prevents transferring to peer agents.
 	DisallowTransferToPeers bool
+	// AllowedTransferTargets are the names of the agents the agent can
+	// transfer to, among its sub-agents, its parent and its peers allowed by
+	// DisallowTransferToParent and DisallowTransferToPeers. The other agents
+	// aren't offered to the model, and the transfers to them return an
+	// error to the model.
+	// Optional: if nil, the agent can transfer to all of them.
+	AllowedTransferTargets []string
+	// TransferLoopThreshold is the number of transfers of an invocation back
+	// to an agent which transferred to the transferring agent, e.g. A→B→A,
+	// tolerated before a transfer of the agent is considered a loop: it is
+	// replaced with an event escalating the loop, which ends the
+	// invocation.
+	// Optional: if zero, [DefaultTransferLoopThreshold] is used, and if
+	// negative, the loops aren't detected.
+	TransferLoopThreshold int
 
 	// Whether to include contents (conversation history) in the model request.
+	//
+	// The agents excluding the history can still read the outputs of the
+	// agents before them from the session state, e.g. with an {output}
+	// placeholder in their Instruction for an agent with the OutputKey
+	// "output": the state isn't filtered.
 	IncludeContents IncludeContents
+	// ContentsFilter selects the conversation history of the agent with
+	// IncludeContentsFiltered, e.g. [AgentAndUserEvents] or [LastTurns].
+	// It is required with IncludeContentsFiltered, and can't be set
+	// otherwise.
+	ContentsFilter ContentsFilter
 
 	// TODO(ngeorgy): consider to switch to jsonschema for input and output schema.
 	// The input schema when agent is used as a tool.
@@ -243,6 +458,16 @@ type Config struct {
 	// NOTE: when this is set, agent can only reply and cannot use any tools,
 	// such as function tools, RAGs, agent transfer, etc.
 	OutputSchema *genai.Schema
+	// OutputRepairAttempts is the number of repair turns when a reply
+	// doesn't match the OutputSchema: the validation errors are sent back to
+	// the model, which is asked to reply again.
+	// Optional: if zero, [DefaultOutputRepairAttempts] is used. If negative,
+	// there are no repair turns.
+	OutputRepairAttempts int
+	// OutputValidation is what happens to the replies which still don't
+	// match the OutputSchema after the repair turns.
+	// Optional: if empty, [OutputValidationStrict] is used.
+	OutputValidation OutputValidation
 
 	// Callbacks are executed in the order they are provided.
 	// If a callback returns result/error, then the execution of the callback
@@ -255,19 +480,106 @@ type Config struct {
 	// list stops and this result/error is returned instead.
 	AfterToolCallbacks []AfterToolCallback
 	// Toolsets will be used by llmagent to extract tools and pass to the
-	// underlying LLM.
+	// underlying LLM. Their tools are listed again for each model request, so
+	// the tools of a toolset can depend on the session state, and a toolset
+	// holding resources can implement [tool.ClosableToolset] to release them
+	// when the runner is closed.
 	Toolsets []tool.Toolset
 
 	OnToolErrorCallbacks []OnToolErrorCallback
 
+	// ToolTimeout bounds the duration of each tool call, except for the tools
+	// with their own timeout, see [tool.TimeoutTool]. Zero means no timeout.
+	// The calls which time out fail with an error wrapping
+	// [context.DeadlineExceeded].
+	//
+	// Whatever the timeout, the panics of the tools are recovered: their
+	// calls fail with an error, and their stacks are logged.
+	ToolTimeout time.Duration
+	// ToolErrorPolicy is what happens to the tool calls which fail, after
+	// the OnToolErrorCallbacks.
+	// Optional: if empty, [ToolErrorPolicyReturnToModel] is used.
+	ToolErrorPolicy ToolErrorPolicy
+	// ToolRetries is the number of times the failed tool calls are retried
+	// with [ToolErrorPolicyRetry].
+	// Optional: if zero, [DefaultToolRetries] is used.
+	ToolRetries int
+	// ToolParallelism is the maximum number of function calls of a model
+	// response which run concurrently. The calls of the tools which aren't
+	// safe for parallel calls, see [tool.ParallelSafeTool], run alone. The
+	// tools and the tool callbacks, including those of the plugins, must be
+	// safe for concurrent use unless it is one.
+	// Optional: if zero, [DefaultToolParallelism] is used.
+	ToolParallelism int
+	// ToolCache caches the results of the cacheable tools, see
+	// [tool.CacheableTool], e.g. [tool.NewLRUResultCache]. The function
+	// response events list the calls served from the cache with the
+	// [CachedToolCallsMetadataKey] custom metadata.
+	// Optional: if nil, the results aren't cached.
+	ToolCache tool.ResultCache
+	// ToolCacheScope is the scope of the cached results.
+	// Optional: if empty, [tool.CacheScopeSession] is used.
+	ToolCacheScope tool.CacheScope
+
 	// OutputKey is an optional parameter to specify the key in session state for the agent output.
 	//
 	// Typical uses cases are:
 	// - Extracts agent reply for later use, such as in tools, callbacks, etc.
 	// - Connects agents to coordinate with each other.
 	OutputKey string
+
+	// HistoryBudget bounds the tokens of the model requests of the agent,
+	// by dropping or summarizing their oldest turns.
+	// Optional: if nil, the requests are sent whole.
+	HistoryBudget *HistoryBudget
+
+	// CodeExecutor runs the first Python or Go code block of the replies of
+	// the model, whose result is added to the conversation before the model
+	// is called again, see [codeexecutor]. The files written by the code are
+	// saved as artifacts.
+	// Optional: if nil, the code blocks are left as text.
+	CodeExecutor codeexecutor.CodeExecutor
+
+	// Planner makes the model plan before acting, e.g.
+	// [planner.PlanReActPlanner] or [planner.BuiltInPlanner]: it adds its
+	// planning instruction to the model requests, and processes the replies
+	// of the model, e.g. to mark the planning as thoughts.
+	// Optional: if nil, there is no planning.
+	Planner planner.Planner
+
+	// Examples are few-shot example conversations included in the model
+	// requests, e.g. to show the model which tools to call.
+	// Optional: it can't be set together with ExampleProvider.
+	Examples []*example.Example
+	// ExampleProvider selects the examples of the model requests based on
+	// the user content of the invocation, e.g. by retrieving the examples
+	// closest to it.
+	// Optional: it can't be set together with Examples.
+	ExampleProvider example.Provider
+	// ExampleFormat is how the examples are included in the model requests.
+	// Optional: if empty, ExampleFormatInstruction.
+	ExampleFormat ExampleFormat
+	// ExamplesMaxTokens is the budget of the estimated tokens of the
+	// examples, see [model.EstimateTokens]: the examples after the ones
+	// within the budget are dropped.
+	// Optional: if zero, all the examples are included.
+	ExamplesMaxTokens int
 }
 
+// ExampleFormat is how the few-shot examples are included in the model
+// requests.
+type ExampleFormat string
+
+const (
+	// ExampleFormatInstruction formats the examples as text in the system
+	// instruction, after the instruction of the agent, with their function
+	// calls and responses in code blocks.
+	ExampleFormatInstruction ExampleFormat = "instruction"
+	// ExampleFormatHistory includes the contents of the examples as turns
+	// before the conversation history.
+	ExampleFormatHistory ExampleFormat = "history"
+)
+
 // BeforeModelCallback that is called before sending a request to the model.
 //
 // If it returns non-nil LLMResponse or error, the actual model call is skipped
@@ -296,6 +608,10 @@ type OnModelErrorCallback func(ctx agent.CallbackContext, llmRequest *model.LLMR
 //
 // To modify tool arguments and still run the tool,
 // update args in place and return (nil, nil).
+//
+// A returned error doesn't end the invocation: it is sent to the model as the
+// error of the tool call. State changes made through ctx are recorded in the
+// function response event.
 type BeforeToolCallback func(ctx tool.Context, tool tool.Tool, args map[string]any) (map[string]any, error)
 
 // AfterToolCallback is a function type executed after a tool's Run method has completed,
@@ -313,6 +629,106 @@ type AfterToolCallback func(ctx tool.Context, tool tool.Tool, args, result map[s
 // is replaced with the returned response/error.
 type OnToolErrorCallback func(ctx tool.Context, tool tool.Tool, args map[string]any, err error) (map[string]any, error)
 
+// DefaultOutputRepairAttempts is the [Config.OutputRepairAttempts] used if
+// it is zero.
+const DefaultOutputRepairAttempts = 2
+
+// ErrorCodeInvalidOutput is the error code of the events which replace the
+// replies which don't match the output schema in [OutputValidationStrict]
+// mode.
+const ErrorCodeInvalidOutput = llminternal.ErrorCodeInvalidOutput
+
+// OutputValidation controls the replies of an llmagent which don't match its
+// output schema.
+type OutputValidation string
+
+const (
+	// OutputValidationStrict replaces the invalid replies with an event with
+	// the [ErrorCodeInvalidOutput] error code.
+	OutputValidationStrict OutputValidation = "strict"
+	// OutputValidationLenient keeps the invalid replies, and saves their raw
+	// text to the output key.
+	OutputValidationLenient OutputValidation = "lenient"
+)
+
+// DefaultToolRetries is the number of retries of the failed tool calls with
+// [ToolErrorPolicyRetry] if the ToolRetries of the [Config] is zero.
+const DefaultToolRetries = 2
+
+// DefaultTransferLoopThreshold is the number of transfers back to an agent
+// of an invocation tolerated before a transfer loop is broken, if the
+// TransferLoopThreshold of the [Config] is zero.
+const DefaultTransferLoopThreshold = 2
+
+// DefaultToolParallelism is the maximum number of concurrent function calls
+// of a model response if the ToolParallelism of the [Config] is zero.
+const DefaultToolParallelism = 8
+
+// ToolCallDurationsMetadataKey is the custom metadata key of the function
+// response events, whose value maps the IDs of the function calls to their
+// durations in milliseconds.
+const ToolCallDurationsMetadataKey = llminternal.ToolCallDurationsMetadataKey
+
+// CachedToolCallsMetadataKey is the custom metadata key of the function
+// response events, whose value lists the IDs of the function calls whose
+// results were served from the tool cache, see the ToolCache of the
+// [Config].
+const CachedToolCallsMetadataKey = llminternal.CachedToolCallsMetadataKey
+
+// ToolErrorPolicy controls the tool calls of an llmagent which fail, i.e.
+// whose tools return an error, panic or time out. The calls whose tools
+// requested a confirmation aren't failures, nor are the calls of unknown
+// tools, whose errors are always returned to the model.
+type ToolErrorPolicy string
+
+const (
+	// ToolErrorPolicyReturnToModel returns the errors to the model, in the
+	// "error" of the function responses.
+	ToolErrorPolicyReturnToModel ToolErrorPolicy = "return_to_model"
+	// ToolErrorPolicyFailInvocation ends the invocation with the error.
+	ToolErrorPolicyFailInvocation ToolErrorPolicy = "fail_invocation"
+	// ToolErrorPolicyRetry retries the calls as many times as the
+	// ToolRetries of the [Config], then returns their errors to the model.
+	ToolErrorPolicyRetry ToolErrorPolicy = "retry"
+)
+
+// HistoryBudget is the token budget of the model requests of an llmagent.
+//
+// The tokens of the requests are counted by the model of the agent, if it
+// implements [model.TokenCounter], or else estimated. When a request is
+// over the budget, its oldest turns are dropped, or summarized, until it
+// fits. The system instruction and the latest turn, which starts with the
+// latest user content, are always kept. The model response events of the
+// shrunk requests have the [HistoryBudgetMetadataKey] custom metadata.
+type HistoryBudget struct {
+	// MaxTokens is the budget of tokens of a request.
+	MaxTokens int
+	// Strategy is how the requests are shrunk.
+	// Optional: if empty, [HistoryStrategyDrop] is used.
+	Strategy HistoryStrategy
+	// SummaryModel summarizes the oldest turns with [HistoryStrategySummarize],
+	// typically a cheaper model than that of the agent.
+	// Optional: if nil, the model of the agent is used.
+	SummaryModel model.LLM
+}
+
+// HistoryStrategy is how the requests over their [HistoryBudget] are shrunk.
+type HistoryStrategy string
+
+const (
+	// HistoryStrategyDrop drops the oldest turns.
+	HistoryStrategyDrop HistoryStrategy = "drop"
+	// HistoryStrategySummarize replaces the oldest turns with a user content
+	// with their summary. If the summary fails, they are dropped.
+	HistoryStrategySummarize HistoryStrategy = "summarize"
+)
+
+// HistoryBudgetMetadataKey is the custom metadata key of the model response
+// events of the requests shrunk to fit their [HistoryBudget]. Its value is
+// a map with the "strategy", the number of "dropped_contents", and the
+// "tokens_before", "tokens_after" and "tokens_shed" counts.
+const HistoryBudgetMetadataKey = llminternal.HistoryBudgetMetadataKey
+
 // IncludeContents controls what parts of prior conversation history is received by llmagent.
 type IncludeContents string
 
@@ -321,8 +737,48 @@ const (
 	IncludeContentsNone IncludeContents = "none"
 	// IncludeContentsDefault is enabled by default. The llmagent receives the relevant conversation history.
 	IncludeContentsDefault IncludeContents = "default"
+	// IncludeContentsFiltered makes the llmagent receive the conversation history selected by its ContentsFilter.
+	IncludeContentsFiltered IncludeContents = "filtered"
 )
 
+// ContentsFilter returns the events of the conversation history the agent
+// receives, in order, among the events of the session. The events of the
+// other branches, e.g. of the sibling agents of a parallel agent, are
+// already excluded.
+type ContentsFilter func(ctx agent.ReadonlyContext, events []*session.Event) []*session.Event
+
+// AgentAndUserEvents is a [ContentsFilter] keeping only the events authored
+// by the agent and the user.
+func AgentAndUserEvents(ctx agent.ReadonlyContext, events []*session.Event) []*session.Event {
+	var filtered []*session.Event
+	for _, ev := range events {
+		if ev.Author == ctx.AgentName() || ev.Author == "user" {
+			filtered = append(filtered, ev)
+		}
+	}
+	return filtered
+}
+
+// LastTurns returns a [ContentsFilter] keeping only the events of the last n
+// turns, a turn starting with an event authored by the user.
+func LastTurns(n int) ContentsFilter {
+	return func(ctx agent.ReadonlyContext, events []*session.Event) []*session.Event {
+		turns := 0
+		for i := len(events) - 1; i >= 0; i-- {
+			if events[i].Author != "user" {
+				continue
+			}
+			if turns++; turns == n {
+				return events[i:]
+			}
+		}
+		if n <= 0 {
+			return nil
+		}
+		return events
+	}
+}
+
 type llmAgent struct {
 	agent.Agent
 	llminternal.State
@@ -337,6 +793,7 @@ type llmAgent struct {
 	beforeToolCallbacks  []llminternal.BeforeToolCallback
 	afterToolCallbacks   []llminternal.AfterToolCallback
 	onToolErrorCallbacks []llminternal.OnToolErrorCallback
+	toolOptions          llminternal.ToolOptions
 
 	inputSchema  *genai.Schema
 	outputSchema *genai.Schema
@@ -367,6 +824,7 @@ func (a *llmAgent) run(ctx agent.InvocationContext) iter.Seq2[*session.Event, er
 		BeforeToolCallbacks:   a.beforeToolCallbacks,
 		AfterToolCallbacks:    a.afterToolCallbacks,
 		OnToolErrorCallbacks:  a.onToolErrorCallbacks,
+		ToolOptions:           a.toolOptions,
 	}
 
 	return func(yield func(*session.Event, error) bool) {
@@ -396,16 +854,22 @@ func (a *llmAgent) maybeSaveOutputToState(event *session.Event) {
 				sb.WriteString(part.Text)
 			}
 		}
-		result := sb.String()
+		var result any = sb.String()
 
-		// TODO: add output schema validation and unmarshalling
 		if a.OutputSchema != nil {
 			// If the result from the final chunk is just whitespace or empty,
 			// it means this is an empty final chunk of a stream.
 			// Do not attempt to parse it as JSON.
-			if strings.TrimSpace(result) == "" {
+			if strings.TrimSpace(sb.String()) == "" {
 				return
 			}
+			// Save the structured output, the replies were validated by
+			// the flow. The raw text of the invalid replies kept in lenient
+			// mode is saved as is.
+			var value any
+			if err := json.Unmarshal([]byte(sb.String()), &value); err == nil {
+				result = value
+			}
 		}
 
 		if event.Actions.StateDelta == nil {
@@ -29,6 +29,14 @@ type MockOutputSchema struct {
 	Confidence float64 `json:"confidence"`
 }
 
+var outputSchema = &genai.Schema{
+	Type: genai.TypeObject,
+	Properties: map[string]*genai.Schema{
+		"message":    {Type: genai.TypeString},
+		"confidence": {Type: genai.TypeNumber},
+	},
+}
+
 // createTestEvent is a helper to build events for tests.
 func createTestEvent(author, contentText string, isFinal bool) *session.Event {
 	var parts []*genai.Part
@@ -105,7 +113,24 @@ func TestLlmAgent_MaybeSaveOutputToState(t *testing.T) {
 			event:          createTestEvent("testagent", "Test response", true),
 			wantStateDelta: map[string]any{},
 		},
-		// TODO tests with OutputSchema
+		{
+			name:           "saves structured output with output schema",
+			agentConfig:    Config{Name: "test_agent", OutputKey: "result", OutputSchema: outputSchema},
+			event:          createTestEvent("test_agent", `{"message": "hi", "confidence": 0.5}`, true),
+			wantStateDelta: map[string]any{"result": map[string]any{"message": "hi", "confidence": 0.5}},
+		},
+		{
+			name:           "saves raw text of invalid output with output schema",
+			agentConfig:    Config{Name: "test_agent", OutputKey: "result", OutputSchema: outputSchema, OutputValidation: OutputValidationLenient},
+			event:          createTestEvent("test_agent", "not json", true),
+			wantStateDelta: map[string]any{"result": "not json"},
+		},
+		{
+			name:           "skips whitespace output with output schema",
+			agentConfig:    Config{Name: "test_agent", OutputKey: "result", OutputSchema: outputSchema},
+			event:          createTestEvent("test_agent", "  ", true),
+			wantStateDelta: map[string]any{},
+		},
 	}
 
 	// Iterate over the test cases
@@ -15,6 +15,7 @@
 package llmagent_test
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"iter"
@@ -22,15 +23,19 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"google.golang.org/genai"
 
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/codeexecutor"
+	"google.golang.org/adk/example"
 	"google.golang.org/adk/internal/testutil"
 	"google.golang.org/adk/model"
 	"google.golang.org/adk/model/gemini"
+	"google.golang.org/adk/planner"
 	"google.golang.org/adk/session"
 	"google.golang.org/adk/tool"
 	"google.golang.org/adk/tool/functiontool"
@@ -441,6 +446,61 @@ func TestModelCallbacks(t *testing.T) {
 	}
 }
 
+func TestCallbackPanics(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		cfg     llmagent.Config
+		wantErr string
+	}{
+		{
+			name: "before model callback",
+			cfg: llmagent.Config{
+				BeforeModelCallbacks: []llmagent.BeforeModelCallback{panickingBeforeModelCallback},
+			},
+			wantErr: "callback llmagent_test.panickingBeforeModelCallback panicked: boom",
+		},
+		{
+			name: "before agent callback",
+			cfg: llmagent.Config{
+				BeforeAgentCallbacks: []agent.BeforeAgentCallback{
+					func(agent.CallbackContext) (*genai.Content, error) {
+						panic("boom")
+					},
+				},
+			},
+			wantErr: "panicked: boom",
+		},
+		{
+			name: "on model error callback",
+			cfg: llmagent.Config{
+				OnModelErrorCallbacks: []llmagent.OnModelErrorCallback{
+					func(agent.CallbackContext, *model.LLMRequest, error) (*model.LLMResponse, error) {
+						panic("boom")
+					},
+				},
+			},
+			wantErr: "panicked: boom",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.cfg.Name = "panicking_agent"
+			tc.cfg.Model = &testutil.MockModel{}
+			a, err := llmagent.New(tc.cfg)
+			if err != nil {
+				t.Fatalf("failed to create llm agent: %v", err)
+			}
+			_, err = testutil.CollectTextParts(testutil.NewTestAgentRunner(t, a).Run(t, "test_session", "hi"))
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("run error = %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func panickingBeforeModelCallback(agent.CallbackContext, *model.LLMRequest) (*model.LLMResponse, error) {
+	panic("boom")
+}
+
 func TestToolCallback(t *testing.T) {
 	model := newGeminiModel(t, modelName, nil)
 
@@ -1067,6 +1127,83 @@ func TestAgentTransfer(t *testing.T) {
 		})
 	})
 
+	t.Run("transfer_loop", func(t *testing.T) {
+		// root_agent -- sub_agent_1, transferring to each other.
+		model := testModel(
+			transferCall("sub_agent_1"),
+			transferCall("root_agent"),
+			transferCall("sub_agent_1"),
+			text("response1"))
+
+		subAgent1, err := llmagent.New(llmagent.Config{
+			Name:                  "sub_agent_1",
+			Model:                 model,
+			TransferLoopThreshold: 1,
+		})
+		if err != nil {
+			t.Fatalf("failed to create subAgent1: %v", err)
+		}
+
+		rootAgent, err := llmagent.New(llmagent.Config{
+			Name:                  "root_agent",
+			Model:                 model,
+			SubAgents:             []agent.Agent{subAgent1},
+			TransferLoopThreshold: 1,
+		})
+		if err != nil {
+			t.Fatalf("failed to create rootAgent: %v", err)
+		}
+
+		check(t, rootAgent, [][]content{
+			0: {
+				{"root_agent", transferCall("sub_agent_1").Parts},
+				{"root_agent", transferResponse().Parts},
+				{"sub_agent_1", transferCall("root_agent").Parts},
+				{"sub_agent_1", transferResponse().Parts},
+				{"root_agent", transferCall("sub_agent_1").Parts},
+				{"root_agent", transferResponse().Parts},
+				{"root_agent", text("Stopped the transfer from agent root_agent back to agent sub_agent_1: the agents keep transferring to each other.").Parts},
+			},
+		})
+	})
+
+	t.Run("records_transfer", func(t *testing.T) {
+		model := testModel(
+			transferCall("sub_agent_1"),
+			text("response1"))
+
+		subAgent1, err := llmagent.New(llmagent.Config{
+			Name:  "sub_agent_1",
+			Model: model,
+		})
+		if err != nil {
+			t.Fatalf("failed to create subAgent1: %v", err)
+		}
+		rootAgent, err := llmagent.New(llmagent.Config{
+			Name:      "root_agent",
+			Model:     model,
+			SubAgents: []agent.Agent{subAgent1},
+		})
+		if err != nil {
+			t.Fatalf("failed to create rootAgent: %v", err)
+		}
+
+		runner := testutil.NewTestAgentRunner(t, rootAgent)
+		var transfers []*session.EventTransfer
+		for ev, err := range runner.Run(t, "session_id", "round 0") {
+			if err != nil {
+				t.Fatalf("stream ended with an error: %v", err)
+			}
+			if ev.Actions.Transfer != nil {
+				transfers = append(transfers, ev.Actions.Transfer)
+			}
+		}
+		want := []*session.EventTransfer{{FromAgent: "root_agent", ToAgent: "sub_agent_1"}}
+		if diff := cmp.Diff(want, transfers); diff != "" {
+			t.Errorf("transfers diff (-want, +got) = %v", diff)
+		}
+	})
+
 	// TODO: cover cases similar to adk-python's
 	// tests/unittests/flows/llm_flows/test_agent_transfer.py
 	//   - test_auto_to_sequential
@@ -1096,3 +1233,963 @@ type roundTripperFunc func(*http.Request) (*http.Response, error)
 func (fn roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
 	return fn(req)
 }
+
+// unavailableModel fails with a retryable error.
+type unavailableModel struct{}
+
+func (unavailableModel) Name() string { return "unavailable" }
+
+func (unavailableModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		yield(nil, genai.APIError{Code: http.StatusServiceUnavailable, Status: "UNAVAILABLE"})
+	}
+}
+
+func TestModelName(t *testing.T) {
+	registry := model.NewRegistry()
+	if err := registry.Register("mock-*", func(ctx context.Context, name string) (model.LLM, error) {
+		return &testutil.MockModel{Responses: []*genai.Content{genai.NewContentFromText("Hello from "+name, genai.RoleModel)}}, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Register("unavailable-*", func(ctx context.Context, name string) (model.LLM, error) {
+		return unavailableModel{}, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		cfg        llmagent.Config
+		wantText   string
+		wantModel  any
+		wantNewErr bool
+	}{
+		{
+			name:     "model_name",
+			cfg:      llmagent.Config{ModelName: "mock-1"},
+			wantText: "Hello from mock-1",
+		},
+		{
+			name:      "fallback_model_names",
+			cfg:       llmagent.Config{ModelName: "unavailable-1", FallbackModelNames: []string{"mock-2"}},
+			wantText:  "Hello from mock-2",
+			wantModel: "mock-2",
+		},
+		{
+			name:      "model_with_fallback_model_names",
+			cfg:       llmagent.Config{Model: unavailableModel{}, FallbackModelNames: []string{"mock-3"}},
+			wantText:  "Hello from mock-3",
+			wantModel: "mock-3",
+		},
+		{
+			name:       "unregistered_model_name",
+			cfg:        llmagent.Config{ModelName: "gpt-4o"},
+			wantNewErr: true,
+		},
+		{
+			name:       "unregistered_fallback_model_name",
+			cfg:        llmagent.Config{ModelName: "mock-1", FallbackModelNames: []string{"gpt-4o"}},
+			wantNewErr: true,
+		},
+		{
+			name:       "model_and_model_name",
+			cfg:        llmagent.Config{Model: unavailableModel{}, ModelName: "mock-1"},
+			wantNewErr: true,
+		},
+		{
+			name:       "fallback_model_names_only",
+			cfg:        llmagent.Config{FallbackModelNames: []string{"mock-1"}},
+			wantNewErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			cfg.Name = "agent"
+			cfg.ModelRegistry = registry
+			a, err := llmagent.New(cfg)
+			if (err != nil) != tt.wantNewErr {
+				t.Fatalf("llmagent.New() error = %v, wantErr %v", err, tt.wantNewErr)
+			}
+			if tt.wantNewErr {
+				return
+			}
+
+			events, err := testutil.CollectEvents(testutil.NewTestAgentRunner(t, a).Run(t, "session", "Hi"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(events) != 1 {
+				t.Fatalf("got %d events, want 1", len(events))
+			}
+			if got := events[0].Content.Parts[0].Text; got != tt.wantText {
+				t.Errorf("event text = %q, want %q", got, tt.wantText)
+			}
+			if got := events[0].CustomMetadata[model.ModelMetadataKey]; got != tt.wantModel {
+				t.Errorf("event model = %v, want %v", got, tt.wantModel)
+			}
+		})
+	}
+}
+
+func TestOutputValidation(t *testing.T) {
+	schema := &genai.Schema{
+		Type:       genai.TypeObject,
+		Properties: map[string]*genai.Schema{"city": {Type: genai.TypeString}},
+		Required:   []string{"city"},
+	}
+	valid := genai.NewContentFromText(`{"city": "Paris"}`, genai.RoleModel)
+	invalid := genai.NewContentFromText(`The city is Paris.`, genai.RoleModel)
+	tests := []struct {
+		name           string
+		responses      []*genai.Content
+		repairAttempts int
+		validation     llmagent.OutputValidation
+		wantTexts      []string
+		wantErrorCode  string
+		wantOutput     any
+		wantRequests   int
+	}{
+		{
+			name:         "valid",
+			responses:    []*genai.Content{valid},
+			wantTexts:    []string{`{"city": "Paris"}`},
+			wantOutput:   map[string]any{"city": "Paris"},
+			wantRequests: 1,
+		},
+		{
+			name:         "repaired",
+			responses:    []*genai.Content{invalid, valid},
+			wantTexts:    []string{`{"city": "Paris"}`},
+			wantOutput:   map[string]any{"city": "Paris"},
+			wantRequests: 2,
+		},
+		{
+			name:          "strict",
+			responses:     []*genai.Content{invalid, invalid, invalid},
+			wantErrorCode: llmagent.ErrorCodeInvalidOutput,
+			wantRequests:  3,
+		},
+		{
+			name:           "no repair",
+			responses:      []*genai.Content{invalid, valid},
+			repairAttempts: -1,
+			wantErrorCode:  llmagent.ErrorCodeInvalidOutput,
+			wantRequests:   1,
+		},
+		{
+			name:           "lenient",
+			responses:      []*genai.Content{invalid, invalid},
+			repairAttempts: 1,
+			validation:     llmagent.OutputValidationLenient,
+			wantTexts:      []string{"The city is Paris."},
+			wantOutput:     "The city is Paris.",
+			wantRequests:   2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testLLM := &testutil.MockModel{Responses: tt.responses}
+			a, err := llmagent.New(llmagent.Config{
+				Name:                 "city_agent",
+				Model:                testLLM,
+				OutputSchema:         schema,
+				OutputKey:            "city",
+				OutputRepairAttempts: tt.repairAttempts,
+				OutputValidation:     tt.validation,
+			})
+			if err != nil {
+				t.Fatalf("failed to create llm agent: %v", err)
+			}
+			var texts []string
+			var errorCode string
+			var output any
+			for ev, err := range testutil.NewTestAgentRunner(t, a).Run(t, "test_session", "where?") {
+				if err != nil {
+					t.Fatalf("agent returned an error: %v", err)
+				}
+				if ev.Content != nil {
+					for _, part := range ev.Content.Parts {
+						texts = append(texts, part.Text)
+					}
+				}
+				if ev.ErrorCode != "" {
+					errorCode = ev.ErrorCode
+				}
+				if v, ok := ev.Actions.StateDelta["city"]; ok {
+					output = v
+				}
+			}
+			if diff := cmp.Diff(tt.wantTexts, texts); diff != "" {
+				t.Errorf("texts mismatch (-want +got):\n%s", diff)
+			}
+			if errorCode != tt.wantErrorCode {
+				t.Errorf("error code = %q, want %q", errorCode, tt.wantErrorCode)
+			}
+			if diff := cmp.Diff(tt.wantOutput, output); diff != "" {
+				t.Errorf("output mismatch (-want +got):\n%s", diff)
+			}
+			if len(testLLM.Requests) != tt.wantRequests {
+				t.Fatalf("model requests = %d, want %d", len(testLLM.Requests), tt.wantRequests)
+			}
+			if tt.wantRequests > 1 {
+				// The repair request carries the invalid reply and the
+				// validation error.
+				contents := testLLM.Requests[1].Contents
+				if n := len(contents); n < 2 || contents[n-2].Parts[0].Text != invalid.Parts[0].Text || !strings.Contains(contents[n-1].Parts[0].Text, "output schema") {
+					t.Errorf("repair request contents = %v, want the invalid reply and the validation error", contents)
+				}
+			}
+		})
+	}
+}
+
+func TestThinkingConfig(t *testing.T) {
+	budget := int32(1024)
+	thinkingConfig := &genai.ThinkingConfig{ThinkingBudget: &budget, IncludeThoughts: true}
+
+	tests := []struct {
+		name       string
+		cfg        llmagent.Config
+		want       *genai.ThinkingConfig
+		wantNewErr bool
+	}{
+		{
+			name: "thinking_config",
+			cfg:  llmagent.Config{ThinkingConfig: thinkingConfig},
+			want: thinkingConfig,
+		},
+		{
+			name: "generate_content_config",
+			cfg:  llmagent.Config{GenerateContentConfig: &genai.GenerateContentConfig{ThinkingConfig: thinkingConfig}},
+			want: thinkingConfig,
+		},
+		{
+			name: "none",
+		},
+		{
+			name: "both",
+			cfg: llmagent.Config{
+				ThinkingConfig:        thinkingConfig,
+				GenerateContentConfig: &genai.GenerateContentConfig{ThinkingConfig: &genai.ThinkingConfig{}},
+			},
+			wantNewErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reply := &genai.Content{Role: genai.RoleModel, Parts: []*genai.Part{
+				{Text: "Let me think.", Thought: true},
+				{Text: "Hello"},
+			}}
+			mock := &testutil.MockModel{Responses: []*genai.Content{reply}}
+			cfg := tt.cfg
+			cfg.Name = "agent"
+			cfg.Model = mock
+			cfg.OutputKey = "reply"
+			a, err := llmagent.New(cfg)
+			if (err != nil) != tt.wantNewErr {
+				t.Fatalf("llmagent.New() error = %v, wantErr %v", err, tt.wantNewErr)
+			}
+			if tt.wantNewErr {
+				return
+			}
+
+			events, err := testutil.CollectEvents(testutil.NewTestAgentRunner(t, a).Run(t, "session", "Hi"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, mock.Requests[0].Config.ThinkingConfig); diff != "" {
+				t.Errorf("request ThinkingConfig mismatch (-want +got):\n%s", diff)
+			}
+			if len(events) != 1 {
+				t.Fatalf("got %d events, want 1", len(events))
+			}
+			if diff := cmp.Diff(reply, events[0].Content); diff != "" {
+				t.Errorf("event content mismatch (-want +got):\n%s", diff)
+			}
+			// The thoughts aren't part of the output of the agent.
+			if got := events[0].Actions.StateDelta["reply"]; got != "Hello" {
+				t.Errorf("saved output = %v, want %q", got, "Hello")
+			}
+		})
+	}
+}
+
+// hangingModel blocks until its context is done.
+type hangingModel struct{}
+
+func (hangingModel) Name() string { return "hanging" }
+
+func (hangingModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		<-ctx.Done()
+		yield(nil, ctx.Err())
+	}
+}
+
+func TestModelTimeout(t *testing.T) {
+	a, err := llmagent.New(llmagent.Config{
+		Name:         "agent",
+		Model:        hangingModel{},
+		ModelTimeout: 10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("failed to create llm agent: %v", err)
+	}
+
+	var events []*session.Event
+	for ev, err := range testutil.NewTestAgentRunner(t, a).Run(t, "session", "Hi") {
+		if err != nil {
+			t.Fatalf("agent returned an error: %v", err)
+		}
+		events = append(events, ev)
+	}
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want 1", len(events))
+	}
+	if got := events[0].ErrorCode; got != model.ErrorCodeTimeout {
+		t.Errorf("event error code = %q, want %q", got, model.ErrorCodeTimeout)
+	}
+	if want := "model hanging call timed out after 10ms"; events[0].ErrorMessage != want {
+		t.Errorf("event error message = %q, want %q", events[0].ErrorMessage, want)
+	}
+}
+
+func TestToolErrorPolicy(t *testing.T) {
+	tests := []struct {
+		name         string
+		policy       llmagent.ToolErrorPolicy
+		retries      int
+		failures     int
+		wantResponse map[string]any
+		wantErr      string
+		wantCalls    int
+	}{
+		{
+			name:         "return to model",
+			failures:     1,
+			wantResponse: map[string]any{"error": "transient failure"},
+			wantCalls:    1,
+		},
+		{
+			name:         "retry",
+			policy:       llmagent.ToolErrorPolicyRetry,
+			failures:     2,
+			wantResponse: map[string]any{"result": "done"},
+			wantCalls:    3,
+		},
+		{
+			name:         "retry exhausted",
+			policy:       llmagent.ToolErrorPolicyRetry,
+			retries:      1,
+			failures:     2,
+			wantResponse: map[string]any{"error": "transient failure"},
+			wantCalls:    2,
+		},
+		{
+			name:      "fail invocation",
+			policy:    llmagent.ToolErrorPolicyFailInvocation,
+			failures:  1,
+			wantErr:   `call of tool "flaky" failed: transient failure`,
+			wantCalls: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			flaky, err := functiontool.New(functiontool.Config{
+				Name:        "flaky",
+				Description: "fails a few times",
+			}, func(tool.Context, struct{}) (string, error) {
+				calls++
+				if calls <= tt.failures {
+					return "", errors.New("transient failure")
+				}
+				return "done", nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			testLLM := &testutil.MockModel{Responses: []*genai.Content{
+				genai.NewContentFromFunctionCall("flaky", map[string]any{}, genai.RoleModel),
+				genai.NewContentFromText("answer", genai.RoleModel),
+			}}
+			a, err := llmagent.New(llmagent.Config{
+				Name:            "agent",
+				Model:           testLLM,
+				Tools:           []tool.Tool{flaky},
+				ToolErrorPolicy: tt.policy,
+				ToolRetries:     tt.retries,
+			})
+			if err != nil {
+				t.Fatalf("failed to create llm agent: %v", err)
+			}
+
+			var runErr error
+			var response map[string]any
+			for ev, err := range testutil.NewTestAgentRunner(t, a).Run(t, "session", "Hi") {
+				if err != nil {
+					runErr = err
+					break
+				}
+				if ev.Content != nil && len(ev.Content.Parts) > 0 && ev.Content.Parts[0].FunctionResponse != nil {
+					response = ev.Content.Parts[0].FunctionResponse.Response
+				}
+			}
+			if tt.wantErr != "" {
+				if runErr == nil || runErr.Error() != tt.wantErr {
+					t.Errorf("run error = %v, want %q", runErr, tt.wantErr)
+				}
+			} else if runErr != nil {
+				t.Fatalf("agent returned an error: %v", runErr)
+			}
+			if diff := cmp.Diff(tt.wantResponse, response); diff != "" {
+				t.Errorf("function response mismatch (-want +got):\n%s", diff)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("tool calls = %d, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+
+	if _, err := llmagent.New(llmagent.Config{Name: "agent", ToolErrorPolicy: "ignore"}); err == nil {
+		t.Errorf("llmagent.New() with an invalid tool error policy succeeded, want an error")
+	}
+}
+
+func TestToolCache(t *testing.T) {
+	calls := 0
+	geocode, err := functiontool.New(functiontool.Config{
+		Name:        "geocode",
+		Description: "geocodes an address",
+		CacheTTL:    time.Hour,
+	}, func(_ tool.Context, args struct {
+		Address string `json:"address"`
+	}) (string, error) {
+		calls++
+		return "48.85,2.35", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	call := genai.NewContentFromFunctionCall("geocode", map[string]any{"address": "Paris"}, genai.RoleModel)
+	testLLM := &testutil.MockModel{Responses: []*genai.Content{
+		call, genai.NewContentFromText("answer", genai.RoleModel),
+		call, genai.NewContentFromText("answer", genai.RoleModel),
+	}}
+	a, err := llmagent.New(llmagent.Config{
+		Name:      "agent",
+		Model:     testLLM,
+		Tools:     []tool.Tool{geocode},
+		ToolCache: tool.NewLRUResultCache(1 << 20),
+	})
+	if err != nil {
+		t.Fatalf("failed to create llm agent: %v", err)
+	}
+
+	runner := testutil.NewTestAgentRunner(t, a)
+	var cached []bool
+	for range 2 {
+		for ev, err := range runner.Run(t, "session", "Where is Paris?") {
+			if err != nil {
+				t.Fatalf("agent returned an error: %v", err)
+			}
+			if ev.Content != nil && len(ev.Content.Parts) > 0 && ev.Content.Parts[0].FunctionResponse != nil {
+				_, ok := ev.CustomMetadata[llmagent.CachedToolCallsMetadataKey]
+				cached = append(cached, ok)
+			}
+		}
+	}
+	if diff := cmp.Diff([]bool{false, true}, cached); diff != "" {
+		t.Errorf("cached function responses mismatch (-want +got):\n%s", diff)
+	}
+	if calls != 1 {
+		t.Errorf("tool calls = %d, want 1", calls)
+	}
+
+	if _, err := llmagent.New(llmagent.Config{Name: "agent", ToolCacheScope: "global"}); err == nil {
+		t.Errorf("llmagent.New() with an invalid tool cache scope succeeded, want an error")
+	}
+}
+
+func TestHistoryBudget(t *testing.T) {
+	for _, cfg := range []llmagent.HistoryBudget{{MaxTokens: 0}, {MaxTokens: 10, Strategy: "forget"}} {
+		if _, err := llmagent.New(llmagent.Config{Name: "agent", Model: &testutil.MockModel{}, HistoryBudget: &cfg}); err == nil {
+			t.Errorf("llmagent.New() with history budget %+v succeeded, want error", cfg)
+		}
+	}
+
+	long := strings.Repeat("word ", 20)
+	mock := &testutil.MockModel{Responses: []*genai.Content{
+		genai.NewContentFromText(long, genai.RoleModel),
+		genai.NewContentFromText("Second reply", genai.RoleModel),
+	}}
+	a, err := llmagent.New(llmagent.Config{
+		Name:          "agent",
+		Model:         mock,
+		HistoryBudget: &llmagent.HistoryBudget{MaxTokens: 20},
+	})
+	if err != nil {
+		t.Fatalf("failed to create llm agent: %v", err)
+	}
+	runner := testutil.NewTestAgentRunner(t, a)
+	if _, err := testutil.CollectEvents(runner.Run(t, "session", long)); err != nil {
+		t.Fatal(err)
+	}
+	events, err := testutil.CollectEvents(runner.Run(t, "session", "Next question"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first turn is dropped from the second request.
+	gotContents := mock.Requests[1].Contents
+	if len(gotContents) != 1 || gotContents[0].Parts[0].Text != "Next question" {
+		t.Errorf("second request contents = %v, want only the latest user content", gotContents)
+	}
+	metadata, ok := events[0].CustomMetadata[llmagent.HistoryBudgetMetadataKey].(map[string]any)
+	if !ok {
+		t.Fatalf("event custom metadata = %v, want the history budget", events[0].CustomMetadata)
+	}
+	if metadata["strategy"] != "drop" || metadata["dropped_contents"] != 2 {
+		t.Errorf("history budget metadata = %v, want 2 dropped contents", metadata)
+	}
+}
+
+type adminToolset struct {
+	del tool.Tool
+}
+
+func (*adminToolset) Name() string { return "admin" }
+
+func (ts *adminToolset) Tools(ctx agent.ReadonlyContext) ([]tool.Tool, error) {
+	if admin, err := ctx.ReadonlyState().Get("admin"); err != nil || admin != true {
+		return nil, nil
+	}
+	return []tool.Tool{ts.del}, nil
+}
+
+func TestToolsets_PerRequest(t *testing.T) {
+	grant, err := functiontool.New(functiontool.Config{
+		Name:        "grant",
+		Description: "grants the admin role",
+	}, func(ctx tool.Context, _ struct{}) (string, error) {
+		return "granted", ctx.State().Set("admin", true)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	del, err := functiontool.New(functiontool.Config{
+		Name:        "delete",
+		Description: "deletes everything",
+	}, func(tool.Context, struct{}) (string, error) {
+		return "deleted", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testLLM := &testutil.MockModel{Responses: []*genai.Content{
+		genai.NewContentFromFunctionCall("grant", map[string]any{}, genai.RoleModel),
+		genai.NewContentFromText("done", genai.RoleModel),
+	}}
+	a, err := llmagent.New(llmagent.Config{
+		Name:     "agent",
+		Model:    testLLM,
+		Tools:    []tool.Tool{grant},
+		Toolsets: []tool.Toolset{&adminToolset{del: del}},
+	})
+	if err != nil {
+		t.Fatalf("failed to create llm agent: %v", err)
+	}
+	if _, err := testutil.CollectEvents(testutil.NewTestAgentRunner(t, a).Run(t, "session", "Make me an admin")); err != nil {
+		t.Fatal(err)
+	}
+
+	var got [][]string
+	for _, req := range testLLM.Requests {
+		var names []string
+		for _, t := range req.Config.Tools {
+			for _, decl := range t.FunctionDeclarations {
+				names = append(names, decl.Name)
+			}
+		}
+		got = append(got, names)
+	}
+	want := [][]string{{"grant"}, {"grant", "delete"}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("tools of the model requests mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestToolCallback_Policy(t *testing.T) {
+	var got []int
+	limit, err := functiontool.New(functiontool.Config{
+		Name:        "limit",
+		Description: "lists n items",
+	}, func(_ tool.Context, args struct {
+		N int `json:"n"`
+	}) (map[string]any, error) {
+		got = append(got, args.N)
+		return map[string]any{"items": args.N}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testLLM := &testutil.MockModel{Responses: []*genai.Content{
+		genai.NewContentFromFunctionCall("limit", map[string]any{"n": 50.0}, genai.RoleModel),
+		genai.NewContentFromFunctionCall("limit", map[string]any{"n": -1.0}, genai.RoleModel),
+		genai.NewContentFromText("done", genai.RoleModel),
+	}}
+	a, err := llmagent.New(llmagent.Config{
+		Name:  "agent",
+		Model: testLLM,
+		Tools: []tool.Tool{limit},
+		BeforeToolCallbacks: []llmagent.BeforeToolCallback{
+			func(ctx tool.Context, _ tool.Tool, args map[string]any) (map[string]any, error) {
+				n, _ := args["n"].(float64)
+				if n < 0 {
+					return nil, fmt.Errorf("n must not be negative")
+				}
+				if n > 10 {
+					args["n"] = 10
+					return nil, ctx.State().Set("clamped", true)
+				}
+				return nil, nil
+			},
+		},
+		AfterToolCallbacks: []llmagent.AfterToolCallback{
+			func(_ tool.Context, _ tool.Tool, _, result map[string]any, err error) (map[string]any, error) {
+				if err != nil {
+					return nil, nil
+				}
+				return map[string]any{"items": result["items"], "checked": true}, nil
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create llm agent: %v", err)
+	}
+	events, err := testutil.CollectEvents(testutil.NewTestAgentRunner(t, a).Run(t, "session", "List the items"))
+	if err != nil {
+		t.Fatalf("agent returned an error: %v", err)
+	}
+
+	var responses []map[string]any
+	clamped := false
+	for _, ev := range events {
+		if ev.Content == nil {
+			continue
+		}
+		for _, p := range ev.Content.Parts {
+			if p.FunctionResponse != nil {
+				responses = append(responses, p.FunctionResponse.Response)
+				clamped = clamped || ev.Actions.StateDelta["clamped"] == true
+			}
+		}
+	}
+	if diff := cmp.Diff([]int{10}, got); diff != "" {
+		t.Errorf("tool arguments mismatch (-want +got):\n%s", diff)
+	}
+	want := []map[string]any{
+		{"items": 10.0, "checked": true},
+		{"error": "n must not be negative"},
+	}
+	if diff := cmp.Diff(want, responses); diff != "" {
+		t.Errorf("function responses mismatch (-want +got):\n%s", diff)
+	}
+	if !clamped {
+		t.Errorf("function response events have no state delta of the before callback")
+	}
+}
+
+// stdoutExecutor prints the code it runs.
+type stdoutExecutor struct{}
+
+func (stdoutExecutor) ExecuteCode(_ agent.InvocationContext, input *codeexecutor.Input) (*codeexecutor.Result, error) {
+	return &codeexecutor.Result{Stdout: "ran " + input.Language + ": " + input.Code}, nil
+}
+
+func TestCodeExecutor(t *testing.T) {
+	testLLM := &testutil.MockModel{Responses: []*genai.Content{
+		genai.NewContentFromText("Computing.\n```python\nprint(6 * 7)\n```\nignored", genai.RoleModel),
+		genai.NewContentFromText("It's 42.", genai.RoleModel),
+	}}
+	a, err := llmagent.New(llmagent.Config{
+		Name:         "agent",
+		Model:        testLLM,
+		CodeExecutor: stdoutExecutor{},
+	})
+	if err != nil {
+		t.Fatalf("failed to create llm agent: %v", err)
+	}
+	events, err := testutil.CollectEvents(testutil.NewTestAgentRunner(t, a).Run(t, "session", "What is 6 * 7?"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []*genai.Content
+	for _, ev := range events {
+		got = append(got, ev.Content)
+	}
+	want := []*genai.Content{
+		genai.NewContentFromParts([]*genai.Part{
+			genai.NewPartFromText("Computing.\n"),
+			genai.NewPartFromExecutableCode("print(6 * 7)", genai.LanguagePython),
+		}, genai.RoleModel),
+		genai.NewContentFromParts([]*genai.Part{
+			genai.NewPartFromCodeExecutionResult(genai.OutcomeOK, "Code execution result:\nran python: print(6 * 7)\n"),
+		}, genai.RoleModel),
+		genai.NewContentFromText("It's 42.", genai.RoleModel),
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("event contents mismatch (-want +got):\n%s", diff)
+	}
+	if len(testLLM.Requests) != 2 {
+		t.Fatalf("got %d model requests, want 2", len(testLLM.Requests))
+	}
+	wantContents := []*genai.Content{
+		genai.NewContentFromText("What is 6 * 7?", genai.RoleUser),
+		genai.NewContentFromParts([]*genai.Part{
+			genai.NewPartFromText("Computing.\n"),
+			genai.NewPartFromText("```tool_code\nprint(6 * 7)\n```"),
+		}, genai.RoleModel),
+		genai.NewContentFromText("```tool_output\nCode execution result:\nran python: print(6 * 7)\n\n```", genai.RoleModel),
+	}
+	if diff := cmp.Diff(wantContents, testLLM.Requests[1].Contents); diff != "" {
+		t.Errorf("contents of the second model request mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestPlanner(t *testing.T) {
+	testLLM := &testutil.MockModel{Responses: []*genai.Content{
+		genai.NewContentFromText("/*PLANNING*/\n1. Answer directly.\n/*FINAL_ANSWER*/\nIt's 42.", genai.RoleModel),
+		genai.NewContentFromText("/*FINAL_ANSWER*/ Still 42.", genai.RoleModel),
+	}}
+	a, err := llmagent.New(llmagent.Config{
+		Name:        "agent",
+		Model:       testLLM,
+		Planner:     &planner.PlanReActPlanner{},
+		OutputKey:   "answer",
+		Instruction: "Answer the questions.",
+	})
+	if err != nil {
+		t.Fatalf("failed to create llm agent: %v", err)
+	}
+	runner := testutil.NewTestAgentRunner(t, a)
+	events, err := testutil.CollectEvents(runner.Run(t, "session", "What is 6 * 7?"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want 1", len(events))
+	}
+	want := genai.NewContentFromParts([]*genai.Part{
+		{Text: "/*PLANNING*/\n1. Answer directly.\n", Thought: true},
+		genai.NewPartFromText("It's 42."),
+	}, genai.RoleModel)
+	if diff := cmp.Diff(want, events[0].Content); diff != "" {
+		t.Errorf("event content mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string]any{"answer": "It's 42."}, events[0].Actions.StateDelta); diff != "" {
+		t.Errorf("state delta mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := testutil.CollectEvents(runner.Run(t, "session", "Sure?")); err != nil {
+		t.Fatal(err)
+	}
+	req := testLLM.Requests[1]
+	if !strings.Contains(req.Config.SystemInstruction.Parts[len(req.Config.SystemInstruction.Parts)-1].Text, planner.FinalAnswerTag) {
+		t.Errorf("SystemInstruction = %v, want the planning instruction", req.Config.SystemInstruction)
+	}
+	wantContents := []*genai.Content{
+		genai.NewContentFromText("What is 6 * 7?", genai.RoleUser),
+		genai.NewContentFromParts([]*genai.Part{
+			genai.NewPartFromText("/*PLANNING*/\n1. Answer directly.\n"),
+			genai.NewPartFromText("It's 42."),
+		}, genai.RoleModel),
+		genai.NewContentFromText("Sure?", genai.RoleUser),
+	}
+	if diff := cmp.Diff(wantContents, req.Contents); diff != "" {
+		t.Errorf("contents of the second model request mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// thinkingModel is a MockModel supporting thinking.
+type thinkingModel struct {
+	*testutil.MockModel
+}
+
+func (thinkingModel) SupportsThinking() bool {
+	return true
+}
+
+func TestBuiltInPlanner(t *testing.T) {
+	thinkingConfig := &genai.ThinkingConfig{ThinkingBudget: genai.Ptr[int32](512), IncludeThoughts: true}
+	t.Run("unsupported", func(t *testing.T) {
+		_, err := llmagent.New(llmagent.Config{
+			Name:    "agent",
+			Model:   &testutil.MockModel{},
+			Planner: &planner.BuiltInPlanner{ThinkingConfig: thinkingConfig},
+		})
+		if err == nil {
+			t.Error("New() with a model not supporting thinking succeeded, want error")
+		}
+	})
+
+	t.Run("conflicting thinking configs", func(t *testing.T) {
+		_, err := llmagent.New(llmagent.Config{
+			Name:           "agent",
+			Model:          thinkingModel{&testutil.MockModel{}},
+			ThinkingConfig: thinkingConfig,
+			Planner:        &planner.BuiltInPlanner{ThinkingConfig: thinkingConfig},
+		})
+		if err == nil {
+			t.Error("New() with two thinking configs succeeded, want error")
+		}
+	})
+
+	t.Run("lenient", func(t *testing.T) {
+		testLLM := &testutil.MockModel{Responses: []*genai.Content{genai.NewContentFromText("42", genai.RoleModel)}}
+		a, err := llmagent.New(llmagent.Config{
+			Name:    "agent",
+			Model:   testLLM,
+			Planner: &planner.BuiltInPlanner{ThinkingConfig: thinkingConfig, Lenient: true},
+		})
+		if err != nil {
+			t.Fatalf("failed to create llm agent: %v", err)
+		}
+		if _, err := testutil.CollectEvents(testutil.NewTestAgentRunner(t, a).Run(t, "session", "What is 6 * 7?")); err != nil {
+			t.Fatal(err)
+		}
+		if got := testLLM.Requests[0].Config.ThinkingConfig; got != nil {
+			t.Errorf("ThinkingConfig = %v, want nil", got)
+		}
+	})
+
+	t.Run("supported", func(t *testing.T) {
+		testLLM := &testutil.MockModel{Responses: []*genai.Content{
+			genai.NewContentFromParts([]*genai.Part{{Text: "6 * 7 is 42.", Thought: true}, genai.NewPartFromText("42")}, genai.RoleModel),
+			genai.NewContentFromText("Yes.", genai.RoleModel),
+		}}
+		a, err := llmagent.New(llmagent.Config{
+			Name:    "agent",
+			Model:   thinkingModel{testLLM},
+			Planner: &planner.BuiltInPlanner{ThinkingConfig: thinkingConfig},
+		})
+		if err != nil {
+			t.Fatalf("failed to create llm agent: %v", err)
+		}
+		runner := testutil.NewTestAgentRunner(t, a)
+		events, err := testutil.CollectEvents(runner.Run(t, "session", "What is 6 * 7?"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(events) != 1 {
+			t.Fatalf("got %d events, want 1", len(events))
+		}
+		want := genai.NewContentFromParts([]*genai.Part{{Text: "6 * 7 is 42.", Thought: true}, genai.NewPartFromText("42")}, genai.RoleModel)
+		if diff := cmp.Diff(want, events[0].Content); diff != "" {
+			t.Errorf("event content mismatch (-want +got):\n%s", diff)
+		}
+		if diff := cmp.Diff(thinkingConfig, testLLM.Requests[0].Config.ThinkingConfig); diff != "" {
+			t.Errorf("ThinkingConfig mismatch (-want +got):\n%s", diff)
+		}
+		if testLLM.Requests[0].Config.SystemInstruction != nil {
+			t.Errorf("SystemInstruction = %v, want nil", testLLM.Requests[0].Config.SystemInstruction)
+		}
+
+		if _, err := testutil.CollectEvents(runner.Run(t, "session", "Sure?")); err != nil {
+			t.Fatal(err)
+		}
+		if got := testLLM.Requests[1].Contents[1].Parts[0]; !got.Thought {
+			t.Errorf("thought of the history unmarked: %v", got)
+		}
+	})
+}
+
+func TestNew_IncludeContents(t *testing.T) {
+	tests := []struct {
+		name            string
+		includeContents llmagent.IncludeContents
+		contentsFilter  llmagent.ContentsFilter
+		wantErr         bool
+	}{
+		{name: "default", includeContents: llmagent.IncludeContentsDefault},
+		{name: "filtered", includeContents: llmagent.IncludeContentsFiltered, contentsFilter: llmagent.LastTurns(2)},
+		{name: "filtered without filter", includeContents: llmagent.IncludeContentsFiltered, wantErr: true},
+		{name: "filter without filtered", includeContents: llmagent.IncludeContentsNone, contentsFilter: llmagent.AgentAndUserEvents, wantErr: true},
+		{name: "invalid", includeContents: "all", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := llmagent.New(llmagent.Config{
+				Name:            "agent",
+				Model:           &testutil.MockModel{},
+				IncludeContents: tt.includeContents,
+				ContentsFilter:  tt.contentsFilter,
+			})
+			if gotErr := err != nil; gotErr != tt.wantErr {
+				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLastTurns(t *testing.T) {
+	events := []*session.Event{
+		{Author: "user", InvocationID: "1"},
+		{Author: "agent", InvocationID: "1"},
+		{Author: "user", InvocationID: "2"},
+		{Author: "agent", InvocationID: "2"},
+		{Author: "user", InvocationID: "3"},
+	}
+	for n, want := range map[int][]*session.Event{0: nil, 1: events[4:], 2: events[2:], 3: events, 4: events} {
+		if diff := cmp.Diff(want, llmagent.LastTurns(n)(nil, events)); diff != "" {
+			t.Errorf("LastTurns(%d) mismatch (-want +got):\n%s", n, diff)
+		}
+	}
+}
+
+func TestExamples(t *testing.T) {
+	greeting := &example.Example{
+		Input:  genai.NewContentFromText("Hi!", genai.RoleUser),
+		Output: []*genai.Content{genai.NewContentFromText("Hello! How can I help?", genai.RoleModel)},
+	}
+	if _, err := llmagent.New(llmagent.Config{
+		Name:            "agent",
+		Model:           &testutil.MockModel{},
+		Examples:        []*example.Example{greeting},
+		ExampleProvider: &staticExamples{greeting},
+	}); err == nil {
+		t.Error("New() with both Examples and ExampleProvider succeeded, want error")
+	}
+
+	testLLM := &testutil.MockModel{Responses: []*genai.Content{genai.NewContentFromText("It's sunny.", genai.RoleModel)}}
+	a, err := llmagent.New(llmagent.Config{
+		Name:            "agent",
+		Model:           testLLM,
+		ExampleProvider: &staticExamples{greeting},
+		ExampleFormat:   llmagent.ExampleFormatHistory,
+	})
+	if err != nil {
+		t.Fatalf("failed to create llm agent: %v", err)
+	}
+	if _, err := testutil.CollectEvents(testutil.NewTestAgentRunner(t, a).Run(t, "session", "Weather?")); err != nil {
+		t.Fatal(err)
+	}
+	want := []*genai.Content{
+		genai.NewContentFromText("Hi!", genai.RoleUser),
+		genai.NewContentFromText("Hello! How can I help?", genai.RoleModel),
+		genai.NewContentFromText("Weather?", genai.RoleUser),
+	}
+	if diff := cmp.Diff(want, testLLM.Requests[0].Contents); diff != "" {
+		t.Errorf("contents of the model request mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// staticExamples is an example.Provider of fixed examples.
+type staticExamples []*example.Example
+
+func (e *staticExamples) Examples(agent.ReadonlyContext, string) ([]*example.Example, error) {
+	return *e, nil
+}
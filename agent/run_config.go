@@ -14,6 +14,12 @@
 
 package agent
 
+import (
+	"fmt"
+
+	"google.golang.org/genai"
+)
+
 // StreamingMode defines the streaming mode for agent execution.
 type StreamingMode string
 
@@ -23,6 +29,10 @@ const (
 	// StreamingModeSSE enables server-sent events streaming, one-way, where
 	// LLM response parts are streamed immediately as they are generated.
 	StreamingModeSSE StreamingMode = "sse"
+	// StreamingModeBidi is the mode of the live runs, where the requests of
+	// the user and the responses of the model are streamed both ways, see
+	// [google.golang.org/adk/runner.Runner.RunLive].
+	StreamingModeBidi StreamingMode = "bidi"
 )
 
 // RunConfig controls runtime behavior of an agent.
@@ -32,4 +42,42 @@ type RunConfig struct {
 	// If true, ADK runner will save each part of the user input that is a blob
 	// (e.g., images, files) as an artifact.
 	SaveInputBlobsAsArtifacts bool
+	// If true, the runner and the workflow agents record checkpoints of the
+	// progress of the invocation in its session, so that the invocation can
+	// be resumed from them if it is interrupted, e.g. by the restart of the
+	// process, see [google.golang.org/adk/runner.Runner.Resume].
+	Checkpointing bool
+	// MaxLLMCalls is the maximum number of model calls of an invocation,
+	// which protects against the agents calling tools in a loop. The model
+	// call exceeding it isn't made: the invocation ends with a final event
+	// with the [ErrorCodeLLMCallsLimit] error code instead.
+	// Optional: zero means no limit.
+	MaxLLMCalls int
+	// ResponseModalities are the modalities of the responses of the model,
+	// e.g. audio in live runs. They replace the response modalities of the
+	// generate content config of the agents.
+	// Optional: the agents' own configuration applies.
+	ResponseModalities []genai.Modality
+	// If true, the runner appends the partial events, e.g. the chunks of the
+	// streamed responses of the model, to the session too, so that the
+	// clients reading the session later can replay the stream. They aren't
+	// part of the history sent to the model nor of the memory.
+	// Optional: by default only the final events are persisted.
+	SavePartialEvents bool
+}
+
+// ErrorCodeLLMCallsLimit is the error code of the event ending an invocation
+// which exceeded the MaxLLMCalls of its [RunConfig].
+const ErrorCodeLLMCallsLimit = "LLM_CALLS_LIMIT_EXCEEDED"
+
+// LLMCallsLimitError is the error of an invocation which exceeded the
+// MaxLLMCalls of its [RunConfig]. Its message is the error message of the
+// event ending the invocation.
+type LLMCallsLimitError struct {
+	// Limit is the MaxLLMCalls of the invocation.
+	Limit int
+}
+
+func (e *LLMCallsLimitError) Error() string {
+	return fmt.Sprintf("the invocation exceeded its limit of %d LLM calls", e.Limit)
 }
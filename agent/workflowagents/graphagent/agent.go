@@ -0,0 +1,353 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package graphagent provides an agent that runs its sub-agents as the nodes
+// of a graph, following the edges whose conditions hold.
+package graphagent
+
+import (
+	"fmt"
+	"iter"
+	"maps"
+	"slices"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/workflowagents/loopagent"
+	agentinternal "google.golang.org/adk/internal/agent"
+	"google.golang.org/adk/internal/checkpoint"
+	icontext "google.golang.org/adk/internal/context"
+	"google.golang.org/adk/session"
+)
+
+// DefaultMaxSteps is the maximum number of node runs of an invocation of a
+// GraphAgent without MaxSteps.
+const DefaultMaxSteps = 25
+
+// New creates a GraphAgent.
+//
+// GraphAgent runs its sub-agents, the nodes of the graph, starting with the
+// Entry node. Once a node ran, the edges from it whose condition holds are
+// taken, and their target nodes run next, in the order of the sub-agents.
+//
+// A node reachable from several edges joins them: it only runs once no other
+// node to run can still reach it, so it runs once after all the branches
+// converging to it. The nodes of a cycle run again each time an edge to them
+// is taken, up to the MaxSteps budget of the invocation.
+//
+// The graph ends once the Terminal node ran, or once there is no node left
+// to run, or on the escalation of a node. Its final response is the one of
+// the last node that ran.
+//
+// The events of the nodes have the branch "<graph agent>.<node>", prefixed
+// with the branch of the GraphAgent, if any.
+//
+// With checkpointing, see [agent.RunConfig], the graph records the nodes to
+// run once each node ended, and a resumed graph runs them. The conditions of
+// the edges of a resumed graph don't see the temporary state deltas of the
+// nodes which ran before the interruption.
+func New(cfg Config) (agent.Agent, error) {
+	if cfg.AgentConfig.Run != nil {
+		return nil, fmt.Errorf("GraphAgent doesn't allow custom Run implementations")
+	}
+	g, err := newGraph(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid graph of agent %q: %w", cfg.AgentConfig.Name, err)
+	}
+
+	maxSteps := cfg.MaxSteps
+	if maxSteps == 0 {
+		maxSteps = DefaultMaxSteps
+	}
+	graphAgentImpl := &graphAgent{
+		graph:    g,
+		maxSteps: maxSteps,
+	}
+	cfg.AgentConfig.Run = graphAgentImpl.run
+
+	graphAgent, err := agent.New(cfg.AgentConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create base agent: %w", err)
+	}
+
+	internalAgent, ok := graphAgent.(agentinternal.Agent)
+	if !ok {
+		return nil, fmt.Errorf("internal error: failed to convert to internal agent")
+	}
+	state := agentinternal.Reveal(internalAgent)
+	state.AgentType = agentinternal.TypeGraphAgent
+	state.Config = cfg
+
+	return graphAgent, nil
+}
+
+// Config defines the configuration for a GraphAgent.
+type Config struct {
+	// Basic agent setup. The sub-agents are the nodes of the graph.
+	AgentConfig agent.Config
+
+	// Entry is the name of the node the graph starts with.
+	Entry string
+	// Edges are the edges between the nodes. Every node must be reachable
+	// from the Entry node.
+	Edges []Edge
+	// Terminal is the name of the node ending the graph once it ran.
+	// Optional: the graph ends once there is no node left to run.
+	Terminal string
+	// MaxSteps is the maximum number of node runs of an invocation. The
+	// invocation fails if the graph doesn't end within it.
+	// Optional: zero means DefaultMaxSteps.
+	MaxSteps uint
+}
+
+// Edge is an edge of a GraphAgent, from the node named From to the node
+// named To.
+type Edge struct {
+	From string
+	To   string
+	// Condition reports whether the edge is taken once the From node ran.
+	// Optional: the edges without a condition are always taken.
+	Condition Condition
+}
+
+// Condition reports whether an edge of a GraphAgent is taken. The state of
+// the context includes the state deltas of the nodes that ran in the
+// invocation, including the temporary ones.
+type Condition func(ctx agent.ReadonlyContext) (bool, error)
+
+// graph is the validated graph of a GraphAgent, whose nodes are the indexes
+// of the sub-agents.
+type graph struct {
+	entry    int
+	terminal int // -1 without a terminal node.
+	// edges are the edges from each node, in their declaration order.
+	edges [][]edge
+	// reaches reports whether a node reaches another one.
+	reaches [][]bool
+}
+
+type edge struct {
+	to        int
+	condition Condition
+}
+
+func newGraph(cfg Config) (*graph, error) {
+	subAgents := cfg.AgentConfig.SubAgents
+	index := make(map[string]int, len(subAgents))
+	for i, subAgent := range subAgents {
+		index[subAgent.Name()] = i
+	}
+	node := func(name, role string) (int, error) {
+		i, ok := index[name]
+		if !ok {
+			return 0, fmt.Errorf("%s %q is not a sub-agent", role, name)
+		}
+		return i, nil
+	}
+
+	if cfg.Entry == "" {
+		return nil, fmt.Errorf("entry node is missing")
+	}
+	entry, err := node(cfg.Entry, "entry node")
+	if err != nil {
+		return nil, err
+	}
+	terminal := -1
+	if cfg.Terminal != "" {
+		if terminal, err = node(cfg.Terminal, "terminal node"); err != nil {
+			return nil, err
+		}
+	}
+	edges := make([][]edge, len(subAgents))
+	for _, e := range cfg.Edges {
+		from, err := node(e.From, "source node of edge")
+		if err != nil {
+			return nil, err
+		}
+		to, err := node(e.To, "target node of edge")
+		if err != nil {
+			return nil, err
+		}
+		edges[from] = append(edges[from], edge{to: to, condition: e.Condition})
+	}
+
+	g := &graph{
+		entry:    entry,
+		terminal: terminal,
+		edges:    edges,
+		reaches:  make([][]bool, len(subAgents)),
+	}
+	for i := range subAgents {
+		g.reaches[i] = g.reachable(i)
+	}
+	fromEntry := slices.Clone(g.reaches[entry])
+	fromEntry[entry] = true
+	for i, subAgent := range subAgents {
+		if !fromEntry[i] {
+			return nil, fmt.Errorf("node %q is unreachable from entry node %q", subAgent.Name(), cfg.Entry)
+		}
+	}
+	return g, nil
+}
+
+// reachable returns the nodes reachable with edges from the node.
+func (g *graph) reachable(from int) []bool {
+	reached := make([]bool, len(g.edges))
+	queue := []int{from}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		for _, e := range g.edges[n] {
+			if reached[e.to] {
+				continue
+			}
+			reached[e.to] = true
+			queue = append(queue, e.to)
+		}
+	}
+	return reached
+}
+
+// next returns the next node to run among the pending ones: the first one
+// no other pending node reaches, or the first one if they all reach each
+// other in a cycle.
+func (g *graph) next(pending []bool) int {
+	first := -1
+	for n := range pending {
+		if !pending[n] {
+			continue
+		}
+		if first < 0 {
+			first = n
+		}
+		waits := false
+		for other := range pending {
+			if pending[other] && other != n && g.reaches[other][n] {
+				waits = true
+				break
+			}
+		}
+		if !waits {
+			return n
+		}
+	}
+	return first
+}
+
+type graphAgent struct {
+	graph    *graph
+	maxSteps uint
+}
+
+func (a *graphAgent) run(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+	return func(yield func(*session.Event, error) bool) {
+		subAgents := ctx.Agent().SubAgents()
+		pending := make([]bool, len(subAgents))
+		pending[a.graph.entry] = true
+		steps := uint(0)
+		if cp, ok := checkpoint.Take(ctx, ctx.Agent().Name()); ok {
+			pending[a.graph.entry] = false
+			for i, subAgent := range subAgents {
+				pending[i] = slices.Contains(cp.Pending, subAgent.Name())
+			}
+			steps = cp.Steps
+		}
+		// delta is the state delta of the nodes that ran.
+		delta := make(map[string]any)
+
+		for ; ; steps++ {
+			n := a.graph.next(pending)
+			if n < 0 {
+				return
+			}
+			if steps == a.maxSteps {
+				yield(nil, fmt.Errorf("agent %q exceeded its budget of %d steps before node %q", ctx.Agent().Name(), a.maxSteps, subAgents[n].Name()))
+				return
+			}
+			pending[n] = false
+
+			node := subAgents[n]
+			var escalation bool
+			for event, err := range node.Run(nodeContext(ctx, node)) {
+				if !yield(event, err) {
+					return
+				}
+				if err != nil {
+					return
+				}
+				if event == nil {
+					continue
+				}
+				if !event.Partial {
+					maps.Copy(delta, event.Actions.StateDelta)
+				}
+
+				if _, ok := event.CustomMetadata[loopagent.ExitMetadataKey]; ok {
+					// A nested loop ended with the escalation, which only
+					// goes on if it is to the root.
+					escalation = false
+				}
+				if event.Actions.Escalate {
+					escalation = true
+				}
+			}
+			if escalation || n == a.graph.terminal {
+				return
+			}
+
+			for _, e := range a.graph.edges[n] {
+				if e.condition != nil {
+					taken, err := e.condition(icontext.NewReadonlyContextWithDelta(ctx, delta))
+					if err != nil {
+						yield(nil, fmt.Errorf("failed to evaluate the condition of edge from %q to %q: %w", node.Name(), subAgents[e.to].Name(), err))
+						return
+					}
+					if !taken {
+						continue
+					}
+				}
+				pending[e.to] = true
+			}
+
+			if checkpoint.Enabled(ctx) {
+				cp := checkpoint.Checkpoint{Ended: node.Name(), Steps: steps + 1}
+				for i, subAgent := range subAgents {
+					if pending[i] {
+						cp.Pending = append(cp.Pending, subAgent.Name())
+					}
+				}
+				if !yield(checkpoint.Event(ctx, cp), nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// nodeContext returns the context of the node, on its own branch.
+func nodeContext(ctx agent.InvocationContext, node agent.Agent) agent.InvocationContext {
+	branch := fmt.Sprintf("%s.%s", ctx.Agent().Name(), node.Name())
+	if ctx.Branch() != "" {
+		branch = fmt.Sprintf("%s.%s", ctx.Branch(), branch)
+	}
+	return icontext.NewInvocationContext(ctx, icontext.InvocationContextParams{
+		Artifacts:    ctx.Artifacts(),
+		Memory:       ctx.Memory(),
+		Session:      ctx.Session(),
+		Branch:       branch,
+		Agent:        node,
+		UserContent:  ctx.UserContent(),
+		RunConfig:    ctx.RunConfig(),
+		InvocationID: ctx.InvocationID(),
+	})
+}
@@ -0,0 +1,268 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphagent_test
+
+import (
+	"iter"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/workflowagents/graphagent"
+	"google.golang.org/adk/internal/testutil"
+	"google.golang.org/adk/session"
+)
+
+func TestGraphAgent(t *testing.T) {
+	// run returns the "<branch>: <text>" of the events of the run, and the
+	// error of the run, if any.
+	run := func(t *testing.T, cfg graphagent.Config) ([]string, error) {
+		t.Helper()
+		cfg.AgentConfig.Name = "graph"
+		a, err := graphagent.New(cfg)
+		if err != nil {
+			t.Fatalf("New() failed: %v", err)
+		}
+		var got []string
+		for event, err := range testutil.NewTestAgentRunner(t, a).Run(t, "session_id", "user input") {
+			if err != nil {
+				return got, err
+			}
+			got = append(got, event.Branch+": "+event.Content.Parts[0].Text)
+		}
+		return got, nil
+	}
+
+	t.Run("ConditionalEdges", func(t *testing.T) {
+		for _, class := range []string{"billing", "support"} {
+			t.Run(class, func(t *testing.T) {
+				got, err := run(t, graphagent.Config{
+					AgentConfig: agent.Config{
+						SubAgents: []agent.Agent{
+							node(t, "classifier", session.EventActions{StateDelta: map[string]any{"temp:class": class}}),
+							node(t, "billing", session.EventActions{}),
+							node(t, "support", session.EventActions{}),
+							node(t, "summary", session.EventActions{}),
+						},
+					},
+					Entry: "classifier",
+					Edges: []graphagent.Edge{
+						{From: "classifier", To: "billing", Condition: stateEquals("temp:class", "billing")},
+						{From: "classifier", To: "support", Condition: stateEquals("temp:class", "support")},
+						{From: "billing", To: "summary"},
+						{From: "support", To: "summary"},
+					},
+				})
+				if err != nil {
+					t.Fatalf("Run() failed: %v", err)
+				}
+				want := []string{
+					"graph.classifier: classifier",
+					"graph." + class + ": " + class,
+					"graph.summary: summary",
+				}
+				if diff := cmp.Diff(want, got); diff != "" {
+					t.Errorf("events mismatch (-want +got):\n%s", diff)
+				}
+			})
+		}
+	})
+
+	t.Run("Join", func(t *testing.T) {
+		got, err := run(t, graphagent.Config{
+			AgentConfig: agent.Config{
+				SubAgents: []agent.Agent{
+					node(t, "start", session.EventActions{}),
+					node(t, "join", session.EventActions{}),
+					node(t, "short", session.EventActions{}),
+					node(t, "long_1", session.EventActions{}),
+					node(t, "long_2", session.EventActions{}),
+				},
+			},
+			Entry: "start",
+			Edges: []graphagent.Edge{
+				{From: "start", To: "short"},
+				{From: "start", To: "long_1"},
+				{From: "short", To: "join"},
+				{From: "long_1", To: "long_2"},
+				{From: "long_2", To: "join"},
+			},
+		})
+		if err != nil {
+			t.Fatalf("Run() failed: %v", err)
+		}
+		want := []string{
+			"graph.start: start",
+			"graph.short: short",
+			"graph.long_1: long_1",
+			"graph.long_2: long_2",
+			"graph.join: join",
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("events mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("Terminal", func(t *testing.T) {
+		got, err := run(t, graphagent.Config{
+			AgentConfig: agent.Config{
+				SubAgents: []agent.Agent{
+					node(t, "first", session.EventActions{}),
+					node(t, "answer", session.EventActions{}),
+					node(t, "log", session.EventActions{}),
+				},
+			},
+			Entry:    "first",
+			Terminal: "answer",
+			Edges: []graphagent.Edge{
+				{From: "first", To: "answer"},
+				{From: "answer", To: "log"},
+			},
+		})
+		if err != nil {
+			t.Fatalf("Run() failed: %v", err)
+		}
+		if diff := cmp.Diff([]string{"graph.first: first", "graph.answer: answer"}, got); diff != "" {
+			t.Errorf("events mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("Escalation", func(t *testing.T) {
+		got, err := run(t, graphagent.Config{
+			AgentConfig: agent.Config{
+				SubAgents: []agent.Agent{
+					node(t, "escalating", session.EventActions{Escalate: true}),
+					node(t, "next", session.EventActions{}),
+				},
+			},
+			Entry: "escalating",
+			Edges: []graphagent.Edge{{From: "escalating", To: "next"}},
+		})
+		if err != nil {
+			t.Fatalf("Run() failed: %v", err)
+		}
+		if diff := cmp.Diff([]string{"graph.escalating: escalating"}, got); diff != "" {
+			t.Errorf("events mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("MaxSteps", func(t *testing.T) {
+		got, err := run(t, graphagent.Config{
+			AgentConfig: agent.Config{
+				SubAgents: []agent.Agent{
+					node(t, "ping", session.EventActions{}),
+					node(t, "pong", session.EventActions{}),
+				},
+			},
+			Entry: "ping",
+			Edges: []graphagent.Edge{
+				{From: "ping", To: "pong"},
+				{From: "pong", To: "ping"},
+			},
+			MaxSteps: 3,
+		})
+		if err == nil || !strings.Contains(err.Error(), "exceeded its budget of 3 steps") {
+			t.Errorf("Run() error = %v, want the budget error", err)
+		}
+		if diff := cmp.Diff([]string{"graph.ping: ping", "graph.pong: pong", "graph.ping: ping"}, got); diff != "" {
+			t.Errorf("events mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func TestNew_Errors(t *testing.T) {
+	subAgents := func(t *testing.T) []agent.Agent {
+		return []agent.Agent{
+			node(t, "a", session.EventActions{}),
+			node(t, "b", session.EventActions{}),
+			node(t, "c", session.EventActions{}),
+		}
+	}
+	tests := []struct {
+		name    string
+		cfg     graphagent.Config
+		wantErr string
+	}{
+		{
+			name:    "missing entry",
+			cfg:     graphagent.Config{Edges: []graphagent.Edge{{From: "a", To: "b"}, {From: "b", To: "c"}}},
+			wantErr: "entry node is missing",
+		},
+		{
+			name:    "unknown entry",
+			cfg:     graphagent.Config{Entry: "z"},
+			wantErr: `entry node "z" is not a sub-agent`,
+		},
+		{
+			name:    "unknown terminal",
+			cfg:     graphagent.Config{Entry: "a", Terminal: "z", Edges: []graphagent.Edge{{From: "a", To: "b"}, {From: "b", To: "c"}}},
+			wantErr: `terminal node "z" is not a sub-agent`,
+		},
+		{
+			name:    "unknown edge target",
+			cfg:     graphagent.Config{Entry: "a", Edges: []graphagent.Edge{{From: "a", To: "z"}}},
+			wantErr: `target node of edge "z" is not a sub-agent`,
+		},
+		{
+			name:    "unreachable node",
+			cfg:     graphagent.Config{Entry: "a", Edges: []graphagent.Edge{{From: "a", To: "b"}, {From: "c", To: "b"}}},
+			wantErr: `node "c" is unreachable from entry node "a"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.AgentConfig = agent.Config{Name: "graph", SubAgents: subAgents(t)}
+			_, err := graphagent.New(tt.cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("New() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// node returns an agent emitting an event with its name and the actions.
+func node(t *testing.T, name string, actions session.EventActions) agent.Agent {
+	t.Helper()
+	a, err := agent.New(agent.Config{
+		Name: name,
+		Run: func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				event := session.NewEvent(ctx.InvocationID())
+				event.Author = name
+				event.Branch = ctx.Branch()
+				event.Content = genai.NewContentFromText(name, genai.RoleModel)
+				event.Actions = actions
+				yield(event, nil)
+			}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func stateEquals(key string, want any) graphagent.Condition {
+	return func(ctx agent.ReadonlyContext) (bool, error) {
+		got, err := ctx.ReadonlyState().Get(key)
+		if err != nil {
+			return false, nil
+		}
+		return got == want, nil
+	}
+}
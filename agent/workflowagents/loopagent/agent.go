@@ -22,6 +22,7 @@ import (
 
 	"google.golang.org/adk/agent"
 	agentinternal "google.golang.org/adk/internal/agent"
+	"google.golang.org/adk/internal/checkpoint"
 	"google.golang.org/adk/session"
 )
 
@@ -35,6 +36,22 @@ type Config struct {
 	MaxIterations uint
 }
 
+// ExitMetadataKey is the key of the CustomMetadata of the last event of a
+// LoopAgent run which ends before its invocation, authored by the LoopAgent.
+// Its value is a map with the "cause" of the exit, either ExitEscalated or
+// ExitMaxIterations, the number of "iterations" which ran and, when given to
+// the exit_loop tool, the "reason" of the escalation.
+const ExitMetadataKey = "adk_loop_exit"
+
+// Causes of the exit of a LoopAgent.
+const (
+	// ExitEscalated is the cause of the exits following the escalation of a
+	// sub-agent, e.g. with the exit_loop tool.
+	ExitEscalated = "escalated"
+	// ExitMaxIterations is the cause of the exits after MaxIterations.
+	ExitMaxIterations = "max_iterations"
+)
+
 // New creates a LoopAgent.
 //
 // LoopAgent repeatedly runs its sub-agents in sequence for a specified number
@@ -42,6 +59,14 @@ type Config struct {
 //
 // Use the LoopAgent when your workflow involves repetition or iterative
 // refinement, such as like revising code.
+//
+// The escalations of the sub-agents end the innermost LoopAgent running them,
+// unless they escalate to the root (see [session.EventActions]), in which case
+// they end all the enclosing LoopAgents.
+//
+// With checkpointing, see [agent.RunConfig], the loop records its iteration
+// and the end of each sub-agent, and a resumed loop starts with the
+// sub-agent which didn't end.
 func New(cfg Config) (agent.Agent, error) {
 	if cfg.AgentConfig.Run != nil {
 		return nil, fmt.Errorf("LoopAgent doesn't allow custom Run implementations")
@@ -73,33 +98,90 @@ type loopAgent struct {
 }
 
 func (a *loopAgent) Run(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
-	count := a.maxIterations
-
 	return func(yield func(*session.Event, error) bool) {
-		for {
-			shouldExit := false
-			for _, subAgent := range ctx.Agent().SubAgents() {
-				for event, err := range subAgent.Run(ctx) {
+		subAgents := ctx.Agent().SubAgents()
+		iteration, step := 1, 0
+		if cp, ok := checkpoint.Take(ctx, ctx.Agent().Name()); ok {
+			iteration, step = cp.Iteration, cp.Step
+		}
+		for ; ; iteration, step = iteration+1, 0 {
+			if a.maxIterations > 0 && uint(iteration) > a.maxIterations {
+				yield(exitEvent(ctx, iteration-1, ExitMaxIterations, nil), nil)
+				return
+			}
+
+			var escalation *session.Event
+			for ; step < len(subAgents); step++ {
+				for event, err := range subAgents[step].Run(ctx) {
 					// TODO: ensure consistency -- if there's an error, return and close iterator, verify everywhere in ADK.
 					if !yield(event, err) {
 						return
 					}
+					if event == nil {
+						continue
+					}
 
-					if event != nil && event.Actions.Escalate {
-						shouldExit = true
+					if _, ok := event.CustomMetadata[ExitMetadataKey]; ok {
+						// A nested loop ended with the escalation, which only
+						// goes on if it is to the root.
+						escalation = nil
+					}
+					if event.Actions.Escalate {
+						escalation = event
 					}
 				}
-				if shouldExit {
+				if escalation != nil {
+					yield(exitEvent(ctx, iteration, ExitEscalated, escalation), nil)
 					return
 				}
+				if checkpoint.Enabled(ctx) {
+					cp := checkpoint.Checkpoint{Ended: subAgents[step].Name(), Iteration: iteration, Step: step + 1}
+					if step == len(subAgents)-1 {
+						cp.Iteration, cp.Step = iteration+1, 0
+					}
+					if !yield(checkpoint.Event(ctx, cp), nil) {
+						return
+					}
+				}
 			}
+		}
+	}
+}
 
-			if count > 0 {
-				count--
-				if count == 0 {
-					return
-				}
+// exitEvent returns the event ending the loop after the given iterations, in
+// which the escalation of the loop, if any, goes on if it is to the root.
+func exitEvent(ctx agent.InvocationContext, iterations int, cause string, escalation *session.Event) *session.Event {
+	exit := map[string]any{"cause": cause, "iterations": iterations}
+	event := session.NewEvent(ctx.InvocationID())
+	event.Author = ctx.Agent().Name()
+	event.Branch = ctx.Branch()
+	if escalation != nil {
+		if reason := escalationReason(escalation); reason != "" {
+			exit["reason"] = reason
+		}
+		event.Actions.Escalate = escalation.Actions.EscalateToRoot
+		event.Actions.EscalateToRoot = escalation.Actions.EscalateToRoot
+	}
+	event.CustomMetadata = map[string]any{ExitMetadataKey: exit}
+	return event
+}
+
+// escalationReason returns the reason of the escalation: the reason of the
+// exit of a nested loop, or the reason given to the exit_loop tool.
+func escalationReason(event *session.Event) string {
+	if exit, ok := event.CustomMetadata[ExitMetadataKey].(map[string]any); ok {
+		reason, _ := exit["reason"].(string)
+		return reason
+	}
+	if event.Content == nil {
+		return ""
+	}
+	for _, part := range event.Content.Parts {
+		if part.FunctionResponse != nil {
+			if reason, ok := part.FunctionResponse.Response["reason"].(string); ok {
+				return reason
 			}
 		}
 	}
+	return ""
 }
@@ -84,6 +84,7 @@ func TestNewLoopAgent(t *testing.T) {
 						},
 					},
 				},
+				{Author: "test_agent"},
 			},
 		},
 		{
@@ -115,6 +116,7 @@ func TestNewLoopAgent(t *testing.T) {
 						},
 					},
 				},
+				{Author: "test_agent"},
 			},
 		},
 		{
@@ -150,6 +152,7 @@ func TestNewLoopAgent(t *testing.T) {
 						},
 					},
 				},
+				{Author: "test_agent"},
 			},
 		},
 		{
@@ -175,6 +178,7 @@ func TestNewLoopAgent(t *testing.T) {
 						SkipSummarization: true,
 					},
 				},
+				{Author: "test_agent"},
 			},
 		},
 	}
@@ -237,6 +241,8 @@ func TestNewLoopAgent(t *testing.T) {
 				cmpopts.IgnoreFields(session.EventActions{}, "StateDelta"),
 				cmpopts.IgnoreFields(genai.FunctionCall{}, "ID"),
 				cmpopts.IgnoreFields(genai.FunctionResponse{}, "ID"),
+				// The durations of the function calls vary.
+				cmpopts.IgnoreFields(model.LLMResponse{}, "CustomMetadata"),
 			}
 
 			for i, gotEvent := range gotEvents {
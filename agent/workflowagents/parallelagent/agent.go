@@ -16,13 +16,17 @@
 package parallelagent
 
 import (
+	"context"
 	"fmt"
 	"iter"
+	"slices"
+	"strings"
 
 	"golang.org/x/sync/errgroup"
 
 	"google.golang.org/adk/agent"
 	agentinternal "google.golang.org/adk/internal/agent"
+	"google.golang.org/adk/internal/checkpoint"
 	icontext "google.golang.org/adk/internal/context"
 	"google.golang.org/adk/session"
 )
@@ -31,6 +35,11 @@ import (
 type Config struct {
 	// Basic agent setup.
 	AgentConfig agent.Config
+
+	// Aggregator aggregates the results of the sub-agents in a final event
+	// of the ParallelAgent, e.g. [Concatenate] or [FirstResult].
+	// Optional: by default, the results aren't aggregated.
+	Aggregator Aggregator
 }
 
 // New creates a ParallelAgent.
@@ -41,12 +50,20 @@ type Config struct {
 // attempts on a single task, such as:
 // - Running different algorithms simultaneously.
 // - Generating multiple responses for review by a subsequent evaluation agent.
+//
+// With checkpointing, see [agent.RunConfig], the ParallelAgent records the
+// sub-agents which ended, and a resumed ParallelAgent only runs the others.
+// The results of the ended sub-agents are restored from the session for its
+// Aggregator.
 func New(cfg Config) (agent.Agent, error) {
 	if cfg.AgentConfig.Run != nil {
 		return nil, fmt.Errorf("ParallelAgent doesn't allow custom Run implementations")
 	}
 
-	cfg.AgentConfig.Run = run
+	parallelAgentImpl := &parallelAgent{
+		aggregator: cfg.Aggregator,
+	}
+	cfg.AgentConfig.Run = parallelAgentImpl.run
 
 	parallelAgent, err := agent.New(cfg.AgentConfig)
 	if err != nil {
@@ -64,23 +81,60 @@ func New(cfg Config) (agent.Agent, error) {
 	return parallelAgent, nil
 }
 
-func run(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+type parallelAgent struct {
+	aggregator Aggregator
+}
+
+func (a *parallelAgent) run(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
 	curAgent := ctx.Agent()
+	subAgents := curAgent.SubAgents()
 
 	var (
-		errGroup, errGroupCtx = errgroup.WithContext(ctx)
-		doneChan              = make(chan bool)
-		resultsChan           = make(chan result)
+		// ended are the sub-agents which ended before the interruption of
+		// the resumed invocation, whose results are restored.
+		ended   = make([]bool, len(subAgents))
+		done    []string
+		finals  = make([]*session.Event, len(subAgents))
+		outputs = make([]map[string]any, len(subAgents))
+		winner  = -1
+	)
+	if cp, ok := checkpoint.Take(ctx, curAgent.Name()); ok {
+		for i, subAgent := range subAgents {
+			if !slices.Contains(cp.Done, subAgent.Name()) {
+				continue
+			}
+			ended[i] = true
+			done = append(done, subAgent.Name())
+			finals[i], outputs[i] = endedResult(ctx, branchOf(ctx, subAgent), subAgent)
+			if a.aggregator.firstWins && winner < 0 && finals[i] != nil {
+				winner = i
+			}
+		}
+	}
+
+	var (
+		errGroup                    = &errgroup.Group{}
+		errGroupCtx context.Context = ctx
+		doneChan                    = make(chan bool)
+		resultsChan                 = make(chan result)
+		cancels                     = make([]context.CancelFunc, len(subAgents))
 	)
+	if !a.aggregator.firstWins {
+		// The error of a sub-agent cancels the others.
+		errGroup, errGroupCtx = errgroup.WithContext(ctx)
+	}
 
-	for _, sa := range ctx.Agent().SubAgents() {
-		branch := fmt.Sprintf("%s.%s", curAgent.Name(), sa.Name())
-		if ctx.Branch() != "" {
-			branch = fmt.Sprintf("%s.%s", ctx.Branch(), branch)
+	for i, subAgent := range subAgents {
+		if ended[i] || winner >= 0 {
+			cancels[i] = func() {}
+			continue
 		}
-		subAgent := sa
+		branch := branchOf(ctx, subAgent)
+		branchCtx, cancel := context.WithCancel(errGroupCtx)
+		cancels[i] = cancel
 		errGroup.Go(func() error {
-			subCtx := icontext.NewInvocationContext(errGroupCtx, icontext.InvocationContextParams{
+			defer cancel()
+			subCtx := icontext.NewInvocationContext(branchCtx, icontext.InvocationContextParams{
 				Artifacts:    ctx.Artifacts(),
 				Memory:       ctx.Memory(),
 				Session:      ctx.Session(),
@@ -91,7 +145,7 @@ func run(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
 				InvocationID: ctx.InvocationID(),
 			})
 
-			if err := runSubAgent(subCtx, subAgent, resultsChan, doneChan); err != nil {
+			if err := runSubAgent(subCtx, i, subAgent, resultsChan, doneChan); err != nil {
 				return fmt.Errorf("failed to run sub-agent %q: %w", subAgent.Name(), err)
 			}
 
@@ -106,16 +160,122 @@ func run(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
 
 	return func(yield func(*session.Event, error) bool) {
 		defer close(doneChan)
+		defer func() {
+			for _, cancel := range cancels {
+				cancel()
+			}
+		}()
 
+		var (
+			// failed reports whether an error was yielded, firstErr is the
+			// first error withheld until a sub-agent wins with FirstResult.
+			failed   bool
+			firstErr error
+		)
 		for res := range resultsChan {
+			if winner >= 0 && res.branch != winner {
+				// The other sub-agents are canceled.
+				continue
+			}
+			if res.done {
+				done = append(done, subAgents[res.branch].Name())
+				if checkpoint.Enabled(ctx) {
+					cp := checkpoint.Checkpoint{Ended: subAgents[res.branch].Name(), Done: slices.Clone(done)}
+					if !yield(checkpoint.Event(ctx, cp), nil) {
+						return
+					}
+				}
+				if a.aggregator.firstWins && winner < 0 && finals[res.branch] != nil {
+					winner = res.branch
+					for i, cancel := range cancels {
+						if i != winner {
+							cancel()
+						}
+					}
+				}
+				continue
+			}
+			if res.err != nil {
+				if a.aggregator.firstWins {
+					if firstErr == nil {
+						firstErr = res.err
+					}
+					continue
+				}
+				failed = true
+			} else if res.event != nil {
+				if isFinal(res.event) {
+					finals[res.branch] = res.event
+				}
+				if key := outputKey(subAgents[res.branch]); key != "" {
+					if value, ok := res.event.Actions.StateDelta[key]; ok {
+						outputs[res.branch] = map[string]any{key: value}
+					}
+				}
+			}
 			if !yield(res.event, res.err) {
-				break
+				return
+			}
+		}
+
+		if a.aggregator.aggregate == nil || failed {
+			return
+		}
+		if a.aggregator.firstWins {
+			if winner < 0 {
+				if firstErr != nil {
+					yield(nil, firstErr)
+				}
+				return
 			}
+			for i := range finals {
+				if i != winner {
+					finals[i], outputs[i] = nil, nil
+				}
+			}
+		}
+		ev, err := a.aggregator.aggregate(ctx, finals, outputs)
+		if err != nil {
+			yield(nil, fmt.Errorf("failed to aggregate the results of agent %q: %w", curAgent.Name(), err))
+			return
+		}
+		if ev != nil {
+			yield(ev, nil)
+		}
+	}
+}
+
+// branchOf returns the branch of the sub-agent.
+func branchOf(ctx agent.InvocationContext, subAgent agent.Agent) string {
+	branch := fmt.Sprintf("%s.%s", ctx.Agent().Name(), subAgent.Name())
+	if ctx.Branch() != "" {
+		branch = fmt.Sprintf("%s.%s", ctx.Branch(), branch)
+	}
+	return branch
+}
+
+// endedResult returns the final event and the output of the sub-agent of
+// the branch which ended before the interruption of the resumed invocation,
+// from the events of the session.
+func endedResult(ctx agent.InvocationContext, branch string, subAgent agent.Agent) (final *session.Event, output map[string]any) {
+	key := outputKey(subAgent)
+	for event := range ctx.Session().Events().All() {
+		if event.InvocationID != ctx.InvocationID() || (event.Branch != branch && !strings.HasPrefix(event.Branch, branch+".")) {
+			continue
+		}
+		if isFinal(event) {
+			final = event
+		}
+		if value, ok := event.Actions.StateDelta[key]; ok && key != "" {
+			output = map[string]any{key: value}
 		}
 	}
+	return final, output
 }
 
-func runSubAgent(ctx agent.InvocationContext, agent agent.Agent, results chan<- result, done <-chan bool) error {
+// runSubAgent runs the sub-agent of the given branch, and sends a done
+// result once it ends without error.
+func runSubAgent(ctx agent.InvocationContext, branch int, agent agent.Agent, results chan<- result, done <-chan bool) error {
 	for event, err := range agent.Run(ctx) {
 		select {
 		case <-done:
@@ -124,23 +284,33 @@ func runSubAgent(ctx agent.InvocationContext, agent agent.Agent, results chan<-
 			select {
 			case <-done:
 			case results <- result{
-				err: ctx.Err(),
+				branch: branch,
+				err:    ctx.Err(),
 			}:
 			}
 			return ctx.Err()
 		case results <- result{
-			event: event,
-			err:   err,
+			branch: branch,
+			event:  event,
+			err:    err,
 		}:
 			if err != nil {
 				return err
 			}
 		}
 	}
+	select {
+	case <-done:
+	case results <- result{branch: branch, done: true}:
+	}
 	return nil
 }
 
 type result struct {
-	event *session.Event
-	err   error
+	// branch is the index of the sub-agent.
+	branch int
+	event  *session.Event
+	err    error
+	// done reports that the sub-agent ended without error.
+	done bool
 }
@@ -27,8 +27,10 @@ import (
 	"google.golang.org/genai"
 
 	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
 	"google.golang.org/adk/agent/workflowagents/loopagent"
 	"google.golang.org/adk/agent/workflowagents/parallelagent"
+	"google.golang.org/adk/internal/testutil"
 	"google.golang.org/adk/model"
 	"google.golang.org/adk/runner"
 	"google.golang.org/adk/session"
@@ -135,6 +137,11 @@ func TestNewParallelAgent(t *testing.T) {
 					}
 					t.Errorf("got unexpected error: %v", err)
 				}
+				if isLoopExit(event) {
+					// The exits of the loop agents running the sub-agents aren't
+					// under test.
+					continue
+				}
 
 				gotEvents = append(gotEvents, event)
 			}
@@ -224,3 +231,130 @@ func customRun(id int, agentErr error) func(agent.InvocationContext) iter.Seq2[*
 		}
 	}
 }
+
+func isLoopExit(event *session.Event) bool {
+	if event == nil {
+		return false
+	}
+	_, ok := event.CustomMetadata[loopagent.ExitMetadataKey]
+	return ok
+}
+
+func TestAggregator(t *testing.T) {
+	textAgent := func(name, text string) agent.Agent {
+		return must(agent.New(agent.Config{
+			Name: name,
+			Run: func(agent.InvocationContext) iter.Seq2[*session.Event, error] {
+				return func(yield func(*session.Event, error) bool) {
+					yield(&session.Event{
+						LLMResponse: model.LLMResponse{
+							Content: genai.NewContentFromText(text, genai.RoleModel),
+						},
+					}, nil)
+				}
+			},
+		}))
+	}
+	// run returns the last event of the run of the ParallelAgent.
+	run := func(t *testing.T, aggregator parallelagent.Aggregator, subAgents ...agent.Agent) *session.Event {
+		t.Helper()
+		a := must(parallelagent.New(parallelagent.Config{
+			AgentConfig: agent.Config{
+				Name:      "parallel",
+				SubAgents: subAgents,
+			},
+			Aggregator: aggregator,
+		}))
+		var last *session.Event
+		for ev, err := range testutil.NewTestAgentRunner(t, a).Run(t, "session_id", "user input") {
+			if err != nil {
+				t.Fatalf("Run() failed: %v", err)
+			}
+			last = ev
+		}
+		if last == nil || last.Author != "parallel" {
+			t.Fatalf("last event = %v, want the aggregate event of the ParallelAgent", last)
+		}
+		return last
+	}
+
+	t.Run("Concatenate", func(t *testing.T) {
+		got := run(t, parallelagent.Concatenate(), textAgent("sub1", "one"), textAgent("sub2", "two"), textAgent("sub3", "three"))
+		want := genai.NewContentFromParts([]*genai.Part{
+			genai.NewPartFromText("one"),
+			genai.NewPartFromText("two"),
+			genai.NewPartFromText("three"),
+		}, genai.RoleModel)
+		if diff := cmp.Diff(want, got.Content); diff != "" {
+			t.Errorf("aggregate content mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("FirstResult", func(t *testing.T) {
+		canceled := make(chan struct{}, 2)
+		slowAgent := func(name string) agent.Agent {
+			return must(llmagent.New(llmagent.Config{
+				Name:  name,
+				Model: &blockingModel{canceled: canceled},
+			}))
+		}
+		got := run(t, parallelagent.FirstResult(), slowAgent("slow1"), textAgent("fast", "fast answer"), slowAgent("slow2"))
+		if diff := cmp.Diff(genai.NewContentFromText("fast answer", genai.RoleModel), got.Content); diff != "" {
+			t.Errorf("aggregate content mismatch (-want +got):\n%s", diff)
+		}
+		for i := range 2 {
+			select {
+			case <-canceled:
+			case <-time.After(time.Second):
+				t.Fatalf("%d of the 2 model calls canceled", i)
+			}
+		}
+	})
+
+	t.Run("MergeOutputs", func(t *testing.T) {
+		outputAgent := func(name, outputKey, text string) agent.Agent {
+			return must(llmagent.New(llmagent.Config{
+				Name:      name,
+				Model:     &testutil.MockModel{Responses: []*genai.Content{genai.NewContentFromText(text, genai.RoleModel)}},
+				OutputKey: outputKey,
+			}))
+		}
+		got := run(t, parallelagent.MergeOutputs("results"), outputAgent("sub1", "out1", "one"), outputAgent("sub2", "out2", "two"))
+		want := map[string]any{"results": map[string]any{"out1": "one", "out2": "two"}}
+		if diff := cmp.Diff(want, got.Actions.StateDelta); diff != "" {
+			t.Errorf("aggregate state delta mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("Custom", func(t *testing.T) {
+		var gotAuthors []string
+		aggregator := parallelagent.Custom(func(ctx agent.InvocationContext, finals []*session.Event) (*genai.Content, error) {
+			for _, final := range finals {
+				gotAuthors = append(gotAuthors, final.Author)
+			}
+			return genai.NewContentFromText(fmt.Sprintf("%d results", len(finals)), genai.RoleModel), nil
+		})
+		got := run(t, aggregator, textAgent("sub1", "one"), textAgent("sub2", "two"))
+		if diff := cmp.Diff(genai.NewContentFromText("2 results", genai.RoleModel), got.Content); diff != "" {
+			t.Errorf("aggregate content mismatch (-want +got):\n%s", diff)
+		}
+		if diff := cmp.Diff([]string{"sub1", "sub2"}, gotAuthors); diff != "" {
+			t.Errorf("final event authors mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
+
+// blockingModel blocks its calls until they are canceled.
+type blockingModel struct {
+	canceled chan<- struct{}
+}
+
+func (m *blockingModel) Name() string { return "blocking" }
+
+func (m *blockingModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		<-ctx.Done()
+		m.canceled <- struct{}{}
+		yield(nil, ctx.Err())
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parallelagent
+
+import (
+	"strings"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/internal/llminternal"
+	"google.golang.org/adk/session"
+)
+
+// Aggregator aggregates the results of the sub-agents of a ParallelAgent in
+// a final event authored by the ParallelAgent, emitted once all of them
+// ended. The zero value doesn't aggregate them.
+//
+// The result of a sub-agent, its branch, is its final event: the last
+// non-partial final response with content of the branch.
+type Aggregator struct {
+	// firstWins cancels the other branches once a branch ends with a final
+	// event, and ignores their events from then on.
+	firstWins bool
+	aggregate func(ctx agent.InvocationContext, finals []*session.Event, outputs []map[string]any) (*session.Event, error)
+}
+
+// AggregateFunc returns the content of the aggregate event given the final
+// events of the sub-agents, in their declaration order, nil for the
+// sub-agents without a final event.
+type AggregateFunc func(ctx agent.InvocationContext, finals []*session.Event) (*genai.Content, error)
+
+// Concatenate returns the aggregator concatenating the text of the final
+// events of the sub-agents, in their declaration order, with one text part
+// per sub-agent.
+func Concatenate() Aggregator {
+	return Custom(func(ctx agent.InvocationContext, finals []*session.Event) (*genai.Content, error) {
+		var parts []*genai.Part
+		for _, final := range finals {
+			if text := finalText(final); text != "" {
+				parts = append(parts, genai.NewPartFromText(text))
+			}
+		}
+		if len(parts) == 0 {
+			return nil, nil
+		}
+		return genai.NewContentFromParts(parts, genai.RoleModel), nil
+	})
+}
+
+// FirstResult returns the aggregator keeping the result of the first
+// sub-agent ending with a final event: it cancels the other sub-agents,
+// including their model calls in flight, and the content of the aggregate
+// event is the content of the final event of the first sub-agent.
+//
+// The errors of the sub-agents don't cancel the others, and are only
+// reported if none of them ends with a final event.
+func FirstResult() Aggregator {
+	return Aggregator{
+		firstWins: true,
+		aggregate: func(ctx agent.InvocationContext, finals []*session.Event, _ []map[string]any) (*session.Event, error) {
+			for _, final := range finals {
+				if final != nil {
+					return aggregateEvent(ctx, final.Content), nil
+				}
+			}
+			return nil, nil
+		},
+	}
+}
+
+// MergeOutputs returns the aggregator merging the outputs of the LLM
+// sub-agents, saved in the session state under their OutputKey, into a map
+// from their OutputKey to their output saved in the session state under
+// stateKey by the aggregate event.
+func MergeOutputs(stateKey string) Aggregator {
+	return Aggregator{
+		aggregate: func(ctx agent.InvocationContext, _ []*session.Event, outputs []map[string]any) (*session.Event, error) {
+			merged := make(map[string]any)
+			for _, output := range outputs {
+				for key, value := range output {
+					merged[key] = value
+				}
+			}
+			ev := aggregateEvent(ctx, nil)
+			ev.Actions.StateDelta[stateKey] = merged
+			return ev, nil
+		},
+	}
+}
+
+// Custom returns the aggregator whose aggregate event has the content
+// returned by fn. There is no aggregate event if fn returns nil content.
+func Custom(fn AggregateFunc) Aggregator {
+	return Aggregator{
+		aggregate: func(ctx agent.InvocationContext, finals []*session.Event, _ []map[string]any) (*session.Event, error) {
+			content, err := fn(ctx, finals)
+			if err != nil || content == nil {
+				return nil, err
+			}
+			return aggregateEvent(ctx, content), nil
+		},
+	}
+}
+
+// aggregateEvent returns the aggregate event authored by the ParallelAgent
+// of the context.
+func aggregateEvent(ctx agent.InvocationContext, content *genai.Content) *session.Event {
+	ev := session.NewEvent(ctx.InvocationID())
+	ev.Author = ctx.Agent().Name()
+	ev.Branch = ctx.Branch()
+	ev.Content = content
+	return ev
+}
+
+// isFinal reports whether the event may be the final event of its branch.
+func isFinal(ev *session.Event) bool {
+	return ev != nil && !ev.Partial && ev.Content != nil && ev.IsFinalResponse()
+}
+
+// finalText returns the text of the final event, without thoughts.
+func finalText(final *session.Event) string {
+	if final == nil || final.Content == nil {
+		return ""
+	}
+	var sb strings.Builder
+	for _, part := range final.Content.Parts {
+		if part.Text != "" && !part.Thought {
+			sb.WriteString(part.Text)
+		}
+	}
+	return sb.String()
+}
+
+// outputKey returns the OutputKey of the sub-agent, if it is an LLM agent.
+func outputKey(subAgent agent.Agent) string {
+	llmAgent, ok := subAgent.(llminternal.Agent)
+	if !ok {
+		return ""
+	}
+	return llminternal.Reveal(llmAgent).OutputKey
+}
@@ -17,25 +17,55 @@ package sequentialagent
 
 import (
 	"fmt"
+	"iter"
+	"maps"
 
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/workflowagents/loopagent"
 	agentinternal "google.golang.org/adk/internal/agent"
+	"google.golang.org/adk/internal/checkpoint"
+	icontext "google.golang.org/adk/internal/context"
+	"google.golang.org/adk/session"
 )
 
+// SkipMetadataKey is the key of the CustomMetadata of the events of a
+// SequentialAgent recording the steps it skipped, authored by the
+// SequentialAgent. Its value is a map with the name of the skipped "agent".
+const SkipMetadataKey = "adk_sequence_skip"
+
 // New creates a SequentialAgent.
 //
 // SequentialAgent executes its sub-agents once, in the order they are listed.
 //
 // Use the SequentialAgent when you want the execution to occur in a fixed,
 // strict order.
+//
+// The sub-agents with a condition in Conditions only run if it holds. The
+// escalations of the sub-agents end the sequence: the later sub-agents
+// don't run.
+//
+// With checkpointing, see [agent.RunConfig], the sequence records the end of
+// each step, and a resumed sequence starts with the step which didn't end.
+// Its conditions don't see the temporary state deltas of the steps which
+// ended before the interruption.
 func New(cfg Config) (agent.Agent, error) {
-	sequentialAgent, err := loopagent.New(loopagent.Config{
-		AgentConfig:   cfg.AgentConfig,
-		MaxIterations: 1,
-	})
+	if cfg.AgentConfig.Run != nil {
+		return nil, fmt.Errorf("SequentialAgent doesn't allow custom Run implementations")
+	}
+	for name := range cfg.Conditions {
+		if !hasSubAgent(cfg.AgentConfig.SubAgents, name) {
+			return nil, fmt.Errorf("condition of unknown sub-agent %q of agent %q", name, cfg.AgentConfig.Name)
+		}
+	}
+
+	sequentialAgentImpl := &sequentialAgent{
+		conditions: cfg.Conditions,
+	}
+	cfg.AgentConfig.Run = sequentialAgentImpl.run
+
+	sequentialAgent, err := agent.New(cfg.AgentConfig)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to create base agent: %w", err)
 	}
 
 	internalAgent, ok := sequentialAgent.(agentinternal.Agent)
@@ -53,4 +83,101 @@ func New(cfg Config) (agent.Agent, error) {
 type Config struct {
 	// Basic agent setup.
 	AgentConfig agent.Config
+
+	// Conditions are the conditions of the steps of the sequence, by the
+	// name of their sub-agent. The sub-agents whose condition doesn't hold
+	// are skipped.
+	// Optional: the sub-agents without a condition always run.
+	Conditions map[string]Condition
+}
+
+// Condition reports whether a step of a SequentialAgent runs. The state of
+// the context includes the state deltas of the earlier steps of the
+// invocation, including the temporary ones.
+type Condition func(ctx agent.ReadonlyContext) (bool, error)
+
+type sequentialAgent struct {
+	conditions map[string]Condition
+}
+
+func (a *sequentialAgent) run(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+	return func(yield func(*session.Event, error) bool) {
+		// delta is the state delta of the earlier steps.
+		delta := make(map[string]any)
+		subAgents := ctx.Agent().SubAgents()
+		start := 0
+		if cp, ok := checkpoint.Take(ctx, ctx.Agent().Name()); ok {
+			start = cp.Step
+		}
+		for i := start; i < len(subAgents); i++ {
+			subAgent := subAgents[i]
+			if condition, ok := a.conditions[subAgent.Name()]; ok {
+				run, err := condition(icontext.NewReadonlyContextWithDelta(ctx, delta))
+				if err != nil {
+					yield(nil, fmt.Errorf("failed to evaluate the condition of sub-agent %q: %w", subAgent.Name(), err))
+					return
+				}
+				if !run {
+					if !yield(skipEvent(ctx, subAgent), nil) || !yieldCheckpoint(ctx, i, yield) {
+						return
+					}
+					continue
+				}
+			}
+
+			var escalation bool
+			for event, err := range subAgent.Run(ctx) {
+				if !yield(event, err) {
+					return
+				}
+				if event == nil {
+					continue
+				}
+				if !event.Partial {
+					maps.Copy(delta, event.Actions.StateDelta)
+				}
+
+				if _, ok := event.CustomMetadata[loopagent.ExitMetadataKey]; ok {
+					// A nested loop ended with the escalation, which only
+					// goes on if it is to the root.
+					escalation = false
+				}
+				if event.Actions.Escalate {
+					escalation = true
+				}
+			}
+			if escalation || !yieldCheckpoint(ctx, i, yield) {
+				return
+			}
+		}
+	}
+}
+
+// yieldCheckpoint yields the checkpoint of the sequence once its step ended,
+// if the invocation records checkpoints.
+func yieldCheckpoint(ctx agent.InvocationContext, step int, yield func(*session.Event, error) bool) bool {
+	if !checkpoint.Enabled(ctx) {
+		return true
+	}
+	subAgent := ctx.Agent().SubAgents()[step]
+	return yield(checkpoint.Event(ctx, checkpoint.Checkpoint{Ended: subAgent.Name(), Step: step + 1}), nil)
+}
+
+// skipEvent returns the event recording that the step of the sub-agent was
+// skipped.
+func skipEvent(ctx agent.InvocationContext, subAgent agent.Agent) *session.Event {
+	event := session.NewEvent(ctx.InvocationID())
+	event.Author = ctx.Agent().Name()
+	event.Branch = ctx.Branch()
+	event.CustomMetadata = map[string]any{SkipMetadataKey: map[string]any{"agent": subAgent.Name()}}
+	return event
+}
+
+func hasSubAgent(subAgents []agent.Agent, name string) bool {
+	for _, subAgent := range subAgents {
+		if subAgent.Name() == name {
+			return true
+		}
+	}
+	return false
 }
@@ -27,6 +27,7 @@ import (
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/llmagent"
 	"google.golang.org/adk/agent/workflowagents/sequentialagent"
+	"google.golang.org/adk/internal/testutil"
 	"google.golang.org/adk/model"
 	"google.golang.org/adk/runner"
 	"google.golang.org/adk/session"
@@ -312,3 +313,109 @@ func (f *FakeLLM) GenerateContent(ctx context.Context, req *model.LLMRequest, st
 		}, nil)
 	}
 }
+
+func TestConditions(t *testing.T) {
+	// step returns an agent emitting an event with the given actions.
+	step := func(name string, actions session.EventActions) agent.Agent {
+		return must(agent.New(agent.Config{
+			Name: name,
+			Run: func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+				return func(yield func(*session.Event, error) bool) {
+					event := session.NewEvent(ctx.InvocationID())
+					event.Content = genai.NewContentFromText(name, genai.RoleModel)
+					event.Actions = actions
+					yield(event, nil)
+				}
+			},
+		}))
+	}
+	stateEquals := func(key string, want any) sequentialagent.Condition {
+		return func(ctx agent.ReadonlyContext) (bool, error) {
+			got, err := ctx.ReadonlyState().Get(key)
+			if err != nil {
+				return false, nil
+			}
+			return got == want, nil
+		}
+	}
+	// run returns the texts of the events of the run, and the skipped agents.
+	run := func(t *testing.T, cfg sequentialagent.Config) (texts, skipped []string) {
+		t.Helper()
+		cfg.AgentConfig.Name = "sequence"
+		a, err := sequentialagent.New(cfg)
+		if err != nil {
+			t.Fatalf("New() failed: %v", err)
+		}
+		for event, err := range testutil.NewTestAgentRunner(t, a).Run(t, "session_id", "user input") {
+			if err != nil {
+				t.Fatalf("Run() failed: %v", err)
+			}
+			if skip, ok := event.CustomMetadata[sequentialagent.SkipMetadataKey].(map[string]any); ok {
+				skipped = append(skipped, skip["agent"].(string))
+				continue
+			}
+			texts = append(texts, event.Content.Parts[0].Text)
+		}
+		return texts, skipped
+	}
+
+	t.Run("SkipsSteps", func(t *testing.T) {
+		texts, skipped := run(t, sequentialagent.Config{
+			AgentConfig: agent.Config{
+				SubAgents: []agent.Agent{
+					step("classifier", session.EventActions{StateDelta: map[string]any{"temp:class": "billing"}}),
+					step("billing", session.EventActions{}),
+					step("support", session.EventActions{}),
+					step("summary", session.EventActions{}),
+				},
+			},
+			Conditions: map[string]sequentialagent.Condition{
+				"billing": stateEquals("temp:class", "billing"),
+				"support": stateEquals("temp:class", "support"),
+			},
+		})
+		if diff := cmp.Diff([]string{"classifier", "billing", "summary"}, texts); diff != "" {
+			t.Errorf("texts mismatch (-want +got):\n%s", diff)
+		}
+		if diff := cmp.Diff([]string{"support"}, skipped); diff != "" {
+			t.Errorf("skipped agents mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("Escalation", func(t *testing.T) {
+		texts, _ := run(t, sequentialagent.Config{
+			AgentConfig: agent.Config{
+				SubAgents: []agent.Agent{
+					step("first", session.EventActions{}),
+					step("escalating", session.EventActions{Escalate: true}),
+					step("last", session.EventActions{}),
+				},
+			},
+		})
+		if diff := cmp.Diff([]string{"first", "escalating"}, texts); diff != "" {
+			t.Errorf("texts mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("UnknownSubAgent", func(t *testing.T) {
+		_, err := sequentialagent.New(sequentialagent.Config{
+			AgentConfig: agent.Config{
+				Name:      "sequence",
+				SubAgents: []agent.Agent{step("first", session.EventActions{})},
+			},
+			Conditions: map[string]sequentialagent.Condition{
+				"unknown": stateEquals("key", "value"),
+			},
+		})
+		if err == nil {
+			t.Errorf("New() succeeded, want error")
+		}
+	})
+}
+
+func must[T agent.Agent](a T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
@@ -0,0 +1,331 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package artifactgc deletes the artifact versions which are no longer
+// needed: the oldest versions of the artifacts, the aged ones, and those of
+// the sessions which no longer exist.
+//
+// A collection runs on demand with [GC], or periodically with [Start].
+package artifactgc
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"maps"
+	"slices"
+	"time"
+
+	"google.golang.org/adk/artifact"
+	"google.golang.org/adk/session"
+)
+
+// Policy selects the artifact versions deleted by [GC]. A version is deleted
+// if any of the rules selects it.
+type Policy struct {
+	// MaxVersions is the number of most recent versions kept of every
+	// artifact.
+	// Optional: if zero, the number of versions isn't limited.
+	MaxVersions int
+	// MaxAge is the age from which the versions are deleted, except the
+	// latest version of every artifact.
+	// Optional: if zero, the versions don't age.
+	MaxAge time.Duration
+	// DeleteOrphaned deletes all the versions of the artifacts of the
+	// sessions which don't exist in Sessions. The user-scoped artifacts are
+	// never orphaned.
+	DeleteOrphaned bool
+	// Sessions is the session service of the sessions of the artifacts. The
+	// versions referenced by the latest events of the existing sessions are
+	// never deleted, see [GC].
+	Sessions session.Service
+	// Now returns the current time, which the creation times of the versions
+	// are compared with.
+	// Optional: if nil, [time.Now] is used.
+	Now func() time.Time
+}
+
+// validate checks that the policy is valid and deletes something.
+func (p *Policy) validate() error {
+	if p.MaxVersions < 0 {
+		return fmt.Errorf("max versions must not be negative, got %d", p.MaxVersions)
+	}
+	if p.MaxAge < 0 {
+		return fmt.Errorf("max age must not be negative, got %v", p.MaxAge)
+	}
+	if p.MaxVersions == 0 && p.MaxAge == 0 && !p.DeleteOrphaned {
+		return errors.New("the policy deletes nothing: set max versions, max age or delete orphaned")
+	}
+	if p.Sessions == nil {
+		return errors.New("the session service is required")
+	}
+	return nil
+}
+
+// Summary reports what a collection did.
+type Summary struct {
+	// Scanned is the number of walked artifact versions.
+	Scanned int
+	// Deleted is the number of deleted versions.
+	Deleted int
+	// Skipped is the number of versions selected by the policy, but kept
+	// because they are referenced by the latest events of their sessions.
+	Skipped int
+	// BytesReclaimed is the total size of the deleted versions.
+	BytesReclaimed int64
+}
+
+// artifactID identifies an artifact, whose session ID is empty if it is
+// user-scoped.
+type artifactID struct {
+	appName, userID, sessionID, fileName string
+}
+
+// userID identifies a user of an app.
+type userID struct {
+	appName, userID string
+}
+
+// GC deletes the artifact versions of artifacts selected by policy, and
+// reports what it did. artifacts must implement [artifact.Walker], otherwise
+// an error wrapping [errors.ErrUnsupported] is returned.
+//
+// A version is kept if it is the one recorded by the most recent event of an
+// existing session whose artifact delta holds the artifact, so that the
+// version which a session currently refers to is never deleted. The
+// user-scoped artifacts are checked against all the sessions of their user.
+// The versions saved or referenced while the collection runs may still be
+// deleted, so GC is best run when the artifacts are idle.
+func GC(ctx context.Context, artifacts artifact.Service, policy Policy) (*Summary, error) {
+	if err := policy.validate(); err != nil {
+		return nil, fmt.Errorf("invalid policy: %w", err)
+	}
+	walker, ok := artifacts.(artifact.Walker)
+	if !ok {
+		return nil, fmt.Errorf("artifact service %T doesn't enumerate its versions: %w", artifacts, errors.ErrUnsupported)
+	}
+	now := time.Now
+	if policy.Now != nil {
+		now = policy.Now
+	}
+	cutoff := now().Add(-policy.MaxAge)
+
+	summary := &Summary{}
+	versions := map[artifactID][]*artifact.VersionInfo{}
+	for info, err := range walker.Walk(ctx) {
+		if err != nil {
+			return nil, fmt.Errorf("failed to walk the artifact versions: %w", err)
+		}
+		summary.Scanned++
+		id := artifactID{info.AppName, info.UserID, info.SessionID, info.FileName}
+		versions[id] = append(versions[id], info)
+	}
+
+	refs := &references{
+		sessions: policy.Sessions,
+		live:     map[userID]map[string]bool{},
+		latest:   map[artifactID]int64{},
+		loaded:   map[artifactID]bool{},
+	}
+	for id, infos := range versions {
+		// Most recent first.
+		slices.SortFunc(infos, func(a, b *artifact.VersionInfo) int { return cmp.Compare(b.Version, a.Version) })
+		orphaned := false
+		if policy.DeleteOrphaned && id.sessionID != "" {
+			live, err := refs.isLive(ctx, id.appName, id.userID, id.sessionID)
+			if err != nil {
+				return nil, err
+			}
+			orphaned = !live
+		}
+		for i, info := range infos {
+			selected := orphaned ||
+				(policy.MaxVersions > 0 && i >= policy.MaxVersions) ||
+				(policy.MaxAge > 0 && i > 0 && info.CreateTime.Before(cutoff))
+			if !selected {
+				continue
+			}
+			if !orphaned {
+				referenced, err := refs.isReferenced(ctx, id, info.Version)
+				if err != nil {
+					return nil, err
+				}
+				if referenced {
+					summary.Skipped++
+					continue
+				}
+			}
+			if err := deleteVersion(ctx, artifacts, info); err != nil {
+				return nil, err
+			}
+			summary.Deleted++
+			summary.BytesReclaimed += info.Size
+		}
+	}
+	return summary, nil
+}
+
+// userScopedSessionID is the session ID of the requests deleting the
+// user-scoped artifacts, which the services ignore.
+const userScopedSessionID = "-"
+
+// deleteVersion deletes an artifact version, which may have been deleted
+// since it was walked.
+func deleteVersion(ctx context.Context, artifacts artifact.Service, info *artifact.VersionInfo) error {
+	sessionID := info.SessionID
+	if sessionID == "" {
+		sessionID = userScopedSessionID
+	}
+	err := artifacts.Delete(ctx, &artifact.DeleteRequest{
+		AppName:   info.AppName,
+		UserID:    info.UserID,
+		SessionID: sessionID,
+		FileName:  info.FileName,
+		Version:   info.Version,
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete version %d of artifact %q of user %q of app %q: %w", info.Version, info.FileName, info.UserID, info.AppName, err)
+	}
+	return nil
+}
+
+// references looks up the sessions of the artifacts, and caches them for
+// the duration of a collection.
+type references struct {
+	sessions session.Service
+	// live are the IDs of the existing sessions of the users.
+	live map[userID]map[string]bool
+	// latest are the versions recorded by the most recent events of the
+	// sessions for their artifacts. The keys of the user-scoped artifacts
+	// are those of the sessions which reference them.
+	latest map[artifactID]int64
+	// loaded are the sessions whose events were read into latest.
+	loaded map[artifactID]bool
+}
+
+// liveSessions returns the IDs of the existing sessions of a user.
+func (r *references) liveSessions(ctx context.Context, appName, user string) (map[string]bool, error) {
+	key := userID{appName, user}
+	if live, ok := r.live[key]; ok {
+		return live, nil
+	}
+	live := map[string]bool{}
+	req := &session.ListRequest{AppName: appName, UserID: user}
+	for {
+		resp, err := r.sessions.List(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the sessions of user %q of app %q: %w", user, appName, err)
+		}
+		for _, s := range resp.Sessions {
+			live[s.ID()] = true
+		}
+		if resp.NextPageToken == "" {
+			break
+		}
+		req.PageToken = resp.NextPageToken
+	}
+	r.live[key] = live
+	return live, nil
+}
+
+// isLive reports whether a session exists.
+func (r *references) isLive(ctx context.Context, appName, user, sessionID string) (bool, error) {
+	live, err := r.liveSessions(ctx, appName, user)
+	if err != nil {
+		return false, err
+	}
+	return live[sessionID], nil
+}
+
+// isReferenced reports whether version is the one of the artifact recorded
+// by the most recent event of one of the existing sessions referencing it.
+func (r *references) isReferenced(ctx context.Context, id artifactID, version int64) (bool, error) {
+	live, err := r.liveSessions(ctx, id.appName, id.userID)
+	if err != nil {
+		return false, err
+	}
+	var sessionIDs []string
+	if id.sessionID == "" {
+		sessionIDs = slices.Collect(maps.Keys(live))
+	} else if live[id.sessionID] {
+		sessionIDs = []string{id.sessionID}
+	}
+	for _, sessionID := range sessionIDs {
+		if err := r.load(ctx, id.appName, id.userID, sessionID); err != nil {
+			return false, err
+		}
+		if latest, ok := r.latest[artifactID{id.appName, id.userID, sessionID, id.fileName}]; ok && latest == version {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// load reads the artifact versions referenced by the events of a session.
+func (r *references) load(ctx context.Context, appName, user, sessionID string) error {
+	key := artifactID{appName: appName, userID: user, sessionID: sessionID}
+	if r.loaded[key] {
+		return nil
+	}
+	resp, err := r.sessions.Get(ctx, &session.GetRequest{AppName: appName, UserID: user, SessionID: sessionID})
+	if err != nil {
+		return fmt.Errorf("failed to get session %q of user %q of app %q: %w", sessionID, user, appName, err)
+	}
+	// Later events overwrite the versions of the earlier ones.
+	for event := range resp.Session.Events().All() {
+		for fileName, version := range event.Actions.ArtifactDelta {
+			r.latest[artifactID{appName, user, sessionID, fileName}] = version
+		}
+	}
+	r.loaded[key] = true
+	return nil
+}
+
+// Start starts a goroutine which runs GC every interval, until ctx is done.
+// The summaries and the errors of the collections are logged. It fails if
+// the policy is invalid, or if artifacts doesn't implement [artifact.Walker].
+func Start(ctx context.Context, artifacts artifact.Service, policy Policy, interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("interval must be positive, got %v", interval)
+	}
+	if err := policy.validate(); err != nil {
+		return fmt.Errorf("invalid policy: %w", err)
+	}
+	if _, ok := artifacts.(artifact.Walker); !ok {
+		return fmt.Errorf("artifact service %T doesn't enumerate its versions: %w", artifacts, errors.ErrUnsupported)
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			summary, err := GC(ctx, artifacts, policy)
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Printf("failed to collect the garbage artifacts: %v", err)
+				}
+				continue
+			}
+			log.Printf("collected the garbage artifacts: scanned %d versions, deleted %d, skipped %d referenced ones, reclaimed %d bytes", summary.Scanned, summary.Deleted, summary.Skipped, summary.BytesReclaimed)
+		}
+	}()
+	return nil
+}
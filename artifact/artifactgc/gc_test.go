@@ -0,0 +1,204 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package artifactgc_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/artifact"
+	"google.golang.org/adk/artifact/artifactgc"
+	"google.golang.org/adk/session"
+)
+
+// plainService hides the optional interfaces of the service.
+type plainService struct {
+	artifact.Service
+}
+
+// setup stores the versions of the artifacts of an existing session
+// ("live"), of a deleted one ("gone") and user-scoped ones, some of which
+// are referenced by the events of the live session.
+func setup(t *testing.T) (artifact.Service, session.Service) {
+	t.Helper()
+	ctx := t.Context()
+	artifacts, sessions := artifact.InMemoryService(), session.InMemoryService()
+	saves := []struct {
+		sessionID, fileName string
+		versions            int
+	}{
+		{"live", "doc", 4},
+		{"gone", "old", 2},
+		{"live", "user:profile", 3},
+	}
+	for _, save := range saves {
+		for i := range save.versions {
+			if _, err := artifacts.Save(ctx, &artifact.SaveRequest{
+				AppName: "app", UserID: "user", SessionID: save.sessionID, FileName: save.fileName,
+				Part: genai.NewPartFromBytes(fmt.Appendf(nil, "v%d", i+1), "text/plain"),
+			}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	created, err := sessions.Create(ctx, &session.CreateRequest{AppName: "app", UserID: "user", SessionID: "live"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, delta := range []map[string]int64{
+		{"doc": 2, "user:profile": 1},
+		{"doc": 3},
+		nil,
+	} {
+		event := session.NewEvent("invocation")
+		event.Author = "agent"
+		event.Actions.ArtifactDelta = delta
+		if err := sessions.AppendEvent(ctx, created.Session, event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return artifacts, sessions
+}
+
+func TestGC(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		policy       artifactgc.Policy
+		want         artifactgc.Summary
+		wantVersions map[string][]int64
+	}{
+		{
+			name:   "max versions",
+			policy: artifactgc.Policy{MaxVersions: 1},
+			// The versions 3 of doc and 1 of user:profile are the latest
+			// ones referenced by the live session.
+			want:         artifactgc.Summary{Scanned: 9, Deleted: 4, Skipped: 2, BytesReclaimed: 8},
+			wantVersions: map[string][]int64{"doc": {4, 3}, "old": {2}, "user:profile": {3, 1}},
+		},
+		{
+			name:         "max age",
+			policy:       artifactgc.Policy{MaxAge: time.Hour, Now: func() time.Time { return time.Now().Add(2 * time.Hour) }},
+			want:         artifactgc.Summary{Scanned: 9, Deleted: 4, Skipped: 2, BytesReclaimed: 8},
+			wantVersions: map[string][]int64{"doc": {4, 3}, "old": {2}, "user:profile": {3, 1}},
+		},
+		{
+			name:         "not aged",
+			policy:       artifactgc.Policy{MaxAge: time.Hour},
+			want:         artifactgc.Summary{Scanned: 9},
+			wantVersions: map[string][]int64{"doc": {4, 3, 2, 1}, "old": {2, 1}, "user:profile": {3, 2, 1}},
+		},
+		{
+			name:         "orphaned",
+			policy:       artifactgc.Policy{DeleteOrphaned: true},
+			want:         artifactgc.Summary{Scanned: 9, Deleted: 2, BytesReclaimed: 4},
+			wantVersions: map[string][]int64{"doc": {4, 3, 2, 1}, "user:profile": {3, 2, 1}},
+		},
+		{
+			name:   "max versions and orphaned",
+			policy: artifactgc.Policy{MaxVersions: 2, DeleteOrphaned: true},
+			// The version 2 of doc is referenced by an event, but not by the
+			// latest one referencing doc.
+			want:         artifactgc.Summary{Scanned: 9, Deleted: 4, Skipped: 1, BytesReclaimed: 8},
+			wantVersions: map[string][]int64{"doc": {4, 3}, "user:profile": {3, 2, 1}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			artifacts, sessions := setup(t)
+			tc.policy.Sessions = sessions
+			got, err := artifactgc.GC(t.Context(), artifacts, tc.policy)
+			if err != nil {
+				t.Fatalf("GC() failed: %v", err)
+			}
+			if diff := cmp.Diff(&tc.want, got); diff != "" {
+				t.Errorf("GC() summary mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantVersions, versions(t, artifacts)); diff != "" {
+				t.Errorf("GC() versions mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// versions returns the versions of the artifacts set up by setup, most
+// recent first.
+func versions(t *testing.T, artifacts artifact.Service) map[string][]int64 {
+	t.Helper()
+	got := map[string][]int64{}
+	for _, id := range []struct{ sessionID, fileName string }{{"live", "doc"}, {"gone", "old"}, {"live", "user:profile"}} {
+		resp, err := artifacts.Versions(t.Context(), &artifact.VersionsRequest{AppName: "app", UserID: "user", SessionID: id.sessionID, FileName: id.fileName})
+		if err != nil {
+			continue
+		}
+		got[id.fileName] = resp.Versions
+	}
+	return got
+}
+
+func TestGC_Errors(t *testing.T) {
+	artifacts, sessions := setup(t)
+	for _, tc := range []struct {
+		name      string
+		artifacts artifact.Service
+		policy    artifactgc.Policy
+		wantErr   error
+	}{
+		{"not a walker", plainService{artifacts}, artifactgc.Policy{MaxVersions: 1, Sessions: sessions}, errors.ErrUnsupported},
+		{"negative max versions", artifacts, artifactgc.Policy{MaxVersions: -1, Sessions: sessions}, nil},
+		{"negative max age", artifacts, artifactgc.Policy{MaxAge: -time.Hour, Sessions: sessions}, nil},
+		{"no rule", artifacts, artifactgc.Policy{Sessions: sessions}, nil},
+		{"no session service", artifacts, artifactgc.Policy{MaxVersions: 1}, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := artifactgc.GC(t.Context(), tc.artifacts, tc.policy)
+			if err == nil {
+				t.Fatalf("GC() succeeded, want an error")
+			}
+			if tc.wantErr != nil && !errors.Is(err, tc.wantErr) {
+				t.Errorf("GC() error = %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestStart(t *testing.T) {
+	artifacts, sessions := setup(t)
+	policy := artifactgc.Policy{DeleteOrphaned: true, Sessions: sessions}
+	if err := artifactgc.Start(t.Context(), plainService{artifacts}, policy, time.Millisecond); !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("Start() of a service which isn't a walker error = %v, want %v", err, errors.ErrUnsupported)
+	}
+	if err := artifactgc.Start(t.Context(), artifacts, policy, 0); err == nil {
+		t.Errorf("Start() with a zero interval succeeded, want an error")
+	}
+
+	if err := artifactgc.Start(t.Context(), artifacts, policy, time.Millisecond); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, ok := versions(t, artifacts)["old"]; !ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("the scheduled collection didn't delete the orphaned artifact")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -31,6 +31,7 @@ type gcsClient interface {
 type gcsBucket interface {
 	object(name string) gcsObject
 	objects(ctx context.Context, q *storage.Query) gcsObjectIterator
+	signedURL(object string, opts *storage.SignedURLOptions) (string, error)
 }
 
 // gcsObject is an interface that a gcs object handle must satisfy.
@@ -51,6 +52,7 @@ type gcsWriter interface {
 	io.Writer // Provides Write(p []byte) (n int, err error)
 	io.Closer // Provides Close() error
 	SetContentType(string)
+	SetMetadata(map[string]string)
 }
 
 // ---------------------- Wrapper Implementations for Real gcs Types --------------------------------
@@ -87,6 +89,11 @@ func (w *gcsBucketWrapper) objects(ctx context.Context, q *storage.Query) gcsObj
 	return &gcsObjectIteratorWrapper{iter: realIterator}
 }
 
+// SignedURL implements the gcsBucket interface for gcsBucketWrapper.
+func (w *gcsBucketWrapper) signedURL(object string, opts *storage.SignedURLOptions) (string, error) {
+	return w.bucket.SignedURL(object, opts)
+}
+
 // gcsObjectWrapper wraps a storage.ObjectHandle to satisfy the gcsObject interface.
 type gcsObjectWrapper struct {
 	object *storage.ObjectHandle
@@ -138,6 +145,10 @@ func (g *gcsWriterWrapper) SetContentType(cType string) {
 	g.w.ContentType = cType
 }
 
+func (g *gcsWriterWrapper) SetMetadata(metadata map[string]string) {
+	g.w.Metadata = metadata
+}
+
 var (
 	_ gcsClient         = (*gcsClientWrapper)(nil)
 	_ gcsBucket         = (*gcsBucketWrapper)(nil)
@@ -17,6 +17,7 @@ package gcsartifact
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"io"
 	"io/fs"
 	"strings"
@@ -25,7 +26,9 @@ import (
 	"time"
 
 	"cloud.google.com/go/storage"
+	"github.com/google/go-cmp/cmp"
 	"google.golang.org/api/iterator"
+	"google.golang.org/genai"
 
 	"google.golang.org/adk/artifact"
 	"google.golang.org/adk/internal/artifact/tests"
@@ -49,6 +52,104 @@ func TestGCSArtifactService(t *testing.T) {
 	tests.TestArtifactService(t, "GCS", factory)
 }
 
+func TestGCSArtifactService_SaveStream(t *testing.T) {
+	s, err := newGCSArtifactServiceForTesting("new")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := bytes.Repeat([]byte("data"), 1000)
+	for wantVersion := int64(1); wantVersion <= 2; wantVersion++ {
+		resp, err := artifact.SaveStream(t.Context(), s, &artifact.SaveStreamRequest{
+			AppName:   "app",
+			UserID:    "user",
+			SessionID: "session",
+			FileName:  "file.bin",
+			MIMEType:  "application/octet-stream",
+			Content:   bytes.NewReader(content),
+		})
+		if err != nil {
+			t.Fatalf("SaveStream() failed: %v", err)
+		}
+		if resp.Version != wantVersion {
+			t.Errorf("SaveStream() version = %d, want %d", resp.Version, wantVersion)
+		}
+	}
+	loaded, err := s.Load(t.Context(), &artifact.LoadRequest{AppName: "app", UserID: "user", SessionID: "session", FileName: "file.bin"})
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if loaded.Part.InlineData == nil || !bytes.Equal(loaded.Part.InlineData.Data, content) {
+		t.Errorf("Load() returned different content than saved")
+	}
+	if got, want := loaded.Part.InlineData.MIMEType, "application/octet-stream"; got != want {
+		t.Errorf("Load() MIME type = %q, want %q", got, want)
+	}
+}
+
+func TestGCSArtifactService_LoadKeepsPartKind(t *testing.T) {
+	s, err := newGCSArtifactServiceForTesting("new")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name string
+		part *genai.Part
+	}{
+		{"text", genai.NewPartFromText("hello")},
+		{"text bytes", genai.NewPartFromBytes([]byte("hello"), "text/plain")},
+		{"image", genai.NewPartFromBytes([]byte{0x89, 'P', 'N', 'G'}, "image/png")},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := &artifact.SaveRequest{AppName: "app", UserID: "user", SessionID: "session", FileName: tc.name, Part: tc.part}
+			if _, err := s.Save(t.Context(), req); err != nil {
+				t.Fatalf("Save() failed: %v", err)
+			}
+			got, err := s.Load(t.Context(), &artifact.LoadRequest{AppName: "app", UserID: "user", SessionID: "session", FileName: tc.name})
+			if err != nil {
+				t.Fatalf("Load() failed: %v", err)
+			}
+			if diff := cmp.Diff(tc.part, got.Part); diff != "" {
+				t.Errorf("Load() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGCSArtifactService_SignURL(t *testing.T) {
+	s, err := newGCSArtifactServiceForTesting("new")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, fileName := range []string{"report.pdf", "user:profile.png"} {
+		if _, err := s.Save(t.Context(), &artifact.SaveRequest{
+			AppName: "app", UserID: "user", SessionID: "session", FileName: fileName,
+			Part: genai.NewPartFromBytes([]byte("data"), "application/octet-stream"),
+		}); err != nil {
+			t.Fatalf("Save(%s) failed: %v", fileName, err)
+		}
+	}
+	for _, tc := range []struct {
+		fileName string
+		wantBlob string
+	}{
+		{"report.pdf", "app/user/session/report.pdf/1"},
+		{"user:profile.png", "app/user/user/user:profile.png/1"},
+	} {
+		t.Run(tc.fileName, func(t *testing.T) {
+			resp, err := s.(artifact.URLSigner).SignURL(t.Context(), &artifact.SignURLRequest{
+				AppName: "app", UserID: "user", SessionID: "session", FileName: tc.fileName, TTL: time.Hour,
+			})
+			if err != nil {
+				t.Fatalf("SignURL() failed: %v", err)
+			}
+			want := fmt.Sprintf("https://storage.example.com/%s?method=GET&expires=%d", tc.wantBlob, resp.Expires.Unix())
+			if resp.URL != want {
+				t.Errorf("SignURL() URL = %q, want %q", resp.URL, want)
+			}
+		})
+	}
+}
+
 // ---------------------------------- Mock Implementations -----------------------------------
 // fakeClient implements the gcsClient interface for testing.
 type fakeClient struct {
@@ -84,6 +185,11 @@ func (f *fakeBucket) object(name string) gcsObject {
 	return f.objectsMap[name]
 }
 
+// SignedURL returns a fake URL of the object, with its signing options.
+func (f *fakeBucket) signedURL(object string, opts *storage.SignedURLOptions) (string, error) {
+	return fmt.Sprintf("https://storage.example.com/%s?method=%s&expires=%d", object, opts.Method, opts.Expires.Unix()), nil
+}
+
 // Objects simulates iterating over objects with a prefix.
 func (f *fakeBucket) objects(ctx context.Context, q *storage.Query) gcsObjectIterator {
 	f.mu.Lock()
@@ -94,7 +200,7 @@ func (f *fakeBucket) objects(ctx context.Context, q *storage.Query) gcsObjectIte
 		if q != nil && q.Prefix != "" && !strings.HasPrefix(name, q.Prefix) {
 			continue
 		}
-		if !obj.deleted {
+		if !obj.deleted && !obj.created.IsZero() {
 			matchingObjects = append(matchingObjects, obj)
 		}
 	}
@@ -114,6 +220,9 @@ type fakeObject struct {
 	data        []byte
 	deleted     bool
 	contentType string
+	metadata    map[string]string
+	// created is when the object was last written, zero if it never was.
+	created time.Time
 }
 
 // NewWriter returns a fake writer that stores data in memory.
@@ -132,7 +241,7 @@ func (f *fakeObject) attrs(ctx context.Context) (*storage.ObjectAttrs, error) {
 	if f.deleted || f.data == nil {
 		return nil, storage.ErrObjectNotExist
 	}
-	return &storage.ObjectAttrs{Name: f.name, Created: time.Now(), ContentType: f.contentType}, nil
+	return &storage.ObjectAttrs{Name: f.name, Created: f.created, ContentType: f.contentType, Metadata: f.metadata, Size: int64(len(f.data))}, nil
 }
 
 // Delete marks the object as deleted in memory.
@@ -158,6 +267,7 @@ type fakeWriter struct {
 	obj         *fakeObject
 	buffer      *bytes.Buffer
 	contentType string
+	metadata    map[string]string
 }
 
 func (w *fakeWriter) Write(p []byte) (n int, err error) {
@@ -169,6 +279,8 @@ func (w *fakeWriter) Close() error {
 	defer w.obj.mu.Unlock()
 	w.obj.data = w.buffer.Bytes()
 	w.obj.contentType = w.contentType
+	w.obj.metadata = w.metadata
+	w.obj.created = time.Now()
 	return nil
 }
 
@@ -177,6 +289,10 @@ func (w *fakeWriter) SetContentType(cType string) {
 	w.contentType = cType
 }
 
+func (w *fakeWriter) SetMetadata(metadata map[string]string) {
+	w.metadata = metadata
+}
+
 // fakeObjectIterator is a fake iterator that returns attributes from a slice.
 // This type is the key to solving the 'unknown field' error.
 type fakeObjectIterator struct {
@@ -192,7 +308,7 @@ func (i *fakeObjectIterator) next() (*storage.ObjectAttrs, error) {
 	}
 	obj := i.objects[i.index]
 	i.index++
-	return &storage.ObjectAttrs{Name: obj.name, ContentType: obj.contentType}, nil
+	return &storage.ObjectAttrs{Name: obj.name, ContentType: obj.contentType, Size: int64(len(obj.data)), Created: obj.created}, nil
 }
 
 var (
@@ -20,15 +20,19 @@
 package gcsartifact
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
 	"io/fs"
+	"iter"
 	"maps"
+	"net/http"
 	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"cloud.google.com/go/storage"
 	"golang.org/x/sync/errgroup"
@@ -39,6 +43,13 @@ import (
 	"google.golang.org/adk/artifact"
 )
 
+const (
+	// metadataPartKind is the object metadata key recording how the part
+	// was provided, so that text parts are loaded back as text.
+	metadataPartKind = "adk-part-kind"
+	partKindText     = "text"
+)
+
 // gcsService is a google cloud storage implementation of the Service.
 type gcsService struct {
 	bucketName    string
@@ -91,20 +102,43 @@ func buildUserPrefix(appName, userID string) string {
 }
 
 // Save implements [artifact.Service]
-func (s *gcsService) Save(ctx context.Context, req *artifact.SaveRequest) (_ *artifact.SaveResponse, err error) {
-	err = req.Validate()
+func (s *gcsService) Save(ctx context.Context, req *artifact.SaveRequest) (*artifact.SaveResponse, error) {
+	err := req.Validate()
 	if err != nil {
 		return nil, fmt.Errorf("request validation failed: %w", err)
 	}
-	appName, userID, sessionID, fileName := req.AppName, req.UserID, req.SessionID, req.FileName
 	newArtifact := req.Part
+	var mimeType string
+	var content io.Reader
+	metadata := maps.Clone(req.Metadata)
+	if newArtifact.InlineData != nil {
+		mimeType, content = newArtifact.InlineData.MIMEType, bytes.NewReader(newArtifact.InlineData.Data)
+	} else {
+		mimeType, content = "text/plain", strings.NewReader(newArtifact.Text)
+		if metadata == nil {
+			metadata = map[string]string{}
+		}
+		metadata[metadataPartKind] = partKindText
+	}
+	return s.saveStream(ctx, req.AppName, req.UserID, req.SessionID, req.FileName, mimeType, metadata, content)
+}
+
+// SaveStream implements [artifact.StreamSaver]
+func (s *gcsService) SaveStream(ctx context.Context, req *artifact.SaveStreamRequest) (*artifact.SaveResponse, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("request validation failed: %w", err)
+	}
+	return s.saveStream(ctx, req.AppName, req.UserID, req.SessionID, req.FileName, req.MIMEType, req.Metadata, req.Content)
+}
 
+func (s *gcsService) saveStream(ctx context.Context, appName, userID, sessionID, fileName, mimeType string, metadata map[string]string, content io.Reader) (_ *artifact.SaveResponse, err error) {
 	nextVersion := int64(1)
 
 	// TODO race condition, could use mutex but it's a remote resource so the issue would still occurs
 	// with multiple consumers, and gcs does not have transactions spanning several operations
 	response, err := s.versions(ctx, &artifact.VersionsRequest{
-		AppName: req.AppName, UserID: req.UserID, SessionID: req.SessionID, FileName: req.FileName,
+		AppName: appName, UserID: userID, SessionID: sessionID, FileName: fileName,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list artifact versions: %w", err)
@@ -114,23 +148,24 @@ func (s *gcsService) Save(ctx context.Context, req *artifact.SaveRequest) (_ *ar
 	}
 
 	blobName := buildBlobName(appName, userID, sessionID, fileName, nextVersion)
-	writer := s.bucket.object(blobName).newWriter(ctx)
+	// Cancelling the writer context before closing it aborts the upload, so
+	// that a failed read doesn't leave a truncated version behind.
+	writerCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	writer := s.bucket.object(blobName).newWriter(writerCtx)
 	defer func() {
 		if closeErr := writer.Close(); closeErr != nil && err == nil {
 			err = fmt.Errorf("failed to close blob writer: %w", closeErr)
 		}
 	}()
 
-	if newArtifact.InlineData != nil {
-		writer.SetContentType(newArtifact.InlineData.MIMEType)
-		if _, err := writer.Write(newArtifact.InlineData.Data); err != nil {
-			return nil, fmt.Errorf("failed to write blob to GCS: %w", err)
-		}
-	} else {
-		writer.SetContentType("text/plain")
-		if _, err := writer.Write([]byte(newArtifact.Text)); err != nil {
-			return nil, fmt.Errorf("failed to write text to GCS: %w", err)
-		}
+	writer.SetContentType(mimeType)
+	if metadata != nil {
+		writer.SetMetadata(metadata)
+	}
+	if _, err := io.Copy(writer, content); err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to write blob to GCS: %w", err)
 	}
 
 	return &artifact.SaveResponse{Version: nextVersion}, nil
@@ -183,7 +218,33 @@ func (s *gcsService) Delete(ctx context.Context, req *artifact.DeleteRequest) er
 
 // Load implements [artifact.Service]
 func (s *gcsService) Load(ctx context.Context, req *artifact.LoadRequest) (_ *artifact.LoadResponse, err error) {
-	err = req.Validate()
+	resp, err := s.LoadStream(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := resp.Content.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to close blob reader: %w", closeErr)
+		}
+	}()
+
+	// Read all the content into a byte slice
+	data, err := io.ReadAll(resp.Content)
+	if err != nil {
+		return nil, fmt.Errorf("could not read data from blob: %w", err)
+	}
+
+	// Create the genai.Part and return the response.
+	if resp.Text {
+		return &artifact.LoadResponse{Part: genai.NewPartFromText(string(data)), Metadata: resp.Metadata}, nil
+	}
+	return &artifact.LoadResponse{Part: genai.NewPartFromBytes(data, resp.MIMEType), Metadata: resp.Metadata}, nil
+}
+
+// LoadStream implements [artifact.StreamLoader]. The content is read from
+// GCS while it is consumed, and doesn't implement [io.Seeker].
+func (s *gcsService) LoadStream(ctx context.Context, req *artifact.LoadRequest) (*artifact.LoadStreamResponse, error) {
+	err := req.Validate()
 	if err != nil {
 		return nil, fmt.Errorf("request validation failed: %w", err)
 	}
@@ -220,22 +281,25 @@ func (s *gcsService) Load(ctx context.Context, req *artifact.LoadRequest) (_ *ar
 	if err != nil {
 		return nil, fmt.Errorf("could not create reader for blob '%s': %w", blobName, err)
 	}
-	defer func() {
-		if closeErr := reader.Close(); closeErr != nil && err == nil {
-			err = fmt.Errorf("failed to close blob reader: %w", closeErr)
-		}
-	}()
+	return &artifact.LoadStreamResponse{
+		Version:  version,
+		MIMEType: attrs.ContentType,
+		Text:     attrs.Metadata[metadataPartKind] == partKindText,
+		Size:     attrs.Size,
+		Metadata: userMetadata(attrs.Metadata),
+		Content:  reader,
+	}, nil
+}
 
-	// Read all the content into a byte slice
-	data, err := io.ReadAll(reader)
-	if err != nil {
-		return nil, fmt.Errorf("could not read data from blob '%s': %w", blobName, err)
+// userMetadata returns the metadata of a blob without the keys set by the
+// service.
+func userMetadata(metadata map[string]string) map[string]string {
+	metadata = maps.Clone(metadata)
+	delete(metadata, metadataPartKind)
+	if len(metadata) == 0 {
+		return nil
 	}
-
-	// Create the genai.Part and return the response.
-	part := genai.NewPartFromBytes(data, attrs.ContentType)
-
-	return &artifact.LoadResponse{Part: part}, nil
+	return metadata
 }
 
 // fetchFilenamesFromPrefix is a reusable helper function.
@@ -350,3 +414,157 @@ func (s *gcsService) Versions(ctx context.Context, req *artifact.VersionsRequest
 	}
 	return response, nil
 }
+
+// maxParallelDeletes is the number of blobs deleted in parallel by
+// DeleteUserData.
+const maxParallelDeletes = 16
+
+// DeleteUserData implements [artifact.UserDataDeleter]. It deletes all the
+// blobs under the prefix of the user, which holds the artifacts of the
+// sessions of the user and the user-scoped ones.
+func (s *gcsService) DeleteUserData(ctx context.Context, req *artifact.DeleteUserDataRequest) (*artifact.DeleteUserDataResponse, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("request validation failed: %w", err)
+	}
+	blobsIterator := s.bucket.objects(ctx, &storage.Query{
+		Prefix: fmt.Sprintf("%s/%s/", req.AppName, req.UserID),
+	})
+	var blobNames []string
+	for {
+		blob, err := blobsIterator.next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error iterating blobs: %w", err)
+		}
+		blobNames = append(blobNames, blob.Name)
+	}
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(maxParallelDeletes)
+	for _, blobName := range blobNames {
+		g.Go(func() error {
+			if err := s.bucket.object(blobName).delete(gctx); err != nil {
+				return fmt.Errorf("failed to delete artifact %s: %w", blobName, err)
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return &artifact.DeleteUserDataResponse{DeletedVersions: len(blobNames)}, nil
+}
+
+// GetUsage implements [artifact.UsageReporter]. It lists all the blobs
+// under the prefix of the user.
+func (s *gcsService) GetUsage(ctx context.Context, req *artifact.GetUsageRequest) (*artifact.GetUsageResponse, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("request validation failed: %w", err)
+	}
+	query := &storage.Query{Prefix: fmt.Sprintf("%s/%s/", req.AppName, req.UserID)}
+	if err := query.SetAttrSelection([]string{"Name", "Size"}); err != nil {
+		return nil, fmt.Errorf("error setting query attribute selection: %w", err)
+	}
+	blobsIterator := s.bucket.objects(ctx, query)
+	usage := &artifact.GetUsageResponse{}
+	for {
+		blob, err := blobsIterator.next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error iterating blobs: %w", err)
+		}
+		usage.Versions++
+		usage.Bytes += blob.Size
+	}
+	return usage, nil
+}
+
+// SignURL implements [artifact.URLSigner]. The URLs are signed with the V4
+// scheme, with the credentials of the client or through the IAM API, see
+// [storage.BucketHandle.SignedURL].
+func (s *gcsService) SignURL(ctx context.Context, req *artifact.SignURLRequest) (*artifact.SignURLResponse, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("request validation failed: %w", err)
+	}
+	version, err := artifact.SignedVersion(ctx, s, req)
+	if err != nil {
+		return nil, err
+	}
+	expires := time.Now().Add(req.TTL)
+	blobName := buildBlobName(req.AppName, req.UserID, req.SessionID, req.FileName, version)
+	url, err := s.bucket.signedURL(blobName, &storage.SignedURLOptions{
+		Method:  http.MethodGet,
+		Expires: expires,
+		Scheme:  storage.SigningSchemeV4,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign the URL of blob %q: %w", blobName, err)
+	}
+	return &artifact.SignURLResponse{Version: version, URL: url, Expires: expires}, nil
+}
+
+// Walk implements [artifact.Walker]. It lists all the blobs of the bucket,
+// ignoring those which aren't artifact versions.
+func (s *gcsService) Walk(ctx context.Context) iter.Seq2[*artifact.VersionInfo, error] {
+	return func(yield func(*artifact.VersionInfo, error) bool) {
+		query := &storage.Query{}
+		if err := query.SetAttrSelection([]string{"Name", "Size", "Created"}); err != nil {
+			yield(nil, fmt.Errorf("error setting query attribute selection: %w", err))
+			return
+		}
+		blobsIterator := s.bucket.objects(ctx, query)
+		for {
+			blob, err := blobsIterator.next()
+			if err == iterator.Done {
+				return
+			}
+			if err != nil {
+				yield(nil, fmt.Errorf("error iterating blobs: %w", err))
+				return
+			}
+			info, ok := parseBlobName(blob.Name)
+			if !ok {
+				continue
+			}
+			info.Size = blob.Size
+			info.CreateTime = blob.Created
+			if !yield(info, nil) {
+				return
+			}
+		}
+	}
+}
+
+// parseBlobName returns the artifact version of a blob built by
+// buildBlobName.
+func parseBlobName(name string) (*artifact.VersionInfo, bool) {
+	segments := strings.Split(name, "/")
+	if len(segments) != 5 {
+		return nil, false
+	}
+	version, err := strconv.ParseInt(segments[4], 10, 64)
+	if err != nil {
+		return nil, false
+	}
+	info := &artifact.VersionInfo{AppName: segments[0], UserID: segments[1], SessionID: segments[2], FileName: segments[3], Version: version}
+	if fileHasUserNamespace(info.FileName) {
+		info.SessionID = ""
+	}
+	return info, true
+}
+
+var (
+	_ artifact.StreamSaver     = (*gcsService)(nil)
+	_ artifact.StreamLoader    = (*gcsService)(nil)
+	_ artifact.UserDataDeleter = (*gcsService)(nil)
+	_ artifact.UsageReporter   = (*gcsService)(nil)
+	_ artifact.URLSigner       = (*gcsService)(nil)
+	_ artifact.Walker          = (*gcsService)(nil)
+)
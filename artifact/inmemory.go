@@ -15,6 +15,7 @@
 package artifact
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io/fs"
@@ -25,6 +26,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"google.golang.org/genai"
 	"rsc.io/omap"
@@ -35,8 +37,16 @@ import (
 // It is primarily for testing and demonstration purposes.
 type inMemoryService struct {
 	mu sync.RWMutex
-	// ordered(appName, userID, sessionID) -> session
-	artifacts omap.Map[string, *genai.Part]
+	// ordered(appName, userID, sessionID, fileName, version) -> artifact
+	artifacts omap.Map[string, *storedArtifact]
+}
+
+// storedArtifact is an artifact version and its metadata, which are never
+// modified once stored.
+type storedArtifact struct {
+	part     *genai.Part
+	metadata map[string]string
+	created  time.Time
 }
 
 // InMemoryService returns a new in-memory artifact service.
@@ -80,8 +90,8 @@ func (ak *artifactKey) Decode(key string) error {
 // scan returns an iterator over all key-value pairs
 // in the range begin ≤ key ≤ end.
 // TODO: add a concurrent tests.
-func (s *inMemoryService) scan(lo, hi string) iter.Seq2[artifactKey, *genai.Part] {
-	return func(yield func(key artifactKey, val *genai.Part) bool) {
+func (s *inMemoryService) scan(lo, hi string) iter.Seq2[artifactKey, *storedArtifact] {
+	return func(yield func(key artifactKey, val *storedArtifact) bool) {
 		for k, val := range s.artifacts.Scan(lo, hi) {
 			var key artifactKey
 			if err := key.Decode(k); err != nil {
@@ -95,7 +105,7 @@ func (s *inMemoryService) scan(lo, hi string) iter.Seq2[artifactKey, *genai.Part
 	}
 }
 
-func (s *inMemoryService) find(appName, userID, sessionID, fileName string) (int64, *genai.Part, bool) {
+func (s *inMemoryService) find(appName, userID, sessionID, fileName string) (int64, *storedArtifact, bool) {
 	lo := artifactKey{AppName: appName, UserID: userID, SessionID: sessionID, FileName: fileName, Version: math.MaxInt64}.Encode()
 	hi := artifactKey{AppName: appName, UserID: userID, SessionID: sessionID, FileName: fileName, Version: 0}.Encode()
 	for key, val := range s.scan(lo, hi) {
@@ -105,7 +115,7 @@ func (s *inMemoryService) find(appName, userID, sessionID, fileName string) (int
 	return 0, nil, false
 }
 
-func (s *inMemoryService) get(appName, userID, sessionID, fileName string, version int64) (*genai.Part, bool) {
+func (s *inMemoryService) get(appName, userID, sessionID, fileName string, version int64) (*storedArtifact, bool) {
 	key := artifactKey{
 		AppName:   appName,
 		UserID:    userID,
@@ -116,7 +126,7 @@ func (s *inMemoryService) get(appName, userID, sessionID, fileName string, versi
 	return s.artifacts.Get(key)
 }
 
-func (s *inMemoryService) set(appName, userID, sessionID, fileName string, version int64, artifact *genai.Part) {
+func (s *inMemoryService) set(appName, userID, sessionID, fileName string, version int64, artifact *storedArtifact) {
 	key := artifactKey{
 		AppName:   appName,
 		UserID:    userID,
@@ -145,7 +155,7 @@ func (s *inMemoryService) Save(ctx context.Context, req *SaveRequest) (*SaveResp
 		return nil, fmt.Errorf("request validation failed: %w", err)
 	}
 	appName, userID, sessionID, fileName := req.AppName, req.UserID, req.SessionID, req.FileName
-	artifact := req.Part
+	artifact := &storedArtifact{part: req.Part, metadata: maps.Clone(req.Metadata), created: time.Now()}
 	// If file is user scoped, store it under user scope path
 	if fileHasUserNamespace(fileName) {
 		sessionID = userScopedArtifactKey
@@ -211,14 +221,69 @@ func (s *inMemoryService) Load(ctx context.Context, req *LoadRequest) (*LoadResp
 		if !ok {
 			return nil, fmt.Errorf("artifact not found: %w", fs.ErrNotExist)
 		}
-		return &LoadResponse{Part: artifact}, nil
+		return &LoadResponse{Part: artifact.part, Metadata: maps.Clone(artifact.metadata)}, nil
 	}
 	// pick the latest version
 	_, artifact, ok := s.find(appName, userID, sessionID, fileName)
 	if !ok {
 		return nil, fmt.Errorf("artifact not found: %w", fs.ErrNotExist)
 	}
-	return &LoadResponse{Part: artifact}, nil
+	return &LoadResponse{Part: artifact.part, Metadata: maps.Clone(artifact.metadata)}, nil
+}
+
+// Open implements [artifact.Opener]
+func (s *inMemoryService) Open(ctx context.Context, req *LoadRequest) (*OpenResponse, error) {
+	version, artifact, err := s.lookup(req)
+	if err != nil {
+		return nil, err
+	}
+	// Stored parts are never modified, so the content can be read after the
+	// lock is released.
+	if part := artifact.part; part.InlineData != nil {
+		return &OpenResponse{Version: version, MIMEType: part.InlineData.MIMEType, Content: bytes.NewReader(part.InlineData.Data)}, nil
+	}
+	return &OpenResponse{Version: version, MIMEType: "text/plain; charset=utf-8", Content: strings.NewReader(artifact.part.Text)}, nil
+}
+
+// LoadStream implements [artifact.StreamLoader]. The content is read from
+// memory and implements [io.Seeker].
+func (s *inMemoryService) LoadStream(ctx context.Context, req *LoadRequest) (*LoadStreamResponse, error) {
+	version, artifact, err := s.lookup(req)
+	if err != nil {
+		return nil, err
+	}
+	resp := newLoadStreamResponse(version, artifact.part)
+	resp.Metadata = maps.Clone(artifact.metadata)
+	return resp, nil
+}
+
+// lookup returns the part of an artifact version, the latest one if
+// req.Version is unset.
+func (s *inMemoryService) lookup(req *LoadRequest) (int64, *storedArtifact, error) {
+	err := req.Validate()
+	if err != nil {
+		return 0, nil, fmt.Errorf("request validation failed: %w", err)
+	}
+	appName, userID, sessionID, fileName := req.AppName, req.UserID, req.SessionID, req.FileName
+	if fileHasUserNamespace(fileName) {
+		sessionID = userScopedArtifactKey
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	version := req.Version
+	var artifact *storedArtifact
+	var ok bool
+	if version > 0 {
+		artifact, ok = s.get(appName, userID, sessionID, fileName, version)
+	} else {
+		version, artifact, ok = s.find(appName, userID, sessionID, fileName)
+	}
+	if !ok {
+		return 0, nil, fmt.Errorf("artifact not found: %w", fs.ErrNotExist)
+	}
+	return version, artifact, nil
 }
 
 // List implements [artifact.Service]
@@ -285,4 +350,98 @@ func (s *inMemoryService) Versions(ctx context.Context, req *VersionsRequest) (*
 	return &VersionsResponse{Versions: versions}, nil
 }
 
-var _ Service = (*inMemoryService)(nil)
+// DeleteUserData implements [artifact.UserDataDeleter]
+func (s *inMemoryService) DeleteUserData(ctx context.Context, req *DeleteUserDataRequest) (*DeleteUserDataResponse, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("request validation failed: %w", err)
+	}
+	appName, userID := req.AppName, req.UserID
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// The range covers the sessions of the user and the user scope.
+	lo := artifactKey{AppName: appName, UserID: userID}.Encode()
+	hi := artifactKey{AppName: appName, UserID: userID + "\x00"}.Encode()
+	var keys []string
+	for k := range s.artifacts.Scan(lo, hi) {
+		var key artifactKey
+		if err := key.Decode(k); err != nil || key.AppName != appName || key.UserID != userID {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	for _, k := range keys {
+		s.artifacts.Delete(k)
+	}
+	return &DeleteUserDataResponse{DeletedVersions: len(keys)}, nil
+}
+
+// GetUsage implements [artifact.UsageReporter]
+func (s *inMemoryService) GetUsage(ctx context.Context, req *GetUsageRequest) (*GetUsageResponse, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("request validation failed: %w", err)
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// The range covers the sessions of the user and the user scope.
+	lo := artifactKey{AppName: req.AppName, UserID: req.UserID}.Encode()
+	hi := artifactKey{AppName: req.AppName, UserID: req.UserID + "\x00"}.Encode()
+	usage := &GetUsageResponse{}
+	for key, val := range s.scan(lo, hi) {
+		if key.AppName != req.AppName || key.UserID != req.UserID {
+			continue
+		}
+		usage.Versions++
+		usage.Bytes += PartSize(val.part)
+	}
+	return usage, nil
+}
+
+// Walk implements [Walker]. The versions are those stored when Walk is
+// called.
+func (s *inMemoryService) Walk(ctx context.Context) iter.Seq2[*VersionInfo, error] {
+	return func(yield func(*VersionInfo, error) bool) {
+		s.mu.RLock()
+		var versions []*VersionInfo
+		for k, val := range s.artifacts.All() {
+			var key artifactKey
+			if err := key.Decode(k); err != nil {
+				continue
+			}
+			if fileHasUserNamespace(key.FileName) {
+				key.SessionID = ""
+			}
+			versions = append(versions, &VersionInfo{
+				AppName:    key.AppName,
+				UserID:     key.UserID,
+				SessionID:  key.SessionID,
+				FileName:   key.FileName,
+				Version:    key.Version,
+				Size:       PartSize(val.part),
+				CreateTime: val.created,
+			})
+		}
+		s.mu.RUnlock()
+		for _, version := range versions {
+			if err := ctx.Err(); err != nil {
+				yield(nil, err)
+				return
+			}
+			if !yield(version, nil) {
+				return
+			}
+		}
+	}
+}
+
+var (
+	_ Service         = (*inMemoryService)(nil)
+	_ Opener          = (*inMemoryService)(nil)
+	_ StreamLoader    = (*inMemoryService)(nil)
+	_ UserDataDeleter = (*inMemoryService)(nil)
+	_ UsageReporter   = (*inMemoryService)(nil)
+	_ Walker          = (*inMemoryService)(nil)
+)
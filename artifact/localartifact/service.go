@@ -0,0 +1,691 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package localartifact provides an [artifact.Service] storing the artifacts
+// in a local directory, which is convenient to inspect them during
+// development.
+//
+// Every version of an artifact is a file, next to a JSON sidecar holding its
+// content type:
+//
+//	root/app/user/session/file/v1
+//	root/app/user/session/file/v1.json
+//	root/app/user/user/user:file/v1
+//
+// All the accesses go through an [os.Root], so that neither the names of the
+// requests nor symbolic links can reach files outside of the root directory.
+package localartifact
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"iter"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/artifact"
+)
+
+const (
+	// userScopedDir is the directory replacing the session of the user-scoped
+	// artifacts.
+	userScopedDir = "user"
+	// sidecarExt is the extension of the sidecar of a version.
+	sidecarExt = ".json"
+	// dirBatchSize is the number of directory entries read at once.
+	dirBatchSize = 64
+)
+
+// sidecar is the metadata of a version. It is written once the content is,
+// so the versions without a sidecar are ignored.
+type sidecar struct {
+	MIMEType    string `json:"mimeType"`
+	DisplayName string `json:"displayName,omitempty"`
+	// Text reports that the artifact was saved as a text part.
+	Text     bool              `json:"text,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// localService is a local filesystem implementation of the Service.
+type localService struct {
+	root *os.Root
+}
+
+// NewService creates a service storing the artifacts under rootDir, which is
+// created if needed. The directory stays open for the life of the process.
+func NewService(rootDir string) (artifact.Service, error) {
+	if err := os.MkdirAll(rootDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create the root directory: %w", err)
+	}
+	root, err := os.OpenRoot(rootDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the root directory: %w", err)
+	}
+	return &localService{root: root}, nil
+}
+
+// fileHasUserNamespace checks if a filename indicates a user-namespaced artifact.
+func fileHasUserNamespace(filename string) bool {
+	return strings.HasPrefix(filename, "user:")
+}
+
+// validatePathSegment checks that name is a single path segment, which can't
+// designate a parent directory.
+func validatePathSegment(field, name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, "/\\\x00") {
+		return fmt.Errorf("invalid %s %q: not usable as a path segment", field, name)
+	}
+	return nil
+}
+
+// userDir returns the directory of the artifacts of a user.
+func userDir(appName, userID string) (string, error) {
+	if err := validatePathSegment("app name", appName); err != nil {
+		return "", err
+	}
+	if err := validatePathSegment("user ID", userID); err != nil {
+		return "", err
+	}
+	return filepath.Join(appName, userID), nil
+}
+
+// sessionDir returns the directory of the artifacts of a session.
+func sessionDir(appName, userID, sessionID string) (string, error) {
+	dir, err := userDir(appName, userID)
+	if err != nil {
+		return "", err
+	}
+	if err := validatePathSegment("session ID", sessionID); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, sessionID), nil
+}
+
+// artifactDir returns the directory of the versions of an artifact.
+func artifactDir(appName, userID, sessionID, fileName string) (string, error) {
+	if fileHasUserNamespace(fileName) {
+		sessionID = userScopedDir
+	}
+	dir, err := sessionDir(appName, userID, sessionID)
+	if err != nil {
+		return "", err
+	}
+	if err := validatePathSegment("file name", fileName); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, fileName), nil
+}
+
+func versionFile(version int64) string {
+	return "v" + strconv.FormatInt(version, 10)
+}
+
+// parseVersion returns the version of the content file or the sidecar
+// called name.
+func parseVersion(name string) (version int64, isSidecar, ok bool) {
+	name, isSidecar = strings.CutSuffix(name, sidecarExt)
+	digits, found := strings.CutPrefix(name, "v")
+	if !found {
+		return 0, false, false
+	}
+	version, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, false, false
+	}
+	return version, isSidecar, true
+}
+
+// mkdirAll creates dir and its missing parents.
+func (s *localService) mkdirAll(dir string) error {
+	var path string
+	for _, segment := range strings.Split(dir, string(filepath.Separator)) {
+		path = filepath.Join(path, segment)
+		if err := s.root.Mkdir(path, 0o755); err != nil && !errors.Is(err, fs.ErrExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// readDir calls fn with the entries of dir, reading them by batches. A
+// missing directory has no entries. It stops at the first error of fn, and
+// errStop stops it without error.
+func (s *localService) readDir(dir string, fn func(fs.DirEntry) error) (err error) {
+	f, err := s.root.Open(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := f.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}()
+	for {
+		entries, err := f.ReadDir(dirBatchSize)
+		for _, entry := range entries {
+			if err := fn(entry); err != nil {
+				if err == errStop {
+					return nil
+				}
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// errStop is returned by the callbacks of readDir to stop reading.
+var errStop = errors.New("stop")
+
+// removeAll removes dir and its content, and returns the number of removed
+// versions.
+func (s *localService) removeAll(dir string) (int, error) {
+	removed := 0
+	var names []string
+	err := s.readDir(dir, func(entry fs.DirEntry) error {
+		if entry.IsDir() {
+			n, err := s.removeAll(filepath.Join(dir, entry.Name()))
+			removed += n
+			return err
+		}
+		names = append(names, entry.Name())
+		return nil
+	})
+	if err != nil {
+		return removed, err
+	}
+	for _, name := range names {
+		if err := s.root.Remove(filepath.Join(dir, name)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return removed, err
+		}
+		if _, isSidecar, ok := parseVersion(name); ok && isSidecar {
+			removed++
+		}
+	}
+	if err := s.root.Remove(dir); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return removed, err
+	}
+	return removed, nil
+}
+
+// Save implements [artifact.Service]
+func (s *localService) Save(ctx context.Context, req *artifact.SaveRequest) (*artifact.SaveResponse, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("request validation failed: %w", err)
+	}
+	var meta sidecar
+	var content io.Reader
+	if req.Part.InlineData != nil {
+		meta = sidecar{MIMEType: req.Part.InlineData.MIMEType, DisplayName: req.Part.InlineData.DisplayName}
+		content = bytes.NewReader(req.Part.InlineData.Data)
+	} else {
+		meta = sidecar{MIMEType: "text/plain", Text: true}
+		content = strings.NewReader(req.Part.Text)
+	}
+	meta.Metadata = req.Metadata
+	return s.save(req.AppName, req.UserID, req.SessionID, req.FileName, meta, content)
+}
+
+// SaveStream implements [artifact.StreamSaver]
+func (s *localService) SaveStream(ctx context.Context, req *artifact.SaveStreamRequest) (*artifact.SaveResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("request validation failed: %w", err)
+	}
+	return s.save(req.AppName, req.UserID, req.SessionID, req.FileName, sidecar{MIMEType: req.MIMEType, Metadata: req.Metadata}, req.Content)
+}
+
+func (s *localService) save(appName, userID, sessionID, fileName string, meta sidecar, content io.Reader) (_ *artifact.SaveResponse, err error) {
+	dir, err := artifactDir(appName, userID, sessionID, fileName)
+	if err != nil {
+		return nil, fmt.Errorf("request validation failed: %w", err)
+	}
+	if err := s.mkdirAll(dir); err != nil {
+		return nil, fmt.Errorf("failed to create the artifact directory: %w", err)
+	}
+
+	// The versions being written have no sidecar yet, but their number is
+	// taken as well.
+	var latest int64
+	if err := s.readDir(dir, func(entry fs.DirEntry) error {
+		if version, _, ok := parseVersion(entry.Name()); ok {
+			latest = max(latest, version)
+		}
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to list artifact versions: %w", err)
+	}
+
+	// The exclusive creation of the content file claims the version against
+	// the concurrent writers.
+	version := latest + 1
+	var f *os.File
+	for {
+		f, err = s.root.OpenFile(filepath.Join(dir, versionFile(version)), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if !errors.Is(err, fs.ErrExist) {
+			break
+		}
+		version++
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the artifact file: %w", err)
+	}
+	name := filepath.Join(dir, versionFile(version))
+	defer func() {
+		if err != nil {
+			s.root.Remove(name)
+		}
+	}()
+	if _, err := io.Copy(f, content); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to write the artifact file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write the artifact file: %w", err)
+	}
+
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode the artifact metadata: %w", err)
+	}
+	f, err = s.root.OpenFile(name+sidecarExt, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the artifact metadata: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		s.root.Remove(name + sidecarExt)
+		return nil, fmt.Errorf("failed to write the artifact metadata: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		s.root.Remove(name + sidecarExt)
+		return nil, fmt.Errorf("failed to write the artifact metadata: %w", err)
+	}
+	return &artifact.SaveResponse{Version: version}, nil
+}
+
+// Delete implements [artifact.Service]
+func (s *localService) Delete(ctx context.Context, req *artifact.DeleteRequest) error {
+	err := req.Validate()
+	if err != nil {
+		return fmt.Errorf("request validation failed: %w", err)
+	}
+	dir, err := artifactDir(req.AppName, req.UserID, req.SessionID, req.FileName)
+	if err != nil {
+		return fmt.Errorf("request validation failed: %w", err)
+	}
+
+	// Delete all versions
+	if req.Version == 0 {
+		if _, err := s.removeAll(dir); err != nil {
+			return fmt.Errorf("failed to delete artifact: %w", err)
+		}
+		return nil
+	}
+
+	// Delete specific version, starting with its sidecar for it to be
+	// ignored if the content can't be removed.
+	name := filepath.Join(dir, versionFile(req.Version))
+	for _, name := range []string{name + sidecarExt, name} {
+		if err := s.root.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to delete artifact: %w", err)
+		}
+	}
+	return nil
+}
+
+// Load implements [artifact.Service]
+func (s *localService) Load(ctx context.Context, req *artifact.LoadRequest) (_ *artifact.LoadResponse, err error) {
+	resp, meta, err := s.open(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := resp.Content.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}()
+	data, err := io.ReadAll(resp.Content)
+	if err != nil {
+		return nil, fmt.Errorf("could not read artifact: %w", err)
+	}
+	if meta.Text {
+		return &artifact.LoadResponse{Part: genai.NewPartFromText(string(data)), Metadata: meta.Metadata}, nil
+	}
+	return &artifact.LoadResponse{Part: &genai.Part{InlineData: &genai.Blob{
+		Data:        data,
+		MIMEType:    meta.MIMEType,
+		DisplayName: meta.DisplayName,
+	}}, Metadata: meta.Metadata}, nil
+}
+
+// LoadStream implements [artifact.StreamLoader]. The content is an
+// [*os.File], which implements [io.Seeker].
+func (s *localService) LoadStream(ctx context.Context, req *artifact.LoadRequest) (*artifact.LoadStreamResponse, error) {
+	resp, _, err := s.open(req)
+	return resp, err
+}
+
+// open opens the content of an artifact version and reads its sidecar.
+func (s *localService) open(req *artifact.LoadRequest) (*artifact.LoadStreamResponse, *sidecar, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, nil, fmt.Errorf("request validation failed: %w", err)
+	}
+	dir, err := artifactDir(req.AppName, req.UserID, req.SessionID, req.FileName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("request validation failed: %w", err)
+	}
+	version := req.Version
+	if version == 0 {
+		versions, err := s.versions(dir)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list artifact versions: %w", err)
+		}
+		if len(versions) == 0 {
+			return nil, nil, fmt.Errorf("artifact not found: %w", fs.ErrNotExist)
+		}
+		version = slices.Max(versions)
+	}
+
+	name := filepath.Join(dir, versionFile(version))
+	data, err := s.readFile(name + sidecarExt)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not read the metadata of artifact '%s': %w", name, err)
+	}
+	var meta sidecar
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, nil, fmt.Errorf("could not decode the metadata of artifact '%s': %w", name, err)
+	}
+	f, err := s.root.Open(name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not open artifact '%s': %w", name, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, fmt.Errorf("could not open artifact '%s': %w", name, err)
+	}
+	return &artifact.LoadStreamResponse{
+		Version:  version,
+		MIMEType: meta.MIMEType,
+		Text:     meta.Text,
+		Size:     info.Size(),
+		Metadata: meta.Metadata,
+		Content:  f,
+	}, &meta, nil
+}
+
+func (s *localService) readFile(name string) (_ []byte, err error) {
+	f, err := s.root.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := f.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}()
+	return io.ReadAll(f)
+}
+
+// versions returns the sorted versions of the artifact of dir, which have a
+// sidecar.
+func (s *localService) versions(dir string) ([]int64, error) {
+	versions := make([]int64, 0)
+	err := s.readDir(dir, func(entry fs.DirEntry) error {
+		if version, isSidecar, ok := parseVersion(entry.Name()); ok && isSidecar {
+			versions = append(versions, version)
+		}
+		return nil
+	})
+	slices.Sort(versions)
+	return versions, err
+}
+
+// hasVersion reports whether the artifact of dir has a version, reading just
+// enough of its entries to find one.
+func (s *localService) hasVersion(dir string) (bool, error) {
+	found := false
+	err := s.readDir(dir, func(entry fs.DirEntry) error {
+		if _, isSidecar, ok := parseVersion(entry.Name()); ok && isSidecar {
+			found = true
+			return errStop
+		}
+		return nil
+	})
+	return found, err
+}
+
+// fetchFilenames adds the names of the artifacts of dir which have a version
+// and match keep to filenamesSet.
+func (s *localService) fetchFilenames(dir string, keep func(string) bool, filenamesSet map[string]bool) error {
+	return s.readDir(dir, func(entry fs.DirEntry) error {
+		if !entry.IsDir() || !keep(entry.Name()) {
+			return nil
+		}
+		ok, err := s.hasVersion(filepath.Join(dir, entry.Name()))
+		if ok {
+			filenamesSet[entry.Name()] = true
+		}
+		return err
+	})
+}
+
+// List implements [artifact.Service]
+func (s *localService) List(ctx context.Context, req *artifact.ListRequest) (*artifact.ListResponse, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("request validation failed: %w", err)
+	}
+	dir, err := sessionDir(req.AppName, req.UserID, req.SessionID)
+	if err != nil {
+		return nil, fmt.Errorf("request validation failed: %w", err)
+	}
+	filenamesSet := map[string]bool{}
+
+	// Fetch filenames for the session.
+	if err := s.fetchFilenames(dir, func(string) bool { return true }, filenamesSet); err != nil {
+		return nil, fmt.Errorf("failed to fetch session filenames: %w", err)
+	}
+
+	// Fetch filenames for the user.
+	dir = filepath.Join(filepath.Dir(dir), userScopedDir)
+	if err := s.fetchFilenames(dir, fileHasUserNamespace, filenamesSet); err != nil {
+		return nil, fmt.Errorf("failed to fetch user filenames: %w", err)
+	}
+
+	filenames := slices.Collect(maps.Keys(filenamesSet))
+	sort.Strings(filenames)
+	return &artifact.ListResponse{FileNames: filenames}, nil
+}
+
+// Versions implements [artifact.Service] and returns an error if no versions are found.
+func (s *localService) Versions(ctx context.Context, req *artifact.VersionsRequest) (*artifact.VersionsResponse, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("request validation failed: %w", err)
+	}
+	dir, err := artifactDir(req.AppName, req.UserID, req.SessionID, req.FileName)
+	if err != nil {
+		return nil, fmt.Errorf("request validation failed: %w", err)
+	}
+	versions, err := s.versions(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list artifact versions: %w", err)
+	}
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("artifact not found: %w", fs.ErrNotExist)
+	}
+	return &artifact.VersionsResponse{Versions: versions}, nil
+}
+
+// DeleteUserData implements [artifact.UserDataDeleter]. It removes the
+// directory of the user, which holds the artifacts of the sessions of the
+// user and the user-scoped ones.
+func (s *localService) DeleteUserData(ctx context.Context, req *artifact.DeleteUserDataRequest) (*artifact.DeleteUserDataResponse, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("request validation failed: %w", err)
+	}
+	dir, err := userDir(req.AppName, req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("request validation failed: %w", err)
+	}
+	removed, err := s.removeAll(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete user artifacts: %w", err)
+	}
+	return &artifact.DeleteUserDataResponse{DeletedVersions: removed}, nil
+}
+
+// GetUsage implements [artifact.UsageReporter]. It walks the directory of
+// the user.
+func (s *localService) GetUsage(ctx context.Context, req *artifact.GetUsageRequest) (*artifact.GetUsageResponse, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("request validation failed: %w", err)
+	}
+	dir, err := userDir(req.AppName, req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("request validation failed: %w", err)
+	}
+	usage := &artifact.GetUsageResponse{}
+	if err := s.addUsage(dir, usage); err != nil {
+		return nil, fmt.Errorf("failed to get the usage of the user: %w", err)
+	}
+	return usage, nil
+}
+
+// addUsage adds the versions under dir to usage.
+func (s *localService) addUsage(dir string, usage *artifact.GetUsageResponse) error {
+	return s.readDir(dir, func(entry fs.DirEntry) error {
+		name := filepath.Join(dir, entry.Name())
+		if entry.IsDir() {
+			return s.addUsage(name, usage)
+		}
+		if _, isSidecar, ok := parseVersion(entry.Name()); !ok || !isSidecar {
+			return nil
+		}
+		info, err := s.root.Stat(strings.TrimSuffix(name, sidecarExt))
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		usage.Versions++
+		usage.Bytes += info.Size()
+		return nil
+	})
+}
+
+// errYieldStopped is returned by the callbacks of walk when the iteration
+// of Walk is stopped.
+var errYieldStopped = errors.New("yield stopped")
+
+// Walk implements [artifact.Walker]. The creation times of the versions are
+// the modification times of their sidecars, which are written last.
+func (s *localService) Walk(ctx context.Context) iter.Seq2[*artifact.VersionInfo, error] {
+	return func(yield func(*artifact.VersionInfo, error) bool) {
+		err := s.walk(ctx, ".", nil, func(info *artifact.VersionInfo) error {
+			if !yield(info, nil) {
+				return errYieldStopped
+			}
+			return nil
+		})
+		if err != nil && err != errYieldStopped {
+			yield(nil, err)
+		}
+	}
+}
+
+// walk calls fn for the versions under dir, whose path segments below the
+// root are segments: the app name, the user ID, the session ID and the file
+// name.
+func (s *localService) walk(ctx context.Context, dir string, segments []string, fn func(*artifact.VersionInfo) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if len(segments) < 4 {
+		return s.readDir(dir, func(entry fs.DirEntry) error {
+			if !entry.IsDir() {
+				return nil
+			}
+			return s.walk(ctx, filepath.Join(dir, entry.Name()), append(slices.Clip(segments), entry.Name()), fn)
+		})
+	}
+	return s.readDir(dir, func(entry fs.DirEntry) error {
+		version, isSidecar, ok := parseVersion(entry.Name())
+		if !ok || !isSidecar {
+			return nil
+		}
+		sidecarInfo, err := entry.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		contentInfo, err := s.root.Stat(filepath.Join(dir, versionFile(version)))
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		info := &artifact.VersionInfo{
+			AppName:    segments[0],
+			UserID:     segments[1],
+			SessionID:  segments[2],
+			FileName:   segments[3],
+			Version:    version,
+			Size:       contentInfo.Size(),
+			CreateTime: sidecarInfo.ModTime(),
+		}
+		if fileHasUserNamespace(info.FileName) {
+			info.SessionID = ""
+		}
+		return fn(info)
+	})
+}
+
+var (
+	_ artifact.Service         = (*localService)(nil)
+	_ artifact.StreamSaver     = (*localService)(nil)
+	_ artifact.StreamLoader    = (*localService)(nil)
+	_ artifact.UserDataDeleter = (*localService)(nil)
+	_ artifact.UsageReporter   = (*localService)(nil)
+	_ artifact.Walker          = (*localService)(nil)
+)
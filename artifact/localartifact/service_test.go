@@ -0,0 +1,193 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package localartifact
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/artifact"
+	"google.golang.org/adk/internal/artifact/tests"
+)
+
+func TestLocalArtifactService(t *testing.T) {
+	factory := func(t *testing.T) (artifact.Service, error) {
+		return NewService(t.TempDir())
+	}
+	tests.TestArtifactService(t, "Local", factory)
+}
+
+func TestLocalArtifactService_Layout(t *testing.T) {
+	rootDir := t.TempDir()
+	s, err := NewService(rootDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, save := range []struct {
+		fileName string
+		part     *genai.Part
+	}{
+		{"report.pdf", &genai.Part{InlineData: &genai.Blob{Data: []byte("%PDF"), MIMEType: "application/pdf", DisplayName: "Report"}}},
+		{"report.pdf", genai.NewPartFromBytes([]byte("%PDF-2"), "application/pdf")},
+		{"user:notes", genai.NewPartFromText("hello")},
+	} {
+		if _, err := s.Save(t.Context(), &artifact.SaveRequest{
+			AppName: "app", UserID: "user", SessionID: "session", FileName: save.fileName, Part: save.part,
+		}); err != nil {
+			t.Fatalf("Save(%s) failed: %v", save.fileName, err)
+		}
+	}
+
+	for _, tc := range []struct {
+		path        string
+		wantContent string
+		wantSidecar sidecar
+	}{
+		{"app/user/session/report.pdf/v1", "%PDF", sidecar{MIMEType: "application/pdf", DisplayName: "Report"}},
+		{"app/user/session/report.pdf/v2", "%PDF-2", sidecar{MIMEType: "application/pdf"}},
+		{"app/user/user/user:notes/v1", "hello", sidecar{MIMEType: "text/plain", Text: true}},
+	} {
+		content, err := os.ReadFile(filepath.Join(rootDir, filepath.FromSlash(tc.path)))
+		if err != nil {
+			t.Fatalf("ReadFile(%s) failed: %v", tc.path, err)
+		}
+		if string(content) != tc.wantContent {
+			t.Errorf("%s = %q, want %q", tc.path, content, tc.wantContent)
+		}
+		data, err := os.ReadFile(filepath.Join(rootDir, filepath.FromSlash(tc.path)+sidecarExt))
+		if err != nil {
+			t.Fatalf("ReadFile(%s.json) failed: %v", tc.path, err)
+		}
+		var got sidecar
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("Unmarshal(%s.json) failed: %v", tc.path, err)
+		}
+		if diff := cmp.Diff(tc.wantSidecar, got); diff != "" {
+			t.Errorf("%s.json mismatch (-want +got):\n%s", tc.path, diff)
+		}
+	}
+
+	got, err := s.Load(t.Context(), &artifact.LoadRequest{AppName: "app", UserID: "user", SessionID: "session", FileName: "report.pdf", Version: 1})
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	want := &genai.Part{InlineData: &genai.Blob{Data: []byte("%PDF"), MIMEType: "application/pdf", DisplayName: "Report"}}
+	if diff := cmp.Diff(want, got.Part); diff != "" {
+		t.Errorf("Load() mismatch (-want +got):\n%s", diff)
+	}
+
+	// Deleting all the versions removes the directory of the artifact.
+	if err := s.Delete(t.Context(), &artifact.DeleteRequest{AppName: "app", UserID: "user", SessionID: "session", FileName: "report.pdf"}); err != nil {
+		t.Fatalf("Delete() failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(rootDir, "app", "user", "session", "report.pdf")); !os.IsNotExist(err) {
+		t.Errorf("Stat() of the deleted artifact directory = %v, want not exist", err)
+	}
+}
+
+func TestLocalArtifactService_ListSkipsEmptyArtifacts(t *testing.T) {
+	s, err := NewService(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, fileName := range []string{"kept", "emptied"} {
+		if _, err := s.Save(t.Context(), &artifact.SaveRequest{
+			AppName: "app", UserID: "user", SessionID: "session", FileName: fileName, Part: genai.NewPartFromText("v1"),
+		}); err != nil {
+			t.Fatalf("Save(%s) failed: %v", fileName, err)
+		}
+	}
+	if err := s.Delete(t.Context(), &artifact.DeleteRequest{AppName: "app", UserID: "user", SessionID: "session", FileName: "emptied", Version: 1}); err != nil {
+		t.Fatalf("Delete() failed: %v", err)
+	}
+	got, err := s.List(t.Context(), &artifact.ListRequest{AppName: "app", UserID: "user", SessionID: "session"})
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	if diff := cmp.Diff([]string{"kept"}, got.FileNames); diff != "" {
+		t.Errorf("List() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestLocalArtifactService_PathTraversal(t *testing.T) {
+	parent := t.TempDir()
+	rootDir := filepath.Join(parent, "root")
+	s, err := NewService(rootDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A symbolic link to the outside of the root can't be followed either.
+	outside := filepath.Join(parent, "outside")
+	if err := os.Mkdir(outside, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(rootDir, "linked")); err != nil {
+		t.Skipf("symbolic links are not supported: %v", err)
+	}
+
+	for _, tc := range []struct {
+		name                                 string
+		appName, userID, sessionID, fileName string
+	}{
+		{"parent file name", "app", "user", "session", ".."},
+		{"current file name", "app", "user", "session", "."},
+		{"parent session", "app", "user", "..", "file"},
+		{"nested session", "app", "user", "../../etc", "passwd"},
+		{"parent user", "app", "..", "session", "file"},
+		{"backslash app", `..\..`, "user", "session", "file"},
+		{"symbolic link", "linked", "user", "session", "file"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := s.Save(t.Context(), &artifact.SaveRequest{
+				AppName: tc.appName, UserID: tc.userID, SessionID: tc.sessionID, FileName: tc.fileName,
+				Part: genai.NewPartFromText("pwned"),
+			}); err == nil {
+				t.Errorf("Save() succeeded, want an error")
+			}
+			if _, err := s.Load(t.Context(), &artifact.LoadRequest{
+				AppName: tc.appName, UserID: tc.userID, SessionID: tc.sessionID, FileName: tc.fileName,
+			}); err == nil {
+				t.Errorf("Load() succeeded, want an error")
+			}
+			if err := s.Delete(t.Context(), &artifact.DeleteRequest{
+				AppName: tc.appName, UserID: tc.userID, SessionID: tc.sessionID, FileName: tc.fileName,
+			}); err == nil {
+				t.Errorf("Delete() succeeded, want an error")
+			}
+		})
+	}
+
+	var escaped []string
+	if err := filepath.WalkDir(parent, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && d.Type()&fs.ModeSymlink == 0 {
+			escaped = append(escaped, path)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(escaped) > 0 {
+		t.Errorf("files were written: %v", escaped)
+	}
+}
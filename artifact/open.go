@@ -0,0 +1,38 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package artifact
+
+import (
+	"context"
+	"io"
+)
+
+// Opener is implemented by the services which give random access to the
+// content of an artifact, e.g. to serve byte ranges of it.
+type Opener interface {
+	// Open opens the content of an artifact version. If req.Version is unset,
+	// the latest version is opened.
+	Open(ctx context.Context, req *LoadRequest) (*OpenResponse, error)
+}
+
+// OpenResponse is the return type of [Opener.Open].
+type OpenResponse struct {
+	// Version is the opened version of the artifact.
+	Version int64
+	// MIMEType is the media type of the content.
+	MIMEType string
+	// Content is the content of the artifact.
+	Content io.ReadSeeker
+}
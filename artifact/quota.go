@@ -0,0 +1,246 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package artifact
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"iter"
+	"sync"
+)
+
+// ErrQuotaExceeded is matched by the errors of the saves rejected by the
+// services of [WithQuota], see [QuotaExceededError].
+var ErrQuotaExceeded = errors.New("artifact quota exceeded")
+
+// QuotaLimits are the limits of the artifacts stored for each user, in each
+// app. A zero limit is no limit.
+type QuotaLimits struct {
+	// MaxBytes is the maximum total size of the artifact versions of a user.
+	MaxBytes int64
+	// MaxVersions is the maximum number of artifact versions of a user.
+	MaxVersions int
+}
+
+// QuotaExceededError is returned by the saves which would make the usage of
+// a user exceed its limits. It matches [ErrQuotaExceeded].
+type QuotaExceededError struct {
+	AppName, UserID string
+	// Limits are the limits of the user.
+	Limits QuotaLimits
+	// Usage is the usage of the user before the save.
+	Usage GetUsageResponse
+	// Size is the size of the rejected content. For a stream, it is the size
+	// read until the limit was exceeded.
+	Size int64
+}
+
+func (e *QuotaExceededError) Error() string {
+	if e.Limits.MaxVersions > 0 && e.Usage.Versions >= e.Limits.MaxVersions {
+		return fmt.Sprintf("%v: user %q of app %q has %d artifact versions, the limit is %d", ErrQuotaExceeded, e.UserID, e.AppName, e.Usage.Versions, e.Limits.MaxVersions)
+	}
+	return fmt.Sprintf("%v: user %q of app %q uses %d bytes, saving %d more exceeds the limit of %d", ErrQuotaExceeded, e.UserID, e.AppName, e.Usage.Bytes, e.Size, e.Limits.MaxBytes)
+}
+
+// Is reports whether target is ErrQuotaExceeded.
+func (e *QuotaExceededError) Is(target error) bool {
+	return target == ErrQuotaExceeded
+}
+
+// quotaLockStripes is the number of locks serializing the saves of the
+// users, which are hashed to one of them.
+const quotaLockStripes = 64
+
+// quotaService is the [Service] of WithQuota.
+type quotaService struct {
+	inner  Service
+	usage  UsageReporter
+	limits QuotaLimits
+	locks  [quotaLockStripes]sync.Mutex
+}
+
+// quotaDeleterService is the quotaService of an inner [UserDataDeleter].
+type quotaDeleterService struct {
+	*quotaService
+}
+
+// WithQuota returns a service which stores the artifacts in inner, and
+// rejects the saves which would make the usage of a user exceed limits with
+// a [QuotaExceededError]. inner must implement [UsageReporter], whose usage
+// is read before every save.
+//
+// The saves of a user are serialized in the returned service, but the saves
+// done through other processes or services may still exceed the limits.
+//
+// Besides [Service], the returned service implements [StreamSaver], whose
+// content is read until the limit is exceeded, [StreamLoader], [UsageReporter],
+// [URLSigner] and [Walker], which fail if inner doesn't implement them, and,
+// if inner does, [UserDataDeleter].
+func WithQuota(inner Service, limits QuotaLimits) (Service, error) {
+	usage, ok := inner.(UsageReporter)
+	if !ok {
+		return nil, fmt.Errorf("artifact service %T doesn't report the usage of the users", inner)
+	}
+	if limits.MaxBytes < 0 || limits.MaxVersions < 0 {
+		return nil, fmt.Errorf("invalid quota limits %+v: limits can't be negative", limits)
+	}
+	s := &quotaService{inner: inner, usage: usage, limits: limits}
+	if _, ok := inner.(UserDataDeleter); ok {
+		return &quotaDeleterService{s}, nil
+	}
+	return s, nil
+}
+
+// lock locks the saves of a user, and returns the function unlocking them.
+func (s *quotaService) lock(appName, userID string) func() {
+	h := fnv.New32a()
+	h.Write([]byte(appName))
+	h.Write([]byte{0})
+	h.Write([]byte(userID))
+	mu := &s.locks[h.Sum32()%quotaLockStripes]
+	mu.Lock()
+	return mu.Unlock
+}
+
+// check returns the usage of a user, or the error of quota exceeded if a new
+// version of size bytes doesn't fit.
+func (s *quotaService) check(ctx context.Context, appName, userID string, size int64) (*GetUsageResponse, error) {
+	usage, err := s.usage.GetUsage(ctx, &GetUsageRequest{AppName: appName, UserID: userID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the artifact usage: %w", err)
+	}
+	if (s.limits.MaxVersions > 0 && usage.Versions >= s.limits.MaxVersions) ||
+		(s.limits.MaxBytes > 0 && usage.Bytes+size > s.limits.MaxBytes) {
+		return nil, &QuotaExceededError{AppName: appName, UserID: userID, Limits: s.limits, Usage: *usage, Size: size}
+	}
+	return usage, nil
+}
+
+// Save implements [Service]
+func (s *quotaService) Save(ctx context.Context, req *SaveRequest) (*SaveResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("request validation failed: %w", err)
+	}
+	defer s.lock(req.AppName, req.UserID)()
+	if _, err := s.check(ctx, req.AppName, req.UserID, PartSize(req.Part)); err != nil {
+		return nil, err
+	}
+	return s.inner.Save(ctx, req)
+}
+
+// SaveStream implements [StreamSaver]
+func (s *quotaService) SaveStream(ctx context.Context, req *SaveStreamRequest) (*SaveResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("request validation failed: %w", err)
+	}
+	defer s.lock(req.AppName, req.UserID)()
+	usage, err := s.check(ctx, req.AppName, req.UserID, 0)
+	if err != nil {
+		return nil, err
+	}
+	if s.limits.MaxBytes == 0 {
+		return SaveStream(ctx, s.inner, req)
+	}
+	limited := *req
+	limited.Content = &quotaReader{
+		r:         req.Content,
+		remaining: s.limits.MaxBytes - usage.Bytes,
+		err:       &QuotaExceededError{AppName: req.AppName, UserID: req.UserID, Limits: s.limits, Usage: *usage},
+	}
+	return SaveStream(ctx, s.inner, &limited)
+}
+
+// quotaReader fails with err once more than remaining bytes are read.
+type quotaReader struct {
+	r         io.Reader
+	remaining int64
+	read      int64
+	err       *QuotaExceededError
+}
+
+func (q *quotaReader) Read(p []byte) (int, error) {
+	n, err := q.r.Read(p)
+	q.read += int64(n)
+	if q.read > q.remaining {
+		q.err.Size = q.read
+		return n, q.err
+	}
+	return n, err
+}
+
+// Load implements [Service]
+func (s *quotaService) Load(ctx context.Context, req *LoadRequest) (*LoadResponse, error) {
+	return s.inner.Load(ctx, req)
+}
+
+// LoadStream implements [StreamLoader]
+func (s *quotaService) LoadStream(ctx context.Context, req *LoadRequest) (*LoadStreamResponse, error) {
+	return LoadStream(ctx, s.inner, req)
+}
+
+// Delete implements [Service]
+func (s *quotaService) Delete(ctx context.Context, req *DeleteRequest) error {
+	return s.inner.Delete(ctx, req)
+}
+
+// List implements [Service]
+func (s *quotaService) List(ctx context.Context, req *ListRequest) (*ListResponse, error) {
+	return s.inner.List(ctx, req)
+}
+
+// Versions implements [Service]
+func (s *quotaService) Versions(ctx context.Context, req *VersionsRequest) (*VersionsResponse, error) {
+	return s.inner.Versions(ctx, req)
+}
+
+// GetUsage implements [UsageReporter]
+func (s *quotaService) GetUsage(ctx context.Context, req *GetUsageRequest) (*GetUsageResponse, error) {
+	return s.usage.GetUsage(ctx, req)
+}
+
+// SignURL implements [URLSigner]. It returns an error wrapping
+// [errors.ErrUnsupported] if inner doesn't sign URLs.
+func (s *quotaService) SignURL(ctx context.Context, req *SignURLRequest) (*SignURLResponse, error) {
+	return SignURL(ctx, s.inner, req)
+}
+
+// Walk implements [Walker]. It fails with an error wrapping
+// [errors.ErrUnsupported] if inner doesn't enumerate its versions.
+func (s *quotaService) Walk(ctx context.Context) iter.Seq2[*VersionInfo, error] {
+	if walker, ok := s.inner.(Walker); ok {
+		return walker.Walk(ctx)
+	}
+	return func(yield func(*VersionInfo, error) bool) {
+		yield(nil, fmt.Errorf("artifact service %T doesn't enumerate its versions: %w", s.inner, errors.ErrUnsupported))
+	}
+}
+
+// DeleteUserData implements [UserDataDeleter]
+func (s *quotaDeleterService) DeleteUserData(ctx context.Context, req *DeleteUserDataRequest) (*DeleteUserDataResponse, error) {
+	return s.inner.(UserDataDeleter).DeleteUserData(ctx, req)
+}
+
+var (
+	_ Service         = (*quotaService)(nil)
+	_ StreamSaver     = (*quotaService)(nil)
+	_ StreamLoader    = (*quotaService)(nil)
+	_ UsageReporter   = (*quotaService)(nil)
+	_ URLSigner       = (*quotaService)(nil)
+	_ Walker          = (*quotaService)(nil)
+	_ UserDataDeleter = (*quotaDeleterService)(nil)
+)
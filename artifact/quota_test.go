@@ -0,0 +1,181 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package artifact_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/artifact"
+	"google.golang.org/adk/internal/artifact/tests"
+)
+
+func TestWithQuota(t *testing.T) {
+	factory := func(t *testing.T) (artifact.Service, error) {
+		return artifact.WithQuota(artifact.InMemoryService(), artifact.QuotaLimits{MaxBytes: 1 << 20, MaxVersions: 100})
+	}
+	tests.TestArtifactService(t, "Quota", factory)
+}
+
+func TestWithQuota_Errors(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		inner  artifact.Service
+		limits artifact.QuotaLimits
+	}{
+		{"no usage reporter", plainService{artifact.InMemoryService()}, artifact.QuotaLimits{MaxBytes: 10}},
+		{"negative bytes", artifact.InMemoryService(), artifact.QuotaLimits{MaxBytes: -1}},
+		{"negative versions", artifact.InMemoryService(), artifact.QuotaLimits{MaxVersions: -1}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := artifact.WithQuota(tc.inner, tc.limits); err == nil {
+				t.Errorf("WithQuota() succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestWithQuota_Limits(t *testing.T) {
+	save := func(s artifact.Service, userID, fileName, data string) error {
+		_, err := s.Save(t.Context(), &artifact.SaveRequest{
+			AppName: "app", UserID: userID, SessionID: "session", FileName: fileName,
+			Part: genai.NewPartFromBytes([]byte(data), "text/plain"),
+		})
+		return err
+	}
+	saveStream := func(s artifact.Service, userID, fileName, data string) error {
+		_, err := s.(artifact.StreamSaver).SaveStream(t.Context(), &artifact.SaveStreamRequest{
+			AppName: "app", UserID: userID, SessionID: "session", FileName: fileName,
+			MIMEType: "text/plain", Content: strings.NewReader(data),
+		})
+		return err
+	}
+
+	for _, tc := range []struct {
+		name    string
+		limits  artifact.QuotaLimits
+		save    func(s artifact.Service, userID, fileName, data string) error
+		wantErr *artifact.QuotaExceededError
+	}{
+		{
+			name:   "bytes",
+			limits: artifact.QuotaLimits{MaxBytes: 10},
+			save:   save,
+			wantErr: &artifact.QuotaExceededError{
+				AppName: "app", UserID: "user", Limits: artifact.QuotaLimits{MaxBytes: 10},
+				Usage: artifact.GetUsageResponse{Versions: 2, Bytes: 8}, Size: 4,
+			},
+		},
+		{
+			name:   "bytes of a stream",
+			limits: artifact.QuotaLimits{MaxBytes: 10},
+			save:   saveStream,
+			wantErr: &artifact.QuotaExceededError{
+				AppName: "app", UserID: "user", Limits: artifact.QuotaLimits{MaxBytes: 10},
+				Usage: artifact.GetUsageResponse{Versions: 2, Bytes: 8}, Size: 4,
+			},
+		},
+		{
+			name:   "versions",
+			limits: artifact.QuotaLimits{MaxVersions: 2},
+			save:   save,
+			wantErr: &artifact.QuotaExceededError{
+				AppName: "app", UserID: "user", Limits: artifact.QuotaLimits{MaxVersions: 2},
+				Usage: artifact.GetUsageResponse{Versions: 2, Bytes: 8}, Size: 4,
+			},
+		},
+		{
+			name:   "versions of a stream",
+			limits: artifact.QuotaLimits{MaxVersions: 2},
+			save:   saveStream,
+			wantErr: &artifact.QuotaExceededError{
+				AppName: "app", UserID: "user", Limits: artifact.QuotaLimits{MaxVersions: 2},
+				Usage: artifact.GetUsageResponse{Versions: 2, Bytes: 8},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := artifact.WithQuota(artifact.InMemoryService(), tc.limits)
+			if err != nil {
+				t.Fatalf("WithQuota() failed: %v", err)
+			}
+			if err := tc.save(s, "user", "file", "1234"); err != nil {
+				t.Fatalf("save(file@v1) failed: %v", err)
+			}
+			if err := tc.save(s, "user", "file", "5678"); err != nil {
+				t.Fatalf("save(file@v2) failed: %v", err)
+			}
+
+			err = tc.save(s, "user", "other", "abcd")
+			if !errors.Is(err, artifact.ErrQuotaExceeded) {
+				t.Fatalf("save(other) error = %v, want %v", err, artifact.ErrQuotaExceeded)
+			}
+			var quotaErr *artifact.QuotaExceededError
+			if !errors.As(err, &quotaErr) {
+				t.Fatalf("save(other) error = %v, want a *QuotaExceededError", err)
+			}
+			if diff := cmp.Diff(tc.wantErr, quotaErr); diff != "" {
+				t.Errorf("save(other) error mismatch (-want +got):\n%s", diff)
+			}
+			versions, err := s.Versions(t.Context(), &artifact.VersionsRequest{AppName: "app", UserID: "user", SessionID: "session", FileName: "other"})
+			if err == nil {
+				t.Errorf("Versions(other) = %v, want the rejected artifact not to be saved", versions.Versions)
+			}
+
+			// The quota is per user.
+			if err := tc.save(s, "user2", "other", "abcd"); err != nil {
+				t.Errorf("save(other) of another user failed: %v", err)
+			}
+
+			// Deleting frees the quota.
+			if err := s.Delete(t.Context(), &artifact.DeleteRequest{AppName: "app", UserID: "user", SessionID: "session", FileName: "file", Version: 1}); err != nil {
+				t.Fatalf("Delete(file@v1) failed: %v", err)
+			}
+			if err := tc.save(s, "user", "other", "abcd"); err != nil {
+				t.Errorf("save(other) after a delete failed: %v", err)
+			}
+		})
+	}
+}
+
+// usageOnlyService is a service reporting its usage, but not implementing
+// UserDataDeleter.
+type usageOnlyService struct {
+	artifact.Service
+	artifact.UsageReporter
+}
+
+func TestWithQuota_UserDataDeleter(t *testing.T) {
+	inner := artifact.InMemoryService()
+	s, err := artifact.WithQuota(inner, artifact.QuotaLimits{MaxBytes: 10})
+	if err != nil {
+		t.Fatalf("WithQuota() failed: %v", err)
+	}
+	if _, ok := s.(artifact.UserDataDeleter); !ok {
+		t.Errorf("WithQuota() of a UserDataDeleter isn't a UserDataDeleter")
+	}
+
+	s, err = artifact.WithQuota(usageOnlyService{inner, inner.(artifact.UsageReporter)}, artifact.QuotaLimits{MaxBytes: 10})
+	if err != nil {
+		t.Fatalf("WithQuota() failed: %v", err)
+	}
+	if _, ok := s.(artifact.UserDataDeleter); ok {
+		t.Errorf("WithQuota() of a service without DeleteUserData is a UserDataDeleter")
+	}
+}
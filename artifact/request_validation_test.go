@@ -122,6 +122,44 @@ func TestSaveRequest_Validate(t *testing.T) {
 			wantErr:    true,
 			wantErrMsg: "invalid name: filename cannot contain path separators",
 		},
+		{
+			name: "Valid metadata",
+			req: &SaveRequest{
+				AppName:   "MyApp",
+				UserID:    "user-123",
+				SessionID: "sess-abc",
+				FileName:  "file.txt",
+				Part:      genai.NewPartFromBytes([]byte("data"), "text/plain"),
+				Metadata:  map[string]string{"source": "upload", "caption_v2": "Any value, even UTF-8: é"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Metadata key with uppercase letters",
+			req: &SaveRequest{
+				AppName:   "MyApp",
+				UserID:    "user-123",
+				SessionID: "sess-abc",
+				FileName:  "file.txt",
+				Part:      genai.NewPartFromBytes([]byte("data"), "text/plain"),
+				Metadata:  map[string]string{"Source": "upload"},
+			},
+			wantErr:    true,
+			wantErrMsg: `invalid metadata key "Source": only lowercase letters, digits, '-' and '_' are allowed`,
+		},
+		{
+			name: "Metadata key with the reserved prefix",
+			req: &SaveRequest{
+				AppName:   "MyApp",
+				UserID:    "user-123",
+				SessionID: "sess-abc",
+				FileName:  "file.txt",
+				Part:      genai.NewPartFromBytes([]byte("data"), "text/plain"),
+				Metadata:  map[string]string{"adk-part-kind": "text"},
+			},
+			wantErr:    true,
+			wantErrMsg: `invalid metadata key "adk-part-kind": the "adk-" prefix is reserved`,
+		},
 	}
 	executeValidatorTestCases(t, "SaveRequest", testCases)
 }
@@ -0,0 +1,654 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package s3artifact provides an Amazon S3 [artifact.Service], which also
+// works with the S3-compatible stores like MinIO.
+//
+// Artifacts are stored as objects keyed by application name, user ID,
+// session ID, filename and version, like in the gcsartifact package:
+//
+//	app/user/session/file/version
+//	app/user/user/user:file/version
+//
+// The content larger than [Config.MultipartThreshold] is uploaded with the
+// multipart API, so that saving a stream never holds more than one part in
+// memory.
+package s3artifact
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"iter"
+	"maps"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/artifact"
+)
+
+const (
+	// MinPartSize is the smallest part size accepted by S3 for all the parts
+	// of a multipart upload but the last one.
+	MinPartSize = 5 << 20
+	// DefaultMultipartThreshold is the default of [Config.MultipartThreshold].
+	DefaultMultipartThreshold = 8 << 20
+
+	// metadataPartKind is the object metadata key recording how the part
+	// was provided, so that text parts are loaded back as text.
+	metadataPartKind = "adk-part-kind"
+	partKindText     = "text"
+
+	// maxDeleteBatch is the maximum number of keys of a DeleteObjects call.
+	maxDeleteBatch = 1000
+	// maxSaveAttempts is the number of versions Save tries when other
+	// writers concurrently save the same artifact.
+	maxSaveAttempts = 3
+)
+
+// errVersionConflict is returned when the version being saved was created
+// by another writer in the meantime.
+var errVersionConflict = errors.New("artifact version already exists")
+
+// Config is the configuration of the S3 service.
+type Config struct {
+	// Bucket is the name of the bucket storing the artifacts.
+	Bucket string
+	// AWSConfig configures the S3 client, e.g. its region, credentials and,
+	// for the S3-compatible stores, BaseEndpoint. It is usually loaded with
+	// config.LoadDefaultConfig of github.com/aws/aws-sdk-go-v2/config.
+	AWSConfig aws.Config
+	// UsePathStyle addresses the bucket in the path of the URLs instead of
+	// the host name, as MinIO requires.
+	UsePathStyle bool
+	// MultipartThreshold is the content size from which the multipart API
+	// is used, which is also the size of the uploaded parts. Defaults to
+	// DefaultMultipartThreshold. It must be at least MinPartSize.
+	MultipartThreshold int64
+}
+
+// s3Service is an Amazon S3 implementation of the Service.
+type s3Service struct {
+	bucket    string
+	client    s3API
+	presigner s3Presigner
+	partSize  int64
+}
+
+// NewService creates an S3 service for the bucket of cfg.
+func NewService(cfg Config) (artifact.Service, error) {
+	if cfg.Bucket == "" {
+		return nil, errors.New("bucket is required")
+	}
+	threshold := cfg.MultipartThreshold
+	if threshold == 0 {
+		threshold = DefaultMultipartThreshold
+	}
+	if threshold < MinPartSize {
+		return nil, fmt.Errorf("multipart threshold %d is smaller than the minimum part size %d", threshold, MinPartSize)
+	}
+	client := s3.NewFromConfig(cfg.AWSConfig, func(o *s3.Options) {
+		o.UsePathStyle = cfg.UsePathStyle
+	})
+	return &s3Service{bucket: cfg.Bucket, client: client, presigner: s3.NewPresignClient(client), partSize: threshold}, nil
+}
+
+// fileHasUserNamespace checks if a filename indicates a user-namespaced object.
+func fileHasUserNamespace(filename string) bool {
+	return strings.HasPrefix(filename, "user:")
+}
+
+// buildObjectKey constructs the key of an artifact version.
+func buildObjectKey(appName, userID, sessionID, fileName string, version int64) string {
+	return buildObjectKeyPrefix(appName, userID, sessionID, fileName) + strconv.FormatInt(version, 10)
+}
+
+func buildObjectKeyPrefix(appName, userID, sessionID, fileName string) string {
+	if fileHasUserNamespace(fileName) {
+		return fmt.Sprintf("%s/%s/user/%s/", appName, userID, fileName)
+	}
+	return fmt.Sprintf("%s/%s/%s/%s/", appName, userID, sessionID, fileName)
+}
+
+func buildSessionPrefix(appName, userID, sessionID string) string {
+	return fmt.Sprintf("%s/%s/%s/", appName, userID, sessionID)
+}
+
+func buildUserPrefix(appName, userID string) string {
+	return fmt.Sprintf("%s/%s/user/", appName, userID)
+}
+
+// Save implements [artifact.Service]
+func (s *s3Service) Save(ctx context.Context, req *artifact.SaveRequest) (*artifact.SaveResponse, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("request validation failed: %w", err)
+	}
+	var mimeType string
+	var data []byte
+	metadata := maps.Clone(req.Metadata)
+	if req.Part.InlineData != nil {
+		mimeType, data = req.Part.InlineData.MIMEType, req.Part.InlineData.Data
+	} else {
+		mimeType, data = "text/plain", []byte(req.Part.Text)
+		if metadata == nil {
+			metadata = map[string]string{}
+		}
+		metadata[metadataPartKind] = partKindText
+	}
+	// Unlike a stream, the content can be written again when another writer
+	// took the version first.
+	for attempt := 1; ; attempt++ {
+		resp, err := s.saveStream(ctx, req.AppName, req.UserID, req.SessionID, req.FileName, mimeType, metadata, bytes.NewReader(data))
+		if errors.Is(err, errVersionConflict) && attempt < maxSaveAttempts {
+			continue
+		}
+		return resp, err
+	}
+}
+
+// SaveStream implements [artifact.StreamSaver]
+func (s *s3Service) SaveStream(ctx context.Context, req *artifact.SaveStreamRequest) (*artifact.SaveResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("request validation failed: %w", err)
+	}
+	return s.saveStream(ctx, req.AppName, req.UserID, req.SessionID, req.FileName, req.MIMEType, req.Metadata, req.Content)
+}
+
+func (s *s3Service) saveStream(ctx context.Context, appName, userID, sessionID, fileName, mimeType string, metadata map[string]string, content io.Reader) (*artifact.SaveResponse, error) {
+	nextVersion := int64(1)
+	response, err := s.versions(ctx, &artifact.VersionsRequest{
+		AppName: appName, UserID: userID, SessionID: sessionID, FileName: fileName,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list artifact versions: %w", err)
+	}
+	if len(response.Versions) > 0 {
+		nextVersion = slices.Max(response.Versions) + 1
+	}
+	key := buildObjectKey(appName, userID, sessionID, fileName, nextVersion)
+
+	part := make([]byte, s.partSize)
+	n, err := io.ReadFull(content, part)
+	switch {
+	case err == io.EOF || err == io.ErrUnexpectedEOF:
+		// The content fits in one part, which is put as is.
+		_, err = s.client.PutObject(ctx, &s3.PutObjectInput{
+			Bucket:      aws.String(s.bucket),
+			Key:         aws.String(key),
+			Body:        bytes.NewReader(part[:n]),
+			ContentType: aws.String(mimeType),
+			Metadata:    metadata,
+			// Versions are never overwritten, even by concurrent writers.
+			IfNoneMatch: aws.String("*"),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to write object to S3: %w", conflictError(err, nextVersion))
+		}
+	case err != nil:
+		return nil, fmt.Errorf("failed to read artifact content: %w", err)
+	default:
+		if err := s.uploadParts(ctx, key, mimeType, metadata, part, content); err != nil {
+			return nil, conflictError(err, nextVersion)
+		}
+	}
+	return &artifact.SaveResponse{Version: nextVersion}, nil
+}
+
+// uploadParts uploads the content with the multipart API, starting with the
+// already read first part. The buffer of the part is reused for the next
+// ones, and the upload is aborted if any of them fails.
+func (s *s3Service) uploadParts(ctx context.Context, key, mimeType string, metadata map[string]string, part []byte, content io.Reader) (err error) {
+	upload, err := s.client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(key),
+		ContentType: aws.String(mimeType),
+		Metadata:    metadata,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create multipart upload: %w", err)
+	}
+	defer func() {
+		if err == nil {
+			return
+		}
+		// The parts of an aborted upload are not billed anymore, which is
+		// worth trying even when ctx is done.
+		if _, abortErr := s.client.AbortMultipartUpload(context.WithoutCancel(ctx), &s3.AbortMultipartUploadInput{
+			Bucket:   aws.String(s.bucket),
+			Key:      aws.String(key),
+			UploadId: upload.UploadId,
+		}); abortErr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to abort multipart upload: %w", abortErr))
+		}
+	}()
+
+	var completed []types.CompletedPart
+	n := len(part)
+	for partNumber := int32(1); n > 0; partNumber++ {
+		uploaded, err := s.client.UploadPart(ctx, &s3.UploadPartInput{
+			Bucket:     aws.String(s.bucket),
+			Key:        aws.String(key),
+			UploadId:   upload.UploadId,
+			PartNumber: aws.Int32(partNumber),
+			Body:       bytes.NewReader(part[:n]),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to upload part %d: %w", partNumber, err)
+		}
+		completed = append(completed, types.CompletedPart{
+			ETag:           uploaded.ETag,
+			PartNumber:     aws.Int32(partNumber),
+			ChecksumCRC32:  uploaded.ChecksumCRC32,
+			ChecksumCRC32C: uploaded.ChecksumCRC32C,
+			ChecksumSHA1:   uploaded.ChecksumSHA1,
+			ChecksumSHA256: uploaded.ChecksumSHA256,
+		})
+		n, err = io.ReadFull(content, part)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return fmt.Errorf("failed to read artifact content: %w", err)
+		}
+	}
+
+	if _, err := s.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(s.bucket),
+		Key:             aws.String(key),
+		UploadId:        upload.UploadId,
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: completed},
+		IfNoneMatch:     aws.String("*"),
+	}); err != nil {
+		return fmt.Errorf("failed to complete multipart upload: %w", err)
+	}
+	return nil
+}
+
+// conflictError wraps err with errVersionConflict if it reports that the
+// object of the version already exists.
+func conflictError(err error, version int64) error {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.ErrorCode() {
+		case "PreconditionFailed", "ConditionalRequestConflict":
+			return fmt.Errorf("version %d: %w: %w", version, errVersionConflict, err)
+		}
+	}
+	return err
+}
+
+// Delete implements [artifact.Service]
+func (s *s3Service) Delete(ctx context.Context, req *artifact.DeleteRequest) error {
+	err := req.Validate()
+	if err != nil {
+		return fmt.Errorf("request validation failed: %w", err)
+	}
+	appName, userID, sessionID, fileName := req.AppName, req.UserID, req.SessionID, req.FileName
+
+	// Delete specific version
+	if req.Version != 0 {
+		_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+			Bucket: aws.String(s.bucket),
+			Key:    aws.String(buildObjectKey(appName, userID, sessionID, fileName, req.Version)),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete artifact: %w", err)
+		}
+		return nil
+	}
+
+	// Delete all versions
+	keys, err := s.listKeys(ctx, buildObjectKeyPrefix(appName, userID, sessionID, fileName))
+	if err != nil {
+		return fmt.Errorf("failed to fetch versions on delete artifact: %w", err)
+	}
+	if err := s.deleteKeys(ctx, keys); err != nil {
+		return fmt.Errorf("failed to delete artifact: %w", err)
+	}
+	return nil
+}
+
+// Load implements [artifact.Service]
+func (s *s3Service) Load(ctx context.Context, req *artifact.LoadRequest) (_ *artifact.LoadResponse, err error) {
+	resp, err := s.LoadStream(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := resp.Content.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to close object body: %w", closeErr)
+		}
+	}()
+	data, err := io.ReadAll(resp.Content)
+	if err != nil {
+		return nil, fmt.Errorf("could not read data from object: %w", err)
+	}
+
+	if resp.Text {
+		return &artifact.LoadResponse{Part: genai.NewPartFromText(string(data)), Metadata: resp.Metadata}, nil
+	}
+	return &artifact.LoadResponse{Part: genai.NewPartFromBytes(data, resp.MIMEType), Metadata: resp.Metadata}, nil
+}
+
+// LoadStream implements [artifact.StreamLoader]. The content is the body of
+// the response of S3, which doesn't implement [io.Seeker].
+func (s *s3Service) LoadStream(ctx context.Context, req *artifact.LoadRequest) (*artifact.LoadStreamResponse, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("request validation failed: %w", err)
+	}
+	version := req.Version
+	if version == 0 {
+		response, err := s.versions(ctx, &artifact.VersionsRequest{
+			AppName: req.AppName, UserID: req.UserID, SessionID: req.SessionID, FileName: req.FileName,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list artifact versions: %w", err)
+		}
+		if len(response.Versions) == 0 {
+			return nil, fmt.Errorf("artifact not found: %w", fs.ErrNotExist)
+		}
+		version = slices.Max(response.Versions)
+	}
+
+	key := buildObjectKey(req.AppName, req.UserID, req.SessionID, req.FileName, version)
+	object, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return nil, fmt.Errorf("artifact '%s' not found: %w", key, fs.ErrNotExist)
+		}
+		return nil, fmt.Errorf("could not get object '%s': %w", key, err)
+	}
+	size := int64(-1)
+	if object.ContentLength != nil {
+		size = *object.ContentLength
+	}
+	return &artifact.LoadStreamResponse{
+		Version:  version,
+		MIMEType: aws.ToString(object.ContentType),
+		Text:     object.Metadata[metadataPartKind] == partKindText,
+		Size:     size,
+		Metadata: userMetadata(object.Metadata),
+		Content:  object.Body,
+	}, nil
+}
+
+// userMetadata returns the metadata of an object without the keys set by the
+// service.
+func userMetadata(metadata map[string]string) map[string]string {
+	metadata = maps.Clone(metadata)
+	delete(metadata, metadataPartKind)
+	if len(metadata) == 0 {
+		return nil
+	}
+	return metadata
+}
+
+// listKeys returns the keys of all the objects under prefix.
+func (s *s3Service) listKeys(ctx context.Context, prefix string) ([]string, error) {
+	objects, err := s.listObjects(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, len(objects))
+	for i, object := range objects {
+		keys[i] = aws.ToString(object.Key)
+	}
+	return keys, nil
+}
+
+// listObjects returns all the objects under prefix.
+func (s *s3Service) listObjects(ctx context.Context, prefix string) ([]types.Object, error) {
+	var objects []types.Object
+	pages := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(prefix),
+	})
+	for pages.HasMorePages() {
+		page, err := pages.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing objects: %w", err)
+		}
+		objects = append(objects, page.Contents...)
+	}
+	return objects, nil
+}
+
+// deleteKeys deletes the objects of keys, in batches of maxDeleteBatch keys.
+func (s *s3Service) deleteKeys(ctx context.Context, keys []string) error {
+	for batch := range slices.Chunk(keys, maxDeleteBatch) {
+		objects := make([]types.ObjectIdentifier, len(batch))
+		for i, key := range batch {
+			objects[i] = types.ObjectIdentifier{Key: aws.String(key)}
+		}
+		resp, err := s.client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+			Bucket: aws.String(s.bucket),
+			Delete: &types.Delete{Objects: objects, Quiet: aws.Bool(true)},
+		})
+		if err != nil {
+			return err
+		}
+		if len(resp.Errors) > 0 {
+			e := resp.Errors[0]
+			return fmt.Errorf("failed to delete %d objects, first %s: %s", len(resp.Errors), aws.ToString(e.Key), aws.ToString(e.Message))
+		}
+	}
+	return nil
+}
+
+// fetchFilenamesFromPrefix adds the filenames of the objects under prefix to
+// filenamesSet.
+func (s *s3Service) fetchFilenamesFromPrefix(ctx context.Context, prefix string, filenamesSet map[string]bool) error {
+	keys, err := s.listKeys(ctx, prefix)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		// Extract filename from key: appName/userId/sessionId/filename/version or appName/userId/user/filename/version
+		segments := strings.Split(key, "/")
+		if len(segments) < 2 {
+			return fmt.Errorf("error listing objects: incorrect number of segments in key %q", key)
+		}
+		filenamesSet[segments[len(segments)-2]] = true
+	}
+	return nil
+}
+
+// List implements [artifact.Service]
+func (s *s3Service) List(ctx context.Context, req *artifact.ListRequest) (*artifact.ListResponse, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("request validation failed: %w", err)
+	}
+	filenamesSet := map[string]bool{}
+
+	// Fetch filenames for the session.
+	err = s.fetchFilenamesFromPrefix(ctx, buildSessionPrefix(req.AppName, req.UserID, req.SessionID), filenamesSet)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch session filenames: %w", err)
+	}
+
+	// Fetch filenames for the user.
+	err = s.fetchFilenamesFromPrefix(ctx, buildUserPrefix(req.AppName, req.UserID), filenamesSet)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user filenames: %w", err)
+	}
+
+	filenames := slices.Collect(maps.Keys(filenamesSet))
+	sort.Strings(filenames)
+	return &artifact.ListResponse{FileNames: filenames}, nil
+}
+
+// versions internal function that does not return error if versions are empty
+func (s *s3Service) versions(ctx context.Context, req *artifact.VersionsRequest) (*artifact.VersionsResponse, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("request validation failed: %w", err)
+	}
+	keys, err := s.listKeys(ctx, buildObjectKeyPrefix(req.AppName, req.UserID, req.SessionID, req.FileName))
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]int64, 0, len(keys))
+	for _, key := range keys {
+		version, err := strconv.ParseInt(key[strings.LastIndex(key, "/")+1:], 10, 64)
+		// if the file version is not convertible to number, just ignore it
+		if err != nil {
+			continue
+		}
+		versions = append(versions, version)
+	}
+	return &artifact.VersionsResponse{Versions: versions}, nil
+}
+
+// Versions implements [artifact.Service] and returns an error if no versions are found.
+func (s *s3Service) Versions(ctx context.Context, req *artifact.VersionsRequest) (*artifact.VersionsResponse, error) {
+	response, err := s.versions(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if len(response.Versions) == 0 {
+		return nil, fmt.Errorf("artifact not found: %w", fs.ErrNotExist)
+	}
+	return response, nil
+}
+
+// DeleteUserData implements [artifact.UserDataDeleter]. It deletes all the
+// objects under the prefix of the user, which holds the artifacts of the
+// sessions of the user and the user-scoped ones.
+func (s *s3Service) DeleteUserData(ctx context.Context, req *artifact.DeleteUserDataRequest) (*artifact.DeleteUserDataResponse, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("request validation failed: %w", err)
+	}
+	keys, err := s.listKeys(ctx, fmt.Sprintf("%s/%s/", req.AppName, req.UserID))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.deleteKeys(ctx, keys); err != nil {
+		return nil, fmt.Errorf("failed to delete user artifacts: %w", err)
+	}
+	return &artifact.DeleteUserDataResponse{DeletedVersions: len(keys)}, nil
+}
+
+// GetUsage implements [artifact.UsageReporter]. It lists all the objects
+// under the prefix of the user.
+func (s *s3Service) GetUsage(ctx context.Context, req *artifact.GetUsageRequest) (*artifact.GetUsageResponse, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("request validation failed: %w", err)
+	}
+	objects, err := s.listObjects(ctx, fmt.Sprintf("%s/%s/", req.AppName, req.UserID))
+	if err != nil {
+		return nil, err
+	}
+	usage := &artifact.GetUsageResponse{Versions: len(objects)}
+	for _, object := range objects {
+		usage.Bytes += aws.ToInt64(object.Size)
+	}
+	return usage, nil
+}
+
+// SignURL implements [artifact.URLSigner]. The URLs are presigned with the
+// credentials of the client, and stop being valid when those expire if they
+// are temporary.
+func (s *s3Service) SignURL(ctx context.Context, req *artifact.SignURLRequest) (*artifact.SignURLResponse, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("request validation failed: %w", err)
+	}
+	version, err := artifact.SignedVersion(ctx, s, req)
+	if err != nil {
+		return nil, err
+	}
+	expires := time.Now().Add(req.TTL)
+	key := buildObjectKey(req.AppName, req.UserID, req.SessionID, req.FileName, version)
+	presigned, err := s.presigner.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	}, s3.WithPresignExpires(req.TTL))
+	if err != nil {
+		return nil, fmt.Errorf("failed to presign the URL of object %q: %w", key, err)
+	}
+	return &artifact.SignURLResponse{Version: version, URL: presigned.URL, Expires: expires}, nil
+}
+
+// Walk implements [artifact.Walker]. It lists all the objects of the bucket,
+// ignoring those which aren't artifact versions. The creation times are the
+// last modification times of the objects, which are never modified.
+func (s *s3Service) Walk(ctx context.Context) iter.Seq2[*artifact.VersionInfo, error] {
+	return func(yield func(*artifact.VersionInfo, error) bool) {
+		pages := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{Bucket: aws.String(s.bucket)})
+		for pages.HasMorePages() {
+			page, err := pages.NextPage(ctx)
+			if err != nil {
+				yield(nil, fmt.Errorf("error listing objects: %w", err))
+				return
+			}
+			for _, object := range page.Contents {
+				info, ok := parseObjectKey(aws.ToString(object.Key))
+				if !ok {
+					continue
+				}
+				info.Size = aws.ToInt64(object.Size)
+				info.CreateTime = aws.ToTime(object.LastModified)
+				if !yield(info, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// parseObjectKey returns the artifact version of a key built by
+// buildObjectKey.
+func parseObjectKey(key string) (*artifact.VersionInfo, bool) {
+	segments := strings.Split(key, "/")
+	if len(segments) != 5 {
+		return nil, false
+	}
+	version, err := strconv.ParseInt(segments[4], 10, 64)
+	if err != nil {
+		return nil, false
+	}
+	info := &artifact.VersionInfo{AppName: segments[0], UserID: segments[1], SessionID: segments[2], FileName: segments[3], Version: version}
+	if fileHasUserNamespace(info.FileName) {
+		info.SessionID = ""
+	}
+	return info, true
+}
+
+var (
+	_ artifact.Service         = (*s3Service)(nil)
+	_ artifact.StreamSaver     = (*s3Service)(nil)
+	_ artifact.StreamLoader    = (*s3Service)(nil)
+	_ artifact.UserDataDeleter = (*s3Service)(nil)
+	_ artifact.UsageReporter   = (*s3Service)(nil)
+	_ artifact.URLSigner       = (*s3Service)(nil)
+	_ artifact.Walker          = (*s3Service)(nil)
+)
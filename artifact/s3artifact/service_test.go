@@ -0,0 +1,487 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s3artifact
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/artifact"
+	"google.golang.org/adk/internal/artifact/tests"
+)
+
+// newS3ArtifactServiceForTesting creates an s3Service storing the objects in
+// memory, with parts of partSize bytes.
+func newS3ArtifactServiceForTesting(partSize int64) (*s3Service, *fakeS3) {
+	fake := &fakeS3{objects: map[string]*fakeObject{}, uploads: map[string]*fakeUpload{}}
+	// Presigning doesn't call S3, the URLs are signed with fake credentials.
+	presigner := s3.NewPresignClient(s3.New(s3.Options{
+		Region: "us-east-1",
+		Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "AKID", SecretAccessKey: "SECRET"}, nil
+		}),
+	}))
+	return &s3Service{bucket: "bucket", client: fake, presigner: presigner, partSize: partSize}, fake
+}
+
+func TestS3ArtifactService(t *testing.T) {
+	factory := func(t *testing.T) (artifact.Service, error) {
+		s, _ := newS3ArtifactServiceForTesting(MinPartSize)
+		return s, nil
+	}
+	tests.TestArtifactService(t, "S3", factory)
+}
+
+func TestNewService(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{"defaults", Config{Bucket: "bucket"}, false},
+		{"threshold", Config{Bucket: "bucket", MultipartThreshold: MinPartSize}, false},
+		{"no bucket", Config{}, true},
+		{"small threshold", Config{Bucket: "bucket", MultipartThreshold: MinPartSize - 1}, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewService(tc.cfg)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Errorf("NewService() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestS3ArtifactService_SaveStream(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		size          int
+		wantPartSizes []int
+	}{
+		{"small", 5, nil},
+		{"one part", 10, []int{10}},
+		{"several parts", 25, []int{10, 10, 5}},
+		{"exact parts", 20, []int{10, 10}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, fake := newS3ArtifactServiceForTesting(10)
+			content := bytes.Repeat([]byte("x"), tc.size)
+			resp, err := s.SaveStream(t.Context(), &artifact.SaveStreamRequest{
+				AppName: "app", UserID: "user", SessionID: "session", FileName: "file.bin",
+				MIMEType: "application/octet-stream",
+				Content:  bytes.NewReader(content),
+			})
+			if err != nil {
+				t.Fatalf("SaveStream() failed: %v", err)
+			}
+			if resp.Version != 1 {
+				t.Errorf("SaveStream() version = %d, want 1", resp.Version)
+			}
+			if diff := cmp.Diff(tc.wantPartSizes, fake.partSizes); diff != "" {
+				t.Errorf("SaveStream() uploaded parts mismatch (-want +got):\n%s", diff)
+			}
+			got, err := s.Load(t.Context(), &artifact.LoadRequest{AppName: "app", UserID: "user", SessionID: "session", FileName: "file.bin"})
+			if err != nil {
+				t.Fatalf("Load() failed: %v", err)
+			}
+			if diff := cmp.Diff(genai.NewPartFromBytes(content, "application/octet-stream"), got.Part); diff != "" {
+				t.Errorf("Load() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestS3ArtifactService_SaveStreamAbortsUpload(t *testing.T) {
+	s, fake := newS3ArtifactServiceForTesting(10)
+	readErr := errors.New("connection reset")
+	_, err := s.SaveStream(t.Context(), &artifact.SaveStreamRequest{
+		AppName: "app", UserID: "user", SessionID: "session", FileName: "file.bin",
+		MIMEType: "application/octet-stream",
+		Content:  io.MultiReader(bytes.NewReader(make([]byte, 15)), &errReader{readErr}),
+	})
+	if !errors.Is(err, readErr) {
+		t.Fatalf("SaveStream() error = %v, want %v", err, readErr)
+	}
+	if fake.aborted != 1 || len(fake.uploads) != 0 {
+		t.Errorf("SaveStream() aborted %d uploads and left %d, want 1 and 0", fake.aborted, len(fake.uploads))
+	}
+	if _, err := s.Versions(t.Context(), &artifact.VersionsRequest{AppName: "app", UserID: "user", SessionID: "session", FileName: "file.bin"}); err == nil {
+		t.Errorf("Versions() succeeded after a failed save, want an error")
+	}
+}
+
+func TestS3ArtifactService_SaveRetriesConcurrentVersion(t *testing.T) {
+	s, fake := newS3ArtifactServiceForTesting(10)
+	// Another writer saves the first version between the listing of the
+	// versions and the put.
+	fake.racingPuts = 1
+	resp, err := s.Save(t.Context(), &artifact.SaveRequest{
+		AppName: "app", UserID: "user", SessionID: "session", FileName: "file",
+		Part: genai.NewPartFromText("mine"),
+	})
+	if err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	if resp.Version != 2 {
+		t.Errorf("Save() version = %d, want 2", resp.Version)
+	}
+
+	// There is no retry for a stream, whose content was already consumed.
+	fake.racingPuts = 1
+	_, err = s.SaveStream(t.Context(), &artifact.SaveStreamRequest{
+		AppName: "app", UserID: "user", SessionID: "session", FileName: "file",
+		MIMEType: "text/plain",
+		Content:  strings.NewReader("mine"),
+	})
+	if !errors.Is(err, errVersionConflict) {
+		t.Errorf("SaveStream() error = %v, want %v", err, errVersionConflict)
+	}
+}
+
+func TestS3ArtifactService_LoadKeepsPartKind(t *testing.T) {
+	s, _ := newS3ArtifactServiceForTesting(10)
+	for _, tc := range []struct {
+		name string
+		part *genai.Part
+	}{
+		{"text", genai.NewPartFromText("hello")},
+		{"text bytes", genai.NewPartFromBytes([]byte("hello"), "text/plain")},
+		{"image", genai.NewPartFromBytes([]byte{0x89, 'P', 'N', 'G'}, "image/png")},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := &artifact.SaveRequest{AppName: "app", UserID: "user", SessionID: "session", FileName: tc.name, Part: tc.part}
+			if _, err := s.Save(t.Context(), req); err != nil {
+				t.Fatalf("Save() failed: %v", err)
+			}
+			got, err := s.Load(t.Context(), &artifact.LoadRequest{AppName: "app", UserID: "user", SessionID: "session", FileName: tc.name})
+			if err != nil {
+				t.Fatalf("Load() failed: %v", err)
+			}
+			if diff := cmp.Diff(tc.part, got.Part); diff != "" {
+				t.Errorf("Load() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// The test below needs an S3-compatible store, e.g. a disposable MinIO:
+//
+//	docker run --rm -p 9000:9000 minio/minio server /data
+//	S3_ENDPOINT=http://localhost:9000 AWS_ACCESS_KEY_ID=minioadmin AWS_SECRET_ACCESS_KEY=minioadmin \
+//	  go test ./artifact/s3artifact
+//
+// Every run of the suite uses its own bucket, which is deleted afterwards.
+func TestS3ArtifactService_Endpoint(t *testing.T) {
+	endpoint := os.Getenv("S3_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("S3_ENDPOINT is not set")
+	}
+	awsConfig, err := config.LoadDefaultConfig(t.Context(), config.WithRegion("us-east-1"))
+	if err != nil {
+		t.Fatalf("LoadDefaultConfig() failed: %v", err)
+	}
+	awsConfig.BaseEndpoint = aws.String(endpoint)
+	client := s3.NewFromConfig(awsConfig, func(o *s3.Options) { o.UsePathStyle = true })
+
+	factory := func(t *testing.T) (artifact.Service, error) {
+		bucket := "adk-test-" + uuid.NewString()
+		if _, err := client.CreateBucket(t.Context(), &s3.CreateBucketInput{Bucket: aws.String(bucket)}); err != nil {
+			return nil, err
+		}
+		s, err := NewService(Config{Bucket: bucket, AWSConfig: awsConfig, UsePathStyle: true, MultipartThreshold: MinPartSize})
+		if err != nil {
+			return nil, err
+		}
+		t.Cleanup(func() {
+			ctx := context.WithoutCancel(t.Context())
+			keys, err := s.(*s3Service).listKeys(ctx, "")
+			if err == nil {
+				err = s.(*s3Service).deleteKeys(ctx, keys)
+			}
+			if err == nil {
+				_, err = client.DeleteBucket(ctx, &s3.DeleteBucketInput{Bucket: aws.String(bucket)})
+			}
+			if err != nil {
+				t.Errorf("failed to delete bucket %s: %v", bucket, err)
+			}
+		})
+		return s, nil
+	}
+	tests.TestArtifactService(t, "S3", factory)
+
+	t.Run("Multipart", func(t *testing.T) {
+		s, err := factory(t)
+		if err != nil {
+			t.Fatal(err)
+		}
+		content := bytes.Repeat([]byte("0123456789"), (2*MinPartSize+MinPartSize/2)/10)
+		if _, err := artifact.SaveStream(t.Context(), s, &artifact.SaveStreamRequest{
+			AppName: "app", UserID: "user", SessionID: "session", FileName: "file.bin",
+			MIMEType: "application/octet-stream",
+			Content:  bytes.NewReader(content),
+		}); err != nil {
+			t.Fatalf("SaveStream() failed: %v", err)
+		}
+		got, err := s.Load(t.Context(), &artifact.LoadRequest{AppName: "app", UserID: "user", SessionID: "session", FileName: "file.bin"})
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if got.Part.InlineData == nil || !bytes.Equal(got.Part.InlineData.Data, content) {
+			t.Errorf("Load() returned different content than saved")
+		}
+	})
+
+	t.Run("SignURL", func(t *testing.T) {
+		s, err := factory(t)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.Save(t.Context(), &artifact.SaveRequest{
+			AppName: "app", UserID: "user", SessionID: "session", FileName: "report.pdf",
+			Part: genai.NewPartFromBytes([]byte("%PDF-1.7"), "application/pdf"),
+		}); err != nil {
+			t.Fatalf("Save() failed: %v", err)
+		}
+		signed, err := s.(artifact.URLSigner).SignURL(t.Context(), &artifact.SignURLRequest{
+			AppName: "app", UserID: "user", SessionID: "session", FileName: "report.pdf", TTL: time.Minute,
+		})
+		if err != nil {
+			t.Fatalf("SignURL() failed: %v", err)
+		}
+		resp, err := http.Get(signed.URL)
+		if err != nil {
+			t.Fatalf("GET of the signed URL failed: %v", err)
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("failed to read the response: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK || string(body) != "%PDF-1.7" {
+			t.Errorf("GET of the signed URL = %d %q, want %d %q", resp.StatusCode, body, http.StatusOK, "%PDF-1.7")
+		}
+		if got := resp.Header.Get("Content-Type"); got != "application/pdf" {
+			t.Errorf("GET of the signed URL Content-Type = %q, want %q", got, "application/pdf")
+		}
+	})
+}
+
+// errReader is a reader failing with err.
+type errReader struct {
+	err error
+}
+
+func (r *errReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+// ---------------------------------- Mock Implementations -----------------------------------
+// fakeS3 implements the s3API interface for testing, ignoring the bucket
+// names.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string]*fakeObject
+	uploads map[string]*fakeUpload
+	// partSizes are the sizes of the uploaded parts.
+	partSizes []int
+	// aborted is the number of aborted uploads.
+	aborted int
+	// racingPuts is the number of the next puts preceded by the put of the
+	// same key by another writer.
+	racingPuts int
+}
+
+type fakeObject struct {
+	data        []byte
+	contentType string
+	metadata    map[string]string
+	modified    time.Time
+}
+
+type fakeUpload struct {
+	object *fakeObject
+	parts  map[int32][]byte
+}
+
+// fakeListPageSize is small for the pagination of the listings to be tested.
+const fakeListPageSize = 2
+
+var errPreconditionFailed = &smithy.GenericAPIError{Code: "PreconditionFailed", Message: "At least one of the pre-conditions you specified did not hold"}
+
+func (f *fakeS3) put(key string, object *fakeObject, ifNoneMatch *string) error {
+	if f.racingPuts > 0 {
+		f.racingPuts--
+		f.objects[key] = &fakeObject{data: []byte("theirs"), contentType: "text/plain"}
+	}
+	if _, ok := f.objects[key]; ok && aws.ToString(ifNoneMatch) == "*" {
+		return errPreconditionFailed
+	}
+	object.modified = time.Now()
+	f.objects[key] = object
+	return nil
+}
+
+func (f *fakeS3) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	data, err := io.ReadAll(params.Body)
+	if err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	object := &fakeObject{data: data, contentType: aws.ToString(params.ContentType), metadata: params.Metadata}
+	if err := f.put(aws.ToString(params.Key), object, params.IfNoneMatch); err != nil {
+		return nil, err
+	}
+	return &s3.PutObjectOutput{}, nil
+}
+
+func (f *fakeS3) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	object, ok := f.objects[aws.ToString(params.Key)]
+	if !ok {
+		return nil, &types.NoSuchKey{}
+	}
+	return &s3.GetObjectOutput{
+		Body:          io.NopCloser(bytes.NewReader(object.data)),
+		ContentType:   aws.String(object.contentType),
+		ContentLength: aws.Int64(int64(len(object.data))),
+		Metadata:      object.metadata,
+	}, nil
+}
+
+func (f *fakeS3) DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.objects, aws.ToString(params.Key))
+	return &s3.DeleteObjectOutput{}, nil
+}
+
+func (f *fakeS3) DeleteObjects(ctx context.Context, params *s3.DeleteObjectsInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectsOutput, error) {
+	if len(params.Delete.Objects) > maxDeleteBatch {
+		return nil, fmt.Errorf("%d keys to delete, want at most %d", len(params.Delete.Objects), maxDeleteBatch)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, object := range params.Delete.Objects {
+		delete(f.objects, aws.ToString(object.Key))
+	}
+	return &s3.DeleteObjectsOutput{}, nil
+}
+
+func (f *fakeS3) ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var keys []string
+	for key := range f.objects {
+		if strings.HasPrefix(key, aws.ToString(params.Prefix)) && key > aws.ToString(params.ContinuationToken) {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	out := &s3.ListObjectsV2Output{}
+	if len(keys) > fakeListPageSize {
+		keys = keys[:fakeListPageSize]
+		out.IsTruncated = aws.Bool(true)
+		out.NextContinuationToken = aws.String(keys[len(keys)-1])
+	}
+	for _, key := range keys {
+		object := f.objects[key]
+		out.Contents = append(out.Contents, types.Object{Key: aws.String(key), Size: aws.Int64(int64(len(object.data))), LastModified: aws.Time(object.modified)})
+	}
+	return out, nil
+}
+
+func (f *fakeS3) CreateMultipartUpload(ctx context.Context, params *s3.CreateMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	id := uuid.NewString()
+	f.uploads[id] = &fakeUpload{
+		object: &fakeObject{contentType: aws.ToString(params.ContentType), metadata: params.Metadata},
+		parts:  map[int32][]byte{},
+	}
+	return &s3.CreateMultipartUploadOutput{UploadId: aws.String(id)}, nil
+}
+
+func (f *fakeS3) UploadPart(ctx context.Context, params *s3.UploadPartInput, optFns ...func(*s3.Options)) (*s3.UploadPartOutput, error) {
+	data, err := io.ReadAll(params.Body)
+	if err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	upload, ok := f.uploads[aws.ToString(params.UploadId)]
+	if !ok {
+		return nil, &types.NoSuchUpload{}
+	}
+	partNumber := aws.ToInt32(params.PartNumber)
+	upload.parts[partNumber] = data
+	f.partSizes = append(f.partSizes, len(data))
+	return &s3.UploadPartOutput{ETag: aws.String(strconv.Itoa(int(partNumber)))}, nil
+}
+
+func (f *fakeS3) CompleteMultipartUpload(ctx context.Context, params *s3.CompleteMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	upload, ok := f.uploads[aws.ToString(params.UploadId)]
+	if !ok {
+		return nil, &types.NoSuchUpload{}
+	}
+	for _, part := range params.MultipartUpload.Parts {
+		data, ok := upload.parts[aws.ToInt32(part.PartNumber)]
+		if !ok || aws.ToString(part.ETag) != strconv.Itoa(int(aws.ToInt32(part.PartNumber))) {
+			return nil, &smithy.GenericAPIError{Code: "InvalidPart"}
+		}
+		upload.object.data = append(upload.object.data, data...)
+	}
+	if err := f.put(aws.ToString(params.Key), upload.object, params.IfNoneMatch); err != nil {
+		return nil, err
+	}
+	delete(f.uploads, aws.ToString(params.UploadId))
+	return &s3.CompleteMultipartUploadOutput{}, nil
+}
+
+func (f *fakeS3) AbortMultipartUpload(ctx context.Context, params *s3.AbortMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.uploads[aws.ToString(params.UploadId)]; !ok {
+		return nil, &types.NoSuchUpload{}
+	}
+	delete(f.uploads, aws.ToString(params.UploadId))
+	f.aborted++
+	return &s3.AbortMultipartUploadOutput{}, nil
+}
+
+var _ s3API = (*fakeS3)(nil)
@@ -61,6 +61,9 @@ type SaveRequest struct {
 	// If set, the artifact will be saved with this version.
 	// If unset, a new version will be created.
 	Version int64
+	// Metadata is stored with the version and returned when it is loaded.
+	// See [ValidateMetadata] for the allowed keys.
+	Metadata map[string]string
 }
 
 // validateRequiredStrings checks a slice of fields in order.
@@ -106,7 +109,7 @@ func (req *SaveRequest) Validate() error {
 	if err := validateFileName(req.FileName); err != nil {
 		return err
 	}
-	return nil
+	return ValidateMetadata(req.Metadata)
 }
 
 func validateFileName(name string) error {
@@ -116,6 +119,26 @@ func validateFileName(name string) error {
 	return nil
 }
 
+// reservedMetadataPrefix is the prefix of the metadata keys used by the
+// implementations.
+const reservedMetadataPrefix = "adk-"
+
+// ValidateMetadata checks that the keys of the metadata of an artifact are
+// made of lowercase ASCII letters, digits, '-' and '_', which all the
+// implementations can store, e.g. as HTTP headers. The keys starting with
+// "adk-" are reserved.
+func ValidateMetadata(metadata map[string]string) error {
+	for key := range metadata {
+		if key == "" || strings.TrimLeft(key, "abcdefghijklmnopqrstuvwxyz0123456789-_") != "" {
+			return fmt.Errorf("invalid metadata key %q: only lowercase letters, digits, '-' and '_' are allowed", key)
+		}
+		if strings.HasPrefix(key, reservedMetadataPrefix) {
+			return fmt.Errorf("invalid metadata key %q: the %q prefix is reserved", key, reservedMetadataPrefix)
+		}
+	}
+	return nil
+}
+
 // SaveResponse is the return type of [ArtifactService.Save].
 type SaveResponse struct {
 	Version int64
@@ -159,6 +182,8 @@ func (req *LoadRequest) Validate() error {
 type LoadResponse struct {
 	// Part is the artifact stored.
 	Part *genai.Part
+	// Metadata is the metadata saved with the version, if any.
+	Metadata map[string]string
 }
 
 // DeleteRequest is the parameter for [ArtifactService.Delete].
@@ -0,0 +1,112 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package artifact
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+	"time"
+)
+
+// MaxSignedURLTTL is the longest validity of the signed URLs, which is the
+// limit of the V4 signatures of GCS and S3.
+const MaxSignedURLTTL = 7 * 24 * time.Hour
+
+// URLSigner is implemented by the services which can mint URLs giving a
+// time-limited access to the content of an artifact version, so that it can
+// be downloaded without going through the ADK server.
+type URLSigner interface {
+	// SignURL returns a URL of an HTTP GET request downloading the content
+	// of an artifact version. If req.Version is unset, the latest version is
+	// signed.
+	SignURL(ctx context.Context, req *SignURLRequest) (*SignURLResponse, error)
+}
+
+// SignURLRequest is the parameter for [URLSigner.SignURL].
+type SignURLRequest struct {
+	AppName, UserID, SessionID, FileName string
+	// TTL is how long the URL is valid, up to [MaxSignedURLTTL].
+	TTL time.Duration
+
+	// Below are optional fields.
+	Version int64
+}
+
+// Validate checks if the struct is valid or if it is missing fields.
+func (req *SignURLRequest) Validate() error {
+	fieldsToCheck := []requiredField{
+		{Name: "AppName", Value: req.AppName},
+		{Name: "UserID", Value: req.UserID},
+		{Name: "SessionID", Value: req.SessionID},
+		{Name: "FileName", Value: req.FileName},
+	}
+	missingFields := validateRequiredStrings(fieldsToCheck)
+	if len(missingFields) > 0 {
+		return fmt.Errorf("invalid sign URL request: missing required fields: %s", strings.Join(missingFields, ", "))
+	}
+	if req.TTL <= 0 || req.TTL > MaxSignedURLTTL {
+		return fmt.Errorf("invalid sign URL request: TTL %v must be positive and at most %v", req.TTL, MaxSignedURLTTL)
+	}
+	return validateFileName(req.FileName)
+}
+
+// SignURLResponse is the return type of [URLSigner.SignURL].
+type SignURLResponse struct {
+	// Version is the signed version of the artifact.
+	Version int64
+	// URL is the signed URL.
+	URL string
+	// Expires is when the URL stops being valid.
+	Expires time.Time
+}
+
+// SignURL returns a signed URL downloading the content of an artifact
+// version if s implements [URLSigner], or an error wrapping
+// [errors.ErrUnsupported].
+func SignURL(ctx context.Context, s Service, req *SignURLRequest) (*SignURLResponse, error) {
+	if signer, ok := s.(URLSigner); ok {
+		return signer.SignURL(ctx, req)
+	}
+	return nil, fmt.Errorf("artifact service %T doesn't sign URLs: %w", s, errors.ErrUnsupported)
+}
+
+// SignedVersion returns the version of an artifact signed by [URLSigner.SignURL]:
+// req.Version if it exists, or the latest version if it is unset. The
+// versions are listed with s.
+func SignedVersion(ctx context.Context, s Service, req *SignURLRequest) (int64, error) {
+	resp, err := s.Versions(ctx, &VersionsRequest{
+		AppName:   req.AppName,
+		UserID:    req.UserID,
+		SessionID: req.SessionID,
+		FileName:  req.FileName,
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(resp.Versions) == 0 {
+		return 0, fmt.Errorf("artifact not found: %w", fs.ErrNotExist)
+	}
+	if req.Version == 0 {
+		return slices.Max(resp.Versions), nil
+	}
+	if !slices.Contains(resp.Versions, req.Version) {
+		return 0, fmt.Errorf("artifact version %d not found: %w", req.Version, fs.ErrNotExist)
+	}
+	return req.Version, nil
+}
@@ -0,0 +1,186 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package artifact
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"google.golang.org/genai"
+)
+
+// StreamSaver is implemented by the services which can save an artifact
+// while reading its content, without holding all of it in memory.
+type StreamSaver interface {
+	// SaveStream saves the content read from req.Content as a new version of
+	// the artifact.
+	SaveStream(ctx context.Context, req *SaveStreamRequest) (*SaveResponse, error)
+}
+
+// SaveStreamRequest is the parameter for [StreamSaver.SaveStream].
+type SaveStreamRequest struct {
+	AppName, UserID, SessionID, FileName string
+	// MIMEType is the media type of the content.
+	MIMEType string
+	// Content is read until EOF.
+	Content io.Reader
+
+	// Below are optional fields.
+
+	// Metadata is stored with the version and returned when it is loaded.
+	// See [ValidateMetadata] for the allowed keys.
+	Metadata map[string]string
+}
+
+// Validate checks if the struct is valid or if it is missing fields.
+func (req *SaveStreamRequest) Validate() error {
+	fieldsToCheck := []requiredField{
+		{Name: "AppName", Value: req.AppName},
+		{Name: "UserID", Value: req.UserID},
+		{Name: "SessionID", Value: req.SessionID},
+		{Name: "FileName", Value: req.FileName},
+		{Name: "MIMEType", Value: req.MIMEType},
+	}
+	missingFields := validateRequiredStrings(fieldsToCheck)
+	if req.Content == nil {
+		missingFields = append(missingFields, "Content")
+	}
+	if len(missingFields) > 0 {
+		return fmt.Errorf("invalid save stream request: missing required fields: %s", strings.Join(missingFields, ", "))
+	}
+	if err := validateFileName(req.FileName); err != nil {
+		return err
+	}
+	return ValidateMetadata(req.Metadata)
+}
+
+// SaveStream saves the content read from req.Content as a new version of the
+// artifact. If s implements [StreamSaver] the content is streamed to it,
+// otherwise it is read in memory and saved with [Service.Save].
+func SaveStream(ctx context.Context, s Service, req *SaveStreamRequest) (*SaveResponse, error) {
+	if ss, ok := s.(StreamSaver); ok {
+		return ss.SaveStream(ctx, req)
+	}
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("request validation failed: %w", err)
+	}
+	data, err := io.ReadAll(req.Content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read artifact content: %w", err)
+	}
+	return s.Save(ctx, &SaveRequest{
+		AppName:   req.AppName,
+		UserID:    req.UserID,
+		SessionID: req.SessionID,
+		FileName:  req.FileName,
+		Part:      genai.NewPartFromBytes(data, req.MIMEType),
+		Metadata:  req.Metadata,
+	})
+}
+
+// StreamLoader is implemented by the services which can load an artifact
+// while it is read, without holding all of its content in memory.
+type StreamLoader interface {
+	// LoadStream opens the content of an artifact version for reading. If
+	// req.Version is unset, the latest version is opened. The caller must
+	// close the returned content.
+	//
+	// The implementations document whether the content also implements
+	// [io.Seeker].
+	LoadStream(ctx context.Context, req *LoadRequest) (*LoadStreamResponse, error)
+}
+
+// LoadStreamResponse is the return type of [StreamLoader.LoadStream].
+type LoadStreamResponse struct {
+	// Version is the opened version of the artifact.
+	Version int64
+	// MIMEType is the media type of the content.
+	MIMEType string
+	// Text reports that the artifact was saved as a text part, which
+	// [Service.Load] returns as such.
+	Text bool
+	// Size is the length of the content in bytes, or -1 if it is unknown.
+	Size int64
+	// Metadata is the metadata saved with the version, if any.
+	Metadata map[string]string
+	// Content is the content of the artifact.
+	Content io.ReadCloser
+}
+
+// LoadStream opens the content of an artifact version for reading. If s
+// implements [StreamLoader] the content is streamed from it, otherwise it is
+// loaded in memory with [Service.Load] and the returned content implements
+// [io.Seeker]. The caller must close the returned content.
+func LoadStream(ctx context.Context, s Service, req *LoadRequest) (*LoadStreamResponse, error) {
+	if sl, ok := s.(StreamLoader); ok {
+		return sl.LoadStream(ctx, req)
+	}
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("request validation failed: %w", err)
+	}
+	versionReq := *req
+	if versionReq.Version == 0 {
+		versions, err := s.Versions(ctx, &VersionsRequest{
+			AppName:   req.AppName,
+			UserID:    req.UserID,
+			SessionID: req.SessionID,
+			FileName:  req.FileName,
+		})
+		if err != nil {
+			return nil, err
+		}
+		versionReq.Version = slices.Max(versions.Versions)
+	}
+	resp, err := s.Load(ctx, &versionReq)
+	if err != nil {
+		return nil, err
+	}
+	streamResp := newLoadStreamResponse(versionReq.Version, resp.Part)
+	streamResp.Metadata = resp.Metadata
+	return streamResp, nil
+}
+
+// newLoadStreamResponse returns the response streaming the content of part
+// from memory.
+func newLoadStreamResponse(version int64, part *genai.Part) *LoadStreamResponse {
+	if part.InlineData != nil {
+		return &LoadStreamResponse{
+			Version:  version,
+			MIMEType: part.InlineData.MIMEType,
+			Size:     int64(len(part.InlineData.Data)),
+			Content:  readSeekNopCloser{bytes.NewReader(part.InlineData.Data)},
+		}
+	}
+	return &LoadStreamResponse{
+		Version:  version,
+		MIMEType: "text/plain; charset=utf-8",
+		Text:     true,
+		Size:     int64(len(part.Text)),
+		Content:  readSeekNopCloser{strings.NewReader(part.Text)},
+	}
+}
+
+// readSeekNopCloser adds a no-op Close method to an [io.ReadSeeker].
+type readSeekNopCloser struct {
+	io.ReadSeeker
+}
+
+func (readSeekNopCloser) Close() error {
+	return nil
+}
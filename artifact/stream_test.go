@@ -0,0 +1,82 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package artifact_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/artifact"
+)
+
+// plainService hides the optional interfaces of the service.
+type plainService struct {
+	artifact.Service
+}
+
+func TestLoadStream_Fallback(t *testing.T) {
+	s := plainService{artifact.InMemoryService()}
+	for _, part := range []*genai.Part{
+		genai.NewPartFromBytes([]byte("one"), "image/png"),
+		genai.NewPartFromBytes([]byte("two!"), "image/png"),
+	} {
+		if _, err := s.Save(t.Context(), &artifact.SaveRequest{AppName: "app", UserID: "user", SessionID: "session", FileName: "image", Part: part}); err != nil {
+			t.Fatalf("Save() failed: %v", err)
+		}
+	}
+	if _, err := s.Save(t.Context(), &artifact.SaveRequest{AppName: "app", UserID: "user", SessionID: "session", FileName: "notes", Part: genai.NewPartFromText("hello")}); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	type loaded struct {
+		Version  int64
+		MIMEType string
+		Text     bool
+		Size     int64
+		Content  string
+	}
+	for _, tc := range []struct {
+		name     string
+		fileName string
+		version  int64
+		want     loaded
+	}{
+		{"latest", "image", 0, loaded{Version: 2, MIMEType: "image/png", Size: 4, Content: "two!"}},
+		{"ver=1", "image", 1, loaded{Version: 1, MIMEType: "image/png", Size: 3, Content: "one"}},
+		{"text", "notes", 0, loaded{Version: 1, MIMEType: "text/plain; charset=utf-8", Text: true, Size: 5, Content: "hello"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := artifact.LoadStream(t.Context(), s, &artifact.LoadRequest{AppName: "app", UserID: "user", SessionID: "session", FileName: tc.fileName, Version: tc.version})
+			if err != nil {
+				t.Fatalf("LoadStream() failed: %v", err)
+			}
+			defer resp.Content.Close()
+			if _, ok := resp.Content.(io.Seeker); !ok {
+				t.Errorf("LoadStream() content of %T doesn't implement io.Seeker", resp.Content)
+			}
+			content, err := io.ReadAll(resp.Content)
+			if err != nil {
+				t.Fatalf("failed to read the content: %v", err)
+			}
+			got := loaded{Version: resp.Version, MIMEType: resp.MIMEType, Text: resp.Text, Size: resp.Size, Content: string(content)}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("LoadStream() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
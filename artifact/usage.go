@@ -0,0 +1,66 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package artifact
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/genai"
+)
+
+// UsageReporter is implemented by the services which can report the storage
+// used by the artifacts of a user.
+type UsageReporter interface {
+	// GetUsage returns the usage of the artifacts of the sessions of a user
+	// and of its user-scoped ones. It is computed from the stored versions,
+	// so it accounts for the deleted ones.
+	GetUsage(ctx context.Context, req *GetUsageRequest) (*GetUsageResponse, error)
+}
+
+// GetUsageRequest is the parameter for [UsageReporter.GetUsage].
+type GetUsageRequest struct {
+	AppName, UserID string
+}
+
+// Validate checks if the struct is valid or if it is missing a field.
+func (req *GetUsageRequest) Validate() error {
+	missingFields := validateRequiredStrings([]requiredField{
+		{Name: "AppName", Value: req.AppName},
+		{Name: "UserID", Value: req.UserID},
+	})
+	if len(missingFields) > 0 {
+		return fmt.Errorf("invalid get usage request: missing required fields: %s", strings.Join(missingFields, ", "))
+	}
+	return nil
+}
+
+// GetUsageResponse is the return type of [UsageReporter.GetUsage].
+type GetUsageResponse struct {
+	// Versions is the number of stored artifact versions.
+	Versions int
+	// Bytes is the total size of their contents.
+	Bytes int64
+}
+
+// PartSize returns the size of the content of an artifact as stored by the
+// services: the length of its inline data or of its text.
+func PartSize(part *genai.Part) int64 {
+	if part.InlineData != nil {
+		return int64(len(part.InlineData.Data))
+	}
+	return int64(len(part.Text))
+}
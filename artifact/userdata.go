@@ -0,0 +1,52 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package artifact
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// UserDataDeleter is implemented by the services which can delete all the
+// artifacts of a user at once, those of the sessions of the user and the
+// user-scoped ones, e.g. to satisfy a data-deletion request. Deleting the
+// artifacts of a user who has none is not an error.
+type UserDataDeleter interface {
+	DeleteUserData(ctx context.Context, req *DeleteUserDataRequest) (*DeleteUserDataResponse, error)
+}
+
+// DeleteUserDataRequest is the parameter for [UserDataDeleter.DeleteUserData].
+type DeleteUserDataRequest struct {
+	AppName, UserID string
+}
+
+// Validate checks if the struct is valid or if it is missing a field.
+func (req *DeleteUserDataRequest) Validate() error {
+	missingFields := validateRequiredStrings([]requiredField{
+		{Name: "AppName", Value: req.AppName},
+		{Name: "UserID", Value: req.UserID},
+	})
+	if len(missingFields) > 0 {
+		return fmt.Errorf("invalid delete user data request: missing required fields: %s", strings.Join(missingFields, ", "))
+	}
+	return nil
+}
+
+// DeleteUserDataResponse is the return type of [UserDataDeleter.DeleteUserData].
+type DeleteUserDataResponse struct {
+	// DeletedVersions is the number of deleted artifact versions.
+	DeletedVersions int
+}
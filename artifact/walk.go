@@ -0,0 +1,43 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package artifact
+
+import (
+	"context"
+	"iter"
+	"time"
+)
+
+// Walker is implemented by the services which can enumerate all the artifact
+// versions they store, e.g. to collect the garbage ones.
+type Walker interface {
+	// Walk returns all the stored artifact versions, in no particular order.
+	// The iteration stops at the first error.
+	Walk(ctx context.Context) iter.Seq2[*VersionInfo, error]
+}
+
+// VersionInfo describes a stored artifact version.
+type VersionInfo struct {
+	AppName, UserID string
+	// SessionID is empty for the user-scoped artifacts, whose names start
+	// with "user:".
+	SessionID string
+	FileName  string
+	Version   int64
+	// Size is the length of the content in bytes.
+	Size int64
+	// CreateTime is when the version was saved.
+	CreateTime time.Time
+}
@@ -94,6 +94,11 @@ func (l *consoleLauncher) Run(ctx context.Context, config *launcher.Config) erro
 	if err != nil {
 		return fmt.Errorf("failed to create runner: %v", err)
 	}
+	defer func() {
+		if err := r.Close(context.WithoutCancel(ctx)); err != nil {
+			log.Printf("Failed to close the runner: %v", err)
+		}
+	}()
 
 	inputChan := make(chan string)
 	readErrChan := make(chan error, 1)
@@ -26,13 +26,24 @@ import (
 	"google.golang.org/adk/cmd/launcher"
 	weblauncher "google.golang.org/adk/cmd/launcher/web"
 	"google.golang.org/adk/internal/cli/util"
+	"google.golang.org/adk/memory/ingest"
 	"google.golang.org/adk/server/adkrest"
+	"google.golang.org/adk/server/adkrest/metrics"
+	"google.golang.org/adk/server/adkrest/ratelimit"
 )
 
 // apiConfig contains parametres for lauching ADK REST API
 type apiConfig struct {
-	frontendAddress string
-	sseWriteTimeout time.Duration
+	frontendAddress      string
+	sseWriteTimeout      time.Duration
+	sseKeepAliveInterval time.Duration
+	maxRunDuration       time.Duration
+	maxUploadSize        int64
+	metrics              bool
+	rateLimitRPM         float64
+	rateLimitBurst       int
+	sessionLockWait      time.Duration
+	memoryIngestion      bool
 }
 
 // apiLauncher can launch ADK REST API
@@ -70,8 +81,54 @@ func (a *apiLauncher) UserMessage(webURL string, printer func(v ...any)) {
 
 // SetupSubrouters adds the API router to the parent router.
 func (a *apiLauncher) SetupSubrouters(router *mux.Router, config *launcher.Config) error {
+	var recorder metrics.Recorder
+	if a.config.metrics {
+		var err error
+		recorder, err = newMetricsRecorder()
+		if err != nil {
+			return fmt.Errorf("failed to create metrics recorder: %w", err)
+		}
+	}
+
+	var limiter ratelimit.Limiter
+	if a.config.rateLimitRPM > 0 {
+		var err error
+		limiter, err = ratelimit.NewInMemory(ratelimit.Config{
+			RequestsPerMinute: a.config.rateLimitRPM,
+			Burst:             a.config.rateLimitBurst,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create rate limiter: %w", err)
+		}
+	}
+
+	var ingester *ingest.Ingester
+	if a.config.memoryIngestion {
+		if config.MemoryService == nil {
+			return fmt.Errorf("memory ingestion requires a memory service")
+		}
+		var err error
+		ingester, err = ingest.New(ingest.Config{
+			Memory:   config.MemoryService,
+			Sessions: config.SessionService,
+			Metrics:  recorder,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create memory ingester: %w", err)
+		}
+	}
+
 	// Create the ADK REST API handler
-	apiHandler := adkrest.NewHandler(config, a.config.sseWriteTimeout)
+	apiHandler := adkrest.NewHandlerWithOptions(config, adkrest.Options{
+		SSEWriteTimeout:       a.config.sseWriteTimeout,
+		SSEKeepAliveInterval:  a.config.sseKeepAliveInterval,
+		MaxRunDuration:        a.config.maxRunDuration,
+		MaxArtifactUploadSize: a.config.maxUploadSize,
+		Metrics:               recorder,
+		RateLimiter:           limiter,
+		SessionLockWait:       a.config.sessionLockWait,
+		MemoryIngester:        ingester,
+	})
 
 	// Wrap it with CORS middleware
 	corsHandler := corsWithArgs(a.config.frontendAddress)(apiHandler)
@@ -111,6 +168,14 @@ func NewLauncher() weblauncher.Sublauncher {
 	fs := flag.NewFlagSet("web", flag.ContinueOnError)
 	fs.StringVar(&config.frontendAddress, "webui_address", "localhost:8080", "ADK WebUI address as seen from the user browser. It's used to allow CORS requests. Please specify only hostname and (optionally) port.")
 	fs.DurationVar(&config.sseWriteTimeout, "sse-write-timeout", 120*time.Second, "SSE server write timeout (i.e. '10s', '2m' - see time.ParseDuration for details) - for writing the SSE response after reading the headers & body")
+	fs.DurationVar(&config.sseKeepAliveInterval, "sse-keep-alive-interval", 15*time.Second, "Interval of keep-alive comments sent on idle SSE streams (i.e. '10s', '1m' - see time.ParseDuration for details). Negative value disables keep-alives")
+	fs.DurationVar(&config.maxRunDuration, "max-run-duration", 0, "Maximum duration of an agent run (i.e. '5m' - see time.ParseDuration for details). Zero means no limit")
+	fs.Int64Var(&config.maxUploadSize, "max-artifact-upload-size", 0, "Maximum size in bytes of an artifact upload request. Zero means the default of 100 MiB")
+	fs.Float64Var(&config.rateLimitRPM, "rate-limit-rpm", 0, "Maximum number of runs per minute for each user of an app. Zero means no limit")
+	fs.IntVar(&config.rateLimitBurst, "rate-limit-burst", 1, "Number of runs a user can start at once before being rate limited")
+	fs.DurationVar(&config.sessionLockWait, "session-lock-wait", 0, "How long a run waits for another run of its session to finish (i.e. '30s' - see time.ParseDuration for details). Zero means such runs are rejected right away")
+	fs.BoolVar(&config.memoryIngestion, "memory-ingestion", false, "Whether the sessions are added to the memory service in the background at the end of their runs")
+	fs.BoolVar(&config.metrics, "metrics", false, "Expose Prometheus metrics on /api/metrics")
 
 	return &apiLauncher{
 		config: config,
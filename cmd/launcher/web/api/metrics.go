@@ -0,0 +1,28 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !adk_noprometheus
+
+package api
+
+import (
+	"google.golang.org/adk/server/adkrest/metrics"
+	"google.golang.org/adk/server/adkrest/metrics/prometheus"
+)
+
+// newMetricsRecorder returns the recorder behind the -metrics flag. Build
+// with the adk_noprometheus tag to drop the Prometheus dependency.
+func newMetricsRecorder() (metrics.Recorder, error) {
+	return prometheus.New(prometheus.Config{})
+}
@@ -0,0 +1,29 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build adk_noprometheus
+
+package api
+
+import (
+	"errors"
+
+	"google.golang.org/adk/server/adkrest/metrics"
+)
+
+// newMetricsRecorder reports that metrics are not available, as the binary
+// was built with the adk_noprometheus tag.
+func newMetricsRecorder() (metrics.Recorder, error) {
+	return nil, errors.New("metrics are not available: built with the adk_noprometheus tag")
+}
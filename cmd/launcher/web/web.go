@@ -26,6 +26,7 @@ import (
 
 	"github.com/gorilla/mux"
 
+	"google.golang.org/adk/artifact/artifactgc"
 	"google.golang.org/adk/cmd/launcher"
 	"google.golang.org/adk/cmd/launcher/universal"
 	"google.golang.org/adk/internal/cli/util"
@@ -39,6 +40,15 @@ type webConfig struct {
 	readTimeout     time.Duration
 	idleTimeout     time.Duration
 	shutdownTimeout time.Duration
+	artifactGC      artifactGCConfig
+}
+
+// artifactGCConfig contains parameters for the artifact garbage collection
+type artifactGCConfig struct {
+	interval    time.Duration
+	maxVersions int
+	maxAge      time.Duration
+	orphaned    bool
 }
 
 // webLauncher can launch web server
@@ -168,6 +178,18 @@ func (w *webLauncher) Run(ctx context.Context, config *launcher.Config) error {
 		}
 	}
 
+	if gc := w.config.artifactGC; gc.interval > 0 && config.ArtifactService != nil {
+		policy := artifactgc.Policy{
+			MaxVersions:    gc.maxVersions,
+			MaxAge:         gc.maxAge,
+			DeleteOrphaned: gc.orphaned,
+			Sessions:       config.SessionService,
+		}
+		if err := artifactgc.Start(ctx, config.ArtifactService, policy, gc.interval); err != nil {
+			return fmt.Errorf("artifact garbage collection setup failed: %w", err)
+		}
+	}
+
 	log.Printf("Starting the web server: %+v", w.config)
 	log.Println()
 	webUrl := fmt.Sprintf("http://localhost:%v", fmt.Sprint(w.config.port))
@@ -226,6 +248,10 @@ func NewLauncher(sublaunchers ...Sublauncher) launcher.SubLauncher {
 	fs.DurationVar(&config.readTimeout, "read-timeout", 15*time.Second, "Server read timeout (i.e. '10s', '2m' - see time.ParseDuration for details) - for reading the whole request including body")
 	fs.DurationVar(&config.idleTimeout, "idle-timeout", 60*time.Second, "Server idle timeout (i.e. '10s', '2m' - see time.ParseDuration for details) - for waiting for the next request (only when keep-alive is enabled)")
 	fs.DurationVar(&config.shutdownTimeout, "shutdown-timeout", 15*time.Second, "Server shutdown timeout (i.e. '10s', '2m' - see time.ParseDuration for details) - for waiting for active requests to finish during shutdown")
+	fs.DurationVar(&config.artifactGC.interval, "artifact-gc-interval", 0, "Artifact garbage collection interval (i.e. '1h', '24h' - see time.ParseDuration for details) - the collection is disabled when zero")
+	fs.IntVar(&config.artifactGC.maxVersions, "artifact-gc-max-versions", 0, "Number of most recent versions of each artifact kept by the garbage collection - all of them when zero")
+	fs.DurationVar(&config.artifactGC.maxAge, "artifact-gc-max-age", 0, "Age after which versions other than the latest one of each artifact are deleted by the garbage collection - never when zero")
+	fs.BoolVar(&config.artifactGC.orphaned, "artifact-gc-orphaned", false, "Whether the garbage collection deletes the artifacts of sessions which no longer exist")
 
 	return &webLauncher{
 		config:       config,
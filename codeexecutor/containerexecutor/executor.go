@@ -0,0 +1,524 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package containerexecutor provides a code executor running the code in
+// containers, with the Docker CLI or a compatible one, e.g. Podman.
+//
+// Each execution runs in a new container with limits of CPU, memory and
+// duration, without network by default, and with the gVisor runtime (runsc)
+// when it's available. The working directory of the code is a workspace
+// directory of the host, mounted read-write, where the input artifacts are
+// copied and whose new files are collected as output files.
+//
+// In stateful mode, the Python executions of a session run in a kernel, a
+// long-lived interpreter in a container, which keeps their variables and
+// workspace until it's idle for Config.KernelIdleTimeout or reset. The
+// kernels don't survive the executor: the next executions of their sessions
+// run in new kernels, and report that the state was lost.
+package containerexecutor
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/codeexecutor"
+)
+
+// Defaults of the Config.
+const (
+	DefaultTimeout            = 30 * time.Second
+	DefaultCPUs               = 1
+	DefaultMemoryBytes        = 512 << 20
+	DefaultMaxOutputBytes     = 64 << 10
+	DefaultMaxOutputFileBytes = 10 << 20
+	DefaultKernelIdleTimeout  = 30 * time.Minute
+)
+
+// runtimeGVisor is the name of the gVisor runtime, used when it's available.
+const runtimeGVisor = "runsc"
+
+// DefaultImages are the default images of the containers, by language.
+var DefaultImages = map[string]string{
+	codeexecutor.LanguagePython: "python:3.12-slim",
+	codeexecutor.LanguageGo:     "golang:1.24",
+}
+
+// Config provides initial configuration for the container executor.
+type Config struct {
+	// Command is the container CLI, "docker" by default.
+	Command string
+	// Runtime is the OCI runtime of the containers. If it's empty, the
+	// gVisor runtime is used if the container runtime has it, and the
+	// default one otherwise.
+	Runtime string
+	// Images are the images of the containers, by language, overriding the
+	// DefaultImages. The images must have python3 for Python, and go for Go.
+	Images map[string]string
+
+	// Timeout bounds the duration of each execution. Defaults to
+	// DefaultTimeout.
+	Timeout time.Duration
+	// CPUs is the number of CPUs of each execution. Defaults to DefaultCPUs.
+	CPUs float64
+	// MemoryBytes is the memory of each execution. Defaults to
+	// DefaultMemoryBytes.
+	MemoryBytes int64
+	// Network enables the network of the containers, which have none by
+	// default.
+	Network bool
+
+	// InputArtifacts are path.Match patterns of the names of the artifacts
+	// of the session copied in the workspace before each execution.
+	InputArtifacts []string
+
+	// MaxOutputBytes is the size of the stdout and stderr of an execution
+	// returned, the longer outputs are truncated. Defaults to
+	// DefaultMaxOutputBytes.
+	MaxOutputBytes int
+	// MaxOutputFileBytes is the size of the largest output file, the larger
+	// ones aren't collected. Defaults to DefaultMaxOutputFileBytes.
+	MaxOutputFileBytes int
+
+	// Stateful makes the Python executions of each session run in a kernel
+	// keeping their state, the Go ones remain stateless.
+	Stateful bool
+	// KernelIdleTimeout is the duration after which the kernels without
+	// executions are stopped. Defaults to DefaultKernelIdleTimeout.
+	KernelIdleTimeout time.Duration
+}
+
+// Executor is a [codeexecutor.CodeExecutor] running the code in containers.
+// A stateful executor must be closed to stop its kernels, and implements
+// [codeexecutor.KernelResetter] for the tool of package resetkerneltool.
+type Executor struct {
+	cfg     Config
+	runtime string
+
+	mu      sync.Mutex
+	kernels map[string]*kernel // by ID
+}
+
+// New returns a container executor. It fails with an error wrapping
+// [codeexecutor.ErrNoRuntime] if the container CLI isn't installed or its
+// daemon doesn't answer, rather than letting the code run on the host.
+func New(cfg Config) (*Executor, error) {
+	if cfg.Command == "" {
+		cfg.Command = "docker"
+	}
+	images := make(map[string]string, len(DefaultImages)+len(cfg.Images))
+	for language, image := range DefaultImages {
+		images[language] = image
+	}
+	for language, image := range cfg.Images {
+		images[language] = image
+	}
+	cfg.Images = images
+	for _, pattern := range cfg.InputArtifacts {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid input artifact pattern %q: %w", pattern, err)
+		}
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if cfg.CPUs <= 0 {
+		cfg.CPUs = DefaultCPUs
+	}
+	if cfg.MemoryBytes <= 0 {
+		cfg.MemoryBytes = DefaultMemoryBytes
+	}
+	if cfg.MaxOutputBytes <= 0 {
+		cfg.MaxOutputBytes = DefaultMaxOutputBytes
+	}
+	if cfg.MaxOutputFileBytes <= 0 {
+		cfg.MaxOutputFileBytes = DefaultMaxOutputFileBytes
+	}
+	if cfg.KernelIdleTimeout <= 0 {
+		cfg.KernelIdleTimeout = DefaultKernelIdleTimeout
+	}
+
+	if _, err := exec.LookPath(cfg.Command); err != nil {
+		return nil, fmt.Errorf("container CLI %q not found: %w", cfg.Command, codeexecutor.ErrNoRuntime)
+	}
+	runtimes, err := availableRuntimes(cfg.Command)
+	if err != nil {
+		return nil, err
+	}
+	e := &Executor{cfg: cfg, runtime: cfg.Runtime, kernels: map[string]*kernel{}}
+	switch _, ok := runtimes[cfg.Runtime]; {
+	case cfg.Runtime == "":
+		if _, ok := runtimes[runtimeGVisor]; ok {
+			e.runtime = runtimeGVisor
+		}
+	case !ok:
+		return nil, fmt.Errorf("container runtime %q not available: %w", cfg.Runtime, codeexecutor.ErrNoRuntime)
+	}
+	return e, nil
+}
+
+// availableRuntimes returns the OCI runtimes of the container runtime.
+func availableRuntimes(command string) (map[string]json.RawMessage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, command, "info", "--format", "{{json .Runtimes}}")
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("container runtime not available: %v: %s: %w", err, strings.TrimSpace(stderr.String()), codeexecutor.ErrNoRuntime)
+	}
+	var runtimes map[string]json.RawMessage
+	if err := json.Unmarshal(out, &runtimes); err != nil {
+		return nil, fmt.Errorf("failed to parse the runtimes of the container runtime: %w", err)
+	}
+	return runtimes, nil
+}
+
+// Runtime returns the OCI runtime of the containers, empty for the default
+// one.
+func (e *Executor) Runtime() string {
+	return e.runtime
+}
+
+// ExecuteCode implements codeexecutor.CodeExecutor.
+func (e *Executor) ExecuteCode(ctx agent.InvocationContext, input *codeexecutor.Input) (*codeexecutor.Result, error) {
+	image, ok := e.cfg.Images[input.Language]
+	if !ok {
+		return nil, fmt.Errorf("unsupported language %q", input.Language)
+	}
+	if e.cfg.Stateful && input.Language == codeexecutor.LanguagePython {
+		return e.executeInKernel(ctx, input, image)
+	}
+	dir, err := os.MkdirTemp("", "adk-code-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the workspace: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	workspace, codeDir := filepath.Join(dir, "workspace"), filepath.Join(dir, "code")
+	for _, d := range []string{workspace, codeDir} {
+		if err := os.Mkdir(d, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create the workspace: %w", err)
+		}
+	}
+
+	inputs, err := e.inputFiles(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeInputFiles(workspace, inputs); err != nil {
+		return nil, err
+	}
+	before, err := snapshot(workspace)
+	if err != nil {
+		return nil, err
+	}
+	var command []string
+	switch input.Language {
+	case codeexecutor.LanguageGo:
+		command = []string{"go", "run", "/code/main.go"}
+		err = os.WriteFile(filepath.Join(codeDir, "main.go"), []byte(input.Code), 0o644)
+	default:
+		command = []string{"python3", "/code/main.py"}
+		err = os.WriteFile(filepath.Join(codeDir, "main.py"), []byte(input.Code), 0o644)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write the code: %w", err)
+	}
+
+	name := "adk-code-" + input.ExecutionID
+	if input.ExecutionID == "" {
+		name += strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	runCtx, cancel := context.WithTimeout(ctx, e.cfg.Timeout)
+	defer cancel()
+	stdout := &cappedBuffer{max: e.cfg.MaxOutputBytes}
+	stderr := &cappedBuffer{max: e.cfg.MaxOutputBytes}
+	cmd := exec.CommandContext(runCtx, e.cfg.Command, append(e.runArgs(name, workspace, codeDir, image), command...)...)
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	cmd.WaitDelay = time.Second
+	err = cmd.Run()
+	if runCtx.Err() != nil {
+		e.kill(name)
+		if errors.Is(runCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+			return nil, fmt.Errorf("code execution timed out after %v: %w", e.cfg.Timeout, context.DeadlineExceeded)
+		}
+		return nil, runCtx.Err()
+	}
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == 125:
+		// The container CLI failed, rather than the code.
+		return nil, fmt.Errorf("failed to run the container: %s", strings.TrimSpace(stderr.String()))
+	case errors.As(err, &exitErr):
+		if stderr.Len() == 0 {
+			fmt.Fprintf(stderr, "exit status %d", exitErr.ExitCode())
+		}
+	case err != nil:
+		return nil, fmt.Errorf("failed to run the container: %w", err)
+	}
+
+	files, skipped, err := e.outputFiles(workspace, before)
+	if err != nil {
+		return nil, err
+	}
+	result := &codeexecutor.Result{Stdout: stdout.String(), Stderr: stderr.String(), OutputFiles: files}
+	for _, note := range skipped {
+		result.Stdout += "\n" + note
+	}
+	return result, nil
+}
+
+// runArgs returns the arguments of the container CLI running the container,
+// without its command. The code directory isn't mounted if it's empty.
+func (e *Executor) runArgs(name, workspace, codeDir, image string) []string {
+	args := []string{
+		"run", "--rm", "--name", name,
+		"--cpus", strconv.FormatFloat(e.cfg.CPUs, 'f', -1, 64),
+		"--memory", strconv.FormatInt(e.cfg.MemoryBytes, 10),
+		"--memory-swap", strconv.FormatInt(e.cfg.MemoryBytes, 10),
+		"--pids-limit", "256",
+		"--read-only",
+		"--tmpfs", "/tmp:rw,exec,size=512m",
+		"--cap-drop", "ALL",
+		"--security-opt", "no-new-privileges",
+		"--user", fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid()),
+		"-e", "HOME=/tmp",
+		"-e", "GOCACHE=/tmp/go-cache",
+		"-e", "GOPATH=/tmp/go",
+		"-e", "GOTOOLCHAIN=local",
+		"-v", workspace + ":/workspace:rw",
+		"-w", "/workspace",
+	}
+	if codeDir != "" {
+		args = append(args, "-v", codeDir+":/code:ro")
+	}
+	if !e.cfg.Network {
+		args = append(args, "--network", "none")
+	}
+	if e.runtime != "" {
+		args = append(args, "--runtime", e.runtime)
+	}
+	return append(args, image)
+}
+
+// kill kills the container of an execution interrupted, which the container
+// CLI may leave running.
+func (e *Executor) kill(name string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_ = exec.CommandContext(ctx, e.cfg.Command, "kill", name).Run()
+}
+
+// inputFiles returns the input files of the execution and the artifacts
+// matching the InputArtifacts.
+func (e *Executor) inputFiles(ctx agent.InvocationContext, input *codeexecutor.Input) ([]codeexecutor.File, error) {
+	files := append([]codeexecutor.File{}, input.InputFiles...)
+	if len(e.cfg.InputArtifacts) == 0 || ctx.Artifacts() == nil {
+		return files, nil
+	}
+	list, err := ctx.Artifacts().List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list artifacts: %w", err)
+	}
+	for _, name := range list.FileNames {
+		if !e.isInputArtifact(name) {
+			continue
+		}
+		load, err := ctx.Artifacts().Load(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load artifact %q: %w", name, err)
+		}
+		file := codeexecutor.File{Name: name}
+		switch part := load.Part; {
+		case part == nil:
+		case part.InlineData != nil:
+			file.Content, file.MIMEType = part.InlineData.Data, part.InlineData.MIMEType
+		default:
+			file.Content, file.MIMEType = []byte(part.Text), "text/plain"
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+func (e *Executor) isInputArtifact(name string) bool {
+	for _, pattern := range e.cfg.InputArtifacts {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// outputFiles returns the files of the workspace written by the code, new or
+// different from the snapshot taken before the execution, and notes on the
+// files over MaxOutputFileBytes, which aren't returned.
+func (e *Executor) outputFiles(workspace string, before map[string][sha256.Size]byte) ([]codeexecutor.File, []string, error) {
+	var files []codeexecutor.File
+	var skipped []string
+	err := filepath.WalkDir(workspace, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		rel, err := filepath.Rel(workspace, p)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if info.Size() > int64(e.cfg.MaxOutputFileBytes) {
+			skipped = append(skipped, fmt.Sprintf("[output file %q of %d bytes not saved: larger than %d bytes]", name, info.Size(), e.cfg.MaxOutputFileBytes))
+			return nil
+		}
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		if sum, ok := before[name]; ok && sum == sha256.Sum256(content) {
+			return nil
+		}
+		files = append(files, codeexecutor.File{Name: name, Content: content})
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to collect the output files: %w", err)
+	}
+	return files, skipped, nil
+}
+
+// snapshot returns the hashes of the contents of the files of the workspace,
+// by name.
+func snapshot(workspace string) (map[string][sha256.Size]byte, error) {
+	sums := map[string][sha256.Size]byte{}
+	err := filepath.WalkDir(workspace, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		rel, err := filepath.Rel(workspace, p)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		sums[filepath.ToSlash(rel)] = sha256.Sum256(content)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the files of the workspace: %w", err)
+	}
+	return sums, nil
+}
+
+// writeInputFiles writes the input files in the workspace.
+func writeInputFiles(workspace string, files []codeexecutor.File) error {
+	for _, file := range files {
+		if err := writeFile(workspace, file.Name, file.Content); err != nil {
+			return fmt.Errorf("failed to copy input file %q: %w", file.Name, err)
+		}
+	}
+	return nil
+}
+
+// writeFile writes the file of the name, a relative path, in dir.
+//
+// The code run in the container can replace the files of a kernel's
+// workspace with symbolic links, e.g. to the files of the host user. The
+// file is written through an [os.Root] of dir, which doesn't follow the
+// links out of it, and replaces the existing file rather than writing
+// through it.
+func writeFile(dir, name string, content []byte) error {
+	name = filepath.FromSlash(name)
+	if !filepath.IsLocal(name) {
+		return fmt.Errorf("invalid file name")
+	}
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return err
+	}
+	defer root.Close()
+
+	var path string
+	if parent := filepath.Dir(name); parent != "." {
+		for _, segment := range strings.Split(parent, string(filepath.Separator)) {
+			path = filepath.Join(path, segment)
+			if err := root.Mkdir(path, 0o755); err != nil && !errors.Is(err, fs.ErrExist) {
+				return err
+			}
+		}
+	}
+	if err := root.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	f, err := root.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// cappedBuffer keeps the first max bytes written to it.
+type cappedBuffer struct {
+	buf       bytes.Buffer
+	max       int
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	room := b.max - b.buf.Len()
+	if len(p) > room {
+		b.truncated = true
+		b.buf.Write(p[:max(room, 0)])
+	} else {
+		b.buf.Write(p)
+	}
+	return len(p), nil
+}
+
+func (b *cappedBuffer) Len() int {
+	return b.buf.Len()
+}
+
+// String returns the content written, with a note if it was truncated.
+func (b *cappedBuffer) String() string {
+	s := strings.ToValidUTF8(b.buf.String(), "")
+	if b.truncated {
+		s += "\n[output truncated]"
+	}
+	return s
+}
+
+var _ codeexecutor.CodeExecutor = (*Executor)(nil)
@@ -0,0 +1,289 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package containerexecutor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/artifact"
+	"google.golang.org/adk/codeexecutor"
+	artifactinternal "google.golang.org/adk/internal/artifact"
+	icontext "google.golang.org/adk/internal/context"
+)
+
+// fakeCLI is a container CLI whose runs, instead of running a container,
+// record their arguments and act on the workspace as told by FAKE_MODE. Its
+// kernels reply with the number of their executions.
+const fakeCLI = `#!/bin/sh
+case "$1" in
+info) echo '{"runc":{},"runsc":{}}'; exit 0;;
+kill) exit 0;;
+esac
+echo "$@" > "$FAKE_ARGS"
+interactive=""
+[ "$2" = "-i" ] && interactive=1
+ws=""
+while [ $# -gt 0 ]; do
+	case "$1" in -v) case "$2" in *:/workspace:rw) ws="${2%:/workspace:rw}";; esac; shift;; esac
+	shift
+done
+if [ -n "$interactive" ]; then
+	n=0
+	while read -r line; do
+		n=$((n+1))
+		case "$line" in
+		*crash*) echo "Segmentation fault" >&2; exit 139;;
+		*sleep*) exec sleep 5;;
+		*write*) echo "run $n" > "$ws/out.txt";;
+		esac
+		printf '%s\n' '{"stdout":"run '"$n"'\n","stderr":""}'
+	done
+	exit 0
+fi
+case "$FAKE_MODE" in
+ok) tr a-z A-Z < "$ws/data.csv" > "$ws/upper.csv"; echo "hello";;
+fail) echo "Traceback: ZeroDivisionError" >&2; exit 1;;
+silent) exit 3;;
+big) head -c 100 /dev/zero > "$ws/big.bin"; head -c 50 /dev/zero | tr '\0' x;;
+daemon) echo "docker: Error response from daemon: pull access denied" >&2; exit 125;;
+sleep) exec sleep 5;;
+esac
+`
+
+func newFakeExecutor(t *testing.T, mode string, cfg Config) *Executor {
+	t.Helper()
+	dir := t.TempDir()
+	cfg.Command = filepath.Join(dir, "docker")
+	if err := os.WriteFile(cfg.Command, []byte(fakeCLI), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("FAKE_MODE", mode)
+	t.Setenv("FAKE_ARGS", filepath.Join(dir, "args"))
+	e, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	return e
+}
+
+func newInvocationContext(t *testing.T, service artifact.Service) agent.InvocationContext {
+	t.Helper()
+	return icontext.NewInvocationContext(t.Context(), icontext.InvocationContextParams{
+		Artifacts: &artifactinternal.Artifacts{
+			Service:   service,
+			AppName:   "app",
+			UserID:    "user",
+			SessionID: "session",
+		},
+		InvocationID: "invocation",
+	})
+}
+
+func TestExecuteCode(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    string
+		cfg     Config
+		want    *codeexecutor.Result
+		wantErr string
+	}{
+		{
+			name: "output files",
+			mode: "ok",
+			cfg:  Config{InputArtifacts: []string{"*.csv"}},
+			want: &codeexecutor.Result{
+				Stdout:      "hello\n",
+				OutputFiles: []codeexecutor.File{{Name: "upper.csv", Content: []byte("A,B\n1,2\n")}},
+			},
+		},
+		{
+			name: "code failure",
+			mode: "fail",
+			want: &codeexecutor.Result{Stderr: "Traceback: ZeroDivisionError\n"},
+		},
+		{
+			name: "exit status",
+			mode: "silent",
+			want: &codeexecutor.Result{Stderr: "exit status 3"},
+		},
+		{
+			name: "output limits",
+			mode: "big",
+			cfg:  Config{MaxOutputBytes: 10, MaxOutputFileBytes: 64},
+			want: &codeexecutor.Result{Stdout: "xxxxxxxxxx\n[output truncated]\n" + `[output file "big.bin" of 100 bytes not saved: larger than 64 bytes]`},
+		},
+		{
+			name:    "container failure",
+			mode:    "daemon",
+			wantErr: "pull access denied",
+		},
+		{
+			name:    "timeout",
+			mode:    "sleep",
+			cfg:     Config{Timeout: 100 * time.Millisecond},
+			wantErr: "timed out",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newFakeExecutor(t, tt.mode, tt.cfg)
+			service := artifact.InMemoryService()
+			for name, part := range map[string]*genai.Part{
+				"data.csv":  genai.NewPartFromText("a,b\n1,2\n"),
+				"notes.txt": genai.NewPartFromText("ignored"),
+			} {
+				if _, err := service.Save(t.Context(), &artifact.SaveRequest{AppName: "app", UserID: "user", SessionID: "session", FileName: name, Part: part}); err != nil {
+					t.Fatalf("Save(%q) failed: %v", name, err)
+				}
+			}
+			got, err := e.ExecuteCode(newInvocationContext(t, service), &codeexecutor.Input{Code: "print('hello')", Language: codeexecutor.LanguagePython, ExecutionID: "1"})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ExecuteCode() error = %v, want containing %q", err, tt.wantErr)
+				}
+				if tt.mode == "sleep" && !errors.Is(err, context.DeadlineExceeded) {
+					t.Errorf("ExecuteCode() error = %v, want wrapping context.DeadlineExceeded", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExecuteCode() failed: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("ExecuteCode() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestExecuteCode_Args(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		cfg      Config
+		language string
+		want     []string
+		notWant  []string
+	}{
+		{
+			name:     "defaults",
+			language: codeexecutor.LanguagePython,
+			want:     []string{"--cpus 1 ", "--memory 536870912 ", "--network none", "--runtime runsc", "--read-only", "python:3.12-slim python3 /code/main.py"},
+		},
+		{
+			name:     "go with network",
+			cfg:      Config{Network: true, Runtime: "runc", CPUs: 0.5, Images: map[string]string{codeexecutor.LanguageGo: "golang:alpine"}},
+			language: codeexecutor.LanguageGo,
+			want:     []string{"--cpus 0.5 ", "--runtime runc", "golang:alpine go run /code/main.go"},
+			notWant:  []string{"--network"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newFakeExecutor(t, "ok", tt.cfg)
+			if _, err := e.ExecuteCode(newInvocationContext(t, artifact.InMemoryService()), &codeexecutor.Input{Code: "x", Language: tt.language, InputFiles: []codeexecutor.File{{Name: "data.csv", Content: []byte("x")}}}); err != nil {
+				t.Fatalf("ExecuteCode() failed: %v", err)
+			}
+			data, err := os.ReadFile(os.Getenv("FAKE_ARGS"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			args := string(data)
+			for _, want := range tt.want {
+				if !strings.Contains(args, want) {
+					t.Errorf("args %q don't contain %q", args, want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(args, notWant) {
+					t.Errorf("args %q contain %q", args, notWant)
+				}
+			}
+		})
+	}
+}
+
+func TestExecuteCode_UnsupportedLanguage(t *testing.T) {
+	e := newFakeExecutor(t, "ok", Config{})
+	if _, err := e.ExecuteCode(newInvocationContext(t, artifact.InMemoryService()), &codeexecutor.Input{Code: "x", Language: "ruby"}); err == nil {
+		t.Error("ExecuteCode() succeeded, want an error for the unsupported language")
+	}
+}
+
+func TestNew_NoRuntime(t *testing.T) {
+	for _, cfg := range []Config{
+		{Command: "adk-no-such-container-cli"},
+		{Command: "false"},
+	} {
+		if _, err := New(cfg); !errors.Is(err, codeexecutor.ErrNoRuntime) {
+			t.Errorf("New(%+v) error = %v, want wrapping codeexecutor.ErrNoRuntime", cfg, err)
+		}
+	}
+	dir := t.TempDir()
+	command := filepath.Join(dir, "docker")
+	if err := os.WriteFile(command, []byte(fakeCLI), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(Config{Command: command, Runtime: "kata"}); !errors.Is(err, codeexecutor.ErrNoRuntime) {
+		t.Errorf("New() error = %v, want wrapping codeexecutor.ErrNoRuntime for the unavailable runtime", err)
+	}
+}
+
+func TestWriteFile_Symlinks(t *testing.T) {
+	workspace, outside := t.TempDir(), t.TempDir()
+	target := filepath.Join(outside, ".bashrc")
+	if err := os.WriteFile(target, []byte("host file"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// The code in the container planted links to the files of the host.
+	if err := os.Symlink(target, filepath.Join(workspace, "data.csv")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(workspace, "dir")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writeFile(workspace, "data.csv", []byte("a,b")); err != nil {
+		t.Fatalf("writeFile() error = %v", err)
+	}
+	if err := writeFile(workspace, "dir/.bashrc", []byte("a,b")); err == nil {
+		t.Error("writeFile() through a link out of the workspace succeeded, want an error")
+	}
+
+	if got, err := os.ReadFile(target); err != nil || string(got) != "host file" {
+		t.Errorf("host file = %q, %v, want it unchanged", got, err)
+	}
+	info, err := os.Lstat(filepath.Join(workspace, "data.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.Mode().IsRegular() {
+		t.Errorf("data.csv mode = %v, want a regular file", info.Mode())
+	}
+	if got, err := os.ReadFile(filepath.Join(workspace, "data.csv")); err != nil || string(got) != "a,b" {
+		t.Errorf("data.csv = %q, %v, want %q", got, err, "a,b")
+	}
+	if err := writeFile(workspace, "sub/dir/file.txt", []byte("nested")); err != nil {
+		t.Errorf("writeFile() of a nested file error = %v", err)
+	}
+}
@@ -0,0 +1,300 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package containerexecutor
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/codeexecutor"
+)
+
+// kernelDriver is the Python program of the kernels. It runs the code of each
+// request, a JSON line of its stdin, in the same globals, and replies with a
+// JSON line of the outputs of the code on the original stdout, the stdout of
+// the code going to the stderr of the kernel.
+const kernelDriver = `
+import contextlib, io, json, os, sys, traceback
+replies = os.fdopen(os.dup(1), "w")
+os.dup2(2, 1)
+namespace = {"__name__": "__main__"}
+for line in sys.stdin:
+    code = json.loads(line)["code"]
+    out, err = io.StringIO(), io.StringIO()
+    with contextlib.redirect_stdout(out), contextlib.redirect_stderr(err):
+        try:
+            exec(compile(code, "<code>", "exec"), namespace)
+        except BaseException:
+            traceback.print_exc()
+    replies.write(json.dumps({"stdout": out.getvalue(), "stderr": err.getvalue()}) + "\n")
+    replies.flush()
+`
+
+// kernel is a Python interpreter running in a container, whose executions
+// share their state.
+type kernel struct {
+	id        string
+	name      string // of the container
+	dir       string
+	workspace string
+
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	replies *bufio.Reader
+	stderr  *cappedBuffer
+	idle    *time.Timer
+
+	// mu serializes the executions of the kernel.
+	mu sync.Mutex
+	// inputs are the hashes of the input files copied in the workspace, by
+	// name, which are copied again only if they changed.
+	inputs map[string][sha256.Size]byte
+	closed bool
+}
+
+type kernelRequest struct {
+	Code string `json:"code"`
+}
+
+type kernelReply struct {
+	Stdout string `json:"stdout"`
+	Stderr string `json:"stderr"`
+}
+
+// executeInKernel runs the code in the kernel of the input, or in a new
+// kernel if it's gone.
+func (e *Executor) executeInKernel(ctx agent.InvocationContext, input *codeexecutor.Input, image string) (*codeexecutor.Result, error) {
+	var k *kernel
+	var preserved bool
+	for {
+		var err error
+		k, preserved, err = e.kernel(input.KernelID, image)
+		if err != nil {
+			return nil, err
+		}
+		k.mu.Lock()
+		if !k.closed {
+			break
+		}
+		// The kernel stopped, e.g. idle, while the execution was waiting
+		// for it: the next one is new.
+		k.mu.Unlock()
+	}
+	defer k.mu.Unlock()
+	k.idle.Stop()
+	defer func() {
+		if !k.closed {
+			k.idle.Reset(e.cfg.KernelIdleTimeout)
+		}
+	}()
+
+	inputs, err := e.inputFiles(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range inputs {
+		sum := sha256.Sum256(file.Content)
+		if k.inputs[file.Name] == sum {
+			continue
+		}
+		if err := writeInputFiles(k.workspace, []codeexecutor.File{file}); err != nil {
+			return nil, err
+		}
+		k.inputs[file.Name] = sum
+	}
+	before, err := snapshot(k.workspace)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := json.Marshal(kernelRequest{Code: input.Code})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode the code: %w", err)
+	}
+	done := make(chan error, 1)
+	var reply kernelReply
+	go func() {
+		if _, err := k.stdin.Write(append(request, '\n')); err != nil {
+			done <- err
+			return
+		}
+		line, err := k.replies.ReadBytes('\n')
+		if err != nil {
+			done <- err
+			return
+		}
+		done <- json.Unmarshal(line, &reply)
+	}()
+	timer := time.NewTimer(e.cfg.Timeout)
+	defer timer.Stop()
+	select {
+	case err = <-done:
+	case <-timer.C:
+		e.closeKernel(k)
+		return nil, fmt.Errorf("code execution timed out after %v, the kernel was stopped: %w", e.cfg.Timeout, context.DeadlineExceeded)
+	case <-ctx.Done():
+		e.closeKernel(k)
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		e.closeKernel(k)
+		return nil, fmt.Errorf("kernel failed: %w: %s", err, k.stderr.String())
+	}
+
+	files, skipped, err := e.outputFiles(k.workspace, before)
+	if err != nil {
+		return nil, err
+	}
+	stdout := &cappedBuffer{max: e.cfg.MaxOutputBytes}
+	stderr := &cappedBuffer{max: e.cfg.MaxOutputBytes}
+	stdout.Write([]byte(reply.Stdout))
+	stderr.Write([]byte(reply.Stderr))
+	result := &codeexecutor.Result{
+		Stdout:         stdout.String(),
+		Stderr:         stderr.String(),
+		OutputFiles:    files,
+		KernelID:       k.id,
+		StatePreserved: preserved,
+	}
+	for _, note := range skipped {
+		result.Stdout += "\n" + note
+	}
+	return result, nil
+}
+
+// kernel returns the running kernel of the ID and true, or a new kernel and
+// false if there is none.
+func (e *Executor) kernel(id, image string) (*kernel, bool, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if k, ok := e.kernels[id]; ok {
+		return k, true, nil
+	}
+	k, err := e.startKernel(image)
+	if err != nil {
+		return nil, false, err
+	}
+	e.kernels[k.id] = k
+	return k, false, nil
+}
+
+// startKernel starts a kernel in a new container.
+func (e *Executor) startKernel(image string) (*kernel, error) {
+	id := uuid.NewString()
+	dir, err := os.MkdirTemp("", "adk-kernel-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the workspace: %w", err)
+	}
+	k := &kernel{
+		id:        id,
+		name:      "adk-kernel-" + id,
+		dir:       dir,
+		workspace: filepath.Join(dir, "workspace"),
+		stderr:    &cappedBuffer{max: e.cfg.MaxOutputBytes},
+		inputs:    map[string][sha256.Size]byte{},
+	}
+	if err := os.Mkdir(k.workspace, 0o755); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("failed to create the workspace: %w", err)
+	}
+	args := append([]string{"run", "-i"}, e.runArgs(k.name, k.workspace, "", image)[1:]...)
+	k.cmd = exec.Command(e.cfg.Command, append(args, "python3", "-u", "-c", kernelDriver)...)
+	k.cmd.Stderr = k.stderr
+	k.stdin, err = k.cmd.StdinPipe()
+	if err == nil {
+		var stdout io.ReadCloser
+		stdout, err = k.cmd.StdoutPipe()
+		k.replies = bufio.NewReader(stdout)
+	}
+	if err == nil {
+		err = k.cmd.Start()
+	}
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("failed to start the kernel: %w", err)
+	}
+	k.idle = time.AfterFunc(e.cfg.KernelIdleTimeout, func() {
+		k.mu.Lock()
+		defer k.mu.Unlock()
+		e.closeKernel(k)
+	})
+	return k, nil
+}
+
+// closeKernel stops the kernel and removes its workspace. The caller holds
+// the lock of the kernel.
+func (e *Executor) closeKernel(k *kernel) {
+	e.mu.Lock()
+	if e.kernels[k.id] == k {
+		delete(e.kernels, k.id)
+	}
+	e.mu.Unlock()
+	if k.closed {
+		return
+	}
+	k.closed = true
+	k.idle.Stop()
+	k.stdin.Close()
+	e.kill(k.name)
+	if k.cmd.Process != nil {
+		k.cmd.Process.Kill()
+	}
+	k.cmd.Wait()
+	os.RemoveAll(k.dir)
+}
+
+// ResetKernel implements codeexecutor.KernelResetter.
+func (e *Executor) ResetKernel(ctx context.Context, kernelID string) error {
+	e.mu.Lock()
+	k, ok := e.kernels[kernelID]
+	e.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	e.closeKernel(k)
+	return nil
+}
+
+// Close stops the kernels.
+func (e *Executor) Close() error {
+	e.mu.Lock()
+	kernels := make([]*kernel, 0, len(e.kernels))
+	for _, k := range e.kernels {
+		kernels = append(kernels, k)
+	}
+	e.mu.Unlock()
+	var errs []error
+	for _, k := range kernels {
+		errs = append(errs, e.ResetKernel(context.Background(), k.id))
+	}
+	return errors.Join(errs...)
+}
+
+var _ codeexecutor.KernelResetter = (*Executor)(nil)
@@ -0,0 +1,137 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package containerexecutor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"google.golang.org/adk/artifact"
+	"google.golang.org/adk/codeexecutor"
+)
+
+func TestExecuteCode_Kernel(t *testing.T) {
+	e := newFakeExecutor(t, "ok", Config{Stateful: true})
+	t.Cleanup(func() { e.Close() })
+	ctx := newInvocationContext(t, artifact.InMemoryService())
+	execute := func(code, kernelID string) *codeexecutor.Result {
+		t.Helper()
+		result, err := e.ExecuteCode(ctx, &codeexecutor.Input{Code: code, Language: codeexecutor.LanguagePython, KernelID: kernelID})
+		if err != nil {
+			t.Fatalf("ExecuteCode(%q) failed: %v", code, err)
+		}
+		return result
+	}
+
+	first := execute("x = 1", "")
+	if first.KernelID == "" || first.StatePreserved || first.Stdout != "run 1\n" {
+		t.Fatalf("first ExecuteCode() = %+v, want the first run of a new kernel", first)
+	}
+	kernelID := first.KernelID
+	got := execute("write()", kernelID)
+	want := &codeexecutor.Result{
+		Stdout:         "run 2\n",
+		OutputFiles:    []codeexecutor.File{{Name: "out.txt", Content: []byte("run 2\n")}},
+		KernelID:       kernelID,
+		StatePreserved: true,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("second ExecuteCode() mismatch (-want +got):\n%s", diff)
+	}
+	if got := execute("print(x)", kernelID); len(got.OutputFiles) != 0 || got.Stdout != "run 3\n" {
+		t.Errorf("third ExecuteCode() = %+v, want the third run without output files", got)
+	}
+
+	if err := e.ResetKernel(t.Context(), kernelID); err != nil {
+		t.Fatalf("ResetKernel() failed: %v", err)
+	}
+	got = execute("print(x)", kernelID)
+	if got.KernelID == kernelID || got.StatePreserved || got.Stdout != "run 1\n" {
+		t.Errorf("ExecuteCode() after the reset = %+v, want the first run of a new kernel", got)
+	}
+}
+
+func TestExecuteCode_KernelFailures(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		code    string
+		wait    time.Duration
+		wantErr string
+	}{
+		{
+			name:    "timeout",
+			cfg:     Config{Timeout: 100 * time.Millisecond},
+			code:    "sleep()",
+			wantErr: "timed out",
+		},
+		{
+			name:    "crash",
+			code:    "crash()",
+			wantErr: "Segmentation fault",
+		},
+		{
+			name: "idle",
+			cfg:  Config{KernelIdleTimeout: 50 * time.Millisecond},
+			wait: 300 * time.Millisecond,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.Stateful = true
+			e := newFakeExecutor(t, "ok", tt.cfg)
+			t.Cleanup(func() { e.Close() })
+			ctx := newInvocationContext(t, artifact.InMemoryService())
+			first, err := e.ExecuteCode(ctx, &codeexecutor.Input{Code: "x = 1", Language: codeexecutor.LanguagePython})
+			if err != nil {
+				t.Fatalf("ExecuteCode() failed: %v", err)
+			}
+			time.Sleep(tt.wait)
+			if tt.code != "" {
+				_, err := e.ExecuteCode(ctx, &codeexecutor.Input{Code: tt.code, Language: codeexecutor.LanguagePython, KernelID: first.KernelID})
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ExecuteCode(%q) error = %v, want containing %q", tt.code, err, tt.wantErr)
+				}
+				if tt.name == "timeout" && !errors.Is(err, context.DeadlineExceeded) {
+					t.Errorf("ExecuteCode() error = %v, want wrapping context.DeadlineExceeded", err)
+				}
+			}
+			// The kernel is gone.
+			got, err := e.ExecuteCode(ctx, &codeexecutor.Input{Code: "print(x)", Language: codeexecutor.LanguagePython, KernelID: first.KernelID})
+			if err != nil {
+				t.Fatalf("ExecuteCode() failed: %v", err)
+			}
+			if got.KernelID == first.KernelID || got.StatePreserved {
+				t.Errorf("ExecuteCode() = %+v, want a run in a new kernel", got)
+			}
+		})
+	}
+}
+
+func TestExecuteCode_KernelGo(t *testing.T) {
+	e := newFakeExecutor(t, "silent", Config{Stateful: true})
+	got, err := e.ExecuteCode(newInvocationContext(t, artifact.InMemoryService()), &codeexecutor.Input{Code: "package main", Language: codeexecutor.LanguageGo, KernelID: "kernel"})
+	if err != nil {
+		t.Fatalf("ExecuteCode() failed: %v", err)
+	}
+	if got.KernelID != "" || got.StatePreserved {
+		t.Errorf("ExecuteCode() = %+v, want a stateless run", got)
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package codeexecutor defines the interface of the executors running the
+// code written by the models of the agents.
+//
+// When an LLM agent has a [CodeExecutor], the first code block of the
+// replies of its model, in Python or Go, is run by the executor: the reply
+// ends with the code, as an executable code part, and is followed by an
+// event with the result of the execution, as a code execution result part,
+// after which the model is called again. The files written by the code are
+// saved as artifacts of the session.
+//
+// The stateful executors run the executions of a session in a kernel, e.g. a
+// long-lived interpreter, keeping their variables: the ID of the kernel of
+// the session is kept in its state under [KernelStateKey], and the result
+// events report with the [KernelMetadataKey] custom metadata whether the
+// code ran with the state of the previous executions.
+package codeexecutor
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/adk/agent"
+)
+
+// KernelStateKey is the key of the session state holding the ID of the
+// kernel of the stateful executions of the session.
+const KernelStateKey = "adk_code_kernel"
+
+// KernelMetadataKey is the key of the custom metadata of the events of the
+// results of stateful executions, a map with the "kernel_id" of the kernel
+// and whether the "state_preserved" of the previous executions.
+const KernelMetadataKey = "adk_code_kernel"
+
+// Languages of the code.
+const (
+	LanguagePython = "python"
+	LanguageGo     = "go"
+)
+
+// ErrNoRuntime is returned by the executors whose runtime, e.g. a container
+// runtime, isn't available, rather than running the code on the host.
+var ErrNoRuntime = errors.New("no code execution runtime available")
+
+// CodeExecutor runs code.
+type CodeExecutor interface {
+	// ExecuteCode runs the code of the input. The failures of the code, e.g.
+	// its exceptions or a non-zero exit status, are reported in the stderr
+	// of the result; the errors are the failures of the executor. The
+	// executions which time out fail with an error wrapping
+	// context.DeadlineExceeded.
+	ExecuteCode(ctx agent.InvocationContext, input *Input) (*Result, error)
+}
+
+// Input is the code to run.
+type Input struct {
+	// Code is the source code, in the Language.
+	Code     string
+	Language string
+	// InputFiles are files available to the code, in its working
+	// directory.
+	InputFiles []File
+	// ExecutionID identifies the execution, e.g. to name its resources.
+	ExecutionID string
+	// KernelID is the ID of the kernel of the previous executions of the
+	// session, for the stateful executors, empty if there is none.
+	KernelID string
+}
+
+// Result is the output of the code.
+type Result struct {
+	Stdout string
+	Stderr string
+	// OutputFiles are the files written by the code in its working
+	// directory.
+	OutputFiles []File
+	// KernelID is the ID of the kernel which ran the code, empty if the
+	// executor isn't stateful.
+	KernelID string
+	// StatePreserved reports whether the code ran with the state of the
+	// previous executions of the kernel of the Input. It's false when the
+	// kernel is new, e.g. after its idle timeout, a reset or a restart of
+	// the server.
+	StatePreserved bool
+}
+
+// KernelResetter is implemented by the stateful executors whose kernels can
+// be reset, e.g. by a tool of the agent.
+type KernelResetter interface {
+	// ResetKernel stops the kernel: the next execution of its session starts
+	// from a blank state. It's a no-op if the kernel is already gone.
+	ResetKernel(ctx context.Context, kernelID string) error
+}
+
+// File is a file read or written by the code.
+type File struct {
+	// Name is the path of the file, relative to the working directory.
+	Name     string
+	MIMEType string
+	Content  []byte
+}
@@ -0,0 +1,41 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package example defines the few-shot examples of the LLM agents, which are
+// included in their model requests to show the model how to answer, e.g.
+// which tools to call, and the [Provider] interface selecting them
+// dynamically, e.g. by retrieving the examples closest to the query.
+package example
+
+import (
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+)
+
+// Example is an example conversation: the input of the user and the
+// expected output of the model, e.g. a function call content, the function
+// response content, and the final text content of the model.
+type Example struct {
+	Input  *genai.Content
+	Output []*genai.Content
+}
+
+// Provider selects the examples of the model requests.
+type Provider interface {
+	// Examples returns the examples for the query, the text of the user
+	// content of the invocation, in order of relevance: when the examples
+	// exceed their token budget, the last ones are dropped.
+	Examples(ctx agent.ReadonlyContext, query string) ([]*Example, error)
+}
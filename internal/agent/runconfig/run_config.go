@@ -14,7 +14,12 @@
 
 package runconfig
 
-import "context"
+import (
+	"context"
+	"sync/atomic"
+
+	"google.golang.org/adk/agent"
+)
 
 type StreamingMode string
 
@@ -26,6 +31,23 @@ const (
 
 type RunConfig struct {
 	StreamingMode StreamingMode
+	// MaxLLMCalls is the maximum number of model calls of the invocation,
+	// zero for no limit.
+	MaxLLMCalls int
+	// LiveRequests is the queue of the requests of the user of a live run.
+	LiveRequests *agent.LiveRequestQueue
+
+	llmCalls atomic.Int64
+}
+
+// CountLLMCall counts a model call of the invocation, and reports whether
+// it is within MaxLLMCalls.
+func (c *RunConfig) CountLLMCall() bool {
+	if c == nil {
+		return true
+	}
+	n := c.llmCalls.Add(1)
+	return c.MaxLLMCalls <= 0 || n <= int64(c.MaxLLMCalls)
 }
 
 func ToContext(ctx context.Context, cfg *RunConfig) context.Context {
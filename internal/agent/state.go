@@ -31,6 +31,7 @@ const (
 	TypeLoopAgent       Type = "LoopAgent"
 	TypeSequentialAgent Type = "SequentialAgent"
 	TypeParallelAgent   Type = "ParallelAgent"
+	TypeGraphAgent      Type = "GraphAgent"
 	TypeCustomAgent     Type = "CustomAgent"
 )
 
@@ -16,6 +16,7 @@ package artifact
 
 import (
 	"context"
+	"time"
 
 	"google.golang.org/genai"
 
@@ -60,6 +61,37 @@ func (a *Artifacts) LoadVersion(ctx context.Context, name string, version int) (
 	})
 }
 
+// LoadStream implements [agent.ArtifactStreamer].
+func (a *Artifacts) LoadStream(ctx context.Context, name string) (*artifact.LoadStreamResponse, error) {
+	return artifact.LoadStream(ctx, a.Service, &artifact.LoadRequest{
+		AppName:   a.AppName,
+		UserID:    a.UserID,
+		SessionID: a.SessionID,
+		FileName:  name,
+	})
+}
+
+// SignURL implements [agent.ArtifactURLSigner].
+func (a *Artifacts) SignURL(ctx context.Context, name string, ttl time.Duration) (*artifact.SignURLResponse, error) {
+	return artifact.SignURL(ctx, a.Service, &artifact.SignURLRequest{
+		AppName:   a.AppName,
+		UserID:    a.UserID,
+		SessionID: a.SessionID,
+		FileName:  name,
+		TTL:       ttl,
+	})
+}
+
+// Versions implements [agent.ArtifactVersioner].
+func (a *Artifacts) Versions(ctx context.Context, name string) (*artifact.VersionsResponse, error) {
+	return a.Service.Versions(ctx, &artifact.VersionsRequest{
+		AppName:   a.AppName,
+		UserID:    a.UserID,
+		SessionID: a.SessionID,
+		FileName:  name,
+	})
+}
+
 func (a *Artifacts) List(ctx context.Context) (*artifact.ListResponse, error) {
 	return a.Service.List(ctx, &artifact.ListRequest{
 		AppName:   a.AppName,
@@ -68,4 +100,9 @@ func (a *Artifacts) List(ctx context.Context) (*artifact.ListResponse, error) {
 	})
 }
 
-var _ agent.Artifacts = (*Artifacts)(nil)
+var (
+	_ agent.Artifacts         = (*Artifacts)(nil)
+	_ agent.ArtifactStreamer  = (*Artifacts)(nil)
+	_ agent.ArtifactURLSigner = (*Artifacts)(nil)
+	_ agent.ArtifactVersioner = (*Artifacts)(nil)
+)
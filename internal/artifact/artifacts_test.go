@@ -88,6 +88,14 @@ func TestArtifacts_WithLoadVersion(t *testing.T) {
 	if diff := cmp.Diff(part2, loadResp.Part); diff != "" {
 		t.Errorf("Loaded part differs from saved part (-want +got):\n%s", diff)
 	}
+
+	versionsResp, err := a.Versions(t.Context(), "testArtifact")
+	if err != nil {
+		t.Fatalf("Versions failed: %v", err)
+	}
+	if diff := cmp.Diff([]int64{2, 1}, versionsResp.Versions); diff != "" {
+		t.Errorf("Versions returned unexpected versions (-want +got):\n%s", diff)
+	}
 }
 
 func TestArtifacts_Errors(t *testing.T) {
@@ -18,9 +18,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"google.golang.org/genai"
@@ -56,6 +59,445 @@ func TestArtifactService(t *testing.T, name string, factory func(t *testing.T) (
 		}
 		testArtifactService_UserScoped(ctx, t, srv, name)
 	})
+	t.Run(fmt.Sprintf("Test%sArtifactService_DeleteUserData", name), func(t *testing.T) {
+		ctx := t.Context()
+		// Create the service using the factory for this sub-test
+		srv, err := factory(t)
+		if err != nil {
+			t.Fatalf("Failed to set up service: %v", err)
+		}
+		testArtifactService_DeleteUserData(ctx, t, srv)
+	})
+	t.Run(fmt.Sprintf("Test%sArtifactService_LoadStream", name), func(t *testing.T) {
+		ctx := t.Context()
+		// Create the service using the factory for this sub-test
+		srv, err := factory(t)
+		if err != nil {
+			t.Fatalf("Failed to set up service: %v", err)
+		}
+		testArtifactService_LoadStream(ctx, t, srv)
+	})
+	t.Run(fmt.Sprintf("Test%sArtifactService_Metadata", name), func(t *testing.T) {
+		ctx := t.Context()
+		// Create the service using the factory for this sub-test
+		srv, err := factory(t)
+		if err != nil {
+			t.Fatalf("Failed to set up service: %v", err)
+		}
+		testArtifactService_Metadata(ctx, t, srv)
+	})
+	t.Run(fmt.Sprintf("Test%sArtifactService_GetUsage", name), func(t *testing.T) {
+		ctx := t.Context()
+		// Create the service using the factory for this sub-test
+		srv, err := factory(t)
+		if err != nil {
+			t.Fatalf("Failed to set up service: %v", err)
+		}
+		testArtifactService_GetUsage(ctx, t, srv)
+	})
+	t.Run(fmt.Sprintf("Test%sArtifactService_SignURL", name), func(t *testing.T) {
+		ctx := t.Context()
+		// Create the service using the factory for this sub-test
+		srv, err := factory(t)
+		if err != nil {
+			t.Fatalf("Failed to set up service: %v", err)
+		}
+		testArtifactService_SignURL(ctx, t, srv)
+	})
+	t.Run(fmt.Sprintf("Test%sArtifactService_Walk", name), func(t *testing.T) {
+		ctx := t.Context()
+		// Create the service using the factory for this sub-test
+		srv, err := factory(t)
+		if err != nil {
+			t.Fatalf("Failed to set up service: %v", err)
+		}
+		testArtifactService_Walk(ctx, t, srv)
+	})
+}
+
+func testArtifactService_Walk(ctx context.Context, t *testing.T, srv artifact.Service) {
+	walker, ok := srv.(artifact.Walker)
+	if !ok {
+		t.Skip("the service doesn't enumerate its versions")
+	}
+	// The decorators of other services implement Walker, but fail if the
+	// services they wrap don't.
+	for _, err := range walker.Walk(ctx) {
+		if errors.Is(err, errors.ErrUnsupported) {
+			t.Skip("the service doesn't enumerate its versions")
+		}
+		break
+	}
+
+	before := time.Now()
+	saves := []struct {
+		appName, userID, sessionID, fileName string
+		data                                 string
+	}{
+		{"testapp", "testuser", "session1", "file1", "one"},
+		{"testapp", "testuser", "session1", "file1", "two!"},
+		{"testapp", "testuser", "session2", "file2", "three"},
+		{"testapp", "testuser", "session1", "user:profile", "four"},
+		{"testapp", "testuser2", "session1", "file1", "five"},
+		{"testapp2", "testuser", "session1", "file1", "six"},
+	}
+	for _, save := range saves {
+		if _, err := srv.Save(ctx, &artifact.SaveRequest{
+			AppName: save.appName, UserID: save.userID, SessionID: save.sessionID, FileName: save.fileName,
+			Part: genai.NewPartFromBytes([]byte(save.data), "text/plain"),
+		}); err != nil {
+			t.Fatalf("Save(%+v) failed: %v", save, err)
+		}
+	}
+	if err := srv.Delete(ctx, &artifact.DeleteRequest{AppName: "testapp2", UserID: "testuser", SessionID: "session1", FileName: "file1"}); err != nil {
+		t.Fatalf("Delete() failed: %v", err)
+	}
+	after := time.Now()
+
+	var got []artifact.VersionInfo
+	for info, err := range walker.Walk(ctx) {
+		if err != nil {
+			t.Fatalf("Walk() failed: %v", err)
+		}
+		// Some stores keep the times with a precision of a second.
+		if info.CreateTime.Before(before.Add(-time.Second)) || info.CreateTime.After(after.Add(time.Second)) {
+			t.Errorf("Walk() version %+v was created at %v, want between %v and %v", info, info.CreateTime, before, after)
+		}
+		info.CreateTime = time.Time{}
+		got = append(got, *info)
+	}
+	key := func(info artifact.VersionInfo) string {
+		return fmt.Sprintf("%s/%s/%s/%s/%d", info.AppName, info.UserID, info.SessionID, info.FileName, info.Version)
+	}
+	slices.SortFunc(got, func(a, b artifact.VersionInfo) int {
+		return strings.Compare(key(a), key(b))
+	})
+	want := []artifact.VersionInfo{
+		{AppName: "testapp", UserID: "testuser", FileName: "user:profile", Version: 1, Size: 4},
+		{AppName: "testapp", UserID: "testuser", SessionID: "session1", FileName: "file1", Version: 1, Size: 3},
+		{AppName: "testapp", UserID: "testuser", SessionID: "session1", FileName: "file1", Version: 2, Size: 4},
+		{AppName: "testapp", UserID: "testuser", SessionID: "session2", FileName: "file2", Version: 1, Size: 5},
+		{AppName: "testapp", UserID: "testuser2", SessionID: "session1", FileName: "file1", Version: 1, Size: 4},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Walk() mismatch (-want +got):\n%s", diff)
+	}
+
+	// The iteration can be stopped.
+	for range walker.Walk(ctx) {
+		break
+	}
+}
+
+func testArtifactService_SignURL(ctx context.Context, t *testing.T, srv artifact.Service) {
+	signer, ok := srv.(artifact.URLSigner)
+	if !ok {
+		t.Skip("the service doesn't sign URLs")
+	}
+	// The decorators of other services implement URLSigner, but fail if the
+	// services they wrap don't.
+	if _, err := signer.SignURL(ctx, &artifact.SignURLRequest{
+		AppName: "testapp", UserID: "testuser", SessionID: "session1", FileName: "file1", TTL: time.Minute,
+	}); errors.Is(err, errors.ErrUnsupported) {
+		t.Skip("the service doesn't sign URLs")
+	}
+	for _, data := range []string{"one", "two"} {
+		if _, err := srv.Save(ctx, &artifact.SaveRequest{
+			AppName: "testapp", UserID: "testuser", SessionID: "session1", FileName: "file1",
+			Part: genai.NewPartFromBytes([]byte(data), "text/plain"),
+		}); err != nil {
+			t.Fatalf("Save() failed: %v", err)
+		}
+	}
+
+	urls := map[string]int64{}
+	for _, tc := range []struct {
+		name        string
+		version     int64
+		wantVersion int64
+		wantErr     error
+	}{
+		{name: "latest", wantVersion: 2},
+		{name: "version", version: 1, wantVersion: 1},
+		{name: "missing version", version: 3, wantErr: fs.ErrNotExist},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			before := time.Now()
+			resp, err := signer.SignURL(ctx, &artifact.SignURLRequest{
+				AppName: "testapp", UserID: "testuser", SessionID: "session1", FileName: "file1",
+				Version: tc.version, TTL: 10 * time.Minute,
+			})
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("SignURL() error = %v, want %v", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SignURL() failed: %v", err)
+			}
+			if resp.Version != tc.wantVersion {
+				t.Errorf("SignURL() version = %d, want %d", resp.Version, tc.wantVersion)
+			}
+			if resp.URL == "" {
+				t.Errorf("SignURL() returned an empty URL")
+			}
+			if version, ok := urls[resp.URL]; ok {
+				t.Errorf("SignURL() returned the URL of version %d for version %d", version, resp.Version)
+			}
+			urls[resp.URL] = resp.Version
+			if resp.Expires.Before(before.Add(10*time.Minute)) || resp.Expires.After(time.Now().Add(10*time.Minute)) {
+				t.Errorf("SignURL() expires at %v, want 10 minutes after the call at %v", resp.Expires, before)
+			}
+		})
+	}
+
+	for _, req := range []*artifact.SignURLRequest{
+		{AppName: "testapp", UserID: "testuser", SessionID: "session1", FileName: "missing", TTL: time.Minute},
+		{AppName: "testapp", UserID: "testuser", SessionID: "session1", FileName: "file1"},
+		{AppName: "testapp", UserID: "testuser", SessionID: "session1", FileName: "file1", TTL: artifact.MaxSignedURLTTL + time.Second},
+	} {
+		if _, err := signer.SignURL(ctx, req); err == nil {
+			t.Errorf("SignURL(%+v) succeeded, want an error", req)
+		}
+	}
+}
+
+func testArtifactService_Metadata(ctx context.Context, t *testing.T, srv artifact.Service) {
+	metadata := map[string]string{"source": "camera", "caption_v2": "A cat, on a mat."}
+	saves := []struct {
+		fileName string
+		part     *genai.Part
+		metadata map[string]string
+	}{
+		{"image", genai.NewPartFromBytes([]byte("png"), "image/png"), metadata},
+		{"notes", genai.NewPartFromText("hello"), metadata},
+		{"plain", genai.NewPartFromText("hello"), nil},
+	}
+	for _, save := range saves {
+		if _, err := srv.Save(ctx, &artifact.SaveRequest{
+			AppName: "testapp", UserID: "testuser", SessionID: "session1", FileName: save.fileName,
+			Part: save.part, Metadata: save.metadata,
+		}); err != nil {
+			t.Fatalf("Save(%s) failed: %v", save.fileName, err)
+		}
+	}
+	for _, save := range saves {
+		req := &artifact.LoadRequest{AppName: "testapp", UserID: "testuser", SessionID: "session1", FileName: save.fileName}
+		got, err := srv.Load(ctx, req)
+		if err != nil {
+			t.Fatalf("Load(%s) failed: %v", save.fileName, err)
+		}
+		if diff := cmp.Diff(save.part, got.Part); diff != "" {
+			t.Errorf("Load(%s) part mismatch (-want +got):\n%s", save.fileName, diff)
+		}
+		if diff := cmp.Diff(save.metadata, got.Metadata); diff != "" {
+			t.Errorf("Load(%s) metadata mismatch (-want +got):\n%s", save.fileName, diff)
+		}
+		if loader, ok := srv.(artifact.StreamLoader); ok {
+			resp, err := loader.LoadStream(ctx, req)
+			if err != nil {
+				t.Fatalf("LoadStream(%s) failed: %v", save.fileName, err)
+			}
+			resp.Content.Close()
+			if diff := cmp.Diff(save.metadata, resp.Metadata); diff != "" {
+				t.Errorf("LoadStream(%s) metadata mismatch (-want +got):\n%s", save.fileName, diff)
+			}
+		}
+	}
+
+	if _, err := srv.Save(ctx, &artifact.SaveRequest{
+		AppName: "testapp", UserID: "testuser", SessionID: "session1", FileName: "invalid",
+		Part: genai.NewPartFromText("hello"), Metadata: map[string]string{"adk-part-kind": "bytes"},
+	}); err == nil {
+		t.Errorf("Save() with a reserved metadata key succeeded, want an error")
+	}
+}
+
+func testArtifactService_GetUsage(ctx context.Context, t *testing.T, srv artifact.Service) {
+	reporter, ok := srv.(artifact.UsageReporter)
+	if !ok {
+		t.Skip("the service doesn't report the usage of the users")
+	}
+	saves := []struct {
+		appName, userID, sessionID, fileName string
+		part                                 *genai.Part
+	}{
+		{"testapp", "testuser", "session1", "file1", genai.NewPartFromBytes([]byte("12345"), "application/octet-stream")},
+		{"testapp", "testuser", "session1", "file1", genai.NewPartFromBytes([]byte("123"), "application/octet-stream")},
+		{"testapp", "testuser", "session2", "file2", genai.NewPartFromText("ab")},
+		{"testapp", "testuser", "session1", "user:profile", genai.NewPartFromText("abcd")},
+		// The artifacts of the other users and apps are not counted.
+		{"testapp", "testuser2", "session1", "file1", genai.NewPartFromText("other")},
+		{"testapp2", "testuser", "session1", "file1", genai.NewPartFromText("other")},
+	}
+	for _, save := range saves {
+		if _, err := srv.Save(ctx, &artifact.SaveRequest{
+			AppName: save.appName, UserID: save.userID, SessionID: save.sessionID, FileName: save.fileName, Part: save.part,
+		}); err != nil {
+			t.Fatalf("Save(%+v) failed: %v", save, err)
+		}
+	}
+	getUsage := func() artifact.GetUsageResponse {
+		t.Helper()
+		resp, err := reporter.GetUsage(ctx, &artifact.GetUsageRequest{AppName: "testapp", UserID: "testuser"})
+		if err != nil {
+			t.Fatalf("GetUsage() failed: %v", err)
+		}
+		return *resp
+	}
+	if diff := cmp.Diff(artifact.GetUsageResponse{Versions: 4, Bytes: 14}, getUsage()); diff != "" {
+		t.Errorf("GetUsage() mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := srv.Delete(ctx, &artifact.DeleteRequest{AppName: "testapp", UserID: "testuser", SessionID: "session1", FileName: "file1", Version: 1}); err != nil {
+		t.Fatalf("Delete(file1@v1) failed: %v", err)
+	}
+	if diff := cmp.Diff(artifact.GetUsageResponse{Versions: 3, Bytes: 9}, getUsage()); diff != "" {
+		t.Errorf("GetUsage() after deleting a version mismatch (-want +got):\n%s", diff)
+	}
+	if err := srv.Delete(ctx, &artifact.DeleteRequest{AppName: "testapp", UserID: "testuser", SessionID: "session1", FileName: "user:profile"}); err != nil {
+		t.Fatalf("Delete(user:profile) failed: %v", err)
+	}
+	if diff := cmp.Diff(artifact.GetUsageResponse{Versions: 2, Bytes: 5}, getUsage()); diff != "" {
+		t.Errorf("GetUsage() after deleting an artifact mismatch (-want +got):\n%s", diff)
+	}
+
+	resp, err := reporter.GetUsage(ctx, &artifact.GetUsageRequest{AppName: "testapp", UserID: "nobody"})
+	if err != nil {
+		t.Fatalf("GetUsage(nobody) failed: %v", err)
+	}
+	if diff := cmp.Diff(artifact.GetUsageResponse{}, *resp); diff != "" {
+		t.Errorf("GetUsage(nobody) mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func testArtifactService_LoadStream(ctx context.Context, t *testing.T, srv artifact.Service) {
+	loader, ok := srv.(artifact.StreamLoader)
+	if !ok {
+		t.Skip("the service doesn't stream the artifacts")
+	}
+	for _, save := range []struct {
+		fileName string
+		part     *genai.Part
+	}{
+		{"image", genai.NewPartFromBytes([]byte("one"), "image/png")},
+		{"image", genai.NewPartFromBytes([]byte("two!"), "image/png")},
+		{"user:notes", genai.NewPartFromText("hello")},
+	} {
+		if _, err := srv.Save(ctx, &artifact.SaveRequest{
+			AppName: "testapp", UserID: "testuser", SessionID: "session1", FileName: save.fileName, Part: save.part,
+		}); err != nil {
+			t.Fatalf("Save(%s) failed: %v", save.fileName, err)
+		}
+	}
+
+	type loaded struct {
+		Version int64
+		Text    bool
+		Size    int64
+		Content string
+	}
+	for _, tc := range []struct {
+		name         string
+		fileName     string
+		version      int64
+		want         loaded
+		wantMIMEType string
+	}{
+		{"latest", "image", 0, loaded{Version: 2, Size: 4, Content: "two!"}, "image/png"},
+		{"ver=1", "image", 1, loaded{Version: 1, Size: 3, Content: "one"}, "image/png"},
+		{"text", "user:notes", 0, loaded{Version: 1, Text: true, Size: 5, Content: "hello"}, ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := loader.LoadStream(ctx, &artifact.LoadRequest{
+				AppName: "testapp", UserID: "testuser", SessionID: "session1", FileName: tc.fileName, Version: tc.version,
+			})
+			if err != nil {
+				t.Fatalf("LoadStream() failed: %v", err)
+			}
+			defer resp.Content.Close()
+			content, err := io.ReadAll(resp.Content)
+			if err != nil {
+				t.Fatalf("failed to read the content: %v", err)
+			}
+			got := loaded{Version: resp.Version, Text: resp.Text, Size: resp.Size, Content: string(content)}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("LoadStream() mismatch (-want +got):\n%s", diff)
+			}
+			if tc.wantMIMEType != "" && resp.MIMEType != tc.wantMIMEType {
+				t.Errorf("LoadStream() MIME type = %q, want %q", resp.MIMEType, tc.wantMIMEType)
+			}
+		})
+	}
+
+	_, err := loader.LoadStream(ctx, &artifact.LoadRequest{
+		AppName: "testapp", UserID: "testuser", SessionID: "session1", FileName: "missing",
+	})
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("LoadStream(missing) error = %v, want %v", err, fs.ErrNotExist)
+	}
+}
+
+func testArtifactService_DeleteUserData(ctx context.Context, t *testing.T, srv artifact.Service) {
+	deleter, ok := srv.(artifact.UserDataDeleter)
+	if !ok {
+		t.Skip("the service doesn't delete the artifacts of users")
+	}
+	saves := []struct{ appName, userID, sessionID, fileName string }{
+		{"testapp", "testuser", "session1", "file1"},
+		{"testapp", "testuser", "session1", "file1"},
+		{"testapp", "testuser", "session2", "file2"},
+		{"testapp", "testuser", "session1", "user:profile"},
+		// The artifacts of the other users and apps are kept.
+		{"testapp", "testuser2", "session1", "file1"},
+		{"testapp", "testuser2", "session1", "user:profile"},
+		{"testapp2", "testuser", "session1", "file1"},
+	}
+	for _, save := range saves {
+		if _, err := srv.Save(ctx, &artifact.SaveRequest{
+			AppName: save.appName, UserID: save.userID, SessionID: save.sessionID, FileName: save.fileName,
+			Part: genai.NewPartFromText("content"),
+		}); err != nil {
+			t.Fatalf("Save(%+v) failed: %v", save, err)
+		}
+	}
+
+	resp, err := deleter.DeleteUserData(ctx, &artifact.DeleteUserDataRequest{AppName: "testapp", UserID: "testuser"})
+	if err != nil {
+		t.Fatalf("DeleteUserData() failed: %v", err)
+	}
+	if resp.DeletedVersions != 4 {
+		t.Errorf("DeleteUserData() deleted %d versions, want 4", resp.DeletedVersions)
+	}
+	for _, list := range []struct {
+		appName, userID, sessionID string
+		want                       []string
+	}{
+		{"testapp", "testuser", "session1", nil},
+		{"testapp", "testuser", "session2", nil},
+		{"testapp", "testuser2", "session1", []string{"file1", "user:profile"}},
+		{"testapp2", "testuser", "session1", []string{"file1"}},
+	} {
+		got, err := srv.List(ctx, &artifact.ListRequest{AppName: list.appName, UserID: list.userID, SessionID: list.sessionID})
+		if err != nil {
+			t.Fatalf("List(%+v) failed: %v", list, err)
+		}
+		if len(got.FileNames) == 0 {
+			got.FileNames = nil
+		}
+		if diff := cmp.Diff(list.want, got.FileNames); diff != "" {
+			t.Errorf("List(%s/%s/%s) mismatch (-want +got):\n%s", list.appName, list.userID, list.sessionID, diff)
+		}
+	}
+
+	resp, err = deleter.DeleteUserData(ctx, &artifact.DeleteUserDataRequest{AppName: "testapp", UserID: "testuser"})
+	if err != nil {
+		t.Fatalf("DeleteUserData() again failed: %v", err)
+	}
+	if resp.DeletedVersions != 0 {
+		t.Errorf("DeleteUserData() again deleted %d versions, want 0", resp.DeletedVersions)
+	}
 }
 
 func testArtifactService(ctx context.Context, t *testing.T, srv artifact.Service, testSuffix string) {
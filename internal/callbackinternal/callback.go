@@ -0,0 +1,58 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package callbackinternal runs the callbacks of the agents, recovering
+// their panics.
+package callbackinternal
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// Call returns the result of call, which calls the callback. A panic of the
+// callback is returned as an error naming the callback, so that it doesn't
+// crash the program. The stack of the panic is logged.
+func Call[T any](callback any, call func() (T, error)) (result T, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			name := Name(callback)
+			log.Printf("Callback %s panicked: %v\n%s", name, r, debug.Stack())
+			err = fmt.Errorf("callback %s panicked: %v", name, r)
+		}
+	}()
+	return call()
+}
+
+// Name returns the name of the callback function, without its package
+// path, e.g. "main.logRequest" or "main.main.func1" for a function literal.
+func Name(callback any) string {
+	v := reflect.ValueOf(callback)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return fmt.Sprintf("%T", callback)
+	}
+	fn := runtime.FuncForPC(v.Pointer())
+	if fn == nil {
+		return fmt.Sprintf("%T", callback)
+	}
+	name := fn.Name()
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
@@ -0,0 +1,54 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package callbackinternal
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func logRequest(string) (string, error) {
+	panic("boom")
+}
+
+func TestCall(t *testing.T) {
+	t.Run("Result", func(t *testing.T) {
+		wantErr := errors.New("error")
+		got, err := Call(logRequest, func() (string, error) { return "result", wantErr })
+		if got != "result" || err != wantErr {
+			t.Errorf("Call() = (%q, %v), want (%q, %v)", got, err, "result", wantErr)
+		}
+	})
+	t.Run("Panic", func(t *testing.T) {
+		_, err := Call(logRequest, func() (string, error) { return logRequest("") })
+		if err == nil {
+			t.Fatal("Call() succeeded, want error")
+		}
+		if want := "callback callbackinternal.logRequest panicked: boom"; err.Error() != want {
+			t.Errorf("Call() error = %q, want %q", err, want)
+		}
+	})
+}
+
+func TestName(t *testing.T) {
+	if got, want := Name(logRequest), "callbackinternal.logRequest"; got != want {
+		t.Errorf("Name() = %q, want %q", got, want)
+	}
+	literal := func() {}
+	if got := Name(literal); !strings.HasPrefix(got, "callbackinternal.TestName.func") {
+		t.Errorf("Name() = %q, want the name of the function literal", got)
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package checkpoint records the progress of the invocations run with
+// checkpointing in checkpoint events, and gives it back to the agents of the
+// invocations resuming from them.
+package checkpoint
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/session"
+)
+
+// Checkpoint is the value of a checkpoint event, see
+// [session.CheckpointMetadataKey].
+//
+// The runner records the start of an invocation with the Agent running it,
+// and its end with Complete. The workflow agents record their progress each
+// time one of their sub-agents Ended, with the fields of their kind.
+type Checkpoint struct {
+	// Agent is the agent running the invocation.
+	Agent string `json:"agent,omitempty"`
+	// Complete reports that the invocation ended.
+	Complete bool `json:"complete,omitempty"`
+
+	// Ended is the name of the sub-agent which ended.
+	Ended string `json:"ended,omitempty"`
+	// Step is the index of the next sub-agent to run of a sequential or a
+	// loop agent.
+	Step int `json:"step,omitempty"`
+	// Iteration is the iteration of a loop agent, from 1.
+	Iteration int `json:"iteration,omitempty"`
+	// Done are the names of the sub-agents of a parallel agent which ended.
+	Done []string `json:"done,omitempty"`
+	// Pending are the names of the nodes of a graph agent to run, and Steps
+	// the number of nodes which ran.
+	Pending []string `json:"pending,omitempty"`
+	Steps   uint     `json:"steps,omitempty"`
+}
+
+// Enabled reports whether the invocation records checkpoints.
+func Enabled(ctx agent.InvocationContext) bool {
+	cfg := ctx.RunConfig()
+	return cfg != nil && cfg.Checkpointing
+}
+
+// Event returns the checkpoint event of the agent of the context.
+func Event(ctx agent.InvocationContext, cp Checkpoint) *session.Event {
+	event := session.NewEvent(ctx.InvocationID())
+	event.Author = ctx.Agent().Name()
+	event.Branch = ctx.Branch()
+	event.CustomMetadata = map[string]any{session.CheckpointMetadataKey: cp.value()}
+	return event
+}
+
+// value returns the checkpoint as a map, as it is stored by the sessions
+// in JSON.
+func (cp Checkpoint) value() map[string]any {
+	var value map[string]any
+	b, _ := json.Marshal(cp)
+	_ = json.Unmarshal(b, &value)
+	return value
+}
+
+// FromEvent returns the checkpoint of the event, if it is a checkpoint event.
+func FromEvent(event *session.Event) (Checkpoint, bool) {
+	value, ok := event.CustomMetadata[session.CheckpointMetadataKey]
+	if !ok {
+		return Checkpoint{}, false
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return Checkpoint{}, false
+	}
+	var cp Checkpoint
+	if err := json.Unmarshal(b, &cp); err != nil {
+		return Checkpoint{}, false
+	}
+	return cp, true
+}
+
+// Resumption holds the checkpoints of the workflow agents of a resumed
+// invocation, by agent name. It is safe for concurrent use.
+type Resumption struct {
+	mu          sync.Mutex
+	checkpoints map[string]Checkpoint
+}
+
+// NewResumption returns the resumption from the checkpoints.
+func NewResumption(checkpoints map[string]Checkpoint) *Resumption {
+	return &Resumption{checkpoints: checkpoints}
+}
+
+// ToContext returns the context of the resumed invocation.
+func ToContext(ctx context.Context, r *Resumption) context.Context {
+	return context.WithValue(ctx, resumptionCtxKey, r)
+}
+
+// Resuming reports whether the invocation of the context is resumed.
+func Resuming(ctx context.Context) bool {
+	_, ok := ctx.Value(resumptionCtxKey).(*Resumption)
+	return ok
+}
+
+// Take returns the checkpoint the agent resumes from, if any. The checkpoint
+// is only returned once, so that the later runs of the agent in the
+// invocation start over.
+func Take(ctx context.Context, agentName string) (Checkpoint, bool) {
+	r, ok := ctx.Value(resumptionCtxKey).(*Resumption)
+	if !ok {
+		return Checkpoint{}, false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cp, ok := r.checkpoints[agentName]
+	delete(r.checkpoints, agentName)
+	return cp, ok
+}
+
+type ctxKey int
+
+const resumptionCtxKey ctxKey = 0
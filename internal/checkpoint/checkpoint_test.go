@@ -0,0 +1,85 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checkpoint
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"google.golang.org/adk/session"
+)
+
+func TestFromEvent(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  any
+		want   Checkpoint
+		wantOK bool
+	}{
+		{
+			name:   "value",
+			value:  Checkpoint{Ended: "writer", Iteration: 2, Step: 1}.value(),
+			want:   Checkpoint{Ended: "writer", Iteration: 2, Step: 1},
+			wantOK: true,
+		},
+		{
+			// The numbers of the sessions stored in JSON are float64.
+			name:   "decoded JSON",
+			value:  map[string]any{"ended": "node", "pending": []any{"a", "b"}, "steps": float64(3)},
+			want:   Checkpoint{Ended: "node", Pending: []string{"a", "b"}, Steps: 3},
+			wantOK: true,
+		},
+		{
+			name:  "invalid",
+			value: map[string]any{"step": "one"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := session.NewEvent("invocation")
+			event.CustomMetadata = map[string]any{session.CheckpointMetadataKey: tt.value}
+			got, ok := FromEvent(event)
+			if ok != tt.wantOK {
+				t.Fatalf("FromEvent() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("FromEvent() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+	if _, ok := FromEvent(session.NewEvent("invocation")); ok {
+		t.Error("FromEvent() of an event without checkpoint succeeded")
+	}
+}
+
+func TestTake(t *testing.T) {
+	ctx := context.Background()
+	if _, ok := Take(ctx, "loop"); ok || Resuming(ctx) {
+		t.Fatal("Take() succeeded without resumption")
+	}
+	ctx = ToContext(ctx, NewResumption(map[string]Checkpoint{"loop": {Iteration: 3}}))
+	if !Resuming(ctx) {
+		t.Error("Resuming() = false, want true")
+	}
+	if cp, ok := Take(ctx, "loop"); !ok || cp.Iteration != 3 {
+		t.Errorf("Take() = %+v, %v, want the checkpoint of the loop", cp, ok)
+	}
+	// The later runs of the loop start over.
+	if _, ok := Take(ctx, "loop"); ok {
+		t.Error("second Take() succeeded, want no checkpoint")
+	}
+}
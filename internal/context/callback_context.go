@@ -16,7 +16,10 @@ package context
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"iter"
+	"time"
 
 	"google.golang.org/genai"
 
@@ -45,6 +48,30 @@ func (ia *internalArtifacts) Save(ctx context.Context, name string, data *genai.
 	return resp, nil
 }
 
+// LoadStream implements [agent.ArtifactStreamer].
+func (ia *internalArtifacts) LoadStream(ctx context.Context, name string) (*artifact.LoadStreamResponse, error) {
+	if streamer, ok := ia.Artifacts.(agent.ArtifactStreamer); ok {
+		return streamer.LoadStream(ctx, name)
+	}
+	return nil, fmt.Errorf("artifacts of %T can't be streamed: %w", ia.Artifacts, errors.ErrUnsupported)
+}
+
+// Versions implements [agent.ArtifactVersioner].
+func (ia *internalArtifacts) Versions(ctx context.Context, name string) (*artifact.VersionsResponse, error) {
+	if versioner, ok := ia.Artifacts.(agent.ArtifactVersioner); ok {
+		return versioner.Versions(ctx, name)
+	}
+	return nil, fmt.Errorf("versions of the artifacts of %T can't be listed: %w", ia.Artifacts, errors.ErrUnsupported)
+}
+
+// SignURL implements [agent.ArtifactURLSigner].
+func (ia *internalArtifacts) SignURL(ctx context.Context, name string, ttl time.Duration) (*artifact.SignURLResponse, error) {
+	if signer, ok := ia.Artifacts.(agent.ArtifactURLSigner); ok {
+		return signer.SignURL(ctx, name, ttl)
+	}
+	return nil, fmt.Errorf("artifacts of %T can't be signed: %w", ia.Artifacts, errors.ErrUnsupported)
+}
+
 func NewCallbackContext(ctx agent.InvocationContext) agent.CallbackContext {
 	return newCallbackContext(ctx, make(map[string]any))
 }
@@ -16,6 +16,7 @@ package context
 
 import (
 	"context"
+	"iter"
 
 	"google.golang.org/genai"
 
@@ -70,3 +71,53 @@ func (c *ReadonlyContext) InvocationID() string {
 func (c *ReadonlyContext) UserContent() *genai.Content {
 	return c.InvocationContext.UserContent()
 }
+
+// NewReadonlyContextWithDelta returns the readonly context whose state
+// includes the state delta, e.g. of the events of the invocation which the
+// session doesn't include, like the temporary ones.
+func NewReadonlyContextWithDelta(ctx agent.InvocationContext, delta map[string]any) agent.ReadonlyContext {
+	return &deltaContext{
+		ReadonlyContext: NewReadonlyContext(ctx),
+		state:           &deltaState{state: ctx.Session().State(), delta: delta},
+	}
+}
+
+type deltaContext struct {
+	agent.ReadonlyContext
+	state session.ReadonlyState
+}
+
+func (c *deltaContext) ReadonlyState() session.ReadonlyState {
+	return c.state
+}
+
+// deltaState is the state with a delta applied.
+type deltaState struct {
+	state session.ReadonlyState
+	delta map[string]any
+}
+
+func (s *deltaState) Get(key string) (any, error) {
+	if value, ok := s.delta[key]; ok {
+		return value, nil
+	}
+	return s.state.Get(key)
+}
+
+func (s *deltaState) All() iter.Seq2[string, any] {
+	return func(yield func(string, any) bool) {
+		for key, value := range s.state.All() {
+			if _, ok := s.delta[key]; ok {
+				continue
+			}
+			if !yield(key, value) {
+				return
+			}
+		}
+		for key, value := range s.delta {
+			if !yield(key, value) {
+				return
+			}
+		}
+	}
+}
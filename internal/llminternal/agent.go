@@ -15,10 +15,16 @@
 package llminternal
 
 import (
+	"time"
+
 	"google.golang.org/genai"
 
 	"google.golang.org/adk/agent"
+	"google.golang.org/adk/codeexecutor"
+	"google.golang.org/adk/example"
 	"google.golang.org/adk/model"
+	"google.golang.org/adk/planner"
+	"google.golang.org/adk/session"
 	"google.golang.org/adk/tool"
 )
 
@@ -34,8 +40,18 @@ type State struct {
 	Toolsets []tool.Toolset
 
 	IncludeContents string
+	// ContentsFilter selects the events of the history when IncludeContents
+	// is "filtered".
+	ContentsFilter func(ctx agent.ReadonlyContext, events []*session.Event) []*session.Event
 
 	GenerateContentConfig *genai.GenerateContentConfig
+	// ThinkingConfig overrides the ThinkingConfig of the
+	// GenerateContentConfig.
+	ThinkingConfig *genai.ThinkingConfig
+	// ModelTimeout and ModelIdleTimeout are the default timeouts of the
+	// model requests.
+	ModelTimeout     time.Duration
+	ModelIdleTimeout time.Duration
 
 	Instruction               string
 	InstructionProvider       InstructionProvider
@@ -44,11 +60,50 @@ type State struct {
 
 	DisallowTransferToParent bool
 	DisallowTransferToPeers  bool
+	// AllowedTransferTargets are the names of the agents the agent can
+	// transfer to, among its sub-agents, parent and peers, nil if there is
+	// no allowlist.
+	AllowedTransferTargets []string
+	// TransferLoopThreshold is the number of transfers back to an agent of
+	// the invocation tolerated before the transfer loop is broken, negative
+	// if there is no limit.
+	TransferLoopThreshold int
 
 	InputSchema  *genai.Schema
 	OutputSchema *genai.Schema
+	// OutputRepairAttempts is the number of repair turns of the replies
+	// which don't match the OutputSchema.
+	OutputRepairAttempts int
+	// OutputValidationLenient keeps the replies which still don't match the
+	// OutputSchema after the repair turns, instead of replacing them with
+	// an error.
+	OutputValidationLenient bool
 
 	OutputKey string
+
+	// HistoryMaxTokens is the token budget of the requests, zero if there
+	// is none. The oldest turns of the requests over the budget are
+	// dropped, or summarized by the HistorySummaryModel if it is set.
+	HistoryMaxTokens    int
+	HistorySummaryModel model.LLM
+
+	// CodeExecutor runs the code blocks of the model responses, nil if
+	// they aren't run.
+	CodeExecutor codeexecutor.CodeExecutor
+
+	// Planner makes the model plan before acting, nil if there is none.
+	Planner planner.Planner
+
+	// Examples are the few-shot examples of the requests, unless the
+	// ExampleProvider selects them.
+	Examples        []*example.Example
+	ExampleProvider example.Provider
+	// ExamplesInHistory includes the examples as turns of the contents
+	// before the history, rather than in the system instruction.
+	ExamplesInHistory bool
+	// ExamplesMaxTokens is the token budget of the examples, zero if there
+	// is none.
+	ExamplesMaxTokens int
 }
 
 type InstructionProvider func(ctx agent.ReadonlyContext) (string, error)
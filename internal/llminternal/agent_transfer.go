@@ -81,7 +81,10 @@ func AgentTransferRequestProcessor(ctx agent.InvocationContext, req *model.LLMRe
 
 		// TODO(hyangah): why do we set this up in request processor
 		// instead of registering this as a normal function tool of the Agent?
-		transferToAgentTool := &TransferToAgentTool{}
+		transferToAgentTool := &TransferToAgentTool{
+			targets:   agentNames(targets),
+			enumerate: asLLMAgent(agent).internal().AllowedTransferTargets != nil,
+		}
 		si, err := instructionsForTransferToAgent(agent, parents[agent.Name()], targets, transferToAgentTool)
 		if err != nil {
 			yield(nil, err)
@@ -95,7 +98,14 @@ func AgentTransferRequestProcessor(ctx agent.InvocationContext, req *model.LLMRe
 	}
 }
 
-type TransferToAgentTool struct{}
+// TransferToAgentTool transfers the control to another agent. It only
+// transfers to its targets, if it has any: the calls with another agent
+// return an error to the model. The declaration enumerates the targets of
+// the agents with an allowlist of transfer targets.
+type TransferToAgentTool struct {
+	targets   []string
+	enumerate bool
+}
 
 // Description implements tool.Tool.
 func (t *TransferToAgentTool) Description() string {
@@ -114,6 +124,10 @@ func (t *TransferToAgentTool) IsLongRunning() bool {
 }
 
 func (t *TransferToAgentTool) Declaration() *genai.FunctionDeclaration {
+	var enum []string
+	if t.enumerate {
+		enum = t.targets
+	}
 	return &genai.FunctionDeclaration{
 		Name:        t.Name(),
 		Description: t.Description(),
@@ -123,6 +137,7 @@ func (t *TransferToAgentTool) Declaration() *genai.FunctionDeclaration {
 				"agent_name": {
 					Type:        "string",
 					Description: "the agent name to transfer to",
+					Enum:        enum,
 				},
 			},
 			Required: []string{"agent_name"},
@@ -148,23 +163,40 @@ func (t *TransferToAgentTool) Run(ctx tool.Context, args any) (map[string]any, e
 	if !ok || agent == "" {
 		return nil, fmt.Errorf("empty agent_name: %v", args)
 	}
+	if t.targets != nil && !slices.Contains(t.targets, agent) {
+		// The model sees the error, and can transfer to an allowed agent.
+		return map[string]any{
+			"error":          fmt.Sprintf("agent %s is not a transfer target, transfer to one of the allowed agents", agent),
+			"agent_name":     agent,
+			"allowed_agents": t.targets,
+		}, nil
+	}
 	ctx.Actions().TransferToAgent = agent
 	return map[string]any{}, nil
 }
 
 var _ tool.Tool = (*TransferToAgentTool)(nil)
 
-func transferTargets(agent, parent agent.Agent) []agent.Agent {
-	targets := slices.Clone(agent.SubAgents())
+// TransferTargets returns the agents to which agent can transfer the
+// control, given its parent. It is nil if agent doesn't use transfers.
+func TransferTargets(agent, parent agent.Agent) []agent.Agent {
+	if !shouldUseAutoFlow(agent) {
+		return nil
+	}
+	return transferTargets(agent, parent)
+}
 
+func transferTargets(agent, parent agent.Agent) []agent.Agent {
 	llmAgent := asLLMAgent(agent)
+	targets := allowedTransferTargets(llmAgent, agent.SubAgents())
+
 	llmParent := asLLMAgent(parent)
 
 	if llmParent == nil {
 		return targets
 	}
 
-	if !llmAgent.internal().DisallowTransferToParent {
+	if !llmAgent.internal().DisallowTransferToParent && isAllowedTransferTarget(llmAgent, parent.Name()) {
 		targets = append(targets, parent)
 	}
 	// For peer-agent transfers, it's only enabled when all below conditions are met:
@@ -172,7 +204,7 @@ func transferTargets(agent, parent agent.Agent) []agent.Agent {
 	// - DisallowTransferToPeers is false.
 	if !llmAgent.internal().DisallowTransferToPeers {
 		if shouldUseAutoFlow(parent) {
-			for _, peer := range parent.SubAgents() {
+			for _, peer := range allowedTransferTargets(llmAgent, parent.SubAgents()) {
 				if peer.Name() != agent.Name() {
 					targets = append(targets, peer)
 				}
@@ -182,6 +214,27 @@ func transferTargets(agent, parent agent.Agent) []agent.Agent {
 	return targets
 }
 
+// allowedTransferTargets returns the agents in the AllowedTransferTargets of
+// the LLM agent, all of them if it has no allowlist.
+func allowedTransferTargets(llmAgent Agent, agents []agent.Agent) []agent.Agent {
+	return slices.DeleteFunc(slices.Clone(agents), func(a agent.Agent) bool {
+		return !isAllowedTransferTarget(llmAgent, a.Name())
+	})
+}
+
+func isAllowedTransferTarget(llmAgent Agent, name string) bool {
+	allowed := llmAgent.internal().AllowedTransferTargets
+	return allowed == nil || slices.Contains(allowed, name)
+}
+
+func agentNames(agents []agent.Agent) []string {
+	names := make([]string, len(agents))
+	for i, a := range agents {
+		names[i] = a.Name()
+	}
+	return names
+}
+
 func asLLMAgent(agent agent.Agent) Agent {
 	if agent == nil {
 		return nil
@@ -254,7 +307,7 @@ var transferToAgentPromptTmpl = template.Must(
 	template.New("transfer_to_agent_prompt").Parse(agentTransferInstructionTemplate))
 
 func instructionsForTransferToAgent(curAgent, parent agent.Agent, targets []agent.Agent, transferTool tool.Tool) (string, error) {
-	if asLLMAgent(curAgent).internal().DisallowTransferToParent {
+	if asLLMAgent(curAgent).internal().DisallowTransferToParent || !slices.Contains(targets, parent) {
 		parent = nil
 	}
 
@@ -295,3 +348,42 @@ you are best for answering the question according to the descriptions, transfer
 to your parent agent. If you don't have parent agent, try answer by yourself.
 {{end}}
 `
+
+// isTransferLoop reports whether the transfer returns to an agent which
+// transferred to the agent of the context earlier in the invocation, e.g.
+// A→B→A, with more than TransferLoopThreshold of such returns in the
+// invocation.
+func isTransferLoop(ctx agent.InvocationContext, transfer *session.EventTransfer) bool {
+	llmAgent := asLLMAgent(ctx.Agent())
+	if llmAgent == nil || ctx.Session() == nil || llmAgent.internal().TransferLoopThreshold < 0 {
+		return false
+	}
+	var transfers []*session.EventTransfer
+	for ev := range ctx.Session().Events().All() {
+		if ev.InvocationID == ctx.InvocationID() && ev.Actions.Transfer != nil {
+			transfers = append(transfers, ev.Actions.Transfer)
+		}
+	}
+	transfers = append(transfers, transfer)
+	returns := 0
+	for i, t := range transfers {
+		for _, prev := range transfers[:i] {
+			if prev.FromAgent == t.ToAgent && prev.ToAgent == t.FromAgent {
+				returns++
+				break
+			}
+		}
+	}
+	return returns > llmAgent.internal().TransferLoopThreshold
+}
+
+// transferLoopEvent returns the event escalating the transfer loop instead
+// of the transfer.
+func transferLoopEvent(ctx agent.InvocationContext, transfer *session.EventTransfer) *session.Event {
+	ev := session.NewEvent(ctx.InvocationID())
+	ev.Author = ctx.Agent().Name()
+	ev.Branch = ctx.Branch()
+	ev.Content = genai.NewContentFromText(fmt.Sprintf("Stopped the transfer from agent %s back to agent %s: the agents keep transferring to each other.", transfer.FromAgent, transfer.ToAgent), genai.RoleModel)
+	ev.Actions.Escalate = true
+	return ev
+}
@@ -267,6 +267,37 @@ func TestAgentTransferRequestProcessor(t *testing.T) {
 		check(t, curAgent, root, "", []string{"Sub1", "Sub2"}, []string{"Parent", "Current"})
 	})
 
+	t.Run("AgentWithAllowedTransferTargets", func(t *testing.T) {
+		curAgent := utils.Must(llmagent.New(llmagent.Config{
+			Name:                   "Current",
+			Model:                  llm,
+			AllowedTransferTargets: []string{"Sub2", "Peer"},
+			SubAgents: []agent.Agent{
+				utils.Must(llmagent.New(llmagent.Config{
+					Name:  "Sub1",
+					Model: llm,
+				})),
+				utils.Must(llmagent.New(llmagent.Config{
+					Name:  "Sub2",
+					Model: llm,
+				})),
+			},
+		}))
+		root := utils.Must(llmagent.New(llmagent.Config{
+			Name:  "Parent",
+			Model: llm,
+			SubAgents: []agent.Agent{
+				curAgent,
+				utils.Must(llmagent.New(llmagent.Config{
+					Name:  "Peer",
+					Model: llm,
+				})),
+			},
+		}))
+
+		check(t, curAgent, root, "", []string{"Sub2", "Peer"}, []string{"Parent", "Sub1", "Current"})
+	})
+
 	t.Run("AgentWithDisallowTransferToPeers", func(t *testing.T) {
 		curAgent := utils.Must(llmagent.New(llmagent.Config{
 			Name:                    "Current",
@@ -465,6 +496,53 @@ func TestTransferToAgentToolRun(t *testing.T) {
 		}
 	})
 
+	t.Run("DisallowedTarget", func(t *testing.T) {
+		llm := &struct{ model.LLM }{}
+		curAgent := utils.Must(llmagent.New(llmagent.Config{
+			Name:                   "Current",
+			Model:                  llm,
+			AllowedTransferTargets: []string{"Sub2"},
+			SubAgents: []agent.Agent{
+				utils.Must(llmagent.New(llmagent.Config{Name: "Sub1", Model: llm})),
+				utils.Must(llmagent.New(llmagent.Config{Name: "Sub2", Model: llm})),
+			},
+		}))
+		parents, err := parentmap.New(curAgent)
+		if err != nil {
+			t.Fatal(err)
+		}
+		invCtx := icontext.NewInvocationContext(parentmap.ToContext(t.Context(), parents), icontext.InvocationContextParams{
+			Agent: curAgent,
+		})
+
+		req := &model.LLMRequest{}
+		for _, err := range llminternal.AgentTransferRequestProcessor(invCtx, req, &llminternal.Flow{}) {
+			if err != nil {
+				t.Fatalf("AgentTransferRequestProcessor failed: %v", err)
+			}
+		}
+		curTool, ok := req.Tools["transfer_to_agent"].(*llminternal.TransferToAgentTool)
+		if !ok {
+			t.Fatalf("req.Tools does not include transfer_to_agent: %v", req.Tools)
+		}
+		if got, want := curTool.Declaration().Parameters.Properties["agent_name"].Enum, []string{"Sub2"}; !slices.Equal(got, want) {
+			t.Errorf("agent_name enum = %v, want %v", got, want)
+		}
+
+		ctx := toolinternal.NewToolContext(invCtx, "", &session.EventActions{}, nil)
+		args := map[string]any{"agent_name": "Sub1"}
+		got, err := curTool.Run(ctx, args)
+		if err != nil {
+			t.Fatalf("Run(%v) failed: %v", args, err)
+		}
+		if _, ok := got["error"]; !ok {
+			t.Errorf("Run(%v) = %v, want error result", args, got)
+		}
+		if got := ctx.Actions().TransferToAgent; got != "" {
+			t.Errorf("Run(%v) set TransferToAgent = %q, want none", args, got)
+		}
+	})
+
 	t.Run("InvalidArguments", func(t *testing.T) {
 		testCases := []struct {
 			name string
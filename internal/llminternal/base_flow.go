@@ -19,15 +19,22 @@ import (
 	"errors"
 	"fmt"
 	"iter"
+	"log"
 	"maps"
+	"reflect"
+	"runtime/debug"
 	"slices"
 	"strings"
+	"sync"
+	"time"
 
+	"golang.org/x/sync/errgroup"
 	"google.golang.org/genai"
 
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/internal/agent/parentmap"
 	"google.golang.org/adk/internal/agent/runconfig"
+	"google.golang.org/adk/internal/callbackinternal"
 	icontext "google.golang.org/adk/internal/context"
 	"google.golang.org/adk/internal/plugininternal/plugincontext"
 	"google.golang.org/adk/internal/telemetry"
@@ -65,6 +72,32 @@ type Flow struct {
 	BeforeToolCallbacks   []BeforeToolCallback
 	AfterToolCallbacks    []AfterToolCallback
 	OnToolErrorCallbacks  []OnToolErrorCallback
+	ToolOptions           ToolOptions
+
+	// agentTools reports whether the Tools are those of the agent, which
+	// are resolved again for each model request, see toolProcessor.
+	agentTools bool
+}
+
+// ToolOptions are the options of the tool calls of a flow.
+type ToolOptions struct {
+	// Timeout bounds the duration of the calls of the tools which don't
+	// have their own timeout, see [tool.TimeoutTool]. Zero means no timeout.
+	Timeout time.Duration
+	// Parallelism is the maximum number of concurrent calls. Zero or one
+	// means the calls run sequentially.
+	Parallelism int
+	// Retries is the number of times the failed calls are retried.
+	Retries int
+	// FailInvocation ends the invocation with the errors of the calls which
+	// still fail after the retries and the OnToolErrorCallbacks, instead of
+	// returning them to the model.
+	FailInvocation bool
+	// Cache caches the results of the cacheable tools, see
+	// [tool.CacheableTool], in the CacheScope, the session if empty.
+	// Optional: if nil, the results aren't cached.
+	Cache      tool.ResultCache
+	CacheScope tool.CacheScope
 }
 
 var (
@@ -73,8 +106,12 @@ var (
 		toolProcessor,
 		authPreprocessor,
 		RequestConfirmationRequestProcessor,
+		pendingCallsRequestProcessor,
 		instructionsRequestProcessor,
 		identityRequestProcessor,
+		// The examples are appended to the instructions, and to the contents
+		// before the history.
+		examplesRequestProcessor,
 		ContentsRequestProcessor,
 		// Some implementations of NL Planning mark planning contents as thoughts in the post processor.
 		// Since these need to be unmarked, NL Planning should be after contentsRequestProcessor.
@@ -84,6 +121,9 @@ var (
 		codeExecutionRequestProcessor,
 		outputSchemaRequestProcessor,
 		AgentTransferRequestProcessor,
+		// The history budget should be after the processors which add to the
+		// request, so that all its tokens are counted.
+		historyBudgetRequestProcessor,
 		removeDisplayNameIfExists,
 	}
 	DefaultResponseProcessors = []func(ctx agent.InvocationContext, req *model.LLMRequest, resp *model.LLMResponse) error{
@@ -93,6 +133,9 @@ var (
 )
 
 func (f *Flow) Run(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+	if queue, ok := f.liveQueue(ctx); ok {
+		return f.runLive(ctx, queue)
+	}
 	return func(yield func(*session.Event, error) bool) {
 		for {
 			var lastEvent *session.Event
@@ -150,7 +193,7 @@ func (f *Flow) runOneStep(ctx agent.InvocationContext) iter.Seq2[*session.Event,
 		// Create event to pass to callback state delta
 		stateDelta := make(map[string]any)
 		// Calls the LLM.
-		for resp, err := range f.callLLM(ctx, req, stateDelta) {
+		for resp, err := range f.callLLMWithOutputValidation(ctx, req, stateDelta) {
 			if err != nil {
 				yield(nil, err)
 				return
@@ -179,16 +222,39 @@ func (f *Flow) runOneStep(ctx agent.InvocationContext) iter.Seq2[*session.Event,
 			}
 
 			// Build the event and yield.
-			modelResponseEvent := f.finalizeModelResponseEvent(ctx, resp, tools, stateDelta)
+			modelResponseEvent := f.finalizeModelResponseEvent(ctx, req, resp, tools, stateDelta)
+			if resp.ErrorCode == agent.ErrorCodeLLMCallsLimit {
+				// The escalation stops the workflow agents running the
+				// agent, up to the root.
+				modelResponseEvent.Actions.Escalate = true
+				modelResponseEvent.Actions.EscalateToRoot = true
+			}
 			telemetry.TraceLLMCall(spans, ctx, req, modelResponseEvent)
 			if !yield(modelResponseEvent, nil) {
 				return
 			}
 			// TODO: generate and yield an auth event if needed.
 
+			// Run the code of the response, the model continues after its
+			// result.
+			codeEvent, err := f.executeCode(ctx, resp)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if codeEvent != nil {
+				if !yield(codeEvent, nil) {
+					return
+				}
+				continue
+			}
+
 			// Handle function calls.
 
-			ev, err := f.handleFunctionCalls(ctx, tools, resp, nil)
+			ev, stopped, err := f.streamFunctionCalls(ctx, tools, resp, nil, yield)
+			if stopped {
+				return
+			}
 			if err != nil {
 				yield(nil, err)
 				return
@@ -198,6 +264,15 @@ func (f *Flow) runOneStep(ctx agent.InvocationContext) iter.Seq2[*session.Event,
 				continue
 			}
 
+			var loopEvent *session.Event
+			if ev.Actions.TransferToAgent != "" {
+				ev.Actions.Transfer = &session.EventTransfer{FromAgent: ctx.Agent().Name(), ToAgent: ev.Actions.TransferToAgent}
+				if isTransferLoop(ctx, ev.Actions.Transfer) {
+					loopEvent = transferLoopEvent(ctx, ev.Actions.Transfer)
+					ev.Actions.TransferToAgent, ev.Actions.Transfer = "", nil
+				}
+			}
+
 			toolConfirmationEvent := generateRequestConfirmationEvent(ctx, modelResponseEvent, ev)
 			if toolConfirmationEvent != nil {
 				if !yield(toolConfirmationEvent, nil) {
@@ -208,6 +283,10 @@ func (f *Flow) runOneStep(ctx agent.InvocationContext) iter.Seq2[*session.Event,
 			if !yield(ev, nil) {
 				return
 			}
+			if loopEvent != nil {
+				yield(loopEvent, nil)
+				return
+			}
 
 			// If the model response is structured, yield it as a final model response event.
 			outputSchemaResponse, err := retrieveStructuredModelResponse(ev)
@@ -297,7 +376,9 @@ func (f *Flow) callLLM(ctx agent.InvocationContext, req *model.LLMRequest, state
 
 		for _, callback := range f.BeforeModelCallbacks {
 			cctx := icontext.NewCallbackContextWithDelta(ctx, stateDelta)
-			callbackResponse, callbackErr := callback(cctx, req)
+			callbackResponse, callbackErr := callbackinternal.Call(callback, func() (*model.LLMResponse, error) {
+				return callback(cctx, req)
+			})
 
 			if callbackResponse != nil || callbackErr != nil {
 				yield(callbackResponse, callbackErr)
@@ -309,9 +390,18 @@ func (f *Flow) callLLM(ctx agent.InvocationContext, req *model.LLMRequest, state
 		// to help with slicing the billing reports on a per-agent basis.
 
 		// TODO: RunLive mode when invocation_context.run_config.support_cfc is true.
-		useStream := runconfig.FromContext(ctx).StreamingMode == runconfig.StreamingModeSSE
+		runConfig := runconfig.FromContext(ctx)
+		useStream := runConfig.StreamingMode == runconfig.StreamingModeSSE
+
+		// The model call exceeding the limit of the invocation isn't made,
+		// its response ends the invocation.
+		if !runConfig.CountLLMCall() {
+			limitErr := &agent.LLMCallsLimitError{Limit: runConfig.MaxLLMCalls}
+			yield(&model.LLMResponse{ErrorCode: agent.ErrorCodeLLMCallsLimit, ErrorMessage: limitErr.Error(), TurnComplete: true}, nil)
+			return
+		}
 
-		for resp, err := range f.Model.GenerateContent(ctx, req, useStream) {
+		for resp, err := range model.GenerateWithTimeouts(ctx, f.Model, req, useStream) {
 			if err != nil {
 				cbResp, cbErr := f.runOnModelErrorCallbacks(ctx, req, stateDelta, err)
 				if cbErr != nil {
@@ -319,8 +409,13 @@ func (f *Flow) callLLM(ctx agent.InvocationContext, req *model.LLMRequest, state
 					return
 				}
 				if cbResp == nil {
-					yield(nil, err)
-					return
+					// The timeouts end the model call, not the invocation.
+					var timeoutErr *model.TimeoutError
+					if !errors.As(err, &timeoutErr) {
+						yield(nil, err)
+						return
+					}
+					cbResp = &model.LLMResponse{ErrorCode: model.ErrorCodeTimeout, ErrorMessage: err.Error(), TurnComplete: true}
 				}
 				resp = cbResp
 				err = cbErr
@@ -367,7 +462,9 @@ func (f *Flow) runAfterModelCallbacks(ctx agent.InvocationContext, llmResp *mode
 
 	for _, callback := range f.AfterModelCallbacks {
 		cctx := icontext.NewCallbackContextWithDelta(ctx, stateDelta)
-		callbackResponse, callbackErr := callback(cctx, llmResp, llmErr)
+		callbackResponse, callbackErr := callbackinternal.Call(callback, func() (*model.LLMResponse, error) {
+			return callback(cctx, llmResp, llmErr)
+		})
 
 		if callbackResponse != nil || callbackErr != nil {
 			return callbackResponse, callbackErr
@@ -389,7 +486,9 @@ func (f *Flow) runOnModelErrorCallbacks(ctx agent.InvocationContext, llmReq *mod
 
 	for _, callback := range f.OnModelErrorCallbacks {
 		cctx := icontext.NewCallbackContextWithDelta(ctx, stateDelta)
-		callbackResponse, callbackErr := callback(cctx, llmReq, llmErr)
+		callbackResponse, callbackErr := callbackinternal.Call(callback, func() (*model.LLMResponse, error) {
+			return callback(cctx, llmReq, llmErr)
+		})
 
 		if callbackResponse != nil || callbackErr != nil {
 			return callbackResponse, callbackErr
@@ -423,7 +522,7 @@ func (f *Flow) agentToRun(ctx agent.InvocationContext, agentName string) agent.A
 	return nil
 }
 
-func (f *Flow) finalizeModelResponseEvent(ctx agent.InvocationContext, resp *model.LLMResponse, tools map[string]tool.Tool, stateDelta map[string]any) *session.Event {
+func (f *Flow) finalizeModelResponseEvent(ctx agent.InvocationContext, req *model.LLMRequest, resp *model.LLMResponse, tools map[string]tool.Tool, stateDelta map[string]any) *session.Event {
 	// FunctionCall & FunctionResponse matching algorithm assumes non-empty function call IDs
 	// but function call ID is optional in genai API and some models do not use the field.
 	// Generate function call ids. (see functions.populate_client_function_call_id in python SDK)
@@ -434,6 +533,23 @@ func (f *Flow) finalizeModelResponseEvent(ctx agent.InvocationContext, resp *mod
 	ev.Branch = ctx.Branch()
 	ev.LLMResponse = *resp
 	ev.Actions.StateDelta = stateDelta
+	if len(req.CustomMetadata) > 0 {
+		// The metadata of the response takes precedence over the one of the
+		// request. The map of the response belongs to the model.
+		metadata := maps.Clone(req.CustomMetadata)
+		maps.Copy(metadata, resp.CustomMetadata)
+		ev.CustomMetadata = metadata
+	}
+	// Record the model of the usage, for its accounting per model.
+	if resp.UsageMetadata != nil && req.Model != "" {
+		if _, ok := ev.CustomMetadata[model.ModelMetadataKey]; !ok {
+			ev.CustomMetadata = maps.Clone(ev.CustomMetadata)
+			if ev.CustomMetadata == nil {
+				ev.CustomMetadata = make(map[string]any)
+			}
+			ev.CustomMetadata[model.ModelMetadataKey] = req.Model
+		}
+	}
 
 	// Populate ev.LongRunningToolIDs
 	ev.LongRunningToolIDs = findLongRunningFunctionCallIDs(resp.Content, tools)
@@ -486,77 +602,226 @@ Suggested fixes:
 
 // handleFunctionCalls calls the functions and returns the function response event.
 //
+// The calls run concurrently, at most Parallelism of the ToolOptions at a
+// time, except for the calls of the tools which aren't safe for parallel
+// calls, see [tool.ParallelSafeTool], which run alone. The function
+// responses keep the order of the calls, and the custom metadata of the event
+// records the duration of each call with the [ToolCallDurationsMetadataKey],
+// and the calls served from the tool cache with the
+// [CachedToolCallsMetadataKey].
+//
 // TODO: accept filters to include/exclude function calls.
-// TODO: check feasibility of running tool.Run concurrently.
 func (f *Flow) handleFunctionCalls(ctx agent.InvocationContext, toolsDict map[string]tool.Tool, resp *model.LLMResponse, toolConfirmations map[string]*toolconfirmation.ToolConfirmation) (*session.Event, error) {
-	var fnResponseEvents []*session.Event
-
 	fnCalls := utils.FunctionCalls(resp.Content)
 	toolNames := slices.Collect(maps.Keys(toolsDict))
-	var result map[string]any
-	for _, fnCall := range fnCalls {
+	if f.ToolOptions.Cache != nil {
+		ctx = ctx.WithContext(toolinternal.WithToolCache(ctx, &toolinternal.ToolCache{
+			Cache: f.ToolOptions.Cache,
+			Scope: f.ToolOptions.CacheScope,
+		}))
+	}
+	calls := make([]functionCallResult, len(fnCalls))
+	// The calls of the tools which aren't parallel-safe hold the lock,
+	// the others share it.
+	var exclusive sync.RWMutex
+	var g errgroup.Group
+	g.SetLimit(max(f.ToolOptions.Parallelism, 1))
+	for i, fnCall := range fnCalls {
 		var confirmation *toolconfirmation.ToolConfirmation
 		if toolConfirmations != nil {
 			confirmation = toolConfirmations[fnCall.ID]
 		}
-		toolCtx := toolinternal.NewToolContext(ctx, fnCall.ID, &session.EventActions{StateDelta: make(map[string]any)}, confirmation)
-
-		spans := telemetry.StartTrace(ctx, "execute_tool "+fnCall.Name)
-		curTool, found := toolsDict[fnCall.Name]
-		if !found {
-			err := newToolNotFoundError(fnCall.Name, toolNames)
-			result, err = f.runOnToolErrorCallbacks(toolCtx, &fakeTool{name: fnCall.Name}, fnCall.Args, err)
-			if err != nil {
-				result = map[string]any{"error": err.Error()}
+		g.Go(func() error {
+			curTool := toolsDict[fnCall.Name]
+			if isParallelSafe(curTool) {
+				exclusive.RLock()
+				defer exclusive.RUnlock()
+			} else {
+				exclusive.Lock()
+				defer exclusive.Unlock()
 			}
-		} else if funcTool, ok := curTool.(toolinternal.FunctionTool); !ok {
-			err := newToolNotFoundError(fnCall.Name, toolNames)
-			result, err = f.runOnToolErrorCallbacks(toolCtx, &fakeTool{name: fnCall.Name}, fnCall.Args, err)
-			if err != nil {
-				result = map[string]any{"error": err.Error()}
-			}
-		} else {
-			result = f.callTool(toolCtx, funcTool, fnCall.Args)
-		}
-
-		// TODO: handle long-running tool.
-		ev := session.NewEvent(ctx.InvocationID())
-		ev.LLMResponse = model.LLMResponse{
-			Content: &genai.Content{
-				Role: "user",
-				Parts: []*genai.Part{
-					{
-						FunctionResponse: &genai.FunctionResponse{
-							ID:       fnCall.ID,
-							Name:     fnCall.Name,
-							Response: result,
-						},
-					},
-				},
-			},
-		}
-		ev.Author = ctx.Agent().Name()
-		ev.Branch = ctx.Branch()
-		ev.Actions = *toolCtx.Actions()
+			var err error
+			calls[i], err = f.handleFunctionCall(ctx, curTool, toolNames, fnCall, confirmation)
+			return err
+		})
+	}
+	// The failures of calls don't cancel the other calls.
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
 
-		traceTool := curTool
-		if traceTool == nil {
-			traceTool = &fakeTool{name: fnCall.Name}
+	var fnResponseEvents []*session.Event
+	var pendingIDs, cachedIDs []string
+	durations := make(map[string]any, len(calls))
+	for _, c := range calls {
+		fnResponseEvents = append(fnResponseEvents, c.event)
+		if c.pending {
+			pendingIDs = append(pendingIDs, c.id)
 		}
-		telemetry.TraceToolCall(spans, traceTool, fnCall.Args, ev)
-
-		fnResponseEvents = append(fnResponseEvents, ev)
+		if c.cached {
+			cachedIDs = append(cachedIDs, c.id)
+		}
+		durations[c.id] = c.duration.Milliseconds()
 	}
 	mergedEvent, err := mergeParallelFunctionResponseEvents(fnResponseEvents)
 	if err != nil {
 		return mergedEvent, err
 	}
+	if mergedEvent != nil {
+		if mergedEvent.CustomMetadata == nil {
+			mergedEvent.CustomMetadata = make(map[string]any)
+		}
+		mergedEvent.CustomMetadata[ToolCallDurationsMetadataKey] = durations
+		if len(cachedIDs) > 0 {
+			mergedEvent.CustomMetadata[CachedToolCallsMetadataKey] = cachedIDs
+		}
+	}
+	if mergedEvent != nil && len(pendingIDs) > 0 {
+		// The pending responses end the invocation, until the results of
+		// the calls are submitted.
+		mergedEvent.LongRunningToolIDs = pendingIDs
+	}
 	// this is needed for debug traces of parallel calls
 	spans := telemetry.StartTrace(ctx, "execute_tool (merged)")
 	telemetry.TraceMergedToolCalls(spans, mergedEvent)
 	return mergedEvent, nil
 }
 
+// streamFunctionCalls handles the function calls like handleFunctionCalls,
+// and yields the events the tools forward while they run, see
+// [toolinternal.ForwardEvent], which then belong to the invocation. If the
+// consumer of the events stops, the calls are cancelled, and it reports that
+// it stopped.
+func (f *Flow) streamFunctionCalls(ctx agent.InvocationContext, toolsDict map[string]tool.Tool, resp *model.LLMResponse, toolConfirmations map[string]*toolconfirmation.ToolConfirmation, yield func(*session.Event, error) bool) (ev *session.Event, stopped bool, err error) {
+	callCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	forwarded := make(chan *session.Event)
+	forwardCtx := toolinternal.WithEventForwarder(callCtx, func(ev *session.Event) {
+		ev.InvocationID = ctx.InvocationID()
+		select {
+		case forwarded <- ev:
+		case <-callCtx.Done():
+		}
+	})
+
+	type result struct {
+		ev  *session.Event
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		ev, err := f.handleFunctionCalls(ctx.WithContext(forwardCtx), toolsDict, resp, toolConfirmations)
+		done <- result{ev, err}
+	}()
+	for {
+		select {
+		case fwd := <-forwarded:
+			if !yield(fwd, nil) {
+				cancel()
+				<-done
+				return nil, true, nil
+			}
+		case r := <-done:
+			return r.ev, false, r.err
+		}
+	}
+}
+
+// ToolCallDurationsMetadataKey is the custom metadata key of the function
+// response events, whose value maps the IDs of the function calls to their
+// durations in milliseconds.
+const ToolCallDurationsMetadataKey = "adk_tool_call_durations"
+
+// CachedToolCallsMetadataKey is the custom metadata key of the function
+// response events, whose value lists the IDs of the function calls whose
+// results were served from the tool cache.
+const CachedToolCallsMetadataKey = "adk_cached_tool_calls"
+
+// functionCallResult is the result of a function call of a model response.
+type functionCallResult struct {
+	id       string
+	event    *session.Event
+	duration time.Duration
+	// pending reports whether the response of the call is pending, see
+	// [isPending].
+	pending bool
+	// cached reports whether the result of the call was served from the
+	// tool cache.
+	cached bool
+}
+
+// handleFunctionCall calls the function of the tool, nil if it isn't
+// known, and returns the function response event.
+func (f *Flow) handleFunctionCall(ctx agent.InvocationContext, curTool tool.Tool, toolNames []string, fnCall *genai.FunctionCall, confirmation *toolconfirmation.ToolConfirmation) (functionCallResult, error) {
+	start := time.Now()
+	toolCtx := toolinternal.NewToolContext(ctx, fnCall.ID, &session.EventActions{StateDelta: make(map[string]any)}, confirmation)
+
+	spans := telemetry.StartTrace(ctx, "execute_tool "+fnCall.Name)
+	var result map[string]any
+	var pending bool
+	if funcTool, ok := curTool.(toolinternal.FunctionTool); !ok {
+		err := newToolNotFoundError(fnCall.Name, toolNames)
+		result, err = f.runOnToolErrorCallbacks(toolCtx, &fakeTool{name: fnCall.Name}, fnCall.Args, err)
+		if err != nil {
+			result = map[string]any{"error": err.Error()}
+		}
+	} else {
+		var err error
+		result, err = f.runToolCall(toolCtx, funcTool, fnCall.Args)
+		if err != nil {
+			if f.ToolOptions.FailInvocation && !confirmationRequested(toolCtx) {
+				return functionCallResult{}, fmt.Errorf("call of tool %q failed: %w", fnCall.Name, err)
+			}
+			result = map[string]any{"error": err.Error()}
+		} else if _, failed := result["error"]; !failed && isPending(curTool) {
+			pending = true
+		}
+	}
+
+	ev := session.NewEvent(ctx.InvocationID())
+	ev.LLMResponse = model.LLMResponse{
+		Content: &genai.Content{
+			Role: "user",
+			Parts: []*genai.Part{
+				{
+					FunctionResponse: &genai.FunctionResponse{
+						ID:       fnCall.ID,
+						Name:     fnCall.Name,
+						Response: result,
+						Parts:    toolinternal.ResponseParts(toolCtx),
+					},
+				},
+			},
+		},
+	}
+	ev.GroundingMetadata = toolinternal.GroundingMetadata(toolCtx)
+	ev.Author = ctx.Agent().Name()
+	ev.Branch = ctx.Branch()
+	ev.Actions = *toolCtx.Actions()
+
+	traceTool := curTool
+	if traceTool == nil {
+		traceTool = &fakeTool{name: fnCall.Name}
+	}
+	duration := time.Since(start)
+	telemetry.TraceToolCall(spans, traceTool, fnCall.Args, ev, duration)
+	return functionCallResult{id: fnCall.ID, event: ev, duration: duration, pending: pending, cached: toolinternal.IsCachedResult(toolCtx)}, nil
+}
+
+// isParallelSafe reports whether the tool can be called concurrently with
+// other tools, see [tool.ParallelSafeTool].
+func isParallelSafe(t tool.Tool) bool {
+	p, ok := t.(tool.ParallelSafeTool)
+	return !ok || p.ParallelSafe()
+}
+
+// isPending reports whether the responses of the tool are pending, see
+// [toolinternal.PendingTool].
+func isPending(t tool.Tool) bool {
+	p, ok := t.(toolinternal.PendingTool)
+	return ok && t.IsLongRunning() && p.PausesInvocation()
+}
+
 func (f *Flow) runOnToolErrorCallbacks(toolCtx tool.Context, tool tool.Tool, fArgs map[string]any, err error) (map[string]any, error) {
 	pluginManager := pluginManagerFromContext(toolCtx)
 	if pluginManager != nil {
@@ -569,6 +834,16 @@ func (f *Flow) runOnToolErrorCallbacks(toolCtx tool.Context, tool tool.Tool, fAr
 }
 
 func (f *Flow) callTool(toolCtx tool.Context, tool toolinternal.FunctionTool, fArgs map[string]any) map[string]any {
+	response, err := f.runToolCall(toolCtx, tool, fArgs)
+	if err != nil {
+		return map[string]any{"error": err.Error()}
+	}
+	return response
+}
+
+// runToolCall runs the tool with its callbacks, and returns the error of the
+// call which remains after the callbacks.
+func (f *Flow) runToolCall(toolCtx tool.Context, tool toolinternal.FunctionTool, fArgs map[string]any) (map[string]any, error) {
 	var response map[string]any
 	var err error
 	pluginManager := pluginManagerFromContext(toolCtx)
@@ -580,7 +855,7 @@ func (f *Flow) callTool(toolCtx tool.Context, tool toolinternal.FunctionTool, fA
 	}
 
 	if response == nil && err == nil {
-		response, err = tool.Run(toolCtx, fArgs)
+		response, err = runCachedTool(toolCtx, tool, fArgs, f.runTool)
 	}
 
 	var errorResponse map[string]any
@@ -608,16 +883,110 @@ func (f *Flow) callTool(toolCtx tool.Context, tool toolinternal.FunctionTool, fA
 		response = alteredResponse
 		err = alteredErr
 	}
+	return response, err
+}
 
-	if err != nil {
-		return map[string]any{"error": err.Error()}
+// runCachedTool serves the result of the call from the tool cache of the
+// context, if the tool is cacheable, and otherwise runs the tool with run,
+// caching its successful results.
+func runCachedTool(toolCtx tool.Context, t toolinternal.FunctionTool, args map[string]any, run func(tool.Context, toolinternal.FunctionTool, map[string]any) (map[string]any, error)) (map[string]any, error) {
+	cache := toolinternal.ToolCacheFromContext(toolCtx)
+	c, ok := t.(tool.CacheableTool)
+	if cache == nil || !ok || c.CacheTTL() <= 0 {
+		return run(toolCtx, t, args)
+	}
+	if result, ok := cache.Get(toolCtx, t.Name(), args); ok {
+		toolinternal.MarkCachedResult(toolCtx)
+		return result, nil
+	}
+	result, err := run(toolCtx, t, args)
+	if _, failed := result["error"]; err == nil && !failed && !confirmationRequested(toolCtx) {
+		cache.Set(toolCtx, t.Name(), args, result, c.CacheTTL())
+	}
+	return result, err
+}
+
+// runTool runs the tool, and retries the failed calls as many times as the
+// Retries of the ToolOptions, unless the invocation is cancelled or the tool
+// requested a confirmation.
+func (f *Flow) runTool(toolCtx tool.Context, t toolinternal.FunctionTool, args map[string]any) (map[string]any, error) {
+	for attempt := 0; ; attempt++ {
+		result, err := f.runToolOnce(toolCtx, t, args)
+		if err == nil || attempt >= f.ToolOptions.Retries || toolCtx.Err() != nil || confirmationRequested(toolCtx) {
+			return result, err
+		}
+		log.Printf("Call %s of tool %s failed, retrying: %v", toolCtx.FunctionCallID(), t.Name(), err)
 	}
-	return response
+}
+
+// runToolOnce runs the tool within its timeout. The calls which time out
+// are abandoned: they end with an error wrapping
+// [context.DeadlineExceeded], while the tool may still run until it checks
+// its context. The tool runs in an attempt of the tool context, see
+// [toolinternal.WithAttempt], whose actions are dropped if it times out.
+func (f *Flow) runToolOnce(toolCtx tool.Context, t toolinternal.FunctionTool, args map[string]any) (map[string]any, error) {
+	timeout := f.ToolOptions.Timeout
+	if tt, ok := t.(tool.TimeoutTool); ok && tt.Timeout() != 0 {
+		timeout = tt.Timeout()
+	}
+	if timeout <= 0 {
+		return safeRun(toolCtx, t, args)
+	}
+
+	ctx, cancel := context.WithTimeout(toolCtx, timeout)
+	defer cancel()
+	type result struct {
+		response map[string]any
+		err      error
+	}
+	attempt, commit := toolinternal.WithAttempt(toolCtx, ctx)
+	done := make(chan result, 1)
+	go func() {
+		response, err := safeRun(attempt, t, args)
+		done <- result{response, err}
+	}()
+	select {
+	case r := <-done:
+		commit()
+		return r.response, r.err
+	case <-ctx.Done():
+		select {
+		case r := <-done:
+			commit()
+			return r.response, r.err
+		default:
+		}
+		if err := toolCtx.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("tool %q timed out after %v: %w", t.Name(), timeout, context.DeadlineExceeded)
+	}
+}
+
+// safeRun runs the tool, and returns its panic as an error, so that it
+// doesn't end the turn. The stack of the panic is logged.
+func safeRun(ctx tool.Context, t toolinternal.FunctionTool, args map[string]any) (result map[string]any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Tool %s panicked: %v\n%s", t.Name(), r, debug.Stack())
+			err = fmt.Errorf("tool %q panicked: %v", t.Name(), r)
+		}
+	}()
+	return t.Run(ctx, args)
+}
+
+// confirmationRequested reports whether the tool requested a confirmation of
+// its call, in which case its error isn't a failure.
+func confirmationRequested(toolCtx tool.Context) bool {
+	_, ok := toolCtx.Actions().RequestedToolConfirmations[toolCtx.FunctionCallID()]
+	return ok
 }
 
 func (f *Flow) invokeBeforeToolCallbacks(toolCtx tool.Context, tool tool.Tool, fArgs map[string]any) (map[string]any, error) {
 	for _, callback := range f.BeforeToolCallbacks {
-		result, err := callback(toolCtx, tool, fArgs)
+		result, err := callbackinternal.Call(callback, func() (map[string]any, error) {
+			return callback(toolCtx, tool, fArgs)
+		})
 		if err != nil {
 			return nil, err
 		}
@@ -632,7 +1001,9 @@ func (f *Flow) invokeBeforeToolCallbacks(toolCtx tool.Context, tool tool.Tool, f
 
 func (f *Flow) invokeAfterToolCallbacks(toolCtx tool.Context, tool toolinternal.FunctionTool, fArgs, fResult map[string]any, fErr error) (map[string]any, error) {
 	for _, callback := range f.AfterToolCallbacks {
-		result, err := callback(toolCtx, tool, fArgs, fResult, fErr)
+		result, err := callbackinternal.Call(callback, func() (map[string]any, error) {
+			return callback(toolCtx, tool, fArgs, fResult, fErr)
+		})
 		if err != nil {
 			return nil, err
 		}
@@ -648,7 +1019,9 @@ func (f *Flow) invokeAfterToolCallbacks(toolCtx tool.Context, tool toolinternal.
 
 func (f *Flow) invokeOnToolErrorCallbacks(toolCtx tool.Context, tool tool.Tool, fArgs map[string]any, fErr error) (map[string]any, error) {
 	for _, callback := range f.OnToolErrorCallbacks {
-		result, err := callback(toolCtx, tool, fArgs, fErr)
+		result, err := callbackinternal.Call(callback, func() (map[string]any, error) {
+			return callback(toolCtx, tool, fArgs, fErr)
+		})
 		if err != nil {
 			return nil, err
 		}
@@ -671,12 +1044,14 @@ func mergeParallelFunctionResponseEvents(events []*session.Event) (*session.Even
 	}
 	var parts []*genai.Part
 	var actions *session.EventActions
+	var grounding *genai.GroundingMetadata
 	for _, ev := range events {
 		if ev == nil || ev.LLMResponse.Content == nil {
 			continue
 		}
 		parts = append(parts, ev.LLMResponse.Content.Parts...)
 		actions = mergeEventActions(actions, &ev.Actions)
+		grounding = mergeGroundingMetadata(grounding, ev.GroundingMetadata)
 	}
 	// reuse events[0]
 	ev := events[0]
@@ -685,6 +1060,7 @@ func mergeParallelFunctionResponseEvents(events []*session.Event) (*session.Even
 			Role:  "user",
 			Parts: parts,
 		},
+		GroundingMetadata: grounding,
 	}
 	ev.Actions = *actions
 	return ev, nil
@@ -703,12 +1079,16 @@ func mergeEventActions(base, other *session.EventActions) *session.EventActions
 	}
 	if other.TransferToAgent != "" {
 		base.TransferToAgent = other.TransferToAgent
+		base.Transfer = other.Transfer
 	}
 	if other.Escalate {
 		base.Escalate = true
 	}
+	if other.EscalateToRoot {
+		base.EscalateToRoot = true
+	}
 	if other.StateDelta != nil {
-		base.StateDelta = deepMergeMap(base.StateDelta, other.StateDelta)
+		base.StateDelta = deepMergeMap(base.StateDelta, other.StateDelta, "")
 	}
 	// TODO add similar logic for state
 	if other.RequestedToolConfirmations != nil {
@@ -720,17 +1100,23 @@ func mergeEventActions(base, other *session.EventActions) *session.EventActions
 	return base
 }
 
-func deepMergeMap(dst, src map[string]any) map[string]any {
+// deepMergeMap merges src into dst, whose values are replaced by those of
+// src, with a warning if they differ. The path is that of the maps in the
+// state delta.
+func deepMergeMap(dst, src map[string]any, path string) map[string]any {
 	if dst == nil {
 		dst = make(map[string]any)
 	}
 	for key, value := range src {
 		if srcMap, ok := value.(map[string]any); ok {
 			if dstMap, ok := dst[key].(map[string]any); ok {
-				dst[key] = deepMergeMap(dstMap, srcMap)
+				dst[key] = deepMergeMap(dstMap, srcMap, path+key+".")
 				continue
 			}
 		}
+		if old, ok := dst[key]; ok && !reflect.DeepEqual(old, value) {
+			log.Printf("Parallel function calls set conflicting values of the state key %q, keeping that of the latest call", path+key)
+		}
 		dst[key] = value
 	}
 	return dst
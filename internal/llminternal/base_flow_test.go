@@ -15,14 +15,22 @@
 package llminternal
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"maps"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"google.golang.org/genai"
 
+	"google.golang.org/adk/agent"
 	icontext "google.golang.org/adk/internal/context"
 	"google.golang.org/adk/internal/toolinternal"
+	"google.golang.org/adk/internal/utils"
 	"google.golang.org/adk/model"
 	"google.golang.org/adk/session"
 	"google.golang.org/adk/tool"
@@ -386,6 +394,30 @@ func TestCallTool(t *testing.T) {
 			},
 			want: map[string]any{"result": "error_handled_in_on_tool_error_callback"},
 		},
+		{
+			name: "before callback panic passed to on tool error callback as error",
+			tool: &mockFunctionTool{
+				name: "testTool",
+				runFunc: func(ctx tool.Context, args map[string]any) (map[string]any, error) {
+					t.Error("tool should not be called")
+					return nil, nil
+				},
+			},
+			beforeToolCallbacks: []BeforeToolCallback{
+				func(ctx tool.Context, tool tool.Tool, args map[string]any) (map[string]any, error) {
+					panic("boom")
+				},
+			},
+			onToolErrorCallbacks: []OnToolErrorCallback{
+				func(ctx tool.Context, tool tool.Tool, args map[string]any, err error) (map[string]any, error) {
+					if err == nil || !strings.Contains(err.Error(), "panicked: boom") {
+						return nil, fmt.Errorf("unexpected error in on tool error callback: %v", err)
+					}
+					return map[string]any{"result": "panic_handled_in_on_tool_error_callback"}, nil
+				},
+			},
+			want: map[string]any{"result": "panic_handled_in_on_tool_error_callback"},
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -575,3 +607,515 @@ func TestMergeEventActions(t *testing.T) {
 		})
 	}
 }
+
+func TestFinalizeModelResponseEvent_CustomMetadata(t *testing.T) {
+	tests := []struct {
+		name     string
+		request  map[string]any
+		response map[string]any
+		usage    bool
+		want     map[string]any
+	}{
+		{
+			name: "no metadata",
+		},
+		{
+			name:     "usage records the model",
+			response: map[string]any{"key": "response"},
+			usage:    true,
+			want:     map[string]any{"key": "response", model.ModelMetadataKey: "test-model"},
+		},
+		{
+			name:     "usage keeps the model of the response",
+			response: map[string]any{model.ModelMetadataKey: "fallback-model"},
+			usage:    true,
+			want:     map[string]any{model.ModelMetadataKey: "fallback-model"},
+		},
+		{
+			name:     "response metadata",
+			response: map[string]any{"key": "response"},
+			want:     map[string]any{"key": "response"},
+		},
+		{
+			name:    "request metadata",
+			request: map[string]any{"key": "request"},
+			want:    map[string]any{"key": "request"},
+		},
+		{
+			name:     "response metadata takes precedence",
+			request:  map[string]any{"key": "request", "other": "request"},
+			response: map[string]any{"key": "response"},
+			want:     map[string]any{"key": "response", "other": "request"},
+		},
+	}
+
+	a := utils.Must(agent.New(agent.Config{Name: "TestAgent"}))
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := icontext.NewInvocationContext(t.Context(), icontext.InvocationContextParams{Agent: a})
+			req := &model.LLMRequest{Model: "test-model", CustomMetadata: tc.request}
+			resp := &model.LLMResponse{CustomMetadata: maps.Clone(tc.response)}
+			if tc.usage {
+				resp.UsageMetadata = &genai.GenerateContentResponseUsageMetadata{TotalTokenCount: 1}
+			}
+
+			f := &Flow{}
+			got := f.finalizeModelResponseEvent(ctx, req, resp, nil, nil)
+			if diff := cmp.Diff(tc.want, got.CustomMetadata); diff != "" {
+				t.Errorf("CustomMetadata mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.response, resp.CustomMetadata); diff != "" {
+				t.Errorf("response CustomMetadata modified (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// timeoutFunctionTool is a mockFunctionTool with its own timeout.
+type timeoutFunctionTool struct {
+	*mockFunctionTool
+	timeout time.Duration
+}
+
+func (m *timeoutFunctionTool) Timeout() time.Duration {
+	return m.timeout
+}
+
+func TestRunToolCall_Options(t *testing.T) {
+	blocked := make(chan struct{})
+	t.Cleanup(func() { close(blocked) })
+	waitForDeadline := func(ctx tool.Context, args map[string]any) (map[string]any, error) {
+		if _, ok := ctx.Deadline(); !ok {
+			return nil, errors.New("no deadline")
+		}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	checkNoDeadline := func(ctx tool.Context, args map[string]any) (map[string]any, error) {
+		if _, ok := ctx.Deadline(); ok {
+			return nil, errors.New("unexpected deadline")
+		}
+		return map[string]any{"result": "ok"}, nil
+	}
+
+	tests := []struct {
+		name         string
+		options      ToolOptions
+		tool         toolinternal.FunctionTool
+		want         map[string]any
+		wantErr      string
+		wantDeadline bool
+	}{
+		{
+			name:    "panic",
+			tool:    &mockFunctionTool{name: "testTool", runFunc: func(tool.Context, map[string]any) (map[string]any, error) { panic("boom") }},
+			wantErr: `tool "testTool" panicked: boom`,
+		},
+		{
+			name:    "panic with a timeout",
+			options: ToolOptions{Timeout: time.Minute},
+			tool:    &mockFunctionTool{name: "testTool", runFunc: func(tool.Context, map[string]any) (map[string]any, error) { panic("boom") }},
+			wantErr: `tool "testTool" panicked: boom`,
+		},
+		{
+			name:         "timeout of the flow",
+			options:      ToolOptions{Timeout: 10 * time.Millisecond},
+			tool:         &mockFunctionTool{name: "testTool", runFunc: waitForDeadline},
+			wantErr:      "context deadline exceeded",
+			wantDeadline: true,
+		},
+		{
+			name:    "timeout of a tool ignoring its context",
+			options: ToolOptions{Timeout: 10 * time.Millisecond},
+			tool: &mockFunctionTool{name: "testTool", runFunc: func(tool.Context, map[string]any) (map[string]any, error) {
+				<-blocked
+				return nil, nil
+			}},
+			wantErr:      `tool "testTool" timed out after 10ms: context deadline exceeded`,
+			wantDeadline: true,
+		},
+		{
+			name:         "timeout of the tool",
+			tool:         &timeoutFunctionTool{mockFunctionTool: &mockFunctionTool{name: "testTool", runFunc: waitForDeadline}, timeout: 10 * time.Millisecond},
+			wantErr:      "context deadline exceeded",
+			wantDeadline: true,
+		},
+		{
+			name:    "tool without timeout",
+			options: ToolOptions{Timeout: 10 * time.Millisecond},
+			tool:    &timeoutFunctionTool{mockFunctionTool: &mockFunctionTool{name: "testTool", runFunc: checkNoDeadline}, timeout: -1},
+			want:    map[string]any{"result": "ok"},
+		},
+		{
+			name: "no timeout",
+			tool: &mockFunctionTool{name: "testTool", runFunc: checkNoDeadline},
+			want: map[string]any{"result": "ok"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			f := &Flow{ToolOptions: tc.options}
+			ctx := icontext.NewInvocationContext(t.Context(), icontext.InvocationContextParams{})
+			got, err := f.runToolCall(toolinternal.NewToolContext(ctx, "", nil, nil), tc.tool, nil)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("runToolCall() error = %v, want %q", err, tc.wantErr)
+				}
+				if tc.wantDeadline && !errors.Is(err, context.DeadlineExceeded) {
+					t.Errorf("runToolCall() error = %v, want a context.DeadlineExceeded", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("runToolCall() error = %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("runToolCall() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRunToolCall_LateActionsOfTimedOutTools(t *testing.T) {
+	release, written := make(chan struct{}), make(chan struct{})
+	tl := &mockFunctionTool{name: "testTool", runFunc: func(ctx tool.Context, args map[string]any) (map[string]any, error) {
+		// The tool ignores its context, and writes its actions after the
+		// deadline.
+		<-release
+		defer close(written)
+		ctx.Actions().StateDelta["late"] = true
+		ctx.Actions().ArtifactDelta = map[string]int64{"late.txt": 1}
+		return nil, nil
+	}}
+	f := &Flow{ToolOptions: ToolOptions{Timeout: 10 * time.Millisecond}}
+	ctx := icontext.NewInvocationContext(t.Context(), icontext.InvocationContextParams{})
+	toolCtx := toolinternal.NewToolContext(ctx, "", nil, nil)
+	if _, err := f.runToolCall(toolCtx, tl, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("runToolCall() error = %v, want a context.DeadlineExceeded", err)
+	}
+
+	close(release)
+	// The actions of the call are read while the abandoned tool runs, as
+	// when its function response event is appended.
+	if got := len(toolCtx.Actions().StateDelta) + len(toolCtx.Actions().ArtifactDelta); got != 0 {
+		t.Errorf("actions of the timed out call have %d deltas, want none", got)
+	}
+	<-written
+	if diff := cmp.Diff(map[string]any{}, toolCtx.Actions().StateDelta); diff != "" {
+		t.Errorf("state delta mismatch (-want +got):\n%s", diff)
+	}
+	if toolCtx.Actions().ArtifactDelta != nil {
+		t.Errorf("artifact delta = %v, want none", toolCtx.Actions().ArtifactDelta)
+	}
+}
+
+func TestRunToolCall_ActionsWithTimeout(t *testing.T) {
+	tl := &mockFunctionTool{name: "testTool", runFunc: func(ctx tool.Context, args map[string]any) (map[string]any, error) {
+		ctx.Actions().StateDelta["key"] = "value"
+		ctx.Actions().SkipSummarization = true
+		return map[string]any{"result": "ok"}, nil
+	}}
+	f := &Flow{ToolOptions: ToolOptions{Timeout: time.Minute}}
+	ctx := icontext.NewInvocationContext(t.Context(), icontext.InvocationContextParams{})
+	toolCtx := toolinternal.NewToolContext(ctx, "", &session.EventActions{StateDelta: map[string]any{"before": "callback"}}, nil)
+	if _, err := f.runToolCall(toolCtx, tl, nil); err != nil {
+		t.Fatalf("runToolCall() error = %v", err)
+	}
+	if diff := cmp.Diff(map[string]any{"before": "callback", "key": "value"}, toolCtx.Actions().StateDelta); diff != "" {
+		t.Errorf("state delta mismatch (-want +got):\n%s", diff)
+	}
+	if !toolCtx.Actions().SkipSummarization {
+		t.Error("SkipSummarization of the tool wasn't kept")
+	}
+}
+
+func TestRunToolCall_Retries(t *testing.T) {
+	tests := []struct {
+		name         string
+		retries      int
+		failures     int
+		want         map[string]any
+		wantErr      bool
+		wantAttempts int
+	}{
+		{name: "no retries", failures: 1, wantErr: true, wantAttempts: 1},
+		{name: "success after retries", retries: 2, failures: 2, want: map[string]any{"result": "ok"}, wantAttempts: 3},
+		{name: "failure after retries", retries: 2, failures: 3, wantErr: true, wantAttempts: 3},
+		{name: "no failure", retries: 2, want: map[string]any{"result": "ok"}, wantAttempts: 1},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			attempts := 0
+			tl := &mockFunctionTool{name: "testTool", runFunc: func(tool.Context, map[string]any) (map[string]any, error) {
+				attempts++
+				if attempts <= tc.failures {
+					return nil, errors.New("transient")
+				}
+				return map[string]any{"result": "ok"}, nil
+			}}
+			f := &Flow{ToolOptions: ToolOptions{Retries: tc.retries}}
+			ctx := icontext.NewInvocationContext(t.Context(), icontext.InvocationContextParams{})
+			got, err := f.runToolCall(toolinternal.NewToolContext(ctx, "", nil, nil), tl, nil)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("runToolCall() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("runToolCall() mismatch (-want +got):\n%s", diff)
+			}
+			if attempts != tc.wantAttempts {
+				t.Errorf("attempts = %d, want %d", attempts, tc.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestHandleFunctionCalls_Failures(t *testing.T) {
+	panicking := &mockFunctionTool{name: "panicking", runFunc: func(tool.Context, map[string]any) (map[string]any, error) { panic("boom") }}
+	sibling := &mockFunctionTool{name: "sibling", runFunc: func(tool.Context, map[string]any) (map[string]any, error) {
+		return map[string]any{"result": "ok"}, nil
+	}}
+	confirming := &mockFunctionTool{name: "confirming", runFunc: func(ctx tool.Context, args map[string]any) (map[string]any, error) {
+		if err := ctx.RequestConfirmation("hint", nil); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("requires confirmation")
+	}}
+	tools := map[string]tool.Tool{"panicking": panicking, "sibling": sibling, "confirming": confirming}
+	resp := func(names ...string) *model.LLMResponse {
+		content := &genai.Content{Role: genai.RoleModel}
+		for _, name := range names {
+			content.Parts = append(content.Parts, &genai.Part{FunctionCall: &genai.FunctionCall{ID: name + "-id", Name: name}})
+		}
+		return &model.LLMResponse{Content: content}
+	}
+
+	tests := []struct {
+		name    string
+		options ToolOptions
+		resp    *model.LLMResponse
+		want    []*genai.FunctionResponse
+		wantErr string
+	}{
+		{
+			name: "panic doesn't stop the sibling calls",
+			resp: resp("panicking", "sibling"),
+			want: []*genai.FunctionResponse{
+				{ID: "panicking-id", Name: "panicking", Response: map[string]any{"error": `tool "panicking" panicked: boom`}},
+				{ID: "sibling-id", Name: "sibling", Response: map[string]any{"result": "ok"}},
+			},
+		},
+		{
+			name:    "failure ends the invocation",
+			options: ToolOptions{FailInvocation: true},
+			resp:    resp("sibling", "panicking"),
+			wantErr: `call of tool "panicking" failed: tool "panicking" panicked: boom`,
+		},
+		{
+			name:    "confirmation request isn't a failure",
+			options: ToolOptions{FailInvocation: true},
+			resp:    resp("confirming"),
+			want: []*genai.FunctionResponse{
+				{ID: "confirming-id", Name: "confirming", Response: map[string]any{"error": "requires confirmation"}},
+			},
+		},
+		{
+			name:    "unknown tool isn't a failure",
+			options: ToolOptions{FailInvocation: true},
+			resp:    resp("unknown"),
+		},
+	}
+
+	a := utils.Must(agent.New(agent.Config{Name: "TestAgent"}))
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := icontext.NewInvocationContext(t.Context(), icontext.InvocationContextParams{Agent: a})
+			f := &Flow{ToolOptions: tc.options}
+			ev, err := f.handleFunctionCalls(ctx, tools, tc.resp, nil)
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Fatalf("handleFunctionCalls() error = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("handleFunctionCalls() error = %v", err)
+			}
+			if tc.want == nil {
+				return
+			}
+			if diff := cmp.Diff(tc.want, utils.FunctionResponses(ev.Content)); diff != "" {
+				t.Errorf("handleFunctionCalls() responses mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// sequentialFunctionTool is a mockFunctionTool which isn't parallel-safe.
+type sequentialFunctionTool struct {
+	*mockFunctionTool
+}
+
+func (m *sequentialFunctionTool) ParallelSafe() bool {
+	return false
+}
+
+func TestHandleFunctionCalls_Parallel(t *testing.T) {
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	// track records the number of concurrent calls.
+	track := func(key string) func(tool.Context, map[string]any) (map[string]any, error) {
+		return func(ctx tool.Context, args map[string]any) (map[string]any, error) {
+			mu.Lock()
+			running++
+			maxRunning = max(maxRunning, running)
+			mu.Unlock()
+			time.Sleep(20 * time.Millisecond)
+			mu.Lock()
+			running--
+			mu.Unlock()
+			ctx.Actions().StateDelta["shared"] = key
+			return map[string]any{"result": key}, nil
+		}
+	}
+	tools := map[string]tool.Tool{
+		"a":          &mockFunctionTool{name: "a", runFunc: track("a")},
+		"b":          &mockFunctionTool{name: "b", runFunc: track("b")},
+		"c":          &mockFunctionTool{name: "c", runFunc: track("c")},
+		"sequential": &sequentialFunctionTool{&mockFunctionTool{name: "sequential", runFunc: track("sequential")}},
+	}
+
+	tests := []struct {
+		name           string
+		parallelism    int
+		calls          []string
+		wantMaxRunning int
+	}{
+		{name: "parallel", parallelism: 3, calls: []string{"a", "b", "c"}, wantMaxRunning: 3},
+		{name: "bounded", parallelism: 2, calls: []string{"a", "b", "c"}, wantMaxRunning: 2},
+		{name: "sequential by default", calls: []string{"a", "b", "c"}, wantMaxRunning: 1},
+		{name: "tool which isn't parallel-safe", parallelism: 3, calls: []string{"sequential", "sequential"}, wantMaxRunning: 1},
+	}
+
+	a := utils.Must(agent.New(agent.Config{Name: "TestAgent"}))
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			running, maxRunning = 0, 0
+			content := &genai.Content{Role: genai.RoleModel}
+			for i, name := range tc.calls {
+				content.Parts = append(content.Parts, &genai.Part{FunctionCall: &genai.FunctionCall{ID: fmt.Sprintf("call-%d", i), Name: name}})
+			}
+			ctx := icontext.NewInvocationContext(t.Context(), icontext.InvocationContextParams{Agent: a})
+			f := &Flow{ToolOptions: ToolOptions{Parallelism: tc.parallelism}}
+			ev, err := f.handleFunctionCalls(ctx, tools, &model.LLMResponse{Content: content}, nil)
+			if err != nil {
+				t.Fatalf("handleFunctionCalls() error = %v", err)
+			}
+
+			if maxRunning != tc.wantMaxRunning {
+				t.Errorf("concurrent calls = %d, want %d", maxRunning, tc.wantMaxRunning)
+			}
+			var want []*genai.FunctionResponse
+			for i, name := range tc.calls {
+				want = append(want, &genai.FunctionResponse{ID: fmt.Sprintf("call-%d", i), Name: name, Response: map[string]any{"result": name}})
+			}
+			if diff := cmp.Diff(want, utils.FunctionResponses(ev.Content)); diff != "" {
+				t.Errorf("handleFunctionCalls() responses mismatch (-want +got):\n%s", diff)
+			}
+			// The state delta of the latest call wins.
+			if got, want := ev.Actions.StateDelta["shared"], tc.calls[len(tc.calls)-1]; got != want {
+				t.Errorf("state delta = %v, want %v", got, want)
+			}
+			durations, ok := ev.CustomMetadata[ToolCallDurationsMetadataKey].(map[string]any)
+			if !ok || len(durations) != len(tc.calls) {
+				t.Fatalf("durations = %v, want one per call", ev.CustomMetadata[ToolCallDurationsMetadataKey])
+			}
+			for id, d := range durations {
+				if ms, ok := d.(int64); !ok || ms < 20 {
+					t.Errorf("duration of call %s = %v, want at least 20ms", id, d)
+				}
+			}
+		})
+	}
+}
+
+type cacheableFunctionTool struct {
+	*mockFunctionTool
+	ttl time.Duration
+}
+
+func (m *cacheableFunctionTool) CacheTTL() time.Duration {
+	return m.ttl
+}
+
+func TestHandleFunctionCalls_Cache(t *testing.T) {
+	runs := map[string]int{}
+	run := func(name string) func(tool.Context, map[string]any) (map[string]any, error) {
+		return func(ctx tool.Context, args map[string]any) (map[string]any, error) {
+			runs[name]++
+			if args["fail"] == true {
+				return nil, fmt.Errorf("failed")
+			}
+			return map[string]any{"result": fmt.Sprintf("%s %v", name, args["q"])}, nil
+		}
+	}
+	tools := map[string]tool.Tool{
+		"lookup":   &cacheableFunctionTool{&mockFunctionTool{name: "lookup", runFunc: run("lookup")}, time.Hour},
+		"uncached": &mockFunctionTool{name: "uncached", runFunc: run("uncached")},
+		"update": &mockFunctionTool{name: "update", runFunc: func(ctx tool.Context, args map[string]any) (map[string]any, error) {
+			return map[string]any{}, ctx.ClearToolCache(ctx, "lookup")
+		}},
+	}
+	a := utils.Must(agent.New(agent.Config{Name: "TestAgent"}))
+	sess, err := session.InMemoryService().Create(t.Context(), &session.CreateRequest{AppName: "app", UserID: "user"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := icontext.NewInvocationContext(t.Context(), icontext.InvocationContextParams{Agent: a, Session: sess.Session})
+	f := &Flow{ToolOptions: ToolOptions{Cache: tool.NewLRUResultCache(1 << 20)}}
+	call := func(name string, args map[string]any) *session.Event {
+		t.Helper()
+		content := &genai.Content{Role: genai.RoleModel, Parts: []*genai.Part{{FunctionCall: &genai.FunctionCall{ID: "call", Name: name, Args: args}}}}
+		ev, err := f.handleFunctionCalls(ctx, tools, &model.LLMResponse{Content: content}, nil)
+		if err != nil {
+			t.Fatalf("handleFunctionCalls() error = %v", err)
+		}
+		return ev
+	}
+
+	steps := []struct {
+		name       string
+		tool       string
+		args       map[string]any
+		wantRuns   map[string]int
+		wantCached bool
+	}{
+		{name: "miss", tool: "lookup", args: map[string]any{"q": "a", "n": 1}, wantRuns: map[string]int{"lookup": 1}},
+		{name: "hit", tool: "lookup", args: map[string]any{"n": 1, "q": "a"}, wantRuns: map[string]int{"lookup": 1}, wantCached: true},
+		{name: "other arguments", tool: "lookup", args: map[string]any{"q": "b"}, wantRuns: map[string]int{"lookup": 2}},
+		{name: "failures aren't cached", tool: "lookup", args: map[string]any{"fail": true}, wantRuns: map[string]int{"lookup": 3}},
+		{name: "failures aren't served", tool: "lookup", args: map[string]any{"fail": true}, wantRuns: map[string]int{"lookup": 4}},
+		{name: "tool which isn't cacheable", tool: "uncached", args: map[string]any{"q": "a"}, wantRuns: map[string]int{"lookup": 4, "uncached": 1}},
+		{name: "tool which isn't cacheable again", tool: "uncached", args: map[string]any{"q": "a"}, wantRuns: map[string]int{"lookup": 4, "uncached": 2}},
+		{name: "clear", tool: "update", wantRuns: map[string]int{"lookup": 4, "uncached": 2}},
+		{name: "miss after clear", tool: "lookup", args: map[string]any{"q": "a", "n": 1}, wantRuns: map[string]int{"lookup": 5, "uncached": 2}},
+		{name: "hit after clear", tool: "lookup", args: map[string]any{"q": "a", "n": 1}, wantRuns: map[string]int{"lookup": 5, "uncached": 2}, wantCached: true},
+	}
+	for _, step := range steps {
+		ev := call(step.tool, step.args)
+		if diff := cmp.Diff(step.wantRuns, runs); diff != "" {
+			t.Errorf("%s: runs mismatch (-want +got):\n%s", step.name, diff)
+		}
+		_, cached := ev.CustomMetadata[CachedToolCallsMetadataKey]
+		if cached != step.wantCached {
+			t.Errorf("%s: cached = %v, want %v", step.name, cached, step.wantCached)
+		}
+		if step.wantCached {
+			if diff := cmp.Diff([]string{"call"}, ev.CustomMetadata[CachedToolCallsMetadataKey]); diff != "" {
+				t.Errorf("%s: cached calls mismatch (-want +got):\n%s", step.name, diff)
+			}
+			if diff := cmp.Diff(map[string]any{"result": "lookup a"}, utils.FunctionResponses(ev.Content)[0].Response); diff != "" {
+				t.Errorf("%s: cached response mismatch (-want +got):\n%s", step.name, diff)
+			}
+		}
+	}
+}
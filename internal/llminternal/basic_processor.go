@@ -42,6 +42,17 @@ func basicRequestProcessor(ctx agent.InvocationContext, req *model.LLMRequest, f
 		if req.Config == nil {
 			req.Config = &genai.GenerateContentConfig{}
 		}
+		req.Timeout = state.ModelTimeout
+		req.IdleTimeout = state.ModelIdleTimeout
+		if state.ThinkingConfig != nil {
+			req.Config.ThinkingConfig = clone(state.ThinkingConfig)
+		}
+		if cfg := ctx.RunConfig(); cfg != nil && len(cfg.ResponseModalities) > 0 {
+			req.Config.ResponseModalities = make([]string, len(cfg.ResponseModalities))
+			for i, modality := range cfg.ResponseModalities {
+				req.Config.ResponseModalities[i] = string(modality)
+			}
+		}
 
 		// Set OutputSchema directly if no tools are present or native combo support exists.
 		// Otherwise, OutputSchemaRequestProcessor will be used to provide a tool-based workaround.
@@ -0,0 +1,208 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llminternal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"mime"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/codeexecutor"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+)
+
+// languageGo is the language of the executable code parts of Go code, which
+// genai doesn't define.
+const languageGo genai.Language = "GO"
+
+// codeBlockPattern matches the fenced code blocks run by the code executors,
+// whose language is the first submatch.
+var codeBlockPattern = regexp.MustCompile("(?s)```(python|py|tool_code|go|golang)[ \t]*\n(.*?)\n?```")
+
+// codeExecutorOf returns the code executor of the agent of the context, nil
+// if it has none.
+func codeExecutorOf(ctx agent.InvocationContext) codeexecutor.CodeExecutor {
+	llmAgent := asLLMAgent(ctx.Agent())
+	if llmAgent == nil {
+		return nil
+	}
+	return llmAgent.internal().CodeExecutor
+}
+
+// codeExecutionRequestProcessor replaces the executable code and code
+// execution result parts of the contents of the requests with their text, as
+// fenced code blocks, when the agent has a code executor: the models only
+// understand these parts when they ran the code themselves.
+func codeExecutionRequestProcessor(ctx agent.InvocationContext, req *model.LLMRequest, f *Flow) iter.Seq2[*session.Event, error] {
+	return func(yield func(*session.Event, error) bool) {
+		if codeExecutorOf(ctx) == nil {
+			return
+		}
+		for i, content := range req.Contents {
+			if content == nil || !hasCodeExecutionParts(content) {
+				continue
+			}
+			converted := *content
+			converted.Parts = make([]*genai.Part, 0, len(content.Parts))
+			for _, part := range content.Parts {
+				switch {
+				case part.ExecutableCode != nil:
+					fence := "```tool_code\n"
+					if part.ExecutableCode.Language == languageGo {
+						fence = "```go\n"
+					}
+					part = genai.NewPartFromText(fence + part.ExecutableCode.Code + "\n```")
+				case part.CodeExecutionResult != nil:
+					part = genai.NewPartFromText("```tool_output\n" + part.CodeExecutionResult.Output + "\n```")
+				}
+				converted.Parts = append(converted.Parts, part)
+			}
+			req.Contents[i] = &converted
+		}
+	}
+}
+
+func hasCodeExecutionParts(content *genai.Content) bool {
+	for _, part := range content.Parts {
+		if part.ExecutableCode != nil || part.CodeExecutionResult != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// codeExecutionResponseProcessor ends the complete model responses with
+// their first code block, as an executable code part, when the agent has a
+// code executor, for [Flow.executeCode] to run it. The parts after the code
+// block are dropped: the model continues after the result of the code.
+func codeExecutionResponseProcessor(ctx agent.InvocationContext, req *model.LLMRequest, resp *model.LLMResponse) error {
+	if resp.Partial || resp.Content == nil || codeExecutorOf(ctx) == nil || hasCodeExecutionParts(resp.Content) {
+		return nil
+	}
+	for i, part := range resp.Content.Parts {
+		if part.Text == "" || part.Thought {
+			continue
+		}
+		loc := codeBlockPattern.FindStringSubmatchIndex(part.Text)
+		if loc == nil {
+			continue
+		}
+		parts := append([]*genai.Part{}, resp.Content.Parts[:i]...)
+		if before := part.Text[:loc[0]]; strings.TrimSpace(before) != "" {
+			parts = append(parts, genai.NewPartFromText(before))
+		}
+		language := genai.LanguagePython
+		if l := part.Text[loc[2]:loc[3]]; l == "go" || l == "golang" {
+			language = languageGo
+		}
+		parts = append(parts, genai.NewPartFromExecutableCode(part.Text[loc[4]:loc[5]], language))
+		content := *resp.Content
+		content.Parts = parts
+		resp.Content = &content
+		return nil
+	}
+	return nil
+}
+
+// executeCode runs the code ending the model response with the code executor
+// of the agent, saves the files written by the code as artifacts, and returns
+// the event of the result of the code, nil if there is no code to run.
+func (f *Flow) executeCode(ctx agent.InvocationContext, resp *model.LLMResponse) (*session.Event, error) {
+	executor := codeExecutorOf(ctx)
+	if executor == nil || resp.Partial || resp.Content == nil || len(resp.Content.Parts) == 0 {
+		return nil, nil
+	}
+	code := resp.Content.Parts[len(resp.Content.Parts)-1].ExecutableCode
+	if code == nil {
+		return nil, nil
+	}
+	ev := session.NewEvent(ctx.InvocationID())
+	ev.Author = ctx.Agent().Name()
+	ev.Branch = ctx.Branch()
+
+	language := codeexecutor.LanguagePython
+	if code.Language == languageGo {
+		language = codeexecutor.LanguageGo
+	}
+	// The stateful executors run the code in the kernel of the session.
+	kernelID := ""
+	if ctx.Session() != nil {
+		if v, err := ctx.Session().State().Get(codeexecutor.KernelStateKey); err == nil {
+			kernelID, _ = v.(string)
+		}
+	}
+	result, err := executor.ExecuteCode(ctx, &codeexecutor.Input{Code: code.Code, Language: language, ExecutionID: ev.ID, KernelID: kernelID})
+	var part *genai.Part
+	switch {
+	case err != nil && errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil:
+		part = genai.NewPartFromCodeExecutionResult(genai.OutcomeDeadlineExceeded, "Code execution timed out.")
+	case err != nil:
+		return nil, fmt.Errorf("failed to execute code: %w", err)
+	case result.Stderr != "":
+		part = genai.NewPartFromCodeExecutionResult(genai.OutcomeFailed, result.Stderr)
+	default:
+		var saved []string
+		for _, file := range result.OutputFiles {
+			mimeType := file.MIMEType
+			if mimeType == "" {
+				mimeType = mime.TypeByExtension(path.Ext(file.Name))
+			}
+			if mimeType == "" {
+				mimeType = http.DetectContentType(file.Content)
+			}
+			resp, err := ctx.Artifacts().Save(ctx, file.Name, genai.NewPartFromBytes(file.Content, mimeType))
+			if err != nil {
+				return nil, fmt.Errorf("failed to save output file %q of the code: %w", file.Name, err)
+			}
+			if ev.Actions.ArtifactDelta == nil {
+				ev.Actions.ArtifactDelta = make(map[string]int64)
+			}
+			ev.Actions.ArtifactDelta[file.Name] = resp.Version
+			saved = append(saved, "`"+file.Name+"`")
+		}
+		var output []string
+		if result.Stdout != "" || len(saved) == 0 {
+			output = append(output, "Code execution result:\n"+result.Stdout+"\n")
+		}
+		if len(saved) > 0 {
+			output = append(output, "Saved artifacts:\n"+strings.Join(saved, ","))
+		}
+		part = genai.NewPartFromCodeExecutionResult(genai.OutcomeOK, strings.Join(output, "\n\n"))
+	}
+	ev.LLMResponse = model.LLMResponse{Content: genai.NewContentFromParts([]*genai.Part{part}, genai.RoleModel)}
+	if err == nil && result.KernelID != "" {
+		if result.KernelID != kernelID {
+			ev.Actions.StateDelta[codeexecutor.KernelStateKey] = result.KernelID
+		}
+		if kernelID != "" && !result.StatePreserved {
+			part.CodeExecutionResult.Output = "The kernel of the previous executions is gone, their variables and imports were lost; the code ran in a new kernel.\n\n" + part.CodeExecutionResult.Output
+		}
+		ev.CustomMetadata = map[string]any{codeexecutor.KernelMetadataKey: map[string]any{
+			"kernel_id":       result.KernelID,
+			"state_preserved": result.StatePreserved,
+		}}
+	}
+	return ev, nil
+}
@@ -0,0 +1,322 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llminternal
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/artifact"
+	"google.golang.org/adk/codeexecutor"
+	artifactinternal "google.golang.org/adk/internal/artifact"
+	icontext "google.golang.org/adk/internal/context"
+	"google.golang.org/adk/internal/utils"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+)
+
+// fakeExecutor returns its result, or fails with its error.
+type fakeExecutor struct {
+	result *codeexecutor.Result
+	err    error
+	input  *codeexecutor.Input
+}
+
+func (e *fakeExecutor) ExecuteCode(_ agent.InvocationContext, input *codeexecutor.Input) (*codeexecutor.Result, error) {
+	e.input = input
+	return e.result, e.err
+}
+
+func newCodeExecutionContext(t *testing.T, executor codeexecutor.CodeExecutor, artifacts artifact.Service) agent.InvocationContext {
+	t.Helper()
+	a := &mockLLMAgent{Agent: utils.Must(agent.New(agent.Config{Name: "agent"})), s: &State{CodeExecutor: executor}}
+	return icontext.NewInvocationContext(t.Context(), icontext.InvocationContextParams{
+		Agent: a,
+		Artifacts: &artifactinternal.Artifacts{
+			Service:   artifacts,
+			AppName:   "app",
+			UserID:    "user",
+			SessionID: "session",
+		},
+		InvocationID: "invocation",
+	})
+}
+
+func TestCodeExecutionResponseProcessor(t *testing.T) {
+	tests := []struct {
+		name     string
+		executor codeexecutor.CodeExecutor
+		resp     *model.LLMResponse
+		want     *genai.Content
+	}{
+		{
+			name:     "python",
+			executor: &fakeExecutor{},
+			resp:     &model.LLMResponse{Content: genai.NewContentFromText("Let me compute it.\n```python\nprint(1 + 1)\n```\nThe answer is 3.", genai.RoleModel)},
+			want: genai.NewContentFromParts([]*genai.Part{
+				genai.NewPartFromText("Let me compute it.\n"),
+				genai.NewPartFromExecutableCode("print(1 + 1)", genai.LanguagePython),
+			}, genai.RoleModel),
+		},
+		{
+			name:     "go after a thought",
+			executor: &fakeExecutor{},
+			resp: &model.LLMResponse{Content: genai.NewContentFromParts([]*genai.Part{
+				{Text: "```python\nignored()\n```", Thought: true},
+				genai.NewPartFromText("```go\npackage main\n```"),
+				genai.NewPartFromText("dropped"),
+			}, genai.RoleModel)},
+			want: genai.NewContentFromParts([]*genai.Part{
+				{Text: "```python\nignored()\n```", Thought: true},
+				genai.NewPartFromExecutableCode("package main", languageGo),
+			}, genai.RoleModel),
+		},
+		{
+			name:     "other language",
+			executor: &fakeExecutor{},
+			resp:     &model.LLMResponse{Content: genai.NewContentFromText("```sh\nls\n```", genai.RoleModel)},
+			want:     genai.NewContentFromText("```sh\nls\n```", genai.RoleModel),
+		},
+		{
+			name: "no executor",
+			resp: &model.LLMResponse{Content: genai.NewContentFromText("```python\nprint(1)\n```", genai.RoleModel)},
+			want: genai.NewContentFromText("```python\nprint(1)\n```", genai.RoleModel),
+		},
+		{
+			name:     "partial",
+			executor: &fakeExecutor{},
+			resp:     &model.LLMResponse{Partial: true, Content: genai.NewContentFromText("```python\nprint(1)\n```", genai.RoleModel)},
+			want:     genai.NewContentFromText("```python\nprint(1)\n```", genai.RoleModel),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newCodeExecutionContext(t, tt.executor, artifact.InMemoryService())
+			if err := codeExecutionResponseProcessor(ctx, &model.LLMRequest{}, tt.resp); err != nil {
+				t.Fatalf("codeExecutionResponseProcessor() failed: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, tt.resp.Content); diff != "" {
+				t.Errorf("content mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCodeExecutionRequestProcessor(t *testing.T) {
+	history := []*genai.Content{
+		genai.NewContentFromText("What is 1 + 1?", genai.RoleUser),
+		genai.NewContentFromParts([]*genai.Part{
+			genai.NewPartFromText("Let me compute it."),
+			genai.NewPartFromExecutableCode("print(1 + 1)", genai.LanguagePython),
+		}, genai.RoleModel),
+		genai.NewContentFromParts([]*genai.Part{
+			genai.NewPartFromCodeExecutionResult(genai.OutcomeOK, "Code execution result:\n2\n"),
+		}, genai.RoleModel),
+	}
+	req := &model.LLMRequest{Contents: append([]*genai.Content{}, history...)}
+	ctx := newCodeExecutionContext(t, &fakeExecutor{}, artifact.InMemoryService())
+	for _, err := range codeExecutionRequestProcessor(ctx, req, &Flow{}) {
+		t.Fatalf("codeExecutionRequestProcessor() error = %v", err)
+	}
+	want := []*genai.Content{
+		genai.NewContentFromText("What is 1 + 1?", genai.RoleUser),
+		genai.NewContentFromParts([]*genai.Part{
+			genai.NewPartFromText("Let me compute it."),
+			genai.NewPartFromText("```tool_code\nprint(1 + 1)\n```"),
+		}, genai.RoleModel),
+		genai.NewContentFromText("```tool_output\nCode execution result:\n2\n\n```", genai.RoleModel),
+	}
+	if diff := cmp.Diff(want, req.Contents); diff != "" {
+		t.Errorf("contents mismatch (-want +got):\n%s", diff)
+	}
+	if history[1].Parts[1].ExecutableCode == nil {
+		t.Errorf("codeExecutionRequestProcessor() changed the contents of the history")
+	}
+}
+
+func TestExecuteCode(t *testing.T) {
+	codeResp := &model.LLMResponse{Content: genai.NewContentFromParts([]*genai.Part{
+		genai.NewPartFromExecutableCode("print(1 + 1)", genai.LanguagePython),
+	}, genai.RoleModel)}
+	tests := []struct {
+		name          string
+		executor      *fakeExecutor
+		resp          *model.LLMResponse
+		want          *genai.Content
+		wantArtifacts map[string]int64
+		wantErr       bool
+	}{
+		{
+			name:     "stdout",
+			executor: &fakeExecutor{result: &codeexecutor.Result{Stdout: "2\n"}},
+			resp:     codeResp,
+			want:     genai.NewContentFromParts([]*genai.Part{genai.NewPartFromCodeExecutionResult(genai.OutcomeOK, "Code execution result:\n2\n\n")}, genai.RoleModel),
+		},
+		{
+			name: "output files",
+			executor: &fakeExecutor{result: &codeexecutor.Result{OutputFiles: []codeexecutor.File{
+				{Name: "plot.png", Content: []byte("png")},
+				{Name: "data.csv", Content: []byte("a,b"), MIMEType: "text/csv"},
+			}}},
+			resp:          codeResp,
+			want:          genai.NewContentFromParts([]*genai.Part{genai.NewPartFromCodeExecutionResult(genai.OutcomeOK, "Saved artifacts:\n`plot.png`,`data.csv`")}, genai.RoleModel),
+			wantArtifacts: map[string]int64{"plot.png": 1, "data.csv": 1},
+		},
+		{
+			name:     "stderr",
+			executor: &fakeExecutor{result: &codeexecutor.Result{Stdout: "partial", Stderr: "ZeroDivisionError"}},
+			resp:     codeResp,
+			want:     genai.NewContentFromParts([]*genai.Part{genai.NewPartFromCodeExecutionResult(genai.OutcomeFailed, "ZeroDivisionError")}, genai.RoleModel),
+		},
+		{
+			name:     "timeout",
+			executor: &fakeExecutor{err: fmt.Errorf("timed out: %w", context.DeadlineExceeded)},
+			resp:     codeResp,
+			want:     genai.NewContentFromParts([]*genai.Part{genai.NewPartFromCodeExecutionResult(genai.OutcomeDeadlineExceeded, "Code execution timed out.")}, genai.RoleModel),
+		},
+		{
+			name:     "no runtime",
+			executor: &fakeExecutor{err: codeexecutor.ErrNoRuntime},
+			resp:     codeResp,
+			wantErr:  true,
+		},
+		{
+			name:     "no code",
+			executor: &fakeExecutor{},
+			resp:     &model.LLMResponse{Content: genai.NewContentFromText("2", genai.RoleModel)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := artifact.InMemoryService()
+			ctx := newCodeExecutionContext(t, tt.executor, service)
+			ev, err := (&Flow{}).executeCode(ctx, tt.resp)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("executeCode() succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("executeCode() failed: %v", err)
+			}
+			if tt.want == nil {
+				if ev != nil {
+					t.Errorf("executeCode() = %+v, want no event", ev)
+				}
+				return
+			}
+			if diff := cmp.Diff(tt.want, ev.Content); diff != "" {
+				t.Errorf("content mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantArtifacts, ev.Actions.ArtifactDelta); diff != "" {
+				t.Errorf("artifact delta mismatch (-want +got):\n%s", diff)
+			}
+			if got := tt.executor.input; got.Code != "print(1 + 1)" || got.Language != codeexecutor.LanguagePython || got.ExecutionID != ev.ID {
+				t.Errorf("ExecuteCode() called with %+v", got)
+			}
+			for name := range tt.wantArtifacts {
+				load, err := service.Load(t.Context(), &artifact.LoadRequest{AppName: "app", UserID: "user", SessionID: "session", FileName: name})
+				if err != nil {
+					t.Fatalf("Load(%q) failed: %v", name, err)
+				}
+				if name == "data.csv" && load.Part.InlineData.MIMEType != "text/csv" {
+					t.Errorf("MIME type of %q = %q, want text/csv", name, load.Part.InlineData.MIMEType)
+				}
+			}
+		})
+	}
+}
+
+func TestExecuteCode_Kernel(t *testing.T) {
+	codeResp := &model.LLMResponse{Content: genai.NewContentFromParts([]*genai.Part{
+		genai.NewPartFromExecutableCode("df.plot()", genai.LanguagePython),
+	}, genai.RoleModel)}
+	tests := []struct {
+		name           string
+		state          map[string]any
+		result         *codeexecutor.Result
+		wantKernelID   string
+		wantOutput     string
+		wantStateDelta map[string]any
+		wantMetadata   map[string]any
+	}{
+		{
+			name:           "new kernel",
+			result:         &codeexecutor.Result{Stdout: "ok", KernelID: "k1"},
+			wantOutput:     "Code execution result:\nok\n",
+			wantStateDelta: map[string]any{codeexecutor.KernelStateKey: "k1"},
+			wantMetadata:   map[string]any{codeexecutor.KernelMetadataKey: map[string]any{"kernel_id": "k1", "state_preserved": false}},
+		},
+		{
+			name:           "preserved",
+			state:          map[string]any{codeexecutor.KernelStateKey: "k1"},
+			result:         &codeexecutor.Result{Stdout: "ok", KernelID: "k1", StatePreserved: true},
+			wantKernelID:   "k1",
+			wantOutput:     "Code execution result:\nok\n",
+			wantStateDelta: map[string]any{},
+			wantMetadata:   map[string]any{codeexecutor.KernelMetadataKey: map[string]any{"kernel_id": "k1", "state_preserved": true}},
+		},
+		{
+			name:           "lost",
+			state:          map[string]any{codeexecutor.KernelStateKey: "k1"},
+			result:         &codeexecutor.Result{Stderr: "NameError: name 'df' is not defined", KernelID: "k2"},
+			wantKernelID:   "k1",
+			wantOutput:     "The kernel of the previous executions is gone, their variables and imports were lost; the code ran in a new kernel.\n\nNameError: name 'df' is not defined",
+			wantStateDelta: map[string]any{codeexecutor.KernelStateKey: "k2"},
+			wantMetadata:   map[string]any{codeexecutor.KernelMetadataKey: map[string]any{"kernel_id": "k2", "state_preserved": false}},
+		},
+		{
+			name:           "stateless",
+			state:          map[string]any{codeexecutor.KernelStateKey: "k1"},
+			result:         &codeexecutor.Result{Stdout: "ok"},
+			wantKernelID:   "k1",
+			wantOutput:     "Code execution result:\nok\n",
+			wantStateDelta: map[string]any{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := &fakeExecutor{result: tt.result}
+			a := &mockLLMAgent{Agent: utils.Must(agent.New(agent.Config{Name: "agent"})), s: &State{CodeExecutor: executor}}
+			sess, err := session.InMemoryService().Create(t.Context(), &session.CreateRequest{AppName: "app", UserID: "user", State: tt.state})
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx := icontext.NewInvocationContext(t.Context(), icontext.InvocationContextParams{Agent: a, Session: sess.Session})
+			ev, err := (&Flow{}).executeCode(ctx, codeResp)
+			if err != nil {
+				t.Fatalf("executeCode() failed: %v", err)
+			}
+			if executor.input.KernelID != tt.wantKernelID {
+				t.Errorf("ExecuteCode() called with kernel ID %q, want %q", executor.input.KernelID, tt.wantKernelID)
+			}
+			if got := ev.Content.Parts[0].CodeExecutionResult.Output; got != tt.wantOutput {
+				t.Errorf("output = %q, want %q", got, tt.wantOutput)
+			}
+			if diff := cmp.Diff(tt.wantStateDelta, ev.Actions.StateDelta); diff != "" {
+				t.Errorf("state delta mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantMetadata, ev.CustomMetadata); diff != "" {
+				t.Errorf("custom metadata mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"google.golang.org/genai"
 
 	"google.golang.org/adk/agent"
+	icontext "google.golang.org/adk/internal/context"
 	"google.golang.org/adk/internal/utils"
 	"google.golang.org/adk/model"
 	"google.golang.org/adk/session"
@@ -41,8 +42,9 @@ func ContentsRequestProcessor(ctx agent.InvocationContext, req *model.LLMRequest
 			// Do nothing.
 			return // In python, no error is yielded.
 		}
+		state := llmAgent.internal()
 		fn := buildContentsDefault // "" or "default".
-		if llmAgent.internal().IncludeContents == "none" {
+		if state.IncludeContents == "none" {
 			// Include current turn context only (no conversation history)
 			fn = buildContentsCurrentTurnContextOnly
 		}
@@ -52,6 +54,14 @@ func ContentsRequestProcessor(ctx agent.InvocationContext, req *model.LLMRequest
 				events = append(events, e)
 			}
 		}
+		if state.IncludeContents == "filtered" && state.ContentsFilter != nil {
+			// Filter the events of the branch only, for the filter not to
+			// see those of the other branches.
+			events = slices.DeleteFunc(events, func(ev *session.Event) bool {
+				return !eventBelongsToBranch(ctx.Branch(), ev)
+			})
+			events = state.ContentsFilter(icontext.NewReadonlyContext(ctx), events)
+		}
 		contents, err := fn(ctx.Agent().Name(), ctx.Branch(), events)
 		if err != nil {
 			yield(nil, err)
@@ -77,6 +87,11 @@ func buildContentsDefault(agentName, invocationBranch string, events []*session.
 			// But unlike python that distinguishes None vs empty string, two cases are indistinguishable in Go.
 			continue
 		}
+		// Skip the partial events saved with the run config, their final
+		// event has the whole content.
+		if ev.Partial {
+			continue
+		}
 		// Skip events that do not belong to the current branch.
 		// TODO: can we use a richer type for branch (e.g. []string) instead of using string prefix test?
 		if !eventBelongsToBranch(invocationBranch, ev) {
@@ -108,6 +108,7 @@ func TestContentsRequestProcessor_IncludeContents(t *testing.T) {
 	testCases := []struct {
 		name            string
 		includeContents llmagent.IncludeContents
+		contentsFilter  llmagent.ContentsFilter
 		events          []*session.Event
 		want            []*genai.Content
 	}{
@@ -212,6 +213,24 @@ func TestContentsRequestProcessor_IncludeContents(t *testing.T) {
 				genai.NewContentFromFunctionResponse("func1", nil, "user"),
 			},
 		},
+		{
+			name:            "helloAndGoodBye",
+			includeContents: "filtered",
+			contentsFilter:  llmagent.LastTurns(1),
+			events:          helloAndGoodBye,
+			want: []*genai.Content{
+				genai.NewContentFromText("good bye", "user"),
+			},
+		},
+		{
+			name:            "agentTransfer",
+			includeContents: "filtered",
+			contentsFilter:  llmagent.AgentAndUserEvents,
+			events:          agentTransfer,
+			want: []*genai.Content{
+				genai.NewContentFromFunctionCall("func1", nil, "model"),
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -220,6 +239,7 @@ func TestContentsRequestProcessor_IncludeContents(t *testing.T) {
 				Name:            agentName,
 				Model:           testModel,
 				IncludeContents: tc.includeContents,
+				ContentsFilter:  tc.contentsFilter,
 			}))
 
 			ctx := icontext.NewInvocationContext(t.Context(), icontext.InvocationContextParams{
@@ -246,6 +266,45 @@ func TestContentsRequestProcessor_IncludeContents(t *testing.T) {
 	}
 }
 
+func TestContentsRequestProcessor_ContentsFilterBranch(t *testing.T) {
+	events := []*session.Event{
+		{Author: "user", LLMResponse: model.LLMResponse{Content: genai.NewContentFromText("question", "user")}},
+		{Author: "a", Branch: "root.parallel.a", LLMResponse: model.LLMResponse{Content: genai.NewContentFromText("answer of a", "model")}},
+		{Author: "b", Branch: "root.parallel.b", LLMResponse: model.LLMResponse{Content: genai.NewContentFromText("answer of b", "model")}},
+	}
+	var seen []*session.Event
+	testAgent := utils.Must(llmagent.New(llmagent.Config{
+		Name:            "a",
+		Model:           &testModel{},
+		IncludeContents: llmagent.IncludeContentsFiltered,
+		ContentsFilter: func(ctx agent.ReadonlyContext, events []*session.Event) []*session.Event {
+			seen = events
+			return events
+		},
+	}))
+	ctx := icontext.NewInvocationContext(t.Context(), icontext.InvocationContextParams{
+		Agent:   testAgent,
+		Branch:  "root.parallel.a",
+		Session: &fakeSession{events: events},
+	})
+	req := &model.LLMRequest{}
+	for _, err := range llminternal.ContentsRequestProcessor(ctx, req, &llminternal.Flow{}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if diff := cmp.Diff(events[:2], seen); diff != "" {
+		t.Errorf("events of the filter mismatch (-want +got):\n%s", diff)
+	}
+	want := []*genai.Content{
+		genai.NewContentFromText("question", "user"),
+		genai.NewContentFromText("answer of a", "model"),
+	}
+	if diff := cmp.Diff(want, req.Contents); diff != "" {
+		t.Errorf("Contents mismatch (-want +got):\n%s", diff)
+	}
+}
+
 func TestContentsRequestProcessor(t *testing.T) {
 	const agentName = "testAgent"
 	testModel := &testModel{}
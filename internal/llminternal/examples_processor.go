@@ -0,0 +1,183 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llminternal
+
+import (
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/example"
+	icontext "google.golang.org/adk/internal/context"
+	"google.golang.org/adk/internal/utils"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+)
+
+// The markers of the examples formatted in the system instruction.
+const (
+	examplesIntro = "<EXAMPLES>\nBegin few-shot\n" +
+		"The following are examples of user queries and model responses using the available tools.\n\n"
+	examplesEnd = "End few-shot\n" +
+		"Now, try to follow these examples and complete the following conversation\n</EXAMPLES>"
+	exampleStart           = "EXAMPLE %d:\nBegin example\n"
+	exampleEnd             = "End example\n\n"
+	exampleUserPrefix      = "[user]\n"
+	exampleModelPrefix     = "[model]\n"
+	functionCallPrefix     = "```tool_code\n"
+	functionResponsePrefix = "```tool_outputs\n"
+	functionSuffix         = "\n```\n"
+)
+
+// examplesRequestProcessor includes the few-shot examples of the agent in
+// the requests, within their token budget: in the system instruction, after
+// the instructions of the agent, or as turns of the contents before the
+// history.
+func examplesRequestProcessor(ctx agent.InvocationContext, req *model.LLMRequest, f *Flow) iter.Seq2[*session.Event, error] {
+	return func(yield func(*session.Event, error) bool) {
+		llmAgent := asLLMAgent(ctx.Agent())
+		if llmAgent == nil {
+			return // do nothing.
+		}
+		state := llmAgent.internal()
+		examples := state.Examples
+		if state.ExampleProvider != nil {
+			var err error
+			examples, err = state.ExampleProvider.Examples(icontext.NewReadonlyContext(ctx), query(ctx.UserContent()))
+			if err != nil {
+				yield(nil, fmt.Errorf("failed to get the examples: %w", err))
+				return
+			}
+		}
+		examples = withinTokenBudget(examples, state.ExamplesMaxTokens)
+		if len(examples) == 0 {
+			return
+		}
+		if state.ExamplesInHistory {
+			for _, ex := range examples {
+				req.Contents = append(req.Contents, exampleContents(ex)...)
+			}
+			return
+		}
+		utils.AppendInstructions(req, formatExamples(examples))
+	}
+}
+
+// query returns the text of the user content.
+func query(content *genai.Content) string {
+	if content == nil {
+		return ""
+	}
+	var texts []string
+	for _, part := range content.Parts {
+		if part != nil && part.Text != "" && !part.Thought {
+			texts = append(texts, part.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// exampleContents returns the contents of the example, cloned for the
+// request not to share them.
+func exampleContents(ex *example.Example) []*genai.Content {
+	var contents []*genai.Content
+	for _, content := range append([]*genai.Content{ex.Input}, ex.Output...) {
+		if content != nil {
+			contents = append(contents, clone(content))
+		}
+	}
+	return contents
+}
+
+// withinTokenBudget returns the first examples whose estimated tokens fit
+// the budget, all of them if maxTokens isn't positive.
+func withinTokenBudget(examples []*example.Example, maxTokens int) []*example.Example {
+	if maxTokens <= 0 {
+		return examples
+	}
+	tokens := 0
+	for i, ex := range examples {
+		tokens += model.EstimateTokens(&model.LLMRequest{Contents: exampleContents(ex)})
+		if tokens > maxTokens {
+			return examples[:i]
+		}
+	}
+	return examples
+}
+
+// formatExamples formats the examples for the system instruction, with the
+// function calls and responses in code blocks.
+func formatExamples(examples []*example.Example) string {
+	var b strings.Builder
+	b.WriteString(examplesIntro)
+	for i, ex := range examples {
+		fmt.Fprintf(&b, exampleStart, i+1)
+		if ex.Input != nil {
+			b.WriteString(exampleUserPrefix)
+			writeExampleParts(&b, ex.Input)
+			b.WriteString("\n")
+		}
+		// The function responses are part of the turn of the model.
+		if len(ex.Output) > 0 {
+			b.WriteString(exampleModelPrefix)
+		}
+		for _, content := range ex.Output {
+			if content != nil {
+				writeExampleParts(&b, content)
+			}
+		}
+		b.WriteString(exampleEnd)
+	}
+	b.WriteString(examplesEnd)
+	return b.String()
+}
+
+func writeExampleParts(b *strings.Builder, content *genai.Content) {
+	for _, part := range content.Parts {
+		switch {
+		case part == nil || part.Thought:
+		case part.Text != "":
+			b.WriteString(part.Text)
+			b.WriteString("\n")
+		case part.FunctionCall != nil:
+			b.WriteString(functionCallPrefix)
+			b.WriteString(formatFunctionCall(part.FunctionCall))
+			b.WriteString(functionSuffix)
+		case part.FunctionResponse != nil:
+			b.WriteString(functionResponsePrefix)
+			b.WriteString(stringify(part.FunctionResponse.Response))
+			b.WriteString(functionSuffix)
+		}
+	}
+}
+
+// formatFunctionCall formats the call like a Python call, e.g.
+// get_weather(city="Paris"), with its arguments in order of their names.
+func formatFunctionCall(call *genai.FunctionCall) string {
+	names := make([]string, 0, len(call.Args))
+	for name := range call.Args {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	args := make([]string, len(names))
+	for i, name := range names {
+		args[i] = name + "=" + stringify(call.Args[name])
+	}
+	return call.Name + "(" + strings.Join(args, ", ") + ")"
+}
@@ -0,0 +1,171 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llminternal
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/example"
+	icontext "google.golang.org/adk/internal/context"
+	"google.golang.org/adk/internal/utils"
+	"google.golang.org/adk/model"
+)
+
+var weatherExample = &example.Example{
+	Input: genai.NewContentFromText("What's the weather in Paris?", genai.RoleUser),
+	Output: []*genai.Content{
+		genai.NewContentFromFunctionCall("get_weather", map[string]any{"unit": "C", "city": "Paris"}, genai.RoleModel),
+		genai.NewContentFromFunctionResponse("get_weather", map[string]any{"temperature": 20}, genai.RoleUser),
+		genai.NewContentFromText("It's 20°C in Paris.", genai.RoleModel),
+	},
+}
+
+var greetingExample = &example.Example{
+	Input:  genai.NewContentFromText("Hi!", genai.RoleUser),
+	Output: []*genai.Content{genai.NewContentFromText("Hello! How can I help?", genai.RoleModel)},
+}
+
+// fakeExampleProvider returns its examples, and records the queries.
+type fakeExampleProvider struct {
+	examples []*example.Example
+	err      error
+	queries  []string
+}
+
+func (p *fakeExampleProvider) Examples(_ agent.ReadonlyContext, query string) ([]*example.Example, error) {
+	p.queries = append(p.queries, query)
+	return p.examples, p.err
+}
+
+func runExamplesRequestProcessor(t *testing.T, state *State, req *model.LLMRequest) error {
+	t.Helper()
+	a := &mockLLMAgent{Agent: utils.Must(agent.New(agent.Config{Name: "agent"})), s: state}
+	ctx := icontext.NewInvocationContext(t.Context(), icontext.InvocationContextParams{
+		Agent:       a,
+		UserContent: genai.NewContentFromText("Weather in Rome?", genai.RoleUser),
+	})
+	for _, err := range examplesRequestProcessor(ctx, req, &Flow{}) {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestExamplesRequestProcessor_Instruction(t *testing.T) {
+	req := &model.LLMRequest{}
+	utils.AppendInstructions(req, "Report the weather.")
+	if err := runExamplesRequestProcessor(t, &State{Examples: []*example.Example{weatherExample, greetingExample}}, req); err != nil {
+		t.Fatal(err)
+	}
+	want := "<EXAMPLES>\nBegin few-shot\n" +
+		"The following are examples of user queries and model responses using the available tools.\n\n" +
+		"EXAMPLE 1:\nBegin example\n" +
+		"[user]\nWhat's the weather in Paris?\n\n" +
+		"[model]\n" +
+		"```tool_code\nget_weather(city=\"Paris\", unit=\"C\")\n```\n" +
+		"```tool_outputs\n{\"temperature\":20}\n```\n" +
+		"It's 20°C in Paris.\n" +
+		"End example\n\n" +
+		"EXAMPLE 2:\nBegin example\n" +
+		"[user]\nHi!\n\n" +
+		"[model]\nHello! How can I help?\n" +
+		"End example\n\n" +
+		"End few-shot\n" +
+		"Now, try to follow these examples and complete the following conversation\n</EXAMPLES>"
+	parts := req.Config.SystemInstruction.Parts
+	if len(parts) != 2 || parts[0].Text != "Report the weather." {
+		t.Fatalf("SystemInstruction = %v, want the instruction followed by the examples", req.Config.SystemInstruction)
+	}
+	if diff := cmp.Diff(want, parts[1].Text); diff != "" {
+		t.Errorf("examples mismatch (-want +got):\n%s", diff)
+	}
+	if len(req.Contents) != 0 {
+		t.Errorf("Contents = %v, want none", req.Contents)
+	}
+}
+
+func TestExamplesRequestProcessor_History(t *testing.T) {
+	req := &model.LLMRequest{}
+	if err := runExamplesRequestProcessor(t, &State{Examples: []*example.Example{weatherExample}, ExamplesInHistory: true}, req); err != nil {
+		t.Fatal(err)
+	}
+	want := append([]*genai.Content{weatherExample.Input}, weatherExample.Output...)
+	if diff := cmp.Diff(want, req.Contents); diff != "" {
+		t.Errorf("Contents mismatch (-want +got):\n%s", diff)
+	}
+	if req.Contents[0] == weatherExample.Input {
+		t.Errorf("Contents share the contents of the example")
+	}
+	if req.Config != nil && req.Config.SystemInstruction != nil {
+		t.Errorf("SystemInstruction = %v, want nil", req.Config.SystemInstruction)
+	}
+}
+
+func TestExamplesRequestProcessor_Provider(t *testing.T) {
+	provider := &fakeExampleProvider{examples: []*example.Example{greetingExample}}
+	req := &model.LLMRequest{}
+	if err := runExamplesRequestProcessor(t, &State{ExampleProvider: provider, ExamplesInHistory: true}, req); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"Weather in Rome?"}, provider.queries); diff != "" {
+		t.Errorf("queries mismatch (-want +got):\n%s", diff)
+	}
+	if len(req.Contents) != 2 {
+		t.Errorf("got %d contents, want the 2 of the example", len(req.Contents))
+	}
+
+	provider = &fakeExampleProvider{err: errors.New("index unavailable")}
+	err := runExamplesRequestProcessor(t, &State{ExampleProvider: provider}, &model.LLMRequest{})
+	if err == nil || !strings.Contains(err.Error(), "index unavailable") {
+		t.Errorf("examplesRequestProcessor() error = %v, want the error of the provider", err)
+	}
+}
+
+func TestExamplesRequestProcessor_TokenBudget(t *testing.T) {
+	greetingTokens := model.EstimateTokens(&model.LLMRequest{Contents: exampleContents(greetingExample)})
+	tests := []struct {
+		name      string
+		maxTokens int
+		want      int
+	}{
+		{"no budget", 0, 3},
+		{"two examples", 2 * greetingTokens, 2},
+		{"one example", 2*greetingTokens - 1, 1},
+		{"none", greetingTokens - 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &model.LLMRequest{}
+			state := &State{
+				Examples:          []*example.Example{greetingExample, greetingExample, greetingExample},
+				ExamplesInHistory: true,
+				ExamplesMaxTokens: tt.maxTokens,
+			}
+			if err := runExamplesRequestProcessor(t, state, req); err != nil {
+				t.Fatal(err)
+			}
+			if got := len(req.Contents) / 2; got != tt.want {
+				t.Errorf("got %d examples, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -70,7 +70,6 @@ func generateRequestConfirmationEvent(
 		parts = append(parts, &genai.Part{
 			FunctionCall: requestConfirmationFC,
 		})
-		longRunningToolIDs = append(longRunningToolIDs, requestConfirmationFC.ID)
 	}
 
 	if len(parts) == 0 {
@@ -82,6 +81,10 @@ func generateRequestConfirmationEvent(
 		Role:  genai.RoleModel,
 	}
 	utils.PopulateClientFunctionCallID(content)
+	// The confirmation requests pause the invocation until the user answers.
+	for _, part := range parts {
+		longRunningToolIDs = append(longRunningToolIDs, part.FunctionCall.ID)
+	}
 
 	return &session.Event{
 		InvocationID: invocationContext.InvocationID(),
@@ -71,6 +71,26 @@ func IsGemini2OrAbove(model string) bool {
 	return version >= 2.0
 }
 
+// SupportsThinking returns true if the model is a Gemini model supporting
+// thinking, i.e. a Gemini 2.5+ or a thinking variant of an older one.
+func SupportsThinking(model string) bool {
+	if !IsGeminiModel(model) {
+		return false
+	}
+	if strings.Contains(extractModelName(model), "thinking") {
+		return true
+	}
+	matches := geminiModelVersionRegex.FindStringSubmatch(extractModelName(model))
+	if len(matches) < 2 {
+		return false
+	}
+	version, err := strconv.ParseFloat(matches[1], 64)
+	if err != nil {
+		return false
+	}
+	return version >= 2.5
+}
+
 // CanGeminiModelUseOutputSchemaWithTools returns true if the model is a Gemini model and the variant is Vertex AI and the model is a Gemini 2.x+ .
 func CanGeminiModelUseOutputSchemaWithTools(model string) bool {
 	return IsGeminiModel(model) && IsVertexVariant() && IsGemini2OrAbove(model)
@@ -41,6 +41,28 @@ func TestIsGemini2OrAbove(t *testing.T) {
 	}
 }
 
+func TestSupportsThinking(t *testing.T) {
+	testCases := []struct {
+		model string
+		want  bool
+	}{
+		{"gemini-2.0-flash", false},
+		{"gemini-2.0-flash-thinking-exp", true},
+		{"gemini-2.5-flash", true},
+		{"models/gemini-2.5-pro", true},
+		{"gemini-3", true},
+		{"gemini-1.5-pro", false},
+		{"claude-sonnet-4-5", false},
+	}
+
+	for _, tc := range testCases {
+		got := SupportsThinking(tc.model)
+		if got != tc.want {
+			t.Errorf("SupportsThinking(%q) = %v, want %v", tc.model, got, tc.want)
+		}
+	}
+}
+
 func TestIsGeminiModel(t *testing.T) {
 	testCases := []struct {
 		model string
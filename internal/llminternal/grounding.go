@@ -0,0 +1,109 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llminternal
+
+import (
+	"encoding/json"
+	"slices"
+
+	"google.golang.org/genai"
+)
+
+// mergeGroundingMetadata returns the grounding metadata of both base and
+// other, e.g. of the partial responses of a stream or of the function
+// responses of parallel calls. The chunks and supports found in both are
+// kept once, and the chunk indices of the supports of other are mapped to the
+// merged chunks. The other fields are the ones of other, if set.
+func mergeGroundingMetadata(base, other *genai.GroundingMetadata) *genai.GroundingMetadata {
+	if other == nil {
+		return base
+	}
+	if base == nil {
+		return other
+	}
+	merged := *base
+	merged.GroundingChunks = slices.Clone(base.GroundingChunks)
+	merged.GroundingSupports = slices.Clone(base.GroundingSupports)
+
+	chunkIndices := make(map[string]int32, len(merged.GroundingChunks))
+	for i, c := range merged.GroundingChunks {
+		chunkIndices[jsonKey(c)] = int32(i)
+	}
+	// indices maps the chunk indices of other to the merged ones.
+	indices := make([]int32, len(other.GroundingChunks))
+	for i, c := range other.GroundingChunks {
+		key := jsonKey(c)
+		idx, ok := chunkIndices[key]
+		if !ok {
+			idx = int32(len(merged.GroundingChunks))
+			chunkIndices[key] = idx
+			merged.GroundingChunks = append(merged.GroundingChunks, c)
+		}
+		indices[i] = idx
+	}
+
+	supports := make(map[string]bool, len(merged.GroundingSupports))
+	for _, s := range merged.GroundingSupports {
+		supports[jsonKey(s)] = true
+	}
+	for _, s := range other.GroundingSupports {
+		support := *s
+		support.GroundingChunkIndices = nil
+		for _, i := range s.GroundingChunkIndices {
+			if i >= 0 && int(i) < len(indices) {
+				support.GroundingChunkIndices = append(support.GroundingChunkIndices, indices[i])
+			}
+		}
+		if key := jsonKey(&support); !supports[key] {
+			supports[key] = true
+			merged.GroundingSupports = append(merged.GroundingSupports, &support)
+		}
+	}
+
+	merged.RetrievalQueries = appendNew(merged.RetrievalQueries, other.RetrievalQueries)
+	merged.WebSearchQueries = appendNew(merged.WebSearchQueries, other.WebSearchQueries)
+	if other.SearchEntryPoint != nil {
+		merged.SearchEntryPoint = other.SearchEntryPoint
+	}
+	if other.RetrievalMetadata != nil {
+		merged.RetrievalMetadata = other.RetrievalMetadata
+	}
+	if other.GoogleMapsWidgetContextToken != "" {
+		merged.GoogleMapsWidgetContextToken = other.GoogleMapsWidgetContextToken
+	}
+	merged.SourceFlaggingUris = appendNew(merged.SourceFlaggingUris, other.SourceFlaggingUris)
+	return &merged
+}
+
+// appendNew appends the values of other which aren't in base, without
+// changing base.
+func appendNew[T any](base, other []T) []T {
+	keys := make(map[string]bool, len(base))
+	for _, v := range base {
+		keys[jsonKey(v)] = true
+	}
+	for _, v := range other {
+		if key := jsonKey(v); !keys[key] {
+			keys[key] = true
+			base = append(slices.Clip(base), v)
+		}
+	}
+	return base
+}
+
+func jsonKey(v any) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
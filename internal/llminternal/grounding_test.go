@@ -0,0 +1,84 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llminternal
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+)
+
+func TestMergeGroundingMetadata(t *testing.T) {
+	chunk := func(uri string) *genai.GroundingChunk {
+		return &genai.GroundingChunk{RetrievedContext: &genai.GroundingChunkRetrievedContext{URI: uri}}
+	}
+	support := func(start, end int32, indices ...int32) *genai.GroundingSupport {
+		return &genai.GroundingSupport{Segment: &genai.Segment{StartIndex: start, EndIndex: end}, GroundingChunkIndices: indices}
+	}
+	a := &genai.GroundingMetadata{
+		RetrievalQueries:  []string{"a"},
+		GroundingChunks:   []*genai.GroundingChunk{chunk("gs://a")},
+		GroundingSupports: []*genai.GroundingSupport{support(0, 5, 0)},
+	}
+	b := &genai.GroundingMetadata{
+		RetrievalQueries:  []string{"b"},
+		GroundingChunks:   []*genai.GroundingChunk{chunk("gs://b")},
+		GroundingSupports: []*genai.GroundingSupport{support(6, 9, 0)},
+	}
+	tests := []struct {
+		name        string
+		base, other *genai.GroundingMetadata
+		want        *genai.GroundingMetadata
+	}{
+		{name: "both nil"},
+		{name: "base nil", other: b, want: b},
+		{name: "other nil", base: a, want: a},
+		{
+			name:  "distinct",
+			base:  a,
+			other: b,
+			want: &genai.GroundingMetadata{
+				RetrievalQueries:  []string{"a", "b"},
+				GroundingChunks:   []*genai.GroundingChunk{chunk("gs://a"), chunk("gs://b")},
+				GroundingSupports: []*genai.GroundingSupport{support(0, 5, 0), support(6, 9, 1)},
+			},
+		},
+		{
+			name: "cumulative",
+			base: a,
+			other: &genai.GroundingMetadata{
+				RetrievalQueries:  []string{"a"},
+				GroundingChunks:   []*genai.GroundingChunk{chunk("gs://b"), chunk("gs://a")},
+				GroundingSupports: []*genai.GroundingSupport{support(0, 5, 1), support(6, 9, 0, 1)},
+			},
+			want: &genai.GroundingMetadata{
+				RetrievalQueries:  []string{"a"},
+				GroundingChunks:   []*genai.GroundingChunk{chunk("gs://a"), chunk("gs://b")},
+				GroundingSupports: []*genai.GroundingSupport{support(0, 5, 0), support(6, 9, 1, 0)},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, mergeGroundingMetadata(tt.base, tt.other)); diff != "" {
+				t.Errorf("mergeGroundingMetadata() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+	if len(a.GroundingChunks) != 1 || len(a.GroundingSupports) != 1 {
+		t.Errorf("mergeGroundingMetadata() changed its base to %+v", a)
+	}
+}
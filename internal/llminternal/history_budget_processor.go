@@ -0,0 +1,195 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llminternal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"log"
+	"maps"
+	"strings"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+)
+
+// HistoryBudgetMetadataKey is the custom metadata key of the model response
+// events of the requests whose oldest turns were dropped or summarized to
+// fit the token budget of their agent. Its value is a map with the
+// "strategy", the number of "dropped_contents", and the "tokens_before",
+// "tokens_after" and "tokens_shed" counts.
+const HistoryBudgetMetadataKey = "adk_history_budget"
+
+const (
+	historySummaryInstruction = "Summarize the following conversation between a user and an AI agent. " +
+		"Keep the facts, decisions and open questions that the rest of the conversation may need, and reply with the summary only.\n\n"
+	historySummaryPrefix = "For context, a summary of the earlier conversation:\n"
+)
+
+// historyBudgetRequestProcessor drops the oldest turns of the requests over
+// the token budget of their agent, or replaces them with a summary written
+// by the summary model of the agent. The system instruction and the latest
+// turn, which starts with the latest user content, are always kept.
+func historyBudgetRequestProcessor(ctx agent.InvocationContext, req *model.LLMRequest, f *Flow) iter.Seq2[*session.Event, error] {
+	return func(yield func(*session.Event, error) bool) {
+		llmAgent := asLLMAgent(ctx.Agent())
+		if llmAgent == nil {
+			return // do nothing.
+		}
+		state := llmAgent.internal()
+		if state.HistoryMaxTokens <= 0 {
+			return
+		}
+		before := countRequestTokens(ctx, f.Model, req)
+		if before <= state.HistoryMaxTokens {
+			return
+		}
+		turns := splitTurns(req.Contents)
+		if len(turns) < 2 {
+			return
+		}
+
+		// The tokens of the turns are estimated, in proportion to the
+		// counted tokens of the request.
+		scale := float64(before) / float64(max(model.EstimateTokens(req), 1))
+		dropped, shed := 0, 0
+		for _, turn := range turns[:len(turns)-1] {
+			if before-shed <= state.HistoryMaxTokens {
+				break
+			}
+			shed += int(scale * float64(model.EstimateTokens(&model.LLMRequest{Contents: turn})))
+			dropped += len(turn)
+		}
+
+		strategy := "drop"
+		contents := req.Contents[dropped:]
+		if state.HistorySummaryModel != nil {
+			summary, err := summarizeContents(ctx, state.HistorySummaryModel, req.Contents[:dropped])
+			if err != nil {
+				log.Printf("Failed to summarize the dropped history of agent %s, dropping it: %v", ctx.Agent().Name(), err)
+			} else {
+				strategy = "summarize"
+				contents = append([]*genai.Content{genai.NewContentFromText(historySummaryPrefix+summary, genai.RoleUser)}, contents...)
+			}
+		}
+		req.Contents = contents
+
+		after := countRequestTokens(ctx, f.Model, req)
+		req.CustomMetadata = maps.Clone(req.CustomMetadata)
+		if req.CustomMetadata == nil {
+			req.CustomMetadata = make(map[string]any)
+		}
+		req.CustomMetadata[HistoryBudgetMetadataKey] = map[string]any{
+			"strategy":         strategy,
+			"dropped_contents": dropped,
+			"tokens_before":    before,
+			"tokens_after":     after,
+			"tokens_shed":      before - after,
+		}
+	}
+}
+
+// countRequestTokens counts the tokens of the request with the model, or
+// estimates them if the model fails to count them.
+func countRequestTokens(ctx agent.InvocationContext, llm model.LLM, req *model.LLMRequest) int {
+	n, err := model.CountTokens(ctx, llm, req)
+	if err != nil {
+		log.Printf("Failed to count the tokens of the request of agent %s, estimating them: %v", ctx.Agent().Name(), err)
+		return model.EstimateTokens(req)
+	}
+	return n
+}
+
+// splitTurns splits the contents into turns, which start with a user
+// content that isn't only function responses, so that the function calls
+// and their responses are in the same turn.
+func splitTurns(contents []*genai.Content) [][]*genai.Content {
+	var turns [][]*genai.Content
+	for i, content := range contents {
+		if i == 0 || startsTurn(content) {
+			turns = append(turns, nil)
+		}
+		turns[len(turns)-1] = append(turns[len(turns)-1], content)
+	}
+	return turns
+}
+
+func startsTurn(content *genai.Content) bool {
+	if content == nil || content.Role != genai.RoleUser {
+		return false
+	}
+	for _, part := range content.Parts {
+		if part != nil && part.FunctionResponse == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// summarizeContents returns the summary of the contents written by the
+// model, from their transcript.
+func summarizeContents(ctx agent.InvocationContext, llm model.LLM, contents []*genai.Content) (string, error) {
+	req := &model.LLMRequest{
+		Model:    llm.Name(),
+		Contents: []*genai.Content{genai.NewContentFromText(historySummaryInstruction+transcript(contents), genai.RoleUser)},
+		Config:   &genai.GenerateContentConfig{},
+	}
+	var summary string
+	for resp, err := range llm.GenerateContent(ctx, req, false) {
+		if err != nil {
+			return "", fmt.Errorf("failed to call summary model: %w", err)
+		}
+		if resp.ErrorCode != "" {
+			return "", fmt.Errorf("summary model failed with %s: %s", resp.ErrorCode, resp.ErrorMessage)
+		}
+		if text, ok := replyText(resp); ok && !resp.Partial {
+			summary += text
+		}
+	}
+	if summary == "" {
+		return "", errors.New("summary model returned no summary")
+	}
+	return summary, nil
+}
+
+// transcript renders the text, the function calls and the function
+// responses of the contents, one line each. The thoughts are left out.
+func transcript(contents []*genai.Content) string {
+	var sb strings.Builder
+	for _, content := range contents {
+		if content == nil {
+			continue
+		}
+		for _, part := range content.Parts {
+			switch {
+			case part == nil || part.Thought:
+			case part.Text != "":
+				fmt.Fprintf(&sb, "%s: %s\n", content.Role, part.Text)
+			case part.FunctionCall != nil:
+				args, _ := json.Marshal(part.FunctionCall.Args)
+				fmt.Fprintf(&sb, "%s: called %s(%s)\n", content.Role, part.FunctionCall.Name, args)
+			case part.FunctionResponse != nil:
+				resp, _ := json.Marshal(part.FunctionResponse.Response)
+				fmt.Fprintf(&sb, "%s: %s returned %s\n", content.Role, part.FunctionResponse.Name, resp)
+			}
+		}
+	}
+	return sb.String()
+}
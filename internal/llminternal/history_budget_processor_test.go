@@ -0,0 +1,157 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llminternal
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	icontext "google.golang.org/adk/internal/context"
+	"google.golang.org/adk/internal/utils"
+	"google.golang.org/adk/model"
+)
+
+// summaryLLM replies with its summary, or fails with its error.
+type summaryLLM struct {
+	summary string
+	err     error
+	req     *model.LLMRequest
+}
+
+func (m *summaryLLM) Name() string { return "summary" }
+
+func (m *summaryLLM) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		m.req = req
+		if m.err != nil {
+			yield(nil, m.err)
+			return
+		}
+		yield(&model.LLMResponse{Content: genai.NewContentFromText(m.summary, genai.RoleModel)}, nil)
+	}
+}
+
+func TestHistoryBudgetRequestProcessor(t *testing.T) {
+	// Each text is 10 tokens, as estimated.
+	text := func(s string) string { return s + strings.Repeat(".", 40-len(s)) }
+	user := func(s string) *genai.Content { return genai.NewContentFromText(text(s), genai.RoleUser) }
+	reply := func(s string) *genai.Content { return genai.NewContentFromText(text(s), genai.RoleModel) }
+	call := &genai.Content{Role: genai.RoleModel, Parts: []*genai.Part{genai.NewPartFromFunctionCall("get_weather", map[string]any{"city": "Paris"})}}
+	response := &genai.Content{Role: genai.RoleUser, Parts: []*genai.Part{genai.NewPartFromFunctionResponse("get_weather", map[string]any{"weather": "sunny"})}}
+
+	history := []*genai.Content{user("q1"), reply("a1"), user("q2"), call, response, reply("a2"), user("q3")}
+
+	tests := []struct {
+		name         string
+		maxTokens    int
+		summaryModel *summaryLLM
+		want         []*genai.Content
+		wantMetadata map[string]any
+	}{
+		{
+			name:      "no_budget",
+			maxTokens: 0,
+			want:      history,
+		},
+		{
+			name:      "within_budget",
+			maxTokens: 1000,
+			want:      history,
+		},
+		{
+			name:      "drop_oldest_turn",
+			maxTokens: 70,
+			want:      history[2:],
+			wantMetadata: map[string]any{
+				"strategy":         "drop",
+				"dropped_contents": 2,
+			},
+		},
+		{
+			// The function call and its response are dropped together, the
+			// latest turn is kept even if it doesn't fit.
+			name:      "keep_latest_turn",
+			maxTokens: 1,
+			want:      history[6:],
+			wantMetadata: map[string]any{
+				"strategy":         "drop",
+				"dropped_contents": 6,
+			},
+		},
+		{
+			name:         "summarize",
+			maxTokens:    70,
+			summaryModel: &summaryLLM{summary: "The user asked q1."},
+			want:         append([]*genai.Content{genai.NewContentFromText(historySummaryPrefix+"The user asked q1.", genai.RoleUser)}, history[2:]...),
+			wantMetadata: map[string]any{
+				"strategy":         "summarize",
+				"dropped_contents": 2,
+			},
+		},
+		{
+			name:         "summary_failure",
+			maxTokens:    70,
+			summaryModel: &summaryLLM{err: errors.New("unavailable")},
+			want:         history[2:],
+			wantMetadata: map[string]any{
+				"strategy":         "drop",
+				"dropped_contents": 2,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := &State{HistoryMaxTokens: tt.maxTokens}
+			if tt.summaryModel != nil {
+				state.HistorySummaryModel = tt.summaryModel
+			}
+			a := &mockLLMAgent{Agent: utils.Must(agent.New(agent.Config{Name: "agent"})), s: state}
+			ctx := icontext.NewInvocationContext(t.Context(), icontext.InvocationContextParams{Agent: a})
+			req := &model.LLMRequest{Contents: history, Config: &genai.GenerateContentConfig{}}
+
+			for _, err := range historyBudgetRequestProcessor(ctx, req, &Flow{Model: &mockLLM{name: "model"}}) {
+				t.Fatalf("historyBudgetRequestProcessor() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, req.Contents); diff != "" {
+				t.Errorf("contents mismatch (-want +got):\n%s", diff)
+			}
+			if tt.wantMetadata != nil {
+				before := model.EstimateTokens(&model.LLMRequest{Contents: history})
+				after := model.EstimateTokens(&model.LLMRequest{Contents: tt.want})
+				tt.wantMetadata["tokens_before"] = before
+				tt.wantMetadata["tokens_after"] = after
+				tt.wantMetadata["tokens_shed"] = before - after
+				if diff := cmp.Diff(tt.wantMetadata, req.CustomMetadata[HistoryBudgetMetadataKey]); diff != "" {
+					t.Errorf("metadata mismatch (-want +got):\n%s", diff)
+				}
+			} else if len(req.CustomMetadata) > 0 {
+				t.Errorf("custom metadata = %v, want none", req.CustomMetadata)
+			}
+			if m := tt.summaryModel; m != nil && m.req != nil {
+				got := utils.TextParts(m.req.Contents[0])[0]
+				if !strings.Contains(got, "user: "+text("q1")) || !strings.Contains(got, "model: "+text("a1")) {
+					t.Errorf("summary request = %q, want the transcript of the first turn", got)
+				}
+			}
+		})
+	}
+}
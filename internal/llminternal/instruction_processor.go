@@ -15,13 +15,18 @@
 package llminternal
 
 import (
+	"errors"
 	"fmt"
 	"iter"
+	"log"
+	"mime"
 	"regexp"
 	"slices"
 	"strings"
 	"unicode"
 
+	"google.golang.org/genai"
+
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/internal/agent/parentmap"
 	icontext "google.golang.org/adk/internal/context"
@@ -45,35 +50,34 @@ func instructionsRequestProcessor(ctx agent.InvocationContext, req *model.LLMReq
 			return // do nothing.
 		}
 
-		parents := parentmap.FromContext(ctx)
-
-		rootAgent := asLLMAgent(parents.RootAgent(ctx.Agent()))
-		if rootAgent == nil {
-			rootAgent = llmAgent
-		}
-
-		// Append global instructions.
-		if err := appendGlobalInstructions(ctx, req, rootAgent.internal()); err != nil {
-			yield(nil, fmt.Errorf("failed to append global instructions: %w", err))
-			return
-		}
-
 		// Append agent's instruction
 		if err := appendInstructions(ctx, req, llmAgent.internal()); err != nil {
 			yield(nil, fmt.Errorf("failed to append instructions: %w", err))
 			return
 		}
+
+		// Prepend the global instruction of the root agent, only if it is an
+		// LLM agent, before all the other instructions.
+		parents := parentmap.FromContext(ctx)
+		if rootAgent := asLLMAgent(parents.RootAgent(ctx.Agent())); rootAgent != nil {
+			if err := prependGlobalInstructions(ctx, req, rootAgent.internal()); err != nil {
+				yield(nil, fmt.Errorf("failed to prepend global instructions: %w", err))
+				return
+			}
+		}
 	}
 }
 
 // The regex to find placeholders like {variable} or {artifact.file_name}.
+// The placeholders in double braces, like {{variable}}, are escaped: they
+// are replaced with the placeholder in single braces.
 var placeholderRegex = regexp.MustCompile(`{+[^{}]*}+`)
 
 func appendInstructions(ctx agent.InvocationContext, req *model.LLMRequest, agentState *State) error {
 	if agentState.InstructionProvider != nil {
 		instruction, err := agentState.InstructionProvider(icontext.NewReadonlyContext(ctx))
 		if err != nil {
-			return fmt.Errorf("failed to evaluate global instruction provider: %w", err)
+			return fmt.Errorf("failed to evaluate instruction provider: %w", err)
 		}
 
 		utils.AppendInstructions(req, instruction)
@@ -93,14 +97,14 @@ func appendInstructions(ctx agent.InvocationContext, req *model.LLMRequest, agen
 	return nil
 }
 
-func appendGlobalInstructions(ctx agent.InvocationContext, req *model.LLMRequest, agentState *State) error {
+func prependGlobalInstructions(ctx agent.InvocationContext, req *model.LLMRequest, agentState *State) error {
 	if agentState.GlobalInstructionProvider != nil {
 		instruction, err := agentState.GlobalInstructionProvider(icontext.NewReadonlyContext(ctx))
 		if err != nil {
 			return fmt.Errorf("failed to evaluate global instruction provider: %w", err)
 		}
 
-		utils.AppendInstructions(req, instruction)
+		utils.PrependInstructions(req, instruction)
 		return nil
 	}
 
@@ -113,10 +117,21 @@ func appendGlobalInstructions(ctx agent.InvocationContext, req *model.LLMRequest
 		return fmt.Errorf("failed to inject session state into global instruction: %w", err)
 	}
 
-	utils.AppendInstructions(req, inst)
+	utils.PrependInstructions(req, inst)
 	return nil
 }
 
+// HasGlobalInstruction reports whether the agent is an LLM agent with a
+// global instruction.
+func HasGlobalInstruction(a agent.Agent) bool {
+	llmAgent := asLLMAgent(a)
+	if llmAgent == nil {
+		return false
+	}
+	state := llmAgent.internal()
+	return state.GlobalInstruction != "" || state.GlobalInstructionProvider != nil
+}
+
 // replaceMatch is the Go equivalent of the _replace_match async function in the Python code.
 func replaceMatch(ctx agent.InvocationContext, match string) (string, error) {
 	// Trim curly braces: "{var_name}" -> "var_name"
@@ -130,6 +145,9 @@ func replaceMatch(ctx agent.InvocationContext, match string) (string, error) {
 	if after, ok := strings.CutPrefix(varName, "artifact."); ok {
 		fileName := after
 		if ctx.Artifacts() == nil {
+			if optional {
+				return "", nil
+			}
 			return "", fmt.Errorf("artifact service is not initialized")
 		}
 		resp, err := ctx.Artifacts().Load(ctx, fileName)
@@ -140,7 +158,7 @@ func replaceMatch(ctx agent.InvocationContext, match string) (string, error) {
 			}
 			return "", fmt.Errorf("failed to load artifact %s: %w", fileName, err)
 		}
-		return resp.Part.Text, nil
+		return artifactText(fileName, resp.Part)
 	}
 
 	if !isValidStateName(varName) {
@@ -150,7 +168,9 @@ func replaceMatch(ctx agent.InvocationContext, match string) (string, error) {
 	value, err := ctx.Session().State().Get(varName)
 	if err != nil {
 		if optional {
-			// TODO: log error when !errors.Is(err, session.ErrStateKeyNotExist)
+			if !errors.Is(err, session.ErrStateKeyNotExist) {
+				log.Printf("Failed to get the optional state key %q, leaving it empty: %v", varName, err)
+			}
 			return "", nil
 		}
 		return "", err
@@ -163,6 +183,34 @@ func replaceMatch(ctx agent.InvocationContext, match string) (string, error) {
 	return fmt.Sprintf("%v", value), nil
 }
 
+// artifactText returns the text of the artifact part, i.e. its text or its
+// inline data of a text MIME type.
+func artifactText(fileName string, part *genai.Part) (string, error) {
+	switch {
+	case part == nil:
+		return "", nil
+	case part.Text != "":
+		return part.Text, nil
+	case part.InlineData != nil && isTextMIMEType(part.InlineData.MIMEType):
+		return string(part.InlineData.Data), nil
+	case part.InlineData != nil:
+		return "", fmt.Errorf("artifact %s is not text, its MIME type is %q", fileName, part.InlineData.MIMEType)
+	}
+	return "", nil
+}
+
+func isTextMIMEType(mimeType string) bool {
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		return false
+	}
+	switch mediaType {
+	case "application/json", "application/xml", "application/yaml", "application/x-yaml":
+		return true
+	}
+	return strings.HasPrefix(mediaType, "text/")
+}
+
 // isIdentifier checks if a string is a valid Go identifier.
 // This is the equivalent of Python's `str.isidentifier()`.
 func isIdentifier(s string) bool {
@@ -213,11 +261,17 @@ func InjectSessionState(ctx agent.InvocationContext, template string) (string, e
 		// Append the text between the last match and this one
 		result.WriteString(template[lastIndex:startIndex])
 
-		// Get the replacement for the current match
 		matchStr := template[startIndex:endIndex]
+		if escaped, ok := unescapePlaceholder(matchStr); ok {
+			result.WriteString(escaped)
+			lastIndex = endIndex
+			continue
+		}
+
+		// Get the replacement for the current match
 		replacement, err := replaceMatch(ctx, matchStr)
 		if err != nil {
-			return "", err // Propagate the error
+			return "", fmt.Errorf("failed to resolve placeholder %s: %w", matchStr, err)
 		}
 		result.WriteString(replacement)
 
@@ -229,3 +283,12 @@ func InjectSessionState(ctx agent.InvocationContext, template string) (string, e
 
 	return result.String(), nil
 }
+
+// unescapePlaceholder returns the placeholder in single braces if match is
+// a placeholder in double braces, e.g. "{name}" for "{{name}}".
+func unescapePlaceholder(match string) (string, bool) {
+	if !strings.HasPrefix(match, "{{") || !strings.HasSuffix(match, "}}") {
+		return "", false
+	}
+	return match[1 : len(match)-1], true
+}
@@ -19,13 +19,17 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"google.golang.org/genai"
 
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/artifact"
+	"google.golang.org/adk/internal/agent/parentmap"
 	artifactinternal "google.golang.org/adk/internal/artifact"
 	icontext "google.golang.org/adk/internal/context"
 	"google.golang.org/adk/internal/sessioninternal"
+	"google.golang.org/adk/internal/utils"
+	"google.golang.org/adk/model"
 	"google.golang.org/adk/session"
 )
 
@@ -136,6 +140,48 @@ func TestInjectSessionState(t *testing.T) {
 			wantErr:    true,
 			wantErrMsg: "failed to load artifact : request validation failed: invalid load request: missing required fields: FileName",
 		},
+		{
+			name:     "escaped placeholders",
+			template: "Use {{user_name}} for the name of {user_name}, and {{artifact.my_file}} for the file.",
+			state:    map[string]any{"user_name": "Foo"},
+			want:     "Use {user_name} for the name of Foo, and {artifact.my_file} for the file.",
+		},
+		{
+			name:     "json is kept",
+			template: `Reply with {"name": "{user_name}"}.`,
+			state:    map[string]any{"user_name": "Foo"},
+			want:     `Reply with {"name": "Foo"}.`,
+		},
+		{
+			name:       "error names the placeholder",
+			template:   "Hello {missing_key}!",
+			state:      map[string]any{},
+			wantErr:    true,
+			wantErrMsg: "failed to resolve placeholder {missing_key}",
+		},
+		{
+			name:     "text inline data artifact",
+			template: "Spec: {artifact.spec_doc}",
+			artifacts: map[string]*genai.Part{
+				"spec_doc": genai.NewPartFromBytes([]byte("# Spec"), "text/markdown; charset=utf-8"),
+			},
+			want: "Spec: # Spec",
+		},
+		{
+			name:     "binary artifact",
+			template: "Image: {artifact.image}",
+			artifacts: map[string]*genai.Part{
+				"image": genai.NewPartFromBytes([]byte{0x89, 'P', 'N', 'G'}, "image/png"),
+			},
+			wantErr:    true,
+			wantErrMsg: `artifact image is not text, its MIME type is "image/png"`,
+		},
+		{
+			name:             "optional artifact without artifact service",
+			template:         "Optional artifact: {artifact.my_file?}",
+			expectNilService: true,
+			want:             "Optional artifact: ",
+		},
 		// Corresponds to: test_inject_session_state_with_multiple_variables_and_artifacts
 		{
 			name: "complex template with mixed variables and artifacts",
@@ -225,3 +271,59 @@ And another optional artifact:
 		})
 	}
 }
+
+func TestInstructionsRequestProcessor_GlobalInstruction(t *testing.T) {
+	sub := &mockLLMAgent{
+		Agent: utils.Must(agent.New(agent.Config{Name: "sub"})),
+		s:     &State{Instruction: "Answer about {topic}.", GlobalInstruction: "Ignored."},
+	}
+	root := &mockLLMAgent{
+		Agent: utils.Must(agent.New(agent.Config{Name: "root", SubAgents: []agent.Agent{sub}})),
+		s:     &State{Instruction: "Delegate.", GlobalInstruction: "You work for {company}."},
+	}
+	parents, err := parentmap.New(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessionService := session.InMemoryService()
+	createResp, err := sessionService.Create(t.Context(), &session.CreateRequest{
+		AppName:   "testApp",
+		UserID:    "testUser",
+		SessionID: "testSession",
+		State:     map[string]any{"company": "Acme", "topic": "anvils"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		agent agent.Agent
+		want  []string
+	}{
+		{"root", root, []string{"You work for Acme.", "Tool instruction.", "Delegate."}},
+		{"sub-agent", sub, []string{"You work for Acme.", "Tool instruction.", "Answer about anvils."}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := icontext.NewInvocationContext(parentmap.ToContext(t.Context(), parents), icontext.InvocationContextParams{
+				Agent:   tt.agent,
+				Session: sessioninternal.NewMutableSession(sessionService, createResp.Session),
+			})
+			req := &model.LLMRequest{}
+			utils.AppendInstructions(req, "Tool instruction.")
+			for _, err := range instructionsRequestProcessor(ctx, req, &Flow{}) {
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			var got []string
+			for _, part := range req.Config.SystemInstruction.Parts {
+				got = append(got, part.Text)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("SystemInstruction mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llminternal
+
+import (
+	"fmt"
+	"iter"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/internal/agent/runconfig"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+)
+
+// SupportsLive reports whether the agent runs in the live runs: it is an LLM
+// agent whose model supports live sessions, see [model.SupportsLive].
+func SupportsLive(a agent.Agent) bool {
+	llmAgent := asLLMAgent(a)
+	return llmAgent != nil && llmAgent.internal().Model != nil && model.SupportsLive(llmAgent.internal().Model)
+}
+
+// liveQueue returns the request queue of a live run of the flow, if its model
+// supports live sessions.
+func (f *Flow) liveQueue(ctx agent.InvocationContext) (*agent.LiveRequestQueue, bool) {
+	cfg := runconfig.FromContext(ctx)
+	if cfg == nil || cfg.LiveRequests == nil || f.Model == nil || !model.SupportsLive(f.Model) {
+		return nil, false
+	}
+	return cfg.LiveRequests, true
+}
+
+// received is a response of a live session.
+type received struct {
+	resp *model.LLMResponse
+	err  error
+}
+
+// runLive runs the flow in a live session of the model, until the queue is
+// closed or the session ends. The requests of the queue are sent to the model
+// as they come: the contents of the user are yielded as user events, the
+// realtime media isn't recorded. The responses of the model are yielded as
+// they come, and its function calls are run and answered in the session.
+func (f *Flow) runLive(ctx agent.InvocationContext, queue *agent.LiveRequestQueue) iter.Seq2[*session.Event, error] {
+	return func(yield func(*session.Event, error) bool) {
+		req := &model.LLMRequest{Model: f.Model.Name()}
+		for ev, err := range f.preprocess(ctx, req) {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if ev != nil {
+				if !yield(ev, nil) {
+					return
+				}
+			}
+		}
+		if ctx.Ended() {
+			return
+		}
+
+		conn, err := model.Connect(ctx, f.Model, req)
+		if err != nil {
+			yield(nil, fmt.Errorf("failed to connect to model %q: %w", f.Model.Name(), err))
+			return
+		}
+		defer conn.Close()
+
+		stop := make(chan struct{})
+		defer close(stop)
+		responses := make(chan received)
+		go func() {
+			defer close(responses)
+			for resp, err := range conn.Receive() {
+				select {
+				case responses <- received{resp: resp, err: err}:
+				case <-stop:
+					return
+				}
+				if err != nil {
+					return
+				}
+			}
+		}()
+
+		tools := make(map[string]tool.Tool)
+		for name, t := range req.Tools {
+			if t, ok := t.(tool.Tool); ok {
+				tools[name] = t
+			}
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-queue.Done():
+				return
+			case r := <-queue.Requests():
+				if r.Content != nil {
+					ev := session.NewEvent(ctx.InvocationID())
+					ev.Author = "user"
+					ev.Branch = ctx.Branch()
+					ev.Content = r.Content
+					if !yield(ev, nil) {
+						return
+					}
+					if err := conn.SendContent(r.Content); err != nil {
+						yield(nil, fmt.Errorf("failed to send content to model %q: %w", f.Model.Name(), err))
+						return
+					}
+				}
+				if r.Blob != nil {
+					if err := conn.SendRealtimeInput(r.Blob); err != nil {
+						yield(nil, fmt.Errorf("failed to send realtime input to model %q: %w", f.Model.Name(), err))
+						return
+					}
+				}
+			case rec, ok := <-responses:
+				if !ok {
+					return
+				}
+				if rec.err != nil {
+					yield(nil, rec.err)
+					return
+				}
+				ev := f.finalizeModelResponseEvent(ctx, req, rec.resp, tools, make(map[string]any))
+				if !yield(ev, nil) {
+					return
+				}
+				if rec.resp.Partial {
+					continue
+				}
+				fev, stopped, err := f.streamFunctionCalls(ctx, tools, rec.resp, nil, yield)
+				if stopped {
+					return
+				}
+				if err != nil {
+					yield(nil, err)
+					return
+				}
+				if fev == nil {
+					continue
+				}
+				if !yield(fev, nil) {
+					return
+				}
+				if err := conn.SendContent(fev.Content); err != nil {
+					yield(nil, fmt.Errorf("failed to send function responses to model %q: %w", f.Model.Name(), err))
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llminternal
+
+import (
+	"iter"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	icontext "google.golang.org/adk/internal/context"
+	"google.golang.org/adk/internal/utils"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/planner"
+	"google.golang.org/adk/session"
+)
+
+// plannerOf returns the planner of the agent of the context, nil if it has
+// none.
+func plannerOf(ctx agent.InvocationContext) planner.Planner {
+	llmAgent := asLLMAgent(ctx.Agent())
+	if llmAgent == nil {
+		return nil
+	}
+	return llmAgent.internal().Planner
+}
+
+// nlPlanningRequestProcessor appends the planning instruction of the planner
+// of the agent to the requests, and unmarks the thoughts of the contents, for
+// the model to see its previous plans. With a BuiltInPlanner, it applies its
+// thinking config instead, and the thoughts are left to the model.
+func nlPlanningRequestProcessor(ctx agent.InvocationContext, req *model.LLMRequest, f *Flow) iter.Seq2[*session.Event, error] {
+	return func(yield func(*session.Event, error) bool) {
+		p := plannerOf(ctx)
+		if p == nil {
+			return
+		}
+		if builtIn, ok := p.(*planner.BuiltInPlanner); ok {
+			builtIn.ApplyThinkingConfig(req)
+			return
+		}
+		if instruction := p.PlanningInstruction(icontext.NewReadonlyContext(ctx), req); instruction != "" {
+			utils.AppendInstructions(req, instruction)
+		}
+		for i, content := range req.Contents {
+			if content == nil || !hasThoughts(content) {
+				continue
+			}
+			unmarked := *content
+			unmarked.Parts = make([]*genai.Part, len(content.Parts))
+			for j, part := range content.Parts {
+				if part.Thought {
+					p := *part
+					p.Thought = false
+					part = &p
+				}
+				unmarked.Parts[j] = part
+			}
+			req.Contents[i] = &unmarked
+		}
+	}
+}
+
+func hasThoughts(content *genai.Content) bool {
+	for _, part := range content.Parts {
+		if part.Thought {
+			return true
+		}
+	}
+	return false
+}
+
+// nlPlanningResponseProcessor replaces the parts of the complete responses
+// with the ones processed by the planner of the agent.
+func nlPlanningResponseProcessor(ctx agent.InvocationContext, req *model.LLMRequest, resp *model.LLMResponse) error {
+	p := plannerOf(ctx)
+	if p == nil || resp.Partial || resp.Content == nil {
+		return nil
+	}
+	if parts := p.ProcessPlanningResponse(icontext.NewReadonlyContext(ctx), resp.Content.Parts); parts != nil {
+		content := *resp.Content
+		content.Parts = parts
+		resp.Content = &content
+	}
+	return nil
+}
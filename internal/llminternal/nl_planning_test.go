@@ -0,0 +1,134 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llminternal
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	icontext "google.golang.org/adk/internal/context"
+	"google.golang.org/adk/internal/utils"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/planner"
+)
+
+// fakePlanner instructs the model with its instruction, and keeps the last
+// part of the responses.
+type fakePlanner struct {
+	instruction string
+}
+
+func (p *fakePlanner) PlanningInstruction(agent.ReadonlyContext, *model.LLMRequest) string {
+	return p.instruction
+}
+
+func (p *fakePlanner) ProcessPlanningResponse(_ agent.ReadonlyContext, parts []*genai.Part) []*genai.Part {
+	if len(parts) == 0 {
+		return nil
+	}
+	return parts[len(parts)-1:]
+}
+
+func newPlanningContext(t *testing.T, p planner.Planner) agent.InvocationContext {
+	t.Helper()
+	a := &mockLLMAgent{Agent: utils.Must(agent.New(agent.Config{Name: "agent"})), s: &State{Planner: p}}
+	return icontext.NewInvocationContext(t.Context(), icontext.InvocationContextParams{Agent: a, InvocationID: "invocation"})
+}
+
+func TestNLPlanningRequestProcessor(t *testing.T) {
+	plan := &genai.Part{Text: "/*PLANNING*/ 1. Answer.", Thought: true}
+	contents := []*genai.Content{
+		genai.NewContentFromText("question", genai.RoleUser),
+		genai.NewContentFromParts([]*genai.Part{plan, genai.NewPartFromText("answer")}, genai.RoleModel),
+	}
+	req := &model.LLMRequest{Contents: append([]*genai.Content(nil), contents...)}
+	ctx := newPlanningContext(t, &fakePlanner{instruction: "Plan first."})
+	for _, err := range nlPlanningRequestProcessor(ctx, req, &Flow{}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if req.Config == nil || req.Config.SystemInstruction == nil {
+		t.Fatalf("SystemInstruction = nil, want the planning instruction")
+	}
+	if diff := cmp.Diff(genai.NewContentFromText("Plan first.", genai.RoleUser).Parts, req.Config.SystemInstruction.Parts); diff != "" {
+		t.Errorf("SystemInstruction mismatch (-want +got):\n%s", diff)
+	}
+	want := []*genai.Content{
+		genai.NewContentFromText("question", genai.RoleUser),
+		genai.NewContentFromParts([]*genai.Part{genai.NewPartFromText("/*PLANNING*/ 1. Answer."), genai.NewPartFromText("answer")}, genai.RoleModel),
+	}
+	if diff := cmp.Diff(want, req.Contents); diff != "" {
+		t.Errorf("Contents mismatch (-want +got):\n%s", diff)
+	}
+	if !plan.Thought || !contents[1].Parts[0].Thought {
+		t.Errorf("the thoughts of the original contents were unmarked")
+	}
+}
+
+func TestNLPlanningRequestProcessor_NoPlanner(t *testing.T) {
+	req := &model.LLMRequest{Contents: []*genai.Content{
+		genai.NewContentFromParts([]*genai.Part{{Text: "thinking", Thought: true}}, genai.RoleModel),
+	}}
+	for _, err := range nlPlanningRequestProcessor(newPlanningContext(t, nil), req, &Flow{}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if req.Config != nil && req.Config.SystemInstruction != nil {
+		t.Errorf("SystemInstruction = %v, want nil", req.Config.SystemInstruction)
+	}
+	if !req.Contents[0].Parts[0].Thought {
+		t.Errorf("thought unmarked without a planner")
+	}
+}
+
+func TestNLPlanningResponseProcessor(t *testing.T) {
+	tests := []struct {
+		name string
+		resp *model.LLMResponse
+		want *genai.Content
+	}{
+		{
+			name: "complete",
+			resp: &model.LLMResponse{Content: genai.NewContentFromParts([]*genai.Part{genai.NewPartFromText("plan"), genai.NewPartFromText("answer")}, genai.RoleModel)},
+			want: genai.NewContentFromText("answer", genai.RoleModel),
+		},
+		{
+			name: "partial",
+			resp: &model.LLMResponse{Partial: true, Content: genai.NewContentFromParts([]*genai.Part{genai.NewPartFromText("plan"), genai.NewPartFromText("answer")}, genai.RoleModel)},
+			want: genai.NewContentFromParts([]*genai.Part{genai.NewPartFromText("plan"), genai.NewPartFromText("answer")}, genai.RoleModel),
+		},
+		{
+			name: "no content",
+			resp: &model.LLMResponse{},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := nlPlanningResponseProcessor(newPlanningContext(t, &fakePlanner{}), &model.LLMRequest{}, tt.resp); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, tt.resp.Content); diff != "" {
+				t.Errorf("Content mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llminternal
+
+import (
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/internal/utils"
+	"google.golang.org/adk/model"
+)
+
+// ErrorCodeInvalidOutput is the error code of the responses replacing the
+// replies which don't match the output schema of their agent, after the
+// repair turns.
+const ErrorCodeInvalidOutput = "INVALID_OUTPUT"
+
+const repairInstruction = "Your previous reply doesn't match the required output schema: %v. " +
+	"Reply again with only the JSON value matching the schema, without any other text."
+
+// callLLMWithOutputValidation calls the model like callLLM, and validates the
+// text replies against the output schema of the agent, if it has one which
+// is set on the requests. The invalid replies are not yielded: they are
+// sent back to the model with the validation errors, for it to repair them.
+func (f *Flow) callLLMWithOutputValidation(ctx agent.InvocationContext, req *model.LLMRequest, stateDelta map[string]any) iter.Seq2[*model.LLMResponse, error] {
+	llmAgent := asLLMAgent(ctx.Agent())
+	if llmAgent == nil {
+		return f.callLLM(ctx, req, stateDelta)
+	}
+	state := llmAgent.internal()
+	// With the set_model_response tool, the output is validated by the tool.
+	if state.OutputSchema == nil || needOutputSchemaProcessor(state) {
+		return f.callLLM(ctx, req, stateDelta)
+	}
+	return func(yield func(*model.LLMResponse, error) bool) {
+		attemptReq := req
+		for attempt := 0; ; attempt++ {
+			var invalid *model.LLMResponse
+			var validationErr error
+			for resp, err := range f.callLLM(ctx, attemptReq, stateDelta) {
+				if err == nil && !resp.Partial {
+					if text, ok := replyText(resp); ok {
+						if _, validationErr = utils.ValidateOutput(text, state.OutputSchema); validationErr != nil {
+							invalid = resp
+							break
+						}
+					}
+				}
+				if !yield(resp, err) {
+					return
+				}
+			}
+			if invalid == nil {
+				return
+			}
+
+			if attempt < state.OutputRepairAttempts {
+				repairReq := *req
+				repairReq.Contents = append(slices.Clone(attemptReq.Contents),
+					invalid.Content,
+					genai.NewContentFromText(fmt.Sprintf(repairInstruction, validationErr), genai.RoleUser))
+				attemptReq = &repairReq
+				continue
+			}
+			if state.OutputValidationLenient {
+				yield(invalid, nil)
+				return
+			}
+			yield(&model.LLMResponse{
+				ErrorCode:    ErrorCodeInvalidOutput,
+				ErrorMessage: fmt.Sprintf("reply doesn't match the output schema after %d repair attempts: %v", attempt, validationErr),
+				TurnComplete: true,
+			}, nil)
+			return
+		}
+	}
+}
+
+// replyText returns the text of the response, if it is a text reply without
+// function calls.
+func replyText(resp *model.LLMResponse) (string, bool) {
+	if resp.Content == nil || len(utils.FunctionCalls(resp.Content)) > 0 {
+		return "", false
+	}
+	var sb strings.Builder
+	for _, part := range resp.Content.Parts {
+		if part.Text != "" && !part.Thought {
+			sb.WriteString(part.Text)
+		}
+	}
+	if strings.TrimSpace(sb.String()) == "" {
+		return "", false
+	}
+	return sb.String(), true
+}
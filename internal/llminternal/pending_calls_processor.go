@@ -0,0 +1,81 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llminternal
+
+import (
+	"iter"
+	"slices"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/internal/checkpoint"
+	"google.golang.org/adk/internal/utils"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+)
+
+// pendingCallsRequestProcessor runs, in a resumed invocation, the function
+// calls of the last model response of the agent which have no response: the
+// invocation was interrupted while they ran. The calls with a response in
+// the session ended before the interruption, and don't run again. The
+// long-running calls are left pending.
+func pendingCallsRequestProcessor(ctx agent.InvocationContext, req *model.LLMRequest, f *Flow) iter.Seq2[*session.Event, error] {
+	return func(yield func(*session.Event, error) bool) {
+		if !checkpoint.Resuming(ctx) || ctx.Session() == nil {
+			return
+		}
+
+		var last *session.Event
+		responded := make(map[string]bool)
+		for event := range ctx.Session().Events().All() {
+			if event.InvocationID != ctx.InvocationID() {
+				continue
+			}
+			for _, fr := range utils.FunctionResponses(event.Content) {
+				responded[fr.ID] = true
+			}
+			if event.Author == ctx.Agent().Name() && event.Branch == ctx.Branch() && event.Content != nil {
+				last = event
+			}
+		}
+		if last == nil {
+			return
+		}
+		var parts []*genai.Part
+		for _, fc := range utils.FunctionCalls(last.Content) {
+			if responded[fc.ID] || slices.Contains(last.LongRunningToolIDs, fc.ID) {
+				continue
+			}
+			parts = append(parts, &genai.Part{FunctionCall: fc})
+		}
+		if len(parts) == 0 {
+			return
+		}
+
+		toolsmap := make(map[string]tool.Tool)
+		for _, tool := range f.Tools {
+			toolsmap[tool.Name()] = tool
+		}
+		ev, stopped, err := f.streamFunctionCalls(ctx, toolsmap, &model.LLMResponse{
+			Content: &genai.Content{Parts: parts, Role: genai.RoleModel},
+		}, nil, yield)
+		if stopped || ev == nil && err == nil {
+			return
+		}
+		yield(ev, err)
+	}
+}
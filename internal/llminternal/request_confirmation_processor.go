@@ -53,7 +53,6 @@ func RequestConfirmationRequestProcessor(ctx agent.InvocationContext, req *model
 			}
 		}
 		confirmationResponses := make(map[string]toolconfirmation.ToolConfirmation)
-		confirmationEventIndex := -1
 		for k := len(events) - 1; k >= 0; k-- {
 			event := events[k]
 			// Find the first event authored by user
@@ -98,7 +97,6 @@ func RequestConfirmationRequestProcessor(ctx agent.InvocationContext, req *model
 				}
 				confirmationResponses[funcResp.ID] = tc
 			}
-			confirmationEventIndex = k
 			break
 		}
 
@@ -106,7 +104,6 @@ func RequestConfirmationRequestProcessor(ctx agent.InvocationContext, req *model
 			return
 		}
 
-		// TODO could we skip events for >= confirmationEventIndex
 		for k := len(events) - 2; k >= 0; k-- {
 			event := events[k]
 			// Find the system generated FunctionCall event requesting the tool confirmation
@@ -124,6 +121,12 @@ func RequestConfirmationRequestProcessor(ctx agent.InvocationContext, req *model
 				if err != nil {
 					continue
 				}
+				if confirmation.Confirmed && confirmation.Arguments != nil {
+					// The call is executed with the arguments edited by the user.
+					editedCall := *originalFunctionCall
+					editedCall.Args = confirmation.Arguments
+					originalFunctionCall = &editedCall
+				}
 
 				toolsToResumeByFunctionCallID[originalFunctionCall.ID] = &confirmedCall{
 					confirmation: &confirmation,
@@ -136,14 +139,20 @@ func RequestConfirmationRequestProcessor(ctx agent.InvocationContext, req *model
 			}
 
 			// TODO consider forward or backward pass instead of nested loops
-			// Remove the tools that have already been confirmed.
-			for j := len(events) - 1; j > confirmationEventIndex; j-- {
+			// Remove the tools that have already been confirmed, i.e. whose
+			// calls have a response after the confirmation request other than
+			// the one requesting the confirmation, so that a confirmation
+			// can't be replayed.
+			for j := len(events) - 1; j > k; j-- {
 				event = events[j]
 				responses := utils.FunctionResponses(event.Content)
 				if len(responses) == 0 {
 					continue
 				}
 				for _, resp := range responses {
+					if _, requested := event.Actions.RequestedToolConfirmations[resp.ID]; requested {
+						continue
+					}
 					delete(toolsToResumeByFunctionCallID, resp.ID)
 				}
 				if len(toolsToResumeByFunctionCallID) == 0 {
@@ -161,10 +170,10 @@ func RequestConfirmationRequestProcessor(ctx agent.InvocationContext, req *model
 				toolsToResumeConfirmation[callID] = cc.confirmation
 			}
 
-			ev, err := f.handleFunctionCalls(ctx, toolsmap, &model.LLMResponse{
+			ev, stopped, err := f.streamFunctionCalls(ctx, toolsmap, &model.LLMResponse{
 				Content: &genai.Content{Parts: parts, Role: genai.RoleUser},
-			}, toolsToResumeConfirmation)
-			if !yield(ev, err) {
+			}, toolsToResumeConfirmation, yield)
+			if stopped || !yield(ev, err) {
 				return
 			}
 		}
@@ -273,6 +273,8 @@ func TestRequestConfirmationRequestProcessor(t *testing.T) {
 					cmpopts.IgnoreFields(session.Event{}, "Timestamp"),
 					cmpopts.IgnoreFields(session.Event{}, "InvocationID"),
 					cmpopts.IgnoreFields(session.EventActions{}, "StateDelta"),
+					// The durations of the function calls vary.
+					cmpopts.IgnoreFields(model.LLMResponse{}, "CustomMetadata"),
 				}
 
 				if diff := cmp.Diff(tt.wantEvents, gotEvents, ignoreFields...); diff != "" {
@@ -34,6 +34,8 @@ type streamingResponseAggregator struct {
 	thoughtText string
 	response    *model.LLMResponse
 	role        string
+	// grounding is the grounding metadata of the responses aggregated so far.
+	grounding *genai.GroundingMetadata
 }
 
 // NewStreamingResponseAggregator creates a new, initialized streamingResponseAggregator.
@@ -70,6 +72,7 @@ func (s *streamingResponseAggregator) ProcessResponse(ctx context.Context, genRe
 // returning an aggregated response if the next event has zero parts or is audio data
 func (s *streamingResponseAggregator) aggregateResponse(llmResponse *model.LLMResponse) *model.LLMResponse {
 	s.response = llmResponse
+	s.grounding = mergeGroundingMetadata(s.grounding, llmResponse.GroundingMetadata)
 
 	var part0 *genai.Part
 	if llmResponse.Content != nil && len(llmResponse.Content.Parts) > 0 {
@@ -100,7 +103,9 @@ func (s *streamingResponseAggregator) aggregateResponse(llmResponse *model.LLMRe
 			len(llmResponse.Content.Parts) == 0 ||
 			// don't yield the merged text event when receiving audio data
 			(len(llmResponse.Content.Parts) > 0 && llmResponse.Content.Parts[0].InlineData == nil)) {
-		return s.createAggregateResponse()
+		// The usage of the response is that of the whole call, it is
+		// reported by the response itself.
+		return s.createAggregateResponse(false)
 	}
 
 	return nil
@@ -109,10 +114,13 @@ func (s *streamingResponseAggregator) aggregateResponse(llmResponse *model.LLMRe
 // Close generates an aggregated response at the end, if needed,
 // this should be called after all the model responses are processed.
 func (s *streamingResponseAggregator) Close() *model.LLMResponse {
-	return s.createAggregateResponse()
+	return s.createAggregateResponse(true)
 }
 
-func (s *streamingResponseAggregator) createAggregateResponse() *model.LLMResponse {
+// createAggregateResponse returns the aggregated response, with the usage of
+// the last response if withUsage is set. The usage metadata of a call must
+// be reported by exactly one of its non-partial responses.
+func (s *streamingResponseAggregator) createAggregateResponse(withUsage bool) *model.LLMResponse {
 	if (s.text != "" || s.thoughtText != "") && s.response != nil {
 		var parts []*genai.Part
 		if s.thoughtText != "" {
@@ -126,10 +134,12 @@ func (s *streamingResponseAggregator) createAggregateResponse() *model.LLMRespon
 			Content:           &genai.Content{Parts: parts, Role: s.role},
 			ErrorCode:         s.response.ErrorCode,
 			ErrorMessage:      s.response.ErrorMessage,
-			UsageMetadata:     s.response.UsageMetadata,
-			GroundingMetadata: s.response.GroundingMetadata,
+			GroundingMetadata: s.grounding,
 			FinishReason:      s.response.FinishReason,
 		}
+		if withUsage {
+			response.UsageMetadata = s.response.UsageMetadata
+		}
 		s.clear()
 		return response
 	}
@@ -142,4 +152,5 @@ func (s *streamingResponseAggregator) clear() {
 	s.text = ""
 	s.thoughtText = ""
 	s.role = ""
+	s.grounding = nil
 }
@@ -20,6 +20,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"google.golang.org/genai"
 
+	"google.golang.org/adk/internal/llminternal"
 	"google.golang.org/adk/internal/testutil"
 	"google.golang.org/adk/model"
 )
@@ -205,3 +206,112 @@ func TestStreamAggregator(t *testing.T) {
 		})
 	}
 }
+
+func TestStreamAggregator_Usage(t *testing.T) {
+	usage := &genai.GenerateContentResponseUsageMetadata{PromptTokenCount: 10, CandidatesTokenCount: 5, TotalTokenCount: 15}
+	tests := []struct {
+		name   string
+		chunks []*genai.Content
+	}{
+		{
+			name:   "text",
+			chunks: []*genai.Content{genai.NewContentFromText("hel", genai.RoleModel), genai.NewContentFromText("lo", genai.RoleModel)},
+		},
+		{
+			name: "text then function call",
+			chunks: []*genai.Content{
+				genai.NewContentFromText("let me check", genai.RoleModel),
+				genai.NewContentFromFunctionCall("get_weather", map[string]any{"city": "Paris"}, genai.RoleModel),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aggregator := llminternal.NewStreamingResponseAggregator()
+			var responses []*model.LLMResponse
+			for _, chunk := range tt.chunks {
+				for resp, err := range aggregator.ProcessResponse(t.Context(), &genai.GenerateContentResponse{
+					Candidates:    []*genai.Candidate{{Content: chunk}},
+					UsageMetadata: usage,
+				}) {
+					if err != nil {
+						t.Fatalf("ProcessResponse() error = %v", err)
+					}
+					responses = append(responses, resp)
+				}
+			}
+			if resp := aggregator.Close(); resp != nil {
+				responses = append(responses, resp)
+			}
+			// The usage of the call must be counted once, from the
+			// non-partial responses.
+			count := 0
+			for _, resp := range responses {
+				if !resp.Partial && resp.UsageMetadata != nil {
+					count++
+				}
+			}
+			if count != 1 {
+				t.Errorf("non-partial responses with usage = %d, want 1", count)
+			}
+		})
+	}
+}
+
+func TestStreamAggregator_GroundingMetadata(t *testing.T) {
+	web := func(uri string) *genai.GroundingChunk {
+		return &genai.GroundingChunk{Web: &genai.GroundingChunkWeb{URI: uri}}
+	}
+	chunks := []*genai.GenerateContentResponse{
+		{Candidates: []*genai.Candidate{{
+			Content:           genai.NewContentFromText("Paris is the capital of France.", genai.RoleModel),
+			GroundingMetadata: &genai.GroundingMetadata{WebSearchQueries: []string{"capital of france"}},
+		}}},
+		{Candidates: []*genai.Candidate{{
+			Content: genai.NewContentFromText(" It has 2 million inhabitants.", genai.RoleModel),
+			GroundingMetadata: &genai.GroundingMetadata{
+				GroundingChunks: []*genai.GroundingChunk{web("https://a.example")},
+				GroundingSupports: []*genai.GroundingSupport{
+					{Segment: &genai.Segment{EndIndex: 31}, GroundingChunkIndices: []int32{0}},
+				},
+			},
+		}}},
+		{Candidates: []*genai.Candidate{{
+			FinishReason: genai.FinishReasonStop,
+			GroundingMetadata: &genai.GroundingMetadata{
+				GroundingChunks: []*genai.GroundingChunk{web("https://b.example"), web("https://a.example")},
+				GroundingSupports: []*genai.GroundingSupport{
+					{Segment: &genai.Segment{StartIndex: 32, EndIndex: 62}, GroundingChunkIndices: []int32{0, 1}},
+				},
+				SearchEntryPoint: &genai.SearchEntryPoint{RenderedContent: "<div></div>"},
+			},
+		}}},
+	}
+	aggregator := llminternal.NewStreamingResponseAggregator()
+	var final *model.LLMResponse
+	for _, chunk := range chunks {
+		for resp, err := range aggregator.ProcessResponse(t.Context(), chunk) {
+			if err != nil {
+				t.Fatalf("ProcessResponse() error = %v", err)
+			}
+			if !resp.Partial && resp.Content != nil {
+				final = resp
+			}
+		}
+	}
+	if final == nil {
+		t.Fatalf("no aggregated response")
+	}
+	want := &genai.GroundingMetadata{
+		WebSearchQueries: []string{"capital of france"},
+		GroundingChunks:  []*genai.GroundingChunk{web("https://a.example"), web("https://b.example")},
+		GroundingSupports: []*genai.GroundingSupport{
+			{Segment: &genai.Segment{EndIndex: 31}, GroundingChunkIndices: []int32{0}},
+			{Segment: &genai.Segment{StartIndex: 32, EndIndex: 62}, GroundingChunkIndices: []int32{1, 0}},
+		},
+		SearchEntryPoint: &genai.SearchEntryPoint{RenderedContent: "<div></div>"},
+	}
+	if diff := cmp.Diff(want, final.GroundingMetadata); diff != "" {
+		t.Errorf("grounding metadata of the aggregated response mismatch (-want +got):\n%s", diff)
+	}
+}
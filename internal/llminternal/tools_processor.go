@@ -24,11 +24,13 @@ import (
 	"google.golang.org/adk/session"
 )
 
-// ContentRequestProcessor populates the LLMRequest's Contents based on
-// the InvocationContext that includes the previous events.
+// toolProcessor sets the Tools of the flow to the tools of the agent and of
+// its toolsets, unless the flow has its own. The toolsets are called for each
+// model request, so that the tools they return can depend on the state of
+// the session, which the previous tool calls of the invocation may change.
 func toolProcessor(ctx agent.InvocationContext, req *model.LLMRequest, f *Flow) iter.Seq2[*session.Event, error] {
 	return func(yield func(*session.Event, error) bool) {
-		if f.Tools != nil {
+		if f.Tools != nil && !f.agentTools {
 			return
 		}
 		llmAgent, ok := ctx.Agent().(Agent)
@@ -46,6 +48,6 @@ func toolProcessor(ctx agent.InvocationContext, req *model.LLMRequest, f *Flow)
 
 			tools = append(tools, tsTools...)
 		}
-		f.Tools = tools
+		f.Tools, f.agentTools = tools, true
 	}
 }
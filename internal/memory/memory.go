@@ -39,3 +39,15 @@ func (a *Memory) Search(ctx context.Context, query string) (*memory.SearchRespon
 		Query:   query,
 	})
 }
+
+// Forget deletes the memories of the user, whatever the app and user of the
+// request.
+func (a *Memory) Forget(ctx context.Context, req *memory.ForgetRequest) (*memory.ForgetResponse, error) {
+	f, ok := a.Service.(memory.Forgetter)
+	if !ok {
+		return nil, memory.ErrForgetUnsupported
+	}
+	scoped := *req
+	scoped.AppName, scoped.UserID = a.AppName, a.UserID
+	return f.Forget(ctx, &scoped)
+}
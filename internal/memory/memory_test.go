@@ -15,6 +15,8 @@
 package memory_test
 
 import (
+	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -43,6 +45,7 @@ func TestMemory_AddAndSearch(t *testing.T) {
 
 	events := []*session.Event{
 		{
+			ID:        "e1",
 			Timestamp: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC),
 			Author:    "user1",
 			LLMResponse: model.LLMResponse{
@@ -50,6 +53,7 @@ func TestMemory_AddAndSearch(t *testing.T) {
 			},
 		},
 		{
+			ID:        "e2",
 			Timestamp: time.Date(2025, 1, 1, 10, 5, 0, 0, time.UTC),
 			Author:    "user1",
 			LLMResponse: model.LLMResponse{
@@ -82,11 +86,17 @@ func TestMemory_AddAndSearch(t *testing.T) {
 		Content:   content1,
 		Author:    "user1",
 		Timestamp: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC),
+		SessionID: sessionID,
+		EventID:   "e1",
+		ID:        sessionID + "/e1",
 	}
 	entry2 := memory.Entry{
 		Content:   content2,
 		Author:    "user1",
 		Timestamp: time.Date(2025, 1, 1, 10, 5, 0, 0, time.UTC),
+		SessionID: sessionID,
+		EventID:   "e2",
+		ID:        sessionID + "/e2",
 	}
 
 	tests := []struct {
@@ -252,3 +262,62 @@ func TestMemory_Search_Isolation(t *testing.T) {
 		t.Errorf("memory2.Search returned diff (-want +got):\n%s", diff)
 	}
 }
+
+func TestMemory_Forget(t *testing.T) {
+	memService := memory.InMemoryService()
+	sessionService := session.InMemoryService()
+	var memories []*imemory.Memory
+	for _, userID := range []string{"user1", "user2"} {
+		m := &imemory.Memory{Service: memService, UserID: userID, AppName: "testApp", SessionID: "sess1"}
+		memories = append(memories, m)
+		created, err := sessionService.Create(t.Context(), &session.CreateRequest{AppName: "testApp", UserID: userID, SessionID: "sess1"})
+		if err != nil {
+			t.Fatalf("Failed to create session: %v", err)
+		}
+		if err := sessionService.AppendEvent(t.Context(), created.Session, &session.Event{
+			ID:          "e1",
+			Timestamp:   time.Now(),
+			Author:      userID,
+			LLMResponse: model.LLMResponse{Content: genai.NewContentFromText("My flight is tomorrow", genai.RoleUser)},
+		}); err != nil {
+			t.Fatalf("Failed to append event: %v", err)
+		}
+		if err := m.AddSession(t.Context(), sessioninternal.NewMutableSession(sessionService, created.Session)); err != nil {
+			t.Fatalf("AddSession failed: %v", err)
+		}
+	}
+
+	// The app and user of the request are ignored.
+	got, err := memories[0].Forget(t.Context(), &memory.ForgetRequest{AppName: "testApp", UserID: "user2", Text: "flight"})
+	if err != nil {
+		t.Fatalf("Forget() failed: %v", err)
+	}
+	if got.Forgotten != 1 {
+		t.Errorf("Forget() forgot %d memories, want 1", got.Forgotten)
+	}
+	for i, want := range []int{0, 1} {
+		res, err := memories[i].Search(t.Context(), "flight")
+		if err != nil {
+			t.Fatalf("Search() failed: %v", err)
+		}
+		if len(res.Memories) != want {
+			t.Errorf("Search() of user %d returned %d memories, want %d", i+1, len(res.Memories), want)
+		}
+	}
+
+	unsupported := &imemory.Memory{Service: searchOnlyService{}, UserID: "user1", AppName: "testApp"}
+	if _, err := unsupported.Forget(t.Context(), &memory.ForgetRequest{Text: "flight"}); !errors.Is(err, memory.ErrForgetUnsupported) {
+		t.Errorf("Forget() error = %v, want %v", err, memory.ErrForgetUnsupported)
+	}
+}
+
+// searchOnlyService is a memory service which can't forget.
+type searchOnlyService struct{}
+
+func (searchOnlyService) AddSession(ctx context.Context, s session.Session) error {
+	return nil
+}
+
+func (searchOnlyService) Search(ctx context.Context, req *memory.SearchRequest) (*memory.SearchResponse, error) {
+	return &memory.SearchResponse{}, nil
+}
@@ -0,0 +1,57 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package netinternal
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestIsPrivate(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{addr: "8.8.8.8", want: false},
+		{addr: "2001:4860:4860::8888", want: false},
+		{addr: "100.128.0.1", want: false},
+		{addr: "127.0.0.1", want: true},
+		{addr: "::1", want: true},
+		{addr: "10.0.0.1", want: true},
+		{addr: "192.168.1.1", want: true},
+		{addr: "fd00::1", want: true},
+		{addr: "169.254.169.254", want: true},
+		{addr: "fe80::1", want: true},
+		{addr: "0.0.0.0", want: true},
+		{addr: "::", want: true},
+		{addr: "100.64.0.1", want: true},
+		{addr: "224.0.0.1", want: true},
+		{addr: "239.255.255.250", want: true},
+		{addr: "ff02::1", want: true},
+		{addr: "::ffff:127.0.0.1", want: true},
+		{addr: "::ffff:10.0.0.1", want: true},
+		{addr: "::ffff:8.8.8.8", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			if got := IsPrivate(netip.MustParseAddr(tt.addr)); got != tt.want {
+				t.Errorf("IsPrivate(%s) = %v, want %v", tt.addr, got, tt.want)
+			}
+		})
+	}
+	if !IsPrivate(netip.Addr{}) {
+		t.Error("IsPrivate(zero Addr) = false, want true")
+	}
+}
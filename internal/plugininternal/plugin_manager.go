@@ -286,3 +286,9 @@ func (pm *PluginManager) Close() error {
 func ToContext(ctx context.Context, cfg *PluginManager) context.Context {
 	return context.WithValue(ctx, plugincontext.PluginManagerCtxKey, cfg)
 }
+
+// WithScratch returns the context of an invocation with a new scratch space
+// shared by the plugins, see [plugin.ScratchFromContext].
+func WithScratch(ctx context.Context) context.Context {
+	return context.WithValue(ctx, plugincontext.ScratchCtxKey, &plugin.Scratch{})
+}
@@ -16,4 +16,7 @@ package plugincontext
 
 type ctxKey int
 
-const PluginManagerCtxKey ctxKey = 0
+const (
+	PluginManagerCtxKey ctxKey = 0
+	ScratchCtxKey       ctxKey = 1
+)
@@ -15,8 +15,10 @@
 package sessioninternal
 
 import (
+	"context"
 	"fmt"
 	"iter"
+	"sync"
 	"time"
 
 	"google.golang.org/adk/session"
@@ -24,7 +26,9 @@ import (
 
 // MutableSession implements session.Session
 type MutableSession struct {
-	service       session.Service
+	service session.Service
+
+	mu            sync.RWMutex
 	storedSession session.Session
 }
 
@@ -36,32 +40,58 @@ func NewMutableSession(service session.Service, storedSession session.Session) *
 	}
 }
 
+// StoredSession returns the session of the service wrapped by s.
+func (s *MutableSession) StoredSession() session.Session {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.storedSession
+}
+
+// Reload gets the session from the service again, e.g. after
+// [session.ErrConcurrentModification], and wraps it instead of the previous
+// one.
+func (s *MutableSession) Reload(ctx context.Context) error {
+	stored := s.StoredSession()
+	resp, err := s.service.Get(ctx, &session.GetRequest{
+		AppName:   stored.AppName(),
+		UserID:    stored.UserID(),
+		SessionID: stored.ID(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get session: %w", err)
+	}
+	s.mu.Lock()
+	s.storedSession = resp.Session
+	s.mu.Unlock()
+	return nil
+}
+
 func (s *MutableSession) State() session.State {
 	return s
 }
 
 func (s *MutableSession) AppName() string {
-	return s.storedSession.AppName()
+	return s.StoredSession().AppName()
 }
 
 func (s *MutableSession) UserID() string {
-	return s.storedSession.UserID()
+	return s.StoredSession().UserID()
 }
 
 func (s *MutableSession) ID() string {
-	return s.storedSession.ID()
+	return s.StoredSession().ID()
 }
 
 func (s *MutableSession) Events() session.Events {
-	return s.storedSession.Events()
+	return s.StoredSession().Events()
 }
 
 func (s *MutableSession) LastUpdateTime() time.Time {
-	return s.storedSession.LastUpdateTime()
+	return s.StoredSession().LastUpdateTime()
 }
 
 func (s *MutableSession) Get(key string) (any, error) {
-	value, err := s.storedSession.State().Get(key)
+	value, err := s.StoredSession().State().Get(key)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get key %q from state: %w", key, err)
 	}
@@ -69,11 +99,11 @@ func (s *MutableSession) Get(key string) (any, error) {
 }
 
 func (s *MutableSession) All() iter.Seq2[string, any] {
-	return s.storedSession.State().All()
+	return s.StoredSession().State().All()
 }
 
 func (s *MutableSession) Set(key string, value any) error {
-	mutableState, ok := s.storedSession.State().(MutableState)
+	mutableState, ok := s.StoredSession().State().(MutableState)
 	if !ok {
 		return fmt.Errorf("this session state is not mutable")
 	}
@@ -12,9 +12,10 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package database
+package sessioninternal
 
 import (
+	"context"
 	"fmt"
 	"iter"
 	"maps"
@@ -25,8 +26,23 @@ import (
 	"google.golang.org/adk/session"
 )
 
-// TODO localSession is identical to session.session. Move to sessioninternal
-type localSession struct {
+// StoredSessionConfig describes a [StoredSession].
+type StoredSessionConfig struct {
+	AppName   string
+	UserID    string
+	SessionID string
+	// State is the merged state of the session, its app and its user.
+	State map[string]any
+	// Events are the events of the session, in chronological order.
+	Events []*session.Event
+	// UpdatedAt is the last update time of the stored session.
+	UpdatedAt time.Time
+}
+
+// StoredSession is the session.Session returned by the services which
+// store the sessions, e.g. in a database. It holds a copy of the stored
+// session, which the services update as they append events.
+type StoredSession struct {
 	appName   string
 	userID    string
 	sessionID string
@@ -38,38 +54,87 @@ type localSession struct {
 	updatedAt time.Time
 }
 
-func (s *localSession) ID() string {
+// NewStoredSession returns the session described by cfg.
+func NewStoredSession(cfg StoredSessionConfig) *StoredSession {
+	return &StoredSession{
+		appName:   cfg.AppName,
+		userID:    cfg.UserID,
+		sessionID: cfg.SessionID,
+		events:    cfg.Events,
+		state:     cfg.State,
+		updatedAt: cfg.UpdatedAt,
+	}
+}
+
+func (s *StoredSession) ID() string {
 	return s.sessionID
 }
 
-func (s *localSession) AppName() string {
+func (s *StoredSession) AppName() string {
 	return s.appName
 }
 
-func (s *localSession) UserID() string {
+func (s *StoredSession) UserID() string {
 	return s.userID
 }
 
-func (s *localSession) State() session.State {
+func (s *StoredSession) State() session.State {
 	return &state{
 		mu:    &s.mu,
 		state: s.state,
 	}
 }
 
-func (s *localSession) Events() session.Events {
+func (s *StoredSession) Events() session.Events {
 	return events(s.events)
 }
 
-func (s *localSession) LastUpdateTime() time.Time {
+func (s *StoredSession) LastUpdateTime() time.Time {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	return s.updatedAt
 }
 
-func (s *localSession) appendEvent(event *session.Event) error {
-	if event.Partial {
+// StateMap returns the state of the session, for the services to store it.
+func (s *StoredSession) StateMap() map[string]any {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.state
+}
+
+// SetState replaces the state of the session, e.g. once it is merged with
+// the states of its app and user.
+func (s *StoredSession) SetState(state map[string]any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.state = state
+}
+
+// SetEvents replaces the events of the session.
+func (s *StoredSession) SetEvents(events []*session.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.events = events
+}
+
+// SetLastUpdateTime records the last update time of the stored session,
+// which the services compare with the stored one to detect stale sessions.
+func (s *StoredSession) SetLastUpdateTime(t time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.updatedAt = t
+}
+
+// AppendEvent applies the state delta of the event to the session and
+// appends the event, without its temporary state keys. Partial events are
+// ignored, unless the context opts in, see [session.WithPartialEvents].
+func (s *StoredSession) AppendEvent(ctx context.Context, event *session.Event) error {
+	if event.Partial && !session.SavesPartialEvents(ctx) {
 		return nil
 	}
 
@@ -77,10 +142,10 @@ func (s *localSession) appendEvent(event *session.Event) error {
 	defer s.mu.Unlock()
 
 	if err := updateSessionState(s, event); err != nil {
-		return fmt.Errorf("failed to update localSession state: %w", err)
+		return fmt.Errorf("failed to update session state: %w", err)
 	}
 
-	processedEvent := trimTempDeltaState(event)
+	processedEvent := TrimTempDeltaState(event)
 	s.events = append(s.events, processedEvent)
 	return nil
 }
@@ -150,7 +215,7 @@ func (s *state) Set(key string, value any) error {
 }
 
 // TrimTempDeltaState removes temporary state delta keys from the event.
-func trimTempDeltaState(event *session.Event) *session.Event {
+func TrimTempDeltaState(event *session.Event) *session.Event {
 	if len(event.Actions.StateDelta) == 0 {
 		return event
 	}
@@ -170,7 +235,7 @@ func trimTempDeltaState(event *session.Event) *session.Event {
 }
 
 // updateSessionState updates the session state based on the event state delta.
-func updateSessionState(sess *localSession, event *session.Event) error {
+func updateSessionState(sess *StoredSession, event *session.Event) error {
 	if event.Actions.StateDelta == nil {
 		return nil // Nothing to do
 	}
@@ -186,7 +251,7 @@ func updateSessionState(sess *localSession, event *session.Event) error {
 }
 
 var (
-	_ session.Session = (*localSession)(nil)
+	_ session.Session = (*StoredSession)(nil)
 	_ session.Events  = (*events)(nil)
 	_ session.State   = (*state)(nil)
 )
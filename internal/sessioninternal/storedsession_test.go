@@ -0,0 +1,77 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sessioninternal_test
+
+import (
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"google.golang.org/adk/internal/sessioninternal"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+)
+
+func TestStoredSession_AppendEvent(t *testing.T) {
+	updatedAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	s := sessioninternal.NewStoredSession(sessioninternal.StoredSessionConfig{
+		AppName:   "app",
+		UserID:    "user",
+		SessionID: "session",
+		State:     map[string]any{"app:version": "v1", "topic": "weather"},
+		UpdatedAt: updatedAt,
+	})
+
+	events := []*session.Event{
+		{ID: "1", Actions: session.EventActions{StateDelta: map[string]any{"topic": "news", "temp:draft": "x"}}},
+		{ID: "2", LLMResponse: model.LLMResponse{Partial: true}, Actions: session.EventActions{StateDelta: map[string]any{"topic": "partial"}}},
+		{ID: "3"},
+	}
+	for _, event := range events {
+		if err := s.AppendEvent(t.Context(), event); err != nil {
+			t.Fatalf("AppendEvent() failed: %v", err)
+		}
+	}
+
+	wantState := map[string]any{"app:version": "v1", "topic": "news", "temp:draft": "x"}
+	if diff := cmp.Diff(wantState, maps.Collect(s.State().All())); diff != "" {
+		t.Errorf("state mismatch (-want +got):\n%s", diff)
+	}
+	var gotIDs []string
+	for event := range s.Events().All() {
+		gotIDs = append(gotIDs, event.ID)
+	}
+	if diff := cmp.Diff([]string{"1", "3"}, gotIDs); diff != "" {
+		t.Errorf("event IDs mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string]any{"topic": "news"}, s.Events().At(0).Actions.StateDelta); diff != "" {
+		t.Errorf("stored state delta mismatch (-want +got):\n%s", diff)
+	}
+	if got := s.LastUpdateTime(); !got.Equal(updatedAt) {
+		t.Errorf("LastUpdateTime() = %v, want %v", got, updatedAt)
+	}
+
+	s.SetEvents(slices.Clone(events[:1]))
+	s.SetLastUpdateTime(updatedAt.Add(time.Second))
+	if got := s.Events().Len(); got != 1 {
+		t.Errorf("Events().Len() = %d, want 1", got)
+	}
+	if got := s.LastUpdateTime(); !got.Equal(updatedAt.Add(time.Second)) {
+		t.Errorf("LastUpdateTime() = %v, want %v", got, updatedAt.Add(time.Second))
+	}
+}
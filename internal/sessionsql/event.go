@@ -0,0 +1,196 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sessionsql holds the storage model shared by the session services
+// backed by SQL databases.
+package sessionsql
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+)
+
+// EventRow is an event as stored in an events table. The JSON columns are
+// nil when the field of the event is empty.
+type EventRow struct {
+	ID                 string
+	InvocationID       string
+	Author             string
+	Branch             string
+	Timestamp          time.Time
+	Content            []byte
+	Actions            []byte
+	LongRunningToolIDs []byte
+	GroundingMetadata  []byte
+	CitationMetadata   []byte
+	UsageMetadata      []byte
+	CustomMetadata     []byte
+	Partial            bool
+	TurnComplete       bool
+	Interrupted        bool
+	ErrorCode          string
+	ErrorMessage       string
+}
+
+// NewEventRow converts the event to its row.
+func NewEventRow(event *session.Event) (*EventRow, error) {
+	row := &EventRow{
+		ID:           event.ID,
+		InvocationID: event.InvocationID,
+		Author:       event.Author,
+		Branch:       event.Branch,
+		Timestamp:    event.Timestamp,
+		Partial:      event.Partial,
+		TurnComplete: event.TurnComplete,
+		Interrupted:  event.Interrupted,
+		ErrorCode:    event.ErrorCode,
+		ErrorMessage: event.ErrorMessage,
+	}
+
+	var err error
+	if row.Actions, err = json.Marshal(event.Actions); err != nil {
+		return nil, fmt.Errorf("failed to marshal event actions: %w", err)
+	}
+	if event.Content != nil {
+		if row.Content, err = json.Marshal(event.Content); err != nil {
+			return nil, fmt.Errorf("failed to marshal content: %w", err)
+		}
+	}
+	if len(event.LongRunningToolIDs) > 0 {
+		if row.LongRunningToolIDs, err = json.Marshal(event.LongRunningToolIDs); err != nil {
+			return nil, fmt.Errorf("failed to marshal long running tool IDs: %w", err)
+		}
+	}
+	if event.GroundingMetadata != nil {
+		if row.GroundingMetadata, err = json.Marshal(event.GroundingMetadata); err != nil {
+			return nil, fmt.Errorf("failed to marshal grounding metadata: %w", err)
+		}
+	}
+	if event.CitationMetadata != nil {
+		if row.CitationMetadata, err = json.Marshal(event.CitationMetadata); err != nil {
+			return nil, fmt.Errorf("failed to marshal citation metadata: %w", err)
+		}
+	}
+	if event.UsageMetadata != nil {
+		if row.UsageMetadata, err = json.Marshal(event.UsageMetadata); err != nil {
+			return nil, fmt.Errorf("failed to marshal usage metadata: %w", err)
+		}
+	}
+	if len(event.CustomMetadata) > 0 {
+		if row.CustomMetadata, err = json.Marshal(event.CustomMetadata); err != nil {
+			return nil, fmt.Errorf("failed to marshal custom metadata: %w", err)
+		}
+	}
+	return row, nil
+}
+
+// Event converts the row back to an event.
+func (r *EventRow) Event() (*session.Event, error) {
+	event := &session.Event{
+		ID:           r.ID,
+		InvocationID: r.InvocationID,
+		Author:       r.Author,
+		Branch:       r.Branch,
+		Timestamp:    r.Timestamp,
+		LLMResponse: model.LLMResponse{
+			Partial:      r.Partial,
+			TurnComplete: r.TurnComplete,
+			Interrupted:  r.Interrupted,
+			ErrorCode:    r.ErrorCode,
+			ErrorMessage: r.ErrorMessage,
+		},
+	}
+
+	if err := unmarshalColumn(r.Actions, &event.Actions, "actions"); err != nil {
+		return nil, err
+	}
+	var content *genai.Content
+	if err := unmarshalColumn(r.Content, &content, "content"); err != nil {
+		return nil, err
+	}
+	event.Content = content
+	if err := unmarshalColumn(r.LongRunningToolIDs, &event.LongRunningToolIDs, "long running tool IDs"); err != nil {
+		return nil, err
+	}
+	if err := unmarshalColumn(r.GroundingMetadata, &event.GroundingMetadata, "grounding metadata"); err != nil {
+		return nil, err
+	}
+	if err := unmarshalColumn(r.CitationMetadata, &event.CitationMetadata, "citation metadata"); err != nil {
+		return nil, err
+	}
+	if err := unmarshalColumn(r.UsageMetadata, &event.UsageMetadata, "usage metadata"); err != nil {
+		return nil, err
+	}
+	if err := unmarshalColumn(r.CustomMetadata, &event.CustomMetadata, "custom metadata"); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// unmarshalColumn decodes a JSON column, leaving v untouched if the column
+// is NULL.
+func unmarshalColumn(data []byte, v any, what string) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to unmarshal %s: %w", what, err)
+	}
+	return nil
+}
+
+// MarshalState encodes a state for a JSON column.
+func MarshalState(state map[string]any) ([]byte, error) {
+	if state == nil {
+		state = map[string]any{}
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal state: %w", err)
+	}
+	return data, nil
+}
+
+// UnmarshalState decodes a state from a JSON column.
+func UnmarshalState(data []byte) (map[string]any, error) {
+	state := make(map[string]any)
+	if err := unmarshalColumn(data, &state, "state"); err != nil {
+		return nil, err
+	}
+	if state == nil {
+		// The column holds the JSON null.
+		state = make(map[string]any)
+	}
+	return state, nil
+}
+
+// Placeholders returns the placeholders of n query parameters, "$1, $2, ...".
+func Placeholders(n int) string {
+	var sb strings.Builder
+	for i := 1; i <= n; i++ {
+		if i > 1 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString("$" + strconv.Itoa(i))
+	}
+	return sb.String()
+}
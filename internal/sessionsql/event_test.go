@@ -0,0 +1,87 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sessionsql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+)
+
+func TestEventRow_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		event *session.Event
+	}{
+		{
+			name: "minimal",
+			event: &session.Event{
+				ID:           "e1",
+				InvocationID: "inv1",
+				Author:       "user",
+				Timestamp:    time.Date(2025, 1, 2, 3, 4, 5, 6000, time.UTC),
+			},
+		},
+		{
+			name: "full",
+			event: &session.Event{
+				ID:                 "e2",
+				InvocationID:       "inv1",
+				Author:             "agent",
+				Branch:             "root.agent",
+				Timestamp:          time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+				LongRunningToolIDs: []string{"call1"},
+				Actions: session.EventActions{
+					StateDelta: map[string]any{"k": "v", "user:n": float64(1)},
+				},
+				LLMResponse: model.LLMResponse{
+					Content:        genai.NewContentFromText("hello", genai.RoleModel),
+					CustomMetadata: map[string]any{"source": "test"},
+					UsageMetadata:  &genai.GenerateContentResponseUsageMetadata{TotalTokenCount: 42},
+					TurnComplete:   true,
+					Interrupted:    true,
+					ErrorCode:      "CODE",
+					ErrorMessage:   "message",
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			row, err := NewEventRow(tt.event)
+			if err != nil {
+				t.Fatalf("NewEventRow() error = %v", err)
+			}
+			got, err := row.Event()
+			if err != nil {
+				t.Fatalf("event() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.event, got); diff != "" {
+				t.Errorf("event round trip mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPlaceholders(t *testing.T) {
+	if got, want := Placeholders(3), "$1, $2, $3"; got != want {
+		t.Errorf("Placeholders(3) = %q, want %q", got, want)
+	}
+}
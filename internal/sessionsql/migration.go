@@ -0,0 +1,117 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sessionsql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Migration is a schema change, applied in the order of its version.
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// LoadMigrations reads the migrations of the directory. Their file names
+// start with their version, e.g. "0001_create_tables.sql".
+func LoadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+	var migrations []Migration
+	seen := make(map[int]string)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || path.Ext(name) != ".sql" {
+			continue
+		}
+		prefix, _, _ := strings.Cut(name, "_")
+		version, err := strconv.Atoi(prefix)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %q doesn't start with a positive version", name)
+		}
+		if other, ok := seen[version]; ok {
+			return nil, fmt.Errorf("migrations %q and %q have the same version", other, name)
+		}
+		seen[version] = name
+		content, err := fs.ReadFile(fsys, path.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %q: %w", name, err)
+		}
+		migrations = append(migrations, Migration{Version: version, Name: name, SQL: string(content)})
+	}
+	// fs.ReadDir sorts the entries by name, but the versions may not be padded.
+	slices.SortFunc(migrations, func(a, b Migration) int { return a.Version - b.Version })
+	return migrations, nil
+}
+
+// ApplyMigrations applies the migrations which weren't applied yet in the
+// transaction, and records them in the schema_migrations table. The caller
+// must make sure that concurrent calls are serialized.
+func ApplyMigrations(ctx context.Context, tx *sql.Tx, migrations []Migration) error {
+	return ApplyTableMigrations(ctx, tx, "schema_migrations", migrations)
+}
+
+// ApplyTableMigrations is like [ApplyMigrations], but records the migrations
+// in the given table, so that the schemas of several stores can share a
+// database.
+func ApplyTableMigrations(ctx context.Context, tx *sql.Tx, table string, migrations []Migration) error {
+	if _, err := tx.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+table+` (
+	version INTEGER NOT NULL PRIMARY KEY,
+	name    TEXT    NOT NULL
+)`); err != nil {
+		return fmt.Errorf("failed to create %s table: %w", table, err)
+	}
+
+	applied := make(map[int]bool)
+	rows, err := tx.QueryContext(ctx, "SELECT version FROM "+table)
+	if err != nil {
+		return fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to read applied migrations: %w", err)
+		}
+		applied[version] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+
+	for _, m := range migrations {
+		if applied[m.Version] {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, m.SQL); err != nil {
+			return fmt.Errorf("failed to apply migration %q: %w", m.Name, err)
+		}
+		if _, err := tx.ExecContext(ctx, "INSERT INTO "+table+" (version, name) VALUES ($1, $2)", m.Version, m.Name); err != nil {
+			return fmt.Errorf("failed to record migration %q: %w", m.Name, err)
+		}
+	}
+	return nil
+}
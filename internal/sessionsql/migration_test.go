@@ -0,0 +1,72 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sessionsql
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	tests := []struct {
+		name    string
+		fsys    fstest.MapFS
+		want    []int
+		wantErr bool
+	}{
+		{
+			name: "sorted by version",
+			fsys: fstest.MapFS{
+				"migrations/10_indexes.sql": {Data: []byte("CREATE INDEX ...")},
+				"migrations/2_columns.sql":  {Data: []byte("ALTER TABLE ...")},
+				"migrations/1_tables.sql":   {Data: []byte("CREATE TABLE ...")},
+				"migrations/README.md":      {Data: []byte("not a migration")},
+			},
+			want: []int{1, 2, 10},
+		},
+		{
+			name: "missing version",
+			fsys: fstest.MapFS{
+				"migrations/tables.sql": {Data: []byte("CREATE TABLE ...")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicate version",
+			fsys: fstest.MapFS{
+				"migrations/1_tables.sql":  {Data: []byte("CREATE TABLE ...")},
+				"migrations/01_tables.sql": {Data: []byte("CREATE TABLE ...")},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := LoadMigrations(tt.fsys, "migrations")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadMigrations() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var got []int
+			for _, m := range migrations {
+				got = append(got, m.Version)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("LoadMigrations() versions mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sessionsql
+
+import (
+	"strconv"
+	"strings"
+)
+
+// likeEscaper escapes the wildcards of the LIKE patterns, and the escape
+// character itself.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// LikeConditions returns the conditions that expr contains each keyword,
+// "expr LIKE $n ESCAPE '\'" joined by AND, whose parameters are numbered
+// from first, and their arguments. like is the operator, e.g. ILIKE in
+// PostgreSQL.
+func LikeConditions(expr, like string, keywords []string, first int) (string, []any) {
+	conds := make([]string, len(keywords))
+	args := make([]any, len(keywords))
+	for i, keyword := range keywords {
+		conds[i] = expr + " " + like + " $" + strconv.Itoa(first+i) + ` ESCAPE '\'`
+		args[i] = "%" + likeEscaper.Replace(keyword) + "%"
+	}
+	return strings.Join(conds, " AND "), args
+}
@@ -0,0 +1,31 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sessionsql
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLikeConditions(t *testing.T) {
+	gotConds, gotArgs := LikeConditions("text", "LIKE", []string{"paris", `50%_off\`}, 3)
+	if want := `text LIKE $3 ESCAPE '\' AND text LIKE $4 ESCAPE '\'`; gotConds != want {
+		t.Errorf("LikeConditions() conditions = %q, want %q", gotConds, want)
+	}
+	if diff := cmp.Diff([]any{"%paris%", `%50\%\_off\\%`}, gotArgs); diff != "" {
+		t.Errorf("LikeConditions() args mismatch (-want +got):\n%s", diff)
+	}
+}
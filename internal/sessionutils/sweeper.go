@@ -0,0 +1,40 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sessionutils
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// StartSweeper calls sweep every interval in a new goroutine, until ctx is
+// done. The errors of sweep are logged.
+func StartSweeper(ctx context.Context, interval time.Duration, sweep func(context.Context) error) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if err := sweep(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("failed to sweep the expired sessions: %v", err)
+			}
+		}
+	}()
+}
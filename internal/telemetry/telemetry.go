@@ -22,6 +22,7 @@ import (
 	"context"
 	"encoding/json"
 	"sync"
+	"time"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
@@ -79,6 +80,10 @@ const (
 	gcpVertexAgentLLMResponseName  = "gcp.vertex.agent.llm_response"
 	gcpVertexAgentInvocationID     = "gcp.vertex.agent.invocation_id"
 	gcpVertexAgentSessionID        = "gcp.vertex.agent.session_id"
+	gcpVertexAgentLLMRetryCount    = "gcp.vertex.agent.llm_retry_count"
+	gcpVertexAgentLLMCacheHit      = "gcp.vertex.agent.llm_cache_hit"
+
+	gcpVertexAgentToolCallDurationMs = "gcp.vertex.agent.tool_call_duration_ms"
 
 	executeToolName = "execute_tool"
 	mergeToolName   = "(merged tools)"
@@ -110,9 +115,8 @@ func RegisterTelemetry() {
 // That means that the spans are NOT recording/exporting
 // If the local tracer is not set, we'll set up tracer with all registered span processors.
 func getTracers() []trace.Tracer {
-	if localTracer.tp == nil {
-		RegisterTelemetry()
-	}
+	// The tool calls start their traces concurrently.
+	RegisterTelemetry()
 	return []trace.Tracer{
 		localTracer.tp.Tracer(systemName),
 		otel.GetTracerProvider().Tracer(systemName),
@@ -153,8 +157,9 @@ func TraceMergedToolCalls(spans []trace.Span, fnResponseEvent *session.Event) {
 	}
 }
 
-// TraceToolCall traces the tool execution events.
-func TraceToolCall(spans []trace.Span, tool tool.Tool, fnArgs map[string]any, fnResponseEvent *session.Event) {
+// TraceToolCall traces the tool execution events, and the duration of the
+// call.
+func TraceToolCall(spans []trace.Span, tool tool.Tool, fnArgs map[string]any, fnResponseEvent *session.Event, duration time.Duration) {
 	if fnResponseEvent == nil {
 		return
 	}
@@ -171,6 +176,7 @@ func TraceToolCall(spans []trace.Span, tool tool.Tool, fnArgs map[string]any, fn
 			attribute.String(gcpVertexAgentLLMRequestName, "{}"),
 			attribute.String(gcpVertexAgentToolCallArgsName, safeSerialize(fnArgs)),
 			attribute.String(gcpVertexAgentEventID, fnResponseEvent.ID),
+			attribute.Int64(gcpVertexAgentToolCallDurationMs, duration.Milliseconds()),
 		}
 
 		toolCallID := "<not specified>"
@@ -225,6 +231,12 @@ func TraceLLMCall(spans []trace.Span, agentCtx agent.InvocationContext, llmReque
 		if event.FinishReason != "" {
 			attributes = append(attributes, attribute.String(genAiResponseFinishReason, string(event.FinishReason)))
 		}
+		if retries, ok := event.CustomMetadata[model.RetriesMetadataKey].(int); ok {
+			attributes = append(attributes, attribute.Int(gcpVertexAgentLLMRetryCount, retries))
+		}
+		if hit, ok := event.CustomMetadata[model.CacheHitMetadataKey].(bool); ok {
+			attributes = append(attributes, attribute.Bool(gcpVertexAgentLLMCacheHit, hit))
+		}
 		if event.UsageMetadata != nil {
 			if event.UsageMetadata.PromptTokenCount > 0 {
 				attributes = append(attributes, attribute.Int(genAiResponsePromptTokenCount, int(event.UsageMetadata.PromptTokenCount)))
@@ -0,0 +1,171 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package toolinternal
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/tool"
+)
+
+// ToolCache caches the results of the cacheable tools, see
+// [tool.CacheableTool].
+//
+// The keys of the results hash the scope of the calls, the name of the
+// tool, the canonical JSON of the arguments, and the generations of the
+// scope and of the tool. The generations are random tokens stored in the
+// cache, which clearing the cache replaces, so that the results cached
+// before aren't found anymore, whatever the store of the cache.
+type ToolCache struct {
+	Cache tool.ResultCache
+	Scope tool.CacheScope
+}
+
+type toolCacheKey struct{}
+
+// WithToolCache returns a context whose tool calls use the cache.
+func WithToolCache(ctx context.Context, c *ToolCache) context.Context {
+	return context.WithValue(ctx, toolCacheKey{}, c)
+}
+
+// ToolCacheFromContext returns the tool cache of the context, or nil.
+func ToolCacheFromContext(ctx context.Context) *ToolCache {
+	c, _ := ctx.Value(toolCacheKey{}).(*ToolCache)
+	return c
+}
+
+// Get returns the cached result of the call of the tool with the args, and
+// whether it was found. Cache failures are logged, and reported as misses.
+func (c *ToolCache) Get(ctx agent.ReadonlyContext, toolName string, args map[string]any) (map[string]any, bool) {
+	key, err := c.resultKey(ctx, toolName, args)
+	if err != nil {
+		log.Printf("Failed to compute the cache key of tool %s: %v", toolName, err)
+		return nil, false
+	}
+	data, ok, err := c.Cache.Get(ctx, key)
+	if err != nil {
+		log.Printf("Failed to get the result %s of tool %s from the cache: %v", key, toolName, err)
+		return nil, false
+	}
+	if !ok {
+		return nil, false
+	}
+	var result map[string]any
+	if err := json.Unmarshal(data, &result); err != nil {
+		log.Printf("Failed to decode the result %s of tool %s of the cache: %v", key, toolName, err)
+		return nil, false
+	}
+	return result, true
+}
+
+// Set caches the result of the call of the tool with the args for ttl.
+// Cache failures are logged.
+func (c *ToolCache) Set(ctx agent.ReadonlyContext, toolName string, args, result map[string]any, ttl time.Duration) {
+	key, err := c.resultKey(ctx, toolName, args)
+	if err != nil {
+		log.Printf("Failed to compute the cache key of tool %s: %v", toolName, err)
+		return
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		log.Printf("Failed to encode the result %s of tool %s: %v", key, toolName, err)
+		return
+	}
+	if err := c.Cache.Set(ctx, key, data, ttl); err != nil {
+		log.Printf("Failed to store the result %s of tool %s in the cache: %v", key, toolName, err)
+	}
+}
+
+// Clear clears the cached results of the named tools, or of all the tools,
+// in the scope of the call of tc.
+func (c *ToolCache) Clear(ctx context.Context, tc agent.ReadonlyContext, toolNames ...string) error {
+	scope := c.scope(tc)
+	keys := []string{generationKey(scope, "")}
+	if len(toolNames) > 0 {
+		keys = keys[:0]
+		for _, name := range toolNames {
+			keys = append(keys, generationKey(scope, name))
+		}
+	}
+	for _, key := range keys {
+		if err := c.Cache.Set(ctx, key, []byte(uuid.NewString()), 0); err != nil {
+			return fmt.Errorf("failed to clear the tool cache: %w", err)
+		}
+	}
+	return nil
+}
+
+func (c *ToolCache) scope(ctx agent.ReadonlyContext) []string {
+	if c.Scope == tool.CacheScopeApp {
+		return []string{string(tool.CacheScopeApp), ctx.AppName()}
+	}
+	return []string{string(tool.CacheScopeSession), ctx.AppName(), ctx.UserID(), ctx.SessionID()}
+}
+
+func (c *ToolCache) resultKey(ctx agent.ReadonlyContext, toolName string, args map[string]any) (string, error) {
+	scope := c.scope(ctx)
+	scopeGeneration, err := c.generation(ctx, generationKey(scope, ""))
+	if err != nil {
+		return "", err
+	}
+	toolGeneration, err := c.generation(ctx, generationKey(scope, toolName))
+	if err != nil {
+		return "", err
+	}
+	// The keys of the maps are sorted by json.Marshal, which makes the
+	// encoding of the arguments canonical.
+	return hash(scope, toolName, scopeGeneration, toolGeneration, args)
+}
+
+// generation returns the generation stored with the key, storing a new one
+// if there is none, so that the results cached before a generation is
+// dropped by the cache aren't found either.
+func (c *ToolCache) generation(ctx context.Context, key string) (string, error) {
+	data, ok, err := c.Cache.Get(ctx, key)
+	if err != nil {
+		return "", fmt.Errorf("failed to get the cache generation: %w", err)
+	}
+	if ok {
+		return string(data), nil
+	}
+	generation := uuid.NewString()
+	if err := c.Cache.Set(ctx, key, []byte(generation), 0); err != nil {
+		return "", fmt.Errorf("failed to store the cache generation: %w", err)
+	}
+	return generation, nil
+}
+
+func generationKey(scope []string, toolName string) string {
+	key, _ := hash("generation", scope, toolName)
+	return "generation-" + key
+}
+
+func hash(values ...any) (string, error) {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cache key: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
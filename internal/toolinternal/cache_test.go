@@ -0,0 +1,120 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package toolinternal
+
+import (
+	"testing"
+	"time"
+
+	contextinternal "google.golang.org/adk/internal/context"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+)
+
+func TestToolCache(t *testing.T) {
+	service := session.InMemoryService()
+	toolContext := func(t *testing.T, appName, userID string) tool.Context {
+		t.Helper()
+		resp, err := service.Create(t.Context(), &session.CreateRequest{AppName: appName, UserID: userID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		inv := contextinternal.NewInvocationContext(t.Context(), contextinternal.InvocationContextParams{Session: resp.Session})
+		return NewToolContext(inv, "", nil, nil)
+	}
+	args := map[string]any{"q": "a", "opts": map[string]any{"x": 1, "y": 2}}
+	result := map[string]any{"result": "a"}
+
+	tests := []struct {
+		name    string
+		scope   tool.CacheScope
+		other   func(t *testing.T) tool.Context
+		wantHit bool
+	}{
+		{
+			name:    "same session",
+			wantHit: true,
+		},
+		{
+			name:  "other session",
+			other: func(t *testing.T) tool.Context { return toolContext(t, "app", "user") },
+		},
+		{
+			name:    "other user of the app",
+			scope:   tool.CacheScopeApp,
+			other:   func(t *testing.T) tool.Context { return toolContext(t, "app", "other") },
+			wantHit: true,
+		},
+		{
+			name:  "other app",
+			scope: tool.CacheScopeApp,
+			other: func(t *testing.T) tool.Context { return toolContext(t, "other", "user") },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &ToolCache{Cache: tool.NewLRUResultCache(1 << 20), Scope: tt.scope}
+			ctx := toolContext(t, "app", "user")
+			c.Set(ctx, "lookup", args, result, time.Hour)
+			other := ctx
+			if tt.other != nil {
+				other = tt.other(t)
+			}
+			_, hit := c.Get(other, "lookup", map[string]any{"opts": map[string]any{"y": 2, "x": 1}, "q": "a"})
+			if hit != tt.wantHit {
+				t.Errorf("Get() hit = %v, want %v", hit, tt.wantHit)
+			}
+			if _, hit := c.Get(other, "other_tool", args); hit {
+				t.Errorf("Get() of another tool hit, want a miss")
+			}
+		})
+	}
+}
+
+func TestToolCache_Clear(t *testing.T) {
+	resp, err := session.InMemoryService().Create(t.Context(), &session.CreateRequest{AppName: "app", UserID: "user"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &ToolCache{Cache: tool.NewLRUResultCache(1 << 20)}
+	inv := contextinternal.NewInvocationContext(WithToolCache(t.Context(), c), contextinternal.InvocationContextParams{Session: resp.Session})
+	ctx := NewToolContext(inv, "", nil, nil)
+	set := func() {
+		for _, name := range []string{"a", "b"} {
+			c.Set(ctx, name, nil, map[string]any{"tool": name}, 0)
+		}
+	}
+	hits := func() []bool {
+		_, a := c.Get(ctx, "a", nil)
+		_, b := c.Get(ctx, "b", nil)
+		return []bool{a, b}
+	}
+
+	set()
+	if err := ctx.ClearToolCache(t.Context(), "a"); err != nil {
+		t.Fatalf("ClearToolCache(a) failed: %v", err)
+	}
+	if got := hits(); got[0] || !got[1] {
+		t.Errorf("hits after ClearToolCache(a) = %v, want [false true]", got)
+	}
+
+	set()
+	if err := ctx.ClearToolCache(t.Context()); err != nil {
+		t.Fatalf("ClearToolCache() failed: %v", err)
+	}
+	if got := hits(); got[0] || got[1] {
+		t.Errorf("hits after ClearToolCache() = %v, want [false false]", got)
+	}
+}
@@ -16,7 +16,11 @@ package toolinternal
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
+	"slices"
+	"time"
 
 	"github.com/google/uuid"
 	"google.golang.org/genai"
@@ -50,6 +54,30 @@ func (ia *internalArtifacts) Save(ctx context.Context, name string, data *genai.
 	return resp, nil
 }
 
+// LoadStream implements [agent.ArtifactStreamer].
+func (ia *internalArtifacts) LoadStream(ctx context.Context, name string) (*artifact.LoadStreamResponse, error) {
+	if streamer, ok := ia.Artifacts.(agent.ArtifactStreamer); ok {
+		return streamer.LoadStream(ctx, name)
+	}
+	return nil, fmt.Errorf("artifacts of %T can't be streamed: %w", ia.Artifacts, errors.ErrUnsupported)
+}
+
+// Versions implements [agent.ArtifactVersioner].
+func (ia *internalArtifacts) Versions(ctx context.Context, name string) (*artifact.VersionsResponse, error) {
+	if versioner, ok := ia.Artifacts.(agent.ArtifactVersioner); ok {
+		return versioner.Versions(ctx, name)
+	}
+	return nil, fmt.Errorf("versions of the artifacts of %T can't be listed: %w", ia.Artifacts, errors.ErrUnsupported)
+}
+
+// SignURL implements [agent.ArtifactURLSigner].
+func (ia *internalArtifacts) SignURL(ctx context.Context, name string, ttl time.Duration) (*artifact.SignURLResponse, error) {
+	if signer, ok := ia.Artifacts.(agent.ArtifactURLSigner); ok {
+		return signer.SignURL(ctx, name, ttl)
+	}
+	return nil, fmt.Errorf("artifacts of %T can't be signed: %w", ia.Artifacts, errors.ErrUnsupported)
+}
+
 func NewToolContext(ctx agent.InvocationContext, functionCallID string, actions *session.EventActions, confirmation *toolconfirmation.ToolConfirmation) tool.Context {
 	if functionCallID == "" {
 		functionCallID = uuid.NewString()
@@ -72,9 +100,155 @@ func NewToolContext(ctx agent.InvocationContext, functionCallID string, actions
 			eventActions: actions,
 		},
 		toolConfirmation: confirmation,
+		grounding:        new(*genai.GroundingMetadata),
+		responseParts:    new([]*genai.FunctionResponsePart),
+	}
+}
+
+type eventForwarderKey struct{}
+
+// WithEventForwarder returns a context whose tools forward their events to
+// forward, see [ForwardEvent].
+func WithEventForwarder(ctx context.Context, forward func(*session.Event)) context.Context {
+	return context.WithValue(ctx, eventForwarderKey{}, forward)
+}
+
+// ForwardsEvents reports whether the tools of the context can forward their
+// events, see [ForwardEvent].
+func ForwardsEvents(ctx context.Context) bool {
+	_, ok := ctx.Value(eventForwarderKey{}).(func(*session.Event))
+	return ok
+}
+
+// ForwardedEventMetadataKey is the custom metadata key of the events
+// forwarded by the tools, whose value is the ID of the function call which
+// forwarded them. The forwarded events are streamed, but not saved in the
+// session.
+const ForwardedEventMetadataKey = "adk_forwarded_by"
+
+// ForwardEvent forwards a copy of an event of a running tool, e.g. an event
+// of the sub-agent of an agent tool, to the event stream of the invocation
+// calling the tool, with the [ForwardedEventMetadataKey]. It reports whether
+// the event was forwarded.
+//
+// The forwarded events have no actions: the escalations, transfers and state
+// deltas of the events of a sub-agent belong to its own invocation, and must
+// not act on the agents of the calling one.
+func ForwardEvent(ctx tool.Context, ev *session.Event) bool {
+	forward, ok := ctx.Value(eventForwarderKey{}).(func(*session.Event))
+	if !ok {
+		return false
+	}
+	fwd := *ev
+	fwd.Actions = session.EventActions{}
+	fwd.CustomMetadata = maps.Clone(ev.CustomMetadata)
+	if fwd.CustomMetadata == nil {
+		fwd.CustomMetadata = make(map[string]any)
+	}
+	fwd.CustomMetadata[ForwardedEventMetadataKey] = ctx.FunctionCallID()
+	forward(&fwd)
+	return true
+}
+
+// IsForwarded reports whether the event was forwarded by a tool, see
+// [ForwardEvent].
+func IsForwarded(ev *session.Event) bool {
+	_, ok := ev.CustomMetadata[ForwardedEventMetadataKey]
+	return ok
+}
+
+// WithAttempt returns a copy of the tool context for an attempt of its call,
+// whose deadline and cancellation are those of ctx, e.g. for the timeouts of
+// the tool calls, and the function committing the attempt.
+//
+// The copy has its own actions, grounding metadata and response parts, which
+// start as those of the tool context: commit sets them in the tool context
+// once the attempt has finished. The abandoned attempts, e.g. those which
+// timed out, aren't committed, so that they can't change the tool context,
+// nor race with its readers, after their call has ended.
+func WithAttempt(tc tool.Context, ctx context.Context) (attempt tool.Context, commit func()) {
+	c, ok := tc.(*toolContext)
+	if !ok {
+		return tc, func() {}
+	}
+	actions := *c.eventActions
+	actions.StateDelta = maps.Clone(c.eventActions.StateDelta)
+	actions.ArtifactDelta = maps.Clone(c.eventActions.ArtifactDelta)
+	actions.RequestedToolConfirmations = maps.Clone(c.eventActions.RequestedToolConfirmations)
+	grounding := *c.grounding
+	responseParts := slices.Clone(*c.responseParts)
+
+	newCtx := *c
+	newCtx.invocationContext = c.invocationContext.WithContext(ctx)
+	newCtx.CallbackContext = contextinternal.NewCallbackContextWithDelta(newCtx.invocationContext, actions.StateDelta)
+	newCtx.eventActions = &actions
+	newCtx.artifacts = &internalArtifacts{Artifacts: c.artifacts.Artifacts, eventActions: &actions}
+	newCtx.grounding = &grounding
+	newCtx.responseParts = &responseParts
+	return &newCtx, func() {
+		// The state delta is shared with the callback context of the tool
+		// context, its entries are replaced in place.
+		stateDelta := c.eventActions.StateDelta
+		clear(stateDelta)
+		maps.Copy(stateDelta, actions.StateDelta)
+		*c.eventActions = actions
+		c.eventActions.StateDelta = stateDelta
+		*c.grounding = grounding
+		*c.responseParts = responseParts
+	}
+}
+
+// MarkCachedResult records that the result of the call of the tool context
+// was served from the tool cache.
+func MarkCachedResult(tc tool.Context) {
+	if c, ok := tc.(*toolContext); ok {
+		c.cachedResult = true
+	}
+}
+
+// IsCachedResult reports whether the result of the call of the tool context
+// was served from the tool cache, see [MarkCachedResult].
+func IsCachedResult(tc tool.Context) bool {
+	c, ok := tc.(*toolContext)
+	return ok && c.cachedResult
+}
+
+// SetGroundingMetadata sets the grounding metadata of the function response
+// event of the call of the tool context, e.g. the sources of the chunks
+// retrieved by the tool, for UIs to render citations.
+func SetGroundingMetadata(tc tool.Context, md *genai.GroundingMetadata) {
+	if c, ok := tc.(*toolContext); ok {
+		*c.grounding = md
 	}
 }
 
+// GroundingMetadata returns the grounding metadata of the call of the tool
+// context, see [SetGroundingMetadata].
+func GroundingMetadata(tc tool.Context) *genai.GroundingMetadata {
+	if c, ok := tc.(*toolContext); ok {
+		return *c.grounding
+	}
+	return nil
+}
+
+// AddResponseParts adds parts to the function response of the call of the
+// tool context, e.g. the screenshots taken by the tool, for multimodal models
+// to see them along with the result.
+func AddResponseParts(tc tool.Context, parts ...*genai.FunctionResponsePart) {
+	if c, ok := tc.(*toolContext); ok {
+		*c.responseParts = append(*c.responseParts, parts...)
+	}
+}
+
+// ResponseParts returns the parts of the function response of the call of the
+// tool context, see [AddResponseParts].
+func ResponseParts(tc tool.Context) []*genai.FunctionResponsePart {
+	if c, ok := tc.(*toolContext); ok {
+		return *c.responseParts
+	}
+	return nil
+}
+
 type toolContext struct {
 	agent.CallbackContext
 	invocationContext agent.InvocationContext
@@ -82,6 +256,15 @@ type toolContext struct {
 	eventActions      *session.EventActions
 	artifacts         *internalArtifacts
 	toolConfirmation  *toolconfirmation.ToolConfirmation
+	// cachedResult is whether the result of the call was served from the
+	// tool cache, see [MarkCachedResult].
+	cachedResult bool
+	// grounding is the grounding metadata of the call, see
+	// [SetGroundingMetadata].
+	grounding **genai.GroundingMetadata
+	// responseParts are the parts of the function response of the call,
+	// see [AddResponseParts].
+	responseParts *[]*genai.FunctionResponsePart
 }
 
 func (c *toolContext) Artifacts() agent.Artifacts {
@@ -102,11 +285,31 @@ func (c *toolContext) AgentName() string {
 
 func (c *toolContext) SearchMemory(ctx context.Context, query string) (*memory.SearchResponse, error) {
 	if c.invocationContext.Memory() == nil {
-		return nil, fmt.Errorf("memory service is not set")
+		return nil, tool.ErrMemoryNotSet
 	}
 	return c.invocationContext.Memory().Search(ctx, query)
 }
 
+func (c *toolContext) ForgetMemory(ctx context.Context, req *memory.ForgetRequest) (*memory.ForgetResponse, error) {
+	mem := c.invocationContext.Memory()
+	if mem == nil {
+		return nil, tool.ErrMemoryNotSet
+	}
+	f, ok := mem.(memory.Forgetter)
+	if !ok {
+		return nil, memory.ErrForgetUnsupported
+	}
+	return f.Forget(ctx, req)
+}
+
+func (c *toolContext) ClearToolCache(ctx context.Context, toolNames ...string) error {
+	cache := ToolCacheFromContext(c)
+	if cache == nil {
+		return nil
+	}
+	return cache.Clear(ctx, c, toolNames...)
+}
+
 func (c *toolContext) ToolConfirmation() *toolconfirmation.ToolConfirmation {
 	return c.toolConfirmation
 }
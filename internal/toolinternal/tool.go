@@ -31,3 +31,10 @@ type FunctionTool interface {
 type RequestProcessor interface {
 	ProcessRequest(ctx tool.Context, req *model.LLMRequest) error
 }
+
+// PendingTool is implemented by the long-running tools whose calls end the
+// invocation: their responses are pending until the results of the calls
+// are submitted, as function responses of the user.
+type PendingTool interface {
+	PausesInvocation() bool
+}
@@ -352,3 +352,76 @@ func TestValidateOutputSchema(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateOutput(t *testing.T) {
+	object := &genai.Schema{
+		Type: genai.TypeObject,
+		Properties: map[string]*genai.Schema{
+			"result": {Type: genai.TypeString},
+		},
+		Required: []string{"result"},
+	}
+	array := &genai.Schema{Type: genai.TypeArray, Items: &genai.Schema{Type: genai.TypeInteger}}
+
+	tests := []struct {
+		name       string
+		output     string
+		schema     *genai.Schema
+		wantOutput any
+		wantErr    bool
+	}{
+		{
+			name:       "valid object",
+			output:     `{"result": "success"}`,
+			schema:     object,
+			wantOutput: map[string]any{"result": "success"},
+		},
+		{
+			name:       "valid array",
+			output:     `[1, 2]`,
+			schema:     array,
+			wantOutput: []any{1.0, 2.0},
+		},
+		{
+			name:    "array item mismatch",
+			output:  `[1, 2.5]`,
+			schema:  array,
+			wantErr: true,
+		},
+		{
+			name:    "object instead of array",
+			output:  `{"result": "success"}`,
+			schema:  array,
+			wantErr: true,
+		},
+		{
+			name:    "missing required key",
+			output:  `{}`,
+			schema:  object,
+			wantErr: true,
+		},
+		{
+			name:    "invalid json",
+			output:  "```json\n{\"result\": \"success\"}\n```",
+			schema:  object,
+			wantErr: true,
+		},
+		{
+			name:    "nil schema",
+			output:  `{"result": "success"}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotOutput, err := ValidateOutput(tt.output, tt.schema)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateOutput() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(gotOutput, tt.wantOutput) {
+				t.Errorf("ValidateOutput() = %v, want %v", gotOutput, tt.wantOutput)
+			}
+		})
+	}
+}
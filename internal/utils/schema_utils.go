@@ -136,3 +136,23 @@ func ValidateOutputSchema(output string, schema *genai.Schema) (map[string]any,
 	}
 	return outputMap, nil
 }
+
+// ValidateOutput validates an output JSON string against a schema of any
+// type, and returns the parsed value.
+func ValidateOutput(output string, schema *genai.Schema) (any, error) {
+	if schema == nil {
+		return nil, fmt.Errorf("schema cannot be nil")
+	}
+	var value any
+	if err := json.Unmarshal([]byte(output), &value); err != nil {
+		return nil, fmt.Errorf("failed to parse output JSON: %w", err)
+	}
+	ok, err := matchType(value, schema, false)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("output type mismatch, expected schema type %s, got value %v of type %T", schema.Type, value, value)
+	}
+	return value, nil
+}
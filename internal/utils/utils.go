@@ -143,3 +143,23 @@ func AppendInstructions(r *model.LLMRequest, instructions ...string) {
 		r.Config.SystemInstruction.Parts = append(r.Config.SystemInstruction.Parts, genai.NewPartFromText(inst))
 	}
 }
+
+// PrependInstructions inserts the instructions before the system instruction
+// of the request.
+func PrependInstructions(r *model.LLMRequest, instructions ...string) {
+	if len(instructions) == 0 {
+		return
+	}
+
+	inst := strings.Join(instructions, "\n\n")
+
+	if r.Config == nil {
+		r.Config = &genai.GenerateContentConfig{}
+	}
+
+	if r.Config.SystemInstruction == nil {
+		r.Config.SystemInstruction = genai.NewContentFromText(inst, genai.RoleUser)
+	} else {
+		r.Config.SystemInstruction.Parts = append([]*genai.Part{genai.NewPartFromText(inst)}, r.Config.SystemInstruction.Parts...)
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package version
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+)
+
+// modulePath is the path of the ADK Go module.
+const modulePath = "google.golang.org/adk"
+
+// BuildInfo describes the binary which is running.
+type BuildInfo struct {
+	// ModuleVersion is the version of the ADK module the binary was built
+	// with, e.g. "v0.3.0" or a pseudo-version. It is "(devel)" when the ADK
+	// module is the main module, and empty if it is unknown.
+	ModuleVersion string
+	// Revision is the VCS revision of the main module.
+	// Optional: empty if the binary was built without VCS information.
+	Revision string
+	// RevisionTime is the time of the revision, in RFC 3339 format.
+	RevisionTime string
+	// Modified reports whether the working tree had local changes.
+	Modified bool
+	// GoVersion is the version of the Go toolchain which built the binary.
+	GoVersion string
+}
+
+// Build returns the build information of the running binary.
+var Build = sync.OnceValue(func() BuildInfo {
+	info := BuildInfo{GoVersion: runtime.Version()}
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	info.GoVersion = bi.GoVersion
+	if bi.Main.Path == modulePath {
+		info.ModuleVersion = bi.Main.Version
+	}
+	for _, dep := range bi.Deps {
+		if dep.Path != modulePath {
+			continue
+		}
+		info.ModuleVersion = dep.Version
+		if dep.Replace != nil {
+			info.ModuleVersion = dep.Replace.Version
+		}
+	}
+	for _, setting := range bi.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			info.Revision = setting.Value
+		case "vcs.time":
+			info.RevisionTime = setting.Value
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+	return info
+})
+
+type userAgentCtxKey struct{}
+
+// WithUserAgent returns a context in which the product, e.g.
+// "adk-rest-server/0.3.0", is appended to the user agent of the model calls.
+func WithUserAgent(ctx context.Context, product string) context.Context {
+	products, _ := ctx.Value(userAgentCtxKey{}).([]string)
+	// Don't share the backing array with the parent context.
+	products = append(products[:len(products):len(products)], product)
+	return context.WithValue(ctx, userAgentCtxKey{}, products)
+}
+
+// UserAgent returns the user agent of the model calls made with ctx: the ADK
+// and Go versions, followed by the products added with [WithUserAgent].
+func UserAgent(ctx context.Context) string {
+	ua := fmt.Sprintf("google-adk/%s gl-go/%s", Version, strings.TrimPrefix(runtime.Version(), "go"))
+	if products, _ := ctx.Value(userAgentCtxKey{}).([]string); len(products) > 0 {
+		ua += " " + strings.Join(products, " ")
+	}
+	return ua
+}
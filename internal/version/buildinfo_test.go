@@ -0,0 +1,58 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package version
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestUserAgent(t *testing.T) {
+	base := "google-adk/" + Version + " gl-go/" + strings.TrimPrefix(runtime.Version(), "go")
+	ctx := WithUserAgent(context.Background(), "server/1")
+	first := WithUserAgent(ctx, "first/1")
+	second := WithUserAgent(ctx, "second/1")
+
+	tc := []struct {
+		name string
+		ctx  context.Context
+		want string
+	}{
+		{name: "no products", ctx: context.Background(), want: base},
+		{name: "one product", ctx: ctx, want: base + " server/1"},
+		{name: "nested product", ctx: first, want: base + " server/1 first/1"},
+		{name: "sibling product", ctx: second, want: base + " server/1 second/1"},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := UserAgent(tt.ctx); got != tt.want {
+				t.Errorf("UserAgent() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuild(t *testing.T) {
+	info := Build()
+	if info.GoVersion != runtime.Version() {
+		t.Errorf("GoVersion = %q, want %q", info.GoVersion, runtime.Version())
+	}
+	// Tests are built with the ADK module as the main module.
+	if info.ModuleVersion != "(devel)" {
+		t.Errorf("ModuleVersion = %q, want (devel)", info.ModuleVersion)
+	}
+}
@@ -0,0 +1,343 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ingest adds the sessions to a memory service in the background,
+// once their invocations end.
+//
+// An [Ingester] is shared by the runners with [runner.Config]:
+//
+//	ingester, err := ingest.New(ingest.Config{Memory: memoryService, Sessions: sessionService})
+//	...
+//	defer ingester.Close(ctx)
+//	r, err := runner.New(runner.Config{..., MemoryIngester: ingester})
+//
+// A session is ingested by a pool of workers, once at a time. The ingestions
+// are incremental for the memory services which implement
+// [memory.EventAdder]: only the events appended since the previous ingestion
+// of the session by the ingester are added.
+//
+// [runner.Config]: https://pkg.go.dev/google.golang.org/adk/runner#Config
+package ingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"google.golang.org/adk/memory"
+	"google.golang.org/adk/session"
+)
+
+const (
+	// DefaultWorkers is the default number of sessions ingested
+	// concurrently.
+	DefaultWorkers = 4
+	// DefaultQueueSize is the default number of sessions waiting for
+	// ingestion.
+	DefaultQueueSize = 1000
+	// DefaultMaxAttempts is the default number of attempts to ingest a
+	// session before it is dropped.
+	DefaultMaxAttempts = 3
+	// DefaultRetryBackoff is the default backoff before the first retry of a
+	// failed ingestion.
+	DefaultRetryBackoff = time.Second
+)
+
+// maxWatermarks bounds the number of sessions whose last ingested event is
+// kept. The sessions whose watermark is evicted are ingested in full.
+const maxWatermarks = 10000
+
+// Recorder records the ingestions, e.g. as metrics. The metrics recorders of
+// [google.golang.org/adk/server/adkrest/metrics] implement it.
+type Recorder interface {
+	// MemoryIngested records a session added to the memory.
+	MemoryIngested(appName string)
+	// MemoryIngestionFailed records a session dropped without being added.
+	MemoryIngestionFailed(appName string)
+}
+
+// Config is the configuration of an [Ingester].
+type Config struct {
+	// Memory is the service to which the sessions are added. Required.
+	Memory memory.Service
+	// Sessions is the service from which the sessions are read. Required.
+	Sessions session.Service
+	// Workers is the number of sessions ingested concurrently. Defaults to
+	// [DefaultWorkers].
+	Workers int
+	// QueueSize is the number of sessions waiting for ingestion, beyond
+	// which the sessions are dropped. Defaults to [DefaultQueueSize].
+	QueueSize int
+	// MaxAttempts is the number of attempts to ingest a session before it
+	// is dropped. Defaults to [DefaultMaxAttempts].
+	MaxAttempts int
+	// RetryBackoff is the backoff before the first retry of a failed
+	// ingestion, doubled for every following one. Defaults to
+	// [DefaultRetryBackoff].
+	RetryBackoff time.Duration
+	// Metrics records the ingested and failed sessions.
+	// Optional: if nil, they are not recorded.
+	Metrics Recorder
+}
+
+// Ingester adds the sessions to a memory service in the background.
+type Ingester struct {
+	cfg   Config
+	queue chan sessionKey
+	wg    sync.WaitGroup
+
+	// ctx is cancelled when Close gives up waiting for the workers.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu     sync.Mutex
+	closed bool
+	// states holds the sessions which are queued or being ingested.
+	states map[sessionKey]*sessionState
+	// watermarks holds the ID of the last ingested event of the sessions.
+	watermarks map[sessionKey]string
+}
+
+type sessionKey struct {
+	appName, userID, sessionID string
+}
+
+type sessionState struct {
+	running bool
+	// dirty tells that the session was enqueued again while it was being
+	// ingested.
+	dirty bool
+}
+
+// New creates an [Ingester] and starts its workers.
+func New(cfg Config) (*Ingester, error) {
+	if cfg.Memory == nil {
+		return nil, errors.New("memory service is required")
+	}
+	if cfg.Sessions == nil {
+		return nil, errors.New("session service is required")
+	}
+	if cfg.Workers < 0 || cfg.QueueSize < 0 || cfg.MaxAttempts < 0 || cfg.RetryBackoff < 0 {
+		return nil, fmt.Errorf("workers, queue size, max attempts and retry backoff must not be negative")
+	}
+	if cfg.Workers == 0 {
+		cfg.Workers = DefaultWorkers
+	}
+	if cfg.QueueSize == 0 {
+		cfg.QueueSize = DefaultQueueSize
+	}
+	if cfg.MaxAttempts == 0 {
+		cfg.MaxAttempts = DefaultMaxAttempts
+	}
+	if cfg.RetryBackoff == 0 {
+		cfg.RetryBackoff = DefaultRetryBackoff
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	i := &Ingester{
+		cfg:        cfg,
+		queue:      make(chan sessionKey, cfg.QueueSize),
+		ctx:        ctx,
+		cancel:     cancel,
+		states:     make(map[sessionKey]*sessionState),
+		watermarks: make(map[sessionKey]string),
+	}
+	for range cfg.Workers {
+		i.wg.Add(1)
+		go func() {
+			defer i.wg.Done()
+			for k := range i.queue {
+				i.process(k)
+			}
+		}()
+	}
+	return i, nil
+}
+
+// Enqueue schedules the ingestion of the session. It doesn't block: the
+// session is dropped if the queue is full. A session which is already queued
+// is ingested once.
+func (i *Ingester) Enqueue(appName, userID, sessionID string) {
+	k := sessionKey{appName: appName, userID: userID, sessionID: sessionID}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.closed {
+		return
+	}
+	if st, ok := i.states[k]; ok {
+		if st.running {
+			st.dirty = true
+		}
+		return
+	}
+	select {
+	case i.queue <- k:
+		i.states[k] = &sessionState{}
+	default:
+		i.drop(k, errors.New("the queue is full"))
+	}
+}
+
+// Close stops accepting sessions and waits for the queued ones to be
+// ingested. If ctx is done first, the ingestions in progress are cancelled
+// and the error of ctx is returned.
+func (i *Ingester) Close(ctx context.Context) error {
+	i.mu.Lock()
+	if !i.closed {
+		i.closed = true
+		close(i.queue)
+	}
+	i.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		i.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		i.cancel()
+		return nil
+	case <-ctx.Done():
+		i.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// process ingests the session, retrying the failed attempts.
+func (i *Ingester) process(k sessionKey) {
+	i.mu.Lock()
+	i.states[k].running = true
+	i.mu.Unlock()
+
+	backoff := i.cfg.RetryBackoff
+	for attempt := 1; ; attempt++ {
+		err := i.ingest(k)
+		if err == nil {
+			if i.cfg.Metrics != nil {
+				i.cfg.Metrics.MemoryIngested(k.appName)
+			}
+			break
+		}
+		if attempt == i.cfg.MaxAttempts || i.ctx.Err() != nil {
+			i.mu.Lock()
+			i.drop(k, fmt.Errorf("%d attempts failed: %w", attempt, err))
+			i.mu.Unlock()
+			break
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-i.ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	st := i.states[k]
+	if !st.dirty || i.closed {
+		delete(i.states, k)
+		return
+	}
+	*st = sessionState{}
+	select {
+	case i.queue <- k:
+	default:
+		delete(i.states, k)
+		i.drop(k, errors.New("the queue is full"))
+	}
+}
+
+// drop logs and records a session which isn't ingested. The caller must hold
+// the lock.
+func (i *Ingester) drop(k sessionKey, err error) {
+	log.Printf("Memory ingestion of session %q of app %q dropped: %v", k.sessionID, k.appName, err)
+	if i.cfg.Metrics != nil {
+		i.cfg.Metrics.MemoryIngestionFailed(k.appName)
+	}
+}
+
+// ingest adds the events of the session appended since its watermark to the
+// memory, or the whole session if the memory service doesn't add events.
+func (i *Ingester) ingest(k sessionKey) error {
+	resp, err := i.cfg.Sessions.Get(i.ctx, &session.GetRequest{
+		AppName:   k.appName,
+		UserID:    k.userID,
+		SessionID: k.sessionID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get session: %w", err)
+	}
+	sess := resp.Session
+	events := sess.Events()
+	if events.Len() == 0 {
+		return nil
+	}
+	last := events.At(events.Len() - 1).ID
+
+	i.mu.Lock()
+	watermark, ok := i.watermarks[k]
+	i.mu.Unlock()
+
+	adder, incremental := i.cfg.Memory.(memory.EventAdder)
+	switch start := since(events, watermark); {
+	case ok && incremental && start == events.Len():
+		return nil
+	case ok && incremental && start >= 0:
+		var added []*session.Event
+		for j := start; j < events.Len(); j++ {
+			added = append(added, events.At(j))
+		}
+		err = adder.AddEvents(i.ctx, sess, added)
+	default:
+		// Without a watermark, the session was never ingested by this
+		// ingester, or its events changed, e.g. by a compaction.
+		err = i.cfg.Memory.AddSession(i.ctx, sess)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to add session to memory: %w", err)
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if _, ok := i.watermarks[k]; !ok && len(i.watermarks) >= maxWatermarks {
+		for evicted := range i.watermarks {
+			delete(i.watermarks, evicted)
+			break
+		}
+	}
+	i.watermarks[k] = last
+	return nil
+}
+
+// since returns the index of the event following the one with the ID, or -1
+// if there is no such event.
+func since(events session.Events, id string) int {
+	for j := events.Len() - 1; j >= 0; j-- {
+		if events.At(j).ID == id {
+			return j + 1
+		}
+	}
+	return -1
+}
@@ -0,0 +1,320 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ingest_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/memory"
+	"google.golang.org/adk/memory/ingest"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+)
+
+// recordingMemory records the events added to memory. Its calls fail while
+// failures is positive, and wait for release if it is set.
+type recordingMemory struct {
+	mu       sync.Mutex
+	calls    []string
+	failures int
+	started  chan string
+	release  chan struct{}
+}
+
+func (m *recordingMemory) record(ctx context.Context, call string, events []*session.Event) error {
+	if m.started != nil {
+		m.started <- call
+	}
+	if m.release != nil {
+		select {
+		case <-m.release:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.failures > 0 {
+		m.failures--
+		return errors.New("unavailable")
+	}
+	for _, e := range events {
+		call += " " + e.LLMResponse.Content.Parts[0].Text
+	}
+	m.calls = append(m.calls, call)
+	return nil
+}
+
+func (m *recordingMemory) recorded() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls
+}
+
+func (m *recordingMemory) AddSession(ctx context.Context, s session.Session) error {
+	var events []*session.Event
+	for e := range s.Events().All() {
+		events = append(events, e)
+	}
+	return m.record(ctx, "session", events)
+}
+
+func (m *recordingMemory) Search(ctx context.Context, req *memory.SearchRequest) (*memory.SearchResponse, error) {
+	return &memory.SearchResponse{}, nil
+}
+
+// incrementalMemory is a recordingMemory which adds events.
+type incrementalMemory struct {
+	*recordingMemory
+}
+
+func (m incrementalMemory) AddEvents(ctx context.Context, s session.Session, events []*session.Event) error {
+	return m.record(ctx, "events", events)
+}
+
+// fakeRecorder sends the outcome of every ingestion.
+type fakeRecorder chan string
+
+func (r fakeRecorder) MemoryIngested(appName string)        { r <- "ingested " + appName }
+func (r fakeRecorder) MemoryIngestionFailed(appName string) { r <- "failed " + appName }
+
+// wait returns the next n outcomes.
+func (r fakeRecorder) wait(t *testing.T, n int) []string {
+	t.Helper()
+	var got []string
+	for range n {
+		select {
+		case outcome := <-r:
+			got = append(got, outcome)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for ingestions, got %v", got)
+		}
+	}
+	return got
+}
+
+type fixture struct {
+	sessions session.Service
+	memory   *recordingMemory
+	metrics  fakeRecorder
+}
+
+func newFixture(t *testing.T, sessionIDs ...string) *fixture {
+	t.Helper()
+	f := &fixture{sessions: session.InMemoryService(), memory: &recordingMemory{}, metrics: make(fakeRecorder, 10)}
+	for _, id := range sessionIDs {
+		if _, err := f.sessions.Create(t.Context(), &session.CreateRequest{AppName: "app", UserID: "user", SessionID: id}); err != nil {
+			t.Fatal(err)
+		}
+		f.appendText(t, id, "hello")
+	}
+	return f
+}
+
+func (f *fixture) appendText(t *testing.T, sessionID, text string) {
+	t.Helper()
+	resp, err := f.sessions.Get(t.Context(), &session.GetRequest{AppName: "app", UserID: "user", SessionID: sessionID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	event := session.NewEvent("invocation")
+	event.Author = "user"
+	event.LLMResponse = model.LLMResponse{Content: genai.NewContentFromText(text, genai.RoleUser)}
+	if err := f.sessions.AppendEvent(t.Context(), resp.Session, event); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (f *fixture) newIngester(t *testing.T, mem memory.Service, cfg ingest.Config) *ingest.Ingester {
+	t.Helper()
+	cfg.Memory, cfg.Sessions, cfg.Metrics = mem, f.sessions, f.metrics
+	if cfg.RetryBackoff == 0 {
+		cfg.RetryBackoff = time.Millisecond
+	}
+	i, err := ingest.New(cfg)
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := i.Close(context.Background()); err != nil {
+			t.Errorf("Close() failed: %v", err)
+		}
+	})
+	return i
+}
+
+func TestIngester_Incremental(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		incremental bool
+		want        []string
+	}{
+		{
+			name:        "incremental",
+			incremental: true,
+			want:        []string{"session hello", "events again later", "session hello again later"},
+		},
+		{
+			name: "full",
+			want: []string{"session hello", "session hello again later", "session hello again later", "session hello again later"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newFixture(t, "s1")
+			var mem memory.Service = f.memory
+			if tc.incremental {
+				mem = incrementalMemory{f.memory}
+			}
+			i := f.newIngester(t, mem, ingest.Config{})
+
+			i.Enqueue("app", "user", "s1")
+			f.metrics.wait(t, 1)
+			f.appendText(t, "s1", "again")
+			f.appendText(t, "s1", "later")
+			i.Enqueue("app", "user", "s1")
+			f.metrics.wait(t, 1)
+			// Without new events, there is nothing to add incrementally.
+			i.Enqueue("app", "user", "s1")
+			f.metrics.wait(t, 1)
+
+			// Another ingester has no watermark, so it ingests the whole
+			// session.
+			other := f.newIngester(t, mem, ingest.Config{})
+			other.Enqueue("app", "user", "s1")
+			f.metrics.wait(t, 1)
+
+			if diff := cmp.Diff(tc.want, f.memory.recorded()); diff != "" {
+				t.Errorf("memory calls mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestIngester_Retries(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		failures    int
+		maxAttempts int
+		wantMetrics []string
+		wantCalls   []string
+	}{
+		{
+			name:        "succeeds after retries",
+			failures:    2,
+			wantMetrics: []string{"ingested app"},
+			wantCalls:   []string{"session hello"},
+		},
+		{
+			name:        "dropped",
+			failures:    2,
+			maxAttempts: 2,
+			wantMetrics: []string{"failed app"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newFixture(t, "s1")
+			f.memory.failures = tc.failures
+			i := f.newIngester(t, f.memory, ingest.Config{MaxAttempts: tc.maxAttempts})
+
+			i.Enqueue("app", "user", "s1")
+			if diff := cmp.Diff(tc.wantMetrics, f.metrics.wait(t, 1)); diff != "" {
+				t.Errorf("metrics mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantCalls, f.memory.recorded()); diff != "" {
+				t.Errorf("memory calls mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestIngester_Queue(t *testing.T) {
+	f := newFixture(t, "s1", "s2", "s3")
+	f.memory.started = make(chan string, 10)
+	f.memory.release = make(chan struct{})
+	i := f.newIngester(t, f.memory, ingest.Config{Workers: 1, QueueSize: 1})
+
+	i.Enqueue("app", "user", "s1")
+	<-f.memory.started
+	// s2 takes the room of the queue, once: s3 is dropped.
+	i.Enqueue("app", "user", "s2")
+	i.Enqueue("app", "user", "s2")
+	i.Enqueue("app", "user", "s3")
+	if diff := cmp.Diff([]string{"failed app"}, f.metrics.wait(t, 1)); diff != "" {
+		t.Errorf("metrics mismatch (-want +got):\n%s", diff)
+	}
+	// s1 is being ingested: enqueuing it again ingests it once more once
+	// its ingestion ends, but the queue is still full then.
+	i.Enqueue("app", "user", "s1")
+	i.Enqueue("app", "user", "s1")
+
+	close(f.memory.release)
+	got := f.metrics.wait(t, 3)
+	if diff := cmp.Diff([]string{"ingested app", "failed app", "ingested app"}, got); diff != "" {
+		t.Errorf("metrics mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestIngester_Close(t *testing.T) {
+	f := newFixture(t, "s1", "s2")
+	f.memory.started = make(chan string, 10)
+	f.memory.release = make(chan struct{})
+	i, err := ingest.New(ingest.Config{Memory: f.memory, Sessions: f.sessions, Metrics: f.metrics, Workers: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	i.Enqueue("app", "user", "s1")
+	<-f.memory.started
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+	if err := i.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Close() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if diff := cmp.Diff([]string{"failed app"}, f.metrics.wait(t, 1)); diff != "" {
+		t.Errorf("metrics mismatch (-want +got):\n%s", diff)
+	}
+	// Closed ingesters ignore the sessions.
+	i.Enqueue("app", "user", "s2")
+	if err := i.Close(t.Context()); err != nil {
+		t.Errorf("second Close() failed: %v", err)
+	}
+	if len(f.metrics) != 0 {
+		t.Errorf("closed ingester ingested a session")
+	}
+}
+
+func TestNew_Errors(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		cfg  ingest.Config
+	}{
+		{"no memory", ingest.Config{Sessions: session.InMemoryService()}},
+		{"no sessions", ingest.Config{Memory: memory.InMemoryService()}},
+		{"negative workers", ingest.Config{Memory: memory.InMemoryService(), Sessions: session.InMemoryService(), Workers: -1}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := ingest.New(tc.cfg); err == nil {
+				t.Errorf("New() succeeded, want an error")
+			}
+		})
+	}
+}
@@ -16,7 +16,10 @@ package memory
 
 import (
 	"context"
+	"fmt"
+	"iter"
 	"maps"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -30,9 +33,34 @@ import (
 func InMemoryService() Service {
 	return &inMemoryService{
 		store: make(map[key]map[sessionID][]value),
+		now:   time.Now,
 	}
 }
 
+// InMemoryConfig is the configuration of the in-memory memory service.
+type InMemoryConfig struct {
+	// TTL is the time to live of the memories of the events without a
+	// [TTLKey] custom metadata. Zero keeps them forever.
+	TTL time.Duration
+	// Now returns the current time, which the expiration times are compared
+	// to. Defaults to [time.Now].
+	Now func() time.Time
+}
+
+// NewInMemoryService returns an in-memory implementation of the memory
+// service with the given configuration, which implements [Forgetter] too.
+func NewInMemoryService(cfg InMemoryConfig) (Service, error) {
+	if cfg.TTL < 0 {
+		return nil, fmt.Errorf("ttl must not be negative, got %v", cfg.TTL)
+	}
+	s := InMemoryService().(*inMemoryService)
+	s.ttl = cfg.TTL
+	if cfg.Now != nil {
+		s.now = cfg.Now
+	}
+	return s, nil
+}
+
 type key struct {
 	appName, userID string
 }
@@ -40,25 +68,109 @@ type key struct {
 type sessionID string
 
 type value struct {
+	eventID   string
 	content   *genai.Content
 	author    string
 	timestamp time.Time
+	expiresAt time.Time
 
 	// precomputed set of words in the content for simple keyword matching.
 	words map[string]struct{}
 }
 
+var (
+	_ EventAdder = (*inMemoryService)(nil)
+	_ Forgetter  = (*inMemoryService)(nil)
+)
+
 // inMemoryService is an in-memory implementation of Service.
 type inMemoryService struct {
 	mu    sync.RWMutex
 	store map[key]map[sessionID][]value
+
+	ttl time.Duration
+	now func() time.Time
 }
 
 func (s *inMemoryService) AddSession(ctx context.Context, curSession session.Session) error {
+	values := s.eventValues(curSession.Events().All())
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sessionValues(curSession)[sessionID(curSession.ID())] = values
+	return nil
+}
+
+func (s *inMemoryService) AddEvents(ctx context.Context, curSession session.Session, events []*session.Event) error {
+	values := s.eventValues(slices.Values(events))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v := s.sessionValues(curSession)
+	sid := sessionID(curSession.ID())
+	v[sid] = append(v[sid], values...)
+	return nil
+}
+
+// sessionValues returns the values of the sessions of the user of the
+// session, without the expired ones. The caller must hold the write lock.
+func (s *inMemoryService) sessionValues(curSession session.Session) map[sessionID][]value {
+	k := key{
+		appName: curSession.AppName(),
+		userID:  curSession.UserID(),
+	}
+	v, ok := s.store[k]
+	if !ok {
+		v = map[sessionID][]value{}
+		s.store[k] = v
+	}
+	now := s.now()
+	s.deleteValues(v, func(_ sessionID, e value) bool { return e.expired(now) })
+	return v
+}
+
+// deleteValues deletes the values of the sessions matching del. The caller
+// must hold the write lock.
+func (s *inMemoryService) deleteValues(sessions map[sessionID][]value, del func(sessionID, value) bool) {
+	for sid, values := range sessions {
+		kept := slices.DeleteFunc(values, func(e value) bool { return del(sid, e) })
+		if len(kept) == 0 {
+			delete(sessions, sid)
+		} else {
+			sessions[sid] = kept
+		}
+	}
+}
+
+// expired reports whether the value is expired at now.
+func (v value) expired(now time.Time) bool {
+	return !v.expiresAt.IsZero() && !now.Before(v.expiresAt)
+}
+
+// text returns the text of the content of the value.
+func (v value) text() string {
+	var texts []string
+	for _, part := range v.content.Parts {
+		if part.Text != "" {
+			texts = append(texts, part.Text)
+		}
+	}
+	return strings.Join(texts, " ")
+}
+
+// memoryID returns the ID of the memory of an event.
+func memoryID(sid sessionID, eventID string) string {
+	return string(sid) + "/" + eventID
+}
+
+// eventValues returns the values of the events with text content.
+func (s *inMemoryService) eventValues(events iter.Seq[*session.Event]) []value {
 	var values []value
 
-	for event := range curSession.Events().All() {
-		if event.LLMResponse.Content == nil {
+	for event := range events {
+		if event.LLMResponse.Content == nil || event.Partial {
 			continue
 		}
 
@@ -76,30 +188,15 @@ func (s *inMemoryService) AddSession(ctx context.Context, curSession session.Ses
 		}
 
 		values = append(values, value{
+			eventID:   event.ID,
 			content:   event.LLMResponse.Content,
 			author:    event.Author,
 			timestamp: event.Timestamp,
+			expiresAt: ExpiresAt(event, s.ttl),
 			words:     words,
 		})
 	}
-
-	k := key{
-		appName: curSession.AppName(),
-		userID:  curSession.UserID(),
-	}
-
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	v, ok := s.store[k]
-	if !ok {
-		v = map[sessionID][]value{}
-		s.store[k] = v
-	}
-
-	sid := sessionID(curSession.ID())
-	v[sid] = values
-	return nil
+	return values
 }
 
 func (s *inMemoryService) Search(ctx context.Context, req *SearchRequest) (*SearchResponse, error) {
@@ -110,22 +207,26 @@ func (s *inMemoryService) Search(ctx context.Context, req *SearchRequest) (*Sear
 		userID:  req.UserID,
 	}
 
-	s.mu.RLock()
-	values, ok := s.store[k]
-	s.mu.RUnlock()
-	if !ok {
-		return &SearchResponse{}, nil
-	}
-
+	now := s.now()
 	res := &SearchResponse{}
 
-	for _, events := range values {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for sid, events := range s.store[k] {
 		for _, e := range events {
+			if e.expired(now) || !inRange(e.timestamp, req.After, req.Before) {
+				continue
+			}
 			if checkMapsIntersect(e.words, queryWords) {
 				res.Memories = append(res.Memories, Entry{
 					Content:   e.content,
 					Author:    e.author,
 					Timestamp: e.timestamp,
+					SessionID: string(sid),
+					EventID:   e.eventID,
+					ID:        memoryID(sid, e.eventID),
+					ExpiresAt: e.expiresAt,
 				})
 			}
 		}
@@ -134,6 +235,41 @@ func (s *inMemoryService) Search(ctx context.Context, req *SearchRequest) (*Sear
 	return res, nil
 }
 
+func (s *inMemoryService) Forget(ctx context.Context, req *ForgetRequest) (*ForgetResponse, error) {
+	if !req.HasCriteria() {
+		return nil, ErrNoForgetCriteria
+	}
+	text := strings.ToLower(req.Text)
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	forgotten := 0
+	sessions := s.store[key{appName: req.AppName, userID: req.UserID}]
+	s.deleteValues(sessions, func(sid sessionID, e value) bool {
+		// The expired memories are deleted too, but not counted as they
+		// were forgotten already.
+		if e.expired(now) {
+			return true
+		}
+		if (req.SessionID == "" || string(sid) == req.SessionID) &&
+			(len(req.IDs) == 0 || slices.Contains(req.IDs, memoryID(sid, e.eventID))) &&
+			(text == "" || strings.Contains(strings.ToLower(e.text()), text)) {
+			forgotten++
+			return true
+		}
+		return false
+	})
+	return &ForgetResponse{Forgotten: forgotten}, nil
+}
+
+// inRange reports whether t is in [after, before), the zero times leaving the
+// range open.
+func inRange(t, after, before time.Time) bool {
+	return (after.IsZero() || !t.Before(after)) && (before.IsZero() || t.Before(before))
+}
+
 func checkMapsIntersect(m1, m2 map[string]struct{}) bool {
 	if len(m1) == 0 || len(m2) == 0 {
 		return false
@@ -41,6 +41,7 @@ func Test_inMemoryService_SearchMemory(t *testing.T) {
 			initSessions: []session.Session{
 				makeSession(t, "app1", "user1", "sess1", []*session.Event{
 					{
+						ID:     "e1",
 						Author: "user1",
 						LLMResponse: model.LLMResponse{
 							Content: genai.NewContentFromText("The Quick brown fox", genai.RoleUser),
@@ -55,6 +56,7 @@ func Test_inMemoryService_SearchMemory(t *testing.T) {
 				}),
 				makeSession(t, "app1", "user1", "sess2", []*session.Event{
 					{
+						ID:          "e3",
 						Author:      "test-bot",
 						LLMResponse: model.LLMResponse{Content: genai.NewContentFromText("hello world", genai.RoleModel)},
 						Timestamp:   must(time.Parse(time.RFC3339, "2023-10-02T10:00:00Z")),
@@ -75,11 +77,59 @@ func Test_inMemoryService_SearchMemory(t *testing.T) {
 						Content:   genai.NewContentFromText("The Quick brown fox", genai.RoleUser),
 						Author:    "user1",
 						Timestamp: must(time.Parse(time.RFC3339, "2023-10-01T10:00:00Z")),
+						SessionID: "sess1",
+						EventID:   "e1",
+						ID:        "sess1/e1",
 					},
 					{
 						Content:   genai.NewContentFromText("hello world", genai.RoleModel),
 						Author:    "test-bot",
 						Timestamp: must(time.Parse(time.RFC3339, "2023-10-02T10:00:00Z")),
+						SessionID: "sess2",
+						EventID:   "e3",
+						ID:        "sess2/e3",
+					},
+				},
+			},
+		},
+		{
+			name: "time range",
+			initSessions: []session.Session{
+				makeSession(t, "app1", "user1", "sess1", []*session.Event{
+					{
+						Author:      "user1",
+						LLMResponse: model.LLMResponse{Content: genai.NewContentFromText("hello before", genai.RoleUser)},
+						Timestamp:   must(time.Parse(time.RFC3339, "2023-10-01T10:00:00Z")),
+					},
+					{
+						ID:          "e2",
+						Author:      "user1",
+						LLMResponse: model.LLMResponse{Content: genai.NewContentFromText("hello within", genai.RoleUser)},
+						Timestamp:   must(time.Parse(time.RFC3339, "2023-10-02T10:00:00Z")),
+					},
+					{
+						Author:      "user1",
+						LLMResponse: model.LLMResponse{Content: genai.NewContentFromText("hello after", genai.RoleUser)},
+						Timestamp:   must(time.Parse(time.RFC3339, "2023-10-03T10:00:00Z")),
+					},
+				}),
+			},
+			req: &memory.SearchRequest{
+				AppName: "app1",
+				UserID:  "user1",
+				Query:   "hello",
+				After:   must(time.Parse(time.RFC3339, "2023-10-02T10:00:00Z")),
+				Before:  must(time.Parse(time.RFC3339, "2023-10-03T10:00:00Z")),
+			},
+			wantResp: &memory.SearchResponse{
+				Memories: []memory.Entry{
+					{
+						Content:   genai.NewContentFromText("hello within", genai.RoleUser),
+						Author:    "user1",
+						Timestamp: must(time.Parse(time.RFC3339, "2023-10-02T10:00:00Z")),
+						SessionID: "sess1",
+						EventID:   "e2",
+						ID:        "sess1/e2",
 					},
 				},
 			},
@@ -157,6 +207,43 @@ func Test_inMemoryService_SearchMemory(t *testing.T) {
 	}
 }
 
+func Test_inMemoryService_AddEvents(t *testing.T) {
+	s := memory.InMemoryService()
+	first := &session.Event{
+		ID:          "e1",
+		Author:      "user1",
+		LLMResponse: model.LLMResponse{Content: genai.NewContentFromText("hello first", genai.RoleUser)},
+		Timestamp:   must(time.Parse(time.RFC3339, "2023-10-01T10:00:00Z")),
+	}
+	second := &session.Event{
+		ID:          "e2",
+		Author:      "test-bot",
+		LLMResponse: model.LLMResponse{Content: genai.NewContentFromText("hello second", genai.RoleModel)},
+		Timestamp:   must(time.Parse(time.RFC3339, "2023-10-02T10:00:00Z")),
+	}
+	if err := s.AddSession(t.Context(), makeSession(t, "app1", "user1", "sess1", []*session.Event{first})); err != nil {
+		t.Fatalf("inMemoryService.AddSession() error = %v", err)
+	}
+	sess := makeSession(t, "app1", "user1", "sess1", []*session.Event{first, second})
+	if err := s.(memory.EventAdder).AddEvents(t.Context(), sess, []*session.Event{second}); err != nil {
+		t.Fatalf("inMemoryService.AddEvents() error = %v", err)
+	}
+
+	got, err := s.Search(t.Context(), &memory.SearchRequest{AppName: "app1", UserID: "user1", Query: "hello"})
+	if err != nil {
+		t.Fatalf("inMemoryService.SearchMemory() error = %v", err)
+	}
+	want := &memory.SearchResponse{
+		Memories: []memory.Entry{
+			{Content: first.Content, Author: "user1", Timestamp: first.Timestamp, SessionID: "sess1", EventID: "e1", ID: "sess1/e1"},
+			{Content: second.Content, Author: "test-bot", Timestamp: second.Timestamp, SessionID: "sess1", EventID: "e2", ID: "sess1/e2"},
+		},
+	}
+	if diff := cmp.Diff(want, got, sortMemories); diff != "" {
+		t.Errorf("inMemoryService.SearchMemory() mismatch (-want +got):\n%s", diff)
+	}
+}
+
 func makeSession(t *testing.T, appName, userID, sessionID string, events []*session.Event) session.Session {
 	t.Helper()
 
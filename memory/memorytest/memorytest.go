@@ -0,0 +1,296 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package memorytest provides a conformance suite for the implementations
+// of [memory.Service], which is the reference of their behavior:
+//
+//   - Search returns the memories of the events of the user which contain a
+//     word of the query, with the IDs of their session and event, and never
+//     those of the other users and apps.
+//   - The memories of the events whose [memory.TTLKey] custom metadata has
+//     elapsed since their timestamp aren't returned. The other memories
+//     have their expiration time, if any.
+//   - The services which implement [memory.Forgetter] delete the memories of
+//     the user matching all the criteria of the request and return their
+//     number. Requests without criteria fail with
+//     [memory.ErrNoForgetCriteria]. The memories have an ID, which can be
+//     forgotten.
+//
+// The texts of the suite are a few words long, so that every event is a
+// single memory, and the queries are single words, so that the services
+// which search by similarity pass too if the memories containing the word
+// are more similar to the query than the other ones.
+package memorytest
+
+import (
+	"errors"
+	"iter"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/memory"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+)
+
+// RunConformance runs the conformance suite against the service. Every
+// subtest calls factory with its own [testing.T] to get its own empty
+// service, so that the factory can fail the subtest and register the
+// cleanups of the service on it.
+func RunConformance(t *testing.T, factory func(t *testing.T) memory.Service) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, s memory.Service)
+	}{
+		{"Search", testSearch},
+		{"Expiration", testExpiration},
+		{"ForgetWithoutCriteria", testForgetWithoutCriteria},
+		{"ForgetSession", testForgetSession},
+		{"ForgetIDs", testForgetIDs},
+		{"ForgetText", testForgetText},
+		{"ForgetAllCriteria", testForgetAllCriteria},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.fn(t, factory(t))
+		})
+	}
+}
+
+const (
+	appName = "app"
+	user1   = "user1"
+	user2   = "user2"
+)
+
+func testSearch(t *testing.T, s memory.Service) {
+	ts := time.Now().Add(-time.Hour).Truncate(time.Second)
+	addSession(t, s, appName, user1, "s1",
+		newEvent("e1", "flight to paris", ts, nil),
+		newEvent("e2", "hotel in rome", ts, nil))
+	addSession(t, s, appName, user1, "s2", newEvent("e3", "paris museum tickets", ts, nil))
+	addSession(t, s, appName, user2, "s3", newEvent("e4", "paris again", ts, nil))
+	addSession(t, s, "other_app", user1, "s4", newEvent("e5", "paris", ts, nil))
+
+	res, err := s.Search(t.Context(), &memory.SearchRequest{AppName: appName, UserID: user1, Query: "paris"})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	got := make([]memory.Entry, len(res.Memories))
+	for i, m := range res.Memories {
+		got[i] = memory.Entry{SessionID: m.SessionID, EventID: m.EventID, Author: m.Author, Timestamp: m.Timestamp}
+		if !m.Timestamp.Equal(ts) {
+			t.Errorf("Search() memory %s timestamp = %v, want %v", m.EventID, m.Timestamp, ts)
+		}
+		got[i].Timestamp = time.Time{}
+	}
+	slices.SortFunc(got, func(a, b memory.Entry) int { return strings.Compare(a.EventID, b.EventID) })
+	want := []memory.Entry{
+		{SessionID: "s1", EventID: "e1", Author: "user"},
+		{SessionID: "s2", EventID: "e3", Author: "user"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Search() mismatch (-want +got):\n%s", diff)
+	}
+
+	checkSearch(t, s, user1, "rome", []string{"s1/e2"})
+	checkSearch(t, s, user2, "paris", []string{"s3/e4"})
+	checkSearch(t, s, user1, "", nil)
+}
+
+func testExpiration(t *testing.T, s memory.Service) {
+	ts := time.Now().Add(-time.Hour).Truncate(time.Second)
+	addSession(t, s, appName, user1, "s1",
+		newEvent("e1", "flight tomorrow", ts.Add(-time.Hour), "1h"),
+		newEvent("e2", "flight booked", ts, float64(24*60*60)),
+		newEvent("e3", "flight passport", ts, nil),
+		newEvent("e4", "flight seat", ts, 30*time.Minute),
+		newEvent("e5", "flight meal", ts, "0s"))
+
+	res, err := s.Search(t.Context(), &memory.SearchRequest{AppName: appName, UserID: user1, Query: "flight"})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	got := make(map[string]time.Time)
+	for _, m := range res.Memories {
+		got[m.EventID] = m.ExpiresAt
+	}
+	want := map[string]time.Time{
+		"e2": ts.Add(24 * time.Hour),
+		"e3": {},
+		"e5": {},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Search() expiration times mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func testForgetWithoutCriteria(t *testing.T, s memory.Service) {
+	f := forgetter(t, s)
+	addForgetSessions(t, s)
+
+	_, err := f.Forget(t.Context(), &memory.ForgetRequest{AppName: appName, UserID: user1})
+	if !errors.Is(err, memory.ErrNoForgetCriteria) {
+		t.Errorf("Forget() error = %v, want %v", err, memory.ErrNoForgetCriteria)
+	}
+	checkSearch(t, s, user1, "flight", []string{"s1/e1", "s2/e3"})
+}
+
+func testForgetSession(t *testing.T, s memory.Service) {
+	f := forgetter(t, s)
+	addForgetSessions(t, s)
+
+	checkForget(t, f, &memory.ForgetRequest{AppName: appName, UserID: user1, SessionID: "s1"}, 2)
+	checkSearch(t, s, user1, "flight", []string{"s2/e3"})
+	checkSearch(t, s, user1, "hotel", nil)
+	checkSearch(t, s, user2, "flight", []string{"s1/e4"})
+
+	checkForget(t, f, &memory.ForgetRequest{AppName: appName, UserID: user1, SessionID: "s1"}, 0)
+}
+
+func testForgetIDs(t *testing.T, s memory.Service) {
+	f := forgetter(t, s)
+	addForgetSessions(t, s)
+
+	res, err := s.Search(t.Context(), &memory.SearchRequest{AppName: appName, UserID: user1, Query: "tomorrow"})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(res.Memories) != 1 || res.Memories[0].ID == "" {
+		t.Fatalf("Search() = %+v, want a single memory with an ID", res.Memories)
+	}
+	id := res.Memories[0].ID
+
+	checkForget(t, f, &memory.ForgetRequest{AppName: appName, UserID: user2, IDs: []string{id}}, 0)
+	checkForget(t, f, &memory.ForgetRequest{AppName: appName, UserID: user1, IDs: []string{id, "unknown"}}, 1)
+	checkSearch(t, s, user1, "flight", []string{"s2/e3"})
+	checkSearch(t, s, user2, "flight", []string{"s1/e4"})
+}
+
+func testForgetText(t *testing.T, s memory.Service) {
+	f := forgetter(t, s)
+	addForgetSessions(t, s)
+
+	checkForget(t, f, &memory.ForgetRequest{AppName: appName, UserID: user1, Text: "PARIS"}, 2)
+	checkSearch(t, s, user1, "paris", nil)
+	checkSearch(t, s, user1, "flight", []string{"s1/e1"})
+	checkSearch(t, s, user2, "paris", []string{"s1/e4"})
+}
+
+func testForgetAllCriteria(t *testing.T, s memory.Service) {
+	f := forgetter(t, s)
+	addForgetSessions(t, s)
+
+	checkForget(t, f, &memory.ForgetRequest{AppName: appName, UserID: user1, SessionID: "s2", Text: "flight"}, 1)
+	checkSearch(t, s, user1, "flight", []string{"s1/e1"})
+	checkSearch(t, s, user1, "paris", []string{"s1/e2"})
+}
+
+// forgetter returns the service as a [memory.Forgetter], skipping the test if
+// it isn't one.
+func forgetter(t *testing.T, s memory.Service) memory.Forgetter {
+	t.Helper()
+	f, ok := s.(memory.Forgetter)
+	if !ok {
+		t.Skip("the service doesn't implement memory.Forgetter")
+	}
+	return f
+}
+
+// addForgetSessions adds the sessions of the Forget tests. The session IDs
+// of the users overlap, so that forgetting the session of one user keeps the
+// other one.
+func addForgetSessions(t *testing.T, s memory.Service) {
+	t.Helper()
+	ts := time.Now().Add(-time.Hour).Truncate(time.Second)
+	addSession(t, s, appName, user1, "s1",
+		newEvent("e1", "flight tomorrow morning", ts, nil),
+		newEvent("e2", "hotel in paris", ts, nil))
+	addSession(t, s, appName, user1, "s2", newEvent("e3", "flight to paris", ts, nil))
+	addSession(t, s, appName, user2, "s1", newEvent("e4", "flight to paris", ts, nil))
+}
+
+func checkForget(t *testing.T, f memory.Forgetter, req *memory.ForgetRequest, want int) {
+	t.Helper()
+	res, err := f.Forget(t.Context(), req)
+	if err != nil {
+		t.Fatalf("Forget(%+v) error = %v", req, err)
+	}
+	if res.Forgotten != want {
+		t.Errorf("Forget(%+v) forgot %d memories, want %d", req, res.Forgotten, want)
+	}
+}
+
+// checkSearch checks the session and event IDs, as "session/event", of the
+// memories of the user found by the query.
+func checkSearch(t *testing.T, s memory.Service, userID, query string, want []string) {
+	t.Helper()
+	res, err := s.Search(t.Context(), &memory.SearchRequest{AppName: appName, UserID: userID, Query: query})
+	if err != nil {
+		t.Fatalf("Search(%q) error = %v", query, err)
+	}
+	var got []string
+	for _, m := range res.Memories {
+		got = append(got, m.SessionID+"/"+m.EventID)
+	}
+	slices.Sort(got)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Search(%q) of %s mismatch (-want +got):\n%s", query, userID, diff)
+	}
+}
+
+// newEvent returns a user event with the text, whose memories have the time
+// to live ttl unless it is nil.
+func newEvent(id, text string, timestamp time.Time, ttl any) *session.Event {
+	ev := &session.Event{
+		ID:          id,
+		Author:      "user",
+		Timestamp:   timestamp,
+		LLMResponse: model.LLMResponse{Content: genai.NewContentFromText(text, genai.RoleUser)},
+	}
+	if ttl != nil {
+		ev.CustomMetadata = map[string]any{memory.TTLKey: ttl}
+	}
+	return ev
+}
+
+func addSession(t *testing.T, s memory.Service, appName, userID, sessionID string, events ...*session.Event) {
+	t.Helper()
+	sess := &testSession{appName: appName, userID: userID, id: sessionID, events: events}
+	if err := s.AddSession(t.Context(), sess); err != nil {
+		t.Fatalf("AddSession(%s) error = %v", sessionID, err)
+	}
+}
+
+// testSession is a session of the events, which is all the memory services
+// read.
+type testSession struct {
+	appName, userID, id string
+	events              []*session.Event
+}
+
+func (s *testSession) ID() string                    { return s.id }
+func (s *testSession) AppName() string               { return s.appName }
+func (s *testSession) UserID() string                { return s.userID }
+func (s *testSession) Events() session.Events        { return s }
+func (s *testSession) State() session.State          { panic("not implemented") }
+func (s *testSession) LastUpdateTime() time.Time     { return time.Time{} }
+func (s *testSession) All() iter.Seq[*session.Event] { return slices.Values(s.events) }
+func (s *testSession) Len() int                      { return len(s.events) }
+func (s *testSession) At(i int) *session.Event       { return s.events[i] }
@@ -17,6 +17,8 @@ package memory
 
 import (
 	"context"
+	"errors"
+	"strconv"
 	"time"
 
 	"google.golang.org/genai"
@@ -38,11 +40,117 @@ type Service interface {
 	Search(ctx context.Context, req *SearchRequest) (*SearchResponse, error)
 }
 
-// SearchRequest represents a request for memory search.
+// EventAdder is implemented by the memory services which can add some events
+// of a session, so that the memory can be updated incrementally as the
+// session grows.
+type EventAdder interface {
+	// AddEvents adds the events of the session to the memory service,
+	// keeping the memories of its other events.
+	AddEvents(ctx context.Context, s session.Session, events []*session.Event) error
+}
+
+// Forgetter is implemented by the memory services which can delete memories,
+// e.g. when the user asks to be forgotten.
+type Forgetter interface {
+	// Forget deletes the memories of the user matching all the criteria of
+	// the request. It returns [ErrNoForgetCriteria] if the request has none.
+	//
+	// The memories of a session added again to the memory are remembered
+	// again.
+	Forget(ctx context.Context, req *ForgetRequest) (*ForgetResponse, error)
+}
+
+// ErrNoForgetCriteria is returned by [Forgetter.Forget] for the requests
+// without criteria, rather than forgetting all the memories of the user.
+var ErrNoForgetCriteria = errors.New("forget request has no criteria")
+
+// ErrForgetUnsupported is returned when forgetting memories of a memory
+// service which doesn't implement [Forgetter].
+var ErrForgetUnsupported = errors.New("memory service does not support forgetting")
+
+// ForgetRequest represents a request to delete memories. At least one of
+// SessionID, IDs and Text must be set.
+type ForgetRequest struct {
+	AppName string
+	UserID  string
+	// SessionID selects the memories of the session.
+	SessionID string
+	// IDs selects the memories of the IDs, see [Entry.ID].
+	IDs []string
+	// Text selects the memories whose text contains it, in any case.
+	Text string
+}
+
+// HasCriteria reports whether the request has at least one criterion.
+func (r *ForgetRequest) HasCriteria() bool {
+	return r.SessionID != "" || len(r.IDs) > 0 || r.Text != ""
+}
+
+// ForgetResponse represents the response to a request to delete memories.
+type ForgetResponse struct {
+	// Forgotten is the number of deleted memories.
+	Forgotten int
+}
+
+// TTLKey is the key of the custom metadata of the events which sets the time
+// to live of their memories, from the timestamp of the event. The value is a
+// [time.Duration], a duration string such as "24h", or a number of seconds.
+// A zero or negative time to live keeps the memories forever, overriding the
+// default time to live of the service.
+const TTLKey = "adk_memory_ttl"
+
+// ExpiresAt returns the expiration time of the memories of the event, from
+// its [TTLKey] custom metadata or else defaultTTL. It returns the zero time
+// for the memories which don't expire.
+func ExpiresAt(event *session.Event, defaultTTL time.Duration) time.Time {
+	ttl := defaultTTL
+	if v, ok := event.CustomMetadata[TTLKey]; ok {
+		if d, ok := parseTTL(v); ok {
+			ttl = d
+		}
+	}
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	start := event.Timestamp
+	if start.IsZero() {
+		start = time.Now()
+	}
+	return start.Add(ttl)
+}
+
+// parseTTL parses the value of a [TTLKey] custom metadata, which may have
+// been decoded from JSON by the session service.
+func parseTTL(v any) (time.Duration, bool) {
+	switch v := v.(type) {
+	case time.Duration:
+		return v, true
+	case string:
+		if d, err := time.ParseDuration(v); err == nil {
+			return d, true
+		}
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return time.Duration(f * float64(time.Second)), true
+		}
+	case float64:
+		return time.Duration(v * float64(time.Second)), true
+	case int:
+		return time.Duration(v) * time.Second, true
+	case int64:
+		return time.Duration(v) * time.Second, true
+	}
+	return 0, false
+}
+
+// SearchRequest represents a request for memory search. The expired memories
+// are never returned, even before the service deletes them.
 type SearchRequest struct {
 	Query   string
 	UserID  string
 	AppName string
+	// After and Before, when set, restrict the search to the memories whose
+	// timestamp is in [After, Before).
+	After, Before time.Time
 }
 
 // SearchResponse represents the response from a memory search.
@@ -59,4 +167,18 @@ type Entry struct {
 	// Timestamp shows when the original content of this memory happened.
 	// This string will be forwarded to LLM. Preferred format is ISO 8601 format.
 	Timestamp time.Time
+	// SessionID and EventID identify the event the memory originates from,
+	// for the services which keep track of it.
+	SessionID string
+	EventID   string
+	// ID identifies the memory among those of the user, for the services
+	// which implement [Forgetter].
+	ID string
+	// ExpiresAt is the time after which the memory is no longer returned,
+	// zero for the memories which don't expire.
+	ExpiresAt time.Time
+	// Score is the relevance of the memory to the query, for the services
+	// which rank the memories, e.g. a cosine similarity. It is zero for the
+	// other services.
+	Score float64
 }
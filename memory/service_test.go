@@ -0,0 +1,105 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/memory"
+	"google.golang.org/adk/memory/memorytest"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+)
+
+func TestInMemoryService_Conformance(t *testing.T) {
+	memorytest.RunConformance(t, func(t *testing.T) memory.Service {
+		return memory.InMemoryService()
+	})
+}
+
+func TestNewInMemoryService_TTL(t *testing.T) {
+	now := must(time.Parse(time.RFC3339, "2023-10-02T10:00:00Z"))
+	s, err := memory.NewInMemoryService(memory.InMemoryConfig{
+		TTL: 24 * time.Hour,
+		Now: func() time.Time { return now },
+	})
+	if err != nil {
+		t.Fatalf("NewInMemoryService() error = %v", err)
+	}
+	events := []*session.Event{
+		{ID: "expired", Timestamp: now.Add(-24 * time.Hour)},
+		{ID: "kept", Timestamp: now.Add(-time.Hour)},
+		{ID: "forever", Timestamp: now.Add(-48 * time.Hour), LLMResponse: model.LLMResponse{CustomMetadata: map[string]any{memory.TTLKey: 0}}},
+	}
+	for _, ev := range events {
+		ev.LLMResponse.Content = genai.NewContentFromText("hello "+ev.ID, genai.RoleUser)
+	}
+	if err := s.AddSession(t.Context(), makeSession(t, "app1", "user1", "sess1", events)); err != nil {
+		t.Fatalf("AddSession() error = %v", err)
+	}
+
+	got, err := s.Search(t.Context(), &memory.SearchRequest{AppName: "app1", UserID: "user1", Query: "hello"})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	want := &memory.SearchResponse{
+		Memories: []memory.Entry{
+			{Content: events[2].Content, Timestamp: events[2].Timestamp, SessionID: "sess1", EventID: "forever", ID: "sess1/forever"},
+			{Content: events[1].Content, Timestamp: events[1].Timestamp, SessionID: "sess1", EventID: "kept", ID: "sess1/kept", ExpiresAt: now.Add(23 * time.Hour)},
+		},
+	}
+	if diff := cmp.Diff(want, got, sortMemories); diff != "" {
+		t.Errorf("Search() mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := memory.NewInMemoryService(memory.InMemoryConfig{TTL: -time.Hour}); err == nil {
+		t.Error("NewInMemoryService() with a negative TTL succeeded, want error")
+	}
+}
+
+func TestExpiresAt(t *testing.T) {
+	ts := must(time.Parse(time.RFC3339, "2023-10-01T10:00:00Z"))
+	tests := []struct {
+		name       string
+		ttl        any
+		defaultTTL time.Duration
+		want       time.Time
+	}{
+		{name: "no ttl"},
+		{name: "default ttl", defaultTTL: time.Hour, want: ts.Add(time.Hour)},
+		{name: "duration", ttl: 2 * time.Hour, defaultTTL: time.Hour, want: ts.Add(2 * time.Hour)},
+		{name: "duration string", ttl: "30m", want: ts.Add(30 * time.Minute)},
+		{name: "seconds string", ttl: "90", want: ts.Add(90 * time.Second)},
+		{name: "json seconds", ttl: float64(1.5), want: ts.Add(1500 * time.Millisecond)},
+		{name: "int seconds", ttl: 60, want: ts.Add(time.Minute)},
+		{name: "zero overrides default", ttl: "0s", defaultTTL: time.Hour},
+		{name: "invalid uses default", ttl: "soon", defaultTTL: time.Hour, want: ts.Add(time.Hour)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ev := &session.Event{Timestamp: ts}
+			if tt.ttl != nil {
+				ev.CustomMetadata = map[string]any{memory.TTLKey: tt.ttl}
+			}
+			if got := memory.ExpiresAt(ev, tt.defaultTTL); !got.Equal(tt.want) {
+				t.Errorf("ExpiresAt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
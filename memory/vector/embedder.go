@@ -0,0 +1,105 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vector
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/genai"
+)
+
+// Purpose tells an [Embedder] how the embedded texts are used, which some
+// models take into account to produce better suited embeddings.
+type Purpose int
+
+const (
+	// PurposeDocument is used for the texts stored in the index.
+	PurposeDocument Purpose = iota
+	// PurposeQuery is used for the search queries.
+	PurposeQuery
+)
+
+// Embedder computes the embeddings of texts.
+type Embedder interface {
+	// Name identifies the embedding model. Embeddings computed by embedders
+	// with different names are not comparable.
+	Name() string
+	// Embed returns the embeddings of the texts, in the same order.
+	Embed(ctx context.Context, texts []string, purpose Purpose) ([][]float32, error)
+}
+
+// geminiBatchSize is the maximum number of texts embedded by a single call to
+// the Gemini API.
+const geminiBatchSize = 100
+
+type geminiEmbedder struct {
+	client *genai.Client
+	name   string
+}
+
+// NewGeminiEmbedder returns an [Embedder] backed by the Gemini API.
+//
+// It uses the provided context and configuration to initialize the underlying
+// [genai.Client]. The modelName specifies which embedding model to target
+// (e.g., "gemini-embedding-001").
+//
+// An error is returned if the [genai.Client] fails to initialize.
+func NewGeminiEmbedder(ctx context.Context, modelName string, cfg *genai.ClientConfig) (Embedder, error) {
+	client, err := genai.NewClient(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &geminiEmbedder{
+		name:   modelName,
+		client: client,
+	}, nil
+}
+
+func (e *geminiEmbedder) Name() string {
+	return e.name
+}
+
+func (e *geminiEmbedder) Embed(ctx context.Context, texts []string, purpose Purpose) ([][]float32, error) {
+	config := &genai.EmbedContentConfig{TaskType: "RETRIEVAL_DOCUMENT"}
+	if purpose == PurposeQuery {
+		config.TaskType = "RETRIEVAL_QUERY"
+	}
+
+	embeddings := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += geminiBatchSize {
+		end := min(start+geminiBatchSize, len(texts))
+		contents := make([]*genai.Content, 0, end-start)
+		for _, text := range texts[start:end] {
+			contents = append(contents, genai.NewContentFromText(text, genai.RoleUser))
+		}
+
+		resp, err := e.client.Models.EmbedContent(ctx, e.name, contents, config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to call embedding model: %w", err)
+		}
+		if len(resp.Embeddings) != len(contents) {
+			return nil, fmt.Errorf("embedding model returned %d embeddings for %d texts", len(resp.Embeddings), len(contents))
+		}
+		for _, embedding := range resp.Embeddings {
+			if embedding == nil {
+				return nil, fmt.Errorf("embedding model returned an empty embedding")
+			}
+			embeddings = append(embeddings, embedding.Values)
+		}
+	}
+	return embeddings, nil
+}
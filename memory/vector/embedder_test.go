@@ -0,0 +1,112 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vector_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/memory/vector"
+)
+
+// fakeEmbeddingServer serves the Gemini API batchEmbedContents method,
+// embedding each text as its length and recording the task types.
+type fakeEmbeddingServer struct {
+	calls     int
+	taskTypes []string
+}
+
+func (f *fakeEmbeddingServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasSuffix(r.URL.Path, "/models/test-embedding:batchEmbedContents") {
+		http.Error(w, "unexpected path "+r.URL.Path, http.StatusNotFound)
+		return
+	}
+	var req struct {
+		Requests []struct {
+			Content  genai.Content `json:"content"`
+			TaskType string        `json:"taskType"`
+		} `json:"requests"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	f.calls++
+
+	type embedding struct {
+		Values []float32 `json:"values"`
+	}
+	var resp struct {
+		Embeddings []embedding `json:"embeddings"`
+	}
+	for _, r := range req.Requests {
+		f.taskTypes = append(f.taskTypes, r.TaskType)
+		resp.Embeddings = append(resp.Embeddings, embedding{Values: []float32{float32(len(r.Content.Parts[0].Text))}})
+	}
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func TestGeminiEmbedder(t *testing.T) {
+	fake := &fakeEmbeddingServer{}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	embedder, err := vector.NewGeminiEmbedder(t.Context(), "test-embedding", &genai.ClientConfig{
+		APIKey:      "fake-key",
+		Backend:     genai.BackendGeminiAPI,
+		HTTPOptions: genai.HTTPOptions{BaseURL: server.URL},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := embedder.Name(); got != "test-embedding" {
+		t.Errorf("Name() = %q, want %q", got, "test-embedding")
+	}
+
+	var texts []string
+	var want [][]float32
+	for i := range 150 {
+		text := fmt.Sprint(i)
+		texts = append(texts, text)
+		want = append(want, []float32{float32(len(text))})
+	}
+	got, err := embedder.Embed(t.Context(), texts, vector.PurposeDocument)
+	if err != nil {
+		t.Fatalf("Embed() failed: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Embed() mismatch (-want +got):\n%s", diff)
+	}
+	if fake.calls != 2 {
+		t.Errorf("Embed() made %d calls, want 2 batches", fake.calls)
+	}
+
+	if _, err := embedder.Embed(t.Context(), []string{"query"}, vector.PurposeQuery); err != nil {
+		t.Fatalf("Embed() failed: %v", err)
+	}
+	wantTaskTypes := []string{"RETRIEVAL_DOCUMENT", "RETRIEVAL_QUERY"}
+	if gotTaskTypes := []string{fake.taskTypes[0], fake.taskTypes[len(fake.taskTypes)-1]}; !cmp.Equal(wantTaskTypes, gotTaskTypes) {
+		t.Errorf("task types = %v, want %v", gotTaskTypes, wantTaskTypes)
+	}
+}
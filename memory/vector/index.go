@@ -0,0 +1,311 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vector
+
+import (
+	"cmp"
+	"context"
+	"iter"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/adk/memory"
+)
+
+// Record is a chunk of the text of a session event, stored in an [Index]
+// with its embedding.
+type Record struct {
+	AppName, UserID, SessionID, EventID string
+	// Chunk is the position of the chunk in the text of the event.
+	Chunk int
+
+	Text      string
+	Role      string
+	Author    string
+	Timestamp time.Time
+	// ExpiresAt is the time after which the record is no longer searched,
+	// zero for the records which don't expire.
+	ExpiresAt time.Time
+
+	// Embedder is the name of the [Embedder] which computed the vector.
+	Embedder string
+	Vector   []float32
+}
+
+// ID returns the ID of the memory of the record, see [memory.Entry.ID].
+func (r *Record) ID() string {
+	return r.SessionID + "/" + r.EventID + "/" + strconv.Itoa(r.Chunk)
+}
+
+// expired reports whether the record is expired at now.
+func (r *Record) expired(now time.Time) bool {
+	return !r.ExpiresAt.IsZero() && !now.Before(r.ExpiresAt)
+}
+
+// Query is a similarity search in an [Index].
+type Query struct {
+	AppName, UserID string
+	// Embedder is the name of the [Embedder] which computed the vector. Only
+	// the records embedded by it are searched.
+	Embedder string
+	Vector   []float32
+	// TopK is the maximum number of matches.
+	TopK int
+	// MinScore is the similarity which the matches must exceed.
+	MinScore float64
+	// After and Before, when set, restrict the search to the records whose
+	// timestamp is in [After, Before).
+	After, Before time.Time
+	// Now, when set, excludes the records expired at the time.
+	Now time.Time
+}
+
+// Match is a record found by a [Query].
+type Match struct {
+	Record *Record
+	// Score is the cosine similarity of the record to the query, between -1
+	// and 1.
+	Score float64
+}
+
+// Index stores the records and searches them by similarity.
+//
+// Implementations must be safe for concurrent use.
+type Index interface {
+	// ReplaceSession replaces the records of a session with the given ones.
+	ReplaceSession(ctx context.Context, appName, userID, sessionID string, records []*Record) error
+	// Add stores the records, replacing the ones of the same chunk of the
+	// same event.
+	Add(ctx context.Context, records []*Record) error
+	// Update replaces the stored records of the same chunk and text of the
+	// same event with the given ones, which is used to update their vectors.
+	// Records which are no longer stored are ignored.
+	Update(ctx context.Context, records []*Record) error
+	// Search returns the records most similar to the query, most similar
+	// first.
+	Search(ctx context.Context, query *Query) ([]Match, error)
+	// All iterates over all the records.
+	All(ctx context.Context) iter.Seq2[*Record, error]
+	// Forget deletes the records of the user matching all the criteria of
+	// the request, which has at least one, and returns their number. The
+	// IDs of the request are those of [Record.ID], and its text matches the
+	// text of the records.
+	Forget(ctx context.Context, req *memory.ForgetRequest) (int, error)
+	// DeleteExpired deletes the records expired at now and returns their
+	// number.
+	DeleteExpired(ctx context.Context, now time.Time) (int, error)
+}
+
+// InMemoryIndex returns an in-process implementation of [Index], which
+// compares the query to all the records of the user. Thread-safe.
+func InMemoryIndex() Index {
+	return &inMemoryIndex{
+		store: make(map[userKey]map[string][]*Record),
+	}
+}
+
+type userKey struct {
+	appName, userID string
+}
+
+type inMemoryIndex struct {
+	mu sync.RWMutex
+	// store holds the records of each session of the users.
+	store map[userKey]map[string][]*Record
+}
+
+func (x *inMemoryIndex) ReplaceSession(ctx context.Context, appName, userID, sessionID string, records []*Record) error {
+	k := userKey{appName: appName, userID: userID}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	sessions, ok := x.store[k]
+	if !ok {
+		sessions = map[string][]*Record{}
+		x.store[k] = sessions
+	}
+	if len(records) == 0 {
+		delete(sessions, sessionID)
+		return nil
+	}
+	sessions[sessionID] = slices.Clone(records)
+	return nil
+}
+
+func (x *inMemoryIndex) Add(ctx context.Context, records []*Record) error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	for _, r := range records {
+		k := userKey{appName: r.AppName, userID: r.UserID}
+		sessions, ok := x.store[k]
+		if !ok {
+			sessions = map[string][]*Record{}
+			x.store[k] = sessions
+		}
+		stored := sessions[r.SessionID]
+		i := slices.IndexFunc(stored, func(s *Record) bool {
+			return s.EventID == r.EventID && s.Chunk == r.Chunk
+		})
+		if i >= 0 {
+			stored[i] = r
+		} else {
+			sessions[r.SessionID] = append(stored, r)
+		}
+	}
+	return nil
+}
+
+func (x *inMemoryIndex) Update(ctx context.Context, records []*Record) error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	for _, r := range records {
+		stored := x.store[userKey{appName: r.AppName, userID: r.UserID}][r.SessionID]
+		i := slices.IndexFunc(stored, func(s *Record) bool {
+			return s.EventID == r.EventID && s.Chunk == r.Chunk && s.Text == r.Text
+		})
+		if i >= 0 {
+			stored[i] = r
+		}
+	}
+	return nil
+}
+
+func (x *inMemoryIndex) Search(ctx context.Context, query *Query) ([]Match, error) {
+	k := userKey{appName: query.AppName, userID: query.UserID}
+
+	x.mu.RLock()
+	var matches []Match
+	for _, records := range x.store[k] {
+		for _, r := range records {
+			if r.Embedder != query.Embedder || !inRange(r.Timestamp, query.After, query.Before) ||
+				(!query.Now.IsZero() && r.expired(query.Now)) {
+				continue
+			}
+			score, ok := cosine(query.Vector, r.Vector)
+			if !ok || score <= query.MinScore {
+				continue
+			}
+			matches = append(matches, Match{Record: r, Score: score})
+		}
+	}
+	x.mu.RUnlock()
+
+	slices.SortFunc(matches, func(a, b Match) int {
+		return cmp.Or(
+			cmp.Compare(b.Score, a.Score),
+			a.Record.Timestamp.Compare(b.Record.Timestamp),
+			cmp.Compare(a.Record.EventID, b.Record.EventID),
+			cmp.Compare(a.Record.Chunk, b.Record.Chunk),
+		)
+	})
+	if query.TopK > 0 && len(matches) > query.TopK {
+		matches = matches[:query.TopK]
+	}
+	return matches, nil
+}
+
+func (x *inMemoryIndex) All(ctx context.Context) iter.Seq2[*Record, error] {
+	return func(yield func(*Record, error) bool) {
+		x.mu.RLock()
+		var records []*Record
+		for _, sessions := range x.store {
+			for _, rs := range sessions {
+				records = append(records, rs...)
+			}
+		}
+		x.mu.RUnlock()
+
+		for _, r := range records {
+			if err := ctx.Err(); err != nil {
+				yield(nil, err)
+				return
+			}
+			if !yield(r, nil) {
+				return
+			}
+		}
+	}
+}
+
+func (x *inMemoryIndex) Forget(ctx context.Context, req *memory.ForgetRequest) (int, error) {
+	text := strings.ToLower(req.Text)
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	return deleteRecords(x.store[userKey{appName: req.AppName, userID: req.UserID}], func(r *Record) bool {
+		return (req.SessionID == "" || r.SessionID == req.SessionID) &&
+			(len(req.IDs) == 0 || slices.Contains(req.IDs, r.ID())) &&
+			(text == "" || strings.Contains(strings.ToLower(r.Text), text))
+	}), nil
+}
+
+func (x *inMemoryIndex) DeleteExpired(ctx context.Context, now time.Time) (int, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	n := 0
+	for _, sessions := range x.store {
+		n += deleteRecords(sessions, func(r *Record) bool { return r.expired(now) })
+	}
+	return n, nil
+}
+
+// deleteRecords deletes the records of the sessions matching del and returns
+// their number. The caller must hold the write lock.
+func deleteRecords(sessions map[string][]*Record, del func(*Record) bool) int {
+	n := 0
+	for id, records := range sessions {
+		kept := slices.DeleteFunc(records, del)
+		n += len(records) - len(kept)
+		if len(kept) == 0 {
+			delete(sessions, id)
+		} else {
+			sessions[id] = kept
+		}
+	}
+	return n
+}
+
+// inRange reports whether t is in [after, before), the zero times leaving the
+// range open.
+func inRange(t, after, before time.Time) bool {
+	return (after.IsZero() || !t.Before(after)) && (before.IsZero() || t.Before(before))
+}
+
+// cosine returns the cosine similarity of the vectors. It reports false if
+// the vectors have different dimensions or one of them is zero.
+func cosine(a, b []float32) (float64, bool) {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0, false
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0, false
+	}
+	return dot / math.Sqrt(normA*normB), true
+}
@@ -0,0 +1,117 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vector_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"google.golang.org/adk/memory/vector"
+)
+
+func TestInMemoryIndex(t *testing.T) {
+	record := func(sessionID, eventID, text string, v ...float32) *vector.Record {
+		return &vector.Record{AppName: "app", UserID: "user", SessionID: sessionID, EventID: eventID, Text: text, Embedder: "e", Vector: v}
+	}
+	ctx := t.Context()
+	index := vector.InMemoryIndex()
+	if err := index.ReplaceSession(ctx, "app", "user", "s1", []*vector.Record{
+		record("s1", "a", "a", 1, 0),
+		record("s1", "b", "b", 1, 1),
+		record("s1", "c", "c", 0, 1),
+		record("s1", "d", "d", 1, 0, 0),
+		record("s1", "z", "z", 0, 0),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := index.ReplaceSession(ctx, "app", "other", "s2", []*vector.Record{
+		{AppName: "app", UserID: "other", SessionID: "s2", EventID: "x", Embedder: "e", Vector: []float32{1, 0}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := index.Add(ctx, []*vector.Record{
+		record("s1", "e", "e", 1, 0, 0),
+		// Replaces the stored record of the same chunk.
+		record("s1", "d", "d", 1, 0, 0),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := index.Update(ctx, []*vector.Record{
+		record("s1", "c", "c", -1, 1),
+		// The text of the chunk changed since it was read.
+		record("s1", "b", "old", 0, 1),
+		// The session no longer exists.
+		record("s3", "a", "a", 1, 0),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name  string
+		query *vector.Query
+		want  []string
+	}{
+		{
+			name:  "ranked",
+			query: &vector.Query{AppName: "app", UserID: "user", Embedder: "e", Vector: []float32{1, 0}, MinScore: -1},
+			want:  []string{"a", "b", "c"},
+		},
+		{
+			name:  "positive",
+			query: &vector.Query{AppName: "app", UserID: "user", Embedder: "e", Vector: []float32{1, 0}},
+			want:  []string{"a", "b"},
+		},
+		{
+			name:  "top k",
+			query: &vector.Query{AppName: "app", UserID: "user", Embedder: "e", Vector: []float32{1, 0}, TopK: 1},
+			want:  []string{"a"},
+		},
+		{
+			name:  "other embedder",
+			query: &vector.Query{AppName: "app", UserID: "user", Embedder: "other", Vector: []float32{1, 0}},
+		},
+		{
+			name:  "other dimensions",
+			query: &vector.Query{AppName: "app", UserID: "user", Embedder: "e", Vector: []float32{1, 0, 0}},
+			want:  []string{"d", "e"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			matches, err := index.Search(ctx, tc.query)
+			if err != nil {
+				t.Fatalf("Search() failed: %v", err)
+			}
+			var got []string
+			for _, m := range matches {
+				got = append(got, m.Record.EventID)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Search() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	var n int
+	for _, err := range index.All(ctx) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		n++
+	}
+	if n != 7 {
+		t.Errorf("All() returned %d records, want 7", n)
+	}
+}
@@ -0,0 +1,3 @@
+CREATE INDEX IF NOT EXISTS memory_chunks_embedding_{{.Dimensions}}_hnsw_idx ON memory_chunks
+	USING hnsw ((embedding::halfvec({{.Dimensions}})) halfvec_cosine_ops)
+	WHERE dimensions = {{.Dimensions}};
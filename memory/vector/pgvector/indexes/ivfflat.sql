@@ -0,0 +1,3 @@
+CREATE INDEX IF NOT EXISTS memory_chunks_embedding_{{.Dimensions}}_ivfflat_idx ON memory_chunks
+	USING ivfflat ((embedding::halfvec({{.Dimensions}})) halfvec_cosine_ops) WITH (lists = {{.Lists}})
+	WHERE dimensions = {{.Dimensions}};
@@ -0,0 +1,74 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgvector
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"text/template"
+
+	"google.golang.org/adk/internal/sessionsql"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+//go:embed indexes/*.sql
+var indexFiles embed.FS
+
+// indexTemplates are the statements creating the approximate nearest
+// neighbor index of each method.
+var indexTemplates = template.Must(template.ParseFS(indexFiles, "indexes/*.sql"))
+
+// migrationsTable records the applied migrations, apart from the ones of the
+// session service which may share the database.
+const migrationsTable = "memory_schema_migrations"
+
+// migrationLockID is the key of the advisory lock which serializes the
+// migrations of the replicas sharing the database.
+const migrationLockID = 0x61646b5f6d656d6f // "adk_memo"
+
+// migrate applies the migrations which weren't applied yet and creates the
+// approximate nearest neighbor index of the configuration, in a single
+// transaction.
+func migrate(ctx context.Context, db *sql.DB, migrations []sessionsql.Migration, cfg *Config) error {
+	var index bytes.Buffer
+	if err := indexTemplates.ExecuteTemplate(&index, string(cfg.Method)+".sql", cfg); err != nil {
+		return fmt.Errorf("failed to render %s index: %w", cfg.Method, err)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin migration: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", int64(migrationLockID)); err != nil {
+		return fmt.Errorf("failed to lock migrations: %w", err)
+	}
+	if err := sessionsql.ApplyTableMigrations(ctx, tx, migrationsTable, migrations); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, index.String()); err != nil {
+		return fmt.Errorf("failed to create %s index: %w", cfg.Method, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migrations: %w", err)
+	}
+	return nil
+}
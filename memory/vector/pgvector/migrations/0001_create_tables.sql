@@ -0,0 +1,23 @@
+CREATE EXTENSION IF NOT EXISTS vector;
+
+CREATE TABLE memory_chunks (
+	app_name   TEXT        NOT NULL,
+	user_id    TEXT        NOT NULL,
+	session_id TEXT        NOT NULL,
+	event_id   TEXT        NOT NULL,
+	chunk      INTEGER     NOT NULL,
+	text       TEXT        NOT NULL,
+	role       TEXT        NOT NULL,
+	author     TEXT        NOT NULL,
+	timestamp  TIMESTAMPTZ NOT NULL,
+	embedder   TEXT        NOT NULL,
+	-- dimensions is the number of dimensions of embedding, which isn't
+	-- constrained so that the vectors of several models can be stored while
+	-- re-embedding. The approximate nearest neighbor indexes are partial
+	-- indexes of a number of dimensions, in half precision.
+	dimensions INTEGER     NOT NULL,
+	embedding  vector      NOT NULL,
+	PRIMARY KEY (app_name, user_id, session_id, event_id, chunk)
+);
+
+CREATE INDEX memory_chunks_app_name_user_id_timestamp_idx ON memory_chunks (app_name, user_id, timestamp);
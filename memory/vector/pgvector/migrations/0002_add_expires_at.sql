@@ -0,0 +1,5 @@
+-- expires_at is the time after which the chunk is no longer searched, NULL
+-- for the chunks which don't expire.
+ALTER TABLE memory_chunks ADD COLUMN expires_at TIMESTAMPTZ;
+
+CREATE INDEX memory_chunks_expires_at_idx ON memory_chunks (expires_at) WHERE expires_at IS NOT NULL;
@@ -0,0 +1,325 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package vector provides a memory service which searches the memories by
+// semantic similarity.
+//
+// Sessions added to the memory are split into chunks, which are embedded with
+// an [Embedder] and stored in an [Index]. The searches return the chunks most
+// similar to the query.
+package vector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+	"time"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/memory"
+	"google.golang.org/adk/session"
+)
+
+const (
+	// DefaultTopK is the default maximum number of memories returned by a
+	// search.
+	DefaultTopK = 5
+	// DefaultChunkSize is the default maximum number of words of a chunk.
+	DefaultChunkSize = 256
+	// DefaultChunkOverlap is the default number of words shared by
+	// consecutive chunks of the same text.
+	DefaultChunkOverlap = 32
+)
+
+// reembedBatchSize is the number of records re-embedded at once by Reembed.
+const reembedBatchSize = 100
+
+// Config is the configuration of the vector memory service.
+type Config struct {
+	// Embedder computes the embeddings of the chunks and queries. Required.
+	Embedder Embedder
+	// Index stores the chunks. Defaults to [InMemoryIndex].
+	Index Index
+	// TopK is the maximum number of memories returned by a search. Defaults
+	// to [DefaultTopK].
+	TopK int
+	// MinScore is the cosine similarity, between -1 and 1, which the
+	// memories returned by a search must exceed. Unrelated texts score 0.
+	MinScore float64
+	// ChunkSize is the maximum number of words of a chunk. Defaults to
+	// [DefaultChunkSize].
+	ChunkSize int
+	// ChunkOverlap is the number of words shared by consecutive chunks of
+	// the same text. It must be smaller than ChunkSize. Defaults to
+	// [DefaultChunkOverlap], or to no overlap if ChunkSize is set.
+	ChunkOverlap int
+	// TTL is the time to live of the memories of the events without a
+	// [memory.TTLKey] custom metadata. Zero keeps them forever.
+	TTL time.Duration
+}
+
+// Service is a memory service which searches the memories by similarity.
+type Service interface {
+	memory.Service
+	// Reembed recomputes with the embedder of the service the vectors of the
+	// chunks embedded by other embedders, e.g. after switching embedding
+	// models. The chunks are not returned by searches until then. It
+	// returns the number of re-embedded chunks.
+	Reembed(ctx context.Context) (int, error)
+	// DeleteExpired deletes the expired chunks from the index, which
+	// searches skip until then. It returns the number of deleted chunks.
+	DeleteExpired(ctx context.Context) (int, error)
+}
+
+// NewService returns a new vector memory service.
+func NewService(cfg Config) (Service, error) {
+	if cfg.Embedder == nil {
+		return nil, errors.New("embedder is required")
+	}
+	if cfg.Index == nil {
+		cfg.Index = InMemoryIndex()
+	}
+	if cfg.TopK < 0 {
+		return nil, fmt.Errorf("top k must not be negative, got %d", cfg.TopK)
+	}
+	if cfg.TopK == 0 {
+		cfg.TopK = DefaultTopK
+	}
+	if cfg.MinScore < -1 || cfg.MinScore > 1 {
+		return nil, fmt.Errorf("min score must be between -1 and 1, got %v", cfg.MinScore)
+	}
+	if cfg.ChunkSize < 0 || cfg.ChunkOverlap < 0 {
+		return nil, fmt.Errorf("chunk size and overlap must not be negative, got %d and %d", cfg.ChunkSize, cfg.ChunkOverlap)
+	}
+	if cfg.ChunkSize == 0 {
+		cfg.ChunkSize = DefaultChunkSize
+		if cfg.ChunkOverlap == 0 {
+			cfg.ChunkOverlap = DefaultChunkOverlap
+		}
+	}
+	if cfg.ChunkOverlap >= cfg.ChunkSize {
+		return nil, fmt.Errorf("chunk overlap must be smaller than chunk size %d, got %d", cfg.ChunkSize, cfg.ChunkOverlap)
+	}
+	if cfg.TTL < 0 {
+		return nil, fmt.Errorf("ttl must not be negative, got %v", cfg.TTL)
+	}
+	return &service{cfg: cfg}, nil
+}
+
+var (
+	_ memory.EventAdder = (*service)(nil)
+	_ memory.Forgetter  = (*service)(nil)
+)
+
+type service struct {
+	cfg Config
+}
+
+func (s *service) AddSession(ctx context.Context, curSession session.Session) error {
+	records := s.records(curSession, curSession.Events().All())
+	if err := s.embed(ctx, records); err != nil {
+		return err
+	}
+	if err := s.cfg.Index.ReplaceSession(ctx, curSession.AppName(), curSession.UserID(), curSession.ID(), records); err != nil {
+		return fmt.Errorf("failed to store session %q: %w", curSession.ID(), err)
+	}
+	return nil
+}
+
+func (s *service) AddEvents(ctx context.Context, curSession session.Session, events []*session.Event) error {
+	records := s.records(curSession, slices.Values(events))
+	if len(records) == 0 {
+		return nil
+	}
+	if err := s.embed(ctx, records); err != nil {
+		return err
+	}
+	if err := s.cfg.Index.Add(ctx, records); err != nil {
+		return fmt.Errorf("failed to store events of session %q: %w", curSession.ID(), err)
+	}
+	return nil
+}
+
+// records returns the records of the chunks of the text of the events of the
+// session, without their vectors.
+func (s *service) records(curSession session.Session, events iter.Seq[*session.Event]) []*Record {
+	var records []*Record
+	for event := range events {
+		content := event.LLMResponse.Content
+		if content == nil || event.Partial {
+			continue
+		}
+
+		var texts []string
+		for _, part := range content.Parts {
+			if part.Text != "" {
+				texts = append(texts, part.Text)
+			}
+		}
+		expiresAt := memory.ExpiresAt(event, s.cfg.TTL)
+		for i, chunk := range s.chunks(strings.Join(texts, "\n")) {
+			records = append(records, &Record{
+				AppName:   curSession.AppName(),
+				UserID:    curSession.UserID(),
+				SessionID: curSession.ID(),
+				EventID:   event.ID,
+				Chunk:     i,
+				Text:      chunk,
+				Role:      content.Role,
+				Author:    event.Author,
+				Timestamp: event.Timestamp,
+				ExpiresAt: expiresAt,
+			})
+		}
+	}
+	return records
+}
+
+func (s *service) Search(ctx context.Context, req *memory.SearchRequest) (*memory.SearchResponse, error) {
+	if strings.TrimSpace(req.Query) == "" {
+		return &memory.SearchResponse{}, nil
+	}
+
+	vectors, err := s.cfg.Embedder.Embed(ctx, []string{req.Query}, PurposeQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+	if len(vectors) != 1 {
+		return nil, fmt.Errorf("embedder returned %d embeddings for the query", len(vectors))
+	}
+
+	matches, err := s.cfg.Index.Search(ctx, &Query{
+		AppName:  req.AppName,
+		UserID:   req.UserID,
+		Embedder: s.cfg.Embedder.Name(),
+		Vector:   vectors[0],
+		TopK:     s.cfg.TopK,
+		MinScore: s.cfg.MinScore,
+		After:    req.After,
+		Before:   req.Before,
+		Now:      time.Now(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search index: %w", err)
+	}
+
+	res := &memory.SearchResponse{}
+	for _, m := range matches {
+		res.Memories = append(res.Memories, memory.Entry{
+			Content:   genai.NewContentFromText(m.Record.Text, genai.Role(m.Record.Role)),
+			Author:    m.Record.Author,
+			Timestamp: m.Record.Timestamp,
+			SessionID: m.Record.SessionID,
+			EventID:   m.Record.EventID,
+			ID:        m.Record.ID(),
+			ExpiresAt: m.Record.ExpiresAt,
+			Score:     m.Score,
+		})
+	}
+	return res, nil
+}
+
+func (s *service) Forget(ctx context.Context, req *memory.ForgetRequest) (*memory.ForgetResponse, error) {
+	if !req.HasCriteria() {
+		return nil, memory.ErrNoForgetCriteria
+	}
+	n, err := s.cfg.Index.Forget(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to forget memories: %w", err)
+	}
+	return &memory.ForgetResponse{Forgotten: n}, nil
+}
+
+func (s *service) DeleteExpired(ctx context.Context) (int, error) {
+	n, err := s.cfg.Index.DeleteExpired(ctx, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired memories: %w", err)
+	}
+	return n, nil
+}
+
+func (s *service) Reembed(ctx context.Context) (int, error) {
+	name := s.cfg.Embedder.Name()
+	var stale []*Record
+	for r, err := range s.cfg.Index.All(ctx) {
+		if err != nil {
+			return 0, fmt.Errorf("failed to list index: %w", err)
+		}
+		if r.Embedder != name {
+			reembedded := *r
+			stale = append(stale, &reembedded)
+		}
+	}
+
+	for start := 0; start < len(stale); start += reembedBatchSize {
+		batch := stale[start:min(start+reembedBatchSize, len(stale))]
+		if err := s.embed(ctx, batch); err != nil {
+			return start, err
+		}
+		if err := s.cfg.Index.Update(ctx, batch); err != nil {
+			return start, fmt.Errorf("failed to update index: %w", err)
+		}
+	}
+	return len(stale), nil
+}
+
+// embed sets the vectors of the records.
+func (s *service) embed(ctx context.Context, records []*Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+	texts := make([]string, len(records))
+	for i, r := range records {
+		texts[i] = r.Text
+	}
+	vectors, err := s.cfg.Embedder.Embed(ctx, texts, PurposeDocument)
+	if err != nil {
+		return fmt.Errorf("failed to embed chunks: %w", err)
+	}
+	if len(vectors) != len(records) {
+		return fmt.Errorf("embedder returned %d embeddings for %d chunks", len(vectors), len(records))
+	}
+	name := s.cfg.Embedder.Name()
+	for i, r := range records {
+		r.Embedder = name
+		r.Vector = vectors[i]
+	}
+	return nil
+}
+
+// chunks splits the text into chunks of at most ChunkSize words, consecutive
+// chunks sharing ChunkOverlap words.
+func (s *service) chunks(text string) []string {
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return nil
+	}
+	if len(words) <= s.cfg.ChunkSize {
+		return []string{strings.TrimSpace(text)}
+	}
+
+	var chunks []string
+	step := s.cfg.ChunkSize - s.cfg.ChunkOverlap
+	for start := 0; ; start += step {
+		end := min(start+s.cfg.ChunkSize, len(words))
+		chunks = append(chunks, strings.Join(words[start:end], " "))
+		if end == len(words) {
+			return chunks
+		}
+	}
+}
@@ -0,0 +1,510 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vector_test
+
+import (
+	"context"
+	"hash/fnv"
+	"iter"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/memory"
+	"google.golang.org/adk/memory/memorytest"
+	"google.golang.org/adk/memory/vector"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+)
+
+// ignoreScore ignores the similarities, checked by TestService_Search_Scores.
+var ignoreScore = cmpopts.IgnoreFields(memory.Entry{}, "Score")
+
+// conceptEmbedder embeds the texts as the number of words of each concept
+// they contain, which makes synonyms similar.
+type conceptEmbedder struct {
+	name string
+	// reversed lays the concepts out in reverse order, as another model
+	// would lay them out differently.
+	reversed bool
+}
+
+var concepts = map[string]int{
+	"car": 0, "automobile": 0, "vehicle": 0,
+	"dog": 1, "puppy": 1, "canine": 1,
+	"pizza": 2, "food": 2, "meal": 2,
+}
+
+func (e *conceptEmbedder) Name() string {
+	return e.name
+}
+
+func (e *conceptEmbedder) Embed(ctx context.Context, texts []string, purpose vector.Purpose) ([][]float32, error) {
+	var vectors [][]float32
+	for _, text := range texts {
+		v := make([]float32, 3)
+		for _, word := range strings.Fields(strings.ToLower(text)) {
+			if i, ok := concepts[strings.Trim(word, ".,?!")]; ok {
+				if e.reversed {
+					i = len(v) - 1 - i
+				}
+				v[i]++
+			}
+		}
+		vectors = append(vectors, v)
+	}
+	return vectors, nil
+}
+
+// wordEmbedder embeds the texts as the number of occurrences of their words,
+// hashed to the dimensions of the vectors, so that only the texts sharing a
+// word are similar, as the conformance suite expects.
+type wordEmbedder struct{}
+
+func (wordEmbedder) Name() string {
+	return "words"
+}
+
+func (wordEmbedder) Embed(ctx context.Context, texts []string, purpose vector.Purpose) ([][]float32, error) {
+	var vectors [][]float32
+	for _, text := range texts {
+		v := make([]float32, 1024)
+		for _, word := range strings.Fields(strings.ToLower(text)) {
+			h := fnv.New32a()
+			h.Write([]byte(word))
+			v[h.Sum32()%uint32(len(v))]++
+		}
+		vectors = append(vectors, v)
+	}
+	return vectors, nil
+}
+
+func TestService_Conformance(t *testing.T) {
+	memorytest.RunConformance(t, func(t *testing.T) memory.Service {
+		s, err := vector.NewService(vector.Config{Embedder: wordEmbedder{}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	})
+}
+
+func addSessions(t *testing.T, s memory.Service) {
+	t.Helper()
+	for _, sess := range []session.Session{
+		makeSession("app", "user", "s1", []*session.Event{
+			makeEvent("e1", "user", "I bought a new car yesterday.", genai.RoleUser, 1),
+			makeEvent("e2", "bot", "Your puppy will love riding in it!", genai.RoleModel, 2),
+		}),
+		makeSession("app", "user", "s2", []*session.Event{
+			makeEvent("e3", "user", "We ordered pizza.", genai.RoleUser, 3),
+			{ID: "e4", Author: "bot"},
+		}),
+		makeSession("app", "other", "s3", []*session.Event{
+			makeEvent("e5", "other", "My car broke down.", genai.RoleUser, 4),
+		}),
+	} {
+		if err := s.AddSession(t.Context(), sess); err != nil {
+			t.Fatalf("AddSession() failed: %v", err)
+		}
+	}
+}
+
+func TestService_Search(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		cfg   vector.Config
+		query string
+		// after and before are the minutes of the time range of the
+		// search, if set.
+		after, before int
+		want          []memory.Entry
+	}{
+		{
+			name:  "synonym",
+			query: "Which automobile did I buy?",
+			want:  []memory.Entry{entry("e1", "s1", "user", "I bought a new car yesterday.", genai.RoleUser, 1)},
+		},
+		{
+			name:  "ranked by similarity",
+			query: "dog and dog food",
+			want: []memory.Entry{
+				entry("e2", "s1", "bot", "Your puppy will love riding in it!", genai.RoleModel, 2),
+				entry("e3", "s2", "user", "We ordered pizza.", genai.RoleUser, 3),
+			},
+		},
+		{
+			name:  "top k",
+			cfg:   vector.Config{TopK: 1},
+			query: "dog and dog food",
+			want:  []memory.Entry{entry("e2", "s1", "bot", "Your puppy will love riding in it!", genai.RoleModel, 2)},
+		},
+		{
+			name:   "time range",
+			query:  "dog and dog food",
+			after:  3,
+			before: 4,
+			want:   []memory.Entry{entry("e3", "s2", "user", "We ordered pizza.", genai.RoleUser, 3)},
+		},
+		{
+			name:  "min score",
+			cfg:   vector.Config{MinScore: 0.9},
+			query: "dog and dog food",
+		},
+		{
+			name:  "no match",
+			query: "the weather",
+		},
+		{
+			name: "empty query",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.cfg.Embedder = &conceptEmbedder{name: "concepts"}
+			s, err := vector.NewService(tc.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			addSessions(t, s)
+
+			req := &memory.SearchRequest{AppName: "app", UserID: "user", Query: tc.query}
+			if tc.after != 0 {
+				req.After, req.Before = timestamp(tc.after), timestamp(tc.before)
+			}
+			got, err := s.Search(t.Context(), req)
+			if err != nil {
+				t.Fatalf("Search() failed: %v", err)
+			}
+			if diff := cmp.Diff(&memory.SearchResponse{Memories: tc.want}, got, ignoreScore); diff != "" {
+				t.Errorf("Search() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestService_Search_Scores(t *testing.T) {
+	s, err := vector.NewService(vector.Config{Embedder: &conceptEmbedder{name: "concepts"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	addSessions(t, s)
+
+	got, err := s.Search(t.Context(), &memory.SearchRequest{AppName: "app", UserID: "user", Query: "dog and dog food"})
+	if err != nil {
+		t.Fatalf("Search() failed: %v", err)
+	}
+	if len(got.Memories) == 0 {
+		t.Fatal("Search() found no memories")
+	}
+	for i, m := range got.Memories {
+		if m.Score <= 0 || m.Score > 1+1e-6 {
+			t.Errorf("Memories[%d].Score = %v, want in (0, 1]", i, m.Score)
+		}
+		if i > 0 && m.Score > got.Memories[i-1].Score {
+			t.Errorf("Memories[%d].Score = %v, want at most %v", i, m.Score, got.Memories[i-1].Score)
+		}
+	}
+}
+
+func TestService_AddSession_Replaces(t *testing.T) {
+	s, err := vector.NewService(vector.Config{Embedder: &conceptEmbedder{name: "concepts"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	addSessions(t, s)
+	updated := makeSession("app", "user", "s1", []*session.Event{
+		makeEvent("e6", "user", "I sold my car.", genai.RoleUser, 5),
+	})
+	if err := s.AddSession(t.Context(), updated); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := s.Search(t.Context(), &memory.SearchRequest{AppName: "app", UserID: "user", Query: "car"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &memory.SearchResponse{Memories: []memory.Entry{entry("e6", "s1", "user", "I sold my car.", genai.RoleUser, 5)}}
+	if diff := cmp.Diff(want, got, ignoreScore); diff != "" {
+		t.Errorf("Search() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestService_AddEvents(t *testing.T) {
+	s, err := vector.NewService(vector.Config{Embedder: &conceptEmbedder{name: "concepts"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	addSessions(t, s)
+	added := makeEvent("e6", "user", "Then I washed the car.", genai.RoleUser, 5)
+	sess := makeSession("app", "user", "s1", []*session.Event{
+		makeEvent("e1", "user", "I bought a new car yesterday.", genai.RoleUser, 1),
+		makeEvent("e2", "bot", "Your puppy will love riding in it!", genai.RoleModel, 2),
+		added,
+	})
+	if err := s.(memory.EventAdder).AddEvents(t.Context(), sess, []*session.Event{added}); err != nil {
+		t.Fatalf("AddEvents() failed: %v", err)
+	}
+
+	got, err := s.Search(t.Context(), &memory.SearchRequest{AppName: "app", UserID: "user", Query: "car"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &memory.SearchResponse{Memories: []memory.Entry{
+		entry("e1", "s1", "user", "I bought a new car yesterday.", genai.RoleUser, 1),
+		entry("e6", "s1", "user", "Then I washed the car.", genai.RoleUser, 5),
+	}}
+	if diff := cmp.Diff(want, got, ignoreScore); diff != "" {
+		t.Errorf("Search() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestService_Chunks(t *testing.T) {
+	index := vector.InMemoryIndex()
+	s, err := vector.NewService(vector.Config{
+		Embedder:     &conceptEmbedder{name: "concepts"},
+		Index:        index,
+		ChunkSize:    3,
+		ChunkOverlap: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddSession(t.Context(), makeSession("app", "user", "s1", []*session.Event{
+		makeEvent("e1", "user", "one two\nthree four five six", genai.RoleUser, 1),
+	})); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for r, err := range index.All(t.Context()) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, r.Text)
+	}
+	want := []string{"one two three", "three four five", "five six"}
+	if diff := cmp.Diff(want, got, ignoreScore); diff != "" {
+		t.Errorf("chunks mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestService_Reembed(t *testing.T) {
+	index := vector.InMemoryIndex()
+	old, err := vector.NewService(vector.Config{Embedder: &conceptEmbedder{name: "v1"}, Index: index})
+	if err != nil {
+		t.Fatal(err)
+	}
+	addSessions(t, old)
+
+	s, err := vector.NewService(vector.Config{Embedder: &conceptEmbedder{name: "v2", reversed: true}, Index: index})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &memory.SearchRequest{AppName: "app", UserID: "user", Query: "automobile"}
+	got, err := s.Search(t.Context(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Memories) != 0 {
+		t.Errorf("Search() before Reembed() = %v, want no memories", got.Memories)
+	}
+
+	n, err := s.Reembed(t.Context())
+	if err != nil {
+		t.Fatalf("Reembed() failed: %v", err)
+	}
+	if n != 4 {
+		t.Errorf("Reembed() = %d, want 4", n)
+	}
+	got, err = s.Search(t.Context(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &memory.SearchResponse{Memories: []memory.Entry{entry("e1", "s1", "user", "I bought a new car yesterday.", genai.RoleUser, 1)}}
+	if diff := cmp.Diff(want, got, ignoreScore); diff != "" {
+		t.Errorf("Search() after Reembed() mismatch (-want +got):\n%s", diff)
+	}
+
+	if n, err := s.Reembed(t.Context()); err != nil || n != 0 {
+		t.Errorf("second Reembed() = (%d, %v), want (0, nil)", n, err)
+	}
+}
+
+func TestService_DeleteExpired(t *testing.T) {
+	index := vector.InMemoryIndex()
+	s, err := vector.NewService(vector.Config{Embedder: &conceptEmbedder{name: "concepts"}, Index: index, TTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	forever := makeEvent("e3", "user", "My dog is a puppy forever.", genai.RoleUser, 0)
+	forever.Timestamp = now.Add(-2 * time.Hour)
+	forever.CustomMetadata = map[string]any{memory.TTLKey: "0"}
+	sess := makeSession("app", "user", "s1", []*session.Event{
+		makeEvent("e1", "user", "My dog is a puppy.", genai.RoleUser, 0),
+		makeEvent("e2", "user", "My dog is a canine.", genai.RoleUser, 0),
+		forever,
+	})
+	sess.Events().At(0).Timestamp = now.Add(-2 * time.Hour)
+	sess.Events().At(1).Timestamp = now.Add(-time.Minute)
+	if err := s.AddSession(t.Context(), sess); err != nil {
+		t.Fatalf("AddSession() failed: %v", err)
+	}
+
+	searchIDs := func() []string {
+		t.Helper()
+		got, err := s.Search(t.Context(), &memory.SearchRequest{AppName: "app", UserID: "user", Query: "dog"})
+		if err != nil {
+			t.Fatalf("Search() failed: %v", err)
+		}
+		var ids []string
+		for _, m := range got.Memories {
+			ids = append(ids, m.EventID)
+		}
+		slices.Sort(ids)
+		return ids
+	}
+	countRecords := func() int {
+		t.Helper()
+		n := 0
+		for _, err := range index.All(t.Context()) {
+			if err != nil {
+				t.Fatalf("All() failed: %v", err)
+			}
+			n++
+		}
+		return n
+	}
+
+	if diff := cmp.Diff([]string{"e2", "e3"}, searchIDs()); diff != "" {
+		t.Errorf("Search() before DeleteExpired() mismatch (-want +got):\n%s", diff)
+	}
+	if got := countRecords(); got != 3 {
+		t.Errorf("index has %d records before DeleteExpired(), want 3", got)
+	}
+	n, err := s.DeleteExpired(t.Context())
+	if err != nil {
+		t.Fatalf("DeleteExpired() failed: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("DeleteExpired() = %d, want 1", n)
+	}
+	if got := countRecords(); got != 2 {
+		t.Errorf("index has %d records after DeleteExpired(), want 2", got)
+	}
+	if diff := cmp.Diff([]string{"e2", "e3"}, searchIDs()); diff != "" {
+		t.Errorf("Search() after DeleteExpired() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestNewService_Errors(t *testing.T) {
+	embedder := &conceptEmbedder{name: "concepts"}
+	for _, tc := range []struct {
+		name string
+		cfg  vector.Config
+	}{
+		{"no embedder", vector.Config{}},
+		{"negative top k", vector.Config{Embedder: embedder, TopK: -1}},
+		{"min score out of range", vector.Config{Embedder: embedder, MinScore: 1.5}},
+		{"negative chunk size", vector.Config{Embedder: embedder, ChunkSize: -1}},
+		{"overlap too large", vector.Config{Embedder: embedder, ChunkSize: 4, ChunkOverlap: 4}},
+		{"negative ttl", vector.Config{Embedder: embedder, TTL: -time.Hour}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := vector.NewService(tc.cfg); err == nil {
+				t.Errorf("NewService() succeeded, want an error")
+			}
+		})
+	}
+}
+
+func timestamp(i int) time.Time {
+	return time.Date(2025, 1, 1, 0, i, 0, 0, time.UTC)
+}
+
+func makeEvent(id, author, text string, role genai.Role, minute int) *session.Event {
+	return &session.Event{
+		ID:          id,
+		Author:      author,
+		LLMResponse: model.LLMResponse{Content: genai.NewContentFromText(text, role)},
+		Timestamp:   timestamp(minute),
+	}
+}
+
+func entry(eventID, sessionID, author, text string, role genai.Role, minute int) memory.Entry {
+	return memory.Entry{
+		Content:   genai.NewContentFromText(text, role),
+		Author:    author,
+		Timestamp: timestamp(minute),
+		SessionID: sessionID,
+		EventID:   eventID,
+		ID:        sessionID + "/" + eventID + "/0",
+	}
+}
+
+func makeSession(appName, userID, sessionID string, events []*session.Event) session.Session {
+	return &testSession{
+		appName:   appName,
+		userID:    userID,
+		sessionID: sessionID,
+		events:    events,
+	}
+}
+
+type testSession struct {
+	appName, userID, sessionID string
+	events                     []*session.Event
+}
+
+func (s *testSession) ID() string {
+	return s.sessionID
+}
+
+func (s *testSession) AppName() string {
+	return s.appName
+}
+
+func (s *testSession) UserID() string {
+	return s.userID
+}
+
+func (s *testSession) Events() session.Events {
+	return s
+}
+
+func (s *testSession) All() iter.Seq[*session.Event] {
+	return slices.Values(s.events)
+}
+
+func (s *testSession) Len() int {
+	return len(s.events)
+}
+
+func (s *testSession) At(i int) *session.Event {
+	return s.events[i]
+}
+
+func (s *testSession) State() session.State {
+	panic("not implemented")
+}
+
+func (s *testSession) LastUpdateTime() time.Time {
+	panic("not implemented")
+}
@@ -0,0 +1,289 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package anthropic implements the [model.LLM] interface for Anthropic Claude
+// models, with the Messages API.
+//
+// The contents, function declarations and generation config of the requests
+// are translated to Anthropic messages, and the responses back to genai
+// contents, so the same agents run against Claude and Gemini. Streamed
+// responses yield partial text responses followed by the aggregated one,
+// like the Gemini models.
+package anthropic
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/internal/llminternal"
+	"google.golang.org/adk/internal/version"
+	"google.golang.org/adk/model"
+)
+
+const (
+	// DefaultBaseURL is the default base URL of the Anthropic API.
+	DefaultBaseURL = "https://api.anthropic.com"
+	// DefaultMaxTokens is the default maximum number of tokens generated by
+	// the requests, which the Messages API requires.
+	DefaultMaxTokens = 4096
+
+	// apiVersion is the version of the Messages API the package implements.
+	apiVersion = "2023-06-01"
+)
+
+// Config is the configuration of an Anthropic model.
+type Config struct {
+	// APIKey authenticates the requests. Defaults to the ANTHROPIC_API_KEY
+	// environment variable.
+	APIKey string
+	// BaseURL is the base URL of the API. Defaults to [DefaultBaseURL].
+	BaseURL string
+	// HTTPClient sends the requests. Defaults to [http.DefaultClient].
+	HTTPClient *http.Client
+	// MaxTokens is the maximum number of tokens generated by the requests
+	// whose config doesn't set MaxOutputTokens. Defaults to
+	// [DefaultMaxTokens].
+	MaxTokens int
+}
+
+type anthropicModel struct {
+	name string
+	cfg  Config
+}
+
+// NewModel returns [model.LLM], backed by the Anthropic Messages API.
+//
+// The modelName specifies which Claude model to target (e.g.,
+// "claude-sonnet-4-5"). An error is returned if no API key is configured.
+func NewModel(ctx context.Context, modelName string, cfg *Config) (model.LLM, error) {
+	var c Config
+	if cfg != nil {
+		c = *cfg
+	}
+	if c.APIKey == "" {
+		c.APIKey = os.Getenv("ANTHROPIC_API_KEY")
+	}
+	if c.APIKey == "" {
+		return nil, errors.New("anthropic API key is not set")
+	}
+	if c.BaseURL == "" {
+		c.BaseURL = DefaultBaseURL
+	}
+	c.BaseURL = strings.TrimSuffix(c.BaseURL, "/")
+	if c.HTTPClient == nil {
+		c.HTTPClient = http.DefaultClient
+	}
+	if c.MaxTokens < 0 {
+		return nil, fmt.Errorf("max tokens must not be negative, got %d", c.MaxTokens)
+	}
+	if c.MaxTokens == 0 {
+		c.MaxTokens = DefaultMaxTokens
+	}
+	return &anthropicModel{name: modelName, cfg: c}, nil
+}
+
+func (m *anthropicModel) Name() string {
+	return m.name
+}
+
+// extendedThinkingModel matches the names of the Claude models supporting
+// extended thinking: Claude 3.7 Sonnet and the Claude 4+ models.
+var extendedThinkingModel = regexp.MustCompile(`^claude-(3-7-|(opus|sonnet|haiku)-([4-9]|[1-9]\d)\b|([4-9]|[1-9]\d)\b)`)
+
+// SupportsThinking reports whether the model supports extended thinking.
+func (m *anthropicModel) SupportsThinking() bool {
+	return extendedThinkingModel.MatchString(m.name)
+}
+
+// GenerateContent calls the underlying model.
+func (m *anthropicModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		body, err := newMessageRequest(m.name, req, m.cfg.MaxTokens, stream)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		resp, err := m.send(ctx, body)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		defer resp.Body.Close()
+
+		if !stream {
+			var msg messageResponse
+			if err := json.NewDecoder(resp.Body).Decode(&msg); err != nil {
+				yield(nil, fmt.Errorf("failed to decode model response: %w", err))
+				return
+			}
+			llmResponse, err := msg.llmResponse()
+			yield(llmResponse, err)
+			return
+		}
+
+		aggregator := llminternal.NewStreamingResponseAggregator()
+		for genResp, err := range readStream(resp.Body) {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for llmResponse, err := range aggregator.ProcessResponse(ctx, genResp) {
+				if !yield(llmResponse, err) {
+					return // Consumer stopped
+				}
+			}
+		}
+		if closeResult := aggregator.Close(); closeResult != nil {
+			yield(closeResult, nil)
+		}
+	}
+}
+
+// send posts the request to the Messages API. It returns an error for the
+// unsuccessful responses.
+func (m *anthropicModel) send(ctx context.Context, body *messageRequest) (*http.Response, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode model request: %w", err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, m.cfg.BaseURL+"/v1/messages", bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create model request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("X-Api-Key", m.cfg.APIKey)
+	httpReq.Header.Set("Anthropic-Version", apiVersion)
+	httpReq.Header.Set("User-Agent", version.UserAgent(ctx))
+
+	resp, err := m.cfg.HTTPClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call model: %w", err)
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		apiErr := newAPIError(resp.StatusCode, data)
+		apiErr.RetryDelay = model.ParseRetryAfter(resp.Header)
+		return nil, apiErr
+	}
+	return resp, nil
+}
+
+// APIError is an error returned by the Anthropic API.
+type APIError struct {
+	// StatusCode is the HTTP status code of the response, zero for the
+	// errors of a stream.
+	StatusCode int
+	// Type is the type of the error, e.g. "overloaded_error".
+	Type    string
+	Message string
+	// RetryDelay is the delay of the Retry-After header of the response,
+	// zero when it has none.
+	RetryDelay time.Duration
+}
+
+func (e *APIError) Error() string {
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("anthropic API error %s: %s", e.Type, e.Message)
+	}
+	return fmt.Sprintf("anthropic API error %d %s: %s", e.StatusCode, e.Type, e.Message)
+}
+
+// RetryAfter returns the delay to wait before retrying, zero when the
+// response didn't ask for any.
+func (e *APIError) RetryAfter() time.Duration {
+	return e.RetryDelay
+}
+
+// HTTPStatusCode returns the HTTP status code of the error. For the errors
+// of a stream, it is the status code of the error type.
+func (e *APIError) HTTPStatusCode() int {
+	if e.StatusCode != 0 {
+		return e.StatusCode
+	}
+	switch e.Type {
+	case "rate_limit_error":
+		return http.StatusTooManyRequests
+	case "api_error":
+		return http.StatusInternalServerError
+	case "overloaded_error":
+		return 529
+	}
+	return 0
+}
+
+// newAPIError returns the error of an unsuccessful response with the body.
+func newAPIError(statusCode int, body []byte) *APIError {
+	var resp struct {
+		Error apiError `json:"error"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil || resp.Error.Message == "" {
+		return &APIError{StatusCode: statusCode, Type: http.StatusText(statusCode), Message: strings.TrimSpace(string(body))}
+	}
+	return &APIError{StatusCode: statusCode, Type: resp.Error.Type, Message: resp.Error.Message}
+}
+
+// readStream reads the server-sent events of a streamed response, and
+// yields the genai responses of their contents.
+func readStream(r io.Reader) iter.Seq2[*genai.GenerateContentResponse, error] {
+	return func(yield func(*genai.GenerateContentResponse, error) bool) {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), 16<<20)
+		s := &streamState{}
+		for scanner.Scan() {
+			data, ok := strings.CutPrefix(scanner.Text(), "data:")
+			if !ok {
+				continue
+			}
+			var ev streamEvent
+			if err := json.Unmarshal([]byte(strings.TrimSpace(data)), &ev); err != nil {
+				yield(nil, fmt.Errorf("failed to decode stream event: %w", err))
+				return
+			}
+			responses, err := s.handle(&ev)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, resp := range responses {
+				if !yield(resp, nil) {
+					return
+				}
+			}
+			if ev.Type == "message_stop" {
+				break
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			yield(nil, fmt.Errorf("failed to read stream: %w", err))
+			return
+		}
+		if resp := s.flush(); resp != nil {
+			yield(resp, nil)
+		}
+	}
+}
@@ -0,0 +1,600 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package anthropic
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/internal/httprr"
+	"google.golang.org/adk/internal/version"
+	"google.golang.org/adk/model"
+)
+
+//go:generate go test -httprecord=testdata/.*\.httprr
+
+const testModelName = "claude-sonnet-4-5"
+
+func TestModel_Generate(t *testing.T) {
+	weatherTool := &genai.Tool{FunctionDeclarations: []*genai.FunctionDeclaration{{
+		Name:        "get_weather",
+		Description: "Returns the current weather of a city.",
+		Parameters: &genai.Schema{
+			Type:       genai.TypeObject,
+			Properties: map[string]*genai.Schema{"city": {Type: genai.TypeString}},
+			Required:   []string{"city"},
+		},
+	}}}
+	tests := []struct {
+		name    string
+		req     *model.LLMRequest
+		want    *model.LLMResponse
+		wantErr bool
+	}{
+		{
+			name: "ok",
+			req: &model.LLMRequest{
+				Contents: genai.Text("What is the capital of France? One word."),
+				Config: &genai.GenerateContentConfig{
+					SystemInstruction: genai.NewContentFromText("You answer geography questions.", genai.RoleUser),
+					Temperature:       new(float32),
+				},
+			},
+			want: &model.LLMResponse{
+				Content: genai.NewContentFromText("Paris", genai.RoleModel),
+				UsageMetadata: &genai.GenerateContentResponseUsageMetadata{
+					CandidatesTokenCount: 4,
+					PromptTokenCount:     24,
+					TotalTokenCount:      28,
+				},
+				FinishReason: genai.FinishReasonStop,
+			},
+		},
+		{
+			name: "tool_use",
+			req: &model.LLMRequest{
+				Contents: genai.Text("What is the weather in Paris?"),
+				Config:   &genai.GenerateContentConfig{Tools: []*genai.Tool{weatherTool}},
+			},
+			want: &model.LLMResponse{
+				Content: &genai.Content{Role: genai.RoleModel, Parts: []*genai.Part{
+					{Text: "I'll check the weather in Paris."},
+					{FunctionCall: &genai.FunctionCall{ID: "toolu_01A09q90qw90lq917835lq9", Name: "get_weather", Args: map[string]any{"city": "Paris"}}},
+				}},
+				UsageMetadata: &genai.GenerateContentResponseUsageMetadata{
+					CandidatesTokenCount: 57,
+					PromptTokenCount:     392,
+					TotalTokenCount:      449,
+				},
+				FinishReason: genai.FinishReasonStop,
+			},
+		},
+		{
+			name: "tool_result",
+			req: &model.LLMRequest{
+				Contents: []*genai.Content{
+					genai.NewContentFromText("What is the weather in Paris?", genai.RoleUser),
+					genai.NewContentFromFunctionCall("get_weather", map[string]any{"city": "Paris"}, genai.RoleModel),
+					{Role: genai.RoleUser, Parts: []*genai.Part{{FunctionResponse: &genai.FunctionResponse{
+						ID:       "toolu_01A09q90qw90lq917835lq9",
+						Name:     "get_weather",
+						Response: map[string]any{"weather": "sunny, 22°C"},
+					}}}},
+				},
+				Config: &genai.GenerateContentConfig{Tools: []*genai.Tool{weatherTool}},
+			},
+			want: &model.LLMResponse{
+				Content: genai.NewContentFromText("It is sunny and 22°C in Paris.", genai.RoleModel),
+				UsageMetadata: &genai.GenerateContentResponseUsageMetadata{
+					CandidatesTokenCount:    15,
+					PromptTokenCount:        480,
+					CachedContentTokenCount: 400,
+					TotalTokenCount:         495,
+				},
+				FinishReason: genai.FinishReasonStop,
+			},
+		},
+		{
+			name: "max_tokens",
+			req: &model.LLMRequest{
+				Contents: genai.Text("Write a poem about the sea."),
+				Config:   &genai.GenerateContentConfig{MaxOutputTokens: 8},
+			},
+			want: &model.LLMResponse{
+				Content: genai.NewContentFromText("The sea, the endless", genai.RoleModel),
+				UsageMetadata: &genai.GenerateContentResponseUsageMetadata{
+					CandidatesTokenCount: 8,
+					PromptTokenCount:     14,
+					TotalTokenCount:      22,
+				},
+				FinishReason: genai.FinishReasonMaxTokens,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpRecordFilename := filepath.Join("testdata", strings.ReplaceAll(t.Name(), "/", "_")+".httprr")
+
+			testModel, err := NewModel(t.Context(), testModelName, newTestConfig(t, httpRecordFilename))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for got, err := range testModel.GenerateContent(t.Context(), tt.req, false) {
+				if (err != nil) != tt.wantErr {
+					t.Errorf("Model.Generate() error = %v, wantErr %v", err, tt.wantErr)
+					return
+				}
+				if diff := cmp.Diff(tt.want, got); diff != "" {
+					t.Errorf("Model.Generate() = %v, want %v\ndiff(-want +got):\n%v", got, tt.want, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestModel_Generate_APIError(t *testing.T) {
+	httpRecordFilename := filepath.Join("testdata", "TestModel_Generate_api_error.httprr")
+	testModel, err := NewModel(t.Context(), testModelName, newTestConfig(t, httpRecordFilename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &model.LLMRequest{
+		Contents: genai.Text("Hello"),
+		Config:   &genai.GenerateContentConfig{Temperature: genai.Ptr[float32](3)},
+	}
+	for _, err := range testModel.GenerateContent(t.Context(), req, false) {
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("Model.Generate() error = %v, want an *APIError", err)
+		}
+		want := &APIError{StatusCode: http.StatusBadRequest, Type: "invalid_request_error", Message: "temperature: range: 0..1"}
+		if diff := cmp.Diff(want, apiErr); diff != "" {
+			t.Errorf("Model.Generate() error mismatch (-want +got):\n%s", diff)
+		}
+	}
+}
+
+func TestModel_GenerateStream(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     *model.LLMRequest
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "ok",
+			req: &model.LLMRequest{
+				Contents: genai.Text("What is the capital of France? Answer in a sentence."),
+				Config: &genai.GenerateContentConfig{
+					Temperature: new(float32),
+				},
+			},
+			want: "The capital of France is Paris.",
+		},
+		{
+			name: "overloaded",
+			req: &model.LLMRequest{
+				Contents: genai.Text("What is the capital of Italy? Answer in a sentence."),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpRecordFilename := filepath.Join("testdata", strings.ReplaceAll(t.Name(), "/", "_")+".httprr")
+
+			model, err := NewModel(t.Context(), testModelName, newTestConfig(t, httpRecordFilename))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Transforms the stream into strings, concatenating the text value of the response parts
+			got, err := readResponse(model.GenerateContent(t.Context(), tt.req, true))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Model.GenerateStream() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if diff := cmp.Diff(tt.want, got.PartialText); diff != "" {
+				t.Errorf("Model.GenerateStream() = %v, want %v\ndiff(-want +got):\n%v", got.PartialText, tt.want, diff)
+			}
+			if tt.wantErr {
+				return
+			}
+			// Since we are expecting GenerateStream to aggregate partial events, the text should be the same
+			if diff := cmp.Diff(tt.want, got.FinalText); diff != "" {
+				t.Errorf("Model.GenerateStream() = %v, want %v\ndiff(-want +got):\n%v", got.FinalText, tt.want, diff)
+			}
+		})
+	}
+}
+
+func TestModel_GenerateStream_ToolUse(t *testing.T) {
+	httpRecordFilename := filepath.Join("testdata", strings.ReplaceAll(t.Name(), "/", "_")+".httprr")
+
+	testModel, err := NewModel(t.Context(), testModelName, newTestConfig(t, httpRecordFilename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &model.LLMRequest{
+		Contents: genai.Text("What is the weather in Paris and in Rome?"),
+		Config: &genai.GenerateContentConfig{
+			Tools: []*genai.Tool{{FunctionDeclarations: []*genai.FunctionDeclaration{{
+				Name:                 "get_weather",
+				Description:          "Returns the current weather of a city.",
+				ParametersJsonSchema: map[string]any{"type": "object", "properties": map[string]any{"city": map[string]any{"type": "string"}}},
+			}}}},
+		},
+	}
+
+	var got []*model.LLMResponse
+	for resp, err := range testModel.GenerateContent(t.Context(), req, true) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, resp)
+	}
+
+	usage := &genai.GenerateContentResponseUsageMetadata{
+		CandidatesTokenCount: 89,
+		PromptTokenCount:     398,
+		TotalTokenCount:      487,
+	}
+	want := []*model.LLMResponse{
+		{Content: genai.NewContentFromText("Let me check ", genai.RoleModel), Partial: true},
+		{Content: genai.NewContentFromText("both cities.", genai.RoleModel), Partial: true},
+		// The aggregated text carries the finish reason of the response ending
+		// it, the usage of the call is only reported by that response.
+		{
+			Content:      genai.NewContentFromText("Let me check both cities.", genai.RoleModel),
+			FinishReason: genai.FinishReasonStop,
+		},
+		{
+			Content: &genai.Content{Role: genai.RoleModel, Parts: []*genai.Part{
+				{FunctionCall: &genai.FunctionCall{ID: "toolu_01", Name: "get_weather", Args: map[string]any{"city": "Paris"}}},
+				{FunctionCall: &genai.FunctionCall{ID: "toolu_02", Name: "get_weather", Args: map[string]any{"city": "Rome"}}},
+			}},
+			UsageMetadata: usage,
+			FinishReason:  genai.FinishReasonStop,
+			TurnComplete:  true,
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Model.GenerateStream() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestModel_TrackingHeaders(t *testing.T) {
+	t.Run("verifies_headers_are_set", func(t *testing.T) {
+		httpRecordFilename := filepath.Join("testdata", strings.ReplaceAll(t.Name(), "/", "_")+".httprr")
+
+		cfg := newTestConfig(t, httpRecordFilename)
+		headersChecked := false
+		cfg.HTTPClient.Transport = &headerInterceptor{
+			base: cfg.HTTPClient.Transport,
+			check: func(req *http.Request) {
+				headersChecked = true
+				if ua := req.Header.Get("User-Agent"); !strings.Contains(ua, "google-adk/") || !strings.Contains(ua, "gl-go/") {
+					t.Errorf("User-Agent header should contain both 'google-adk/' and 'gl-go/', but got: %q", ua)
+				}
+				if ua := req.Header.Get("User-Agent"); !strings.HasSuffix(ua, " adk-test/1.0") {
+					t.Errorf("User-Agent header should end with the product of the context, but got: %q", ua)
+				}
+				if v := req.Header.Get("Anthropic-Version"); v != apiVersion {
+					t.Errorf("Anthropic-Version header = %q, want %q", v, apiVersion)
+				}
+				if key := req.Header.Get("X-Api-Key"); key == "" {
+					t.Error("X-Api-Key header is not set")
+				}
+			},
+		}
+
+		anthropicModel, err := NewModel(t.Context(), testModelName, cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req := &model.LLMRequest{Contents: genai.Text("ping")}
+		ctx := version.WithUserAgent(t.Context(), "adk-test/1.0")
+		for _, err := range anthropicModel.GenerateContent(ctx, req, false) {
+			if err != nil {
+				t.Logf("GenerateContent finished with error (expected if no recording exists): %v", err)
+			}
+		}
+
+		if !headersChecked {
+			t.Error("HTTP request was not intercepted; headers not verified")
+		}
+	})
+}
+
+func TestNewModel(t *testing.T) {
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	if _, err := NewModel(t.Context(), testModelName, nil); err == nil {
+		t.Error("NewModel() without API key succeeded, want error")
+	}
+	if _, err := NewModel(t.Context(), testModelName, &Config{APIKey: "key", MaxTokens: -1}); err == nil {
+		t.Error("NewModel() with negative max tokens succeeded, want error")
+	}
+
+	t.Setenv("ANTHROPIC_API_KEY", "envkey")
+	m, err := NewModel(t.Context(), testModelName, &Config{BaseURL: "http://localhost:8080/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Config{APIKey: "envkey", BaseURL: "http://localhost:8080", HTTPClient: http.DefaultClient, MaxTokens: DefaultMaxTokens}
+	if diff := cmp.Diff(want, m.(*anthropicModel).cfg); diff != "" {
+		t.Errorf("NewModel() config mismatch (-want +got):\n%s", diff)
+	}
+	if got := m.Name(); got != testModelName {
+		t.Errorf("Name() = %q, want %q", got, testModelName)
+	}
+}
+
+func TestNewMessageRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     *model.LLMRequest
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "empty",
+			req:  &model.LLMRequest{},
+			want: `{"model":"m","max_tokens":100,"messages":[{"role":"user","content":[{"type":"text","text":"` + userStart + `"}]}]}`,
+		},
+		{
+			name: "model_last",
+			req: &model.LLMRequest{Contents: []*genai.Content{
+				genai.NewContentFromText("Hi", genai.RoleUser),
+				genai.NewContentFromText("Hello", genai.RoleModel),
+			}},
+			want: `{"model":"m","max_tokens":100,"messages":[` +
+				`{"role":"user","content":[{"type":"text","text":"Hi"}]},` +
+				`{"role":"assistant","content":[{"type":"text","text":"Hello"}]},` +
+				`{"role":"user","content":[{"type":"text","text":"` + userContinuation + `"}]}]}`,
+		},
+		{
+			name: "merged_function_responses",
+			req: &model.LLMRequest{Contents: []*genai.Content{
+				genai.NewContentFromText("Weather?", genai.RoleUser),
+				{Role: genai.RoleModel, Parts: []*genai.Part{
+					{FunctionCall: &genai.FunctionCall{ID: "a", Name: "f"}},
+					{FunctionCall: &genai.FunctionCall{ID: "b", Name: "f", Args: map[string]any{"x": 1}}},
+				}},
+				{Role: genai.RoleUser, Parts: []*genai.Part{{FunctionResponse: &genai.FunctionResponse{ID: "a", Name: "f", Response: map[string]any{"result": "ok"}}}}},
+				{Role: genai.RoleUser, Parts: []*genai.Part{{FunctionResponse: &genai.FunctionResponse{ID: "b", Name: "f", Response: map[string]any{"error": "failed"}}}}},
+			}},
+			want: `{"model":"m","max_tokens":100,"messages":[` +
+				`{"role":"user","content":[{"type":"text","text":"Weather?"}]},` +
+				`{"role":"assistant","content":[{"type":"tool_use","id":"a","name":"f","input":{}},{"type":"tool_use","id":"b","name":"f","input":{"x":1}}]},` +
+				`{"role":"user","content":[{"type":"tool_result","tool_use_id":"a","content":"{\"result\":\"ok\"}"},{"type":"tool_result","tool_use_id":"b","content":"{\"error\":\"failed\"}","is_error":true}]}]}`,
+		},
+		{
+			name: "media_and_thoughts",
+			req: &model.LLMRequest{Contents: []*genai.Content{
+				{Role: genai.RoleUser, Parts: []*genai.Part{
+					{InlineData: &genai.Blob{MIMEType: "image/png", Data: []byte("png")}},
+					{FileData: &genai.FileData{MIMEType: "application/pdf", FileURI: "https://example.com/a.pdf"}},
+				}},
+				{Role: genai.RoleModel, Parts: []*genai.Part{
+					{Text: "unsigned", Thought: true},
+					{Text: "signed", Thought: true, ThoughtSignature: []byte("sig")},
+					{Text: "Done"},
+				}},
+				genai.NewContentFromText("Thanks", genai.RoleUser),
+			}},
+			want: `{"model":"m","max_tokens":100,"messages":[` +
+				`{"role":"user","content":[{"type":"image","source":{"type":"base64","media_type":"image/png","data":"cG5n"}},{"type":"document","source":{"type":"url","url":"https://example.com/a.pdf"}}]},` +
+				`{"role":"assistant","content":[{"type":"thinking","thinking":"signed","signature":"sig"},{"type":"text","text":"Done"}]},` +
+				`{"role":"user","content":[{"type":"text","text":"Thanks"}]}]}`,
+		},
+		{
+			name: "config",
+			req: &model.LLMRequest{
+				Contents: genai.Text("Hi"),
+				Config: &genai.GenerateContentConfig{
+					SystemInstruction: &genai.Content{Parts: []*genai.Part{{Text: "Be brief."}, {Text: "Be kind."}}},
+					MaxOutputTokens:   50,
+					Temperature:       genai.Ptr[float32](0.5),
+					TopP:              genai.Ptr[float32](0.25),
+					TopK:              genai.Ptr[float32](10),
+					StopSequences:     []string{"END"},
+					ThinkingConfig:    &genai.ThinkingConfig{ThinkingBudget: genai.Ptr[int32](1024)},
+					Tools: []*genai.Tool{{FunctionDeclarations: []*genai.FunctionDeclaration{
+						{Name: "f", Description: "Does f."},
+						{Name: "g", Parameters: &genai.Schema{
+							Type: genai.TypeObject,
+							Properties: map[string]*genai.Schema{
+								"n":    {Type: genai.TypeInteger, Nullable: genai.Ptr(true)},
+								"tags": {Type: genai.TypeArray, Items: &genai.Schema{Type: genai.TypeString, Enum: []string{"a", "b"}}},
+							},
+							Required: []string{"tags"},
+						}},
+					}}},
+					ToolConfig: &genai.ToolConfig{FunctionCallingConfig: &genai.FunctionCallingConfig{
+						Mode:                 genai.FunctionCallingConfigModeAny,
+						AllowedFunctionNames: []string{"g"},
+					}},
+				},
+			},
+			want: `{"model":"m","max_tokens":50,"system":"Be brief.\n\nBe kind.",` +
+				`"messages":[{"role":"user","content":[{"type":"text","text":"Hi"}]}],` +
+				`"tools":[{"name":"f","description":"Does f.","input_schema":{"properties":{},"type":"object"}},` +
+				`{"name":"g","input_schema":{"properties":{"n":{"type":["integer","null"]},"tags":{"items":{"enum":["a","b"],"type":"string"},"type":"array"}},"required":["tags"],"type":"object"}}],` +
+				`"tool_choice":{"type":"tool","name":"g"},"temperature":0.5,"top_p":0.25,"top_k":10,"stop_sequences":["END"],` +
+				`"thinking":{"type":"enabled","budget_tokens":1024}}`,
+		},
+		{
+			name: "unsupported_tool",
+			req: &model.LLMRequest{
+				Contents: genai.Text("Hi"),
+				Config:   &genai.GenerateContentConfig{Tools: []*genai.Tool{{GoogleSearch: &genai.GoogleSearch{}}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unsupported_media",
+			req: &model.LLMRequest{Contents: []*genai.Content{{Role: genai.RoleUser, Parts: []*genai.Part{
+				{InlineData: &genai.Blob{MIMEType: "audio/wav", Data: []byte("wav")}},
+			}}}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newMessageRequest("m", tt.req, 100, false)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newMessageRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			data, err := json.Marshal(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, string(data)); diff != "" {
+				t.Errorf("newMessageRequest() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFinishReason(t *testing.T) {
+	tests := map[string]genai.FinishReason{
+		"":              genai.FinishReasonUnspecified,
+		"end_turn":      genai.FinishReasonStop,
+		"stop_sequence": genai.FinishReasonStop,
+		"tool_use":      genai.FinishReasonStop,
+		"max_tokens":    genai.FinishReasonMaxTokens,
+		"refusal":       genai.FinishReasonSafety,
+		"unknown":       genai.FinishReasonOther,
+	}
+	for stopReason, want := range tests {
+		if got := finishReason(stopReason); got != want {
+			t.Errorf("finishReason(%q) = %q, want %q", stopReason, got, want)
+		}
+	}
+}
+
+// newTestConfig returns the Config configured for record and replay.
+func newTestConfig(t *testing.T, rrfile string) *Config {
+	t.Helper()
+	rr, err := httprr.Open(rrfile, http.DefaultTransport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr.ScrubReq(scrubRequest)
+	cfg := &Config{HTTPClient: &http.Client{Transport: rr}}
+	if recording, _ := httprr.Recording(rrfile); !recording {
+		cfg.APIKey = "fakekey"
+	}
+	return cfg
+}
+
+func scrubRequest(req *http.Request) error {
+	req.Header.Del("X-Api-Key")
+	req.Header.Del("User-Agent") // contains version numbers
+	b := req.Body.(*httprr.Body)
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, b.Data); err == nil {
+		b.Data = buf.Bytes()
+	}
+	return nil
+}
+
+// TextResponse holds the concatenated text from a response stream,
+// separated into partial and final parts.
+type TextResponse struct {
+	// PartialText is the full text concatenated from all partial (streaming) responses.
+	PartialText string
+	// FinalText is the full text concatenated from all final (non-partial) responses.
+	FinalText string
+}
+
+// readResponse transforms a sequence into a TextResponse, concatenating the text value of the response parts
+// depending on whether the responses are partial.
+func readResponse(s iter.Seq2[*model.LLMResponse, error]) (TextResponse, error) {
+	var partialBuilder, finalBuilder strings.Builder
+	var result TextResponse
+
+	for resp, err := range s {
+		if err != nil {
+			// Return what we have so far, along with the error.
+			result.PartialText = partialBuilder.String()
+			result.FinalText = finalBuilder.String()
+			return result, err
+		}
+		if resp.Content == nil || len(resp.Content.Parts) == 0 {
+			return result, fmt.Errorf("encountered an empty response: %v", resp)
+		}
+
+		text := resp.Content.Parts[0].Text
+		if resp.Partial {
+			partialBuilder.WriteString(text)
+		} else {
+			finalBuilder.WriteString(text)
+		}
+	}
+
+	result.PartialText = partialBuilder.String()
+	result.FinalText = finalBuilder.String()
+	return result, nil
+}
+
+// headerInterceptor is a http.RoundTripper that executes a check function on the request
+// before delegating to the base transport.
+type headerInterceptor struct {
+	base  http.RoundTripper
+	check func(*http.Request)
+}
+
+func (h *headerInterceptor) RoundTrip(req *http.Request) (*http.Response, error) {
+	if h.check != nil {
+		h.check(req)
+	}
+	return h.base.RoundTrip(req)
+}
+
+func TestModel_SupportsThinking(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"claude-3-7-sonnet-latest", true},
+		{"claude-sonnet-4-5", true},
+		{"claude-opus-4-1-20250805", true},
+		{"claude-3-5-haiku-latest", false},
+		{"claude-3-opus-20240229", false},
+	}
+	for _, tt := range tests {
+		m := &anthropicModel{name: tt.name}
+		if got := m.SupportsThinking(); got != tt.want {
+			t.Errorf("SupportsThinking() of %q = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package anthropic
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/internal/llminternal/converters"
+	"google.golang.org/adk/model"
+)
+
+// messageRequest is the body of a request to the Messages API.
+type messageRequest struct {
+	Model         string          `json:"model"`
+	MaxTokens     int             `json:"max_tokens"`
+	System        string          `json:"system,omitempty"`
+	Messages      []message       `json:"messages"`
+	Tools         []toolDef       `json:"tools,omitempty"`
+	ToolChoice    *toolChoice     `json:"tool_choice,omitempty"`
+	Temperature   *float32        `json:"temperature,omitempty"`
+	TopP          *float32        `json:"top_p,omitempty"`
+	TopK          *int            `json:"top_k,omitempty"`
+	StopSequences []string        `json:"stop_sequences,omitempty"`
+	Thinking      *thinkingConfig `json:"thinking,omitempty"`
+	Stream        bool            `json:"stream,omitempty"`
+}
+
+type message struct {
+	Role    string         `json:"role"`
+	Content []contentBlock `json:"content"`
+}
+
+// contentBlock is a block of the content of a message, whose fields depend
+// on its type.
+type contentBlock struct {
+	Type string `json:"type"`
+	// Text is the text of the "text" blocks.
+	Text string `json:"text,omitempty"`
+	// ID, Name and Input are those of the "tool_use" blocks.
+	ID    string          `json:"id,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
+	// ToolUseID, Content and IsError are those of the "tool_result" blocks.
+	ToolUseID string `json:"tool_use_id,omitempty"`
+	Content   string `json:"content,omitempty"`
+	IsError   bool   `json:"is_error,omitempty"`
+	// Source is the source of the "image" and "document" blocks.
+	Source *source `json:"source,omitempty"`
+	// Thinking and Signature are those of the "thinking" blocks.
+	Thinking  string `json:"thinking,omitempty"`
+	Signature string `json:"signature,omitempty"`
+}
+
+type source struct {
+	Type      string `json:"type"`
+	MediaType string `json:"media_type,omitempty"`
+	Data      string `json:"data,omitempty"`
+	URL       string `json:"url,omitempty"`
+}
+
+type toolDef struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	InputSchema any    `json:"input_schema"`
+}
+
+type toolChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+}
+
+type thinkingConfig struct {
+	Type         string `json:"type"`
+	BudgetTokens int    `json:"budget_tokens,omitempty"`
+}
+
+const (
+	// userContinuation is the user message appended to the requests whose
+	// last message is from the model, so that the model continues.
+	userContinuation = "Continue processing previous requests as instructed. Exit or provide a summary if no more outputs are needed."
+	// userStart is the user message of the requests without messages.
+	userStart = "Handle the requests as specified in the System Instruction."
+)
+
+// newMessageRequest translates the request to a request of the Messages API.
+func newMessageRequest(modelName string, req *model.LLMRequest, maxTokens int, stream bool) (*messageRequest, error) {
+	body := &messageRequest{Model: modelName, MaxTokens: maxTokens, Stream: stream}
+
+	for _, content := range req.Contents {
+		if content == nil {
+			continue
+		}
+		blocks, err := contentBlocks(content)
+		if err != nil {
+			return nil, err
+		}
+		if len(blocks) == 0 {
+			continue
+		}
+		role := "user"
+		if content.Role == genai.RoleModel {
+			role = "assistant"
+		}
+		// Consecutive contents of the same role, e.g. the responses of
+		// parallel function calls, are a single message.
+		if n := len(body.Messages); n > 0 && body.Messages[n-1].Role == role {
+			body.Messages[n-1].Content = append(body.Messages[n-1].Content, blocks...)
+			continue
+		}
+		body.Messages = append(body.Messages, message{Role: role, Content: blocks})
+	}
+	switch {
+	case len(body.Messages) == 0:
+		body.Messages = append(body.Messages, message{Role: "user", Content: []contentBlock{{Type: "text", Text: userStart}}})
+	case body.Messages[len(body.Messages)-1].Role != "user":
+		body.Messages = append(body.Messages, message{Role: "user", Content: []contentBlock{{Type: "text", Text: userContinuation}}})
+	}
+
+	cfg := req.Config
+	if cfg == nil {
+		return body, nil
+	}
+	if cfg.SystemInstruction != nil {
+		var texts []string
+		for _, part := range cfg.SystemInstruction.Parts {
+			if part != nil && part.Text != "" {
+				texts = append(texts, part.Text)
+			}
+		}
+		body.System = strings.Join(texts, "\n\n")
+	}
+	if cfg.MaxOutputTokens > 0 {
+		body.MaxTokens = int(cfg.MaxOutputTokens)
+	}
+	body.Temperature = cfg.Temperature
+	body.TopP = cfg.TopP
+	if cfg.TopK != nil {
+		topK := int(*cfg.TopK)
+		body.TopK = &topK
+	}
+	body.StopSequences = cfg.StopSequences
+	if cfg.ThinkingConfig != nil && cfg.ThinkingConfig.ThinkingBudget != nil && *cfg.ThinkingConfig.ThinkingBudget > 0 {
+		body.Thinking = &thinkingConfig{Type: "enabled", BudgetTokens: int(*cfg.ThinkingConfig.ThinkingBudget)}
+	}
+
+	for _, t := range cfg.Tools {
+		if t == nil {
+			continue
+		}
+		if t.FunctionDeclarations == nil {
+			return nil, fmt.Errorf("only function tools are supported by anthropic models")
+		}
+		for _, decl := range t.FunctionDeclarations {
+			body.Tools = append(body.Tools, toolDef{
+				Name:        decl.Name,
+				Description: decl.Description,
+				InputSchema: inputSchema(decl),
+			})
+		}
+	}
+	if cfg.ToolConfig != nil && cfg.ToolConfig.FunctionCallingConfig != nil && len(body.Tools) > 0 {
+		fc := cfg.ToolConfig.FunctionCallingConfig
+		switch fc.Mode {
+		case genai.FunctionCallingConfigModeAny:
+			if len(fc.AllowedFunctionNames) == 1 {
+				body.ToolChoice = &toolChoice{Type: "tool", Name: fc.AllowedFunctionNames[0]}
+			} else {
+				body.ToolChoice = &toolChoice{Type: "any"}
+			}
+		case genai.FunctionCallingConfigModeNone:
+			body.ToolChoice = &toolChoice{Type: "none"}
+		case genai.FunctionCallingConfigModeAuto:
+			body.ToolChoice = &toolChoice{Type: "auto"}
+		}
+	}
+	return body, nil
+}
+
+// contentBlocks translates the parts of the content to content blocks.
+func contentBlocks(content *genai.Content) ([]contentBlock, error) {
+	var blocks []contentBlock
+	for _, part := range content.Parts {
+		if part == nil {
+			continue
+		}
+		switch {
+		case part.Thought:
+			// The thoughts can only be sent back with their signature, which
+			// the aggregated thoughts of the streamed responses don't have.
+			if len(part.ThoughtSignature) > 0 && part.Text != "" {
+				blocks = append(blocks, contentBlock{Type: "thinking", Thinking: part.Text, Signature: string(part.ThoughtSignature)})
+			}
+		case part.Text != "":
+			blocks = append(blocks, contentBlock{Type: "text", Text: part.Text})
+		case part.FunctionCall != nil:
+			input, err := json.Marshal(part.FunctionCall.Args)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode arguments of function call %q: %w", part.FunctionCall.Name, err)
+			}
+			if part.FunctionCall.Args == nil {
+				input = []byte("{}")
+			}
+			blocks = append(blocks, contentBlock{Type: "tool_use", ID: part.FunctionCall.ID, Name: part.FunctionCall.Name, Input: input})
+		case part.FunctionResponse != nil:
+			result, err := json.Marshal(part.FunctionResponse.Response)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode response of function %q: %w", part.FunctionResponse.Name, err)
+			}
+			_, isError := part.FunctionResponse.Response["error"]
+			blocks = append(blocks, contentBlock{
+				Type:      "tool_result",
+				ToolUseID: part.FunctionResponse.ID,
+				Content:   string(result),
+				IsError:   isError && len(part.FunctionResponse.Response) == 1,
+			})
+		case part.InlineData != nil:
+			typ, err := mediaBlockType(part.InlineData.MIMEType)
+			if err != nil {
+				return nil, err
+			}
+			blocks = append(blocks, contentBlock{Type: typ, Source: &source{
+				Type:      "base64",
+				MediaType: part.InlineData.MIMEType,
+				Data:      base64.StdEncoding.EncodeToString(part.InlineData.Data),
+			}})
+		case part.FileData != nil:
+			typ, err := mediaBlockType(part.FileData.MIMEType)
+			if err != nil {
+				return nil, err
+			}
+			blocks = append(blocks, contentBlock{Type: typ, Source: &source{Type: "url", URL: part.FileData.FileURI}})
+		}
+	}
+	return blocks, nil
+}
+
+// mediaBlockType returns the type of the content blocks of the MIME type.
+func mediaBlockType(mimeType string) (string, error) {
+	switch {
+	case strings.HasPrefix(mimeType, "image/"):
+		return "image", nil
+	case mimeType == "application/pdf":
+		return "document", nil
+	}
+	return "", fmt.Errorf("unsupported MIME type %q for anthropic models", mimeType)
+}
+
+// inputSchema returns the JSON schema of the parameters of the function.
+func inputSchema(decl *genai.FunctionDeclaration) any {
+	if decl.ParametersJsonSchema != nil {
+		return decl.ParametersJsonSchema
+	}
+	if decl.Parameters == nil {
+		return map[string]any{"type": "object", "properties": map[string]any{}}
+	}
+	return converters.SchemaToJSON(decl.Parameters)
+}
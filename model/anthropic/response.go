@@ -0,0 +1,243 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package anthropic
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/internal/llminternal/converters"
+	"google.golang.org/adk/model"
+)
+
+// messageResponse is the body of a response of the Messages API.
+type messageResponse struct {
+	ID         string         `json:"id"`
+	Model      string         `json:"model"`
+	Role       string         `json:"role"`
+	Content    []contentBlock `json:"content"`
+	StopReason string         `json:"stop_reason"`
+	Usage      *usage         `json:"usage"`
+}
+
+type usage struct {
+	InputTokens              int32 `json:"input_tokens"`
+	OutputTokens             int32 `json:"output_tokens"`
+	CacheCreationInputTokens int32 `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int32 `json:"cache_read_input_tokens"`
+}
+
+type apiError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// streamEvent is an event of a streamed response.
+type streamEvent struct {
+	Type         string           `json:"type"`
+	Message      *messageResponse `json:"message"`
+	Index        int              `json:"index"`
+	ContentBlock *contentBlock    `json:"content_block"`
+	Delta        *streamDelta     `json:"delta"`
+	Usage        *usage           `json:"usage"`
+	Error        *apiError        `json:"error"`
+}
+
+type streamDelta struct {
+	Type        string `json:"type"`
+	Text        string `json:"text"`
+	PartialJSON string `json:"partial_json"`
+	Thinking    string `json:"thinking"`
+	Signature   string `json:"signature"`
+	StopReason  string `json:"stop_reason"`
+}
+
+// llmResponse translates the message to an LLM response.
+func (m *messageResponse) llmResponse() (*model.LLMResponse, error) {
+	content := &genai.Content{Role: genai.RoleModel}
+	for i := range m.Content {
+		part, err := blockPart(&m.Content[i])
+		if err != nil {
+			return nil, err
+		}
+		if part != nil {
+			content.Parts = append(content.Parts, part)
+		}
+	}
+	return converters.Genai2LLMResponse(&genai.GenerateContentResponse{
+		Candidates:    []*genai.Candidate{{Content: content, FinishReason: finishReason(m.StopReason)}},
+		UsageMetadata: usageMetadata(m.Usage, m.Usage),
+		ModelVersion:  m.Model,
+		ResponseID:    m.ID,
+	}), nil
+}
+
+// blockPart translates the content block of a response to a part. It
+// returns nil for the blocks without a genai equivalent.
+func blockPart(b *contentBlock) (*genai.Part, error) {
+	switch b.Type {
+	case "text":
+		return &genai.Part{Text: b.Text}, nil
+	case "thinking":
+		return &genai.Part{Text: b.Thinking, Thought: true, ThoughtSignature: []byte(b.Signature)}, nil
+	case "tool_use":
+		var args map[string]any
+		if len(b.Input) > 0 {
+			if err := json.Unmarshal(b.Input, &args); err != nil {
+				return nil, fmt.Errorf("failed to decode input of tool use %q: %w", b.Name, err)
+			}
+		}
+		return &genai.Part{FunctionCall: &genai.FunctionCall{ID: b.ID, Name: b.Name, Args: args}}, nil
+	}
+	return nil, nil
+}
+
+// finishReason translates the stop reason of a message.
+func finishReason(stopReason string) genai.FinishReason {
+	switch stopReason {
+	case "":
+		return genai.FinishReasonUnspecified
+	case "end_turn", "stop_sequence", "tool_use", "pause_turn":
+		return genai.FinishReasonStop
+	case "max_tokens":
+		return genai.FinishReasonMaxTokens
+	case "refusal":
+		return genai.FinishReasonSafety
+	}
+	return genai.FinishReasonOther
+}
+
+// usageMetadata translates the token usage of a message. The input tokens
+// of streamed messages are only reported by their first event.
+func usageMetadata(input, output *usage) *genai.GenerateContentResponseUsageMetadata {
+	if input == nil || output == nil {
+		return nil
+	}
+	prompt := input.InputTokens + input.CacheCreationInputTokens + input.CacheReadInputTokens
+	return &genai.GenerateContentResponseUsageMetadata{
+		PromptTokenCount:        prompt,
+		CachedContentTokenCount: input.CacheReadInputTokens,
+		CandidatesTokenCount:    output.OutputTokens,
+		TotalTokenCount:         prompt + output.OutputTokens,
+	}
+}
+
+// streamState translates the events of a streamed response to genai
+// responses.
+//
+// The text and thinking deltas are yielded as they arrive, and the tool uses
+// once their input is complete. The last response is held back until the
+// end of the message, so that it carries the finish reason and the usage
+// like the last response of a Gemini stream.
+type streamState struct {
+	message *messageResponse
+	// block is the content block being streamed, and input the input of the
+	// tool uses received so far.
+	block *contentBlock
+	input []byte
+	// pending is the response not yielded yet.
+	pending *genai.GenerateContentResponse
+}
+
+// handle processes the event and returns the responses ready to be yielded.
+func (s *streamState) handle(ev *streamEvent) ([]*genai.GenerateContentResponse, error) {
+	switch ev.Type {
+	case "message_start":
+		s.message = ev.Message
+	case "content_block_start":
+		if ev.ContentBlock == nil {
+			return nil, fmt.Errorf("stream event %q has no content block", ev.Type)
+		}
+		block := *ev.ContentBlock
+		s.block = &block
+		s.input = nil
+	case "content_block_delta":
+		if s.block == nil || ev.Delta == nil {
+			return nil, fmt.Errorf("unexpected stream event %q", ev.Type)
+		}
+		switch ev.Delta.Type {
+		case "text_delta":
+			return s.push(&genai.Part{Text: ev.Delta.Text}), nil
+		case "thinking_delta":
+			return s.push(&genai.Part{Text: ev.Delta.Thinking, Thought: true}), nil
+		case "input_json_delta":
+			s.input = append(s.input, ev.Delta.PartialJSON...)
+		}
+	case "content_block_stop":
+		block := s.block
+		s.block = nil
+		if block == nil || block.Type != "tool_use" {
+			return nil, nil
+		}
+		if len(s.input) > 0 {
+			block.Input = s.input
+		}
+		part, err := blockPart(block)
+		if err != nil {
+			return nil, err
+		}
+		// The parallel tool uses are a single response, as they would be
+		// for a unary request.
+		if s.pending != nil && s.pending.Candidates[0].Content.Parts[0].FunctionCall != nil {
+			s.pending.Candidates[0].Content.Parts = append(s.pending.Candidates[0].Content.Parts, part)
+			return nil, nil
+		}
+		return s.push(part), nil
+	case "message_delta":
+		if s.pending == nil {
+			return nil, nil
+		}
+		if ev.Delta != nil {
+			s.pending.Candidates[0].FinishReason = finishReason(ev.Delta.StopReason)
+		}
+		var input *usage
+		if s.message != nil {
+			input = s.message.Usage
+		}
+		s.pending.UsageMetadata = usageMetadata(input, ev.Usage)
+	case "error":
+		if ev.Error == nil {
+			return nil, fmt.Errorf("stream error event has no error")
+		}
+		return nil, &APIError{Type: ev.Error.Type, Message: ev.Error.Message}
+	}
+	return nil, nil
+}
+
+// push makes a response of the part pending, and returns the previously
+// pending one.
+func (s *streamState) push(part *genai.Part) []*genai.GenerateContentResponse {
+	prev := s.flush()
+	s.pending = &genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{{Content: &genai.Content{Role: genai.RoleModel, Parts: []*genai.Part{part}}}},
+	}
+	if s.message != nil {
+		s.pending.ModelVersion = s.message.Model
+		s.pending.ResponseID = s.message.ID
+	}
+	if prev == nil {
+		return nil
+	}
+	return []*genai.GenerateContentResponse{prev}
+}
+
+// flush returns the pending response, if any.
+func (s *streamState) flush() *genai.GenerateContentResponse {
+	resp := s.pending
+	s.pending = nil
+	return resp
+}
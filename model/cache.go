@@ -0,0 +1,271 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"log"
+	"maps"
+	"time"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/internal/lru"
+)
+
+// CacheHitMetadataKey is the custom metadata key of the responses served
+// from the cache of the models with a cache, whose value is true.
+const CacheHitMetadataKey = "adk_cache_hit"
+
+// ResponseCache stores the serialized responses of a model by the key of
+// their requests. Implementations must be safe for concurrent use, and may
+// be backed by an external store, e.g. Redis with GET and SET with an
+// expiration.
+type ResponseCache interface {
+	// Get returns the value of the key, and whether it was found.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores the value of the key. A positive ttl is the duration after
+	// which the value may be dropped.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// CacheConfig configures the cache of the responses of a model.
+type CacheConfig struct {
+	// Force caches the responses of all the requests. By default, only the
+	// responses of the deterministic requests, with a zero temperature, are
+	// cached.
+	Force bool
+	// TTL is the duration for which the responses are cached. Zero means
+	// until the cache drops them.
+	TTL time.Duration
+}
+
+type cachedModel struct {
+	llm   LLM
+	cache ResponseCache
+	cfg   CacheConfig
+}
+
+// WithCache returns the model serving the responses to the requests which
+// were already answered from the cache. The requests are identified by a
+// hash of their model, contents, and generation config, which includes the
+// system instruction and the declarations of the tools.
+//
+// The responses to the streamed requests are cached as the single response
+// merging their non-partial responses, and replayed as such. The responses
+// served from the cache are marked with the [CacheHitMetadataKey] custom
+// metadata, and have no usage metadata since they didn't use any tokens.
+// The failed calls and the responses with an error code are not cached.
+// Cache failures are logged, and the model is called as if the response
+// wasn't cached.
+func WithCache(llm LLM, cache ResponseCache, cfg CacheConfig) LLM {
+	return &cachedModel{llm: llm, cache: cache, cfg: cfg}
+}
+
+func (m *cachedModel) Name() string {
+	return m.llm.Name()
+}
+
+// CountTokens counts the tokens of the request with the cached model.
+func (m *cachedModel) CountTokens(ctx context.Context, req *LLMRequest) (int, error) {
+	return CountTokens(ctx, m.llm, req)
+}
+
+// SupportsThinking reports whether the cached model supports thinking.
+func (m *cachedModel) SupportsThinking() bool {
+	return SupportsThinking(m.llm)
+}
+
+// SupportsLive reports whether the cached model supports live sessions.
+func (m *cachedModel) SupportsLive() bool {
+	return SupportsLive(m.llm)
+}
+
+// Connect opens a live session of the cached model. The responses of the
+// live sessions aren't cached.
+func (m *cachedModel) Connect(ctx context.Context, req *LLMRequest) (LiveSession, error) {
+	return Connect(ctx, m.llm, req)
+}
+
+func (m *cachedModel) GenerateContent(ctx context.Context, req *LLMRequest, stream bool) iter.Seq2[*LLMResponse, error] {
+	if !m.cfg.Force && !deterministic(req) {
+		return m.llm.GenerateContent(ctx, req, stream)
+	}
+	return func(yield func(*LLMResponse, error) bool) {
+		key, err := CacheKey(req)
+		if err != nil {
+			log.Printf("Failed to compute the cache key of the model request: %v", err)
+			for resp, err := range m.llm.GenerateContent(ctx, req, stream) {
+				if !yield(resp, err) {
+					return
+				}
+			}
+			return
+		}
+		if resp := m.get(ctx, key); resp != nil {
+			yield(resp, nil)
+			return
+		}
+
+		var final []*LLMResponse
+		cacheable := true
+		for resp, err := range m.llm.GenerateContent(ctx, req, stream) {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if resp != nil && !resp.Partial && cacheable {
+				// Copy the response before the consumer modifies it, e.g.
+				// by populating the IDs of the function calls.
+				clone, err := cloneResponse(resp)
+				if err != nil {
+					log.Printf("Failed to encode the model response %s: %v", key, err)
+					cacheable = false
+				}
+				final = append(final, clone)
+			}
+			if !yield(resp, nil) {
+				return
+			}
+		}
+		if !cacheable {
+			return
+		}
+		if resp := mergeResponses(final); resp != nil && resp.ErrorCode == "" {
+			m.set(ctx, key, resp)
+		}
+	}
+}
+
+func cloneResponse(resp *LLMResponse) (*LLMResponse, error) {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return nil, err
+	}
+	clone := &LLMResponse{}
+	if err := json.Unmarshal(data, clone); err != nil {
+		return nil, err
+	}
+	return clone, nil
+}
+
+func (m *cachedModel) get(ctx context.Context, key string) *LLMResponse {
+	data, ok, err := m.cache.Get(ctx, key)
+	if err != nil {
+		log.Printf("Failed to get the model response %s from the cache: %v", key, err)
+		return nil
+	}
+	if !ok {
+		return nil
+	}
+	resp := &LLMResponse{}
+	if err := json.Unmarshal(data, resp); err != nil {
+		log.Printf("Failed to decode the model response %s of the cache: %v", key, err)
+		return nil
+	}
+	resp.Partial = false
+	resp.TurnComplete = true
+	resp.UsageMetadata = nil
+	if resp.CustomMetadata == nil {
+		resp.CustomMetadata = make(map[string]any)
+	}
+	resp.CustomMetadata[CacheHitMetadataKey] = true
+	return resp
+}
+
+func (m *cachedModel) set(ctx context.Context, key string, resp *LLMResponse) {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		log.Printf("Failed to encode the model response %s: %v", key, err)
+		return
+	}
+	if err := m.cache.Set(ctx, key, data, m.cfg.TTL); err != nil {
+		log.Printf("Failed to store the model response %s in the cache: %v", key, err)
+	}
+}
+
+// deterministic reports whether the request has a zero temperature.
+func deterministic(req *LLMRequest) bool {
+	return req.Config != nil && req.Config.Temperature != nil && *req.Config.Temperature == 0
+}
+
+// CacheKey returns the key of the request in the caches of the models with
+// a cache: a hash of its model, contents and generation config.
+func CacheKey(req *LLMRequest) (string, error) {
+	data, err := json.Marshal(struct {
+		Model    string                       `json:"model"`
+		Contents []*genai.Content             `json:"contents"`
+		Config   *genai.GenerateContentConfig `json:"config"`
+	}{req.Model, req.Contents, req.Config})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode request: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// mergeResponses returns the response merging the parts and the metadata of
+// the responses, or nil if there are none.
+func mergeResponses(responses []*LLMResponse) *LLMResponse {
+	if len(responses) == 0 {
+		return nil
+	}
+	if len(responses) == 1 {
+		return responses[0]
+	}
+	merged := &LLMResponse{}
+	for _, resp := range responses {
+		if resp.Content != nil {
+			if merged.Content == nil {
+				merged.Content = &genai.Content{Role: resp.Content.Role}
+			}
+			merged.Content.Parts = append(merged.Content.Parts, resp.Content.Parts...)
+		}
+		if resp.CustomMetadata != nil {
+			if merged.CustomMetadata == nil {
+				merged.CustomMetadata = make(map[string]any)
+			}
+			maps.Copy(merged.CustomMetadata, resp.CustomMetadata)
+		}
+		if resp.CitationMetadata != nil {
+			merged.CitationMetadata = resp.CitationMetadata
+		}
+		if resp.GroundingMetadata != nil {
+			merged.GroundingMetadata = resp.GroundingMetadata
+		}
+		if resp.UsageMetadata != nil {
+			merged.UsageMetadata = resp.UsageMetadata
+		}
+		if resp.ErrorCode != "" {
+			merged.ErrorCode, merged.ErrorMessage = resp.ErrorCode, resp.ErrorMessage
+		}
+		if resp.FinishReason != "" {
+			merged.FinishReason = resp.FinishReason
+		}
+	}
+	return merged
+}
+
+// NewLRUCache returns an in-memory [ResponseCache] which drops the least
+// recently used responses to keep the total size of the cached responses
+// under maxBytes. The responses larger than maxBytes are not cached.
+func NewLRUCache(maxBytes int) ResponseCache {
+	return lru.New(maxBytes)
+}
@@ -0,0 +1,249 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+)
+
+func TestWithCache(t *testing.T) {
+	zero := float32(0)
+	deterministic := &model.LLMRequest{
+		Model:    "m",
+		Contents: []*genai.Content{genai.NewContentFromText("hi", genai.RoleUser)},
+		Config:   &genai.GenerateContentConfig{Temperature: &zero},
+	}
+	usage := &genai.GenerateContentResponseUsageMetadata{PromptTokenCount: 3, TotalTokenCount: 5}
+	call := genai.NewPartFromFunctionCall("get_weather", map[string]any{"city": "Paris"})
+	tests := []struct {
+		name      string
+		llm       *fakeModel
+		cfg       model.CacheConfig
+		req       *model.LLMRequest
+		stream    bool
+		want      []*model.LLMResponse
+		wantCalls int
+	}{
+		{
+			name: "cached",
+			llm:  &fakeModel{name: "m", responses: []*model.LLMResponse{{Content: genai.NewContentFromText("hello", genai.RoleModel), UsageMetadata: usage}}},
+			req:  deterministic,
+			want: []*model.LLMResponse{{
+				Content:        genai.NewContentFromText("hello", genai.RoleModel),
+				CustomMetadata: map[string]any{model.CacheHitMetadataKey: true},
+				TurnComplete:   true,
+			}},
+			wantCalls: 1,
+		},
+		{
+			name:      "not deterministic",
+			llm:       &fakeModel{name: "m", responses: []*model.LLMResponse{textResponse("hello")}},
+			req:       &model.LLMRequest{Model: "m", Contents: deterministic.Contents},
+			want:      []*model.LLMResponse{textResponse("hello")},
+			wantCalls: 2,
+		},
+		{
+			name: "forced",
+			llm:  &fakeModel{name: "m", responses: []*model.LLMResponse{textResponse("hello")}},
+			cfg:  model.CacheConfig{Force: true},
+			req:  &model.LLMRequest{Model: "m", Contents: deterministic.Contents},
+			want: []*model.LLMResponse{{
+				Content:        genai.NewContentFromText("hello", genai.RoleModel),
+				CustomMetadata: map[string]any{model.CacheHitMetadataKey: true},
+				TurnComplete:   true,
+			}},
+			wantCalls: 1,
+		},
+		{
+			name: "stream replayed as one response",
+			llm: &fakeModel{name: "m", responses: []*model.LLMResponse{
+				{Content: genai.NewContentFromText("let me ", genai.RoleModel), Partial: true},
+				{Content: genai.NewContentFromText("let me check", genai.RoleModel)},
+				{Content: genai.NewContentFromParts([]*genai.Part{call}, genai.RoleModel), UsageMetadata: usage, FinishReason: genai.FinishReasonStop, TurnComplete: true},
+			}},
+			req:    deterministic,
+			stream: true,
+			want: []*model.LLMResponse{{
+				Content:        genai.NewContentFromParts([]*genai.Part{genai.NewPartFromText("let me check"), call}, genai.RoleModel),
+				CustomMetadata: map[string]any{model.CacheHitMetadataKey: true},
+				FinishReason:   genai.FinishReasonStop,
+				TurnComplete:   true,
+			}},
+			wantCalls: 1,
+		},
+		{
+			name:      "error",
+			llm:       &fakeModel{name: "m", err: statusError(http.StatusServiceUnavailable)},
+			req:       deterministic,
+			wantCalls: 2,
+		},
+		{
+			name:      "error code",
+			llm:       &fakeModel{name: "m", responses: []*model.LLMResponse{{ErrorCode: "SAFETY", ErrorMessage: "blocked"}}},
+			req:       deterministic,
+			want:      []*model.LLMResponse{{ErrorCode: "SAFETY", ErrorMessage: "blocked"}},
+			wantCalls: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			llm := model.WithCache(tt.llm, model.NewLRUCache(1<<20), tt.cfg)
+			// The first call fills the cache, the second one is served
+			// from it when the response is cached.
+			for range llm.GenerateContent(t.Context(), tt.req, tt.stream) {
+			}
+			var got []*model.LLMResponse
+			for resp, err := range llm.GenerateContent(t.Context(), tt.req, tt.stream) {
+				if err != nil {
+					continue
+				}
+				got = append(got, resp)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("GenerateContent() mismatch (-want +got):\n%s", diff)
+			}
+			if tt.llm.calls != tt.wantCalls {
+				t.Errorf("GenerateContent() calls = %d, want %d", tt.llm.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	zero := float32(0)
+	base := func() *model.LLMRequest {
+		return &model.LLMRequest{
+			Model:    "m",
+			Contents: []*genai.Content{genai.NewContentFromText("hi", genai.RoleUser)},
+			Config:   &genai.GenerateContentConfig{Temperature: &zero, SystemInstruction: genai.NewContentFromText("be brief", genai.RoleUser)},
+		}
+	}
+	key := func(req *model.LLMRequest) string {
+		t.Helper()
+		k, err := model.CacheKey(req)
+		if err != nil {
+			t.Fatalf("CacheKey() error = %v", err)
+		}
+		return k
+	}
+	want := key(base())
+	if got := key(base()); got != want {
+		t.Errorf("CacheKey() of identical requests = %q and %q, want equal keys", got, want)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*model.LLMRequest)
+	}{
+		{name: "model", modify: func(r *model.LLMRequest) { r.Model = "other" }},
+		{name: "contents", modify: func(r *model.LLMRequest) { r.Contents[0] = genai.NewContentFromText("hello", genai.RoleUser) }},
+		{name: "system instruction", modify: func(r *model.LLMRequest) {
+			r.Config.SystemInstruction = genai.NewContentFromText("be verbose", genai.RoleUser)
+		}},
+		{name: "tools", modify: func(r *model.LLMRequest) {
+			r.Config.Tools = []*genai.Tool{{FunctionDeclarations: []*genai.FunctionDeclaration{{Name: "get_weather"}}}}
+		}},
+		{name: "generation config", modify: func(r *model.LLMRequest) { r.Config.MaxOutputTokens = 10 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := base()
+			tt.modify(req)
+			if got := key(req); got == want {
+				t.Errorf("CacheKey() = %q, want a key other than that of the base request", got)
+			}
+		})
+	}
+}
+
+func TestLRUCache(t *testing.T) {
+	ctx := t.Context()
+	cache := model.NewLRUCache(10)
+	set := func(key, value string, ttl time.Duration) {
+		t.Helper()
+		if err := cache.Set(ctx, key, []byte(value), ttl); err != nil {
+			t.Fatalf("Set(%q) error = %v", key, err)
+		}
+	}
+	get := func(key string) string {
+		t.Helper()
+		value, ok, err := cache.Get(ctx, key)
+		if err != nil {
+			t.Fatalf("Get(%q) error = %v", key, err)
+		}
+		if !ok {
+			return "<missing>"
+		}
+		return string(value)
+	}
+
+	set("a", "aaaa", 0)
+	set("b", "bbbb", 0)
+	get("a") // a is now more recently used than b.
+	set("c", "cccc", 0)
+	set("big", "more than ten bytes", 0)
+	set("expired", "e", time.Nanosecond)
+	time.Sleep(time.Millisecond)
+
+	got := map[string]string{}
+	for _, key := range []string{"a", "b", "c", "big", "expired"} {
+		got[key] = get(key)
+	}
+	want := map[string]string{"a": "aaaa", "b": "<missing>", "c": "cccc", "big": "<missing>", "expired": "<missing>"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("cached values mismatch (-want +got):\n%s", diff)
+	}
+}
+
+type failingCache struct{}
+
+func (failingCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	return nil, false, errors.New("unavailable")
+}
+
+func (failingCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return errors.New("unavailable")
+}
+
+func TestWithCache_CacheFailure(t *testing.T) {
+	zero := float32(0)
+	llm := &fakeModel{name: "m", responses: []*model.LLMResponse{textResponse("hello")}}
+	req := &model.LLMRequest{Model: "m", Config: &genai.GenerateContentConfig{Temperature: &zero}}
+	cached := model.WithCache(llm, failingCache{}, model.CacheConfig{})
+	for range 2 {
+		var got []*model.LLMResponse
+		for resp, err := range cached.GenerateContent(t.Context(), req, false) {
+			if err != nil {
+				t.Fatalf("GenerateContent() error = %v", err)
+			}
+			got = append(got, resp)
+		}
+		if diff := cmp.Diff([]*model.LLMResponse{textResponse("hello")}, got); diff != "" {
+			t.Errorf("GenerateContent() mismatch (-want +got):\n%s", diff)
+		}
+	}
+	if llm.calls != 2 {
+		t.Errorf("GenerateContent() calls = %d, want 2", llm.calls)
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"maps"
+	"net"
+	"net/http"
+	"slices"
+	"syscall"
+
+	"google.golang.org/genai"
+)
+
+// ModelMetadataKey is the custom metadata key of the responses of the
+// fallback models, and of the events with usage metadata, whose value is the
+// name of the model that answered.
+const ModelMetadataKey = "adk_model"
+
+type fallbackModel struct {
+	models []LLM
+}
+
+// NewFallback returns a model that calls the models in order, until one
+// doesn't fail with a retryable error, as reported by [IsRetryable]. The
+// models are not retried after they yielded a response, e.g. a partial
+// response of a stream.
+//
+// The custom metadata of the responses records the name of the model that
+// answered with the [ModelMetadataKey]. The name of the returned model is
+// that of the first one.
+func NewFallback(models ...LLM) LLM {
+	return &fallbackModel{models: models}
+}
+
+func (m *fallbackModel) Name() string {
+	if len(m.models) == 0 {
+		return ""
+	}
+	return m.models[0].Name()
+}
+
+// CountTokens counts the tokens of the request with the first model.
+func (m *fallbackModel) CountTokens(ctx context.Context, req *LLMRequest) (int, error) {
+	if len(m.models) == 0 {
+		return EstimateTokens(req), nil
+	}
+	return CountTokens(ctx, m.models[0], req)
+}
+
+// SupportsThinking reports whether all the models support thinking, as any
+// of them may reply.
+func (m *fallbackModel) SupportsThinking() bool {
+	for _, llm := range m.models {
+		if !SupportsThinking(llm) {
+			return false
+		}
+	}
+	return len(m.models) > 0
+}
+
+// SupportsLive reports whether any of the models supports live sessions.
+func (m *fallbackModel) SupportsLive() bool {
+	return slices.ContainsFunc(m.models, SupportsLive)
+}
+
+// Connect opens a live session of the first model which supports them. The
+// next such model is tried if the connection fails with a retryable error,
+// as reported by [IsRetryable], but the sessions don't fall back once open.
+func (m *fallbackModel) Connect(ctx context.Context, req *LLMRequest) (LiveSession, error) {
+	var errs []error
+	live := slices.DeleteFunc(slices.Clone(m.models), func(llm LLM) bool { return !SupportsLive(llm) })
+	for i, llm := range live {
+		modelReq := *req
+		modelReq.Model = llm.Name()
+		session, err := Connect(ctx, llm, &modelReq)
+		if err == nil {
+			return session, nil
+		}
+		errs = append(errs, err)
+		if i == len(live)-1 || ctx.Err() != nil || !IsRetryable(err) {
+			break
+		}
+	}
+	if len(errs) == 0 {
+		return nil, fmt.Errorf("fallback model %q: %w", m.Name(), ErrLiveUnsupported)
+	}
+	return nil, errors.Join(errs...)
+}
+
+func (m *fallbackModel) GenerateContent(ctx context.Context, req *LLMRequest, stream bool) iter.Seq2[*LLMResponse, error] {
+	return func(yield func(*LLMResponse, error) bool) {
+		if len(m.models) == 0 {
+			yield(nil, errors.New("fallback model has no models"))
+			return
+		}
+		var errs []error
+		for i, llm := range m.models {
+			modelReq := *req
+			modelReq.Model = llm.Name()
+			yielded := false
+			var lastErr error
+			for resp, err := range GenerateWithTimeouts(ctx, llm, &modelReq, stream) {
+				if err != nil && !yielded && i < len(m.models)-1 && ctx.Err() == nil && IsRetryable(err) {
+					lastErr = err
+					break
+				}
+				if resp != nil {
+					resp.CustomMetadata = maps.Clone(resp.CustomMetadata)
+					if resp.CustomMetadata == nil {
+						resp.CustomMetadata = make(map[string]any)
+					}
+					resp.CustomMetadata[ModelMetadataKey] = llm.Name()
+				}
+				if err != nil && len(errs) > 0 {
+					err = errors.Join(append(errs, err)...)
+				}
+				yielded = true
+				if !yield(resp, err) {
+					return
+				}
+			}
+			if lastErr == nil {
+				return
+			}
+			errs = append(errs, lastErr)
+		}
+	}
+}
+
+// enforcesTimeouts reports that the timeouts of the requests apply to each
+// model, so that the next model is tried after a timeout.
+func (m *fallbackModel) enforcesTimeouts() bool {
+	return true
+}
+
+// statusCoder is implemented by the errors of the model APIs with an HTTP
+// status code, e.g. those of the model subpackages.
+type statusCoder interface {
+	HTTPStatusCode() int
+}
+
+// IsRetryable reports whether the error of a model is transient, worth
+// retrying or trying another model for: exhausted quotas and rate limits,
+// server errors, timeouts, including those of [*TimeoutError], reset
+// connections and responses cut short.
+//
+// The status codes are those of the genai API errors and of the errors with
+// an HTTPStatusCode() int method.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	code := 0
+	var apiErr genai.APIError
+	var apiErrPtr *genai.APIError
+	var sc statusCoder
+	switch {
+	case errors.As(err, &apiErr):
+		code = apiErr.Code
+	case errors.As(err, &apiErrPtr):
+		code = apiErrPtr.Code
+	case errors.As(err, &sc):
+		code = sc.HTTPStatusCode()
+	}
+	if code == http.StatusTooManyRequests || code == http.StatusRequestTimeout || code >= 500 {
+		return true
+	}
+	var timeoutErr *TimeoutError
+	if errors.As(err, &timeoutErr) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
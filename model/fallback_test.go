@@ -0,0 +1,182 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"syscall"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+)
+
+type statusError int
+
+func (e statusError) Error() string       { return fmt.Sprintf("status %d", int(e)) }
+func (e statusError) HTTPStatusCode() int { return int(e) }
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestNewFallback(t *testing.T) {
+	quota := genai.APIError{Code: http.StatusTooManyRequests, Status: "RESOURCE_EXHAUSTED"}
+	tests := []struct {
+		name      string
+		models    []*fakeModel
+		want      []*model.LLMResponse
+		wantErr   bool
+		wantCalls []int
+	}{
+		{
+			name: "primary",
+			models: []*fakeModel{
+				{name: "a", responses: []*model.LLMResponse{textResponse("a")}},
+				{name: "b", responses: []*model.LLMResponse{textResponse("b")}},
+			},
+			want:      []*model.LLMResponse{{Content: genai.NewContentFromText("a", genai.RoleModel), CustomMetadata: map[string]any{model.ModelMetadataKey: "a"}}},
+			wantCalls: []int{1, 0},
+		},
+		{
+			name: "retryable_errors",
+			models: []*fakeModel{
+				{name: "a", err: quota},
+				{name: "b", err: fmt.Errorf("failed to call model: %w", statusError(http.StatusServiceUnavailable))},
+				{name: "c", err: timeoutError{}},
+				{name: "d", responses: []*model.LLMResponse{{
+					Content:        genai.NewContentFromText("d", genai.RoleModel),
+					CustomMetadata: map[string]any{"k": "v"},
+				}}},
+			},
+			want: []*model.LLMResponse{{
+				Content:        genai.NewContentFromText("d", genai.RoleModel),
+				CustomMetadata: map[string]any{"k": "v", model.ModelMetadataKey: "d"},
+			}},
+			wantCalls: []int{1, 1, 1, 1},
+		},
+		{
+			name: "non_retryable_error",
+			models: []*fakeModel{
+				{name: "a", err: genai.APIError{Code: http.StatusBadRequest, Status: "INVALID_ARGUMENT"}},
+				{name: "b", responses: []*model.LLMResponse{textResponse("b")}},
+			},
+			wantErr:   true,
+			wantCalls: []int{1, 0},
+		},
+		{
+			name: "error_after_response",
+			models: []*fakeModel{
+				{name: "a", responses: []*model.LLMResponse{{Content: genai.NewContentFromText("par", genai.RoleModel), Partial: true}}, err: quota},
+				{name: "b", responses: []*model.LLMResponse{textResponse("b")}},
+			},
+			want: []*model.LLMResponse{{
+				Content:        genai.NewContentFromText("par", genai.RoleModel),
+				Partial:        true,
+				CustomMetadata: map[string]any{model.ModelMetadataKey: "a"},
+			}},
+			wantErr:   true,
+			wantCalls: []int{1, 0},
+		},
+		{
+			name: "all_failed",
+			models: []*fakeModel{
+				{name: "a", err: quota},
+				{name: "b", err: statusError(http.StatusInternalServerError)},
+			},
+			wantErr:   true,
+			wantCalls: []int{1, 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var models []model.LLM
+			for _, m := range tt.models {
+				models = append(models, m)
+			}
+			var got []*model.LLMResponse
+			var gotErr error
+			for resp, err := range model.NewFallback(models...).GenerateContent(t.Context(), &model.LLMRequest{}, true) {
+				if err != nil {
+					gotErr = err
+					continue
+				}
+				got = append(got, resp)
+			}
+			if (gotErr != nil) != tt.wantErr {
+				t.Errorf("GenerateContent() error = %v, wantErr %v", gotErr, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("GenerateContent() mismatch (-want +got):\n%s", diff)
+			}
+			var calls []int
+			for _, m := range tt.models {
+				calls = append(calls, m.calls)
+			}
+			if diff := cmp.Diff(tt.wantCalls, calls); diff != "" {
+				t.Errorf("model calls mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNewFallback_AllFailedErrors(t *testing.T) {
+	errA := statusError(http.StatusInternalServerError)
+	errB := statusError(http.StatusBadGateway)
+	llm := model.NewFallback(&fakeModel{name: "a", err: errA}, &fakeModel{name: "b", err: errB})
+	for _, err := range llm.GenerateContent(t.Context(), &model.LLMRequest{}, false) {
+		if !errors.Is(err, errA) || !errors.Is(err, errB) {
+			t.Errorf("GenerateContent() error = %v, want the errors of all the models", err)
+		}
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "quota", err: genai.APIError{Code: http.StatusTooManyRequests}, want: true},
+		{name: "quota_pointer", err: &genai.APIError{Code: http.StatusTooManyRequests}, want: true},
+		{name: "server_error", err: genai.APIError{Code: http.StatusInternalServerError}, want: true},
+		{name: "bad_request", err: genai.APIError{Code: http.StatusBadRequest}, want: false},
+		{name: "status_coder", err: fmt.Errorf("wrapped: %w", statusError(529)), want: true},
+		{name: "status_coder_client_error", err: statusError(http.StatusUnauthorized), want: false},
+		{name: "deadline", err: fmt.Errorf("failed to call model: %w", context.DeadlineExceeded), want: true},
+		{name: "net_timeout", err: fmt.Errorf("failed to call model: %w", timeoutError{}), want: true},
+		{name: "model_timeout", err: fmt.Errorf("model call failed after 2 attempts: %w", &model.TimeoutError{Model: "a", Timeout: 1}), want: true},
+		{name: "connection_reset", err: fmt.Errorf("failed to call model: %w", syscall.ECONNRESET), want: true},
+		{name: "unexpected_eof", err: fmt.Errorf("failed to read stream: %w", io.ErrUnexpectedEOF), want: true},
+		{name: "canceled", err: context.Canceled, want: false},
+		{name: "other", err: errors.New("invalid response"), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := model.IsRetryable(tt.err); got != tt.want {
+				t.Errorf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
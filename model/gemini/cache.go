@@ -0,0 +1,282 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gemini
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+)
+
+// DefaultCacheTTL is the [CacheConfig.TTL] used if it is zero.
+const DefaultCacheTTL = time.Hour
+
+// CacheMetadataKey is the custom metadata key of the responses of the models
+// with a context cache, whose value is a map with the "name" key of the
+// cached content which the request referenced, its "expire_time", and the
+// "hit" key, set if the cached content was created by a previous request.
+// The tokens read from the cache are reported by the CachedContentTokenCount
+// of the usage metadata.
+const CacheMetadataKey = "adk_context_cache"
+
+// expiryMargin is the remaining lifetime below which a cached content is
+// recreated, so that it doesn't expire while a request is in flight.
+const expiryMargin = time.Minute
+
+// CacheConfig configures the context caching of the static prefix of the
+// requests of a model: their system instruction, tools, tool config and
+// documents.
+type CacheConfig struct {
+	// TTL is the lifetime of the cached contents.
+	// Optional: if zero, [DefaultCacheTTL] is used.
+	TTL time.Duration
+	// Documents are contents which precede the contents of every request,
+	// e.g. reference documents, and are cached with the system instruction.
+	Documents []*genai.Content
+	// Store stores the handles of the cached contents by the hash of their
+	// prefix, and may be shared by the processes of an app.
+	// Optional: if nil, the handles are stored in memory.
+	Store CacheStore
+}
+
+// CacheHandle is the handle of a cached content.
+type CacheHandle struct {
+	// Name is the resource name of the cached content, e.g.
+	// "cachedContents/abc".
+	Name       string    `json:"name"`
+	ExpireTime time.Time `json:"expireTime"`
+}
+
+// CacheStore stores the handles of cached contents. Implementations must be
+// safe for concurrent use.
+type CacheStore interface {
+	// Get returns the handle of the key, or nil if there is none.
+	Get(ctx context.Context, key string) (*CacheHandle, error)
+	Set(ctx context.Context, key string, handle *CacheHandle) error
+	Delete(ctx context.Context, key string) error
+}
+
+// NewCachedModel returns [model.LLM], backed by the Gemini API, which caches
+// the static prefix of the requests with context caching, so that its
+// tokens are billed at the cached rate.
+//
+// The cached content is created on the first request with a prefix, and
+// referenced by the following requests with the same prefix, as detected by
+// its hash. It is recreated when it expires, or when the prefix changes. If
+// it can't be created, e.g. because the prefix has less tokens than the
+// minimum of the model, the requests are sent without it, with the
+// documents preceding their contents, and the creation is retried after the
+// TTL.
+func NewCachedModel(ctx context.Context, modelName string, cfg *genai.ClientConfig, cacheCfg CacheConfig) (model.LLM, error) {
+	llm, err := NewModel(ctx, modelName, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cacheCfg.TTL <= 0 {
+		cacheCfg.TTL = DefaultCacheTTL
+	}
+	if cacheCfg.Store == nil {
+		cacheCfg.Store = NewInMemoryCacheStore()
+	}
+	m := llm.(*geminiModel)
+	m.cache = &contextCache{cfg: cacheCfg, failed: make(map[string]time.Time)}
+	return m, nil
+}
+
+type contextCache struct {
+	cfg CacheConfig
+
+	mu sync.Mutex
+	// failed records when the creation of the cached contents failed, by
+	// key.
+	failed map[string]time.Time
+}
+
+// cachedRequest is a request which references a cached content.
+type cachedRequest struct {
+	key      string
+	handle   *CacheHandle
+	hit      bool
+	contents []*genai.Content
+	config   *genai.GenerateContentConfig
+}
+
+// prepare returns the contents and the config of the request with the cached
+// prefix, and the cached request which references it, or nil if the request
+// is sent without cache.
+func (c *contextCache) prepare(ctx context.Context, m *geminiModel, req *model.LLMRequest) (*cachedRequest, error) {
+	if req.Config.CachedContent != "" || (req.Config.SystemInstruction == nil && len(req.Config.Tools) == 0 && len(c.cfg.Documents) == 0) {
+		return nil, nil
+	}
+	key, err := c.key(m.name, req.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if failedAt, ok := c.failed[key]; ok && time.Since(failedAt) < c.cfg.TTL {
+		return nil, nil
+	}
+	handle, err := c.cfg.Store.Get(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cached content handle: %w", err)
+	}
+	hit := handle != nil && time.Until(handle.ExpireTime) > expiryMargin
+	if !hit {
+		cached, err := m.client.Caches.Create(ctx, m.name, &genai.CreateCachedContentConfig{
+			HTTPOptions:       req.Config.HTTPOptions,
+			TTL:               c.cfg.TTL,
+			Contents:          c.cfg.Documents,
+			SystemInstruction: req.Config.SystemInstruction,
+			Tools:             req.Config.Tools,
+			ToolConfig:        req.Config.ToolConfig,
+		})
+		if err != nil {
+			log.Printf("Failed to create the cached content of model %s, sending the requests without cache: %v", m.name, err)
+			c.failed[key] = time.Now()
+			return nil, nil
+		}
+		handle = &CacheHandle{Name: cached.Name, ExpireTime: cached.ExpireTime}
+		if handle.ExpireTime.IsZero() {
+			handle.ExpireTime = time.Now().Add(c.cfg.TTL)
+		}
+		if err := c.cfg.Store.Set(ctx, key, handle); err != nil {
+			return nil, fmt.Errorf("failed to store cached content handle: %w", err)
+		}
+	}
+
+	// The cached parts of the prefix must not be sent again.
+	config := *req.Config
+	config.CachedContent = handle.Name
+	config.SystemInstruction = nil
+	config.Tools = nil
+	config.ToolConfig = nil
+	return &cachedRequest{key: key, handle: handle, hit: hit, contents: req.Contents, config: &config}, nil
+}
+
+// invalidate deletes the handle of a cached content which the API doesn't
+// find anymore, e.g. because it was deleted.
+func (c *contextCache) invalidate(ctx context.Context, cached *cachedRequest) {
+	if err := c.cfg.Store.Delete(ctx, cached.key); err != nil {
+		log.Printf("Failed to delete the cached content handle %s: %v", cached.handle.Name, err)
+	}
+}
+
+// uncachedContents returns the contents of a request sent without cache,
+// preceded by the documents.
+func (c *contextCache) uncachedContents(contents []*genai.Content) []*genai.Content {
+	if len(c.cfg.Documents) == 0 {
+		return contents
+	}
+	return append(slices.Clone(c.cfg.Documents), contents...)
+}
+
+// key returns the hash of the prefix of the requests with the config.
+func (c *contextCache) key(modelName string, config *genai.GenerateContentConfig) (string, error) {
+	data, err := json.Marshal(struct {
+		Model             string            `json:"model"`
+		SystemInstruction *genai.Content    `json:"systemInstruction"`
+		Tools             []*genai.Tool     `json:"tools"`
+		ToolConfig        *genai.ToolConfig `json:"toolConfig"`
+		Documents         []*genai.Content  `json:"documents"`
+	}{modelName, config.SystemInstruction, config.Tools, config.ToolConfig, c.cfg.Documents})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cached prefix: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// record records the cached content in the custom metadata of the response.
+func (cached *cachedRequest) record(resp *model.LLMResponse) {
+	if resp == nil {
+		return
+	}
+	resp.CustomMetadata = maps.Clone(resp.CustomMetadata)
+	if resp.CustomMetadata == nil {
+		resp.CustomMetadata = make(map[string]any)
+	}
+	resp.CustomMetadata[CacheMetadataKey] = map[string]any{
+		"name":        cached.handle.Name,
+		"expire_time": cached.handle.ExpireTime.Format(time.RFC3339),
+		"hit":         cached.hit,
+	}
+}
+
+// isCacheNotFound reports whether the error is that of a request which
+// references a cached content which doesn't exist anymore.
+func isCacheNotFound(err error) bool {
+	var apiErr genai.APIError
+	var apiErrPtr *genai.APIError
+	code := 0
+	switch {
+	case errors.As(err, &apiErr):
+		code = apiErr.Code
+	case errors.As(err, &apiErrPtr):
+		code = apiErrPtr.Code
+	}
+	return code == http.StatusNotFound || code == http.StatusForbidden
+}
+
+type inMemoryCacheStore struct {
+	mu      sync.Mutex
+	handles map[string]*CacheHandle
+}
+
+// NewInMemoryCacheStore returns a [CacheStore] which keeps the handles in
+// memory.
+func NewInMemoryCacheStore() CacheStore {
+	return &inMemoryCacheStore{handles: make(map[string]*CacheHandle)}
+}
+
+func (s *inMemoryCacheStore) Get(ctx context.Context, key string) (*CacheHandle, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	handle, ok := s.handles[key]
+	if !ok {
+		return nil, nil
+	}
+	clone := *handle
+	return &clone, nil
+}
+
+func (s *inMemoryCacheStore) Set(ctx context.Context, key string, handle *CacheHandle) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clone := *handle
+	s.handles[key] = &clone
+	return nil
+}
+
+func (s *inMemoryCacheStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.handles, key)
+	return nil
+}
@@ -0,0 +1,211 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gemini
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+)
+
+// fakeCacheAPI is a fake of the cachedContents and generateContent methods
+// of the Gemini API.
+type fakeCacheAPI struct {
+	mu sync.Mutex
+	// ttl is the lifetime of the created cached contents.
+	ttl time.Duration
+	// failCreate fails the creations of cached contents.
+	failCreate bool
+	// created are the bodies of the creation requests.
+	created []map[string]any
+	// generated are the bodies of the generation requests.
+	generated []map[string]any
+	// missing are the names of the cached contents which aren't found.
+	missing map[string]bool
+}
+
+func (f *fakeCacheAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var body map[string]any
+	data, _ := io.ReadAll(r.Body)
+	_ = json.Unmarshal(data, &body)
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/cachedContents"):
+		if f.failCreate {
+			http.Error(w, `{"error": {"code": 400, "message": "too few tokens", "status": "INVALID_ARGUMENT"}}`, http.StatusBadRequest)
+			return
+		}
+		f.created = append(f.created, body)
+		json.NewEncoder(w).Encode(map[string]any{
+			"name":       "cachedContents/" + string(rune('a'+len(f.created)-1)),
+			"expireTime": time.Now().Add(f.ttl).Format(time.RFC3339),
+		})
+	case strings.HasSuffix(r.URL.Path, ":generateContent"):
+		if name, _ := body["cachedContent"].(string); f.missing[name] {
+			http.Error(w, `{"error": {"code": 404, "message": "not found", "status": "NOT_FOUND"}}`, http.StatusNotFound)
+			return
+		}
+		f.generated = append(f.generated, body)
+		cached := 0
+		if body["cachedContent"] != nil {
+			cached = 100
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"candidates":    []any{map[string]any{"content": map[string]any{"role": "model", "parts": []any{map[string]any{"text": "ok"}}}}},
+			"usageMetadata": map[string]any{"promptTokenCount": 110, "cachedContentTokenCount": cached},
+		})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newCachedTestModel(t *testing.T, api *fakeCacheAPI, cfg CacheConfig) model.LLM {
+	t.Helper()
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+	llm, err := NewCachedModel(t.Context(), "gemini-2.5-flash", &genai.ClientConfig{
+		APIKey:      "fake",
+		Backend:     genai.BackendGeminiAPI,
+		HTTPOptions: genai.HTTPOptions{BaseURL: server.URL},
+	}, cfg)
+	if err != nil {
+		t.Fatalf("NewCachedModel() error = %v", err)
+	}
+	return llm
+}
+
+func cacheTestRequest(instruction string) *model.LLMRequest {
+	return &model.LLMRequest{
+		Contents: []*genai.Content{genai.NewContentFromText("hi", genai.RoleUser)},
+		Config: &genai.GenerateContentConfig{
+			SystemInstruction: genai.NewContentFromText(instruction, genai.RoleUser),
+		},
+	}
+}
+
+// generateCacheMetadata calls the model and returns the cache metadata of
+// its response.
+func generateCacheMetadata(t *testing.T, llm model.LLM, req *model.LLMRequest) any {
+	t.Helper()
+	var metadata any
+	for resp, err := range llm.GenerateContent(t.Context(), req, false) {
+		if err != nil {
+			t.Fatalf("GenerateContent() error = %v", err)
+		}
+		metadata = resp.CustomMetadata[CacheMetadataKey]
+	}
+	return metadata
+}
+
+func TestCachedModel(t *testing.T) {
+	api := &fakeCacheAPI{ttl: time.Hour}
+	llm := newCachedTestModel(t, api, CacheConfig{
+		Documents: []*genai.Content{genai.NewContentFromText("document", genai.RoleUser)},
+	})
+
+	req := cacheTestRequest("instruction")
+	if got := generateCacheMetadata(t, llm, req); got.(map[string]any)["hit"] != false {
+		t.Errorf("first call: cache metadata = %v, want a miss", got)
+	}
+	if req.Config.SystemInstruction == nil {
+		t.Errorf("GenerateContent() cleared the system instruction of the request")
+	}
+	if got := generateCacheMetadata(t, llm, cacheTestRequest("instruction")); got.(map[string]any)["hit"] != true {
+		t.Errorf("second call: cache metadata = %v, want a hit", got)
+	}
+	if got, want := len(api.created), 1; got != want {
+		t.Fatalf("created cached contents = %d, want %d", got, want)
+	}
+	wantCreated := map[string]any{
+		"contents":          []any{map[string]any{"role": "user", "parts": []any{map[string]any{"text": "document"}}}},
+		"systemInstruction": map[string]any{"role": "user", "parts": []any{map[string]any{"text": "instruction"}}},
+		"model":             "models/gemini-2.5-flash",
+		"ttl":               "3600s",
+	}
+	if diff := cmp.Diff(wantCreated, api.created[0]); diff != "" {
+		t.Errorf("created cached content mismatch (-want +got):\n%s", diff)
+	}
+	for i, body := range api.generated {
+		if body["cachedContent"] != "cachedContents/a" || body["systemInstruction"] != nil {
+			t.Errorf("request %d = %v, want a reference to the cached content without system instruction", i, body)
+		}
+		if got := len(body["contents"].([]any)); got != 1 {
+			t.Errorf("request %d has %d contents, want 1", i, got)
+		}
+	}
+
+	// A change of the prefix creates another cached content.
+	if got := generateCacheMetadata(t, llm, cacheTestRequest("other instruction")); got.(map[string]any)["name"] != "cachedContents/b" {
+		t.Errorf("changed prefix: cache metadata = %v, want cachedContents/b", got)
+	}
+}
+
+func TestCachedModel_Expired(t *testing.T) {
+	// The cached contents expire within the expiry margin, so they are
+	// recreated by every request.
+	api := &fakeCacheAPI{ttl: time.Second}
+	llm := newCachedTestModel(t, api, CacheConfig{})
+
+	for range 2 {
+		generateCacheMetadata(t, llm, cacheTestRequest("instruction"))
+	}
+	if got, want := len(api.created), 2; got != want {
+		t.Errorf("created cached contents = %d, want %d", got, want)
+	}
+}
+
+func TestCachedModel_NotFound(t *testing.T) {
+	api := &fakeCacheAPI{ttl: time.Hour, missing: map[string]bool{}}
+	llm := newCachedTestModel(t, api, CacheConfig{})
+
+	generateCacheMetadata(t, llm, cacheTestRequest("instruction"))
+	api.missing["cachedContents/a"] = true
+	got := generateCacheMetadata(t, llm, cacheTestRequest("instruction"))
+	if got.(map[string]any)["name"] != "cachedContents/b" || got.(map[string]any)["hit"] != false {
+		t.Errorf("cache metadata = %v, want a miss of cachedContents/b", got)
+	}
+}
+
+func TestCachedModel_CreateFailure(t *testing.T) {
+	api := &fakeCacheAPI{ttl: time.Hour, failCreate: true}
+	llm := newCachedTestModel(t, api, CacheConfig{
+		Documents: []*genai.Content{genai.NewContentFromText("document", genai.RoleUser)},
+	})
+
+	for range 2 {
+		if got := generateCacheMetadata(t, llm, cacheTestRequest("instruction")); got != nil {
+			t.Errorf("cache metadata = %v, want none", got)
+		}
+	}
+	for i, body := range api.generated {
+		if body["cachedContent"] != nil || body["systemInstruction"] == nil {
+			t.Errorf("request %d = %v, want the request without cache", i, body)
+		}
+		if got := len(body["contents"].([]any)); got != 2 {
+			t.Errorf("request %d has %d contents, want the document and the user content", i, got)
+		}
+	}
+}
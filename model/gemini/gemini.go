@@ -20,22 +20,23 @@ import (
 	"fmt"
 	"iter"
 	"net/http"
-	"runtime"
-	"strings"
 
 	"google.golang.org/genai"
 
 	"google.golang.org/adk/internal/llminternal"
 	"google.golang.org/adk/internal/llminternal/converters"
+	"google.golang.org/adk/internal/llminternal/googlellm"
 	"google.golang.org/adk/internal/version"
 	"google.golang.org/adk/model"
 )
 
 // TODO: test coverage
 type geminiModel struct {
-	client             *genai.Client
-	name               string
-	versionHeaderValue string
+	client *genai.Client
+	name   string
+	// cache caches the static prefix of the requests, if set by
+	// [NewCachedModel].
+	cache *contextCache
 }
 
 // NewModel returns [model.LLM], backed by the Gemini API.
@@ -51,14 +52,9 @@ func NewModel(ctx context.Context, modelName string, cfg *genai.ClientConfig) (m
 		return nil, err
 	}
 
-	// Create header value once, when the model is created
-	headerValue := fmt.Sprintf("google-adk/%s gl-go/%s", version.Version,
-		strings.TrimPrefix(runtime.Version(), "go"))
-
 	return &geminiModel{
-		name:               modelName,
-		client:             client,
-		versionHeaderValue: headerValue,
+		name:   modelName,
+		client: client,
 	}, nil
 }
 
@@ -66,6 +62,12 @@ func (m *geminiModel) Name() string {
 	return m.name
 }
 
+// SupportsThinking reports whether the model is a Gemini 2.5+ or a thinking
+// model.
+func (m *geminiModel) SupportsThinking() bool {
+	return googlellm.SupportsThinking(m.name)
+}
+
 // GenerateContent calls the underlying model.
 func (m *geminiModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
 	m.maybeAppendUserContent(req)
@@ -78,27 +80,104 @@ func (m *geminiModel) GenerateContent(ctx context.Context, req *model.LLMRequest
 	if req.Config.HTTPOptions.Headers == nil {
 		req.Config.HTTPOptions.Headers = make(http.Header)
 	}
-	m.addHeaders(req.Config.HTTPOptions.Headers)
+	m.addHeaders(ctx, req.Config.HTTPOptions.Headers)
+
+	if m.cache != nil {
+		return m.generateCached(ctx, req, stream)
+	}
+	return m.call(ctx, req.Contents, req.Config, stream)
+}
 
+// call calls the model with the contents and the config.
+func (m *geminiModel) call(ctx context.Context, contents []*genai.Content, config *genai.GenerateContentConfig, stream bool) iter.Seq2[*model.LLMResponse, error] {
 	if stream {
-		return m.generateStream(ctx, req)
+		return m.generateStream(ctx, contents, config)
 	}
 
 	return func(yield func(*model.LLMResponse, error) bool) {
-		resp, err := m.generate(ctx, req)
+		resp, err := m.generate(ctx, contents, config)
 		yield(resp, err)
 	}
 }
 
-// addHeaders sets the x-goog-api-client and user-agent headers
-func (m *geminiModel) addHeaders(headers http.Header) {
-	headers.Set("x-goog-api-client", m.versionHeaderValue)
-	headers.Set("user-agent", m.versionHeaderValue)
+// generateCached calls the model with the static prefix of the request
+// replaced by a cached content. If the cached content isn't found, e.g.
+// because it was deleted before it expired, it is recreated and the call is
+// retried once, unless a response was already yielded.
+func (m *geminiModel) generateCached(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		for attempt := 0; ; attempt++ {
+			cached, err := m.cache.prepare(ctx, m, req)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if cached == nil {
+				for resp, err := range m.call(ctx, m.cache.uncachedContents(req.Contents), req.Config, stream) {
+					if !yield(resp, err) {
+						return
+					}
+				}
+				return
+			}
+
+			yielded := false
+			for resp, err := range m.call(ctx, cached.contents, cached.config, stream) {
+				if err != nil && !yielded && attempt == 0 && isCacheNotFound(err) {
+					m.cache.invalidate(ctx, cached)
+					break
+				}
+				yielded = true
+				cached.record(resp)
+				if !yield(resp, err) {
+					return
+				}
+			}
+			if yielded || attempt > 0 {
+				return
+			}
+		}
+	}
+}
+
+// CountTokens counts the tokens of the request with the count-tokens API.
+// The Gemini API only counts the tokens of the contents, those of the system
+// instruction and the tools are estimated with [model.EstimateTokens].
+func (m *geminiModel) CountTokens(ctx context.Context, req *model.LLMRequest) (int, error) {
+	contents := req.Contents
+	if m.cache != nil {
+		contents = m.cache.uncachedContents(contents)
+	}
+	var cfg *genai.CountTokensConfig
+	estimated := 0
+	if req.Config != nil {
+		if m.client.ClientConfig().Backend == genai.BackendVertexAI {
+			cfg = &genai.CountTokensConfig{SystemInstruction: req.Config.SystemInstruction, Tools: req.Config.Tools}
+		} else {
+			estimated = model.EstimateTokens(&model.LLMRequest{Config: &genai.GenerateContentConfig{
+				SystemInstruction: req.Config.SystemInstruction,
+				Tools:             req.Config.Tools,
+			}})
+		}
+	}
+	resp, err := m.client.Models.CountTokens(ctx, m.name, contents, cfg)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count tokens: %w", err)
+	}
+	return int(resp.TotalTokens) + estimated, nil
+}
+
+// addHeaders sets the x-goog-api-client and user-agent headers, which include
+// the products added to ctx with [version.WithUserAgent].
+func (m *geminiModel) addHeaders(ctx context.Context, headers http.Header) {
+	headerValue := version.UserAgent(ctx)
+	headers.Set("x-goog-api-client", headerValue)
+	headers.Set("user-agent", headerValue)
 }
 
 // generate calls the model synchronously returning result from the first candidate.
-func (m *geminiModel) generate(ctx context.Context, req *model.LLMRequest) (*model.LLMResponse, error) {
-	resp, err := m.client.Models.GenerateContent(ctx, m.name, req.Contents, req.Config)
+func (m *geminiModel) generate(ctx context.Context, contents []*genai.Content, config *genai.GenerateContentConfig) (*model.LLMResponse, error) {
+	resp, err := m.client.Models.GenerateContent(ctx, m.name, contents, config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to call model: %w", err)
 	}
@@ -110,11 +189,11 @@ func (m *geminiModel) generate(ctx context.Context, req *model.LLMRequest) (*mod
 }
 
 // generateStream returns a stream of responses from the model.
-func (m *geminiModel) generateStream(ctx context.Context, req *model.LLMRequest) iter.Seq2[*model.LLMResponse, error] {
+func (m *geminiModel) generateStream(ctx context.Context, contents []*genai.Content, config *genai.GenerateContentConfig) iter.Seq2[*model.LLMResponse, error] {
 	aggregator := llminternal.NewStreamingResponseAggregator()
 
 	return func(yield func(*model.LLMResponse, error) bool) {
-		for resp, err := range m.client.Models.GenerateContentStream(ctx, m.name, req.Contents, req.Config) {
+		for resp, err := range m.client.Models.GenerateContentStream(ctx, m.name, contents, config) {
 			if err != nil {
 				yield(nil, err)
 				return
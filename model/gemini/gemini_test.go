@@ -15,9 +15,11 @@
 package gemini
 
 import (
+	"encoding/json"
 	"fmt"
 	"iter"
 	"net/http"
+	"net/http/httptest"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -28,6 +30,7 @@ import (
 
 	"google.golang.org/adk/internal/httprr"
 	"google.golang.org/adk/internal/testutil"
+	"google.golang.org/adk/internal/version"
 	"google.golang.org/adk/model"
 )
 
@@ -155,6 +158,9 @@ func TestModel_TrackingHeaders(t *testing.T) {
 				if xgac := req.Header.Get("x-goog-api-client"); !strings.Contains(xgac, "google-adk/") || !strings.Contains(xgac, "gl-go/") {
 					t.Errorf("x-goog-api-client header should contain both 'google-adk/' and 'gl-go/', but got: %q", xgac)
 				}
+				if ua := req.Header.Get("User-Agent"); !strings.HasSuffix(ua, " adk-test/1.0") {
+					t.Errorf("User-Agent header should end with the product of the context, but got: %q", ua)
+				}
 			},
 		}
 
@@ -176,7 +182,8 @@ func TestModel_TrackingHeaders(t *testing.T) {
 		// Trigger a request to fire the interceptor.
 		// We don't strictly care about the success of the call, only that it was attempted with headers.
 		req := &model.LLMRequest{Contents: genai.Text("ping")}
-		for _, err := range geminiModel.GenerateContent(t.Context(), req, false) {
+		ctx := version.WithUserAgent(t.Context(), "adk-test/1.0")
+		for _, err := range geminiModel.GenerateContent(ctx, req, false) {
 			if err != nil {
 				t.Logf("GenerateContent finished with error (expected if no recording exists): %v", err)
 			}
@@ -243,3 +250,45 @@ func (h *headerInterceptor) RoundTrip(req *http.Request) (*http.Response, error)
 	}
 	return h.base.RoundTrip(req)
 }
+
+func TestModel_CountTokens(t *testing.T) {
+	var gotBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, ":countTokens") {
+			http.NotFound(w, r)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&gotBody); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		fmt.Fprint(w, `{"totalTokens": 7}`)
+	}))
+	defer server.Close()
+
+	llm, err := NewModel(t.Context(), "gemini-2.5-flash", &genai.ClientConfig{
+		APIKey:      "fake",
+		Backend:     genai.BackendGeminiAPI,
+		HTTPOptions: genai.HTTPOptions{BaseURL: server.URL},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &model.LLMRequest{
+		Contents: []*genai.Content{genai.NewContentFromText("Hello", genai.RoleUser)},
+		Config: &genai.GenerateContentConfig{
+			SystemInstruction: genai.NewContentFromText("12345678", genai.RoleUser),
+		},
+	}
+	got, err := model.CountTokens(t.Context(), llm, req)
+	if err != nil {
+		t.Fatalf("CountTokens() error = %v", err)
+	}
+	// The Gemini API counts the contents, the system instruction is
+	// estimated.
+	if want := 7 + 2; got != want {
+		t.Errorf("CountTokens() = %d, want %d", got, want)
+	}
+	if _, ok := gotBody["systemInstruction"]; ok {
+		t.Errorf("request = %v, want no system instruction", gotBody)
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gemini
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"strings"
+	"sync/atomic"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+)
+
+var _ model.LiveLLM = (*geminiModel)(nil)
+
+// Connect opens a session of the Gemini Live API, see [model.LiveLLM]. The
+// client of the model must be configured with the API version of the Live
+// API, e.g. v1alpha for the Gemini API.
+func (m *geminiModel) Connect(ctx context.Context, req *model.LLMRequest) (model.LiveSession, error) {
+	session, err := m.client.Live.Connect(ctx, m.name, liveConnectConfig(req.Config))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the live API: %w", err)
+	}
+	if len(req.Contents) > 0 {
+		// The history doesn't start a turn of the model.
+		complete := false
+		if err := session.SendClientContent(genai.LiveClientContentInput{Turns: req.Contents, TurnComplete: &complete}); err != nil {
+			session.Close()
+			return nil, fmt.Errorf("failed to send the history to the live API: %w", err)
+		}
+	}
+	return &liveSession{session: session}, nil
+}
+
+// liveConnectConfig returns the configuration of a live session with the
+// generation config of a request.
+func liveConnectConfig(cfg *genai.GenerateContentConfig) *genai.LiveConnectConfig {
+	if cfg == nil {
+		return &genai.LiveConnectConfig{}
+	}
+	live := &genai.LiveConnectConfig{
+		SystemInstruction: cfg.SystemInstruction,
+		Tools:             cfg.Tools,
+		Temperature:       cfg.Temperature,
+		TopP:              cfg.TopP,
+		TopK:              cfg.TopK,
+		MaxOutputTokens:   cfg.MaxOutputTokens,
+		Seed:              cfg.Seed,
+		MediaResolution:   cfg.MediaResolution,
+		SpeechConfig:      cfg.SpeechConfig,
+		ThinkingConfig:    cfg.ThinkingConfig,
+	}
+	for _, modality := range cfg.ResponseModalities {
+		live.ResponseModalities = append(live.ResponseModalities, genai.Modality(modality))
+	}
+	return live
+}
+
+// liveSession is a session of the Gemini Live API.
+type liveSession struct {
+	session *genai.Session
+	closed  atomic.Bool
+}
+
+func (s *liveSession) SendContent(content *genai.Content) error {
+	var responses []*genai.FunctionResponse
+	for _, part := range content.Parts {
+		if part.FunctionResponse != nil {
+			responses = append(responses, part.FunctionResponse)
+		}
+	}
+	if len(responses) > 0 {
+		return s.session.SendToolResponse(genai.LiveToolResponseInput{FunctionResponses: responses})
+	}
+	complete := true
+	return s.session.SendClientContent(genai.LiveClientContentInput{Turns: []*genai.Content{content}, TurnComplete: &complete})
+}
+
+func (s *liveSession) SendRealtimeInput(blob *genai.Blob) error {
+	var input genai.LiveRealtimeInput
+	switch {
+	case strings.HasPrefix(blob.MIMEType, "audio/"):
+		input.Audio = blob
+	case strings.HasPrefix(blob.MIMEType, "image/"), strings.HasPrefix(blob.MIMEType, "video/"):
+		input.Video = blob
+	default:
+		input.Media = blob
+	}
+	return s.session.SendRealtimeInput(input)
+}
+
+func (s *liveSession) Receive() iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		// text is the text of the current turn of the model, whose chunks
+		// are partial responses.
+		var text strings.Builder
+		for {
+			msg, err := s.session.Receive()
+			if err != nil {
+				if !s.closed.Load() {
+					yield(nil, fmt.Errorf("failed to receive from the live API: %w", err))
+				}
+				return
+			}
+			for _, resp := range liveResponses(msg, &text) {
+				if !yield(resp, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (s *liveSession) Close() error {
+	s.closed.Store(true)
+	return s.session.Close()
+}
+
+// liveResponses returns the responses of a message of the live API. The
+// chunks of the turns of the model are partial, and the end of a turn is a
+// final response with the text of the turn.
+func liveResponses(msg *genai.LiveServerMessage, text *strings.Builder) []*model.LLMResponse {
+	var responses []*model.LLMResponse
+	if content := msg.ServerContent; content != nil {
+		if content.ModelTurn != nil {
+			for _, part := range content.ModelTurn.Parts {
+				if part.Text != "" && !part.Thought {
+					text.WriteString(part.Text)
+				}
+			}
+			responses = append(responses, &model.LLMResponse{
+				Content:           content.ModelTurn,
+				GroundingMetadata: content.GroundingMetadata,
+				Partial:           true,
+			})
+		}
+		if content.TurnComplete || content.Interrupted {
+			resp := &model.LLMResponse{TurnComplete: content.TurnComplete, Interrupted: content.Interrupted}
+			if text.Len() > 0 {
+				resp.Content = genai.NewContentFromText(text.String(), genai.RoleModel)
+				text.Reset()
+			}
+			responses = append(responses, resp)
+		}
+	}
+	if call := msg.ToolCall; call != nil && len(call.FunctionCalls) > 0 {
+		content := &genai.Content{Role: genai.RoleModel}
+		for _, fc := range call.FunctionCalls {
+			content.Parts = append(content.Parts, &genai.Part{FunctionCall: fc})
+		}
+		responses = append(responses, &model.LLMResponse{Content: content})
+	}
+	return responses
+}
@@ -0,0 +1,52 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gemini
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+)
+
+func TestLiveResponses(t *testing.T) {
+	fc := &genai.FunctionCall{ID: "1", Name: "get_weather", Args: map[string]any{"city": "Paris"}}
+	msgs := []*genai.LiveServerMessage{
+		{ServerContent: &genai.LiveServerContent{ModelTurn: genai.NewContentFromText("Hello, ", genai.RoleModel)}},
+		{ServerContent: &genai.LiveServerContent{ModelTurn: genai.NewContentFromText("world", genai.RoleModel)}},
+		{ServerContent: &genai.LiveServerContent{TurnComplete: true}},
+		{ServerContent: &genai.LiveServerContent{Interrupted: true}},
+		{ToolCall: &genai.LiveServerToolCall{FunctionCalls: []*genai.FunctionCall{fc}}},
+	}
+	want := []*model.LLMResponse{
+		{Content: genai.NewContentFromText("Hello, ", genai.RoleModel), Partial: true},
+		{Content: genai.NewContentFromText("world", genai.RoleModel), Partial: true},
+		{Content: genai.NewContentFromText("Hello, world", genai.RoleModel), TurnComplete: true},
+		{Interrupted: true},
+		{Content: &genai.Content{Role: genai.RoleModel, Parts: []*genai.Part{{FunctionCall: fc}}}},
+	}
+
+	var text strings.Builder
+	var got []*model.LLMResponse
+	for _, msg := range msgs {
+		got = append(got, liveResponses(msg, &text)...)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("liveResponses() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+
+	"google.golang.org/genai"
+)
+
+// Interceptor intercepts the requests and the responses of a model, e.g. to
+// log or to redact them. Both of its functions are optional.
+type Interceptor struct {
+	// Request is called before the model is called, and may modify the
+	// request: its contents and its system instruction are copies of those
+	// of the caller. An error vetoes the call, which fails with the error.
+	Request func(ctx context.Context, req *LLMRequest) error
+	// Response is called with each response of the model, including the
+	// partial responses of streams as soon as they are received, and may
+	// modify it. An error ends the call, which fails with the error.
+	Response func(ctx context.Context, req *LLMRequest, resp *LLMResponse) error
+}
+
+type interceptedModel struct {
+	llm          LLM
+	interceptors []Interceptor
+}
+
+// WithInterceptor returns the model whose requests and responses pass
+// through the interceptors. The requests pass through the interceptors in
+// order, and the responses in reverse order, so that the first interceptor
+// sees the requests as sent by the caller and the responses as returned to
+// it.
+//
+// The tokens of the requests are counted after their interception, see
+// [CountTokens].
+func WithInterceptor(llm LLM, interceptors ...Interceptor) LLM {
+	return &interceptedModel{llm: llm, interceptors: interceptors}
+}
+
+func (m *interceptedModel) Name() string {
+	return m.llm.Name()
+}
+
+// CountTokens counts the tokens of the intercepted request with the model,
+// as counting them may send the request to the model API.
+func (m *interceptedModel) CountTokens(ctx context.Context, req *LLMRequest) (int, error) {
+	req, err := m.interceptRequest(ctx, req)
+	if err != nil {
+		return 0, err
+	}
+	return CountTokens(ctx, m.llm, req)
+}
+
+// SupportsThinking reports whether the intercepted model supports thinking.
+func (m *interceptedModel) SupportsThinking() bool {
+	return SupportsThinking(m.llm)
+}
+
+// SupportsLive reports whether the intercepted model supports live sessions.
+func (m *interceptedModel) SupportsLive() bool {
+	return SupportsLive(m.llm)
+}
+
+// Connect opens a live session of the intercepted model with the intercepted
+// request. The responses of the session pass through the interceptors too.
+func (m *interceptedModel) Connect(ctx context.Context, req *LLMRequest) (LiveSession, error) {
+	req, err := m.interceptRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	session, err := Connect(ctx, m.llm, req)
+	if err != nil {
+		return nil, err
+	}
+	return &interceptedSession{LiveSession: session, ctx: ctx, req: req, model: m}, nil
+}
+
+// interceptedSession is a live session of an intercepted model.
+type interceptedSession struct {
+	LiveSession
+	ctx   context.Context
+	req   *LLMRequest
+	model *interceptedModel
+}
+
+func (s *interceptedSession) Receive() iter.Seq2[*LLMResponse, error] {
+	return func(yield func(*LLMResponse, error) bool) {
+		for resp, err := range s.LiveSession.Receive() {
+			if err == nil && resp != nil {
+				if err := s.model.interceptResponse(s.ctx, s.req, resp); err != nil {
+					yield(nil, err)
+					return
+				}
+			}
+			if !yield(resp, err) {
+				return
+			}
+		}
+	}
+}
+
+func (m *interceptedModel) GenerateContent(ctx context.Context, req *LLMRequest, stream bool) iter.Seq2[*LLMResponse, error] {
+	return func(yield func(*LLMResponse, error) bool) {
+		req, err := m.interceptRequest(ctx, req)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		for resp, err := range m.llm.GenerateContent(ctx, req, stream) {
+			if err == nil && resp != nil {
+				if err := m.interceptResponse(ctx, req, resp); err != nil {
+					yield(nil, err)
+					return
+				}
+			}
+			if !yield(resp, err) {
+				return
+			}
+		}
+	}
+}
+
+// interceptRequest returns the copy of the request intercepted by the
+// interceptors.
+func (m *interceptedModel) interceptRequest(ctx context.Context, req *LLMRequest) (*LLMRequest, error) {
+	intercepted := *req
+	contents, err := cloneContents(req.Contents)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy request contents: %w", err)
+	}
+	intercepted.Contents = contents
+	if req.Config != nil {
+		config := *req.Config
+		if config.SystemInstruction, err = cloneContents(req.Config.SystemInstruction); err != nil {
+			return nil, fmt.Errorf("failed to copy request system instruction: %w", err)
+		}
+		intercepted.Config = &config
+	}
+	for _, interceptor := range m.interceptors {
+		if interceptor.Request == nil {
+			continue
+		}
+		if err := interceptor.Request(ctx, &intercepted); err != nil {
+			return nil, fmt.Errorf("model call vetoed by interceptor: %w", err)
+		}
+	}
+	return &intercepted, nil
+}
+
+func (m *interceptedModel) interceptResponse(ctx context.Context, req *LLMRequest, resp *LLMResponse) error {
+	for i := len(m.interceptors) - 1; i >= 0; i-- {
+		if f := m.interceptors[i].Response; f != nil {
+			if err := f(ctx, req, resp); err != nil {
+				return fmt.Errorf("model response rejected by interceptor: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// cloneContents returns a deep copy of the contents.
+func cloneContents[T []*genai.Content | *genai.Content](v T) (T, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var clone T
+	if err := json.Unmarshal(data, &clone); err != nil {
+		return nil, err
+	}
+	return clone, nil
+}
@@ -0,0 +1,154 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+)
+
+// streamingModel streams the texts of its chunks, and records its request
+// and the chunks in the log.
+type streamingModel struct {
+	chunks []string
+	log    *[]string
+	req    *model.LLMRequest
+}
+
+func (m *streamingModel) Name() string { return "streaming" }
+
+func (m *streamingModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		m.req = req
+		for _, chunk := range m.chunks {
+			*m.log = append(*m.log, "model: "+chunk)
+			if !yield(&model.LLMResponse{Content: genai.NewContentFromText(chunk, genai.RoleModel), Partial: true}, nil) {
+				return
+			}
+		}
+	}
+}
+
+// appendInterceptor appends its name to the texts of the requests and of
+// the responses, and records them in the log.
+func appendInterceptor(name string, log *[]string) model.Interceptor {
+	return model.Interceptor{
+		Request: func(ctx context.Context, req *model.LLMRequest) error {
+			*log = append(*log, "request: "+name)
+			req.Contents[0].Parts[0].Text += " " + name
+			return nil
+		},
+		Response: func(ctx context.Context, req *model.LLMRequest, resp *model.LLMResponse) error {
+			*log = append(*log, "response: "+name)
+			resp.Content.Parts[0].Text += " " + name
+			return nil
+		},
+	}
+}
+
+func TestWithInterceptor(t *testing.T) {
+	var log []string
+	llm := &streamingModel{chunks: []string{"a", "b"}, log: &log}
+	req := &model.LLMRequest{Contents: []*genai.Content{genai.NewContentFromText("hi", genai.RoleUser)}}
+
+	var got []string
+	for resp, err := range model.WithInterceptor(llm, appendInterceptor("first", &log), appendInterceptor("second", &log)).GenerateContent(t.Context(), req, true) {
+		if err != nil {
+			t.Fatalf("GenerateContent() error = %v", err)
+		}
+		got = append(got, resp.Content.Parts[0].Text)
+	}
+
+	if diff := cmp.Diff([]string{"a second first", "b second first"}, got); diff != "" {
+		t.Errorf("responses mismatch (-want +got):\n%s", diff)
+	}
+	if got, want := llm.req.Contents[0].Parts[0].Text, "hi first second"; got != want {
+		t.Errorf("model request text = %q, want %q", got, want)
+	}
+	if got, want := req.Contents[0].Parts[0].Text, "hi"; got != want {
+		t.Errorf("caller request text = %q, want %q", got, want)
+	}
+	// The chunks are intercepted as they are streamed.
+	wantLog := []string{
+		"request: first", "request: second",
+		"model: a", "response: second", "response: first",
+		"model: b", "response: second", "response: first",
+	}
+	if diff := cmp.Diff(wantLog, log); diff != "" {
+		t.Errorf("log mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWithInterceptor_Errors(t *testing.T) {
+	errVeto := errors.New("veto")
+	tests := []struct {
+		name        string
+		interceptor model.Interceptor
+		wantTexts   []string
+		wantCalled  bool
+	}{
+		{
+			name: "request_veto",
+			interceptor: model.Interceptor{
+				Request: func(ctx context.Context, req *model.LLMRequest) error { return errVeto },
+			},
+		},
+		{
+			name: "response_error",
+			interceptor: model.Interceptor{
+				Response: func(ctx context.Context, req *model.LLMRequest, resp *model.LLMResponse) error {
+					if resp.Content.Parts[0].Text == "b" {
+						return errVeto
+					}
+					return nil
+				},
+			},
+			wantTexts:  []string{"a"},
+			wantCalled: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var log []string
+			llm := &streamingModel{chunks: []string{"a", "b", "c"}, log: &log}
+			req := &model.LLMRequest{Contents: []*genai.Content{genai.NewContentFromText("hi", genai.RoleUser)}}
+			var texts []string
+			var gotErr error
+			for resp, err := range model.WithInterceptor(llm, tt.interceptor).GenerateContent(t.Context(), req, true) {
+				if err != nil {
+					gotErr = err
+					continue
+				}
+				texts = append(texts, resp.Content.Parts[0].Text)
+			}
+			if !errors.Is(gotErr, errVeto) {
+				t.Errorf("GenerateContent() error = %v, want %v", gotErr, errVeto)
+			}
+			if diff := cmp.Diff(tt.wantTexts, texts); diff != "" {
+				t.Errorf("responses mismatch (-want +got):\n%s", diff)
+			}
+			if called := llm.req != nil; called != tt.wantCalled {
+				t.Errorf("model called = %v, want %v", called, tt.wantCalled)
+			}
+		})
+	}
+}
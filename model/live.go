@@ -0,0 +1,88 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+
+	"google.golang.org/genai"
+)
+
+// LiveLLM is implemented by the models with a bidirectional streaming API,
+// e.g. the Gemini Live API, which take realtime media such as audio. The
+// agents run with such models in the live runs, see
+// [google.golang.org/adk/runner.Runner.RunLive]. The model wrappers of this
+// package implement it too, see [SupportsLive].
+type LiveLLM interface {
+	LLM
+	// Connect opens a live session of the model, configured by the
+	// request. The contents of the request are sent as the history of the
+	// session.
+	Connect(ctx context.Context, req *LLMRequest) (LiveSession, error)
+}
+
+// LiveSupporter is implemented by the live models which know whether they
+// support live sessions, e.g. the model wrappers of this package, which
+// implement [LiveLLM] whatever the models they wrap.
+type LiveSupporter interface {
+	SupportsLive() bool
+}
+
+// ErrLiveUnsupported is the error of [Connect] for the models which don't
+// support live sessions.
+var ErrLiveUnsupported = errors.New("model doesn't support live sessions")
+
+// SupportsLive reports whether the model supports live sessions: it
+// implements [LiveLLM], and reports it supports them if it implements
+// [LiveSupporter]. The model wrappers of this package report whether the
+// models they wrap support them, and the fallback models whether any of them
+// does.
+func SupportsLive(llm LLM) bool {
+	if _, ok := llm.(LiveLLM); !ok {
+		return false
+	}
+	supporter, ok := llm.(LiveSupporter)
+	return !ok || supporter.SupportsLive()
+}
+
+// Connect opens a live session of the model, see [LiveLLM]. It fails with
+// [ErrLiveUnsupported] if the model doesn't support live sessions, see
+// [SupportsLive].
+func Connect(ctx context.Context, llm LLM, req *LLMRequest) (LiveSession, error) {
+	if !SupportsLive(llm) {
+		return nil, fmt.Errorf("model %q: %w", llm.Name(), ErrLiveUnsupported)
+	}
+	return llm.(LiveLLM).Connect(ctx, req)
+}
+
+// LiveSession is a live session of a [LiveLLM]. Its Send methods can be
+// called while the responses are received.
+type LiveSession interface {
+	// SendContent sends a turn of the user, or the responses to the
+	// function calls of the model.
+	SendContent(content *genai.Content) error
+	// SendRealtimeInput sends a chunk of realtime media, e.g. audio.
+	SendRealtimeInput(blob *genai.Blob) error
+	// Receive returns the responses of the model until the session ends.
+	// The chunks of the turns of the model are partial responses, and the
+	// end of each turn is a response with TurnComplete, or Interrupted if
+	// the user interrupted the model.
+	Receive() iter.Seq2[*LLMResponse, error]
+	// Close ends the session.
+	Close() error
+}
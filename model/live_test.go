@@ -0,0 +1,149 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"testing"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+)
+
+// liveModel is a live model whose sessions reply to the realtime inputs
+// with their MIME type.
+type liveModel struct {
+	fakeModel
+	connectErr error
+	connected  []*model.LLMRequest
+}
+
+func (m *liveModel) Connect(ctx context.Context, req *model.LLMRequest) (model.LiveSession, error) {
+	if m.connectErr != nil {
+		return nil, m.connectErr
+	}
+	m.connected = append(m.connected, req)
+	return &liveSession{}, nil
+}
+
+type liveSession struct {
+	blobs []*genai.Blob
+}
+
+func (s *liveSession) SendContent(content *genai.Content) error { return nil }
+
+func (s *liveSession) SendRealtimeInput(blob *genai.Blob) error {
+	s.blobs = append(s.blobs, blob)
+	return nil
+}
+
+func (s *liveSession) Receive() iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		for _, blob := range s.blobs {
+			if !yield(&model.LLMResponse{Content: genai.NewContentFromText(blob.MIMEType, genai.RoleModel), TurnComplete: true}, nil) {
+				return
+			}
+		}
+	}
+}
+
+func (s *liveSession) Close() error { return nil }
+
+func TestSupportsLive(t *testing.T) {
+	live, plain := &liveModel{fakeModel: fakeModel{name: "live"}}, &fakeModel{name: "plain"}
+	registry := model.NewRegistry()
+	if err := registry.Register("live*", func(context.Context, string) (model.LLM, error) { return live, nil }); err != nil {
+		t.Fatal(err)
+	}
+	lazy, err := registry.Resolve("live-model")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		llm  model.LLM
+		want bool
+	}{
+		{"live", live, true},
+		{"plain", plain, false},
+		{"retry", model.WithRetry(live, model.RetryPolicy{}), true},
+		{"retry of plain", model.WithRetry(plain, model.RetryPolicy{}), false},
+		{"interceptor", model.WithInterceptor(live), true},
+		{"cache", model.WithCache(live, model.NewLRUCache(1024), model.CacheConfig{}), true},
+		{"registry", lazy, true},
+		{"fallback", model.NewFallback(plain, live), true},
+		{"fallback to plain", model.NewFallback(plain, plain), false},
+	}
+	for _, tt := range tests {
+		if got := model.SupportsLive(tt.llm); got != tt.want {
+			t.Errorf("SupportsLive(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestConnect(t *testing.T) {
+	live := &liveModel{fakeModel: fakeModel{name: "live"}}
+	intercepted := model.WithRetry(model.WithInterceptor(live, model.Interceptor{
+		Request: func(ctx context.Context, req *model.LLMRequest) error {
+			req.Contents = append(req.Contents, genai.NewContentFromText("intercepted", genai.RoleUser))
+			return nil
+		},
+		Response: func(ctx context.Context, req *model.LLMRequest, resp *model.LLMResponse) error {
+			resp.Content.Parts[0].Text += " (intercepted)"
+			return nil
+		},
+	}), model.RetryPolicy{})
+
+	session, err := model.Connect(t.Context(), intercepted, &model.LLMRequest{})
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	if len(live.connected) != 1 || len(live.connected[0].Contents) != 1 {
+		t.Fatalf("requests of the live model = %v, want the intercepted request", live.connected)
+	}
+	if err := session.SendRealtimeInput(&genai.Blob{MIMEType: "audio/pcm", Data: []byte{0}}); err != nil {
+		t.Fatalf("SendRealtimeInput() error = %v", err)
+	}
+	var got []string
+	for resp, err := range session.Receive() {
+		if err != nil {
+			t.Fatalf("Receive() error = %v", err)
+		}
+		got = append(got, resp.Content.Parts[0].Text)
+	}
+	if len(got) != 1 || got[0] != "audio/pcm (intercepted)" {
+		t.Errorf("responses = %q, want the intercepted response to the blob", got)
+	}
+
+	if _, err := model.Connect(t.Context(), model.WithRetry(&fakeModel{name: "plain"}, model.RetryPolicy{}), &model.LLMRequest{}); !errors.Is(err, model.ErrLiveUnsupported) {
+		t.Errorf("Connect(plain) error = %v, want ErrLiveUnsupported", err)
+	}
+}
+
+func TestConnect_Fallback(t *testing.T) {
+	unavailable := &liveModel{fakeModel: fakeModel{name: "unavailable"}, connectErr: statusError(503)}
+	live := &liveModel{fakeModel: fakeModel{name: "live"}}
+	fallback := model.NewFallback(&fakeModel{name: "plain"}, unavailable, live)
+
+	if _, err := model.Connect(t.Context(), fallback, &model.LLMRequest{}); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	if len(live.connected) != 1 || live.connected[0].Model != "live" {
+		t.Errorf("requests of the live model = %v, want one for the live model", live.connected)
+	}
+}
@@ -18,6 +18,7 @@ package model
 import (
 	"context"
 	"iter"
+	"time"
 
 	"google.golang.org/genai"
 )
@@ -35,6 +36,18 @@ type LLMRequest struct {
 	Config   *genai.GenerateContentConfig
 
 	Tools map[string]any `json:"-"`
+	// CustomMetadata is added to the custom metadata of the model response
+	// events, e.g. for the request processors to record how they changed the
+	// request.
+	CustomMetadata map[string]any `json:"-"`
+
+	// Timeout bounds the duration of an unary call of the model, or the
+	// time to the first response of a streaming call. Zero means no
+	// timeout. See [GenerateWithTimeouts].
+	Timeout time.Duration `json:"-"`
+	// IdleTimeout bounds the time between the responses of a streaming
+	// call. Zero means no timeout.
+	IdleTimeout time.Duration `json:"-"`
 }
 
 // LLMResponse is the raw LLM response.
@@ -0,0 +1,112 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ollama
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"google.golang.org/genai"
+)
+
+// FallbackTool is a function declaration rendered by the
+// [Config.ToolFallbackTemplate].
+type FallbackTool struct {
+	Name        string
+	Description string
+	// Parameters is the JSON schema of the parameters of the function.
+	Parameters string
+}
+
+// DefaultToolFallbackTemplate is the default [Config.ToolFallbackTemplate].
+// It asks the model to reply with a JSON object to call a tool, which the
+// templates replacing it must keep asking for.
+var DefaultToolFallbackTemplate = template.Must(template.New("tools").Parse(`You can use the following tools:
+{{range .}}
+- {{.Name}}: {{.Description}}
+  Parameters (JSON schema): {{.Parameters}}
+{{end}}
+To use a tool, reply with only a JSON object of the form {"name": "<tool name>", "arguments": {<parameters>}}, and nothing else. The result of the tool will be sent to you in the next message. Otherwise, reply normally.`))
+
+// fallbackCall is the JSON object of a tool call in fallback mode.
+type fallbackCall struct {
+	Name      string         `json:"name"`
+	Arguments map[string]any `json:"arguments"`
+}
+
+// renderTools renders the function declarations with the template.
+func renderTools(tmpl *template.Template, decls []*genai.FunctionDeclaration) (string, error) {
+	tools := make([]FallbackTool, 0, len(decls))
+	for _, decl := range decls {
+		params, err := json.Marshal(parameters(decl))
+		if err != nil {
+			return "", fmt.Errorf("failed to encode parameters of function %q: %w", decl.Name, err)
+		}
+		tools = append(tools, FallbackTool{Name: decl.Name, Description: decl.Description, Parameters: string(params)})
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, tools); err != nil {
+		return "", fmt.Errorf("failed to render tool fallback template: %w", err)
+	}
+	return b.String(), nil
+}
+
+// parseFallbackCalls returns the function calls of the text of a reply in
+// fallback mode, a JSON object or array of objects possibly in a code
+// block. It returns nil if the text is not a call of the declared tools.
+func parseFallbackCalls(text string, tools map[string]bool) []*genai.Part {
+	text = strings.TrimSpace(text)
+	if body, ok := strings.CutPrefix(text, "```"); ok {
+		body = strings.TrimPrefix(body, "json")
+		body, ok = strings.CutSuffix(strings.TrimSpace(body), "```")
+		if !ok {
+			return nil
+		}
+		text = strings.TrimSpace(body)
+	}
+	if strings.HasPrefix(text, "{") {
+		text = "[" + text + "]"
+	}
+	if !strings.HasPrefix(text, "[") {
+		return nil
+	}
+	var calls []struct {
+		Name      string         `json:"name"`
+		Arguments map[string]any `json:"arguments"`
+		// Parameters is the arguments of the models replying with the
+		// name of the JSON schema.
+		Parameters map[string]any `json:"parameters"`
+	}
+	if err := json.Unmarshal([]byte(text), &calls); err != nil {
+		return nil
+	}
+	if len(calls) == 0 {
+		return nil
+	}
+	parts := make([]*genai.Part, 0, len(calls))
+	for _, call := range calls {
+		if !tools[call.Name] {
+			return nil
+		}
+		args := call.Arguments
+		if args == nil {
+			args = call.Parameters
+		}
+		parts = append(parts, &genai.Part{FunctionCall: &genai.FunctionCall{Name: call.Name, Args: args}})
+	}
+	return parts
+}
@@ -0,0 +1,295 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ollama implements the [model.LLM] interface for the models served
+// by a local Ollama daemon, with its chat API.
+//
+// The Ollama options of the requests, e.g. num_ctx, and their keep_alive are
+// set for all the requests of a model with its [Config], and for a request
+// with the ExtraBody of the HTTPOptions of its generation config:
+//
+//	Config: &genai.GenerateContentConfig{
+//		HTTPOptions: &genai.HTTPOptions{ExtraBody: map[string]any{
+//			"options":    map[string]any{"num_ctx": 8192},
+//			"keep_alive": "10m",
+//		}},
+//	}
+//
+// The function declarations of the requests to the models without tool
+// support are rendered into the system prompt with the
+// [Config.ToolFallbackTemplate], and the tool calls of the model parsed from
+// its JSON replies.
+package ollama
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"text/template"
+	"time"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/internal/llminternal"
+	"google.golang.org/adk/internal/version"
+	"google.golang.org/adk/model"
+)
+
+// DefaultHost is the default address of the Ollama daemon.
+const DefaultHost = "http://localhost:11434"
+
+// Config is the configuration of an Ollama model.
+type Config struct {
+	// Host is the address of the Ollama daemon. Defaults to the OLLAMA_HOST
+	// environment variable, and to [DefaultHost] if it is not set.
+	Host string
+	// HTTPClient sends the requests. Defaults to [http.DefaultClient].
+	HTTPClient *http.Client
+	// Options are the default Ollama options of the requests, e.g.
+	// {"num_ctx": 8192}. The generation config of the requests overrides
+	// them.
+	Options map[string]any
+	// KeepAlive is how long the model stays loaded after the requests,
+	// e.g. "10m", or "-1" to keep it loaded. Defaults to the Ollama
+	// default.
+	KeepAlive string
+	// ToolFallbackTemplate renders the function declarations of the
+	// requests to the models without tool support into their system prompt.
+	// It is executed with a []FallbackTool. Defaults to
+	// [DefaultToolFallbackTemplate].
+	ToolFallbackTemplate *template.Template
+}
+
+type ollamaModel struct {
+	name string
+	cfg  Config
+	// noTools reports whether the model turned out not to support tools.
+	noTools atomic.Bool
+}
+
+// NewModel returns [model.LLM], backed by the Ollama chat API.
+//
+// The modelName specifies which pulled model to target (e.g.,
+// "llama3.2").
+func NewModel(ctx context.Context, modelName string, cfg *Config) (model.LLM, error) {
+	var c Config
+	if cfg != nil {
+		c = *cfg
+	}
+	if c.Host == "" {
+		c.Host = os.Getenv("OLLAMA_HOST")
+	}
+	if c.Host == "" {
+		c.Host = DefaultHost
+	}
+	if !strings.Contains(c.Host, "://") {
+		// OLLAMA_HOST is usually a host and port.
+		c.Host = "http://" + c.Host
+	}
+	c.Host = strings.TrimSuffix(c.Host, "/")
+	if c.HTTPClient == nil {
+		c.HTTPClient = http.DefaultClient
+	}
+	if c.ToolFallbackTemplate == nil {
+		c.ToolFallbackTemplate = DefaultToolFallbackTemplate
+	}
+	return &ollamaModel{name: modelName, cfg: c}, nil
+}
+
+func (m *ollamaModel) Name() string {
+	return m.name
+}
+
+// GenerateContent calls the underlying model.
+func (m *ollamaModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		fallback := m.noTools.Load()
+		resp, tools, err := m.chat(ctx, req, stream, fallback)
+		var apiErr *APIError
+		if !fallback && errors.As(err, &apiErr) && apiErr.toolsUnsupported() {
+			// The model doesn't support tools, which the following
+			// requests render into the prompt right away.
+			m.noTools.Store(true)
+			fallback = true
+			resp, tools, err = m.chat(ctx, req, stream, fallback)
+		}
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		defer resp.Body.Close()
+
+		if !stream {
+			var chunk chatResponse
+			if err := json.NewDecoder(resp.Body).Decode(&chunk); err != nil {
+				yield(nil, fmt.Errorf("failed to decode model response: %w", err))
+				return
+			}
+			yield(chunk.llmResponse(tools), nil)
+			return
+		}
+
+		aggregator := llminternal.NewStreamingResponseAggregator()
+		for genResp, err := range readStream(resp.Body, tools) {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for llmResponse, err := range aggregator.ProcessResponse(ctx, genResp) {
+				if !yield(llmResponse, err) {
+					return // Consumer stopped
+				}
+			}
+		}
+		if closeResult := aggregator.Close(); closeResult != nil {
+			yield(closeResult, nil)
+		}
+	}
+}
+
+// chat posts the request to the chat API, with its function declarations
+// rendered into the prompt in fallback mode. It returns the names of the
+// declared functions in fallback mode, and an error for the unsuccessful
+// responses.
+func (m *ollamaModel) chat(ctx context.Context, req *model.LLMRequest, stream, fallback bool) (*http.Response, map[string]bool, error) {
+	body, tools, err := m.newChatRequest(req, stream, fallback)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode model request: %w", err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, m.cfg.Host+"/api/chat", bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create model request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("User-Agent", version.UserAgent(ctx))
+
+	resp, err := m.cfg.HTTPClient.Do(httpReq)
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return nil, nil, fmt.Errorf("failed to connect to Ollama at %s, make sure it is running (ollama serve) or set OLLAMA_HOST to its address: %w", m.cfg.Host, err)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to call model: %w", err)
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		apiErr := newAPIError(resp.StatusCode, data)
+		apiErr.RetryDelay = model.ParseRetryAfter(resp.Header)
+		return nil, nil, apiErr
+	}
+	return resp, tools, nil
+}
+
+// APIError is an error returned by the Ollama daemon.
+type APIError struct {
+	// StatusCode is the HTTP status code of the response, zero for the
+	// errors of a stream.
+	StatusCode int
+	Message    string
+	// RetryDelay is the delay of the Retry-After header of the response,
+	// zero when it has none.
+	RetryDelay time.Duration
+}
+
+func (e *APIError) Error() string {
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("ollama error: %s", e.Message)
+	}
+	return fmt.Sprintf("ollama error %d: %s", e.StatusCode, e.Message)
+}
+
+// toolsUnsupported reports whether the error is that of the requests with
+// tools to the models without tool support.
+func (e *APIError) toolsUnsupported() bool {
+	return e.StatusCode == http.StatusBadRequest && strings.Contains(e.Message, "does not support tools")
+}
+
+// RetryAfter returns the delay to wait before retrying, zero when the
+// response didn't ask for any.
+func (e *APIError) RetryAfter() time.Duration {
+	return e.RetryDelay
+}
+
+// HTTPStatusCode returns the HTTP status code of the error, zero for the
+// errors of a stream.
+func (e *APIError) HTTPStatusCode() int {
+	return e.StatusCode
+}
+
+// newAPIError returns the error of an unsuccessful response with the body.
+func newAPIError(statusCode int, body []byte) *APIError {
+	var resp struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil || resp.Error == "" {
+		return &APIError{StatusCode: statusCode, Message: strings.TrimSpace(string(body))}
+	}
+	return &APIError{StatusCode: statusCode, Message: resp.Error}
+}
+
+// readStream reads the newline-delimited chunks of a streamed response,
+// and yields their genai responses.
+func readStream(r io.Reader, tools map[string]bool) iter.Seq2[*genai.GenerateContentResponse, error] {
+	return func(yield func(*genai.GenerateContentResponse, error) bool) {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), 16<<20)
+		s := &streamState{tools: tools}
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			var chunk chatResponse
+			if err := json.Unmarshal(line, &chunk); err != nil {
+				yield(nil, fmt.Errorf("failed to decode stream chunk: %w", err))
+				return
+			}
+			if chunk.Error != "" {
+				yield(nil, &APIError{Message: chunk.Error})
+				return
+			}
+			for _, resp := range s.handle(&chunk) {
+				if !yield(resp, nil) {
+					return
+				}
+			}
+			if chunk.Done {
+				break
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			yield(nil, fmt.Errorf("failed to read stream: %w", err))
+			return
+		}
+		for _, resp := range s.close() {
+			if !yield(resp, nil) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,488 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ollama
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/internal/httprr"
+	"google.golang.org/adk/model"
+)
+
+//go:generate go test -httprecord=testdata/.*\.httprr
+
+var weatherTool = &genai.Tool{FunctionDeclarations: []*genai.FunctionDeclaration{{
+	Name:        "get_weather",
+	Description: "Returns the current weather of a city.",
+	Parameters: &genai.Schema{
+		Type:       genai.TypeObject,
+		Properties: map[string]*genai.Schema{"city": {Type: genai.TypeString}},
+		Required:   []string{"city"},
+	},
+}}}
+
+func TestModel_Generate(t *testing.T) {
+	tests := []struct {
+		name      string
+		modelName string
+		cfg       Config
+		req       *model.LLMRequest
+		want      *model.LLMResponse
+	}{
+		{
+			name:      "ok",
+			modelName: "llama3.2",
+			cfg:       Config{Options: map[string]any{"num_ctx": 4096}, KeepAlive: "10m"},
+			req: &model.LLMRequest{
+				Contents: genai.Text("What is the capital of France? One word."),
+				Config: &genai.GenerateContentConfig{
+					SystemInstruction: genai.NewContentFromText("You answer geography questions.", genai.RoleUser),
+					Temperature:       new(float32),
+				},
+			},
+			want: &model.LLMResponse{
+				Content: genai.NewContentFromText("Paris", genai.RoleModel),
+				UsageMetadata: &genai.GenerateContentResponseUsageMetadata{
+					CandidatesTokenCount: 2,
+					PromptTokenCount:     38,
+					TotalTokenCount:      40,
+				},
+				FinishReason: genai.FinishReasonStop,
+			},
+		},
+		{
+			name:      "extra_body",
+			modelName: "llama3.2",
+			cfg:       Config{Options: map[string]any{"num_ctx": 4096, "num_gpu": 1}},
+			req: &model.LLMRequest{
+				Contents: genai.Text("Write a poem about the sea."),
+				Config: &genai.GenerateContentConfig{
+					MaxOutputTokens: 5,
+					HTTPOptions: &genai.HTTPOptions{ExtraBody: map[string]any{
+						"options":    map[string]any{"num_ctx": 8192},
+						"keep_alive": "-1",
+					}},
+				},
+			},
+			want: &model.LLMResponse{
+				Content: genai.NewContentFromText("The sea, vast and", genai.RoleModel),
+				UsageMetadata: &genai.GenerateContentResponseUsageMetadata{
+					CandidatesTokenCount: 5,
+					PromptTokenCount:     31,
+					TotalTokenCount:      36,
+				},
+				FinishReason: genai.FinishReasonMaxTokens,
+			},
+		},
+		{
+			name:      "tool_calls",
+			modelName: "llama3.2",
+			req: &model.LLMRequest{
+				Contents: genai.Text("What is the weather in Paris and in Rome?"),
+				Config:   &genai.GenerateContentConfig{Tools: []*genai.Tool{weatherTool}},
+			},
+			want: &model.LLMResponse{
+				Content: &genai.Content{Role: genai.RoleModel, Parts: []*genai.Part{
+					{FunctionCall: &genai.FunctionCall{Name: "get_weather", Args: map[string]any{"city": "Paris"}}},
+					{FunctionCall: &genai.FunctionCall{Name: "get_weather", Args: map[string]any{"city": "Rome"}}},
+				}},
+				UsageMetadata: &genai.GenerateContentResponseUsageMetadata{
+					CandidatesTokenCount: 36,
+					PromptTokenCount:     170,
+					TotalTokenCount:      206,
+				},
+				FinishReason: genai.FinishReasonStop,
+			},
+		},
+		{
+			name:      "tool_results",
+			modelName: "llama3.2",
+			req: &model.LLMRequest{
+				Contents: []*genai.Content{
+					genai.NewContentFromText("What is the weather in Paris?", genai.RoleUser),
+					genai.NewContentFromFunctionCall("get_weather", map[string]any{"city": "Paris"}, genai.RoleModel),
+					genai.NewContentFromFunctionResponse("get_weather", map[string]any{"weather": "sunny"}, genai.RoleUser),
+				},
+				Config: &genai.GenerateContentConfig{Tools: []*genai.Tool{weatherTool}},
+			},
+			want: &model.LLMResponse{
+				Content: genai.NewContentFromText("It is sunny in Paris.", genai.RoleModel),
+				UsageMetadata: &genai.GenerateContentResponseUsageMetadata{
+					CandidatesTokenCount: 7,
+					PromptTokenCount:     201,
+					TotalTokenCount:      208,
+				},
+				FinishReason: genai.FinishReasonStop,
+			},
+		},
+		{
+			name:      "image",
+			modelName: "llava",
+			req: &model.LLMRequest{
+				Contents: []*genai.Content{{Role: genai.RoleUser, Parts: []*genai.Part{
+					{Text: "What color is this image? One word."},
+					{InlineData: &genai.Blob{MIMEType: "image/png", Data: []byte("\x89PNG red pixel")}},
+				}}},
+			},
+			want: &model.LLMResponse{
+				Content: genai.NewContentFromText("Red", genai.RoleModel),
+				UsageMetadata: &genai.GenerateContentResponseUsageMetadata{
+					CandidatesTokenCount: 2,
+					PromptTokenCount:     593,
+					TotalTokenCount:      595,
+				},
+				FinishReason: genai.FinishReasonStop,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpRecordFilename := filepath.Join("testdata", strings.ReplaceAll(t.Name(), "/", "_")+".httprr")
+
+			testModel, err := NewModel(t.Context(), tt.modelName, newTestConfig(t, httpRecordFilename, tt.cfg))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for got, err := range testModel.GenerateContent(t.Context(), tt.req, false) {
+				if err != nil {
+					t.Fatalf("Model.Generate() error = %v", err)
+				}
+				if diff := cmp.Diff(tt.want, got); diff != "" {
+					t.Errorf("Model.Generate() = %v, want %v\ndiff(-want +got):\n%v", got, tt.want, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestModel_Generate_ToolFallback(t *testing.T) {
+	httpRecordFilename := filepath.Join("testdata", strings.ReplaceAll(t.Name(), "/", "_")+".httprr")
+
+	testModel, err := NewModel(t.Context(), "gemma2", newTestConfig(t, httpRecordFilename, Config{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &model.LLMRequest{
+		Contents: genai.Text("What is the weather in Paris?"),
+		Config:   &genai.GenerateContentConfig{Tools: []*genai.Tool{weatherTool}},
+	}
+	want := &model.LLMResponse{
+		Content: &genai.Content{Role: genai.RoleModel, Parts: []*genai.Part{
+			{FunctionCall: &genai.FunctionCall{Name: "get_weather", Args: map[string]any{"city": "Paris"}}},
+		}},
+		UsageMetadata: &genai.GenerateContentResponseUsageMetadata{
+			CandidatesTokenCount: 19,
+			PromptTokenCount:     142,
+			TotalTokenCount:      161,
+		},
+		FinishReason: genai.FinishReasonStop,
+	}
+	// The first request is retried in fallback mode, and the second one is
+	// in fallback mode right away.
+	for range 2 {
+		for got, err := range testModel.GenerateContent(t.Context(), req, false) {
+			if err != nil {
+				t.Fatalf("Model.Generate() error = %v", err)
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("Model.Generate() = %v, want %v\ndiff(-want +got):\n%v", got, want, diff)
+			}
+		}
+	}
+}
+
+func TestModel_GenerateStream(t *testing.T) {
+	tests := []struct {
+		name      string
+		modelName string
+		req       *model.LLMRequest
+		want      string
+	}{
+		{
+			name:      "ok",
+			modelName: "llama3.2",
+			req: &model.LLMRequest{
+				Contents: genai.Text("What is the capital of France? Answer in a sentence."),
+				Config:   &genai.GenerateContentConfig{Temperature: new(float32)},
+			},
+			want: "The capital of France is Paris.",
+		},
+		{
+			// The text of the replies in fallback mode that are not tool
+			// calls streams too.
+			name:      "tool_fallback_text",
+			modelName: "gemma2",
+			req: &model.LLMRequest{
+				Contents: genai.Text("Hello!"),
+				Config:   &genai.GenerateContentConfig{Tools: []*genai.Tool{weatherTool}},
+			},
+			want: "Hello! How can I help you today?",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpRecordFilename := filepath.Join("testdata", strings.ReplaceAll(t.Name(), "/", "_")+".httprr")
+
+			testModel, err := NewModel(t.Context(), tt.modelName, newTestConfig(t, httpRecordFilename, Config{}))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var partial, final strings.Builder
+			for resp, err := range testModel.GenerateContent(t.Context(), tt.req, true) {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if resp.Partial {
+					partial.WriteString(resp.Content.Parts[0].Text)
+				} else {
+					final.WriteString(resp.Content.Parts[0].Text)
+				}
+			}
+			if diff := cmp.Diff(tt.want, partial.String()); diff != "" {
+				t.Errorf("Model.GenerateStream() partial text mismatch (-want +got):\n%s", diff)
+			}
+			// Since we are expecting GenerateStream to aggregate partial events, the text should be the same
+			if diff := cmp.Diff(tt.want, final.String()); diff != "" {
+				t.Errorf("Model.GenerateStream() final text mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestModel_GenerateStream_ToolCalls(t *testing.T) {
+	tests := []struct {
+		name      string
+		modelName string
+	}{
+		{name: "native", modelName: "llama3.2"},
+		// The JSON reply in fallback mode is held back, and parsed.
+		{name: "fallback", modelName: "gemma2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpRecordFilename := filepath.Join("testdata", strings.ReplaceAll(t.Name(), "/", "_")+".httprr")
+
+			testModel, err := NewModel(t.Context(), tt.modelName, newTestConfig(t, httpRecordFilename, Config{}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req := &model.LLMRequest{
+				Contents: genai.Text("What is the weather in Paris and in Rome?"),
+				Config:   &genai.GenerateContentConfig{Tools: []*genai.Tool{weatherTool}},
+			}
+
+			var got []*model.LLMResponse
+			for resp, err := range testModel.GenerateContent(t.Context(), req, true) {
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, resp)
+			}
+
+			want := []*model.LLMResponse{{
+				Content: &genai.Content{Role: genai.RoleModel, Parts: []*genai.Part{
+					{FunctionCall: &genai.FunctionCall{Name: "get_weather", Args: map[string]any{"city": "Paris"}}},
+					{FunctionCall: &genai.FunctionCall{Name: "get_weather", Args: map[string]any{"city": "Rome"}}},
+				}},
+				UsageMetadata: &genai.GenerateContentResponseUsageMetadata{
+					CandidatesTokenCount: 36,
+					PromptTokenCount:     170,
+					TotalTokenCount:      206,
+				},
+				FinishReason: genai.FinishReasonStop,
+				TurnComplete: true,
+			}}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("Model.GenerateStream() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestModel_Generate_ConnectionRefused(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	host := server.URL
+	server.Close()
+
+	testModel, err := NewModel(t.Context(), "llama3.2", &Config{Host: host})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, err := range testModel.GenerateContent(t.Context(), &model.LLMRequest{Contents: genai.Text("Hi")}, false) {
+		if err == nil {
+			t.Fatal("Model.Generate() succeeded, want error")
+		}
+		if msg := err.Error(); !strings.Contains(msg, host) || !strings.Contains(msg, "ollama serve") {
+			t.Errorf("Model.Generate() error = %q, want it to mention %q and how to start Ollama", msg, host)
+		}
+	}
+}
+
+func TestModel_Generate_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"model \"llama9\" not found, try pulling it first"}`, http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	testModel, err := NewModel(t.Context(), "llama9", &Config{Host: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, err := range testModel.GenerateContent(t.Context(), &model.LLMRequest{Contents: genai.Text("Hi")}, true) {
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("Model.Generate() error = %v, want an *APIError", err)
+		}
+		want := &APIError{StatusCode: http.StatusNotFound, Message: `model "llama9" not found, try pulling it first`}
+		if diff := cmp.Diff(want, apiErr); diff != "" {
+			t.Errorf("Model.Generate() error mismatch (-want +got):\n%s", diff)
+		}
+	}
+}
+
+func TestNewModel(t *testing.T) {
+	tests := []struct {
+		name     string
+		envHost  string
+		cfg      *Config
+		wantHost string
+	}{
+		{name: "default", wantHost: DefaultHost},
+		{name: "env", envHost: "0.0.0.0:11434", wantHost: "http://0.0.0.0:11434"},
+		{name: "config", envHost: "0.0.0.0:11434", cfg: &Config{Host: "https://ollama.example.com/"}, wantHost: "https://ollama.example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OLLAMA_HOST", tt.envHost)
+			m, err := NewModel(t.Context(), "llama3.2", tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := m.(*ollamaModel).cfg.Host; got != tt.wantHost {
+				t.Errorf("NewModel() host = %q, want %q", got, tt.wantHost)
+			}
+			if got := m.Name(); got != "llama3.2" {
+				t.Errorf("Name() = %q, want %q", got, "llama3.2")
+			}
+		})
+	}
+}
+
+func TestNewChatRequest_Fallback(t *testing.T) {
+	tmpl := template.Must(template.New("tools").Parse(`Tools:{{range .}} {{.Name}} {{.Parameters}}{{end}}`))
+	m, err := NewModel(t.Context(), "gemma2", &Config{ToolFallbackTemplate: tmpl})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &model.LLMRequest{
+		Contents: []*genai.Content{
+			genai.NewContentFromText("Weather in Paris?", genai.RoleUser),
+			genai.NewContentFromFunctionCall("get_weather", map[string]any{"city": "Paris"}, genai.RoleModel),
+			genai.NewContentFromFunctionResponse("get_weather", map[string]any{"weather": "sunny"}, genai.RoleUser),
+		},
+		Config: &genai.GenerateContentConfig{
+			SystemInstruction: genai.NewContentFromText("Be brief.", genai.RoleUser),
+			Tools:             []*genai.Tool{weatherTool},
+		},
+	}
+	body, tools, err := m.(*ollamaModel).newChatRequest(req, false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(map[string]bool{"get_weather": true}, tools); diff != "" {
+		t.Errorf("newChatRequest() tools mismatch (-want +got):\n%s", diff)
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"messages":[` +
+		`{"content":"Be brief.\n\nTools: get_weather {\"properties\":{\"city\":{\"type\":\"string\"}},\"required\":[\"city\"],\"type\":\"object\"}","role":"system"},` +
+		`{"content":"Weather in Paris?","role":"user"},` +
+		`{"content":"{\"name\":\"get_weather\",\"arguments\":{\"city\":\"Paris\"}}","role":"assistant"},` +
+		`{"content":"Result of the get_weather tool: {\"weather\":\"sunny\"}","role":"user"}],` +
+		`"model":"gemma2","stream":false}`
+	if diff := cmp.Diff(want, string(data)); diff != "" {
+		t.Errorf("newChatRequest() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseFallbackCalls(t *testing.T) {
+	tools := map[string]bool{"get_weather": true}
+	call := func(city string) *genai.Part {
+		return &genai.Part{FunctionCall: &genai.FunctionCall{Name: "get_weather", Args: map[string]any{"city": city}}}
+	}
+	tests := []struct {
+		name string
+		text string
+		want []*genai.Part
+	}{
+		{name: "object", text: ` {"name": "get_weather", "arguments": {"city": "Paris"}}`, want: []*genai.Part{call("Paris")}},
+		{name: "parameters", text: `{"name": "get_weather", "parameters": {"city": "Paris"}}`, want: []*genai.Part{call("Paris")}},
+		{
+			name: "array",
+			text: `[{"name": "get_weather", "arguments": {"city": "Paris"}}, {"name": "get_weather", "arguments": {"city": "Rome"}}]`,
+			want: []*genai.Part{call("Paris"), call("Rome")},
+		},
+		{name: "code_block", text: "```json\n{\"name\": \"get_weather\", \"arguments\": {\"city\": \"Paris\"}}\n```", want: []*genai.Part{call("Paris")}},
+		{name: "text", text: "It is sunny."},
+		{name: "unknown_tool", text: `{"name": "get_time", "arguments": {}}`},
+		{name: "other_json", text: `{"capital": "Paris"}`},
+		{name: "invalid_json", text: `{"name": "get_weather",`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, parseFallbackCalls(tt.text, tools)); diff != "" {
+				t.Errorf("parseFallbackCalls() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// newTestConfig returns the Config configured for record and replay.
+func newTestConfig(t *testing.T, rrfile string, cfg Config) *Config {
+	t.Helper()
+	rr, err := httprr.Open(rrfile, http.DefaultTransport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr.ScrubReq(scrubRequest)
+	cfg.HTTPClient = &http.Client{Transport: rr}
+	cfg.Host = DefaultHost
+	return &cfg
+}
+
+func scrubRequest(req *http.Request) error {
+	req.Header.Del("User-Agent") // contains version numbers
+	b := req.Body.(*httprr.Body)
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, b.Data); err == nil {
+		b.Data = buf.Bytes()
+	}
+	return nil
+}
@@ -0,0 +1,296 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ollama
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strings"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/internal/llminternal/converters"
+	"google.golang.org/adk/model"
+)
+
+// chatRequest is the body of a request to the chat API.
+type chatRequest struct {
+	Model    string    `json:"model"`
+	Messages []message `json:"messages"`
+	Tools    []toolDef `json:"tools,omitempty"`
+	// Format is "json", or the JSON schema of the response.
+	Format  any            `json:"format,omitempty"`
+	Options map[string]any `json:"options,omitempty"`
+	// Stream is always set, as the API streams by default.
+	Stream    bool   `json:"stream"`
+	KeepAlive string `json:"keep_alive,omitempty"`
+	Think     *bool  `json:"think,omitempty"`
+}
+
+type message struct {
+	Role      string     `json:"role"`
+	Content   string     `json:"content"`
+	Thinking  string     `json:"thinking,omitempty"`
+	Images    []string   `json:"images,omitempty"`
+	ToolCalls []toolCall `json:"tool_calls,omitempty"`
+	ToolName  string     `json:"tool_name,omitempty"`
+}
+
+type toolCall struct {
+	Function struct {
+		Name      string         `json:"name"`
+		Arguments map[string]any `json:"arguments"`
+	} `json:"function"`
+}
+
+type toolDef struct {
+	Type     string      `json:"type"`
+	Function functionDef `json:"function"`
+}
+
+type functionDef struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Parameters  any    `json:"parameters"`
+}
+
+// newChatRequest translates the request to the body of a request to the chat
+// API. In fallback mode, the function declarations are rendered into the
+// system prompt, and the function calls and responses into text messages. It
+// returns the names of the declared functions in fallback mode.
+func (m *ollamaModel) newChatRequest(req *model.LLMRequest, stream, fallback bool) (map[string]any, map[string]bool, error) {
+	body := &chatRequest{
+		Model:     m.name,
+		Stream:    stream,
+		KeepAlive: m.cfg.KeepAlive,
+		Options:   maps.Clone(m.cfg.Options),
+	}
+	cfg := req.Config
+	if cfg == nil {
+		cfg = &genai.GenerateContentConfig{}
+	}
+
+	var system []string
+	if cfg.SystemInstruction != nil {
+		for _, part := range cfg.SystemInstruction.Parts {
+			if part != nil && part.Text != "" {
+				system = append(system, part.Text)
+			}
+		}
+	}
+	var (
+		decls []*genai.FunctionDeclaration
+		tools map[string]bool
+	)
+	for _, t := range cfg.Tools {
+		if t == nil {
+			continue
+		}
+		if t.FunctionDeclarations == nil {
+			return nil, nil, fmt.Errorf("only function tools are supported by ollama models")
+		}
+		for _, decl := range t.FunctionDeclarations {
+			decls = append(decls, decl)
+		}
+	}
+	if fallback && len(decls) > 0 {
+		tools = make(map[string]bool, len(decls))
+		for _, decl := range decls {
+			tools[decl.Name] = true
+		}
+		prompt, err := renderTools(m.cfg.ToolFallbackTemplate, decls)
+		if err != nil {
+			return nil, nil, err
+		}
+		system = append(system, prompt)
+	} else {
+		for _, decl := range decls {
+			body.Tools = append(body.Tools, toolDef{Type: "function", Function: functionDef{
+				Name:        decl.Name,
+				Description: decl.Description,
+				Parameters:  parameters(decl),
+			}})
+		}
+	}
+	if len(system) > 0 {
+		body.Messages = append(body.Messages, message{Role: "system", Content: strings.Join(system, "\n\n")})
+	}
+
+	for _, content := range req.Contents {
+		if content == nil {
+			continue
+		}
+		messages, err := contentMessages(content, fallback)
+		if err != nil {
+			return nil, nil, err
+		}
+		body.Messages = append(body.Messages, messages...)
+	}
+
+	options := map[string]any{}
+	if cfg.Temperature != nil {
+		options["temperature"] = *cfg.Temperature
+	}
+	if cfg.TopP != nil {
+		options["top_p"] = *cfg.TopP
+	}
+	if cfg.TopK != nil {
+		options["top_k"] = int(*cfg.TopK)
+	}
+	if cfg.MaxOutputTokens > 0 {
+		options["num_predict"] = cfg.MaxOutputTokens
+	}
+	if len(cfg.StopSequences) > 0 {
+		options["stop"] = cfg.StopSequences
+	}
+	if cfg.Seed != nil {
+		options["seed"] = *cfg.Seed
+	}
+	if cfg.PresencePenalty != nil {
+		options["presence_penalty"] = *cfg.PresencePenalty
+	}
+	if cfg.FrequencyPenalty != nil {
+		options["frequency_penalty"] = *cfg.FrequencyPenalty
+	}
+	if len(options) > 0 {
+		if body.Options == nil {
+			body.Options = options
+		} else {
+			maps.Copy(body.Options, options)
+		}
+	}
+	switch {
+	case cfg.ResponseJsonSchema != nil:
+		body.Format = cfg.ResponseJsonSchema
+	case cfg.ResponseSchema != nil:
+		body.Format = converters.SchemaToJSON(cfg.ResponseSchema)
+	case cfg.ResponseMIMEType == "application/json":
+		body.Format = "json"
+	}
+	if cfg.ThinkingConfig != nil && cfg.ThinkingConfig.IncludeThoughts {
+		body.Think = &cfg.ThinkingConfig.IncludeThoughts
+	}
+
+	payload, err := withExtraBody(body, cfg.HTTPOptions)
+	if err != nil {
+		return nil, nil, err
+	}
+	return payload, tools, nil
+}
+
+// contentMessages translates the content to messages, with a tool message
+// per function response.
+func contentMessages(content *genai.Content, fallback bool) ([]message, error) {
+	role := "user"
+	if content.Role == genai.RoleModel {
+		role = "assistant"
+	}
+	var (
+		messages []message
+		msg      = message{Role: role}
+		texts    []string
+	)
+	for _, part := range content.Parts {
+		switch {
+		case part == nil:
+		case part.Thought:
+			msg.Thinking += part.Text
+		case part.Text != "":
+			texts = append(texts, part.Text)
+		case part.FunctionCall != nil:
+			args := part.FunctionCall.Args
+			if args == nil {
+				args = map[string]any{}
+			}
+			if fallback {
+				data, err := json.Marshal(fallbackCall{Name: part.FunctionCall.Name, Arguments: args})
+				if err != nil {
+					return nil, fmt.Errorf("failed to encode arguments of function call %q: %w", part.FunctionCall.Name, err)
+				}
+				texts = append(texts, string(data))
+				continue
+			}
+			var call toolCall
+			call.Function.Name = part.FunctionCall.Name
+			call.Function.Arguments = args
+			msg.ToolCalls = append(msg.ToolCalls, call)
+		case part.FunctionResponse != nil:
+			result, err := json.Marshal(part.FunctionResponse.Response)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode response of function %q: %w", part.FunctionResponse.Name, err)
+			}
+			if fallback {
+				texts = append(texts, fmt.Sprintf("Result of the %s tool: %s", part.FunctionResponse.Name, result))
+				continue
+			}
+			messages = append(messages, message{Role: "tool", ToolName: part.FunctionResponse.Name, Content: string(result)})
+		case part.InlineData != nil:
+			if !strings.HasPrefix(part.InlineData.MIMEType, "image/") {
+				return nil, fmt.Errorf("unsupported MIME type %q for ollama models", part.InlineData.MIMEType)
+			}
+			msg.Images = append(msg.Images, base64.StdEncoding.EncodeToString(part.InlineData.Data))
+		case part.FileData != nil:
+			return nil, fmt.Errorf("file data %q is not supported by ollama models, use inline data", part.FileData.FileURI)
+		}
+	}
+	msg.Content = strings.Join(texts, "\n")
+	if msg.Content != "" || msg.Thinking != "" || msg.Images != nil || msg.ToolCalls != nil {
+		messages = append(messages, msg)
+	}
+	return messages, nil
+}
+
+// parameters returns the JSON schema of the parameters of the function.
+func parameters(decl *genai.FunctionDeclaration) any {
+	if decl.ParametersJsonSchema != nil {
+		return decl.ParametersJsonSchema
+	}
+	if decl.Parameters == nil {
+		return map[string]any{"type": "object", "properties": map[string]any{}}
+	}
+	return converters.SchemaToJSON(decl.Parameters)
+}
+
+// withExtraBody returns the body as a map, with the extra body of the HTTP
+// options merged into it. The nested objects, e.g. the options, are merged
+// rather than replaced.
+func withExtraBody(body *chatRequest, opts *genai.HTTPOptions) (map[string]any, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode model request: %w", err)
+	}
+	var payload map[string]any
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, fmt.Errorf("failed to encode model request: %w", err)
+	}
+	if opts != nil {
+		mergeMaps(payload, opts.ExtraBody)
+	}
+	return payload, nil
+}
+
+func mergeMaps(dst, src map[string]any) {
+	for k, v := range src {
+		if sv, ok := v.(map[string]any); ok {
+			if dv, ok := dst[k].(map[string]any); ok {
+				mergeMaps(dv, sv)
+				continue
+			}
+		}
+		dst[k] = v
+	}
+}
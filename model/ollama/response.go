@@ -0,0 +1,205 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ollama
+
+import (
+	"strings"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/internal/llminternal/converters"
+	"google.golang.org/adk/model"
+)
+
+// chatResponse is the body of a response of the chat API, or a chunk of a
+// streamed response.
+type chatResponse struct {
+	Model   string `json:"model"`
+	Message struct {
+		Role      string     `json:"role"`
+		Content   string     `json:"content"`
+		Thinking  string     `json:"thinking"`
+		ToolCalls []toolCall `json:"tool_calls"`
+	} `json:"message"`
+	Done            bool   `json:"done"`
+	DoneReason      string `json:"done_reason"`
+	PromptEvalCount int32  `json:"prompt_eval_count"`
+	EvalCount       int32  `json:"eval_count"`
+	Error           string `json:"error"`
+}
+
+// llmResponse translates the response to an LLM response. The text of the
+// responses in fallback mode, with the names of the declared tools, is
+// parsed for tool calls.
+func (r *chatResponse) llmResponse(tools map[string]bool) *model.LLMResponse {
+	content := &genai.Content{Role: genai.RoleModel}
+	if r.Message.Thinking != "" {
+		content.Parts = append(content.Parts, &genai.Part{Text: r.Message.Thinking, Thought: true})
+	}
+	if calls := parseFallbackCalls(r.Message.Content, tools); calls != nil {
+		content.Parts = append(content.Parts, calls...)
+	} else if r.Message.Content != "" {
+		content.Parts = append(content.Parts, &genai.Part{Text: r.Message.Content})
+	}
+	content.Parts = append(content.Parts, toolCallParts(r.Message.ToolCalls)...)
+	return converters.Genai2LLMResponse(&genai.GenerateContentResponse{
+		Candidates:    []*genai.Candidate{{Content: content, FinishReason: finishReason(r.DoneReason)}},
+		UsageMetadata: r.usageMetadata(),
+		ModelVersion:  r.Model,
+	})
+}
+
+func toolCallParts(calls []toolCall) []*genai.Part {
+	var parts []*genai.Part
+	for _, call := range calls {
+		parts = append(parts, &genai.Part{FunctionCall: &genai.FunctionCall{Name: call.Function.Name, Args: call.Function.Arguments}})
+	}
+	return parts
+}
+
+// finishReason translates the reason a response is done.
+func finishReason(reason string) genai.FinishReason {
+	switch reason {
+	case "":
+		return ""
+	case "stop":
+		return genai.FinishReasonStop
+	case "length":
+		return genai.FinishReasonMaxTokens
+	}
+	return genai.FinishReasonOther
+}
+
+// usageMetadata returns the token usage of a done response.
+func (r *chatResponse) usageMetadata() *genai.GenerateContentResponseUsageMetadata {
+	if !r.Done {
+		return nil
+	}
+	return &genai.GenerateContentResponseUsageMetadata{
+		PromptTokenCount:     r.PromptEvalCount,
+		CandidatesTokenCount: r.EvalCount,
+		TotalTokenCount:      r.PromptEvalCount + r.EvalCount,
+	}
+}
+
+// streamState translates the chunks of a streamed response to genai
+// responses.
+//
+// The text and thinking deltas are yielded as they arrive. The last
+// response is held back until the end of the stream, so that it carries the
+// finish reason and the usage of the last chunk, like the last response of a
+// Gemini stream.
+//
+// In fallback mode, the text starting like a JSON object or array, or a
+// code block, is held back until the end of the stream to be parsed for
+// tool calls.
+type streamState struct {
+	// tools are the names of the declared tools in fallback mode.
+	tools map[string]bool
+	// text is the text held back in fallback mode, until sniffed reports
+	// whether it looks like a tool call.
+	text     strings.Builder
+	sniffed  bool
+	buffered bool
+	done     *chatResponse
+	pending  *genai.GenerateContentResponse
+}
+
+// handle processes the chunk and returns the responses ready to be yielded.
+func (s *streamState) handle(chunk *chatResponse) []*genai.GenerateContentResponse {
+	var responses []*genai.GenerateContentResponse
+	if chunk.Message.Thinking != "" {
+		responses = s.push(responses, chunk.Model, &genai.Part{Text: chunk.Message.Thinking, Thought: true})
+	}
+	if text := chunk.Message.Content; text != "" {
+		switch {
+		case s.tools == nil || (s.sniffed && !s.buffered):
+			responses = s.push(responses, chunk.Model, &genai.Part{Text: text})
+		case s.buffered:
+			s.text.WriteString(text)
+		default:
+			s.text.WriteString(text)
+			head := strings.TrimSpace(s.text.String())
+			if head == "" {
+				break
+			}
+			s.sniffed = true
+			s.buffered = strings.HasPrefix(head, "{") || strings.HasPrefix(head, "[") || strings.HasPrefix(head, "`")
+			if !s.buffered {
+				responses = s.push(responses, chunk.Model, &genai.Part{Text: s.text.String()})
+				s.text.Reset()
+			}
+		}
+	}
+	if calls := toolCallParts(chunk.Message.ToolCalls); calls != nil {
+		responses = s.pushCalls(responses, chunk.Model, calls)
+	}
+	if chunk.Done {
+		s.done = chunk
+	}
+	return responses
+}
+
+// pushCalls makes a response of the function calls pending. The
+// consecutive function calls are a single response, as they are for a
+// unary request.
+func (s *streamState) pushCalls(responses []*genai.GenerateContentResponse, modelVersion string, calls []*genai.Part) []*genai.GenerateContentResponse {
+	if s.pending != nil && s.pending.Candidates[0].Content.Parts[0].FunctionCall != nil {
+		s.pending.Candidates[0].Content.Parts = append(s.pending.Candidates[0].Content.Parts, calls...)
+		return responses
+	}
+	return s.push(responses, modelVersion, calls...)
+}
+
+// push makes a response of the parts pending, and appends the previously
+// pending one to the responses.
+func (s *streamState) push(responses []*genai.GenerateContentResponse, modelVersion string, parts ...*genai.Part) []*genai.GenerateContentResponse {
+	if s.pending != nil {
+		responses = append(responses, s.pending)
+	}
+	s.pending = &genai.GenerateContentResponse{
+		Candidates:   []*genai.Candidate{{Content: &genai.Content{Role: genai.RoleModel, Parts: parts}}},
+		ModelVersion: modelVersion,
+	}
+	return responses
+}
+
+// close returns the responses left at the end of the stream, the last one
+// carrying the finish reason and the usage.
+func (s *streamState) close() []*genai.GenerateContentResponse {
+	var responses []*genai.GenerateContentResponse
+	var modelVersion string
+	if s.done != nil {
+		modelVersion = s.done.Model
+	}
+	if text := s.text.String(); strings.TrimSpace(text) != "" {
+		if calls := parseFallbackCalls(text, s.tools); calls != nil {
+			responses = s.pushCalls(responses, modelVersion, calls)
+		} else {
+			responses = s.push(responses, modelVersion, &genai.Part{Text: text})
+		}
+		s.text.Reset()
+	}
+	if s.pending == nil {
+		return responses
+	}
+	if s.done != nil {
+		s.pending.Candidates[0].FinishReason = finishReason(s.done.DoneReason)
+		s.pending.UsageMetadata = s.done.usageMetadata()
+	}
+	responses = append(responses, s.pending)
+	s.pending = nil
+	return responses
+}
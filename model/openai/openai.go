@@ -0,0 +1,269 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package openai implements the [model.LLM] interface for the models served
+// with the OpenAI chat completions API.
+//
+// Besides OpenAI, the API is served by many providers and local servers, such
+// as vLLM, LM Studio or Ollama, which are targeted with the BaseURL of the
+// [Config]. Streamed responses yield partial text responses followed by the
+// aggregated one, like the Gemini models.
+package openai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/internal/llminternal"
+	"google.golang.org/adk/internal/version"
+	"google.golang.org/adk/model"
+)
+
+// DefaultBaseURL is the default base URL of the API.
+const DefaultBaseURL = "https://api.openai.com/v1"
+
+// Config is the configuration of an OpenAI-compatible model.
+type Config struct {
+	// APIKey authenticates the requests as a bearer token. Defaults to the
+	// OPENAI_API_KEY environment variable. The requests are not
+	// authenticated without a key, as local servers may not require one.
+	APIKey string
+	// BaseURL is the base URL of the API, to which "/chat/completions" is
+	// appended. Defaults to [DefaultBaseURL].
+	BaseURL string
+	// HTTPClient sends the requests. Defaults to [http.DefaultClient].
+	HTTPClient *http.Client
+	// Header holds additional headers of the requests, e.g. the
+	// organization or project headers of OpenAI.
+	Header http.Header
+}
+
+type openaiModel struct {
+	name string
+	cfg  Config
+}
+
+// NewModel returns [model.LLM], backed by an OpenAI-compatible chat
+// completions API.
+//
+// The modelName specifies which model to target (e.g., "gpt-4o", or the
+// name of a model served by a local server).
+func NewModel(ctx context.Context, modelName string, cfg *Config) (model.LLM, error) {
+	var c Config
+	if cfg != nil {
+		c = *cfg
+	}
+	if c.APIKey == "" {
+		c.APIKey = os.Getenv("OPENAI_API_KEY")
+	}
+	if c.BaseURL == "" {
+		c.BaseURL = DefaultBaseURL
+	}
+	c.BaseURL = strings.TrimSuffix(c.BaseURL, "/")
+	if c.HTTPClient == nil {
+		c.HTTPClient = http.DefaultClient
+	}
+	return &openaiModel{name: modelName, cfg: c}, nil
+}
+
+func (m *openaiModel) Name() string {
+	return m.name
+}
+
+// GenerateContent calls the underlying model.
+func (m *openaiModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		body, err := newChatRequest(m.name, req, stream)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		resp, err := m.send(ctx, body)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		defer resp.Body.Close()
+
+		if !stream {
+			var completion chatCompletion
+			if err := json.NewDecoder(resp.Body).Decode(&completion); err != nil {
+				yield(nil, fmt.Errorf("failed to decode model response: %w", err))
+				return
+			}
+			llmResponse, err := completion.llmResponse()
+			yield(llmResponse, err)
+			return
+		}
+
+		aggregator := llminternal.NewStreamingResponseAggregator()
+		for genResp, err := range readStream(resp.Body) {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for llmResponse, err := range aggregator.ProcessResponse(ctx, genResp) {
+				if !yield(llmResponse, err) {
+					return // Consumer stopped
+				}
+			}
+		}
+		if closeResult := aggregator.Close(); closeResult != nil {
+			yield(closeResult, nil)
+		}
+	}
+}
+
+// send posts the request to the chat completions API. It returns an error
+// for the unsuccessful responses.
+func (m *openaiModel) send(ctx context.Context, body *chatRequest) (*http.Response, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode model request: %w", err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, m.cfg.BaseURL+"/chat/completions", bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create model request: %w", err)
+	}
+	for k, v := range m.cfg.Header {
+		httpReq.Header[k] = v
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if m.cfg.APIKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+m.cfg.APIKey)
+	}
+	httpReq.Header.Set("User-Agent", version.UserAgent(ctx))
+
+	resp, err := m.cfg.HTTPClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call model: %w", err)
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		apiErr := newAPIError(resp.StatusCode, data)
+		apiErr.RetryDelay = model.ParseRetryAfter(resp.Header)
+		return nil, apiErr
+	}
+	return resp, nil
+}
+
+// APIError is an error returned by the API.
+type APIError struct {
+	// StatusCode is the HTTP status code of the response, zero for the
+	// errors of a stream.
+	StatusCode int
+	// Type and Code identify the error, e.g. "invalid_request_error" and
+	// "context_length_exceeded". Servers may leave them empty.
+	Type    string
+	Code    string
+	Message string
+	// RetryDelay is the delay of the Retry-After header of the response,
+	// zero when it has none.
+	RetryDelay time.Duration
+}
+
+func (e *APIError) Error() string {
+	msg := e.Message
+	if e.Code != "" {
+		msg = e.Code + ": " + msg
+	}
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("openai API error %s: %s", e.Type, msg)
+	}
+	return fmt.Sprintf("openai API error %d %s: %s", e.StatusCode, e.Type, msg)
+}
+
+// RetryAfter returns the delay to wait before retrying, zero when the
+// response didn't ask for any.
+func (e *APIError) RetryAfter() time.Duration {
+	return e.RetryDelay
+}
+
+// HTTPStatusCode returns the HTTP status code of the error, zero for the
+// errors of a stream.
+func (e *APIError) HTTPStatusCode() int {
+	return e.StatusCode
+}
+
+// newAPIError returns the error of an unsuccessful response with the body.
+func newAPIError(statusCode int, body []byte) *APIError {
+	var resp struct {
+		Error *apiError `json:"error"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil || resp.Error == nil || resp.Error.Message == "" {
+		return &APIError{StatusCode: statusCode, Type: http.StatusText(statusCode), Message: strings.TrimSpace(string(body))}
+	}
+	return resp.Error.apiError(statusCode)
+}
+
+// readStream reads the server-sent events of a streamed response, and
+// yields the genai responses of their chunks.
+func readStream(r io.Reader) iter.Seq2[*genai.GenerateContentResponse, error] {
+	return func(yield func(*genai.GenerateContentResponse, error) bool) {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), 16<<20)
+		s := &streamState{}
+		for scanner.Scan() {
+			data, ok := strings.CutPrefix(scanner.Text(), "data:")
+			if !ok {
+				continue
+			}
+			data = strings.TrimSpace(data)
+			if data == "[DONE]" {
+				break
+			}
+			var chunk chatCompletionChunk
+			if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+				yield(nil, fmt.Errorf("failed to decode stream chunk: %w", err))
+				return
+			}
+			responses, err := s.handle(&chunk)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, resp := range responses {
+				if !yield(resp, nil) {
+					return
+				}
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			yield(nil, fmt.Errorf("failed to read stream: %w", err))
+			return
+		}
+		responses, err := s.close()
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		for _, resp := range responses {
+			if !yield(resp, nil) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,630 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openai
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/internal/httprr"
+	"google.golang.org/adk/internal/version"
+	"google.golang.org/adk/model"
+)
+
+//go:generate go test -httprecord=testdata/.*\.httprr
+
+const testModelName = "gpt-4o-mini"
+
+var weatherTool = &genai.Tool{FunctionDeclarations: []*genai.FunctionDeclaration{{
+	Name:        "get_weather",
+	Description: "Returns the current weather of a city.",
+	Parameters: &genai.Schema{
+		Type:       genai.TypeObject,
+		Properties: map[string]*genai.Schema{"city": {Type: genai.TypeString}},
+		Required:   []string{"city"},
+	},
+}}}
+
+func TestModel_Generate(t *testing.T) {
+	tests := []struct {
+		name string
+		req  *model.LLMRequest
+		want *model.LLMResponse
+	}{
+		{
+			name: "ok",
+			req: &model.LLMRequest{
+				Contents: genai.Text("What is the capital of France? One word."),
+				Config: &genai.GenerateContentConfig{
+					SystemInstruction: genai.NewContentFromText("You answer geography questions.", genai.RoleUser),
+					Temperature:       new(float32),
+				},
+			},
+			want: &model.LLMResponse{
+				Content: genai.NewContentFromText("Paris", genai.RoleModel),
+				UsageMetadata: &genai.GenerateContentResponseUsageMetadata{
+					CandidatesTokenCount: 2,
+					PromptTokenCount:     27,
+					TotalTokenCount:      29,
+				},
+				FinishReason: genai.FinishReasonStop,
+			},
+		},
+		{
+			name: "parallel_tool_calls",
+			req: &model.LLMRequest{
+				Contents: genai.Text("What is the weather in Paris and in Rome?"),
+				Config:   &genai.GenerateContentConfig{Tools: []*genai.Tool{weatherTool}},
+			},
+			want: &model.LLMResponse{
+				Content: &genai.Content{Role: genai.RoleModel, Parts: []*genai.Part{
+					{FunctionCall: &genai.FunctionCall{ID: "call_paris", Name: "get_weather", Args: map[string]any{"city": "Paris"}}},
+					{FunctionCall: &genai.FunctionCall{ID: "call_rome", Name: "get_weather", Args: map[string]any{"city": "Rome"}}},
+				}},
+				UsageMetadata: &genai.GenerateContentResponseUsageMetadata{
+					CandidatesTokenCount: 46,
+					PromptTokenCount:     61,
+					TotalTokenCount:      107,
+				},
+				FinishReason: genai.FinishReasonStop,
+			},
+		},
+		{
+			name: "tool_results",
+			req: &model.LLMRequest{
+				Contents: []*genai.Content{
+					genai.NewContentFromText("What is the weather in Paris and in Rome?", genai.RoleUser),
+					{Role: genai.RoleModel, Parts: []*genai.Part{
+						{FunctionCall: &genai.FunctionCall{ID: "call_paris", Name: "get_weather", Args: map[string]any{"city": "Paris"}}},
+						{FunctionCall: &genai.FunctionCall{ID: "call_rome", Name: "get_weather", Args: map[string]any{"city": "Rome"}}},
+					}},
+					{Role: genai.RoleUser, Parts: []*genai.Part{
+						{FunctionResponse: &genai.FunctionResponse{ID: "call_paris", Name: "get_weather", Response: map[string]any{"weather": "sunny"}}},
+						{FunctionResponse: &genai.FunctionResponse{ID: "call_rome", Name: "get_weather", Response: map[string]any{"weather": "rainy"}}},
+					}},
+				},
+				Config: &genai.GenerateContentConfig{Tools: []*genai.Tool{weatherTool}},
+			},
+			want: &model.LLMResponse{
+				Content: genai.NewContentFromText("It is sunny in Paris and rainy in Rome.", genai.RoleModel),
+				UsageMetadata: &genai.GenerateContentResponseUsageMetadata{
+					CandidatesTokenCount:    11,
+					PromptTokenCount:        123,
+					CachedContentTokenCount: 64,
+					TotalTokenCount:         134,
+				},
+				FinishReason: genai.FinishReasonStop,
+			},
+		},
+		{
+			name: "length",
+			req: &model.LLMRequest{
+				Contents: genai.Text("Write a poem about the sea."),
+				Config:   &genai.GenerateContentConfig{MaxOutputTokens: 5},
+			},
+			want: &model.LLMResponse{
+				Content: genai.NewContentFromText("The sea, vast and", genai.RoleModel),
+				UsageMetadata: &genai.GenerateContentResponseUsageMetadata{
+					CandidatesTokenCount: 5,
+					PromptTokenCount:     14,
+					TotalTokenCount:      19,
+				},
+				FinishReason: genai.FinishReasonMaxTokens,
+			},
+		},
+		{
+			name: "response_schema",
+			req: &model.LLMRequest{
+				Contents: genai.Text("What is the capital of France?"),
+				Config: &genai.GenerateContentConfig{
+					ResponseMIMEType: "application/json",
+					ResponseSchema: &genai.Schema{
+						Type:       genai.TypeObject,
+						Properties: map[string]*genai.Schema{"capital": {Type: genai.TypeString}},
+						Required:   []string{"capital"},
+					},
+				},
+			},
+			want: &model.LLMResponse{
+				Content: genai.NewContentFromText(`{"capital":"Paris"}`, genai.RoleModel),
+				UsageMetadata: &genai.GenerateContentResponseUsageMetadata{
+					CandidatesTokenCount: 7,
+					PromptTokenCount:     50,
+					TotalTokenCount:      57,
+				},
+				FinishReason: genai.FinishReasonStop,
+			},
+		},
+		{
+			name: "reasoning",
+			req: &model.LLMRequest{
+				Contents: genai.Text("What is 2+2?"),
+			},
+			want: &model.LLMResponse{
+				Content: &genai.Content{Role: genai.RoleModel, Parts: []*genai.Part{
+					{Text: "Two plus two is four.", Thought: true},
+					{Text: "4"},
+				}},
+				UsageMetadata: &genai.GenerateContentResponseUsageMetadata{
+					CandidatesTokenCount: 2,
+					PromptTokenCount:     12,
+					ThoughtsTokenCount:   8,
+					TotalTokenCount:      22,
+				},
+				FinishReason: genai.FinishReasonStop,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpRecordFilename := filepath.Join("testdata", strings.ReplaceAll(t.Name(), "/", "_")+".httprr")
+
+			testModel, err := NewModel(t.Context(), testModelName, newTestConfig(t, httpRecordFilename))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for got, err := range testModel.GenerateContent(t.Context(), tt.req, false) {
+				if err != nil {
+					t.Fatalf("Model.Generate() error = %v", err)
+				}
+				if diff := cmp.Diff(tt.want, got); diff != "" {
+					t.Errorf("Model.Generate() = %v, want %v\ndiff(-want +got):\n%v", got, tt.want, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestModel_Generate_APIError(t *testing.T) {
+	httpRecordFilename := filepath.Join("testdata", strings.ReplaceAll(t.Name(), "/", "_")+".httprr")
+	testModel, err := NewModel(t.Context(), testModelName, newTestConfig(t, httpRecordFilename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &model.LLMRequest{Contents: genai.Text(strings.Repeat("long ", 100))}
+	for _, err := range testModel.GenerateContent(t.Context(), req, false) {
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("Model.Generate() error = %v, want an *APIError", err)
+		}
+		want := &APIError{
+			StatusCode: http.StatusBadRequest,
+			Type:       "invalid_request_error",
+			Code:       "context_length_exceeded",
+			Message:    "This model's maximum context length is 64 tokens.",
+		}
+		if diff := cmp.Diff(want, apiErr); diff != "" {
+			t.Errorf("Model.Generate() error mismatch (-want +got):\n%s", diff)
+		}
+	}
+}
+
+func TestModel_GenerateStream(t *testing.T) {
+	tests := []struct {
+		name             string
+		req              *model.LLMRequest
+		want             string
+		wantUsage        *genai.GenerateContentResponseUsageMetadata
+		wantFinishReason genai.FinishReason
+	}{
+		{
+			name: "ok",
+			req: &model.LLMRequest{
+				Contents: genai.Text("What is the capital of France? Answer in a sentence."),
+				Config:   &genai.GenerateContentConfig{Temperature: new(float32)},
+			},
+			want:             "The capital of France is Paris.",
+			wantUsage:        &genai.GenerateContentResponseUsageMetadata{CandidatesTokenCount: 8, PromptTokenCount: 19, TotalTokenCount: 27},
+			wantFinishReason: genai.FinishReasonStop,
+		},
+		{
+			// Some servers don't report the usage of streamed responses.
+			name: "no_usage",
+			req: &model.LLMRequest{
+				Contents: genai.Text("What is the capital of Italy? Answer in a sentence."),
+			},
+			want:             "The capital of Italy is Rome.",
+			wantFinishReason: genai.FinishReasonStop,
+		},
+		{
+			name: "length",
+			req: &model.LLMRequest{
+				Contents: genai.Text("Write a poem about the sea."),
+				Config:   &genai.GenerateContentConfig{MaxOutputTokens: 5},
+			},
+			want:             "The sea, vast and",
+			wantUsage:        &genai.GenerateContentResponseUsageMetadata{CandidatesTokenCount: 5, PromptTokenCount: 14, TotalTokenCount: 19},
+			wantFinishReason: genai.FinishReasonMaxTokens,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpRecordFilename := filepath.Join("testdata", strings.ReplaceAll(t.Name(), "/", "_")+".httprr")
+
+			testModel, err := NewModel(t.Context(), testModelName, newTestConfig(t, httpRecordFilename))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var partial strings.Builder
+			var final []*model.LLMResponse
+			for resp, err := range testModel.GenerateContent(t.Context(), tt.req, true) {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if resp.Partial {
+					partial.WriteString(resp.Content.Parts[0].Text)
+				} else {
+					final = append(final, resp)
+				}
+			}
+
+			if diff := cmp.Diff(tt.want, partial.String()); diff != "" {
+				t.Errorf("Model.GenerateStream() partial text mismatch (-want +got):\n%s", diff)
+			}
+			// Since we are expecting GenerateStream to aggregate partial events, the text should be the same
+			want := []*model.LLMResponse{{
+				Content:       genai.NewContentFromText(tt.want, genai.RoleModel),
+				UsageMetadata: tt.wantUsage,
+				FinishReason:  tt.wantFinishReason,
+			}}
+			if diff := cmp.Diff(want, final); diff != "" {
+				t.Errorf("Model.GenerateStream() final responses mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestModel_GenerateStream_ParallelToolCalls(t *testing.T) {
+	httpRecordFilename := filepath.Join("testdata", strings.ReplaceAll(t.Name(), "/", "_")+".httprr")
+
+	testModel, err := NewModel(t.Context(), testModelName, newTestConfig(t, httpRecordFilename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &model.LLMRequest{
+		Contents: genai.Text("What is the weather in Paris and in Rome?"),
+		Config:   &genai.GenerateContentConfig{Tools: []*genai.Tool{weatherTool}},
+	}
+
+	var got []*model.LLMResponse
+	for resp, err := range testModel.GenerateContent(t.Context(), req, true) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, resp)
+	}
+
+	usage := &genai.GenerateContentResponseUsageMetadata{
+		CandidatesTokenCount: 46,
+		PromptTokenCount:     61,
+		TotalTokenCount:      107,
+	}
+	want := []*model.LLMResponse{
+		{Content: genai.NewContentFromText("Checking both.", genai.RoleModel), Partial: true},
+		// The aggregated text carries the finish reason of the response ending
+		// it, the usage of the call is only reported by that response.
+		{
+			Content:      genai.NewContentFromText("Checking both.", genai.RoleModel),
+			FinishReason: genai.FinishReasonStop,
+		},
+		{
+			Content: &genai.Content{Role: genai.RoleModel, Parts: []*genai.Part{
+				{FunctionCall: &genai.FunctionCall{ID: "call_paris", Name: "get_weather", Args: map[string]any{"city": "Paris"}}},
+				{FunctionCall: &genai.FunctionCall{ID: "call_rome", Name: "get_weather", Args: map[string]any{"city": "Rome"}}},
+			}},
+			UsageMetadata: usage,
+			FinishReason:  genai.FinishReasonStop,
+			TurnComplete:  true,
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Model.GenerateStream() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestModel_TrackingHeaders(t *testing.T) {
+	t.Run("verifies_headers_are_set", func(t *testing.T) {
+		httpRecordFilename := filepath.Join("testdata", strings.ReplaceAll(t.Name(), "/", "_")+".httprr")
+
+		cfg := newTestConfig(t, httpRecordFilename)
+		cfg.Header = http.Header{"Openai-Project": {"proj_test"}}
+		headersChecked := false
+		cfg.HTTPClient.Transport = &headerInterceptor{
+			base: cfg.HTTPClient.Transport,
+			check: func(req *http.Request) {
+				headersChecked = true
+				if ua := req.Header.Get("User-Agent"); !strings.Contains(ua, "google-adk/") || !strings.Contains(ua, "gl-go/") {
+					t.Errorf("User-Agent header should contain both 'google-adk/' and 'gl-go/', but got: %q", ua)
+				}
+				if ua := req.Header.Get("User-Agent"); !strings.HasSuffix(ua, " adk-test/1.0") {
+					t.Errorf("User-Agent header should end with the product of the context, but got: %q", ua)
+				}
+				if auth := req.Header.Get("Authorization"); !strings.HasPrefix(auth, "Bearer ") {
+					t.Errorf("Authorization header = %q, want a bearer token", auth)
+				}
+				if project := req.Header.Get("Openai-Project"); project != "proj_test" {
+					t.Errorf("Openai-Project header = %q, want %q", project, "proj_test")
+				}
+			},
+		}
+
+		openaiModel, err := NewModel(t.Context(), testModelName, cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req := &model.LLMRequest{Contents: genai.Text("ping")}
+		ctx := version.WithUserAgent(t.Context(), "adk-test/1.0")
+		for _, err := range openaiModel.GenerateContent(ctx, req, false) {
+			if err != nil {
+				t.Logf("GenerateContent finished with error (expected if no recording exists): %v", err)
+			}
+		}
+
+		if !headersChecked {
+			t.Error("HTTP request was not intercepted; headers not verified")
+		}
+	})
+}
+
+func TestNewModel(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "")
+	m, err := NewModel(t.Context(), "llama3", &Config{BaseURL: "http://localhost:8000/v1/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Config{BaseURL: "http://localhost:8000/v1", HTTPClient: http.DefaultClient}
+	if diff := cmp.Diff(want, m.(*openaiModel).cfg); diff != "" {
+		t.Errorf("NewModel() config mismatch (-want +got):\n%s", diff)
+	}
+	if got := m.Name(); got != "llama3" {
+		t.Errorf("Name() = %q, want %q", got, "llama3")
+	}
+
+	t.Setenv("OPENAI_API_KEY", "envkey")
+	m, err = NewModel(t.Context(), testModelName, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = Config{APIKey: "envkey", BaseURL: DefaultBaseURL, HTTPClient: http.DefaultClient}
+	if diff := cmp.Diff(want, m.(*openaiModel).cfg); diff != "" {
+		t.Errorf("NewModel() config mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestNewChatRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     *model.LLMRequest
+		stream  bool
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "stream",
+			req:    &model.LLMRequest{Contents: genai.Text("Hi")},
+			stream: true,
+			want:   `{"model":"m","messages":[{"role":"user","content":"Hi"}],"stream":true,"stream_options":{"include_usage":true}}`,
+		},
+		{
+			name: "messages",
+			req: &model.LLMRequest{Contents: []*genai.Content{
+				{Role: genai.RoleUser, Parts: []*genai.Part{{Text: "Look:"}, {InlineData: &genai.Blob{MIMEType: "image/png", Data: []byte("png")}}}},
+				{Role: genai.RoleModel, Parts: []*genai.Part{
+					{Text: "thinking", Thought: true},
+					{Text: "Let me check."},
+					{FunctionCall: &genai.FunctionCall{ID: "a", Name: "f"}},
+				}},
+				{Role: genai.RoleUser, Parts: []*genai.Part{
+					{FunctionResponse: &genai.FunctionResponse{ID: "a", Name: "f", Response: map[string]any{"result": 1}}},
+					{Text: "And now?"},
+				}},
+			}},
+			want: `{"model":"m","messages":[` +
+				`{"role":"user","content":[{"type":"text","text":"Look:"},{"type":"image_url","image_url":{"url":"data:image/png;base64,cG5n"}}]},` +
+				`{"role":"assistant","content":"Let me check.","tool_calls":[{"id":"a","type":"function","function":{"name":"f","arguments":"{}"}}]},` +
+				`{"role":"tool","content":"{\"result\":1}","tool_call_id":"a"},` +
+				`{"role":"user","content":"And now?"}]}`,
+		},
+		{
+			name: "config",
+			req: &model.LLMRequest{
+				Contents: genai.Text("Hi"),
+				Config: &genai.GenerateContentConfig{
+					SystemInstruction: &genai.Content{Parts: []*genai.Part{{Text: "Be brief."}, {Text: "Be kind."}}},
+					MaxOutputTokens:   50,
+					Temperature:       genai.Ptr[float32](0.5),
+					TopP:              genai.Ptr[float32](0.25),
+					StopSequences:     []string{"END"},
+					Seed:              genai.Ptr[int32](7),
+					Tools: []*genai.Tool{{FunctionDeclarations: []*genai.FunctionDeclaration{
+						{Name: "f", Description: "Does f."},
+						{Name: "g", ParametersJsonSchema: map[string]any{"type": "object"}},
+					}}},
+					ToolConfig: &genai.ToolConfig{FunctionCallingConfig: &genai.FunctionCallingConfig{
+						Mode:                 genai.FunctionCallingConfigModeAny,
+						AllowedFunctionNames: []string{"g"},
+					}},
+					ResponseJsonSchema: map[string]any{"type": "string"},
+				},
+			},
+			want: `{"model":"m","messages":[{"role":"system","content":"Be brief.\n\nBe kind."},{"role":"user","content":"Hi"}],` +
+				`"tools":[{"type":"function","function":{"name":"f","description":"Does f.","parameters":{"properties":{},"type":"object"}}},` +
+				`{"type":"function","function":{"name":"g","parameters":{"type":"object"}}}],` +
+				`"tool_choice":{"type":"function","function":{"name":"g"}},"temperature":0.5,"top_p":0.25,"max_tokens":50,"stop":["END"],"seed":7,` +
+				`"response_format":{"type":"json_schema","json_schema":{"name":"response","schema":{"type":"string"}}}}`,
+		},
+		{
+			name: "json_mode",
+			req: &model.LLMRequest{
+				Contents: genai.Text("Reply in JSON."),
+				Config: &genai.GenerateContentConfig{
+					ResponseMIMEType: "application/json",
+					Tools:            []*genai.Tool{weatherTool},
+					ToolConfig: &genai.ToolConfig{FunctionCallingConfig: &genai.FunctionCallingConfig{
+						Mode: genai.FunctionCallingConfigModeAny,
+					}},
+				},
+			},
+			want: `{"model":"m","messages":[{"role":"user","content":"Reply in JSON."}],` +
+				`"tools":[{"type":"function","function":{"name":"get_weather","description":"Returns the current weather of a city.","parameters":{"properties":{"city":{"type":"string"}},"required":["city"],"type":"object"}}}],` +
+				`"tool_choice":"required","response_format":{"type":"json_object"}}`,
+		},
+		{
+			name: "unsupported_tool",
+			req: &model.LLMRequest{
+				Contents: genai.Text("Hi"),
+				Config:   &genai.GenerateContentConfig{Tools: []*genai.Tool{{GoogleSearch: &genai.GoogleSearch{}}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unsupported_media",
+			req: &model.LLMRequest{Contents: []*genai.Content{{Role: genai.RoleUser, Parts: []*genai.Part{
+				{InlineData: &genai.Blob{MIMEType: "application/pdf", Data: []byte("pdf")}},
+			}}}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newChatRequest("m", tt.req, tt.stream)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newChatRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			data, err := json.Marshal(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, string(data)); diff != "" {
+				t.Errorf("newChatRequest() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestReadStream_ToolCallsWithoutIndex(t *testing.T) {
+	// Some servers send complete tool calls without index, and no finish
+	// reason.
+	stream := `data: {"choices":[{"index":0,"delta":{"tool_calls":[{"id":"a","function":{"name":"f","arguments":"{\"x\":1}"}},{"id":"b","function":{"name":"g","arguments":""}}]}}]}
+
+data: [DONE]
+`
+	var got []*genai.GenerateContentResponse
+	for resp, err := range readStream(strings.NewReader(stream)) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, resp)
+	}
+	want := []*genai.GenerateContentResponse{{Candidates: []*genai.Candidate{{Content: &genai.Content{
+		Role: genai.RoleModel,
+		Parts: []*genai.Part{
+			{FunctionCall: &genai.FunctionCall{ID: "a", Name: "f", Args: map[string]any{"x": float64(1)}}},
+			{FunctionCall: &genai.FunctionCall{ID: "b", Name: "g"}},
+		},
+	}}}}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("readStream() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestReadStream_Error(t *testing.T) {
+	stream := `data: {"choices":[{"index":0,"delta":{"content":"Hi"}}]}
+
+data: {"error":{"message":"The server is overloaded.","type":"server_error","code":503}}
+`
+	var err error
+	for _, err = range readStream(strings.NewReader(stream)) {
+	}
+	want := &APIError{Type: "server_error", Code: "503", Message: "The server is overloaded."}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("readStream() error = %v, want an *APIError", err)
+	}
+	if diff := cmp.Diff(want, apiErr); diff != "" {
+		t.Errorf("readStream() error mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestFinishReason(t *testing.T) {
+	tests := map[string]genai.FinishReason{
+		"":               "",
+		"stop":           genai.FinishReasonStop,
+		"tool_calls":     genai.FinishReasonStop,
+		"function_call":  genai.FinishReasonStop,
+		"length":         genai.FinishReasonMaxTokens,
+		"content_filter": genai.FinishReasonSafety,
+		"unknown":        genai.FinishReasonOther,
+	}
+	for reason, want := range tests {
+		if got := finishReason(reason); got != want {
+			t.Errorf("finishReason(%q) = %q, want %q", reason, got, want)
+		}
+	}
+}
+
+// newTestConfig returns the Config configured for record and replay.
+func newTestConfig(t *testing.T, rrfile string) *Config {
+	t.Helper()
+	rr, err := httprr.Open(rrfile, http.DefaultTransport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr.ScrubReq(scrubRequest)
+	cfg := &Config{HTTPClient: &http.Client{Transport: rr}}
+	if recording, _ := httprr.Recording(rrfile); !recording {
+		cfg.APIKey = "fakekey"
+	}
+	return cfg
+}
+
+func scrubRequest(req *http.Request) error {
+	req.Header.Del("Authorization")
+	req.Header.Del("User-Agent") // contains version numbers
+	b := req.Body.(*httprr.Body)
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, b.Data); err == nil {
+		b.Data = buf.Bytes()
+	}
+	return nil
+}
+
+// headerInterceptor is a http.RoundTripper that executes a check function on the request
+// before delegating to the base transport.
+type headerInterceptor struct {
+	base  http.RoundTripper
+	check func(*http.Request)
+}
+
+func (h *headerInterceptor) RoundTrip(req *http.Request) (*http.Response, error) {
+	if h.check != nil {
+		h.check(req)
+	}
+	return h.base.RoundTrip(req)
+}
@@ -0,0 +1,305 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openai
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/internal/llminternal/converters"
+	"google.golang.org/adk/model"
+)
+
+// chatRequest is the body of a request to the chat completions API.
+type chatRequest struct {
+	Model            string          `json:"model"`
+	Messages         []chatMessage   `json:"messages"`
+	Tools            []toolDef       `json:"tools,omitempty"`
+	ToolChoice       any             `json:"tool_choice,omitempty"`
+	Temperature      *float32        `json:"temperature,omitempty"`
+	TopP             *float32        `json:"top_p,omitempty"`
+	MaxTokens        int32           `json:"max_tokens,omitempty"`
+	Stop             []string        `json:"stop,omitempty"`
+	Seed             *int32          `json:"seed,omitempty"`
+	PresencePenalty  *float32        `json:"presence_penalty,omitempty"`
+	FrequencyPenalty *float32        `json:"frequency_penalty,omitempty"`
+	ResponseFormat   *responseFormat `json:"response_format,omitempty"`
+	Stream           bool            `json:"stream,omitempty"`
+	StreamOptions    *streamOptions  `json:"stream_options,omitempty"`
+}
+
+// chatMessage is a message of a request, whose content is either a string
+// or a list of content parts.
+type chatMessage struct {
+	Role       string     `json:"role"`
+	Content    any        `json:"content,omitempty"`
+	ToolCalls  []toolCall `json:"tool_calls,omitempty"`
+	ToolCallID string     `json:"tool_call_id,omitempty"`
+}
+
+type contentPart struct {
+	Type     string    `json:"type"`
+	Text     string    `json:"text,omitempty"`
+	ImageURL *imageURL `json:"image_url,omitempty"`
+}
+
+type imageURL struct {
+	URL string `json:"url"`
+}
+
+type toolCall struct {
+	// Index identifies the tool call of the chunks of a streamed response.
+	Index    *int         `json:"index,omitempty"`
+	ID       string       `json:"id,omitempty"`
+	Type     string       `json:"type,omitempty"`
+	Function functionCall `json:"function"`
+}
+
+type functionCall struct {
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments"`
+}
+
+type toolDef struct {
+	Type     string      `json:"type"`
+	Function functionDef `json:"function"`
+}
+
+type functionDef struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Parameters  any    `json:"parameters"`
+}
+
+type namedToolChoice struct {
+	Type     string `json:"type"`
+	Function struct {
+		Name string `json:"name"`
+	} `json:"function"`
+}
+
+type responseFormat struct {
+	Type       string      `json:"type"`
+	JSONSchema *jsonSchema `json:"json_schema,omitempty"`
+}
+
+type jsonSchema struct {
+	Name   string `json:"name"`
+	Schema any    `json:"schema"`
+}
+
+type streamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// newChatRequest translates the request to a request of the chat
+// completions API.
+func newChatRequest(modelName string, req *model.LLMRequest, stream bool) (*chatRequest, error) {
+	body := &chatRequest{Model: modelName, Stream: stream}
+	if stream {
+		// The servers that don't report the usage of streamed responses
+		// ignore the option.
+		body.StreamOptions = &streamOptions{IncludeUsage: true}
+	}
+
+	cfg := req.Config
+	if cfg != nil && cfg.SystemInstruction != nil {
+		var texts []string
+		for _, part := range cfg.SystemInstruction.Parts {
+			if part != nil && part.Text != "" {
+				texts = append(texts, part.Text)
+			}
+		}
+		if len(texts) > 0 {
+			body.Messages = append(body.Messages, chatMessage{Role: "system", Content: strings.Join(texts, "\n\n")})
+		}
+	}
+	for _, content := range req.Contents {
+		if content == nil {
+			continue
+		}
+		var (
+			messages []chatMessage
+			err      error
+		)
+		if content.Role == genai.RoleModel {
+			messages, err = assistantMessages(content)
+		} else {
+			messages, err = userMessages(content)
+		}
+		if err != nil {
+			return nil, err
+		}
+		body.Messages = append(body.Messages, messages...)
+	}
+
+	if cfg == nil {
+		return body, nil
+	}
+	body.Temperature = cfg.Temperature
+	body.TopP = cfg.TopP
+	body.MaxTokens = cfg.MaxOutputTokens
+	body.Stop = cfg.StopSequences
+	body.Seed = cfg.Seed
+	body.PresencePenalty = cfg.PresencePenalty
+	body.FrequencyPenalty = cfg.FrequencyPenalty
+	switch {
+	case cfg.ResponseJsonSchema != nil:
+		body.ResponseFormat = &responseFormat{Type: "json_schema", JSONSchema: &jsonSchema{Name: "response", Schema: cfg.ResponseJsonSchema}}
+	case cfg.ResponseSchema != nil:
+		body.ResponseFormat = &responseFormat{Type: "json_schema", JSONSchema: &jsonSchema{Name: "response", Schema: converters.SchemaToJSON(cfg.ResponseSchema)}}
+	case cfg.ResponseMIMEType == "application/json":
+		body.ResponseFormat = &responseFormat{Type: "json_object"}
+	}
+
+	for _, t := range cfg.Tools {
+		if t == nil {
+			continue
+		}
+		if t.FunctionDeclarations == nil {
+			return nil, fmt.Errorf("only function tools are supported by openai models")
+		}
+		for _, decl := range t.FunctionDeclarations {
+			body.Tools = append(body.Tools, toolDef{Type: "function", Function: functionDef{
+				Name:        decl.Name,
+				Description: decl.Description,
+				Parameters:  parameters(decl),
+			}})
+		}
+	}
+	if cfg.ToolConfig != nil && cfg.ToolConfig.FunctionCallingConfig != nil && len(body.Tools) > 0 {
+		fc := cfg.ToolConfig.FunctionCallingConfig
+		switch fc.Mode {
+		case genai.FunctionCallingConfigModeAny:
+			if len(fc.AllowedFunctionNames) == 1 {
+				choice := namedToolChoice{Type: "function"}
+				choice.Function.Name = fc.AllowedFunctionNames[0]
+				body.ToolChoice = choice
+			} else {
+				body.ToolChoice = "required"
+			}
+		case genai.FunctionCallingConfigModeNone:
+			body.ToolChoice = "none"
+		case genai.FunctionCallingConfigModeAuto:
+			body.ToolChoice = "auto"
+		}
+	}
+	return body, nil
+}
+
+// assistantMessages translates the content of the model to an assistant
+// message, whose function calls are tool calls.
+func assistantMessages(content *genai.Content) ([]chatMessage, error) {
+	var (
+		text strings.Builder
+		msg  = chatMessage{Role: "assistant"}
+	)
+	for _, part := range content.Parts {
+		switch {
+		case part == nil || part.Thought:
+			// The servers don't accept the reasoning of previous turns.
+		case part.Text != "":
+			text.WriteString(part.Text)
+		case part.FunctionCall != nil:
+			args, err := json.Marshal(part.FunctionCall.Args)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode arguments of function call %q: %w", part.FunctionCall.Name, err)
+			}
+			if part.FunctionCall.Args == nil {
+				args = []byte("{}")
+			}
+			msg.ToolCalls = append(msg.ToolCalls, toolCall{
+				ID:       part.FunctionCall.ID,
+				Type:     "function",
+				Function: functionCall{Name: part.FunctionCall.Name, Arguments: string(args)},
+			})
+		}
+	}
+	if text.Len() > 0 {
+		msg.Content = text.String()
+	}
+	if msg.Content == nil && msg.ToolCalls == nil {
+		return nil, nil
+	}
+	return []chatMessage{msg}, nil
+}
+
+// userMessages translates the content of the user to a tool message per
+// function response, followed by a user message of the other parts.
+func userMessages(content *genai.Content) ([]chatMessage, error) {
+	var (
+		messages []chatMessage
+		parts    []contentPart
+		textOnly = true
+	)
+	for _, part := range content.Parts {
+		switch {
+		case part == nil || part.Thought:
+		case part.Text != "":
+			parts = append(parts, contentPart{Type: "text", Text: part.Text})
+		case part.FunctionResponse != nil:
+			result, err := json.Marshal(part.FunctionResponse.Response)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode response of function %q: %w", part.FunctionResponse.Name, err)
+			}
+			messages = append(messages, chatMessage{Role: "tool", ToolCallID: part.FunctionResponse.ID, Content: string(result)})
+		case part.InlineData != nil:
+			if !strings.HasPrefix(part.InlineData.MIMEType, "image/") {
+				return nil, fmt.Errorf("unsupported MIME type %q for openai models", part.InlineData.MIMEType)
+			}
+			url := "data:" + part.InlineData.MIMEType + ";base64," + base64.StdEncoding.EncodeToString(part.InlineData.Data)
+			parts = append(parts, contentPart{Type: "image_url", ImageURL: &imageURL{URL: url}})
+			textOnly = false
+		case part.FileData != nil:
+			if !strings.HasPrefix(part.FileData.MIMEType, "image/") {
+				return nil, fmt.Errorf("unsupported MIME type %q for openai models", part.FileData.MIMEType)
+			}
+			parts = append(parts, contentPart{Type: "image_url", ImageURL: &imageURL{URL: part.FileData.FileURI}})
+			textOnly = false
+		}
+	}
+	switch {
+	case len(parts) == 0:
+	case textOnly:
+		// Plain text contents are sent as strings, which all the servers
+		// accept.
+		var text strings.Builder
+		for i, p := range parts {
+			if i > 0 {
+				text.WriteString("\n")
+			}
+			text.WriteString(p.Text)
+		}
+		messages = append(messages, chatMessage{Role: "user", Content: text.String()})
+	default:
+		messages = append(messages, chatMessage{Role: "user", Content: parts})
+	}
+	return messages, nil
+}
+
+// parameters returns the JSON schema of the parameters of the function.
+func parameters(decl *genai.FunctionDeclaration) any {
+	if decl.ParametersJsonSchema != nil {
+		return decl.ParametersJsonSchema
+	}
+	if decl.Parameters == nil {
+		return map[string]any{"type": "object", "properties": map[string]any{}}
+	}
+	return converters.SchemaToJSON(decl.Parameters)
+}
@@ -0,0 +1,301 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openai
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/internal/llminternal/converters"
+	"google.golang.org/adk/model"
+)
+
+// chatCompletion is the body of a response of the chat completions API.
+type chatCompletion struct {
+	ID      string   `json:"id"`
+	Model   string   `json:"model"`
+	Choices []choice `json:"choices"`
+	Usage   *usage   `json:"usage"`
+}
+
+type choice struct {
+	Index        int             `json:"index"`
+	Message      responseMessage `json:"message"`
+	FinishReason string          `json:"finish_reason"`
+}
+
+// responseMessage is the message of a response, or the delta of a chunk of
+// a streamed response.
+type responseMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+	Refusal string `json:"refusal"`
+	// ReasoningContent is the reasoning of the servers of reasoning models,
+	// e.g. vLLM.
+	ReasoningContent string     `json:"reasoning_content"`
+	ToolCalls        []toolCall `json:"tool_calls"`
+}
+
+type usage struct {
+	PromptTokens        int32 `json:"prompt_tokens"`
+	CompletionTokens    int32 `json:"completion_tokens"`
+	TotalTokens         int32 `json:"total_tokens"`
+	PromptTokensDetails *struct {
+		CachedTokens int32 `json:"cached_tokens"`
+	} `json:"prompt_tokens_details"`
+	CompletionTokensDetails *struct {
+		ReasoningTokens int32 `json:"reasoning_tokens"`
+	} `json:"completion_tokens_details"`
+}
+
+// chatCompletionChunk is a chunk of a streamed response.
+type chatCompletionChunk struct {
+	ID      string `json:"id"`
+	Model   string `json:"model"`
+	Choices []struct {
+		Index        int             `json:"index"`
+		Delta        responseMessage `json:"delta"`
+		FinishReason string          `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *usage    `json:"usage"`
+	Error *apiError `json:"error"`
+}
+
+type apiError struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+	// Code is a string for OpenAI, and a number for some servers.
+	Code json.RawMessage `json:"code"`
+}
+
+func (e *apiError) apiError(statusCode int) *APIError {
+	var code string
+	if err := json.Unmarshal(e.Code, &code); err != nil && len(e.Code) > 0 && string(e.Code) != "null" {
+		code = string(e.Code)
+	}
+	return &APIError{StatusCode: statusCode, Type: e.Type, Code: code, Message: e.Message}
+}
+
+// llmResponse translates the first choice of the completion to an LLM
+// response.
+func (c *chatCompletion) llmResponse() (*model.LLMResponse, error) {
+	if len(c.Choices) == 0 {
+		return nil, errors.New("model response has no choices")
+	}
+	choice := c.Choices[0]
+	content := &genai.Content{Role: genai.RoleModel}
+	if choice.Message.ReasoningContent != "" {
+		content.Parts = append(content.Parts, &genai.Part{Text: choice.Message.ReasoningContent, Thought: true})
+	}
+	if text := choice.Message.Content + choice.Message.Refusal; text != "" {
+		content.Parts = append(content.Parts, &genai.Part{Text: text})
+	}
+	for _, call := range choice.Message.ToolCalls {
+		part, err := functionCallPart(call)
+		if err != nil {
+			return nil, err
+		}
+		content.Parts = append(content.Parts, part)
+	}
+	return converters.Genai2LLMResponse(&genai.GenerateContentResponse{
+		Candidates:    []*genai.Candidate{{Content: content, FinishReason: finishReason(choice.FinishReason)}},
+		UsageMetadata: usageMetadata(c.Usage),
+		ModelVersion:  c.Model,
+		ResponseID:    c.ID,
+	}), nil
+}
+
+// functionCallPart translates the tool call to a function call part.
+func functionCallPart(call toolCall) (*genai.Part, error) {
+	var args map[string]any
+	if strings.TrimSpace(call.Function.Arguments) != "" {
+		if err := json.Unmarshal([]byte(call.Function.Arguments), &args); err != nil {
+			return nil, fmt.Errorf("failed to decode arguments of tool call %q: %w", call.Function.Name, err)
+		}
+	}
+	return &genai.Part{FunctionCall: &genai.FunctionCall{ID: call.ID, Name: call.Function.Name, Args: args}}, nil
+}
+
+// finishReason translates the finish reason of a choice. The choices of
+// the servers that don't report one have no finish reason either.
+func finishReason(reason string) genai.FinishReason {
+	switch reason {
+	case "":
+		return ""
+	case "stop", "tool_calls", "function_call":
+		return genai.FinishReasonStop
+	case "length":
+		return genai.FinishReasonMaxTokens
+	case "content_filter":
+		return genai.FinishReasonSafety
+	}
+	return genai.FinishReasonOther
+}
+
+// usageMetadata translates the token usage of a completion, whose
+// completion tokens include the reasoning tokens.
+func usageMetadata(u *usage) *genai.GenerateContentResponseUsageMetadata {
+	if u == nil {
+		return nil
+	}
+	md := &genai.GenerateContentResponseUsageMetadata{
+		PromptTokenCount:     u.PromptTokens,
+		CandidatesTokenCount: u.CompletionTokens,
+		TotalTokenCount:      u.TotalTokens,
+	}
+	if u.PromptTokensDetails != nil {
+		md.CachedContentTokenCount = u.PromptTokensDetails.CachedTokens
+	}
+	if u.CompletionTokensDetails != nil {
+		md.ThoughtsTokenCount = u.CompletionTokensDetails.ReasoningTokens
+		md.CandidatesTokenCount -= md.ThoughtsTokenCount
+	}
+	if md.TotalTokenCount == 0 {
+		md.TotalTokenCount = u.PromptTokens + u.CompletionTokens
+	}
+	return md
+}
+
+// streamState translates the chunks of a streamed response to genai
+// responses.
+//
+// The text and reasoning deltas are yielded as they arrive, and the tool
+// calls, whose arguments are streamed in fragments, once the choice is
+// finished. The last response is held back until the end of the stream, so
+// that it carries the finish reason and the usage, which OpenAI reports in a
+// last chunk without choices, like the last response of a Gemini stream.
+type streamState struct {
+	id, model string
+	// calls are the tool calls being streamed, in the order of their index.
+	calls   []*toolCall
+	finish  string
+	usage   *usage
+	pending *genai.GenerateContentResponse
+}
+
+// handle processes the chunk and returns the responses ready to be yielded.
+func (s *streamState) handle(chunk *chatCompletionChunk) ([]*genai.GenerateContentResponse, error) {
+	if chunk.Error != nil {
+		return nil, chunk.Error.apiError(0)
+	}
+	if chunk.ID != "" {
+		s.id = chunk.ID
+	}
+	if chunk.Model != "" {
+		s.model = chunk.Model
+	}
+	if chunk.Usage != nil {
+		s.usage = chunk.Usage
+	}
+	var responses []*genai.GenerateContentResponse
+	for _, c := range chunk.Choices {
+		if c.Index != 0 {
+			continue
+		}
+		delta := c.Delta
+		if delta.ReasoningContent != "" {
+			responses = s.push(responses, &genai.Part{Text: delta.ReasoningContent, Thought: true})
+		}
+		if text := delta.Content + delta.Refusal; text != "" {
+			responses = s.push(responses, &genai.Part{Text: text})
+		}
+		for _, call := range delta.ToolCalls {
+			s.addToolCall(call)
+		}
+		if c.FinishReason != "" {
+			s.finish = c.FinishReason
+			var err error
+			if responses, err = s.pushToolCalls(responses); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return responses, nil
+}
+
+// addToolCall merges the fragment of a tool call into the tool calls being
+// streamed. The fragments without index, which some servers send for
+// complete tool calls, are new tool calls.
+func (s *streamState) addToolCall(fragment toolCall) {
+	if fragment.Index == nil || *fragment.Index >= len(s.calls) {
+		s.calls = append(s.calls, &toolCall{})
+	}
+	call := s.calls[len(s.calls)-1]
+	if fragment.Index != nil {
+		call = s.calls[*fragment.Index]
+	}
+	if fragment.ID != "" {
+		call.ID = fragment.ID
+	}
+	if fragment.Function.Name != "" {
+		call.Function.Name = fragment.Function.Name
+	}
+	call.Function.Arguments += fragment.Function.Arguments
+}
+
+// pushToolCalls makes a response of the tool calls streamed so far pending.
+// The parallel tool calls are a single response, as they are for a unary
+// request.
+func (s *streamState) pushToolCalls(responses []*genai.GenerateContentResponse) ([]*genai.GenerateContentResponse, error) {
+	if len(s.calls) == 0 {
+		return responses, nil
+	}
+	parts := make([]*genai.Part, 0, len(s.calls))
+	for _, call := range s.calls {
+		part, err := functionCallPart(*call)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, part)
+	}
+	s.calls = nil
+	return s.push(responses, parts...), nil
+}
+
+// push makes a response of the parts pending, and appends the previously
+// pending one to the responses.
+func (s *streamState) push(responses []*genai.GenerateContentResponse, parts ...*genai.Part) []*genai.GenerateContentResponse {
+	if s.pending != nil {
+		responses = append(responses, s.pending)
+	}
+	s.pending = &genai.GenerateContentResponse{
+		Candidates:   []*genai.Candidate{{Content: &genai.Content{Role: genai.RoleModel, Parts: parts}}},
+		ModelVersion: s.model,
+		ResponseID:   s.id,
+	}
+	return responses
+}
+
+// close returns the responses left at the end of the stream, the last one
+// carrying the finish reason and the usage, if reported.
+func (s *streamState) close() ([]*genai.GenerateContentResponse, error) {
+	// The tool calls of the servers that don't report a finish reason.
+	responses, err := s.pushToolCalls(nil)
+	if err != nil {
+		return nil, err
+	}
+	if s.pending == nil {
+		return responses, nil
+	}
+	s.pending.Candidates[0].FinishReason = finishReason(s.finish)
+	s.pending.UsageMetadata = usageMetadata(s.usage)
+	responses = append(responses, s.pending)
+	s.pending = nil
+	return responses, nil
+}
@@ -0,0 +1,129 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"regexp"
+
+	"google.golang.org/genai"
+)
+
+// DefaultRedactionReplacement is the [RedactionRule.Replacement] used if it
+// is empty.
+const DefaultRedactionReplacement = "[REDACTED]"
+
+// RedactionRule replaces the matches of a pattern.
+type RedactionRule struct {
+	Pattern *regexp.Regexp
+	// Replacement replaces the matches, and may refer to their submatches
+	// like with [regexp.Regexp.ReplaceAllString].
+	// Optional: if empty, [DefaultRedactionReplacement] is used.
+	Replacement string
+}
+
+// RedactionConfig configures the interceptor returned by
+// [NewRedactionInterceptor].
+type RedactionConfig struct {
+	// Rules are applied in order.
+	Rules []RedactionRule
+	// Responses also redacts the responses of the model. The matches which
+	// span two partial responses of a stream are not redacted from the
+	// partial responses, only from the final aggregated response.
+	Responses bool
+}
+
+// NewRedactionInterceptor returns an [Interceptor] which redacts the text of
+// the contents and of the system instruction of the requests, as well as
+// the strings of their function calls and function responses, e.g. to keep
+// personal data from leaving the network. It is a reference implementation
+// of an interceptor.
+func NewRedactionInterceptor(cfg RedactionConfig) Interceptor {
+	rules := make([]RedactionRule, len(cfg.Rules))
+	for i, rule := range cfg.Rules {
+		if rule.Replacement == "" {
+			rule.Replacement = DefaultRedactionReplacement
+		}
+		rules[i] = rule
+	}
+	r := redactor{rules: rules}
+	interceptor := Interceptor{
+		Request: func(ctx context.Context, req *LLMRequest) error {
+			for _, content := range req.Contents {
+				r.content(content)
+			}
+			if req.Config != nil {
+				r.content(req.Config.SystemInstruction)
+			}
+			return nil
+		},
+	}
+	if cfg.Responses {
+		interceptor.Response = func(ctx context.Context, req *LLMRequest, resp *LLMResponse) error {
+			r.content(resp.Content)
+			return nil
+		}
+	}
+	return interceptor
+}
+
+type redactor struct {
+	rules []RedactionRule
+}
+
+func (r redactor) content(content *genai.Content) {
+	if content == nil {
+		return
+	}
+	for _, part := range content.Parts {
+		if part == nil {
+			continue
+		}
+		part.Text = r.text(part.Text)
+		if part.FunctionCall != nil {
+			part.FunctionCall.Args = r.value(part.FunctionCall.Args).(map[string]any)
+		}
+		if part.FunctionResponse != nil {
+			part.FunctionResponse.Response = r.value(part.FunctionResponse.Response).(map[string]any)
+		}
+	}
+}
+
+func (r redactor) text(s string) string {
+	for _, rule := range r.rules {
+		s = rule.Pattern.ReplaceAllString(s, rule.Replacement)
+	}
+	return s
+}
+
+// value redacts the strings of a value decoded from JSON, in place.
+func (r redactor) value(v any) any {
+	switch v := v.(type) {
+	case string:
+		return r.text(v)
+	case map[string]any:
+		for k, e := range v {
+			v[k] = r.value(e)
+		}
+		return v
+	case []any:
+		for i, e := range v {
+			v[i] = r.value(e)
+		}
+		return v
+	default:
+		return v
+	}
+}
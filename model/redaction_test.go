@@ -0,0 +1,96 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+)
+
+func TestNewRedactionInterceptor(t *testing.T) {
+	email := model.RedactionRule{Pattern: regexp.MustCompile(`[\w.+-]+@[\w-]+(\.[\w-]+)+`)}
+	phone := model.RedactionRule{Pattern: regexp.MustCompile(`\+?\d[\d -]{7,}\d`), Replacement: "[PHONE]"}
+	card := model.RedactionRule{Pattern: regexp.MustCompile(`\b(\d{4})[ -]?\d{4}[ -]?\d{4}[ -]?(\d{4})\b`), Replacement: "$1-****-****-$2"}
+
+	req := &model.LLMRequest{
+		Contents: []*genai.Content{
+			genai.NewContentFromText("Mail jane.doe@example.com or call +1 555 123 4567.", genai.RoleUser),
+			{Role: genai.RoleModel, Parts: []*genai.Part{genai.NewPartFromFunctionCall("charge", map[string]any{
+				"card":  "4111 1111 1111 1234",
+				"notes": []any{"for bob@example.org", 42.0},
+			})}},
+			{Role: genai.RoleUser, Parts: []*genai.Part{genai.NewPartFromFunctionResponse("charge", map[string]any{
+				"receipt": map[string]any{"to": "bob@example.org"},
+			})}},
+		},
+		Config: &genai.GenerateContentConfig{
+			SystemInstruction: genai.NewContentFromText("The user is jane.doe@example.com.", genai.RoleUser),
+		},
+	}
+	llm := &streamingModel{chunks: []string{"Sent to jane.doe@example.com"}, log: new([]string)}
+
+	tests := []struct {
+		name         string
+		cfg          model.RedactionConfig
+		wantResponse string
+	}{
+		{
+			name:         "requests",
+			cfg:          model.RedactionConfig{Rules: []model.RedactionRule{email, card, phone}},
+			wantResponse: "Sent to jane.doe@example.com",
+		},
+		{
+			name:         "responses",
+			cfg:          model.RedactionConfig{Rules: []model.RedactionRule{email, card, phone}, Responses: true},
+			wantResponse: "Sent to [REDACTED]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			for resp, err := range model.WithInterceptor(llm, model.NewRedactionInterceptor(tt.cfg)).GenerateContent(t.Context(), req, false) {
+				if err != nil {
+					t.Fatalf("GenerateContent() error = %v", err)
+				}
+				got = resp.Content.Parts[0].Text
+			}
+			if got != tt.wantResponse {
+				t.Errorf("response = %q, want %q", got, tt.wantResponse)
+			}
+
+			wantContents := []*genai.Content{
+				genai.NewContentFromText("Mail [REDACTED] or call [PHONE].", genai.RoleUser),
+				{Role: genai.RoleModel, Parts: []*genai.Part{genai.NewPartFromFunctionCall("charge", map[string]any{
+					"card":  "4111-****-****-1234",
+					"notes": []any{"for [REDACTED]", 42.0},
+				})}},
+				{Role: genai.RoleUser, Parts: []*genai.Part{genai.NewPartFromFunctionResponse("charge", map[string]any{
+					"receipt": map[string]any{"to": "[REDACTED]"},
+				})}},
+			}
+			if diff := cmp.Diff(wantContents, llm.req.Contents); diff != "" {
+				t.Errorf("request contents mismatch (-want +got):\n%s", diff)
+			}
+			if got, want := llm.req.Config.SystemInstruction.Parts[0].Text, "The user is [REDACTED]."; got != want {
+				t.Errorf("system instruction = %q, want %q", got, want)
+			}
+		})
+	}
+}
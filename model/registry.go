@@ -0,0 +1,187 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"path"
+	"sync"
+)
+
+// Factory creates the model with the name, which matched the pattern it was
+// registered with in a [Registry].
+type Factory func(ctx context.Context, name string) (LLM, error)
+
+// Registry resolves model names to models, with the factories of the model
+// backends registered for name patterns, e.g.:
+//
+//	r.Register("gemini-*", func(ctx context.Context, name string) (model.LLM, error) {
+//		return gemini.NewModel(ctx, name, nil)
+//	})
+//	r.Register("ollama/*", func(ctx context.Context, name string) (model.LLM, error) {
+//		return ollama.NewModel(ctx, strings.TrimPrefix(name, "ollama/"), nil)
+//	})
+//
+// Registry is safe for concurrent use.
+type Registry struct {
+	mu     sync.RWMutex
+	routes []route
+}
+
+type route struct {
+	pattern string
+	factory Factory
+}
+
+// DefaultRegistry is the registry the agents resolve their model names with
+// by default.
+var DefaultRegistry = NewRegistry()
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register registers the factory of the models whose names match the
+// pattern, in the syntax of [path.Match]. The names matching several
+// patterns are resolved with the first registered one.
+func (r *Registry) Register(pattern string, factory Factory) error {
+	if factory == nil {
+		return errors.New("model factory is nil")
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid model name pattern %q: %w", pattern, err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.routes = append(r.routes, route{pattern: pattern, factory: factory})
+	return nil
+}
+
+// Resolve returns the model with the name, followed by its fallback models
+// tried in order, as described in [NewFallback]. It returns an error if a
+// name matches no registered pattern.
+//
+// The models are created with their factories at their first use, so that
+// resolving them has no cost for the models that are never used, e.g. the
+// fallbacks.
+func (r *Registry) Resolve(name string, fallbacks ...string) (LLM, error) {
+	models := make([]LLM, 0, 1+len(fallbacks))
+	for _, name := range append([]string{name}, fallbacks...) {
+		factory, err := r.lookup(name)
+		if err != nil {
+			return nil, err
+		}
+		models = append(models, &lazyModel{name: name, factory: factory})
+	}
+	if len(models) == 1 {
+		return models[0], nil
+	}
+	return NewFallback(models...), nil
+}
+
+func (r *Registry) lookup(name string) (Factory, error) {
+	if name == "" {
+		return nil, errors.New("model name is empty")
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, rt := range r.routes {
+		if ok, _ := path.Match(rt.pattern, name); ok {
+			return rt.factory, nil
+		}
+	}
+	return nil, fmt.Errorf("no model is registered for name %q", name)
+}
+
+// lazyModel is a model created with its factory at its first use.
+type lazyModel struct {
+	name    string
+	factory Factory
+
+	mu  sync.Mutex
+	llm LLM
+}
+
+func (m *lazyModel) Name() string {
+	return m.name
+}
+
+// CountTokens counts the tokens of the request with the model, which is
+// created if needed.
+func (m *lazyModel) CountTokens(ctx context.Context, req *LLMRequest) (int, error) {
+	llm, err := m.get(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return CountTokens(ctx, llm, req)
+}
+
+// SupportsThinking reports whether the model supports thinking, creating it
+// if needed. It reports false if the model can't be created.
+func (m *lazyModel) SupportsThinking() bool {
+	llm, err := m.get(context.Background())
+	return err == nil && SupportsThinking(llm)
+}
+
+// SupportsLive reports whether the model supports live sessions, creating it
+// if needed. It reports false if the model can't be created.
+func (m *lazyModel) SupportsLive() bool {
+	llm, err := m.get(context.Background())
+	return err == nil && SupportsLive(llm)
+}
+
+// Connect opens a live session of the model, which is created if needed.
+func (m *lazyModel) Connect(ctx context.Context, req *LLMRequest) (LiveSession, error) {
+	llm, err := m.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return Connect(ctx, llm, req)
+}
+
+func (m *lazyModel) GenerateContent(ctx context.Context, req *LLMRequest, stream bool) iter.Seq2[*LLMResponse, error] {
+	return func(yield func(*LLMResponse, error) bool) {
+		llm, err := m.get(ctx)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		for resp, err := range llm.GenerateContent(ctx, req, stream) {
+			if !yield(resp, err) {
+				return
+			}
+		}
+	}
+}
+
+// get returns the model, created on the first call. The failed creations
+// are retried by the next calls.
+func (m *lazyModel) get(ctx context.Context) (LLM, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.llm != nil {
+		return m.llm, nil
+	}
+	llm, err := m.factory(ctx, m.name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create model %q: %w", m.name, err)
+	}
+	m.llm = llm
+	return llm, nil
+}
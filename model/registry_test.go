@@ -0,0 +1,186 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+)
+
+// fakeModel yields its responses, and then its error.
+type fakeModel struct {
+	name      string
+	responses []*model.LLMResponse
+	err       error
+	calls     int
+}
+
+func (m *fakeModel) Name() string {
+	return m.name
+}
+
+func (m *fakeModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		m.calls++
+		for _, resp := range m.responses {
+			if !yield(resp, nil) {
+				return
+			}
+		}
+		if m.err != nil {
+			yield(nil, m.err)
+		}
+	}
+}
+
+func textResponse(text string) *model.LLMResponse {
+	return &model.LLMResponse{Content: genai.NewContentFromText(text, genai.RoleModel)}
+}
+
+func TestRegistry_Resolve(t *testing.T) {
+	r := model.NewRegistry()
+	var created []string
+	factory := func(prefix string) model.Factory {
+		return func(ctx context.Context, name string) (model.LLM, error) {
+			created = append(created, name)
+			return &fakeModel{name: prefix + name, responses: []*model.LLMResponse{textResponse(prefix + name)}}, nil
+		}
+	}
+	for pattern, prefix := range map[string]string{"gemini-*": "g:", "ollama/*": "o:"} {
+		if err := r.Register(pattern, factory(prefix)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.Register("*", factory("any:")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "gemini-2.5-flash", want: "g:gemini-2.5-flash"},
+		{name: "ollama/llama3.2", want: "o:ollama/llama3.2"},
+		{name: "claude-sonnet-4-5", want: "any:claude-sonnet-4-5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			created = nil
+			llm, err := r.Resolve(tt.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := llm.Name(); got != tt.name {
+				t.Errorf("Name() = %q, want %q", got, tt.name)
+			}
+			if len(created) != 0 {
+				t.Errorf("Resolve() created models %v, want them created at first use", created)
+			}
+			for range 2 {
+				for resp, err := range llm.GenerateContent(t.Context(), &model.LLMRequest{}, false) {
+					if err != nil {
+						t.Fatal(err)
+					}
+					if got := resp.Content.Parts[0].Text; got != tt.want {
+						t.Errorf("GenerateContent() = %q, want %q", got, tt.want)
+					}
+				}
+			}
+			if diff := cmp.Diff([]string{tt.name}, created); diff != "" {
+				t.Errorf("created models mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRegistry_Errors(t *testing.T) {
+	r := model.NewRegistry()
+	if err := r.Register("gemini-[", func(ctx context.Context, name string) (model.LLM, error) { return nil, nil }); err == nil {
+		t.Error("Register() with an invalid pattern succeeded, want error")
+	}
+	if err := r.Register("gemini-*", nil); err == nil {
+		t.Error("Register() with a nil factory succeeded, want error")
+	}
+	errNoKey := errors.New("no API key")
+	calls := 0
+	if err := r.Register("claude-*", func(ctx context.Context, name string) (model.LLM, error) {
+		calls++
+		if calls == 1 {
+			return nil, errNoKey
+		}
+		return &fakeModel{name: name, responses: []*model.LLMResponse{textResponse("ok")}}, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"", "gpt-4o"} {
+		if _, err := r.Resolve(name); err == nil {
+			t.Errorf("Resolve(%q) succeeded, want error", name)
+		}
+	}
+	if _, err := r.Resolve("claude-sonnet-4-5", "gpt-4o"); err == nil {
+		t.Error("Resolve() with an unregistered fallback succeeded, want error")
+	}
+
+	llm, err := r.Resolve("claude-sonnet-4-5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, err := range llm.GenerateContent(t.Context(), &model.LLMRequest{}, false) {
+		if !errors.Is(err, errNoKey) {
+			t.Errorf("GenerateContent() error = %v, want %v", err, errNoKey)
+		}
+	}
+	// The failed creation is retried.
+	for _, err := range llm.GenerateContent(t.Context(), &model.LLMRequest{}, false) {
+		if err != nil {
+			t.Errorf("GenerateContent() error = %v", err)
+		}
+	}
+}
+
+func TestRegistry_Resolve_Fallbacks(t *testing.T) {
+	r := model.NewRegistry()
+	models := map[string]*fakeModel{
+		"primary":  {name: "primary", err: genai.APIError{Code: http.StatusTooManyRequests, Status: "RESOURCE_EXHAUSTED"}},
+		"fallback": {name: "fallback", responses: []*model.LLMResponse{textResponse("ok")}},
+	}
+	if err := r.Register("*", func(ctx context.Context, name string) (model.LLM, error) { return models[name], nil }); err != nil {
+		t.Fatal(err)
+	}
+	llm, err := r.Resolve("primary", "fallback")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := llm.Name(); got != "primary" {
+		t.Errorf("Name() = %q, want %q", got, "primary")
+	}
+	for resp, err := range llm.GenerateContent(t.Context(), &model.LLMRequest{}, false) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := resp.CustomMetadata[model.ModelMetadataKey]; got != "fallback" {
+			t.Errorf("response model = %v, want %q", got, "fallback")
+		}
+	}
+}
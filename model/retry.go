@@ -0,0 +1,249 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"maps"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// RetriesMetadataKey is the custom metadata key of the responses of the
+// models with retries, whose value is the number of retries of the call
+// that answered, when it was retried.
+const RetriesMetadataKey = "adk_model_retries"
+
+const (
+	// DefaultMaxAttempts is the default [RetryPolicy.MaxAttempts].
+	DefaultMaxAttempts = 4
+	// DefaultInitialBackoff is the default [RetryPolicy.InitialBackoff].
+	DefaultInitialBackoff = time.Second
+	// DefaultMaxBackoff is the default [RetryPolicy.MaxBackoff].
+	DefaultMaxBackoff = 30 * time.Second
+)
+
+// RetryPolicy is the policy of the retries of the calls of a model.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts of a call, including
+	// the first one. Defaults to [DefaultMaxAttempts].
+	MaxAttempts int
+	// InitialBackoff is the backoff before the first retry, doubled for
+	// each following one. Defaults to [DefaultInitialBackoff].
+	InitialBackoff time.Duration
+	// MaxBackoff caps the backoffs. Defaults to [DefaultMaxBackoff].
+	MaxBackoff time.Duration
+	// MaxElapsed is the time budget of a call with its retries, measured
+	// from its first attempt. The calls aren't retried when the backoff
+	// would exceed it. Zero means no budget.
+	MaxElapsed time.Duration
+	// Retryable reports whether a call failing with the error is retried.
+	// Defaults to [IsRetryable].
+	Retryable func(error) bool
+}
+
+// StreamInterruptedError is the error of the calls of a model that failed
+// after yielding responses, e.g. a stream that failed after some partial
+// text. They are not retried, as the retry would repeat the responses, and
+// the flows can react to the error, e.g. by discarding the partial text.
+type StreamInterruptedError struct {
+	// Err is the error of the model.
+	Err error
+}
+
+func (e *StreamInterruptedError) Error() string {
+	return fmt.Sprintf("model stream interrupted: %v", e.Err)
+}
+
+func (e *StreamInterruptedError) Unwrap() error {
+	return e.Err
+}
+
+type retryModel struct {
+	llm    LLM
+	policy RetryPolicy
+}
+
+// WithRetry returns the model retrying the calls of the model that fail with
+// a retryable error before yielding a response, with an exponential backoff
+// with jitter. The delay the errors ask for, e.g. with a Retry-After header,
+// is honored instead of the backoff.
+//
+// The calls failing after yielding a response fail with a
+// [*StreamInterruptedError]. The custom metadata of the responses of the
+// retried calls records their number of retries with the
+// [RetriesMetadataKey].
+func WithRetry(llm LLM, policy RetryPolicy) LLM {
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = DefaultMaxAttempts
+	}
+	if policy.InitialBackoff <= 0 {
+		policy.InitialBackoff = DefaultInitialBackoff
+	}
+	if policy.MaxBackoff <= 0 {
+		policy.MaxBackoff = DefaultMaxBackoff
+	}
+	if policy.Retryable == nil {
+		policy.Retryable = IsRetryable
+	}
+	return &retryModel{llm: llm, policy: policy}
+}
+
+func (m *retryModel) Name() string {
+	return m.llm.Name()
+}
+
+// CountTokens counts the tokens of the request with the retried model.
+func (m *retryModel) CountTokens(ctx context.Context, req *LLMRequest) (int, error) {
+	return CountTokens(ctx, m.llm, req)
+}
+
+// SupportsThinking reports whether the retried model supports thinking.
+func (m *retryModel) SupportsThinking() bool {
+	return SupportsThinking(m.llm)
+}
+
+// SupportsLive reports whether the retried model supports live sessions.
+func (m *retryModel) SupportsLive() bool {
+	return SupportsLive(m.llm)
+}
+
+// Connect opens a live session of the retried model. The live sessions
+// aren't retried.
+func (m *retryModel) Connect(ctx context.Context, req *LLMRequest) (LiveSession, error) {
+	return Connect(ctx, m.llm, req)
+}
+
+func (m *retryModel) GenerateContent(ctx context.Context, req *LLMRequest, stream bool) iter.Seq2[*LLMResponse, error] {
+	return func(yield func(*LLMResponse, error) bool) {
+		start := time.Now()
+		backoff := m.policy.InitialBackoff
+		for attempt := 1; ; attempt++ {
+			yielded := false
+			var retryErr error
+			for resp, err := range GenerateWithTimeouts(ctx, m.llm, req, stream) {
+				if err != nil {
+					if yielded {
+						err = &StreamInterruptedError{Err: err}
+					} else if attempt < m.policy.MaxAttempts && ctx.Err() == nil && m.policy.Retryable(err) {
+						retryErr = err
+						break
+					} else if attempt > 1 {
+						err = fmt.Errorf("model call failed after %d attempts: %w", attempt, err)
+					}
+				}
+				if resp != nil && attempt > 1 {
+					resp.CustomMetadata = maps.Clone(resp.CustomMetadata)
+					if resp.CustomMetadata == nil {
+						resp.CustomMetadata = make(map[string]any)
+					}
+					resp.CustomMetadata[RetriesMetadataKey] = attempt - 1
+				}
+				yielded = true
+				if !yield(resp, err) {
+					return
+				}
+			}
+			if retryErr == nil {
+				return
+			}
+
+			delay := RetryDelay(retryErr)
+			if delay <= 0 {
+				// Equal jitter, between half the backoff and the backoff.
+				delay = backoff/2 + rand.N(backoff/2+1)
+				backoff = min(2*backoff, m.policy.MaxBackoff)
+			}
+			if m.policy.MaxElapsed > 0 && time.Since(start)+delay > m.policy.MaxElapsed {
+				yield(nil, fmt.Errorf("model call failed after %d attempts, retry budget exhausted: %w", attempt, retryErr))
+				return
+			}
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				yield(nil, fmt.Errorf("model call failed after %d attempts: %w", attempt, errors.Join(retryErr, ctx.Err())))
+				return
+			case <-timer.C:
+			}
+		}
+	}
+}
+
+// enforcesTimeouts reports that the timeouts of the requests apply to each
+// attempt, so that the attempts which time out are retried.
+func (m *retryModel) enforcesTimeouts() bool {
+	return true
+}
+
+// retryDelayer is implemented by the errors of the model APIs reporting how
+// long to wait before retrying, e.g. with a Retry-After header.
+type retryDelayer interface {
+	RetryAfter() time.Duration
+}
+
+// RetryDelay returns how long the error asks to wait before retrying, or
+// zero. The delay is that of the errors with a RetryAfter() time.Duration
+// method, or of the RetryInfo details of the genai API errors.
+func RetryDelay(err error) time.Duration {
+	var rd retryDelayer
+	if errors.As(err, &rd) {
+		return rd.RetryAfter()
+	}
+	var details []map[string]any
+	var apiErr genai.APIError
+	var apiErrPtr *genai.APIError
+	switch {
+	case errors.As(err, &apiErr):
+		details = apiErr.Details
+	case errors.As(err, &apiErrPtr):
+		details = apiErrPtr.Details
+	}
+	for _, detail := range details {
+		if typ, _ := detail["@type"].(string); !strings.HasSuffix(typ, "google.rpc.RetryInfo") {
+			continue
+		}
+		if delay, ok := detail["retryDelay"].(string); ok {
+			if d, err := time.ParseDuration(delay); err == nil {
+				return d
+			}
+		}
+	}
+	return 0
+}
+
+// ParseRetryAfter returns the delay of the Retry-After header of the
+// response, in seconds or as a date, or zero.
+func ParseRetryAfter(header http.Header) time.Duration {
+	value := strings.TrimSpace(header.Get("Retry-After"))
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		return max(time.Duration(seconds*float64(time.Second)), 0)
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0)
+	}
+	return 0
+}
@@ -0,0 +1,256 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+)
+
+// flakyModel fails its first calls with the errors, and then answers.
+type flakyModel struct {
+	errs  []error
+	calls int
+	times []time.Time
+}
+
+func (m *flakyModel) Name() string {
+	return "flaky"
+}
+
+func (m *flakyModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		m.calls++
+		m.times = append(m.times, time.Now())
+		if m.calls <= len(m.errs) {
+			yield(nil, m.errs[m.calls-1])
+			return
+		}
+		yield(textResponse("ok"), nil)
+	}
+}
+
+type retryAfterError struct {
+	statusError
+	delay time.Duration
+}
+
+func (e retryAfterError) RetryAfter() time.Duration { return e.delay }
+
+func TestWithRetry(t *testing.T) {
+	unavailable := statusError(http.StatusServiceUnavailable)
+	policy := model.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}
+	tests := []struct {
+		name      string
+		errs      []error
+		policy    model.RetryPolicy
+		want      []*model.LLMResponse
+		wantErr   bool
+		wantCalls int
+	}{
+		{
+			name:      "no_retry",
+			policy:    policy,
+			want:      []*model.LLMResponse{textResponse("ok")},
+			wantCalls: 1,
+		},
+		{
+			name:   "retried",
+			errs:   []error{genai.APIError{Code: http.StatusTooManyRequests}, unavailable},
+			policy: policy,
+			want: []*model.LLMResponse{{
+				Content:        genai.NewContentFromText("ok", genai.RoleModel),
+				CustomMetadata: map[string]any{model.RetriesMetadataKey: 2},
+			}},
+			wantCalls: 3,
+		},
+		{
+			name:      "non_retryable",
+			errs:      []error{statusError(http.StatusBadRequest)},
+			policy:    policy,
+			wantErr:   true,
+			wantCalls: 1,
+		},
+		{
+			name:      "max_attempts",
+			errs:      []error{unavailable, unavailable, unavailable},
+			policy:    policy,
+			wantErr:   true,
+			wantCalls: 3,
+		},
+		{
+			name:      "max_elapsed",
+			errs:      []error{retryAfterError{unavailable, time.Hour}},
+			policy:    model.RetryPolicy{MaxAttempts: 3, MaxElapsed: time.Minute},
+			wantErr:   true,
+			wantCalls: 1,
+		},
+		{
+			name:   "custom_retryable",
+			errs:   []error{statusError(http.StatusBadRequest)},
+			policy: model.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond, Retryable: func(error) bool { return true }},
+			want: []*model.LLMResponse{{
+				Content:        genai.NewContentFromText("ok", genai.RoleModel),
+				CustomMetadata: map[string]any{model.RetriesMetadataKey: 1},
+			}},
+			wantCalls: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			llm := &flakyModel{errs: tt.errs}
+			var got []*model.LLMResponse
+			var gotErr error
+			for resp, err := range model.WithRetry(llm, tt.policy).GenerateContent(t.Context(), &model.LLMRequest{}, true) {
+				if err != nil {
+					gotErr = err
+					continue
+				}
+				got = append(got, resp)
+			}
+			if (gotErr != nil) != tt.wantErr {
+				t.Fatalf("GenerateContent() error = %v, wantErr %v", gotErr, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("GenerateContent() mismatch (-want +got):\n%s", diff)
+			}
+			if llm.calls != tt.wantCalls {
+				t.Errorf("GenerateContent() calls = %d, want %d", llm.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestWithRetry_RetryAfter(t *testing.T) {
+	delay := 50 * time.Millisecond
+	llm := &flakyModel{errs: []error{retryAfterError{statusError(http.StatusTooManyRequests), delay}}}
+	policy := model.RetryPolicy{InitialBackoff: time.Millisecond}
+	for _, err := range model.WithRetry(llm, policy).GenerateContent(t.Context(), &model.LLMRequest{}, false) {
+		if err != nil {
+			t.Fatalf("GenerateContent() error = %v", err)
+		}
+	}
+	if len(llm.times) != 2 {
+		t.Fatalf("GenerateContent() calls = %d, want 2", len(llm.times))
+	}
+	if got := llm.times[1].Sub(llm.times[0]); got < delay {
+		t.Errorf("GenerateContent() retried after %v, want at least %v", got, delay)
+	}
+}
+
+func TestWithRetry_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	llm := &flakyModel{errs: []error{statusError(http.StatusServiceUnavailable)}}
+	policy := model.RetryPolicy{InitialBackoff: time.Hour, MaxBackoff: time.Hour}
+	time.AfterFunc(10*time.Millisecond, cancel)
+	var gotErr error
+	for _, err := range model.WithRetry(llm, policy).GenerateContent(ctx, &model.LLMRequest{}, false) {
+		gotErr = err
+	}
+	if !errors.Is(gotErr, context.Canceled) {
+		t.Errorf("GenerateContent() error = %v, want %v", gotErr, context.Canceled)
+	}
+	if llm.calls != 1 {
+		t.Errorf("GenerateContent() calls = %d, want 1", llm.calls)
+	}
+}
+
+func TestWithRetry_StreamInterrupted(t *testing.T) {
+	streamErr := statusError(http.StatusServiceUnavailable)
+	llm := &fakeModel{
+		name:      "a",
+		responses: []*model.LLMResponse{{Content: genai.NewContentFromText("par", genai.RoleModel), Partial: true}},
+		err:       streamErr,
+	}
+	policy := model.RetryPolicy{InitialBackoff: time.Millisecond}
+	var got []*model.LLMResponse
+	var gotErr error
+	for resp, err := range model.WithRetry(llm, policy).GenerateContent(t.Context(), &model.LLMRequest{}, true) {
+		if err != nil {
+			gotErr = err
+			continue
+		}
+		got = append(got, resp)
+	}
+	var interrupted *model.StreamInterruptedError
+	if !errors.As(gotErr, &interrupted) || !errors.Is(gotErr, streamErr) {
+		t.Errorf("GenerateContent() error = %v, want a StreamInterruptedError wrapping %v", gotErr, streamErr)
+	}
+	if len(got) != 1 {
+		t.Errorf("GenerateContent() responses = %d, want 1", len(got))
+	}
+	if llm.calls != 1 {
+		t.Errorf("GenerateContent() calls = %d, want 1", llm.calls)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want time.Duration
+	}{
+		{name: "none", err: statusError(http.StatusTooManyRequests), want: 0},
+		{name: "retry_after", err: retryAfterError{statusError(http.StatusTooManyRequests), 3 * time.Second}, want: 3 * time.Second},
+		{
+			name: "retry_info",
+			err: genai.APIError{Code: http.StatusTooManyRequests, Details: []map[string]any{
+				{"@type": "type.googleapis.com/google.rpc.QuotaFailure"},
+				{"@type": "type.googleapis.com/google.rpc.RetryInfo", "retryDelay": "12s"},
+			}},
+			want: 12 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := model.RetryDelay(tt.err); got != tt.want {
+				t.Errorf("RetryDelay(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{name: "none", value: "", want: 0},
+		{name: "seconds", value: "7", want: 7 * time.Second},
+		{name: "past_date", value: "Mon, 02 Jan 2006 15:04:05 GMT", want: 0},
+		{name: "invalid", value: "soon", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.value != "" {
+				header.Set("Retry-After", tt.value)
+			}
+			if got := model.ParseRetryAfter(header); got != tt.want {
+				t.Errorf("ParseRetryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+// ThinkingSupporter is implemented by the models which know whether they
+// support thinking, i.e. honor the ThinkingConfig of the requests and reply
+// with thought parts.
+type ThinkingSupporter interface {
+	SupportsThinking() bool
+}
+
+// SupportsThinking reports whether the model supports thinking. The models
+// which don't implement [ThinkingSupporter] are assumed not to. The model
+// wrappers of this package report whether the models they wrap support it,
+// and the fallback models whether all of them do.
+func SupportsThinking(llm LLM) bool {
+	supporter, ok := llm.(ThinkingSupporter)
+	return ok && supporter.SupportsThinking()
+}
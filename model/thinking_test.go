@@ -0,0 +1,51 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"testing"
+
+	"google.golang.org/adk/model"
+)
+
+type thinkingModel struct {
+	fakeModel
+}
+
+func (*thinkingModel) SupportsThinking() bool {
+	return true
+}
+
+func TestSupportsThinking(t *testing.T) {
+	thinking, plain := &thinkingModel{fakeModel{name: "thinking"}}, &fakeModel{name: "plain"}
+	tests := []struct {
+		name string
+		llm  model.LLM
+		want bool
+	}{
+		{"thinking", thinking, true},
+		{"plain", plain, false},
+		{"retry", model.WithRetry(thinking, model.RetryPolicy{}), true},
+		{"interceptor", model.WithInterceptor(thinking), true},
+		{"cache", model.WithCache(plain, model.NewLRUCache(1024), model.CacheConfig{}), false},
+		{"fallback", model.NewFallback(thinking, thinking), true},
+		{"fallback to plain", model.NewFallback(thinking, plain), false},
+	}
+	for _, tt := range tests {
+		if got := model.SupportsThinking(tt.llm); got != tt.want {
+			t.Errorf("SupportsThinking(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
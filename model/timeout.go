@@ -0,0 +1,119 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"time"
+)
+
+// ErrorCodeTimeout is the error code of the responses of the model calls
+// which timed out.
+const ErrorCodeTimeout = "MODEL_TIMEOUT"
+
+// TimeoutError is the error of a model call which exceeded the Timeout or
+// the IdleTimeout of its request. It is retryable, see [IsRetryable].
+type TimeoutError struct {
+	// Model is the name of the model.
+	Model   string
+	Timeout time.Duration
+	// Idle is set if the timeout is the IdleTimeout of a stream, which
+	// expired between two responses.
+	Idle bool
+}
+
+func (e *TimeoutError) Error() string {
+	if e.Idle {
+		return fmt.Sprintf("model %s stream was idle for %v", e.Model, e.Timeout)
+	}
+	return fmt.Sprintf("model %s call timed out after %v", e.Model, e.Timeout)
+}
+
+// timeoutEnforcer is implemented by the model wrappers which enforce the
+// timeouts of the requests on each of the calls of their models, e.g. on
+// each attempt of a retried call.
+type timeoutEnforcer interface {
+	enforcesTimeouts() bool
+}
+
+// GenerateWithTimeouts calls the model with the timeouts of the request:
+// the Timeout bounds an unary call, or the time to the first response of a
+// streaming call, and the IdleTimeout bounds the time between the responses
+// of a streaming call. The time spent by the caller between the responses
+// isn't counted.
+//
+// A call which exceeds a timeout is canceled and fails with a
+// [*TimeoutError].
+func GenerateWithTimeouts(ctx context.Context, llm LLM, req *LLMRequest, stream bool) iter.Seq2[*LLMResponse, error] {
+	if e, ok := llm.(timeoutEnforcer); ok && e.enforcesTimeouts() {
+		return llm.GenerateContent(ctx, req, stream)
+	}
+	if req.Timeout <= 0 && (!stream || req.IdleTimeout <= 0) {
+		return llm.GenerateContent(ctx, req, stream)
+	}
+	if !stream {
+		return func(yield func(*LLMResponse, error) bool) {
+			ctx, cancel := context.WithTimeoutCause(ctx, req.Timeout, &TimeoutError{Model: llm.Name(), Timeout: req.Timeout})
+			defer cancel()
+			for resp, err := range llm.GenerateContent(ctx, req, stream) {
+				if !yield(resp, timeoutCause(ctx, err)) {
+					return
+				}
+			}
+		}
+	}
+
+	return func(yield func(*LLMResponse, error) bool) {
+		ctx, cancel := context.WithCancelCause(ctx)
+		defer cancel(nil)
+		start := func(timeout time.Duration, idle bool) *time.Timer {
+			if timeout <= 0 {
+				return nil
+			}
+			return time.AfterFunc(timeout, func() {
+				cancel(&TimeoutError{Model: llm.Name(), Timeout: timeout, Idle: idle})
+			})
+		}
+		timer := start(req.Timeout, false)
+		for resp, err := range llm.GenerateContent(ctx, req, stream) {
+			if timer != nil {
+				timer.Stop()
+			}
+			if !yield(resp, timeoutCause(ctx, err)) {
+				return
+			}
+			timer = start(req.IdleTimeout, true)
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// timeoutCause returns the [*TimeoutError] which canceled the context of a
+// failed call, or err.
+func timeoutCause(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	var timeoutErr *TimeoutError
+	if errors.As(context.Cause(ctx), &timeoutErr) {
+		return timeoutErr
+	}
+	return err
+}
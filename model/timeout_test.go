@@ -0,0 +1,175 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"google.golang.org/adk/model"
+)
+
+// slowModel yields its responses after their delays, unless its context is
+// done first.
+type slowModel struct {
+	delays []time.Duration
+	calls  int
+}
+
+func (m *slowModel) Name() string {
+	return "slow"
+}
+
+func (m *slowModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		m.calls++
+		for _, delay := range m.delays {
+			select {
+			case <-ctx.Done():
+				yield(nil, ctx.Err())
+				return
+			case <-time.After(delay):
+			}
+			if !yield(textResponse("ok"), nil) {
+				return
+			}
+		}
+	}
+}
+
+func TestGenerateWithTimeouts(t *testing.T) {
+	const short, long = 10 * time.Millisecond, time.Second
+	tests := []struct {
+		name      string
+		delays    []time.Duration
+		req       *model.LLMRequest
+		stream    bool
+		wantCount int
+		wantErr   *model.TimeoutError
+	}{
+		{
+			name:      "no_timeout",
+			delays:    []time.Duration{short},
+			req:       &model.LLMRequest{},
+			wantCount: 1,
+		},
+		{
+			name:      "within_timeout",
+			delays:    []time.Duration{0},
+			req:       &model.LLMRequest{Timeout: long},
+			wantCount: 1,
+		},
+		{
+			name:    "unary_timeout",
+			delays:  []time.Duration{long},
+			req:     &model.LLMRequest{Timeout: short},
+			wantErr: &model.TimeoutError{Model: "slow", Timeout: short},
+		},
+		{
+			name:    "first_response_timeout",
+			delays:  []time.Duration{long},
+			req:     &model.LLMRequest{Timeout: short, IdleTimeout: long},
+			stream:  true,
+			wantErr: &model.TimeoutError{Model: "slow", Timeout: short},
+		},
+		{
+			name:      "idle_timeout",
+			delays:    []time.Duration{0, 0, long},
+			req:       &model.LLMRequest{Timeout: long, IdleTimeout: short},
+			stream:    true,
+			wantCount: 2,
+			wantErr:   &model.TimeoutError{Model: "slow", Timeout: short, Idle: true},
+		},
+		{
+			// The timeout of streaming calls only bounds the time to their
+			// first response.
+			name:      "stream_longer_than_timeout",
+			delays:    []time.Duration{0, 2 * short, 2 * short},
+			req:       &model.LLMRequest{Timeout: short},
+			stream:    true,
+			wantCount: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count := 0
+			var gotErr error
+			for resp, err := range model.GenerateWithTimeouts(t.Context(), &slowModel{delays: tt.delays}, tt.req, tt.stream) {
+				if err != nil {
+					gotErr = err
+					break
+				}
+				if resp != nil {
+					count++
+				}
+			}
+			if count != tt.wantCount {
+				t.Errorf("got %d responses, want %d", count, tt.wantCount)
+			}
+			if tt.wantErr == nil {
+				if gotErr != nil {
+					t.Fatalf("GenerateWithTimeouts() error = %v", gotErr)
+				}
+				return
+			}
+			var timeoutErr *model.TimeoutError
+			if !errors.As(gotErr, &timeoutErr) {
+				t.Fatalf("GenerateWithTimeouts() error = %v, want a TimeoutError", gotErr)
+			}
+			if diff := cmp.Diff(tt.wantErr, timeoutErr); diff != "" {
+				t.Errorf("GenerateWithTimeouts() error mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGenerateWithTimeouts_CallerTime(t *testing.T) {
+	// The time spent by the caller between the responses isn't counted.
+	req := &model.LLMRequest{IdleTimeout: 10 * time.Millisecond}
+	count := 0
+	for _, err := range model.GenerateWithTimeouts(t.Context(), &slowModel{delays: []time.Duration{0, 0}}, req, true) {
+		if err != nil {
+			t.Fatalf("GenerateWithTimeouts() error = %v", err)
+		}
+		count++
+		time.Sleep(50 * time.Millisecond)
+	}
+	if count != 2 {
+		t.Errorf("got %d responses, want 2", count)
+	}
+}
+
+func TestWithRetry_Timeout(t *testing.T) {
+	// Each attempt has its own timeout.
+	llm := &slowModel{delays: []time.Duration{time.Second}}
+	policy := model.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}
+	req := &model.LLMRequest{Timeout: 10 * time.Millisecond}
+	var gotErr error
+	for _, err := range model.GenerateWithTimeouts(t.Context(), model.WithRetry(llm, policy), req, false) {
+		gotErr = err
+	}
+	var timeoutErr *model.TimeoutError
+	if !errors.As(gotErr, &timeoutErr) {
+		t.Errorf("GenerateContent() error = %v, want a TimeoutError", gotErr)
+	}
+	if llm.calls != 2 {
+		t.Errorf("got %d calls, want 2", llm.calls)
+	}
+}
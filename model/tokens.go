@@ -0,0 +1,85 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"encoding/json"
+
+	"google.golang.org/genai"
+)
+
+// charsPerToken is the number of characters of a token assumed by
+// [EstimateTokens].
+const charsPerToken = 4
+
+// TokenCounter is implemented by the models which count the tokens of the
+// requests, e.g. with a count-tokens API.
+type TokenCounter interface {
+	CountTokens(ctx context.Context, req *LLMRequest) (int, error)
+}
+
+// CountTokens returns the number of tokens of the request, counted by the
+// model if it implements [TokenCounter], or else estimated by
+// [EstimateTokens]. The model wrappers of this package count the tokens
+// with the models they wrap.
+func CountTokens(ctx context.Context, llm LLM, req *LLMRequest) (int, error) {
+	if counter, ok := llm.(TokenCounter); ok {
+		return counter.CountTokens(ctx, req)
+	}
+	return EstimateTokens(req), nil
+}
+
+// EstimateTokens estimates the number of tokens of the contents, the system
+// instruction and the tools of the request, assuming four characters per
+// token of their text and of the JSON encoding of their other parts.
+func EstimateTokens(req *LLMRequest) int {
+	chars := 0
+	for _, content := range req.Contents {
+		chars += contentChars(content)
+	}
+	if req.Config != nil {
+		chars += contentChars(req.Config.SystemInstruction)
+		for _, tool := range req.Config.Tools {
+			chars += jsonChars(tool)
+		}
+	}
+	return (chars + charsPerToken - 1) / charsPerToken
+}
+
+func contentChars(content *genai.Content) int {
+	if content == nil {
+		return 0
+	}
+	chars := 0
+	for _, part := range content.Parts {
+		switch {
+		case part == nil:
+		case part.Text != "":
+			chars += len(part.Text)
+		default:
+			chars += jsonChars(part)
+		}
+	}
+	return chars
+}
+
+func jsonChars(v any) int {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return 0
+	}
+	return len(data)
+}
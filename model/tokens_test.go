@@ -0,0 +1,108 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+)
+
+// countingModel counts 42 tokens for every request.
+type countingModel struct {
+	fakeModel
+}
+
+func (m *countingModel) CountTokens(ctx context.Context, req *model.LLMRequest) (int, error) {
+	return 42, nil
+}
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		name string
+		req  *model.LLMRequest
+		want int
+	}{
+		{
+			name: "empty",
+			req:  &model.LLMRequest{},
+			want: 0,
+		},
+		{
+			name: "text",
+			req:  &model.LLMRequest{Contents: []*genai.Content{genai.NewContentFromText("12345678", genai.RoleUser)}},
+			want: 2,
+		},
+		{
+			name: "rounded_up",
+			req:  &model.LLMRequest{Contents: []*genai.Content{genai.NewContentFromText("123456789", genai.RoleUser)}},
+			want: 3,
+		},
+		{
+			name: "system_instruction",
+			req: &model.LLMRequest{
+				Contents: []*genai.Content{genai.NewContentFromText("1234", genai.RoleUser)},
+				Config:   &genai.GenerateContentConfig{SystemInstruction: genai.NewContentFromText("1234", genai.RoleUser)},
+			},
+			want: 2,
+		},
+		{
+			name: "function_call",
+			req: &model.LLMRequest{Contents: []*genai.Content{{Role: genai.RoleModel, Parts: []*genai.Part{
+				genai.NewPartFromFunctionCall("f", nil),
+			}}}},
+			// {"functionCall":{"name":"f"}} is 29 characters.
+			want: 8,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := model.EstimateTokens(tt.req); got != tt.want {
+				t.Errorf("EstimateTokens() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCountTokens(t *testing.T) {
+	req := &model.LLMRequest{Contents: []*genai.Content{genai.NewContentFromText("12345678", genai.RoleUser)}}
+	counter := &countingModel{fakeModel{name: "counter"}}
+	tests := []struct {
+		name string
+		llm  model.LLM
+		want int
+	}{
+		{name: "estimated", llm: &fakeModel{name: "a"}, want: 2},
+		{name: "counted", llm: counter, want: 42},
+		{name: "retry", llm: model.WithRetry(counter, model.RetryPolicy{}), want: 42},
+		{name: "fallback", llm: model.NewFallback(counter, &fakeModel{name: "a"}), want: 42},
+		{name: "cache", llm: model.WithCache(counter, model.NewLRUCache(1024), model.CacheConfig{}), want: 42},
+		{name: "interceptor", llm: model.WithInterceptor(counter, model.Interceptor{}), want: 42},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := model.CountTokens(t.Context(), tt.llm, req)
+			if err != nil {
+				t.Fatalf("CountTokens() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CountTokens() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
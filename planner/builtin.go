@@ -0,0 +1,68 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/model"
+)
+
+// BuiltInPlanner is a [Planner] relying on the native thinking of the
+// models: it applies its ThinkingConfig to the model requests, and neither
+// instructs the model nor rewrites its responses. The thoughts of the model
+// are the parts of the event contents with their Thought field set, like the
+// planning of the [PlanReActPlanner].
+//
+// The LLM agents fail to be created with a BuiltInPlanner if their model
+// doesn't support thinking, see [model.SupportsThinking], unless the
+// planner is Lenient.
+type BuiltInPlanner struct {
+	// ThinkingConfig is the thinking config of the model requests, e.g. the
+	// budget of thinking tokens and whether the thoughts are returned. It
+	// overrides the ThinkingConfig of the GenerateContentConfig of the
+	// agent, which can't be set together with it.
+	ThinkingConfig *genai.ThinkingConfig
+	// Lenient makes the agents with a model which doesn't support thinking
+	// log a warning and ignore the planner, rather than fail to be created.
+	Lenient bool
+}
+
+// PlanningInstruction implements Planner. There is no planning instruction.
+func (*BuiltInPlanner) PlanningInstruction(agent.ReadonlyContext, *model.LLMRequest) string {
+	return ""
+}
+
+// ProcessPlanningResponse implements Planner. The responses are kept
+// unchanged, the models already mark their thoughts.
+func (*BuiltInPlanner) ProcessPlanningResponse(agent.ReadonlyContext, []*genai.Part) []*genai.Part {
+	return nil
+}
+
+// ApplyThinkingConfig sets the ThinkingConfig of the request to a copy of
+// the one of the planner.
+func (p *BuiltInPlanner) ApplyThinkingConfig(req *model.LLMRequest) {
+	if p.ThinkingConfig == nil {
+		return
+	}
+	if req.Config == nil {
+		req.Config = &genai.GenerateContentConfig{}
+	}
+	thinkingConfig := *p.ThinkingConfig
+	req.Config.ThinkingConfig = &thinkingConfig
+}
+
+var _ Planner = (*BuiltInPlanner)(nil)
@@ -0,0 +1,56 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/planner"
+)
+
+func TestBuiltInPlanner_ApplyThinkingConfig(t *testing.T) {
+	thinkingConfig := &genai.ThinkingConfig{ThinkingBudget: genai.Ptr[int32](1024), IncludeThoughts: true}
+	p := &planner.BuiltInPlanner{ThinkingConfig: thinkingConfig}
+	req := &model.LLMRequest{Config: &genai.GenerateContentConfig{
+		Temperature:    genai.Ptr[float32](0.5),
+		ThinkingConfig: &genai.ThinkingConfig{ThinkingBudget: genai.Ptr[int32](0)},
+	}}
+	p.ApplyThinkingConfig(req)
+	want := &genai.GenerateContentConfig{Temperature: genai.Ptr[float32](0.5), ThinkingConfig: thinkingConfig}
+	if diff := cmp.Diff(want, req.Config); diff != "" {
+		t.Errorf("Config mismatch (-want +got):\n%s", diff)
+	}
+	if req.Config.ThinkingConfig == thinkingConfig {
+		t.Errorf("ThinkingConfig of the request is the one of the planner, want a copy")
+	}
+
+	req = &model.LLMRequest{}
+	p.ApplyThinkingConfig(req)
+	if diff := cmp.Diff(&genai.GenerateContentConfig{ThinkingConfig: thinkingConfig}, req.Config); diff != "" {
+		t.Errorf("Config mismatch (-want +got):\n%s", diff)
+	}
+
+	if got := p.PlanningInstruction(nil, req); got != "" {
+		t.Errorf("PlanningInstruction() = %q, want empty", got)
+	}
+	parts := []*genai.Part{{Text: "thinking", Thought: true}, genai.NewPartFromText("answer")}
+	if got := p.ProcessPlanningResponse(nil, parts); got != nil {
+		t.Errorf("ProcessPlanningResponse() = %v, want nil", got)
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package planner defines the interface of the planners, which make the LLM
+// agents plan before acting, and provides the [PlanReActPlanner].
+package planner
+
+import (
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/model"
+)
+
+// Planner guides the model of an LLM agent to plan its actions, and
+// processes the responses of the model to separate the planning from the
+// answer.
+type Planner interface {
+	// PlanningInstruction returns the instruction appended to the system
+	// instruction of the model requests, empty if there is none.
+	PlanningInstruction(ctx agent.ReadonlyContext, req *model.LLMRequest) string
+	// ProcessPlanningResponse returns the parts of the complete model
+	// responses to keep in their events, e.g. with the planning parts marked
+	// as thoughts, or nil to keep the parts unchanged. The parts can be
+	// modified in place.
+	ProcessPlanningResponse(ctx agent.ReadonlyContext, parts []*genai.Part) []*genai.Part
+}
@@ -0,0 +1,155 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"regexp"
+	"strings"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/model"
+)
+
+// The markers of the sections of the responses of the PlanReActPlanner.
+const (
+	PlanningTag    = "/*PLANNING*/"
+	ReplanningTag  = "/*REPLANNING*/"
+	ReasoningTag   = "/*REASONING*/"
+	ActionTag      = "/*ACTION*/"
+	FinalAnswerTag = "/*FINAL_ANSWER*/"
+)
+
+// planReActInstruction is the planning instruction of the PlanReActPlanner.
+const planReActInstruction = `When answering the question, try to leverage the available tools to gather the information instead of your memorized knowledge.
+
+Follow this process when answering the question: (1) first come up with a plan in natural language text format; (2) then use tools to execute the plan and provide reasoning between tool code snippets to make a summary of current state and next step. Tool code snippets and reasoning should be interleaved with each other. (3) In the end, return one final answer.
+
+Follow this format when answering the question: (1) The planning part should be under ` + PlanningTag + `. (2) The tool code snippets should be under ` + ActionTag + `, and the reasoning parts should be under ` + ReasoningTag + `. (3) The final answer part should be under ` + FinalAnswerTag + `.
+
+Below are the requirements for the planning:
+The plan is made to answer the user query if following the plan. The plan is coherent and covers all aspects of information from user query, and only involves the tools that are accessible by the agent. The plan contains the decomposed steps as a numbered list where each step should use one or multiple available tools. By reading the plan, you can intuitively know which tools to trigger or what actions to take.
+If the initial plan cannot be successfully executed, e.g. a tool call fails or returns an error or an unexpected result, you should learn from the previous execution results and revise your plan under ` + ReplanningTag + `. Then use tools to follow the new plan.
+
+Below are the requirements for the reasoning:
+The reasoning makes a summary of the current trajectory based on the user query and tool outputs. Based on the tool outputs and plan, the reasoning also comes up with instructions to the next steps, making the trajectory closer to the final answer.
+
+Below are the requirements for the final answer:
+The final answer should be precise and follow the query formatting requirements. Some queries may not be answerable with the available tools and information. In those cases, inform the user why you cannot process their query and ask for more information.
+
+Below are the requirements for the tool code:
+Only use the tools and functions which are available to you, with the arguments they declare. Call the tools rather than writing code which calls them. Don't call tools which are not available.
+
+Always start your answer with ` + PlanningTag + `, and write the answer to the user only under ` + FinalAnswerTag + `.`
+
+// tagPattern matches the markers of the sections, tolerating the spaces,
+// case and separators of the markers the models mangle, e.g.
+// "/* Final Answer */".
+var tagPattern = regexp.MustCompile(`(?i)/\*\s*(PLANNING|REPLANNING|REASONING|ACTION|FINAL[\s_-]*ANSWER)\s*\*/`)
+
+// PlanReActPlanner is a [Planner] making the model write a plan, reason and
+// act with tools, and re-plan when the execution of the plan fails, before
+// it answers, in sections marked with the PlanningTag, ReasoningTag,
+// ActionTag, ReplanningTag and FinalAnswerTag.
+//
+// The responses of the model are processed so that the sections before the
+// final answer are marked as thoughts, and only the final answer is the text
+// of the responses. The responses without markers are kept unchanged, and
+// when the model omits the final answer marker in a response without
+// function calls, its last section is the answer.
+//
+// Unlike the thinking of the models, see llmagent.Config.ThinkingConfig, the
+// planning doesn't need a model which supports it.
+type PlanReActPlanner struct{}
+
+// PlanningInstruction implements Planner.
+func (*PlanReActPlanner) PlanningInstruction(agent.ReadonlyContext, *model.LLMRequest) string {
+	return planReActInstruction
+}
+
+// ProcessPlanningResponse implements Planner. The text parts are split in
+// sections at their markers. The function calls of the first group of calls
+// are kept, and the parts after it are dropped: the model continues after
+// the responses of the calls.
+func (*PlanReActPlanner) ProcessPlanningResponse(_ agent.ReadonlyContext, parts []*genai.Part) []*genai.Part {
+	if len(parts) == 0 {
+		return nil
+	}
+	var result []*genai.Part
+	hasAnswer, hasCalls := false, false
+	// last is the index in result of the last section before a final
+	// answer, and lastText its text without its marker.
+	last, lastText := -1, ""
+	for i := 0; i < len(parts); i++ {
+		part := parts[i]
+		if part.FunctionCall != nil {
+			for ; i < len(parts) && parts[i].FunctionCall != nil; i++ {
+				// The calls without names can't be run.
+				if parts[i].FunctionCall.Name != "" {
+					result = append(result, parts[i])
+					hasCalls = true
+				}
+			}
+			if hasCalls {
+				break
+			}
+			i--
+			continue
+		}
+		if part.Text == "" || part.Thought {
+			result = append(result, part)
+			continue
+		}
+		locs := tagPattern.FindAllStringSubmatchIndex(part.Text, -1)
+		if len(locs) == 0 {
+			result = append(result, part)
+			continue
+		}
+		if preamble := part.Text[:locs[0][0]]; strings.TrimSpace(preamble) != "" {
+			result = append(result, thought(preamble))
+		}
+		for j, loc := range locs {
+			end := len(part.Text)
+			if j+1 < len(locs) {
+				end = locs[j+1][0]
+			}
+			body := strings.TrimSpace(part.Text[loc[1]:end])
+			if isFinalAnswer(part.Text[loc[2]:loc[3]]) {
+				if body != "" {
+					result = append(result, genai.NewPartFromText(body))
+					hasAnswer = true
+				}
+				continue
+			}
+			result = append(result, thought(part.Text[loc[0]:end]))
+			last, lastText = len(result)-1, body
+		}
+	}
+	if !hasAnswer && !hasCalls && last >= 0 && lastText != "" {
+		result[last] = genai.NewPartFromText(lastText)
+	}
+	return result
+}
+
+func isFinalAnswer(tag string) bool {
+	return strings.HasPrefix(strings.ToUpper(tag), "FINAL")
+}
+
+func thought(text string) *genai.Part {
+	return &genai.Part{Text: text, Thought: true}
+}
+
+var _ Planner = (*PlanReActPlanner)(nil)
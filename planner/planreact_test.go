@@ -0,0 +1,174 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/planner"
+)
+
+func thought(text string) *genai.Part {
+	return &genai.Part{Text: text, Thought: true}
+}
+
+func TestPlanReActPlanner_ProcessPlanningResponse(t *testing.T) {
+	call := func(name string) *genai.Part {
+		return genai.NewPartFromFunctionCall(name, map[string]any{})
+	}
+	tests := []struct {
+		name  string
+		parts []*genai.Part
+		want  []*genai.Part
+	}{
+		{
+			name:  "empty",
+			parts: nil,
+			want:  nil,
+		},
+		{
+			name: "planning and final answer",
+			parts: []*genai.Part{
+				genai.NewPartFromText("/*PLANNING*/\n1. Look up the weather.\n/*REASONING*/\nIt is sunny.\n/*FINAL_ANSWER*/\nIt's sunny in Paris."),
+			},
+			want: []*genai.Part{
+				thought("/*PLANNING*/\n1. Look up the weather.\n"),
+				thought("/*REASONING*/\nIt is sunny.\n"),
+				genai.NewPartFromText("It's sunny in Paris."),
+			},
+		},
+		{
+			name: "sections in separate parts",
+			parts: []*genai.Part{
+				genai.NewPartFromText("/*PLANNING*/ 1. Answer."),
+				genai.NewPartFromText("/*FINAL_ANSWER*/ 42"),
+			},
+			want: []*genai.Part{
+				thought("/*PLANNING*/ 1. Answer."),
+				genai.NewPartFromText("42"),
+			},
+		},
+		{
+			name: "action with function calls",
+			parts: []*genai.Part{
+				genai.NewPartFromText("/*PLANNING*/\n1. Get the weather.\n/*ACTION*/\n"),
+				call("get_weather"),
+				call(""),
+				call("get_time"),
+				genai.NewPartFromText("/*FINAL_ANSWER*/ made up"),
+				call("ignored"),
+			},
+			want: []*genai.Part{
+				thought("/*PLANNING*/\n1. Get the weather.\n"),
+				thought("/*ACTION*/\n"),
+				call("get_weather"),
+				call("get_time"),
+			},
+		},
+		{
+			name: "replanning",
+			parts: []*genai.Part{
+				genai.NewPartFromText("/*REPLANNING*/\nThe tool failed, retry with the city code.\n/*ACTION*/"),
+				call("get_weather"),
+			},
+			want: []*genai.Part{
+				thought("/*REPLANNING*/\nThe tool failed, retry with the city code.\n"),
+				thought("/*ACTION*/"),
+				call("get_weather"),
+			},
+		},
+		{
+			name: "mangled markers",
+			parts: []*genai.Part{
+				genai.NewPartFromText("Sure! /* planning */ 1. Answer.\n/* Final Answer */ Paris."),
+			},
+			want: []*genai.Part{
+				thought("Sure! "),
+				thought("/* planning */ 1. Answer.\n"),
+				genai.NewPartFromText("Paris."),
+			},
+		},
+		{
+			name: "final-answer spelling",
+			parts: []*genai.Part{
+				genai.NewPartFromText("/*PLANNING*/ plan /*FINAL-ANSWER*/ Paris."),
+			},
+			want: []*genai.Part{
+				thought("/*PLANNING*/ plan "),
+				genai.NewPartFromText("Paris."),
+			},
+		},
+		{
+			name: "no markers",
+			parts: []*genai.Part{
+				genai.NewPartFromText("Paris is the capital of France."),
+			},
+			want: []*genai.Part{
+				genai.NewPartFromText("Paris is the capital of France."),
+			},
+		},
+		{
+			name: "omitted final answer marker",
+			parts: []*genai.Part{
+				genai.NewPartFromText("/*PLANNING*/\n1. Answer directly.\n/*REASONING*/\nParis is the capital of France."),
+			},
+			want: []*genai.Part{
+				thought("/*PLANNING*/\n1. Answer directly.\n"),
+				genai.NewPartFromText("Paris is the capital of France."),
+			},
+		},
+		{
+			name: "empty final answer",
+			parts: []*genai.Part{
+				genai.NewPartFromText("/*PLANNING*/ plan /*FINAL_ANSWER*/"),
+			},
+			want: []*genai.Part{
+				genai.NewPartFromText("plan"),
+			},
+		},
+		{
+			name: "native thoughts",
+			parts: []*genai.Part{
+				thought("/*PLANNING*/ thinking"),
+				genai.NewPartFromText("/*FINAL_ANSWER*/ Paris."),
+			},
+			want: []*genai.Part{
+				thought("/*PLANNING*/ thinking"),
+				genai.NewPartFromText("Paris."),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := (&planner.PlanReActPlanner{}).ProcessPlanningResponse(nil, tt.parts)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("ProcessPlanningResponse() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPlanReActPlanner_PlanningInstruction(t *testing.T) {
+	instruction := (&planner.PlanReActPlanner{}).PlanningInstruction(nil, nil)
+	for _, tag := range []string{planner.PlanningTag, planner.ReplanningTag, planner.ReasoningTag, planner.ActionTag, planner.FinalAnswerTag} {
+		if !strings.Contains(instruction, tag) {
+			t.Errorf("PlanningInstruction() doesn't mention %s", tag)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package auditplugin provides a plugin writing an audit log of the model
+// requests and the tool calls of the agents.
+package auditplugin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/plugin"
+	"google.golang.org/adk/tool"
+)
+
+// The kinds of the records.
+const (
+	KindModelRequest = "model_request"
+	KindToolCall     = "tool_call"
+)
+
+// Record is a record of the audit log, written as a line of JSON.
+type Record struct {
+	Time         time.Time `json:"time"`
+	InvocationID string    `json:"invocationId"`
+	// Seq is the number of the record in the invocation, from 1.
+	Seq   int    `json:"seq"`
+	Agent string `json:"agent"`
+	// Kind is KindModelRequest or KindToolCall.
+	Kind string `json:"kind"`
+
+	// Model is the model of a model request, and Digest the SHA-256 digest,
+	// in hex, of its contents and configuration.
+	Model  string `json:"model,omitempty"`
+	Digest string `json:"digest,omitempty"`
+
+	// Tool is the tool of a tool call, with the ID and the arguments of the
+	// function call.
+	Tool           string         `json:"tool,omitempty"`
+	FunctionCallID string         `json:"functionCallId,omitempty"`
+	Args           map[string]any `json:"args,omitempty"`
+}
+
+// Config defines the configuration of the audit plugin.
+type Config struct {
+	// Name is the name of the plugin.
+	// Optional: the default is "audit_plugin".
+	Name string
+	// Writer is where the records are written.
+	Writer io.Writer
+}
+
+// New creates an instance of the audit plugin.
+//
+// The plugin writes a record of each model request and each tool call of the
+// agents to the Writer, before they are made. The records of an invocation
+// are numbered, see [Record]. A model request or a tool call whose record
+// can't be written fails, so that none escapes the log.
+//
+// The records of a model request only hold the digest of the request: the
+// contents may be sensitive, while the digest proves which request was made
+// given the session.
+func New(cfg Config) (*plugin.Plugin, error) {
+	if cfg.Writer == nil {
+		return nil, fmt.Errorf("writer is required")
+	}
+	name := cfg.Name
+	if name == "" {
+		name = "audit_plugin"
+	}
+	p := &auditPlugin{name: name, w: cfg.Writer}
+	return plugin.New(plugin.Config{
+		Name:                name,
+		BeforeModelCallback: p.beforeModel,
+		BeforeToolCallback:  p.beforeTool,
+	})
+}
+
+type auditPlugin struct {
+	name string
+
+	mu sync.Mutex // guards w
+	w  io.Writer
+}
+
+func (p *auditPlugin) beforeModel(ctx agent.CallbackContext, req *model.LLMRequest) (*model.LLMResponse, error) {
+	digest, err := digest(req)
+	if err != nil {
+		return nil, err
+	}
+	return nil, p.write(ctx, Record{
+		Kind:   KindModelRequest,
+		Model:  req.Model,
+		Digest: digest,
+	})
+}
+
+func (p *auditPlugin) beforeTool(ctx tool.Context, t tool.Tool, args map[string]any) (map[string]any, error) {
+	return nil, p.write(ctx, Record{
+		Kind:           KindToolCall,
+		Tool:           t.Name(),
+		FunctionCallID: ctx.FunctionCallID(),
+		Args:           args,
+	})
+}
+
+// write completes the record with the context and writes it.
+func (p *auditPlugin) write(ctx agent.CallbackContext, record Record) error {
+	record.Time = time.Now()
+	record.InvocationID = ctx.InvocationID()
+	record.Agent = ctx.AgentName()
+	record.Seq = p.next(ctx)
+
+	b, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit record: %w", err)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, err := p.w.Write(append(b, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit record: %w", err)
+	}
+	return nil
+}
+
+// next returns the number of the next record of the invocation, counted in
+// the scratch space of the invocation.
+func (p *auditPlugin) next(ctx agent.CallbackContext) int {
+	scratch := plugin.ScratchFromContext(ctx)
+	if scratch == nil {
+		return 0
+	}
+	var seq int
+	scratch.Update(p.name+":seq", func(value any, _ bool) any {
+		n, _ := value.(int)
+		seq = n + 1
+		return seq
+	})
+	return seq
+}
+
+// digest returns the hex SHA-256 digest of the contents and the
+// configuration of the request.
+func digest(req *model.LLMRequest) (string, error) {
+	b, err := json.Marshal(struct {
+		Model    string `json:"model"`
+		Contents any    `json:"contents"`
+		Config   any    `json:"config"`
+	}{req.Model, req.Contents, req.Config})
+	if err != nil {
+		return "", fmt.Errorf("failed to digest model request: %w", err)
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,143 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auditplugin_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/internal/testutil"
+	"google.golang.org/adk/plugin"
+	"google.golang.org/adk/plugin/auditplugin"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+func TestAuditPlugin(t *testing.T) {
+	var log bytes.Buffer
+	audit, err := auditplugin.New(auditplugin.Config{Writer: &log})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	run(t, audit)
+
+	var got []auditplugin.Record
+	scanner := bufio.NewScanner(&log)
+	for scanner.Scan() {
+		var record auditplugin.Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("invalid record %q: %v", scanner.Text(), err)
+		}
+		if record.Time.IsZero() || record.InvocationID == "" {
+			t.Errorf("record %q has no time or invocation ID", scanner.Text())
+		}
+		got = append(got, record)
+	}
+	want := []auditplugin.Record{
+		{Seq: 1, Agent: "assistant", Kind: auditplugin.KindModelRequest, Model: "mock"},
+		{Seq: 2, Agent: "assistant", Kind: auditplugin.KindToolCall, Tool: "lookup", FunctionCallID: "call_1", Args: map[string]any{"query": "weather"}},
+		{Seq: 3, Agent: "assistant", Kind: auditplugin.KindModelRequest, Model: "mock"},
+	}
+	opts := cmp.Options{cmpopts.IgnoreFields(auditplugin.Record{}, "Time", "InvocationID", "Digest")}
+	if diff := cmp.Diff(want, got, opts); diff != "" {
+		t.Errorf("records mismatch (-want +got):\n%s", diff)
+	}
+	if len(got) == len(want) && (got[0].Digest == "" || got[0].Digest == got[2].Digest) {
+		t.Errorf("model request digests = %q and %q, want different ones", got[0].Digest, got[2].Digest)
+	}
+}
+
+func TestAuditPlugin_WriteError(t *testing.T) {
+	audit, err := auditplugin.New(auditplugin.Config{Writer: failingWriter{}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	a, r := newRunner(t, audit)
+	for _, err := range r.Run(t.Context(), "user", "session", genai.NewContentFromText("hi", genai.RoleUser), agent.RunConfig{}) {
+		if err != nil {
+			return
+		}
+	}
+	t.Errorf("Run() of agent %q succeeded, want the error of the audit log", a.Name())
+}
+
+func TestNew_Errors(t *testing.T) {
+	if _, err := auditplugin.New(auditplugin.Config{}); err == nil {
+		t.Error("New() without a writer succeeded, want an error")
+	}
+}
+
+// run runs the agent with the plugin.
+func run(t *testing.T, p *plugin.Plugin) {
+	t.Helper()
+	_, r := newRunner(t, p)
+	for _, err := range r.Run(t.Context(), "user", "session", genai.NewContentFromText("what's the weather?", genai.RoleUser), agent.RunConfig{}) {
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+	}
+}
+
+// newRunner returns an agent looking something up before answering, and a
+// runner of the agent with the plugin.
+func newRunner(t *testing.T, p *plugin.Plugin) (agent.Agent, *runner.Runner) {
+	t.Helper()
+	type args struct {
+		Query string `json:"query"`
+	}
+	lookup, err := functiontool.New(functiontool.Config{Name: "lookup", Description: "looks something up"}, func(tool.Context, args) (string, error) {
+		return "sunny", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	llm := &testutil.MockModel{Responses: []*genai.Content{
+		{Role: genai.RoleModel, Parts: []*genai.Part{{FunctionCall: &genai.FunctionCall{ID: "call_1", Name: "lookup", Args: map[string]any{"query": "weather"}}}}},
+		genai.NewContentFromText("It's sunny.", genai.RoleModel),
+	}}
+	a, err := llmagent.New(llmagent.Config{Name: "assistant", Model: llm, Tools: []tool.Tool{lookup}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessionService := session.InMemoryService()
+	if _, err := sessionService.Create(t.Context(), &session.CreateRequest{AppName: "app", UserID: "user", SessionID: "session"}); err != nil {
+		t.Fatal(err)
+	}
+	r, err := runner.New(runner.Config{
+		AppName:        "app",
+		Agent:          a,
+		SessionService: sessionService,
+		PluginConfig:   runner.PluginConfig{Plugins: []*plugin.Plugin{p}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a, r
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
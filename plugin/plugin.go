@@ -12,7 +12,13 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Package plugin provides.
+// Package plugin provides the plugins of a runner, whose callbacks run for
+// all the agents of the tree, e.g. for billing, audit or guardrails.
+//
+// The plugins' callbacks run before the callbacks of the agents, with the
+// same semantics: a before callback returning a value or an error skips the
+// step and the next callbacks. The plugins of an invocation share a scratch
+// space, see [ScratchFromContext].
 package plugin
 
 import (
@@ -0,0 +1,80 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"context"
+	"sync"
+
+	"google.golang.org/adk/internal/plugininternal/plugincontext"
+)
+
+// Scratch is the space the plugins share during an invocation, e.g. to carry
+// a value from a before callback to the matching after callback. It starts
+// empty with each invocation and isn't stored in the session. The plugins
+// should prefix their keys with their name, as all the plugins of the runner
+// share it.
+//
+// It is safe for concurrent use, as the parallel agents run the callbacks of
+// their sub-agents concurrently.
+type Scratch struct {
+	mu     sync.Mutex
+	values map[string]any
+}
+
+// Get returns the value of the key, if any.
+func (s *Scratch) Get(key string) (any, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, ok := s.values[key]
+	return value, ok
+}
+
+// Set sets the value of the key.
+func (s *Scratch) Set(key string, value any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.values == nil {
+		s.values = make(map[string]any)
+	}
+	s.values[key] = value
+}
+
+// Update sets the value of the key to the result of f, called with the
+// current value, if any, without another update in between.
+func (s *Scratch) Update(key string, f func(value any, ok bool) any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.values == nil {
+		s.values = make(map[string]any)
+	}
+	value, ok := s.values[key]
+	s.values[key] = f(value, ok)
+}
+
+// Delete removes the key.
+func (s *Scratch) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.values, key)
+}
+
+// ScratchFromContext returns the scratch space of the invocation of the
+// context, which is any of the contexts given to the callbacks of the
+// plugins. It returns nil outside of the invocations run by a runner.
+func ScratchFromContext(ctx context.Context) *Scratch {
+	s, _ := ctx.Value(plugincontext.ScratchCtxKey).(*Scratch)
+	return s
+}
@@ -0,0 +1,48 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"context"
+	"testing"
+)
+
+func TestScratch(t *testing.T) {
+	if s := ScratchFromContext(context.Background()); s != nil {
+		t.Errorf("ScratchFromContext() = %v outside of an invocation, want nil", s)
+	}
+
+	var s Scratch
+	if _, ok := s.Get("key"); ok {
+		t.Error("Get() of a new scratch found the key")
+	}
+	s.Set("key", "value")
+	if got, ok := s.Get("key"); !ok || got != "value" {
+		t.Errorf("Get() = %v, %v, want value, true", got, ok)
+	}
+	for range 2 {
+		s.Update("count", func(value any, _ bool) any {
+			n, _ := value.(int)
+			return n + 1
+		})
+	}
+	if got, _ := s.Get("count"); got != 2 {
+		t.Errorf("Get() after two updates = %v, want 2", got)
+	}
+	s.Delete("key")
+	if _, ok := s.Get("key"); ok {
+		t.Error("Get() found the deleted key")
+	}
+}
@@ -17,9 +17,13 @@ package runner
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"iter"
 	"log"
+	"maps"
+	"math/rand/v2"
+	"reflect"
 	"time"
 
 	"google.golang.org/genai"
@@ -29,16 +33,20 @@ import (
 	"google.golang.org/adk/internal/agent/parentmap"
 	"google.golang.org/adk/internal/agent/runconfig"
 	artifactinternal "google.golang.org/adk/internal/artifact"
+	"google.golang.org/adk/internal/checkpoint"
 	icontext "google.golang.org/adk/internal/context"
 	"google.golang.org/adk/internal/llminternal"
 	imemory "google.golang.org/adk/internal/memory"
 	"google.golang.org/adk/internal/plugininternal"
 	"google.golang.org/adk/internal/sessioninternal"
+	"google.golang.org/adk/internal/toolinternal"
 	"google.golang.org/adk/internal/utils"
 	"google.golang.org/adk/memory"
+	"google.golang.org/adk/memory/ingest"
 	"google.golang.org/adk/model"
 	"google.golang.org/adk/plugin"
 	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
 )
 
 // Config is used to create a [Runner].
@@ -52,6 +60,9 @@ type Config struct {
 	ArtifactService artifact.Service
 	// optional
 	MemoryService memory.Service
+	// optional: the sessions are enqueued for ingestion into memory at the
+	// end of their invocations.
+	MemoryIngester *ingest.Ingester
 	// optional
 	PluginConfig PluginConfig
 }
@@ -75,6 +86,7 @@ func New(cfg Config) (*Runner, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create agent tree: %w", err)
 	}
+	warnSubAgentGlobalInstructions(cfg.Agent)
 
 	pluginManager, err := plugininternal.NewPluginManager(plugininternal.PluginConfig{
 		Plugins:      cfg.PluginConfig.Plugins,
@@ -90,6 +102,7 @@ func New(cfg Config) (*Runner, error) {
 		sessionService:  cfg.SessionService,
 		artifactService: cfg.ArtifactService,
 		memoryService:   cfg.MemoryService,
+		memoryIngester:  cfg.MemoryIngester,
 		parents:         parents,
 		pluginManager:   pluginManager,
 	}, nil
@@ -104,11 +117,50 @@ type Runner struct {
 	sessionService  session.Service
 	artifactService artifact.Service
 	memoryService   memory.Service
+	memoryIngester  *ingest.Ingester
 
 	parents       parentmap.Map
 	pluginManager *plugininternal.PluginManager
 }
 
+// Close releases the resources of the runner: it closes the toolsets of the
+// LLM agents of its agent tree which hold resources, see
+// [tool.CloseToolset], e.g. the connections of the MCP toolsets, and its
+// plugins. The runner can't run its agents afterwards.
+//
+// The runners created for each request of a server share the agents of the
+// server, whose toolsets must only be closed when it shuts down.
+func (r *Runner) Close(ctx context.Context) error {
+	var errs []error
+	closed := make(map[tool.Toolset]bool)
+	var closeAgent func(a agent.Agent)
+	closeAgent = func(a agent.Agent) {
+		if llmAgent, ok := a.(llminternal.Agent); ok {
+			for _, ts := range llminternal.Reveal(llmAgent).Toolsets {
+				// The toolsets shared by several agents are closed once.
+				comparable := reflect.TypeOf(ts).Comparable()
+				if comparable && closed[ts] {
+					continue
+				}
+				if comparable {
+					closed[ts] = true
+				}
+				if err := tool.CloseToolset(ctx, ts); err != nil {
+					errs = append(errs, fmt.Errorf("failed to close toolset %q of agent %q: %w", ts.Name(), a.Name(), err))
+				}
+			}
+		}
+		for _, sub := range a.SubAgents() {
+			closeAgent(sub)
+		}
+	}
+	closeAgent(r.rootAgent)
+	if err := r.pluginManager.Close(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
 // Run runs the agent for the given user input, yielding events from agents.
 // For each user message it finds the proper agent within an agent tree to
 // continue the conversation within the session.
@@ -135,105 +187,318 @@ func (r *Runner) Run(ctx context.Context, userID, sessionID string, msg *genai.C
 			return
 		}
 
-		ctx = parentmap.ToContext(ctx, r.parents)
-		ctx = runconfig.ToContext(ctx, &runconfig.RunConfig{
-			StreamingMode: runconfig.StreamingMode(cfg.StreamingMode),
+		r.run(ctx, storedSession, invocation{agent: agentToRun, msg: msg, cfg: cfg}, yield)
+	}
+}
+
+// ErrLiveUnsupported is the error of [Runner.RunLive] for the root agents
+// which don't run live, see [Runner.SupportsLive].
+var ErrLiveUnsupported = errors.New("agent doesn't support live runs")
+
+// SupportsLive reports whether the root agent runs live: it is an LLM agent
+// whose model supports live sessions, see [model.SupportsLive].
+func (r *Runner) SupportsLive() bool {
+	return llminternal.SupportsLive(r.rootAgent)
+}
+
+// RunLive runs the root agent in a live session of its model, in a single
+// invocation which lasts until the queue is closed or ctx is done. The
+// requests of the queue, turns of the user and chunks of realtime media such
+// as audio, are sent to the model as they come, and the events of the
+// responses of the model are yielded as they come: the chunks of its turns
+// as partial events, and the end of each turn as a final one.
+//
+// The turns of the user are saved in the session as user events, the
+// realtime media isn't. The live run stays with the root agent: the
+// transfers to other agents aren't supported. It fails with
+// [ErrLiveUnsupported] if the root agent doesn't run live.
+func (r *Runner) RunLive(ctx context.Context, userID, sessionID string, queue *agent.LiveRequestQueue, cfg agent.RunConfig) iter.Seq2[*session.Event, error] {
+	return func(yield func(*session.Event, error) bool) {
+		if !r.SupportsLive() {
+			yield(nil, fmt.Errorf("%w: %q", ErrLiveUnsupported, r.rootAgent.Name()))
+			return
+		}
+		resp, err := r.sessionService.Get(ctx, &session.GetRequest{
+			AppName:   r.appName,
+			UserID:    userID,
+			SessionID: sessionID,
 		})
-		ctx = plugininternal.ToContext(ctx, r.pluginManager)
-
-		var artifacts agent.Artifacts
-		if r.artifactService != nil {
-			artifacts = &artifactinternal.Artifacts{
-				Service:   r.artifactService,
-				SessionID: storedSession.ID(),
-				AppName:   storedSession.AppName(),
-				UserID:    storedSession.UserID(),
-			}
+		if err != nil {
+			yield(nil, err)
+			return
 		}
+		cfg.StreamingMode = agent.StreamingModeBidi
+		r.run(ctx, resp.Session, invocation{agent: r.rootAgent, cfg: cfg, live: queue}, yield)
+	}
+}
 
-		var memoryImpl agent.Memory = nil
-		if r.memoryService != nil {
-			memoryImpl = &imemory.Memory{
-				Service:   r.memoryService,
-				SessionID: storedSession.ID(),
-				UserID:    storedSession.UserID(),
-				AppName:   storedSession.AppName(),
-			}
-		}
+// ErrNotResumable is the error of [Runner.Resume] for the invocations which
+// can't be resumed: those run without checkpointing, and those which ended.
+var ErrNotResumable = errors.New("invocation is not resumable")
 
-		ctx := icontext.NewInvocationContext(ctx, icontext.InvocationContextParams{
-			Artifacts:   artifacts,
-			Memory:      memoryImpl,
-			Session:     sessioninternal.NewMutableSession(r.sessionService, storedSession),
-			Agent:       agentToRun,
-			UserContent: msg,
-			RunConfig:   &cfg,
+// Resume resumes the invocation of the session with the ID, which was run
+// with checkpointing, see [agent.RunConfig], and interrupted before its end,
+// e.g. by the restart of the process. It yields the events of the rest of
+// the invocation, with the same invocation ID, as [Runner.Run].
+//
+// The agent of the invocation runs again, without a new user message: its
+// workflow agents start with the sub-agents which didn't end, and its LLM
+// agents first run the function calls of the model which didn't end. The
+// function calls whose response was recorded in the session don't run
+// again, so that their side effects aren't repeated.
+//
+// The resumed invocation records checkpoints too, whatever
+// cfg.Checkpointing.
+func (r *Runner) Resume(ctx context.Context, userID, sessionID, invocationID string, cfg agent.RunConfig) iter.Seq2[*session.Event, error] {
+	return func(yield func(*session.Event, error) bool) {
+		resp, err := r.sessionService.Get(ctx, &session.GetRequest{
+			AppName:   r.appName,
+			UserID:    userID,
+			SessionID: sessionID,
 		})
-		ctx, err = r.appendMessageToSession(ctx, storedSession, msg, cfg.SaveInputBlobsAsArtifacts, r.pluginManager)
 		if err != nil {
 			yield(nil, err)
 			return
 		}
 
-		pluginManager := r.pluginManager
-		if pluginManager != nil {
-			// Defer the after run callbacks to perform global cleanup tasks or finalizing logs and metrics data.
-			// This does NOT emit any event.
-			defer pluginManager.RunAfterRunCallback(ctx)
-
-			earlyExitResult, err := pluginManager.RunBeforeRunCallback(ctx)
-			if earlyExitResult != nil || err != nil {
-				earlyExitEvent := session.NewEvent(ctx.InvocationID())
-				earlyExitEvent.Author = "user"
-				earlyExitEvent.LLMResponse = model.LLMResponse{
-					Content: msg,
-				}
-				if err := r.sessionService.AppendEvent(ctx, storedSession, earlyExitEvent); err != nil {
-					yield(nil, fmt.Errorf("failed to add event to session: %w", err))
-					return
+		inv, err := r.resumedInvocation(resp.Session, invocationID)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		inv.cfg = cfg
+		inv.cfg.Checkpointing = true
+		r.run(ctx, resp.Session, inv, yield)
+	}
+}
+
+// invocation is an invocation to run.
+type invocation struct {
+	agent agent.Agent
+	// msg is the message of the user, appended to the session unless the
+	// invocation is resumed.
+	msg *genai.Content
+	cfg agent.RunConfig
+
+	// id is the ID of the resumed invocation, and resumption holds the
+	// checkpoints of its workflow agents.
+	id         string
+	resumption *checkpoint.Resumption
+
+	// live is the request queue of a live run.
+	live *agent.LiveRequestQueue
+}
+
+// resumedInvocation returns the invocation of the session with the ID to
+// resume from its checkpoints.
+//
+// The checkpoints of the agents which ended are dropped with the checkpoint
+// recording their end, so that they start over if they run again.
+func (r *Runner) resumedInvocation(storedSession session.Session, invocationID string) (invocation, error) {
+	inv := invocation{id: invocationID}
+	checkpoints := make(map[string]checkpoint.Checkpoint)
+	for event := range storedSession.Events().All() {
+		if event.InvocationID != invocationID {
+			continue
+		}
+		if event.Author == "user" && inv.msg == nil {
+			inv.msg = event.Content
+			continue
+		}
+		cp, ok := checkpoint.FromEvent(event)
+		if !ok {
+			continue
+		}
+		switch {
+		case cp.Complete:
+			return invocation{}, fmt.Errorf("%w: invocation %q already ended", ErrNotResumable, invocationID)
+		case cp.Agent != "":
+			if inv.agent = findAgent(r.rootAgent, cp.Agent); inv.agent == nil {
+				return invocation{}, fmt.Errorf("%w: invocation %q was run by unknown agent %q", ErrNotResumable, invocationID, cp.Agent)
+			}
+		default:
+			checkpoints[event.Author] = cp
+			var drop func(a agent.Agent)
+			drop = func(a agent.Agent) {
+				delete(checkpoints, a.Name())
+				for _, sub := range a.SubAgents() {
+					drop(sub)
 				}
-				yield(earlyExitEvent, err)
-				return
+			}
+			if ended := findAgent(r.rootAgent, cp.Ended); ended != nil {
+				drop(ended)
 			}
 		}
+	}
+	if inv.agent == nil {
+		return invocation{}, fmt.Errorf("%w: invocation %q has no checkpoint", ErrNotResumable, invocationID)
+	}
+	inv.resumption = checkpoint.NewResumption(checkpoints)
+	return inv, nil
+}
 
-		for event, err := range agentToRun.Run(ctx) {
-			if err != nil {
-				if !yield(event, err) {
-					return
-				}
-				continue
+// run runs the invocation in the session.
+func (r *Runner) run(ctx context.Context, storedSession session.Session, inv invocation, yield func(*session.Event, error) bool) {
+	agentToRun, msg, cfg := inv.agent, inv.msg, inv.cfg
+
+	ctx = parentmap.ToContext(ctx, r.parents)
+	ctx = runconfig.ToContext(ctx, &runconfig.RunConfig{
+		StreamingMode: runconfig.StreamingMode(cfg.StreamingMode),
+		MaxLLMCalls:   cfg.MaxLLMCalls,
+		LiveRequests:  inv.live,
+	})
+	ctx = plugininternal.ToContext(ctx, r.pluginManager)
+	ctx = plugininternal.WithScratch(ctx)
+	if inv.resumption != nil {
+		ctx = checkpoint.ToContext(ctx, inv.resumption)
+	}
+
+	var artifacts agent.Artifacts
+	if r.artifactService != nil {
+		artifacts = &artifactinternal.Artifacts{
+			Service:   r.artifactService,
+			SessionID: storedSession.ID(),
+			AppName:   storedSession.AppName(),
+			UserID:    storedSession.UserID(),
+		}
+	}
+
+	var memoryImpl agent.Memory = nil
+	if r.memoryService != nil {
+		memoryImpl = &imemory.Memory{
+			Service:   r.memoryService,
+			SessionID: storedSession.ID(),
+			UserID:    storedSession.UserID(),
+			AppName:   storedSession.AppName(),
+		}
+	}
+
+	mutableSession := sessioninternal.NewMutableSession(r.sessionService, storedSession)
+	invCtx := icontext.NewInvocationContext(ctx, icontext.InvocationContextParams{
+		Artifacts:    artifacts,
+		Memory:       memoryImpl,
+		Session:      mutableSession,
+		Agent:        agentToRun,
+		UserContent:  msg,
+		RunConfig:    &cfg,
+		InvocationID: inv.id,
+	})
+	if inv.resumption == nil {
+		var err error
+		invCtx, err = r.appendMessageToSession(invCtx, mutableSession, msg, cfg.SaveInputBlobsAsArtifacts, r.pluginManager)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		if cfg.Checkpointing {
+			start := checkpoint.Event(invCtx, checkpoint.Checkpoint{Agent: agentToRun.Name()})
+			if err := r.appendEvent(invCtx, mutableSession, start); err != nil {
+				yield(nil, fmt.Errorf("failed to add event to session: %w", err))
+				return
 			}
+		}
+	}
+	if r.memoryIngester != nil {
+		defer r.memoryIngester.Enqueue(storedSession.AppName(), storedSession.UserID(), storedSession.ID())
+	}
+	usage := &session.InvocationUsage{InvocationID: invCtx.InvocationID()}
+	var lastAgentEvent *session.Event
 
-			if pluginManager != nil {
-				modifiedEvent, err := pluginManager.RunOnEventCallback(ctx, event)
+	pluginManager := r.pluginManager
+	if pluginManager != nil {
+		// Defer the after run callbacks to perform global cleanup tasks or finalizing logs and metrics data.
+		// This does NOT emit any event.
+		defer pluginManager.RunAfterRunCallback(invCtx)
+
+		earlyExitResult, err := pluginManager.RunBeforeRunCallback(invCtx)
+		if earlyExitResult != nil || err != nil {
+			if inv.resumption != nil {
+				// The message of the user is already in the session.
 				if err != nil {
-					if !yield(nil, err) {
-						return
-					}
-					continue
-				}
-				if modifiedEvent != nil {
-					event = modifiedEvent
+					yield(nil, err)
 				}
+				return
+			}
+			earlyExitEvent := session.NewEvent(invCtx.InvocationID())
+			earlyExitEvent.Author = "user"
+			earlyExitEvent.LLMResponse = model.LLMResponse{
+				Content: msg,
+			}
+			if err := r.appendEvent(invCtx, mutableSession, earlyExitEvent); err != nil {
+				yield(nil, fmt.Errorf("failed to add event to session: %w", err))
+				return
 			}
+			yield(earlyExitEvent, err)
+			return
+		}
+	}
 
-			// only commit non-partial event to a session service
-			if !event.LLMResponse.Partial {
-				if err := r.sessionService.AppendEvent(ctx, storedSession, event); err != nil {
-					yield(nil, fmt.Errorf("failed to add event to session: %w", err))
+	var failed bool
+	for event, err := range agentToRun.Run(invCtx) {
+		if err != nil {
+			failed = true
+			if !yield(event, err) {
+				return
+			}
+			continue
+		}
+
+		if pluginManager != nil {
+			modifiedEvent, err := pluginManager.RunOnEventCallback(invCtx, event)
+			if err != nil {
+				failed = true
+				if !yield(nil, err) {
 					return
 				}
+				continue
+			}
+			if modifiedEvent != nil {
+				event = modifiedEvent
 			}
+		}
 
-			if !yield(event, nil) {
+		// only commit non-partial event to a session service, unless the
+		// run config asks for them, the events forwarded by the tools are
+		// saved in their own sessions.
+		if (!event.LLMResponse.Partial || cfg.SavePartialEvents) && !toolinternal.IsForwarded(event) {
+			appendCtx := context.Context(invCtx)
+			if event.LLMResponse.Partial {
+				appendCtx = session.WithPartialEvents(invCtx)
+			}
+			addUsage(usage, event)
+			if err := r.appendEvent(appendCtx, mutableSession, event); err != nil {
+				yield(nil, fmt.Errorf("failed to add event to session: %w", err))
 				return
 			}
+			if event.Author != "user" && !event.LLMResponse.Partial {
+				lastAgentEvent = event
+			}
 		}
+
+		if !yield(event, nil) {
+			return
+		}
+	}
+	if cfg.Checkpointing && !failed && invCtx.Err() == nil {
+		r.appendComplete(invCtx, mutableSession, lastAgentEvent)
+	}
+}
+
+// appendComplete records the end of the invocation run with checkpointing,
+// with an event of the author of the last agent event, so that the agent to
+// run isn't changed.
+func (r *Runner) appendComplete(ctx agent.InvocationContext, sess *sessioninternal.MutableSession, lastAgentEvent *session.Event) {
+	event := checkpoint.Event(ctx, checkpoint.Checkpoint{Complete: true})
+	if lastAgentEvent != nil {
+		event.Author = lastAgentEvent.Author
+		event.Branch = lastAgentEvent.Branch
+	}
+	if err := r.appendEvent(ctx, sess, event); err != nil {
+		log.Printf("Failed to record the end of invocation %s: %v", ctx.InvocationID(), err)
 	}
 }
 
-func (r *Runner) appendMessageToSession(ctx agent.InvocationContext, storedSession session.Session, msg *genai.Content, saveInputBlobsAsArtifacts bool, pluginManager *plugininternal.PluginManager) (agent.InvocationContext, error) {
+func (r *Runner) appendMessageToSession(ctx agent.InvocationContext, sess *sessioninternal.MutableSession, msg *genai.Content, saveInputBlobsAsArtifacts bool, pluginManager *plugininternal.PluginManager) (agent.InvocationContext, error) {
 	if msg == nil {
 		return ctx, nil
 	}
@@ -281,12 +546,68 @@ func (r *Runner) appendMessageToSession(ctx agent.InvocationContext, storedSessi
 		Content: msg,
 	}
 
-	if err := r.sessionService.AppendEvent(ctx, storedSession, event); err != nil {
+	if err := r.appendEvent(ctx, sess, event); err != nil {
 		return ctx, fmt.Errorf("failed to append event to sessionService: %w", err)
 	}
 	return ctx, nil
 }
 
+const (
+	// maxAppendAttempts bounds the attempts to append an event to a session
+	// which is modified concurrently.
+	maxAppendAttempts = 5
+	// appendRetryBackoff is the backoff before the first retry, doubled for
+	// every following one.
+	appendRetryBackoff = 20 * time.Millisecond
+)
+
+// appendEvent appends the event to the session. If another writer appended
+// to the session concurrently, it gets the session again and retries after
+// a jittered backoff, so that the state delta of the event is applied on
+// top of the latest state.
+func (r *Runner) appendEvent(ctx context.Context, sess *sessioninternal.MutableSession, event *session.Event) error {
+	backoff := appendRetryBackoff
+	for attempt := 1; ; attempt++ {
+		err := r.sessionService.AppendEvent(ctx, sess.StoredSession(), event)
+		if !errors.Is(err, session.ErrConcurrentModification) || attempt == maxAppendAttempts {
+			return err
+		}
+
+		// Wait between half and the whole backoff, so that the writers
+		// which conflicted don't retry in lockstep.
+		timer := time.NewTimer(backoff/2 + rand.N(backoff/2))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		backoff *= 2
+
+		if err := sess.Reload(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+// addUsage adds the usage metadata of the event to the token usage of the
+// invocation, and records the usage so far in the
+// [session.KeyInvocationUsage] state delta of the event. The last event of
+// the invocation with usage metadata thus records its whole usage, without
+// events of its own.
+func addUsage(usage *session.InvocationUsage, event *session.Event) {
+	if event.Partial || event.UsageMetadata == nil {
+		return
+	}
+	usage.Add(event)
+	stateDelta := maps.Clone(event.Actions.StateDelta)
+	if stateDelta == nil {
+		stateDelta = make(map[string]any)
+	}
+	stateDelta[session.KeyInvocationUsage] = usage.StateValue()
+	event.Actions.StateDelta = stateDelta
+}
+
 // findAgentToRun returns the agent that should handle the next request based on
 // session history.
 func (r *Runner) findAgentToRun(session session.Session, msg *genai.Content) (agent.Agent, error) {
@@ -365,6 +686,17 @@ func (r *Runner) isTransferableAcrossAgentTree(agentToRun agent.Agent) bool {
 	return true
 }
 
+// warnSubAgentGlobalInstructions logs a warning for the sub-agents of the
+// tree setting a global instruction: only the one of the root agent is used.
+func warnSubAgentGlobalInstructions(a agent.Agent) {
+	for _, subAgent := range a.SubAgents() {
+		if llminternal.HasGlobalInstruction(subAgent) {
+			log.Printf("Agent %s sets a global instruction but isn't the root agent, ignoring it", subAgent.Name())
+		}
+		warnSubAgentGlobalInstructions(subAgent)
+	}
+}
+
 func findAgent(curAgent agent.Agent, targetName string) agent.Agent {
 	if curAgent == nil || curAgent.Name() == targetName {
 		return curAgent
@@ -17,18 +17,26 @@ package runner
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"iter"
 	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"google.golang.org/genai"
 
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/agent/workflowagents/loopagent"
+	"google.golang.org/adk/agent/workflowagents/sequentialagent"
 	"google.golang.org/adk/artifact"
+	"google.golang.org/adk/memory"
+	"google.golang.org/adk/memory/ingest"
 	"google.golang.org/adk/model"
 	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
 )
 
 func TestRunner_findAgentToRun(t *testing.T) {
@@ -215,6 +223,139 @@ func Test_isTransferrableAcrossAgentTree(t *testing.T) {
 	}
 }
 
+func TestRunner_MemoryIngester(t *testing.T) {
+	ctx := t.Context()
+	sessionService := session.InMemoryService()
+	memoryService := memory.InMemoryService()
+	ingester, err := ingest.New(ingest.Config{Memory: memoryService, Sessions: sessionService})
+	if err != nil {
+		t.Fatalf("ingest.New() error = %v", err)
+	}
+
+	testAgent := must(agent.New(agent.Config{
+		Name: "test_agent",
+		Run: func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				event := session.NewEvent(ctx.InvocationID())
+				event.Author = "test_agent"
+				event.LLMResponse = model.LLMResponse{Content: genai.NewContentFromText("blue whales are mammals", genai.RoleModel)}
+				yield(event, nil)
+			}
+		},
+	}))
+	r, err := New(Config{
+		AppName:        "testApp",
+		Agent:          testAgent,
+		SessionService: sessionService,
+		MemoryIngester: ingester,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := sessionService.Create(ctx, &session.CreateRequest{AppName: "testApp", UserID: "testUser", SessionID: "testSession"}); err != nil {
+		t.Fatalf("sessionService.Create() error = %v", err)
+	}
+
+	for _, err := range r.Run(ctx, "testUser", "testSession", genai.NewContentFromText("tell me about whales", genai.RoleUser), agent.RunConfig{}) {
+		if err != nil {
+			t.Fatalf("r.Run() returned an error: %v", err)
+		}
+	}
+	// Close waits for the enqueued session to be ingested.
+	if err := ingester.Close(ctx); err != nil {
+		t.Fatalf("ingester.Close() error = %v", err)
+	}
+
+	resp, err := memoryService.Search(ctx, &memory.SearchRequest{AppName: "testApp", UserID: "testUser", Query: "whales"})
+	if err != nil {
+		t.Fatalf("memoryService.Search() error = %v", err)
+	}
+	var got []string
+	for _, m := range resp.Memories {
+		got = append(got, m.Author)
+	}
+	if diff := cmp.Diff([]string{"user", "test_agent"}, got); diff != "" {
+		t.Errorf("memory authors mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRunner_InvocationUsage(t *testing.T) {
+	ctx := t.Context()
+	sessionService := session.InMemoryService()
+	usage := func(prompt, candidates int32) *genai.GenerateContentResponseUsageMetadata {
+		return &genai.GenerateContentResponseUsageMetadata{PromptTokenCount: prompt, CandidatesTokenCount: candidates, TotalTokenCount: prompt + candidates}
+	}
+	testAgent := must(agent.New(agent.Config{
+		Name: "test_agent",
+		Run: func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				for _, resp := range []model.LLMResponse{
+					// The partial responses aren't counted.
+					{Content: genai.NewContentFromText("hel", genai.RoleModel), UsageMetadata: usage(10, 1), Partial: true},
+					{Content: genai.NewContentFromText("hello", genai.RoleModel), UsageMetadata: usage(10, 2), CustomMetadata: map[string]any{model.ModelMetadataKey: "m1"}},
+					{Content: genai.NewContentFromText("bye", genai.RoleModel), UsageMetadata: usage(20, 3), CustomMetadata: map[string]any{model.ModelMetadataKey: "m2"}},
+				} {
+					event := session.NewEvent(ctx.InvocationID())
+					event.Author = "test_agent"
+					event.LLMResponse = resp
+					if !yield(event, nil) {
+						return
+					}
+				}
+			}
+		},
+	}))
+	r, err := New(Config{AppName: "testApp", Agent: testAgent, SessionService: sessionService})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := sessionService.Create(ctx, &session.CreateRequest{AppName: "testApp", UserID: "testUser", SessionID: "testSession"}); err != nil {
+		t.Fatalf("sessionService.Create() error = %v", err)
+	}
+
+	var invocationID string
+	for event, err := range r.Run(ctx, "testUser", "testSession", genai.NewContentFromText("hi", genai.RoleUser), agent.RunConfig{}) {
+		if err != nil {
+			t.Fatalf("r.Run() returned an error: %v", err)
+		}
+		invocationID = event.InvocationID
+	}
+
+	resp, err := sessionService.Get(ctx, &session.GetRequest{AppName: "testApp", UserID: "testUser", SessionID: "testSession"})
+	if err != nil {
+		t.Fatalf("sessionService.Get() error = %v", err)
+	}
+	got, err := resp.Session.State().Get(session.KeyInvocationUsage)
+	if err != nil {
+		t.Fatalf("State().Get(%q) error = %v", session.KeyInvocationUsage, err)
+	}
+	want := map[string]any{
+		"invocation_id":     invocationID,
+		"responses":         2,
+		"prompt_tokens":     int64(30),
+		"candidates_tokens": int64(5),
+		"cached_tokens":     int64(0),
+		"thoughts_tokens":   int64(0),
+		"tool_use_tokens":   int64(0),
+		"total_tokens":      int64(35),
+		"models": map[string]any{
+			"m1": (&session.Usage{Responses: 1, PromptTokens: 10, CandidatesTokens: 2, TotalTokens: 12}).StateValue(),
+			"m2": (&session.Usage{Responses: 1, PromptTokens: 20, CandidatesTokens: 3, TotalTokens: 23}).StateValue(),
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("invocation usage state mismatch (-want +got):\n%s", diff)
+	}
+	// The usage is recorded by the events of the model responses.
+	events := resp.Session.Events()
+	if events.Len() != 3 {
+		t.Fatalf("got %d events, want the user event and the 2 model responses", events.Len())
+	}
+	if last := events.At(events.Len() - 1); last.Content.Parts[0].Text != "bye" || last.Actions.StateDelta[session.KeyInvocationUsage] == nil {
+		t.Errorf("last event = %+v, want the last response with the usage", last)
+	}
+}
+
 func TestRunner_SaveInputBlobsAsArtifacts(t *testing.T) {
 	ctx := context.Background()
 	appName := "testApp"
@@ -393,3 +534,481 @@ func createSession(t *testing.T, ctx context.Context, sessionID, appName, userID
 
 	return resp.Session
 }
+
+func TestRunner_ConcurrentModification(t *testing.T) {
+	appName, userID, sessionID := "testApp", "testUser", "testSession"
+
+	tests := []struct {
+		name      string
+		conflicts int
+		wantErr   bool
+		wantCalls int
+	}{
+		{
+			name:      "retried after a concurrent append",
+			conflicts: 1,
+			// The user message is appended twice, the agent event once.
+			wantCalls: 3,
+		},
+		{
+			name:      "gives up after the last attempt",
+			conflicts: maxAppendAttempts,
+			wantErr:   true,
+			wantCalls: maxAppendAttempts,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &conflictingService{Service: session.InMemoryService(), conflicts: tt.conflicts}
+			if _, err := service.Create(t.Context(), &session.CreateRequest{AppName: appName, UserID: userID, SessionID: sessionID}); err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+
+			testAgent := must(agent.New(agent.Config{
+				Name: "test_agent",
+				Run: func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+					return func(yield func(*session.Event, error) bool) {
+						event := session.NewEvent(ctx.InvocationID())
+						event.Author = "test_agent"
+						event.Actions.StateDelta = map[string]any{"agent": "done"}
+						yield(event, nil)
+					}
+				},
+			}))
+			r, err := New(Config{AppName: appName, Agent: testAgent, SessionService: service})
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			var gotErr error
+			for _, err := range r.Run(t.Context(), userID, sessionID, genai.NewContentFromText("hi", genai.RoleUser), agent.RunConfig{}) {
+				if err != nil {
+					gotErr = err
+				}
+			}
+			if tt.wantErr {
+				if !errors.Is(gotErr, session.ErrConcurrentModification) {
+					t.Errorf("Run() error = %v, want %v", gotErr, session.ErrConcurrentModification)
+				}
+			} else if gotErr != nil {
+				t.Fatalf("Run() error = %v", gotErr)
+			}
+			if service.calls != tt.wantCalls {
+				t.Errorf("AppendEvent() calls = %d, want %d", service.calls, tt.wantCalls)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			resp, err := service.Get(t.Context(), &session.GetRequest{AppName: appName, UserID: userID, SessionID: sessionID})
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			var gotAuthors []string
+			for event := range resp.Session.Events().All() {
+				gotAuthors = append(gotAuthors, event.Author)
+			}
+			if diff := cmp.Diff([]string{"other", "user", "test_agent"}, gotAuthors); diff != "" {
+				t.Errorf("session event authors mismatch (-want +got):\n%s", diff)
+			}
+			for key, want := range map[string]any{"other": "done", "agent": "done"} {
+				if got, err := resp.Session.State().Get(key); err != nil || got != want {
+					t.Errorf("session state %q = %v, %v, want %v", key, got, err, want)
+				}
+			}
+		})
+	}
+}
+
+// conflictingService appends an event of another writer before each of the
+// first conflicts appends, so that they fail with
+// [session.ErrConcurrentModification].
+type conflictingService struct {
+	session.Service
+	conflicts, calls int
+}
+
+func (s *conflictingService) AppendEvent(ctx context.Context, sess session.Session, event *session.Event) error {
+	s.calls++
+	if s.calls <= s.conflicts {
+		resp, err := s.Get(ctx, &session.GetRequest{AppName: sess.AppName(), UserID: sess.UserID(), SessionID: sess.ID()})
+		if err != nil {
+			return err
+		}
+		other := session.NewEvent("other_invocation")
+		other.Author = "other"
+		other.Actions.StateDelta = map[string]any{"other": "done"}
+		if err := s.Service.AppendEvent(ctx, resp.Session, other); err != nil {
+			return err
+		}
+	}
+	return s.Service.AppendEvent(ctx, sess, event)
+}
+
+type closableToolset struct {
+	name   string
+	closed int
+	err    error
+}
+
+func (ts *closableToolset) Name() string { return ts.name }
+
+func (ts *closableToolset) Tools(agent.ReadonlyContext) ([]tool.Tool, error) { return nil, nil }
+
+func (ts *closableToolset) Close(context.Context) error {
+	ts.closed++
+	return ts.err
+}
+
+func TestRunner_Close(t *testing.T) {
+	shared := &closableToolset{name: "shared"}
+	failing := &closableToolset{name: "failing", err: errors.New("connection lost")}
+	sub := must(llmagent.New(llmagent.Config{
+		Name:     "sub",
+		Toolsets: []tool.Toolset{shared, tool.FilterToolset(failing, tool.StringPredicate(nil))},
+	}))
+	root := must(llmagent.New(llmagent.Config{
+		Name:      "root",
+		Toolsets:  []tool.Toolset{shared},
+		SubAgents: []agent.Agent{sub},
+	}))
+	r, err := New(Config{AppName: "testApp", Agent: root, SessionService: session.InMemoryService()})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	err = r.Close(t.Context())
+	if err == nil || !strings.Contains(err.Error(), `failed to close toolset "failing" of agent "sub": connection lost`) {
+		t.Errorf("Close() error = %v, want the error of the failing toolset", err)
+	}
+	if shared.closed != 1 || failing.closed != 1 {
+		t.Errorf("toolsets closed %d and %d times, want once", shared.closed, failing.closed)
+	}
+}
+
+func TestRunner_Resume(t *testing.T) {
+	ctx := t.Context()
+	sessionService := session.InMemoryService()
+	runs := make(map[string]int)
+	step := func(name string) agent.Agent {
+		return must(agent.New(agent.Config{
+			Name: name,
+			Run: func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+				return func(yield func(*session.Event, error) bool) {
+					runs[name]++
+					event := session.NewEvent(ctx.InvocationID())
+					event.Author = name
+					event.Content = genai.NewContentFromText(name, genai.RoleModel)
+					yield(event, nil)
+				}
+			},
+		}))
+	}
+	loop := must(loopagent.New(loopagent.Config{
+		AgentConfig:   agent.Config{Name: "loop", SubAgents: []agent.Agent{step("counter")}},
+		MaxIterations: 2,
+	}))
+	root := must(sequentialagent.New(sequentialagent.Config{
+		AgentConfig: agent.Config{Name: "pipeline", SubAgents: []agent.Agent{step("first"), loop, step("interrupted"), step("last")}},
+	}))
+	r, err := New(Config{AppName: "testApp", Agent: root, SessionService: sessionService})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := sessionService.Create(ctx, &session.CreateRequest{AppName: "testApp", UserID: "testUser", SessionID: "testSession"}); err != nil {
+		t.Fatalf("sessionService.Create() error = %v", err)
+	}
+
+	// The invocation is interrupted once the interrupted agent ran, before
+	// the sequence records the end of its step.
+	var invocationID string
+	for event, err := range r.Run(ctx, "testUser", "testSession", genai.NewContentFromText("hi", genai.RoleUser), agent.RunConfig{Checkpointing: true}) {
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		invocationID = event.InvocationID
+		if event.Author == "interrupted" {
+			break
+		}
+	}
+
+	var texts []string
+	for event, err := range r.Resume(ctx, "testUser", "testSession", invocationID, agent.RunConfig{}) {
+		if err != nil {
+			t.Fatalf("Resume() error = %v", err)
+		}
+		if event.InvocationID != invocationID {
+			t.Errorf("event invocation ID = %q, want %q", event.InvocationID, invocationID)
+		}
+		if event.Content != nil {
+			texts = append(texts, event.Content.Parts[0].Text)
+		}
+	}
+	if diff := cmp.Diff([]string{"interrupted", "last"}, texts); diff != "" {
+		t.Errorf("resumed texts mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string]int{"first": 1, "counter": 2, "interrupted": 2, "last": 1}, runs); diff != "" {
+		t.Errorf("agent runs mismatch (-want +got):\n%s", diff)
+	}
+
+	// The invocation ended.
+	for _, err := range r.Resume(ctx, "testUser", "testSession", invocationID, agent.RunConfig{}) {
+		if !errors.Is(err, ErrNotResumable) {
+			t.Errorf("Resume() of an ended invocation error = %v, want %v", err, ErrNotResumable)
+		}
+	}
+
+	// The invocations run without checkpointing can't be resumed.
+	for event, err := range r.Run(ctx, "testUser", "testSession", genai.NewContentFromText("hi", genai.RoleUser), agent.RunConfig{}) {
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		invocationID = event.InvocationID
+	}
+	for _, err := range r.Resume(ctx, "testUser", "testSession", invocationID, agent.RunConfig{}) {
+		if !errors.Is(err, ErrNotResumable) {
+			t.Errorf("Resume() of an invocation without checkpoints error = %v, want %v", err, ErrNotResumable)
+		}
+	}
+}
+
+func TestRunner_Resume_FunctionCalls(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		// interrupt reports whether the invocation is interrupted after the
+		// event.
+		interrupt func(*session.Event) bool
+		wantCalls int
+	}{
+		{
+			name:      "pending call",
+			interrupt: func(e *session.Event) bool { return len(e.Content.Parts) > 0 && e.Content.Parts[0].FunctionCall != nil },
+			wantCalls: 1,
+		},
+		{
+			name: "ended call",
+			interrupt: func(e *session.Event) bool {
+				return len(e.Content.Parts) > 0 && e.Content.Parts[0].FunctionResponse != nil
+			},
+			wantCalls: 1,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := t.Context()
+			sessionService := session.InMemoryService()
+			calls := 0
+			sendEmail, err := functiontool.New(functiontool.Config{Name: "send_email", Description: "sends an email"}, func(tool.Context, struct{}) (string, error) {
+				calls++
+				return "sent", nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			llm := &scriptedModel{responses: []*genai.Content{
+				{Role: genai.RoleModel, Parts: []*genai.Part{{FunctionCall: &genai.FunctionCall{ID: "call_1", Name: "send_email", Args: map[string]any{}}}}},
+				genai.NewContentFromText("done", genai.RoleModel),
+			}}
+			a := must(llmagent.New(llmagent.Config{Name: "assistant", Model: llm, Tools: []tool.Tool{sendEmail}}))
+			r, err := New(Config{AppName: "testApp", Agent: a, SessionService: sessionService})
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if _, err := sessionService.Create(ctx, &session.CreateRequest{AppName: "testApp", UserID: "testUser", SessionID: "testSession"}); err != nil {
+				t.Fatalf("sessionService.Create() error = %v", err)
+			}
+
+			var invocationID string
+			for event, err := range r.Run(ctx, "testUser", "testSession", genai.NewContentFromText("send it", genai.RoleUser), agent.RunConfig{Checkpointing: true}) {
+				if err != nil {
+					t.Fatalf("Run() error = %v", err)
+				}
+				invocationID = event.InvocationID
+				if event.Content != nil && tc.interrupt(event) {
+					break
+				}
+			}
+			var texts []string
+			for event, err := range r.Resume(ctx, "testUser", "testSession", invocationID, agent.RunConfig{}) {
+				if err != nil {
+					t.Fatalf("Resume() error = %v", err)
+				}
+				if event.Content != nil && event.Content.Parts[0].Text != "" {
+					texts = append(texts, event.Content.Parts[0].Text)
+				}
+			}
+			if diff := cmp.Diff([]string{"done"}, texts); diff != "" {
+				t.Errorf("resumed texts mismatch (-want +got):\n%s", diff)
+			}
+			if calls != tc.wantCalls {
+				t.Errorf("send_email calls = %d, want %d", calls, tc.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRunner_MaxLLMCalls(t *testing.T) {
+	ctx := t.Context()
+	sessionService := session.InMemoryService()
+	lookup, err := functiontool.New(functiontool.Config{Name: "lookup", Description: "looks something up"}, func(tool.Context, struct{}) (string, error) {
+		return "not found", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The model calls the tool again and again.
+	llm := &loopingModel{}
+	root := must(sequentialagent.New(sequentialagent.Config{
+		AgentConfig: agent.Config{
+			Name: "pipeline",
+			SubAgents: []agent.Agent{
+				must(llmagent.New(llmagent.Config{Name: "researcher", Model: llm, Tools: []tool.Tool{lookup}})),
+				must(llmagent.New(llmagent.Config{Name: "writer", Model: llm})),
+			},
+		},
+	}))
+	r, err := New(Config{AppName: "testApp", Agent: root, SessionService: sessionService})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := sessionService.Create(ctx, &session.CreateRequest{AppName: "testApp", UserID: "testUser", SessionID: "testSession"}); err != nil {
+		t.Fatalf("sessionService.Create() error = %v", err)
+	}
+
+	cfg := agent.RunConfig{MaxLLMCalls: 3, ResponseModalities: []genai.Modality{genai.ModalityText}}
+	var last *session.Event
+	for event, err := range r.Run(ctx, "testUser", "testSession", genai.NewContentFromText("find it", genai.RoleUser), cfg) {
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		last = event
+	}
+	if llm.calls != 3 {
+		t.Errorf("model calls = %d, want 3", llm.calls)
+	}
+	if last == nil || last.Author != "researcher" || last.ErrorCode != agent.ErrorCodeLLMCallsLimit || !last.IsFinalResponse() {
+		t.Fatalf("last event = %+v, want the final limit event of the researcher", last)
+	}
+	if want := (&agent.LLMCallsLimitError{Limit: 3}).Error(); last.ErrorMessage != want {
+		t.Errorf("last event error message = %q, want %q", last.ErrorMessage, want)
+	}
+	if diff := cmp.Diff([]string{"TEXT"}, llm.modalities); diff != "" {
+		t.Errorf("response modalities mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRunner_SavePartialEvents(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		save bool
+		want []string
+	}{
+		{
+			name: "default",
+			want: []string{"hi", "hello", "again", "hello"},
+		},
+		{
+			name: "save partial events",
+			save: true,
+			want: []string{"hi", "hel", "hello", "again", "hel", "hello"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := t.Context()
+			sessionService := session.InMemoryService()
+			llm := &partialModel{}
+			r, err := New(Config{AppName: "testApp", Agent: must(llmagent.New(llmagent.Config{Name: "greeter", Model: llm})), SessionService: sessionService})
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if _, err := sessionService.Create(ctx, &session.CreateRequest{AppName: "testApp", UserID: "testUser", SessionID: "testSession"}); err != nil {
+				t.Fatalf("sessionService.Create() error = %v", err)
+			}
+
+			cfg := agent.RunConfig{StreamingMode: agent.StreamingModeSSE, SavePartialEvents: tc.save}
+			for _, msg := range []string{"hi", "again"} {
+				for _, err := range r.Run(ctx, "testUser", "testSession", genai.NewContentFromText(msg, genai.RoleUser), cfg) {
+					if err != nil {
+						t.Fatalf("Run() error = %v", err)
+					}
+				}
+			}
+
+			resp, err := sessionService.Get(ctx, &session.GetRequest{AppName: "testApp", UserID: "testUser", SessionID: "testSession"})
+			if err != nil {
+				t.Fatalf("sessionService.Get() error = %v", err)
+			}
+			var got []string
+			for event := range resp.Session.Events().All() {
+				if event.Content != nil {
+					got = append(got, event.Content.Parts[0].Text)
+				}
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("session event texts mismatch (-want +got):\n%s", diff)
+			}
+			// The saved partial events aren't part of the history.
+			if diff := cmp.Diff([]string{"hi", "hello", "again"}, llm.history); diff != "" {
+				t.Errorf("history of the second model call mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// partialModel streams a partial response before its final one, and records
+// the texts of the contents of the last request.
+type partialModel struct {
+	history []string
+}
+
+func (m *partialModel) Name() string { return "partial" }
+
+func (m *partialModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		m.history = nil
+		for _, content := range req.Contents {
+			m.history = append(m.history, content.Parts[0].Text)
+		}
+		if !yield(&model.LLMResponse{Content: genai.NewContentFromText("hel", genai.RoleModel), Partial: true}, nil) {
+			return
+		}
+		yield(&model.LLMResponse{Content: genai.NewContentFromText("hello", genai.RoleModel), TurnComplete: true}, nil)
+	}
+}
+
+// loopingModel calls the lookup tool in all its responses.
+type loopingModel struct {
+	calls      int
+	modalities []string
+}
+
+func (m *loopingModel) Name() string { return "looping" }
+
+func (m *loopingModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		m.calls++
+		m.modalities = req.Config.ResponseModalities
+		yield(&model.LLMResponse{Content: &genai.Content{
+			Role:  genai.RoleModel,
+			Parts: []*genai.Part{{FunctionCall: &genai.FunctionCall{ID: fmt.Sprintf("call_%d", m.calls), Name: "lookup", Args: map[string]any{}}}},
+		}}, nil)
+	}
+}
+
+// scriptedModel answers the requests with its responses, in order.
+type scriptedModel struct {
+	responses []*genai.Content
+	calls     int
+}
+
+func (m *scriptedModel) Name() string { return "scripted" }
+
+func (m *scriptedModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		if m.calls == len(m.responses) {
+			yield(nil, fmt.Errorf("unexpected model call %d", m.calls+1))
+			return
+		}
+		m.calls++
+		yield(&model.LLMResponse{Content: m.responses[m.calls-1]}, nil)
+	}
+}
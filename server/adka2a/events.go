@@ -297,6 +297,7 @@ func toEventActions(meta map[string]any) session.EventActions {
 	}
 	var result session.EventActions
 	result.Escalate, _ = meta[metadataEscalateKey].(bool)
+	result.EscalateToRoot, _ = meta[metadataEscalateToRootKey].(bool)
 	result.TransferToAgent, _ = meta[metadataTransferToAgentKey].(string)
 	return result
 }
@@ -30,6 +30,7 @@ var (
 	customMetaContextIDKey = ToADKMetaKey("context_id")
 
 	metadataEscalateKey        = ToA2AMetaKey("escalate")
+	metadataEscalateToRootKey  = ToA2AMetaKey("escalate_to_root")
 	metadataTransferToAgentKey = ToA2AMetaKey("transfer_to_agent")
 	metadataErrorCodeKey       = ToA2AMetaKey("error_code")
 	metadataCitationKey        = ToA2AMetaKey("citation_metadata")
@@ -124,6 +125,9 @@ func setActionsMeta(meta map[string]any, actions session.EventActions) map[strin
 	if actions.Escalate {
 		meta[metadataEscalateKey] = true
 	}
+	if actions.EscalateToRoot {
+		meta[metadataEscalateToRootKey] = true
+	}
 	if actions.TransferToAgent != "" {
 		meta[metadataTransferToAgentKey] = actions.TransferToAgent
 	}
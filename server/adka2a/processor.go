@@ -154,6 +154,7 @@ func (p *eventProcessor) makeTaskFailedEvent(cause error, event *session.Event)
 
 func (p *eventProcessor) updateTerminalActions(event *session.Event) {
 	p.terminalActions.Escalate = p.terminalActions.Escalate || event.Actions.Escalate
+	p.terminalActions.EscalateToRoot = p.terminalActions.EscalateToRoot || event.Actions.EscalateToRoot
 	if event.Actions.TransferToAgent != "" {
 		p.terminalActions.TransferToAgent = event.Actions.TransferToAgent
 	}
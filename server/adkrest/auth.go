@@ -0,0 +1,145 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adkrest
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"google.golang.org/adk/server/adkrest/controllers"
+	"google.golang.org/adk/server/adkrest/internal/auth"
+)
+
+// APIKeyHeader is the request header carrying the API key. Keys can also be
+// sent as bearer tokens in the Authorization header.
+const APIKeyHeader = "X-API-Key"
+
+// ErrMissingCredentials is returned by the authenticators for the requests
+// which don't carry credentials.
+var ErrMissingCredentials = errors.New("missing credentials")
+
+// Principal is the authenticated caller of a request.
+type Principal = auth.Principal
+
+// PrincipalFromContext returns the principal authenticated by the
+// [AuthOptions.Authenticator] of the handler serving the request, if any.
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	return auth.FromContext(ctx)
+}
+
+// Authenticator authenticates the callers of the REST API.
+type Authenticator interface {
+	// Authenticate returns the caller of the request, or an error if the
+	// request doesn't carry valid credentials.
+	Authenticate(req *http.Request) (*Principal, error)
+}
+
+// AuthOptions configures the authentication of the REST API. All the routes
+// except the health and readiness probes require authentication.
+type AuthOptions struct {
+	// Authenticator authenticates the requests, e.g.
+	// [NewAPIKeyAuthenticator] or [NewOIDCAuthenticator].
+	Authenticator Authenticator
+	// RequireUserMatch rejects with 403 status code the requests whose user
+	// ID, either in the path or in the run request, is not the subject of
+	// the principal.
+	RequireUserMatch bool
+}
+
+// unauthenticatedRoutes are the names of the routes open to everyone, so that
+// probes don't need credentials.
+var unauthenticatedRoutes = map[string]bool{
+	"Healthz": true,
+	"Readyz":  true,
+}
+
+// withAuth authenticates the requests with opts. It must be installed as a
+// middleware of the router, so that the matched route is known.
+func withAuth(opts *AuthOptions) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if route := mux.CurrentRoute(r); route != nil && unauthenticatedRoutes[route.GetName()] {
+				next.ServeHTTP(w, r)
+				return
+			}
+			principal, err := opts.Authenticator.Authenticate(r)
+			if err != nil {
+				rejectRequest(w, r, controllers.Unauthorized(err.Error()))
+				return
+			}
+			ctx := auth.ToContext(r.Context(), principal, opts.RequireUserMatch)
+			if userID := mux.Vars(r)["user_id"]; userID != "" {
+				if err := auth.CheckUser(ctx, userID); err != nil {
+					rejectRequest(w, r, controllers.Forbidden(err.Error()))
+					return
+				}
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func rejectRequest(w http.ResponseWriter, r *http.Request, err error) {
+	controllers.NewErrorHandler(func(http.ResponseWriter, *http.Request) error { return err })(w, r)
+}
+
+// NewAPIKeyAuthenticator returns an [Authenticator] which accepts the given
+// API keys, sent in the [APIKeyHeader] header or as bearer tokens. keys maps
+// every key to the identity of its owner, which is the subject of the
+// principal.
+func NewAPIKeyAuthenticator(keys map[string]string) Authenticator {
+	return apiKeyAuthenticator{keys: keys}
+}
+
+type apiKeyAuthenticator struct {
+	keys map[string]string
+}
+
+func (a apiKeyAuthenticator) Authenticate(req *http.Request) (*Principal, error) {
+	key := req.Header.Get(APIKeyHeader)
+	if key == "" {
+		key, _ = bearerToken(req)
+	}
+	if key == "" {
+		return nil, ErrMissingCredentials
+	}
+	// Compare with all the keys in constant time, not to leak which prefix
+	// of a key matched.
+	var identity string
+	found := false
+	for k, id := range a.keys {
+		if subtle.ConstantTimeCompare([]byte(k), []byte(key)) == 1 {
+			identity, found = id, true
+		}
+	}
+	if !found {
+		return nil, errors.New("invalid API key")
+	}
+	return &Principal{Subject: identity}, nil
+}
+
+// bearerToken returns the token of the "Authorization: Bearer" header.
+func bearerToken(req *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(req.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
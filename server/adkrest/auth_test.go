@@ -0,0 +1,251 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adkrest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/gorilla/mux"
+
+	"google.golang.org/adk/server/adkrest/controllers"
+)
+
+// newAuthTestRouter returns a router with a health route and a user route
+// which replies with the subject of the principal.
+func newAuthTestRouter(opts *AuthOptions) *mux.Router {
+	router := mux.NewRouter()
+	router.Methods(http.MethodGet).Path("/healthz").Name("Healthz").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	router.Methods(http.MethodGet).Path("/apps/{app_name}/users/{user_id}/sessions").Name("ListSessions").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := PrincipalFromContext(r.Context())
+		if !ok {
+			http.Error(w, "no principal", http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(principal.Subject))
+	})
+	router.Use(withAuth(opts))
+	return router
+}
+
+func TestWithAuth_APIKey(t *testing.T) {
+	authenticator := NewAPIKeyAuthenticator(map[string]string{"key-alice": "alice", "key-bob": "bob"})
+
+	tests := []struct {
+		name             string
+		path             string
+		header           map[string]string
+		requireUserMatch bool
+		wantStatus       int
+		wantBody         string
+	}{
+		{
+			name:       "api key header",
+			path:       "/apps/app/users/alice/sessions",
+			header:     map[string]string{APIKeyHeader: "key-alice"},
+			wantStatus: http.StatusOK,
+			wantBody:   "alice",
+		},
+		{
+			name:       "bearer api key",
+			path:       "/apps/app/users/alice/sessions",
+			header:     map[string]string{"Authorization": "Bearer key-bob"},
+			wantStatus: http.StatusOK,
+			wantBody:   "bob",
+		},
+		{
+			name:       "missing key",
+			path:       "/apps/app/users/alice/sessions",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "invalid key",
+			path:       "/apps/app/users/alice/sessions",
+			header:     map[string]string{APIKeyHeader: "key-eve"},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "other user allowed",
+			path:       "/apps/app/users/alice/sessions",
+			header:     map[string]string{APIKeyHeader: "key-bob"},
+			wantStatus: http.StatusOK,
+			wantBody:   "bob",
+		},
+		{
+			name:             "other user rejected",
+			path:             "/apps/app/users/alice/sessions",
+			header:           map[string]string{APIKeyHeader: "key-bob"},
+			requireUserMatch: true,
+			wantStatus:       http.StatusForbidden,
+		},
+		{
+			name:       "health is open",
+			path:       "/healthz",
+			wantStatus: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newAuthTestRouter(&AuthOptions{Authenticator: authenticator, RequireUserMatch: tt.requireUserMatch})
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("status = %v, want %v", rr.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusUnauthorized || tt.wantStatus == http.StatusForbidden {
+				var errResp controllers.ErrorResponse
+				if err := json.NewDecoder(rr.Body).Decode(&errResp); err != nil {
+					t.Fatalf("decode error response: %v", err)
+				}
+				if errResp.Error.Status != tt.wantStatus {
+					t.Errorf("error response status = %v, want %v", errResp.Error.Status, tt.wantStatus)
+				}
+				return
+			}
+			if got := rr.Body.String(); got != tt.wantBody {
+				t.Errorf("body = %q, want %q", got, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestOIDCAuthenticator(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	idpMux := http.NewServeMux()
+	idp := httptest.NewServer(idpMux)
+	t.Cleanup(idp.Close)
+	idpMux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"issuer": idp.URL, "jwks_uri": idp.URL + "/keys"})
+	})
+	idpMux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+			{Key: key.Public(), KeyID: "key1", Algorithm: string(jose.RS256), Use: "sig"},
+		}})
+	})
+
+	authenticator, err := NewOIDCAuthenticator(t.Context(), OIDCConfig{IssuerURL: idp.URL, Audience: "adk"})
+	if err != nil {
+		t.Fatalf("NewOIDCAuthenticator() failed: %v", err)
+	}
+
+	sign := func(signingKey *rsa.PrivateKey, keyID string, claims jwt.Claims) string {
+		t.Helper()
+		signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: signingKey}, (&jose.SignerOptions{}).WithHeader("kid", keyID))
+		if err != nil {
+			t.Fatal(err)
+		}
+		token, err := jwt.Signed(signer).Claims(claims).Claims(map[string]any{"email": "alice@example.com"}).Serialize()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	now := time.Now()
+	valid := jwt.Claims{
+		Issuer:   idp.URL,
+		Subject:  "alice",
+		Audience: jwt.Audience{"adk"},
+		Expiry:   jwt.NewNumericDate(now.Add(time.Hour)),
+		IssuedAt: jwt.NewNumericDate(now),
+	}
+	with := func(change func(c *jwt.Claims)) jwt.Claims {
+		c := valid
+		change(&c)
+		return c
+	}
+
+	tests := []struct {
+		name        string
+		token       string
+		wantSubject string
+		wantErr     bool
+	}{
+		{name: "valid", token: sign(key, "key1", valid), wantSubject: "alice"},
+		{name: "wrong audience", token: sign(key, "key1", with(func(c *jwt.Claims) { c.Audience = jwt.Audience{"other"} })), wantErr: true},
+		{name: "wrong issuer", token: sign(key, "key1", with(func(c *jwt.Claims) { c.Issuer = "https://evil.example.com" })), wantErr: true},
+		{name: "expired", token: sign(key, "key1", with(func(c *jwt.Claims) { c.Expiry = jwt.NewNumericDate(now.Add(-time.Hour)) })), wantErr: true},
+		{name: "no expiry", token: sign(key, "key1", with(func(c *jwt.Claims) { c.Expiry = nil })), wantErr: true},
+		{name: "no subject", token: sign(key, "key1", with(func(c *jwt.Claims) { c.Subject = "" })), wantErr: true},
+		{name: "unknown key", token: sign(otherKey, "key2", valid), wantErr: true},
+		{name: "wrong signature", token: sign(otherKey, "key1", valid), wantErr: true},
+		{name: "malformed", token: "not-a-token", wantErr: true},
+		{name: "missing", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			principal, err := authenticator.Authenticate(req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Authenticate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if principal.Subject != tt.wantSubject {
+				t.Errorf("Authenticate() subject = %q, want %q", principal.Subject, tt.wantSubject)
+			}
+			if got := principal.Claims["email"]; got != "alice@example.com" {
+				t.Errorf("Authenticate() email claim = %v, want %q", got, "alice@example.com")
+			}
+		})
+	}
+}
+
+func TestNewOIDCAuthenticator_Errors(t *testing.T) {
+	idp := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(idp.Close)
+
+	tests := []struct {
+		name string
+		cfg  OIDCConfig
+	}{
+		{name: "missing audience", cfg: OIDCConfig{IssuerURL: idp.URL}},
+		{name: "discovery fails", cfg: OIDCConfig{IssuerURL: idp.URL, Audience: "adk"}},
+		{name: "keys fetch fails", cfg: OIDCConfig{IssuerURL: idp.URL, Audience: "adk", JWKSURL: idp.URL + "/keys"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewOIDCAuthenticator(t.Context(), tt.cfg); err == nil {
+				t.Errorf("NewOIDCAuthenticator() succeeded, want error")
+			}
+		})
+	}
+}
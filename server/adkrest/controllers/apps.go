@@ -15,11 +15,25 @@
 package controllers
 
 import (
+	"fmt"
 	"net/http"
 
+	"github.com/gorilla/mux"
+
 	"google.golang.org/adk/agent"
+	"google.golang.org/adk/server/adkrest/internal/services"
+)
+
+const (
+	// GraphFormatDOT is the Graphviz DOT format of the app graph.
+	GraphFormatDOT = "dot"
+	// GraphFormatMermaid is the Mermaid flowchart format of the app graph.
+	GraphFormatMermaid = "mermaid"
 )
 
+// maxGraphDepth is the maximum depth of the agent trees drawn by the app graph.
+const maxGraphDepth = 32
+
 // AppsAPIController is the controller for the Apps API.
 type AppsAPIController struct {
 	agentLoader agent.Loader
@@ -35,3 +49,31 @@ func (c *AppsAPIController) ListAppsHandler(rw http.ResponseWriter, req *http.Re
 	apps := c.agentLoader.ListAgents()
 	EncodeJSONResponse(apps, http.StatusOK, rw)
 }
+
+// AppGraphHandler returns the graph of the agents and tools of an app, in the
+// format set with the format query parameter: [GraphFormatDOT] (the default)
+// or [GraphFormatMermaid].
+func (c *AppsAPIController) AppGraphHandler(rw http.ResponseWriter, req *http.Request) {
+	appName := mux.Vars(req)["app_name"]
+	format := req.URL.Query().Get("format")
+	if format == "" {
+		format = GraphFormatDOT
+	}
+	if format != GraphFormatDOT && format != GraphFormatMermaid {
+		httpError(rw, req, fmt.Sprintf("invalid format %q: must be %q or %q", format, GraphFormatDOT, GraphFormatMermaid), http.StatusBadRequest)
+		return
+	}
+	root, err := c.agentLoader.LoadAgent(appName)
+	if err != nil {
+		httpError(rw, req, fmt.Sprintf("failed to load agent: %v", err), http.StatusNotFound)
+		return
+	}
+	topology := services.AgentTopology(root, maxGraphDepth)
+	if format == GraphFormatMermaid {
+		rw.Header().Set("Content-Type", "text/vnd.mermaid; charset=utf-8")
+		_, _ = rw.Write([]byte(topology.Mermaid()))
+		return
+	}
+	rw.Header().Set("Content-Type", "text/vnd.graphviz; charset=utf-8")
+	_, _ = rw.Write([]byte(topology.DOT()))
+}
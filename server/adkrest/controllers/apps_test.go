@@ -0,0 +1,93 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/server/adkrest/controllers"
+)
+
+func TestAppGraphHandler(t *testing.T) {
+	root, err := agent.New(agent.Config{Name: "app"})
+	if err != nil {
+		t.Fatalf("agent.New() failed: %v", err)
+	}
+	apiController := controllers.NewAppsAPIController(agent.NewSingleLoader(root))
+
+	tc := []struct {
+		name            string
+		appName         string
+		format          string
+		wantStatus      int
+		wantContentType string
+		wantPrefix      string
+	}{
+		{
+			name:            "default format",
+			appName:         "app",
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/vnd.graphviz; charset=utf-8",
+			wantPrefix:      "digraph AgentGraph {",
+		},
+		{
+			name:            "mermaid",
+			appName:         "app",
+			format:          controllers.GraphFormatMermaid,
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/vnd.mermaid; charset=utf-8",
+			wantPrefix:      "flowchart LR",
+		},
+		{
+			name:       "unknown format",
+			appName:    "app",
+			format:     "svg",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unknown app",
+			appName:    "other",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/apps/"+tt.appName+"/graph?format="+tt.format, nil)
+			req = mux.SetURLVars(req, map[string]string{"app_name": tt.appName})
+			rr := httptest.NewRecorder()
+
+			apiController.AppGraphHandler(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rr.Code, tt.wantStatus, rr.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if got := rr.Header().Get("Content-Type"); got != tt.wantContentType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.wantContentType)
+			}
+			if !strings.HasPrefix(rr.Body.String(), tt.wantPrefix) {
+				t.Errorf("body = %q, want prefix %q", rr.Body, tt.wantPrefix)
+			}
+		})
+	}
+}
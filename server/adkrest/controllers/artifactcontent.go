@@ -0,0 +1,152 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"google.golang.org/adk/artifact"
+)
+
+// serveArtifactContent writes the raw content of an artifact version, with its
+// media type as Content-Type. Artifact versions are immutable, so the ETag is
+// derived from the version and conditional requests with If-None-Match are
+// answered with 304 status code.
+//
+// Byte-range requests are supported if the artifact service implements
+// [artifact.Opener], or [artifact.StreamLoader] with seekable contents;
+// otherwise the full content is always sent, with "Accept-Ranges: none".
+// The content is streamed to the client if the service implements either.
+func (c *ArtifactsAPIController) serveArtifactContent(rw http.ResponseWriter, req *http.Request, loadReq *artifact.LoadRequest) {
+	if opener, ok := c.artifactService.(artifact.Opener); ok {
+		resp, err := opener.Open(req.Context(), loadReq)
+		if err != nil {
+			writeError(rw, req, artifactLoadError(err))
+			return
+		}
+		rw.Header().Set("Content-Type", resp.MIMEType)
+		rw.Header().Set("ETag", artifactETag(resp.Version))
+		http.ServeContent(rw, req, "", time.Time{}, resp.Content)
+		return
+	}
+	if loader, ok := c.artifactService.(artifact.StreamLoader); ok {
+		c.streamArtifactContent(rw, req, loader, loadReq)
+		return
+	}
+
+	version := loadReq.Version
+	if version == 0 {
+		versions, err := c.artifactService.Versions(req.Context(), &artifact.VersionsRequest{
+			AppName:   loadReq.AppName,
+			UserID:    loadReq.UserID,
+			SessionID: loadReq.SessionID,
+			FileName:  loadReq.FileName,
+		})
+		if err != nil {
+			writeError(rw, req, artifactLoadError(err))
+			return
+		}
+		version = slices.Max(versions.Versions)
+	}
+	etag := artifactETag(version)
+	rw.Header().Set("Accept-Ranges", "none")
+	rw.Header().Set("ETag", etag)
+	if etagMatches(req.Header.Get("If-None-Match"), etag) {
+		rw.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	versionReq := *loadReq
+	versionReq.Version = version
+	resp, err := c.artifactService.Load(req.Context(), &versionReq)
+	if err != nil {
+		writeError(rw, req, artifactLoadError(err))
+		return
+	}
+	var contentType string
+	var content []byte
+	if resp.Part.InlineData != nil {
+		contentType, content = resp.Part.InlineData.MIMEType, resp.Part.InlineData.Data
+	} else {
+		contentType, content = "text/plain; charset=utf-8", []byte(resp.Part.Text)
+	}
+	rw.Header().Set("Content-Type", contentType)
+	rw.Header().Set("Content-Length", fmt.Sprint(len(content)))
+	rw.WriteHeader(http.StatusOK)
+	_, _ = rw.Write(content)
+}
+
+// streamArtifactContent writes the content of an artifact version loaded with
+// loader.
+func (c *ArtifactsAPIController) streamArtifactContent(rw http.ResponseWriter, req *http.Request, loader artifact.StreamLoader, loadReq *artifact.LoadRequest) {
+	resp, err := loader.LoadStream(req.Context(), loadReq)
+	if err != nil {
+		writeError(rw, req, artifactLoadError(err))
+		return
+	}
+	defer resp.Content.Close()
+	contentType := resp.MIMEType
+	if resp.Text {
+		contentType = "text/plain; charset=utf-8"
+	}
+	etag := artifactETag(resp.Version)
+	rw.Header().Set("Content-Type", contentType)
+	rw.Header().Set("ETag", etag)
+	if seeker, ok := resp.Content.(io.ReadSeeker); ok {
+		http.ServeContent(rw, req, "", time.Time{}, seeker)
+		return
+	}
+
+	rw.Header().Set("Accept-Ranges", "none")
+	if etagMatches(req.Header.Get("If-None-Match"), etag) {
+		rw.WriteHeader(http.StatusNotModified)
+		return
+	}
+	if resp.Size >= 0 {
+		rw.Header().Set("Content-Length", fmt.Sprint(resp.Size))
+	}
+	rw.WriteHeader(http.StatusOK)
+	_, _ = io.Copy(rw, resp.Content)
+}
+
+func artifactETag(version int64) string {
+	return fmt.Sprintf(`"v%d"`, version)
+}
+
+// etagMatches reports whether the If-None-Match header value matches etag,
+// using the weak comparison of RFC 9110.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for candidate := range strings.SplitSeq(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+func artifactLoadError(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return newStatusError(err, http.StatusNotFound)
+	}
+	return newStatusError(fmt.Errorf("failed to load artifact: %w", err), http.StatusInternalServerError)
+}
@@ -0,0 +1,227 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/artifact"
+	"google.golang.org/adk/server/adkrest/controllers"
+)
+
+// serviceOnly hides the optional interfaces implemented by an artifact
+// service.
+type serviceOnly struct {
+	artifact.Service
+}
+
+// streamOnly exposes [artifact.StreamLoader] as the only optional interface
+// of an artifact service, with unseekable contents unless seekable is set.
+type streamOnly struct {
+	artifact.Service
+	seekable bool
+}
+
+func (s streamOnly) LoadStream(ctx context.Context, req *artifact.LoadRequest) (*artifact.LoadStreamResponse, error) {
+	resp, err := s.Service.(artifact.StreamLoader).LoadStream(ctx, req)
+	if err == nil && !s.seekable {
+		resp.Content = struct{ io.ReadCloser }{resp.Content}
+	}
+	return resp, err
+}
+
+func TestLoadArtifactHandler_Media(t *testing.T) {
+	const content = "0123456789"
+
+	tests := []struct {
+		name             string
+		hideOpener       bool
+		stream           string // "seekable" or "unseekable" to load the content with a StreamLoader.
+		version          string
+		header           map[string]string
+		wantStatus       int
+		wantBody         string
+		wantContentRange string
+		wantAcceptRanges string
+	}{
+		{
+			name:             "full content",
+			wantStatus:       http.StatusOK,
+			wantBody:         content,
+			wantAcceptRanges: "bytes",
+		},
+		{
+			name:             "partial range",
+			header:           map[string]string{"Range": "bytes=2-5"},
+			wantStatus:       http.StatusPartialContent,
+			wantBody:         "2345",
+			wantContentRange: "bytes 2-5/10",
+			wantAcceptRanges: "bytes",
+		},
+		{
+			name:             "invalid range",
+			header:           map[string]string{"Range": "bytes=20-30"},
+			wantStatus:       http.StatusRequestedRangeNotSatisfiable,
+			wantContentRange: "bytes */10",
+		},
+		{
+			name:       "not modified",
+			header:     map[string]string{"If-None-Match": `"v2"`},
+			wantStatus: http.StatusNotModified,
+		},
+		{
+			name:             "modified",
+			header:           map[string]string{"If-None-Match": `"v1"`},
+			wantStatus:       http.StatusOK,
+			wantBody:         content,
+			wantAcceptRanges: "bytes",
+		},
+		{
+			name:             "older version",
+			version:          "1",
+			wantStatus:       http.StatusOK,
+			wantBody:         "old",
+			wantAcceptRanges: "bytes",
+		},
+		{
+			name:             "without opener",
+			hideOpener:       true,
+			header:           map[string]string{"Range": "bytes=2-5"},
+			wantStatus:       http.StatusOK,
+			wantBody:         content,
+			wantAcceptRanges: "none",
+		},
+		{
+			name:             "without opener not modified",
+			hideOpener:       true,
+			header:           map[string]string{"If-None-Match": `W/"v0", "v2"`},
+			wantStatus:       http.StatusNotModified,
+			wantAcceptRanges: "none",
+		},
+		{
+			name:             "seekable stream",
+			stream:           "seekable",
+			header:           map[string]string{"Range": "bytes=2-5"},
+			wantStatus:       http.StatusPartialContent,
+			wantBody:         "2345",
+			wantContentRange: "bytes 2-5/10",
+			wantAcceptRanges: "bytes",
+		},
+		{
+			name:             "unseekable stream",
+			stream:           "unseekable",
+			header:           map[string]string{"Range": "bytes=2-5"},
+			wantStatus:       http.StatusOK,
+			wantBody:         content,
+			wantAcceptRanges: "none",
+		},
+		{
+			name:             "unseekable stream older version",
+			stream:           "unseekable",
+			version:          "1",
+			wantStatus:       http.StatusOK,
+			wantBody:         "old",
+			wantAcceptRanges: "none",
+		},
+		{
+			name:             "unseekable stream not modified",
+			stream:           "unseekable",
+			header:           map[string]string{"If-None-Match": `"v2"`},
+			wantStatus:       http.StatusNotModified,
+			wantAcceptRanges: "none",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var artifactService artifact.Service = artifact.InMemoryService()
+			for _, data := range []string{"old", content} {
+				_, err := artifactService.Save(t.Context(), &artifact.SaveRequest{
+					AppName: "testApp", UserID: "testUser", SessionID: "testSession", FileName: "audio.wav",
+					Part: genai.NewPartFromBytes([]byte(data), "audio/wav"),
+				})
+				if err != nil {
+					t.Fatalf("Save() failed: %v", err)
+				}
+			}
+			if tt.hideOpener {
+				artifactService = serviceOnly{artifactService}
+			}
+			if tt.stream != "" {
+				artifactService = streamOnly{Service: artifactService, seekable: tt.stream == "seekable"}
+			}
+			apiController := controllers.NewArtifactsAPIController(artifactService, controllers.ArtifactsAPIOptions{})
+
+			vars := map[string]string{"app_name": "testApp", "user_id": "testUser", "session_id": "testSession", "artifact_name": "audio.wav"}
+			req := httptest.NewRequest(http.MethodGet, "/apps/testApp/users/testUser/sessions/testSession/artifacts/audio.wav?alt=media", nil)
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			rr := httptest.NewRecorder()
+			if tt.version != "" {
+				vars["version"] = tt.version
+				apiController.LoadArtifactVersionHandler(rr, mux.SetURLVars(req, vars))
+			} else {
+				apiController.LoadArtifactHandler(rr, mux.SetURLVars(req, vars))
+			}
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, tt.wantStatus)
+			}
+			if got := rr.Body.String(); tt.wantBody != "" && got != tt.wantBody {
+				t.Errorf("body = %q, want %q", got, tt.wantBody)
+			}
+			if got := rr.Header().Get("Content-Range"); got != tt.wantContentRange {
+				t.Errorf("Content-Range = %q, want %q", got, tt.wantContentRange)
+			}
+			if got := rr.Header().Get("Accept-Ranges"); got != tt.wantAcceptRanges {
+				t.Errorf("Accept-Ranges = %q, want %q", got, tt.wantAcceptRanges)
+			}
+			if got, want := rr.Header().Get("Content-Length"), fmt.Sprint(len(tt.wantBody)); rr.Code == http.StatusOK && got != want {
+				t.Errorf("Content-Length = %q, want %q", got, want)
+			}
+			if rr.Code == http.StatusOK || rr.Code == http.StatusPartialContent {
+				if got, want := rr.Header().Get("Content-Type"), "audio/wav"; got != want {
+					t.Errorf("Content-Type = %q, want %q", got, want)
+				}
+				wantETag := `"v2"`
+				if tt.version != "" {
+					wantETag = `"v` + tt.version + `"`
+				}
+				if got := rr.Header().Get("ETag"); got != wantETag {
+					t.Errorf("ETag = %q, want %q", got, wantETag)
+				}
+			}
+		})
+	}
+
+	t.Run("not found", func(t *testing.T) {
+		apiController := controllers.NewArtifactsAPIController(artifact.InMemoryService(), controllers.ArtifactsAPIOptions{})
+		vars := map[string]string{"app_name": "testApp", "user_id": "testUser", "session_id": "testSession", "artifact_name": "missing.wav"}
+		req := httptest.NewRequest(http.MethodGet, "/apps/testApp/users/testUser/sessions/testSession/artifacts/missing.wav?alt=media", nil)
+		rr := httptest.NewRecorder()
+		apiController.LoadArtifactHandler(rr, mux.SetURLVars(req, vars))
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusNotFound)
+		}
+	})
+}
@@ -15,8 +15,16 @@
 package controllers
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"mime/multipart"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 
@@ -24,13 +32,38 @@ import (
 	"google.golang.org/adk/server/adkrest/internal/models"
 )
 
+// DefaultMaxArtifactUploadSize is the default size limit of the artifact
+// upload requests.
+const DefaultMaxArtifactUploadSize = 100 << 20
+
+// DefaultSignedURLTTL is the validity of the signed artifact URLs when the
+// request doesn't set it.
+const DefaultSignedURLTTL = 10 * time.Minute
+
 // ArtifactsAPIController is the controller for the Artifacts API.
 type ArtifactsAPIController struct {
 	artifactService artifact.Service
+	opts            ArtifactsAPIOptions
 }
 
-func NewArtifactsAPIController(artifactService artifact.Service) *ArtifactsAPIController {
-	return &ArtifactsAPIController{artifactService: artifactService}
+// ArtifactsAPIOptions contains optional settings of the Artifacts API
+// controller. The zero value is valid and uses defaults.
+type ArtifactsAPIOptions struct {
+	// MaxUploadSize is the maximum size in bytes of an upload request body.
+	// Optional: if zero, [DefaultMaxArtifactUploadSize] is used.
+	MaxUploadSize int64
+	// AllowedContentTypes are the media types accepted by the upload
+	// endpoint, e.g. "application/pdf". A "type/*" entry accepts all the
+	// subtypes of type.
+	// Optional: if empty, all content types are accepted.
+	AllowedContentTypes []string
+}
+
+func NewArtifactsAPIController(artifactService artifact.Service, opts ArtifactsAPIOptions) *ArtifactsAPIController {
+	if opts.MaxUploadSize == 0 {
+		opts.MaxUploadSize = DefaultMaxArtifactUploadSize
+	}
+	return &ArtifactsAPIController{artifactService: artifactService, opts: opts}
 }
 
 // ListArtifactsHandler lists all the artifact filenames within a session.
@@ -38,11 +71,11 @@ func (c *ArtifactsAPIController) ListArtifactsHandler(rw http.ResponseWriter, re
 	vars := mux.Vars(req)
 	sessionID, err := models.SessionIDFromHTTPParameters(vars)
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusBadRequest)
+		httpError(rw, req, err.Error(), http.StatusBadRequest)
 		return
 	}
 	if sessionID.ID == "" {
-		http.Error(rw, "session_id parameter is required", http.StatusBadRequest)
+		httpError(rw, req, "session_id parameter is required", http.StatusBadRequest)
 		return
 	}
 	resp, err := c.artifactService.List(req.Context(), &artifact.ListRequest{
@@ -51,7 +84,7 @@ func (c *ArtifactsAPIController) ListArtifactsHandler(rw http.ResponseWriter, re
 		SessionID: sessionID.ID,
 	})
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		httpError(rw, req, err.Error(), http.StatusInternalServerError)
 		return
 	}
 	files := resp.FileNames
@@ -62,20 +95,22 @@ func (c *ArtifactsAPIController) ListArtifactsHandler(rw http.ResponseWriter, re
 }
 
 // LoadArtifactHandler gets an artifact from the artifact service storage.
+// The artifact is returned as a JSON part, or as its raw content if the alt
+// query parameter is "media", see [ArtifactsAPIController.serveArtifactContent].
 func (c *ArtifactsAPIController) LoadArtifactHandler(rw http.ResponseWriter, req *http.Request) {
 	vars := mux.Vars(req)
 	sessionID, err := models.SessionIDFromHTTPParameters(vars)
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusBadRequest)
+		httpError(rw, req, err.Error(), http.StatusBadRequest)
 		return
 	}
 	if sessionID.ID == "" {
-		http.Error(rw, "session_id parameter is required", http.StatusBadRequest)
+		httpError(rw, req, "session_id parameter is required", http.StatusBadRequest)
 		return
 	}
 	artifactName := vars["artifact_name"]
 	if artifactName == "" {
-		http.Error(rw, "artifact_name parameter is required", http.StatusBadRequest)
+		httpError(rw, req, "artifact_name parameter is required", http.StatusBadRequest)
 		return
 	}
 	loadReq := &artifact.LoadRequest{
@@ -90,47 +125,53 @@ func (c *ArtifactsAPIController) LoadArtifactHandler(rw http.ResponseWriter, req
 	if version != "" {
 		versionInt, err := strconv.Atoi(version)
 		if err != nil {
-			http.Error(rw, "version parameter must be an integer", http.StatusBadRequest)
+			httpError(rw, req, "version parameter must be an integer", http.StatusBadRequest)
 			return
 		}
 		loadReq.Version = int64(versionInt)
 	}
 
+	if req.URL.Query().Get("alt") == "media" {
+		c.serveArtifactContent(rw, req, loadReq)
+		return
+	}
 	resp, err := c.artifactService.Load(req.Context(), loadReq)
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		httpError(rw, req, err.Error(), http.StatusInternalServerError)
 		return
 	}
 	EncodeJSONResponse(resp.Part, http.StatusOK, rw)
 }
 
 // LoadArtifactVersionHandler gets an artifact from the artifact service storage with specified version.
+// Like [ArtifactsAPIController.LoadArtifactHandler], it returns the raw content if the alt query
+// parameter is "media".
 func (c *ArtifactsAPIController) LoadArtifactVersionHandler(rw http.ResponseWriter, req *http.Request) {
 	vars := mux.Vars(req)
 	sessionID, err := models.SessionIDFromHTTPParameters(vars)
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusBadRequest)
+		httpError(rw, req, err.Error(), http.StatusBadRequest)
 		return
 	}
 	if sessionID.ID == "" {
-		http.Error(rw, "session_id parameter is required", http.StatusBadRequest)
+		httpError(rw, req, "session_id parameter is required", http.StatusBadRequest)
 		return
 	}
 	artifactName := vars["artifact_name"]
 	if artifactName == "" {
-		http.Error(rw, "artifact_name parameter is required", http.StatusBadRequest)
+		httpError(rw, req, "artifact_name parameter is required", http.StatusBadRequest)
 		return
 	}
 	version := vars["version"]
 
 	if version == "" {
-		http.Error(rw, "version parameter is required", http.StatusBadRequest)
+		httpError(rw, req, "version parameter is required", http.StatusBadRequest)
 		return
 	}
 
 	versionInt, err := strconv.Atoi(version)
 	if err != nil {
-		http.Error(rw, "version parameter must be an integer", http.StatusBadRequest)
+		httpError(rw, req, "version parameter must be an integer", http.StatusBadRequest)
 		return
 	}
 
@@ -142,29 +183,104 @@ func (c *ArtifactsAPIController) LoadArtifactVersionHandler(rw http.ResponseWrit
 		Version:   int64(versionInt),
 	}
 
+	if req.URL.Query().Get("alt") == "media" {
+		c.serveArtifactContent(rw, req, loadReq)
+		return
+	}
 	resp, err := c.artifactService.Load(req.Context(), loadReq)
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		httpError(rw, req, err.Error(), http.StatusInternalServerError)
 		return
 	}
 	EncodeJSONResponse(resp.Part, http.StatusOK, rw)
 }
 
+// SignArtifactURLHandler returns a signed URL downloading an artifact
+// version, by default the latest one, directly from the artifact store. The
+// ttl query parameter sets the validity of the URL in seconds, which
+// defaults to [DefaultSignedURLTTL]. The endpoint responds with 501 status
+// code if the artifact service doesn't implement [artifact.URLSigner].
+func (c *ArtifactsAPIController) SignArtifactURLHandler(rw http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	sessionID, err := models.SessionIDFromHTTPParameters(vars)
+	if err != nil {
+		httpError(rw, req, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if sessionID.ID == "" {
+		httpError(rw, req, "session_id parameter is required", http.StatusBadRequest)
+		return
+	}
+	artifactName := vars["artifact_name"]
+	if artifactName == "" {
+		httpError(rw, req, "artifact_name parameter is required", http.StatusBadRequest)
+		return
+	}
+	signReq := &artifact.SignURLRequest{
+		AppName:   sessionID.AppName,
+		UserID:    sessionID.UserID,
+		SessionID: sessionID.ID,
+		FileName:  artifactName,
+		TTL:       DefaultSignedURLTTL,
+	}
+
+	queryParams := req.URL.Query()
+	if version := queryParams.Get("version"); version != "" {
+		versionInt, err := strconv.Atoi(version)
+		if err != nil {
+			httpError(rw, req, "version parameter must be an integer", http.StatusBadRequest)
+			return
+		}
+		signReq.Version = int64(versionInt)
+	}
+	if ttl := queryParams.Get("ttl"); ttl != "" {
+		seconds, err := strconv.Atoi(ttl)
+		if err != nil || seconds <= 0 || time.Duration(seconds) > artifact.MaxSignedURLTTL/time.Second {
+			httpError(rw, req, fmt.Sprintf("ttl parameter must be a positive number of seconds, at most %d", int(artifact.MaxSignedURLTTL.Seconds())), http.StatusBadRequest)
+			return
+		}
+		signReq.TTL = time.Duration(seconds) * time.Second
+	}
+	if err := signReq.Validate(); err != nil {
+		httpError(rw, req, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resp, err := artifact.SignURL(req.Context(), c.artifactService, signReq)
+	switch {
+	case errors.Is(err, errors.ErrUnsupported):
+		httpError(rw, req, "the artifact service doesn't sign URLs", http.StatusNotImplemented)
+		return
+	case errors.Is(err, fs.ErrNotExist):
+		httpError(rw, req, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		httpError(rw, req, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	EncodeJSONResponse(models.ArtifactSignedURL{
+		Name:      artifactName,
+		Version:   resp.Version,
+		URL:       resp.URL,
+		ExpiresAt: resp.Expires,
+	}, http.StatusOK, rw)
+}
+
 // DeleteArtifactHandler handles deleting an artifact.
 func (c *ArtifactsAPIController) DeleteArtifactHandler(rw http.ResponseWriter, req *http.Request) {
 	vars := mux.Vars(req)
 	sessionID, err := models.SessionIDFromHTTPParameters(vars)
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusBadRequest)
+		httpError(rw, req, err.Error(), http.StatusBadRequest)
 		return
 	}
 	if sessionID.ID == "" {
-		http.Error(rw, "session_id parameter is required", http.StatusBadRequest)
+		httpError(rw, req, "session_id parameter is required", http.StatusBadRequest)
 		return
 	}
 	artifactName := vars["artifact_name"]
 	if artifactName == "" {
-		http.Error(rw, "artifact_name parameter is required", http.StatusBadRequest)
+		httpError(rw, req, "artifact_name parameter is required", http.StatusBadRequest)
 		return
 	}
 	err = c.artifactService.Delete(req.Context(), &artifact.DeleteRequest{
@@ -174,8 +290,141 @@ func (c *ArtifactsAPIController) DeleteArtifactHandler(rw http.ResponseWriter, r
 		FileName:  artifactName,
 	})
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		httpError(rw, req, err.Error(), http.StatusInternalServerError)
 		return
 	}
 	EncodeJSONResponse(nil, http.StatusOK, rw)
 }
+
+// UploadArtifactHandler saves the "file" part of a multipart/form-data request
+// as a new version of an artifact. The artifact is named after the file name
+// of the part, unless the name query parameter is set. The content is
+// streamed to the artifact service if it implements [artifact.StreamSaver].
+func (c *ArtifactsAPIController) UploadArtifactHandler(rw http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	sessionID, err := models.SessionIDFromHTTPParameters(vars)
+	if err != nil {
+		httpError(rw, req, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if sessionID.ID == "" {
+		httpError(rw, req, "session_id parameter is required", http.StatusBadRequest)
+		return
+	}
+
+	req.Body = http.MaxBytesReader(rw, req.Body, c.opts.MaxUploadSize)
+	mr, err := req.MultipartReader()
+	if err != nil {
+		httpError(rw, req, fmt.Sprintf("request must be multipart/form-data: %v", err), http.StatusUnsupportedMediaType)
+		return
+	}
+	part, err := nextFilePart(mr)
+	if err != nil {
+		writeError(rw, req, uploadError(err))
+		return
+	}
+	defer part.Close()
+
+	name := req.URL.Query().Get("name")
+	if name == "" {
+		name = part.FileName()
+	}
+	mimeType := part.Header.Get("Content-Type")
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+	if !c.contentTypeAllowed(mimeType) {
+		httpError(rw, req, fmt.Sprintf("content type %q is not allowed", mimeType), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	content := &countingReader{r: part}
+	saveReq := &artifact.SaveStreamRequest{
+		AppName:   sessionID.AppName,
+		UserID:    sessionID.UserID,
+		SessionID: sessionID.ID,
+		FileName:  name,
+		MIMEType:  mimeType,
+		Content:   content,
+	}
+	if err := saveReq.Validate(); err != nil {
+		httpError(rw, req, err.Error(), http.StatusBadRequest)
+		return
+	}
+	resp, err := artifact.SaveStream(req.Context(), c.artifactService, saveReq)
+	if err != nil {
+		writeError(rw, req, uploadError(err))
+		return
+	}
+	EncodeJSONResponse(models.ArtifactUpload{
+		Name:     name,
+		Version:  resp.Version,
+		MIMEType: mimeType,
+		Size:     content.n,
+	}, http.StatusOK, rw)
+}
+
+// nextFilePart skips the parts of the form until the "file" one.
+func nextFilePart(mr *multipart.Reader) (*multipart.Part, error) {
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil, newStatusError(errors.New(`multipart form must contain a "file" part`), http.StatusBadRequest)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if part.FormName() == "file" {
+			return part, nil
+		}
+		part.Close()
+	}
+}
+
+// uploadError reports the errors caused by an oversized body or an exceeded
+// artifact quota with 413 status code, and the other ones with 500.
+func uploadError(err error) error {
+	if _, ok := err.(statusError); ok {
+		return err
+	}
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return newStatusError(fmt.Errorf("upload exceeds the maximum size of %d bytes", maxBytesErr.Limit), http.StatusRequestEntityTooLarge)
+	}
+	if errors.Is(err, artifact.ErrQuotaExceeded) {
+		return newStatusError(err, http.StatusRequestEntityTooLarge)
+	}
+	return newStatusError(fmt.Errorf("failed to upload artifact: %w", err), http.StatusInternalServerError)
+}
+
+func (c *ArtifactsAPIController) contentTypeAllowed(contentType string) bool {
+	if len(c.opts.AllowedContentTypes) == 0 {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, allowed := range c.opts.AllowedContentTypes {
+		allowed = strings.ToLower(allowed)
+		if allowed == mediaType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(allowed, "/*"); ok && strings.HasPrefix(mediaType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// countingReader counts the bytes read from r.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += int64(n)
+	return n, err
+}
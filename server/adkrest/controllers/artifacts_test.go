@@ -0,0 +1,304 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/gorilla/mux"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/artifact"
+	"google.golang.org/adk/server/adkrest/controllers"
+	"google.golang.org/adk/server/adkrest/internal/models"
+)
+
+func TestUploadArtifactHandler(t *testing.T) {
+	content := bytes.Repeat([]byte("%PDF-1.7\n"), 100)
+
+	tests := []struct {
+		name       string
+		opts       controllers.ArtifactsAPIOptions
+		quota      artifact.QuotaLimits
+		query      string
+		body       func(t *testing.T) (*bytes.Buffer, string)
+		wantStatus int
+		want       models.ArtifactUpload
+	}{
+		{
+			name:       "upload",
+			body:       multipartBody("file", "report.pdf", "application/pdf", content),
+			wantStatus: http.StatusOK,
+			want:       models.ArtifactUpload{Name: "report.pdf", Version: 1, MIMEType: "application/pdf", Size: int64(len(content))},
+		},
+		{
+			name:       "name from query",
+			query:      "?name=renamed.pdf",
+			body:       multipartBody("file", "report.pdf", "application/pdf", content),
+			wantStatus: http.StatusOK,
+			want:       models.ArtifactUpload{Name: "renamed.pdf", Version: 1, MIMEType: "application/pdf", Size: int64(len(content))},
+		},
+		{
+			name:       "allowed content type",
+			opts:       controllers.ArtifactsAPIOptions{AllowedContentTypes: []string{"image/*", "application/pdf"}},
+			body:       multipartBody("file", "report.pdf", "application/pdf", content),
+			wantStatus: http.StatusOK,
+			want:       models.ArtifactUpload{Name: "report.pdf", Version: 1, MIMEType: "application/pdf", Size: int64(len(content))},
+		},
+		{
+			name:       "content type not allowed",
+			opts:       controllers.ArtifactsAPIOptions{AllowedContentTypes: []string{"image/*"}},
+			body:       multipartBody("file", "report.pdf", "application/pdf", content),
+			wantStatus: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:       "too large",
+			opts:       controllers.ArtifactsAPIOptions{MaxUploadSize: 100},
+			body:       multipartBody("file", "report.pdf", "application/pdf", content),
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:       "quota exceeded",
+			quota:      artifact.QuotaLimits{MaxBytes: 100},
+			body:       multipartBody("file", "report.pdf", "application/pdf", content),
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:       "missing file part",
+			body:       multipartBody("other", "report.pdf", "application/pdf", content),
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid name",
+			query:      "?name=a/b",
+			body:       multipartBody("file", "report.pdf", "application/pdf", content),
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "not multipart",
+			body: func(t *testing.T) (*bytes.Buffer, string) {
+				return bytes.NewBuffer(content), "application/pdf"
+			},
+			wantStatus: http.StatusUnsupportedMediaType,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			artifactService, err := artifact.WithQuota(artifact.InMemoryService(), tt.quota)
+			if err != nil {
+				t.Fatalf("WithQuota() failed: %v", err)
+			}
+			apiController := controllers.NewArtifactsAPIController(artifactService, tt.opts)
+			vars := map[string]string{"app_name": "testApp", "user_id": "testUser", "session_id": "testSession"}
+
+			body, contentType := tt.body(t)
+			req := httptest.NewRequest(http.MethodPost, "/apps/testApp/users/testUser/sessions/testSession/artifacts"+tt.query, body)
+			req.Header.Set("Content-Type", contentType)
+			req = mux.SetURLVars(req, vars)
+			rr := httptest.NewRecorder()
+
+			apiController.UploadArtifactHandler(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v, body: %s", rr.Code, tt.wantStatus, rr.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var got models.ArtifactUpload
+			if err := json.NewDecoder(rr.Body).Decode(&got); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("UploadArtifactHandler() mismatch (-want +got):\n%s", diff)
+			}
+
+			vars["artifact_name"] = got.Name
+			req = mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/apps/testApp/users/testUser/sessions/testSession/artifacts/"+got.Name, nil), vars)
+			rr = httptest.NewRecorder()
+			apiController.LoadArtifactHandler(rr, req)
+			if rr.Code != http.StatusOK {
+				t.Fatalf("LoadArtifactHandler() status = %v, want %v", rr.Code, http.StatusOK)
+			}
+			var part genai.Part
+			if err := json.NewDecoder(rr.Body).Decode(&part); err != nil {
+				t.Fatalf("decode artifact: %v", err)
+			}
+			if part.InlineData == nil || !bytes.Equal(part.InlineData.Data, content) {
+				t.Errorf("LoadArtifactHandler() returned different content than uploaded")
+			}
+		})
+	}
+}
+
+// signingService signs fake URLs of the artifacts of the wrapped service.
+type signingService struct {
+	artifact.Service
+}
+
+func (s signingService) SignURL(ctx context.Context, req *artifact.SignURLRequest) (*artifact.SignURLResponse, error) {
+	version, err := artifact.SignedVersion(ctx, s, req)
+	if err != nil {
+		return nil, err
+	}
+	return &artifact.SignURLResponse{
+		Version: version,
+		URL:     fmt.Sprintf("https://storage.example.com/%s/%d?ttl=%d", req.FileName, version, int(req.TTL.Seconds())),
+		Expires: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC).Add(req.TTL),
+	}, nil
+}
+
+func TestSignArtifactURLHandler(t *testing.T) {
+	inMemory := artifact.InMemoryService()
+	for _, data := range []string{"one", "two"} {
+		if _, err := inMemory.Save(t.Context(), &artifact.SaveRequest{
+			AppName: "testApp", UserID: "testUser", SessionID: "testSession", FileName: "report.pdf",
+			Part: genai.NewPartFromBytes([]byte(data), "application/pdf"),
+		}); err != nil {
+			t.Fatalf("Save() failed: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name         string
+		service      artifact.Service
+		artifactName string
+		query        string
+		wantStatus   int
+		want         models.ArtifactSignedURL
+	}{
+		{
+			name:         "latest",
+			service:      signingService{inMemory},
+			artifactName: "report.pdf",
+			wantStatus:   http.StatusOK,
+			want: models.ArtifactSignedURL{
+				Name: "report.pdf", Version: 2, URL: "https://storage.example.com/report.pdf/2?ttl=600",
+				ExpiresAt: time.Date(2025, 1, 1, 0, 10, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:         "version and ttl",
+			service:      signingService{inMemory},
+			artifactName: "report.pdf",
+			query:        "?version=1&ttl=60",
+			wantStatus:   http.StatusOK,
+			want: models.ArtifactSignedURL{
+				Name: "report.pdf", Version: 1, URL: "https://storage.example.com/report.pdf/1?ttl=60",
+				ExpiresAt: time.Date(2025, 1, 1, 0, 1, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:         "missing artifact",
+			service:      signingService{inMemory},
+			artifactName: "missing.pdf",
+			wantStatus:   http.StatusNotFound,
+		},
+		{
+			name:         "missing version",
+			service:      signingService{inMemory},
+			artifactName: "report.pdf",
+			query:        "?version=3",
+			wantStatus:   http.StatusNotFound,
+		},
+		{
+			name:         "invalid ttl",
+			service:      signingService{inMemory},
+			artifactName: "report.pdf",
+			query:        "?ttl=0",
+			wantStatus:   http.StatusBadRequest,
+		},
+		{
+			name:         "ttl too long",
+			service:      signingService{inMemory},
+			artifactName: "report.pdf",
+			query:        "?ttl=604801",
+			wantStatus:   http.StatusBadRequest,
+		},
+		{
+			name:         "invalid version",
+			service:      signingService{inMemory},
+			artifactName: "report.pdf",
+			query:        "?version=latest",
+			wantStatus:   http.StatusBadRequest,
+		},
+		{
+			name:         "unsupported",
+			service:      inMemory,
+			artifactName: "report.pdf",
+			wantStatus:   http.StatusNotImplemented,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiController := controllers.NewArtifactsAPIController(tt.service, controllers.ArtifactsAPIOptions{})
+			vars := map[string]string{"app_name": "testApp", "user_id": "testUser", "session_id": "testSession", "artifact_name": tt.artifactName}
+			req := httptest.NewRequest(http.MethodGet, "/apps/testApp/users/testUser/sessions/testSession/artifacts/"+tt.artifactName+":signedUrl"+tt.query, nil)
+			req = mux.SetURLVars(req, vars)
+			rr := httptest.NewRecorder()
+
+			apiController.SignArtifactURLHandler(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v, body: %s", rr.Code, tt.wantStatus, rr.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var got models.ArtifactSignedURL
+			if err := json.NewDecoder(rr.Body).Decode(&got); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("SignArtifactURLHandler() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func multipartBody(fieldName, fileName, contentType string, content []byte) func(t *testing.T) (*bytes.Buffer, string) {
+	return func(t *testing.T) (*bytes.Buffer, string) {
+		t.Helper()
+		body := &bytes.Buffer{}
+		w := multipart.NewWriter(body)
+		if err := w.WriteField("description", "quarterly report"); err != nil {
+			t.Fatalf("WriteField() failed: %v", err)
+		}
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Disposition", `form-data; name="`+fieldName+`"; filename="`+fileName+`"`)
+		header.Set("Content-Type", contentType)
+		part, err := w.CreatePart(header)
+		if err != nil {
+			t.Fatalf("CreatePart() failed: %v", err)
+		}
+		if _, err := part.Write(content); err != nil {
+			t.Fatalf("Write() failed: %v", err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("Close() failed: %v", err)
+		}
+		return body, w.FormDataContentType()
+	}
+}
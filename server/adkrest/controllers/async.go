@@ -0,0 +1,379 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/server/adkrest/internal/models"
+	"google.golang.org/adk/session"
+)
+
+const (
+	// RunModeSync is the default mode of the run endpoint: the response
+	// carries the events of the run.
+	RunModeSync = "sync"
+	// RunModeAsync is the mode of the run endpoint in which the run continues
+	// in the background. The response is sent as soon as the invocation has
+	// started, and the events are posted to the callback URL of the request.
+	RunModeAsync = "async"
+)
+
+const defaultAsyncRetention = time.Hour
+
+// asyncRun is the state of a run started in async mode.
+type asyncRun struct {
+	inv *invocation
+	// webhook delivers the callbacks, it is nil if the run has no callback URL.
+	webhook *webhook
+
+	mu           sync.Mutex
+	state        string
+	errorCode    string
+	errorMessage string
+	finishedAt   time.Time
+}
+
+func (r *asyncRun) finish(state, errorCode, errorMessage string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.state, r.errorCode, r.errorMessage = state, errorCode, errorMessage
+	r.finishedAt = time.Now()
+}
+
+// callback returns the callback describing the current state of the run.
+func (r *asyncRun) callback() models.InvocationCallback {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return models.InvocationCallback{
+		InvocationID: r.inv.ID(),
+		State:        r.state,
+		ErrorCode:    r.errorCode,
+		ErrorMessage: r.errorMessage,
+	}
+}
+
+func (r *asyncRun) status() models.InvocationStatus {
+	cb := r.callback()
+	status := models.InvocationStatus{
+		InvocationID: cb.InvocationID,
+		State:        cb.State,
+		ErrorCode:    cb.ErrorCode,
+		ErrorMessage: cb.ErrorMessage,
+	}
+	if r.webhook != nil {
+		status.Deliveries = r.webhook.status()
+	}
+	return status
+}
+
+// isExpired reports whether the run finished more than retention ago.
+func (r *asyncRun) isExpired(now time.Time, retention time.Duration) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return !r.finishedAt.IsZero() && now.Sub(r.finishedAt) > retention
+}
+
+// asyncRegistry keeps the status of the async runs, for some time after they
+// finish.
+type asyncRegistry struct {
+	mu   sync.Mutex
+	runs map[invocationKey]*asyncRun
+}
+
+// add registers a run, and forgets the ones which finished more than
+// retention ago.
+func (r *asyncRegistry) add(key invocationKey, run *asyncRun, retention time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.runs == nil {
+		r.runs = make(map[invocationKey]*asyncRun)
+	}
+	now := time.Now()
+	for k, old := range r.runs {
+		if old.isExpired(now, retention) {
+			delete(r.runs, k)
+		}
+	}
+	r.runs[key] = run
+}
+
+func (r *asyncRegistry) get(key invocationKey) (*asyncRun, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	run, ok := r.runs[key]
+	return run, ok
+}
+
+// runAsync starts the run in the background, so that it outlives the
+// request. It replies with 202 status code once the invocation has started.
+func (c *RuntimeAPIController) runAsync(rw http.ResponseWriter, req *http.Request, runAgentRequest models.RunAgentRequest) error {
+	if err := c.validateCallback(runAgentRequest); err != nil {
+		return err
+	}
+	// The run only keeps the values of the request context, e.g. its logger.
+	bgReq := req.WithContext(context.WithoutCancel(req.Context()))
+	ctx, done, err := c.startRun(bgReq, runAgentRequest)
+	if err != nil {
+		return err
+	}
+	r, rCfg, err := c.prepareAsyncRun(ctx, runAgentRequest)
+	if err != nil {
+		done()
+		return err
+	}
+
+	logger := responseLogger(rw, req)
+	inv := invocationFromContext(ctx)
+	run := &asyncRun{inv: inv, state: models.InvocationStateRunning}
+	if runAgentRequest.CallbackURL != "" {
+		run.webhook = newWebhook(runAgentRequest.CallbackURL, c.opts.Webhooks, logger)
+		// The deliveries are tracked as a run of their own, so that shutdown
+		// waits for them.
+		hookCtx, hookDone, err := c.runs.start(bgReq.Context())
+		if err != nil {
+			done()
+			return err
+		}
+		go func() {
+			defer hookDone()
+			defer func() {
+				// A panic, e.g. of a custom HTTP client, gives up on the
+				// callbacks but not on the server.
+				if v := recover(); v != nil {
+					logger.Error("ADK: callback delivery panicked", "invocation_id", inv.ID(), "panic", v, "stack", string(debug.Stack()))
+					run.webhook.stop(ErrInternal)
+				}
+			}()
+			run.webhook.run(hookCtx)
+		}()
+	}
+
+	ended := make(chan struct{})
+	var runErr error
+	go func() {
+		defer close(ended)
+		defer done()
+		defer func() {
+			if v := recover(); v != nil {
+				runErr = recoverAsyncRun(run, v, logger)
+			}
+		}()
+		runErr = c.runInBackground(ctx, r, rCfg, runAgentRequest, run, logger)
+	}()
+
+	select {
+	case <-inv.startSignal:
+	case <-ended:
+		select {
+		case <-inv.startSignal:
+		default:
+			// The run failed before its invocation started, nothing runs in the background.
+			if runErr == nil {
+				runErr = errors.New("run ended before its invocation started")
+			}
+			return newStatusError(fmt.Errorf("failed to run agent: %w", runErr), http.StatusInternalServerError)
+		}
+	}
+	key := inv.invocationKey()
+	c.async.add(key, run, c.opts.AsyncRetention)
+
+	addLogAttrs(rw, "invocation_id", key.invocationID)
+	rw.Header().Set("Location", invocationStatusPath(key))
+	EncodeJSONResponse(run.status(), http.StatusAccepted, rw)
+	return nil
+}
+
+// validateCallback checks the callback settings of an async run.
+func (c *RuntimeAPIController) validateCallback(runAgentRequest models.RunAgentRequest) error {
+	switch runAgentRequest.CallbackMode {
+	case "", models.CallbackModeEvents, models.CallbackModeTerminal:
+	default:
+		return newStatusError(fmt.Errorf("invalid callbackMode %q: must be %q or %q", runAgentRequest.CallbackMode, models.CallbackModeEvents, models.CallbackModeTerminal), http.StatusBadRequest)
+	}
+	if runAgentRequest.CallbackURL == "" {
+		return nil
+	}
+	if len(c.opts.Webhooks.Secret) == 0 {
+		return newStatusError(errors.New("callbacks are not enabled on this server"), http.StatusNotImplemented)
+	}
+	u, err := url.Parse(runAgentRequest.CallbackURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return newStatusError(fmt.Errorf("invalid callbackUrl %q: must be an absolute HTTP(S) URL", runAgentRequest.CallbackURL), http.StatusBadRequest)
+	}
+	if err := c.opts.Webhooks.checkURL(u); err != nil {
+		return newStatusError(fmt.Errorf("invalid callbackUrl %q: %w", runAgentRequest.CallbackURL, err), http.StatusBadRequest)
+	}
+	return nil
+}
+
+func (c *RuntimeAPIController) prepareAsyncRun(ctx context.Context, runAgentRequest models.RunAgentRequest) (*runner.Runner, *agent.RunConfig, error) {
+	if err := c.validateSessionExists(ctx, runAgentRequest.AppName, runAgentRequest.UserId, runAgentRequest.SessionId); err != nil {
+		return nil, nil, err
+	}
+	return c.getRunner(runAgentRequest)
+}
+
+// runInBackground runs the agent of an async run and posts the callbacks.
+// It returns the error which ended the run, if any.
+func (c *RuntimeAPIController) runInBackground(ctx context.Context, r *runner.Runner, rCfg *agent.RunConfig, runAgentRequest models.RunAgentRequest, run *asyncRun, logger *slog.Logger) error {
+	inv := invocationFromContext(ctx)
+	terminalOnly := runAgentRequest.CallbackMode == models.CallbackModeTerminal
+	var last *models.Event
+	var runErr error
+	for event, err := range r.Run(ctx, runAgentRequest.UserId, runAgentRequest.SessionId, &runAgentRequest.NewMessage, *rCfg) {
+		if err != nil {
+			runErr = err
+			break
+		}
+		inv.publish(event)
+		if event.Partial {
+			continue
+		}
+		ev := *event
+		if runAgentRequest.ExcludeThoughts {
+			ev, _ = withoutThoughts(ev)
+		}
+		e := models.FromSessionEvent(ev)
+		last = &e
+		if run.webhook != nil && !terminalOnly {
+			cb := run.callback()
+			cb.Event = last
+			run.webhook.enqueue(cb)
+		}
+	}
+
+	switch cause, interrupted := interruption(ctx); {
+	case interrupted:
+		run.finish(models.InvocationStateFailed, cause.ErrorCode(), cause.Error())
+		if err := c.recordInterruption(ctx, runAgentRequest.AppName, runAgentRequest.UserId, runAgentRequest.SessionId, inv.ID(), cause); err != nil {
+			logger.Error("ADK: failed to record interrupted invocation", "invocation_id", inv.ID(), "error", err)
+		}
+		runErr = cause
+	case runErr != nil:
+		run.finish(models.InvocationStateFailed, codeFromStatus(http.StatusInternalServerError), runErr.Error())
+		logger.Error("ADK: async run failed", "invocation_id", inv.ID(), "error", runErr)
+	default:
+		run.finish(models.InvocationStateCompleted, "", "")
+	}
+	if run.webhook != nil {
+		// The client is told about the runs which fail before their
+		// invocation starts by the response.
+		if inv.ID() != "" {
+			cb := run.callback()
+			if terminalOnly {
+				cb.Event = last
+			}
+			run.webhook.enqueue(cb)
+		}
+		run.webhook.close()
+	}
+	return runErr
+}
+
+// recoverAsyncRun reports the panic v of an async run, which nobody else
+// would recover: it is logged with its stack and recorded on the spans of
+// the invocation, the run fails with an [ErrInternal] error and the callback
+// receiver is told so. It returns the error of the run.
+func recoverAsyncRun(run *asyncRun, v any, logger *slog.Logger) error {
+	stack := string(debug.Stack())
+	logger.Error("ADK: async run panicked", "invocation_id", run.inv.ID(), "panic", v, "stack", stack)
+	run.inv.recordPanic(v, stack)
+	run.finish(models.InvocationStateFailed, "INTERNAL", ErrInternal.Error())
+	if run.webhook != nil {
+		if run.inv.ID() != "" {
+			run.webhook.enqueue(run.callback())
+		}
+		run.webhook.close()
+	}
+	return ErrInternal
+}
+
+// invocationStatusPath returns the path of the status of the invocation,
+// relative to the run endpoint.
+func invocationStatusPath(key invocationKey) string {
+	return fmt.Sprintf("apps/%s/users/%s/sessions/%s/invocations/%s",
+		url.PathEscape(key.appName), url.PathEscape(key.userID), url.PathEscape(key.sessionID), url.PathEscape(key.invocationID))
+}
+
+// GetInvocationHandler returns the status of an invocation. The status of
+// the async runs includes their callbacks, and is kept for a while after
+// they finish. The other invocations are only known to be running or to
+// have finished.
+func (c *RuntimeAPIController) GetInvocationHandler(rw http.ResponseWriter, req *http.Request) error {
+	params := mux.Vars(req)
+	sessionID, err := models.SessionIDFromHTTPParameters(params)
+	if err != nil {
+		return newStatusError(err, http.StatusBadRequest)
+	}
+	invocationID := params["invocation_id"]
+	if sessionID.ID == "" || invocationID == "" {
+		return newStatusError(errors.New("session_id and invocation_id parameters are required"), http.StatusBadRequest)
+	}
+	addLogAttrs(rw, "app_name", sessionID.AppName, "session_id", sessionID.ID, "invocation_id", invocationID)
+	key := invocationKey{
+		appName:      sessionID.AppName,
+		userID:       sessionID.UserID,
+		sessionID:    sessionID.ID,
+		invocationID: invocationID,
+	}
+
+	if run, ok := c.async.get(key); ok {
+		EncodeJSONResponse(run.status(), http.StatusOK, rw)
+		return nil
+	}
+	if _, ok := c.invocations.get(key); ok {
+		EncodeJSONResponse(models.InvocationStatus{InvocationID: invocationID, State: models.InvocationStateRunning}, http.StatusOK, rw)
+		return nil
+	}
+
+	resp, err := c.sessionService.Get(req.Context(), &session.GetRequest{
+		AppName:   sessionID.AppName,
+		UserID:    sessionID.UserID,
+		SessionID: sessionID.ID,
+	})
+	if err != nil {
+		return newStatusError(fmt.Errorf("failed to get session: %w", err), http.StatusNotFound)
+	}
+	var status *models.InvocationStatus
+	for event := range resp.Session.Events().All() {
+		if event.InvocationID != invocationID {
+			continue
+		}
+		if status == nil {
+			status = &models.InvocationStatus{InvocationID: invocationID, State: models.InvocationStateCompleted}
+		}
+		if event.Interrupted {
+			status.State, status.ErrorCode, status.ErrorMessage = models.InvocationStateFailed, event.ErrorCode, event.ErrorMessage
+		}
+	}
+	if status == nil {
+		return newStatusError(fmt.Errorf("invocation %q not found", invocationID), http.StatusNotFound)
+	}
+	EncodeJSONResponse(status, http.StatusOK, rw)
+	return nil
+}
@@ -0,0 +1,603 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/gorilla/mux"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/server/adkrest/controllers"
+	"google.golang.org/adk/server/adkrest/internal/models"
+	"google.golang.org/adk/session"
+)
+
+var webhookSecret = []byte("secret")
+
+// callbackReceiver records the callbacks posted to it. The first failures
+// requests are answered with failStatus.
+type callbackReceiver struct {
+	t          *testing.T
+	failures   int
+	failStatus int
+
+	mu        sync.Mutex
+	callbacks []models.InvocationCallback
+	received  chan struct{}
+}
+
+func newCallbackReceiver(t *testing.T, failures, failStatus int) (*callbackReceiver, *httptest.Server) {
+	r := &callbackReceiver{t: t, failures: failures, failStatus: failStatus, received: make(chan struct{}, 100)}
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	return r, srv
+}
+
+func (r *callbackReceiver) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		r.t.Errorf("ReadAll() failed: %v", err)
+		return
+	}
+	want := controllers.WebhookSignature(webhookSecret, req.Header.Get(controllers.WebhookTimestampHeader), body)
+	if got := req.Header.Get(controllers.WebhookSignatureHeader); !hmac.Equal([]byte(got), []byte(want)) {
+		r.t.Errorf("signature = %q, want %q", got, want)
+	}
+	if req.Header.Get(controllers.WebhookDeliveryHeader) == "" {
+		r.t.Errorf("%s header is missing", controllers.WebhookDeliveryHeader)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failures > 0 {
+		r.failures--
+		rw.WriteHeader(r.failStatus)
+		return
+	}
+	var cb models.InvocationCallback
+	if err := json.Unmarshal(body, &cb); err != nil {
+		r.t.Errorf("malformed callback %q: %v", body, err)
+	}
+	r.callbacks = append(r.callbacks, cb)
+	r.received <- struct{}{}
+}
+
+// callbackSummary is the part of a callback compared by the tests.
+type callbackSummary struct {
+	State string
+	Text  string
+}
+
+func summarize(callbacks []models.InvocationCallback) []callbackSummary {
+	var got []callbackSummary
+	for _, cb := range callbacks {
+		s := callbackSummary{State: cb.State}
+		if cb.Event != nil && cb.Event.Content != nil {
+			s.Text = cb.Event.Content.Parts[0].Text
+		}
+		got = append(got, s)
+	}
+	return got
+}
+
+func newAsyncTestServer(t *testing.T, a agent.Agent, webhooks controllers.WebhookOptions) *httptest.Server {
+	t.Helper()
+	sessionService := session.InMemoryService()
+	if _, err := sessionService.Create(context.Background(), &session.CreateRequest{AppName: a.Name(), UserID: "user", SessionID: "session"}); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	controller := controllers.NewRuntimeAPIControllerWithOptions(sessionService, nil, agent.NewSingleLoader(a), nil, 10*time.Second, runner.PluginConfig{}, controllers.RuntimeAPIOptions{
+		Webhooks: webhooks,
+	})
+	router := mux.NewRouter()
+	router.Methods(http.MethodPost).Path("/run").Handler(controllers.NewErrorHandler(controller.RunHandler))
+	router.Methods(http.MethodGet).Path("/apps/{app_name}/users/{user_id}/sessions/{session_id}/invocations/{invocation_id}").Handler(controllers.NewErrorHandler(controller.GetInvocationHandler))
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func postAsyncRun(t *testing.T, srv *httptest.Server, appName, mode string, runAgentRequest models.RunAgentRequest) *http.Response {
+	t.Helper()
+	runAgentRequest.AppName = appName
+	runAgentRequest.UserId = "user"
+	runAgentRequest.SessionId = "session"
+	runAgentRequest.NewMessage = *genai.NewContentFromText("hi", genai.RoleUser)
+	body, err := json.Marshal(runAgentRequest)
+	if err != nil {
+		t.Fatalf("json.Marshal() failed: %v", err)
+	}
+	resp, err := http.Post(srv.URL+"/run?mode="+mode, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("Post() failed: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func getJSON(t *testing.T, url string, v any) {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET %s: status = %d, want %d", url, resp.StatusCode, http.StatusOK)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("Decode() failed: %v", err)
+	}
+}
+
+// waitDeliveries polls the status of the invocation until none of its
+// callbacks is pending.
+func waitDeliveries(t *testing.T, statusURL string) models.InvocationStatus {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var status models.InvocationStatus
+		getJSON(t, statusURL, &status)
+		pending := status.State == models.InvocationStateRunning
+		for _, d := range status.Deliveries {
+			pending = pending || d.State == models.DeliveryStatePending
+		}
+		if !pending {
+			return status
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("callbacks are still pending: %+v", status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestRunHandler_Async(t *testing.T) {
+	tc := []struct {
+		name           string
+		callbackMode   string
+		failures       int
+		failStatus     int
+		wantCallbacks  []callbackSummary
+		wantDeliveries []string
+		wantAttempts   int
+	}{
+		{
+			name: "each final event",
+			wantCallbacks: []callbackSummary{
+				{State: models.InvocationStateRunning, Text: "one"},
+				{State: models.InvocationStateRunning, Text: "two"},
+				{State: models.InvocationStateCompleted},
+			},
+			wantDeliveries: []string{models.DeliveryStateDelivered, models.DeliveryStateDelivered, models.DeliveryStateDelivered},
+			wantAttempts:   1,
+		},
+		{
+			name:           "terminal event only",
+			callbackMode:   models.CallbackModeTerminal,
+			wantCallbacks:  []callbackSummary{{State: models.InvocationStateCompleted, Text: "two"}},
+			wantDeliveries: []string{models.DeliveryStateDelivered},
+			wantAttempts:   1,
+		},
+		{
+			name:           "retried after server errors",
+			callbackMode:   models.CallbackModeTerminal,
+			failures:       2,
+			failStatus:     http.StatusServiceUnavailable,
+			wantCallbacks:  []callbackSummary{{State: models.InvocationStateCompleted, Text: "two"}},
+			wantDeliveries: []string{models.DeliveryStateDelivered},
+			wantAttempts:   3,
+		},
+		{
+			name:           "attempts exhausted",
+			callbackMode:   models.CallbackModeTerminal,
+			failures:       10,
+			failStatus:     http.StatusInternalServerError,
+			wantDeliveries: []string{models.DeliveryStateFailed},
+			wantAttempts:   3,
+		},
+		{
+			name:           "client errors are not retried",
+			callbackMode:   models.CallbackModeTerminal,
+			failures:       10,
+			failStatus:     http.StatusNotFound,
+			wantDeliveries: []string{models.DeliveryStateFailed},
+			wantAttempts:   1,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver, callbackSrv := newCallbackReceiver(t, tt.failures, tt.failStatus)
+			a := newSlowAgent(t, 0, "one", "two")
+			srv := newAsyncTestServer(t, a, controllers.WebhookOptions{
+				Secret:                webhookSecret,
+				MaxAttempts:           3,
+				InitialBackoff:        time.Millisecond,
+				AllowPrivateAddresses: true,
+			})
+
+			resp := postAsyncRun(t, srv, a.Name(), controllers.RunModeAsync, models.RunAgentRequest{
+				CallbackURL:  callbackSrv.URL,
+				CallbackMode: tt.callbackMode,
+			})
+			if resp.StatusCode != http.StatusAccepted {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusAccepted)
+			}
+			var accepted models.InvocationStatus
+			if err := json.NewDecoder(resp.Body).Decode(&accepted); err != nil {
+				t.Fatalf("Decode() failed: %v", err)
+			}
+			if accepted.InvocationID == "" {
+				t.Fatalf("response has no invocation ID: %+v", accepted)
+			}
+			location, err := url.Parse(resp.Header.Get("Location"))
+			if err != nil {
+				t.Fatalf("malformed Location header: %v", err)
+			}
+			statusURL := resp.Request.URL.ResolveReference(location).String()
+
+			status := waitDeliveries(t, statusURL)
+			if status.InvocationID != accepted.InvocationID || status.State != models.InvocationStateCompleted {
+				t.Errorf("status = %+v, want completed invocation %q", status, accepted.InvocationID)
+			}
+			var gotDeliveries []string
+			for _, d := range status.Deliveries {
+				gotDeliveries = append(gotDeliveries, d.State)
+			}
+			if diff := cmp.Diff(tt.wantDeliveries, gotDeliveries); diff != "" {
+				t.Errorf("delivery states mismatch (-want +got):\n%s", diff)
+			}
+			if got := status.Deliveries[0].Attempts; got != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", got, tt.wantAttempts)
+			}
+
+			receiver.mu.Lock()
+			defer receiver.mu.Unlock()
+			if diff := cmp.Diff(tt.wantCallbacks, summarize(receiver.callbacks)); diff != "" {
+				t.Errorf("callbacks mismatch (-want +got):\n%s", diff)
+			}
+			for _, cb := range receiver.callbacks {
+				if cb.InvocationID != accepted.InvocationID {
+					t.Errorf("callback invocation ID = %q, want %q", cb.InvocationID, accepted.InvocationID)
+				}
+			}
+		})
+	}
+}
+
+func TestRunHandler_AsyncOutlivesRequest(t *testing.T) {
+	receiver, callbackSrv := newCallbackReceiver(t, 0, 0)
+	a := newSlowAgent(t, 50*time.Millisecond, "one")
+	sessionService := session.InMemoryService()
+	if _, err := sessionService.Create(context.Background(), &session.CreateRequest{AppName: a.Name(), UserID: "user", SessionID: "session"}); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	controller := controllers.NewRuntimeAPIControllerWithOptions(sessionService, nil, agent.NewSingleLoader(a), nil, 10*time.Second, runner.PluginConfig{}, controllers.RuntimeAPIOptions{
+		Webhooks: controllers.WebhookOptions{Secret: webhookSecret, AllowPrivateAddresses: true},
+	})
+
+	body, err := json.Marshal(models.RunAgentRequest{
+		AppName:      a.Name(),
+		UserId:       "user",
+		SessionId:    "session",
+		NewMessage:   *genai.NewContentFromText("hi", genai.RoleUser),
+		CallbackURL:  callbackSrv.URL,
+		CallbackMode: models.CallbackModeTerminal,
+	})
+	if err != nil {
+		t.Fatalf("json.Marshal() failed: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodPost, "/run?mode=async", bytes.NewReader(body)).WithContext(ctx)
+	rr := httptest.NewRecorder()
+	controllers.NewErrorHandler(controller.RunHandler)(rr, req)
+	cancel()
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want %d", rr.Code, http.StatusAccepted)
+	}
+
+	select {
+	case <-receiver.received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no callback received")
+	}
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	want := []callbackSummary{{State: models.InvocationStateCompleted, Text: "one"}}
+	if diff := cmp.Diff(want, summarize(receiver.callbacks)); diff != "" {
+		t.Errorf("callbacks mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRunHandler_AsyncValidation(t *testing.T) {
+	tc := []struct {
+		name       string
+		mode       string
+		webhooks   controllers.WebhookOptions
+		request    models.RunAgentRequest
+		wantStatus int
+	}{
+		{
+			name:       "unknown mode",
+			mode:       "later",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "callbacks disabled",
+			mode:       controllers.RunModeAsync,
+			request:    models.RunAgentRequest{CallbackURL: "https://example.com/hook"},
+			wantStatus: http.StatusNotImplemented,
+		},
+		{
+			name:       "relative callback URL",
+			mode:       controllers.RunModeAsync,
+			webhooks:   controllers.WebhookOptions{Secret: webhookSecret},
+			request:    models.RunAgentRequest{CallbackURL: "/hook"},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unknown callback mode",
+			mode:       controllers.RunModeAsync,
+			webhooks:   controllers.WebhookOptions{Secret: webhookSecret},
+			request:    models.RunAgentRequest{CallbackURL: "https://example.com/hook", CallbackMode: "some"},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "loopback callback address",
+			mode:       controllers.RunModeAsync,
+			webhooks:   controllers.WebhookOptions{Secret: webhookSecret},
+			request:    models.RunAgentRequest{CallbackURL: "http://127.0.0.1:8080/hook"},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "private callback address",
+			mode:       controllers.RunModeAsync,
+			webhooks:   controllers.WebhookOptions{Secret: webhookSecret},
+			request:    models.RunAgentRequest{CallbackURL: "http://[fd00::1]/hook"},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "shared address space callback address",
+			mode:       controllers.RunModeAsync,
+			webhooks:   controllers.WebhookOptions{Secret: webhookSecret},
+			request:    models.RunAgentRequest{CallbackURL: "http://100.64.0.1/hook"},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "multicast callback address",
+			mode:       controllers.RunModeAsync,
+			webhooks:   controllers.WebhookOptions{Secret: webhookSecret},
+			request:    models.RunAgentRequest{CallbackURL: "http://224.0.0.1/hook"},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "IPv4-mapped loopback callback address",
+			mode:       controllers.RunModeAsync,
+			webhooks:   controllers.WebhookOptions{Secret: webhookSecret},
+			request:    models.RunAgentRequest{CallbackURL: "http://[::ffff:127.0.0.1]/hook"},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "host not allowed",
+			mode:       controllers.RunModeAsync,
+			webhooks:   controllers.WebhookOptions{Secret: webhookSecret, AllowedHosts: []string{"hooks.example.com"}},
+			request:    models.RunAgentRequest{CallbackURL: "https://example.com/hook"},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "allowed host",
+			mode:       controllers.RunModeAsync,
+			webhooks:   controllers.WebhookOptions{Secret: webhookSecret, AllowedHosts: []string{"Hooks.Example.com"}},
+			request:    models.RunAgentRequest{CallbackURL: "https://hooks.example.com:8443/hook"},
+			wantStatus: http.StatusAccepted,
+		},
+		{
+			name: "URL check fails",
+			mode: controllers.RunModeAsync,
+			webhooks: controllers.WebhookOptions{Secret: webhookSecret, CheckURL: func(u *url.URL) error {
+				return errors.New("not registered")
+			}},
+			request:    models.RunAgentRequest{CallbackURL: "https://example.com/hook"},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "without callback",
+			mode:       controllers.RunModeAsync,
+			wantStatus: http.StatusAccepted,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newSlowAgent(t, 0, "one")
+			srv := newAsyncTestServer(t, a, tt.webhooks)
+			resp := postAsyncRun(t, srv, a.Name(), tt.mode, tt.request)
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestRunHandler_AsyncDefaultClient(t *testing.T) {
+	// The receiver listens on a loopback address, which the default client
+	// refuses to connect to, whatever the host name.
+	_, callbackSrv := newCallbackReceiver(t, 0, 0)
+	callbackURL, err := url.Parse(callbackSrv.URL)
+	if err != nil {
+		t.Fatalf("url.Parse() failed: %v", err)
+	}
+	a := newSlowAgent(t, 0, "one")
+	srv := newAsyncTestServer(t, a, controllers.WebhookOptions{
+		Secret:         webhookSecret,
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+	})
+	resp := postAsyncRun(t, srv, a.Name(), controllers.RunModeAsync, models.RunAgentRequest{
+		CallbackURL:  "http://localhost:" + callbackURL.Port() + "/hook",
+		CallbackMode: models.CallbackModeTerminal,
+	})
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusAccepted)
+	}
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("malformed Location header: %v", err)
+	}
+	status := waitDeliveries(t, resp.Request.URL.ResolveReference(location).String())
+	const wantError = "callback address is not allowed"
+	if d := status.Deliveries[0]; d.State != models.DeliveryStateFailed || d.Attempts != 1 || !strings.Contains(d.LastError, wantError) {
+		t.Errorf("delivery = %+v, want one failed attempt with error %q", d, wantError)
+	}
+}
+
+func TestRunHandler_AsyncRedirect(t *testing.T) {
+	target, targetSrv := newCallbackReceiver(t, 0, 0)
+	redirectSrv := httptest.NewServer(http.RedirectHandler(targetSrv.URL, http.StatusTemporaryRedirect))
+	t.Cleanup(redirectSrv.Close)
+	a := newSlowAgent(t, 0, "one")
+	srv := newAsyncTestServer(t, a, controllers.WebhookOptions{
+		Secret:                webhookSecret,
+		MaxAttempts:           3,
+		InitialBackoff:        time.Millisecond,
+		AllowPrivateAddresses: true,
+	})
+	resp := postAsyncRun(t, srv, a.Name(), controllers.RunModeAsync, models.RunAgentRequest{
+		CallbackURL:  redirectSrv.URL,
+		CallbackMode: models.CallbackModeTerminal,
+	})
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusAccepted)
+	}
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("malformed Location header: %v", err)
+	}
+	status := waitDeliveries(t, resp.Request.URL.ResolveReference(location).String())
+	if d := status.Deliveries[0]; d.State != models.DeliveryStateFailed || d.Attempts != 1 {
+		t.Errorf("delivery = %+v, want one failed attempt", d)
+	}
+	target.mu.Lock()
+	defer target.mu.Unlock()
+	if len(target.callbacks) != 0 {
+		t.Errorf("redirect was followed, target received %d callbacks", len(target.callbacks))
+	}
+}
+
+func TestRunHandler_AsyncPanic(t *testing.T) {
+	receiver, callbackSrv := newCallbackReceiver(t, 0, 0)
+	a := newPanickingAgent(t)
+	srv := newAsyncTestServer(t, a, controllers.WebhookOptions{Secret: webhookSecret, AllowPrivateAddresses: true})
+	resp := postAsyncRun(t, srv, a.Name(), controllers.RunModeAsync, models.RunAgentRequest{
+		CallbackURL:  callbackSrv.URL,
+		CallbackMode: models.CallbackModeTerminal,
+	})
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusAccepted)
+	}
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("malformed Location header: %v", err)
+	}
+	status := waitDeliveries(t, resp.Request.URL.ResolveReference(location).String())
+	if status.State != models.InvocationStateFailed || status.ErrorCode != "INTERNAL" || status.ErrorMessage != controllers.ErrInternal.Error() {
+		t.Errorf("status = %+v, want a failed invocation with an INTERNAL error", status)
+	}
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	if diff := cmp.Diff([]callbackSummary{{State: models.InvocationStateFailed}}, summarize(receiver.callbacks)); diff != "" {
+		t.Errorf("callbacks mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// panickingTransport is an [http.RoundTripper] which panics.
+type panickingTransport struct{}
+
+func (panickingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	panic("transport failed")
+}
+
+func TestRunHandler_AsyncWebhookPanic(t *testing.T) {
+	a := newSlowAgent(t, 0, "one")
+	srv := newAsyncTestServer(t, a, controllers.WebhookOptions{
+		Secret:     webhookSecret,
+		HTTPClient: &http.Client{Transport: panickingTransport{}},
+	})
+	resp := postAsyncRun(t, srv, a.Name(), controllers.RunModeAsync, models.RunAgentRequest{
+		CallbackURL: "https://example.com/hook",
+	})
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusAccepted)
+	}
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("malformed Location header: %v", err)
+	}
+	status := waitDeliveries(t, resp.Request.URL.ResolveReference(location).String())
+	if status.State != models.InvocationStateCompleted {
+		t.Errorf("status = %+v, want a completed invocation", status)
+	}
+	for _, d := range status.Deliveries {
+		if d.State != models.DeliveryStateFailed {
+			t.Errorf("delivery = %+v, want a failed one", d)
+		}
+	}
+}
+
+func TestGetInvocationHandler(t *testing.T) {
+	a := newSlowAgent(t, 0, "one")
+	srv := newAsyncTestServer(t, a, controllers.WebhookOptions{})
+	resp := postAsyncRun(t, srv, a.Name(), controllers.RunModeSync, models.RunAgentRequest{})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	var events []models.Event
+	if err := json.NewDecoder(resp.Body).Decode(&events); err != nil {
+		t.Fatalf("Decode() failed: %v", err)
+	}
+	invocationID := events[0].InvocationID
+	statusURL := srv.URL + "/apps/" + a.Name() + "/users/user/sessions/session/invocations/"
+
+	var status models.InvocationStatus
+	getJSON(t, statusURL+invocationID, &status)
+	want := models.InvocationStatus{InvocationID: invocationID, State: models.InvocationStateCompleted}
+	if diff := cmp.Diff(want, status); diff != "" {
+		t.Errorf("status mismatch (-want +got):\n%s", diff)
+	}
+
+	notFound, err := http.Get(statusURL + "unknown")
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	defer notFound.Body.Close()
+	if notFound.StatusCode != http.StatusNotFound {
+		t.Errorf("status of an unknown invocation = %d, want %d", notFound.StatusCode, http.StatusNotFound)
+	}
+}
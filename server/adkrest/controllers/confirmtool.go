@@ -0,0 +1,121 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/internal/utils"
+	"google.golang.org/adk/server/adkrest/internal/models"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool/toolconfirmation"
+)
+
+// ConfirmToolHandler approves or denies a function call of an invocation
+// which requires a confirmation, a [models.ConfirmToolRequest], and resumes
+// the flow: the agent which made the call runs with the decision, in a new
+// invocation, and the response has all the events of the run, as for the run
+// endpoint. An approved call is executed, with the edited arguments if any,
+// and the model is given the refusal of a denied one.
+//
+// The decision is tied to the confirmation request of the call with the ID,
+// found in the events of the invocation. It fails with 404 status code if
+// the invocation has no such request, and with 409 status code if the
+// invocation is still running or the call was already confirmed or denied.
+func (c *RuntimeAPIController) ConfirmToolHandler(rw http.ResponseWriter, req *http.Request) error {
+	var confirmReq models.ConfirmToolRequest
+	sess, invocationID, err := c.pausedInvocation(rw, req, &confirmReq)
+	if err != nil {
+		return err
+	}
+	if confirmReq.FunctionCallID == "" {
+		return newStatusError(errors.New("functionCallId is required"), http.StatusBadRequest)
+	}
+	if !confirmReq.Confirmed && confirmReq.Arguments != nil {
+		return newStatusError(errors.New("arguments can only be edited for an approved call"), http.StatusBadRequest)
+	}
+
+	request, answered := findConfirmationRequest(sess.Events(), invocationID, confirmReq.FunctionCallID)
+	if request == nil {
+		return newStatusError(fmt.Errorf("invocation %q has no confirmation request for function call %q", invocationID, confirmReq.FunctionCallID), http.StatusNotFound)
+	}
+	if answered {
+		return newStatusError(fmt.Errorf("function call %q was already confirmed or denied", confirmReq.FunctionCallID), http.StatusConflict)
+	}
+
+	response := map[string]any{"confirmed": confirmReq.Confirmed}
+	if confirmReq.Arguments != nil {
+		response["arguments"] = confirmReq.Arguments
+	}
+	if confirmReq.Reason != "" {
+		response["reason"] = confirmReq.Reason
+	}
+	runAgentRequest := models.RunAgentRequest{
+		AppName:   sess.AppName(),
+		UserId:    sess.UserID(),
+		SessionId: sess.ID(),
+		NewMessage: genai.Content{
+			Role: genai.RoleUser,
+			Parts: []*genai.Part{{
+				FunctionResponse: &genai.FunctionResponse{
+					ID:       request.ID,
+					Name:     toolconfirmation.FunctionCallName,
+					Response: response,
+				},
+			}},
+		},
+		ExcludeThoughts: confirmReq.ExcludeThoughts,
+	}
+	if err := c.checkRateLimit(rw, req, runAgentRequest); err != nil {
+		return err
+	}
+	return c.runSync(rw, req, runAgentRequest)
+}
+
+// findConfirmationRequest returns the pending confirmation request of the
+// invocation for the function call with the ID, and whether it was answered,
+// i.e. a later event of the user has a response to the request.
+func findConfirmationRequest(events session.Events, invocationID, callID string) (request *genai.FunctionCall, answered bool) {
+	for event := range events.All() {
+		if request == nil {
+			if event.InvocationID != invocationID {
+				continue
+			}
+			for _, fc := range utils.FunctionCalls(event.Content) {
+				if fc.Name != toolconfirmation.FunctionCallName || !slices.Contains(event.LongRunningToolIDs, fc.ID) {
+					continue
+				}
+				if original, err := toolconfirmation.OriginalCallFrom(fc); err == nil && original.ID == callID {
+					request = fc
+				}
+			}
+			continue
+		}
+		if event.Author != "user" {
+			continue
+		}
+		for _, fr := range utils.FunctionResponses(event.Content) {
+			if fr.ID == request.ID {
+				return request, true
+			}
+		}
+	}
+	return request, false
+}
@@ -0,0 +1,201 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/gorilla/mux"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/internal/testutil"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/server/adkrest/controllers"
+	"google.golang.org/adk/server/adkrest/internal/models"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+	"google.golang.org/adk/tool/toolconfirmation"
+)
+
+func TestConfirmToolHandler(t *testing.T) {
+	type deleteArgs struct {
+		ID string `json:"id"`
+	}
+	tests := []struct {
+		name        string
+		confirm     models.ConfirmToolRequest
+		wantDeleted []string
+		// wantResponse is the response of the call given to the model.
+		wantResponse map[string]any
+	}{
+		{
+			name:         "approved",
+			confirm:      models.ConfirmToolRequest{Confirmed: true},
+			wantDeleted:  []string{"1"},
+			wantResponse: map[string]any{"result": "deleted 1"},
+		},
+		{
+			name:         "approved with edited arguments",
+			confirm:      models.ConfirmToolRequest{Confirmed: true, Arguments: map[string]any{"id": "2"}},
+			wantDeleted:  []string{"2"},
+			wantResponse: map[string]any{"result": "deleted 2"},
+		},
+		{
+			name:    "denied",
+			confirm: models.ConfirmToolRequest{Reason: "record 1 is still in use"},
+			wantResponse: map[string]any{
+				"status": "rejected",
+				"error":  `the user rejected the call of tool "delete_record"`,
+				"reason": "record 1 is still in use",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var deleted []string
+			deleteRecord, err := functiontool.New(functiontool.Config{
+				Name:                "delete_record",
+				Description:         "deletes a record",
+				RequireConfirmation: true,
+			}, func(ctx tool.Context, args deleteArgs) (string, error) {
+				deleted = append(deleted, args.ID)
+				return "deleted " + args.ID, nil
+			})
+			if err != nil {
+				t.Fatalf("functiontool.New() failed: %v", err)
+			}
+			llm := &testutil.MockModel{Responses: []*genai.Content{
+				genai.NewContentFromFunctionCall("delete_record", map[string]any{"id": "1"}, genai.RoleModel),
+				genai.NewContentFromText("Done.", genai.RoleModel),
+			}}
+			a, err := llmagent.New(llmagent.Config{Name: "records", Model: llm, Tools: []tool.Tool{deleteRecord}})
+			if err != nil {
+				t.Fatalf("llmagent.New() failed: %v", err)
+			}
+			post := newConfirmTestServer(t, a)
+
+			status, events := post("/run", models.RunAgentRequest{
+				AppName:    "records",
+				UserId:     "user",
+				SessionId:  "session",
+				NewMessage: *genai.NewContentFromText("Delete record 1.", genai.RoleUser),
+			})
+			if status != http.StatusOK {
+				t.Fatalf("run: status = %d, want 200", status)
+			}
+			var request *genai.FunctionCall
+			var invocationID string
+			for _, event := range events {
+				for _, p := range event.Content.Parts {
+					if p.FunctionCall != nil && p.FunctionCall.Name == toolconfirmation.FunctionCallName {
+						request, invocationID = p.FunctionCall, event.InvocationID
+						if len(event.LongRunningToolIDs) != 1 || event.LongRunningToolIDs[0] != request.ID {
+							t.Errorf("LongRunningToolIDs = %v, want the ID of the confirmation request", event.LongRunningToolIDs)
+						}
+					}
+				}
+			}
+			if request == nil {
+				t.Fatalf("run: no confirmation request in events")
+			}
+			if len(deleted) != 0 || len(llm.Requests) != 1 {
+				t.Fatalf("run: deleted %v, %d model requests, want no deletion and 1 request", deleted, len(llm.Requests))
+			}
+			original, err := toolconfirmation.OriginalCallFrom(request)
+			if err != nil {
+				t.Fatalf("OriginalCallFrom() failed: %v", err)
+			}
+
+			confirmPath := "/apps/records/users/user/sessions/session/invocations/" + invocationID + ":confirmTool"
+			// The decision is tied to the call, not to the confirmation request.
+			confirmReq := tt.confirm
+			confirmReq.FunctionCallID = request.ID
+			if status, _ := post(confirmPath, confirmReq); status != http.StatusNotFound {
+				t.Errorf("confirm with the ID of the request: status = %d, want %d", status, http.StatusNotFound)
+			}
+
+			confirmReq.FunctionCallID = original.ID
+			status, events = post(confirmPath, confirmReq)
+			if status != http.StatusOK || len(events) == 0 {
+				t.Fatalf("confirm: status = %d, %d events, want 200 and events", status, len(events))
+			}
+			if diff := cmp.Diff(tt.wantDeleted, deleted); diff != "" {
+				t.Errorf("deleted records mismatch (-want +got):\n%s", diff)
+			}
+			if len(llm.Requests) != 2 {
+				t.Fatalf("model called %d times, want 2", len(llm.Requests))
+			}
+			contents := llm.Requests[1].Contents
+			last := contents[len(contents)-1]
+			if len(last.Parts) != 1 || last.Parts[0].FunctionResponse == nil {
+				t.Fatalf("last content = %+v, want the function response", last)
+			}
+			if diff := cmp.Diff(tt.wantResponse, last.Parts[0].FunctionResponse.Response); diff != "" {
+				t.Errorf("function response given to the model mismatch (-want +got):\n%s", diff)
+			}
+
+			// A decision can't be replayed.
+			if status, _ := post(confirmPath, confirmReq); status != http.StatusConflict {
+				t.Errorf("second confirm: status = %d, want %d", status, http.StatusConflict)
+			}
+			if diff := cmp.Diff(tt.wantDeleted, deleted); diff != "" {
+				t.Errorf("deleted records after replay mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func newConfirmTestServer(t *testing.T, a agent.Agent) func(path string, body any) (int, []models.Event) {
+	t.Helper()
+	sessionService := session.InMemoryService()
+	if _, err := sessionService.Create(t.Context(), &session.CreateRequest{AppName: a.Name(), UserID: "user", SessionID: "session"}); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	controller := controllers.NewRuntimeAPIControllerWithOptions(sessionService, nil, agent.NewSingleLoader(a), nil, 10*time.Second, runner.PluginConfig{}, controllers.RuntimeAPIOptions{})
+	router := mux.NewRouter()
+	router.HandleFunc("/run", controllers.NewErrorHandler(controller.RunHandler))
+	router.HandleFunc("/apps/{app_name}/users/{user_id}/sessions/{session_id}/invocations/{invocation_id}:confirmTool", controllers.NewErrorHandler(controller.ConfirmToolHandler))
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+
+	return func(path string, body any) (int, []models.Event) {
+		t.Helper()
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("json.Marshal() failed: %v", err)
+		}
+		resp, err := http.Post(srv.URL+path, "application/json", bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("POST %s failed: %v", path, err)
+		}
+		defer resp.Body.Close()
+		var events []models.Event
+		if resp.StatusCode == http.StatusOK {
+			if err := json.NewDecoder(resp.Body).Decode(&events); err != nil {
+				t.Fatalf("failed to decode events: %v", err)
+			}
+		}
+		return resp.StatusCode, events
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"time"
+
+	"google.golang.org/adk/internal/telemetry"
+	"google.golang.org/adk/internal/version"
+	"google.golang.org/adk/server/adkrest/internal/models"
+)
+
+// TimeoutHeader is the request header with which clients set the maximum
+// duration of a run, in seconds. It is capped by the server maximum.
+const TimeoutHeader = "X-ADK-Timeout-Seconds"
+
+// ErrRunTimeout is reported to the runs which didn't finish before their deadline.
+var ErrRunTimeout = errors.New("run exceeded its deadline")
+
+// runTimeout returns the maximum duration of the run requested by req, or
+// zero if the run is not limited.
+func (c *RuntimeAPIController) runTimeout(req *http.Request) (time.Duration, error) {
+	timeout := c.opts.MaxRunDuration
+	header := req.Header.Get(TimeoutHeader)
+	if header == "" {
+		return timeout, nil
+	}
+	seconds, err := strconv.ParseFloat(header, 64)
+	if err != nil || seconds <= 0 {
+		return 0, newStatusError(fmt.Errorf("invalid %s header %q: must be a positive number of seconds", TimeoutHeader, header), http.StatusBadRequest)
+	}
+	requested := time.Duration(seconds * float64(time.Second))
+	if timeout > 0 && requested > timeout {
+		return timeout, nil
+	}
+	return requested, nil
+}
+
+// startRun prepares the context of a run requested by req: the run is
+// tracked for shutdown, holds the lock of its session, is limited by its
+// deadline, is tagged in the user agent of its model calls and its
+// invocation is reported through [invocationFromContext] and can be
+// cancelled. done must be called when the handler has finished writing the
+// response, it releases the lock and ends the spans of the invocation.
+func (c *RuntimeAPIController) startRun(req *http.Request, runAgentRequest models.RunAgentRequest) (ctx context.Context, done func(), err error) {
+	timeout, err := c.runTimeout(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	ctx, untrack, err := c.runs.start(req.Context())
+	if err != nil {
+		return nil, nil, err
+	}
+	unlock, err := c.lockSession(ctx, runAgentRequest.AppName, runAgentRequest.UserId, runAgentRequest.SessionId)
+	if err != nil {
+		untrack()
+		return nil, nil, err
+	}
+	done = func() {
+		unlock()
+		untrack()
+	}
+	ctx = version.WithUserAgent(ctx, userAgentProduct)
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, timeout, ErrRunTimeout)
+		stop := done
+		done = func() {
+			cancel()
+			stop()
+		}
+	}
+	ctx, inv := withInvocation(ctx)
+	inv.spans = telemetry.StartTrace(ctx, "invoke_agent "+runAgentRequest.AppName)
+	stop := done
+	done = func() {
+		// The handlers defer done, so that the panics of their run are
+		// recorded on the spans of the invocation before they end. The
+		// panic is then propagated to the recovery of the handler.
+		v := recover()
+		if v != nil && v != http.ErrAbortHandler {
+			inv.recordPanic(v, string(debug.Stack()))
+		}
+		if stats, ok := inv.stats(); ok {
+			c.opts.Metrics.ObserveInvocation(stats)
+		}
+		c.invocations.remove(inv)
+		inv.finish()
+		inv.cancel(nil)
+		inv.endSpans()
+		stop()
+		if v != nil {
+			panic(v)
+		}
+	}
+	return ctx, done, nil
+}
+
+// interruption returns the error describing why the server interrupted the
+// run, if it did.
+func interruption(ctx context.Context) (statusError, bool) {
+	cause := context.Cause(ctx)
+	switch {
+	case errors.Is(cause, ErrShuttingDown):
+		return newStatusError(ErrShuttingDown, http.StatusServiceUnavailable), true
+	case errors.Is(cause, ErrRunTimeout):
+		return statusError{Err: ErrRunTimeout, Code: http.StatusGatewayTimeout, Reason: "DEADLINE_EXCEEDED"}, true
+	case errors.Is(cause, ErrRunCancelled):
+		return statusError{Err: ErrRunCancelled, Code: http.StatusConflict, Reason: "CANCELLED"}, true
+	}
+	return statusError{}, false
+}
@@ -0,0 +1,152 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/server/adkrest/controllers"
+	"google.golang.org/adk/server/adkrest/internal/models"
+	"google.golang.org/adk/session"
+)
+
+func TestRunHandlers_Deadline(t *testing.T) {
+	tc := []struct {
+		name           string
+		streaming      bool
+		maxRunDuration time.Duration
+		header         string
+		wantStatus     int
+		wantCode       string
+	}{
+		{
+			name:       "streaming run exceeds header deadline",
+			streaming:  true,
+			header:     "0.05",
+			wantStatus: http.StatusOK,
+			wantCode:   "DEADLINE_EXCEEDED",
+		},
+		{
+			name:           "run exceeds server maximum",
+			maxRunDuration: 50 * time.Millisecond,
+			wantStatus:     http.StatusGatewayTimeout,
+			wantCode:       "DEADLINE_EXCEEDED",
+		},
+		{
+			name:           "header is capped by server maximum",
+			maxRunDuration: 50 * time.Millisecond,
+			header:         "3600",
+			wantStatus:     http.StatusGatewayTimeout,
+			wantCode:       "DEADLINE_EXCEEDED",
+		},
+		{
+			name:       "invalid header",
+			header:     "soon",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "BAD_REQUEST",
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newSlowAgent(t, 5*time.Second, "never")
+			sessionService := session.InMemoryService()
+			if _, err := sessionService.Create(context.Background(), &session.CreateRequest{AppName: a.Name(), UserID: "user", SessionID: "session"}); err != nil {
+				t.Fatalf("Create() failed: %v", err)
+			}
+			controller := controllers.NewRuntimeAPIControllerWithOptions(sessionService, nil, agent.NewSingleLoader(a), nil, 10*time.Second, runner.PluginConfig{}, controllers.RuntimeAPIOptions{
+				SSEKeepAliveInterval: -1,
+				MaxRunDuration:       tt.maxRunDuration,
+			})
+			handler := controller.RunHandler
+			if tt.streaming {
+				handler = controller.RunSSEHandler
+			}
+			srv := httptest.NewServer(controllers.NewErrorHandler(handler))
+			t.Cleanup(srv.Close)
+
+			body, err := json.Marshal(models.RunAgentRequest{
+				AppName:    a.Name(),
+				UserId:     "user",
+				SessionId:  "session",
+				NewMessage: *genai.NewContentFromText("hi", genai.RoleUser),
+			})
+			if err != nil {
+				t.Fatalf("json.Marshal() failed: %v", err)
+			}
+			req, err := http.NewRequest(http.MethodPost, srv.URL, bytes.NewReader(body))
+			if err != nil {
+				t.Fatalf("NewRequest() failed: %v", err)
+			}
+			if tt.header != "" {
+				req.Header.Set(controllers.TimeoutHeader, tt.header)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Do() failed: %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			got, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("ReadAll() failed: %v", err)
+			}
+			data := string(got)
+			if tt.streaming {
+				var event string
+				event, data = parseSSEFrame(strings.TrimSpace(data))
+				if event != "error" {
+					t.Fatalf("body = %q, want an error event", got)
+				}
+			}
+			var errResp controllers.ErrorResponse
+			if err := json.Unmarshal([]byte(data), &errResp); err != nil {
+				t.Fatalf("malformed error body %q: %v", got, err)
+			}
+			if errResp.Error.Code != tt.wantCode {
+				t.Errorf("error code = %q, want %q", errResp.Error.Code, tt.wantCode)
+			}
+
+			if tt.wantCode != "DEADLINE_EXCEEDED" {
+				return
+			}
+			getResp, err := sessionService.Get(context.Background(), &session.GetRequest{AppName: a.Name(), UserID: "user", SessionID: "session"})
+			if err != nil {
+				t.Fatalf("Get() failed: %v", err)
+			}
+			events := getResp.Session.Events()
+			if events.Len() != 2 {
+				t.Fatalf("session has %d events, want the user message and the interruption marker", events.Len())
+			}
+			marker := events.At(1)
+			if !marker.Interrupted || marker.ErrorCode != "DEADLINE_EXCEEDED" || marker.InvocationID != events.At(0).InvocationID {
+				t.Errorf("unexpected interruption marker: %+v", marker)
+			}
+		})
+	}
+}
@@ -48,13 +48,13 @@ func (c *DebugAPIController) TraceDictHandler(rw http.ResponseWriter, req *http.
 	params := mux.Vars(req)
 	eventID := params["event_id"]
 	if eventID == "" {
-		http.Error(rw, "event_id parameter is required", http.StatusBadRequest)
+		httpError(rw, req, "event_id parameter is required", http.StatusBadRequest)
 		return
 	}
 	traceDict := c.spansExporter.GetTraceDict()
 	eventDict, ok := traceDict[eventID]
 	if !ok {
-		http.Error(rw, fmt.Sprintf("event not found: %s", eventID), http.StatusNotFound)
+		httpError(rw, req, fmt.Sprintf("event not found: %s", eventID), http.StatusNotFound)
 		return
 	}
 	EncodeJSONResponse(eventDict, http.StatusOK, rw)
@@ -65,7 +65,7 @@ func (c *DebugAPIController) EventGraphHandler(rw http.ResponseWriter, req *http
 	vars := mux.Vars(req)
 	sessionID, err := models.SessionIDFromHTTPParameters(vars)
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusBadRequest)
+		httpError(rw, req, err.Error(), http.StatusBadRequest)
 		return
 	}
 	resp, err := c.sessionService.Get(req.Context(), &session.GetRequest{
@@ -74,12 +74,12 @@ func (c *DebugAPIController) EventGraphHandler(rw http.ResponseWriter, req *http
 		SessionID: sessionID.ID,
 	})
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusBadRequest)
+		httpError(rw, req, err.Error(), http.StatusBadRequest)
 		return
 	}
 	eventID := vars["event_id"]
 	if eventID == "" {
-		http.Error(rw, "event_id parameter is required", http.StatusBadRequest)
+		httpError(rw, req, "event_id parameter is required", http.StatusBadRequest)
 		return
 	}
 
@@ -92,7 +92,7 @@ func (c *DebugAPIController) EventGraphHandler(rw http.ResponseWriter, req *http
 	}
 
 	if event == nil {
-		http.Error(rw, "event not found", http.StatusNotFound)
+		httpError(rw, req, "event not found", http.StatusNotFound)
 		return
 	}
 
@@ -118,12 +118,12 @@ func (c *DebugAPIController) EventGraphHandler(rw http.ResponseWriter, req *http
 
 	agent, err := c.agentloader.LoadAgent(sessionID.AppName)
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		httpError(rw, req, err.Error(), http.StatusInternalServerError)
 		return
 	}
 	graph, err := services.GetAgentGraph(req.Context(), agent, highlightedPairs)
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		httpError(rw, req, err.Error(), http.StatusInternalServerError)
 		return
 	}
 	EncodeJSONResponse(map[string]string{"dotSrc": graph}, http.StatusOK, rw)
@@ -14,9 +14,27 @@
 
 package controllers
 
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/adk/server/adkrest/internal/requestid"
+)
+
 type statusError struct {
 	Err  error
 	Code int
+	// Reason is a stable machine-readable error code, e.g. "NOT_FOUND".
+	// Optional: if empty, it is derived from the status code.
+	Reason string
+	// RetryAfter tells the client when to retry the request.
+	// Optional: if zero, no hint is sent.
+	RetryAfter time.Duration
 }
 
 func newStatusError(err error, code int) statusError {
@@ -32,3 +50,143 @@ func (se statusError) Error() string {
 func (se statusError) Status() int {
 	return se.Code
 }
+
+// Unwrap returns the wrapped error.
+func (se statusError) Unwrap() error {
+	return se.Err
+}
+
+// ErrorCode returns the machine-readable error code.
+func (se statusError) ErrorCode() string {
+	if se.Reason != "" {
+		return se.Reason
+	}
+	return codeFromStatus(se.Code)
+}
+
+// retryAfterSeconds returns the retry hint in whole seconds, at least 1, or
+// zero if there is no hint.
+func (se statusError) retryAfterSeconds() int {
+	if se.RetryAfter <= 0 {
+		return 0
+	}
+	return max(int(math.Ceil(se.RetryAfter.Seconds())), 1)
+}
+
+// BadRequest returns an error which is reported with 400 status code.
+func BadRequest(msg string) error {
+	return newStatusError(errors.New(msg), http.StatusBadRequest)
+}
+
+// Unauthorized returns an error which is reported with 401 status code.
+func Unauthorized(msg string) error {
+	return newStatusError(errors.New(msg), http.StatusUnauthorized)
+}
+
+// Forbidden returns an error which is reported with 403 status code.
+func Forbidden(msg string) error {
+	return newStatusError(errors.New(msg), http.StatusForbidden)
+}
+
+// NotFound returns an error which is reported with 404 status code.
+func NotFound(msg string) error {
+	return newStatusError(errors.New(msg), http.StatusNotFound)
+}
+
+// Conflict returns an error which is reported with 409 status code.
+func Conflict(msg string) error {
+	return newStatusError(errors.New(msg), http.StatusConflict)
+}
+
+// ErrorResponse is the JSON body of an error response.
+type ErrorResponse struct {
+	Error ErrorDetails `json:"error"`
+}
+
+// ErrorDetails describes an error returned by the REST API.
+type ErrorDetails struct {
+	// Code is a stable machine-readable error code, e.g. "NOT_FOUND".
+	Code string `json:"code"`
+	// Message is a human-readable description of the error.
+	Message string `json:"message"`
+	// Status is the HTTP status code of the response.
+	Status int `json:"status"`
+	// RequestID is the ID of the request which failed, if known.
+	RequestID string `json:"requestId,omitempty"`
+	// RetryAfter is the number of seconds after which the request can be
+	// retried, if known. It is also sent in the [RetryAfterHeader] header.
+	RetryAfter int `json:"retryAfter,omitempty"`
+}
+
+// writeError writes err as an [ErrorResponse]. Errors which don't carry a
+// status code are reported as internal server errors.
+func writeError(rw http.ResponseWriter, req *http.Request, err error) {
+	resp := newErrorResponse(req, err)
+
+	h := rw.Header()
+	h.Del("Content-Length")
+	h.Set("Content-Type", "application/json; charset=UTF-8")
+	h.Set("X-Content-Type-Options", "nosniff")
+	if resp.Error.RetryAfter > 0 {
+		h.Set(RetryAfterHeader, strconv.Itoa(resp.Error.RetryAfter))
+	}
+	rw.WriteHeader(resp.Error.Status)
+	_ = json.NewEncoder(rw).Encode(resp)
+}
+
+// newErrorResponse describes err as an [ErrorResponse]. The request is optional.
+func newErrorResponse(req *http.Request, err error) ErrorResponse {
+	status := http.StatusInternalServerError
+	code := ""
+	var se interface {
+		Status() int
+	}
+	if errors.As(err, &se) {
+		status = se.Status()
+	}
+	var coded interface {
+		ErrorCode() string
+	}
+	if errors.As(err, &coded) {
+		code = coded.ErrorCode()
+	}
+	if code == "" {
+		code = codeFromStatus(status)
+	}
+	var retryable interface {
+		retryAfterSeconds() int
+	}
+	retryAfter := 0
+	if errors.As(err, &retryable) {
+		retryAfter = retryable.retryAfterSeconds()
+	}
+
+	resp := ErrorResponse{
+		Error: ErrorDetails{
+			Code:       code,
+			Message:    err.Error(),
+			Status:     status,
+			RetryAfter: retryAfter,
+		},
+	}
+	if req != nil {
+		resp.Error.RequestID = requestid.FromContext(req.Context())
+	}
+	return resp
+}
+
+// httpError is a drop-in replacement of http.Error which writes the error as
+// an [ErrorResponse].
+func httpError(rw http.ResponseWriter, req *http.Request, msg string, status int) {
+	writeError(rw, req, newStatusError(errors.New(msg), status))
+}
+
+// codeFromStatus converts status code to an error code, e.g. 404 to "NOT_FOUND".
+func codeFromStatus(status int) string {
+	text := http.StatusText(status)
+	if text == "" {
+		return "UNKNOWN"
+	}
+	text = strings.NewReplacer(" ", "_", "-", "_", "'", "").Replace(text)
+	return strings.ToUpper(text)
+}
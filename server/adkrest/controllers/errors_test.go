@@ -0,0 +1,103 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"google.golang.org/adk/server/adkrest/internal/requestid"
+)
+
+func TestNewErrorHandler_JSONErrorBody(t *testing.T) {
+	tc := []struct {
+		name       string
+		err        error
+		requestID  string
+		wantStatus int
+		want       ErrorResponse
+	}{
+		{
+			name:       "bad request helper",
+			err:        BadRequest("missing field"),
+			wantStatus: http.StatusBadRequest,
+			want:       ErrorResponse{Error: ErrorDetails{Code: "BAD_REQUEST", Message: "missing field", Status: http.StatusBadRequest}},
+		},
+		{
+			name:       "not found helper",
+			err:        NotFound("no such session"),
+			wantStatus: http.StatusNotFound,
+			want:       ErrorResponse{Error: ErrorDetails{Code: "NOT_FOUND", Message: "no such session", Status: http.StatusNotFound}},
+		},
+		{
+			name:       "conflict helper with request ID",
+			err:        Conflict("already running"),
+			requestID:  "req-1",
+			wantStatus: http.StatusConflict,
+			want:       ErrorResponse{Error: ErrorDetails{Code: "CONFLICT", Message: "already running", Status: http.StatusConflict, RequestID: "req-1"}},
+		},
+		{
+			name:       "wrapped status error",
+			err:        fmt.Errorf("outer: %w", newStatusError(errors.New("inner"), http.StatusGatewayTimeout)),
+			wantStatus: http.StatusGatewayTimeout,
+			want:       ErrorResponse{Error: ErrorDetails{Code: "GATEWAY_TIMEOUT", Message: "outer: inner", Status: http.StatusGatewayTimeout}},
+		},
+		{
+			name:       "custom reason",
+			err:        statusError{Err: errors.New("slow down"), Code: http.StatusTooManyRequests, Reason: "RATE_LIMITED"},
+			wantStatus: http.StatusTooManyRequests,
+			want:       ErrorResponse{Error: ErrorDetails{Code: "RATE_LIMITED", Message: "slow down", Status: http.StatusTooManyRequests}},
+		},
+		{
+			name:       "plain error",
+			err:        errors.New("boom"),
+			wantStatus: http.StatusInternalServerError,
+			want:       ErrorResponse{Error: ErrorDetails{Code: "INTERNAL_SERVER_ERROR", Message: "boom", Status: http.StatusInternalServerError}},
+		},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewErrorHandler(func(http.ResponseWriter, *http.Request) error { return tt.err })
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.requestID != "" {
+				req = req.WithContext(requestid.ToContext(req.Context(), tt.requestID))
+			}
+			rr := httptest.NewRecorder()
+
+			handler(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rr.Code, tt.wantStatus)
+			}
+			if got := rr.Header().Get("Content-Type"); got != "application/json; charset=UTF-8" {
+				t.Errorf("Content-Type = %q, want JSON", got)
+			}
+			var got ErrorResponse
+			if err := json.NewDecoder(rr.Body).Decode(&got); err != nil {
+				t.Fatalf("decode error response: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("error response mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
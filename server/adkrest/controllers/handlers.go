@@ -16,10 +16,21 @@
 package controllers
 
 import (
+	"bufio"
 	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
+	"runtime/debug"
 	"sync/atomic"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"google.golang.org/adk/server/adkrest/internal/logging"
 )
 
 // TODO: Move to an internal package, controllers doesn't have to be public API.
@@ -29,6 +40,11 @@ import (
 type trackingResponseWriter struct {
 	http.ResponseWriter
 	headerWritten atomic.Bool
+	// logger is used to report problems with the response. It carries
+	// the attributes of the request being served (e.g. its path).
+	logger *slog.Logger
+	// stream is the stream written to the response, if any.
+	stream *streamWriter
 }
 
 // WriteHeader tracks that headers have been written and delegates to the underlying writer.
@@ -37,7 +53,7 @@ func (w *trackingResponseWriter) WriteHeader(statusCode int) {
 	// Atomic CAS ensures we only write headers once, even if called concurrently
 	if !w.headerWritten.CompareAndSwap(false, true) {
 		// Headers already written, log and skip to avoid superfluous WriteHeader
-		log.Printf("ADK: Skipping duplicate WriteHeader call (status %d) - headers already sent", statusCode)
+		w.logger.Warn("ADK: skipping duplicate WriteHeader call, headers already sent", "status", statusCode)
 		return
 	}
 	w.ResponseWriter.WriteHeader(statusCode)
@@ -55,6 +71,33 @@ func (w *trackingResponseWriter) Unwrap() http.ResponseWriter {
 	return w.ResponseWriter
 }
 
+// Hijack lets the caller take over the connection (e.g. for a WebSocket upgrade).
+// Once hijacked, the response is considered started.
+func (w *trackingResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, brw, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+	w.headerWritten.Store(true)
+	return conn, brw, nil
+}
+
+// addLogAttrs adds attributes (e.g. the session ID) to the log lines emitted
+// while serving the response. It is a no-op if rw is not a trackingResponseWriter.
+func addLogAttrs(rw http.ResponseWriter, args ...any) {
+	if tw, ok := rw.(*trackingResponseWriter); ok {
+		tw.logger = tw.logger.With(args...)
+	}
+}
+
+// responseLogger returns the logger used for the response written to rw.
+func responseLogger(rw http.ResponseWriter, req *http.Request) *slog.Logger {
+	if tw, ok := rw.(*trackingResponseWriter); ok {
+		return tw.logger
+	}
+	return logging.FromContext(req.Context())
+}
+
 // EncodeJSONResponse uses the json encoder to write an interface to the http response with an optional status code
 func EncodeJSONResponse(i any, status int, w http.ResponseWriter) {
 	wHeader := w.Header()
@@ -68,44 +111,89 @@ func EncodeJSONResponse(i any, status int, w http.ResponseWriter) {
 			// Only attempt error response if headers haven't been written yet
 			// If w is our trackingResponseWriter, we can check safely
 			if tw, ok := w.(*trackingResponseWriter); ok && tw.headerWritten.Load() {
-				log.Printf("ADK: Failed to encode JSON response after headers written: %v", err)
+				tw.logger.Error("ADK: failed to encode JSON response after headers written", "status", status, "error", err)
 				return
 			}
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeError(w, nil, err)
 		}
 	}
 }
 
 type errorHandler func(http.ResponseWriter, *http.Request) error
 
-// NewErrorHandler writes the error code returned from the http handler.
+// NewErrorHandler writes the error returned from the http handler as a JSON
+// [ErrorResponse], using the status code of the error if it has one.
 // It uses trackingResponseWriter to prevent "superfluous WriteHeader" errors
 // when handlers return errors after already starting to write a response (e.g., SSE streaming).
+// Panics of the handler, e.g. in a tool, are recovered and reported as
+// internal server errors, see [recoverHandler].
 func NewErrorHandler(fn errorHandler) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Wrap the response writer to track if headers have been written
-		tw := &trackingResponseWriter{ResponseWriter: w}
+		tw := &trackingResponseWriter{
+			ResponseWriter: w,
+			logger:         logging.FromContext(r.Context()).With("path", r.URL.Path),
+		}
+		defer func() {
+			if v := recover(); v != nil {
+				recoverHandler(tw, r, v)
+			}
+		}()
 
 		err := fn(tw, r)
 		if err != nil {
 			// Check if headers were already written (e.g., during SSE streaming)
 			if tw.headerWritten.Load() {
 				// Headers already written, just log the error
-				log.Printf("ADK: Error occurred after response started: %v", err)
+				tw.logger.Error("ADK: error occurred after response started", "error", err)
 				return
 			}
 
-			if statusErr, ok := err.(statusError); ok {
-				// Use tw to ensure safety, though we know headers aren't written yet
-				http.Error(tw, statusErr.Error(), statusErr.Status())
-			} else {
-				http.Error(tw, err.Error(), http.StatusInternalServerError)
-			}
+			// Errors without a status code are reported as internal server errors.
+			writeError(tw, r, err)
+		}
+	}
+}
+
+// ErrInternal is reported to the clients of the handlers which panicked. The
+// panic itself is only logged, as it may reveal details of the server.
+var ErrInternal = errors.New("internal error")
+
+// recoverHandler reports the panic v of a handler: it is logged with its
+// stack and recorded on the span of the request, if any, and the client
+// receives an [ErrInternal] error, either as a JSON [ErrorResponse] or as the
+// last frame of the stream which was being written. The panics of the runs
+// are also recorded on the spans of their invocation, see
+// [RuntimeAPIController.startRun]. [http.ErrAbortHandler] panics are
+// propagated, as they are used to abort the response on purpose.
+func recoverHandler(tw *trackingResponseWriter, r *http.Request, v any) {
+	if err, ok := v.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+		panic(v)
+	}
+	stack := string(debug.Stack())
+	tw.logger.Error("ADK: handler panicked", "panic", v, "stack", stack)
+	recordPanic(trace.SpanFromContext(r.Context()), v, stack)
+
+	err := statusError{Err: ErrInternal, Code: http.StatusInternalServerError, Reason: "INTERNAL"}
+	switch {
+	case !tw.headerWritten.Load():
+		writeError(tw, r, err)
+	case tw.stream != nil:
+		// Let the client know that the stream is over, rather than leaving it
+		// waiting for more frames.
+		if err := tw.stream.writeError(err); err != nil {
+			tw.logger.Error("ADK: failed to write the error frame of a panicked handler", "error", err)
 		}
 	}
 }
 
+// recordPanic records the panic v and its stack as an exception of span.
+func recordPanic(span trace.Span, v any, stack string) {
+	span.RecordError(fmt.Errorf("panic: %v", v), trace.WithAttributes(attribute.String("exception.stacktrace", stack)))
+	span.SetStatus(codes.Error, "panic")
+}
+
 // Unimplemented returns 501 - Status Not Implemented error
 func Unimplemented(rw http.ResponseWriter, req *http.Request) {
-	rw.WriteHeader(http.StatusNotImplemented)
+	httpError(rw, req, "not implemented", http.StatusNotImplemented)
 }
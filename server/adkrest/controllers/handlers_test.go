@@ -0,0 +1,179 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"google.golang.org/adk/server/adkrest/internal/logging"
+)
+
+func TestNewErrorHandler_Logging(t *testing.T) {
+	tc := []struct {
+		name      string
+		handler   errorHandler
+		wantLevel string
+		wantAttrs map[string]any
+	}{
+		{
+			name: "duplicate WriteHeader",
+			handler: func(rw http.ResponseWriter, req *http.Request) error {
+				rw.WriteHeader(http.StatusOK)
+				rw.WriteHeader(http.StatusInternalServerError)
+				return nil
+			},
+			wantLevel: "WARN",
+			wantAttrs: map[string]any{"path": "/run_sse", "status": float64(http.StatusInternalServerError), "request_id": "req-1"},
+		},
+		{
+			name: "error after response started",
+			handler: func(rw http.ResponseWriter, req *http.Request) error {
+				addLogAttrs(rw, "session_id", "s1")
+				rw.WriteHeader(http.StatusOK)
+				return errors.New("stream broke")
+			},
+			wantLevel: "ERROR",
+			wantAttrs: map[string]any{"path": "/run_sse", "session_id": "s1", "error": "stream broke", "request_id": "req-1"},
+		},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := slog.New(slog.NewJSONHandler(&buf, nil)).With("request_id", "req-1")
+			req := httptest.NewRequest(http.MethodPost, "/run_sse", nil)
+			req = req.WithContext(logging.ToContext(req.Context(), logger))
+
+			NewErrorHandler(tt.handler)(httptest.NewRecorder(), req)
+
+			var got map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+				t.Fatalf("failed to decode log line %q: %v", buf.String(), err)
+			}
+			if got["level"] != tt.wantLevel {
+				t.Errorf("level = %v, want %v", got["level"], tt.wantLevel)
+			}
+			for k, want := range tt.wantAttrs {
+				if got[k] != want {
+					t.Errorf("attribute %q = %v, want %v", k, got[k], want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewErrorHandler_Recover(t *testing.T) {
+	tc := []struct {
+		name            string
+		handler         errorHandler
+		wantStatus      int
+		wantContentType string
+		wantBody        string
+	}{
+		{
+			name: "panic before the response",
+			handler: func(rw http.ResponseWriter, req *http.Request) error {
+				panic("tool failed")
+			},
+			wantStatus:      http.StatusInternalServerError,
+			wantContentType: "application/json; charset=UTF-8",
+			wantBody:        `{"error":{"code":"INTERNAL","message":"internal error","status":500}}` + "\n",
+		},
+		{
+			name: "panic mid stream",
+			handler: func(rw http.ResponseWriter, req *http.Request) error {
+				stream := newStreamWriter(rw, http.NewResponseController(rw), req)
+				stream.start(-1)
+				defer stream.close()
+				if err := stream.writeEnd("inv-1"); err != nil {
+					return err
+				}
+				panic(errors.New("tool failed"))
+			},
+			wantStatus:      http.StatusOK,
+			wantContentType: ContentTypeSSE,
+			wantBody: "event: end\ndata: {\"invocationId\":\"inv-1\"}\n\n" +
+				"event: error\ndata: {\"error\":{\"code\":\"INTERNAL\",\"message\":\"internal error\",\"status\":500}}\n\n",
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			recorder := tracetest.NewSpanRecorder()
+			tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+			ctx, span := tp.Tracer("test").Start(context.Background(), "request")
+			req := httptest.NewRequest(http.MethodPost, "/run_sse", nil)
+			req = req.WithContext(logging.ToContext(ctx, slog.New(slog.NewJSONHandler(&logs, nil))))
+			rr := httptest.NewRecorder()
+
+			NewErrorHandler(tt.handler)(rr, req)
+			span.End()
+
+			if rr.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rr.Code, tt.wantStatus)
+			}
+			if got := rr.Header().Get("Content-Type"); got != tt.wantContentType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.wantContentType)
+			}
+			if diff := cmp.Diff(tt.wantBody, rr.Body.String()); diff != "" {
+				t.Errorf("body mismatch (-want +got):\n%s", diff)
+			}
+
+			var line map[string]any
+			if err := json.Unmarshal(logs.Bytes(), &line); err != nil {
+				t.Fatalf("failed to decode log line %q: %v", logs.String(), err)
+			}
+			if line["level"] != "ERROR" || line["panic"] != "tool failed" || !strings.Contains(fmt.Sprint(line["stack"]), "TestNewErrorHandler_Recover") {
+				t.Errorf("log line = %v, want an error with the panic and its stack", line)
+			}
+
+			spans := recorder.Ended()
+			if len(spans) != 1 {
+				t.Fatalf("%d spans ended, want 1", len(spans))
+			}
+			if got := spans[0].Status().Code; got != codes.Error {
+				t.Errorf("span status = %v, want %v", got, codes.Error)
+			}
+			if events := spans[0].Events(); len(events) != 1 || events[0].Name != "exception" {
+				t.Errorf("span events = %v, want the exception of the panic", events)
+			}
+		})
+	}
+}
+
+func TestNewErrorHandler_RecoverAbort(t *testing.T) {
+	defer func() {
+		if got := recover(); got != http.ErrAbortHandler {
+			t.Errorf("recovered %v, want %v", got, http.ErrAbortHandler)
+		}
+	}()
+	NewErrorHandler(func(rw http.ResponseWriter, req *http.Request) error {
+		panic(http.ErrAbortHandler)
+	})(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
@@ -0,0 +1,145 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"google.golang.org/adk/server/adkrest/internal/models"
+)
+
+const (
+	// DefaultHealthCheckTimeout is the default timeout of a readiness check.
+	DefaultHealthCheckTimeout = 2 * time.Second
+	// DefaultHealthCacheTTL is the default duration for which the readiness
+	// results are reused.
+	DefaultHealthCacheTTL = 2 * time.Second
+)
+
+// HealthChecker is implemented by the services which can check that their
+// dependencies are reachable, e.g. a database. Services implementing it are
+// discovered automatically by the readiness endpoint.
+type HealthChecker interface {
+	// Ping returns an error if the service can't serve requests.
+	Ping(ctx context.Context) error
+}
+
+// HealthCheckerFunc adapts a function to the [HealthChecker] interface.
+type HealthCheckerFunc func(ctx context.Context) error
+
+// Ping implements [HealthChecker].
+func (f HealthCheckerFunc) Ping(ctx context.Context) error {
+	return f(ctx)
+}
+
+// HealthAPIOptions contains optional settings of the Health API controller.
+// The zero value is valid and uses defaults.
+type HealthAPIOptions struct {
+	// CheckTimeout bounds the duration of every readiness check.
+	// Optional: if zero, [DefaultHealthCheckTimeout] is used.
+	CheckTimeout time.Duration
+	// CacheTTL is how long the readiness results are reused, so that frequent
+	// probes don't overload the dependencies.
+	// Optional: if zero, [DefaultHealthCacheTTL] is used. A negative value
+	// disables caching.
+	CacheTTL time.Duration
+}
+
+// HealthAPIController is the controller for the liveness and readiness
+// endpoints.
+type HealthAPIController struct {
+	checks map[string]HealthChecker
+	opts   HealthAPIOptions
+
+	mu        sync.Mutex
+	checkedAt time.Time
+	status    models.HealthStatus
+}
+
+// NewHealthAPIController creates the controller for the Health API. The
+// readiness endpoint runs the checkers of checks by name; nil values, e.g.
+// services which don't implement [HealthChecker], are skipped.
+func NewHealthAPIController(checks map[string]HealthChecker, opts HealthAPIOptions) *HealthAPIController {
+	if opts.CheckTimeout == 0 {
+		opts.CheckTimeout = DefaultHealthCheckTimeout
+	}
+	if opts.CacheTTL == 0 {
+		opts.CacheTTL = DefaultHealthCacheTTL
+	}
+	c := &HealthAPIController{checks: make(map[string]HealthChecker), opts: opts}
+	for name, check := range checks {
+		if check != nil {
+			c.checks[name] = check
+		}
+	}
+	return c
+}
+
+// HealthzHandler reports that the server is alive.
+func (c *HealthAPIController) HealthzHandler(rw http.ResponseWriter, req *http.Request) {
+	EncodeJSONResponse(models.HealthStatus{Status: models.HealthStatusOK}, http.StatusOK, rw)
+}
+
+// ReadyzHandler runs the readiness checks and reports their results, with
+// 503 status code if any of them failed.
+func (c *HealthAPIController) ReadyzHandler(rw http.ResponseWriter, req *http.Request) {
+	status := c.ready(req.Context())
+	code := http.StatusOK
+	if status.Status != models.HealthStatusOK {
+		code = http.StatusServiceUnavailable
+	}
+	EncodeJSONResponse(status, code, rw)
+}
+
+// ready returns the cached results if they are fresh, and runs the checks
+// otherwise. Concurrent callers wait for a single run of the checks.
+func (c *HealthAPIController) ready(ctx context.Context) models.HealthStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.checkedAt.IsZero() && time.Since(c.checkedAt) < c.opts.CacheTTL {
+		return c.status
+	}
+
+	status := models.HealthStatus{Status: models.HealthStatusOK, Checks: make(map[string]models.CheckStatus, len(c.checks))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// The results are shared with other callers, so they must not
+			// depend on this request being cancelled.
+			ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.opts.CheckTimeout)
+			defer cancel()
+			result := models.CheckStatus{Status: models.HealthStatusOK}
+			if err := check.Ping(ctx); err != nil {
+				result = models.CheckStatus{Status: models.HealthStatusFailed, Error: err.Error()}
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			status.Checks[name] = result
+			if result.Status != models.HealthStatusOK {
+				status.Status = models.HealthStatusFailed
+			}
+		}()
+	}
+	wg.Wait()
+
+	c.status, c.checkedAt = status, time.Now()
+	return status
+}
@@ -0,0 +1,136 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"google.golang.org/adk/server/adkrest/controllers"
+	"google.golang.org/adk/server/adkrest/internal/models"
+)
+
+func TestHealthAPIController(t *testing.T) {
+	ok := controllers.HealthCheckerFunc(func(ctx context.Context) error { return nil })
+	failing := controllers.HealthCheckerFunc(func(ctx context.Context) error { return errors.New("connection refused") })
+	slow := controllers.HealthCheckerFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	tests := []struct {
+		name       string
+		checks     map[string]controllers.HealthChecker
+		wantStatus int
+		want       models.HealthStatus
+	}{
+		{
+			name:       "no checks",
+			wantStatus: http.StatusOK,
+			want:       models.HealthStatus{Status: models.HealthStatusOK},
+		},
+		{
+			name:       "all passing",
+			checks:     map[string]controllers.HealthChecker{"db": ok, "model": ok, "unsupported": nil},
+			wantStatus: http.StatusOK,
+			want: models.HealthStatus{Status: models.HealthStatusOK, Checks: map[string]models.CheckStatus{
+				"db":    {Status: models.HealthStatusOK},
+				"model": {Status: models.HealthStatusOK},
+			}},
+		},
+		{
+			name:       "failing",
+			checks:     map[string]controllers.HealthChecker{"db": failing, "model": ok},
+			wantStatus: http.StatusServiceUnavailable,
+			want: models.HealthStatus{Status: models.HealthStatusFailed, Checks: map[string]models.CheckStatus{
+				"db":    {Status: models.HealthStatusFailed, Error: "connection refused"},
+				"model": {Status: models.HealthStatusOK},
+			}},
+		},
+		{
+			name:       "timeout",
+			checks:     map[string]controllers.HealthChecker{"db": slow},
+			wantStatus: http.StatusServiceUnavailable,
+			want: models.HealthStatus{Status: models.HealthStatusFailed, Checks: map[string]models.CheckStatus{
+				"db": {Status: models.HealthStatusFailed, Error: context.DeadlineExceeded.Error()},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := controllers.NewHealthAPIController(tt.checks, controllers.HealthAPIOptions{CheckTimeout: 10 * time.Millisecond})
+
+			rr := httptest.NewRecorder()
+			c.HealthzHandler(rr, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+			if rr.Code != http.StatusOK {
+				t.Errorf("HealthzHandler() status = %v, want %v", rr.Code, http.StatusOK)
+			}
+
+			rr = httptest.NewRecorder()
+			c.ReadyzHandler(rr, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			if rr.Code != tt.wantStatus {
+				t.Errorf("ReadyzHandler() status = %v, want %v", rr.Code, tt.wantStatus)
+			}
+			var got models.HealthStatus
+			if err := json.NewDecoder(rr.Body).Decode(&got); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("ReadyzHandler() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestHealthAPIController_Cache(t *testing.T) {
+	var calls atomic.Int32
+	check := controllers.HealthCheckerFunc(func(ctx context.Context) error {
+		calls.Add(1)
+		return nil
+	})
+
+	tests := []struct {
+		name      string
+		cacheTTL  time.Duration
+		wantCalls int32
+	}{
+		{name: "cached", cacheTTL: time.Hour, wantCalls: 1},
+		{name: "not cached", cacheTTL: -1, wantCalls: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls.Store(0)
+			c := controllers.NewHealthAPIController(map[string]controllers.HealthChecker{"db": check}, controllers.HealthAPIOptions{CacheTTL: tt.cacheTTL})
+			for range 3 {
+				rr := httptest.NewRecorder()
+				c.ReadyzHandler(rr, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+				if rr.Code != http.StatusOK {
+					t.Fatalf("ReadyzHandler() status = %v, want %v", rr.Code, http.StatusOK)
+				}
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("checker called %d times, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
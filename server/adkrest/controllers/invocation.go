@@ -0,0 +1,346 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/plugin"
+	"google.golang.org/adk/server/adkrest/internal/models"
+	"google.golang.org/adk/server/adkrest/metrics"
+	"google.golang.org/adk/session"
+)
+
+// ErrRunCancelled is reported to the runs cancelled through the cancel endpoint.
+var ErrRunCancelled = errors.New("invocation was cancelled")
+
+// invocation is the state of a run started by a handler. It is shared with
+// the runner through the context, so that the handler learns the ID of the
+// invocation as soon as it starts.
+type invocation struct {
+	cancel context.CancelCauseFunc
+	// startSignal is closed once the invocation has started.
+	startSignal chan struct{}
+	// spans trace the run of the invocation.
+	spans []trace.Span
+
+	mu          sync.Mutex
+	id          string
+	key         invocationKey
+	finished    bool
+	subscribers map[chan *session.Event]struct{}
+
+	// Counters reported to the metrics recorder.
+	agentName string
+	started   time.Time
+	events    int
+	llmCalls  int
+}
+
+// subscriberBuffer is the number of events buffered for each subscriber.
+// Subscribers which fall further behind are dropped.
+const subscriberBuffer = 64
+
+// invocationKey identifies a running invocation.
+type invocationKey struct {
+	appName, userID, sessionID, invocationID string
+}
+
+type invocationCtxKey struct{}
+
+func withInvocation(ctx context.Context) (context.Context, *invocation) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	inv := &invocation{cancel: cancel, startSignal: make(chan struct{})}
+	return context.WithValue(ctx, invocationCtxKey{}, inv), inv
+}
+
+func invocationFromContext(ctx context.Context) *invocation {
+	inv, _ := ctx.Value(invocationCtxKey{}).(*invocation)
+	return inv
+}
+
+// ID returns the ID of the invocation, or an empty string if it hasn't started yet.
+func (inv *invocation) ID() string {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	return inv.id
+}
+
+func (inv *invocation) start(key invocationKey, agentName string) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	if inv.id == "" {
+		close(inv.startSignal)
+	}
+	inv.id = key.invocationID
+	inv.key = key
+	inv.agentName = agentName
+	inv.started = time.Now()
+}
+
+func (inv *invocation) invocationKey() invocationKey {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	return inv.key
+}
+
+func (inv *invocation) countLLMCall() {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	inv.llmCalls++
+}
+
+// stats returns the metrics of the invocation, or false if it never started.
+func (inv *invocation) stats() (metrics.InvocationStats, bool) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	if inv.id == "" {
+		return metrics.InvocationStats{}, false
+	}
+	return metrics.InvocationStats{
+		AppName:   inv.key.appName,
+		AgentName: inv.agentName,
+		Duration:  time.Since(inv.started),
+		Events:    inv.events,
+		LLMCalls:  inv.llmCalls,
+	}, true
+}
+
+// subscribe returns a channel on which the events published from now on are
+// delivered. The channel is closed once the invocation finishes, or if the
+// subscriber doesn't keep up. unsubscribe must be called when the subscriber
+// is done.
+func (inv *invocation) subscribe() (events <-chan *session.Event, unsubscribe func()) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	ch := make(chan *session.Event, subscriberBuffer)
+	if inv.finished {
+		close(ch)
+		return ch, func() {}
+	}
+	if inv.subscribers == nil {
+		inv.subscribers = make(map[chan *session.Event]struct{})
+	}
+	inv.subscribers[ch] = struct{}{}
+	return ch, func() {
+		inv.mu.Lock()
+		defer inv.mu.Unlock()
+		if _, ok := inv.subscribers[ch]; ok {
+			delete(inv.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// publish delivers the event to the subscribers.
+func (inv *invocation) publish(event *session.Event) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	inv.events++
+	for ch := range inv.subscribers {
+		select {
+		case ch <- event:
+		default:
+			// Don't let a slow subscriber block the run.
+			delete(inv.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// finish marks the invocation as finished and closes the subscriber channels.
+func (inv *invocation) finish() {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	inv.finished = true
+	for ch := range inv.subscribers {
+		close(ch)
+	}
+	inv.subscribers = nil
+}
+
+// recordPanic records the panic v of the run, and its stack, on the spans
+// of the invocation.
+func (inv *invocation) recordPanic(v any, stack string) {
+	for _, span := range inv.spans {
+		recordPanic(span, v, stack)
+	}
+}
+
+func (inv *invocation) endSpans() {
+	for _, span := range inv.spans {
+		span.End()
+	}
+}
+
+func (inv *invocation) isFinished() bool {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	return inv.finished
+}
+
+// invocationRegistry keeps track of the running invocations, so that they
+// can be cancelled.
+type invocationRegistry struct {
+	mu          sync.Mutex
+	invocations map[invocationKey]*invocation
+}
+
+func (r *invocationRegistry) add(inv *invocation) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.invocations == nil {
+		r.invocations = make(map[invocationKey]*invocation)
+	}
+	r.invocations[inv.key] = inv
+}
+
+// remove removes the invocation once its handler is done. It is a no-op if
+// the invocation never started.
+func (r *invocationRegistry) remove(inv *invocation) {
+	inv.mu.Lock()
+	key := inv.key
+	inv.mu.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.invocations[key] == inv {
+		delete(r.invocations, key)
+	}
+}
+
+func (r *invocationRegistry) get(key invocationKey) (*invocation, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	inv, ok := r.invocations[key]
+	return inv, ok
+}
+
+// newInvocationPlugin returns a plugin which reports the start of the
+// invocations to the handlers which requested them, registers them for
+// cancellation and counts their model calls.
+func (c *RuntimeAPIController) newInvocationPlugin() (*plugin.Plugin, error) {
+	return plugin.New(plugin.Config{
+		Name: "adk_rest_api",
+		BeforeRunCallback: func(ctx agent.InvocationContext) (*genai.Content, error) {
+			inv := invocationFromContext(ctx)
+			if inv == nil {
+				return nil, nil
+			}
+			sess := ctx.Session()
+			inv.start(invocationKey{
+				appName:      sess.AppName(),
+				userID:       sess.UserID(),
+				sessionID:    sess.ID(),
+				invocationID: ctx.InvocationID(),
+			}, ctx.Agent().Name())
+			c.invocations.add(inv)
+			return nil, nil
+		},
+		BeforeModelCallback: func(ctx agent.CallbackContext, llmRequest *model.LLMRequest) (*model.LLMResponse, error) {
+			if inv := invocationFromContext(ctx); inv != nil {
+				inv.countLLMCall()
+			}
+			return nil, nil
+		},
+	})
+}
+
+// CancelInvocationHandler cancels a running invocation. Its handler ends the
+// response as if the agent was interrupted, and the session records that the
+// invocation was cancelled.
+func (c *RuntimeAPIController) CancelInvocationHandler(rw http.ResponseWriter, req *http.Request) error {
+	params := mux.Vars(req)
+	sessionID, err := models.SessionIDFromHTTPParameters(params)
+	if err != nil {
+		return newStatusError(err, http.StatusBadRequest)
+	}
+	invocationID := params["invocation_id"]
+	if sessionID.ID == "" || invocationID == "" {
+		return newStatusError(errors.New("session_id and invocation_id parameters are required"), http.StatusBadRequest)
+	}
+	addLogAttrs(rw, "app_name", sessionID.AppName, "session_id", sessionID.ID, "invocation_id", invocationID)
+
+	inv, ok := c.invocations.get(invocationKey{
+		appName:      sessionID.AppName,
+		userID:       sessionID.UserID,
+		sessionID:    sessionID.ID,
+		invocationID: invocationID,
+	})
+	if ok {
+		inv.cancel(ErrRunCancelled)
+		EncodeJSONResponse(nil, http.StatusOK, rw)
+		return nil
+	}
+
+	// The invocation is not running. Tell apart finished invocations from
+	// unknown ones by looking at the session history.
+	resp, err := c.sessionService.Get(req.Context(), &session.GetRequest{
+		AppName:   sessionID.AppName,
+		UserID:    sessionID.UserID,
+		SessionID: sessionID.ID,
+	})
+	if err != nil {
+		return newStatusError(fmt.Errorf("failed to get session: %w", err), http.StatusNotFound)
+	}
+	for event := range resp.Session.Events().All() {
+		if event.InvocationID == invocationID {
+			return newStatusError(fmt.Errorf("invocation %q has already finished", invocationID), http.StatusConflict)
+		}
+	}
+	return newStatusError(fmt.Errorf("invocation %q not found", invocationID), http.StatusNotFound)
+}
+
+// recordInterruption appends an event to the session which marks the
+// invocation as interrupted by the server, so that clients reading the
+// session know that the invocation didn't complete.
+func (c *RuntimeAPIController) recordInterruption(ctx context.Context, appName, userID, sessionID, invocationID string, cause statusError) error {
+	if invocationID == "" {
+		// The invocation didn't start, so there is nothing to mark.
+		return nil
+	}
+	// The run context is already done, but the marker must be persisted anyway.
+	ctx = context.WithoutCancel(ctx)
+	resp, err := c.sessionService.Get(ctx, &session.GetRequest{AppName: appName, UserID: userID, SessionID: sessionID})
+	if err != nil {
+		return fmt.Errorf("failed to get session: %w", err)
+	}
+	curAgent, err := c.agentLoader.LoadAgent(appName)
+	if err != nil {
+		return fmt.Errorf("failed to load agent: %w", err)
+	}
+	event := session.NewEvent(invocationID)
+	event.Author = curAgent.Name()
+	event.LLMResponse = model.LLMResponse{
+		TurnComplete: true,
+		Interrupted:  true,
+		ErrorCode:    cause.ErrorCode(),
+		ErrorMessage: cause.Error(),
+	}
+	if err := c.sessionService.AppendEvent(ctx, resp.Session, event); err != nil {
+		return fmt.Errorf("failed to append event: %w", err)
+	}
+	return nil
+}
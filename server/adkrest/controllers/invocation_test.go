@@ -0,0 +1,121 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/server/adkrest/controllers"
+	"google.golang.org/adk/server/adkrest/internal/models"
+	"google.golang.org/adk/session"
+)
+
+func TestCancelInvocationHandler(t *testing.T) {
+	a := newSlowAgent(t, 20*time.Millisecond, "first", "second", "third", "fourth", "fifth")
+	sessionService := session.InMemoryService()
+	if _, err := sessionService.Create(context.Background(), &session.CreateRequest{AppName: a.Name(), UserID: "user", SessionID: "session"}); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	controller := controllers.NewRuntimeAPIControllerWithOptions(sessionService, nil, agent.NewSingleLoader(a), nil, 10*time.Second, runner.PluginConfig{}, controllers.RuntimeAPIOptions{SSEKeepAliveInterval: -1})
+	router := mux.NewRouter()
+	router.HandleFunc("/run_sse", controllers.NewErrorHandler(controller.RunSSEHandler))
+	router.HandleFunc("/apps/{app_name}/users/{user_id}/sessions/{session_id}/invocations/{invocation_id}:cancel", controllers.NewErrorHandler(controller.CancelInvocationHandler))
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+
+	cancel := func(invocationID string) int {
+		t.Helper()
+		resp, err := http.Post(srv.URL+"/apps/slow/users/user/sessions/session/invocations/"+invocationID+":cancel", "application/json", nil)
+		if err != nil {
+			t.Fatalf("Post() failed: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	body, err := json.Marshal(models.RunAgentRequest{
+		AppName:    a.Name(),
+		UserId:     "user",
+		SessionId:  "session",
+		NewMessage: *genai.NewContentFromText("hi", genai.RoleUser),
+	})
+	if err != nil {
+		t.Fatalf("json.Marshal() failed: %v", err)
+	}
+	resp, err := http.Post(srv.URL+"/run_sse", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("Post() failed: %v", err)
+	}
+	defer resp.Body.Close()
+	reader := bufio.NewReader(resp.Body)
+	frame := readSSEFrame(t, reader)
+	var first models.Event
+	if _, data := parseSSEFrame(frame); json.Unmarshal([]byte(data), &first) != nil {
+		t.Fatalf("malformed event frame %q", frame)
+	}
+
+	if got := cancel(first.InvocationID); got != http.StatusOK {
+		t.Fatalf("cancel status = %d, want %d", got, http.StatusOK)
+	}
+
+	rest, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("ReadAll() failed: %v", err)
+	}
+	frames := strings.Split(strings.TrimSpace(string(rest)), "\n\n")
+	last := frames[len(frames)-1]
+	event, data := parseSSEFrame(last)
+	if event != "error" {
+		t.Fatalf("last frame = %q, want an error event", last)
+	}
+	var errResp controllers.ErrorResponse
+	if err := json.Unmarshal([]byte(data), &errResp); err != nil {
+		t.Fatalf("malformed error frame %q: %v", last, err)
+	}
+	if errResp.Error.Code != "CANCELLED" {
+		t.Errorf("error code = %q, want %q", errResp.Error.Code, "CANCELLED")
+	}
+
+	getResp, err := sessionService.Get(context.Background(), &session.GetRequest{AppName: a.Name(), UserID: "user", SessionID: "session"})
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	events := getResp.Session.Events()
+	marker := events.At(events.Len() - 1)
+	if !marker.Interrupted || marker.ErrorCode != "CANCELLED" || marker.InvocationID != first.InvocationID {
+		t.Errorf("unexpected cancellation marker: %+v", marker)
+	}
+
+	if got := cancel(first.InvocationID); got != http.StatusConflict {
+		t.Errorf("cancel of finished invocation status = %d, want %d", got, http.StatusConflict)
+	}
+	if got := cancel("unknown"); got != http.StatusNotFound {
+		t.Errorf("cancel of unknown invocation status = %d, want %d", got, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultSSEKeepAliveInterval is the keep-alive interval used when none is configured.
+const DefaultSSEKeepAliveInterval = 15 * time.Second
+
+// streamKeepAlive writes keep-alive frames (e.g. SSE comments) to an otherwise
+// idle stream, so that proxies and load balancers don't terminate the
+// connection while the agent is busy (e.g. inside a long tool call).
+//
+// All writes to the stream must happen inside write, which guarantees that a
+// keep-alive frame is never interleaved with a partially written event.
+type streamKeepAlive struct {
+	rw       http.ResponseWriter
+	rc       *http.ResponseController
+	interval time.Duration
+	frame    []byte
+
+	mu        sync.Mutex
+	lastFlush time.Time
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// startStreamKeepAlive starts writing the keep-alive frame every interval
+// during which nothing was written to the stream. A non-positive interval
+// disables keep-alives, but the returned value is still safe to use.
+// The caller must call close before the handler returns.
+func startStreamKeepAlive(rw http.ResponseWriter, rc *http.ResponseController, interval time.Duration, frame string) *streamKeepAlive {
+	k := &streamKeepAlive{
+		rw:        rw,
+		rc:        rc,
+		interval:  interval,
+		frame:     []byte(frame),
+		lastFlush: time.Now(),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	if interval <= 0 {
+		close(k.done)
+		return k
+	}
+	go k.loop()
+	return k
+}
+
+func (k *streamKeepAlive) loop() {
+	defer close(k.done)
+	ticker := time.NewTicker(k.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-k.stop:
+			return
+		case <-ticker.C:
+			k.mu.Lock()
+			if time.Since(k.lastFlush) >= k.interval {
+				if _, err := k.rw.Write(k.frame); err != nil {
+					k.mu.Unlock()
+					return
+				}
+				if err := k.rc.Flush(); err != nil {
+					k.mu.Unlock()
+					return
+				}
+				k.lastFlush = time.Now()
+			}
+			k.mu.Unlock()
+		}
+	}
+}
+
+// write runs fn, which writes and flushes a complete frame, while holding the
+// stream exclusively.
+func (k *streamKeepAlive) write(fn func() error) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	err := fn()
+	k.lastFlush = time.Now()
+	return err
+}
+
+// close stops the keep-alive goroutine and waits for it to exit, so no writes
+// happen after the handler returns.
+func (k *streamKeepAlive) close() {
+	k.stopOnce.Do(func() { close(k.stop) })
+	<-k.done
+}
@@ -0,0 +1,316 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/server/adkrest/internal/models"
+)
+
+var liveUpgrader = websocket.Upgrader{}
+
+// RunLiveHandler upgrades the connection to a WebSocket and runs the agent
+// with the messages received from the client, streaming the resulting events
+// back as JSON frames.
+//
+// If the agent runs live, see [runner.Runner.SupportsLive], the connection is
+// a single invocation in a live session of its model: the content and the
+// realtime media blobs, e.g. audio, of the messages are forwarded to the
+// model as they come, see [runner.Runner.RunLive].
+//
+// Otherwise, every content message is a user turn which starts its own
+// invocation, and the messages received while a turn runs wait for it to
+// finish. The messages which carry a blob are rejected with an error frame,
+// as the agent has no live session to stream them to.
+//
+// The turns are runs like those of the run_sse endpoint: they are rate
+// limited, bounded by the maximum run duration, serialized with the other
+// runs of the session and can be cancelled. A turn which can't start or is
+// interrupted is reported with an error frame, and the connection stays open
+// unless the server is shutting down.
+//
+// The session is identified by the app_name, user_id and session_id query
+// parameters. The optional response_modalities parameter, a comma separated
+// list such as "AUDIO", sets the modalities of the responses of the model.
+// Errors detected before the upgrade are returned as regular HTTP errors.
+func (c *RuntimeAPIController) RunLiveHandler(rw http.ResponseWriter, req *http.Request) error {
+	query := req.URL.Query()
+	appName, userID, sessionID := query.Get("app_name"), query.Get("user_id"), query.Get("session_id")
+	if appName == "" || userID == "" || sessionID == "" {
+		return newStatusError(fmt.Errorf("app_name, user_id and session_id query parameters are required"), http.StatusBadRequest)
+	}
+	addLogAttrs(rw, "app_name", appName, "session_id", sessionID)
+	if err := checkUser(req, userID); err != nil {
+		return err
+	}
+
+	// The connection is tracked as a run too, so that idle connections are
+	// closed on shutdown.
+	connCtx, done, err := c.runs.start(req.Context())
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	err = c.validateSessionExists(connCtx, appName, userID, sessionID)
+	if err != nil {
+		return err
+	}
+
+	var modalities []genai.Modality
+	if value := query.Get("response_modalities"); value != "" {
+		for _, modality := range strings.Split(value, ",") {
+			modalities = append(modalities, genai.Modality(strings.ToUpper(strings.TrimSpace(modality))))
+		}
+	}
+
+	r, rCfg, err := c.getRunner(models.RunAgentRequest{
+		AppName:   appName,
+		Streaming: true,
+		RunConfig: &models.RunConfig{ResponseModalities: modalities},
+	})
+	if err != nil {
+		return err
+	}
+
+	conn, err := liveUpgrader.Upgrade(rw, req, nil)
+	if err != nil {
+		// The upgrader has already replied to the client.
+		return fmt.Errorf("failed to upgrade connection: %w", err)
+	}
+	defer conn.Close()
+
+	// The context is cancelled when the client goes away or the server shuts
+	// down, which stops the agent and unblocks the reader goroutine below.
+	ctx, cancel := context.WithCancel(connCtx)
+	defer cancel()
+	req = req.WithContext(ctx)
+
+	if r.SupportsLive() {
+		return c.runLiveSession(rw, req, conn, r, rCfg, models.RunAgentRequest{
+			AppName:   appName,
+			UserId:    userID,
+			SessionId: sessionID,
+		})
+	}
+
+	queue := make(chan models.LiveRequest)
+	go func() {
+		defer cancel()
+		defer close(queue)
+		for {
+			var liveReq models.LiveRequest
+			if err := conn.ReadJSON(&liveReq); err != nil {
+				return
+			}
+			if liveReq.Close {
+				return
+			}
+			select {
+			case queue <- liveReq:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for {
+		var liveReq models.LiveRequest
+		select {
+		case next, ok := <-queue:
+			if !ok {
+				return closeLive(ctx, conn)
+			}
+			liveReq = next
+		case <-ctx.Done():
+			return closeLive(ctx, conn)
+		}
+		if liveReq.Blob != nil {
+			if err := conn.WriteJSON(liveStatusErrorEvent(req, BadRequest("realtime blobs are not supported, send the user turns as content"))); err != nil {
+				return nil
+			}
+			continue
+		}
+		msg := liveReq.UserContent()
+		if msg == nil {
+			if err := conn.WriteJSON(liveStatusErrorEvent(req, BadRequest("live request must contain content"))); err != nil {
+				return nil
+			}
+			continue
+		}
+		runAgentRequest := models.RunAgentRequest{
+			AppName:    appName,
+			UserId:     userID,
+			SessionId:  sessionID,
+			NewMessage: *msg,
+			Streaming:  true,
+		}
+		err := c.runLiveTurn(rw, req, conn, r, rCfg, runAgentRequest)
+		if isShutdown(ctx) {
+			return closeLive(ctx, conn)
+		}
+		if err != nil {
+			// The client is gone, nothing else to do.
+			return nil
+		}
+	}
+}
+
+// runLiveSession runs the agent in a single live invocation for the
+// connection, whose messages are forwarded to the live session of the model
+// of the agent, see [runner.Runner.RunLive]. The invocation ends when the
+// client closes the connection or asks to close it.
+func (c *RuntimeAPIController) runLiveSession(rw http.ResponseWriter, req *http.Request, conn *websocket.Conn, r *runner.Runner, rCfg *agent.RunConfig, runAgentRequest models.RunAgentRequest) error {
+	// mu guards the writes to the connection, from the reader of the
+	// messages too.
+	var mu sync.Mutex
+	write := func(v any) error {
+		mu.Lock()
+		defer mu.Unlock()
+		return conn.WriteJSON(v)
+	}
+	closeConn := func() error {
+		mu.Lock()
+		defer mu.Unlock()
+		return closeLive(req.Context(), conn)
+	}
+
+	if err := c.checkRateLimit(rw, req, runAgentRequest); err != nil {
+		_ = write(liveStatusErrorEvent(req, err))
+		return closeConn()
+	}
+	ctx, done, err := c.startRun(req, runAgentRequest)
+	if err != nil {
+		_ = write(liveStatusErrorEvent(req, err))
+		return closeConn()
+	}
+	defer done()
+
+	queue := agent.NewLiveRequestQueue()
+	go func() {
+		defer queue.Close()
+		for {
+			var liveReq models.LiveRequest
+			if err := conn.ReadJSON(&liveReq); err != nil || liveReq.Close {
+				return
+			}
+			msg := liveReq.UserContent()
+			if msg == nil && liveReq.Blob == nil {
+				if err := write(liveStatusErrorEvent(req, BadRequest("live request must contain content or a blob"))); err != nil {
+					return
+				}
+				continue
+			}
+			if err := queue.Send(ctx, agent.LiveRequest{Content: msg, Blob: liveReq.Blob}); err != nil {
+				return
+			}
+		}
+	}()
+
+	for event, err := range r.RunLive(ctx, runAgentRequest.UserId, runAgentRequest.SessionId, queue, *rCfg) {
+		if err != nil {
+			if _, ok := interruption(ctx); ok {
+				break
+			}
+			if err := write(liveErrorEvent(err)); err != nil {
+				return nil
+			}
+			continue
+		}
+		invocationFromContext(ctx).publish(event)
+		if err := write(models.FromSessionEvent(*event)); err != nil {
+			// The client is gone, nothing else to do.
+			return nil
+		}
+	}
+	if cause, ok := interruption(ctx); ok {
+		c.interrupted(rw, req, runAgentRequest, invocationFromContext(ctx).ID(), cause)
+		_ = write(liveStatusErrorEvent(req, cause))
+	}
+	return closeConn()
+}
+
+// runLiveTurn runs the agent for a user turn and writes its events to the
+// connection. It only fails if the events can't be written.
+func (c *RuntimeAPIController) runLiveTurn(rw http.ResponseWriter, req *http.Request, conn *websocket.Conn, r *runner.Runner, rCfg *agent.RunConfig, runAgentRequest models.RunAgentRequest) error {
+	if err := c.checkRateLimit(rw, req, runAgentRequest); err != nil {
+		return conn.WriteJSON(liveStatusErrorEvent(req, err))
+	}
+	ctx, done, err := c.startRun(req, runAgentRequest)
+	if err != nil {
+		return conn.WriteJSON(liveStatusErrorEvent(req, err))
+	}
+	defer done()
+
+	for event, err := range r.Run(ctx, runAgentRequest.UserId, runAgentRequest.SessionId, &runAgentRequest.NewMessage, *rCfg) {
+		if err != nil {
+			if _, ok := interruption(ctx); ok {
+				break
+			}
+			if err := conn.WriteJSON(liveErrorEvent(err)); err != nil {
+				return err
+			}
+			continue
+		}
+		invocationFromContext(ctx).publish(event)
+		if err := conn.WriteJSON(models.FromSessionEvent(*event)); err != nil {
+			return err
+		}
+	}
+	if cause, ok := interruption(ctx); ok {
+		c.interrupted(rw, req, runAgentRequest, invocationFromContext(ctx).ID(), cause)
+		return conn.WriteJSON(liveStatusErrorEvent(req, cause))
+	}
+	return nil
+}
+
+// closeLive sends the close frame to the client: a normal closure, or
+// "going away" if the server is shutting down.
+func closeLive(ctx context.Context, conn *websocket.Conn) error {
+	msg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+	if isShutdown(ctx) {
+		msg = websocket.FormatCloseMessage(websocket.CloseGoingAway, ErrShuttingDown.Error())
+	}
+	_ = conn.WriteMessage(websocket.CloseMessage, msg)
+	return nil
+}
+
+func liveErrorEvent(err error) models.Event {
+	return models.Event{
+		ErrorCode:    "RUN_ERROR",
+		ErrorMessage: fmt.Sprintf("Error while running agent: %v", err),
+	}
+}
+
+// liveStatusErrorEvent reports an error which didn't come from the agent,
+// e.g. an invalid message of the client or a throttled turn, with the code
+// of the error responses of the REST API.
+func liveStatusErrorEvent(req *http.Request, err error) models.Event {
+	resp := newErrorResponse(req, err)
+	return models.Event{
+		ErrorCode:    resp.Error.Code,
+		ErrorMessage: resp.Error.Message,
+	}
+}
@@ -0,0 +1,537 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/server/adkrest/controllers"
+	"google.golang.org/adk/server/adkrest/internal/models"
+	"google.golang.org/adk/session"
+)
+
+// newEchoAgent returns an agent which streams a partial event followed by a
+// final event echoing the user input.
+func newEchoAgent(t *testing.T) agent.Agent {
+	t.Helper()
+	a, err := agent.New(agent.Config{
+		Name: "echo",
+		Run: func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				text := ctx.UserContent().Parts[0].Text
+				partial := session.NewEvent(ctx.InvocationID())
+				partial.LLMResponse = model.LLMResponse{
+					Content: genai.NewContentFromText("echo: ", genai.RoleModel),
+					Partial: true,
+				}
+				if !yield(partial, nil) {
+					return
+				}
+				final := session.NewEvent(ctx.InvocationID())
+				final.LLMResponse = model.LLMResponse{
+					Content:      genai.NewContentFromText("echo: "+text, genai.RoleModel),
+					TurnComplete: true,
+				}
+				yield(final, nil)
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("agent.New() failed: %v", err)
+	}
+	return a
+}
+
+func newLiveTestServer(t *testing.T, sessionService session.Service, a agent.Agent, opts controllers.RuntimeAPIOptions) *httptest.Server {
+	t.Helper()
+	controller := controllers.NewRuntimeAPIControllerWithOptions(sessionService, nil, agent.NewSingleLoader(a), nil, 10*time.Second, runner.PluginConfig{}, opts)
+	srv := httptest.NewServer(controllers.NewErrorHandler(controller.RunLiveHandler))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestRunLiveHandler(t *testing.T) {
+	ctx := context.Background()
+	sessionService := session.InMemoryService()
+	if _, err := sessionService.Create(ctx, &session.CreateRequest{AppName: "echo", UserID: "user", SessionID: "session"}); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	srv := newLiveTestServer(t, sessionService, newEchoAgent(t), controllers.RuntimeAPIOptions{})
+
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "?app_name=echo&user_id=user&session_id=session"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Dial() failed: %v", err)
+	}
+	defer conn.Close()
+
+	for _, turn := range []string{"hello", "world"} {
+		if err := conn.WriteJSON(models.LiveRequest{Content: genai.NewContentFromText(turn, genai.RoleUser)}); err != nil {
+			t.Fatalf("WriteJSON() failed: %v", err)
+		}
+
+		var partial, final models.Event
+		if err := conn.ReadJSON(&partial); err != nil {
+			t.Fatalf("ReadJSON() failed: %v", err)
+		}
+		if !partial.Partial {
+			t.Errorf("first event of turn %q is not partial: %+v", turn, partial)
+		}
+		if err := conn.ReadJSON(&final); err != nil {
+			t.Fatalf("ReadJSON() failed: %v", err)
+		}
+		if final.Partial || !final.TurnComplete {
+			t.Errorf("second event of turn %q is not final: %+v", turn, final)
+		}
+		if got, want := final.Content.Parts[0].Text, "echo: "+turn; got != want {
+			t.Errorf("final event text = %q, want %q", got, want)
+		}
+	}
+
+	if err := conn.WriteJSON(models.LiveRequest{Close: true}); err != nil {
+		t.Fatalf("WriteJSON() failed: %v", err)
+	}
+	_, _, err = conn.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+		t.Errorf("ReadMessage() after close = %v, want normal closure", err)
+	}
+
+	resp, err := sessionService.Get(ctx, &session.GetRequest{AppName: "echo", UserID: "user", SessionID: "session"})
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	// Two user turns and two final agent events, partial events are not persisted.
+	if got, want := resp.Session.Events().Len(), 4; got != want {
+		t.Errorf("session has %d events, want %d", got, want)
+	}
+}
+
+func TestRunLiveHandler_RejectsBlobs(t *testing.T) {
+	ctx := context.Background()
+	sessionService := session.InMemoryService()
+	if _, err := sessionService.Create(ctx, &session.CreateRequest{AppName: "echo", UserID: "user", SessionID: "session"}); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	srv := newLiveTestServer(t, sessionService, newEchoAgent(t), controllers.RuntimeAPIOptions{})
+
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "?app_name=echo&user_id=user&session_id=session"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Dial() failed: %v", err)
+	}
+	defer conn.Close()
+
+	for _, req := range []models.LiveRequest{
+		{Blob: &genai.Blob{MIMEType: "audio/pcm", Data: []byte{0, 1}}},
+		{},
+	} {
+		if err := conn.WriteJSON(req); err != nil {
+			t.Fatalf("WriteJSON() failed: %v", err)
+		}
+		var event models.Event
+		if err := conn.ReadJSON(&event); err != nil {
+			t.Fatalf("ReadJSON() failed: %v", err)
+		}
+		if event.ErrorCode != "BAD_REQUEST" {
+			t.Errorf("event of request %+v = %+v, want a BAD_REQUEST error", req, event)
+		}
+	}
+
+	// The connection is still usable for content turns.
+	if err := conn.WriteJSON(models.LiveRequest{Content: genai.NewContentFromText("hello", genai.RoleUser)}); err != nil {
+		t.Fatalf("WriteJSON() failed: %v", err)
+	}
+	var event models.Event
+	if err := conn.ReadJSON(&event); err != nil {
+		t.Fatalf("ReadJSON() failed: %v", err)
+	}
+	if event.ErrorCode != "" {
+		t.Errorf("event of a content turn = %+v, want no error", event)
+	}
+
+	resp, err := sessionService.Get(ctx, &session.GetRequest{AppName: "echo", UserID: "user", SessionID: "session"})
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	for event := range resp.Session.Events().All() {
+		if event.Content != nil && event.Content.Parts[0].InlineData != nil {
+			t.Errorf("session has an event with the rejected blob")
+		}
+	}
+}
+
+func TestRunLiveHandler_Turns(t *testing.T) {
+	tc := []struct {
+		name     string
+		agent    func(t *testing.T) agent.Agent
+		opts     controllers.RuntimeAPIOptions
+		wantCode string
+	}{
+		{
+			name:     "throttled",
+			agent:    newEchoAgent,
+			opts:     controllers.RuntimeAPIOptions{RateLimiter: &fakeLimiter{retryAfter: time.Second}},
+			wantCode: "TOO_MANY_REQUESTS",
+		},
+		{
+			name:     "exceeds the maximum run duration",
+			agent:    func(t *testing.T) agent.Agent { return newSlowAgent(t, 5*time.Second, "never") },
+			opts:     controllers.RuntimeAPIOptions{MaxRunDuration: 50 * time.Millisecond},
+			wantCode: "DEADLINE_EXCEEDED",
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			a := tt.agent(t)
+			sessionService := session.InMemoryService()
+			if _, err := sessionService.Create(t.Context(), &session.CreateRequest{AppName: a.Name(), UserID: "user", SessionID: "session"}); err != nil {
+				t.Fatalf("Create() failed: %v", err)
+			}
+			srv := newLiveTestServer(t, sessionService, a, tt.opts)
+
+			url := "ws" + strings.TrimPrefix(srv.URL, "http") + "?app_name=" + a.Name() + "&user_id=user&session_id=session"
+			conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+			if err != nil {
+				t.Fatalf("Dial() failed: %v", err)
+			}
+			defer conn.Close()
+
+			// Every turn is a run of its own, and the connection outlives
+			// the failed ones.
+			for range 2 {
+				if err := conn.WriteJSON(models.LiveRequest{Content: genai.NewContentFromText("hello", genai.RoleUser)}); err != nil {
+					t.Fatalf("WriteJSON() failed: %v", err)
+				}
+				var event models.Event
+				if err := conn.ReadJSON(&event); err != nil {
+					t.Fatalf("ReadJSON() failed: %v", err)
+				}
+				if event.ErrorCode != tt.wantCode {
+					t.Errorf("event = %+v, want error code %q", event, tt.wantCode)
+				}
+			}
+		})
+	}
+}
+
+func TestRunLiveHandler_Metrics(t *testing.T) {
+	sessionService := session.InMemoryService()
+	if _, err := sessionService.Create(t.Context(), &session.CreateRequest{AppName: "echo", UserID: "user", SessionID: "session"}); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	recorder := &fakeRecorder{}
+	srv := newLiveTestServer(t, sessionService, newEchoAgent(t), controllers.RuntimeAPIOptions{Metrics: recorder})
+
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "?app_name=echo&user_id=user&session_id=session"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Dial() failed: %v", err)
+	}
+	defer conn.Close()
+	for _, turn := range []string{"hello", "world"} {
+		if err := conn.WriteJSON(models.LiveRequest{Content: genai.NewContentFromText(turn, genai.RoleUser)}); err != nil {
+			t.Fatalf("WriteJSON() failed: %v", err)
+		}
+		for range 2 {
+			var event models.Event
+			if err := conn.ReadJSON(&event); err != nil {
+				t.Fatalf("ReadJSON() failed: %v", err)
+			}
+		}
+	}
+	// The invocation of a turn is observed once the turn is done, which is
+	// before the next turn starts.
+	if err := conn.WriteJSON(models.LiveRequest{Close: true}); err != nil {
+		t.Fatalf("WriteJSON() failed: %v", err)
+	}
+	if _, _, err := conn.ReadMessage(); !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+		t.Fatalf("ReadMessage() after close = %v, want normal closure", err)
+	}
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	if got := len(recorder.invocations); got != 2 {
+		t.Errorf("observed %d invocations, want 2", got)
+	}
+	for _, stats := range recorder.invocations {
+		if stats.AppName != "echo" || stats.Events != 2 {
+			t.Errorf("invocation stats = %+v, want app echo with 2 events", stats)
+		}
+	}
+}
+
+func TestRunLiveHandler_Cancel(t *testing.T) {
+	a := newSlowAgent(t, 20*time.Millisecond, "first", "second", "third", "fourth", "fifth")
+	sessionService := session.InMemoryService()
+	if _, err := sessionService.Create(t.Context(), &session.CreateRequest{AppName: a.Name(), UserID: "user", SessionID: "session"}); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	controller := controllers.NewRuntimeAPIController(sessionService, nil, agent.NewSingleLoader(a), nil, 10*time.Second, runner.PluginConfig{})
+	router := mux.NewRouter()
+	router.HandleFunc("/run_live", controllers.NewErrorHandler(controller.RunLiveHandler))
+	router.HandleFunc("/apps/{app_name}/users/{user_id}/sessions/{session_id}/invocations/{invocation_id}:cancel", controllers.NewErrorHandler(controller.CancelInvocationHandler))
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/run_live?app_name=slow&user_id=user&session_id=session"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Dial() failed: %v", err)
+	}
+	defer conn.Close()
+	if err := conn.WriteJSON(models.LiveRequest{Content: genai.NewContentFromText("hi", genai.RoleUser)}); err != nil {
+		t.Fatalf("WriteJSON() failed: %v", err)
+	}
+	var first models.Event
+	if err := conn.ReadJSON(&first); err != nil {
+		t.Fatalf("ReadJSON() failed: %v", err)
+	}
+
+	resp, err := http.Post(srv.URL+"/apps/slow/users/user/sessions/session/invocations/"+first.InvocationID+":cancel", "application/json", nil)
+	if err != nil {
+		t.Fatalf("Post() failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("cancel status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	for {
+		var event models.Event
+		if err := conn.ReadJSON(&event); err != nil {
+			t.Fatalf("ReadJSON() failed: %v", err)
+		}
+		if event.ErrorCode == "" {
+			continue
+		}
+		if event.ErrorCode != "CANCELLED" {
+			t.Errorf("error event = %+v, want a CANCELLED error", event)
+		}
+		break
+	}
+}
+
+func TestRunLiveHandler_PreUpgradeErrors(t *testing.T) {
+	srv := newLiveTestServer(t, session.InMemoryService(), newEchoAgent(t), controllers.RuntimeAPIOptions{})
+
+	tc := []struct {
+		name       string
+		query      string
+		wantStatus int
+	}{
+		{
+			name:       "missing parameters",
+			query:      "?app_name=echo",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "session does not exist",
+			query:      "?app_name=echo&user_id=user&session_id=missing",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			url := "ws" + strings.TrimPrefix(srv.URL, "http") + tt.query
+			_, resp, err := websocket.DefaultDialer.Dial(url, nil)
+			if err == nil {
+				t.Fatal("Dial() succeeded, want error")
+			}
+			if resp == nil {
+				t.Fatalf("Dial() returned no response: %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
+
+// fakeLiveModel is a live model which answers every user turn and every
+// realtime blob with a partial response followed by a final one.
+type fakeLiveModel struct {
+	blobs chan *genai.Blob
+}
+
+func (m *fakeLiveModel) Name() string { return "fake-live" }
+
+func (m *fakeLiveModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		yield(nil, fmt.Errorf("GenerateContent() isn't supported"))
+	}
+}
+
+func (m *fakeLiveModel) Connect(ctx context.Context, req *model.LLMRequest) (model.LiveSession, error) {
+	return &fakeLiveSession{blobs: m.blobs, inputs: make(chan string, 10), done: make(chan struct{})}, nil
+}
+
+type fakeLiveSession struct {
+	blobs  chan *genai.Blob
+	inputs chan string
+	done   chan struct{}
+	close  sync.Once
+}
+
+func (s *fakeLiveSession) SendContent(content *genai.Content) error {
+	s.inputs <- content.Parts[0].Text
+	return nil
+}
+
+func (s *fakeLiveSession) SendRealtimeInput(blob *genai.Blob) error {
+	s.blobs <- blob
+	s.inputs <- fmt.Sprintf("%d bytes of %s", len(blob.Data), blob.MIMEType)
+	return nil
+}
+
+func (s *fakeLiveSession) Receive() iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		for {
+			var input string
+			select {
+			case input = <-s.inputs:
+			case <-s.done:
+				return
+			}
+			if !yield(&model.LLMResponse{Content: genai.NewContentFromText("heard: ", genai.RoleModel), Partial: true}, nil) {
+				return
+			}
+			if !yield(&model.LLMResponse{Content: genai.NewContentFromText("heard: "+input, genai.RoleModel), TurnComplete: true}, nil) {
+				return
+			}
+		}
+	}
+}
+
+func (s *fakeLiveSession) Close() error {
+	s.close.Do(func() { close(s.done) })
+	return nil
+}
+
+func TestRunLiveHandler_LiveModel(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		wrap func(model.LLM) model.LLM
+	}{
+		{name: "live model", wrap: func(llm model.LLM) model.LLM { return llm }},
+		{name: "wrapped live model", wrap: func(llm model.LLM) model.LLM {
+			return model.WithRetry(model.WithInterceptor(llm), model.RetryPolicy{})
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			testRunLiveHandlerLiveModel(t, tc.wrap)
+		})
+	}
+}
+
+func testRunLiveHandlerLiveModel(t *testing.T, wrap func(model.LLM) model.LLM) {
+	ctx := context.Background()
+	sessionService := session.InMemoryService()
+	if _, err := sessionService.Create(ctx, &session.CreateRequest{AppName: "live", UserID: "user", SessionID: "session"}); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	llm := &fakeLiveModel{blobs: make(chan *genai.Blob, 1)}
+	a, err := llmagent.New(llmagent.Config{Name: "live", Model: wrap(llm)})
+	if err != nil {
+		t.Fatalf("llmagent.New() failed: %v", err)
+	}
+	srv := newLiveTestServer(t, sessionService, a, controllers.RuntimeAPIOptions{})
+
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "?app_name=live&user_id=user&session_id=session"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Dial() failed: %v", err)
+	}
+	defer conn.Close()
+
+	for _, tc := range []struct {
+		req  models.LiveRequest
+		want string
+	}{
+		{
+			req:  models.LiveRequest{Content: genai.NewContentFromText("hello", genai.RoleUser)},
+			want: "heard: hello",
+		},
+		{
+			req:  models.LiveRequest{Blob: &genai.Blob{MIMEType: "audio/pcm", Data: []byte{0, 1, 2}}},
+			want: "heard: 3 bytes of audio/pcm",
+		},
+	} {
+		if err := conn.WriteJSON(tc.req); err != nil {
+			t.Fatalf("WriteJSON() failed: %v", err)
+		}
+
+		var event models.Event
+		for {
+			if err := conn.ReadJSON(&event); err != nil {
+				t.Fatalf("ReadJSON() failed: %v", err)
+			}
+			if event.ErrorCode != "" {
+				t.Fatalf("event of request %+v = %+v, want no error", tc.req, event)
+			}
+			if event.Author != "user" && !event.Partial {
+				break
+			}
+		}
+		if !event.TurnComplete {
+			t.Errorf("final event of request %+v is not complete: %+v", tc.req, event)
+		}
+		if got := event.Content.Parts[0].Text; got != tc.want {
+			t.Errorf("final event text = %q, want %q", got, tc.want)
+		}
+	}
+
+	select {
+	case blob := <-llm.blobs:
+		if got, want := blob.Data, []byte{0, 1, 2}; !bytes.Equal(got, want) {
+			t.Errorf("blob sent to the model = %v, want %v", got, want)
+		}
+	default:
+		t.Error("no blob was sent to the model")
+	}
+
+	if err := conn.WriteJSON(models.LiveRequest{Close: true}); err != nil {
+		t.Fatalf("WriteJSON() failed: %v", err)
+	}
+	_, _, err = conn.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+		t.Errorf("ReadMessage() after close = %v, want normal closure", err)
+	}
+
+	resp, err := sessionService.Get(ctx, &session.GetRequest{AppName: "live", UserID: "user", SessionID: "session"})
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	// The user turn and the two final model events, realtime media isn't
+	// recorded and partial events are not persisted.
+	if got, want := resp.Session.Events().Len(), 3; got != want {
+		t.Errorf("session has %d events, want %d", got, want)
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers_test
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/internal/testutil"
+	"google.golang.org/adk/server/adkrest/controllers"
+	"google.golang.org/adk/server/adkrest/metrics"
+)
+
+// fakeRecorder records the metrics in memory.
+type fakeRecorder struct {
+	mu            sync.Mutex
+	activeStreams map[string]int
+	maxStreams    int
+	invocations   []metrics.InvocationStats
+}
+
+func (r *fakeRecorder) ObserveRequest(string, string, int, time.Duration) {}
+
+func (r *fakeRecorder) StreamStarted(appName string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.activeStreams == nil {
+		r.activeStreams = make(map[string]int)
+	}
+	r.activeStreams[appName]++
+	r.maxStreams = max(r.maxStreams, r.activeStreams[appName])
+}
+
+func (r *fakeRecorder) StreamEnded(appName string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.activeStreams[appName]--
+}
+
+func (r *fakeRecorder) ObserveInvocation(stats metrics.InvocationStats) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.invocations = append(r.invocations, stats)
+}
+
+func (r *fakeRecorder) MemoryIngested(string) {}
+
+func (r *fakeRecorder) MemoryIngestionFailed(string) {}
+
+func (r *fakeRecorder) Handler() http.Handler { return nil }
+
+func TestRunSSEHandler_Metrics(t *testing.T) {
+	a, err := llmagent.New(llmagent.Config{
+		Name:  "assistant",
+		Model: &testutil.MockModel{Responses: []*genai.Content{genai.NewContentFromText("hello", genai.RoleModel)}},
+	})
+	if err != nil {
+		t.Fatalf("llmagent.New() failed: %v", err)
+	}
+	recorder := &fakeRecorder{}
+	srv := newRunSSETestServer(t, a, controllers.RuntimeAPIOptions{SSEKeepAliveInterval: -1, Metrics: recorder})
+
+	postRunSSE(t, srv, a.Name())
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	if recorder.maxStreams != 1 || recorder.activeStreams[a.Name()] != 0 {
+		t.Errorf("active streams: max = %d, at the end = %d, want 1 and 0", recorder.maxStreams, recorder.activeStreams[a.Name()])
+	}
+	want := []metrics.InvocationStats{{AppName: a.Name(), AgentName: "assistant", Events: 1, LLMCalls: 1}}
+	if diff := cmp.Diff(want, recorder.invocations, cmpopts.IgnoreFields(metrics.InvocationStats{}, "Duration")); diff != "" {
+		t.Errorf("recorded invocations mismatch (-want +got):\n%s", diff)
+	}
+}
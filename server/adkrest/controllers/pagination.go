@@ -0,0 +1,56 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+const (
+	// DefaultPageSize is the page size of the listing endpoints when the
+	// client doesn't ask for one.
+	DefaultPageSize = 1000
+	// MaxPageSize is the largest page size of the listing endpoints. Larger
+	// requested sizes are capped.
+	MaxPageSize = 1000
+)
+
+// pageParams are the pagination query parameters of a listing request.
+type pageParams struct {
+	size  int
+	token string
+	// requested is true if the client sent any of the parameters, i.e. it
+	// expects a paginated response.
+	requested bool
+}
+
+func parsePageParams(req *http.Request) (pageParams, error) {
+	query := req.URL.Query()
+	p := pageParams{
+		size:      DefaultPageSize,
+		token:     query.Get("pageToken"),
+		requested: query.Has("pageSize") || query.Has("pageToken"),
+	}
+	if v := query.Get("pageSize"); v != "" {
+		size, err := strconv.Atoi(v)
+		if err != nil || size <= 0 {
+			return p, newStatusError(fmt.Errorf("invalid pageSize %q: must be a positive integer", v), http.StatusBadRequest)
+		}
+		p.size = min(size, MaxPageSize)
+	}
+	return p, nil
+}
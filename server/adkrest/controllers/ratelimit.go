@@ -0,0 +1,54 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"google.golang.org/adk/server/adkrest/internal/models"
+	"google.golang.org/adk/server/adkrest/ratelimit"
+)
+
+// RetryAfterHeader tells the throttled clients how many seconds to wait
+// before starting another run.
+const RetryAfterHeader = "Retry-After"
+
+// checkRateLimit consumes a token of the app and user of the run. Streaming
+// runs consume a single token, when they start. If the limiter fails, the
+// run is allowed: the limiter must not take the API down.
+func (c *RuntimeAPIController) checkRateLimit(rw http.ResponseWriter, req *http.Request, runAgentRequest models.RunAgentRequest) error {
+	if c.opts.RateLimiter == nil {
+		return nil
+	}
+	allowed, retryAfter, err := c.opts.RateLimiter.Allow(req.Context(), ratelimit.Key{
+		AppName: runAgentRequest.AppName,
+		UserID:  runAgentRequest.UserId,
+	})
+	if err != nil {
+		responseLogger(rw, req).Warn("ADK: rate limiter failed, allowing the run", "error", err)
+		return nil
+	}
+	if allowed {
+		return nil
+	}
+	return statusError{
+		Err:  fmt.Errorf("rate limit exceeded for user %q of app %q", runAgentRequest.UserId, runAgentRequest.AppName),
+		Code: http.StatusTooManyRequests,
+		// The throttled clients are always told when to retry.
+		RetryAfter: max(retryAfter, time.Second),
+	}
+}
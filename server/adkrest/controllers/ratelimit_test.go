@@ -0,0 +1,133 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/server/adkrest/controllers"
+	"google.golang.org/adk/server/adkrest/internal/models"
+	"google.golang.org/adk/server/adkrest/ratelimit"
+	"google.golang.org/adk/session"
+)
+
+type fakeLimiter struct {
+	allowed    bool
+	retryAfter time.Duration
+	err        error
+	keys       []ratelimit.Key
+}
+
+func (l *fakeLimiter) Allow(ctx context.Context, key ratelimit.Key) (bool, time.Duration, error) {
+	l.keys = append(l.keys, key)
+	return l.allowed, l.retryAfter, l.err
+}
+
+func TestRunHandlers_RateLimit(t *testing.T) {
+	tc := []struct {
+		name           string
+		streaming      bool
+		limiter        *fakeLimiter
+		wantStatus     int
+		wantRetryAfter string
+	}{
+		{
+			name:       "allowed",
+			limiter:    &fakeLimiter{allowed: true},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "streaming run consumes a single token",
+			streaming:  true,
+			limiter:    &fakeLimiter{allowed: true},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:           "throttled",
+			limiter:        &fakeLimiter{retryAfter: 1500 * time.Millisecond},
+			wantStatus:     http.StatusTooManyRequests,
+			wantRetryAfter: "2",
+		},
+		{
+			name:           "streaming run throttled",
+			streaming:      true,
+			limiter:        &fakeLimiter{retryAfter: 10 * time.Millisecond},
+			wantStatus:     http.StatusTooManyRequests,
+			wantRetryAfter: "1",
+		},
+		{
+			name:       "limiter failure allows the run",
+			limiter:    &fakeLimiter{err: errors.New("redis is down")},
+			wantStatus: http.StatusOK,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newSlowAgent(t, 0, "one", "two", "three")
+			sessionService := session.InMemoryService()
+			if _, err := sessionService.Create(context.Background(), &session.CreateRequest{AppName: a.Name(), UserID: "user", SessionID: "session"}); err != nil {
+				t.Fatalf("Create() failed: %v", err)
+			}
+			controller := controllers.NewRuntimeAPIControllerWithOptions(sessionService, nil, agent.NewSingleLoader(a), nil, 10*time.Second, runner.PluginConfig{}, controllers.RuntimeAPIOptions{
+				SSEKeepAliveInterval: -1,
+				RateLimiter:          tt.limiter,
+			})
+			handler := controller.RunHandler
+			if tt.streaming {
+				handler = controller.RunSSEHandler
+			}
+
+			body, err := json.Marshal(models.RunAgentRequest{
+				AppName:    a.Name(),
+				UserId:     "user",
+				SessionId:  "session",
+				NewMessage: *genai.NewContentFromText("hi", genai.RoleUser),
+			})
+			if err != nil {
+				t.Fatalf("json.Marshal() failed: %v", err)
+			}
+			srv := httptest.NewServer(controllers.NewErrorHandler(handler))
+			t.Cleanup(srv.Close)
+			resp, err := http.Post(srv.URL, "application/json", bytes.NewReader(body))
+			if err != nil {
+				t.Fatalf("Post() failed: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if got := resp.Header.Get(controllers.RetryAfterHeader); got != tt.wantRetryAfter {
+				t.Errorf("%s = %q, want %q", controllers.RetryAfterHeader, got, tt.wantRetryAfter)
+			}
+			wantKeys := []ratelimit.Key{{AppName: a.Name(), UserID: "user"}}
+			if diff := cmp.Diff(wantKeys, tt.limiter.keys); diff != "" {
+				t.Errorf("limiter keys mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"google.golang.org/adk/server/adkrest/internal/models"
+	"google.golang.org/adk/session"
+)
+
+// LastEventIDHeader is the standard SSE header with which reconnecting
+// clients tell the ID of the last event they received.
+const LastEventIDHeader = "Last-Event-ID"
+
+// streamEnd is the data of the frame which ends a resumed stream once the
+// invocation has completed.
+type streamEnd struct {
+	InvocationID string `json:"invocationId"`
+}
+
+// resumeStream continues the stream of an existing invocation: it replays the
+// persisted events which follow lastEventID (or all of them if it is empty)
+// and then, if the invocation is still running, streams its live events.
+// Once the invocation has completed, the stream ends with an "end" frame.
+func (c *RuntimeAPIController) resumeStream(rw http.ResponseWriter, req *http.Request, stream *streamWriter, runAgentRequest models.RunAgentRequest, lastEventID string) error {
+	invocationID := runAgentRequest.InvocationId
+	if invocationID == "" {
+		return newStatusError(fmt.Errorf("invocationId is required to resume a stream with %s header", LastEventIDHeader), http.StatusBadRequest)
+	}
+	addLogAttrs(rw, "invocation_id", invocationID)
+
+	ctx, done, err := c.runs.start(req.Context())
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	// Subscribe before reading the session, so that no event is missed in between.
+	var live <-chan *session.Event
+	inv, running := c.invocations.get(invocationKey{
+		appName:      runAgentRequest.AppName,
+		userID:       runAgentRequest.UserId,
+		sessionID:    runAgentRequest.SessionId,
+		invocationID: invocationID,
+	})
+	if running {
+		var unsubscribe func()
+		live, unsubscribe = inv.subscribe()
+		defer unsubscribe()
+	}
+
+	resp, err := c.sessionService.Get(ctx, &session.GetRequest{
+		AppName:   runAgentRequest.AppName,
+		UserID:    runAgentRequest.UserId,
+		SessionID: runAgentRequest.SessionId,
+	})
+	if err != nil {
+		return newStatusError(fmt.Errorf("failed to get session: %w", err), http.StatusNotFound)
+	}
+	var persisted []*session.Event
+	for event := range resp.Session.Events().All() {
+		if event.InvocationID == invocationID {
+			persisted = append(persisted, event)
+		}
+	}
+	if len(persisted) == 0 && !running {
+		return newStatusError(fmt.Errorf("invocation %q not found", invocationID), http.StatusNotFound)
+	}
+	replay := persisted
+	if lastEventID != "" {
+		found := false
+		for i, event := range persisted {
+			if event.ID == lastEventID {
+				replay, found = persisted[i+1:], true
+				break
+			}
+		}
+		if !found {
+			return newStatusError(fmt.Errorf("event %q not found in invocation %q", lastEventID, invocationID), http.StatusBadRequest)
+		}
+	}
+	// Events already delivered to the client, either before it reconnected
+	// or by the replay below.
+	delivered := make(map[string]bool, len(persisted))
+	for _, event := range persisted {
+		delivered[event.ID] = true
+	}
+
+	c.opts.Metrics.StreamStarted(runAgentRequest.AppName)
+	defer c.opts.Metrics.StreamEnded(runAgentRequest.AppName)
+	stream.start(c.opts.SSEKeepAliveInterval)
+	defer stream.close()
+	for _, event := range replay {
+		if err := stream.writeEvent(*event); err != nil {
+			return err
+		}
+	}
+
+	if running {
+	stream:
+		for {
+			select {
+			case event, ok := <-live:
+				if !ok {
+					break stream
+				}
+				if !event.Partial && delivered[event.ID] {
+					continue
+				}
+				if err := stream.writeEvent(*event); err != nil {
+					return err
+				}
+			case <-ctx.Done():
+				if isShutdown(ctx) {
+					return stream.writeError(newStatusError(ErrShuttingDown, http.StatusServiceUnavailable))
+				}
+				return nil
+			}
+		}
+		if !inv.isFinished() {
+			// The client didn't keep up with the invocation, it has to resume again.
+			return newStatusError(errors.New("stream fell behind the invocation"), http.StatusInternalServerError)
+		}
+	}
+
+	return stream.writeEnd(invocationID)
+}
@@ -0,0 +1,162 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/server/adkrest/controllers"
+	"google.golang.org/adk/server/adkrest/internal/models"
+)
+
+// startRunSSE starts a run_sse request, optionally resuming the given invocation.
+func startRunSSE(t *testing.T, srv *httptest.Server, invocationID, lastEventID string) *http.Response {
+	t.Helper()
+	body, err := json.Marshal(models.RunAgentRequest{
+		AppName:      "slow",
+		UserId:       "user",
+		SessionId:    "session",
+		NewMessage:   *genai.NewContentFromText("hi", genai.RoleUser),
+		InvocationId: invocationID,
+	})
+	if err != nil {
+		t.Fatalf("json.Marshal() failed: %v", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, srv.URL, bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("NewRequest() failed: %v", err)
+	}
+	if lastEventID != "" {
+		req.Header.Set(controllers.LastEventIDHeader, lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Do() failed: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+// sseEvent is a frame of a run_sse stream reduced to what the tests compare.
+type sseEvent struct {
+	Type string
+	Text string
+}
+
+func readSSEEvents(t *testing.T, body io.Reader) []sseEvent {
+	t.Helper()
+	b, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatalf("ReadAll() failed: %v", err)
+	}
+	var got []sseEvent
+	for frame := range strings.SplitSeq(strings.TrimSpace(string(b)), "\n\n") {
+		got = append(got, toSSEEvent(t, frame))
+	}
+	return got
+}
+
+func toSSEEvent(t *testing.T, frame string) sseEvent {
+	t.Helper()
+	typ, data := parseSSEFrame(frame)
+	if typ != "" {
+		return sseEvent{Type: typ}
+	}
+	var event models.Event
+	if err := json.Unmarshal([]byte(data), &event); err != nil {
+		t.Fatalf("malformed event frame %q: %v", frame, err)
+	}
+	return sseEvent{Text: event.Content.Parts[0].Text}
+}
+
+func firstEvent(t *testing.T, frame string) (id string, event models.Event) {
+	t.Helper()
+	id, _ = strings.CutPrefix(strings.Split(frame, "\n")[0], "id: ")
+	_, data := parseSSEFrame(frame)
+	if err := json.Unmarshal([]byte(data), &event); err != nil {
+		t.Fatalf("malformed event frame %q: %v", frame, err)
+	}
+	return id, event
+}
+
+func TestRunSSEHandler_ResumeCompletedInvocation(t *testing.T) {
+	srv := newRunSSETestServer(t, newSlowAgent(t, time.Millisecond, "first", "second", "third"), controllers.RuntimeAPIOptions{SSEKeepAliveInterval: -1})
+
+	resp := startRunSSE(t, srv, "", "")
+	reader := bufio.NewReader(resp.Body)
+	id, event := firstEvent(t, readSSEFrame(t, reader))
+	if _, err := io.ReadAll(reader); err != nil {
+		t.Fatalf("ReadAll() failed: %v", err)
+	}
+
+	resumed := startRunSSE(t, srv, event.InvocationID, id)
+	if resumed.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resumed.StatusCode, http.StatusOK)
+	}
+	want := []sseEvent{{Text: "second"}, {Text: "third"}, {Type: "end"}}
+	if diff := cmp.Diff(want, readSSEEvents(t, resumed.Body)); diff != "" {
+		t.Errorf("resumed stream mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRunSSEHandler_ResumeRunningInvocation(t *testing.T) {
+	srv := newRunSSETestServer(t, newSlowAgent(t, 30*time.Millisecond, "first", "second", "third", "fourth"), controllers.RuntimeAPIOptions{SSEKeepAliveInterval: -1})
+
+	resp := startRunSSE(t, srv, "", "")
+	reader := bufio.NewReader(resp.Body)
+	id, event := firstEvent(t, readSSEFrame(t, reader))
+
+	resumed := startRunSSE(t, srv, event.InvocationID, id)
+	if resumed.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resumed.StatusCode, http.StatusOK)
+	}
+	want := []sseEvent{{Text: "second"}, {Text: "third"}, {Text: "fourth"}, {Type: "end"}}
+	if diff := cmp.Diff(want, readSSEEvents(t, resumed.Body)); diff != "" {
+		t.Errorf("resumed stream mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRunSSEHandler_ResumeErrors(t *testing.T) {
+	srv := newRunSSETestServer(t, newSlowAgent(t, time.Millisecond, "only"), controllers.RuntimeAPIOptions{SSEKeepAliveInterval: -1})
+
+	tc := []struct {
+		name         string
+		invocationID string
+		lastEventID  string
+		wantStatus   int
+	}{
+		{name: "missing invocation ID", lastEventID: "event", wantStatus: http.StatusBadRequest},
+		{name: "unknown invocation", invocationID: "unknown", wantStatus: http.StatusNotFound},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := startRunSSE(t, srv, tt.invocationID, tt.lastEventID)
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"iter"
+	"net/http"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/server/adkrest/internal/models"
+	"google.golang.org/adk/session"
+)
+
+// ResumeInvocationHandler resumes an invocation interrupted before its end,
+// e.g. by the restart of the server, from the checkpoints recorded by its
+// run with the Checkpointing option, a [models.ResumeInvocationRequest]: see
+// [runner.Runner.Resume]. The response has the events of the rest of the
+// invocation, as for the run endpoint.
+//
+// It fails with 409 status code if the invocation is still running, if it
+// ended or if it has no checkpoints.
+func (c *RuntimeAPIController) ResumeInvocationHandler(rw http.ResponseWriter, req *http.Request) error {
+	var resumeReq models.ResumeInvocationRequest
+	sess, invocationID, err := c.pausedInvocation(rw, req, &resumeReq)
+	if err != nil {
+		return err
+	}
+	runAgentRequest := models.RunAgentRequest{
+		AppName:         sess.AppName(),
+		UserId:          sess.UserID(),
+		SessionId:       sess.ID(),
+		ExcludeThoughts: resumeReq.ExcludeThoughts,
+	}
+	if err := c.checkRateLimit(rw, req, runAgentRequest); err != nil {
+		return err
+	}
+	return c.runSyncWith(rw, req, runAgentRequest, func(ctx context.Context, r *runner.Runner, cfg agent.RunConfig) iter.Seq2[*session.Event, error] {
+		return r.Resume(ctx, sess.UserID(), sess.ID(), invocationID, cfg)
+	})
+}
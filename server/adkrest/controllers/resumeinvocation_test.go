@@ -0,0 +1,136 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/gorilla/mux"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/workflowagents/sequentialagent"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/server/adkrest/controllers"
+	"google.golang.org/adk/server/adkrest/internal/models"
+	"google.golang.org/adk/session"
+)
+
+func TestResumeInvocationHandler(t *testing.T) {
+	runs := make(map[string]int)
+	step := func(name string, failures int) agent.Agent {
+		a, err := agent.New(agent.Config{
+			Name: name,
+			Run: func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+				return func(yield func(*session.Event, error) bool) {
+					runs[name]++
+					if runs[name] <= failures {
+						yield(nil, errors.New("pod rescheduled"))
+						return
+					}
+					event := session.NewEvent(ctx.InvocationID())
+					event.Author = name
+					event.Content = genai.NewContentFromText(name, genai.RoleModel)
+					yield(event, nil)
+				}
+			},
+		})
+		if err != nil {
+			t.Fatalf("agent.New() failed: %v", err)
+		}
+		return a
+	}
+	pipeline, err := sequentialagent.New(sequentialagent.Config{
+		AgentConfig: agent.Config{Name: "pipeline", SubAgents: []agent.Agent{step("research", 0), step("write", 1), step("review", 0)}},
+	})
+	if err != nil {
+		t.Fatalf("sequentialagent.New() failed: %v", err)
+	}
+
+	sessionService := session.InMemoryService()
+	if _, err := sessionService.Create(t.Context(), &session.CreateRequest{AppName: "pipeline", UserID: "user", SessionID: "session"}); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	controller := controllers.NewRuntimeAPIControllerWithOptions(sessionService, nil, agent.NewSingleLoader(pipeline), nil, 10*time.Second, runner.PluginConfig{}, controllers.RuntimeAPIOptions{Checkpointing: true})
+	router := mux.NewRouter()
+	router.HandleFunc("/run", controllers.NewErrorHandler(controller.RunHandler))
+	router.HandleFunc("/apps/{app_name}/users/{user_id}/sessions/{session_id}/invocations/{invocation_id}:resume", controllers.NewErrorHandler(controller.ResumeInvocationHandler))
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+	post := func(path string, body any) (int, []models.Event) {
+		t.Helper()
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("json.Marshal() failed: %v", err)
+		}
+		resp, err := http.Post(srv.URL+path, "application/json", bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("POST %s failed: %v", path, err)
+		}
+		defer resp.Body.Close()
+		var events []models.Event
+		if resp.StatusCode == http.StatusOK {
+			if err := json.NewDecoder(resp.Body).Decode(&events); err != nil {
+				t.Fatalf("failed to decode events: %v", err)
+			}
+		}
+		return resp.StatusCode, events
+	}
+
+	if status, _ := post("/run", models.RunAgentRequest{
+		AppName:    "pipeline",
+		UserId:     "user",
+		SessionId:  "session",
+		NewMessage: *genai.NewContentFromText("Write about Go.", genai.RoleUser),
+	}); status != http.StatusInternalServerError {
+		t.Fatalf("run: status = %d, want %d", status, http.StatusInternalServerError)
+	}
+	resp, err := sessionService.Get(t.Context(), &session.GetRequest{AppName: "pipeline", UserID: "user", SessionID: "session"})
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	invocationID := resp.Session.Events().At(0).InvocationID
+
+	resumePath := "/apps/pipeline/users/user/sessions/session/invocations/" + invocationID + ":resume"
+	status, events := post(resumePath, models.ResumeInvocationRequest{})
+	if status != http.StatusOK {
+		t.Fatalf("resume: status = %d, want 200", status)
+	}
+	var texts []string
+	for _, event := range events {
+		if event.Content != nil {
+			texts = append(texts, event.Content.Parts[0].Text)
+		}
+	}
+	if diff := cmp.Diff([]string{"write", "review"}, texts); diff != "" {
+		t.Errorf("resumed texts mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string]int{"research": 1, "write": 2, "review": 1}, runs); diff != "" {
+		t.Errorf("agent runs mismatch (-want +got):\n%s", diff)
+	}
+
+	// The invocation ended.
+	if status, _ := post(resumePath, models.ResumeInvocationRequest{}); status != http.StatusConflict {
+		t.Errorf("second resume: status = %d, want %d", status, http.StatusConflict)
+	}
+}
@@ -17,15 +17,22 @@ package controllers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"iter"
 	"net/http"
 	"time"
 
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/artifact"
 	"google.golang.org/adk/memory"
+	"google.golang.org/adk/memory/ingest"
+	"google.golang.org/adk/plugin"
 	"google.golang.org/adk/runner"
+	"google.golang.org/adk/server/adkrest/internal/auth"
 	"google.golang.org/adk/server/adkrest/internal/models"
+	"google.golang.org/adk/server/adkrest/metrics"
+	"google.golang.org/adk/server/adkrest/ratelimit"
 	"google.golang.org/adk/session"
 )
 
@@ -37,33 +44,145 @@ type RuntimeAPIController struct {
 	artifactService artifact.Service
 	agentLoader     agent.Loader
 	pluginConfig    runner.PluginConfig
+	opts            RuntimeAPIOptions
+	runs            runTracker
+	invocations     invocationRegistry
+	async           asyncRegistry
 }
 
-// NewRuntimeAPIController creates the controller for the Runtime API.
+// RuntimeAPIOptions contains optional settings of the Runtime API controller.
+// The zero value is valid and uses defaults.
+type RuntimeAPIOptions struct {
+	// SSEKeepAliveInterval is how often a keep-alive comment is written to an
+	// SSE stream on which no event has been flushed.
+	// Optional: if zero, [DefaultSSEKeepAliveInterval] is used. A negative
+	// value disables keep-alives.
+	SSEKeepAliveInterval time.Duration
+	// MaxRunDuration is the maximum duration of a run started by the run and
+	// run_sse endpoints. Clients can ask for a shorter one with the
+	// [TimeoutHeader] header.
+	// Optional: if zero, runs are limited only by the header.
+	MaxRunDuration time.Duration
+	// Metrics records the streaming responses and the invocations.
+	// Optional: if nil, metrics are discarded.
+	Metrics metrics.Recorder
+	// RateLimiter limits the rate at which each user of an app can start
+	// runs. Throttled runs are rejected with 429 status code.
+	// Optional: if nil, runs are not limited.
+	RateLimiter ratelimit.Limiter
+	// Webhooks configures the callbacks of the runs started in
+	// [RunModeAsync] mode.
+	Webhooks WebhookOptions
+	// AsyncRetention is how long the status of a finished async run is kept.
+	// Optional: if zero, 1 hour is used.
+	AsyncRetention time.Duration
+	// SessionLocker serializes the runs of each session.
+	// Optional: if nil, concurrent runs of a session are allowed.
+	SessionLocker session.Locker
+	// SessionLockWait is how long a run waits for the lock of its session,
+	// held by another run, before it is rejected with 409 status code.
+	// Optional: if zero, such runs are rejected right away.
+	SessionLockWait time.Duration
+	// MemoryIngester is given to the runners, which enqueue the sessions
+	// for ingestion into memory at the end of their runs.
+	// Optional: if nil, the sessions are not ingested.
+	MemoryIngester *ingest.Ingester
+	// Checkpointing runs the agents with checkpointing, see
+	// [agent.RunConfig], so that the invocations interrupted before their
+	// end, e.g. by the restart of the server, can be resumed with the
+	// resume endpoint.
+	Checkpointing bool
+}
+
+// NewRuntimeAPIController creates the controller for the Runtime API with the
+// default options.
 func NewRuntimeAPIController(sessionService session.Service, memoryService memory.Service, agentLoader agent.Loader, artifactService artifact.Service, sseTimeout time.Duration, pluginConfig runner.PluginConfig) *RuntimeAPIController {
-	return &RuntimeAPIController{sessionService: sessionService, memoryService: memoryService, agentLoader: agentLoader, artifactService: artifactService, sseTimeout: sseTimeout, pluginConfig: pluginConfig}
+	return NewRuntimeAPIControllerWithOptions(sessionService, memoryService, agentLoader, artifactService, sseTimeout, pluginConfig, RuntimeAPIOptions{})
+}
+
+// NewRuntimeAPIControllerWithOptions creates the controller for the Runtime API.
+func NewRuntimeAPIControllerWithOptions(sessionService session.Service, memoryService memory.Service, agentLoader agent.Loader, artifactService artifact.Service, sseTimeout time.Duration, pluginConfig runner.PluginConfig, opts RuntimeAPIOptions) *RuntimeAPIController {
+	if opts.SSEKeepAliveInterval == 0 {
+		opts.SSEKeepAliveInterval = DefaultSSEKeepAliveInterval
+	}
+	if opts.Metrics == nil {
+		opts.Metrics = metrics.Noop()
+	}
+	if opts.AsyncRetention == 0 {
+		opts.AsyncRetention = defaultAsyncRetention
+	}
+	opts.Webhooks.setDefaults()
+	return &RuntimeAPIController{sessionService: sessionService, memoryService: memoryService, agentLoader: agentLoader, artifactService: artifactService, sseTimeout: sseTimeout, pluginConfig: pluginConfig, opts: opts}
 }
 
 // RunAgent executes a non-streaming agent run for a given session and message.
+// In [RunModeAsync] mode, set with the mode query parameter, the run continues
+// in the background and the response only describes its invocation.
 func (c *RuntimeAPIController) RunHandler(rw http.ResponseWriter, req *http.Request) error {
 	runAgentRequest, err := decodeRequestBody(req)
 	if err != nil {
 		return err
 	}
-	sessionEvents, err := c.runAgent(req.Context(), runAgentRequest)
+	addLogAttrs(rw, "app_name", runAgentRequest.AppName, "session_id", runAgentRequest.SessionId)
+	if err := checkUser(req, runAgentRequest.UserId); err != nil {
+		return err
+	}
+	mode := req.URL.Query().Get("mode")
+	if mode != "" && mode != RunModeSync && mode != RunModeAsync {
+		return newStatusError(fmt.Errorf("invalid mode %q: must be %q or %q", mode, RunModeSync, RunModeAsync), http.StatusBadRequest)
+	}
+	if err := c.checkRateLimit(rw, req, runAgentRequest); err != nil {
+		return err
+	}
+	if mode == RunModeAsync {
+		return c.runAsync(rw, req, runAgentRequest)
+	}
+	return c.runSync(rw, req, runAgentRequest)
+}
+
+// runSync runs the agent and responds with all the events of the run.
+func (c *RuntimeAPIController) runSync(rw http.ResponseWriter, req *http.Request, runAgentRequest models.RunAgentRequest) error {
+	return c.runSyncWith(rw, req, runAgentRequest, func(ctx context.Context, r *runner.Runner, cfg agent.RunConfig) iter.Seq2[*session.Event, error] {
+		return r.Run(ctx, runAgentRequest.UserId, runAgentRequest.SessionId, &runAgentRequest.NewMessage, cfg)
+	})
+}
+
+// runFunc runs the agent of the runner with the run configuration.
+type runFunc func(ctx context.Context, r *runner.Runner, cfg agent.RunConfig) iter.Seq2[*session.Event, error]
+
+// runSyncWith runs the agent of the request with run, e.g. to resume an
+// invocation, and responds with all the events of the run.
+func (c *RuntimeAPIController) runSyncWith(rw http.ResponseWriter, req *http.Request, runAgentRequest models.RunAgentRequest, run runFunc) error {
+	ctx, done, err := c.startRun(req, runAgentRequest)
+	if err != nil {
+		return err
+	}
+	defer done()
+	sessionEvents, err := c.runAgent(ctx, runAgentRequest, run)
+	if cause, ok := interruption(ctx); ok {
+		c.interrupted(rw, req, runAgentRequest, invocationFromContext(ctx).ID(), cause)
+		return cause
+	}
 	if err != nil {
 		return err
 	}
 	var events []models.Event
 	for _, event := range sessionEvents {
-		events = append(events, models.FromSessionEvent(*event))
+		e := *event
+		if runAgentRequest.ExcludeThoughts {
+			var ok bool
+			if e, ok = withoutThoughts(e); !ok {
+				continue
+			}
+		}
+		events = append(events, models.FromSessionEvent(e))
 	}
 	EncodeJSONResponse(events, http.StatusOK, rw)
 	return nil
 }
 
-// RunAgent executes a non-streaming agent run for a given session and message.
-func (c *RuntimeAPIController) runAgent(ctx context.Context, runAgentRequest models.RunAgentRequest) ([]*session.Event, error) {
+// RunAgent executes a non-streaming agent run for a given session with run.
+func (c *RuntimeAPIController) runAgent(ctx context.Context, runAgentRequest models.RunAgentRequest, run runFunc) ([]*session.Event, error) {
 	err := c.validateSessionExists(ctx, runAgentRequest.AppName, runAgentRequest.UserId, runAgentRequest.SessionId)
 	if err != nil {
 		return nil, err
@@ -74,24 +193,28 @@ func (c *RuntimeAPIController) runAgent(ctx context.Context, runAgentRequest mod
 		return nil, err
 	}
 
-	resp := r.Run(ctx, runAgentRequest.UserId, runAgentRequest.SessionId, &runAgentRequest.NewMessage, *rCfg)
+	resp := run(ctx, r, *rCfg)
 
 	var events []*session.Event
 	for event, err := range resp {
+		if errors.Is(err, runner.ErrNotResumable) {
+			return nil, newStatusError(err, http.StatusConflict)
+		}
 		if err != nil {
 			return nil, newStatusError(fmt.Errorf("failed to run agent: %w", err), http.StatusInternalServerError)
 		}
+		if inv := invocationFromContext(ctx); inv != nil {
+			inv.publish(event)
+		}
 		events = append(events, event)
 	}
 	return events, nil
 }
 
-// RunSSEHandler executes an agent run and streams the resulting events using Server-Sent Events (SSE).
+// RunSSEHandler executes an agent run and streams the resulting events. The
+// format of the stream is negotiated with the Accept header: Server-Sent
+// Events (the default) or newline-delimited JSON.
 func (c *RuntimeAPIController) RunSSEHandler(rw http.ResponseWriter, req *http.Request) error {
-	rw.Header().Set("Content-Type", "text/event-stream")
-	rw.Header().Set("Cache-Control", "no-cache")
-	rw.Header().Set("Connection", "keep-alive")
-
 	// set custom deadlines for this request - it overrides server-wide timeouts
 	rc := http.NewResponseController(rw)
 	deadline := time.Now().Add(c.sseTimeout)
@@ -99,13 +222,32 @@ func (c *RuntimeAPIController) RunSSEHandler(rw http.ResponseWriter, req *http.R
 	if err != nil {
 		return newStatusError(fmt.Errorf("failed to set write deadline: %w", err), http.StatusInternalServerError)
 	}
+	stream := newStreamWriter(rw, rc, req)
 
 	runAgentRequest, err := decodeRequestBody(req)
 	if err != nil {
 		return err
 	}
+	addLogAttrs(rw, "app_name", runAgentRequest.AppName, "session_id", runAgentRequest.SessionId)
+	if err := checkUser(req, runAgentRequest.UserId); err != nil {
+		return err
+	}
+	stream.excludeThoughts = runAgentRequest.ExcludeThoughts
+
+	if lastEventID := req.Header.Get(LastEventIDHeader); lastEventID != "" || runAgentRequest.InvocationId != "" {
+		return c.resumeStream(rw, req, stream, runAgentRequest, lastEventID)
+	}
+	if err := c.checkRateLimit(rw, req, runAgentRequest); err != nil {
+		return err
+	}
 
-	err = c.validateSessionExists(req.Context(), runAgentRequest.AppName, runAgentRequest.UserId, runAgentRequest.SessionId)
+	ctx, done, err := c.startRun(req, runAgentRequest)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	err = c.validateSessionExists(ctx, runAgentRequest.AppName, runAgentRequest.UserId, runAgentRequest.SessionId)
 	if err != nil {
 		return err
 	}
@@ -115,46 +257,51 @@ func (c *RuntimeAPIController) RunSSEHandler(rw http.ResponseWriter, req *http.R
 		return err
 	}
 
-	resp := r.Run(req.Context(), runAgentRequest.UserId, runAgentRequest.SessionId, &runAgentRequest.NewMessage, *rCfg)
+	resp := r.Run(ctx, runAgentRequest.UserId, runAgentRequest.SessionId, &runAgentRequest.NewMessage, *rCfg)
 
-	rw.WriteHeader(http.StatusOK)
+	// The app name is only used as a label once the app is known to exist.
+	c.opts.Metrics.StreamStarted(runAgentRequest.AppName)
+	defer c.opts.Metrics.StreamEnded(runAgentRequest.AppName)
+	stream.start(c.opts.SSEKeepAliveInterval)
+	defer stream.close()
 	for event, err := range resp {
 		if err != nil {
-			_, err := fmt.Fprintf(rw, "Error while running agent: %v\n", err)
-			if err != nil {
-				return newStatusError(fmt.Errorf("failed to write response: %w", err), http.StatusInternalServerError)
+			if _, ok := interruption(ctx); ok {
+				break
 			}
-			err = rc.Flush()
-			if err != nil {
-				return newStatusError(fmt.Errorf("failed to flush: %w", err), http.StatusInternalServerError)
+			if err := stream.writeRunError(err); err != nil {
+				return err
 			}
-
 			continue
 		}
-		err := flashEvent(rc, rw, *event)
-		if err != nil {
+		invocationFromContext(ctx).publish(event)
+		if err := stream.writeEvent(*event); err != nil {
 			return err
 		}
 	}
+	if cause, ok := interruption(ctx); ok {
+		c.interrupted(rw, req, runAgentRequest, invocationFromContext(ctx).ID(), cause)
+		// Let the client know that the stream was interrupted, rather than
+		// leaving it with a truncated response.
+		return stream.writeError(cause)
+	}
 	return nil
 }
 
-func flashEvent(rc *http.ResponseController, rw http.ResponseWriter, event session.Event) error {
-	_, err := fmt.Fprintf(rw, "data: ")
-	if err != nil {
-		return newStatusError(fmt.Errorf("failed to write response: %w", err), http.StatusInternalServerError)
-	}
-	err = json.NewEncoder(rw).Encode(models.FromSessionEvent(event))
+// interrupted records in the session that the invocation was interrupted by
+// the server. Failures are only logged, as the run has failed anyway.
+func (c *RuntimeAPIController) interrupted(rw http.ResponseWriter, req *http.Request, runAgentRequest models.RunAgentRequest, invocationID string, cause statusError) {
+	err := c.recordInterruption(req.Context(), runAgentRequest.AppName, runAgentRequest.UserId, runAgentRequest.SessionId, invocationID, cause)
 	if err != nil {
-		return newStatusError(fmt.Errorf("failed to encode response: %w", err), http.StatusInternalServerError)
+		responseLogger(rw, req).Error("ADK: failed to record interrupted invocation", "invocation_id", invocationID, "error", err)
 	}
-	_, err = fmt.Fprintf(rw, "\n")
-	if err != nil {
-		return newStatusError(fmt.Errorf("failed to write response: %w", err), http.StatusInternalServerError)
-	}
-	err = rc.Flush()
-	if err != nil {
-		return newStatusError(fmt.Errorf("failed to flush: %w", err), http.StatusInternalServerError)
+}
+
+// checkUser rejects the runs on behalf of another user than the
+// authenticated one, if the server requires them to match.
+func checkUser(req *http.Request, userID string) error {
+	if err := auth.CheckUser(req.Context(), userID); err != nil {
+		return newStatusError(err, http.StatusForbidden)
 	}
 	return nil
 }
@@ -177,26 +324,58 @@ func (c *RuntimeAPIController) getRunner(req models.RunAgentRequest) (*runner.Ru
 		return nil, nil, newStatusError(fmt.Errorf("failed to load agent: %w", err), http.StatusInternalServerError)
 	}
 
+	invocationPlugin, err := c.newInvocationPlugin()
+	if err != nil {
+		return nil, nil, newStatusError(fmt.Errorf("failed to create plugin: %w", err), http.StatusInternalServerError)
+	}
+	pluginConfig := c.pluginConfig
+	pluginConfig.Plugins = append([]*plugin.Plugin{invocationPlugin}, pluginConfig.Plugins...)
+
 	r, err := runner.New(runner.Config{
 		AppName:         req.AppName,
 		Agent:           curAgent,
 		SessionService:  c.sessionService,
 		MemoryService:   c.memoryService,
+		MemoryIngester:  c.opts.MemoryIngester,
 		ArtifactService: c.artifactService,
-		PluginConfig:    c.pluginConfig,
+		PluginConfig:    pluginConfig,
 	},
 	)
 	if err != nil {
 		return nil, nil, newStatusError(fmt.Errorf("failed to create runner: %w", err), http.StatusInternalServerError)
 	}
 
-	streamingMode := agent.StreamingModeNone
+	rCfg, err := runConfig(req)
+	if err != nil {
+		return nil, nil, newStatusError(err, http.StatusBadRequest)
+	}
+	rCfg.Checkpointing = c.opts.Checkpointing
+	return r, rCfg, nil
+}
+
+// runConfig returns the configuration of the run of the request.
+func runConfig(req models.RunAgentRequest) (*agent.RunConfig, error) {
+	rCfg := &agent.RunConfig{StreamingMode: agent.StreamingModeNone}
 	if req.Streaming {
-		streamingMode = agent.StreamingModeSSE
+		rCfg.StreamingMode = agent.StreamingModeSSE
+	}
+	if req.RunConfig == nil {
+		return rCfg, nil
+	}
+	switch mode := agent.StreamingMode(req.RunConfig.StreamingMode); mode {
+	case "":
+	case agent.StreamingModeNone, agent.StreamingModeSSE:
+		rCfg.StreamingMode = mode
+	default:
+		return nil, fmt.Errorf("invalid streaming mode %q, want %q or %q", mode, agent.StreamingModeNone, agent.StreamingModeSSE)
+	}
+	if req.RunConfig.MaxLlmCalls < 0 {
+		return nil, fmt.Errorf("invalid maxLlmCalls %d, want a positive number or zero for no limit", req.RunConfig.MaxLlmCalls)
 	}
-	return r, &agent.RunConfig{
-		StreamingMode: streamingMode,
-	}, nil
+	rCfg.MaxLLMCalls = req.RunConfig.MaxLlmCalls
+	rCfg.ResponseModalities = req.RunConfig.ResponseModalities
+	rCfg.SavePartialEvents = req.RunConfig.SavePartialEvents
+	return rCfg, nil
 }
 
 func decodeRequestBody(req *http.Request) (decodedReq models.RunAgentRequest, err error) {
@@ -15,11 +15,21 @@
 package controllers
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
 	"google.golang.org/adk/plugin"
 	"google.golang.org/adk/runner"
+	"google.golang.org/adk/server/adkrest/internal/auth"
+	"google.golang.org/adk/server/adkrest/internal/models"
 )
 
 func TestNewRuntimeAPIController_PluginsAssignment(t *testing.T) {
@@ -76,3 +86,92 @@ func TestNewRuntimeAPIController_PluginsAssignment(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckUser(t *testing.T) {
+	alice := &auth.Principal{Subject: "alice"}
+	tc := []struct {
+		name       string
+		ctx        context.Context
+		userID     string
+		wantStatus int
+	}{
+		{name: "no principal", ctx: context.Background(), userID: "bob"},
+		{name: "match not required", ctx: auth.ToContext(context.Background(), alice, false), userID: "bob"},
+		{name: "same user", ctx: auth.ToContext(context.Background(), alice, true), userID: "alice"},
+		{name: "other user", ctx: auth.ToContext(context.Background(), alice, true), userID: "bob", wantStatus: http.StatusForbidden},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequestWithContext(tt.ctx, http.MethodPost, "/run", nil)
+			err := checkUser(req, tt.userID)
+			if tt.wantStatus == 0 {
+				if err != nil {
+					t.Errorf("checkUser() failed: %v", err)
+				}
+				return
+			}
+			var se statusError
+			if !errors.As(err, &se) || se.Status() != tt.wantStatus {
+				t.Errorf("checkUser() = %v, want error with status %d", err, tt.wantStatus)
+			}
+			if !errors.Is(err, auth.ErrUserMismatch) {
+				t.Errorf("checkUser() = %v, want %v", err, auth.ErrUserMismatch)
+			}
+		})
+	}
+}
+
+func TestRunConfig(t *testing.T) {
+	tc := []struct {
+		name    string
+		req     models.RunAgentRequest
+		want    *agent.RunConfig
+		wantErr bool
+	}{
+		{
+			name: "default",
+			want: &agent.RunConfig{StreamingMode: agent.StreamingModeNone},
+		},
+		{
+			name: "streaming",
+			req:  models.RunAgentRequest{Streaming: true},
+			want: &agent.RunConfig{StreamingMode: agent.StreamingModeSSE},
+		},
+		{
+			name: "run config",
+			req: models.RunAgentRequest{Streaming: true, RunConfig: &models.RunConfig{
+				StreamingMode:      "none",
+				MaxLlmCalls:        10,
+				ResponseModalities: []genai.Modality{genai.ModalityAudio},
+				SavePartialEvents:  true,
+			}},
+			want: &agent.RunConfig{
+				StreamingMode:      agent.StreamingModeNone,
+				MaxLLMCalls:        10,
+				ResponseModalities: []genai.Modality{genai.ModalityAudio},
+				SavePartialEvents:  true,
+			},
+		},
+		{
+			name:    "invalid streaming mode",
+			req:     models.RunAgentRequest{RunConfig: &models.RunConfig{StreamingMode: "bidi"}},
+			wantErr: true,
+		},
+		{
+			name:    "negative max LLM calls",
+			req:     models.RunAgentRequest{RunConfig: &models.RunConfig{MaxLlmCalls: -1}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := runConfig(tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("runConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("runConfig() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
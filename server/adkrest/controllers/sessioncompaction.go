@@ -0,0 +1,102 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"google.golang.org/adk/server/adkrest/internal/models"
+	"google.golang.org/adk/session"
+)
+
+// CompactSessionHandler replaces the runs of old events of a session with
+// summaries written by [SessionsAPIOptions.Summarizer], see
+// [session.Compactor]. The request body, a [models.CompactSessionRequest],
+// is optional. It fails with 501 status code if the session service can't
+// compact sessions or no summarizer is configured, and with 409 status code
+// if the session was compacted concurrently.
+func (c *SessionsAPIController) CompactSessionHandler(rw http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	sessionID, err := models.SessionIDFromHTTPParameters(params)
+	if err != nil {
+		httpError(rw, req, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if sessionID.ID == "" {
+		httpError(rw, req, "session_id parameter is required", http.StatusBadRequest)
+		return
+	}
+	compactor, ok := c.service.(session.Compactor)
+	if !ok {
+		httpError(rw, req, "the session service doesn't support compaction", http.StatusNotImplemented)
+		return
+	}
+	if c.opts.Summarizer == nil {
+		httpError(rw, req, "session compaction is not enabled on this server", http.StatusNotImplemented)
+		return
+	}
+	var compactReq models.CompactSessionRequest
+	if req.ContentLength != 0 {
+		if err := json.NewDecoder(req.Body).Decode(&compactReq); err != nil {
+			httpError(rw, req, fmt.Sprintf("invalid compaction request: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+	if compactReq.KeepRecent < 0 {
+		httpError(rw, req, fmt.Sprintf("keepRecent must not be negative, got %d", compactReq.KeepRecent), http.StatusBadRequest)
+		return
+	}
+
+	ctx := req.Context()
+	// The services don't tell the missing sessions apart from the other
+	// errors, so the session is checked first.
+	if _, err := c.service.Get(ctx, &session.GetRequest{
+		AppName:         sessionID.AppName,
+		UserID:          sessionID.UserID,
+		SessionID:       sessionID.ID,
+		NumRecentEvents: 1,
+	}); err != nil {
+		httpError(rw, req, err.Error(), http.StatusNotFound)
+		return
+	}
+	resp, err := compactor.Compact(ctx, &session.CompactRequest{
+		AppName:   sessionID.AppName,
+		UserID:    sessionID.UserID,
+		SessionID: sessionID.ID,
+	}, session.CompactionConfig{
+		Summarize:  c.opts.Summarizer,
+		KeepRecent: compactReq.KeepRecent,
+		Archive:    compactReq.Archive,
+	})
+	if errors.Is(err, session.ErrConcurrentModification) {
+		httpError(rw, req, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		httpError(rw, req, fmt.Sprintf("failed to compact session: %v", err), http.StatusInternalServerError)
+		return
+	}
+	compacted, err := models.FromSession(resp.Session)
+	if err != nil {
+		httpError(rw, req, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	EncodeJSONResponse(models.SessionCompaction{Session: compacted, CompactedEvents: resp.CompactedEvents}, http.StatusOK, rw)
+}
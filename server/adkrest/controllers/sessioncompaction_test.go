@@ -0,0 +1,161 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/server/adkrest/controllers"
+	"google.golang.org/adk/server/adkrest/internal/models"
+	"google.golang.org/adk/session"
+)
+
+// plainSessionService hides the optional interfaces of the service.
+type plainSessionService struct {
+	session.Service
+}
+
+func TestCompactSessionHandler(t *testing.T) {
+	summarizer := func(ctx context.Context, events []*session.Event) (*genai.Content, error) {
+		return genai.NewContentFromText(fmt.Sprintf("summary of %d events", len(events)), genai.RoleUser), nil
+	}
+	tests := []struct {
+		name           string
+		plainService   bool
+		summarizer     session.Summarizer
+		sessionID      string
+		body           string
+		wantStatus     int
+		wantCompacted  int
+		wantEventTexts []string
+	}{
+		{
+			name:           "compacted",
+			summarizer:     summarizer,
+			sessionID:      "session",
+			body:           `{"keepRecent": 2}`,
+			wantStatus:     http.StatusOK,
+			wantCompacted:  3,
+			wantEventTexts: []string{"summary of 3 events", "message 3", "message 4"},
+		},
+		{
+			name:           "default keep recent",
+			summarizer:     summarizer,
+			sessionID:      "session",
+			wantStatus:     http.StatusOK,
+			wantEventTexts: []string{"message 0", "message 1", "message 2", "message 3", "message 4"},
+		},
+		{
+			name:       "no summarizer",
+			sessionID:  "session",
+			wantStatus: http.StatusNotImplemented,
+		},
+		{
+			name:         "service without compaction",
+			plainService: true,
+			summarizer:   summarizer,
+			sessionID:    "session",
+			wantStatus:   http.StatusNotImplemented,
+		},
+		{
+			name:       "missing session",
+			summarizer: summarizer,
+			sessionID:  "missing",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "negative keep recent",
+			summarizer: summarizer,
+			sessionID:  "session",
+			body:       `{"keepRecent": -1}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "summarizer error",
+			summarizer: func(ctx context.Context, events []*session.Event) (*genai.Content, error) {
+				return nil, errors.New("model unavailable")
+			},
+			sessionID:  "session",
+			body:       `{"keepRecent": 2}`,
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			sessionService := session.InMemoryService()
+			created, err := sessionService.Create(ctx, &session.CreateRequest{AppName: "app", UserID: "user", SessionID: "session"})
+			if err != nil {
+				t.Fatalf("Create() failed: %v", err)
+			}
+			start := time.Now()
+			for i := range 5 {
+				event := session.NewEvent("inv-1")
+				event.Timestamp = start.Add(time.Duration(i) * time.Second)
+				event.Author = "app"
+				event.LLMResponse = model.LLMResponse{Content: genai.NewContentFromText(fmt.Sprint("message ", i), genai.RoleModel)}
+				if err := sessionService.AppendEvent(ctx, created.Session, event); err != nil {
+					t.Fatalf("AppendEvent() failed: %v", err)
+				}
+			}
+			if tt.plainService {
+				sessionService = plainSessionService{sessionService}
+			}
+
+			srv := newSessionTransferServer(t, sessionService, controllers.SessionsAPIOptions{Summarizer: tt.summarizer})
+			resp, err := http.Post(srv.URL+"/apps/app/users/user/sessions/"+tt.sessionID+":compact", "application/json", strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatalf("Post() failed: %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var got models.SessionCompaction
+			if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+				t.Fatalf("Decode() failed: %v", err)
+			}
+			if got.CompactedEvents != tt.wantCompacted {
+				t.Errorf("compacted events = %d, want %d", got.CompactedEvents, tt.wantCompacted)
+			}
+			var texts []string
+			for _, event := range got.Session.Events {
+				texts = append(texts, event.Content.Parts[0].Text)
+			}
+			if diff := cmp.Diff(tt.wantEventTexts, texts); diff != "" {
+				t.Errorf("events mismatch (-want +got):\n%s", diff)
+			}
+			if tt.wantCompacted > 0 {
+				if c := got.Session.Events[0].Actions.Compaction; c == nil || c.CompactedEvents != tt.wantCompacted {
+					t.Errorf("summary compaction = %+v, want %d compacted events", c, tt.wantCompacted)
+				}
+			}
+		})
+	}
+}
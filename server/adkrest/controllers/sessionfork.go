@@ -0,0 +1,95 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"google.golang.org/adk/server/adkrest/internal/models"
+	"google.golang.org/adk/session"
+)
+
+// ForkSessionHandler creates a session which branches the conversation of
+// a session, see [session.Fork], and responds with the new session. The
+// request body, a [models.ForkSessionRequest], is optional. It fails with
+// 400 status code if the event to fork from isn't in the session, and with
+// 501 status code if copying the artifacts is requested but no artifact
+// service is configured.
+func (c *SessionsAPIController) ForkSessionHandler(rw http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	sessionID, err := models.SessionIDFromHTTPParameters(params)
+	if err != nil {
+		httpError(rw, req, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if sessionID.ID == "" {
+		httpError(rw, req, "session_id parameter is required", http.StatusBadRequest)
+		return
+	}
+	var forkReq models.ForkSessionRequest
+	if req.ContentLength != 0 {
+		if err := json.NewDecoder(req.Body).Decode(&forkReq); err != nil {
+			httpError(rw, req, fmt.Sprintf("invalid fork request: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+	if forkReq.CopyArtifacts && c.opts.ArtifactService == nil {
+		httpError(rw, req, "copying artifacts is not enabled on this server", http.StatusNotImplemented)
+		return
+	}
+
+	ctx := req.Context()
+	// The services don't tell the missing sessions apart from the other
+	// errors, so the session is checked first.
+	if _, err := c.service.Get(ctx, &session.GetRequest{
+		AppName:         sessionID.AppName,
+		UserID:          sessionID.UserID,
+		SessionID:       sessionID.ID,
+		NumRecentEvents: 1,
+	}); err != nil {
+		httpError(rw, req, err.Error(), http.StatusNotFound)
+		return
+	}
+	forkSessionReq := &session.ForkRequest{
+		AppName:      sessionID.AppName,
+		UserID:       sessionID.UserID,
+		SessionID:    sessionID.ID,
+		EventID:      forkReq.EventID,
+		NewSessionID: forkReq.SessionID,
+	}
+	if forkReq.CopyArtifacts {
+		forkSessionReq.Artifacts = c.opts.ArtifactService
+	}
+	resp, err := session.Fork(ctx, c.service, forkSessionReq)
+	if errors.Is(err, session.ErrEventNotFound) {
+		httpError(rw, req, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		httpError(rw, req, fmt.Sprintf("failed to fork session: %v", err), http.StatusInternalServerError)
+		return
+	}
+	forked, err := models.FromSession(resp.Session)
+	if err != nil {
+		httpError(rw, req, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	EncodeJSONResponse(forked, http.StatusOK, rw)
+}
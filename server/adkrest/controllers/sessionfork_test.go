@@ -0,0 +1,157 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/artifact"
+	"google.golang.org/adk/server/adkrest/controllers"
+	"google.golang.org/adk/server/adkrest/internal/models"
+	"google.golang.org/adk/session"
+)
+
+func TestForkSessionHandler(t *testing.T) {
+	tests := []struct {
+		name          string
+		noArtifacts   bool
+		sessionID     string
+		body          string
+		wantStatus    int
+		wantID        string
+		wantEvents    []string
+		wantSource    map[string]any
+		wantArtifacts []string
+	}{
+		{
+			name:       "all events",
+			sessionID:  "session",
+			body:       `{"sessionId": "fork"}`,
+			wantStatus: http.StatusOK,
+			wantID:     "fork",
+			wantEvents: []string{"e0", "e1", "e2"},
+			wantSource: map[string]any{"session_id": "session", "event_id": "e2"},
+		},
+		{
+			name:          "up to event with artifacts",
+			sessionID:     "session",
+			body:          `{"sessionId": "fork", "eventId": "e1", "copyArtifacts": true}`,
+			wantStatus:    http.StatusOK,
+			wantID:        "fork",
+			wantEvents:    []string{"e0", "e1"},
+			wantSource:    map[string]any{"session_id": "session", "event_id": "e1"},
+			wantArtifacts: []string{"notes"},
+		},
+		{
+			name:       "unknown event",
+			sessionID:  "session",
+			body:       `{"eventId": "missing"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "missing session",
+			sessionID:  "missing",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:        "no artifact service",
+			noArtifacts: true,
+			sessionID:   "session",
+			body:        `{"copyArtifacts": true}`,
+			wantStatus:  http.StatusNotImplemented,
+		},
+		{
+			name:       "invalid body",
+			sessionID:  "session",
+			body:       `{`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			sessionService, artifactService := session.InMemoryService(), artifact.InMemoryService()
+			created, err := sessionService.Create(ctx, &session.CreateRequest{AppName: "app", UserID: "user", SessionID: "session"})
+			if err != nil {
+				t.Fatalf("Create() failed: %v", err)
+			}
+			if _, err := artifactService.Save(ctx, &artifact.SaveRequest{AppName: "app", UserID: "user", SessionID: "session", FileName: "notes", Part: genai.NewPartFromText("notes")}); err != nil {
+				t.Fatalf("Save() failed: %v", err)
+			}
+			start := time.Now()
+			for i, id := range []string{"e0", "e1", "e2"} {
+				event := session.NewEvent("inv-1")
+				event.ID = id
+				event.Timestamp = start.Add(time.Duration(i) * time.Second)
+				event.Author = "user"
+				if id == "e1" {
+					event.Actions.ArtifactDelta = map[string]int64{"notes": 1}
+				}
+				if err := sessionService.AppendEvent(ctx, created.Session, event); err != nil {
+					t.Fatalf("AppendEvent() failed: %v", err)
+				}
+			}
+
+			opts := controllers.SessionsAPIOptions{ArtifactService: artifactService}
+			if tt.noArtifacts {
+				opts.ArtifactService = nil
+			}
+			srv := newSessionTransferServer(t, sessionService, opts)
+			resp, err := http.Post(srv.URL+"/apps/app/users/user/sessions/"+tt.sessionID+":fork", "application/json", strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatalf("Post() failed: %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var got models.Session
+			if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+				t.Fatalf("Decode() failed: %v", err)
+			}
+			if got.ID != tt.wantID {
+				t.Errorf("forked session ID = %q, want %q", got.ID, tt.wantID)
+			}
+			var events []string
+			for _, event := range got.Events {
+				events = append(events, event.ID)
+			}
+			if diff := cmp.Diff(tt.wantEvents, events); diff != "" {
+				t.Errorf("events mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantSource, got.State[session.KeyForkSource]); diff != "" {
+				t.Errorf("fork source mismatch (-want +got):\n%s", diff)
+			}
+			list, err := artifactService.List(ctx, &artifact.ListRequest{AppName: "app", UserID: "user", SessionID: got.ID})
+			if err != nil {
+				t.Fatalf("List() failed: %v", err)
+			}
+			if diff := cmp.Diff(tt.wantArtifacts, list.FileNames); diff != "" {
+				t.Errorf("forked artifacts mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
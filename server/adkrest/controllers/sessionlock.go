@@ -0,0 +1,54 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"google.golang.org/adk/session"
+)
+
+// sessionBusyRetryAfter is the delay after which the clients rejected because
+// of a busy session are told to retry.
+const sessionBusyRetryAfter = time.Second
+
+// lockSession acquires the lock of the session of the run, waiting for at
+// most [RuntimeAPIOptions.SessionLockWait]. Busy sessions are reported with
+// 409 status code and a hint of when to retry.
+func (c *RuntimeAPIController) lockSession(ctx context.Context, appName, userID, sessionID string) (unlock func(), err error) {
+	if c.opts.SessionLocker == nil {
+		return func() {}, nil
+	}
+	unlock, err = c.opts.SessionLocker.Lock(ctx, session.LockKey{AppName: appName, UserID: userID, SessionID: sessionID}, c.opts.SessionLockWait)
+	switch {
+	case err == nil:
+		return unlock, nil
+	case isShutdown(ctx):
+		return nil, newStatusError(ErrShuttingDown, http.StatusServiceUnavailable)
+	case errors.Is(err, session.ErrSessionBusy):
+		return nil, statusError{
+			Err:        fmt.Errorf("session %q is busy with another run", sessionID),
+			Code:       http.StatusConflict,
+			Reason:     "SESSION_BUSY",
+			RetryAfter: sessionBusyRetryAfter,
+		}
+	default:
+		return nil, newStatusError(fmt.Errorf("failed to lock session: %w", err), http.StatusServiceUnavailable)
+	}
+}
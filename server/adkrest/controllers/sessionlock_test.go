@@ -0,0 +1,188 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/server/adkrest/controllers"
+	"google.golang.org/adk/server/adkrest/internal/models"
+	"google.golang.org/adk/session"
+)
+
+// newGatedAgent returns an agent whose runs signal on started and wait for
+// release before they finish. It panics when panicking is set.
+func newGatedAgent(t *testing.T, started chan<- struct{}, release <-chan struct{}, panicking *atomic.Bool) agent.Agent {
+	t.Helper()
+	a, err := agent.New(agent.Config{
+		Name: "gated",
+		Run: func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				if panicking.Load() {
+					panic("agent failed")
+				}
+				started <- struct{}{}
+				<-release
+				event := session.NewEvent(ctx.InvocationID())
+				event.LLMResponse = model.LLMResponse{Content: genai.NewContentFromText("done", genai.RoleModel)}
+				yield(event, nil)
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("agent.New() failed: %v", err)
+	}
+	return a
+}
+
+func newLockedRunRequest(t *testing.T, appName string) *http.Request {
+	t.Helper()
+	body, err := json.Marshal(models.RunAgentRequest{
+		AppName:    appName,
+		UserId:     "user",
+		SessionId:  "session",
+		NewMessage: *genai.NewContentFromText("hi", genai.RoleUser),
+	})
+	if err != nil {
+		t.Fatalf("json.Marshal() failed: %v", err)
+	}
+	return httptest.NewRequest(http.MethodPost, "/run", bytes.NewReader(body))
+}
+
+func TestRunHandler_SessionLock(t *testing.T) {
+	tc := []struct {
+		name       string
+		wait       time.Duration
+		wantStatus int
+	}{
+		{
+			name:       "busy session is rejected",
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "busy session is waited for",
+			wait:       time.Minute,
+			wantStatus: http.StatusOK,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			started, release := make(chan struct{}, 2), make(chan struct{})
+			a := newGatedAgent(t, started, release, &atomic.Bool{})
+			sessionService := session.InMemoryService()
+			if _, err := sessionService.Create(context.Background(), &session.CreateRequest{AppName: a.Name(), UserID: "user", SessionID: "session"}); err != nil {
+				t.Fatalf("Create() failed: %v", err)
+			}
+			controller := controllers.NewRuntimeAPIControllerWithOptions(sessionService, nil, agent.NewSingleLoader(a), nil, 10*time.Second, runner.PluginConfig{}, controllers.RuntimeAPIOptions{
+				SessionLocker:   session.NewInMemoryLocker(),
+				SessionLockWait: tt.wait,
+			})
+			handler := controllers.NewErrorHandler(controller.RunHandler)
+
+			first := make(chan int)
+			go func() {
+				rr := httptest.NewRecorder()
+				handler(rr, newLockedRunRequest(t, a.Name()))
+				first <- rr.Code
+			}()
+			<-started
+
+			second := make(chan *httptest.ResponseRecorder)
+			go func() {
+				rr := httptest.NewRecorder()
+				handler(rr, newLockedRunRequest(t, a.Name()))
+				second <- rr
+			}()
+			if tt.wantStatus == http.StatusOK {
+				// The second run must not start before the first one ends.
+				select {
+				case <-started:
+					t.Fatal("second run started while the session is locked")
+				case <-time.After(50 * time.Millisecond):
+				}
+			}
+			var rr *httptest.ResponseRecorder
+			if tt.wantStatus != http.StatusOK {
+				rr = <-second
+			}
+			close(release)
+			if code := <-first; code != http.StatusOK {
+				t.Errorf("first run status = %d, want %d", code, http.StatusOK)
+			}
+			if rr == nil {
+				rr = <-second
+			}
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("second run status = %d, want %d: %s", rr.Code, tt.wantStatus, rr.Body)
+			}
+			if tt.wantStatus != http.StatusConflict {
+				return
+			}
+			if got := rr.Header().Get(controllers.RetryAfterHeader); got != "1" {
+				t.Errorf("%s = %q, want %q", controllers.RetryAfterHeader, got, "1")
+			}
+			var resp controllers.ErrorResponse
+			if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("malformed error response: %v", err)
+			}
+			if resp.Error.Code != "SESSION_BUSY" || resp.Error.RetryAfter != 1 {
+				t.Errorf("error = %+v, want code SESSION_BUSY and retryAfter 1", resp.Error)
+			}
+		})
+	}
+}
+
+func TestRunHandler_SessionLockReleasedOnPanic(t *testing.T) {
+	started, release := make(chan struct{}, 1), make(chan struct{})
+	close(release)
+	panicking := &atomic.Bool{}
+	panicking.Store(true)
+	a := newGatedAgent(t, started, release, panicking)
+	sessionService := session.InMemoryService()
+	if _, err := sessionService.Create(context.Background(), &session.CreateRequest{AppName: a.Name(), UserID: "user", SessionID: "session"}); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	controller := controllers.NewRuntimeAPIControllerWithOptions(sessionService, nil, agent.NewSingleLoader(a), nil, 10*time.Second, runner.PluginConfig{}, controllers.RuntimeAPIOptions{
+		SessionLocker: session.NewInMemoryLocker(),
+	})
+
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Fatal("RunHandler() didn't panic")
+			}
+		}()
+		_ = controller.RunHandler(httptest.NewRecorder(), newLockedRunRequest(t, a.Name()))
+	}()
+
+	panicking.Store(false)
+	if err := controller.RunHandler(httptest.NewRecorder(), newLockedRunRequest(t, a.Name())); err != nil {
+		t.Errorf("RunHandler() after a panic failed: %v", err)
+	}
+}
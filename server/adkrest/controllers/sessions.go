@@ -17,10 +17,15 @@ package controllers
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/mux"
 
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/artifact"
+	"google.golang.org/adk/internal/sessionutils"
 	"google.golang.org/adk/server/adkrest/internal/models"
 	"google.golang.org/adk/session"
 )
@@ -30,11 +35,33 @@ import (
 // SessionsAPIController is the controller for the Sessions API.
 type SessionsAPIController struct {
 	service session.Service
+	opts    SessionsAPIOptions
+}
+
+// SessionsAPIOptions contains optional settings of the Sessions API
+// controller. The zero value is valid and uses defaults.
+type SessionsAPIOptions struct {
+	// AgentLoader is used to resolve the authors of imported events.
+	// Optional: if nil, all the authors but the user are unresolved.
+	AgentLoader agent.Loader
+	// ArtifactService lists the artifacts referenced by the exports.
+	// Optional: if nil, exports don't reference artifacts.
+	ArtifactService artifact.Service
+	// MaxImportSize is the maximum size in bytes of an import request body.
+	// Optional: if zero, [DefaultMaxSessionImportSize] is used.
+	MaxImportSize int64
+	// Summarizer writes the summaries of the compacted events, typically
+	// with a model.
+	// Optional: if nil, sessions can't be compacted.
+	Summarizer session.Summarizer
 }
 
 // NewSessionsAPIController creates a new SessionsAPIController.
-func NewSessionsAPIController(service session.Service) *SessionsAPIController {
-	return &SessionsAPIController{service: service}
+func NewSessionsAPIController(service session.Service, opts SessionsAPIOptions) *SessionsAPIController {
+	if opts.MaxImportSize == 0 {
+		opts.MaxImportSize = DefaultMaxSessionImportSize
+	}
+	return &SessionsAPIController{service: service, opts: opts}
 }
 
 // CreateSesssionHTTP is a HTTP handler for the create session API.
@@ -42,7 +69,7 @@ func (c *SessionsAPIController) CreateSessionHandler(rw http.ResponseWriter, req
 	params := mux.Vars(req)
 	sessionID, err := models.SessionIDFromHTTPParameters(params)
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusBadRequest)
+		httpError(rw, req, err.Error(), http.StatusBadRequest)
 		return
 	}
 	createSessionRequest := models.CreateSessionRequest{}
@@ -50,13 +77,13 @@ func (c *SessionsAPIController) CreateSessionHandler(rw http.ResponseWriter, req
 	if req.ContentLength > 0 {
 		err := json.NewDecoder(req.Body).Decode(&createSessionRequest)
 		if err != nil {
-			http.Error(rw, err.Error(), http.StatusBadRequest)
+			httpError(rw, req, err.Error(), http.StatusBadRequest)
 			return
 		}
 	}
 	respSession, err := c.createSession(req.Context(), sessionID, createSessionRequest)
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		httpError(rw, req, err.Error(), http.StatusInternalServerError)
 		return
 	}
 	EncodeJSONResponse(respSession, http.StatusOK, rw)
@@ -86,11 +113,11 @@ func (c *SessionsAPIController) DeleteSessionHandler(rw http.ResponseWriter, req
 	params := mux.Vars(req)
 	sessionID, err := models.SessionIDFromHTTPParameters(params)
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusBadRequest)
+		httpError(rw, req, err.Error(), http.StatusBadRequest)
 		return
 	}
 	if sessionID.ID == "" {
-		http.Error(rw, "session_id parameter is required", http.StatusBadRequest)
+		httpError(rw, req, "session_id parameter is required", http.StatusBadRequest)
 		return
 	}
 
@@ -100,7 +127,7 @@ func (c *SessionsAPIController) DeleteSessionHandler(rw http.ResponseWriter, req
 		SessionID: sessionID.ID,
 	})
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		httpError(rw, req, err.Error(), http.StatusInternalServerError)
 		return
 	}
 	EncodeJSONResponse(nil, http.StatusOK, rw)
@@ -111,11 +138,11 @@ func (c *SessionsAPIController) GetSessionHandler(rw http.ResponseWriter, req *h
 	params := mux.Vars(req)
 	sessionID, err := models.SessionIDFromHTTPParameters(params)
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusBadRequest)
+		httpError(rw, req, err.Error(), http.StatusBadRequest)
 		return
 	}
 	if sessionID.ID == "" {
-		http.Error(rw, "session_id parameter is required", http.StatusBadRequest)
+		httpError(rw, req, "session_id parameter is required", http.StatusBadRequest)
 		return
 	}
 	storedSession, err := c.service.Get(req.Context(), &session.GetRequest{
@@ -124,12 +151,12 @@ func (c *SessionsAPIController) GetSessionHandler(rw http.ResponseWriter, req *h
 		SessionID: sessionID.ID,
 	})
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		httpError(rw, req, err.Error(), http.StatusInternalServerError)
 		return
 	}
 	session, err := models.FromSession(storedSession.Session)
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		httpError(rw, req, err.Error(), http.StatusInternalServerError)
 		return
 	}
 	EncodeJSONResponse(session, http.StatusOK, rw)
@@ -140,25 +167,120 @@ func (c *SessionsAPIController) ListSessionsHandler(rw http.ResponseWriter, req
 	params := mux.Vars(req)
 	sessionID, err := models.SessionIDFromHTTPParameters(params)
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusBadRequest)
+		httpError(rw, req, err.Error(), http.StatusBadRequest)
+		return
+	}
+	page, err := parsePageParams(req)
+	if err != nil {
+		writeError(rw, req, err)
 		return
 	}
 	var sessions []models.Session
 	resp, err := c.service.List(req.Context(), &session.ListRequest{
 		AppName: sessionID.AppName,
 		UserID:  sessionID.UserID,
+		ListOptions: session.ListOptions{
+			PageSize:  page.size,
+			PageToken: page.token,
+		},
 	})
 	if err != nil {
-		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		httpError(rw, req, err.Error(), http.StatusInternalServerError)
 		return
 	}
 	for _, session := range resp.Sessions {
 		respSession, err := models.FromSession(session)
 		if err != nil {
-			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			httpError(rw, req, err.Error(), http.StatusInternalServerError)
 			return
 		}
 		sessions = append(sessions, respSession)
 	}
-	EncodeJSONResponse(sessions, http.StatusOK, rw)
+	if !page.requested {
+		// Clients unaware of pagination get the first page as a plain list.
+		EncodeJSONResponse(sessions, http.StatusOK, rw)
+		return
+	}
+	EncodeJSONResponse(models.Page[models.Session]{Items: sessions, NextPageToken: resp.NextPageToken}, http.StatusOK, rw)
+}
+
+// ListEventsHandler lists the events of a session in chronological order,
+// one page at a time. The events can be filtered with the author, branch,
+// invocationId, after and before query parameters, see [parseEventFilter].
+func (c *SessionsAPIController) ListEventsHandler(rw http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	sessionID, err := models.SessionIDFromHTTPParameters(params)
+	if err != nil {
+		httpError(rw, req, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if sessionID.ID == "" {
+		httpError(rw, req, "session_id parameter is required", http.StatusBadRequest)
+		return
+	}
+	page, err := parsePageParams(req)
+	if err != nil {
+		writeError(rw, req, err)
+		return
+	}
+	offset, err := sessionutils.DecodePageToken(page.token)
+	if err != nil {
+		httpError(rw, req, err.Error(), http.StatusBadRequest)
+		return
+	}
+	filter, err := parseEventFilter(req)
+	if err != nil {
+		writeError(rw, req, err)
+		return
+	}
+	resp, err := c.service.Get(req.Context(), &session.GetRequest{
+		AppName:   sessionID.AppName,
+		UserID:    sessionID.UserID,
+		SessionID: sessionID.ID,
+	})
+	if err != nil {
+		httpError(rw, req, err.Error(), http.StatusNotFound)
+		return
+	}
+	result := models.Page[models.Event]{Items: []models.Event{}}
+	// Offsets are relative to the filtered events, so the same filter must be
+	// sent with the page token.
+	i := 0
+	for event := range filter.Filter(resp.Session.Events()) {
+		if i >= offset+page.size {
+			result.NextPageToken = sessionutils.EncodePageToken(offset + page.size)
+			break
+		}
+		if i >= offset {
+			result.Items = append(result.Items, models.FromSessionEvent(*event))
+		}
+		i++
+	}
+	EncodeJSONResponse(result, http.StatusOK, rw)
+}
+
+// parseEventFilter builds the event filter from the query parameters of the
+// request. The after and before parameters are RFC 3339 timestamps.
+func parseEventFilter(req *http.Request) (session.EventFilter, error) {
+	query := req.URL.Query()
+	filter := session.EventFilter{
+		Author:       query.Get("author"),
+		Branch:       query.Get("branch"),
+		InvocationID: query.Get("invocationId"),
+	}
+	for _, param := range []struct {
+		name string
+		t    *time.Time
+	}{{"after", &filter.After}, {"before", &filter.Before}} {
+		v := query.Get(param.name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return filter, newStatusError(fmt.Errorf("invalid %s %q: must be an RFC 3339 timestamp", param.name, v), http.StatusBadRequest)
+		}
+		*param.t = t
+	}
+	return filter, nil
 }
@@ -18,19 +18,22 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
-	"strings"
+	"net/url"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/gorilla/mux"
+	"google.golang.org/genai"
 
 	"google.golang.org/adk/server/adkrest/controllers"
 	"google.golang.org/adk/server/adkrest/internal/fakes"
 	"google.golang.org/adk/server/adkrest/internal/models"
+	"google.golang.org/adk/session"
 )
 
 func TestGetSession(t *testing.T) {
@@ -117,7 +120,7 @@ func TestGetSession(t *testing.T) {
 	for _, tt := range tc {
 		t.Run(tt.name, func(t *testing.T) {
 			sessionService := fakes.FakeSessionService{Sessions: tt.storedSessions}
-			apiController := controllers.NewSessionsAPIController(&sessionService)
+			apiController := controllers.NewSessionsAPIController(&sessionService, controllers.SessionsAPIOptions{})
 			req, err := http.NewRequest(http.MethodGet, "/apps/testApp/users/testUser/sessions/testSession", nil)
 			if err != nil {
 				t.Fatalf("new request: %v", err)
@@ -132,7 +135,7 @@ func TestGetSession(t *testing.T) {
 				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
 			}
 			if tt.wantErr != nil {
-				respErr := strings.Trim(rr.Body.String(), "\n")
+				respErr := decodeErrorMessage(t, rr.Body)
 				if tt.wantErr.Error() != respErr {
 					t.Errorf("CreateSession() mismatch (-want +got):\n%v, %v", tt.wantErr.Error(), respErr)
 				}
@@ -230,7 +233,7 @@ func TestCreateSession(t *testing.T) {
 	for _, tt := range tc {
 		t.Run(tt.name, func(t *testing.T) {
 			sessionService := fakes.FakeSessionService{Sessions: tt.storedSessions}
-			apiController := controllers.NewSessionsAPIController(&sessionService)
+			apiController := controllers.NewSessionsAPIController(&sessionService, controllers.SessionsAPIOptions{})
 			reqBytes, err := json.Marshal(tt.createRequestObj)
 			if err != nil {
 				t.Fatalf("marshal request: %v", err)
@@ -249,7 +252,7 @@ func TestCreateSession(t *testing.T) {
 				t.Errorf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
 			}
 			if tt.wantErr != nil {
-				respErr := strings.Trim(rr.Body.String(), "\n")
+				respErr := decodeErrorMessage(t, rr.Body)
 				if tt.wantErr.Error() != respErr {
 					t.Errorf("CreateSession() mismatch (-want +got):\n%v, %v", tt.wantErr.Error(), respErr)
 				}
@@ -304,7 +307,7 @@ func TestDeleteSession(t *testing.T) {
 	for _, tt := range tc {
 		t.Run(tt.name, func(t *testing.T) {
 			sessionService := fakes.FakeSessionService{Sessions: tt.storedSessions}
-			apiController := controllers.NewSessionsAPIController(&sessionService)
+			apiController := controllers.NewSessionsAPIController(&sessionService, controllers.SessionsAPIOptions{})
 			req, err := http.NewRequest(http.MethodDelete, "/apps/testApp/users/testUser/sessions/testSession", nil)
 			if err != nil {
 				t.Fatalf("new request: %v", err)
@@ -406,7 +409,7 @@ func TestListSessions(t *testing.T) {
 	for _, tt := range tc {
 		t.Run(tt.name, func(t *testing.T) {
 			sessionService := fakes.FakeSessionService{Sessions: tt.storedSessions}
-			apiController := controllers.NewSessionsAPIController(&sessionService)
+			apiController := controllers.NewSessionsAPIController(&sessionService, controllers.SessionsAPIOptions{})
 			req, err := http.NewRequest(http.MethodDelete, "/apps/testApp/users/testUser/sessions/testSession", nil)
 			if err != nil {
 				t.Fatalf("new request: %v", err)
@@ -436,6 +439,177 @@ func TestListSessions(t *testing.T) {
 	}
 }
 
+func TestListSessions_Pagination(t *testing.T) {
+	sessionService := session.InMemoryService()
+	for _, id := range []string{"s1", "s2", "s3"} {
+		if _, err := sessionService.Create(t.Context(), &session.CreateRequest{AppName: "testApp", UserID: "testUser", SessionID: id}); err != nil {
+			t.Fatalf("Create() failed: %v", err)
+		}
+	}
+	apiController := controllers.NewSessionsAPIController(sessionService, controllers.SessionsAPIOptions{})
+	list := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/apps/testApp/users/testUser/sessions"+query, nil)
+		req = mux.SetURLVars(req, map[string]string{"app_name": "testApp", "user_id": "testUser"})
+		rr := httptest.NewRecorder()
+		apiController.ListSessionsHandler(rr, req)
+		return rr
+	}
+
+	// Clients unaware of pagination get a plain list.
+	var all []models.Session
+	if err := json.NewDecoder(list("").Body).Decode(&all); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(all) != 3 {
+		t.Errorf("ListSessions() returned %d sessions, want 3", len(all))
+	}
+
+	var gotIDs []string
+	query := "?pageSize=2"
+	for range 3 {
+		rr := list(query)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+		}
+		var page models.Page[models.Session]
+		if err := json.NewDecoder(rr.Body).Decode(&page); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		for _, s := range page.Items {
+			gotIDs = append(gotIDs, s.ID)
+		}
+		if page.NextPageToken == "" {
+			break
+		}
+		query = "?pageSize=2&pageToken=" + page.NextPageToken
+	}
+	if diff := cmp.Diff([]string{"s1", "s2", "s3"}, gotIDs, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+		t.Errorf("paginated ListSessions() mismatch (-want +got):\n%s", diff)
+	}
+
+	if rr := list("?pageSize=-1"); rr.Code != http.StatusBadRequest {
+		t.Errorf("invalid pageSize status = %v, want %v", rr.Code, http.StatusBadRequest)
+	}
+}
+
+func TestListEvents(t *testing.T) {
+	sessionService := session.InMemoryService()
+	created, err := sessionService.Create(t.Context(), &session.CreateRequest{AppName: "testApp", UserID: "testUser", SessionID: "testSession"})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	start := time.Now()
+	for i := range 3 {
+		event := session.NewEvent("invocation")
+		event.Author = []string{"user", "root", "child"}[i]
+		event.Branch = []string{"", "root", "root.child"}[i]
+		event.Timestamp = start.Add(time.Duration(i) * time.Hour)
+		event.Content = genai.NewContentFromText(fmt.Sprintf("event %d", i), genai.RoleUser)
+		if err := sessionService.AppendEvent(t.Context(), created.Session, event); err != nil {
+			t.Fatalf("AppendEvent() failed: %v", err)
+		}
+	}
+	apiController := controllers.NewSessionsAPIController(sessionService, controllers.SessionsAPIOptions{})
+
+	tc := []struct {
+		name          string
+		query         string
+		wantTexts     []string
+		wantNextToken bool
+		wantStatus    int
+	}{
+		{
+			name:       "all events",
+			wantTexts:  []string{"event 0", "event 1", "event 2"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:          "first page",
+			query:         "?pageSize=2",
+			wantTexts:     []string{"event 0", "event 1"},
+			wantNextToken: true,
+			wantStatus:    http.StatusOK,
+		},
+		{
+			name:       "invalid token",
+			query:      "?pageToken=invalid",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "by author",
+			query:      "?author=root",
+			wantTexts:  []string{"event 1"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "by branch",
+			query:      "?branch=root",
+			wantTexts:  []string{"event 1", "event 2"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "by invocation",
+			query:      "?invocationId=other",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "by time range",
+			query:      "?after=" + url.QueryEscape(start.Add(30*time.Minute).Format(time.RFC3339)) + "&before=" + url.QueryEscape(start.Add(90*time.Minute).Format(time.RFC3339)),
+			wantTexts:  []string{"event 1"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:          "filtered page",
+			query:         "?branch=root&pageSize=1",
+			wantTexts:     []string{"event 1"},
+			wantNextToken: true,
+			wantStatus:    http.StatusOK,
+		},
+		{
+			name:       "invalid timestamp",
+			query:      "?after=yesterday",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/apps/testApp/users/testUser/sessions/testSession/events"+tt.query, nil)
+			req = mux.SetURLVars(req, map[string]string{"app_name": "testApp", "user_id": "testUser", "session_id": "testSession"})
+			rr := httptest.NewRecorder()
+
+			apiController.ListEventsHandler(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				var errResp controllers.ErrorResponse
+				if err := json.NewDecoder(rr.Body).Decode(&errResp); err != nil {
+					t.Fatalf("decode error response: %v", err)
+				}
+				if errResp.Error.Status != tt.wantStatus {
+					t.Errorf("error response status = %v, want %v", errResp.Error.Status, tt.wantStatus)
+				}
+				return
+			}
+			var page models.Page[models.Event]
+			if err := json.NewDecoder(rr.Body).Decode(&page); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			var texts []string
+			for _, event := range page.Items {
+				texts = append(texts, event.Content.Parts[0].Text)
+			}
+			if diff := cmp.Diff(tt.wantTexts, texts); diff != "" {
+				t.Errorf("ListEvents() mismatch (-want +got):\n%s", diff)
+			}
+			if got := page.NextPageToken != ""; got != tt.wantNextToken {
+				t.Errorf("ListEvents() has next page = %v, want %v", got, tt.wantNextToken)
+			}
+		})
+	}
+}
+
 func sessionVars(sessionID fakes.SessionKey) map[string]string {
 	return map[string]string{
 		"app_name":   sessionID.AppName,
@@ -456,3 +630,13 @@ func EquateApproxInt(margin int64) cmp.Option {
 		return diff <= margin
 	})
 }
+
+// decodeErrorMessage decodes a JSON error body and returns its message.
+func decodeErrorMessage(t *testing.T, body io.Reader) string {
+	t.Helper()
+	var resp controllers.ErrorResponse
+	if err := json.NewDecoder(body).Decode(&resp); err != nil {
+		t.Fatalf("decode error response: %v", err)
+	}
+	return resp.Error.Message
+}
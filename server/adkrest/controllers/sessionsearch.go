@@ -0,0 +1,76 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"google.golang.org/adk/internal/sessionutils"
+	"google.golang.org/adk/server/adkrest/internal/models"
+	"google.golang.org/adk/session"
+)
+
+// SearchSessionsHandler searches the events and the session states of the
+// sessions of a user for the keywords of the q query parameter, see
+// [session.Searcher], one page at a time, most recent matches first. It
+// fails with 501 status code if the session service can't search.
+func (c *SessionsAPIController) SearchSessionsHandler(rw http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	sessionID, err := models.SessionIDFromHTTPParameters(params)
+	if err != nil {
+		httpError(rw, req, err.Error(), http.StatusBadRequest)
+		return
+	}
+	query := req.URL.Query().Get("q")
+	if strings.TrimSpace(query) == "" {
+		httpError(rw, req, "q parameter is required", http.StatusBadRequest)
+		return
+	}
+	page, err := parsePageParams(req)
+	if err != nil {
+		writeError(rw, req, err)
+		return
+	}
+	if _, err := sessionutils.DecodePageToken(page.token); err != nil {
+		httpError(rw, req, err.Error(), http.StatusBadRequest)
+		return
+	}
+	searcher, ok := c.service.(session.Searcher)
+	if !ok {
+		httpError(rw, req, "the session service doesn't support search", http.StatusNotImplemented)
+		return
+	}
+	resp, err := searcher.Search(req.Context(), &session.SearchRequest{
+		AppName: sessionID.AppName,
+		UserID:  sessionID.UserID,
+		Query:   query,
+		ListOptions: session.ListOptions{
+			PageSize:  page.size,
+			PageToken: page.token,
+		},
+	})
+	if err != nil {
+		httpError(rw, req, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	result := models.Page[models.SearchResult]{Items: make([]models.SearchResult, 0, len(resp.Results)), NextPageToken: resp.NextPageToken}
+	for _, r := range resp.Results {
+		result.Items = append(result.Items, models.FromSearchResult(r))
+	}
+	EncodeJSONResponse(result, http.StatusOK, rw)
+}
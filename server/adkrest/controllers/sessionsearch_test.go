@@ -0,0 +1,133 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/server/adkrest/controllers"
+	"google.golang.org/adk/server/adkrest/internal/models"
+	"google.golang.org/adk/session"
+)
+
+func TestSearchSessionsHandler(t *testing.T) {
+	tests := []struct {
+		name          string
+		plainService  bool
+		query         url.Values
+		wantStatus    int
+		wantEventIDs  []string
+		wantNextToken bool
+	}{
+		{
+			name:         "matches",
+			query:        url.Values{"q": {"paris"}},
+			wantStatus:   http.StatusOK,
+			wantEventIDs: []string{"e2", "e0"},
+		},
+		{
+			name:          "paginated",
+			query:         url.Values{"q": {"paris"}, "pageSize": {"1"}},
+			wantStatus:    http.StatusOK,
+			wantEventIDs:  []string{"e2"},
+			wantNextToken: true,
+		},
+		{
+			name:       "no matches",
+			query:      url.Values{"q": {"tokyo"}},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "missing query",
+			query:      url.Values{"q": {" "}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid page token",
+			query:      url.Values{"q": {"paris"}, "pageToken": {"invalid"}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:         "service without search",
+			plainService: true,
+			query:        url.Values{"q": {"paris"}},
+			wantStatus:   http.StatusNotImplemented,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			sessionService := session.InMemoryService()
+			created, err := sessionService.Create(ctx, &session.CreateRequest{AppName: "app", UserID: "user", SessionID: "session"})
+			if err != nil {
+				t.Fatalf("Create() failed: %v", err)
+			}
+			start := time.Now()
+			for i, text := range []string{"Trip to Paris", "Which dates?", "Paris in May"} {
+				event := session.NewEvent("inv-1")
+				event.ID = fmt.Sprint("e", i)
+				event.Timestamp = start.Add(time.Duration(i) * time.Second)
+				event.Author = "user"
+				event.LLMResponse = model.LLMResponse{Content: genai.NewContentFromText(text, genai.RoleUser)}
+				if err := sessionService.AppendEvent(ctx, created.Session, event); err != nil {
+					t.Fatalf("AppendEvent() failed: %v", err)
+				}
+			}
+			if tt.plainService {
+				sessionService = plainSessionService{sessionService}
+			}
+
+			srv := newSessionTransferServer(t, sessionService, controllers.SessionsAPIOptions{})
+			resp, err := http.Get(srv.URL + "/apps/app/users/user/sessions:search?" + tt.query.Encode())
+			if err != nil {
+				t.Fatalf("Get() failed: %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var got models.Page[models.SearchResult]
+			if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+				t.Fatalf("Decode() failed: %v", err)
+			}
+			var eventIDs []string
+			for _, r := range got.Items {
+				if r.SessionID != "session" || r.Snippet == "" {
+					t.Errorf("result = %+v, want a snippet of session", r)
+				}
+				eventIDs = append(eventIDs, r.EventID)
+			}
+			if diff := cmp.Diff(tt.wantEventIDs, eventIDs); diff != "" {
+				t.Errorf("event IDs mismatch (-want +got):\n%s", diff)
+			}
+			if gotNext := got.NextPageToken != ""; gotNext != tt.wantNextToken {
+				t.Errorf("NextPageToken = %q, want a token: %t", got.NextPageToken, tt.wantNextToken)
+			}
+		})
+	}
+}
@@ -0,0 +1,223 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/artifact"
+	"google.golang.org/adk/server/adkrest/internal/models"
+	"google.golang.org/adk/session"
+)
+
+// DefaultMaxSessionImportSize is the default maximum size of a session import.
+const DefaultMaxSessionImportSize = 32 << 20
+
+// userAuthor is the author of the events sent by the user.
+const userAuthor = "user"
+
+// ExportSessionHandler exports a session as newline-delimited JSON: a
+// session record with its state and the names of its artifacts, followed by
+// a record per event in chronological order, see [models.ExportRecord]. The
+// app and user states, shared with the other sessions, are not exported.
+func (c *SessionsAPIController) ExportSessionHandler(rw http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	sessionID, err := models.SessionIDFromHTTPParameters(params)
+	if err != nil {
+		httpError(rw, req, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if sessionID.ID == "" {
+		httpError(rw, req, "session_id parameter is required", http.StatusBadRequest)
+		return
+	}
+	resp, err := c.service.Get(req.Context(), &session.GetRequest{
+		AppName:   sessionID.AppName,
+		UserID:    sessionID.UserID,
+		SessionID: sessionID.ID,
+	})
+	if err != nil {
+		httpError(rw, req, err.Error(), http.StatusNotFound)
+		return
+	}
+	var artifacts []string
+	if c.opts.ArtifactService != nil {
+		listResp, err := c.opts.ArtifactService.List(req.Context(), &artifact.ListRequest{
+			AppName:   sessionID.AppName,
+			UserID:    sessionID.UserID,
+			SessionID: sessionID.ID,
+		})
+		if err != nil {
+			httpError(rw, req, fmt.Sprintf("failed to list artifacts: %v", err), http.StatusInternalServerError)
+			return
+		}
+		artifacts = listResp.FileNames
+	}
+
+	rw.Header().Set("Content-Type", ContentTypeNDJSON)
+	enc := json.NewEncoder(rw)
+	if err := enc.Encode(models.ExportRecord{Type: models.ExportRecordSession, Session: models.ExportSession(resp.Session, artifacts)}); err != nil {
+		responseLogger(rw, req).Error("ADK: failed to write session export", "error", err)
+		return
+	}
+	for event := range resp.Session.Events().All() {
+		if err := enc.Encode(models.ExportRecord{Type: models.ExportRecordEvent, Event: models.ExportEvent(event)}); err != nil {
+			responseLogger(rw, req).Error("ADK: failed to write session export", "error", err)
+			return
+		}
+	}
+}
+
+// ImportSessionHandler recreates an exported session, see
+// [SessionsAPIController.ExportSessionHandler], for the app and user of the
+// path and under a new ID. The events keep their IDs, order and timestamps.
+// The app and user states are left untouched: their keys are dropped from
+// the state of the session and from the state deltas of the events.
+// Authors which are not agents of the app are reported in the response, but
+// don't fail the import.
+func (c *SessionsAPIController) ImportSessionHandler(rw http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	sessionID, err := models.SessionIDFromHTTPParameters(params)
+	if err != nil {
+		httpError(rw, req, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.Body = http.MaxBytesReader(rw, req.Body, c.opts.MaxImportSize)
+	exported, events, err := decodeSessionExport(req.Body)
+	if err != nil {
+		writeError(rw, req, err)
+		return
+	}
+
+	// The whole export is decoded first, so that malformed exports don't
+	// leave partial sessions behind.
+	ctx := req.Context()
+	created, err := c.service.Create(ctx, &session.CreateRequest{
+		AppName: sessionID.AppName,
+		UserID:  sessionID.UserID,
+		State:   exported.SessionState(),
+	})
+	if err != nil {
+		httpError(rw, req, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for _, event := range events {
+		if err := c.service.AppendEvent(ctx, created.Session, event); err != nil {
+			c.deleteImported(ctx, rw, req, created.Session)
+			httpError(rw, req, fmt.Sprintf("failed to import event %q: %v", event.ID, err), http.StatusInternalServerError)
+			return
+		}
+	}
+	getResp, err := c.service.Get(ctx, &session.GetRequest{
+		AppName:   sessionID.AppName,
+		UserID:    sessionID.UserID,
+		SessionID: created.Session.ID(),
+	})
+	if err != nil {
+		httpError(rw, req, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	imported, err := models.FromSession(getResp.Session)
+	if err != nil {
+		httpError(rw, req, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	EncodeJSONResponse(models.SessionImport{
+		Session:           imported,
+		UnresolvedAuthors: c.unresolvedAuthors(sessionID.AppName, events),
+	}, http.StatusOK, rw)
+}
+
+// deleteImported removes a session whose import failed. Failures are only
+// logged, as the import has failed anyway.
+func (c *SessionsAPIController) deleteImported(ctx context.Context, rw http.ResponseWriter, req *http.Request, s session.Session) {
+	err := c.service.Delete(context.WithoutCancel(ctx), &session.DeleteRequest{AppName: s.AppName(), UserID: s.UserID(), SessionID: s.ID()})
+	if err != nil {
+		responseLogger(rw, req).Error("ADK: failed to delete partially imported session", "session_id", s.ID(), "error", err)
+	}
+}
+
+// decodeSessionExport reads the records of a session export.
+func decodeSessionExport(r io.Reader) (*models.ExportedSession, []*session.Event, error) {
+	d := json.NewDecoder(r)
+	d.DisallowUnknownFields()
+	var header models.ExportRecord
+	if err := d.Decode(&header); err != nil {
+		return nil, nil, importDecodeError(1, err)
+	}
+	if header.Type != models.ExportRecordSession || header.Session == nil {
+		return nil, nil, newStatusError(fmt.Errorf("record 1: want a %q record, got %q", models.ExportRecordSession, header.Type), http.StatusBadRequest)
+	}
+	if header.Session.Version != models.SessionExportVersion {
+		return nil, nil, newStatusError(fmt.Errorf("unsupported export version %d, want %d", header.Session.Version, models.SessionExportVersion), http.StatusBadRequest)
+	}
+	var events []*session.Event
+	for n := 2; ; n++ {
+		var record models.ExportRecord
+		err := d.Decode(&record)
+		if errors.Is(err, io.EOF) {
+			return header.Session, events, nil
+		}
+		if err != nil {
+			return nil, nil, importDecodeError(n, err)
+		}
+		if record.Type != models.ExportRecordEvent || record.Event == nil {
+			return nil, nil, newStatusError(fmt.Errorf("record %d: want an %q record, got %q", n, models.ExportRecordEvent, record.Type), http.StatusBadRequest)
+		}
+		events = append(events, record.Event.ToSessionEvent())
+	}
+}
+
+func importDecodeError(record int, err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return newStatusError(fmt.Errorf("import exceeds the maximum size of %d bytes", maxBytesErr.Limit), http.StatusRequestEntityTooLarge)
+	}
+	return newStatusError(fmt.Errorf("record %d: failed to decode: %w", record, err), http.StatusBadRequest)
+}
+
+// unresolvedAuthors returns, in order of appearance, the authors of the
+// events which are neither the user nor an agent of the app.
+func (c *SessionsAPIController) unresolvedAuthors(appName string, events []*session.Event) []string {
+	known := map[string]bool{userAuthor: true}
+	if c.opts.AgentLoader != nil {
+		if root, err := c.opts.AgentLoader.LoadAgent(appName); err == nil {
+			addAgentNames(known, root)
+		}
+	}
+	var unresolved []string
+	for _, event := range events {
+		if event.Author != "" && !known[event.Author] {
+			unresolved = append(unresolved, event.Author)
+			known[event.Author] = true
+		}
+	}
+	return unresolved
+}
+
+func addAgentNames(names map[string]bool, a agent.Agent) {
+	names[a.Name()] = true
+	for _, sub := range a.SubAgents() {
+		addAgentNames(names, sub)
+	}
+}
@@ -0,0 +1,256 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/gorilla/mux"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/server/adkrest/controllers"
+	"google.golang.org/adk/server/adkrest/internal/models"
+	"google.golang.org/adk/server/adkrest/internal/routers"
+	"google.golang.org/adk/session"
+)
+
+func newSessionTransferServer(t *testing.T, sessionService session.Service, opts controllers.SessionsAPIOptions) *httptest.Server {
+	t.Helper()
+	router := mux.NewRouter()
+	routers.SetupSubRouters(router, routers.NewSessionsAPIRouter(controllers.NewSessionsAPIController(sessionService, opts)))
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func exportSession(t *testing.T, srv *httptest.Server, sessionID string) string {
+	t.Helper()
+	resp, err := http.Get(srv.URL + "/apps/app/users/user/sessions/" + sessionID + ":export")
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("export status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if got := resp.Header.Get("Content-Type"); got != controllers.ContentTypeNDJSON {
+		t.Errorf("Content-Type = %q, want %q", got, controllers.ContentTypeNDJSON)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("ReadAll() failed: %v", err)
+	}
+	return string(body)
+}
+
+func importSession(t *testing.T, srv *httptest.Server, export string) (*http.Response, models.SessionImport) {
+	t.Helper()
+	resp, err := http.Post(srv.URL+"/apps/app/users/user/sessions:import", controllers.ContentTypeNDJSON, strings.NewReader(export))
+	if err != nil {
+		t.Fatalf("Post() failed: %v", err)
+	}
+	defer resp.Body.Close()
+	var imported models.SessionImport
+	if resp.StatusCode == http.StatusOK {
+		if err := json.NewDecoder(resp.Body).Decode(&imported); err != nil {
+			t.Fatalf("Decode() failed: %v", err)
+		}
+	}
+	return resp, imported
+}
+
+func TestSessionExportImport_RoundTrip(t *testing.T) {
+	helper, err := agent.New(agent.Config{Name: "helper"})
+	if err != nil {
+		t.Fatalf("agent.New() failed: %v", err)
+	}
+	root, err := agent.New(agent.Config{Name: "app", SubAgents: []agent.Agent{helper}})
+	if err != nil {
+		t.Fatalf("agent.New() failed: %v", err)
+	}
+	ctx := context.Background()
+	sessionService := session.InMemoryService()
+	created, err := sessionService.Create(ctx, &session.CreateRequest{AppName: "app", UserID: "user", SessionID: "original", State: map[string]any{"topic": "weather"}})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	start := time.Date(2025, 3, 1, 12, 0, 0, 123456789, time.UTC)
+	for i, author := range []string{"user", "app", "helper", "renamed_agent"} {
+		event := session.NewEvent("inv-1")
+		event.Timestamp = start.Add(time.Duration(i) * time.Millisecond)
+		event.Author = author
+		event.LLMResponse = model.LLMResponse{Content: genai.NewContentFromText("message from "+author, genai.RoleModel)}
+		event.Actions.StateDelta = map[string]any{"turns": i + 1}
+		event.Actions.TransferToAgent = "helper"
+		if err := sessionService.AppendEvent(ctx, created.Session, event); err != nil {
+			t.Fatalf("AppendEvent() failed: %v", err)
+		}
+	}
+
+	srv := newSessionTransferServer(t, sessionService, controllers.SessionsAPIOptions{AgentLoader: agent.NewSingleLoader(root)})
+	first := exportSession(t, srv, "original")
+	resp, imported := importSession(t, srv, first)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("import status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if imported.Session.ID == "" || imported.Session.ID == "original" {
+		t.Errorf("imported session ID = %q, want a new ID", imported.Session.ID)
+	}
+	if diff := cmp.Diff([]string{"renamed_agent"}, imported.UnresolvedAuthors); diff != "" {
+		t.Errorf("unresolved authors mismatch (-want +got):\n%s", diff)
+	}
+
+	second := exportSession(t, srv, imported.Session.ID)
+	second = strings.Replace(second, `"id":"`+imported.Session.ID+`"`, `"id":"original"`, 1)
+	if diff := cmp.Diff(first, second); diff != "" {
+		t.Errorf("export of the imported session mismatch (-want +got):\n%s", diff)
+	}
+	if lines := strings.Count(first, "\n"); lines != 5 {
+		t.Errorf("export has %d lines, want the session and 4 events", lines)
+	}
+}
+
+func TestSessionExportImport_AppAndUserState(t *testing.T) {
+	ctx := context.Background()
+	sessionService := session.InMemoryService()
+	created, err := sessionService.Create(ctx, &session.CreateRequest{AppName: "app", UserID: "user", SessionID: "original", State: map[string]any{
+		"topic":       "weather",
+		"app:version": "v1",
+		"user:name":   "ada",
+	}})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	event := session.NewEvent("inv-1")
+	event.Author = "user"
+	event.Actions.StateDelta = map[string]any{"turns": 1, "app:version": "v2", "user:name": "grace"}
+	if err := sessionService.AppendEvent(ctx, created.Session, event); err != nil {
+		t.Fatalf("AppendEvent() failed: %v", err)
+	}
+
+	srv := newSessionTransferServer(t, sessionService, controllers.SessionsAPIOptions{})
+	export := exportSession(t, srv, "original")
+	if strings.Contains(export, "app:") || strings.Contains(export, "user:") {
+		t.Errorf("export has app or user state keys:\n%s", export)
+	}
+
+	// The app and user states change after the export and an export with
+	// app and user keys, e.g. from an older server, is imported: the
+	// import must not overwrite them.
+	stateService := sessionService.(session.StateService)
+	if err := stateService.UpdateAppState(ctx, &session.UpdateAppStateRequest{AppName: "app", Delta: map[string]any{"version": "v3"}}); err != nil {
+		t.Fatalf("UpdateAppState() failed: %v", err)
+	}
+	if err := stateService.UpdateUserState(ctx, &session.UpdateUserStateRequest{AppName: "app", UserID: "user", Delta: map[string]any{"name": "alan"}}); err != nil {
+		t.Fatalf("UpdateUserState() failed: %v", err)
+	}
+	legacy := strings.Replace(export, `"state":{`, `"state":{"app:version":"v1","user:name":"ada",`, 1)
+	legacy = strings.Replace(legacy, `"stateDelta":{`, `"stateDelta":{"app:version":"v2","user:name":"grace",`, 1)
+	for name, export := range map[string]string{"export": export, "legacy export": legacy} {
+		resp, imported := importSession(t, srv, export)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("%s: import status = %d, want %d", name, resp.StatusCode, http.StatusOK)
+		}
+		if diff := cmp.Diff(map[string]any{"topic": "weather", "turns": float64(1), "app:version": "v3", "user:name": "alan"}, imported.Session.State); diff != "" {
+			t.Errorf("%s: imported state mismatch (-want +got):\n%s", name, diff)
+		}
+	}
+	appState, err := stateService.GetAppState(ctx, &session.GetAppStateRequest{AppName: "app"})
+	if err != nil {
+		t.Fatalf("GetAppState() failed: %v", err)
+	}
+	if diff := cmp.Diff(map[string]any{"version": "v3"}, appState.State); diff != "" {
+		t.Errorf("app state mismatch (-want +got):\n%s", diff)
+	}
+	userState, err := stateService.GetUserState(ctx, &session.GetUserStateRequest{AppName: "app", UserID: "user"})
+	if err != nil {
+		t.Fatalf("GetUserState() failed: %v", err)
+	}
+	if diff := cmp.Diff(map[string]any{"name": "alan"}, userState.State); diff != "" {
+		t.Errorf("user state mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestImportSessionHandler_Errors(t *testing.T) {
+	validSession := `{"type":"session","session":{"version":1,"id":"s","appName":"app","userId":"user","lastUpdateTime":"2025-01-01T00:00:00Z","state":{}}}`
+	tc := []struct {
+		name       string
+		body       string
+		maxSize    int64
+		wantStatus int
+	}{
+		{
+			name:       "empty",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "missing session record",
+			body:       `{"type":"event","event":{"id":"e"}}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unsupported version",
+			body:       strings.Replace(validSession, `"version":1`, `"version":2`, 1),
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "malformed event",
+			body:       validSession + "\n{\"type\":\"event\",\"event\":",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "too large",
+			body:       validSession,
+			maxSize:    16,
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:       "session without events",
+			body:       validSession,
+			wantStatus: http.StatusOK,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			sessionService := session.InMemoryService()
+			srv := newSessionTransferServer(t, sessionService, controllers.SessionsAPIOptions{MaxImportSize: tt.maxSize})
+			resp, _ := importSession(t, srv, tt.body)
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			list, err := sessionService.List(context.Background(), &session.ListRequest{AppName: "app", UserID: "user"})
+			if err != nil {
+				t.Fatalf("List() failed: %v", err)
+			}
+			wantSessions := 0
+			if tt.wantStatus == http.StatusOK {
+				wantSessions = 1
+			}
+			if len(list.Sessions) != wantSessions {
+				t.Errorf("%d sessions after the import, want %d", len(list.Sessions), wantSessions)
+			}
+		})
+	}
+}
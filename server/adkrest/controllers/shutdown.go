@@ -0,0 +1,117 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// ErrShuttingDown is reported to the runs interrupted by
+// [RuntimeAPIController.Shutdown] and to the runs requested afterwards.
+var ErrShuttingDown = errors.New("server is shutting down")
+
+// runTracker keeps track of the in-flight run handlers, so that they can be
+// drained on shutdown.
+type runTracker struct {
+	mu       sync.Mutex
+	draining bool
+	nextID   int
+	cancels  map[int]context.CancelCauseFunc
+	wg       sync.WaitGroup
+}
+
+// start registers a new run. The returned context is cancelled with
+// [ErrShuttingDown] cause when the tracker is shut down, and done must be
+// called once the handler has finished writing the response.
+func (t *runTracker) start(ctx context.Context) (runCtx context.Context, done func(), err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.draining {
+		return nil, nil, newStatusError(ErrShuttingDown, http.StatusServiceUnavailable)
+	}
+	if t.cancels == nil {
+		t.cancels = make(map[int]context.CancelCauseFunc)
+	}
+	id := t.nextID
+	t.nextID++
+	runCtx, cancel := context.WithCancelCause(ctx)
+	t.cancels[id] = cancel
+	t.wg.Add(1)
+	return runCtx, func() {
+		t.mu.Lock()
+		delete(t.cancels, id)
+		t.mu.Unlock()
+		cancel(nil)
+		t.wg.Done()
+	}, nil
+}
+
+func (t *runTracker) isDraining() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.draining
+}
+
+// shutdown rejects new runs, cancels the in-flight ones and waits for their
+// handlers to return or for ctx to be done, whichever happens first.
+func (t *runTracker) shutdown(ctx context.Context) error {
+	t.mu.Lock()
+	t.draining = true
+	for _, cancel := range t.cancels {
+		cancel(ErrShuttingDown)
+	}
+	t.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to drain in-flight runs: %w", ctx.Err())
+	}
+}
+
+// isShutdown reports whether ctx was cancelled because of a shutdown.
+func isShutdown(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), ErrShuttingDown)
+}
+
+// Shutdown stops accepting new runs, which are rejected with 503 status code,
+// and cancels the in-flight ones. Streaming handlers finish their responses
+// with a final error event. Shutdown waits until all the run handlers return
+// or ctx is done.
+//
+// It should be called before (or concurrently with) [http.Server.Shutdown],
+// which doesn't interrupt the in-flight handlers by itself.
+func (c *RuntimeAPIController) Shutdown(ctx context.Context) error {
+	return c.runs.shutdown(ctx)
+}
+
+// Ping implements [HealthChecker]: it fails once the controller is shutting
+// down, so that the server is taken out of rotation while draining.
+func (c *RuntimeAPIController) Ping(ctx context.Context) error {
+	if c.runs.isDraining() {
+		return ErrShuttingDown
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/server/adkrest/controllers"
+	"google.golang.org/adk/server/adkrest/internal/models"
+	"google.golang.org/adk/session"
+)
+
+func TestRuntimeAPIController_Shutdown(t *testing.T) {
+	a := newSlowAgent(t, 50*time.Millisecond, "first", "second", "third", "fourth")
+	sessionService := session.InMemoryService()
+	if _, err := sessionService.Create(context.Background(), &session.CreateRequest{AppName: a.Name(), UserID: "user", SessionID: "session"}); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	controller := controllers.NewRuntimeAPIControllerWithOptions(sessionService, nil, agent.NewSingleLoader(a), nil, 10*time.Second, runner.PluginConfig{}, controllers.RuntimeAPIOptions{SSEKeepAliveInterval: -1})
+	srv := httptest.NewServer(controllers.NewErrorHandler(controller.RunSSEHandler))
+	t.Cleanup(srv.Close)
+
+	body, err := json.Marshal(models.RunAgentRequest{
+		AppName:    a.Name(),
+		UserId:     "user",
+		SessionId:  "session",
+		NewMessage: *genai.NewContentFromText("hi", genai.RoleUser),
+	})
+	if err != nil {
+		t.Fatalf("json.Marshal() failed: %v", err)
+	}
+	resp, err := http.Post(srv.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("Post() failed: %v", err)
+	}
+	defer resp.Body.Close()
+	reader := bufio.NewReader(resp.Body)
+	if first := readSSEFrame(t, reader); !strings.HasPrefix(first, "id: ") {
+		t.Fatalf("first frame = %q, want an event frame", first)
+	}
+
+	if err := controller.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() failed: %v", err)
+	}
+
+	rest, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("ReadAll() failed: %v", err)
+	}
+	frames := strings.Split(strings.TrimSuffix(string(rest), "\n\n"), "\n\n")
+	last := frames[len(frames)-1]
+	event, data := parseSSEFrame(last)
+	if event != "error" {
+		t.Fatalf("last frame = %q, want an error event", last)
+	}
+	var errResp controllers.ErrorResponse
+	if err := json.Unmarshal([]byte(data), &errResp); err != nil {
+		t.Fatalf("malformed error frame %q: %v", last, err)
+	}
+	if errResp.Error.Status != http.StatusServiceUnavailable {
+		t.Errorf("error status = %d, want %d", errResp.Error.Status, http.StatusServiceUnavailable)
+	}
+
+	// New runs are rejected once the shutdown has started.
+	resp2, err := http.Post(srv.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("Post() failed: %v", err)
+	}
+	defer resp2.Body.Close()
+	if resp2.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("status after shutdown = %d, want %d", resp2.StatusCode, http.StatusServiceUnavailable)
+	}
+}
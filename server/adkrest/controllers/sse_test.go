@@ -0,0 +1,257 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/server/adkrest/controllers"
+	"google.golang.org/adk/server/adkrest/internal/models"
+	"google.golang.org/adk/session"
+)
+
+// newSlowAgent returns an agent which waits for delay before emitting each of the given texts.
+func newSlowAgent(t *testing.T, delay time.Duration, texts ...string) agent.Agent {
+	t.Helper()
+	a, err := agent.New(agent.Config{
+		Name: "slow",
+		Run: func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				for _, text := range texts {
+					select {
+					case <-time.After(delay):
+					case <-ctx.Done():
+						yield(nil, ctx.Err())
+						return
+					}
+					event := session.NewEvent(ctx.InvocationID())
+					event.LLMResponse = model.LLMResponse{
+						Content: genai.NewContentFromText(text, genai.RoleModel),
+					}
+					if !yield(event, nil) {
+						return
+					}
+				}
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("agent.New() failed: %v", err)
+	}
+	return a
+}
+
+// newPanickingAgent returns an agent whose runs panic, as a failing tool would.
+func newPanickingAgent(t *testing.T) agent.Agent {
+	t.Helper()
+	a, err := agent.New(agent.Config{
+		Name: "panicking",
+		Run: func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				panic("tool failed")
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("agent.New() failed: %v", err)
+	}
+	return a
+}
+
+func newRunSSETestServer(t *testing.T, a agent.Agent, opts controllers.RuntimeAPIOptions) *httptest.Server {
+	t.Helper()
+	sessionService := session.InMemoryService()
+	if _, err := sessionService.Create(context.Background(), &session.CreateRequest{AppName: a.Name(), UserID: "user", SessionID: "session"}); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	controller := controllers.NewRuntimeAPIControllerWithOptions(sessionService, nil, agent.NewSingleLoader(a), nil, 10*time.Second, runner.PluginConfig{}, opts)
+	srv := httptest.NewServer(controllers.NewErrorHandler(controller.RunSSEHandler))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func postRunSSE(t *testing.T, srv *httptest.Server, appName string) string {
+	t.Helper()
+	body, err := json.Marshal(models.RunAgentRequest{
+		AppName:    appName,
+		UserId:     "user",
+		SessionId:  "session",
+		NewMessage: *genai.NewContentFromText("hi", genai.RoleUser),
+	})
+	if err != nil {
+		t.Fatalf("json.Marshal() failed: %v", err)
+	}
+	resp, err := http.Post(srv.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("Post() failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	got, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("ReadAll() failed: %v", err)
+	}
+	return string(got)
+}
+
+// parseSSEFrame returns the event type and the data of an SSE frame.
+func parseSSEFrame(frame string) (event, data string) {
+	for line := range strings.SplitSeq(frame, "\n") {
+		if v, ok := strings.CutPrefix(line, "event: "); ok {
+			event = v
+		}
+		if v, ok := strings.CutPrefix(line, "data: "); ok {
+			data = v
+		}
+	}
+	return event, data
+}
+
+// readSSEFrame reads the next frame of an SSE stream.
+func readSSEFrame(t *testing.T, r *bufio.Reader) string {
+	t.Helper()
+	var lines []string
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("ReadString() failed: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return strings.Join(lines, "\n")
+		}
+		lines = append(lines, line)
+	}
+}
+
+func TestRunSSEHandler_KeepAlive(t *testing.T) {
+	a := newSlowAgent(t, 100*time.Millisecond, "first", "second")
+	srv := newRunSSETestServer(t, a, controllers.RuntimeAPIOptions{SSEKeepAliveInterval: 10 * time.Millisecond})
+
+	body := postRunSSE(t, srv, a.Name())
+
+	var events []string
+	keepAlives := 0
+	for frame := range strings.SplitSeq(strings.TrimSuffix(body, "\n\n"), "\n\n") {
+		switch {
+		case frame == ": keep-alive":
+			keepAlives++
+		case strings.HasPrefix(frame, "id: "):
+			_, data := parseSSEFrame(frame)
+			var event models.Event
+			if err := json.Unmarshal([]byte(data), &event); err != nil {
+				t.Fatalf("malformed event frame %q: %v", frame, err)
+			}
+			events = append(events, event.Content.Parts[0].Text)
+		default:
+			t.Fatalf("unexpected frame %q in body %q", frame, body)
+		}
+	}
+	if keepAlives == 0 {
+		t.Errorf("no keep-alive comments in body %q", body)
+	}
+	if want := []string{"first", "second"}; strings.Join(events, ",") != strings.Join(want, ",") {
+		t.Errorf("events = %v, want %v", events, want)
+	}
+}
+
+func TestRunSSEHandler_KeepAliveDisabled(t *testing.T) {
+	a := newSlowAgent(t, 50*time.Millisecond, "only")
+	srv := newRunSSETestServer(t, a, controllers.RuntimeAPIOptions{SSEKeepAliveInterval: -1})
+
+	body := postRunSSE(t, srv, a.Name())
+
+	if strings.Contains(body, "keep-alive") {
+		t.Errorf("body contains keep-alive comments although they are disabled: %q", body)
+	}
+}
+
+func TestRunSSEHandler_PanicSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	a := newPanickingAgent(t)
+	srv := newRunSSETestServer(t, a, controllers.RuntimeAPIOptions{})
+	body, err := json.Marshal(models.RunAgentRequest{
+		AppName:    a.Name(),
+		UserId:     "user",
+		SessionId:  "session",
+		NewMessage: *genai.NewContentFromText("hi", genai.RoleUser),
+	})
+	if err != nil {
+		t.Fatalf("json.Marshal() failed: %v", err)
+	}
+	resp, err := http.Post(srv.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("Post() failed: %v", err)
+	}
+	defer resp.Body.Close()
+	got, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("ReadAll() failed: %v", err)
+	}
+	if !strings.Contains(string(got), `"code":"INTERNAL"`) {
+		t.Errorf("response = %q, want an INTERNAL error", got)
+	}
+
+	var span sdktrace.ReadOnlySpan
+	for _, s := range recorder.Ended() {
+		if s.Name() == "invoke_agent "+a.Name() {
+			span = s
+		}
+	}
+	if span == nil {
+		t.Fatalf("no invocation span ended, got %d spans", len(recorder.Ended()))
+	}
+	if got := span.Status().Code; got != codes.Error {
+		t.Errorf("span status = %v, want %v", got, codes.Error)
+	}
+	events := span.Events()
+	if len(events) != 1 || events[0].Name != "exception" {
+		t.Fatalf("span events = %v, want the exception of the panic", events)
+	}
+	var stack string
+	for _, attr := range events[0].Attributes {
+		if attr.Key == "exception.stacktrace" {
+			stack = attr.Value.AsString()
+		}
+	}
+	if !strings.Contains(stack, "newPanickingAgent") {
+		t.Errorf("exception stack = %q, want the stack of the panic", stack)
+	}
+}
@@ -0,0 +1,229 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/adk/server/adkrest/internal/models"
+	"google.golang.org/adk/session"
+)
+
+const (
+	// ContentTypeSSE is the media type of Server-Sent Events run streams.
+	ContentTypeSSE = "text/event-stream"
+	// ContentTypeNDJSON is the media type of newline-delimited JSON run streams.
+	ContentTypeNDJSON = "application/x-ndjson"
+)
+
+// streamFormat encodes the frames of a run stream in a wire format.
+type streamFormat interface {
+	contentType() string
+	// keepAliveFrame is written to idle streams and must be ignored by clients.
+	keepAliveFrame() string
+	event(w io.Writer, event session.Event) error
+	// runError reports an error of the agent which doesn't end the stream.
+	runError(w io.Writer, err error) error
+	// terminalError reports the error which ends the stream.
+	terminalError(w io.Writer, resp ErrorResponse) error
+	// end marks the end of the stream of a completed invocation.
+	end(w io.Writer, invocationID string) error
+}
+
+// streamWriter writes the frames of a run stream. Each frame is flushed as
+// soon as it is written, and idle streams are kept alive.
+type streamWriter struct {
+	format    streamFormat
+	rw        http.ResponseWriter
+	rc        *http.ResponseController
+	req       *http.Request
+	keepAlive *streamKeepAlive
+	// excludeThoughts removes the thoughts of the model from the events.
+	excludeThoughts bool
+}
+
+// newStreamWriter picks the format of the stream from the Accept header of
+// req and sets the response headers accordingly. SSE is used unless the
+// client prefers NDJSON.
+func newStreamWriter(rw http.ResponseWriter, rc *http.ResponseController, req *http.Request) *streamWriter {
+	var format streamFormat = sseFormat{}
+	if negotiateStreamContentType(req.Header.Get("Accept")) == ContentTypeNDJSON {
+		format = ndjsonFormat{}
+	}
+	h := rw.Header()
+	h.Set("Content-Type", format.contentType())
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	s := &streamWriter{format: format, rw: rw, rc: rc, req: req}
+	if tw, ok := rw.(*trackingResponseWriter); ok {
+		tw.stream = s
+	}
+	return s
+}
+
+// negotiateStreamContentType returns the supported stream media type with
+// the highest quality in the Accept header, preferring SSE on ties.
+func negotiateStreamContentType(accept string) string {
+	best, bestQ := ContentTypeSSE, -1.0
+	for _, r := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(r))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if (mediaType == ContentTypeSSE || mediaType == ContentTypeNDJSON) && q > bestQ {
+			best, bestQ = mediaType, q
+		}
+	}
+	return best
+}
+
+// start writes the response headers and starts the keep-alives. The caller
+// must call close before the handler returns.
+func (s *streamWriter) start(keepAliveInterval time.Duration) {
+	s.rw.WriteHeader(http.StatusOK)
+	s.keepAlive = startStreamKeepAlive(s.rw, s.rc, keepAliveInterval, s.format.keepAliveFrame())
+}
+
+// close stops the keep-alives.
+func (s *streamWriter) close() {
+	if s.keepAlive != nil {
+		s.keepAlive.close()
+	}
+}
+
+func (s *streamWriter) writeEvent(event session.Event) error {
+	if s.excludeThoughts {
+		var ok bool
+		if event, ok = withoutThoughts(event); !ok {
+			return nil
+		}
+	}
+	return s.write(func(w io.Writer) error { return s.format.event(w, event) })
+}
+
+func (s *streamWriter) writeRunError(err error) error {
+	return s.write(func(w io.Writer) error { return s.format.runError(w, err) })
+}
+
+// writeError writes err as the last frame of the stream.
+func (s *streamWriter) writeError(err error) error {
+	return s.write(func(w io.Writer) error { return s.format.terminalError(w, newErrorResponse(s.req, err)) })
+}
+
+func (s *streamWriter) writeEnd(invocationID string) error {
+	return s.write(func(w io.Writer) error { return s.format.end(w, invocationID) })
+}
+
+// write writes and flushes a complete frame. As the headers have already been
+// sent, the returned errors are only logged by [NewErrorHandler].
+func (s *streamWriter) write(frame func(w io.Writer) error) error {
+	return s.keepAlive.write(func() error {
+		if err := frame(s.rw); err != nil {
+			return newStatusError(fmt.Errorf("failed to write response: %w", err), http.StatusInternalServerError)
+		}
+		if err := s.rc.Flush(); err != nil {
+			return newStatusError(fmt.Errorf("failed to flush: %w", err), http.StatusInternalServerError)
+		}
+		return nil
+	})
+}
+
+// sseFormat writes Server-Sent Events. Persisted (non-partial) events carry
+// their ID, so that clients can resume the stream after them.
+type sseFormat struct{}
+
+func (sseFormat) contentType() string    { return ContentTypeSSE }
+func (sseFormat) keepAliveFrame() string { return ": keep-alive\n\n" }
+
+func (sseFormat) event(w io.Writer, event session.Event) error {
+	if !event.Partial && event.ID != "" {
+		if _, err := fmt.Fprintf(w, "id: %s\n", event.ID); err != nil {
+			return err
+		}
+	}
+	return writeSSEData(w, models.FromSessionEvent(event))
+}
+
+func (sseFormat) runError(w io.Writer, err error) error {
+	_, err = fmt.Fprintf(w, "Error while running agent: %v\n", err)
+	return err
+}
+
+func (sseFormat) terminalError(w io.Writer, resp ErrorResponse) error {
+	if _, err := fmt.Fprintf(w, "event: error\n"); err != nil {
+		return err
+	}
+	return writeSSEData(w, resp)
+}
+
+func (sseFormat) end(w io.Writer, invocationID string) error {
+	if _, err := fmt.Fprintf(w, "event: end\n"); err != nil {
+		return err
+	}
+	return writeSSEData(w, streamEnd{InvocationID: invocationID})
+}
+
+func writeSSEData(w io.Writer, v any) error {
+	if _, err := fmt.Fprintf(w, "data: "); err != nil {
+		return err
+	}
+	// The encoder terminates the data line, the extra new line ends the frame.
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\n")
+	return err
+}
+
+// ndjsonFormat writes one JSON value per line: events are encoded as
+// [models.Event], errors as [ErrorResponse] and the end of the stream as
+// {"end":{"invocationId":...}}.
+type ndjsonFormat struct{}
+
+func (ndjsonFormat) contentType() string { return ContentTypeNDJSON }
+
+// keepAliveFrame returns an empty line, which JSON decoders skip as whitespace.
+func (ndjsonFormat) keepAliveFrame() string { return "\n" }
+
+func (ndjsonFormat) event(w io.Writer, event session.Event) error {
+	return json.NewEncoder(w).Encode(models.FromSessionEvent(event))
+}
+
+func (ndjsonFormat) runError(w io.Writer, err error) error {
+	return json.NewEncoder(w).Encode(newErrorResponse(nil, newStatusError(fmt.Errorf("error while running agent: %w", err), http.StatusInternalServerError)))
+}
+
+func (ndjsonFormat) terminalError(w io.Writer, resp ErrorResponse) error {
+	return json.NewEncoder(w).Encode(resp)
+}
+
+func (ndjsonFormat) end(w io.Writer, invocationID string) error {
+	return json.NewEncoder(w).Encode(struct {
+		End streamEnd `json:"end"`
+	}{End: streamEnd{InvocationID: invocationID}})
+}
@@ -0,0 +1,149 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/server/adkrest/controllers"
+	"google.golang.org/adk/server/adkrest/internal/models"
+)
+
+func decodeSSEStream(t *testing.T, body io.Reader) []models.Event {
+	t.Helper()
+	b, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatalf("ReadAll() failed: %v", err)
+	}
+	var events []models.Event
+	for frame := range strings.SplitSeq(strings.TrimSpace(string(b)), "\n\n") {
+		_, data := parseSSEFrame(frame)
+		if data == "" {
+			// A keep-alive comment.
+			continue
+		}
+		var event models.Event
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			t.Fatalf("malformed event frame %q: %v", frame, err)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func decodeNDJSONStream(t *testing.T, body io.Reader) []models.Event {
+	t.Helper()
+	var events []models.Event
+	d := json.NewDecoder(body)
+	for {
+		var event models.Event
+		err := d.Decode(&event)
+		if errors.Is(err, io.EOF) {
+			return events
+		}
+		if err != nil {
+			t.Fatalf("Decode() failed: %v", err)
+		}
+		events = append(events, event)
+	}
+}
+
+func TestRunSSEHandler_StreamFormats(t *testing.T) {
+	tc := []struct {
+		name            string
+		accept          string
+		wantContentType string
+		decode          func(*testing.T, io.Reader) []models.Event
+	}{
+		{
+			name:            "no accept header",
+			wantContentType: controllers.ContentTypeSSE,
+			decode:          decodeSSEStream,
+		},
+		{
+			name:            "event stream",
+			accept:          "text/event-stream",
+			wantContentType: controllers.ContentTypeSSE,
+			decode:          decodeSSEStream,
+		},
+		{
+			name:            "ndjson",
+			accept:          "application/x-ndjson",
+			wantContentType: controllers.ContentTypeNDJSON,
+			decode:          decodeNDJSONStream,
+		},
+		{
+			name:            "ndjson preferred by quality",
+			accept:          "text/event-stream;q=0.5, application/x-ndjson",
+			wantContentType: controllers.ContentTypeNDJSON,
+			decode:          decodeNDJSONStream,
+		},
+		{
+			name:            "unsupported type",
+			accept:          "application/json",
+			wantContentType: controllers.ContentTypeSSE,
+			decode:          decodeSSEStream,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newRunSSETestServer(t, newSlowAgent(t, 20*time.Millisecond, "first", "second"), controllers.RuntimeAPIOptions{SSEKeepAliveInterval: 5 * time.Millisecond})
+			body, err := json.Marshal(models.RunAgentRequest{
+				AppName:    "slow",
+				UserId:     "user",
+				SessionId:  "session",
+				NewMessage: *genai.NewContentFromText("hi", genai.RoleUser),
+			})
+			if err != nil {
+				t.Fatalf("json.Marshal() failed: %v", err)
+			}
+			req, err := http.NewRequest(http.MethodPost, srv.URL, bytes.NewReader(body))
+			if err != nil {
+				t.Fatalf("NewRequest() failed: %v", err)
+			}
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Do() failed: %v", err)
+			}
+			defer resp.Body.Close()
+			if got := resp.Header.Get("Content-Type"); got != tt.wantContentType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.wantContentType)
+			}
+
+			// Keep-alives are interleaved with the events and must not
+			// break the decoding.
+			var texts []string
+			for _, event := range tt.decode(t, resp.Body) {
+				texts = append(texts, event.Content.Parts[0].Text)
+			}
+			if diff := cmp.Diff([]string{"first", "second"}, texts); diff != "" {
+				t.Errorf("events mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
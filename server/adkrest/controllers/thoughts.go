@@ -0,0 +1,49 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/session"
+)
+
+// withoutThoughts returns the event without the thought parts of its
+// content, for the runs whose request excludes them. It returns false if
+// the event is partial and nothing is left of it, in which case it isn't
+// sent. The events which aren't partial are always sent, so that clients
+// can resume the stream after them.
+func withoutThoughts(event session.Event) (session.Event, bool) {
+	if event.Content == nil {
+		return event, true
+	}
+	var parts []*genai.Part
+	for _, part := range event.Content.Parts {
+		if part != nil && !part.Thought {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) == len(event.Content.Parts) {
+		return event, true
+	}
+	if len(parts) == 0 {
+		event.Content = nil
+		return event, !event.Partial
+	}
+	content := *event.Content
+	content.Parts = parts
+	event.Content = &content
+	return event, true
+}
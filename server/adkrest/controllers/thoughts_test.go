@@ -0,0 +1,93 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+)
+
+func TestWithoutThoughts(t *testing.T) {
+	thought := &genai.Part{Text: "thinking", Thought: true}
+	text := &genai.Part{Text: "answer"}
+	event := func(partial bool, parts ...*genai.Part) session.Event {
+		e := session.Event{ID: "event", LLMResponse: model.LLMResponse{Partial: partial}}
+		if parts != nil {
+			e.Content = &genai.Content{Role: genai.RoleModel, Parts: parts}
+		}
+		return e
+	}
+
+	tests := []struct {
+		name   string
+		event  session.Event
+		want   session.Event
+		wantOK bool
+	}{
+		{
+			name:   "no_content",
+			event:  event(false),
+			want:   event(false),
+			wantOK: true,
+		},
+		{
+			name:   "no_thoughts",
+			event:  event(false, text),
+			want:   event(false, text),
+			wantOK: true,
+		},
+		{
+			name:   "thoughts_and_text",
+			event:  event(true, thought, text),
+			want:   event(true, text),
+			wantOK: true,
+		},
+		{
+			name:   "only_thoughts",
+			event:  event(false, thought),
+			want:   event(false),
+			wantOK: true,
+		},
+		{
+			name:   "partial_only_thoughts",
+			event:  event(true, thought),
+			want:   event(true),
+			wantOK: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := withoutThoughts(tt.event)
+			if ok != tt.wantOK {
+				t.Errorf("withoutThoughts() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("withoutThoughts() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	// The content of the event isn't modified.
+	e := event(false, thought, text)
+	withoutThoughts(e)
+	if got := len(e.Content.Parts); got != 2 {
+		t.Errorf("withoutThoughts() modified the event, got %d parts, want 2", got)
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/gorilla/mux"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/internal/utils"
+	"google.golang.org/adk/server/adkrest/internal/models"
+	"google.golang.org/adk/session"
+)
+
+// SubmitToolResultHandler submits the result of a pending long-running
+// function call of an invocation, a [models.SubmitToolResultRequest], and
+// resumes the flow: the agent which made the call runs with the result as
+// the function response of the user, in a new invocation, and the response
+// has all the events of the run, as for the run endpoint.
+//
+// The pending calls are found in the events of the session, so they can be
+// submitted after a restart of the server. It fails with 404 status code if
+// the invocation has no long-running call with the ID, and with 409 status
+// code if the invocation is still running or the result of the call was
+// already submitted.
+func (c *RuntimeAPIController) SubmitToolResultHandler(rw http.ResponseWriter, req *http.Request) error {
+	var submitReq models.SubmitToolResultRequest
+	sess, invocationID, err := c.pausedInvocation(rw, req, &submitReq)
+	if err != nil {
+		return err
+	}
+	if submitReq.FunctionCallID == "" || submitReq.Response == nil {
+		return newStatusError(errors.New("functionCallId and response are required"), http.StatusBadRequest)
+	}
+	call, submitted := findLongRunningCall(sess.Events(), invocationID, submitReq.FunctionCallID)
+	if call == nil {
+		return newStatusError(fmt.Errorf("invocation %q has no long-running function call %q", invocationID, submitReq.FunctionCallID), http.StatusNotFound)
+	}
+	if submitted {
+		return newStatusError(fmt.Errorf("the result of function call %q was already submitted", submitReq.FunctionCallID), http.StatusConflict)
+	}
+	return c.resumeWithResult(rw, req, sess, call, submitReq.Response, submitReq.ExcludeThoughts)
+}
+
+// resumeWithResult runs the agent with the result of the long-running call as
+// the function response of the user, and responds with the events of the run.
+func (c *RuntimeAPIController) resumeWithResult(rw http.ResponseWriter, req *http.Request, sess session.Session, call *genai.FunctionCall, result map[string]any, excludeThoughts bool) error {
+	runAgentRequest := models.RunAgentRequest{
+		AppName:   sess.AppName(),
+		UserId:    sess.UserID(),
+		SessionId: sess.ID(),
+		NewMessage: genai.Content{
+			Role: genai.RoleUser,
+			Parts: []*genai.Part{{
+				FunctionResponse: &genai.FunctionResponse{
+					ID:       call.ID,
+					Name:     call.Name,
+					Response: result,
+				},
+			}},
+		},
+		ExcludeThoughts: excludeThoughts,
+	}
+	if err := c.checkRateLimit(rw, req, runAgentRequest); err != nil {
+		return err
+	}
+	return c.runSync(rw, req, runAgentRequest)
+}
+
+// pausedInvocation decodes the request body, which resumes a paused
+// invocation, into body. It returns the session of the invocation given in
+// the path, and the ID of the invocation, which must not be running.
+func (c *RuntimeAPIController) pausedInvocation(rw http.ResponseWriter, req *http.Request, body any) (session.Session, string, error) {
+	params := mux.Vars(req)
+	sessionID, err := models.SessionIDFromHTTPParameters(params)
+	if err != nil {
+		return nil, "", newStatusError(err, http.StatusBadRequest)
+	}
+	invocationID := params["invocation_id"]
+	if sessionID.ID == "" || invocationID == "" {
+		return nil, "", newStatusError(errors.New("session_id and invocation_id parameters are required"), http.StatusBadRequest)
+	}
+	addLogAttrs(rw, "app_name", sessionID.AppName, "session_id", sessionID.ID, "invocation_id", invocationID)
+	if err := checkUser(req, sessionID.UserID); err != nil {
+		return nil, "", err
+	}
+
+	d := json.NewDecoder(req.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(body); err != nil {
+		return nil, "", newStatusError(fmt.Errorf("failed to decode request: %w", err), http.StatusBadRequest)
+	}
+
+	if _, running := c.invocations.get(invocationKey{
+		appName:      sessionID.AppName,
+		userID:       sessionID.UserID,
+		sessionID:    sessionID.ID,
+		invocationID: invocationID,
+	}); running {
+		return nil, "", newStatusError(fmt.Errorf("invocation %q is still running", invocationID), http.StatusConflict)
+	}
+	resp, err := c.sessionService.Get(req.Context(), &session.GetRequest{
+		AppName:   sessionID.AppName,
+		UserID:    sessionID.UserID,
+		SessionID: sessionID.ID,
+	})
+	if err != nil {
+		return nil, "", newStatusError(fmt.Errorf("failed to get session: %w", err), http.StatusNotFound)
+	}
+	return resp.Session, invocationID, nil
+}
+
+// findLongRunningCall returns the long-running function call of the
+// invocation with the ID, and whether its result was submitted, i.e. a later
+// event of the user has a response to the call.
+func findLongRunningCall(events session.Events, invocationID, callID string) (call *genai.FunctionCall, submitted bool) {
+	for event := range events.All() {
+		if call == nil {
+			if event.InvocationID != invocationID {
+				continue
+			}
+			for _, fc := range utils.FunctionCalls(event.Content) {
+				if fc.ID == callID && slices.Contains(event.LongRunningToolIDs, callID) {
+					call = fc
+				}
+			}
+			continue
+		}
+		if event.Author != "user" {
+			continue
+		}
+		for _, fr := range utils.FunctionResponses(event.Content) {
+			if fr.ID == callID {
+				return call, true
+			}
+		}
+	}
+	return call, false
+}
+
+// findPendingResponse returns the pending response of the long-running call
+// of the invocation with the ID.
+func findPendingResponse(events session.Events, invocationID, callID string) map[string]any {
+	for event := range events.All() {
+		if event.InvocationID != invocationID || event.Author == "user" {
+			continue
+		}
+		for _, fr := range utils.FunctionResponses(event.Content) {
+			if fr.ID == callID {
+				return fr.Response
+			}
+		}
+	}
+	return nil
+}
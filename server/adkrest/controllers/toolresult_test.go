@@ -0,0 +1,168 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/gorilla/mux"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/internal/testutil"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/server/adkrest/controllers"
+	"google.golang.org/adk/server/adkrest/internal/models"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+func TestSubmitToolResultHandler(t *testing.T) {
+	type renderArgs struct {
+		Video string `json:"video"`
+	}
+	render, err := functiontool.NewLongRunning(functiontool.Config{Name: "render", Description: "renders a video"},
+		func(ctx tool.Context, args renderArgs) (map[string]any, error) {
+			return map[string]any{"job": "job-1"}, nil
+		})
+	if err != nil {
+		t.Fatalf("functiontool.NewLongRunning() failed: %v", err)
+	}
+	llm := &testutil.MockModel{Responses: []*genai.Content{
+		genai.NewContentFromFunctionCall("render", map[string]any{"video": "intro"}, genai.RoleModel),
+		genai.NewContentFromText("The video is at gs://videos/intro.mp4.", genai.RoleModel),
+	}}
+	a, err := llmagent.New(llmagent.Config{Name: "renderer", Model: llm, Tools: []tool.Tool{render}})
+	if err != nil {
+		t.Fatalf("llmagent.New() failed: %v", err)
+	}
+
+	sessionService := session.InMemoryService()
+	if _, err := sessionService.Create(t.Context(), &session.CreateRequest{AppName: "renderer", UserID: "user", SessionID: "session"}); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	controller := controllers.NewRuntimeAPIControllerWithOptions(sessionService, nil, agent.NewSingleLoader(a), nil, 10*time.Second, runner.PluginConfig{}, controllers.RuntimeAPIOptions{})
+	router := mux.NewRouter()
+	router.HandleFunc("/run", controllers.NewErrorHandler(controller.RunHandler))
+	router.HandleFunc("/apps/{app_name}/users/{user_id}/sessions/{session_id}/invocations/{invocation_id}:submitToolResult", controllers.NewErrorHandler(controller.SubmitToolResultHandler))
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+
+	post := func(t *testing.T, path string, body any) (int, []models.Event) {
+		t.Helper()
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("json.Marshal() failed: %v", err)
+		}
+		resp, err := http.Post(srv.URL+path, "application/json", bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("POST %s failed: %v", path, err)
+		}
+		defer resp.Body.Close()
+		var events []models.Event
+		if resp.StatusCode == http.StatusOK {
+			if err := json.NewDecoder(resp.Body).Decode(&events); err != nil {
+				t.Fatalf("failed to decode events: %v", err)
+			}
+		}
+		return resp.StatusCode, events
+	}
+
+	status, events := post(t, "/run", models.RunAgentRequest{
+		AppName:    "renderer",
+		UserId:     "user",
+		SessionId:  "session",
+		NewMessage: *genai.NewContentFromText("Render the intro.", genai.RoleUser),
+	})
+	if status != http.StatusOK || len(events) != 2 {
+		t.Fatalf("run: status = %d, %d events, want 200 and 2 events", status, len(events))
+	}
+	// The invocation ends with the pending response of the call.
+	pending := events[1]
+	if len(pending.LongRunningToolIDs) != 1 || pending.Content == nil || pending.Content.Parts[0].FunctionResponse == nil {
+		t.Fatalf("last event = %+v, want the pending response of the call", pending)
+	}
+	callID := pending.LongRunningToolIDs[0]
+	wantPending := map[string]any{"job": "job-1", "status": functiontool.PendingStatus}
+	if diff := cmp.Diff(wantPending, pending.Content.Parts[0].FunctionResponse.Response); diff != "" {
+		t.Errorf("pending response mismatch (-want +got):\n%s", diff)
+	}
+	if len(llm.Requests) != 1 {
+		t.Fatalf("model called %d times, want 1", len(llm.Requests))
+	}
+
+	submitPath := "/apps/renderer/users/user/sessions/session/invocations/" + pending.InvocationID + ":submitToolResult"
+	result := map[string]any{"url": "gs://videos/intro.mp4"}
+	for _, tt := range []struct {
+		name string
+		path string
+		body models.SubmitToolResultRequest
+		want int
+	}{
+		{name: "missing response", path: submitPath, body: models.SubmitToolResultRequest{FunctionCallID: callID}, want: http.StatusBadRequest},
+		{name: "unknown call", path: submitPath, body: models.SubmitToolResultRequest{FunctionCallID: "other", Response: result}, want: http.StatusNotFound},
+		{
+			name: "unknown invocation",
+			path: "/apps/renderer/users/user/sessions/session/invocations/other:submitToolResult",
+			body: models.SubmitToolResultRequest{FunctionCallID: callID, Response: result},
+			want: http.StatusNotFound,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if status, _ := post(t, tt.path, tt.body); status != tt.want {
+				t.Errorf("status = %d, want %d", status, tt.want)
+			}
+		})
+	}
+
+	status, events = post(t, submitPath, models.SubmitToolResultRequest{FunctionCallID: callID, Response: result})
+	if status != http.StatusOK || len(events) == 0 {
+		t.Fatalf("submit: status = %d, %d events, want 200 and events", status, len(events))
+	}
+	if got := events[len(events)-1].Content.Parts[0].Text; got != "The video is at gs://videos/intro.mp4." {
+		t.Errorf("final answer = %q, want the answer of the model", got)
+	}
+	if len(llm.Requests) != 2 {
+		t.Fatalf("model called %d times, want 2", len(llm.Requests))
+	}
+	// The model is given the submitted result instead of the pending response.
+	contents := llm.Requests[1].Contents
+	last := contents[len(contents)-1]
+	if len(last.Parts) != 1 || last.Parts[0].FunctionResponse == nil {
+		t.Fatalf("last content = %+v, want the function response", last)
+	}
+	if diff := cmp.Diff(result, last.Parts[0].FunctionResponse.Response); diff != "" {
+		t.Errorf("function response given to the model mismatch (-want +got):\n%s", diff)
+	}
+	for _, c := range contents {
+		for _, p := range c.Parts {
+			if p.FunctionResponse != nil && p.FunctionResponse.Response["status"] == functiontool.PendingStatus {
+				t.Errorf("the model was given the pending response %v", p.FunctionResponse.Response)
+			}
+		}
+	}
+
+	if status, _ := post(t, submitPath, models.SubmitToolResultRequest{FunctionCallID: callID, Response: result}); status != http.StatusConflict {
+		t.Errorf("second submit: status = %d, want %d", status, http.StatusConflict)
+	}
+}
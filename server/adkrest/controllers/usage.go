@@ -0,0 +1,71 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"google.golang.org/adk/server/adkrest/internal/models"
+	"google.golang.org/adk/session"
+)
+
+// GetInvocationUsageHandler returns the token usage of an invocation, summed
+// from the usage metadata of its events, in total and by model. The usage
+// of a running invocation is that of its events so far.
+func (c *RuntimeAPIController) GetInvocationUsageHandler(rw http.ResponseWriter, req *http.Request) error {
+	params := mux.Vars(req)
+	sessionID, err := models.SessionIDFromHTTPParameters(params)
+	if err != nil {
+		return newStatusError(err, http.StatusBadRequest)
+	}
+	invocationID := params["invocation_id"]
+	if sessionID.ID == "" || invocationID == "" {
+		return newStatusError(errors.New("session_id and invocation_id parameters are required"), http.StatusBadRequest)
+	}
+	addLogAttrs(rw, "app_name", sessionID.AppName, "session_id", sessionID.ID, "invocation_id", invocationID)
+
+	resp, err := c.sessionService.Get(req.Context(), &session.GetRequest{
+		AppName:   sessionID.AppName,
+		UserID:    sessionID.UserID,
+		SessionID: sessionID.ID,
+	})
+	if err != nil {
+		return newStatusError(fmt.Errorf("failed to get session: %w", err), http.StatusNotFound)
+	}
+	_, running := c.invocations.get(invocationKey{
+		appName:      sessionID.AppName,
+		userID:       sessionID.UserID,
+		sessionID:    sessionID.ID,
+		invocationID: invocationID,
+	})
+	found := running
+	for event := range resp.Session.Events().All() {
+		if event.InvocationID == invocationID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return newStatusError(fmt.Errorf("invocation %q not found", invocationID), http.StatusNotFound)
+	}
+	usage := models.FromInvocationUsage(session.NewInvocationUsage(invocationID, resp.Session.Events()))
+	usage.Running = running
+	EncodeJSONResponse(usage, http.StatusOK, rw)
+	return nil
+}
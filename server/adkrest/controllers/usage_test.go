@@ -0,0 +1,135 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/gorilla/mux"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/server/adkrest/controllers"
+	"google.golang.org/adk/server/adkrest/internal/models"
+	"google.golang.org/adk/session"
+)
+
+func TestGetInvocationUsageHandler(t *testing.T) {
+	ctx := context.Background()
+	sessionService := session.InMemoryService()
+	created, err := sessionService.Create(ctx, &session.CreateRequest{AppName: "slow", UserID: "user", SessionID: "session"})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	usageEvent := func(invocationID, modelName string, prompt, candidates int32) *session.Event {
+		event := session.NewEvent(invocationID)
+		event.Author = "agent"
+		event.UsageMetadata = &genai.GenerateContentResponseUsageMetadata{
+			PromptTokenCount:     prompt,
+			CandidatesTokenCount: candidates,
+			TotalTokenCount:      prompt + candidates,
+		}
+		if modelName != "" {
+			event.CustomMetadata = map[string]any{model.ModelMetadataKey: modelName}
+		}
+		return event
+	}
+	userEvent := session.NewEvent("inv-1")
+	userEvent.Author = "user"
+	userEvent.Content = genai.NewContentFromText("hi", genai.RoleUser)
+	cached := usageEvent("inv-1", "gemini", 100, 20)
+	cached.UsageMetadata.CachedContentTokenCount = 60
+	cached.UsageMetadata.ThoughtsTokenCount = 5
+	for _, event := range []*session.Event{
+		userEvent,
+		cached,
+		usageEvent("inv-1", "gemini", 150, 30),
+		usageEvent("inv-1", "claude", 10, 2),
+		usageEvent("inv-1", "", 1, 1),
+		usageEvent("inv-2", "gemini", 1000, 1000),
+	} {
+		if err := sessionService.AppendEvent(ctx, created.Session, event); err != nil {
+			t.Fatalf("AppendEvent() failed: %v", err)
+		}
+	}
+
+	controller := controllers.NewRuntimeAPIControllerWithOptions(sessionService, nil, agent.NewSingleLoader(newSlowAgent(t, 0)), nil, 10*time.Second, runner.PluginConfig{}, controllers.RuntimeAPIOptions{})
+	router := mux.NewRouter()
+	router.HandleFunc("/apps/{app_name}/users/{user_id}/sessions/{session_id}/invocations/{invocation_id}/usage", controllers.NewErrorHandler(controller.GetInvocationUsageHandler))
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		want       models.InvocationUsage
+	}{
+		{
+			name:       "usage",
+			path:       "/apps/slow/users/user/sessions/session/invocations/inv-1/usage",
+			wantStatus: http.StatusOK,
+			want: models.InvocationUsage{
+				InvocationID: "inv-1",
+				Total:        models.Usage{Responses: 4, PromptTokenCount: 261, CandidatesTokenCount: 53, CachedContentTokenCount: 60, ThoughtsTokenCount: 5, TotalTokenCount: 314},
+				Models: map[string]models.Usage{
+					"gemini": {Responses: 2, PromptTokenCount: 250, CandidatesTokenCount: 50, CachedContentTokenCount: 60, ThoughtsTokenCount: 5, TotalTokenCount: 300},
+					"claude": {Responses: 1, PromptTokenCount: 10, CandidatesTokenCount: 2, TotalTokenCount: 12},
+					"":       {Responses: 1, PromptTokenCount: 1, CandidatesTokenCount: 1, TotalTokenCount: 2},
+				},
+			},
+		},
+		{
+			name:       "unknown invocation",
+			path:       "/apps/slow/users/user/sessions/session/invocations/unknown/usage",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "unknown session",
+			path:       "/apps/slow/users/user/sessions/unknown/invocations/inv-1/usage",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Get(srv.URL + tt.path)
+			if err != nil {
+				t.Fatalf("Get() failed: %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var got models.InvocationUsage
+			if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+				t.Fatalf("Decode() failed: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("usage mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"google.golang.org/adk/server/adkrest/internal/models"
+	"google.golang.org/adk/tool/userchoicetool"
+)
+
+// SubmitChoiceHandler submits the selection of the user for a pending call of
+// the get_user_choice tool of an invocation, a [models.SubmitChoiceRequest],
+// and resumes the flow as [RuntimeAPIController.SubmitToolResultHandler]
+// does, with the result of the selection.
+//
+// It fails with 400 status code if the selection isn't one of the options of
+// a choice which isn't freeform. The model is given a timeout result for the
+// selections submitted after the choice expired.
+func (c *RuntimeAPIController) SubmitChoiceHandler(rw http.ResponseWriter, req *http.Request) error {
+	var choiceReq models.SubmitChoiceRequest
+	sess, invocationID, err := c.pausedInvocation(rw, req, &choiceReq)
+	if err != nil {
+		return err
+	}
+	if choiceReq.FunctionCallID == "" {
+		return newStatusError(errors.New("functionCallId is required"), http.StatusBadRequest)
+	}
+	call, submitted := findLongRunningCall(sess.Events(), invocationID, choiceReq.FunctionCallID)
+	if call == nil || call.Name != userchoicetool.Name {
+		return newStatusError(fmt.Errorf("invocation %q has no pending choice %q", invocationID, choiceReq.FunctionCallID), http.StatusNotFound)
+	}
+	if submitted {
+		return newStatusError(fmt.Errorf("the selection of choice %q was already submitted", choiceReq.FunctionCallID), http.StatusConflict)
+	}
+	choice, err := userchoicetool.ChoiceOf(findPendingResponse(sess.Events(), invocationID, call.ID))
+	if err != nil {
+		return newStatusError(fmt.Errorf("invocation %q has no pending choice %q: %w", invocationID, choiceReq.FunctionCallID, err), http.StatusNotFound)
+	}
+	result, err := choice.Result(choiceReq.Selection, time.Now())
+	if err != nil {
+		return newStatusError(err, http.StatusBadRequest)
+	}
+	return c.resumeWithResult(rw, req, sess, call, result, choiceReq.ExcludeThoughts)
+}
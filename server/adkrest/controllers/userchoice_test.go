@@ -0,0 +1,136 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/gorilla/mux"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/internal/testutil"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/server/adkrest/controllers"
+	"google.golang.org/adk/server/adkrest/internal/models"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/userchoicetool"
+)
+
+func TestSubmitChoiceHandler(t *testing.T) {
+	choiceTool, err := userchoicetool.New(userchoicetool.Config{})
+	if err != nil {
+		t.Fatalf("userchoicetool.New() failed: %v", err)
+	}
+	llm := &testutil.MockModel{Responses: []*genai.Content{
+		genai.NewContentFromFunctionCall(userchoicetool.Name, map[string]any{"options": []any{"AF123", "LH456"}}, genai.RoleModel),
+		genai.NewContentFromText("LH456 is booked.", genai.RoleModel),
+	}}
+	a, err := llmagent.New(llmagent.Config{Name: "travel", Model: llm, Tools: []tool.Tool{choiceTool}})
+	if err != nil {
+		t.Fatalf("llmagent.New() failed: %v", err)
+	}
+
+	sessionService := session.InMemoryService()
+	if _, err := sessionService.Create(t.Context(), &session.CreateRequest{AppName: "travel", UserID: "user", SessionID: "session"}); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	controller := controllers.NewRuntimeAPIControllerWithOptions(sessionService, nil, agent.NewSingleLoader(a), nil, 10*time.Second, runner.PluginConfig{}, controllers.RuntimeAPIOptions{})
+	router := mux.NewRouter()
+	router.HandleFunc("/run", controllers.NewErrorHandler(controller.RunHandler))
+	router.HandleFunc("/apps/{app_name}/users/{user_id}/sessions/{session_id}/invocations/{invocation_id}:submitChoice", controllers.NewErrorHandler(controller.SubmitChoiceHandler))
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+
+	post := func(t *testing.T, path string, body any) (int, []models.Event) {
+		t.Helper()
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("json.Marshal() failed: %v", err)
+		}
+		resp, err := http.Post(srv.URL+path, "application/json", bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("POST %s failed: %v", path, err)
+		}
+		defer resp.Body.Close()
+		var events []models.Event
+		if resp.StatusCode == http.StatusOK {
+			if err := json.NewDecoder(resp.Body).Decode(&events); err != nil {
+				t.Fatalf("failed to decode events: %v", err)
+			}
+		}
+		return resp.StatusCode, events
+	}
+
+	status, events := post(t, "/run", models.RunAgentRequest{
+		AppName:    "travel",
+		UserId:     "user",
+		SessionId:  "session",
+		NewMessage: *genai.NewContentFromText("Book me a flight to Paris.", genai.RoleUser),
+	})
+	if status != http.StatusOK || len(events) != 2 {
+		t.Fatalf("run: status = %d, %d events, want 200 and 2 events", status, len(events))
+	}
+	pending := events[1]
+	if len(pending.LongRunningToolIDs) != 1 {
+		t.Fatalf("last event = %+v, want the pending choice", pending)
+	}
+	callID := pending.LongRunningToolIDs[0]
+
+	submitPath := "/apps/travel/users/user/sessions/session/invocations/" + pending.InvocationID + ":submitChoice"
+	for _, tt := range []struct {
+		name string
+		body models.SubmitChoiceRequest
+		want int
+	}{
+		{name: "missing call", body: models.SubmitChoiceRequest{Selection: "LH456"}, want: http.StatusBadRequest},
+		{name: "unknown call", body: models.SubmitChoiceRequest{FunctionCallID: "other", Selection: "LH456"}, want: http.StatusNotFound},
+		{name: "invalid selection", body: models.SubmitChoiceRequest{FunctionCallID: callID, Selection: "BA789"}, want: http.StatusBadRequest},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if status, _ := post(t, submitPath, tt.body); status != tt.want {
+				t.Errorf("status = %d, want %d", status, tt.want)
+			}
+		})
+	}
+
+	status, events = post(t, submitPath, models.SubmitChoiceRequest{FunctionCallID: callID, Selection: "LH456"})
+	if status != http.StatusOK || len(events) == 0 {
+		t.Fatalf("submit: status = %d, %d events, want 200 and events", status, len(events))
+	}
+	if got := events[len(events)-1].Content.Parts[0].Text; got != "LH456 is booked." {
+		t.Errorf("final answer = %q, want the answer of the model", got)
+	}
+	contents := llm.Requests[len(llm.Requests)-1].Contents
+	last := contents[len(contents)-1]
+	if len(last.Parts) != 1 || last.Parts[0].FunctionResponse == nil {
+		t.Fatalf("last content = %+v, want the function response", last)
+	}
+	if diff := cmp.Diff(map[string]any{"selection": "LH456"}, last.Parts[0].FunctionResponse.Response); diff != "" {
+		t.Errorf("function response given to the model mismatch (-want +got):\n%s", diff)
+	}
+
+	if status, _ := post(t, submitPath, models.SubmitChoiceRequest{FunctionCallID: callID, Selection: "AF123"}); status != http.StatusConflict {
+		t.Errorf("second submit: status = %d, want %d", status, http.StatusConflict)
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"google.golang.org/adk/artifact"
+	"google.golang.org/adk/server/adkrest/internal/models"
+	"google.golang.org/adk/session"
+)
+
+// DeleteUserDataHandler deletes all the sessions, events, user-scoped state
+// and artifacts of a user, see [session.UserDataDeleter] and
+// [artifact.UserDataDeleter], and responds with the counts of the deleted
+// objects. Deleting the data of a user who has none succeeds. It fails with
+// 501 status code, before deleting anything, if the session service or the
+// configured artifact service can't delete the data of a user.
+func (c *SessionsAPIController) DeleteUserDataHandler(rw http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	userID, err := models.SessionIDFromHTTPParameters(params)
+	if err != nil {
+		httpError(rw, req, err.Error(), http.StatusBadRequest)
+		return
+	}
+	sessionDeleter, ok := c.service.(session.UserDataDeleter)
+	if !ok {
+		httpError(rw, req, "the session service doesn't support deleting user data", http.StatusNotImplemented)
+		return
+	}
+	var artifactDeleter artifact.UserDataDeleter
+	if c.opts.ArtifactService != nil {
+		if artifactDeleter, ok = c.opts.ArtifactService.(artifact.UserDataDeleter); !ok {
+			httpError(rw, req, "the artifact service doesn't support deleting user data", http.StatusNotImplemented)
+			return
+		}
+	}
+
+	ctx := req.Context()
+	resp, err := sessionDeleter.DeleteUserData(ctx, &session.DeleteUserDataRequest{AppName: userID.AppName, UserID: userID.UserID})
+	if err != nil {
+		httpError(rw, req, fmt.Sprintf("failed to delete sessions: %v", err), http.StatusInternalServerError)
+		return
+	}
+	deletion := models.UserDataDeletion{
+		DeletedSessions:  resp.DeletedSessions,
+		DeletedEvents:    resp.DeletedEvents,
+		DeletedUserState: resp.DeletedUserState,
+	}
+	if artifactDeleter != nil {
+		resp, err := artifactDeleter.DeleteUserData(ctx, &artifact.DeleteUserDataRequest{AppName: userID.AppName, UserID: userID.UserID})
+		if err != nil {
+			httpError(rw, req, fmt.Sprintf("failed to delete artifacts: %v", err), http.StatusInternalServerError)
+			return
+		}
+		deletion.DeletedArtifacts = resp.DeletedVersions
+	}
+	EncodeJSONResponse(deletion, http.StatusOK, rw)
+}
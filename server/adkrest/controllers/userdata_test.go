@@ -0,0 +1,142 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/artifact"
+	"google.golang.org/adk/server/adkrest/controllers"
+	"google.golang.org/adk/server/adkrest/internal/models"
+	"google.golang.org/adk/session"
+)
+
+// plainArtifactService hides the optional interfaces of the service.
+type plainArtifactService struct {
+	artifact.Service
+}
+
+func TestDeleteUserDataHandler(t *testing.T) {
+	tests := []struct {
+		name                 string
+		plainSessionService  bool
+		plainArtifactService bool
+		noArtifactService    bool
+		userID               string
+		wantStatus           int
+		want                 models.UserDataDeletion
+	}{
+		{
+			name:       "deleted",
+			userID:     "user",
+			wantStatus: http.StatusOK,
+			want:       models.UserDataDeletion{DeletedSessions: 2, DeletedEvents: 1, DeletedUserState: true, DeletedArtifacts: 2},
+		},
+		{
+			name:              "without artifact service",
+			noArtifactService: true,
+			userID:            "user",
+			wantStatus:        http.StatusOK,
+			want:              models.UserDataDeletion{DeletedSessions: 2, DeletedEvents: 1, DeletedUserState: true},
+		},
+		{
+			name:       "user without data",
+			userID:     "nobody",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:                "session service without deletion",
+			plainSessionService: true,
+			userID:              "user",
+			wantStatus:          http.StatusNotImplemented,
+		},
+		{
+			name:                 "artifact service without deletion",
+			plainArtifactService: true,
+			userID:               "user",
+			wantStatus:           http.StatusNotImplemented,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			sessionService := session.InMemoryService()
+			var artifactService artifact.Service = artifact.InMemoryService()
+			for _, sessionID := range []string{"s1", "s2"} {
+				if _, err := sessionService.Create(ctx, &session.CreateRequest{AppName: "app", UserID: "user", SessionID: sessionID}); err != nil {
+					t.Fatalf("Create() failed: %v", err)
+				}
+			}
+			got, err := sessionService.Get(ctx, &session.GetRequest{AppName: "app", UserID: "user", SessionID: "s1"})
+			if err != nil {
+				t.Fatalf("Get() failed: %v", err)
+			}
+			event := session.NewEvent("inv-1")
+			event.Author = "user"
+			event.Actions.StateDelta = map[string]any{"user:name": "Ada"}
+			if err := sessionService.AppendEvent(ctx, got.Session, event); err != nil {
+				t.Fatalf("AppendEvent() failed: %v", err)
+			}
+			for _, fileName := range []string{"notes", "user:profile"} {
+				if _, err := artifactService.Save(ctx, &artifact.SaveRequest{AppName: "app", UserID: "user", SessionID: "s1", FileName: fileName, Part: genai.NewPartFromText("content")}); err != nil {
+					t.Fatalf("Save() failed: %v", err)
+				}
+			}
+			if tt.plainSessionService {
+				sessionService = plainSessionService{sessionService}
+			}
+			if tt.plainArtifactService {
+				artifactService = plainArtifactService{artifactService}
+			}
+			if tt.noArtifactService {
+				artifactService = nil
+			}
+
+			srv := newSessionTransferServer(t, sessionService, controllers.SessionsAPIOptions{ArtifactService: artifactService})
+			httpReq, err := http.NewRequest(http.MethodDelete, srv.URL+"/apps/app/users/"+tt.userID, nil)
+			if err != nil {
+				t.Fatalf("NewRequest() failed: %v", err)
+			}
+			resp, err := http.DefaultClient.Do(httpReq)
+			if err != nil {
+				t.Fatalf("Do() failed: %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				// Nothing was deleted.
+				if _, err := sessionService.Get(ctx, &session.GetRequest{AppName: "app", UserID: "user", SessionID: "s1"}); err != nil {
+					t.Errorf("Get() after the rejected deletion failed: %v", err)
+				}
+				return
+			}
+			var deletion models.UserDataDeletion
+			if err := json.NewDecoder(resp.Body).Decode(&deletion); err != nil {
+				t.Fatalf("Decode() failed: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, deletion); diff != "" {
+				t.Errorf("deletion mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
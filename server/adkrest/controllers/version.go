@@ -0,0 +1,51 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"net/http"
+
+	"google.golang.org/adk/internal/version"
+	"google.golang.org/adk/server/adkrest/internal/models"
+)
+
+// userAgentProduct is added to the user agent of the model calls made by
+// the runs, so that they can be told apart in the provider logs.
+const userAgentProduct = "adk-rest-server/" + version.Version
+
+// VersionAPIController is the controller for the version endpoint.
+type VersionAPIController struct {
+	features map[string]any
+}
+
+// NewVersionAPIController creates the controller for the version endpoint.
+// features describes the features enabled in the server configuration.
+func NewVersionAPIController(features map[string]any) *VersionAPIController {
+	return &VersionAPIController{features: features}
+}
+
+// VersionHandler returns the version and build information of the server.
+func (c *VersionAPIController) VersionHandler(rw http.ResponseWriter, req *http.Request) {
+	build := version.Build()
+	EncodeJSONResponse(models.VersionInfo{
+		Version:       version.Version,
+		ModuleVersion: build.ModuleVersion,
+		Revision:      build.Revision,
+		RevisionTime:  build.RevisionTime,
+		Modified:      build.Modified,
+		GoVersion:     build.GoVersion,
+		Features:      c.features,
+	}, http.StatusOK, rw)
+}
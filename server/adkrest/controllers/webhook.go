@@ -0,0 +1,368 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+
+	"google.golang.org/adk/internal/netinternal"
+	"google.golang.org/adk/server/adkrest/internal/models"
+)
+
+const (
+	// WebhookSignatureHeader carries the signature of a callback, see
+	// [WebhookSignature].
+	WebhookSignatureHeader = "X-ADK-Signature"
+	// WebhookTimestampHeader carries the Unix time at which a callback was
+	// signed, in seconds.
+	WebhookTimestampHeader = "X-ADK-Timestamp"
+	// WebhookDeliveryHeader carries the ID of a callback, which is the same
+	// for all its attempts.
+	WebhookDeliveryHeader = "X-ADK-Delivery-ID"
+)
+
+// WebhookOptions configures the callbacks of the async runs.
+type WebhookOptions struct {
+	// Secret is the key of the signature of the callbacks.
+	// Optional: if empty, the runs can't request callbacks.
+	Secret []byte
+	// HTTPClient posts the callbacks. A custom client is responsible for
+	// restricting the addresses it connects to.
+	// Optional: if nil, a client with a 10 seconds timeout is used. It doesn't
+	// follow redirects nor use proxies and, unless AllowPrivateAddresses is set, refuses to
+	// connect to loopback, private, link-local and unspecified addresses.
+	HTTPClient *http.Client
+	// AllowedHosts lists the hosts to which callbacks can be posted, matched
+	// case-insensitively against the host of the callback URL, without port.
+	// Runs with a callback URL on another host are rejected with 400 status
+	// code.
+	// Optional: if empty, all hosts are allowed.
+	AllowedHosts []string
+	// CheckURL is called with the callback URL of each async run, after
+	// AllowedHosts. Runs for which it returns an error are rejected with 400
+	// status code.
+	// Optional: if nil, no additional check is made.
+	CheckURL func(*url.URL) error
+	// AllowPrivateAddresses allows callbacks to loopback, private, link-local
+	// and unspecified addresses, which are refused by default so that callers
+	// can't reach the internal services of the server's network.
+	AllowPrivateAddresses bool
+	// MaxAttempts is the maximum number of attempts to deliver a callback.
+	// Optional: if zero, 5 is used.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry. It doubles after
+	// each attempt, up to MaxBackoff.
+	// Optional: if zero, 1 second is used.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between retries.
+	// Optional: if zero, 1 minute is used.
+	MaxBackoff time.Duration
+}
+
+func (o *WebhookOptions) setDefaults() {
+	if o.HTTPClient == nil {
+		o.HTTPClient = newWebhookClient(o.AllowPrivateAddresses)
+	}
+	if o.MaxAttempts == 0 {
+		o.MaxAttempts = 5
+	}
+	if o.InitialBackoff == 0 {
+		o.InitialBackoff = time.Second
+	}
+	if o.MaxBackoff == 0 {
+		o.MaxBackoff = time.Minute
+	}
+}
+
+// errBlockedAddress is returned when dialing a callback receiver whose
+// address is not allowed.
+var errBlockedAddress = errors.New("callback address is not allowed")
+
+// newWebhookClient returns the default client of the callbacks.
+func newWebhookClient(allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: 10 * time.Second, KeepAlive: 30 * time.Second}
+	if !allowPrivate {
+		// The address is checked once resolved, so that host names which
+		// resolve to blocked addresses are refused too.
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if addr, err := netip.ParseAddr(host); err != nil || netinternal.IsPrivate(addr) {
+				return fmt.Errorf("%w: %s", errBlockedAddress, host)
+			}
+			return nil
+		}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// checkURL checks that callbacks can be posted to u.
+func (o *WebhookOptions) checkURL(u *url.URL) error {
+	host := u.Hostname()
+	if len(o.AllowedHosts) > 0 && !slices.ContainsFunc(o.AllowedHosts, func(h string) bool { return strings.EqualFold(h, host) }) {
+		return fmt.Errorf("host %q is not allowed", host)
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !o.AllowPrivateAddresses && netinternal.IsPrivate(addr) {
+		return fmt.Errorf("address %s is not allowed", addr)
+	}
+	if o.CheckURL != nil {
+		return o.CheckURL(u)
+	}
+	return nil
+}
+
+// WebhookSignature returns the signature of a callback body, as sent in
+// the [WebhookSignatureHeader] header: "sha256=" followed by the hex-encoded
+// HMAC-SHA256 of the [WebhookTimestampHeader] header, a dot and the body.
+// Receivers should compare it to the header with [hmac.Equal] and reject
+// old timestamps.
+func WebhookSignature(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhook posts the callbacks of an async run to its callback URL, one at a
+// time and in order.
+type webhook struct {
+	url    string
+	opts   WebhookOptions
+	logger *slog.Logger
+
+	mu         sync.Mutex
+	deliveries []*delivery
+	next       int
+	closed     bool
+	// stopped is set once the deliveries have been given up on.
+	stopped error
+	wake    chan struct{}
+}
+
+type delivery struct {
+	id      string
+	eventID string
+	body    []byte
+
+	// Guarded by webhook.mu.
+	state     string
+	attempts  int
+	lastError string
+}
+
+func newWebhook(url string, opts WebhookOptions, logger *slog.Logger) *webhook {
+	return &webhook{url: url, opts: opts, logger: logger, wake: make(chan struct{}, 1)}
+}
+
+// enqueue schedules the delivery of a callback.
+func (w *webhook) enqueue(callback models.InvocationCallback) {
+	body, err := json.Marshal(callback)
+	if err != nil {
+		w.logger.Error("ADK: failed to encode callback", "error", err)
+		return
+	}
+	d := &delivery{id: uuid.NewString(), body: body, state: models.DeliveryStatePending}
+	if callback.Event != nil {
+		d.eventID = callback.Event.ID
+	}
+	w.mu.Lock()
+	if w.stopped != nil {
+		d.state, d.lastError = models.DeliveryStateFailed, w.stopped.Error()
+	}
+	w.deliveries = append(w.deliveries, d)
+	w.mu.Unlock()
+	w.signal()
+}
+
+// close tells that no more callbacks will be enqueued. The pending ones are
+// still delivered.
+func (w *webhook) close() {
+	w.mu.Lock()
+	w.closed = true
+	w.mu.Unlock()
+	w.signal()
+}
+
+func (w *webhook) signal() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run delivers the callbacks until the webhook is closed and all of them
+// have been attempted, or ctx is done.
+func (w *webhook) run(ctx context.Context) {
+	for {
+		w.mu.Lock()
+		var d *delivery
+		if w.next < len(w.deliveries) {
+			d = w.deliveries[w.next]
+			w.next++
+		}
+		closed := w.closed
+		w.mu.Unlock()
+
+		if d == nil {
+			if closed {
+				return
+			}
+			select {
+			case <-w.wake:
+				continue
+			case <-ctx.Done():
+				w.stop(context.Cause(ctx))
+				return
+			}
+		}
+		if !w.deliver(ctx, d) {
+			w.stop(context.Cause(ctx))
+			return
+		}
+	}
+}
+
+// stop gives up, because of cause, on the pending callbacks and on the ones
+// enqueued later.
+func (w *webhook) stop(cause error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.stopped = fmt.Errorf("gave up on delivery: %w", cause)
+	for _, d := range w.deliveries {
+		if d.state == models.DeliveryStatePending {
+			d.state, d.lastError = models.DeliveryStateFailed, w.stopped.Error()
+		}
+	}
+	w.next = len(w.deliveries)
+}
+
+// deliver attempts to post the callback until it is accepted, the attempts
+// are exhausted or ctx is done. Failed callbacks are given up on, so that
+// the following ones are still delivered. deliver returns false if ctx is done.
+func (w *webhook) deliver(ctx context.Context, d *delivery) bool {
+	backoff := w.opts.InitialBackoff
+	for {
+		retry, err := w.post(ctx, d)
+		w.mu.Lock()
+		d.attempts++
+		attempts := d.attempts
+		switch {
+		case err == nil:
+			d.state, d.lastError = models.DeliveryStateDelivered, ""
+		case !retry || attempts >= w.opts.MaxAttempts:
+			d.state, d.lastError = models.DeliveryStateFailed, err.Error()
+		default:
+			d.lastError = err.Error()
+		}
+		state := d.state
+		w.mu.Unlock()
+		if state != models.DeliveryStatePending {
+			if state == models.DeliveryStateFailed {
+				w.logger.Warn("ADK: failed to deliver callback", "delivery_id", d.id, "attempts", attempts, "error", err)
+			}
+			return true
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			w.mu.Lock()
+			d.state = models.DeliveryStateFailed
+			w.mu.Unlock()
+			return false
+		}
+		backoff = min(2*backoff, w.opts.MaxBackoff)
+	}
+}
+
+// post makes one attempt to deliver the callback. It reports whether a
+// failed attempt is worth retrying.
+func (w *webhook) post(ctx context.Context, d *delivery) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(d.body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookDeliveryHeader, d.id)
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, WebhookSignature(w.opts.Secret, timestamp, d.body))
+	resp, err := w.opts.HTTPClient.Do(req)
+	if err != nil {
+		if errors.Is(err, errBlockedAddress) {
+			return false, fmt.Errorf("failed to post callback: %w", err)
+		}
+		return true, fmt.Errorf("failed to post callback: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry = resp.StatusCode >= 500 || resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("callback receiver replied with status %d", resp.StatusCode)
+}
+
+// status returns the status of the callbacks enqueued so far.
+func (w *webhook) status() []models.CallbackDelivery {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	deliveries := make([]models.CallbackDelivery, 0, len(w.deliveries))
+	for _, d := range w.deliveries {
+		deliveries = append(deliveries, models.CallbackDelivery{
+			ID:        d.id,
+			EventID:   d.eventID,
+			State:     d.state,
+			Attempts:  d.attempts,
+			LastError: d.lastError,
+		})
+	}
+	return deliveries
+}
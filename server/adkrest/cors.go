@@ -0,0 +1,121 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adkrest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSOptions configures Cross-Origin Resource Sharing for the REST API,
+// e.g. to allow a browser dev UI served from another origin to call it.
+type CORSOptions struct {
+	// AllowedOrigins lists the origins (e.g. "http://localhost:4200") which
+	// may call the API. "*" allows any origin.
+	AllowedOrigins []string
+	// AllowedMethods lists the methods allowed in cross-origin requests.
+	// Optional: if empty, GET, POST, PUT, PATCH, DELETE and OPTIONS are allowed.
+	AllowedMethods []string
+	// AllowedHeaders lists the request headers allowed in cross-origin requests.
+	// Optional: if empty, Content-Type, Authorization and X-Request-Id are allowed.
+	AllowedHeaders []string
+	// AllowCredentials tells browsers to expose responses to requests made
+	// with credentials (cookies, authorization headers). It requires explicit
+	// origins: "*" would let any website make credentialed calls, so
+	// [NewHandlerWithOptions] panics if AllowedOrigins contains it.
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache the result of a preflight request.
+	// Optional: if zero, the header is not sent.
+	MaxAge time.Duration
+}
+
+var (
+	defaultCORSMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions}
+	defaultCORSHeaders = []string{"Content-Type", "Authorization", RequestIDHeader}
+)
+
+// validate rejects the options which would expose the API to any website.
+func (opts *CORSOptions) validate() error {
+	if opts.AllowCredentials && slices.Contains(opts.AllowedOrigins, "*") {
+		return errors.New(`AllowCredentials requires explicit AllowedOrigins, got "*"`)
+	}
+	return nil
+}
+
+// withCORS adds CORS headers to all the responses, including streaming ones,
+// and answers preflight requests without calling next.
+// If opts is nil, next is returned unchanged. It panics if the options are
+// invalid, as they are fixed by the program.
+func withCORS(opts *CORSOptions, next http.Handler) http.Handler {
+	if opts == nil {
+		return next
+	}
+	if err := opts.validate(); err != nil {
+		panic(fmt.Sprintf("adkrest: invalid CORS options: %v", err))
+	}
+	methods := opts.AllowedMethods
+	if len(methods) == 0 {
+		methods = defaultCORSMethods
+	}
+	headers := opts.AllowedHeaders
+	if len(headers) == 0 {
+		headers = defaultCORSHeaders
+	}
+	allowMethods := strings.Join(methods, ", ")
+	allowHeaders := strings.Join(headers, ", ")
+	anyOrigin := slices.Contains(opts.AllowedOrigins, "*")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		isPreflight := r.Method == http.MethodOptions && origin != "" && r.Header.Get("Access-Control-Request-Method") != ""
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		h := w.Header()
+		h.Add("Vary", "Origin")
+		if anyOrigin || slices.ContainsFunc(opts.AllowedOrigins, func(o string) bool { return strings.EqualFold(o, origin) }) {
+			if anyOrigin {
+				h.Set("Access-Control-Allow-Origin", "*")
+			} else {
+				h.Set("Access-Control-Allow-Origin", origin)
+			}
+			if opts.AllowCredentials {
+				h.Set("Access-Control-Allow-Credentials", "true")
+			}
+			if isPreflight {
+				h.Set("Access-Control-Allow-Methods", allowMethods)
+				h.Set("Access-Control-Allow-Headers", allowHeaders)
+				if opts.MaxAge > 0 {
+					h.Set("Access-Control-Max-Age", strconv.Itoa(int(opts.MaxAge.Seconds())))
+				}
+			} else {
+				h.Set("Access-Control-Expose-Headers", RequestIDHeader)
+			}
+		}
+
+		if isPreflight {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
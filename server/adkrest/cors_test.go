@@ -0,0 +1,133 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adkrest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWithCORS(t *testing.T) {
+	corsHeaders := []string{
+		"Access-Control-Allow-Origin",
+		"Access-Control-Allow-Credentials",
+		"Access-Control-Allow-Methods",
+		"Access-Control-Allow-Headers",
+		"Access-Control-Max-Age",
+	}
+	tc := []struct {
+		name        string
+		opts        *CORSOptions
+		method      string
+		headers     map[string]string
+		wantStatus  int
+		wantCalled  bool
+		wantHeaders map[string]string
+	}{
+		{
+			name:        "disabled",
+			method:      http.MethodOptions,
+			headers:     map[string]string{"Origin": "http://ui.test", "Access-Control-Request-Method": "POST"},
+			wantStatus:  http.StatusOK,
+			wantCalled:  true,
+			wantHeaders: map[string]string{},
+		},
+		{
+			name:       "preflight from allowed origin",
+			opts:       &CORSOptions{AllowedOrigins: []string{"http://ui.test"}, AllowCredentials: true, MaxAge: 10 * time.Minute},
+			method:     http.MethodOptions,
+			headers:    map[string]string{"Origin": "http://ui.test", "Access-Control-Request-Method": "POST"},
+			wantStatus: http.StatusNoContent,
+			wantHeaders: map[string]string{
+				"Access-Control-Allow-Origin":      "http://ui.test",
+				"Access-Control-Allow-Credentials": "true",
+				"Access-Control-Allow-Methods":     "GET, POST, PUT, PATCH, DELETE, OPTIONS",
+				"Access-Control-Allow-Headers":     "Content-Type, Authorization, X-Request-Id",
+				"Access-Control-Max-Age":           "600",
+			},
+		},
+		{
+			name:        "preflight from other origin",
+			opts:        &CORSOptions{AllowedOrigins: []string{"http://ui.test"}},
+			method:      http.MethodOptions,
+			headers:     map[string]string{"Origin": "http://evil.test", "Access-Control-Request-Method": "POST"},
+			wantStatus:  http.StatusNoContent,
+			wantHeaders: map[string]string{},
+		},
+		{
+			name:       "simple request from any origin",
+			opts:       &CORSOptions{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET"}},
+			method:     http.MethodGet,
+			headers:    map[string]string{"Origin": "http://ui.test"},
+			wantStatus: http.StatusOK,
+			wantCalled: true,
+			wantHeaders: map[string]string{
+				"Access-Control-Allow-Origin": "*",
+			},
+		},
+		{
+			name:        "same origin request",
+			opts:        &CORSOptions{AllowedOrigins: []string{"*"}},
+			method:      http.MethodGet,
+			wantStatus:  http.StatusOK,
+			wantCalled:  true,
+			wantHeaders: map[string]string{},
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			handler := withCORS(tt.opts, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+			}))
+			req := httptest.NewRequest(tt.method, "/run_sse", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rr.Code, tt.wantStatus)
+			}
+			if called != tt.wantCalled {
+				t.Errorf("next handler called = %v, want %v", called, tt.wantCalled)
+			}
+			got := map[string]string{}
+			for _, h := range corsHeaders {
+				if v := rr.Header().Get(h); v != "" {
+					got[h] = v
+				}
+			}
+			if diff := cmp.Diff(tt.wantHeaders, got); diff != "" {
+				t.Errorf("CORS headers mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWithCORS_AnyOriginWithCredentials(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("withCORS() with any origin and credentials didn't panic")
+		}
+	}()
+	withCORS(&CORSOptions{AllowedOrigins: []string{"*"}, AllowCredentials: true}, http.NotFoundHandler())
+}
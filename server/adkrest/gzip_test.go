@@ -0,0 +1,124 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adkrest
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/adk/server/adkrest/controllers"
+)
+
+func TestWithGzip(t *testing.T) {
+	jsonHandler := controllers.NewErrorHandler(func(rw http.ResponseWriter, req *http.Request) error {
+		controllers.EncodeJSONResponse(map[string]string{"hello": "world"}, http.StatusOK, rw)
+		return nil
+	})
+	// streamHandler writes two events, flushing each of them through the
+	// writers wrapping the response.
+	streamHandler := controllers.NewErrorHandler(func(rw http.ResponseWriter, req *http.Request) error {
+		rw.Header().Set("Content-Type", "text/event-stream")
+		rw.WriteHeader(http.StatusOK)
+		rc := http.NewResponseController(rw)
+		for i := range 2 {
+			if _, err := fmt.Fprintf(rw, "data: %d\n\n", i); err != nil {
+				return err
+			}
+			if err := rc.Flush(); err != nil {
+				return fmt.Errorf("failed to flush: %w", err)
+			}
+		}
+		return nil
+	})
+
+	tc := []struct {
+		name           string
+		enabled        bool
+		handler        http.Handler
+		acceptEncoding string
+		wantGzip       bool
+		wantFlushed    bool
+		wantBody       string
+	}{
+		{
+			name:           "json response",
+			enabled:        true,
+			handler:        jsonHandler,
+			acceptEncoding: "gzip, deflate",
+			wantGzip:       true,
+			wantBody:       "{\"hello\":\"world\"}\n",
+		},
+		{
+			name:           "gzip not accepted",
+			enabled:        true,
+			handler:        jsonHandler,
+			acceptEncoding: "gzip;q=0, deflate",
+			wantBody:       "{\"hello\":\"world\"}\n",
+		},
+		{
+			name:           "disabled",
+			handler:        jsonHandler,
+			acceptEncoding: "gzip",
+			wantBody:       "{\"hello\":\"world\"}\n",
+		},
+		{
+			name:           "streaming response is skipped",
+			enabled:        true,
+			handler:        streamHandler,
+			acceptEncoding: "gzip",
+			wantFlushed:    true,
+			wantBody:       "data: 0\n\ndata: 1\n\n",
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			rr := httptest.NewRecorder()
+
+			withGzip(tt.enabled, tt.handler).ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d, body %q", rr.Code, http.StatusOK, rr.Body)
+			}
+			gotGzip := rr.Header().Get("Content-Encoding") == "gzip"
+			if gotGzip != tt.wantGzip {
+				t.Errorf("compressed = %v, want %v", gotGzip, tt.wantGzip)
+			}
+			var body io.Reader = rr.Body
+			if gotGzip {
+				zr, err := gzip.NewReader(rr.Body)
+				if err != nil {
+					t.Fatalf("gzip.NewReader() failed: %v", err)
+				}
+				body = zr
+			}
+			got, err := io.ReadAll(body)
+			if err != nil {
+				t.Fatalf("ReadAll() failed: %v", err)
+			}
+			if string(got) != tt.wantBody {
+				t.Errorf("body = %q, want %q", got, tt.wantBody)
+			}
+			if tt.wantFlushed && !rr.Flushed {
+				t.Errorf("streaming response was not flushed to the underlying writer")
+			}
+		})
+	}
+}
@@ -15,7 +15,12 @@
 package adkrest
 
 import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -23,28 +28,262 @@ import (
 
 	"google.golang.org/adk/cmd/launcher"
 	"google.golang.org/adk/internal/telemetry"
+	"google.golang.org/adk/memory/ingest"
 	"google.golang.org/adk/server/adkrest/controllers"
+	"google.golang.org/adk/server/adkrest/internal/logging"
 	"google.golang.org/adk/server/adkrest/internal/routers"
 	"google.golang.org/adk/server/adkrest/internal/services"
+	"google.golang.org/adk/server/adkrest/metrics"
+	"google.golang.org/adk/server/adkrest/ratelimit"
+	"google.golang.org/adk/session"
 )
 
+// Options configures optional behavior of the ADK REST API handler.
+// The zero value is valid and uses defaults.
+type Options struct {
+	// SSEWriteTimeout is the write deadline of the streaming (SSE) responses.
+	// It overrides the server-wide write timeout for these responses.
+	SSEWriteTimeout time.Duration
+	// SSEKeepAliveInterval is how often a ": keep-alive" comment is written to
+	// an SSE stream on which no event has been flushed, so that proxies don't
+	// close idle streams.
+	// Optional: if zero, 15 seconds is used. A negative value disables
+	// keep-alives.
+	SSEKeepAliveInterval time.Duration
+	// MaxRunDuration is the maximum duration of an agent run. Clients can ask
+	// for a shorter one with the X-ADK-Timeout-Seconds header. Streaming runs
+	// which exceed it end with an error event, the other ones fail with 504
+	// status code.
+	// Optional: if zero, runs are limited only by the header.
+	MaxRunDuration time.Duration
+	// Logger is used for all the log lines emitted while serving requests.
+	// Optional: if nil, [slog.Default] is used.
+	Logger *slog.Logger
+	// CORS enables Cross-Origin Resource Sharing on all the routes.
+	// Optional: if nil, no CORS headers are sent.
+	CORS *CORSOptions
+	// Gzip enables gzip compression of the JSON responses for the clients
+	// which send "Accept-Encoding: gzip". Streaming responses are never
+	// compressed.
+	Gzip bool
+	// MaxArtifactUploadSize is the maximum size in bytes of an artifact
+	// upload request.
+	// Optional: if zero, 100 MiB is used.
+	MaxArtifactUploadSize int64
+	// AllowedArtifactContentTypes restricts the media types of the uploaded
+	// artifacts, e.g. "application/pdf" or "image/*".
+	// Optional: if empty, all content types are accepted.
+	AllowedArtifactContentTypes []string
+	// ReadinessChecks are run by the /readyz endpoint, in addition to the
+	// session, artifact and memory services which implement
+	// [controllers.HealthChecker], e.g. to check that the model backend is
+	// reachable.
+	ReadinessChecks map[string]controllers.HealthChecker
+	// Auth enables the authentication of the requests.
+	// Optional: if nil, the API is open to everyone who can reach it.
+	Auth *AuthOptions
+	// Metrics records the request, streaming and invocation metrics, which
+	// are exposed on /metrics if the recorder has a handler, see
+	// [google.golang.org/adk/server/adkrest/metrics/prometheus].
+	// Optional: if nil, no metrics are recorded.
+	Metrics metrics.Recorder
+	// RateLimiter limits the rate at which each user of an app can start runs
+	// with the run and run_sse endpoints, see
+	// [google.golang.org/adk/server/adkrest/ratelimit].
+	// Optional: if nil, runs are not limited.
+	RateLimiter ratelimit.Limiter
+	// Webhooks configures the callbacks of the async runs, which are
+	// started with the mode=async query parameter of the run endpoint.
+	// Optional: without a secret, async runs can't request callbacks.
+	Webhooks controllers.WebhookOptions
+	// SessionLocker serializes the runs of each session, so that concurrent
+	// runs don't interleave their events. Runs of a busy session are rejected
+	// with 409 status code once SessionLockWait elapses.
+	// Optional: if nil, the session service is used if it implements
+	// [session.Locker], as the PostgreSQL one does, otherwise the runs are
+	// serialized in memory, which is only correct with a single replica.
+	SessionLocker session.Locker
+	// SessionLockWait is how long a run waits for another run of its session
+	// to finish.
+	// Optional: if zero, such runs are rejected right away.
+	SessionLockWait time.Duration
+	// CompactionSummarizer writes the summaries of the events compacted by
+	// the sessions/{session_id}:compact endpoint, typically with a model.
+	// Optional: if nil, or if the session service doesn't implement
+	// [session.Compactor], the endpoint fails with 501 status code.
+	CompactionSummarizer session.Summarizer
+	// MemoryIngester adds the sessions to the memory service in the
+	// background at the end of their runs, see
+	// [google.golang.org/adk/memory/ingest]. Its counters are exposed on
+	// /metrics if it is configured with the Metrics recorder.
+	// Optional: if nil, the sessions are only added to memory by the agents.
+	MemoryIngester *ingest.Ingester
+	// ShutdownDrainTimeout bounds how long [Handler.Shutdown] waits for the
+	// in-flight runs to finish their responses.
+	// Optional: if zero, 30 seconds is used.
+	ShutdownDrainTimeout time.Duration
+}
+
+const defaultShutdownDrainTimeout = 30 * time.Second
+
+// The metrics recorders can record the ingestions into memory.
+var _ ingest.Recorder = metrics.Recorder(nil)
+
+// Handler serves the ADK REST API.
+type Handler struct {
+	http.Handler
+	runtime      *controllers.RuntimeAPIController
+	drainTimeout time.Duration
+}
+
+// Shutdown gracefully stops the in-flight runs: new runs are rejected with
+// 503 status code, the running agents are cancelled and streaming responses
+// end with a final error event instead of being cut mid-event.
+// Shutdown returns once all the run handlers have returned, ctx is done or
+// the drain timeout elapses, whichever happens first.
+//
+// Call it before (or concurrently with) [http.Server.Shutdown], e.g.:
+//
+//	srv.RegisterOnShutdown(func() { _ = h.Shutdown(context.Background()) })
+func (h *Handler) Shutdown(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, h.drainTimeout)
+	defer cancel()
+	return h.runtime.Shutdown(ctx)
+}
+
 // NewHandler creates and returns an http.Handler for the ADK REST API.
 func NewHandler(config *launcher.Config, sseWriteTimeout time.Duration) http.Handler {
+	return NewHandlerWithOptions(config, Options{SSEWriteTimeout: sseWriteTimeout})
+}
+
+// NewHandlerWithOptions creates and returns an http.Handler for the ADK REST API
+// configured with the given options.
+func NewHandlerWithOptions(config *launcher.Config, opts Options) *Handler {
 	adkExporter := services.NewAPIServerSpanExporter()
 	telemetry.AddSpanProcessor(sdktrace.NewSimpleSpanProcessor(adkExporter))
 
+	if opts.ShutdownDrainTimeout == 0 {
+		opts.ShutdownDrainTimeout = defaultShutdownDrainTimeout
+	}
+	if opts.SessionLocker == nil {
+		if locker, ok := config.SessionService.(session.Locker); ok {
+			opts.SessionLocker = locker
+		} else {
+			opts.SessionLocker = session.NewInMemoryLocker()
+		}
+	}
+	runtime := controllers.NewRuntimeAPIControllerWithOptions(config.SessionService, config.MemoryService, config.AgentLoader, config.ArtifactService, opts.SSEWriteTimeout, config.PluginConfig, controllers.RuntimeAPIOptions{
+		SSEKeepAliveInterval: opts.SSEKeepAliveInterval,
+		MaxRunDuration:       opts.MaxRunDuration,
+		Metrics:              opts.Metrics,
+		MemoryIngester:       opts.MemoryIngester,
+		RateLimiter:          opts.RateLimiter,
+		Webhooks:             opts.Webhooks,
+		SessionLocker:        opts.SessionLocker,
+		SessionLockWait:      opts.SessionLockWait,
+	})
+
+	checks := map[string]controllers.HealthChecker{
+		"runtime":          runtime,
+		"session_service":  healthChecker(config.SessionService),
+		"artifact_service": healthChecker(config.ArtifactService),
+		"memory_service":   healthChecker(config.MemoryService),
+	}
+	maps.Copy(checks, opts.ReadinessChecks)
+
 	router := mux.NewRouter().StrictSlash(true)
 	// TODO: Allow taking a prefix to allow customizing the path
 	// where the ADK REST API will be served.
-	setupRouter(router,
-		routers.NewSessionsAPIRouter(controllers.NewSessionsAPIController(config.SessionService)),
-		routers.NewRuntimeAPIRouter(controllers.NewRuntimeAPIController(config.SessionService, config.MemoryService, config.AgentLoader, config.ArtifactService, sseWriteTimeout, config.PluginConfig)),
+	subrouters := []routers.Router{
+		routers.NewSessionsAPIRouter(controllers.NewSessionsAPIController(config.SessionService, controllers.SessionsAPIOptions{
+			AgentLoader:     config.AgentLoader,
+			ArtifactService: config.ArtifactService,
+			Summarizer:      opts.CompactionSummarizer,
+		})),
+		routers.NewRuntimeAPIRouter(runtime),
 		routers.NewAppsAPIRouter(controllers.NewAppsAPIController(config.AgentLoader)),
 		routers.NewDebugAPIRouter(controllers.NewDebugAPIController(config.SessionService, config.AgentLoader, adkExporter)),
-		routers.NewArtifactsAPIRouter(controllers.NewArtifactsAPIController(config.ArtifactService)),
+		routers.NewArtifactsAPIRouter(controllers.NewArtifactsAPIController(config.ArtifactService, controllers.ArtifactsAPIOptions{
+			MaxUploadSize:       opts.MaxArtifactUploadSize,
+			AllowedContentTypes: opts.AllowedArtifactContentTypes,
+		})),
 		&routers.EvalAPIRouter{},
-	)
-	return router
+		routers.NewHealthAPIRouter(controllers.NewHealthAPIController(checks, controllers.HealthAPIOptions{})),
+	}
+	if opts.Metrics != nil {
+		if h := opts.Metrics.Handler(); h != nil {
+			subrouters = append(subrouters, routers.NewMetricsAPIRouter(h))
+		}
+		router.Use(withMetrics(opts.Metrics))
+	}
+	features := serverFeatures(config, opts, subrouters)
+	subrouters = append(subrouters, routers.NewVersionAPIRouter(controllers.NewVersionAPIController(features)))
+	openAPIRouter, err := routers.NewOpenAPIRouter(subrouters...)
+	if err != nil {
+		// The document only depends on the routes, which are static.
+		panic(err)
+	}
+	setupRouter(router, append(subrouters, openAPIRouter)...)
+	if opts.Auth != nil {
+		router.Use(withAuth(opts.Auth))
+	}
+	return &Handler{
+		Handler:      withCORS(opts.CORS, withGzip(opts.Gzip, withLogger(opts.Logger, withRequestID(router)))),
+		runtime:      runtime,
+		drainTimeout: opts.ShutdownDrainTimeout,
+	}
+}
+
+// serverFeatures describes the features enabled by the configuration of the
+// server, for the version endpoint.
+func serverFeatures(config *launcher.Config, opts Options, subrouters []routers.Router) map[string]any {
+	features := map[string]any{
+		// Streaming responses can't be written without a write deadline.
+		"streaming":      opts.SSEWriteTimeout > 0,
+		"eval":           false,
+		"artifacts":      backendName(config.ArtifactService),
+		"sessions":       backendName(config.SessionService),
+		"memory":         backendName(config.MemoryService),
+		"auth":           opts.Auth != nil,
+		"metrics":        opts.Metrics != nil,
+		"rateLimit":      opts.RateLimiter != nil,
+		"asyncCallbacks": len(opts.Webhooks.Secret) > 0,
+	}
+	// Only the implemented routes are documented.
+	for _, endpoint := range routers.Endpoints(subrouters...) {
+		if endpoint.Name == "ListEvalSets" && endpoint.Operation != nil {
+			features["eval"] = true
+		}
+	}
+	return features
+}
+
+// backendName returns the type of the service, e.g.
+// "gcsartifact.gcsService", or "none" if it is nil.
+func backendName(service any) string {
+	if service == nil {
+		return "none"
+	}
+	return strings.TrimPrefix(fmt.Sprintf("%T", service), "*")
+}
+
+// healthChecker returns the service as a [controllers.HealthChecker], or nil
+// if it doesn't implement it.
+func healthChecker(service any) controllers.HealthChecker {
+	if checker, ok := service.(controllers.HealthChecker); ok {
+		return checker
+	}
+	return nil
+}
+
+// withLogger stores the logger on the request context, so that handlers can use it.
+func withLogger(logger *slog.Logger, next http.Handler) http.Handler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(logging.ToContext(r.Context(), logger)))
+	})
 }
 
 func setupRouter(router *mux.Router, subrouters ...routers.Router) *mux.Router {
@@ -0,0 +1,76 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package auth stores the authenticated principal of a REST API request in
+// its context.
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	// Subject identifies the caller, e.g. the identity of an API key or the
+	// "sub" claim of a bearer token.
+	Subject string
+	// Claims are the claims of the bearer token the caller authenticated
+	// with, if any.
+	Claims map[string]any
+}
+
+// ErrUserMismatch is reported when a request targets another user than the
+// authenticated one.
+var ErrUserMismatch = errors.New("user ID doesn't match the authenticated principal")
+
+type ctxKey int
+
+const principalCtxKey ctxKey = 0
+
+type principalValue struct {
+	principal        *Principal
+	requireUserMatch bool
+}
+
+// ToContext returns a copy of ctx carrying the principal. If
+// requireUserMatch is true, [CheckUser] only accepts the subject of the
+// principal as user ID.
+func ToContext(ctx context.Context, principal *Principal, requireUserMatch bool) context.Context {
+	return context.WithValue(ctx, principalCtxKey, principalValue{principal: principal, requireUserMatch: requireUserMatch})
+}
+
+// FromContext returns the principal stored in ctx, if any.
+func FromContext(ctx context.Context) (*Principal, bool) {
+	v, ok := ctx.Value(principalCtxKey).(principalValue)
+	if !ok || v.principal == nil {
+		return nil, false
+	}
+	return v.principal, true
+}
+
+// CheckUser returns an error wrapping [ErrUserMismatch] if the request is
+// required to act on behalf of the authenticated principal only, and userID is
+// another user.
+func CheckUser(ctx context.Context, userID string) error {
+	v, ok := ctx.Value(principalCtxKey).(principalValue)
+	if !ok || !v.requireUserMatch || v.principal == nil {
+		return nil
+	}
+	if userID != v.principal.Subject {
+		return fmt.Errorf("%w: %q", ErrUserMismatch, userID)
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import "time"
+
+// ArtifactUpload describes an artifact version created by an upload.
+type ArtifactUpload struct {
+	Name     string `json:"name"`
+	Version  int64  `json:"version"`
+	MIMEType string `json:"mimeType"`
+	Size     int64  `json:"size"`
+}
+
+// ArtifactSignedURL is a signed URL downloading an artifact version.
+type ArtifactSignedURL struct {
+	Name    string `json:"name"`
+	Version int64  `json:"version"`
+	URL     string `json:"url"`
+	// ExpiresAt is when the URL stops being valid.
+	ExpiresAt time.Time `json:"expiresAt"`
+}
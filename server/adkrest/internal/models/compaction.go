@@ -0,0 +1,59 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"time"
+
+	"google.golang.org/adk/session"
+)
+
+// CompactSessionRequest is the request of a session compaction.
+type CompactSessionRequest struct {
+	// KeepRecent is the number of most recent events which are never
+	// compacted. If zero, [session.DefaultKeepRecent] is used.
+	KeepRecent int `json:"keepRecent,omitempty"`
+	// Archive keeps the compacted events in the storage instead of deleting
+	// them.
+	Archive bool `json:"archive,omitempty"`
+}
+
+// SessionCompaction is the response of a session compaction.
+type SessionCompaction struct {
+	Session Session `json:"session"`
+	// CompactedEvents is the number of events replaced by summaries.
+	CompactedEvents int `json:"compactedEvents"`
+}
+
+// EventCompaction represents a data model for session.EventCompaction.
+type EventCompaction struct {
+	StartTime       time.Time `json:"startTime"`
+	EndTime         time.Time `json:"endTime"`
+	CompactedEvents int       `json:"compactedEvents"`
+}
+
+func fromEventCompaction(c *session.EventCompaction) *EventCompaction {
+	if c == nil {
+		return nil
+	}
+	return &EventCompaction{StartTime: c.StartTime, EndTime: c.EndTime, CompactedEvents: c.CompactedEvents}
+}
+
+func (c *EventCompaction) toSession() *session.EventCompaction {
+	if c == nil {
+		return nil
+	}
+	return &session.EventCompaction{StartTime: c.StartTime, EndTime: c.EndTime, CompactedEvents: c.CompactedEvents}
+}
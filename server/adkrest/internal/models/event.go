@@ -27,6 +27,33 @@ import (
 type EventActions struct {
 	StateDelta    map[string]any   `json:"stateDelta"`
 	ArtifactDelta map[string]int64 `json:"artifactDelta"`
+	// Compaction is set on the summaries of compacted events.
+	Compaction *EventCompaction `json:"compaction,omitempty"`
+	// TransferToAgent and Transfer are set on the events handing off the
+	// invocation to another agent.
+	TransferToAgent string         `json:"transferToAgent,omitempty"`
+	Transfer        *EventTransfer `json:"transfer,omitempty"`
+}
+
+// EventTransfer is the hand-off of an invocation from an agent to another,
+// see [session.EventTransfer].
+type EventTransfer struct {
+	FromAgent string `json:"fromAgent"`
+	ToAgent   string `json:"toAgent"`
+}
+
+func fromEventTransfer(t *session.EventTransfer) *EventTransfer {
+	if t == nil {
+		return nil
+	}
+	return &EventTransfer{FromAgent: t.FromAgent, ToAgent: t.ToAgent}
+}
+
+func (t *EventTransfer) toSession() *session.EventTransfer {
+	if t == nil {
+		return nil
+	}
+	return &session.EventTransfer{FromAgent: t.FromAgent, ToAgent: t.ToAgent}
 }
 
 // Event represents a single event in a session.
@@ -40,11 +67,43 @@ type Event struct {
 	LongRunningToolIDs []string                 `json:"longRunningToolIds"`
 	Content            *genai.Content           `json:"content"`
 	GroundingMetadata  *genai.GroundingMetadata `json:"groundingMetadata"`
-	TurnComplete       bool                     `json:"turnComplete"`
-	Interrupted        bool                     `json:"interrupted"`
-	ErrorCode          string                   `json:"errorCode"`
-	ErrorMessage       string                   `json:"errorMessage"`
-	Actions            EventActions             `json:"actions"`
+	// Citations are the spans of the text of the content supported by the
+	// sources of the grounding metadata, for clients to render citations.
+	Citations    []Citation   `json:"citations,omitempty"`
+	TurnComplete bool         `json:"turnComplete"`
+	Interrupted  bool         `json:"interrupted"`
+	ErrorCode    string       `json:"errorCode"`
+	ErrorMessage string       `json:"errorMessage"`
+	Actions      EventActions `json:"actions"`
+}
+
+// Citation is a span of the text of an event supported by grounding sources,
+// see [session.Citation].
+type Citation struct {
+	// StartIndex and EndIndex are the byte offsets of the span in the text
+	// parts of the content, except thoughts, concatenated.
+	StartIndex int              `json:"startIndex"`
+	EndIndex   int              `json:"endIndex"`
+	Text       string           `json:"text"`
+	Sources    []CitationSource `json:"sources"`
+}
+
+// CitationSource is a source supporting a citation.
+type CitationSource struct {
+	URI   string `json:"uri"`
+	Title string `json:"title,omitempty"`
+}
+
+func fromCitations(citations []session.Citation) []Citation {
+	var result []Citation
+	for _, c := range citations {
+		citation := Citation{StartIndex: c.StartIndex, EndIndex: c.EndIndex, Text: c.Text, Sources: []CitationSource{}}
+		for _, s := range c.Sources {
+			citation.Sources = append(citation.Sources, CitationSource{URI: s.URI, Title: s.Title})
+		}
+		result = append(result, citation)
+	}
+	return result
 }
 
 // ToSessionEvent maps Event data struct to session.Event
@@ -66,8 +125,11 @@ func ToSessionEvent(event Event) *session.Event {
 			ErrorMessage:      event.ErrorMessage,
 		},
 		Actions: session.EventActions{
-			StateDelta:    event.Actions.StateDelta,
-			ArtifactDelta: event.Actions.ArtifactDelta,
+			StateDelta:      event.Actions.StateDelta,
+			ArtifactDelta:   event.Actions.ArtifactDelta,
+			Compaction:      event.Actions.Compaction.toSession(),
+			TransferToAgent: event.Actions.TransferToAgent,
+			Transfer:        event.Actions.Transfer.toSession(),
 		},
 	}
 }
@@ -84,13 +146,17 @@ func FromSessionEvent(event session.Event) Event {
 		LongRunningToolIDs: event.LongRunningToolIDs,
 		Content:            event.LLMResponse.Content,
 		GroundingMetadata:  event.LLMResponse.GroundingMetadata,
+		Citations:          fromCitations(event.Citations()),
 		TurnComplete:       event.LLMResponse.TurnComplete,
 		Interrupted:        event.LLMResponse.Interrupted,
 		ErrorCode:          event.LLMResponse.ErrorCode,
 		ErrorMessage:       event.LLMResponse.ErrorMessage,
 		Actions: EventActions{
-			StateDelta:    event.Actions.StateDelta,
-			ArtifactDelta: event.Actions.ArtifactDelta,
+			StateDelta:      event.Actions.StateDelta,
+			ArtifactDelta:   event.Actions.ArtifactDelta,
+			Compaction:      fromEventCompaction(event.Actions.Compaction),
+			TransferToAgent: event.Actions.TransferToAgent,
+			Transfer:        fromEventTransfer(event.Actions.Transfer),
 		},
 	}
 }
@@ -0,0 +1,219 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"maps"
+	"strings"
+	"time"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool/toolconfirmation"
+)
+
+// SessionExportVersion is the version of the session export format.
+const SessionExportVersion = 1
+
+const (
+	// ExportRecordSession is the type of the first record of an export,
+	// which describes the session.
+	ExportRecordSession = "session"
+	// ExportRecordEvent is the type of the records of the events, which
+	// follow the session record in chronological order.
+	ExportRecordEvent = "event"
+)
+
+// ExportRecord is a line of a session export.
+type ExportRecord struct {
+	Type    string           `json:"type"`
+	Session *ExportedSession `json:"session,omitempty"`
+	Event   *ExportedEvent   `json:"event,omitempty"`
+}
+
+// ExportedSession describes an exported session.
+type ExportedSession struct {
+	Version        int       `json:"version"`
+	ID             string    `json:"id"`
+	AppName        string    `json:"appName"`
+	UserID         string    `json:"userId"`
+	LastUpdateTime time.Time `json:"lastUpdateTime"`
+	// State is the state of the session only: the keys of the app and user
+	// states, shared with other sessions, are neither exported nor imported.
+	State map[string]any `json:"state"`
+	// Artifacts are the names of the artifacts of the session. Their content
+	// is not exported.
+	Artifacts []string `json:"artifacts,omitempty"`
+}
+
+// ExportedEvent is an exported event. Unlike [Event], it keeps all the
+// fields of the event and the full precision of its timestamp.
+type ExportedEvent struct {
+	ID                 string                                      `json:"id"`
+	Timestamp          time.Time                                   `json:"timestamp"`
+	InvocationID       string                                      `json:"invocationId"`
+	Branch             string                                      `json:"branch,omitempty"`
+	Author             string                                      `json:"author"`
+	LongRunningToolIDs []string                                    `json:"longRunningToolIds,omitempty"`
+	Content            *genai.Content                              `json:"content,omitempty"`
+	CitationMetadata   *genai.CitationMetadata                     `json:"citationMetadata,omitempty"`
+	GroundingMetadata  *genai.GroundingMetadata                    `json:"groundingMetadata,omitempty"`
+	UsageMetadata      *genai.GenerateContentResponseUsageMetadata `json:"usageMetadata,omitempty"`
+	CustomMetadata     map[string]any                              `json:"customMetadata,omitempty"`
+	LogprobsResult     *genai.LogprobsResult                       `json:"logprobsResult,omitempty"`
+	Partial            bool                                        `json:"partial,omitempty"`
+	TurnComplete       bool                                        `json:"turnComplete,omitempty"`
+	Interrupted        bool                                        `json:"interrupted,omitempty"`
+	ErrorCode          string                                      `json:"errorCode,omitempty"`
+	ErrorMessage       string                                      `json:"errorMessage,omitempty"`
+	FinishReason       genai.FinishReason                          `json:"finishReason,omitempty"`
+	AvgLogprobs        float64                                     `json:"avgLogprobs,omitempty"`
+	Actions            ExportedEventActions                        `json:"actions"`
+}
+
+// ExportedEventActions are the actions of an exported event.
+type ExportedEventActions struct {
+	StateDelta                 map[string]any                               `json:"stateDelta,omitempty"`
+	ArtifactDelta              map[string]int64                             `json:"artifactDelta,omitempty"`
+	RequestedToolConfirmations map[string]toolconfirmation.ToolConfirmation `json:"requestedToolConfirmations,omitempty"`
+	SkipSummarization          bool                                         `json:"skipSummarization,omitempty"`
+	TransferToAgent            string                                       `json:"transferToAgent,omitempty"`
+	Transfer                   *EventTransfer                               `json:"transfer,omitempty"`
+	Escalate                   bool                                         `json:"escalate,omitempty"`
+	EscalateToRoot             bool                                         `json:"escalateToRoot,omitempty"`
+	Compaction                 *EventCompaction                             `json:"compaction,omitempty"`
+}
+
+// SessionImport is the response of a session import.
+type SessionImport struct {
+	Session Session `json:"session"`
+	// UnresolvedAuthors are the authors of imported events which are
+	// neither the user nor an agent of the app.
+	UnresolvedAuthors []string `json:"unresolvedAuthors,omitempty"`
+}
+
+// ExportSession describes the session for the first record of its export.
+func ExportSession(s session.Session, artifacts []string) *ExportedSession {
+	state := map[string]any{}
+	maps.Insert(state, s.State().All())
+	return &ExportedSession{
+		Version:        SessionExportVersion,
+		ID:             s.ID(),
+		AppName:        s.AppName(),
+		UserID:         s.UserID(),
+		LastUpdateTime: s.LastUpdateTime(),
+		State:          sessionScopedState(state),
+		Artifacts:      artifacts,
+	}
+}
+
+// SessionState returns the state to create the imported session with. The
+// keys of the app and user states, which an export is not supposed to have,
+// are dropped.
+func (s *ExportedSession) SessionState() map[string]any {
+	return sessionScopedState(s.State)
+}
+
+// ExportEvent maps session.Event to ExportedEvent.
+func ExportEvent(event *session.Event) *ExportedEvent {
+	return &ExportedEvent{
+		ID:                 event.ID,
+		Timestamp:          event.Timestamp,
+		InvocationID:       event.InvocationID,
+		Branch:             event.Branch,
+		Author:             event.Author,
+		LongRunningToolIDs: event.LongRunningToolIDs,
+		Content:            event.Content,
+		CitationMetadata:   event.CitationMetadata,
+		GroundingMetadata:  event.GroundingMetadata,
+		UsageMetadata:      event.UsageMetadata,
+		CustomMetadata:     event.CustomMetadata,
+		LogprobsResult:     event.LogprobsResult,
+		Partial:            event.Partial,
+		TurnComplete:       event.TurnComplete,
+		Interrupted:        event.Interrupted,
+		ErrorCode:          event.ErrorCode,
+		ErrorMessage:       event.ErrorMessage,
+		FinishReason:       event.FinishReason,
+		AvgLogprobs:        event.AvgLogprobs,
+		Actions: ExportedEventActions{
+			StateDelta:                 sessionScopedState(event.Actions.StateDelta),
+			ArtifactDelta:              event.Actions.ArtifactDelta,
+			RequestedToolConfirmations: event.Actions.RequestedToolConfirmations,
+			SkipSummarization:          event.Actions.SkipSummarization,
+			TransferToAgent:            event.Actions.TransferToAgent,
+			Transfer:                   fromEventTransfer(event.Actions.Transfer),
+			Escalate:                   event.Actions.Escalate,
+			EscalateToRoot:             event.Actions.EscalateToRoot,
+			Compaction:                 fromEventCompaction(event.Actions.Compaction),
+		},
+	}
+}
+
+// ToSessionEvent maps ExportedEvent to session.Event.
+func (e *ExportedEvent) ToSessionEvent() *session.Event {
+	return &session.Event{
+		ID:                 e.ID,
+		Timestamp:          e.Timestamp,
+		InvocationID:       e.InvocationID,
+		Branch:             e.Branch,
+		Author:             e.Author,
+		LongRunningToolIDs: e.LongRunningToolIDs,
+		LLMResponse: model.LLMResponse{
+			Content:           e.Content,
+			CitationMetadata:  e.CitationMetadata,
+			GroundingMetadata: e.GroundingMetadata,
+			UsageMetadata:     e.UsageMetadata,
+			CustomMetadata:    e.CustomMetadata,
+			LogprobsResult:    e.LogprobsResult,
+			Partial:           e.Partial,
+			TurnComplete:      e.TurnComplete,
+			Interrupted:       e.Interrupted,
+			ErrorCode:         e.ErrorCode,
+			ErrorMessage:      e.ErrorMessage,
+			FinishReason:      e.FinishReason,
+			AvgLogprobs:       e.AvgLogprobs,
+		},
+		Actions: session.EventActions{
+			StateDelta:                 sessionScopedState(e.Actions.StateDelta),
+			ArtifactDelta:              e.Actions.ArtifactDelta,
+			RequestedToolConfirmations: e.Actions.RequestedToolConfirmations,
+			SkipSummarization:          e.Actions.SkipSummarization,
+			TransferToAgent:            e.Actions.TransferToAgent,
+			Transfer:                   e.Actions.Transfer.toSession(),
+			Escalate:                   e.Actions.Escalate,
+			EscalateToRoot:             e.Actions.EscalateToRoot,
+			Compaction:                 e.Actions.Compaction.toSession(),
+		},
+	}
+}
+
+// sessionScopedState returns the keys of state which belong to the session
+// itself, dropping the ones of the app and user states and the temporary ones.
+func sessionScopedState(state map[string]any) map[string]any {
+	if state == nil {
+		return nil
+	}
+	scoped := make(map[string]any, len(state))
+	for k, v := range state {
+		if strings.HasPrefix(k, session.KeyPrefixApp) || strings.HasPrefix(k, session.KeyPrefixUser) || strings.HasPrefix(k, session.KeyPrefixTemp) {
+			continue
+		}
+		scoped[k] = v
+	}
+	return scoped
+}
@@ -0,0 +1,28 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+// ForkSessionRequest is the request of a session fork.
+type ForkSessionRequest struct {
+	// EventID is the last event copied into the forked session. If empty,
+	// all the events are copied.
+	EventID string `json:"eventId,omitempty"`
+	// SessionID is the ID of the forked session. If empty, it is generated.
+	SessionID string `json:"sessionId,omitempty"`
+	// CopyArtifacts copies the artifacts referenced by the copied events
+	// into the forked session instead of sharing those of the source
+	// session.
+	CopyArtifacts bool `json:"copyArtifacts,omitempty"`
+}
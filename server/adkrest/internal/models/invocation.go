@@ -0,0 +1,74 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+const (
+	// InvocationStateRunning is the state of an invocation which hasn't finished.
+	InvocationStateRunning = "running"
+	// InvocationStateCompleted is the state of an invocation which finished successfully.
+	InvocationStateCompleted = "completed"
+	// InvocationStateFailed is the state of an invocation which failed or was interrupted.
+	InvocationStateFailed = "failed"
+)
+
+const (
+	// CallbackModeEvents posts each final event of an async run, then its
+	// outcome.
+	CallbackModeEvents = "events"
+	// CallbackModeTerminal posts only the outcome of an async run, with its
+	// last final event.
+	CallbackModeTerminal = "terminal"
+)
+
+const (
+	// DeliveryStatePending is the state of a callback not delivered yet.
+	DeliveryStatePending = "pending"
+	// DeliveryStateDelivered is the state of a callback accepted by the receiver.
+	DeliveryStateDelivered = "delivered"
+	// DeliveryStateFailed is the state of a callback which could not be
+	// delivered, even after retries.
+	DeliveryStateFailed = "failed"
+)
+
+// InvocationStatus is the status of an invocation.
+type InvocationStatus struct {
+	InvocationID string `json:"invocationId"`
+	State        string `json:"state"`
+	ErrorCode    string `json:"errorCode,omitempty"`
+	ErrorMessage string `json:"errorMessage,omitempty"`
+	// Deliveries are the callbacks of an async run, in order.
+	Deliveries []CallbackDelivery `json:"deliveries,omitempty"`
+}
+
+// CallbackDelivery is the status of a callback of an async run.
+type CallbackDelivery struct {
+	ID string `json:"id"`
+	// EventID is the ID of the event carried by the callback, if any.
+	EventID   string `json:"eventId,omitempty"`
+	State     string `json:"state"`
+	Attempts  int    `json:"attempts"`
+	LastError string `json:"lastError,omitempty"`
+}
+
+// InvocationCallback is the body of the callbacks of an async run. The
+// callbacks carrying the outcome of the run have a state other than
+// [InvocationStateRunning].
+type InvocationCallback struct {
+	InvocationID string `json:"invocationId"`
+	State        string `json:"state"`
+	ErrorCode    string `json:"errorCode,omitempty"`
+	ErrorMessage string `json:"errorMessage,omitempty"`
+	Event        *Event `json:"event,omitempty"`
+}
@@ -32,6 +32,43 @@ type RunAgentRequest struct {
 	Streaming bool `json:"streaming,omitempty"`
 
 	StateDelta *map[string]any `json:"stateDelta,omitempty"`
+
+	// InvocationId is set to resume the stream of an existing invocation
+	// instead of starting a new one, see the Last-Event-ID header.
+	InvocationId string `json:"invocationId,omitempty"`
+
+	// CallbackURL is where the callbacks of an async run are posted.
+	CallbackURL string `json:"callbackUrl,omitempty"`
+
+	// CallbackMode selects the callbacks of an async run:
+	// [CallbackModeEvents] (the default) or [CallbackModeTerminal].
+	CallbackMode string `json:"callbackMode,omitempty"`
+
+	// ExcludeThoughts removes the thoughts of the model, i.e. the content
+	// parts with their thought field set, from the returned events. The
+	// events are stored with their thoughts.
+	ExcludeThoughts bool `json:"excludeThoughts,omitempty"`
+
+	// RunConfig configures the run. Optional: the run streams if Streaming
+	// is set, without limits.
+	RunConfig *RunConfig `json:"runConfig,omitempty"`
+}
+
+// RunConfig is the configuration of a run, see [agent.RunConfig].
+type RunConfig struct {
+	// StreamingMode is "none" or "sse", for the text of the model responses
+	// streamed in partial events. It takes precedence over the Streaming
+	// field of the request.
+	StreamingMode string `json:"streamingMode,omitempty"`
+	// MaxLlmCalls is the maximum number of model calls of the invocation,
+	// zero for no limit. The invocation exceeding it ends with a final event
+	// with the LLM_CALLS_LIMIT_EXCEEDED error code.
+	MaxLlmCalls int `json:"maxLlmCalls,omitempty"`
+	// ResponseModalities are the modalities of the responses of the model.
+	ResponseModalities []genai.Modality `json:"responseModalities,omitempty"`
+	// SavePartialEvents persists the partial events of the invocation in
+	// the session too.
+	SavePartialEvents bool `json:"savePartialEvents,omitempty"`
 }
 
 // AssertRunAgentRequestRequired checks if the required fields are not zero-ed
@@ -50,3 +87,80 @@ func (req RunAgentRequest) AssertRunAgentRequestRequired() error {
 
 	return nil
 }
+
+// SubmitToolResultRequest is the request submitting the result of a pending
+// long-running function call of an invocation.
+type SubmitToolResultRequest struct {
+	// FunctionCallID is the ID of the function call.
+	FunctionCallID string `json:"functionCallId"`
+	// Response is the result of the call, given to the model as the
+	// response of the function.
+	Response map[string]any `json:"response"`
+	// ExcludeThoughts removes the thoughts of the model from the returned
+	// events, see [RunAgentRequest].
+	ExcludeThoughts bool `json:"excludeThoughts,omitempty"`
+}
+
+// SubmitChoiceRequest is the request submitting the selection of the user for
+// a pending call of the get_user_choice tool of an invocation.
+type SubmitChoiceRequest struct {
+	// FunctionCallID is the ID of the function call.
+	FunctionCallID string `json:"functionCallId"`
+	// Selection is the option selected by the user, or any answer if the
+	// choice is freeform.
+	Selection string `json:"selection"`
+	// ExcludeThoughts removes the thoughts of the model from the returned
+	// events, see [RunAgentRequest].
+	ExcludeThoughts bool `json:"excludeThoughts,omitempty"`
+}
+
+// ResumeInvocationRequest is the request resuming an interrupted invocation
+// from its checkpoints.
+type ResumeInvocationRequest struct {
+	// ExcludeThoughts removes the thoughts of the model from the returned
+	// events, see [RunAgentRequest].
+	ExcludeThoughts bool `json:"excludeThoughts,omitempty"`
+}
+
+// ConfirmToolRequest is the decision of the user on a function call of an
+// invocation which requires a confirmation.
+type ConfirmToolRequest struct {
+	// FunctionCallID is the ID of the function call to confirm, not of the
+	// confirmation request.
+	FunctionCallID string `json:"functionCallId"`
+	// Confirmed approves the call, or denies it if false.
+	Confirmed bool `json:"confirmed"`
+	// Arguments are the arguments of an approved call edited by the user.
+	// Optional: if nil, the call is executed with the arguments of the model.
+	Arguments map[string]any `json:"arguments,omitempty"`
+	// Reason is the reason of a denial, given to the model.
+	Reason string `json:"reason,omitempty"`
+	// ExcludeThoughts removes the thoughts of the model from the returned
+	// events, see [RunAgentRequest].
+	ExcludeThoughts bool `json:"excludeThoughts,omitempty"`
+}
+
+// LiveRequest is a single message sent by the client over a live (WebSocket) connection.
+// Content is expected to be set, unless Close is true.
+type LiveRequest struct {
+	// Content is a user turn, e.g. text or function responses.
+	Content *genai.Content `json:"content,omitempty"`
+	// Blob is a chunk of realtime media, e.g. audio, forwarded to the live
+	// session of the model of the agent. The requests which carry a blob
+	// are rejected if the agent doesn't run live.
+	Blob *genai.Blob `json:"blob,omitempty"`
+	// Close asks the server to end the live connection.
+	Close bool `json:"close,omitempty"`
+}
+
+// UserContent returns the content which should be sent to the agent for this request.
+// It returns nil if the request carries no content.
+func (req LiveRequest) UserContent() *genai.Content {
+	if req.Content == nil {
+		return nil
+	}
+	if req.Content.Role == "" {
+		req.Content.Role = genai.RoleUser
+	}
+	return req.Content
+}
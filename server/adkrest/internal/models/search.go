@@ -0,0 +1,44 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"time"
+
+	"google.golang.org/adk/session"
+)
+
+// SearchResult represents a data model for session.SearchResult.
+type SearchResult struct {
+	SessionID string `json:"sessionId"`
+	// EventID is the matching event, or empty if a state value matched.
+	EventID string `json:"eventId,omitempty"`
+	// StateKey is the key of the matching state value, or empty if an event
+	// matched.
+	StateKey  string    `json:"stateKey,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+	Snippet   string    `json:"snippet"`
+}
+
+// FromSearchResult converts a session.SearchResult.
+func FromSearchResult(r session.SearchResult) SearchResult {
+	return SearchResult{
+		SessionID: r.SessionID,
+		EventID:   r.EventID,
+		StateKey:  r.StateKey,
+		Timestamp: r.Timestamp,
+		Snippet:   r.Snippet,
+	}
+}
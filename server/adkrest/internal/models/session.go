@@ -105,3 +105,10 @@ func (s Session) Validate() error {
 	}
 	return nil
 }
+
+// Page is a page of a paginated listing.
+type Page[T any] struct {
+	Items []T `json:"items"`
+	// NextPageToken is the token of the next page, or empty if this is the last page.
+	NextPageToken string `json:"nextPageToken,omitempty"`
+}
@@ -0,0 +1,68 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"google.golang.org/adk/session"
+)
+
+// InvocationUsage is the token usage of an invocation, in total and by model.
+type InvocationUsage struct {
+	InvocationID string `json:"invocationId"`
+	// Running is set if the invocation hasn't finished, and its usage may
+	// still grow.
+	Running bool  `json:"running,omitempty"`
+	Total   Usage `json:"total"`
+	// Models is the usage by model name. The usage of the responses of an
+	// unknown model is under the empty name.
+	Models map[string]Usage `json:"models"`
+}
+
+// Usage is a sum of the usage metadata of model responses.
+type Usage struct {
+	Responses               int   `json:"responses"`
+	PromptTokenCount        int64 `json:"promptTokenCount"`
+	CandidatesTokenCount    int64 `json:"candidatesTokenCount"`
+	CachedContentTokenCount int64 `json:"cachedContentTokenCount"`
+	ThoughtsTokenCount      int64 `json:"thoughtsTokenCount"`
+	ToolUsePromptTokenCount int64 `json:"toolUsePromptTokenCount"`
+	TotalTokenCount         int64 `json:"totalTokenCount"`
+}
+
+// FromInvocationUsage converts a session.InvocationUsage to an
+// InvocationUsage.
+func FromInvocationUsage(u *session.InvocationUsage) InvocationUsage {
+	models := make(map[string]Usage, len(u.Models))
+	for name, usage := range u.Models {
+		models[name] = fromUsage(usage)
+	}
+	return InvocationUsage{
+		InvocationID: u.InvocationID,
+		Total:        fromUsage(&u.Total),
+		Models:       models,
+	}
+}
+
+func fromUsage(u *session.Usage) Usage {
+	return Usage{
+		Responses:               u.Responses,
+		PromptTokenCount:        u.PromptTokens,
+		CandidatesTokenCount:    u.CandidatesTokens,
+		CachedContentTokenCount: u.CachedTokens,
+		ThoughtsTokenCount:      u.ThoughtsTokens,
+		ToolUsePromptTokenCount: u.ToolUseTokens,
+		TotalTokenCount:         u.TotalTokens,
+	}
+}
@@ -0,0 +1,25 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+// UserDataDeletion is the response of the deletion of all the data of a
+// user.
+type UserDataDeletion struct {
+	DeletedSessions  int  `json:"deletedSessions"`
+	DeletedEvents    int  `json:"deletedEvents"`
+	DeletedUserState bool `json:"deletedUserState"`
+	// DeletedArtifacts is the number of deleted artifact versions.
+	DeletedArtifacts int `json:"deletedArtifacts"`
+}
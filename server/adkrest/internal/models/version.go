@@ -0,0 +1,33 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+// VersionInfo is the body of the version response.
+type VersionInfo struct {
+	// Version is the ADK Go version, as sent to the models in the user agent.
+	Version string `json:"version"`
+	// ModuleVersion is the version of the ADK module the server was built
+	// with, e.g. a pseudo-version for unreleased builds.
+	ModuleVersion string `json:"moduleVersion,omitempty"`
+	// Revision is the VCS revision of the server binary.
+	Revision     string `json:"revision,omitempty"`
+	RevisionTime string `json:"revisionTime,omitempty"`
+	// Modified reports whether the binary was built from a modified tree.
+	Modified  bool   `json:"modified,omitempty"`
+	GoVersion string `json:"goVersion"`
+	// Features describes the features enabled in the server configuration,
+	// e.g. "streaming": true or "artifacts": "gcsartifact.gcsService".
+	Features map[string]any `json:"features"`
+}
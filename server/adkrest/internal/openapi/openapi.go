@@ -0,0 +1,240 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package openapi builds the OpenAPI 3.1 document of the ADK REST API from
+// the descriptions of its routes. The schemas are derived from the Go types
+// of the request and response bodies.
+package openapi
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Version is the version of the OpenAPI specification of the documents.
+const Version = "3.1.0"
+
+// Operation describes a route of the REST API.
+type Operation struct {
+	// Summary is a short description of the operation.
+	Summary string
+	// Parameters are the query and header parameters of the operation. The
+	// path parameters are derived from the pattern of the route.
+	Parameters []Parameter
+	// Request is the body of the request.
+	// Optional: if nil, the request has no body.
+	Request Body
+	// OptionalRequest tells that the request body can be omitted.
+	OptionalRequest bool
+	// Response is the body of the successful response.
+	// Optional: if nil, the response has no documented body.
+	Response Body
+}
+
+// Body maps the media types of a body to values of the Go types which are
+// encoded in them. A nil value stands for raw [Binary] content.
+type Body map[string]any
+
+// JSON returns the body of the JSON encoding of the type of v.
+func JSON(v any) Body {
+	return Body{"application/json": v}
+}
+
+// Parameter describes a query or header parameter.
+type Parameter struct {
+	Name string
+	// In is where the parameter is sent: "query" or "header".
+	In          string
+	Description string
+	Required    bool
+	// Type is a value of the Go type of the parameter.
+	// Optional: if nil, the parameter is a string.
+	Type any
+}
+
+// Query returns an optional query parameter.
+func Query(name, description string, v any) Parameter {
+	return Parameter{Name: name, In: "query", Description: description, Type: v}
+}
+
+// Header returns an optional header parameter.
+func Header(name, description string) Parameter {
+	return Parameter{Name: name, In: "header", Description: description}
+}
+
+// Endpoint is a route served by the REST API.
+type Endpoint struct {
+	// Name identifies the endpoint, it is used as the ID of the operation.
+	Name    string
+	Methods []string
+	Pattern string
+	// Operation describes the endpoint.
+	// Optional: if nil, the endpoint is not documented.
+	Operation *Operation
+}
+
+// Config describes the API as a whole.
+type Config struct {
+	Title   string
+	Version string
+	// Error is a value of the Go type of the error responses.
+	Error any
+}
+
+// Document is an OpenAPI document.
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+// Info is the metadata of the API.
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// PathItem maps the lowercase HTTP methods of a path to their operations.
+type PathItem map[string]*OperationObject
+
+// OperationObject describes an operation in the document.
+type OperationObject struct {
+	OperationID string               `json:"operationId"`
+	Summary     string               `json:"summary,omitempty"`
+	Parameters  []*ParameterObject   `json:"parameters,omitempty"`
+	RequestBody *RequestBodyObject   `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+// ParameterObject describes a parameter in the document.
+type ParameterObject struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBodyObject describes a request body in the document.
+type RequestBodyObject struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+// Response describes a response in the document.
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// MediaType describes the content of a body in one media type.
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds the schemas referenced by the document.
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+var pathParam = regexp.MustCompile(`\{([^}:]+)(?::[^}]*)?\}`)
+
+// Build returns the document describing the endpoints. The endpoints
+// without operation are left out, as well as the CORS preflight methods.
+func Build(cfg Config, endpoints []Endpoint) (*Document, error) {
+	s := newSchemas()
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    Info{Title: cfg.Title, Version: cfg.Version},
+		Paths:   map[string]PathItem{},
+	}
+	var errorResponse *Response
+	if cfg.Error != nil {
+		errorResponse = &Response{Description: "Error", Content: content(s, JSON(cfg.Error))}
+	}
+	ids := map[string]bool{}
+	for _, e := range endpoints {
+		if e.Operation == nil {
+			continue
+		}
+		if ids[e.Name] {
+			return nil, fmt.Errorf("duplicate operation %q", e.Name)
+		}
+		ids[e.Name] = true
+
+		var params []*ParameterObject
+		for _, m := range pathParam.FindAllStringSubmatch(e.Pattern, -1) {
+			params = append(params, &ParameterObject{Name: m[1], In: "path", Required: true, Schema: &Schema{Type: "string"}})
+		}
+		for _, p := range e.Operation.Parameters {
+			params = append(params, &ParameterObject{Name: p.Name, In: p.In, Description: p.Description, Required: p.Required, Schema: parameterSchema(s, p)})
+		}
+		op := &OperationObject{
+			OperationID: e.Name,
+			Summary:     e.Operation.Summary,
+			Parameters:  params,
+			Responses:   map[string]*Response{"200": {Description: "OK", Content: content(s, e.Operation.Response)}},
+		}
+		if e.Operation.Request != nil {
+			op.RequestBody = &RequestBodyObject{Required: !e.Operation.OptionalRequest, Content: content(s, e.Operation.Request)}
+		}
+		if errorResponse != nil {
+			op.Responses["default"] = errorResponse
+		}
+
+		path := pathParam.ReplaceAllString(e.Pattern, "{$1}")
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = PathItem{}
+			doc.Paths[path] = item
+		}
+		for _, method := range e.Methods {
+			if method == http.MethodOptions {
+				continue
+			}
+			method = strings.ToLower(method)
+			if _, ok := item[method]; ok {
+				return nil, fmt.Errorf("operations %q and %q have the same method and path: %s %s", item[method].OperationID, e.Name, method, path)
+			}
+			item[method] = op
+		}
+	}
+	doc.Components.Schemas = s.components
+	return doc, nil
+}
+
+func parameterSchema(s *schemas, p Parameter) *Schema {
+	if p.Type == nil {
+		return &Schema{Type: "string"}
+	}
+	return s.of(p.Type)
+}
+
+func content(s *schemas, body Body) map[string]*MediaType {
+	if body == nil {
+		return nil
+	}
+	c := make(map[string]*MediaType, len(body))
+	// Sorted, so that the names of the components don't depend on the order
+	// of iteration.
+	for _, mediaType := range slices.Sorted(maps.Keys(body)) {
+		c[mediaType] = &MediaType{Schema: s.of(body[mediaType])}
+	}
+	return c
+}
@@ -0,0 +1,171 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type node struct {
+	Name     string         `json:"name"`
+	Children []*node        `json:"children,omitempty"`
+	Created  time.Time      `json:"created"`
+	Labels   map[string]int `json:"labels,omitempty"`
+	Value    any            `json:"value,omitempty"`
+	Data     []byte         `json:"data,omitempty"`
+	Ignored  string         `json:"-"`
+	internal string
+	embedded
+}
+
+type embedded struct {
+	Extra bool `json:"extra"`
+}
+
+type errorBody struct {
+	Message string `json:"message"`
+}
+
+func TestBuild(t *testing.T) {
+	ref := func(name string) *Schema { return &Schema{Ref: "#/components/schemas/" + name} }
+	errResp := &Response{Description: "Error", Content: map[string]*MediaType{"application/json": {Schema: ref("errorBody")}}}
+
+	doc, err := Build(Config{Title: "Test", Version: "1.0", Error: errorBody{}}, []Endpoint{
+		{
+			Name:    "GetNode",
+			Methods: []string{http.MethodGet},
+			Pattern: "/nodes/{node_id}",
+			Operation: &Operation{
+				Summary:    "Gets a node.",
+				Parameters: []Parameter{Query("depth", "Depth.", 0)},
+				Response:   JSON(node{}),
+			},
+		},
+		{
+			Name:    "StreamNodes",
+			Methods: []string{http.MethodPost, http.MethodOptions},
+			Pattern: "/nodes/{node_id:[0-9]+}:stream",
+			Operation: &Operation{
+				Request:         Body{"multipart/form-data": nil},
+				OptionalRequest: true,
+				Response:        Body{"text/event-stream": OneOf{node{}, ""}},
+			},
+		},
+		{
+			Name:    "Undocumented",
+			Methods: []string{http.MethodGet},
+			Pattern: "/undocumented",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+
+	nodeParam := &ParameterObject{Name: "node_id", In: "path", Required: true, Schema: &Schema{Type: "string"}}
+	want := &Document{
+		OpenAPI: Version,
+		Info:    Info{Title: "Test", Version: "1.0"},
+		Paths: map[string]PathItem{
+			"/nodes/{node_id}": {
+				"get": {
+					OperationID: "GetNode",
+					Summary:     "Gets a node.",
+					Parameters: []*ParameterObject{
+						nodeParam,
+						{Name: "depth", In: "query", Description: "Depth.", Schema: &Schema{Type: "integer", Format: "int64"}},
+					},
+					Responses: map[string]*Response{
+						"200":     {Description: "OK", Content: map[string]*MediaType{"application/json": {Schema: ref("node")}}},
+						"default": errResp,
+					},
+				},
+			},
+			"/nodes/{node_id}:stream": {
+				"post": {
+					OperationID: "StreamNodes",
+					Parameters:  []*ParameterObject{nodeParam},
+					RequestBody: &RequestBodyObject{Content: map[string]*MediaType{"multipart/form-data": {Schema: &Schema{Type: "string", Format: "binary"}}}},
+					Responses: map[string]*Response{
+						"200":     {Description: "OK", Content: map[string]*MediaType{"text/event-stream": {Schema: &Schema{OneOf: []*Schema{ref("node"), {Type: "string"}}}}}},
+						"default": errResp,
+					},
+				},
+			},
+		},
+		Components: Components{Schemas: map[string]*Schema{
+			"errorBody": {Type: "object", Properties: map[string]*Schema{"message": {Type: "string"}}, Required: []string{"message"}},
+			"node": {
+				Type: "object",
+				Properties: map[string]*Schema{
+					"name":     {Type: "string"},
+					"children": {Type: "array", Items: ref("node")},
+					"created":  {Type: "string", Format: "date-time"},
+					"labels":   {Type: "object", AdditionalProperties: &Schema{Type: "integer", Format: "int64"}},
+					"value":    {},
+					"data":     {Type: "string", Format: "byte"},
+					"extra":    {Type: "boolean"},
+				},
+				Required: []string{"name", "created", "extra"},
+			},
+		}},
+	}
+	if diff := cmp.Diff(want, doc); diff != "" {
+		t.Errorf("Build() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestBuild_Conflicts(t *testing.T) {
+	tc := []struct {
+		name      string
+		endpoints []Endpoint
+	}{
+		{
+			name: "duplicate name",
+			endpoints: []Endpoint{
+				{Name: "Op", Methods: []string{http.MethodGet}, Pattern: "/a", Operation: &Operation{}},
+				{Name: "Op", Methods: []string{http.MethodGet}, Pattern: "/b", Operation: &Operation{}},
+			},
+		},
+		{
+			name: "same method and path",
+			endpoints: []Endpoint{
+				{Name: "A", Methods: []string{http.MethodGet}, Pattern: "/a", Operation: &Operation{}},
+				{Name: "B", Methods: []string{http.MethodGet}, Pattern: "/a", Operation: &Operation{}},
+			},
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Build(Config{}, tt.endpoints); err == nil {
+				t.Errorf("Build() succeeded, want an error")
+			}
+		})
+	}
+}
+
+type page[T any] struct {
+	Items []T `json:"items"`
+}
+
+func TestComponentName(t *testing.T) {
+	if got, want := componentName(reflect.TypeFor[page[node]]()), "pagenode"; got != want {
+		t.Errorf("componentName() = %q, want %q", got, want)
+	}
+}
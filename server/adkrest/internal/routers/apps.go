@@ -18,6 +18,7 @@ import (
 	"net/http"
 
 	"google.golang.org/adk/server/adkrest/controllers"
+	"google.golang.org/adk/server/adkrest/internal/openapi"
 )
 
 // AppsAPIRouter defines the routes for the Apps API.
@@ -38,6 +39,21 @@ func (r *AppsAPIRouter) Routes() Routes {
 			Methods:     []string{http.MethodGet},
 			Pattern:     "/list-apps",
 			HandlerFunc: r.appsController.ListAppsHandler,
+			Operation: &openapi.Operation{
+				Summary:  "Lists the names of the apps.",
+				Response: openapi.JSON([]string{}),
+			},
+		},
+		Route{
+			Name:        "GetAppGraph",
+			Methods:     []string{http.MethodGet},
+			Pattern:     "/apps/{app_name}/graph",
+			HandlerFunc: r.appsController.AppGraphHandler,
+			Operation: &openapi.Operation{
+				Summary:    "Returns the graph of the agents and tools of the app.",
+				Parameters: []openapi.Parameter{openapi.Query("format", `"dot" (the default) or "mermaid".`, nil)},
+				Response:   openapi.Body{"text/vnd.graphviz": "", "text/vnd.mermaid": ""},
+			},
 		},
 	}
 }
@@ -17,7 +17,11 @@ package routers
 import (
 	"net/http"
 
+	"google.golang.org/genai"
+
 	"google.golang.org/adk/server/adkrest/controllers"
+	"google.golang.org/adk/server/adkrest/internal/models"
+	"google.golang.org/adk/server/adkrest/internal/openapi"
 )
 
 // ArtifactsAPIRouter defines the routes for the Artifacts API.
@@ -38,24 +42,86 @@ func (r *ArtifactsAPIRouter) Routes() Routes {
 			Methods:     []string{http.MethodGet},
 			Pattern:     "/apps/{app_name}/users/{user_id}/sessions/{session_id}/artifacts",
 			HandlerFunc: r.artifactsController.ListArtifactsHandler,
+			Operation: &openapi.Operation{
+				Summary:  "Lists the names of the artifacts of a session.",
+				Response: openapi.JSON([]string{}),
+			},
+		},
+		Route{
+			Name:        "UploadArtifact",
+			Methods:     []string{http.MethodPost, http.MethodOptions},
+			Pattern:     "/apps/{app_name}/users/{user_id}/sessions/{session_id}/artifacts",
+			HandlerFunc: r.artifactsController.UploadArtifactHandler,
+			Operation: &openapi.Operation{
+				Summary:    "Uploads a new version of an artifact, sent as the file part of a multipart form.",
+				Parameters: []openapi.Parameter{openapi.Query("name", "Name of the artifact. Defaults to the file name of the part.", nil)},
+				Request:    openapi.Body{"multipart/form-data": artifactUploadForm},
+				Response:   openapi.JSON(models.ArtifactUpload{}),
+			},
+		},
+		// Registered before LoadArtifact, which would match the signing path
+		// too.
+		Route{
+			Name:        "SignArtifactURL",
+			Methods:     []string{http.MethodGet},
+			Pattern:     "/apps/{app_name}/users/{user_id}/sessions/{session_id}/artifacts/{artifact_name}:signedUrl",
+			HandlerFunc: r.artifactsController.SignArtifactURLHandler,
+			Operation: &openapi.Operation{
+				Summary: "Returns a time-limited URL downloading an artifact version directly from the artifact store, by default its latest version.",
+				Parameters: []openapi.Parameter{
+					openapi.Query("version", "Version of the artifact.", int64(0)),
+					openapi.Query("ttl", "Validity of the URL in seconds. Defaults to 600.", int64(0)),
+				},
+				Response: openapi.JSON(models.ArtifactSignedURL{}),
+			},
 		},
 		Route{
 			Name:        "LoadArtifact",
 			Methods:     []string{http.MethodGet},
 			Pattern:     "/apps/{app_name}/users/{user_id}/sessions/{session_id}/artifacts/{artifact_name}",
 			HandlerFunc: r.artifactsController.LoadArtifactHandler,
+			Operation: &openapi.Operation{
+				Summary: "Loads an artifact, by default its latest version.",
+				Parameters: []openapi.Parameter{
+					openapi.Query("version", "Version of the artifact.", int64(0)),
+					altParameter,
+				},
+				Response: artifactContent,
+			},
 		},
 		Route{
 			Name:        "LoadArtifactVersion",
 			Methods:     []string{http.MethodGet},
 			Pattern:     "/apps/{app_name}/users/{user_id}/sessions/{session_id}/artifacts/{artifact_name}/versions/{version}",
 			HandlerFunc: r.artifactsController.LoadArtifactVersionHandler,
+			Operation: &openapi.Operation{
+				Summary:    "Loads a version of an artifact.",
+				Parameters: []openapi.Parameter{altParameter},
+				Response:   artifactContent,
+			},
 		},
 		Route{
 			Name:        "DeleteArtifact",
 			Methods:     []string{http.MethodDelete, http.MethodOptions},
 			Pattern:     "/apps/{app_name}/users/{user_id}/sessions/{session_id}/artifacts/{artifact_name}",
 			HandlerFunc: r.artifactsController.DeleteArtifactHandler,
+			Operation: &openapi.Operation{
+				Summary: "Deletes all the versions of an artifact.",
+			},
 		},
 	}
 }
+
+// artifactUploadForm is the multipart form of an artifact upload.
+var artifactUploadForm = struct {
+	File openapi.Binary `json:"file"`
+}{}
+
+var altParameter = openapi.Query("alt", `"media" to get the raw content of the artifact instead of a JSON part.`, nil)
+
+// artifactContent is the body of a loaded artifact: a JSON part, or the raw
+// content with the alt parameter.
+var artifactContent = openapi.Body{
+	"application/json": genai.Part{},
+	"*/*":              nil,
+}
@@ -18,6 +18,7 @@ import (
 	"net/http"
 
 	"google.golang.org/adk/server/adkrest/controllers"
+	"google.golang.org/adk/server/adkrest/internal/openapi"
 )
 
 // DebugAPIRouter defines the routes for the Debug API.
@@ -38,12 +39,20 @@ func (r *DebugAPIRouter) Routes() Routes {
 			Methods:     []string{http.MethodGet},
 			Pattern:     "/debug/trace/{event_id}",
 			HandlerFunc: r.runtimeController.TraceDictHandler,
+			Operation: &openapi.Operation{
+				Summary:  "Gets the attributes of the trace of an event.",
+				Response: openapi.JSON(map[string]string{}),
+			},
 		},
 		Route{
 			Name:        "GetEventGraph",
 			Methods:     []string{http.MethodGet},
 			Pattern:     "/apps/{app_name}/users/{user_id}/sessions/{session_id}/events/{event_id}/graph",
 			HandlerFunc: r.runtimeController.EventGraphHandler,
+			Operation: &openapi.Operation{
+				Summary:  "Gets the graph of the agents involved in an event, in DOT format.",
+				Response: openapi.JSON(map[string]string{}),
+			},
 		},
 		Route{
 			Name:        "GetSessionTrace",
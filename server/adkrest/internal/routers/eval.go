@@ -20,7 +20,8 @@ import (
 	"google.golang.org/adk/server/adkrest/controllers"
 )
 
-// EvalAPIRouter defines the routes for the Eval API.
+// EvalAPIRouter defines the routes for the Eval API. They are not implemented
+// yet, so they are left out of the OpenAPI document.
 type EvalAPIRouter struct{}
 
 // Routes returns the routes for the Apps API.
@@ -0,0 +1,59 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package routers
+
+import (
+	"net/http"
+
+	"google.golang.org/adk/server/adkrest/controllers"
+	"google.golang.org/adk/server/adkrest/internal/models"
+	"google.golang.org/adk/server/adkrest/internal/openapi"
+)
+
+// HealthAPIRouter defines the routes for the liveness and readiness probes.
+type HealthAPIRouter struct {
+	healthController *controllers.HealthAPIController
+}
+
+// NewHealthAPIRouter creates a new HealthAPIRouter.
+func NewHealthAPIRouter(controller *controllers.HealthAPIController) *HealthAPIRouter {
+	return &HealthAPIRouter{healthController: controller}
+}
+
+// Routes returns the routes for the Health API.
+func (r *HealthAPIRouter) Routes() Routes {
+	return Routes{
+		Route{
+			Name:        "Healthz",
+			Methods:     []string{http.MethodGet},
+			Pattern:     "/healthz",
+			HandlerFunc: r.healthController.HealthzHandler,
+			Operation: &openapi.Operation{
+				Summary:  "Reports that the server is alive.",
+				Response: openapi.JSON(models.HealthStatus{}),
+			},
+		},
+		Route{
+			Name:        "Readyz",
+			Methods:     []string{http.MethodGet},
+			Pattern:     "/readyz",
+			HandlerFunc: r.healthController.ReadyzHandler,
+			Operation: &openapi.Operation{
+				Summary:  "Reports whether the server and its dependencies are ready to serve. Fails with 503 status code otherwise.",
+				Response: openapi.JSON(models.HealthStatus{}),
+			},
+		},
+	}
+}
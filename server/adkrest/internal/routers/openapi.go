@@ -0,0 +1,71 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package routers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"google.golang.org/adk/internal/version"
+	"google.golang.org/adk/server/adkrest/controllers"
+	"google.golang.org/adk/server/adkrest/internal/openapi"
+)
+
+// OpenAPIRouter serves the OpenAPI document of the REST API.
+type OpenAPIRouter struct {
+	doc []byte
+}
+
+// NewOpenAPIRouter creates a new OpenAPIRouter. The document describes the
+// routes of the subrouters and the document route itself. It is built once,
+// so the subrouters must be all the routers of the API.
+func NewOpenAPIRouter(subrouters ...Router) (*OpenAPIRouter, error) {
+	r := &OpenAPIRouter{}
+	doc, err := openapi.Build(openapi.Config{
+		Title:   "ADK REST API",
+		Version: version.Version,
+		Error:   controllers.ErrorResponse{},
+	}, Endpoints(append(subrouters, r)...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build OpenAPI document: %w", err)
+	}
+	r.doc, err = json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode OpenAPI document: %w", err)
+	}
+	return r, nil
+}
+
+// Routes returns the route of the OpenAPI document.
+func (r *OpenAPIRouter) Routes() Routes {
+	return Routes{
+		Route{
+			Name:        "GetOpenAPI",
+			Methods:     []string{http.MethodGet},
+			Pattern:     "/openapi.json",
+			HandlerFunc: r.serveDocument,
+			Operation: &openapi.Operation{
+				Summary:  "Gets the OpenAPI document of the API.",
+				Response: openapi.JSON(map[string]any{}),
+			},
+		},
+	}
+}
+
+func (r *OpenAPIRouter) serveDocument(rw http.ResponseWriter, req *http.Request) {
+	rw.Header().Set("Content-Type", "application/json")
+	_, _ = rw.Write(r.doc)
+}
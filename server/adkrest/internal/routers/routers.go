@@ -19,6 +19,8 @@ import (
 	"net/http"
 
 	"github.com/gorilla/mux"
+
+	"google.golang.org/adk/server/adkrest/internal/openapi"
 )
 
 // A Route defines the parameters for an api endpoint
@@ -27,6 +29,9 @@ type Route struct {
 	Methods     []string
 	Pattern     string
 	HandlerFunc http.HandlerFunc
+	// Operation describes the route in the OpenAPI document.
+	// Optional: if nil, the route is not documented.
+	Operation *openapi.Operation
 }
 
 // Routes is a list of defined api endpoints
@@ -58,3 +63,19 @@ func SetupSubRouters(router *mux.Router, subrouters ...Router) {
 		}
 	}
 }
+
+// Endpoints returns the endpoints served by the routes of the subrouters.
+func Endpoints(subrouters ...Router) []openapi.Endpoint {
+	var endpoints []openapi.Endpoint
+	for _, api := range subrouters {
+		for _, route := range api.Routes() {
+			endpoints = append(endpoints, openapi.Endpoint{
+				Name:      route.Name,
+				Methods:   route.Methods,
+				Pattern:   route.Pattern,
+				Operation: route.Operation,
+			})
+		}
+	}
+	return endpoints
+}
@@ -18,6 +18,8 @@ import (
 	"net/http"
 
 	"google.golang.org/adk/server/adkrest/controllers"
+	"google.golang.org/adk/server/adkrest/internal/models"
+	"google.golang.org/adk/server/adkrest/internal/openapi"
 )
 
 // RuntimeAPIRouter defines the routes for the Runtime API.
@@ -38,12 +40,128 @@ func (r *RuntimeAPIRouter) Routes() Routes {
 			Methods:     []string{http.MethodPost, http.MethodOptions},
 			Pattern:     "/run",
 			HandlerFunc: controllers.NewErrorHandler(r.runtimeController.RunHandler),
+			Operation: &openapi.Operation{
+				Summary: "Runs an agent and returns all the events of the run. In async mode, replies with 202 status code and the status of the invocation once it has started, and posts the callbacks of the run to its callback URL.",
+				Parameters: []openapi.Parameter{
+					timeoutParameter,
+					openapi.Query("mode", `"sync" (the default) or "async".`, nil),
+				},
+				Request:  openapi.JSON(models.RunAgentRequest{}),
+				Response: openapi.JSON(openapi.OneOf{[]models.Event{}, models.InvocationStatus{}}),
+			},
 		},
 		Route{
 			Name:        "RunAgentSse",
 			Methods:     []string{http.MethodPost, http.MethodOptions},
 			Pattern:     "/run_sse",
 			HandlerFunc: controllers.NewErrorHandler(r.runtimeController.RunSSEHandler),
+			Operation: &openapi.Operation{
+				Summary: "Runs an agent and streams its events, or resumes the stream of a running invocation.",
+				Parameters: []openapi.Parameter{
+					timeoutParameter,
+					openapi.Header("Accept", "Format of the stream: "+controllers.ContentTypeSSE+" (the default) or "+controllers.ContentTypeNDJSON+"."),
+					openapi.Header(controllers.LastEventIDHeader, "ID of the last event received, to resume the stream of the invocation given in the request."),
+				},
+				Request: openapi.JSON(models.RunAgentRequest{}),
+				Response: openapi.Body{
+					controllers.ContentTypeSSE:    models.Event{},
+					controllers.ContentTypeNDJSON: models.Event{},
+				},
+			},
+		},
+		Route{
+			Name:        "RunAgentLive",
+			Methods:     []string{http.MethodGet},
+			Pattern:     "/run_live",
+			HandlerFunc: controllers.NewErrorHandler(r.runtimeController.RunLiveHandler),
+			Operation: &openapi.Operation{
+				Summary: "Upgrades the connection to a WebSocket on which the client exchanges live messages with an agent.",
+				Parameters: []openapi.Parameter{
+					{Name: "app_name", In: "query", Required: true},
+					{Name: "user_id", In: "query", Required: true},
+					{Name: "session_id", In: "query", Required: true},
+				},
+			},
+		},
+		Route{
+			Name:        "GetInvocation",
+			Methods:     []string{http.MethodGet},
+			Pattern:     "/apps/{app_name}/users/{user_id}/sessions/{session_id}/invocations/{invocation_id}",
+			HandlerFunc: controllers.NewErrorHandler(r.runtimeController.GetInvocationHandler),
+			Operation: &openapi.Operation{
+				Summary:  "Gets the status of an invocation, including the callbacks of async runs.",
+				Response: openapi.JSON(models.InvocationStatus{}),
+			},
+		},
+		Route{
+			Name:        "GetInvocationUsage",
+			Methods:     []string{http.MethodGet},
+			Pattern:     "/apps/{app_name}/users/{user_id}/sessions/{session_id}/invocations/{invocation_id}/usage",
+			HandlerFunc: controllers.NewErrorHandler(r.runtimeController.GetInvocationUsageHandler),
+			Operation: &openapi.Operation{
+				Summary:  "Gets the token usage of an invocation, in total and by model.",
+				Response: openapi.JSON(models.InvocationUsage{}),
+			},
+		},
+		Route{
+			Name:        "CancelInvocation",
+			Methods:     []string{http.MethodPost},
+			Pattern:     "/apps/{app_name}/users/{user_id}/sessions/{session_id}/invocations/{invocation_id}:cancel",
+			HandlerFunc: controllers.NewErrorHandler(r.runtimeController.CancelInvocationHandler),
+			Operation: &openapi.Operation{
+				Summary: "Cancels a running invocation.",
+			},
+		},
+		Route{
+			Name:        "ResumeInvocation",
+			Methods:     []string{http.MethodPost},
+			Pattern:     "/apps/{app_name}/users/{user_id}/sessions/{session_id}/invocations/{invocation_id}:resume",
+			HandlerFunc: controllers.NewErrorHandler(r.runtimeController.ResumeInvocationHandler),
+			Operation: &openapi.Operation{
+				Summary:  "Resumes an invocation interrupted before its end, e.g. by the restart of the server, from its checkpoints, and returns the events of the rest of the invocation.",
+				Request:  openapi.JSON(models.ResumeInvocationRequest{}),
+				Response: openapi.JSON([]models.Event{}),
+			},
+		},
+		Route{
+			Name:        "ConfirmTool",
+			Methods:     []string{http.MethodPost},
+			Pattern:     "/apps/{app_name}/users/{user_id}/sessions/{session_id}/invocations/{invocation_id}:confirmTool",
+			HandlerFunc: controllers.NewErrorHandler(r.runtimeController.ConfirmToolHandler),
+			Operation: &openapi.Operation{
+				Summary:  "Approves, optionally with edited arguments, or denies a function call of an invocation which requires a confirmation, and resumes the flow in a new invocation whose events are returned.",
+				Request:  openapi.JSON(models.ConfirmToolRequest{}),
+				Response: openapi.JSON([]models.Event{}),
+			},
+		},
+		Route{
+			Name:        "SubmitToolResult",
+			Methods:     []string{http.MethodPost},
+			Pattern:     "/apps/{app_name}/users/{user_id}/sessions/{session_id}/invocations/{invocation_id}:submitToolResult",
+			HandlerFunc: controllers.NewErrorHandler(r.runtimeController.SubmitToolResultHandler),
+			Operation: &openapi.Operation{
+				Summary:  "Submits the result of a pending long-running function call of an invocation, and resumes the flow in a new invocation whose events are returned.",
+				Request:  openapi.JSON(models.SubmitToolResultRequest{}),
+				Response: openapi.JSON([]models.Event{}),
+			},
+		},
+		Route{
+			Name:        "SubmitChoice",
+			Methods:     []string{http.MethodPost},
+			Pattern:     "/apps/{app_name}/users/{user_id}/sessions/{session_id}/invocations/{invocation_id}:submitChoice",
+			HandlerFunc: controllers.NewErrorHandler(r.runtimeController.SubmitChoiceHandler),
+			Operation: &openapi.Operation{
+				Summary:  "Submits the selection of the user for a pending call of the get_user_choice tool of an invocation, and resumes the flow in a new invocation whose events are returned.",
+				Request:  openapi.JSON(models.SubmitChoiceRequest{}),
+				Response: openapi.JSON([]models.Event{}),
+			},
 		},
 	}
 }
+
+var timeoutParameter = openapi.Parameter{
+	Name:        controllers.TimeoutHeader,
+	In:          "header",
+	Description: "Maximum duration of the run, in seconds.",
+	Type:        0.0,
+}
@@ -16,8 +16,11 @@ package routers
 
 import (
 	"net/http"
+	"time"
 
 	"google.golang.org/adk/server/adkrest/controllers"
+	"google.golang.org/adk/server/adkrest/internal/models"
+	"google.golang.org/adk/server/adkrest/internal/openapi"
 )
 
 // SessionsAPIRouter defines the routes for the Sessions API.
@@ -33,35 +36,150 @@ func NewSessionsAPIRouter(controller *controllers.SessionsAPIController) *Sessio
 // Routes returns the routes for the Sessions API.
 func (r *SessionsAPIRouter) Routes() Routes {
 	return Routes{
+		// Registered before GetSession, which would match the export path too.
+		Route{
+			Name:        "ExportSession",
+			Methods:     []string{http.MethodGet},
+			Pattern:     "/apps/{app_name}/users/{user_id}/sessions/{session_id}:export",
+			HandlerFunc: r.sessionController.ExportSessionHandler,
+			Operation: &openapi.Operation{
+				Summary:  "Exports a session as newline-delimited JSON: a session record, then a record per event.",
+				Response: openapi.Body{controllers.ContentTypeNDJSON: models.ExportRecord{}},
+			},
+		},
+		Route{
+			Name:        "ImportSession",
+			Methods:     []string{http.MethodPost},
+			Pattern:     "/apps/{app_name}/users/{user_id}/sessions:import",
+			HandlerFunc: r.sessionController.ImportSessionHandler,
+			Operation: &openapi.Operation{
+				Summary:  "Recreates an exported session under a new ID, reporting the authors which are not agents of the app.",
+				Request:  openapi.Body{controllers.ContentTypeNDJSON: models.ExportRecord{}},
+				Response: openapi.JSON(models.SessionImport{}),
+			},
+		},
+		Route{
+			Name:        "SearchSessions",
+			Methods:     []string{http.MethodGet},
+			Pattern:     "/apps/{app_name}/users/{user_id}/sessions:search",
+			HandlerFunc: r.sessionController.SearchSessionsHandler,
+			Operation: &openapi.Operation{
+				Summary: "Searches the event texts and the session states of a user for keywords, most recent matches first.",
+				Parameters: append([]openapi.Parameter{
+					{Name: "q", In: "query", Description: "The keywords, separated by spaces, which a match contains all of, ignoring the case.", Required: true},
+				}, pageParameters...),
+				Response: openapi.JSON(models.Page[models.SearchResult]{}),
+			},
+		},
+		// Registered before CreateSessionWithId, which would match the
+		// compaction and fork paths too.
+		Route{
+			Name:        "CompactSession",
+			Methods:     []string{http.MethodPost},
+			Pattern:     "/apps/{app_name}/users/{user_id}/sessions/{session_id}:compact",
+			HandlerFunc: r.sessionController.CompactSessionHandler,
+			Operation: &openapi.Operation{
+				Summary:         "Replaces the runs of old events of a session with summaries, keeping the recent events and those with state or artifact deltas.",
+				Request:         openapi.JSON(models.CompactSessionRequest{}),
+				OptionalRequest: true,
+				Response:        openapi.JSON(models.SessionCompaction{}),
+			},
+		},
+		Route{
+			Name:        "ForkSession",
+			Methods:     []string{http.MethodPost},
+			Pattern:     "/apps/{app_name}/users/{user_id}/sessions/{session_id}:fork",
+			HandlerFunc: r.sessionController.ForkSessionHandler,
+			Operation: &openapi.Operation{
+				Summary:         "Creates a session with the events of a session up to an event, recording the source in its state.",
+				Request:         openapi.JSON(models.ForkSessionRequest{}),
+				OptionalRequest: true,
+				Response:        openapi.JSON(models.Session{}),
+			},
+		},
 		Route{
 			Name:        "GetSession",
 			Methods:     []string{http.MethodGet},
 			Pattern:     "/apps/{app_name}/users/{user_id}/sessions/{session_id}",
 			HandlerFunc: r.sessionController.GetSessionHandler,
+			Operation: &openapi.Operation{
+				Summary:  "Gets a session with its events and state.",
+				Response: openapi.JSON(models.Session{}),
+			},
 		},
 		Route{
 			Name:        "CreateSession",
 			Methods:     []string{http.MethodPost},
 			Pattern:     "/apps/{app_name}/users/{user_id}/sessions",
 			HandlerFunc: r.sessionController.CreateSessionHandler,
+			Operation:   createSessionOperation,
 		},
 		Route{
 			Name:        "CreateSessionWithId",
 			Methods:     []string{http.MethodPost},
 			Pattern:     "/apps/{app_name}/users/{user_id}/sessions/{session_id}",
 			HandlerFunc: r.sessionController.CreateSessionHandler,
+			Operation:   createSessionOperation,
 		},
 		Route{
 			Name:        "DeleteSession",
 			Methods:     []string{http.MethodDelete, http.MethodOptions},
 			Pattern:     "/apps/{app_name}/users/{user_id}/sessions/{session_id}",
 			HandlerFunc: r.sessionController.DeleteSessionHandler,
+			Operation: &openapi.Operation{
+				Summary: "Deletes a session.",
+			},
 		},
 		Route{
 			Name:        "ListSessions",
 			Methods:     []string{http.MethodGet},
 			Pattern:     "/apps/{app_name}/users/{user_id}/sessions",
 			HandlerFunc: r.sessionController.ListSessionsHandler,
+			Operation: &openapi.Operation{
+				Summary:    "Lists the sessions of a user. Without pagination parameters, the first page is returned as a plain list.",
+				Parameters: pageParameters,
+				Response:   openapi.JSON(openapi.OneOf{[]models.Session{}, models.Page[models.Session]{}}),
+			},
+		},
+		Route{
+			Name:        "DeleteUserData",
+			Methods:     []string{http.MethodDelete},
+			Pattern:     "/apps/{app_name}/users/{user_id}",
+			HandlerFunc: r.sessionController.DeleteUserDataHandler,
+			Operation: &openapi.Operation{
+				Summary:  "Deletes all the sessions, events, user state and artifacts of a user.",
+				Response: openapi.JSON(models.UserDataDeletion{}),
+			},
+		},
+		Route{
+			Name:        "ListEvents",
+			Methods:     []string{http.MethodGet},
+			Pattern:     "/apps/{app_name}/users/{user_id}/sessions/{session_id}/events",
+			HandlerFunc: r.sessionController.ListEventsHandler,
+			Operation: &openapi.Operation{
+				Summary: "Lists the events of a session in chronological order.",
+				Parameters: append([]openapi.Parameter{
+					openapi.Query("author", "Only the events of this author.", nil),
+					openapi.Query("branch", "Only the events of this branch and its sub-branches.", nil),
+					openapi.Query("invocationId", "Only the events of this invocation.", nil),
+					openapi.Query("after", "Only the events after this RFC 3339 timestamp.", time.Time{}),
+					openapi.Query("before", "Only the events before this RFC 3339 timestamp.", time.Time{}),
+				}, pageParameters...),
+				Response: openapi.JSON(models.Page[models.Event]{}),
+			},
 		},
 	}
 }
+
+var createSessionOperation = &openapi.Operation{
+	Summary:         "Creates a session, optionally with an initial state and events.",
+	Request:         openapi.JSON(models.CreateSessionRequest{}),
+	OptionalRequest: true,
+	Response:        openapi.JSON(models.Session{}),
+}
+
+// pageParameters are the query parameters of the paginated listings.
+var pageParameters = []openapi.Parameter{
+	openapi.Query("pageSize", "Maximum number of items of the page.", 0),
+	openapi.Query("pageToken", "Token of the page, returned with the previous page.", nil),
+}
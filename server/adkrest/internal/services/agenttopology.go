@@ -0,0 +1,289 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/adk/agent"
+	agentinternal "google.golang.org/adk/internal/agent"
+	llmagentinternal "google.golang.org/adk/internal/llminternal"
+)
+
+// NodeKind is the kind of a node of an agent topology.
+type NodeKind string
+
+const (
+	NodeKindLLMAgent        NodeKind = "llm"
+	NodeKindSequentialAgent NodeKind = "sequential"
+	NodeKindParallelAgent   NodeKind = "parallel"
+	NodeKindLoopAgent       NodeKind = "loop"
+	NodeKindGraphAgent      NodeKind = "graph"
+	NodeKindCustomAgent     NodeKind = "custom"
+	NodeKindTool            NodeKind = "tool"
+	NodeKindToolset         NodeKind = "toolset"
+)
+
+// EdgeKind is the kind of an edge of an agent topology.
+type EdgeKind string
+
+const (
+	// EdgeKindSubAgent connects an agent to one of its sub-agents.
+	EdgeKindSubAgent EdgeKind = "sub_agent"
+	// EdgeKindTool connects an agent to one of its tools or toolsets.
+	EdgeKindTool EdgeKind = "tool"
+	// EdgeKindTransfer connects an agent to its parent or to a peer to which
+	// it can transfer the control.
+	EdgeKindTransfer EdgeKind = "transfer"
+)
+
+// TopologyNode is an agent, a tool or a toolset.
+type TopologyNode struct {
+	// ID is derived from the names of the node and of its agent, so it is
+	// the same for every topology of the same agent tree. It is a valid DOT
+	// and Mermaid identifier.
+	ID    string
+	Label string
+	Kind  NodeKind
+	// Truncated reports that the sub-agents of the node were left out
+	// because the tree is deeper than the maximum depth.
+	Truncated bool
+}
+
+// TopologyEdge connects two nodes of the topology.
+type TopologyEdge struct {
+	From, To string
+	Kind     EdgeKind
+}
+
+// Topology is the graph of an agent tree.
+type Topology struct {
+	// Nodes are in depth-first order, with the tools of each agent before its
+	// sub-agents.
+	Nodes []TopologyNode
+	Edges []TopologyEdge
+}
+
+// AgentTopology walks the tree of the root agent and returns its graph.
+// Agents deeper than maxDepth, the root being at depth 0, are left out. An
+// agent which appears again in the tree, as it can in custom agents, is
+// connected but not walked again.
+func AgentTopology(root agent.Agent, maxDepth int) *Topology {
+	b := &topologyBuilder{maxDepth: maxDepth, visited: map[string]bool{}}
+	b.addAgent(root, nil, 0)
+	b.addTransfers(root, nil, 0, map[string]bool{})
+	return &b.topology
+}
+
+type topologyBuilder struct {
+	topology Topology
+	maxDepth int
+	// visited are the IDs of the walked agents.
+	visited map[string]bool
+}
+
+func (b *topologyBuilder) addAgent(a agent.Agent, parent agent.Agent, depth int) {
+	id := agentNodeID(a)
+	if parent != nil {
+		b.topology.Edges = append(b.topology.Edges, TopologyEdge{From: agentNodeID(parent), To: id, Kind: EdgeKindSubAgent})
+	}
+	if b.visited[id] {
+		return
+	}
+	b.visited[id] = true
+	truncated := depth >= b.maxDepth && len(a.SubAgents()) > 0
+	b.topology.Nodes = append(b.topology.Nodes, TopologyNode{ID: id, Label: a.Name(), Kind: agentKind(a), Truncated: truncated})
+
+	if llmAgent, ok := a.(llmagentinternal.Agent); ok {
+		state := llmagentinternal.Reveal(llmAgent)
+		for _, t := range state.Tools {
+			b.addTool(id, t.Name(), NodeKindTool)
+		}
+		for _, ts := range state.Toolsets {
+			b.addTool(id, ts.Name(), NodeKindToolset)
+		}
+	}
+	if truncated {
+		return
+	}
+	for _, sub := range a.SubAgents() {
+		b.addAgent(sub, a, depth+1)
+	}
+}
+
+func (b *topologyBuilder) addTool(agentID, name string, kind NodeKind) {
+	id := agentID + "__" + string(kind) + "_" + escapeNodeID(name)
+	b.topology.Nodes = append(b.topology.Nodes, TopologyNode{ID: id, Label: name, Kind: kind})
+	b.topology.Edges = append(b.topology.Edges, TopologyEdge{From: agentID, To: id, Kind: EdgeKindTool})
+}
+
+// addTransfers adds the transfer edges of the walked agents. Transfers to
+// the sub-agents are already shown by the sub-agent edges.
+func (b *topologyBuilder) addTransfers(a, parent agent.Agent, depth int, visited map[string]bool) {
+	id := agentNodeID(a)
+	if visited[id] {
+		return
+	}
+	visited[id] = true
+	for _, target := range llmagentinternal.TransferTargets(a, parent) {
+		targetID := agentNodeID(target)
+		if parent != nil && (targetID == agentNodeID(parent) || isSubAgent(parent, target)) {
+			b.topology.Edges = append(b.topology.Edges, TopologyEdge{From: id, To: targetID, Kind: EdgeKindTransfer})
+		}
+	}
+	if depth >= b.maxDepth {
+		return
+	}
+	for _, sub := range a.SubAgents() {
+		b.addTransfers(sub, a, depth+1, visited)
+	}
+}
+
+func isSubAgent(parent, a agent.Agent) bool {
+	for _, sub := range parent.SubAgents() {
+		if sub.Name() == a.Name() {
+			return true
+		}
+	}
+	return false
+}
+
+func agentKind(a agent.Agent) NodeKind {
+	typed, ok := a.(agentinternal.Agent)
+	if !ok {
+		return NodeKindCustomAgent
+	}
+	switch agentinternal.Reveal(typed).AgentType {
+	case agentinternal.TypeLLMAgent:
+		return NodeKindLLMAgent
+	case agentinternal.TypeSequentialAgent:
+		return NodeKindSequentialAgent
+	case agentinternal.TypeParallelAgent:
+		return NodeKindParallelAgent
+	case agentinternal.TypeLoopAgent:
+		return NodeKindLoopAgent
+	case agentinternal.TypeGraphAgent:
+		return NodeKindGraphAgent
+	default:
+		return NodeKindCustomAgent
+	}
+}
+
+// agentNodeID returns the ID of the agent node. Agent names are unique in a
+// tree, so they identify the agents.
+func agentNodeID(a agent.Agent) string {
+	return "agent_" + escapeNodeID(a.Name())
+}
+
+// escapeNodeID keeps the ASCII letters and digits of the name and replaces
+// the other bytes with their hexadecimal code, prefixed with an underscore.
+// Different names always have different escapes.
+func escapeNodeID(name string) string {
+	var sb strings.Builder
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' {
+			sb.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&sb, "_%02x", c)
+	}
+	return sb.String()
+}
+
+// DOT renders the topology in the Graphviz DOT language.
+func (t *Topology) DOT() string {
+	var sb strings.Builder
+	sb.WriteString("digraph AgentGraph {\n\trankdir=LR;\n")
+	for _, n := range t.Nodes {
+		fmt.Fprintf(&sb, "\t%s [label=%s, shape=%s];\n", n.ID, dotQuote(nodeLabel(n)), dotShapes[n.Kind])
+	}
+	for _, e := range t.Edges {
+		fmt.Fprintf(&sb, "\t%s -> %s%s;\n", e.From, e.To, dotEdgeStyles[e.Kind])
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// Mermaid renders the topology as a Mermaid flowchart.
+func (t *Topology) Mermaid() string {
+	var sb strings.Builder
+	sb.WriteString("flowchart LR\n")
+	for _, n := range t.Nodes {
+		shape := mermaidShapes[n.Kind]
+		fmt.Fprintf(&sb, "\t%s%s%s%s\n", n.ID, shape[0], mermaidQuote(nodeLabel(n)), shape[1])
+	}
+	for _, e := range t.Edges {
+		fmt.Fprintf(&sb, "\t%s %s %s\n", e.From, mermaidArrows[e.Kind], e.To)
+	}
+	return sb.String()
+}
+
+func nodeLabel(n TopologyNode) string {
+	label := n.Label
+	switch n.Kind {
+	case NodeKindTool, NodeKindToolset, NodeKindLLMAgent, NodeKindCustomAgent:
+	default:
+		label += " (" + string(n.Kind) + ")"
+	}
+	if n.Truncated {
+		label += " …"
+	}
+	return label
+}
+
+var dotShapes = map[NodeKind]string{
+	NodeKindLLMAgent:        "ellipse",
+	NodeKindSequentialAgent: "cds",
+	NodeKindParallelAgent:   "parallelogram",
+	NodeKindLoopAgent:       "doublecircle",
+	NodeKindGraphAgent:      "hexagon",
+	NodeKindCustomAgent:     "octagon",
+	NodeKindTool:            "box",
+	NodeKindToolset:         "folder",
+}
+
+var dotEdgeStyles = map[EdgeKind]string{
+	EdgeKindSubAgent: "",
+	EdgeKindTool:     " [arrowhead=none]",
+	EdgeKindTransfer: ` [style=dashed, label="transfer"]`,
+}
+
+// mermaidShapes are the opening and closing delimiters of the node shapes.
+var mermaidShapes = map[NodeKind][2]string{
+	NodeKindLLMAgent:        {"([", "])"},
+	NodeKindSequentialAgent: {"[[", "]]"},
+	NodeKindParallelAgent:   {"[/", "/]"},
+	NodeKindLoopAgent:       {"((", "))"},
+	NodeKindGraphAgent:      {"{", "}"},
+	NodeKindCustomAgent:     {"{{", "}}"},
+	NodeKindTool:            {"[", "]"},
+	NodeKindToolset:         {"[(", ")]"},
+}
+
+var mermaidArrows = map[EdgeKind]string{
+	EdgeKindSubAgent: "-->",
+	EdgeKindTool:     "---",
+	EdgeKindTransfer: "-. transfer .->",
+}
+
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+func mermaidQuote(s string) string {
+	return `"` + strings.NewReplacer(`"`, "#quot;", "\n", " ").Replace(s) + `"`
+}
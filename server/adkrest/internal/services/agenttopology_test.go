@@ -0,0 +1,141 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	agentinternal "google.golang.org/adk/internal/agent"
+	"google.golang.org/adk/tool"
+)
+
+// cyclicAgent is a custom agent whose sub-agents can include its ancestors.
+type cyclicAgent struct {
+	agent.Agent
+	subAgents []agent.Agent
+}
+
+func (a *cyclicAgent) SubAgents() []agent.Agent { return a.subAgents }
+
+func newTransferAgent(t *testing.T, name string, disallowParent, disallowPeers bool, subAgents ...agent.Agent) agent.Agent {
+	t.Helper()
+	a, err := llmagent.New(llmagent.Config{
+		Name:                     name,
+		Model:                    &dummyLLM{},
+		SubAgents:                subAgents,
+		DisallowTransferToParent: disallowParent,
+		DisallowTransferToPeers:  disallowPeers,
+	})
+	if err != nil {
+		t.Fatalf("llmagent.New() failed: %v", err)
+	}
+	return a
+}
+
+func TestAgentTopology_Mermaid(t *testing.T) {
+	step1 := newTestAgent(t, "step-1", "", agentinternal.TypeLLMAgent, nil, []tool.Tool{&mockTool{name: "search"}})
+	step2 := newTestAgent(t, "step_2", "", agentinternal.TypeLLMAgent, nil, nil)
+	pipeline := newTestAgent(t, "pipeline", "", agentinternal.TypeSequentialAgent, []agent.Agent{step1, step2}, nil)
+	billing := newTransferAgent(t, "billing", false, false)
+	support := newTransferAgent(t, "support", true, false)
+	root := newTestAgent(t, "root", "", agentinternal.TypeLLMAgent, []agent.Agent{pipeline, billing, support}, []tool.Tool{&mockTool{name: "search"}})
+
+	want := `flowchart LR
+	agent_root(["root"])
+	agent_root__tool_search["search"]
+	agent_pipeline[["pipeline (sequential)"]]
+	agent_step_2d1(["step-1"])
+	agent_step_2d1__tool_search["search"]
+	agent_step_5f2(["step_2"])
+	agent_billing(["billing"])
+	agent_support(["support"])
+	agent_root --- agent_root__tool_search
+	agent_root --> agent_pipeline
+	agent_pipeline --> agent_step_2d1
+	agent_step_2d1 --- agent_step_2d1__tool_search
+	agent_pipeline --> agent_step_5f2
+	agent_root --> agent_billing
+	agent_root --> agent_support
+	agent_billing -. transfer .-> agent_root
+	agent_billing -. transfer .-> agent_pipeline
+	agent_billing -. transfer .-> agent_support
+	agent_support -. transfer .-> agent_pipeline
+	agent_support -. transfer .-> agent_billing
+`
+	got := AgentTopology(root, 10).Mermaid()
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Mermaid() mismatch (-want +got):\n%s", diff)
+	}
+	// The node IDs only depend on the agent tree.
+	if again := AgentTopology(root, 10).Mermaid(); again != got {
+		t.Errorf("Mermaid() is not stable across calls:\n%s\n%s", got, again)
+	}
+}
+
+func TestAgentTopology_DOT(t *testing.T) {
+	loop := newTestAgent(t, "refine \"draft\"", "", agentinternal.TypeLoopAgent, []agent.Agent{newTestAgent(t, "writer", "", agentinternal.TypeLLMAgent, nil, nil)}, nil)
+
+	want := `digraph AgentGraph {
+	rankdir=LR;
+	agent_refine_20_22draft_22 [label="refine \"draft\" (loop)", shape=doublecircle];
+	agent_writer [label="writer", shape=ellipse];
+	agent_refine_20_22draft_22 -> agent_writer;
+}
+`
+	if diff := cmp.Diff(want, AgentTopology(loop, 10).DOT()); diff != "" {
+		t.Errorf("DOT() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestAgentTopology_Limits(t *testing.T) {
+	leaf := newTestAgent(t, "leaf", "", agentinternal.TypeCustomAgent, nil, nil)
+	custom, err := agent.New(agent.Config{Name: "custom"})
+	if err != nil {
+		t.Fatalf("agent.New() failed: %v", err)
+	}
+	cyclic := &cyclicAgent{Agent: custom}
+	cyclic.subAgents = []agent.Agent{leaf, cyclic}
+
+	t.Run("cycle", func(t *testing.T) {
+		want := &Topology{
+			Nodes: []TopologyNode{
+				{ID: "agent_custom", Label: "custom", Kind: NodeKindCustomAgent},
+				{ID: "agent_leaf", Label: "leaf", Kind: NodeKindLLMAgent},
+			},
+			Edges: []TopologyEdge{
+				{From: "agent_custom", To: "agent_leaf", Kind: EdgeKindSubAgent},
+				{From: "agent_custom", To: "agent_custom", Kind: EdgeKindSubAgent},
+			},
+		}
+		if diff := cmp.Diff(want, AgentTopology(cyclic, 10)); diff != "" {
+			t.Errorf("AgentTopology() mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("depth", func(t *testing.T) {
+		want := &Topology{
+			Nodes: []TopologyNode{
+				{ID: "agent_custom", Label: "custom", Kind: NodeKindCustomAgent, Truncated: true},
+			},
+		}
+		if diff := cmp.Diff(want, AgentTopology(cyclic, 0)); diff != "" {
+			t.Errorf("AgentTopology() mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
@@ -0,0 +1,83 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metrics defines how the ADK REST API reports its metrics.
+//
+// The server only depends on the [Recorder] interface. The
+// [google.golang.org/adk/server/adkrest/metrics/prometheus] package provides
+// a Prometheus implementation; servers which don't import it don't depend on
+// the Prometheus client.
+//
+// Metrics are labeled with app and agent names, but never with user or
+// session IDs, which would make their cardinality unbounded.
+package metrics
+
+import (
+	"net/http"
+	"time"
+)
+
+// Recorder records the metrics of the ADK REST API. Implementations must be
+// safe for concurrent use.
+type Recorder interface {
+	// ObserveRequest records a served HTTP request. route is the name of the
+	// matched route, or an empty string if no route matched.
+	ObserveRequest(route, method string, status int, duration time.Duration)
+	// StreamStarted records that a streaming response started for the app.
+	StreamStarted(appName string)
+	// StreamEnded records that a streaming response started with
+	// StreamStarted ended.
+	StreamEnded(appName string)
+	// ObserveInvocation records a finished invocation.
+	ObserveInvocation(stats InvocationStats)
+	// MemoryIngested records a session of the app added to the memory by
+	// an ingester of [google.golang.org/adk/memory/ingest].
+	MemoryIngested(appName string)
+	// MemoryIngestionFailed records a session of the app dropped by an
+	// ingester without being added to the memory.
+	MemoryIngestionFailed(appName string)
+	// Handler returns the handler exposing the metrics, or nil if they are
+	// not exposed over HTTP.
+	Handler() http.Handler
+}
+
+// InvocationStats describes a finished invocation.
+type InvocationStats struct {
+	// AppName is the name of the app which ran the invocation.
+	AppName string
+	// AgentName is the name of the root agent of the app.
+	AgentName string
+	// Duration is the time from the start of the invocation to the end of
+	// its response.
+	Duration time.Duration
+	// Events is the number of events streamed to the client.
+	Events int
+	// LLMCalls is the number of calls made to the model.
+	LLMCalls int
+}
+
+// Noop returns a [Recorder] which discards all the metrics.
+func Noop() Recorder {
+	return noop{}
+}
+
+type noop struct{}
+
+func (noop) ObserveRequest(string, string, int, time.Duration) {}
+func (noop) StreamStarted(string)                              {}
+func (noop) StreamEnded(string)                                {}
+func (noop) ObserveInvocation(InvocationStats)                 {}
+func (noop) MemoryIngested(string)                             {}
+func (noop) MemoryIngestionFailed(string)                      {}
+func (noop) Handler() http.Handler                             { return nil }
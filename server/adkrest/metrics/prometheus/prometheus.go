@@ -0,0 +1,146 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package prometheus provides a Prometheus [metrics.Recorder] for the ADK
+// REST API.
+package prometheus
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"google.golang.org/adk/server/adkrest/metrics"
+)
+
+// Config configures the Prometheus recorder.
+type Config struct {
+	// Registry is the registry on which the metrics are registered and which
+	// is exposed by the metrics handler.
+	// Optional: if nil, a new registry with the Go runtime and process
+	// collectors is used.
+	Registry *prometheus.Registry
+}
+
+type recorder struct {
+	registry *prometheus.Registry
+
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	activeStreams   *prometheus.GaugeVec
+	invocationTime  *prometheus.HistogramVec
+	events          *prometheus.HistogramVec
+	llmCalls        *prometheus.HistogramVec
+	memoryIngested  *prometheus.CounterVec
+	memoryFailed    *prometheus.CounterVec
+}
+
+// New creates a [metrics.Recorder] which exposes the metrics in the
+// Prometheus text format.
+func New(cfg Config) (metrics.Recorder, error) {
+	registry := cfg.Registry
+	if registry == nil {
+		registry = prometheus.NewRegistry()
+		registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	}
+	countBuckets := prometheus.ExponentialBuckets(1, 2, 10)
+	r := &recorder{
+		registry: registry,
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "adk_http_requests_total",
+			Help: "Number of HTTP requests served, by route, method and status code.",
+		}, []string{"route", "method", "code"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "adk_http_request_duration_seconds",
+			Help:    "Duration of the HTTP requests, including the streaming ones, by route and method.",
+			Buckets: prometheus.ExponentialBuckets(0.005, 4, 10),
+		}, []string{"route", "method"}),
+		activeStreams: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "adk_active_streams",
+			Help: "Number of streaming run responses in progress, by app.",
+		}, []string{"app"}),
+		invocationTime: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "adk_invocation_duration_seconds",
+			Help:    "Duration of the agent invocations, by app and agent.",
+			Buckets: prometheus.ExponentialBuckets(0.1, 2, 12),
+		}, []string{"app", "agent"}),
+		events: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "adk_invocation_events",
+			Help:    "Number of events streamed per invocation, by app and agent.",
+			Buckets: countBuckets,
+		}, []string{"app", "agent"}),
+		llmCalls: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "adk_invocation_llm_calls",
+			Help:    "Number of model calls per invocation, by app and agent.",
+			Buckets: countBuckets,
+		}, []string{"app", "agent"}),
+		memoryIngested: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "adk_memory_ingested_sessions_total",
+			Help: "Number of sessions added to the memory in the background, by app.",
+		}, []string{"app"}),
+		memoryFailed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "adk_memory_ingestion_failures_total",
+			Help: "Number of sessions dropped without being added to the memory, by app.",
+		}, []string{"app"}),
+	}
+	for _, c := range []prometheus.Collector{r.requests, r.requestDuration, r.activeStreams, r.invocationTime, r.events, r.llmCalls, r.memoryIngested, r.memoryFailed} {
+		if err := registry.Register(c); err != nil {
+			return nil, fmt.Errorf("failed to register metrics: %w", err)
+		}
+	}
+	return r, nil
+}
+
+// ObserveRequest implements [metrics.Recorder].
+func (r *recorder) ObserveRequest(route, method string, status int, duration time.Duration) {
+	r.requests.WithLabelValues(route, method, strconv.Itoa(status)).Inc()
+	r.requestDuration.WithLabelValues(route, method).Observe(duration.Seconds())
+}
+
+// StreamStarted implements [metrics.Recorder].
+func (r *recorder) StreamStarted(appName string) {
+	r.activeStreams.WithLabelValues(appName).Inc()
+}
+
+// StreamEnded implements [metrics.Recorder].
+func (r *recorder) StreamEnded(appName string) {
+	r.activeStreams.WithLabelValues(appName).Dec()
+}
+
+// ObserveInvocation implements [metrics.Recorder].
+func (r *recorder) ObserveInvocation(stats metrics.InvocationStats) {
+	r.invocationTime.WithLabelValues(stats.AppName, stats.AgentName).Observe(stats.Duration.Seconds())
+	r.events.WithLabelValues(stats.AppName, stats.AgentName).Observe(float64(stats.Events))
+	r.llmCalls.WithLabelValues(stats.AppName, stats.AgentName).Observe(float64(stats.LLMCalls))
+}
+
+// MemoryIngested implements [metrics.Recorder].
+func (r *recorder) MemoryIngested(appName string) {
+	r.memoryIngested.WithLabelValues(appName).Inc()
+}
+
+// MemoryIngestionFailed implements [metrics.Recorder].
+func (r *recorder) MemoryIngestionFailed(appName string) {
+	r.memoryFailed.WithLabelValues(appName).Inc()
+}
+
+// Handler implements [metrics.Recorder].
+func (r *recorder) Handler() http.Handler {
+	return promhttp.HandlerFor(r.registry, promhttp.HandlerOpts{Registry: r.registry})
+}
@@ -0,0 +1,73 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"google.golang.org/adk/server/adkrest/metrics"
+)
+
+func TestRecorder(t *testing.T) {
+	r, err := New(Config{Registry: prometheus.NewRegistry()})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	r.ObserveRequest("RunSSE", http.MethodPost, http.StatusOK, time.Second)
+	r.StreamStarted("app")
+	r.StreamStarted("app")
+	r.StreamEnded("app")
+	r.ObserveInvocation(metrics.InvocationStats{AppName: "app", AgentName: "root", Duration: 2 * time.Second, Events: 3, LLMCalls: 2})
+	r.MemoryIngested("app")
+	r.MemoryIngested("app")
+	r.MemoryIngestionFailed("app")
+
+	rr := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("metrics handler status = %v, want %v", rr.Code, http.StatusOK)
+	}
+	body := rr.Body.String()
+	for _, want := range []string{
+		`adk_http_requests_total{code="200",method="POST",route="RunSSE"} 1`,
+		`adk_http_request_duration_seconds_count{method="POST",route="RunSSE"} 1`,
+		`adk_active_streams{app="app"} 1`,
+		`adk_invocation_duration_seconds_sum{agent="root",app="app"} 2`,
+		`adk_invocation_events_sum{agent="root",app="app"} 3`,
+		`adk_invocation_llm_calls_sum{agent="root",app="app"} 2`,
+		`adk_memory_ingested_sessions_total{app="app"} 2`,
+		`adk_memory_ingestion_failures_total{app="app"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics output doesn't contain %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestNew_DuplicateRegistration(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	if _, err := New(Config{Registry: registry}); err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	if _, err := New(Config{Registry: registry}); err == nil {
+		t.Errorf("New() on the same registry succeeded, want error")
+	}
+}
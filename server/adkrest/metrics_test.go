@@ -0,0 +1,67 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adkrest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/gorilla/mux"
+
+	"google.golang.org/adk/server/adkrest/metrics"
+)
+
+type request struct {
+	route, method string
+	status        int
+}
+
+type requestRecorder struct {
+	metrics.Recorder
+	requests []request
+}
+
+func (r *requestRecorder) ObserveRequest(route, method string, status int, _ time.Duration) {
+	r.requests = append(r.requests, request{route, method, status})
+}
+
+func TestWithMetrics(t *testing.T) {
+	recorder := &requestRecorder{Recorder: metrics.Noop()}
+	router := mux.NewRouter()
+	router.Methods(http.MethodGet).Path("/apps/{app_name}").Name("GetApp").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if mux.Vars(r)["app_name"] == "missing" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	})
+	router.Use(withMetrics(recorder))
+
+	for _, path := range []string{"/apps/app", "/apps/missing", "/unknown"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	// Requests which don't match any route aren't recorded.
+	want := []request{
+		{route: "GetApp", method: http.MethodGet, status: http.StatusOK},
+		{route: "GetApp", method: http.MethodGet, status: http.StatusNotFound},
+	}
+	if diff := cmp.Diff(want, recorder.requests, cmp.AllowUnexported(request{})); diff != "" {
+		t.Errorf("recorded requests mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adkrest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+// OIDCConfig configures the authenticator returned by [NewOIDCAuthenticator].
+type OIDCConfig struct {
+	// IssuerURL is the issuer of the tokens, e.g.
+	// "https://accounts.google.com". It must match the "iss" claim.
+	IssuerURL string
+	// Audience must be one of the values of the "aud" claim, usually the
+	// client ID of the application.
+	Audience string
+	// JWKSURL is the URL of the signing keys of the issuer.
+	// Optional: if empty, it is read from the OpenID configuration of the
+	// issuer.
+	JWKSURL string
+	// HTTPClient is used to fetch the OpenID configuration and the keys.
+	// Optional: if nil, [http.DefaultClient] is used.
+	HTTPClient *http.Client
+	// KeysRefreshInterval is how often the signing keys are fetched again.
+	// Keys are also fetched when a token is signed with an unknown key.
+	// Optional: if zero, one hour is used.
+	KeysRefreshInterval time.Duration
+}
+
+const (
+	defaultKeysRefreshInterval = time.Hour
+	// minKeysRefreshInterval limits how often tokens signed with unknown keys
+	// can trigger a refresh.
+	minKeysRefreshInterval = time.Minute
+)
+
+var oidcSignatureAlgorithms = []jose.SignatureAlgorithm{
+	jose.RS256, jose.RS384, jose.RS512,
+	jose.ES256, jose.ES384, jose.ES512,
+	jose.PS256, jose.PS384, jose.PS512,
+	jose.EdDSA,
+}
+
+// NewOIDCAuthenticator returns an [Authenticator] which accepts the OIDC
+// bearer tokens signed by the issuer for the audience. The subject of the
+// principal is the "sub" claim, and all the claims of the token are available
+// in [Principal.Claims].
+//
+// The OpenID configuration and the keys of the issuer are fetched before
+// returning, so that a misconfiguration is reported at startup.
+func NewOIDCAuthenticator(ctx context.Context, cfg OIDCConfig) (Authenticator, error) {
+	if cfg.IssuerURL == "" || cfg.Audience == "" {
+		return nil, errors.New("OIDC issuer URL and audience are required")
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+	if cfg.KeysRefreshInterval == 0 {
+		cfg.KeysRefreshInterval = defaultKeysRefreshInterval
+	}
+	if cfg.JWKSURL == "" {
+		var discovery struct {
+			Issuer  string `json:"issuer"`
+			JWKSURI string `json:"jwks_uri"`
+		}
+		url := strings.TrimSuffix(cfg.IssuerURL, "/") + "/.well-known/openid-configuration"
+		if err := fetchJSON(ctx, cfg.HTTPClient, url, &discovery); err != nil {
+			return nil, fmt.Errorf("failed to fetch OpenID configuration: %w", err)
+		}
+		if discovery.Issuer != cfg.IssuerURL {
+			return nil, fmt.Errorf("OpenID configuration issuer %q doesn't match %q", discovery.Issuer, cfg.IssuerURL)
+		}
+		if discovery.JWKSURI == "" {
+			return nil, errors.New("OpenID configuration has no jwks_uri")
+		}
+		cfg.JWKSURL = discovery.JWKSURI
+	}
+	a := &oidcAuthenticator{cfg: cfg}
+	if err := a.refreshKeys(ctx); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+type oidcAuthenticator struct {
+	cfg OIDCConfig
+
+	mu        sync.Mutex
+	keys      *jose.JSONWebKeySet
+	fetchedAt time.Time
+}
+
+func (a *oidcAuthenticator) Authenticate(req *http.Request) (*Principal, error) {
+	token, ok := bearerToken(req)
+	if !ok {
+		return nil, ErrMissingCredentials
+	}
+	parsed, err := jwt.ParseSigned(token, oidcSignatureAlgorithms)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bearer token: %w", err)
+	}
+	var keyID string
+	if len(parsed.Headers) > 0 {
+		keyID = parsed.Headers[0].KeyID
+	}
+	keys, err := a.keySet(req.Context(), keyID)
+	if err != nil {
+		return nil, err
+	}
+	var claims jwt.Claims
+	var allClaims map[string]any
+	if err := parsed.Claims(keys, &claims, &allClaims); err != nil {
+		return nil, fmt.Errorf("invalid bearer token: %w", err)
+	}
+	if claims.Expiry == nil {
+		return nil, errors.New("invalid bearer token: missing expiry")
+	}
+	err = claims.Validate(jwt.Expected{
+		Issuer:      a.cfg.IssuerURL,
+		AnyAudience: jwt.Audience{a.cfg.Audience},
+		Time:        time.Now(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid bearer token: %w", err)
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("invalid bearer token: missing subject")
+	}
+	return &Principal{Subject: claims.Subject, Claims: allClaims}, nil
+}
+
+// keySet returns the signing keys of the issuer, fetching them again if they
+// are stale or don't contain keyID.
+func (a *oidcAuthenticator) keySet(ctx context.Context, keyID string) (*jose.JSONWebKeySet, error) {
+	a.mu.Lock()
+	keys, age := a.keys, time.Since(a.fetchedAt)
+	a.mu.Unlock()
+	stale := age > a.cfg.KeysRefreshInterval
+	unknown := keyID != "" && len(keys.Key(keyID)) == 0 && age > minKeysRefreshInterval
+	if stale || unknown {
+		if err := a.refreshKeys(ctx); err != nil {
+			if stale {
+				return nil, err
+			}
+			// The current keys are still valid, the token will be rejected.
+			return keys, nil
+		}
+		a.mu.Lock()
+		keys = a.keys
+		a.mu.Unlock()
+	}
+	return keys, nil
+}
+
+func (a *oidcAuthenticator) refreshKeys(ctx context.Context) error {
+	var keys jose.JSONWebKeySet
+	if err := fetchJSON(ctx, a.cfg.HTTPClient, a.cfg.JWKSURL, &keys); err != nil {
+		return fmt.Errorf("failed to fetch OIDC signing keys: %w", err)
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.keys, a.fetchedAt = &keys, time.Now()
+	return nil
+}
+
+func fetchJSON(ctx context.Context, client *http.Client, url string, dest any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: unexpected status %s", url, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(dest); err != nil {
+		return fmt.Errorf("GET %s: failed to decode response: %w", url, err)
+	}
+	return nil
+}
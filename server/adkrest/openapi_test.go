@@ -0,0 +1,108 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adkrest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"google.golang.org/adk/cmd/launcher"
+	"google.golang.org/adk/server/adkrest/internal/openapi"
+	"google.golang.org/adk/server/adkrest/metrics"
+)
+
+// exposedRecorder is a recorder which exposes its metrics.
+type exposedRecorder struct {
+	metrics.Recorder
+}
+
+func (exposedRecorder) Handler() http.Handler {
+	return http.NotFoundHandler()
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	tc := []struct {
+		name        string
+		opts        Options
+		wantMetrics bool
+	}{
+		{
+			name: "default routes",
+		},
+		{
+			name:        "metrics enabled",
+			opts:        Options{Metrics: exposedRecorder{Recorder: metrics.Noop()}},
+			wantMetrics: true,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandlerWithOptions(&launcher.Config{}, tt.opts)
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+			if rr.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", rr.Code, http.StatusOK)
+			}
+			if got := rr.Header().Get("Content-Type"); got != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", got)
+			}
+			var doc openapi.Document
+			if err := json.Unmarshal(rr.Body.Bytes(), &doc); err != nil {
+				t.Fatalf("malformed document: %v", err)
+			}
+			if doc.OpenAPI != openapi.Version {
+				t.Errorf("openapi = %q, want %q", doc.OpenAPI, openapi.Version)
+			}
+
+			var ids []string
+			for _, item := range doc.Paths {
+				for _, op := range item {
+					ids = append(ids, op.OperationID)
+				}
+			}
+			for _, id := range []string{"RunAgent", "RunAgentSse", "GetSession", "ListEvents", "UploadArtifact", "Readyz", "GetOpenAPI"} {
+				if !slices.Contains(ids, id) {
+					t.Errorf("operation %q is missing", id)
+				}
+			}
+			// The eval routes are not implemented.
+			if slices.Contains(ids, "ListEvalSets") {
+				t.Errorf("unimplemented eval routes are documented")
+			}
+			if got := slices.Contains(ids, "Metrics"); got != tt.wantMetrics {
+				t.Errorf("metrics documented = %v, want %v", got, tt.wantMetrics)
+			}
+
+			stream := doc.Paths["/run_sse"]["post"].Responses["200"].Content
+			wantStream := map[string]*openapi.MediaType{
+				"text/event-stream":    {Schema: &openapi.Schema{Ref: "#/components/schemas/Event"}},
+				"application/x-ndjson": {Schema: &openapi.Schema{Ref: "#/components/schemas/Event"}},
+			}
+			if diff := cmp.Diff(wantStream, stream); diff != "" {
+				t.Errorf("run_sse response mismatch (-want +got):\n%s", diff)
+			}
+			for _, name := range []string{"Session", "Event", "RunAgentRequest", "ArtifactUpload", "Content", "ErrorResponse"} {
+				if _, ok := doc.Components.Schemas[name]; !ok {
+					t.Errorf("schema %q is missing", name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ratelimit limits the rate at which the clients of the ADK REST API
+// can start agent runs.
+//
+// The server only depends on the [Limiter] interface, so that deployments
+// with several replicas can share the limits, e.g. in Redis. [NewInMemory]
+// returns a limiter local to the process.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Key identifies a bucket of tokens.
+type Key struct {
+	AppName string
+	UserID  string
+}
+
+// Limiter decides whether a run can start.
+type Limiter interface {
+	// Allow consumes a token from the bucket of key. If the bucket is empty,
+	// it returns false and how long to wait until a token is available.
+	Allow(ctx context.Context, key Key) (allowed bool, retryAfter time.Duration, err error)
+}
+
+// Config configures the limiter returned by [NewInMemory].
+type Config struct {
+	// RequestsPerMinute is the rate at which the buckets are refilled.
+	RequestsPerMinute float64
+	// Burst is the capacity of the buckets, i.e. how many runs can start at
+	// once after a period of inactivity.
+	// Optional: if zero, 1 is used.
+	Burst int
+	// IdleTimeout is how long a bucket is kept after its last use. Idle
+	// buckets are removed periodically.
+	// Optional: if zero, 10 minutes is used.
+	IdleTimeout time.Duration
+}
+
+const defaultIdleTimeout = 10 * time.Minute
+
+// InMemory is a token-bucket [Limiter] which keeps the buckets in memory.
+type InMemory struct {
+	cfg Config
+	now func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[Key]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	limiter  *rate.Limiter
+	lastUsed time.Time
+}
+
+// NewInMemory returns a limiter which keeps one token bucket per key in
+// memory.
+func NewInMemory(cfg Config) (*InMemory, error) {
+	if cfg.RequestsPerMinute <= 0 {
+		return nil, errors.New("requests per minute must be positive")
+	}
+	if cfg.Burst == 0 {
+		cfg.Burst = 1
+	}
+	if cfg.IdleTimeout == 0 {
+		cfg.IdleTimeout = defaultIdleTimeout
+	}
+	return &InMemory{cfg: cfg, now: time.Now, buckets: make(map[Key]*bucket)}, nil
+}
+
+// Allow implements [Limiter].
+func (l *InMemory) Allow(ctx context.Context, key Key) (bool, time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{limiter: rate.NewLimiter(rate.Limit(l.cfg.RequestsPerMinute/60), l.cfg.Burst)}
+		l.buckets[key] = b
+	}
+	b.lastUsed = now
+	r := b.limiter.ReserveN(now, 1)
+	if delay := r.DelayFrom(now); delay > 0 {
+		r.CancelAt(now)
+		return false, delay, nil
+	}
+	return true, 0, nil
+}
+
+// sweep removes the idle buckets, at most once per idle timeout. Dropping a
+// bucket is safe once it has had the time to refill.
+func (l *InMemory) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.cfg.IdleTimeout {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if now.Sub(b.lastUsed) >= l.cfg.IdleTimeout {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+var _ Limiter = (*InMemory)(nil)
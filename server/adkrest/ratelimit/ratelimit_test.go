@@ -0,0 +1,97 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestInMemory_Allow(t *testing.T) {
+	l, err := NewInMemory(Config{RequestsPerMinute: 60, Burst: 2})
+	if err != nil {
+		t.Fatalf("NewInMemory() failed: %v", err)
+	}
+	now := time.Unix(1000, 0)
+	l.now = func() time.Time { return now }
+
+	alice := Key{AppName: "app", UserID: "alice"}
+	bob := Key{AppName: "app", UserID: "bob"}
+	steps := []struct {
+		name           string
+		key            Key
+		advance        time.Duration
+		wantAllowed    bool
+		wantRetryAfter time.Duration
+	}{
+		{name: "first token of burst", key: alice, wantAllowed: true},
+		{name: "second token of burst", key: alice, wantAllowed: true},
+		{name: "bucket empty", key: alice, wantRetryAfter: time.Second},
+		{name: "other user has its own bucket", key: bob, wantAllowed: true},
+		{name: "partially refilled", key: alice, advance: 400 * time.Millisecond, wantRetryAfter: 600 * time.Millisecond},
+		{name: "refilled", key: alice, advance: 600 * time.Millisecond, wantAllowed: true},
+		{name: "empty again", key: alice, wantRetryAfter: time.Second},
+	}
+	for _, s := range steps {
+		now = now.Add(s.advance)
+		allowed, retryAfter, err := l.Allow(context.Background(), s.key)
+		if err != nil {
+			t.Fatalf("%s: Allow() failed: %v", s.name, err)
+		}
+		if allowed != s.wantAllowed || retryAfter != s.wantRetryAfter {
+			t.Errorf("%s: Allow() = (%v, %v), want (%v, %v)", s.name, allowed, retryAfter, s.wantAllowed, s.wantRetryAfter)
+		}
+	}
+}
+
+func TestInMemory_Sweep(t *testing.T) {
+	l, err := NewInMemory(Config{RequestsPerMinute: 1, IdleTimeout: time.Minute})
+	if err != nil {
+		t.Fatalf("NewInMemory() failed: %v", err)
+	}
+	now := time.Unix(1000, 0)
+	l.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	idle := Key{AppName: "app", UserID: "idle"}
+	active := Key{AppName: "app", UserID: "active"}
+	for _, key := range []Key{idle, active} {
+		if _, _, err := l.Allow(ctx, key); err != nil {
+			t.Fatalf("Allow() failed: %v", err)
+		}
+	}
+	now = now.Add(30 * time.Second)
+	if _, _, err := l.Allow(ctx, active); err != nil {
+		t.Fatalf("Allow() failed: %v", err)
+	}
+	now = now.Add(40 * time.Second)
+	if _, _, err := l.Allow(ctx, active); err != nil {
+		t.Fatalf("Allow() failed: %v", err)
+	}
+
+	if _, ok := l.buckets[idle]; ok {
+		t.Errorf("idle bucket was not removed")
+	}
+	if _, ok := l.buckets[active]; !ok {
+		t.Errorf("active bucket was removed")
+	}
+}
+
+func TestNewInMemory_InvalidConfig(t *testing.T) {
+	if _, err := NewInMemory(Config{}); err == nil {
+		t.Errorf("NewInMemory() succeeded, want an error")
+	}
+}
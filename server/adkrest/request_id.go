@@ -0,0 +1,74 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adkrest
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"google.golang.org/adk/server/adkrest/internal/logging"
+	"google.golang.org/adk/server/adkrest/internal/requestid"
+)
+
+// RequestIDHeader is the header used to read and echo back the request ID.
+const RequestIDHeader = "X-Request-Id"
+
+// maxRequestIDLength bounds the length of client-provided request IDs.
+const maxRequestIDLength = 128
+
+// RequestIDFromContext returns the ID of the REST API request being served,
+// or an empty string if ctx doesn't belong to such a request.
+//
+// The ID is available in every context derived from the request context,
+// including the invocation context passed to agents, callbacks and tools.
+func RequestIDFromContext(ctx context.Context) string {
+	return requestid.FromContext(ctx)
+}
+
+// withRequestID reads the request ID from the X-Request-Id header, or
+// generates a new one, stores it on the request context and echoes it back
+// in the response header. The logger on the request context is decorated with
+// the ID, so that all log lines of the request can be correlated.
+func withRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !isValidRequestID(id) {
+			id = uuid.NewString()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		trace.SpanFromContext(r.Context()).SetAttributes(attribute.String("http.request.id", id))
+		ctx := requestid.ToContext(r.Context(), id)
+		ctx = logging.ToContext(ctx, logging.FromContext(ctx).With("request_id", id))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// isValidRequestID reports whether a client-provided request ID can be used
+// as is. IDs which could break log lines or headers are replaced.
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		if r < 0x21 || r > 0x7e {
+			return false
+		}
+	}
+	return true
+}
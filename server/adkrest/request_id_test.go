@@ -0,0 +1,116 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adkrest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/cmd/launcher"
+	"google.golang.org/adk/session"
+)
+
+func TestWithRequestID(t *testing.T) {
+	tc := []struct {
+		name      string
+		header    string
+		wantSame  bool
+		wantAnyID bool
+	}{
+		{name: "client provided ID", header: "abc-123", wantSame: true},
+		{name: "missing ID", header: "", wantAnyID: true},
+		{name: "ID with control characters", header: "abc\x01def", wantAnyID: true},
+		{name: "too long ID", header: strings.Repeat("a", maxRequestIDLength+1), wantAnyID: true},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotCtxID string
+			handler := withRequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotCtxID = RequestIDFromContext(r.Context())
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set(RequestIDHeader, tt.header)
+			}
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			gotHeaderID := rr.Header().Get(RequestIDHeader)
+			if gotHeaderID == "" || gotHeaderID != gotCtxID {
+				t.Fatalf("response header ID = %q, context ID = %q, want equal and non-empty", gotHeaderID, gotCtxID)
+			}
+			if tt.wantSame && gotHeaderID != tt.header {
+				t.Errorf("request ID = %q, want %q", gotHeaderID, tt.header)
+			}
+			if tt.wantAnyID && gotHeaderID == tt.header {
+				t.Errorf("request ID = %q, want a generated one", gotHeaderID)
+			}
+		})
+	}
+}
+
+func TestRequestIDPropagatesToInvocationContext(t *testing.T) {
+	var gotID string
+	a, err := agent.New(agent.Config{
+		Name: "app",
+		Run: func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			gotID = RequestIDFromContext(ctx)
+			return func(yield func(*session.Event, error) bool) {}
+		},
+	})
+	if err != nil {
+		t.Fatalf("agent.New() failed: %v", err)
+	}
+	sessionService := session.InMemoryService()
+	if _, err := sessionService.Create(context.Background(), &session.CreateRequest{AppName: "app", UserID: "user", SessionID: "session"}); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	handler := NewHandlerWithOptions(&launcher.Config{
+		SessionService: sessionService,
+		AgentLoader:    agent.NewSingleLoader(a),
+	}, Options{})
+
+	body, err := json.Marshal(map[string]any{
+		"appName":    "app",
+		"userId":     "user",
+		"sessionId":  "session",
+		"newMessage": genai.NewContentFromText("hi", genai.RoleUser),
+	})
+	if err != nil {
+		t.Fatalf("json.Marshal() failed: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/run", bytes.NewReader(body))
+	req.Header.Set(RequestIDHeader, "req-42")
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d, body: %s", rr.Code, http.StatusOK, rr.Body)
+	}
+	if gotID != "req-42" {
+		t.Errorf("RequestIDFromContext() in agent = %q, want %q", gotID, "req-42")
+	}
+}
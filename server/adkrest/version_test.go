@@ -0,0 +1,107 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adkrest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"google.golang.org/adk/artifact"
+	"google.golang.org/adk/cmd/launcher"
+	"google.golang.org/adk/internal/version"
+	"google.golang.org/adk/server/adkrest/internal/models"
+	"google.golang.org/adk/session"
+)
+
+func TestVersionEndpoint(t *testing.T) {
+	tc := []struct {
+		name         string
+		config       *launcher.Config
+		opts         Options
+		wantFeatures map[string]any
+	}{
+		{
+			name:   "defaults",
+			config: &launcher.Config{},
+			wantFeatures: map[string]any{
+				"streaming":      false,
+				"eval":           false,
+				"artifacts":      "none",
+				"sessions":       "none",
+				"memory":         "none",
+				"auth":           false,
+				"metrics":        false,
+				"rateLimit":      false,
+				"asyncCallbacks": false,
+			},
+		},
+		{
+			name: "configured",
+			config: &launcher.Config{
+				SessionService:  session.InMemoryService(),
+				ArtifactService: artifact.InMemoryService(),
+			},
+			opts: Options{
+				SSEWriteTimeout: time.Minute,
+				Auth:            &AuthOptions{Authenticator: NewAPIKeyAuthenticator(map[string]string{"key": "alice"})},
+			},
+			wantFeatures: map[string]any{
+				"streaming":      true,
+				"eval":           false,
+				"artifacts":      "artifact.inMemoryService",
+				"sessions":       "session.inMemoryService",
+				"memory":         "none",
+				"auth":           true,
+				"metrics":        false,
+				"rateLimit":      false,
+				"asyncCallbacks": false,
+			},
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandlerWithOptions(tt.config, tt.opts)
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/version", nil)
+			req.Header.Set(APIKeyHeader, "key")
+			h.ServeHTTP(rr, req)
+			if rr.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d: %s", rr.Code, http.StatusOK, rr.Body)
+			}
+			var got models.VersionInfo
+			if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+				t.Fatalf("malformed response: %v", err)
+			}
+			build := version.Build()
+			want := models.VersionInfo{
+				Version:       version.Version,
+				ModuleVersion: build.ModuleVersion,
+				Revision:      build.Revision,
+				RevisionTime:  build.RevisionTime,
+				Modified:      build.Modified,
+				GoVersion:     build.GoVersion,
+				Features:      tt.wantFeatures,
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("version mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
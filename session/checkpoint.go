@@ -0,0 +1,23 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+// CheckpointMetadataKey is the key of the CustomMetadata of the checkpoint
+// events of the invocations run with checkpointing, see the Checkpointing
+// option of the run configuration. The checkpoint events, without content,
+// record the start and the end of the invocations and the progress of their
+// workflow agents, so that an interrupted invocation can be resumed by the
+// runner.
+const CheckpointMetadataKey = "adk_checkpoint"
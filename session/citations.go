@@ -0,0 +1,100 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"sort"
+	"strings"
+
+	"google.golang.org/genai"
+)
+
+// Citation is a span of the text of an event supported by the sources of its
+// grounding metadata, e.g. the web pages found by the Google Search tool.
+type Citation struct {
+	// StartIndex and EndIndex are the byte offsets of the span in the text
+	// of the event, see [Event.Citations].
+	StartIndex, EndIndex int
+	// Text is the text of the span.
+	Text string
+	// Sources are the sources supporting the span.
+	Sources []CitationSource
+}
+
+// CitationSource is a source supporting a citation.
+type CitationSource struct {
+	URI   string
+	Title string
+}
+
+// Citations returns the citations of the grounding metadata of the event, in
+// the order of their spans in the text of the event: the concatenation of
+// the text parts of its content, except thoughts. The supports whose segment
+// isn't in the text are skipped.
+func (e *Event) Citations() []Citation {
+	if e.GroundingMetadata == nil || e.Content == nil {
+		return nil
+	}
+	var text strings.Builder
+	// offsets are the offsets of the text parts in the text.
+	offsets := make(map[int]int, len(e.Content.Parts))
+	for i, part := range e.Content.Parts {
+		if part.Text != "" && !part.Thought {
+			offsets[i] = text.Len()
+			text.WriteString(part.Text)
+		}
+	}
+
+	var citations []Citation
+	chunks := e.GroundingMetadata.GroundingChunks
+	for _, support := range e.GroundingMetadata.GroundingSupports {
+		segment := support.Segment
+		if segment == nil {
+			continue
+		}
+		offset, ok := offsets[int(segment.PartIndex)]
+		if !ok {
+			continue
+		}
+		part := e.Content.Parts[segment.PartIndex].Text
+		start, end := int(segment.StartIndex), int(segment.EndIndex)
+		if start < 0 || start >= end || end > len(part) {
+			continue
+		}
+		citation := Citation{StartIndex: offset + start, EndIndex: offset + end, Text: part[start:end]}
+		for _, i := range support.GroundingChunkIndices {
+			if i >= 0 && int(i) < len(chunks) {
+				if source, ok := citationSource(chunks[i]); ok {
+					citation.Sources = append(citation.Sources, source)
+				}
+			}
+		}
+		citations = append(citations, citation)
+	}
+	sort.SliceStable(citations, func(i, j int) bool { return citations[i].StartIndex < citations[j].StartIndex })
+	return citations
+}
+
+func citationSource(chunk *genai.GroundingChunk) (CitationSource, bool) {
+	switch {
+	case chunk.Web != nil:
+		return CitationSource{URI: chunk.Web.URI, Title: chunk.Web.Title}, true
+	case chunk.RetrievedContext != nil:
+		return CitationSource{URI: chunk.RetrievedContext.URI, Title: chunk.RetrievedContext.Title}, true
+	case chunk.Maps != nil:
+		return CitationSource{URI: chunk.Maps.URI, Title: chunk.Maps.Title}, true
+	}
+	return CitationSource{}, false
+}
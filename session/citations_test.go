@@ -0,0 +1,68 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+)
+
+func TestEventCitations(t *testing.T) {
+	event := &session.Event{LLMResponse: model.LLMResponse{
+		Content: &genai.Content{Role: genai.RoleModel, Parts: []*genai.Part{
+			{Text: "Let me search.", Thought: true},
+			{Text: "Paris is the capital of France. "},
+			{Text: "It has 2 million inhabitants."},
+		}},
+		GroundingMetadata: &genai.GroundingMetadata{
+			GroundingChunks: []*genai.GroundingChunk{
+				{Web: &genai.GroundingChunkWeb{URI: "https://a.example", Title: "a.example"}},
+				{RetrievedContext: &genai.GroundingChunkRetrievedContext{URI: "gs://docs/paris.pdf", Title: "Paris"}},
+			},
+			GroundingSupports: []*genai.GroundingSupport{
+				{Segment: &genai.Segment{PartIndex: 2, StartIndex: 7, EndIndex: 28}, GroundingChunkIndices: []int32{1}},
+				{Segment: &genai.Segment{PartIndex: 1, EndIndex: 31}, GroundingChunkIndices: []int32{0, 1, 5}},
+				// Out of the text.
+				{Segment: &genai.Segment{PartIndex: 0, EndIndex: 4}, GroundingChunkIndices: []int32{0}},
+				{Segment: &genai.Segment{PartIndex: 2, StartIndex: 7, EndIndex: 100}, GroundingChunkIndices: []int32{0}},
+			},
+		},
+	}}
+	want := []session.Citation{
+		{
+			StartIndex: 0,
+			EndIndex:   31,
+			Text:       "Paris is the capital of France.",
+			Sources:    []session.CitationSource{{URI: "https://a.example", Title: "a.example"}, {URI: "gs://docs/paris.pdf", Title: "Paris"}},
+		},
+		{
+			StartIndex: 39,
+			EndIndex:   60,
+			Text:       "2 million inhabitants",
+			Sources:    []session.CitationSource{{URI: "gs://docs/paris.pdf", Title: "Paris"}},
+		},
+	}
+	if diff := cmp.Diff(want, event.Citations()); diff != "" {
+		t.Errorf("Citations() mismatch (-want +got):\n%s", diff)
+	}
+	if got := (&session.Event{}).Citations(); got != nil {
+		t.Errorf("Citations() of an event without grounding metadata = %v, want nil", got)
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+)
+
+// DefaultKeepRecent is the [CompactionConfig.KeepRecent] used if it is zero.
+const DefaultKeepRecent = 20
+
+// Summarizer returns the content of the event which replaces the events,
+// typically a summary written by a model.
+type Summarizer func(ctx context.Context, events []*Event) (*genai.Content, error)
+
+// CompactionConfig configures a compaction of the events of a session.
+type CompactionConfig struct {
+	// Summarize summarizes the runs of compacted events.
+	Summarize Summarizer
+	// KeepRecent is the number of most recent events which are never
+	// compacted.
+	// Optional: if zero, [DefaultKeepRecent] is used.
+	KeepRecent int
+	// Archive keeps the compacted events in the storage of the service,
+	// hidden from Get, instead of deleting them.
+	Archive bool
+}
+
+// EventCompaction marks the summary event which replaced compacted events.
+type EventCompaction struct {
+	// StartTime and EndTime are the timestamps of the first and the last
+	// compacted events.
+	StartTime time.Time
+	EndTime   time.Time
+	// CompactedEvents is the number of events replaced by the summary.
+	CompactedEvents int
+}
+
+// CompactRequest represents a request to compact the events of a session.
+type CompactRequest struct {
+	AppName   string
+	UserID    string
+	SessionID string
+}
+
+// CompactResponse represents a response from [Compactor.Compact].
+type CompactResponse struct {
+	// Session is the compacted session.
+	Session Session
+	// CompactedEvents is the number of events replaced by summaries.
+	CompactedEvents int
+}
+
+// Compactor is implemented by the services which can compact the events of
+// the sessions. Compact replaces every run of old events with a single
+// summary event, by the user, whose [EventActions.Compaction] describes the
+// run. The [CompactionConfig.KeepRecent] most recent events, and the events
+// with state or artifact deltas, are kept: they split the runs. The last
+// update time of the session doesn't change.
+//
+// Compact fails with [ErrConcurrentModification] if the session was
+// compacted concurrently.
+type Compactor interface {
+	Compact(ctx context.Context, req *CompactRequest, cfg CompactionConfig) (*CompactResponse, error)
+}
+
+// Compaction is a run of events replaced by a summary event.
+type Compaction struct {
+	// Events are the compacted events, in their order in the session.
+	Events []*Event
+	// Summary replaces the events, at the position of the first one.
+	Summary *Event
+}
+
+// Compactions selects the runs of events to compact among the events of a
+// session, in their order, and summarizes them. The services which
+// implement [Compactor] call it.
+func (c CompactionConfig) Compactions(ctx context.Context, events []*Event) ([]Compaction, error) {
+	if c.Summarize == nil {
+		return nil, errors.New("summarizer is required")
+	}
+	if c.KeepRecent < 0 {
+		return nil, fmt.Errorf("keep recent must not be negative, got %d", c.KeepRecent)
+	}
+	keepRecent := c.KeepRecent
+	if keepRecent == 0 {
+		keepRecent = DefaultKeepRecent
+	}
+
+	var runs [][]*Event
+	var run []*Event
+	for _, event := range events[:max(len(events)-keepRecent, 0)] {
+		if len(event.Actions.StateDelta) > 0 || len(event.Actions.ArtifactDelta) > 0 {
+			runs, run = appendRun(runs, run), nil
+			continue
+		}
+		run = append(run, event)
+	}
+	runs = appendRun(runs, run)
+
+	compactions := make([]Compaction, 0, len(runs))
+	for _, run := range runs {
+		content, err := c.Summarize(ctx, run)
+		if err != nil {
+			return nil, fmt.Errorf("failed to summarize events: %w", err)
+		}
+		first, last := run[0], run[len(run)-1]
+		compactions = append(compactions, Compaction{
+			Events: run,
+			Summary: &Event{
+				ID:           uuid.NewString(),
+				InvocationID: first.InvocationID,
+				Author:       "user",
+				Timestamp:    first.Timestamp,
+				LLMResponse:  model.LLMResponse{Content: content},
+				Actions: EventActions{
+					Compaction: &EventCompaction{
+						StartTime:       first.Timestamp,
+						EndTime:         last.Timestamp,
+						CompactedEvents: len(run),
+					},
+				},
+			},
+		})
+	}
+	return compactions, nil
+}
+
+// appendRun appends the run to the runs, if it has more than one event:
+// replacing a single event with its summary would not shorten the session.
+func appendRun(runs [][]*Event, run []*Event) [][]*Event {
+	if len(run) < 2 {
+		return runs
+	}
+	return append(runs, run)
+}
@@ -0,0 +1,44 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session_test
+
+import (
+	"testing"
+
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/session/sessiontest"
+)
+
+func TestInMemoryService_Conformance(t *testing.T) {
+	sessiontest.RunConformance(t, func(t *testing.T) session.Service {
+		return session.InMemoryService()
+	})
+}
+
+func TestInMemoryService_Expiration(t *testing.T) {
+	sessiontest.RunExpiration(t, func(t *testing.T, cfg session.ExpirationConfig) session.Service {
+		s, err := session.NewInMemoryService(session.InMemoryConfig{Expiration: cfg})
+		if err != nil {
+			t.Fatalf("NewInMemoryService() error = %v", err)
+		}
+		return s
+	})
+}
+
+func TestInMemoryService_Compaction(t *testing.T) {
+	sessiontest.RunCompaction(t, func(t *testing.T) session.Service {
+		return session.InMemoryService()
+	})
+}
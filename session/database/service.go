@@ -24,7 +24,10 @@ import (
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
+	"google.golang.org/adk/internal/sessioninternal"
+	"google.golang.org/adk/internal/sessionutils"
 	"google.golang.org/adk/session"
 )
 
@@ -82,24 +85,24 @@ func (s *databaseService) Create(ctx context.Context, req *session.CreateRequest
 	if stateMap == nil {
 		stateMap = make(map[string]any)
 	}
-	val := &localSession{
-		appName:   req.AppName,
-		userID:    req.UserID,
-		sessionID: sessionID,
-		state:     stateMap,
-		updatedAt: time.Now(),
-	}
+	val := sessioninternal.NewStoredSession(sessioninternal.StoredSessionConfig{
+		AppName:   req.AppName,
+		UserID:    req.UserID,
+		SessionID: sessionID,
+		State:     stateMap,
+		UpdatedAt: time.Now(),
+	})
 	createdSession, err := createStorageSession(val)
 	if err != nil {
 		return nil, err
 	}
 
 	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		storageApp, err := fetchStorageAppState(tx, req.AppName)
+		storageApp, err := fetchStorageAppState(forUpdate(tx), req.AppName)
 		if err != nil {
 			return fmt.Errorf("error on create session: %w", err)
 		}
-		storageUser, err := fetchStorageUserState(tx, req.AppName, req.UserID)
+		storageUser, err := fetchStorageUserState(forUpdate(tx), req.AppName, req.UserID)
 		if err != nil {
 			return fmt.Errorf("error on create session: %w", err)
 		}
@@ -125,8 +128,8 @@ func (s *databaseService) Create(ctx context.Context, req *session.CreateRequest
 			return fmt.Errorf("error creating session on database: %w", err)
 		}
 
-		val.state = mergeStates(storageApp.State, storageUser.State, sessionState)
-		val.updatedAt = createdSession.UpdateTime
+		val.SetState(mergeStates(storageApp.State, storageUser.State, sessionState))
+		val.SetLastUpdateTime(createdSession.UpdateTime)
 		return nil
 	})
 	if err != nil {
@@ -195,7 +198,7 @@ func (s *databaseService) Get(ctx context.Context, req *session.GetRequest) (*se
 	}
 
 	responseSession, err := createSessionFromStorageSession(&foundSession)
-	responseSession.state = mergeStates(storageApp.State, storageUser.State, responseSession.state)
+	responseSession.SetState(mergeStates(storageApp.State, storageUser.State, responseSession.StateMap()))
 	if err != nil {
 		return nil, fmt.Errorf("failed to map storage object: %w", err)
 	}
@@ -211,7 +214,7 @@ func (s *databaseService) Get(ctx context.Context, req *session.GetRequest) (*se
 		}
 		responseEvents = append(responseEvents, evt)
 	}
-	responseSession.events = responseEvents
+	responseSession.SetEvents(responseEvents)
 
 	return &session.GetResponse{
 		Session: responseSession,
@@ -237,6 +240,20 @@ func (s *databaseService) List(ctx context.Context, req *session.ListRequest) (*
 		})
 	}
 
+	var offset int
+	if req.PageSize > 0 || req.PageToken != "" {
+		var err error
+		offset, err = sessionutils.DecodePageToken(req.PageToken)
+		if err != nil {
+			return nil, err
+		}
+		listQuery = listQuery.Order("update_time DESC").Order("id ASC").Offset(offset)
+		if req.PageSize > 0 {
+			// Fetch one more session to know whether there is a next page.
+			listQuery = listQuery.Limit(req.PageSize + 1)
+		}
+	}
+
 	err := listQuery.Find(&foundSessions).Error
 	if err != nil {
 		// Specifically check if the error is "record not found".
@@ -269,6 +286,12 @@ func (s *databaseService) List(ctx context.Context, req *session.ListRequest) (*
 		}
 	}
 
+	nextPageToken := ""
+	if req.PageSize > 0 && len(foundSessions) > req.PageSize {
+		foundSessions = foundSessions[:req.PageSize]
+		nextPageToken = sessionutils.EncodePageToken(offset + req.PageSize)
+	}
+
 	// Create response sessions, transform the storageSessions into
 	responseSessions := make([]session.Session, 0, len(foundSessions))
 	for _, storage := range foundSessions {
@@ -283,15 +306,94 @@ func (s *databaseService) List(ctx context.Context, req *session.ListRequest) (*
 		if !ok {
 			userState = &storageUserState{AppName: appName, UserID: userID, State: make(map[string]any)}
 		}
-		sess.state = mergeStates(storageApp.State, userState.State, sess.state)
+		sess.SetState(mergeStates(storageApp.State, userState.State, sess.StateMap()))
 		responseSessions = append(responseSessions, sess)
 	}
 
 	return &session.ListResponse{
-		Sessions: responseSessions,
+		Sessions:      responseSessions,
+		NextPageToken: nextPageToken,
 	}, nil
 }
 
+// Ping checks that the database is reachable.
+func (s *databaseService) Ping(ctx context.Context) error {
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get database connection: %w", err)
+	}
+	if err := sqlDB.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+	return nil
+}
+
+// VersionChecking implements session.VersionChecker: AppendEvent rejects
+// the sessions older than the stored ones.
+func (s *databaseService) VersionChecking() bool {
+	return true
+}
+
+// GetAppState returns the state of the app, implements session.StateService.
+func (s *databaseService) GetAppState(ctx context.Context, req *session.GetAppStateRequest) (*session.GetAppStateResponse, error) {
+	if req.AppName == "" {
+		return nil, fmt.Errorf("app_name is required, got app_name: %q", req.AppName)
+	}
+	storageApp, err := fetchStorageAppState(s.db.WithContext(ctx), req.AppName)
+	if err != nil {
+		return nil, err
+	}
+	return &session.GetAppStateResponse{State: storageApp.State}, nil
+}
+
+// UpdateAppState merges the delta into the state of the app, implements session.StateService.
+func (s *databaseService) UpdateAppState(ctx context.Context, req *session.UpdateAppStateRequest) error {
+	if req.AppName == "" {
+		return fmt.Errorf("app_name is required, got app_name: %q", req.AppName)
+	}
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		storageApp, err := fetchStorageAppState(forUpdate(tx), req.AppName)
+		if err != nil {
+			return err
+		}
+		maps.Copy(storageApp.State, req.Delta)
+		if err := tx.Save(storageApp).Error; err != nil {
+			return fmt.Errorf("failed to save app state: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetUserState returns the state of the user, implements session.StateService.
+func (s *databaseService) GetUserState(ctx context.Context, req *session.GetUserStateRequest) (*session.GetUserStateResponse, error) {
+	if req.AppName == "" || req.UserID == "" {
+		return nil, fmt.Errorf("app_name and user_id are required, got app_name: %q, user_id: %q", req.AppName, req.UserID)
+	}
+	storageUser, err := fetchStorageUserState(s.db.WithContext(ctx), req.AppName, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return &session.GetUserStateResponse{State: storageUser.State}, nil
+}
+
+// UpdateUserState merges the delta into the state of the user, implements session.StateService.
+func (s *databaseService) UpdateUserState(ctx context.Context, req *session.UpdateUserStateRequest) error {
+	if req.AppName == "" || req.UserID == "" {
+		return fmt.Errorf("app_name and user_id are required, got app_name: %q, user_id: %q", req.AppName, req.UserID)
+	}
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		storageUser, err := fetchStorageUserState(forUpdate(tx), req.AppName, req.UserID)
+		if err != nil {
+			return err
+		}
+		maps.Copy(storageUser.State, req.Delta)
+		if err := tx.Save(storageUser).Error; err != nil {
+			return fmt.Errorf("failed to save user state: %w", err)
+		}
+		return nil
+	})
+}
+
 // Delete, deletes a session given a specific id returning error on failure, implements session.Service
 func (s *databaseService) Delete(ctx context.Context, req *session.DeleteRequest) error {
 	appName, userID, sessionID := req.AppName, req.UserID, req.SessionID
@@ -312,10 +414,78 @@ func (s *databaseService) Delete(ctx context.Context, req *session.DeleteRequest
 			return fmt.Errorf("database error during session deletion: %w", result.Error)
 		}
 
+		// Not every database cascades the deletion to the events, e.g. SQLite
+		// without its foreign keys enabled.
+		result = tx.Where(&storageEvent{
+			AppName:   req.AppName,
+			UserID:    req.UserID,
+			SessionID: req.SessionID,
+		}).Delete(&storageEvent{})
+		if result.Error != nil {
+			return fmt.Errorf("database error during events deletion: %w", result.Error)
+		}
+
 		return nil // Returning nil commits the transaction
 	})
 }
 
+// DeleteUserData deletes the sessions of the user in batches of
+// session.DeleteUserDataBatchSize, one transaction per batch, then the user
+// state, implements session.UserDataDeleter.
+func (s *databaseService) DeleteUserData(ctx context.Context, req *session.DeleteUserDataRequest) (*session.DeleteUserDataResponse, error) {
+	appName, userID := req.AppName, req.UserID
+	if appName == "" || userID == "" {
+		return nil, fmt.Errorf("app_name and user_id are required, got app_name: %q, user_id: %q", appName, userID)
+	}
+
+	resp := &session.DeleteUserDataResponse{}
+	for {
+		var sessionIDs []string
+		err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&storageSession{}).
+				Where(&storageSession{AppName: appName, UserID: userID}).
+				Order("id").
+				Limit(session.DeleteUserDataBatchSize).
+				Pluck("id", &sessionIDs).Error; err != nil {
+				return fmt.Errorf("database error while listing sessions: %w", err)
+			}
+			if len(sessionIDs) == 0 {
+				return nil
+			}
+			result := tx.Where(&storageEvent{AppName: appName, UserID: userID}).
+				Where("session_id IN ?", sessionIDs).
+				Delete(&storageEvent{})
+			if result.Error != nil {
+				return fmt.Errorf("database error during events deletion: %w", result.Error)
+			}
+			resp.DeletedEvents += int(result.RowsAffected)
+			result = tx.Where(&storageSession{AppName: appName, UserID: userID}).
+				Where("id IN ?", sessionIDs).
+				Delete(&storageSession{})
+			if result.Error != nil {
+				return fmt.Errorf("database error during session deletion: %w", result.Error)
+			}
+			resp.DeletedSessions += int(result.RowsAffected)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(sessionIDs) < session.DeleteUserDataBatchSize {
+			break
+		}
+	}
+
+	result := s.db.WithContext(ctx).
+		Where(&storageUserState{AppName: appName, UserID: userID}).
+		Delete(&storageUserState{})
+	if result.Error != nil {
+		return nil, fmt.Errorf("database error during user state deletion: %w", result.Error)
+	}
+	resp.DeletedUserState = result.RowsAffected > 0
+	return resp, nil
+}
+
 func (s *databaseService) AppendEvent(ctx context.Context, curSession session.Session, event *session.Event) error {
 	if curSession == nil {
 		return fmt.Errorf("session is nil")
@@ -323,25 +493,25 @@ func (s *databaseService) AppendEvent(ctx context.Context, curSession session.Se
 	if event == nil {
 		return fmt.Errorf("event is nil")
 	}
-	// ignore partial events
-	if event.Partial {
+	// ignore partial events, unless the caller opts in
+	if event.Partial && !session.SavesPartialEvents(ctx) {
 		return nil
 	}
 
 	// Truncate timestamp to microsecond precision to match database precision and prevent rounding errors.
 	event.Timestamp = event.Timestamp.Truncate(time.Microsecond)
 
-	sess, ok := curSession.(*localSession)
+	sess, ok := curSession.(*sessioninternal.StoredSession)
 	if !ok {
 		return fmt.Errorf("unexpected session type %T", sess)
 	}
 	// append it to session
-	if err := sess.appendEvent(event); err != nil {
+	if err := sess.AppendEvent(ctx, event); err != nil {
 		return err
 	}
 
 	// Trim temp state before persisting
-	event = trimTempDeltaState(event)
+	event = sessioninternal.TrimTempDeltaState(event)
 	// applyChanges and persist them
 	err := s.applyEvent(ctx, sess, event)
 	if err != nil {
@@ -349,18 +519,18 @@ func (s *databaseService) AppendEvent(ctx context.Context, curSession session.Se
 	}
 
 	// update local session last update time
-	sess.updatedAt = event.Timestamp
+	sess.SetLastUpdateTime(event.Timestamp)
 	return nil
 }
 
 // applyEvent fetches the session, validates it, applies state changes from an
 // event, and saves the event atomically.
-func (s *databaseService) applyEvent(ctx context.Context, session *localSession, event *session.Event) error {
+func (s *databaseService) applyEvent(ctx context.Context, sess *sessioninternal.StoredSession, event *session.Event) error {
 	// Wrap database operations in a single transaction.
 	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		// Fetch the session object from storage.
 		var storageSess storageSession
-		err := tx.Where(&storageSession{AppName: session.AppName(), UserID: session.UserID(), ID: session.ID()}).
+		err := tx.Where(&storageSession{AppName: sess.AppName(), UserID: sess.UserID(), ID: sess.ID()}).
 			First(&storageSess).Error
 		if err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -372,21 +542,22 @@ func (s *databaseService) applyEvent(ctx context.Context, session *localSession,
 		// Ensure the session object is not stale.
 		// We use UnixMicro() for microsecond-level precision, matching the Python code.
 		storageUpdateTime := storageSess.UpdateTime.UnixMicro()
-		sessionUpdateTime := session.updatedAt.UnixMicro()
+		sessionUpdateTime := sess.LastUpdateTime().UnixMicro()
 		if storageUpdateTime > sessionUpdateTime {
 			return fmt.Errorf(
-				"stale session error: last update time from request (%s) is older than in database (%s)",
+				"%w: last update time from request (%s) is older than in database (%s)",
+				session.ErrConcurrentModification,
 				time.Unix(0, sessionUpdateTime).Format(time.RFC3339Nano),
 				time.Unix(0, storageUpdateTime).Format(time.RFC3339Nano),
 			)
 		}
 
 		// Fetch App and User states.
-		storageApp, err := fetchStorageAppState(tx, session.AppName())
+		storageApp, err := fetchStorageAppState(forUpdate(tx), sess.AppName())
 		if err != nil {
 			return err
 		}
-		storageUser, err := fetchStorageUserState(tx, session.AppName(), session.UserID())
+		storageUser, err := fetchStorageUserState(forUpdate(tx), sess.AppName(), sess.UserID())
 		if err != nil {
 			return err
 		}
@@ -413,7 +584,7 @@ func (s *databaseService) applyEvent(ctx context.Context, session *localSession,
 		}
 
 		// Create the new event record in the database.
-		storageEv, err := createStorageEvent(session, event)
+		storageEv, err := createStorageEvent(sess, event)
 		if err != nil {
 			return fmt.Errorf("failed to map event to storage model: %w", err)
 		}
@@ -427,7 +598,7 @@ func (s *databaseService) applyEvent(ctx context.Context, session *localSession,
 			return fmt.Errorf("failed to save session state: %w", err)
 		}
 
-		session.updatedAt = storageSess.UpdateTime
+		sess.SetLastUpdateTime(storageSess.UpdateTime)
 
 		return nil // Returning nil commits the transaction.
 	})
@@ -435,6 +606,14 @@ func (s *databaseService) applyEvent(ctx context.Context, session *localSession,
 	return err
 }
 
+// forUpdate locks the rows read by the query until the end of the
+// transaction, so that concurrent read-modify-write cycles of the app and
+// user states don't lose keys. SQLite ignores it, as its transactions lock
+// the whole database.
+func forUpdate(tx *gorm.DB) *gorm.DB {
+	return tx.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate})
+}
+
 func fetchStorageAppState(tx *gorm.DB, appName string) (*storageAppState, error) {
 	var storageApp storageAppState
 	if err := tx.First(&storageApp, "app_name = ?", appName).Error; err != nil {
@@ -525,3 +704,10 @@ func mergeStates(appState, userState, sessionState map[string]any) map[string]an
 
 	return mergedState
 }
+
+var (
+	_ session.Service         = (*databaseService)(nil)
+	_ session.StateService    = (*databaseService)(nil)
+	_ session.VersionChecker  = (*databaseService)(nil)
+	_ session.UserDataDeleter = (*databaseService)(nil)
+)
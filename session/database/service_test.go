@@ -16,6 +16,7 @@ package database
 
 import (
 	"maps"
+	"slices"
 	"strconv"
 	"testing"
 	"time"
@@ -26,6 +27,7 @@ import (
 	"google.golang.org/genai"
 	"gorm.io/gorm"
 
+	"google.golang.org/adk/internal/sessioninternal"
 	"google.golang.org/adk/model"
 	"google.golang.org/adk/session"
 )
@@ -175,9 +177,9 @@ func Test_databaseService_Get(t *testing.T) {
 		}
 
 		// Update 'updatedAt' to pass stale validation on append
-		session1.Session.(*localSession).updatedAt = time.Now()
+		session1.Session.(*sessioninternal.StoredSession).SetLastUpdateTime(time.Now())
 
-		err = s.AppendEvent(t.Context(), session1.Session.(*localSession), &session.Event{
+		err = s.AppendEvent(t.Context(), session1.Session.(*sessioninternal.StoredSession), &session.Event{
 			ID:     "event_for_user1",
 			Author: "user",
 			LLMResponse: model.LLMResponse{
@@ -205,14 +207,14 @@ func Test_databaseService_Get(t *testing.T) {
 		}
 
 		for i := 1; i <= numTestEvents; i++ {
-			created.Session.(*localSession).updatedAt = time.Now()
+			created.Session.(*sessioninternal.StoredSession).SetLastUpdateTime(time.Now())
 			event := &session.Event{
 				ID:          strconv.Itoa(i),
 				Author:      "user",
 				Timestamp:   time.Time{}.Add(time.Duration(i) * time.Microsecond),
 				LLMResponse: model.LLMResponse{},
 			}
-			if err := s.AppendEvent(ctx, created.Session.(*localSession), event); err != nil {
+			if err := s.AppendEvent(ctx, created.Session.(*sessioninternal.StoredSession), event); err != nil {
 				t.Fatalf("setupGetWithConfig failed to append event %d: %v", i, err)
 			}
 		}
@@ -236,15 +238,15 @@ func Test_databaseService_Get(t *testing.T) {
 				SessionID: "session1",
 			},
 			wantResponse: &session.GetResponse{
-				Session: &localSession{
-					appName:   "app1",
-					userID:    "user1",
-					sessionID: "session1",
-					state: map[string]any{
+				Session: sessioninternal.NewStoredSession(sessioninternal.StoredSessionConfig{
+					AppName:   "app1",
+					UserID:    "user1",
+					SessionID: "session1",
+					State: map[string]any{
 						"k1": "v1",
 					},
-					events: []*session.Event{},
-				},
+					Events: []*session.Event{},
+				}),
 			},
 		},
 		{
@@ -266,17 +268,17 @@ func Test_databaseService_Get(t *testing.T) {
 				SessionID: "session1",
 			},
 			wantResponse: &session.GetResponse{
-				Session: &localSession{
-					appName:   "app1",
-					userID:    "user2",
-					sessionID: "session1",
+				Session: sessioninternal.NewStoredSession(sessioninternal.StoredSessionConfig{
+					AppName:   "app1",
+					UserID:    "user2",
+					SessionID: "session1",
 					// This is user2's session, which should have its own state
-					state: map[string]any{
+					State: map[string]any{
 						"k1": "v2",
 					},
 					// Critically, it should NOT have the event from user1's session
-					events: []*session.Event{},
-				},
+					Events: []*session.Event{},
+				}),
 			},
 			wantErr: false,
 		},
@@ -349,8 +351,8 @@ func Test_databaseService_Get(t *testing.T) {
 
 			if tt.wantResponse != nil {
 				if diff := cmp.Diff(tt.wantResponse, got,
-					cmp.AllowUnexported(localSession{}),
-					cmpopts.IgnoreFields(localSession{}, "mu", "updatedAt")); diff != "" {
+					cmp.AllowUnexported(sessioninternal.StoredSession{}),
+					cmpopts.IgnoreFields(sessioninternal.StoredSession{}, "mu", "updatedAt")); diff != "" {
 					t.Errorf("Get session mismatch: (-want +got):\n%s", diff)
 				}
 			}
@@ -358,8 +360,9 @@ func Test_databaseService_Get(t *testing.T) {
 			if tt.wantEvents != nil {
 				opts := []cmp.Option{
 					cmpopts.SortSlices(func(a, b *session.Event) bool { return a.Timestamp.Before(b.Timestamp) }),
+					cmpopts.EquateEmpty(),
 				}
-				if diff := cmp.Diff(events(tt.wantEvents), got.Session.Events(), opts...); diff != "" {
+				if diff := cmp.Diff(tt.wantEvents, slices.Collect(got.Session.Events().All()), opts...); diff != "" {
 					t.Errorf("Get session events mismatch: (-want +got):\n%s", diff)
 				}
 			}
@@ -384,22 +387,22 @@ func Test_databaseService_List(t *testing.T) {
 			},
 			wantResponse: &session.ListResponse{
 				Sessions: []session.Session{
-					&localSession{
-						appName:   "app1",
-						userID:    "user1",
-						sessionID: "session1",
-						state: map[string]any{
+					sessioninternal.NewStoredSession(sessioninternal.StoredSessionConfig{
+						AppName:   "app1",
+						UserID:    "user1",
+						SessionID: "session1",
+						State: map[string]any{
 							"k1": "v1",
 						},
-					},
-					&localSession{
-						appName:   "app1",
-						userID:    "user1",
-						sessionID: "session2",
-						state: map[string]any{
+					}),
+					sessioninternal.NewStoredSession(sessioninternal.StoredSessionConfig{
+						AppName:   "app1",
+						UserID:    "user1",
+						SessionID: "session2",
+						State: map[string]any{
 							"k1": "v2",
 						},
-					},
+					}),
 				},
 			},
 		},
@@ -423,14 +426,14 @@ func Test_databaseService_List(t *testing.T) {
 			},
 			wantResponse: &session.ListResponse{
 				Sessions: []session.Session{
-					&localSession{
-						appName:   "app1",
-						userID:    "user2",
-						sessionID: "session1",
-						state: map[string]any{
+					sessioninternal.NewStoredSession(sessioninternal.StoredSessionConfig{
+						AppName:   "app1",
+						UserID:    "user2",
+						SessionID: "session1",
+						State: map[string]any{
 							"k1": "v2",
 						},
-					},
+					}),
 				},
 			},
 		},
@@ -440,9 +443,9 @@ func Test_databaseService_List(t *testing.T) {
 			req:   &session.ListRequest{AppName: "app1", UserID: ""},
 			wantResponse: &session.ListResponse{
 				Sessions: []session.Session{
-					&localSession{appName: "app1", userID: "user1", sessionID: "session1", state: map[string]any{"k1": "v1"}},
-					&localSession{appName: "app1", userID: "user1", sessionID: "session2", state: map[string]any{"k1": "v2"}},
-					&localSession{appName: "app1", userID: "user2", sessionID: "session1", state: map[string]any{"k1": "v2"}},
+					sessioninternal.NewStoredSession(sessioninternal.StoredSessionConfig{AppName: "app1", UserID: "user1", SessionID: "session1", State: map[string]any{"k1": "v1"}}),
+					sessioninternal.NewStoredSession(sessioninternal.StoredSessionConfig{AppName: "app1", UserID: "user1", SessionID: "session2", State: map[string]any{"k1": "v2"}}),
+					sessioninternal.NewStoredSession(sessioninternal.StoredSessionConfig{AppName: "app1", UserID: "user2", SessionID: "session1", State: map[string]any{"k1": "v2"}}),
 				},
 			},
 		},
@@ -459,8 +462,8 @@ func Test_databaseService_List(t *testing.T) {
 			if err == nil {
 				// Sort slices for stable comparison
 				opts := []cmp.Option{
-					cmp.AllowUnexported(localSession{}),
-					cmpopts.IgnoreFields(localSession{}, "mu", "updatedAt"),
+					cmp.AllowUnexported(sessioninternal.StoredSession{}),
+					cmpopts.IgnoreFields(sessioninternal.StoredSession{}, "mu", "updatedAt"),
 					cmpopts.SortSlices(func(a, b session.Session) bool {
 						return a.ID() < b.ID()
 					}),
@@ -473,35 +476,97 @@ func Test_databaseService_List(t *testing.T) {
 	}
 }
 
+func Test_databaseService_ListPagination(t *testing.T) {
+	ctx := t.Context()
+	s := emptyService(t)
+	now := time.Now()
+	var sessions []session.Session
+	for i := range 5 {
+		created, err := s.Create(ctx, &session.CreateRequest{AppName: "app", UserID: "user", SessionID: "session" + strconv.Itoa(i)})
+		if err != nil {
+			t.Fatalf("Create() failed: %v", err)
+		}
+		sessions = append(sessions, created.Session)
+	}
+	// The sessions are updated in the reverse order of creation, but the
+	// second one is updated last.
+	for i, sess := range sessions {
+		ts := now.Add(time.Duration(10-i) * time.Second)
+		if i == 1 {
+			ts = now.Add(time.Minute)
+		}
+		event := &session.Event{ID: "event" + strconv.Itoa(i), Timestamp: ts, LLMResponse: model.LLMResponse{Content: genai.NewContentFromText("hi", genai.RoleUser)}}
+		if err := s.AppendEvent(ctx, sess, event); err != nil {
+			t.Fatalf("AppendEvent() failed: %v", err)
+		}
+	}
+
+	var gotIDs []string
+	var gotPageSizes []int
+	token := ""
+	for {
+		resp, err := s.List(ctx, &session.ListRequest{AppName: "app", UserID: "user", ListOptions: session.ListOptions{PageSize: 2, PageToken: token}})
+		if err != nil {
+			t.Fatalf("List() failed: %v", err)
+		}
+		gotPageSizes = append(gotPageSizes, len(resp.Sessions))
+		for _, sess := range resp.Sessions {
+			gotIDs = append(gotIDs, sess.ID())
+		}
+		if resp.NextPageToken == "" {
+			break
+		}
+		token = resp.NextPageToken
+	}
+
+	if diff := cmp.Diff([]string{"session1", "session0", "session2", "session3", "session4"}, gotIDs); diff != "" {
+		t.Errorf("List() session IDs mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]int{2, 2, 1}, gotPageSizes); diff != "" {
+		t.Errorf("List() page sizes mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := s.List(ctx, &session.ListRequest{AppName: "app", ListOptions: session.ListOptions{PageSize: 2, PageToken: "invalid"}}); err == nil {
+		t.Errorf("List() with invalid page token succeeded, want error")
+	}
+}
+
+func Test_databaseService_Ping(t *testing.T) {
+	s := emptyService(t)
+	if err := s.Ping(t.Context()); err != nil {
+		t.Errorf("Ping() failed: %v", err)
+	}
+}
+
 func Test_databaseService_AppendEvent(t *testing.T) {
 	tests := []struct {
 		name              string
 		setup             func(t *testing.T) *databaseService
-		session           *localSession
+		session           *sessioninternal.StoredSession
 		event             *session.Event
-		wantStoredSession *localSession // State of the session after Get
-		wantEventCount    int           // Expected event count in storage
+		wantStoredSession *sessioninternal.StoredSession // State of the session after Get
+		wantEventCount    int                            // Expected event count in storage
 		wantErr           bool
 	}{
 		{
 			name:  "append event to the session and overwrite in storage",
 			setup: serviceDbWithData,
-			session: &localSession{
-				appName:   "app1",
-				userID:    "user1",
-				sessionID: "session1",
-			},
+			session: sessioninternal.NewStoredSession(sessioninternal.StoredSessionConfig{
+				AppName:   "app1",
+				UserID:    "user1",
+				SessionID: "session1",
+			}),
 			event: &session.Event{
 				ID: "new_event1",
 				LLMResponse: model.LLMResponse{
 					Partial: false,
 				},
 			},
-			wantStoredSession: &localSession{
-				appName:   "app1",
-				userID:    "user1",
-				sessionID: "session1",
-				events: []*session.Event{
+			wantStoredSession: sessioninternal.NewStoredSession(sessioninternal.StoredSessionConfig{
+				AppName:   "app1",
+				UserID:    "user1",
+				SessionID: "session1",
+				Events: []*session.Event{
 					{
 						ID: "new_event1",
 						LLMResponse: model.LLMResponse{
@@ -509,31 +574,31 @@ func Test_databaseService_AppendEvent(t *testing.T) {
 						},
 					},
 				},
-				state: map[string]any{
+				State: map[string]any{
 					"k1": "v1",
 				},
-			},
+			}),
 			wantEventCount: 1,
 		},
 		{
 			name:  "append event to the session with events and overwrite in storage",
 			setup: serviceDbWithData,
-			session: &localSession{
-				appName:   "app2",
-				userID:    "user2",
-				sessionID: "session2",
-			},
+			session: sessioninternal.NewStoredSession(sessioninternal.StoredSessionConfig{
+				AppName:   "app2",
+				UserID:    "user2",
+				SessionID: "session2",
+			}),
 			event: &session.Event{
 				ID: "new_event1",
 				LLMResponse: model.LLMResponse{
 					Partial: false,
 				},
 			},
-			wantStoredSession: &localSession{
-				appName:   "app2",
-				userID:    "user2",
-				sessionID: "session2",
-				events: []*session.Event{
+			wantStoredSession: sessioninternal.NewStoredSession(sessioninternal.StoredSessionConfig{
+				AppName:   "app2",
+				UserID:    "user2",
+				SessionID: "session2",
+				Events: []*session.Event{
 					{
 						ID: "existing_event1",
 						LLMResponse: model.LLMResponse{
@@ -547,20 +612,20 @@ func Test_databaseService_AppendEvent(t *testing.T) {
 						},
 					},
 				},
-				state: map[string]any{
+				State: map[string]any{
 					"k2": "v2",
 				},
-			},
+			}),
 			wantEventCount: 2,
 		},
 		{
 			name:  "append event when session not found should fail",
 			setup: serviceDbWithData,
-			session: &localSession{
-				appName:   "app1",
-				userID:    "user1",
-				sessionID: "custom_session",
-			},
+			session: sessioninternal.NewStoredSession(sessioninternal.StoredSessionConfig{
+				AppName:   "app1",
+				UserID:    "user1",
+				SessionID: "custom_session",
+			}),
 			event: &session.Event{
 				ID: "new_event2",
 				LLMResponse: model.LLMResponse{
@@ -572,11 +637,11 @@ func Test_databaseService_AppendEvent(t *testing.T) {
 		{
 			name:  "append event with bytes content",
 			setup: serviceDbWithData,
-			session: &localSession{
-				appName:   "app1",
-				userID:    "user1",
-				sessionID: "session1",
-			},
+			session: sessioninternal.NewStoredSession(sessioninternal.StoredSessionConfig{
+				AppName:   "app1",
+				UserID:    "user1",
+				SessionID: "session1",
+			}),
 			event: &session.Event{
 				ID:     "event_with_bytes",
 				Author: "user",
@@ -589,11 +654,11 @@ func Test_databaseService_AppendEvent(t *testing.T) {
 					},
 				},
 			},
-			wantStoredSession: &localSession{
-				appName:   "app1",
-				userID:    "user1",
-				sessionID: "session1",
-				events: []*session.Event{
+			wantStoredSession: sessioninternal.NewStoredSession(sessioninternal.StoredSessionConfig{
+				AppName:   "app1",
+				UserID:    "user1",
+				SessionID: "session1",
+				Events: []*session.Event{
 					{
 						ID:     "event_with_bytes",
 						Author: "user",
@@ -607,20 +672,20 @@ func Test_databaseService_AppendEvent(t *testing.T) {
 						},
 					},
 				},
-				state: map[string]any{
+				State: map[string]any{
 					"k1": "v1",
 				},
-			},
+			}),
 			wantEventCount: 1,
 		},
 		{
 			name:  "append event with all fields",
 			setup: serviceDbWithData,
-			session: &localSession{
-				appName:   "app1",
-				userID:    "user1",
-				sessionID: "session1",
-			},
+			session: sessioninternal.NewStoredSession(sessioninternal.StoredSessionConfig{
+				AppName:   "app1",
+				UserID:    "user1",
+				SessionID: "session1",
+			}),
 			event: &session.Event{
 				ID:                 "event_complete",
 				Author:             "user",
@@ -649,11 +714,11 @@ func Test_databaseService_AppendEvent(t *testing.T) {
 					},
 				},
 			},
-			wantStoredSession: &localSession{
-				appName:   "app1",
-				userID:    "user1",
-				sessionID: "session1",
-				events: []*session.Event{
+			wantStoredSession: sessioninternal.NewStoredSession(sessioninternal.StoredSessionConfig{
+				AppName:   "app1",
+				UserID:    "user1",
+				SessionID: "session1",
+				Events: []*session.Event{
 					{
 						ID:                 "event_complete",
 						Author:             "user",
@@ -683,21 +748,21 @@ func Test_databaseService_AppendEvent(t *testing.T) {
 						},
 					},
 				},
-				state: map[string]any{
+				State: map[string]any{
 					"k1": "v1",
 					"k2": "v2",
 				},
-			},
+			}),
 			wantEventCount: 1,
 		},
 		{
 			name:  "partial events are not persisted",
 			setup: serviceDbWithData,
-			session: &localSession{
-				appName:   "app1",
-				userID:    "user1",
-				sessionID: "session1",
-			},
+			session: sessioninternal.NewStoredSession(sessioninternal.StoredSessionConfig{
+				AppName:   "app1",
+				UserID:    "user1",
+				SessionID: "session1",
+			}),
 			event: &session.Event{
 				ID:     "partial_event",
 				Author: "user",
@@ -705,15 +770,15 @@ func Test_databaseService_AppendEvent(t *testing.T) {
 					Partial: true, // This is the key field
 				},
 			},
-			wantStoredSession: &localSession{
-				appName:   "app1",
-				userID:    "user1",
-				sessionID: "session1",
-				events:    []*session.Event{}, // No event should be stored
-				state: map[string]any{
+			wantStoredSession: sessioninternal.NewStoredSession(sessioninternal.StoredSessionConfig{
+				AppName:   "app1",
+				UserID:    "user1",
+				SessionID: "session1",
+				Events:    []*session.Event{}, // No event should be stored
+				State: map[string]any{
 					"k1": "v1",
 				},
-			},
+			}),
 			wantEventCount: 0, // Expect 0 events
 		},
 	}
@@ -723,7 +788,7 @@ func Test_databaseService_AppendEvent(t *testing.T) {
 
 			s := tt.setup(t)
 
-			tt.session.updatedAt = time.Now() // set updatedAt value to pass stale validation
+			tt.session.SetLastUpdateTime(time.Now()) // set updatedAt value to pass stale validation
 			err := s.AppendEvent(ctx, tt.session, tt.event)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("databaseService.AppendEvent() error = %v, wantErr %v", err, tt.wantErr)
@@ -750,8 +815,8 @@ func Test_databaseService_AppendEvent(t *testing.T) {
 
 			// Define comparison options
 			opts := []cmp.Option{
-				cmp.AllowUnexported(localSession{}),
-				cmpopts.IgnoreFields(localSession{}, "mu", "updatedAt"),
+				cmp.AllowUnexported(sessioninternal.StoredSession{}),
+				cmpopts.IgnoreFields(sessioninternal.StoredSession{}, "mu", "updatedAt"),
 				cmpopts.IgnoreFields(session.Event{}, "Timestamp"),
 				// Add sorters if event order is not guaranteed
 				cmpopts.SortSlices(func(a, b *session.Event) bool {
@@ -773,8 +838,8 @@ func Test_databaseService_StateManagement(t *testing.T) {
 	t.Run("app_state_is_shared", func(t *testing.T) {
 		s := emptyService(t)
 		s1, _ := s.Create(ctx, &session.CreateRequest{AppName: appName, UserID: "u1", SessionID: "s1", State: map[string]any{"app:k1": "v1"}})
-		s1.Session.(*localSession).updatedAt = time.Now()
-		err := s.AppendEvent(ctx, s1.Session.(*localSession), &session.Event{
+		s1.Session.(*sessioninternal.StoredSession).SetLastUpdateTime(time.Now())
+		err := s.AppendEvent(ctx, s1.Session.(*sessioninternal.StoredSession), &session.Event{
 			ID:          "event1",
 			Actions:     session.EventActions{StateDelta: map[string]any{"app:k2": "v2"}},
 			LLMResponse: model.LLMResponse{},
@@ -798,8 +863,8 @@ func Test_databaseService_StateManagement(t *testing.T) {
 	t.Run("user_state_is_user_specific", func(t *testing.T) {
 		s := emptyService(t)
 		s1, _ := s.Create(ctx, &session.CreateRequest{AppName: appName, UserID: "u1", SessionID: "s1", State: map[string]any{"user:k1": "v1"}})
-		s1.Session.(*localSession).updatedAt = time.Now()
-		err := s.AppendEvent(ctx, s1.Session.(*localSession), &session.Event{
+		s1.Session.(*sessioninternal.StoredSession).SetLastUpdateTime(time.Now())
+		err := s.AppendEvent(ctx, s1.Session.(*sessioninternal.StoredSession), &session.Event{
 			ID:          "event1",
 			Actions:     session.EventActions{StateDelta: map[string]any{"user:k2": "v2"}},
 			LLMResponse: model.LLMResponse{},
@@ -825,8 +890,8 @@ func Test_databaseService_StateManagement(t *testing.T) {
 	t.Run("session_state_is_not_shared", func(t *testing.T) {
 		s := emptyService(t)
 		s1, _ := s.Create(ctx, &session.CreateRequest{AppName: appName, UserID: "u1", SessionID: "s1", State: map[string]any{"sk1": "v1"}})
-		s1.Session.(*localSession).updatedAt = time.Now()
-		err := s.AppendEvent(ctx, s1.Session.(*localSession), &session.Event{
+		s1.Session.(*sessioninternal.StoredSession).SetLastUpdateTime(time.Now())
+		err := s.AppendEvent(ctx, s1.Session.(*sessioninternal.StoredSession), &session.Event{
 			ID:          "event1",
 			Actions:     session.EventActions{StateDelta: map[string]any{"sk2": "v2"}},
 			LLMResponse: model.LLMResponse{},
@@ -852,17 +917,17 @@ func Test_databaseService_StateManagement(t *testing.T) {
 	t.Run("temp_state_is_not_persisted", func(t *testing.T) {
 		s := emptyService(t)
 		s1, _ := s.Create(ctx, &session.CreateRequest{AppName: appName, UserID: "u1", SessionID: "s1"})
-		s1.Session.(*localSession).updatedAt = time.Now()
+		s1.Session.(*sessioninternal.StoredSession).SetLastUpdateTime(time.Now())
 		event := &session.Event{
 			ID:          "event1",
 			Actions:     session.EventActions{StateDelta: map[string]any{"temp:k1": "v1", "sk": "v2"}},
 			LLMResponse: model.LLMResponse{},
 		}
-		err := s.AppendEvent(ctx, s1.Session.(*localSession), event)
+		err := s.AppendEvent(ctx, s1.Session.(*sessioninternal.StoredSession), event)
 		if err != nil {
 			t.Fatalf("Failed to appendEvent: %v", err)
 		}
-		invocationSession := s1.Session.(*localSession)
+		invocationSession := s1.Session.(*sessioninternal.StoredSession)
 		wantInvocationState := map[string]any{"sk": "v2", "temp:k1": "v1"}
 		gotInvocationState := maps.Collect(invocationSession.State().All())
 		if diff := cmp.Diff(wantInvocationState, gotInvocationState); diff != "" {
@@ -895,39 +960,39 @@ func serviceDbWithData(t *testing.T) *databaseService {
 
 	service := emptyService(t)
 
-	for _, storedSession := range []*localSession{
+	for _, storedSession := range []sessioninternal.StoredSessionConfig{
 		{
-			appName:   "app1",
-			userID:    "user1",
-			sessionID: "session1",
-			state: map[string]any{
+			AppName:   "app1",
+			UserID:    "user1",
+			SessionID: "session1",
+			State: map[string]any{
 				"k1": "v1",
 			},
 		},
 		{
-			appName:   "app1",
-			userID:    "user2",
-			sessionID: "session1",
-			state: map[string]any{
+			AppName:   "app1",
+			UserID:    "user2",
+			SessionID: "session1",
+			State: map[string]any{
 				"k1": "v2",
 			},
 		},
 		{
-			appName:   "app1",
-			userID:    "user1",
-			sessionID: "session2",
-			state: map[string]any{
+			AppName:   "app1",
+			UserID:    "user1",
+			SessionID: "session2",
+			State: map[string]any{
 				"k1": "v2",
 			},
 		},
 		{
-			appName:   "app2",
-			userID:    "user2",
-			sessionID: "session2",
-			state: map[string]any{
+			AppName:   "app2",
+			UserID:    "user2",
+			SessionID: "session2",
+			State: map[string]any{
 				"k2": "v2",
 			},
-			events: []*session.Event{
+			Events: []*session.Event{
 				{
 					ID: "existing_event1",
 					LLMResponse: model.LLMResponse{
@@ -939,16 +1004,16 @@ func serviceDbWithData(t *testing.T) *databaseService {
 	} {
 		// TODO: Consider changing to SQL insert
 		resp, err := service.Create(t.Context(), &session.CreateRequest{
-			AppName:   storedSession.appName,
-			UserID:    storedSession.userID,
-			SessionID: storedSession.sessionID,
-			State:     storedSession.state,
+			AppName:   storedSession.AppName,
+			UserID:    storedSession.UserID,
+			SessionID: storedSession.SessionID,
+			State:     storedSession.State,
 		})
 		if err != nil {
 			t.Fatalf("Failed to create sample sessions on db initialization: %v", err)
 		}
 
-		for _, ev := range storedSession.events {
+		for _, ev := range storedSession.Events {
 			err = service.AppendEvent(t.Context(), resp.Session, ev)
 			if err != nil {
 				t.Fatalf("Failed to append event to session on db initialization: %v", err)
@@ -21,6 +21,7 @@ import (
 
 	"google.golang.org/genai"
 
+	"google.golang.org/adk/internal/sessioninternal"
 	"google.golang.org/adk/model"
 	"google.golang.org/adk/session"
 )
@@ -44,26 +45,26 @@ func (storageSession) TableName() string {
 }
 
 // Helper to map from internal struct to GORM struct
-func createStorageSession(s *localSession) (*storageSession, error) {
+func createStorageSession(s *sessioninternal.StoredSession) (*storageSession, error) {
 	return &storageSession{
-		UserID:     s.userID,
-		AppName:    s.appName,
-		ID:         s.sessionID,
-		State:      s.state,
+		UserID:     s.UserID(),
+		AppName:    s.AppName(),
+		ID:         s.ID(),
+		State:      s.StateMap(),
 		CreateTime: time.Now(),
 		UpdateTime: time.Now(),
 	}, nil
 }
 
 // Helper to map from GORM struct to internal struct
-func createSessionFromStorageSession(storage *storageSession) (*localSession, error) {
-	return &localSession{
-		appName:   storage.AppName,
-		userID:    storage.UserID,
-		sessionID: storage.ID,
-		state:     storage.State,
-		updatedAt: storage.UpdateTime,
-	}, nil
+func createSessionFromStorageSession(storage *storageSession) (*sessioninternal.StoredSession, error) {
+	return sessioninternal.NewStoredSession(sessioninternal.StoredSessionConfig{
+		AppName:   storage.AppName,
+		UserID:    storage.UserID,
+		SessionID: storage.ID,
+		State:     storage.State,
+		UpdatedAt: storage.UpdateTime,
+	}), nil
 }
 
 // storageEvent corresponds to the 'events' table.
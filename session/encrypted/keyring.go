@@ -0,0 +1,137 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encrypted
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// Key is an AES key of the keyring.
+type Key struct {
+	// ID identifies the key in the records it encrypts. It must not be
+	// empty, and must be at most 255 bytes long.
+	ID string
+	// Secret is the key of AES-128, AES-192 or AES-256: 16, 24 or 32 bytes.
+	Secret []byte
+}
+
+// Keyring holds the keys of the service.
+type Keyring struct {
+	// Primary encrypts the records written by the service.
+	Primary Key
+	// Previous are the keys which the primary key replaced. They decrypt
+	// the records written before the rotation, which don't need to be
+	// encrypted again.
+	// Optional.
+	Previous []Key
+}
+
+// ErrUnknownKey is wrapped by the [DecryptionError] of a record encrypted
+// with a key missing from the keyring.
+var ErrUnknownKey = errors.New("unknown key")
+
+// DecryptionError is returned when a stored record can't be decrypted,
+// because it is corrupted or because its key is missing from the keyring.
+type DecryptionError struct {
+	// KeyID is the ID of the key the record was encrypted with, or empty if
+	// the record is too corrupted to tell.
+	KeyID string
+	Err   error
+}
+
+func (e *DecryptionError) Error() string {
+	if e.KeyID == "" {
+		return fmt.Sprintf("failed to decrypt record: %v", e.Err)
+	}
+	return fmt.Sprintf("failed to decrypt record with key %q: %v", e.KeyID, e.Err)
+}
+
+func (e *DecryptionError) Unwrap() error {
+	return e.Err
+}
+
+// envelopeVersion is the first byte of the encrypted records, which are
+// made of the version, the length of the key ID, the key ID, the nonce and
+// the ciphertext.
+const envelopeVersion = 1
+
+// keys holds the ciphers of a keyring.
+type keys struct {
+	primaryID string
+	aeads     map[string]cipher.AEAD
+}
+
+func newKeys(keyring Keyring) (*keys, error) {
+	k := &keys{primaryID: keyring.Primary.ID, aeads: make(map[string]cipher.AEAD)}
+	for i, key := range append([]Key{keyring.Primary}, keyring.Previous...) {
+		if key.ID == "" || len(key.ID) > 255 {
+			return nil, fmt.Errorf("key %d: ID must be 1 to 255 bytes long, got %d", i, len(key.ID))
+		}
+		if _, ok := k.aeads[key.ID]; ok {
+			return nil, fmt.Errorf("key %d: duplicate ID %q", i, key.ID)
+		}
+		block, err := aes.NewCipher(key.Secret)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", key.ID, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", key.ID, err)
+		}
+		k.aeads[key.ID] = aead
+	}
+	return k, nil
+}
+
+// seal encrypts the plaintext with the primary key and a random nonce. The
+// additional data binds the record to its place, e.g. its state key.
+func (k *keys) seal(plaintext, additionalData []byte) ([]byte, error) {
+	aead := k.aeads[k.primaryID]
+	out := make([]byte, 0, 2+len(k.primaryID)+aead.NonceSize()+len(plaintext)+aead.Overhead())
+	out = append(out, envelopeVersion, byte(len(k.primaryID)))
+	out = append(out, k.primaryID...)
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	out = append(out, nonce...)
+	return aead.Seal(out, nonce, plaintext, additionalData), nil
+}
+
+// open decrypts a record sealed with any key of the keyring. It fails with
+// a [*DecryptionError].
+func (k *keys) open(record, additionalData []byte) ([]byte, error) {
+	if len(record) < 2 || record[0] != envelopeVersion || len(record) < 2+int(record[1]) {
+		return nil, &DecryptionError{Err: errors.New("malformed record")}
+	}
+	keyID := string(record[2 : 2+int(record[1])])
+	aead, ok := k.aeads[keyID]
+	if !ok {
+		return nil, &DecryptionError{KeyID: keyID, Err: ErrUnknownKey}
+	}
+	rest := record[2+len(keyID):]
+	if len(rest) < aead.NonceSize()+aead.Overhead() {
+		return nil, &DecryptionError{KeyID: keyID, Err: errors.New("truncated record")}
+	}
+	plaintext, err := aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], additionalData)
+	if err != nil {
+		return nil, &DecryptionError{KeyID: keyID, Err: err}
+	}
+	return plaintext, nil
+}
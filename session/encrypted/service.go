@@ -0,0 +1,301 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package encrypted provides a [session.Service] which encrypts the
+// conversations before they reach the service it wraps, e.g. a database:
+//
+//	sessionService, err := encrypted.NewService(inner, encrypted.Keyring{
+//		Primary: encrypted.Key{ID: "2025-06", Secret: secret},
+//	})
+//
+// The content of the events and the values of the states, including the
+// state deltas of the events, are encrypted with AES-GCM, each with its own
+// random nonce and the ID of its key. The IDs, the timestamps, the authors
+// and the other fields of the events, as well as the state keys, are left
+// in the clear, so that the inner service can still list, filter and route
+// them.
+//
+// To rotate the key, make the new key the primary one and keep the old one
+// among the previous keys, so that the records it encrypted can still be
+// read. The records written before the encryption was enabled are read as
+// they are.
+//
+// Besides [session.Service], the service only implements
+// [session.VersionChecker], on behalf of the inner service: the other
+// optional interfaces, e.g. [session.Searcher], would see the ciphertexts.
+package encrypted
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/internal/sessioninternal"
+	"google.golang.org/adk/session"
+)
+
+const (
+	// MIMEType is the MIME type of the single inline part of an encrypted
+	// event content.
+	MIMEType = "application/vnd.adk.encrypted"
+	// ValuePrefix prefixes the base64 encoding of an encrypted state value.
+	ValuePrefix = "adk-encrypted:"
+)
+
+// contentData is the additional data of the encrypted contents.
+var contentData = []byte("content")
+
+type encryptedService struct {
+	inner session.Service
+	keys  *keys
+}
+
+// NewService returns a service which encrypts the event contents and the
+// state values before passing them to inner, and decrypts them on read. The
+// sessions it returns must be passed back to it, not to inner.
+func NewService(inner session.Service, keyring Keyring) (session.Service, error) {
+	if inner == nil {
+		return nil, fmt.Errorf("inner service is required")
+	}
+	k, err := newKeys(keyring)
+	if err != nil {
+		return nil, fmt.Errorf("invalid keyring: %w", err)
+	}
+	return &encryptedService{inner: inner, keys: k}, nil
+}
+
+// encryptedSession is the decrypted view of a session of the inner
+// service.
+type encryptedSession struct {
+	*sessioninternal.StoredSession
+	inner session.Session
+}
+
+// LastUpdateTime is the one of the inner session, which the inner service
+// updates as it appends the events.
+func (s *encryptedSession) LastUpdateTime() time.Time {
+	return s.inner.LastUpdateTime()
+}
+
+func (s *encryptedService) Create(ctx context.Context, req *session.CreateRequest) (*session.CreateResponse, error) {
+	encReq := *req
+	var err error
+	if encReq.State, err = s.encryptState(req.State); err != nil {
+		return nil, err
+	}
+	resp, err := s.inner.Create(ctx, &encReq)
+	if err != nil {
+		return nil, err
+	}
+	sess, err := s.decryptSession(resp.Session)
+	if err != nil {
+		return nil, err
+	}
+	return &session.CreateResponse{Session: sess}, nil
+}
+
+func (s *encryptedService) Get(ctx context.Context, req *session.GetRequest) (*session.GetResponse, error) {
+	resp, err := s.inner.Get(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	sess, err := s.decryptSession(resp.Session)
+	if err != nil {
+		return nil, err
+	}
+	return &session.GetResponse{Session: sess}, nil
+}
+
+func (s *encryptedService) List(ctx context.Context, req *session.ListRequest) (*session.ListResponse, error) {
+	resp, err := s.inner.List(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]session.Session, 0, len(resp.Sessions))
+	for _, inner := range resp.Sessions {
+		sess, err := s.decryptSession(inner)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, sess)
+	}
+	return &session.ListResponse{Sessions: sessions, NextPageToken: resp.NextPageToken}, nil
+}
+
+func (s *encryptedService) Delete(ctx context.Context, req *session.DeleteRequest) error {
+	return s.inner.Delete(ctx, req)
+}
+
+// AppendEvent appends an encrypted copy of the event to the inner session,
+// and the event itself to the decrypted view.
+func (s *encryptedService) AppendEvent(ctx context.Context, curSession session.Session, event *session.Event) error {
+	if curSession == nil {
+		return fmt.Errorf("session is nil")
+	}
+	if event == nil {
+		return fmt.Errorf("event is nil")
+	}
+	sess, ok := curSession.(*encryptedSession)
+	if !ok {
+		return fmt.Errorf("unexpected session type %T", curSession)
+	}
+	encEvent, err := s.encryptEvent(event)
+	if err != nil {
+		return err
+	}
+	if err := s.inner.AppendEvent(ctx, sess.inner, encEvent); err != nil {
+		return err
+	}
+	return sess.AppendEvent(ctx, event)
+}
+
+// VersionChecking reports whether the inner service checks the versions of
+// the sessions, implements session.VersionChecker.
+func (s *encryptedService) VersionChecking() bool {
+	vc, ok := s.inner.(session.VersionChecker)
+	return ok && vc.VersionChecking()
+}
+
+// encryptEvent returns a copy of the event with its content and the values
+// of its state delta encrypted.
+func (s *encryptedService) encryptEvent(event *session.Event) (*session.Event, error) {
+	encEvent := *event
+	if event.Content != nil {
+		plaintext, err := json.Marshal(event.Content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal content: %w", err)
+		}
+		record, err := s.keys.seal(plaintext, contentData)
+		if err != nil {
+			return nil, err
+		}
+		encEvent.Content = &genai.Content{
+			Role:  event.Content.Role,
+			Parts: []*genai.Part{{InlineData: &genai.Blob{MIMEType: MIMEType, Data: record}}},
+		}
+	}
+	var err error
+	if encEvent.Actions.StateDelta, err = s.encryptState(event.Actions.StateDelta); err != nil {
+		return nil, err
+	}
+	return &encEvent, nil
+}
+
+func (s *encryptedService) encryptState(state map[string]any) (map[string]any, error) {
+	if state == nil {
+		return nil, nil
+	}
+	encState := make(map[string]any, len(state))
+	for key, value := range state {
+		plaintext, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal state value %q: %w", key, err)
+		}
+		record, err := s.keys.seal(plaintext, []byte(key))
+		if err != nil {
+			return nil, err
+		}
+		encState[key] = ValuePrefix + base64.StdEncoding.EncodeToString(record)
+	}
+	return encState, nil
+}
+
+// decryptSession returns the decrypted view of a session of the inner
+// service.
+func (s *encryptedService) decryptSession(inner session.Session) (*encryptedSession, error) {
+	state, err := s.decryptState(maps.Collect(inner.State().All()))
+	if err != nil {
+		return nil, fmt.Errorf("session %q: %w", inner.ID(), err)
+	}
+	events := make([]*session.Event, 0, inner.Events().Len())
+	for event := range inner.Events().All() {
+		decrypted, err := s.decryptEvent(event)
+		if err != nil {
+			return nil, fmt.Errorf("session %q: event %q: %w", inner.ID(), event.ID, err)
+		}
+		events = append(events, decrypted)
+	}
+	return &encryptedSession{
+		StoredSession: sessioninternal.NewStoredSession(sessioninternal.StoredSessionConfig{
+			AppName:   inner.AppName(),
+			UserID:    inner.UserID(),
+			SessionID: inner.ID(),
+			State:     state,
+			Events:    events,
+		}),
+		inner: inner,
+	}, nil
+}
+
+// decryptEvent returns a copy of the event with its content and the values
+// of its state delta decrypted. The plaintext contents are kept.
+func (s *encryptedService) decryptEvent(event *session.Event) (*session.Event, error) {
+	decrypted := *event
+	if c := event.Content; c != nil && len(c.Parts) == 1 && c.Parts[0].InlineData != nil && c.Parts[0].InlineData.MIMEType == MIMEType {
+		plaintext, err := s.keys.open(c.Parts[0].InlineData.Data, contentData)
+		if err != nil {
+			return nil, err
+		}
+		decrypted.Content = &genai.Content{}
+		if err := json.Unmarshal(plaintext, decrypted.Content); err != nil {
+			return nil, &DecryptionError{Err: fmt.Errorf("invalid content: %w", err)}
+		}
+	}
+	var err error
+	if decrypted.Actions.StateDelta, err = s.decryptState(event.Actions.StateDelta); err != nil {
+		return nil, err
+	}
+	return &decrypted, nil
+}
+
+// decryptState returns a copy of the state with its encrypted values
+// decrypted. The plaintext values are kept.
+func (s *encryptedService) decryptState(state map[string]any) (map[string]any, error) {
+	if state == nil {
+		return nil, nil
+	}
+	decrypted := make(map[string]any, len(state))
+	for key, value := range state {
+		encoded, ok := value.(string)
+		if !ok || !strings.HasPrefix(encoded, ValuePrefix) {
+			decrypted[key] = value
+			continue
+		}
+		record, err := base64.StdEncoding.DecodeString(encoded[len(ValuePrefix):])
+		if err != nil {
+			return nil, &DecryptionError{Err: fmt.Errorf("state value %q: %w", key, err)}
+		}
+		plaintext, err := s.keys.open(record, []byte(key))
+		if err != nil {
+			return nil, fmt.Errorf("state value %q: %w", key, err)
+		}
+		var v any
+		if err := json.Unmarshal(plaintext, &v); err != nil {
+			return nil, &DecryptionError{Err: fmt.Errorf("state value %q: %w", key, err)}
+		}
+		decrypted[key] = v
+	}
+	return decrypted, nil
+}
+
+var (
+	_ session.Service        = (*encryptedService)(nil)
+	_ session.VersionChecker = (*encryptedService)(nil)
+)
@@ -0,0 +1,251 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encrypted
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/session/sessiontest"
+)
+
+var (
+	oldKey = Key{ID: "old", Secret: bytes.Repeat([]byte{1}, 32)}
+	newKey = Key{ID: "new", Secret: bytes.Repeat([]byte{2}, 16)}
+)
+
+func newTestService(t *testing.T, inner session.Service, keyring Keyring) session.Service {
+	t.Helper()
+	s, err := NewService(inner, keyring)
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+	return s
+}
+
+func TestService_Conformance(t *testing.T) {
+	sessiontest.RunConformance(t, func(t *testing.T) session.Service {
+		return newTestService(t, session.InMemoryService(), Keyring{Primary: newKey})
+	})
+}
+
+func TestNewService(t *testing.T) {
+	tests := []struct {
+		name    string
+		keyring Keyring
+		wantErr bool
+	}{
+		{name: "primary", keyring: Keyring{Primary: newKey}},
+		{name: "previous", keyring: Keyring{Primary: newKey, Previous: []Key{oldKey}}},
+		{name: "no primary", wantErr: true},
+		{name: "empty ID", keyring: Keyring{Primary: Key{Secret: newKey.Secret}}, wantErr: true},
+		{name: "long ID", keyring: Keyring{Primary: Key{ID: strings.Repeat("k", 256), Secret: newKey.Secret}}, wantErr: true},
+		{name: "invalid secret", keyring: Keyring{Primary: Key{ID: "k", Secret: []byte("short")}}, wantErr: true},
+		{name: "duplicate ID", keyring: Keyring{Primary: newKey, Previous: []Key{{ID: "new", Secret: oldKey.Secret}}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewService(session.InMemoryService(), tt.keyring)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewService() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// appendToInner creates a session with the state and an event with the
+// text through s, and returns the session as stored by inner.
+func appendToInner(t *testing.T, s, inner session.Service, state map[string]any, text string) session.Session {
+	t.Helper()
+	ctx := t.Context()
+	created, err := s.Create(ctx, &session.CreateRequest{AppName: "app", UserID: "user", SessionID: "session", State: state})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	event := session.NewEvent("inv")
+	event.Author = "user"
+	event.LLMResponse = model.LLMResponse{Content: genai.NewContentFromText(text, genai.RoleUser)}
+	event.Actions.StateDelta = map[string]any{"user:name": "Ada", "temp:t": "temp"}
+	if err := s.AppendEvent(ctx, created.Session, event); err != nil {
+		t.Fatalf("AppendEvent() error = %v", err)
+	}
+	if _, ok := event.Actions.StateDelta["temp:t"]; ok {
+		t.Errorf("AppendEvent() kept the temporary key in the event")
+	}
+	stored, err := inner.Get(ctx, &session.GetRequest{AppName: "app", UserID: "user", SessionID: "session"})
+	if err != nil {
+		t.Fatalf("inner Get() error = %v", err)
+	}
+	return stored.Session
+}
+
+func TestService_EncryptsAtRest(t *testing.T) {
+	inner := session.InMemoryService()
+	s := newTestService(t, inner, Keyring{Primary: newKey})
+	stored := appendToInner(t, s, inner, map[string]any{"city": "Paris", "nights": 3.0}, "secret plans")
+
+	for key, value := range stored.State().All() {
+		if str, ok := value.(string); !ok || !strings.HasPrefix(str, ValuePrefix) {
+			t.Errorf("stored state %q = %v, want an encrypted value", key, value)
+		}
+	}
+	event := stored.Events().At(0)
+	if event.Author != "user" || event.ID == "" || event.Timestamp.IsZero() {
+		t.Errorf("stored event = %+v, want its author, ID and timestamp in the clear", event)
+	}
+	if parts := event.Content.Parts; len(parts) != 1 || parts[0].InlineData == nil || parts[0].InlineData.MIMEType != MIMEType ||
+		bytes.Contains(parts[0].InlineData.Data, []byte("secret plans")) {
+		t.Errorf("stored content = %+v, want an encrypted part", event.Content)
+	}
+
+	got, err := s.Get(t.Context(), &session.GetRequest{AppName: "app", UserID: "user", SessionID: "session"})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if diff := cmp.Diff(map[string]any{"city": "Paris", "nights": 3.0, "user:name": "Ada"}, stateOf(got.Session)); diff != "" {
+		t.Errorf("Get() state mismatch (-want +got):\n%s", diff)
+	}
+	gotEvent := got.Session.Events().At(0)
+	if diff := cmp.Diff(genai.NewContentFromText("secret plans", genai.RoleUser), gotEvent.Content); diff != "" {
+		t.Errorf("Get() content mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string]any{"user:name": "Ada"}, gotEvent.Actions.StateDelta); diff != "" {
+		t.Errorf("Get() state delta mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestService_KeyRotation(t *testing.T) {
+	inner := session.InMemoryService()
+	appendToInner(t, newTestService(t, inner, Keyring{Primary: oldKey}), inner, map[string]any{"city": "Paris"}, "written with the old key")
+
+	rotated := newTestService(t, inner, Keyring{Primary: newKey, Previous: []Key{oldKey}})
+	got, err := rotated.Get(t.Context(), &session.GetRequest{AppName: "app", UserID: "user", SessionID: "session"})
+	if err != nil {
+		t.Fatalf("Get() after the rotation error = %v", err)
+	}
+	event := session.NewEvent("inv")
+	event.Timestamp = time.Now().Add(time.Second)
+	event.LLMResponse = model.LLMResponse{Content: genai.NewContentFromText("written with the new key", genai.RoleModel)}
+	if err := rotated.AppendEvent(t.Context(), got.Session, event); err != nil {
+		t.Fatalf("AppendEvent() after the rotation error = %v", err)
+	}
+	got, err = rotated.Get(t.Context(), &session.GetRequest{AppName: "app", UserID: "user", SessionID: "session"})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	var texts []string
+	for event := range got.Session.Events().All() {
+		texts = append(texts, event.Content.Parts[0].Text)
+	}
+	if diff := cmp.Diff([]string{"written with the old key", "written with the new key"}, texts); diff != "" {
+		t.Errorf("Get() texts mismatch (-want +got):\n%s", diff)
+	}
+
+	// Without the old key, the old records can't be read.
+	_, err = newTestService(t, inner, Keyring{Primary: newKey}).Get(t.Context(), &session.GetRequest{AppName: "app", UserID: "user", SessionID: "session"})
+	var decErr *DecryptionError
+	if !errors.As(err, &decErr) || decErr.KeyID != "old" || !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Get() without the old key error = %v, want a DecryptionError of the unknown old key", err)
+	}
+}
+
+func TestService_Corrupted(t *testing.T) {
+	tamper := func(value string) string {
+		record, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, ValuePrefix))
+		if err != nil {
+			panic(err)
+		}
+		record[len(record)-1] ^= 1
+		return ValuePrefix + base64.StdEncoding.EncodeToString(record)
+	}
+	tests := []struct {
+		name    string
+		corrupt func(stored session.Session) any
+	}{
+		{name: "not base64", corrupt: func(session.Session) any { return ValuePrefix + "!!!" }},
+		{name: "malformed", corrupt: func(session.Session) any { return ValuePrefix + base64.StdEncoding.EncodeToString([]byte{9}) }},
+		{name: "truncated", corrupt: func(session.Session) any {
+			return ValuePrefix + base64.StdEncoding.EncodeToString([]byte{envelopeVersion, 3, 'n', 'e', 'w', 1, 2})
+		}},
+		{name: "tampered", corrupt: func(stored session.Session) any {
+			value, _ := stored.State().Get("city")
+			return tamper(value.(string))
+		}},
+		{name: "moved to another key", corrupt: func(stored session.Session) any {
+			value, _ := stored.State().Get("city")
+			return value
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner := session.InMemoryService()
+			s := newTestService(t, inner, Keyring{Primary: newKey})
+			stored := appendToInner(t, s, inner, map[string]any{"city": "Paris"}, "hi")
+			event := session.NewEvent("inv")
+			event.Timestamp = time.Now().Add(time.Second)
+			event.Actions.StateDelta = map[string]any{"corrupted": tt.corrupt(stored)}
+			if err := inner.AppendEvent(t.Context(), stored, event); err != nil {
+				t.Fatalf("inner AppendEvent() error = %v", err)
+			}
+
+			_, err := s.Get(t.Context(), &session.GetRequest{AppName: "app", UserID: "user", SessionID: "session"})
+			var decErr *DecryptionError
+			if !errors.As(err, &decErr) {
+				t.Errorf("Get() error = %v, want a DecryptionError", err)
+			}
+		})
+	}
+}
+
+func TestService_Plaintext(t *testing.T) {
+	inner := session.InMemoryService()
+	created, err := inner.Create(t.Context(), &session.CreateRequest{AppName: "app", UserID: "user", SessionID: "session", State: map[string]any{"city": "Paris"}})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	event := session.NewEvent("inv")
+	event.LLMResponse = model.LLMResponse{Content: genai.NewContentFromText("before the encryption", genai.RoleUser)}
+	if err := inner.AppendEvent(t.Context(), created.Session, event); err != nil {
+		t.Fatalf("AppendEvent() error = %v", err)
+	}
+
+	got, err := newTestService(t, inner, Keyring{Primary: newKey}).Get(t.Context(), &session.GetRequest{AppName: "app", UserID: "user", SessionID: "session"})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if diff := cmp.Diff(map[string]any{"city": "Paris"}, stateOf(got.Session)); diff != "" {
+		t.Errorf("Get() state mismatch (-want +got):\n%s", diff)
+	}
+	if got := got.Session.Events().At(0).Content.Parts[0].Text; got != "before the encryption" {
+		t.Errorf("Get() text = %q, want %q", got, "before the encryption")
+	}
+}
+
+func stateOf(sess session.Session) map[string]any {
+	state := make(map[string]any)
+	for k, v := range sess.State().All() {
+		state[k] = v
+	}
+	return state
+}
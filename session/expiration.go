@@ -0,0 +1,119 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DefaultSweepInterval is the [ExpirationConfig.SweepInterval] used if it is
+// zero.
+const DefaultSweepInterval = time.Minute
+
+// ExpirationConfig configures the expiration of the sessions of a service.
+//
+// A session expires once its last update is older than ExpireAfter. The
+// expired sessions are invisible to Get, List and AppendEvent, and keep
+// their IDs until the sweeps of the [Sweeper] remove them with their
+// events.
+type ExpirationConfig struct {
+	// ExpireAfter is how long after its last update a session expires.
+	// Optional: if zero, the sessions don't expire.
+	ExpireAfter time.Duration
+	// SweepInterval is how often the sweeper started by [Sweeper.Start]
+	// removes the expired sessions.
+	// Optional: if zero, [DefaultSweepInterval] is used.
+	SweepInterval time.Duration
+	// OnSessionExpired is called for every session removed by a sweep, e.g.
+	// to delete its artifacts.
+	// Optional.
+	OnSessionExpired func(ctx context.Context, expired ExpiredSession)
+	// Now returns the current time, e.g. a fake clock in tests. It is the
+	// creation time of the sessions, and the time which their last updates
+	// are compared with.
+	// Optional: if nil, [time.Now] is used.
+	Now func() time.Time
+}
+
+// ExpiredSession identifies a session removed by a sweep.
+type ExpiredSession struct {
+	AppName        string
+	UserID         string
+	SessionID      string
+	LastUpdateTime time.Time
+}
+
+// Sweeper is implemented by the services which expire the sessions, see
+// [ExpirationConfig].
+type Sweeper interface {
+	// Start starts a goroutine which calls Sweep every sweep interval,
+	// until ctx is done. It does nothing if the sessions don't expire.
+	Start(ctx context.Context)
+	// Sweep removes the expired sessions and their events, and calls the
+	// OnSessionExpired hook for each of them.
+	Sweep(ctx context.Context) error
+}
+
+// InMemoryConfig is the configuration of the service returned by
+// [NewInMemoryService].
+type InMemoryConfig struct {
+	Expiration ExpirationConfig
+}
+
+// NewInMemoryService returns an in-memory implementation of the session
+// service, which implements [Sweeper].
+func NewInMemoryService(cfg InMemoryConfig) (Service, error) {
+	expiration, err := cfg.Expiration.WithDefaults()
+	if err != nil {
+		return nil, err
+	}
+	s := InMemoryService().(*inMemoryService)
+	s.expiration = expiration
+	return s, nil
+}
+
+// WithDefaults validates the config, and returns it with the defaults of
+// its optional fields filled in. The services which implement [Sweeper]
+// call it when they are created.
+func (c ExpirationConfig) WithDefaults() (ExpirationConfig, error) {
+	if c.ExpireAfter < 0 {
+		return c, fmt.Errorf("expire after must not be negative, got %v", c.ExpireAfter)
+	}
+	if c.SweepInterval < 0 {
+		return c, fmt.Errorf("sweep interval must not be negative, got %v", c.SweepInterval)
+	}
+	if c.SweepInterval == 0 {
+		c.SweepInterval = DefaultSweepInterval
+	}
+	if c.Now == nil {
+		c.Now = time.Now
+	}
+	return c, nil
+}
+
+// Cutoff returns the time before which the last updates of the sessions
+// are expired, or the zero time if the sessions don't expire.
+func (c ExpirationConfig) Cutoff() time.Time {
+	if c.ExpireAfter == 0 {
+		return time.Time{}
+	}
+	now := time.Now
+	if c.Now != nil {
+		now = c.Now
+	}
+	return now().Add(-c.ExpireAfter)
+}
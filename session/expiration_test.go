@@ -0,0 +1,77 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExpirationConfig_WithDefaults(t *testing.T) {
+	tests := []struct {
+		name              string
+		cfg               ExpirationConfig
+		wantErr           bool
+		wantSweepInterval time.Duration
+	}{
+		{
+			name:              "defaults",
+			wantSweepInterval: DefaultSweepInterval,
+		},
+		{
+			name:              "sweep interval",
+			cfg:               ExpirationConfig{ExpireAfter: time.Hour, SweepInterval: time.Second},
+			wantSweepInterval: time.Second,
+		},
+		{
+			name:    "negative expire after",
+			cfg:     ExpirationConfig{ExpireAfter: -time.Hour},
+			wantErr: true,
+		},
+		{
+			name:    "negative sweep interval",
+			cfg:     ExpirationConfig{SweepInterval: -time.Second},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.cfg.WithDefaults()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("WithDefaults() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.SweepInterval != tt.wantSweepInterval {
+				t.Errorf("WithDefaults() sweep interval = %v, want %v", got.SweepInterval, tt.wantSweepInterval)
+			}
+			if got.Now == nil {
+				t.Errorf("WithDefaults() clock is nil")
+			}
+		})
+	}
+}
+
+func TestExpirationConfig_Cutoff(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	if got := (ExpirationConfig{Now: clock}).Cutoff(); !got.IsZero() {
+		t.Errorf("Cutoff() without expiration = %v, want zero", got)
+	}
+	if got, want := (ExpirationConfig{ExpireAfter: time.Hour, Now: clock}).Cutoff(), now.Add(-time.Hour); !got.Equal(want) {
+		t.Errorf("Cutoff() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"iter"
+	"strings"
+	"time"
+)
+
+// EventFilter selects events of a session. Empty fields match any event, so
+// the zero value matches all events.
+type EventFilter struct {
+	// Author matches events authored by the given agent or user.
+	Author string
+	// Branch matches events of the given branch and of its sub-branches,
+	// e.g. "root.child" matches "root.child" and "root.child.grandchild".
+	Branch string
+	// InvocationID matches events of the given invocation.
+	InvocationID string
+	// After matches events with a timestamp strictly after it.
+	After time.Time
+	// Before matches events with a timestamp strictly before it.
+	Before time.Time
+}
+
+// Match reports whether the event satisfies all the conditions of the filter.
+func (f EventFilter) Match(e *Event) bool {
+	if e == nil {
+		return false
+	}
+	if f.Author != "" && e.Author != f.Author {
+		return false
+	}
+	if f.Branch != "" && e.Branch != f.Branch && !strings.HasPrefix(e.Branch, f.Branch+".") {
+		return false
+	}
+	if f.InvocationID != "" && e.InvocationID != f.InvocationID {
+		return false
+	}
+	if !f.After.IsZero() && !e.Timestamp.After(f.After) {
+		return false
+	}
+	if !f.Before.IsZero() && !e.Timestamp.Before(f.Before) {
+		return false
+	}
+	return true
+}
+
+// Filter returns an iterator over the events matched by the filter,
+// preserving their order.
+func (f EventFilter) Filter(events Events) iter.Seq[*Event] {
+	return func(yield func(*Event) bool) {
+		for e := range events.All() {
+			if f.Match(e) && !yield(e) {
+				return
+			}
+		}
+	}
+}
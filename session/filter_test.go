@@ -0,0 +1,72 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEventFilter_Match(t *testing.T) {
+	now := time.Now()
+	event := &Event{
+		ID:           "e1",
+		Timestamp:    now,
+		InvocationID: "inv1",
+		Branch:       "root.child",
+		Author:       "child",
+	}
+
+	tests := []struct {
+		name   string
+		filter EventFilter
+		event  *Event
+		want   bool
+	}{
+		{name: "zero filter", event: event, want: true},
+		{name: "nil event", event: nil, want: false},
+		{name: "author", filter: EventFilter{Author: "child"}, event: event, want: true},
+		{name: "other author", filter: EventFilter{Author: "root"}, event: event, want: false},
+		{name: "branch", filter: EventFilter{Branch: "root.child"}, event: event, want: true},
+		{name: "parent branch", filter: EventFilter{Branch: "root"}, event: event, want: true},
+		{name: "branch prefix is not a parent", filter: EventFilter{Branch: "root.ch"}, event: event, want: false},
+		{name: "sub-branch", filter: EventFilter{Branch: "root.child.grandchild"}, event: event, want: false},
+		{name: "invocation", filter: EventFilter{InvocationID: "inv1"}, event: event, want: true},
+		{name: "other invocation", filter: EventFilter{InvocationID: "inv2"}, event: event, want: false},
+		{name: "after", filter: EventFilter{After: now.Add(-time.Second)}, event: event, want: true},
+		{name: "after is exclusive", filter: EventFilter{After: now}, event: event, want: false},
+		{name: "before", filter: EventFilter{Before: now.Add(time.Second)}, event: event, want: true},
+		{name: "before is exclusive", filter: EventFilter{Before: now}, event: event, want: false},
+		{
+			name:   "all conditions",
+			filter: EventFilter{Author: "child", Branch: "root", InvocationID: "inv1", After: now.Add(-time.Second), Before: now.Add(time.Second)},
+			event:  event,
+			want:   true,
+		},
+		{
+			name:   "one condition fails",
+			filter: EventFilter{Author: "child", Branch: "root", InvocationID: "inv2"},
+			event:  event,
+			want:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Match(tt.event); got != tt.want {
+				t.Errorf("EventFilter.Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"google.golang.org/adk/artifact"
+	"google.golang.org/adk/internal/sessionutils"
+)
+
+// KeyForkSource is the key of the state of a forked session which records
+// where it was forked from, a map with the "session_id" key of the source
+// session and the "event_id" key of the last copied event, which is empty
+// if the source session had no events.
+const KeyForkSource = "fork_source"
+
+// ErrEventNotFound is returned by [Fork] when the source session has no
+// event with [ForkRequest.EventID].
+var ErrEventNotFound = errors.New("event not found")
+
+// ForkRequest represents a request to fork a session.
+type ForkRequest struct {
+	// AppName, UserID and SessionID identify the source session. The forked
+	// session belongs to the same app and user.
+	AppName   string
+	UserID    string
+	SessionID string
+	// EventID is the last event of the source session which is copied.
+	// Optional: if empty, all the events are copied.
+	EventID string
+	// NewSessionID is the ID of the forked session.
+	// Optional: if empty, it will be autogenerated.
+	NewSessionID string
+	// Artifacts copies the versions of the artifacts referenced by the
+	// copied events into the forked session. The copies get new version
+	// numbers, which the artifact deltas of the copied events refer to.
+	// Optional: if nil, the artifacts are shared: the copied events
+	// reference the artifacts of the source session, see [KeyForkSource].
+	Artifacts artifact.Service
+}
+
+// ForkResponse represents a response from [Fork].
+type ForkResponse struct {
+	Session Session
+}
+
+// Fork creates a session which branches the conversation of the source
+// session: it copies the events of the source session up to
+// [ForkRequest.EventID], and derives the state of the new session by
+// replaying their state deltas. The state which the source session was
+// created with isn't carried over, since it isn't recorded by any event, and
+// neither are the app and user states, which are shared already. The
+// copied events keep their IDs and timestamps.
+//
+// If a copy fails, the forked session is deleted.
+func Fork(ctx context.Context, s Service, req *ForkRequest) (*ForkResponse, error) {
+	if req.AppName == "" || req.UserID == "" || req.SessionID == "" {
+		return nil, fmt.Errorf("app_name, user_id, session_id are required, got app_name: %q, user_id: %q, session_id: %q", req.AppName, req.UserID, req.SessionID)
+	}
+	source, err := s.Get(ctx, &GetRequest{AppName: req.AppName, UserID: req.UserID, SessionID: req.SessionID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get source session: %w", err)
+	}
+
+	var events []*Event
+	found := false
+	for event := range source.Session.Events().All() {
+		events = append(events, event)
+		if req.EventID != "" && event.ID == req.EventID {
+			found = true
+			break
+		}
+	}
+	if req.EventID != "" && !found {
+		return nil, fmt.Errorf("%w: %q in session %q", ErrEventNotFound, req.EventID, req.SessionID)
+	}
+	lastEventID := ""
+	if len(events) > 0 {
+		lastEventID = events[len(events)-1].ID
+	}
+
+	created, err := s.Create(ctx, &CreateRequest{
+		AppName:   req.AppName,
+		UserID:    req.UserID,
+		SessionID: req.NewSessionID,
+		State: map[string]any{
+			KeyForkSource: map[string]any{"session_id": req.SessionID, "event_id": lastEventID},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create forked session: %w", err)
+	}
+	forked := created.Session
+	if err := copyForkedEvents(ctx, s, req, forked, events); err != nil {
+		deleteReq := &DeleteRequest{AppName: req.AppName, UserID: req.UserID, SessionID: forked.ID()}
+		if deleteErr := s.Delete(ctx, deleteReq); deleteErr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to delete forked session: %w", deleteErr))
+		}
+		return nil, err
+	}
+
+	resp, err := s.Get(ctx, &GetRequest{AppName: req.AppName, UserID: req.UserID, SessionID: forked.ID()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get forked session: %w", err)
+	}
+	return &ForkResponse{Session: resp.Session}, nil
+}
+
+// copyForkedEvents appends copies of the events to the forked session. If
+// requested, it first copies the artifacts the events reference, in version
+// order, and rewrites the artifact deltas of the copies with the versions
+// they were saved as.
+func copyForkedEvents(ctx context.Context, s Service, req *ForkRequest, forked Session, events []*Event) error {
+	copiedVersions := make(map[artifactVersion]int64)
+	if req.Artifacts != nil {
+		var referenced []artifactVersion
+		for _, event := range events {
+			for fileName, version := range event.Actions.ArtifactDelta {
+				av := artifactVersion{fileName: fileName, version: version}
+				// User scoped artifacts are shared by all the sessions of the user.
+				if _, ok := copiedVersions[av]; ok || strings.HasPrefix(fileName, "user:") {
+					continue
+				}
+				copiedVersions[av] = version
+				referenced = append(referenced, av)
+			}
+		}
+		slices.SortFunc(referenced, func(a, b artifactVersion) int {
+			return cmp.Or(cmp.Compare(a.fileName, b.fileName), cmp.Compare(a.version, b.version))
+		})
+		for _, av := range referenced {
+			loaded, err := req.Artifacts.Load(ctx, &artifact.LoadRequest{
+				AppName: req.AppName, UserID: req.UserID, SessionID: req.SessionID, FileName: av.fileName, Version: av.version,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to load artifact %q version %d: %w", av.fileName, av.version, err)
+			}
+			saved, err := req.Artifacts.Save(ctx, &artifact.SaveRequest{
+				AppName: req.AppName, UserID: req.UserID, SessionID: forked.ID(), FileName: av.fileName, Part: loaded.Part,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to copy artifact %q version %d: %w", av.fileName, av.version, err)
+			}
+			copiedVersions[av] = saved.Version
+		}
+	}
+
+	for _, event := range events {
+		if event.Partial {
+			continue
+		}
+		copied := *event
+		// The app and user states are shared with the source session, the
+		// old values of their keys must not overwrite the current ones.
+		_, _, copied.Actions.StateDelta = sessionutils.ExtractStateDeltas(event.Actions.StateDelta)
+		copied.Actions.ArtifactDelta = make(map[string]int64, len(event.Actions.ArtifactDelta))
+		for fileName, version := range event.Actions.ArtifactDelta {
+			if v, ok := copiedVersions[artifactVersion{fileName: fileName, version: version}]; ok {
+				version = v
+			}
+			copied.Actions.ArtifactDelta[fileName] = version
+		}
+		if err := s.AppendEvent(ctx, forked, &copied); err != nil {
+			return fmt.Errorf("failed to copy event %q: %w", event.ID, err)
+		}
+	}
+	return nil
+}
+
+type artifactVersion struct {
+	fileName string
+	version  int64
+}
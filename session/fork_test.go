@@ -0,0 +1,165 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/artifact"
+)
+
+func TestFork(t *testing.T) {
+	ctx := t.Context()
+	base := time.Now().Add(-time.Hour)
+	newEvent := func(id string, i int, actions EventActions) *Event {
+		return &Event{ID: id, Author: "user", Timestamp: base.Add(time.Duration(i) * time.Second), Actions: actions}
+	}
+	setup := func(t *testing.T) (Service, artifact.Service) {
+		t.Helper()
+		s, a := InMemoryService(), artifact.InMemoryService()
+		created, err := s.Create(ctx, &CreateRequest{AppName: "app", UserID: "user", SessionID: "source", State: map[string]any{"initial": true}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, fileName := range []string{"notes", "notes", "user:profile"} {
+			if _, err := a.Save(ctx, &artifact.SaveRequest{AppName: "app", UserID: "user", SessionID: "source", FileName: fileName, Part: genai.NewPartFromText(fileName)}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		events := []*Event{
+			newEvent("e1", 1, EventActions{StateDelta: map[string]any{"step": 1, "user:level": 1}}),
+			newEvent("e2", 2, EventActions{StateDelta: map[string]any{"step": 2}, ArtifactDelta: map[string]int64{"notes": 2, "user:profile": 1}}),
+			newEvent("e3", 3, EventActions{StateDelta: map[string]any{"step": 3, "user:level": 3}}),
+		}
+		for _, event := range events {
+			if err := s.AppendEvent(ctx, created.Session, event); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return s, a
+	}
+
+	tests := []struct {
+		name          string
+		req           ForkRequest
+		copyArtifacts bool
+		wantErr       error
+		wantEvents    []string
+		wantState     map[string]any
+		wantArtifacts []string
+	}{
+		{
+			name:       "all events",
+			req:        ForkRequest{NewSessionID: "fork"},
+			wantEvents: []string{"e1", "e2", "e3"},
+			wantState: map[string]any{
+				KeyForkSource: map[string]any{"session_id": "source", "event_id": "e3"},
+				"step":        3,
+				"user:level":  3,
+			},
+			wantArtifacts: []string{"user:profile"},
+		},
+		{
+			name:       "up to event",
+			req:        ForkRequest{NewSessionID: "fork", EventID: "e2"},
+			wantEvents: []string{"e1", "e2"},
+			wantState: map[string]any{
+				KeyForkSource: map[string]any{"session_id": "source", "event_id": "e2"},
+				"step":        2,
+				"user:level":  3,
+			},
+			wantArtifacts: []string{"user:profile"},
+		},
+		{
+			name:          "copy artifacts",
+			req:           ForkRequest{NewSessionID: "fork", EventID: "e2"},
+			copyArtifacts: true,
+			wantEvents:    []string{"e1", "e2"},
+			wantState: map[string]any{
+				KeyForkSource: map[string]any{"session_id": "source", "event_id": "e2"},
+				"step":        2,
+				"user:level":  3,
+			},
+			wantArtifacts: []string{"notes", "user:profile"},
+		},
+		{
+			name:    "unknown event",
+			req:     ForkRequest{NewSessionID: "fork", EventID: "missing"},
+			wantErr: ErrEventNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, a := setup(t)
+			req := tt.req
+			req.AppName, req.UserID, req.SessionID = "app", "user", "source"
+			if tt.copyArtifacts {
+				req.Artifacts = a
+			}
+			resp, err := Fork(ctx, s, &req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Fork() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if _, err := s.Get(ctx, &GetRequest{AppName: "app", UserID: "user", SessionID: "fork"}); err == nil {
+					t.Error("Get() of the failed fork succeeded, want error")
+				}
+				return
+			}
+
+			var gotEvents []string
+			for event := range resp.Session.Events().All() {
+				gotEvents = append(gotEvents, event.ID)
+			}
+			if diff := cmp.Diff(tt.wantEvents, gotEvents); diff != "" {
+				t.Errorf("Fork() events mismatch (-want +got):\n%s", diff)
+			}
+			gotState := make(map[string]any)
+			for k, v := range resp.Session.State().All() {
+				gotState[k] = v
+			}
+			if diff := cmp.Diff(tt.wantState, gotState); diff != "" {
+				t.Errorf("Fork() state mismatch (-want +got):\n%s", diff)
+			}
+
+			list, err := a.List(ctx, &artifact.ListRequest{AppName: "app", UserID: "user", SessionID: "fork"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.wantArtifacts, list.FileNames); diff != "" {
+				t.Errorf("forked artifacts mismatch (-want +got):\n%s", diff)
+			}
+			if tt.copyArtifacts {
+				// The second version of notes was copied as the first one.
+				e2 := resp.Session.Events().At(1)
+				if diff := cmp.Diff(map[string]int64{"notes": 1, "user:profile": 1}, e2.Actions.ArtifactDelta); diff != "" {
+					t.Errorf("copied artifact delta mismatch (-want +got):\n%s", diff)
+				}
+				loaded, err := a.Load(ctx, &artifact.LoadRequest{AppName: "app", UserID: "user", SessionID: "fork", FileName: "notes", Version: 1})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := loaded.Part.Text; got != "notes" {
+					t.Errorf("copied artifact = %q, want %q", got, "notes")
+				}
+			}
+		})
+	}
+}
@@ -41,6 +41,8 @@ type inMemoryService struct {
 	sessions  omap.Map[string, *session] // session.ID) -> storedSession
 	userState map[string]map[string]stateMap
 	appState  map[string]stateMap
+
+	expiration ExpirationConfig
 }
 
 func (s *inMemoryService) Create(ctx context.Context, req *CreateRequest) (*CreateResponse, error) {
@@ -67,24 +69,21 @@ func (s *inMemoryService) Create(ctx context.Context, req *CreateRequest) (*Crea
 		return nil, fmt.Errorf("session %s already exists", req.SessionID)
 	}
 
-	state := req.State
-	if state == nil {
-		state = make(stateMap)
-	}
+	// Only the session-scoped keys are stored with the session, the
+	// temporary keys are dropped.
+	appDelta, userDelta, sessionState := sessionutils.ExtractStateDeltas(req.State)
 	val := &session{
 		id:        key,
-		state:     state,
-		updatedAt: time.Now(),
+		state:     sessionState,
+		updatedAt: s.expiration.Now(),
 	}
 
 	s.sessions.Set(encodedKey, val)
-	appDelta, userDelta, _ := sessionutils.ExtractStateDeltas(req.State)
 	appState := s.updateAppState(appDelta, req.AppName)
 	userState := s.updateUserState(userDelta, req.AppName, req.UserID)
-	val.state = sessionutils.MergeStates(appState, userState, state)
 
 	copiedSession := copySessionWithoutStateAndEvents(val)
-	copiedSession.state = maps.Clone(val.state)
+	copiedSession.state = sessionutils.MergeStates(appState, userState, sessionState)
 	copiedSession.events = slices.Clone(val.events)
 
 	return &CreateResponse{
@@ -108,7 +107,7 @@ func (s *inMemoryService) Get(ctx context.Context, req *GetRequest) (*GetRespons
 	}
 
 	res, ok := s.sessions.Get(id.Encode())
-	if !ok {
+	if !ok || res.updatedAt.Before(s.expiration.Cutoff()) {
 		return nil, fmt.Errorf("session %+v not found", req.SessionID)
 	}
 
@@ -156,6 +155,7 @@ func (s *inMemoryService) List(ctx context.Context, req *ListRequest) (*ListResp
 		hi = id{appName: appName, userID: userID + "\x00"}.Encode()
 	}
 
+	cutoff := s.expiration.Cutoff()
 	sessions := make([]Session, 0)
 	for k, storedSession := range s.sessions.Scan(lo, hi) {
 		var key id
@@ -166,13 +166,37 @@ func (s *inMemoryService) List(ctx context.Context, req *ListRequest) (*ListResp
 		if key.appName != appName && key.userID != userID {
 			break
 		}
+		if storedSession.updatedAt.Before(cutoff) {
+			continue
+		}
 		copiedSession := copySessionWithoutStateAndEvents(storedSession)
 		copiedSession.state = s.mergeStates(storedSession.state, appName, storedSession.UserID())
 		sessions = append(sessions, copiedSession)
 	}
-	return &ListResponse{
-		Sessions: sessions,
-	}, nil
+	if req.PageSize <= 0 && req.PageToken == "" {
+		return &ListResponse{
+			Sessions: sessions,
+		}, nil
+	}
+
+	offset, err := sessionutils.DecodePageToken(req.PageToken)
+	if err != nil {
+		return nil, err
+	}
+	// Sort by the update time, then by the ID, so that pages are stable.
+	slices.SortStableFunc(sessions, func(a, b Session) int {
+		if c := b.LastUpdateTime().Compare(a.LastUpdateTime()); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID(), b.ID())
+	})
+	sessions = sessions[min(offset, len(sessions)):]
+	resp := &ListResponse{Sessions: sessions}
+	if req.PageSize > 0 && len(sessions) > req.PageSize {
+		resp.Sessions = sessions[:req.PageSize]
+		resp.NextPageToken = sessionutils.EncodePageToken(offset + req.PageSize)
+	}
+	return resp, nil
 }
 
 func (s *inMemoryService) Delete(ctx context.Context, req *DeleteRequest) error {
@@ -194,6 +218,68 @@ func (s *inMemoryService) Delete(ctx context.Context, req *DeleteRequest) error
 	return nil
 }
 
+// Search implements [Searcher] by matching every event and state value of
+// the sessions of the user.
+func (s *inMemoryService) Search(ctx context.Context, req *SearchRequest) (*SearchResponse, error) {
+	appName, userID := req.AppName, req.UserID
+	if appName == "" || userID == "" {
+		return nil, fmt.Errorf("app_name and user_id are required, got app_name: %q, user_id: %q", appName, userID)
+	}
+	matcher, err := req.Matcher()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	lo := id{appName: appName, userID: userID}.Encode()
+	hi := id{appName: appName, userID: userID + "\x00"}.Encode()
+	cutoff := s.expiration.Cutoff()
+	var results []SearchResult
+	for _, stored := range s.sessions.Scan(lo, hi) {
+		if stored.id.appName != appName || stored.id.userID != userID || stored.updatedAt.Before(cutoff) {
+			continue
+		}
+		for _, event := range stored.events {
+			if result, ok := matcher.MatchEvent(stored.id.sessionID, event); ok {
+				results = append(results, result)
+			}
+		}
+		results = append(results, matcher.MatchState(stored.id.sessionID, stored.updatedAt, stored.state)...)
+	}
+	return req.Page(results)
+}
+
+// DeleteUserData implements [UserDataDeleter].
+func (s *inMemoryService) DeleteUserData(ctx context.Context, req *DeleteUserDataRequest) (*DeleteUserDataResponse, error) {
+	appName, userID := req.AppName, req.UserID
+	if appName == "" || userID == "" {
+		return nil, fmt.Errorf("app_name and user_id are required, got app_name: %q, user_id: %q", appName, userID)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lo := id{appName: appName, userID: userID}.Encode()
+	hi := id{appName: appName, userID: userID + "\x00"}.Encode()
+	var keys []string
+	resp := &DeleteUserDataResponse{}
+	for k, stored := range s.sessions.Scan(lo, hi) {
+		keys = append(keys, k)
+		resp.DeletedEvents += len(stored.events) + len(stored.archived)
+	}
+	for _, k := range keys {
+		s.sessions.Delete(k)
+	}
+	resp.DeletedSessions = len(keys)
+	if _, ok := s.userState[appName][userID]; ok {
+		delete(s.userState[appName], userID)
+		resp.DeletedUserState = true
+	}
+	return resp, nil
+}
+
 func (s *inMemoryService) AppendEvent(ctx context.Context, curSession Session, event *Event) error {
 	if curSession == nil {
 		return fmt.Errorf("session is nil")
@@ -201,7 +287,7 @@ func (s *inMemoryService) AppendEvent(ctx context.Context, curSession Session, e
 	if event == nil {
 		return fmt.Errorf("event is nil")
 	}
-	if event.Partial {
+	if event.Partial && !SavesPartialEvents(ctx) {
 		return nil
 	}
 
@@ -214,9 +300,13 @@ func (s *inMemoryService) AppendEvent(ctx context.Context, curSession Session, e
 	defer s.mu.Unlock()
 
 	stored_session, ok := s.sessions.Get(sess.id.Encode())
-	if !ok {
+	if !ok || stored_session.updatedAt.Before(s.expiration.Cutoff()) {
 		return fmt.Errorf("session not found, cannot apply event")
 	}
+	if stored_session.updatedAt.After(sess.updatedAt) {
+		return fmt.Errorf("%w: last update time from request (%s) is older than in memory (%s)",
+			ErrConcurrentModification, sess.updatedAt.Format(time.RFC3339Nano), stored_session.updatedAt.Format(time.RFC3339Nano))
+	}
 
 	// update the in-memory session
 	if err := sess.appendEvent(event); err != nil {
@@ -235,6 +325,174 @@ func (s *inMemoryService) AppendEvent(ctx context.Context, curSession Session, e
 	return nil
 }
 
+// Compact implements [Compactor].
+func (s *inMemoryService) Compact(ctx context.Context, req *CompactRequest, cfg CompactionConfig) (*CompactResponse, error) {
+	appName, userID, sessionID := req.AppName, req.UserID, req.SessionID
+	if appName == "" || userID == "" || sessionID == "" {
+		return nil, fmt.Errorf("app_name, user_id, session_id are required, got app_name: %q, user_id: %q, session_id: %q", appName, userID, sessionID)
+	}
+	key := id{appName: appName, userID: userID, sessionID: sessionID}.Encode()
+
+	s.mu.RLock()
+	stored, ok := s.sessions.Get(key)
+	if !ok || stored.updatedAt.Before(s.expiration.Cutoff()) {
+		s.mu.RUnlock()
+		return nil, fmt.Errorf("session %+v not found", sessionID)
+	}
+	events := slices.Clone(stored.events)
+	s.mu.RUnlock()
+
+	// The summaries are written without the lock, since they may take long.
+	compactions, err := cfg.Compactions(ctx, events)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, ok = s.sessions.Get(key)
+	if !ok {
+		return nil, fmt.Errorf("session %+v not found", sessionID)
+	}
+	// Events may have been appended meanwhile, but not removed.
+	if len(stored.events) < len(events) || !slices.Equal(stored.events[:len(events)], events) {
+		return nil, fmt.Errorf("%w: the events were compacted concurrently", ErrConcurrentModification)
+	}
+
+	summaries := make(map[*Event]*Event)
+	compacted := make(map[*Event]bool)
+	for _, c := range compactions {
+		summaries[c.Events[0]] = c.Summary
+		for _, event := range c.Events {
+			compacted[event] = true
+		}
+	}
+	kept := make([]*Event, 0, len(stored.events)-len(compacted)+len(compactions))
+	for _, event := range stored.events {
+		if summary, ok := summaries[event]; ok {
+			kept = append(kept, summary)
+		}
+		if !compacted[event] {
+			kept = append(kept, event)
+		} else if cfg.Archive {
+			stored.archived = append(stored.archived, event)
+		}
+	}
+	stored.events = kept
+
+	copiedSession := copySessionWithoutStateAndEvents(stored)
+	copiedSession.state = s.mergeStates(stored.state, appName, userID)
+	copiedSession.events = slices.Clone(stored.events)
+	return &CompactResponse{
+		Session:         copiedSession,
+		CompactedEvents: len(compacted),
+	}, nil
+}
+
+// Start implements [Sweeper].
+func (s *inMemoryService) Start(ctx context.Context) {
+	if s.expiration.ExpireAfter == 0 {
+		return
+	}
+	sessionutils.StartSweeper(ctx, s.expiration.SweepInterval, s.Sweep)
+}
+
+// Sweep implements [Sweeper].
+func (s *inMemoryService) Sweep(ctx context.Context) error {
+	cutoff := s.expiration.Cutoff()
+	if cutoff.IsZero() {
+		return nil
+	}
+
+	var expired []ExpiredSession
+	s.mu.Lock()
+	for _, sess := range s.sessions.All() {
+		if sess.updatedAt.Before(cutoff) {
+			expired = append(expired, ExpiredSession{
+				AppName:        sess.id.appName,
+				UserID:         sess.id.userID,
+				SessionID:      sess.id.sessionID,
+				LastUpdateTime: sess.updatedAt,
+			})
+		}
+	}
+	// The map can't be modified while it is iterated.
+	for _, e := range expired {
+		s.sessions.Delete(id{appName: e.AppName, userID: e.UserID, sessionID: e.SessionID}.Encode())
+	}
+	s.mu.Unlock()
+
+	if s.expiration.OnSessionExpired != nil {
+		for _, e := range expired {
+			s.expiration.OnSessionExpired(ctx, e)
+		}
+	}
+	return nil
+}
+
+// VersionChecking implements [VersionChecker].
+func (s *inMemoryService) VersionChecking() bool {
+	return true
+}
+
+// GetAppState implements [StateService].
+func (s *inMemoryService) GetAppState(ctx context.Context, req *GetAppStateRequest) (*GetAppStateResponse, error) {
+	if req.AppName == "" {
+		return nil, fmt.Errorf("app_name is required, got app_name: %q", req.AppName)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	state := maps.Clone(s.appState[req.AppName])
+	if state == nil {
+		state = make(stateMap)
+	}
+	return &GetAppStateResponse{State: state}, nil
+}
+
+// UpdateAppState implements [StateService].
+func (s *inMemoryService) UpdateAppState(ctx context.Context, req *UpdateAppStateRequest) error {
+	if req.AppName == "" {
+		return fmt.Errorf("app_name is required, got app_name: %q", req.AppName)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.updateAppState(req.Delta, req.AppName)
+	return nil
+}
+
+// GetUserState implements [StateService].
+func (s *inMemoryService) GetUserState(ctx context.Context, req *GetUserStateRequest) (*GetUserStateResponse, error) {
+	if req.AppName == "" || req.UserID == "" {
+		return nil, fmt.Errorf("app_name and user_id are required, got app_name: %q, user_id: %q", req.AppName, req.UserID)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	state := maps.Clone(s.userState[req.AppName][req.UserID])
+	if state == nil {
+		state = make(stateMap)
+	}
+	return &GetUserStateResponse{State: state}, nil
+}
+
+// UpdateUserState implements [StateService].
+func (s *inMemoryService) UpdateUserState(ctx context.Context, req *UpdateUserStateRequest) error {
+	if req.AppName == "" || req.UserID == "" {
+		return fmt.Errorf("app_name and user_id are required, got app_name: %q, user_id: %q", req.AppName, req.UserID)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.updateUserState(req.Delta, req.AppName, req.UserID)
+	return nil
+}
+
 func (s *inMemoryService) updateAppState(appDelta stateMap, appName string) stateMap {
 	innerMap, ok := s.appState[appName]
 	if !ok {
@@ -292,6 +550,8 @@ type session struct {
 	events    []*Event
 	state     map[string]any
 	updatedAt time.Time
+	// archived are the events compacted with [CompactionConfig.Archive].
+	archived []*Event
 }
 
 func (s *session) ID() string {
@@ -313,8 +573,12 @@ func (s *session) State() State {
 	}
 }
 
+// Events returns a snapshot of the events, as the session is appended to
+// while its agents read it, e.g. by the sub-agents of a parallel agent.
 func (s *session) Events() Events {
-	return events(s.events)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return events(s.events[:len(s.events):len(s.events)])
 }
 
 func (s *session) LastUpdateTime() time.Time {
@@ -324,16 +588,16 @@ func (s *session) LastUpdateTime() time.Time {
 	return s.updatedAt
 }
 
+// appendEvent appends the event, the partial events having been filtered by
+// the service.
 func (s *session) appendEvent(event *Event) error {
-	if event.Partial {
-		return nil
-	}
-
 	if err := updateSessionState(s, event); err != nil {
 		return fmt.Errorf("error on appendEvent: %w", err)
 	}
 	processedEvent := trimTempDeltaState(event)
 
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.events = append(s.events, processedEvent)
 	s.updatedAt = event.Timestamp
 	return nil
@@ -430,9 +694,11 @@ func updateSessionState(session *session, event *Event) error {
 	}
 
 	// ensure the session state map is initialized
+	session.mu.Lock()
 	if session.state == nil {
 		session.state = make(map[string]any)
 	}
+	session.mu.Unlock()
 
 	state := session.State()
 	for key, value := range event.Actions.StateDelta {
@@ -455,4 +721,12 @@ func copySessionWithoutStateAndEvents(sess *session) *session {
 	}
 }
 
-var _ Service = (*inMemoryService)(nil)
+var (
+	_ Service         = (*inMemoryService)(nil)
+	_ StateService    = (*inMemoryService)(nil)
+	_ VersionChecker  = (*inMemoryService)(nil)
+	_ Sweeper         = (*inMemoryService)(nil)
+	_ Compactor       = (*inMemoryService)(nil)
+	_ UserDataDeleter = (*inMemoryService)(nil)
+	_ Searcher        = (*inMemoryService)(nil)
+)
@@ -506,6 +506,61 @@ func Test_databaseService_List(t *testing.T) {
 	}
 }
 
+func Test_inMemoryService_ListPagination(t *testing.T) {
+	ctx := t.Context()
+	s := InMemoryService()
+	now := time.Now()
+	var sessions []Session
+	for i := range 5 {
+		created, err := s.Create(ctx, &CreateRequest{AppName: "app", UserID: "user", SessionID: "session" + strconv.Itoa(i)})
+		if err != nil {
+			t.Fatalf("Create() failed: %v", err)
+		}
+		sessions = append(sessions, created.Session)
+	}
+	// The sessions are updated in the reverse order of creation, but the
+	// second one is updated last.
+	for i, sess := range sessions {
+		ts := now.Add(time.Duration(10-i) * time.Second)
+		if i == 1 {
+			ts = now.Add(time.Minute)
+		}
+		event := &Event{ID: "event" + strconv.Itoa(i), Timestamp: ts, LLMResponse: model.LLMResponse{Content: genai.NewContentFromText("hi", genai.RoleUser)}}
+		if err := s.AppendEvent(ctx, sess, event); err != nil {
+			t.Fatalf("AppendEvent() failed: %v", err)
+		}
+	}
+
+	var gotIDs []string
+	var gotPageSizes []int
+	token := ""
+	for {
+		resp, err := s.List(ctx, &ListRequest{AppName: "app", UserID: "user", ListOptions: ListOptions{PageSize: 2, PageToken: token}})
+		if err != nil {
+			t.Fatalf("List() failed: %v", err)
+		}
+		gotPageSizes = append(gotPageSizes, len(resp.Sessions))
+		for _, sess := range resp.Sessions {
+			gotIDs = append(gotIDs, sess.ID())
+		}
+		if resp.NextPageToken == "" {
+			break
+		}
+		token = resp.NextPageToken
+	}
+
+	if diff := cmp.Diff([]string{"session1", "session0", "session2", "session3", "session4"}, gotIDs); diff != "" {
+		t.Errorf("List() session IDs mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]int{2, 2, 1}, gotPageSizes); diff != "" {
+		t.Errorf("List() page sizes mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := s.List(ctx, &ListRequest{AppName: "app", ListOptions: ListOptions{PageSize: 2, PageToken: "invalid"}}); err == nil {
+		t.Errorf("List() with invalid page token succeeded, want error")
+	}
+}
+
 func Test_databaseService_AppendEvent(t *testing.T) {
 	tests := []struct {
 		name              string
@@ -1058,3 +1113,51 @@ func Test_inMemoryService_CreateConcurrentAccess(t *testing.T) {
 		t.Errorf("expected %d 'already exists' errors, but got %d", expectedErrors, errorCount.Load())
 	}
 }
+
+func Test_inMemoryService_AppendEventConcurrentReads(t *testing.T) {
+	s := InMemoryService()
+	created, err := s.Create(t.Context(), &CreateRequest{AppName: "race-app", UserID: "race-user", SessionID: "race-session"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sess := created.Session
+	const events = 64
+
+	// The readers read the session while it is appended to, like the
+	// sub-agents of a parallel agent.
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			for range events {
+				for event := range sess.Events().All() {
+					_ = event.Author
+				}
+				_, _ = sess.State().Get("key")
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		<-start
+		for i := range events {
+			event := NewEvent("invocation")
+			event.Author = "agent"
+			event.Actions.StateDelta = map[string]any{"key": i}
+			if err := s.AppendEvent(t.Context(), sess, event); err != nil {
+				t.Errorf("AppendEvent() error = %v", err)
+				return
+			}
+		}
+	}()
+	close(start)
+	wg.Wait()
+
+	if got := sess.Events().Len(); got != events {
+		t.Errorf("Events().Len() = %d, want %d", got, events)
+	}
+}
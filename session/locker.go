@@ -0,0 +1,120 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrSessionBusy is returned by [Locker.Lock] when another run holds
+// the lock of the session.
+var ErrSessionBusy = errors.New("session is busy with another run")
+
+// LockKey identifies a locked session.
+type LockKey struct {
+	AppName   string
+	UserID    string
+	SessionID string
+}
+
+// Locker serializes the runs of a session, so that concurrent runs don't
+// interleave their events and state deltas. Services can implement it, e.g.
+// with advisory locks of their database, so that the runs are serialized
+// across the replicas of a server.
+type Locker interface {
+	// Lock acquires the lock of the session. If another run holds it, Lock
+	// waits for at most wait, or until ctx is done, and then fails with
+	// [ErrSessionBusy]. unlock releases the lock; it must be called exactly
+	// once.
+	Lock(ctx context.Context, key LockKey, wait time.Duration) (unlock func(), err error)
+}
+
+// InMemoryLocker is a [Locker] local to the process, for servers with a
+// single replica.
+type InMemoryLocker struct {
+	mu    sync.Mutex
+	locks map[LockKey]*sessionLock
+}
+
+type sessionLock struct {
+	// held has a value while the lock is held.
+	held chan struct{}
+	// refs counts the holder and the waiters, the lock is forgotten once
+	// there are none.
+	refs int
+}
+
+// NewInMemoryLocker returns a locker which keeps the locks in memory.
+func NewInMemoryLocker() *InMemoryLocker {
+	return &InMemoryLocker{locks: make(map[LockKey]*sessionLock)}
+}
+
+// Lock implements [Locker].
+func (l *InMemoryLocker) Lock(ctx context.Context, key LockKey, wait time.Duration) (func(), error) {
+	l.mu.Lock()
+	lock, ok := l.locks[key]
+	if !ok {
+		lock = &sessionLock{held: make(chan struct{}, 1)}
+		l.locks[key] = lock
+	}
+	lock.refs++
+	l.mu.Unlock()
+
+	if err := lock.acquire(ctx, wait); err != nil {
+		l.release(key, lock)
+		return nil, err
+	}
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			<-lock.held
+			l.release(key, lock)
+		})
+	}, nil
+}
+
+func (lock *sessionLock) acquire(ctx context.Context, wait time.Duration) error {
+	select {
+	case lock.held <- struct{}{}:
+		return nil
+	default:
+	}
+	if wait <= 0 {
+		return ErrSessionBusy
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case lock.held <- struct{}{}:
+		return nil
+	case <-timer.C:
+		return ErrSessionBusy
+	case <-ctx.Done():
+		return fmt.Errorf("%w: %w", ErrSessionBusy, context.Cause(ctx))
+	}
+}
+
+func (l *InMemoryLocker) release(key LockKey, lock *sessionLock) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	lock.refs--
+	if lock.refs == 0 {
+		delete(l.locks, key)
+	}
+}
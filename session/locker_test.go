@@ -0,0 +1,65 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/adk/session"
+)
+
+func TestInMemoryLocker(t *testing.T) {
+	ctx := context.Background()
+	key := session.LockKey{AppName: "app", UserID: "user", SessionID: "session"}
+	locker := session.NewInMemoryLocker()
+
+	unlock, err := locker.Lock(ctx, key, 0)
+	if err != nil {
+		t.Fatalf("Lock() failed: %v", err)
+	}
+	if _, err := locker.Lock(ctx, key, 0); !errors.Is(err, session.ErrSessionBusy) {
+		t.Errorf("Lock() of a held lock = %v, want %v", err, session.ErrSessionBusy)
+	}
+	if _, err := locker.Lock(ctx, key, 10*time.Millisecond); !errors.Is(err, session.ErrSessionBusy) {
+		t.Errorf("Lock() of a held lock with a wait = %v, want %v", err, session.ErrSessionBusy)
+	}
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := locker.Lock(cancelled, key, time.Minute); !errors.Is(err, session.ErrSessionBusy) || !errors.Is(err, context.Canceled) {
+		t.Errorf("Lock() with a cancelled context = %v, want %v and %v", err, session.ErrSessionBusy, context.Canceled)
+	}
+	other, err := locker.Lock(ctx, session.LockKey{AppName: "app", UserID: "user", SessionID: "other"}, 0)
+	if err != nil {
+		t.Fatalf("Lock() of another session failed: %v", err)
+	}
+	other()
+
+	time.AfterFunc(10*time.Millisecond, unlock)
+	waited, err := locker.Lock(ctx, key, time.Minute)
+	if err != nil {
+		t.Fatalf("Lock() waiting for the release failed: %v", err)
+	}
+	waited()
+	// Releasing twice is a no-op.
+	waited()
+	again, err := locker.Lock(ctx, key, 0)
+	if err != nil {
+		t.Fatalf("Lock() after the release failed: %v", err)
+	}
+	again()
+}
@@ -0,0 +1,40 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"strings"
+	"testing"
+
+	"google.golang.org/adk/internal/sessionsql"
+)
+
+func TestLoadMigrations_Embedded(t *testing.T) {
+	migrations, err := sessionsql.LoadMigrations(migrationFiles, "migrations")
+	if err != nil {
+		t.Fatalf("LoadMigrations() error = %v", err)
+	}
+	if len(migrations) == 0 || migrations[0].Version != 1 {
+		t.Errorf("LoadMigrations() = %v, want the migrations to start with version 1", migrations)
+	}
+}
+
+func TestEventColumns(t *testing.T) {
+	var row sessionsql.EventRow
+	columns := strings.Count(eventColumns, ",") + 1
+	if len(eventValues(&row)) != columns || len(eventScanDest(&row)) != columns {
+		t.Errorf("row has %d values and %d scan destinations, want %d", len(eventValues(&row)), len(eventScanDest(&row)), columns)
+	}
+}
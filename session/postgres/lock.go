@@ -0,0 +1,78 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"google.golang.org/adk/session"
+)
+
+// lockNotAvailableCode is the SQLSTATE of the errors of the lock requests
+// which exceed lock_timeout.
+const lockNotAvailableCode = "55P03"
+
+// Lock acquires a transaction-level advisory lock of the session, implements
+// session.Locker. The lock is shared by all the services of the database, so
+// that the runs of a session are serialized across replicas. It is held by a
+// transaction, and thus a connection of the pool, until unlock is called.
+func (s *postgresService) Lock(ctx context.Context, key session.LockKey, wait time.Duration) (func(), error) {
+	// The transaction outlives ctx, which only bounds the wait for the lock.
+	tx, err := s.db.BeginTx(context.WithoutCancel(ctx), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	lockID := strconv.Quote(key.AppName) + "/" + strconv.Quote(key.UserID) + "/" + strconv.Quote(key.SessionID)
+	if wait <= 0 {
+		var locked bool
+		err = tx.QueryRowContext(ctx, "SELECT pg_try_advisory_xact_lock(hashtextextended($1, 0))", lockID).Scan(&locked)
+		if err == nil && !locked {
+			err = session.ErrSessionBusy
+		}
+	} else {
+		// SET doesn't take parameters, the timeout is formatted in the query.
+		timeout := max(wait.Milliseconds(), 1)
+		_, err = tx.ExecContext(ctx, "SET LOCAL lock_timeout = "+strconv.FormatInt(timeout, 10))
+		if err == nil {
+			_, err = tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock(hashtextextended($1, 0))", lockID)
+		}
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		var pgErr *pgconn.PgError
+		switch {
+		case errors.Is(err, session.ErrSessionBusy):
+			return nil, err
+		case errors.As(err, &pgErr) && pgErr.Code == lockNotAvailableCode:
+			return nil, session.ErrSessionBusy
+		case ctx.Err() != nil:
+			return nil, fmt.Errorf("%w: %w", session.ErrSessionBusy, context.Cause(ctx))
+		default:
+			return nil, fmt.Errorf("failed to lock session: %w", err)
+		}
+	}
+	var once sync.Once
+	return func() {
+		// Ending the transaction releases the lock.
+		once.Do(func() { _ = tx.Rollback() })
+	}, nil
+}
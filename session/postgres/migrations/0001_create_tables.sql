@@ -0,0 +1,54 @@
+CREATE TABLE sessions (
+	app_name    TEXT        NOT NULL,
+	user_id     TEXT        NOT NULL,
+	id          TEXT        NOT NULL,
+	state       JSONB       NOT NULL DEFAULT '{}',
+	create_time TIMESTAMPTZ NOT NULL,
+	update_time TIMESTAMPTZ NOT NULL,
+	PRIMARY KEY (app_name, user_id, id)
+);
+
+CREATE INDEX sessions_app_name_update_time_idx ON sessions (app_name, update_time DESC, id);
+
+CREATE TABLE events (
+	app_name              TEXT        NOT NULL,
+	user_id               TEXT        NOT NULL,
+	session_id            TEXT        NOT NULL,
+	id                    TEXT        NOT NULL,
+	-- seq orders the events of a session as they were appended.
+	seq                   BIGINT      GENERATED ALWAYS AS IDENTITY,
+	invocation_id         TEXT        NOT NULL,
+	author                TEXT        NOT NULL,
+	branch                TEXT        NOT NULL DEFAULT '',
+	timestamp             TIMESTAMPTZ NOT NULL,
+	content               JSONB,
+	actions               JSONB       NOT NULL DEFAULT '{}',
+	long_running_tool_ids JSONB,
+	grounding_metadata    JSONB,
+	citation_metadata     JSONB,
+	usage_metadata        JSONB,
+	custom_metadata       JSONB,
+	partial               BOOLEAN     NOT NULL DEFAULT FALSE,
+	turn_complete         BOOLEAN     NOT NULL DEFAULT FALSE,
+	interrupted           BOOLEAN     NOT NULL DEFAULT FALSE,
+	error_code            TEXT        NOT NULL DEFAULT '',
+	error_message         TEXT        NOT NULL DEFAULT '',
+	PRIMARY KEY (app_name, user_id, session_id, id),
+	FOREIGN KEY (app_name, user_id, session_id) REFERENCES sessions (app_name, user_id, id) ON DELETE CASCADE
+);
+
+CREATE INDEX events_session_seq_idx ON events (app_name, user_id, session_id, seq);
+
+CREATE TABLE app_states (
+	app_name    TEXT        NOT NULL PRIMARY KEY,
+	state       JSONB       NOT NULL DEFAULT '{}',
+	update_time TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE user_states (
+	app_name    TEXT        NOT NULL,
+	user_id     TEXT        NOT NULL,
+	state       JSONB       NOT NULL DEFAULT '{}',
+	update_time TIMESTAMPTZ NOT NULL,
+	PRIMARY KEY (app_name, user_id)
+);
@@ -0,0 +1,95 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/internal/sessionsql"
+	"google.golang.org/adk/session"
+)
+
+// eventText extracts the text of the parts of the content of an event,
+// joined by newlines.
+const eventText = `(SELECT string_agg(p->>'text', E'\n') FROM jsonb_array_elements(
+	CASE WHEN jsonb_typeof(e.content->'parts') = 'array' THEN e.content->'parts' ELSE '[]'::jsonb END) AS p)`
+
+// Search searches the sessions of the user, implements session.Searcher.
+// The events and the sessions are preselected with ILIKE.
+func (s *postgresService) Search(ctx context.Context, req *session.SearchRequest) (*session.SearchResponse, error) {
+	appName, userID := req.AppName, req.UserID
+	if appName == "" || userID == "" {
+		return nil, fmt.Errorf("app_name and user_id are required, got app_name: %q, user_id: %q", appName, userID)
+	}
+	matcher, err := req.Matcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ensureSchema(ctx); err != nil {
+		return nil, err
+	}
+	keywords := matcher.Keywords()
+	var results []session.SearchResult
+
+	conds, args := sessionsql.LikeConditions("text", "ILIKE", keywords, 3)
+	rows, err := s.db.QueryContext(ctx, `WITH texts AS (
+	SELECT e.session_id, e.id, e.timestamp, e.content, `+eventText+` AS text
+	FROM events AS e
+	WHERE e.app_name = $1 AND e.user_id = $2
+)
+SELECT session_id, id, timestamp, content FROM texts WHERE `+conds,
+		append([]any{appName, userID}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("database error while searching events: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			sessionID string
+			event     session.Event
+			content   []byte
+		)
+		if err := rows.Scan(&sessionID, &event.ID, &event.Timestamp, &content); err != nil {
+			return nil, fmt.Errorf("database error while searching events: %w", err)
+		}
+		event.Content = &genai.Content{}
+		if err := json.Unmarshal(content, event.Content); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal content of event %q: %w", event.ID, err)
+		}
+		if result, ok := matcher.MatchEvent(sessionID, &event); ok {
+			results = append(results, result)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("database error while searching events: %w", err)
+	}
+
+	conds, args = sessionsql.LikeConditions("(j.value #>> '{}')", "ILIKE", keywords, 3)
+	sessions, err := s.fetchSessions(ctx, appName, `SELECT user_id, id, state, update_time FROM sessions
+WHERE app_name = $1 AND user_id = $2
+	AND EXISTS (SELECT 1 FROM jsonb_each(state) AS j WHERE jsonb_typeof(j.value) = 'string' AND `+conds+`)`,
+		append([]any{appName, userID}, args...))
+	if err != nil {
+		return nil, err
+	}
+	for _, sess := range sessions {
+		results = append(results, matcher.MatchState(sess.ID(), sess.LastUpdateTime(), sess.StateMap())...)
+	}
+	return req.Page(results)
+}
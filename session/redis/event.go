@@ -0,0 +1,129 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redis
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"google.golang.org/adk/internal/sessionsql"
+	"google.golang.org/adk/session"
+)
+
+// storedEvent is the JSON of an event in the events list of a session.
+type storedEvent struct {
+	ID                 string          `json:"id"`
+	InvocationID       string          `json:"invocationId,omitempty"`
+	Author             string          `json:"author,omitempty"`
+	Branch             string          `json:"branch,omitempty"`
+	Timestamp          int64           `json:"timestamp"` // Microseconds since the Unix epoch.
+	Content            json.RawMessage `json:"content,omitempty"`
+	Actions            json.RawMessage `json:"actions,omitempty"`
+	LongRunningToolIDs json.RawMessage `json:"longRunningToolIds,omitempty"`
+	GroundingMetadata  json.RawMessage `json:"groundingMetadata,omitempty"`
+	CitationMetadata   json.RawMessage `json:"citationMetadata,omitempty"`
+	UsageMetadata      json.RawMessage `json:"usageMetadata,omitempty"`
+	CustomMetadata     json.RawMessage `json:"customMetadata,omitempty"`
+	Partial            bool            `json:"partial,omitempty"`
+	TurnComplete       bool            `json:"turnComplete,omitempty"`
+	Interrupted        bool            `json:"interrupted,omitempty"`
+	ErrorCode          string          `json:"errorCode,omitempty"`
+	ErrorMessage       string          `json:"errorMessage,omitempty"`
+}
+
+func encodeEvent(event *session.Event) (string, error) {
+	row, err := sessionsql.NewEventRow(event)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(storedEvent{
+		ID:                 row.ID,
+		InvocationID:       row.InvocationID,
+		Author:             row.Author,
+		Branch:             row.Branch,
+		Timestamp:          row.Timestamp.UnixMicro(),
+		Content:            row.Content,
+		Actions:            row.Actions,
+		LongRunningToolIDs: row.LongRunningToolIDs,
+		GroundingMetadata:  row.GroundingMetadata,
+		CitationMetadata:   row.CitationMetadata,
+		UsageMetadata:      row.UsageMetadata,
+		CustomMetadata:     row.CustomMetadata,
+		Partial:            row.Partial,
+		TurnComplete:       row.TurnComplete,
+		Interrupted:        row.Interrupted,
+		ErrorCode:          row.ErrorCode,
+		ErrorMessage:       row.ErrorMessage,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal event: %w", err)
+	}
+	return string(data), nil
+}
+
+func decodeEvent(data string) (*session.Event, error) {
+	var stored storedEvent
+	if err := json.Unmarshal([]byte(data), &stored); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal event: %w", err)
+	}
+	row := sessionsql.EventRow{
+		ID:                 stored.ID,
+		InvocationID:       stored.InvocationID,
+		Author:             stored.Author,
+		Branch:             stored.Branch,
+		Timestamp:          time.UnixMicro(stored.Timestamp),
+		Content:            stored.Content,
+		Actions:            stored.Actions,
+		LongRunningToolIDs: stored.LongRunningToolIDs,
+		GroundingMetadata:  stored.GroundingMetadata,
+		CitationMetadata:   stored.CitationMetadata,
+		UsageMetadata:      stored.UsageMetadata,
+		CustomMetadata:     stored.CustomMetadata,
+		Partial:            stored.Partial,
+		TurnComplete:       stored.TurnComplete,
+		Interrupted:        stored.Interrupted,
+		ErrorCode:          stored.ErrorCode,
+		ErrorMessage:       stored.ErrorMessage,
+	}
+	return row.Event()
+}
+
+// encodeState flattens the state into the field and value pairs of a hash,
+// the values being JSON.
+func encodeState(state map[string]any) ([]any, error) {
+	pairs := make([]any, 0, 2*len(state))
+	for k, v := range state {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal state key %q: %w", k, err)
+		}
+		pairs = append(pairs, k, string(data))
+	}
+	return pairs, nil
+}
+
+// decodeState converts the fields of a hash back to a state.
+func decodeState(fields map[string]string) (map[string]any, error) {
+	state := make(map[string]any, len(fields))
+	for k, data := range fields {
+		var v any
+		if err := json.Unmarshal([]byte(data), &v); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal state key %q: %w", k, err)
+		}
+		state[k] = v
+	}
+	return state, nil
+}
@@ -0,0 +1,778 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package redis provides a [session.Service] which stores the sessions in
+// Redis, for stateless deployments.
+//
+// The metadata and the state of each session are stored in hashes, and its
+// events in a list. The sessions of each app and of each user are indexed by
+// sorted sets ordered by their last update time. The keys of an app share a
+// hash tag, so the service also works with Redis Cluster.
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	goredis "github.com/redis/go-redis/v9"
+
+	"google.golang.org/adk/internal/sessioninternal"
+	"google.golang.org/adk/internal/sessionutils"
+	"google.golang.org/adk/session"
+)
+
+// DefaultKeyPrefix is the prefix of the keys if [Config.KeyPrefix] is empty.
+const DefaultKeyPrefix = "adk:"
+
+// Config is the configuration of the Redis session service.
+type Config struct {
+	// Client is the Redis client, e.g. a [goredis.Client] or a
+	// [goredis.ClusterClient].
+	Client goredis.UniversalClient
+	// KeyPrefix is prepended to all the keys, so that several deployments
+	// can share a Redis.
+	// Optional: if empty, [DefaultKeyPrefix] is used.
+	KeyPrefix string
+	// TTL is the time after which the sessions which aren't appended to
+	// expire. Every append refreshes it. The app and user states don't expire.
+	// Optional: if zero, the sessions don't expire.
+	TTL time.Duration
+}
+
+// redisService is a Redis implementation of session.Service.
+type redisService struct {
+	client goredis.UniversalClient
+	prefix string
+	ttl    time.Duration
+}
+
+// NewSessionService creates a new [session.Service] implementation which
+// stores the sessions in Redis.
+func NewSessionService(cfg Config) (session.Service, error) {
+	if cfg.Client == nil {
+		return nil, fmt.Errorf("redis client is required")
+	}
+	if cfg.TTL < 0 {
+		return nil, fmt.Errorf("ttl must not be negative, got %v", cfg.TTL)
+	}
+	prefix := cfg.KeyPrefix
+	if prefix == "" {
+		prefix = DefaultKeyPrefix
+	}
+	return &redisService{client: cfg.Client, prefix: prefix, ttl: cfg.TTL}, nil
+}
+
+// sessionKeys are the keys of a session.
+type sessionKeys struct {
+	// meta holds the create_time and update_time fields of the session, in
+	// microseconds since the Unix epoch.
+	meta string
+	// state holds the session state, with a JSON value per key.
+	state string
+	// events lists the JSON of the events in the order they were appended.
+	events string
+	// appIndex and userIndex are the sorted sets of the sessions of the app
+	// and of the user, scored by their update time. The members of appIndex
+	// are appMember, those of userIndex are the session IDs.
+	appIndex  string
+	userIndex string
+	appMember string
+	// appState and userState hold the app and user states.
+	appState  string
+	userState string
+}
+
+// key returns the key of the app made of the parts. The app is the hash tag
+// of the key, and the parts are escaped so that they don't contain colons.
+func (s *redisService) key(appName string, parts ...string) string {
+	var sb strings.Builder
+	sb.WriteString(s.prefix)
+	sb.WriteString("{" + url.QueryEscape(appName) + "}")
+	for _, p := range parts {
+		sb.WriteString(":" + p)
+	}
+	return sb.String()
+}
+
+func (s *redisService) appIndexKey(appName string) string {
+	return s.key(appName, "sessions")
+}
+
+func (s *redisService) userIndexKey(appName, userID string) string {
+	return s.key(appName, "user_sessions", url.QueryEscape(userID))
+}
+
+func (s *redisService) appStateKey(appName string) string {
+	return s.key(appName, "app_state")
+}
+
+func (s *redisService) userStateKey(appName, userID string) string {
+	return s.key(appName, "user_state", url.QueryEscape(userID))
+}
+
+func (s *redisService) sessionKeys(appName, userID, sessionID string) sessionKeys {
+	user, id := url.QueryEscape(userID), url.QueryEscape(sessionID)
+	return sessionKeys{
+		meta:      s.key(appName, "session", user, id),
+		state:     s.key(appName, "state", user, id),
+		events:    s.key(appName, "events", user, id),
+		appIndex:  s.appIndexKey(appName),
+		userIndex: s.userIndexKey(appName, userID),
+		appMember: user + ":" + id,
+		appState:  s.appStateKey(appName),
+		userState: s.userStateKey(appName, userID),
+	}
+}
+
+// parseAppMember returns the user and session IDs of a member of the app
+// index.
+func parseAppMember(member string) (userID, sessionID string, err error) {
+	user, id, ok := strings.Cut(member, ":")
+	if !ok {
+		return "", "", fmt.Errorf("invalid session index member %q", member)
+	}
+	if userID, err = url.QueryUnescape(user); err != nil {
+		return "", "", fmt.Errorf("invalid session index member %q: %w", member, err)
+	}
+	if sessionID, err = url.QueryUnescape(id); err != nil {
+		return "", "", fmt.Errorf("invalid session index member %q: %w", member, err)
+	}
+	return userID, sessionID, nil
+}
+
+// Script errors.
+const (
+	errExists   = "ADK_SESSION_EXISTS"
+	errNotFound = "ADK_SESSION_NOT_FOUND"
+	errStale    = "ADK_SESSION_STALE"
+)
+
+// stateArgs are the Lua code which reads the state deltas passed as
+// arguments of the scripts, each as a count followed by field and value
+// pairs, and merges them into the hashes.
+const stateArgs = `
+local function merge(key)
+	local n = tonumber(ARGV[i])
+	i = i + 1
+	for _ = 1, n do
+		redis.call('HSET', key, ARGV[i], ARGV[i + 1])
+		i = i + 2
+	end
+end
+`
+
+// createScript creates a session.
+//
+// KEYS: meta, state, appIndex, userIndex, appState, userState.
+// ARGV: now, ttl in milliseconds, appMember, session ID, then the session,
+// app and user states.
+var createScript = goredis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	return redis.error_reply('` + errExists + `')
+end
+local i = 5
+` + stateArgs + `
+redis.call('HSET', KEYS[1], 'create_time', ARGV[1], 'update_time', ARGV[1])
+merge(KEYS[2])
+merge(KEYS[5])
+merge(KEYS[6])
+redis.call('ZADD', KEYS[3], ARGV[1], ARGV[3])
+redis.call('ZADD', KEYS[4], ARGV[1], ARGV[4])
+local ttl = tonumber(ARGV[2])
+if ttl > 0 then
+	redis.call('PEXPIRE', KEYS[1], ttl)
+	redis.call('PEXPIRE', KEYS[2], ttl)
+end
+return 1
+`)
+
+// appendScript appends an event to a session and merges its state delta,
+// unless the session was updated after the last update known by the caller.
+//
+// KEYS: meta, state, events, appIndex, userIndex, appState, userState.
+// ARGV: last known update time, new update time, ttl in milliseconds,
+// appMember, session ID, event, then the session, app and user deltas.
+var appendScript = goredis.NewScript(`
+local updated = redis.call('HGET', KEYS[1], 'update_time')
+if not updated then
+	return redis.error_reply('` + errNotFound + `')
+end
+if tonumber(updated) > tonumber(ARGV[1]) then
+	return redis.error_reply('` + errStale + ` ' .. updated)
+end
+local i = 7
+` + stateArgs + `
+redis.call('HSET', KEYS[1], 'update_time', ARGV[2])
+redis.call('RPUSH', KEYS[3], ARGV[6])
+merge(KEYS[2])
+merge(KEYS[6])
+merge(KEYS[7])
+redis.call('ZADD', KEYS[4], ARGV[2], ARGV[4])
+redis.call('ZADD', KEYS[5], ARGV[2], ARGV[5])
+local ttl = tonumber(ARGV[3])
+if ttl > 0 then
+	redis.call('PEXPIRE', KEYS[1], ttl)
+	redis.call('PEXPIRE', KEYS[2], ttl)
+	redis.call('PEXPIRE', KEYS[3], ttl)
+end
+return 1
+`)
+
+// stateDeltaArgs returns the script arguments of the state deltas.
+func stateDeltaArgs(deltas ...map[string]any) ([]any, error) {
+	var args []any
+	for _, delta := range deltas {
+		pairs, err := encodeState(delta)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, len(pairs)/2)
+		args = append(args, pairs...)
+	}
+	return args, nil
+}
+
+// scriptError returns the script error code of err, if any.
+func scriptError(err error) string {
+	var redisErr goredis.Error
+	if !errors.As(err, &redisErr) {
+		return ""
+	}
+	code, _, _ := strings.Cut(redisErr.Error(), " ")
+	return code
+}
+
+// Create creates a session, implements session.Service.
+// It fails if the session already exists.
+func (s *redisService) Create(ctx context.Context, req *session.CreateRequest) (*session.CreateResponse, error) {
+	if req.AppName == "" || req.UserID == "" {
+		return nil, fmt.Errorf("app_name and user_id are required")
+	}
+
+	sessionID := req.SessionID
+	if sessionID == "" {
+		sessionID = uuid.NewString()
+	}
+	now := time.Now().Truncate(time.Microsecond)
+	appDelta, userDelta, sessionState := sessionutils.ExtractStateDeltas(req.State)
+
+	keys := s.sessionKeys(req.AppName, req.UserID, sessionID)
+	deltaArgs, err := stateDeltaArgs(sessionState, appDelta, userDelta)
+	if err != nil {
+		return nil, err
+	}
+	args := append([]any{now.UnixMicro(), s.ttl.Milliseconds(), keys.appMember, sessionID}, deltaArgs...)
+	err = createScript.Run(ctx, s.client,
+		[]string{keys.meta, keys.state, keys.appIndex, keys.userIndex, keys.appState, keys.userState},
+		args...).Err()
+	if scriptError(err) == errExists {
+		return nil, fmt.Errorf("session %q already exists", sessionID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error creating session on redis: %w", err)
+	}
+
+	appState, userState, err := s.fetchAppAndUserState(ctx, req.AppName, req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("error on create session: %w", err)
+	}
+	return &session.CreateResponse{
+		Session: sessioninternal.NewStoredSession(sessioninternal.StoredSessionConfig{
+			AppName:   req.AppName,
+			UserID:    req.UserID,
+			SessionID: sessionID,
+			State:     sessionutils.MergeStates(appState, userState, sessionState),
+			UpdatedAt: now,
+		}),
+	}, nil
+}
+
+// Get retrieves a session with its events, implements session.Service.
+func (s *redisService) Get(ctx context.Context, req *session.GetRequest) (*session.GetResponse, error) {
+	appName, userID, sessionID := req.AppName, req.UserID, req.SessionID
+	if appName == "" || userID == "" || sessionID == "" {
+		return nil, fmt.Errorf("app_name, user_id, session_id are required, got app_name: %q, user_id: %q, session_id: %q", appName, userID, sessionID)
+	}
+
+	keys := s.sessionKeys(appName, userID, sessionID)
+	// Without a time filter, only the most recent events are fetched.
+	start := int64(0)
+	if req.After.IsZero() && req.NumRecentEvents > 0 {
+		start = -int64(req.NumRecentEvents)
+	}
+	var (
+		updated   *goredis.StringCmd
+		state     *goredis.MapStringStringCmd
+		events    *goredis.StringSliceCmd
+		appState  *goredis.MapStringStringCmd
+		userState *goredis.MapStringStringCmd
+	)
+	_, err := s.client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+		updated = pipe.HGet(ctx, keys.meta, "update_time")
+		state = pipe.HGetAll(ctx, keys.state)
+		events = pipe.LRange(ctx, keys.events, start, -1)
+		appState = pipe.HGetAll(ctx, keys.appState)
+		userState = pipe.HGetAll(ctx, keys.userState)
+		return nil
+	})
+	if errors.Is(err, goredis.Nil) {
+		return nil, fmt.Errorf("session %q not found", sessionID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("redis error while fetching session: %w", err)
+	}
+
+	cfg := sessioninternal.StoredSessionConfig{AppName: appName, UserID: userID, SessionID: sessionID}
+	if cfg.UpdatedAt, err = parseMicros(updated.Val()); err != nil {
+		return nil, err
+	}
+	if cfg.State, err = mergeStoredStates(appState.Val(), userState.Val(), state.Val()); err != nil {
+		return nil, fmt.Errorf("error on get session: %w", err)
+	}
+	for _, data := range events.Val() {
+		event, err := decodeEvent(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to map event: %w", err)
+		}
+		if !req.After.IsZero() && event.Timestamp.Before(req.After) {
+			continue
+		}
+		cfg.Events = append(cfg.Events, event)
+	}
+	if req.NumRecentEvents > 0 && len(cfg.Events) > req.NumRecentEvents {
+		cfg.Events = cfg.Events[len(cfg.Events)-req.NumRecentEvents:]
+	}
+	return &session.GetResponse{Session: sessioninternal.NewStoredSession(cfg)}, nil
+}
+
+// List retrieves the sessions of an app and optionally of a user, most
+// recently updated first, implements session.Service.
+func (s *redisService) List(ctx context.Context, req *session.ListRequest) (*session.ListResponse, error) {
+	appName, userID := req.AppName, req.UserID
+	if appName == "" {
+		return nil, fmt.Errorf("app_name is required, got app_name: %q", req.AppName)
+	}
+	offset, err := sessionutils.DecodePageToken(req.PageToken)
+	if err != nil {
+		return nil, err
+	}
+
+	index := s.appIndexKey(appName)
+	if userID != "" {
+		index = s.userIndexKey(appName, userID)
+	}
+	// Fetch one more session to know whether there is a next page.
+	want := -1
+	if req.PageSize > 0 {
+		want = req.PageSize + 1
+	}
+	sessions, err := s.fetchIndexedSessions(ctx, appName, userID, index, offset, want)
+	if err != nil {
+		return nil, err
+	}
+	nextPageToken := ""
+	if req.PageSize > 0 && len(sessions) > req.PageSize {
+		sessions = sessions[:req.PageSize]
+		nextPageToken = sessionutils.EncodePageToken(offset + req.PageSize)
+	}
+
+	// Fetch the states of the app and of the users of the sessions.
+	var userIDs []string
+	for _, sess := range sessions {
+		if !slices.Contains(userIDs, sess.userID) {
+			userIDs = append(userIDs, sess.userID)
+		}
+	}
+	var (
+		appState   *goredis.MapStringStringCmd
+		userStates = make(map[string]*goredis.MapStringStringCmd)
+	)
+	if _, err := s.client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+		appState = pipe.HGetAll(ctx, s.appStateKey(appName))
+		for _, id := range userIDs {
+			userStates[id] = pipe.HGetAll(ctx, s.userStateKey(appName, id))
+		}
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("error on list sessions: %w", err)
+	}
+
+	responseSessions := make([]session.Session, 0, len(sessions))
+	for _, sess := range sessions {
+		state, err := mergeStoredStates(appState.Val(), userStates[sess.userID].Val(), sess.storedState)
+		if err != nil {
+			return nil, fmt.Errorf("failed to map session %q: %w", sess.sessionID, err)
+		}
+		responseSessions = append(responseSessions, sessioninternal.NewStoredSession(sessioninternal.StoredSessionConfig{
+			AppName:   appName,
+			UserID:    sess.userID,
+			SessionID: sess.sessionID,
+			State:     state,
+			UpdatedAt: sess.updatedAt,
+		}))
+	}
+	return &session.ListResponse{
+		Sessions:      responseSessions,
+		NextPageToken: nextPageToken,
+	}, nil
+}
+
+// indexedSession is a session fetched from an index, whose state isn't yet
+// merged with the app and user states.
+type indexedSession struct {
+	userID, sessionID string
+	updatedAt         time.Time
+	storedState       map[string]string
+}
+
+// fetchIndexedSessions returns at most want sessions of the index, or all of
+// them if want is negative, starting at offset. The expired sessions are
+// removed from the indexes on the way.
+func (s *redisService) fetchIndexedSessions(ctx context.Context, appName, userID, index string, offset, want int) ([]indexedSession, error) {
+	var sessions []indexedSession
+	start := int64(offset)
+	for {
+		stop := int64(-1)
+		if want >= 0 {
+			stop = start + int64(want-len(sessions)) - 1
+		}
+		members, err := s.client.ZRevRange(ctx, index, start, stop).Result()
+		if err != nil {
+			return nil, fmt.Errorf("redis error while fetching sessions: %w", err)
+		}
+
+		type entry struct {
+			userID, sessionID string
+			updated           *goredis.StringCmd
+			state             *goredis.MapStringStringCmd
+		}
+		entries := make([]entry, len(members))
+		for i, member := range members {
+			entries[i].userID, entries[i].sessionID = userID, member
+			if userID == "" {
+				if entries[i].userID, entries[i].sessionID, err = parseAppMember(member); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if _, err := s.client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+			for i := range entries {
+				keys := s.sessionKeys(appName, entries[i].userID, entries[i].sessionID)
+				entries[i].updated = pipe.HGet(ctx, keys.meta, "update_time")
+				entries[i].state = pipe.HGetAll(ctx, keys.state)
+			}
+			return nil
+		}); err != nil && !errors.Is(err, goredis.Nil) {
+			return nil, fmt.Errorf("redis error while fetching sessions: %w", err)
+		}
+
+		var expired []entry
+		for _, e := range entries {
+			updated, err := e.updated.Result()
+			if errors.Is(err, goredis.Nil) {
+				expired = append(expired, e)
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("redis error while fetching sessions: %w", err)
+			}
+			updatedAt, err := parseMicros(updated)
+			if err != nil {
+				return nil, err
+			}
+			sessions = append(sessions, indexedSession{
+				userID:      e.userID,
+				sessionID:   e.sessionID,
+				updatedAt:   updatedAt,
+				storedState: e.state.Val(),
+			})
+			start++
+		}
+		if len(expired) > 0 {
+			if _, err := s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+				for _, e := range expired {
+					keys := s.sessionKeys(appName, e.userID, e.sessionID)
+					pipe.ZRem(ctx, keys.appIndex, keys.appMember)
+					pipe.ZRem(ctx, keys.userIndex, e.sessionID)
+				}
+				return nil
+			}); err != nil {
+				return nil, fmt.Errorf("failed to remove expired sessions from the indexes: %w", err)
+			}
+		}
+		// The expired sessions were removed, so the next members start where
+		// the valid sessions end.
+		if len(expired) == 0 || want < 0 || len(sessions) >= want {
+			return sessions, nil
+		}
+	}
+}
+
+// Ping checks that Redis is reachable.
+func (s *redisService) Ping(ctx context.Context) error {
+	if err := s.client.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("failed to ping redis: %w", err)
+	}
+	return nil
+}
+
+// VersionChecking implements session.VersionChecker: AppendEvent rejects
+// the sessions older than the stored ones.
+func (s *redisService) VersionChecking() bool {
+	return true
+}
+
+// GetAppState returns the state of the app, implements session.StateService.
+func (s *redisService) GetAppState(ctx context.Context, req *session.GetAppStateRequest) (*session.GetAppStateResponse, error) {
+	if req.AppName == "" {
+		return nil, fmt.Errorf("app_name is required, got app_name: %q", req.AppName)
+	}
+	fields, err := s.client.HGetAll(ctx, s.appStateKey(req.AppName)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch app state: %w", err)
+	}
+	state, err := decodeState(fields)
+	if err != nil {
+		return nil, err
+	}
+	return &session.GetAppStateResponse{State: state}, nil
+}
+
+// UpdateAppState merges the delta into the state of the app, implements session.StateService.
+func (s *redisService) UpdateAppState(ctx context.Context, req *session.UpdateAppStateRequest) error {
+	if req.AppName == "" {
+		return fmt.Errorf("app_name is required, got app_name: %q", req.AppName)
+	}
+	return s.updateState(ctx, s.appStateKey(req.AppName), req.Delta)
+}
+
+// GetUserState returns the state of the user, implements session.StateService.
+func (s *redisService) GetUserState(ctx context.Context, req *session.GetUserStateRequest) (*session.GetUserStateResponse, error) {
+	if req.AppName == "" || req.UserID == "" {
+		return nil, fmt.Errorf("app_name and user_id are required, got app_name: %q, user_id: %q", req.AppName, req.UserID)
+	}
+	fields, err := s.client.HGetAll(ctx, s.userStateKey(req.AppName, req.UserID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user state: %w", err)
+	}
+	state, err := decodeState(fields)
+	if err != nil {
+		return nil, err
+	}
+	return &session.GetUserStateResponse{State: state}, nil
+}
+
+// UpdateUserState merges the delta into the state of the user, implements session.StateService.
+func (s *redisService) UpdateUserState(ctx context.Context, req *session.UpdateUserStateRequest) error {
+	if req.AppName == "" || req.UserID == "" {
+		return fmt.Errorf("app_name and user_id are required, got app_name: %q, user_id: %q", req.AppName, req.UserID)
+	}
+	return s.updateState(ctx, s.userStateKey(req.AppName, req.UserID), req.Delta)
+}
+
+// updateState merges the delta into the hash of a state.
+func (s *redisService) updateState(ctx context.Context, key string, delta map[string]any) error {
+	if len(delta) == 0 {
+		return nil
+	}
+	pairs, err := encodeState(delta)
+	if err != nil {
+		return err
+	}
+	if err := s.client.HSet(ctx, key, pairs...).Err(); err != nil {
+		return fmt.Errorf("failed to save state: %w", err)
+	}
+	return nil
+}
+
+// Delete deletes a session and its events, implements session.Service.
+func (s *redisService) Delete(ctx context.Context, req *session.DeleteRequest) error {
+	appName, userID, sessionID := req.AppName, req.UserID, req.SessionID
+	if appName == "" || userID == "" || sessionID == "" {
+		return fmt.Errorf("app_name, user_id, session_id are required, got app_name: %q, user_id: %q, session_id: %q", appName, userID, sessionID)
+	}
+	keys := s.sessionKeys(appName, userID, sessionID)
+	if _, err := s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.Del(ctx, keys.meta, keys.state, keys.events)
+		pipe.ZRem(ctx, keys.appIndex, keys.appMember)
+		pipe.ZRem(ctx, keys.userIndex, sessionID)
+		return nil
+	}); err != nil {
+		return fmt.Errorf("redis error during session deletion: %w", err)
+	}
+	return nil
+}
+
+// DeleteUserData deletes the sessions of the user in batches of
+// session.DeleteUserDataBatchSize, one transaction per batch, then the user
+// state, implements session.UserDataDeleter.
+func (s *redisService) DeleteUserData(ctx context.Context, req *session.DeleteUserDataRequest) (*session.DeleteUserDataResponse, error) {
+	appName, userID := req.AppName, req.UserID
+	if appName == "" || userID == "" {
+		return nil, fmt.Errorf("app_name and user_id are required, got app_name: %q, user_id: %q", appName, userID)
+	}
+	resp := &session.DeleteUserDataResponse{}
+	userIndex := s.userIndexKey(appName, userID)
+	for {
+		sessionIDs, err := s.client.ZRange(ctx, userIndex, 0, session.DeleteUserDataBatchSize-1).Result()
+		if err != nil {
+			return nil, fmt.Errorf("redis error while fetching sessions: %w", err)
+		}
+		if len(sessionIDs) == 0 {
+			break
+		}
+		events := make([]*goredis.IntCmd, len(sessionIDs))
+		metas := make([]*goredis.IntCmd, len(sessionIDs))
+		if _, err := s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+			for i, sessionID := range sessionIDs {
+				keys := s.sessionKeys(appName, userID, sessionID)
+				events[i] = pipe.LLen(ctx, keys.events)
+				// The expired sessions are still indexed, but they have
+				// no keys left and aren't counted.
+				metas[i] = pipe.Del(ctx, keys.meta)
+				pipe.Del(ctx, keys.state, keys.events)
+				pipe.ZRem(ctx, keys.appIndex, keys.appMember)
+				pipe.ZRem(ctx, keys.userIndex, sessionID)
+			}
+			return nil
+		}); err != nil {
+			return nil, fmt.Errorf("redis error during session deletion: %w", err)
+		}
+		for i := range sessionIDs {
+			resp.DeletedEvents += int(events[i].Val())
+			resp.DeletedSessions += int(metas[i].Val())
+		}
+	}
+	n, err := s.client.Del(ctx, s.userStateKey(appName, userID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("redis error during user state deletion: %w", err)
+	}
+	resp.DeletedUserState = n > 0
+	return resp, nil
+}
+
+// AppendEvent appends the event to the session and persists it together with
+// its state delta, implements session.Service.
+func (s *redisService) AppendEvent(ctx context.Context, curSession session.Session, event *session.Event) error {
+	if curSession == nil {
+		return fmt.Errorf("session is nil")
+	}
+	if event == nil {
+		return fmt.Errorf("event is nil")
+	}
+	// ignore partial events, unless the caller opts in
+	if event.Partial && !session.SavesPartialEvents(ctx) {
+		return nil
+	}
+	sess, ok := curSession.(*sessioninternal.StoredSession)
+	if !ok {
+		return fmt.Errorf("unexpected session type %T", curSession)
+	}
+
+	// Truncate timestamp to microsecond precision to match the stored precision.
+	event.Timestamp = event.Timestamp.Truncate(time.Microsecond)
+	lastUpdate := sess.LastUpdateTime()
+
+	if err := sess.AppendEvent(ctx, event); err != nil {
+		return err
+	}
+	// Trim temp state before persisting
+	event = sessioninternal.TrimTempDeltaState(event)
+
+	data, err := encodeEvent(event)
+	if err != nil {
+		return fmt.Errorf("failed to map event to storage model: %w", err)
+	}
+	appDelta, userDelta, sessionDelta := sessionutils.ExtractStateDeltas(event.Actions.StateDelta)
+	deltaArgs, err := stateDeltaArgs(sessionDelta, appDelta, userDelta)
+	if err != nil {
+		return err
+	}
+	keys := s.sessionKeys(sess.AppName(), sess.UserID(), sess.ID())
+	args := append([]any{
+		lastUpdate.UnixMicro(), event.Timestamp.UnixMicro(), s.ttl.Milliseconds(), keys.appMember, sess.ID(), data,
+	}, deltaArgs...)
+	err = appendScript.Run(ctx, s.client,
+		[]string{keys.meta, keys.state, keys.events, keys.appIndex, keys.userIndex, keys.appState, keys.userState},
+		args...).Err()
+	switch scriptError(err) {
+	case errNotFound:
+		return fmt.Errorf("session not found, cannot apply event")
+	case errStale:
+		return fmt.Errorf("%w: last update time from request (%s) is older than in redis", session.ErrConcurrentModification, lastUpdate.Format(time.RFC3339Nano))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save event: %w", err)
+	}
+
+	sess.SetLastUpdateTime(event.Timestamp)
+	return nil
+}
+
+func (s *redisService) fetchAppAndUserState(ctx context.Context, appName, userID string) (appState, userState map[string]any, err error) {
+	var appCmd, userCmd *goredis.MapStringStringCmd
+	if _, err := s.client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+		appCmd = pipe.HGetAll(ctx, s.appStateKey(appName))
+		userCmd = pipe.HGetAll(ctx, s.userStateKey(appName, userID))
+		return nil
+	}); err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch app and user states: %w", err)
+	}
+	if appState, err = decodeState(appCmd.Val()); err != nil {
+		return nil, nil, err
+	}
+	if userState, err = decodeState(userCmd.Val()); err != nil {
+		return nil, nil, err
+	}
+	return appState, userState, nil
+}
+
+// mergeStoredStates decodes and merges the hashes of the states.
+func mergeStoredStates(app, user, sess map[string]string) (map[string]any, error) {
+	appState, err := decodeState(app)
+	if err != nil {
+		return nil, err
+	}
+	userState, err := decodeState(user)
+	if err != nil {
+		return nil, err
+	}
+	sessionState, err := decodeState(sess)
+	if err != nil {
+		return nil, err
+	}
+	return sessionutils.MergeStates(appState, userState, sessionState), nil
+}
+
+func parseMicros(s string) (time.Time, error) {
+	micros, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q: %w", s, err)
+	}
+	return time.UnixMicro(micros), nil
+}
+
+var (
+	_ session.Service         = (*redisService)(nil)
+	_ session.StateService    = (*redisService)(nil)
+	_ session.VersionChecker  = (*redisService)(nil)
+	_ session.UserDataDeleter = (*redisService)(nil)
+)
@@ -0,0 +1,199 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redis
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/go-cmp/cmp"
+	goredis "github.com/redis/go-redis/v9"
+
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/session/sessiontest"
+)
+
+func newTestService(t *testing.T, cfg Config) (session.Service, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	cfg.Client = client
+	s, err := NewSessionService(cfg)
+	if err != nil {
+		t.Fatalf("NewSessionService() error = %v", err)
+	}
+	return s, mr
+}
+
+func TestRedisService_Conformance(t *testing.T) {
+	sessiontest.RunConformance(t, func(t *testing.T) session.Service {
+		s, _ := newTestService(t, Config{})
+		return s
+	})
+	t.Run("TTL", func(t *testing.T) {
+		sessiontest.RunConformance(t, func(t *testing.T) session.Service {
+			s, _ := newTestService(t, Config{TTL: time.Hour})
+			return s
+		})
+	})
+}
+
+func TestNewSessionService(t *testing.T) {
+	client := goredis.NewClient(&goredis.Options{})
+	defer client.Close()
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{name: "no client"},
+		{name: "negative ttl", cfg: Config{Client: client, TTL: -time.Second}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewSessionService(tt.cfg); err == nil {
+				t.Errorf("NewSessionService() succeeded, want error")
+			}
+		})
+	}
+}
+
+func TestRedisService_TTL(t *testing.T) {
+	ctx := t.Context()
+	s, mr := newTestService(t, Config{TTL: time.Hour})
+
+	var sessions []session.Session
+	for i := range 3 {
+		resp, err := s.Create(ctx, &session.CreateRequest{AppName: "app", UserID: "user", SessionID: fmt.Sprint("s", i)})
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		sessions = append(sessions, resp.Session)
+	}
+	meta := s.(*redisService).sessionKeys("app", "user", "s1").meta
+	if got := mr.TTL(meta); got != time.Hour {
+		t.Errorf("TTL after Create() = %v, want %v", got, time.Hour)
+	}
+
+	// Appending refreshes the TTL of s1 only.
+	mr.FastForward(40 * time.Minute)
+	event := &session.Event{ID: "e1", Author: "user", Timestamp: time.Now(), Actions: session.EventActions{StateDelta: map[string]any{"k": "v"}}}
+	if err := s.AppendEvent(ctx, sessions[1], event); err != nil {
+		t.Fatalf("AppendEvent() error = %v", err)
+	}
+	if got := mr.TTL(meta); got != time.Hour {
+		t.Errorf("TTL after AppendEvent() = %v, want %v", got, time.Hour)
+	}
+	mr.FastForward(40 * time.Minute)
+
+	if _, err := s.Get(ctx, &session.GetRequest{AppName: "app", UserID: "user", SessionID: "s0"}); err == nil {
+		t.Errorf("Get() of an expired session succeeded, want error")
+	}
+	got, err := s.Get(ctx, &session.GetRequest{AppName: "app", UserID: "user", SessionID: "s1"})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.Session.Events().Len() != 1 {
+		t.Errorf("Get() returned %d events, want 1", got.Session.Events().Len())
+	}
+
+	// The expired sessions are left out of the lists, and removed from the
+	// indexes.
+	for _, req := range []*session.ListRequest{
+		{AppName: "app", UserID: "user", ListOptions: session.ListOptions{PageSize: 1}},
+		{AppName: "app"},
+	} {
+		resp, err := s.List(ctx, req)
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		var ids []string
+		for _, sess := range resp.Sessions {
+			ids = append(ids, sess.ID())
+		}
+		if diff := cmp.Diff([]string{"s1"}, ids); diff != "" {
+			t.Errorf("List(%+v) mismatch (-want +got):\n%s", req, diff)
+		}
+		if resp.NextPageToken != "" {
+			t.Errorf("List(%+v) NextPageToken = %q, want none", req, resp.NextPageToken)
+		}
+	}
+	keys := s.(*redisService).sessionKeys("app", "user", "s1")
+	for _, index := range []string{keys.appIndex, keys.userIndex} {
+		members, err := mr.ZMembers(index)
+		if err != nil {
+			t.Fatalf("ZMembers(%s) error = %v", index, err)
+		}
+		if len(members) != 1 {
+			t.Errorf("index %s has members %v, want only s1", index, members)
+		}
+	}
+}
+
+func TestRedisService_KeyPrefix(t *testing.T) {
+	ctx := t.Context()
+	mr := miniredis.RunT(t)
+	client := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	first, err := NewSessionService(Config{Client: client, KeyPrefix: "first:"})
+	if err != nil {
+		t.Fatalf("NewSessionService() error = %v", err)
+	}
+	second, err := NewSessionService(Config{Client: client, KeyPrefix: "second:"})
+	if err != nil {
+		t.Fatalf("NewSessionService() error = %v", err)
+	}
+	if _, err := first.Create(ctx, &session.CreateRequest{AppName: "app", UserID: "user", SessionID: "session", State: map[string]any{"app:k": "v"}}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	// The same session can be created with another prefix, and doesn't see
+	// the app state of the first prefix.
+	resp, err := second.Create(ctx, &session.CreateRequest{AppName: "app", UserID: "user", SessionID: "session"})
+	if err != nil {
+		t.Fatalf("Create() with another prefix error = %v", err)
+	}
+	if _, err := resp.Session.State().Get("app:k"); err == nil {
+		t.Errorf("session of the second prefix has the app state of the first prefix")
+	}
+	for _, key := range mr.Keys() {
+		if !strings.HasPrefix(key, "first:") && !strings.HasPrefix(key, "second:") {
+			t.Errorf("key %q has neither prefix", key)
+		}
+	}
+}
+
+func TestRedisService_Keys(t *testing.T) {
+	s := &redisService{prefix: DefaultKeyPrefix}
+	// Colons in the names can't make keys of different sessions collide.
+	a := s.sessionKeys("app", "a:b", "c")
+	b := s.sessionKeys("app", "a", "b:c")
+	if a.meta == b.meta || a.appMember == b.appMember {
+		t.Errorf("sessionKeys() collide: %+v and %+v", a, b)
+	}
+	userID, sessionID, err := parseAppMember(a.appMember)
+	if err != nil {
+		t.Fatalf("parseAppMember() error = %v", err)
+	}
+	if userID != "a:b" || sessionID != "c" {
+		t.Errorf("parseAppMember(%q) = %q, %q, want %q, %q", a.appMember, userID, sessionID, "a:b", "c")
+	}
+	if want := "adk:{app}:session:a%3Ab:c"; a.meta != want {
+		t.Errorf("sessionKeys().meta = %q, want %q", a.meta, want)
+	}
+}
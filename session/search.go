@@ -0,0 +1,188 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"google.golang.org/adk/internal/sessionutils"
+)
+
+// SearchRequest represents a request to search the sessions of a user.
+type SearchRequest struct {
+	AppName string
+	UserID  string
+	// Query is made of keywords separated by spaces. An event matches if its
+	// text contains all the keywords, a state value if it is a string which
+	// contains all of them, ignoring the case.
+	Query string
+
+	ListOptions
+}
+
+// SearchResult is a match of a search.
+type SearchResult struct {
+	SessionID string
+	// EventID is the matching event, or empty if a state value matched.
+	EventID string
+	// StateKey is the key of the matching value of the session state, or
+	// empty if an event matched.
+	StateKey string
+	// Timestamp is the timestamp of the matching event, or the last update
+	// time of the session if a state value matched.
+	Timestamp time.Time
+	// Snippet is an excerpt of the matching text around the first keyword.
+	Snippet string
+}
+
+// SearchResponse represents a response from [Searcher.Search].
+type SearchResponse struct {
+	// Results are ordered by their timestamp, most recent first.
+	Results []SearchResult
+	// NextPageToken is the token of the next page, or empty if this is the last page.
+	NextPageToken string
+}
+
+// Searcher is implemented by the services which can search the text of the
+// events and the values of the session states of the sessions of a user,
+// e.g. for a "search my past conversations" box. The app and user states,
+// shared by all the sessions, aren't searched.
+type Searcher interface {
+	Search(ctx context.Context, req *SearchRequest) (*SearchResponse, error)
+}
+
+// snippetRunes is the maximum length of a snippet, and snippetContext the
+// length of the text kept before the first keyword.
+const (
+	snippetRunes   = 120
+	snippetContext = 40
+)
+
+// SearchMatcher matches the events and the state values against the
+// keywords of a [SearchRequest]. The services which implement [Searcher]
+// use it, after preselecting the candidates with their own queries.
+type SearchMatcher struct {
+	keywords []string
+}
+
+// Matcher returns the matcher of the query of the request. It fails if the
+// query has no keywords.
+func (req *SearchRequest) Matcher() (*SearchMatcher, error) {
+	keywords := strings.Fields(strings.Map(unicode.ToLower, req.Query))
+	if len(keywords) == 0 {
+		return nil, errors.New("query is required")
+	}
+	return &SearchMatcher{keywords: slices.Compact(slices.Sorted(slices.Values(keywords)))}, nil
+}
+
+// Keywords returns the distinct keywords of the query, in lower case.
+func (m *SearchMatcher) Keywords() []string {
+	return slices.Clone(m.keywords)
+}
+
+// MatchEvent matches the text parts of the content of the event.
+func (m *SearchMatcher) MatchEvent(sessionID string, event *Event) (SearchResult, bool) {
+	if event.Content == nil {
+		return SearchResult{}, false
+	}
+	var texts []string
+	for _, part := range event.Content.Parts {
+		if part != nil && part.Text != "" {
+			texts = append(texts, part.Text)
+		}
+	}
+	snippet, ok := m.match(strings.Join(texts, "\n"))
+	if !ok {
+		return SearchResult{}, false
+	}
+	return SearchResult{SessionID: sessionID, EventID: event.ID, Timestamp: event.Timestamp, Snippet: snippet}, true
+}
+
+// MatchState matches the string values of the session state, in the order
+// of their keys.
+func (m *SearchMatcher) MatchState(sessionID string, updatedAt time.Time, state map[string]any) []SearchResult {
+	var results []SearchResult
+	for _, key := range slices.Sorted(maps.Keys(state)) {
+		value, ok := state[key].(string)
+		if !ok {
+			continue
+		}
+		if snippet, ok := m.match(value); ok {
+			results = append(results, SearchResult{SessionID: sessionID, StateKey: key, Timestamp: updatedAt, Snippet: snippet})
+		}
+	}
+	return results
+}
+
+// match reports whether the text contains all the keywords, and returns the
+// snippet around the first one.
+func (m *SearchMatcher) match(text string) (string, bool) {
+	lower := strings.Map(unicode.ToLower, text)
+	first := -1
+	for _, keyword := range m.keywords {
+		i := strings.Index(lower, keyword)
+		if i < 0 {
+			return "", false
+		}
+		if first < 0 || i < first {
+			first = i
+		}
+	}
+	// Lowering keeps the number of runes, so the position in runes is the
+	// same in the text.
+	start := max(utf8.RuneCountInString(lower[:first])-snippetContext, 0)
+	runes := []rune(text)
+	end := min(start+snippetRunes, len(runes))
+	snippet := strings.Join(strings.Fields(string(runes[start:end])), " ")
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(runes) {
+		snippet += "…"
+	}
+	return snippet, true
+}
+
+// Page orders the results, most recent first, and returns the page of the
+// request.
+func (req *SearchRequest) Page(results []SearchResult) (*SearchResponse, error) {
+	offset, err := sessionutils.DecodePageToken(req.PageToken)
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(results, func(a, b SearchResult) int {
+		return cmp.Or(
+			b.Timestamp.Compare(a.Timestamp),
+			strings.Compare(a.SessionID, b.SessionID),
+			strings.Compare(a.EventID, b.EventID),
+			strings.Compare(a.StateKey, b.StateKey),
+		)
+	})
+	results = results[min(offset, len(results)):]
+	resp := &SearchResponse{Results: results}
+	if req.PageSize > 0 && len(results) > req.PageSize {
+		resp.Results = results[:req.PageSize]
+		resp.NextPageToken = sessionutils.EncodePageToken(offset + req.PageSize)
+	}
+	return resp, nil
+}
@@ -16,6 +16,7 @@ package session
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
@@ -28,14 +29,74 @@ type Service interface {
 	List(context.Context, *ListRequest) (*ListResponse, error)
 	Delete(context.Context, *DeleteRequest) error
 	// AppendEvent is used to append an event to a session, and remove temporary state keys from the event.
+	//
+	// The [Session.LastUpdateTime] of the session is the version expected by
+	// the caller. The services which implement [VersionChecker] return
+	// [ErrConcurrentModification] if the stored session was updated after it.
+	//
+	// Partial events aren't persisted, unless the context opts in with
+	// [WithPartialEvents].
 	AppendEvent(context.Context, Session, *Event) error
 }
 
+type partialEventsKey struct{}
+
+// WithPartialEvents returns a context whose appends persist the partial
+// events too, e.g. the chunks of the streamed responses of the model, for
+// the clients reading the session later to replay the stream. The runner
+// uses it for the invocations with the SavePartialEvents option of their
+// run config.
+func WithPartialEvents(ctx context.Context) context.Context {
+	return context.WithValue(ctx, partialEventsKey{}, true)
+}
+
+// SavesPartialEvents reports whether the appends of the context persist the
+// partial events, see [WithPartialEvents].
+func SavesPartialEvents(ctx context.Context) bool {
+	saves, _ := ctx.Value(partialEventsKey{}).(bool)
+	return saves
+}
+
+// ErrConcurrentModification is returned by [Service.AppendEvent] when the
+// stored session has advanced past the session passed to it, e.g. because
+// another replica appended an event in the meantime. The event isn't
+// appended: the caller should get the session again, and append the event
+// to it.
+var ErrConcurrentModification = errors.New("session was modified concurrently")
+
+// VersionChecker is implemented by the services which report whether
+// [Service.AppendEvent] checks the version of the session. The services
+// which return false can't check it cheaply, and append the events
+// regardless of concurrent updates, the last write winning.
+type VersionChecker interface {
+	VersionChecking() bool
+}
+
+// StateService is implemented by the services which persist the app-scoped
+// and user-scoped states independently of the sessions, so that tools and
+// callbacks can read and write them outside of an event.
+//
+// The state deltas of the events and of [CreateRequest.State] update these
+// states too: the keys prefixed with [KeyPrefixApp] go to the state of the
+// app, the keys prefixed with [KeyPrefixUser] to the state of the user, and
+// the keys prefixed with [KeyPrefixTemp] are never persisted. The sessions
+// returned by the services merge the three scopes, with the prefixes added
+// back to the shared keys.
+type StateService interface {
+	GetAppState(context.Context, *GetAppStateRequest) (*GetAppStateResponse, error)
+	// UpdateAppState merges the delta into the state of the app.
+	UpdateAppState(context.Context, *UpdateAppStateRequest) error
+	GetUserState(context.Context, *GetUserStateRequest) (*GetUserStateResponse, error)
+	// UpdateUserState merges the delta into the state of the user.
+	UpdateUserState(context.Context, *UpdateUserStateRequest) error
+}
+
 // InMemoryService returns an in-memory implementation of the session service.
 func InMemoryService() Service {
 	return &inMemoryService{
-		appState:  make(map[string]stateMap),
-		userState: make(map[string]map[string]stateMap),
+		appState:   make(map[string]stateMap),
+		userState:  make(map[string]map[string]stateMap),
+		expiration: ExpirationConfig{Now: time.Now},
 	}
 }
 
@@ -78,11 +139,26 @@ type GetResponse struct {
 type ListRequest struct {
 	AppName string
 	UserID  string
+
+	ListOptions
+}
+
+// ListOptions paginates the sessions returned by [Service.List].
+// Paginated sessions are ordered by their last update time, most recent first.
+type ListOptions struct {
+	// PageSize is the maximum number of sessions to return.
+	// Optional: if zero, all the sessions are returned.
+	PageSize int
+	// PageToken is the [ListResponse.NextPageToken] of the previous page.
+	// Optional: if empty, the first page is returned.
+	PageToken string
 }
 
 // ListResponse represents a response from [Service.List].
 type ListResponse struct {
 	Sessions []Session
+	// NextPageToken is the token of the next page, or empty if this is the last page.
+	NextPageToken string
 }
 
 // DeleteRequest represents a request to delete a session.
@@ -91,3 +167,45 @@ type DeleteRequest struct {
 	UserID    string
 	SessionID string
 }
+
+// GetAppStateRequest represents a request to get the state of an app.
+type GetAppStateRequest struct {
+	AppName string
+}
+
+// GetAppStateResponse represents a response from [StateService.GetAppState].
+type GetAppStateResponse struct {
+	// State is the state of the app, keyed without the [KeyPrefixApp]
+	// prefix. It is empty if the app has no state.
+	State map[string]any
+}
+
+// UpdateAppStateRequest represents a request to update the state of an app.
+type UpdateAppStateRequest struct {
+	AppName string
+	// Delta is merged into the state of the app, its values overwriting the
+	// current ones. Its keys don't have the [KeyPrefixApp] prefix.
+	Delta map[string]any
+}
+
+// GetUserStateRequest represents a request to get the state of a user.
+type GetUserStateRequest struct {
+	AppName string
+	UserID  string
+}
+
+// GetUserStateResponse represents a response from [StateService.GetUserState].
+type GetUserStateResponse struct {
+	// State is the state of the user, keyed without the [KeyPrefixUser]
+	// prefix. It is empty if the user has no state.
+	State map[string]any
+}
+
+// UpdateUserStateRequest represents a request to update the state of a user.
+type UpdateUserStateRequest struct {
+	AppName string
+	UserID  string
+	// Delta is merged into the state of the user, its values overwriting
+	// the current ones. Its keys don't have the [KeyPrefixUser] prefix.
+	Delta map[string]any
+}
@@ -113,7 +113,9 @@ type Event struct {
 	Actions EventActions
 	// Set of IDs of the long running function calls.
 	// Agent client will know from this field about which function call is long running.
-	// Only valid for function call event.
+	// Only valid for function call event, and for the function response
+	// event of pending calls, which ends the invocation until the results of
+	// the calls are submitted.
 	LongRunningToolIDs []string
 }
 
@@ -155,8 +157,22 @@ type EventActions struct {
 	SkipSummarization bool
 	// If set, the event transfers to the specified agent.
 	TransferToAgent string
+	// Transfer is set with TransferToAgent by the transfers between LLM
+	// agents, e.g. for clients to render "handed off to Billing".
+	Transfer *EventTransfer
 	// The agent is escalating to a higher level agent.
 	Escalate bool
+	// If set with Escalate, the escalation ends all the loop agents enclosing
+	// the agent instead of the innermost one.
+	EscalateToRoot bool
+	// If set, the event is the summary of compacted events, see [Compactor].
+	Compaction *EventCompaction
+}
+
+// EventTransfer is the hand-off of an invocation from an agent to another.
+type EventTransfer struct {
+	FromAgent string
+	ToAgent   string
 }
 
 // Prefixes for defining session's state scopes
@@ -0,0 +1,178 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sessiontest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/session"
+)
+
+// RunCompaction runs the suite of the compaction of the events against a
+// service which implements [session.Compactor]. factory returns an empty
+// service:
+//
+//   - Compact replaces every run of at least two events, older than the
+//     [session.CompactionConfig.KeepRecent] most recent ones and without
+//     state or artifact deltas, with a summary event at its position.
+//   - The state and the last update time of the session don't change, and
+//     events can still be appended to the compacted session.
+//   - Compact fails without changing the session if the summarizer fails.
+func RunCompaction(t *testing.T, factory func(t *testing.T) session.Service) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, s session.Service)
+	}{
+		{"Compact", func(t *testing.T, s session.Service) { testCompact(t, s, false) }},
+		{"CompactArchive", func(t *testing.T, s session.Service) { testCompact(t, s, true) }},
+		{"NothingToCompact", testNothingToCompact},
+		{"SummarizerError", testSummarizerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, factory(t))
+		})
+	}
+}
+
+// summarize joins the IDs of the events, recording the calls in calls.
+func summarize(calls *[][]string) session.Summarizer {
+	return func(ctx context.Context, events []*session.Event) (*genai.Content, error) {
+		var ids []string
+		for _, event := range events {
+			ids = append(ids, event.ID)
+		}
+		*calls = append(*calls, ids)
+		return genai.NewContentFromText("summary of "+strings.Join(ids, ","), genai.RoleUser), nil
+	}
+}
+
+// createCompactable creates a session with the events e1 to e7: e3 has a
+// state delta and e6 an artifact delta.
+func createCompactable(t *testing.T, s session.Service) session.Session {
+	t.Helper()
+	sess := mustCreate(t, s, "app", "user", "session")
+	for i := 1; i <= 7; i++ {
+		event := newEvent(fmt.Sprint("e", i), "agent", base.Add(time.Duration(i)*time.Second), "hi")
+		switch i {
+		case 3:
+			event.Actions.StateDelta = map[string]any{"k": "v"}
+		case 6:
+			event.Actions.ArtifactDelta = map[string]int64{"file": 1}
+		}
+		mustAppend(t, s, sess, event)
+	}
+	return mustGet(t, s, "app", "user", "session")
+}
+
+func testCompact(t *testing.T, s session.Service, archive bool) {
+	before := createCompactable(t, s)
+
+	var calls [][]string
+	resp, err := s.(session.Compactor).Compact(t.Context(),
+		&session.CompactRequest{AppName: "app", UserID: "user", SessionID: "session"},
+		session.CompactionConfig{Summarize: summarize(&calls), KeepRecent: 1, Archive: archive})
+	if err != nil {
+		t.Fatalf("Compact() error = %v", err)
+	}
+	// e3 and e6 split the runs, and e7 is recent.
+	if diff := cmp.Diff([][]string{{"e1", "e2"}, {"e4", "e5"}}, calls); diff != "" {
+		t.Errorf("Compact() summarized runs mismatch (-want +got):\n%s", diff)
+	}
+	if resp.CompactedEvents != 4 {
+		t.Errorf("Compact() compacted events = %d, want 4", resp.CompactedEvents)
+	}
+
+	for op, got := range map[string]session.Session{"Compact()": resp.Session, "Get()": mustGet(t, s, "app", "user", "session")} {
+		var texts []string
+		for event := range got.Events().All() {
+			if c := event.Actions.Compaction; c != nil {
+				texts = append(texts, fmt.Sprintf("%s (%d, %s-%s)", event.Content.Parts[0].Text, c.CompactedEvents,
+					c.StartTime.Sub(base), c.EndTime.Sub(base)))
+				if event.Author != "user" || !event.Timestamp.Equal(c.StartTime) {
+					t.Errorf("%s summary author = %q, timestamp = %v, want user at %v", op, event.Author, event.Timestamp, c.StartTime)
+				}
+				continue
+			}
+			texts = append(texts, event.ID)
+		}
+		want := []string{"summary of e1,e2 (2, 1s-2s)", "e3", "summary of e4,e5 (2, 4s-5s)", "e6", "e7"}
+		if diff := cmp.Diff(want, texts); diff != "" {
+			t.Errorf("%s events mismatch (-want +got):\n%s", op, diff)
+		}
+		if diff := cmp.Diff(map[string]any{"k": "v"}, stateOf(got)); diff != "" {
+			t.Errorf("%s state mismatch (-want +got):\n%s", op, diff)
+		}
+		if !got.LastUpdateTime().Equal(before.LastUpdateTime()) {
+			t.Errorf("%s last update time = %v, want %v", op, got.LastUpdateTime(), before.LastUpdateTime())
+		}
+	}
+
+	mustAppend(t, s, resp.Session, newEvent("e8", "user", base.Add(8*time.Second), "hi"))
+	if got := eventIDs(mustGet(t, s, "app", "user", "session")); len(got) != 6 || got[5] != "e8" {
+		t.Errorf("Get() events after an append = %v, want 6 events ending with e8", got)
+	}
+}
+
+func testNothingToCompact(t *testing.T, s session.Service) {
+	createCompactable(t, s)
+
+	var calls [][]string
+	resp, err := s.(session.Compactor).Compact(t.Context(),
+		&session.CompactRequest{AppName: "app", UserID: "user", SessionID: "session"},
+		session.CompactionConfig{Summarize: summarize(&calls)})
+	if err != nil {
+		t.Fatalf("Compact() error = %v", err)
+	}
+	if len(calls) != 0 || resp.CompactedEvents != 0 {
+		t.Errorf("Compact() of recent events summarized %v, compacted %d events, want none", calls, resp.CompactedEvents)
+	}
+	if diff := cmp.Diff([]string{"e1", "e2", "e3", "e4", "e5", "e6", "e7"}, eventIDs(mustGet(t, s, "app", "user", "session"))); diff != "" {
+		t.Errorf("Get() events mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func testSummarizerError(t *testing.T, s session.Service) {
+	createCompactable(t, s)
+
+	errSummary := errors.New("model unavailable")
+	_, err := s.(session.Compactor).Compact(t.Context(),
+		&session.CompactRequest{AppName: "app", UserID: "user", SessionID: "session"},
+		session.CompactionConfig{
+			Summarize: func(ctx context.Context, events []*session.Event) (*genai.Content, error) {
+				return nil, errSummary
+			},
+			KeepRecent: 1,
+		})
+	if !errors.Is(err, errSummary) {
+		t.Errorf("Compact() error = %v, want %v", err, errSummary)
+	}
+	if diff := cmp.Diff([]string{"e1", "e2", "e3", "e4", "e5", "e6", "e7"}, eventIDs(mustGet(t, s, "app", "user", "session"))); diff != "" {
+		t.Errorf("Get() events mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := s.(session.Compactor).Compact(t.Context(),
+		&session.CompactRequest{AppName: "app", UserID: "user", SessionID: "missing"},
+		session.CompactionConfig{Summarize: summarize(new([][]string))}); err == nil {
+		t.Errorf("Compact() of a missing session succeeded, want error")
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sessiontest
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"google.golang.org/adk/session"
+)
+
+// RunExpiration runs the suite of the expiration of the sessions against a
+// service which implements [session.Sweeper]. factory returns an empty
+// service configured with cfg, whose clock the suite advances instead of
+// sleeping:
+//
+//   - The sessions whose last update is older than
+//     [session.ExpirationConfig.ExpireAfter] are invisible to Get, List and
+//     AppendEvent, and Create fails with their IDs until they are swept.
+//   - Sweep removes them with their events, and calls
+//     [session.ExpirationConfig.OnSessionExpired] for each of them.
+//   - Start sweeps every [session.ExpirationConfig.SweepInterval] until its
+//     context is done.
+func RunExpiration(t *testing.T, factory func(t *testing.T, cfg session.ExpirationConfig) session.Service) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, factory func(t *testing.T, cfg session.ExpirationConfig) session.Service)
+	}{
+		{"Invisible", testExpiredInvisible},
+		{"Sweep", testSweep},
+		{"Start", testStartSweeper},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, factory)
+		})
+	}
+}
+
+// fakeClock is the clock of the services under test.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// createExpiring creates the sessions "old" and "fresh" with an event each,
+// and advances the clock so that "old" expires but not "fresh", which was
+// updated later. It returns "old" as it was before it expired.
+func createExpiring(t *testing.T, s session.Service, clock *fakeClock, expireAfter time.Duration) session.Session {
+	t.Helper()
+	old := mustCreate(t, s, "app", "user", "old")
+	mustAppend(t, s, old, newEvent("e0", "user", clock.Now(), "hi"))
+	fresh := mustCreate(t, s, "app", "user", "fresh")
+	mustAppend(t, s, fresh, newEvent("e1", "user", clock.Now().Add(expireAfter), "hi"))
+	clock.Advance(expireAfter + expireAfter/2)
+	return old
+}
+
+func testExpiredInvisible(t *testing.T, factory func(t *testing.T, cfg session.ExpirationConfig) session.Service) {
+	clock := &fakeClock{now: time.Now()}
+	s := factory(t, session.ExpirationConfig{ExpireAfter: time.Hour, Now: clock.Now})
+	old := createExpiring(t, s, clock, time.Hour)
+
+	if _, err := s.Get(t.Context(), &session.GetRequest{AppName: "app", UserID: "user", SessionID: "old"}); err == nil {
+		t.Errorf("Get() of an expired session succeeded, want error")
+	}
+	checkSession(t, "Get(fresh)", mustGet(t, s, "app", "user", "fresh"), "app", "user", "fresh")
+
+	for _, userID := range []string{"", "user"} {
+		resp, err := s.List(t.Context(), &session.ListRequest{AppName: "app", UserID: userID})
+		if err != nil {
+			t.Fatalf("List(%q) error = %v", userID, err)
+		}
+		var got []string
+		for _, sess := range resp.Sessions {
+			got = append(got, sess.ID())
+		}
+		if diff := cmp.Diff([]string{"fresh"}, got); diff != "" {
+			t.Errorf("List(%q) sessions mismatch (-want +got):\n%s", userID, diff)
+		}
+	}
+
+	if err := s.AppendEvent(t.Context(), old, newEvent("e2", "user", clock.Now(), "hi")); err == nil {
+		t.Errorf("AppendEvent() to an expired session succeeded, want error")
+	}
+	if _, err := s.Create(t.Context(), &session.CreateRequest{AppName: "app", UserID: "user", SessionID: "old"}); err == nil {
+		t.Errorf("Create() of an expired session which wasn't swept succeeded, want error")
+	}
+}
+
+func testSweep(t *testing.T, factory func(t *testing.T, cfg session.ExpirationConfig) session.Service) {
+	clock := &fakeClock{now: time.Now()}
+	var expired []session.ExpiredSession
+	s := factory(t, session.ExpirationConfig{
+		ExpireAfter: time.Hour,
+		Now:         clock.Now,
+		OnSessionExpired: func(ctx context.Context, e session.ExpiredSession) {
+			expired = append(expired, e)
+		},
+	})
+	createExpiring(t, s, clock, time.Hour)
+
+	sweeper := s.(session.Sweeper)
+	if err := sweeper.Sweep(t.Context()); err != nil {
+		t.Fatalf("Sweep() error = %v", err)
+	}
+	want := []session.ExpiredSession{{AppName: "app", UserID: "user", SessionID: "old"}}
+	if diff := cmp.Diff(want, expired, cmpopts.IgnoreFields(session.ExpiredSession{}, "LastUpdateTime")); diff != "" {
+		t.Errorf("OnSessionExpired() sessions mismatch (-want +got):\n%s", diff)
+	}
+	if got := expired[0].LastUpdateTime; !got.Before(clock.Now().Add(-time.Hour)) {
+		t.Errorf("OnSessionExpired() last update time = %v, want before %v", got, clock.Now().Add(-time.Hour))
+	}
+
+	// The events of the swept session are gone with it.
+	recreated := mustCreate(t, s, "app", "user", "old")
+	if got := eventIDs(recreated); len(got) != 0 {
+		t.Errorf("Create() of a swept session returned events %v, want none", got)
+	}
+	if got := eventIDs(mustGet(t, s, "app", "user", "old")); len(got) != 0 {
+		t.Errorf("Get() of a recreated session returned events %v, want none", got)
+	}
+	if diff := cmp.Diff([]string{"e1"}, eventIDs(mustGet(t, s, "app", "user", "fresh"))); diff != "" {
+		t.Errorf("Get(fresh) events mismatch (-want +got):\n%s", diff)
+	}
+
+	expired = nil
+	if err := sweeper.Sweep(t.Context()); err != nil {
+		t.Fatalf("Sweep() error = %v", err)
+	}
+	if len(expired) != 0 {
+		t.Errorf("Sweep() without expired sessions called OnSessionExpired() for %v", expired)
+	}
+}
+
+func testStartSweeper(t *testing.T, factory func(t *testing.T, cfg session.ExpirationConfig) session.Service) {
+	clock := &fakeClock{now: time.Now()}
+	expired := make(chan session.ExpiredSession, 1)
+	s := factory(t, session.ExpirationConfig{
+		ExpireAfter:   time.Hour,
+		SweepInterval: time.Millisecond,
+		Now:           clock.Now,
+		OnSessionExpired: func(ctx context.Context, e session.ExpiredSession) {
+			expired <- e
+		},
+	})
+	createExpiring(t, s, clock, time.Hour)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	s.(session.Sweeper).Start(ctx)
+	select {
+	case e := <-expired:
+		if e.SessionID != "old" {
+			t.Errorf("OnSessionExpired() session = %q, want %q", e.SessionID, "old")
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("the sweeper didn't remove the expired session")
+	}
+}
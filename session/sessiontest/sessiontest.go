@@ -0,0 +1,861 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sessiontest provides a conformance suite for the implementations
+// of [session.Service], which is the reference of their behavior:
+//
+//   - Create fails if the session already exists, and generates an ID if the
+//     request has none. Session IDs are scoped by app and user.
+//   - The keys of the states prefixed with [session.KeyPrefixApp] are shared
+//     by the sessions of the app, those prefixed with [session.KeyPrefixUser]
+//     by the sessions of the user. The keys prefixed with
+//     [session.KeyPrefixTemp] are visible in the session passed to
+//     AppendEvent, but they are neither persisted in the state nor in the
+//     state delta of the stored event. The temporary keys of
+//     [session.CreateRequest.State] are dropped.
+//   - The services which implement [session.StateService] read and update
+//     the same app and user states as the state deltas.
+//   - The state deltas are merged in the order of the events, later values
+//     overwriting earlier ones.
+//   - Partial events aren't persisted, unless the context of their append
+//     opts in with [session.WithPartialEvents]. The other events are
+//     returned in the order they were appended, and the last update time of
+//     the session is the timestamp of its last event.
+//   - [session.GetRequest.NumRecentEvents] keeps the most recent events,
+//     [session.GetRequest.After] keeps the events at or after the time.
+//   - Paginated lists return the most recently updated sessions first.
+//   - Delete removes the session and its events, so a session created again
+//     with the same ID has none, and appending to the deleted session fails.
+//   - Concurrent appends to different sessions don't lose any state delta.
+//     Concurrent appends to the same session may be rejected, e.g. as stale,
+//     but the accepted ones are persisted exactly once.
+//   - The services which implement [session.UserDataDeleter] delete all the
+//     sessions and the user-scoped state of the user, however many sessions
+//     there are, and keep those of the other users and apps.
+//   - The services which implement [session.Searcher] match the text of the
+//     events and the string values of the session states of the user, with
+//     all the keywords of the query in any case, most recent matches first.
+//
+// The suite doesn't pin the behavior of duplicate event IDs, which some
+// services reject.
+package sessiontest
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+)
+
+// RunConformance runs the conformance suite against the service. Every
+// subtest calls factory with its own [testing.T] to get its own empty
+// service, so that the factory can fail the subtest and register the
+// cleanups of the service on it.
+//
+// The states of the suite only hold strings and float64 numbers, so that
+// the services which serialize the states to JSON pass too.
+func RunConformance(t *testing.T, factory func(t *testing.T) session.Service) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, s session.Service)
+	}{
+		{"Create", testCreate},
+		{"CreateExisting", testCreateExisting},
+		{"GetMissing", testGetMissing},
+		{"EventOrdering", testEventOrdering},
+		{"SavedPartialEvents", testSavedPartialEvents},
+		{"GetFilters", testGetFilters},
+		{"StateDelta", testStateDelta},
+		{"List", testList},
+		{"ListPagination", testListPagination},
+		{"Delete", testDelete},
+		{"StateService", testStateService},
+		{"ConcurrentAppends", testConcurrentAppends},
+		{"ConcurrentAppendsToOneSession", testConcurrentAppendsToOneSession},
+		{"ConcurrentModification", testConcurrentModification},
+		{"DeleteUserData", testDeleteUserData},
+		{"Search", testSearch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, factory(t))
+		})
+	}
+}
+
+// base is the timestamp of the events of the suite. It is in the future, so
+// that the events are more recent than the creation of the sessions.
+var base = time.Now().Add(time.Hour).Truncate(time.Second)
+
+func testCreate(t *testing.T, s session.Service) {
+	ctx := t.Context()
+	resp, err := s.Create(ctx, &session.CreateRequest{
+		AppName:   "app",
+		UserID:    "user",
+		SessionID: "session",
+		State:     map[string]any{"k": "v", "app:a": "app", "user:u": "user", "temp:t": "temp"},
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	want := map[string]any{"k": "v", "app:a": "app", "user:u": "user"}
+	checkSession(t, "Create()", resp.Session, "app", "user", "session")
+	if diff := cmp.Diff(want, stateOf(resp.Session)); diff != "" {
+		t.Errorf("Create() state mismatch (-want +got):\n%s", diff)
+	}
+	if resp.Session.LastUpdateTime().IsZero() {
+		t.Errorf("Create() LastUpdateTime is zero")
+	}
+
+	got := mustGet(t, s, "app", "user", "session")
+	checkSession(t, "Get()", got, "app", "user", "session")
+	if diff := cmp.Diff(want, stateOf(got)); diff != "" {
+		t.Errorf("Get() state mismatch (-want +got):\n%s", diff)
+	}
+	if got.Events().Len() != 0 {
+		t.Errorf("Get() returned %d events, want 0", got.Events().Len())
+	}
+
+	generated, err := s.Create(ctx, &session.CreateRequest{AppName: "app", UserID: "user"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if generated.Session.ID() == "" || generated.Session.ID() == "session" {
+		t.Errorf("Create() generated session ID %q, want a new ID", generated.Session.ID())
+	}
+
+	for _, req := range []*session.CreateRequest{
+		{UserID: "user"},
+		{AppName: "app"},
+	} {
+		if _, err := s.Create(ctx, req); err == nil {
+			t.Errorf("Create(%+v) succeeded, want error", req)
+		}
+	}
+}
+
+func testCreateExisting(t *testing.T, s session.Service) {
+	mustCreate(t, s, "app", "user", "session")
+	if _, err := s.Create(t.Context(), &session.CreateRequest{AppName: "app", UserID: "user", SessionID: "session"}); err == nil {
+		t.Errorf("Create() of an existing session succeeded, want error")
+	}
+	// The same ID can be used by other users.
+	mustCreate(t, s, "app", "other", "session")
+}
+
+func testGetMissing(t *testing.T, s session.Service) {
+	mustCreate(t, s, "app", "user", "session")
+	for _, req := range []*session.GetRequest{
+		{AppName: "app", UserID: "user", SessionID: "missing"},
+		{AppName: "app", UserID: "other", SessionID: "session"},
+		{AppName: "other", UserID: "user", SessionID: "session"},
+		{AppName: "app", UserID: "user"},
+	} {
+		if _, err := s.Get(t.Context(), req); err == nil {
+			t.Errorf("Get(%+v) succeeded, want error", req)
+		}
+	}
+}
+
+func testEventOrdering(t *testing.T, s session.Service) {
+	sess := mustCreate(t, s, "app", "user", "session")
+	events := []*session.Event{
+		newEvent("e1", "user", base, "hi"),
+		newEvent("e2", "agent", base.Add(time.Second), "hello"),
+		newEvent("e3", "agent", base.Add(2*time.Second), "bye"),
+	}
+	partial := newEvent("partial", "agent", base.Add(3*time.Second), "by")
+	partial.Partial = true
+	mustAppend(t, s, sess, events[0], events[1], partial, events[2])
+
+	if diff := cmp.Diff([]string{"e1", "e2", "e3"}, eventIDs(sess)); diff != "" {
+		t.Errorf("local session events mismatch (-want +got):\n%s", diff)
+	}
+	got := mustGet(t, s, "app", "user", "session")
+	if diff := cmp.Diff([]string{"e1", "e2", "e3"}, eventIDs(got)); diff != "" {
+		t.Errorf("Get() events mismatch (-want +got):\n%s", diff)
+	}
+	for i, want := range events {
+		event := got.Events().At(i)
+		if event == nil {
+			break
+		}
+		if event.Author != want.Author || event.InvocationID != want.InvocationID || !event.Timestamp.Equal(want.Timestamp) {
+			t.Errorf("Get() event %d = {Author: %q, InvocationID: %q, Timestamp: %v}, want {Author: %q, InvocationID: %q, Timestamp: %v}",
+				i, event.Author, event.InvocationID, event.Timestamp, want.Author, want.InvocationID, want.Timestamp)
+		}
+		if diff := cmp.Diff(want.Content, event.Content); diff != "" {
+			t.Errorf("Get() event %d content mismatch (-want +got):\n%s", i, diff)
+		}
+	}
+	if !got.LastUpdateTime().Equal(events[2].Timestamp) {
+		t.Errorf("Get() LastUpdateTime = %v, want the timestamp of the last event %v", got.LastUpdateTime(), events[2].Timestamp)
+	}
+}
+
+func testSavedPartialEvents(t *testing.T, s session.Service) {
+	sess := mustCreate(t, s, "app", "user", "session")
+	partial := newEvent("partial", "agent", base, "hel")
+	partial.Partial = true
+	final := newEvent("final", "agent", base.Add(time.Second), "hello")
+	for _, event := range []*session.Event{partial, final} {
+		if err := s.AppendEvent(session.WithPartialEvents(t.Context()), sess, event); err != nil {
+			t.Fatalf("AppendEvent(%s) error = %v", event.ID, err)
+		}
+	}
+
+	got := mustGet(t, s, "app", "user", "session")
+	if diff := cmp.Diff([]string{"partial", "final"}, eventIDs(got)); diff != "" {
+		t.Fatalf("Get() events mismatch (-want +got):\n%s", diff)
+	}
+	if !got.Events().At(0).Partial {
+		t.Errorf("Get() event 0 isn't partial")
+	}
+}
+
+func testGetFilters(t *testing.T, s session.Service) {
+	sess := mustCreate(t, s, "app", "user", "session")
+	for i := range 4 {
+		mustAppend(t, s, sess, newEvent(fmt.Sprint("e", i), "user", base.Add(time.Duration(i)*time.Second), "hi"))
+	}
+
+	tests := []struct {
+		name string
+		req  session.GetRequest
+		want []string
+	}{
+		{name: "all", want: []string{"e0", "e1", "e2", "e3"}},
+		{name: "recent", req: session.GetRequest{NumRecentEvents: 2}, want: []string{"e2", "e3"}},
+		{name: "more recent than events", req: session.GetRequest{NumRecentEvents: 10}, want: []string{"e0", "e1", "e2", "e3"}},
+		{name: "after", req: session.GetRequest{After: base.Add(2 * time.Second)}, want: []string{"e2", "e3"}},
+		{name: "after all", req: session.GetRequest{After: base.Add(time.Hour)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.req
+			req.AppName, req.UserID, req.SessionID = "app", "user", "session"
+			resp, err := s.Get(t.Context(), &req)
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, eventIDs(resp.Session)); diff != "" {
+				t.Errorf("Get() events mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func testStateDelta(t *testing.T, s session.Service) {
+	sess := mustCreate(t, s, "app", "user", "session")
+	event := newEvent("e1", "agent", base, "hi")
+	event.Actions.StateDelta = map[string]any{
+		"k":      "v",
+		"app:a":  "app",
+		"user:u": "user",
+		"temp:t": "temp",
+	}
+	mustAppend(t, s, sess, event)
+	if v, err := sess.State().Get("temp:t"); err != nil || v != "temp" {
+		t.Errorf("State().Get(temp:t) of the appended session = %v, %v, want the temporary value", v, err)
+	}
+	event = newEvent("e2", "agent", base.Add(time.Second), "hi")
+	event.Actions.StateDelta = map[string]any{"k": "v2", "n": float64(2)}
+	mustAppend(t, s, sess, event)
+
+	got := mustGet(t, s, "app", "user", "session")
+	if diff := cmp.Diff(map[string]any{"k": "v2", "n": float64(2), "app:a": "app", "user:u": "user"}, stateOf(got)); diff != "" {
+		t.Errorf("Get() state mismatch (-want +got):\n%s", diff)
+	}
+	if first := got.Events().At(0); first != nil {
+		if _, ok := first.Actions.StateDelta["temp:t"]; ok {
+			t.Errorf("Get() event state delta has the temporary key")
+		}
+		if first.Actions.StateDelta["k"] != "v" {
+			t.Errorf("Get() event state delta = %v, want the delta of the event", first.Actions.StateDelta)
+		}
+	}
+
+	// The app state is shared by the sessions of the app, the user state by
+	// the sessions of the user.
+	tests := []struct {
+		appName, userID string
+		want            map[string]any
+	}{
+		{"app", "user", map[string]any{"app:a": "app", "user:u": "user"}},
+		{"app", "other", map[string]any{"app:a": "app"}},
+		{"other", "user", map[string]any{}},
+	}
+	for _, tt := range tests {
+		resp, err := s.Create(t.Context(), &session.CreateRequest{AppName: tt.appName, UserID: tt.userID})
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		got := mustGet(t, s, tt.appName, tt.userID, resp.Session.ID())
+		if diff := cmp.Diff(tt.want, stateOf(got)); diff != "" {
+			t.Errorf("Get() state of a new session of %s/%s mismatch (-want +got):\n%s", tt.appName, tt.userID, diff)
+		}
+	}
+}
+
+func testStateService(t *testing.T, s session.Service) {
+	ss, ok := s.(session.StateService)
+	if !ok {
+		t.Skipf("%T doesn't implement session.StateService", s)
+	}
+	ctx := t.Context()
+	sess := mustCreate(t, s, "app", "user", "session")
+	event := newEvent("e1", "agent", base, "hi")
+	event.Actions.StateDelta = map[string]any{"app:a": "app", "user:u": "user", "temp:t": "temp"}
+	mustAppend(t, s, sess, event)
+
+	if err := ss.UpdateAppState(ctx, &session.UpdateAppStateRequest{AppName: "app", Delta: map[string]any{"b": float64(1)}}); err != nil {
+		t.Fatalf("UpdateAppState() error = %v", err)
+	}
+	if err := ss.UpdateUserState(ctx, &session.UpdateUserStateRequest{AppName: "app", UserID: "user", Delta: map[string]any{"u": "updated"}}); err != nil {
+		t.Fatalf("UpdateUserState() error = %v", err)
+	}
+	if err := ss.UpdateUserState(ctx, &session.UpdateUserStateRequest{AppName: "app", UserID: "other", Delta: map[string]any{"o": "other"}}); err != nil {
+		t.Fatalf("UpdateUserState() error = %v", err)
+	}
+
+	appTests := []struct {
+		appName string
+		want    map[string]any
+	}{
+		{"app", map[string]any{"a": "app", "b": float64(1)}},
+		{"missing", map[string]any{}},
+	}
+	for _, tt := range appTests {
+		resp, err := ss.GetAppState(ctx, &session.GetAppStateRequest{AppName: tt.appName})
+		if err != nil {
+			t.Fatalf("GetAppState(%s) error = %v", tt.appName, err)
+		}
+		if diff := cmp.Diff(tt.want, resp.State); diff != "" {
+			t.Errorf("GetAppState(%s) mismatch (-want +got):\n%s", tt.appName, diff)
+		}
+	}
+	userTests := []struct {
+		userID string
+		want   map[string]any
+	}{
+		{"user", map[string]any{"u": "updated"}},
+		{"other", map[string]any{"o": "other"}},
+		{"missing", map[string]any{}},
+	}
+	for _, tt := range userTests {
+		resp, err := ss.GetUserState(ctx, &session.GetUserStateRequest{AppName: "app", UserID: tt.userID})
+		if err != nil {
+			t.Fatalf("GetUserState(%s) error = %v", tt.userID, err)
+		}
+		if diff := cmp.Diff(tt.want, resp.State); diff != "" {
+			t.Errorf("GetUserState(%s) mismatch (-want +got):\n%s", tt.userID, diff)
+		}
+	}
+
+	// The sessions see the updates outside of the events.
+	got := mustGet(t, s, "app", "user", "session")
+	want := map[string]any{"app:a": "app", "app:b": float64(1), "user:u": "updated"}
+	if diff := cmp.Diff(want, stateOf(got)); diff != "" {
+		t.Errorf("Get() state mismatch (-want +got):\n%s", diff)
+	}
+
+	for _, err := range []error{
+		ss.UpdateAppState(ctx, &session.UpdateAppStateRequest{Delta: map[string]any{"k": "v"}}),
+		ss.UpdateUserState(ctx, &session.UpdateUserStateRequest{AppName: "app", Delta: map[string]any{"k": "v"}}),
+	} {
+		if err == nil {
+			t.Errorf("Update without the required names succeeded, want error")
+		}
+	}
+	if _, err := ss.GetAppState(ctx, &session.GetAppStateRequest{}); err == nil {
+		t.Errorf("GetAppState() without app name succeeded, want error")
+	}
+	if _, err := ss.GetUserState(ctx, &session.GetUserStateRequest{AppName: "app"}); err == nil {
+		t.Errorf("GetUserState() without user ID succeeded, want error")
+	}
+}
+
+func testList(t *testing.T, s session.Service) {
+	for _, key := range [][2]string{{"user1", "s1"}, {"user1", "s2"}, {"user2", "s1"}} {
+		mustCreate(t, s, "app", key[0], key[1])
+	}
+	mustCreate(t, s, "other", "user1", "s3")
+
+	tests := []struct {
+		userID string
+		want   []string
+	}{
+		{userID: "user1", want: []string{"user1/s1", "user1/s2"}},
+		{userID: "user2", want: []string{"user2/s1"}},
+		{userID: "", want: []string{"user1/s1", "user1/s2", "user2/s1"}},
+		{userID: "missing"},
+	}
+	for _, tt := range tests {
+		resp, err := s.List(t.Context(), &session.ListRequest{AppName: "app", UserID: tt.userID})
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		var got []string
+		for _, sess := range resp.Sessions {
+			got = append(got, sess.UserID()+"/"+sess.ID())
+			if sess.AppName() != "app" {
+				t.Errorf("List() returned session of app %q", sess.AppName())
+			}
+		}
+		slices.Sort(got)
+		if diff := cmp.Diff(tt.want, got); diff != "" {
+			t.Errorf("List(user %q) mismatch (-want +got):\n%s", tt.userID, diff)
+		}
+		if resp.NextPageToken != "" {
+			t.Errorf("List() without page size returned NextPageToken %q", resp.NextPageToken)
+		}
+	}
+
+	if _, err := s.List(t.Context(), &session.ListRequest{}); err == nil {
+		t.Errorf("List() without app name succeeded, want error")
+	}
+}
+
+func testListPagination(t *testing.T, s session.Service) {
+	// The sessions are updated in order, so they are listed in the reverse
+	// order.
+	var want []string
+	for i := range 5 {
+		id := fmt.Sprint("s", i)
+		sess := mustCreate(t, s, "app", "user", id)
+		mustAppend(t, s, sess, newEvent(id+"_e", "user", base.Add(time.Duration(i)*time.Second), "hi"))
+		want = append([]string{id}, want...)
+	}
+
+	var got []string
+	req := &session.ListRequest{AppName: "app", UserID: "user", ListOptions: session.ListOptions{PageSize: 2}}
+	for pages := 1; ; pages++ {
+		if pages > len(want) {
+			t.Fatalf("List() didn't stop paginating")
+		}
+		resp, err := s.List(t.Context(), req)
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		if len(resp.Sessions) > 2 {
+			t.Errorf("List() returned %d sessions, want at most 2", len(resp.Sessions))
+		}
+		for _, sess := range resp.Sessions {
+			got = append(got, sess.ID())
+		}
+		if resp.NextPageToken == "" {
+			break
+		}
+		req.PageToken = resp.NextPageToken
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("List() pages mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := s.List(t.Context(), &session.ListRequest{AppName: "app", ListOptions: session.ListOptions{PageToken: "invalid"}}); err == nil {
+		t.Errorf("List() with an invalid page token succeeded, want error")
+	}
+}
+
+func testDelete(t *testing.T, s session.Service) {
+	sess := mustCreate(t, s, "app", "user", "session")
+	mustAppend(t, s, sess, newEvent("e1", "user", base, "hi"))
+	mustCreate(t, s, "app", "user", "kept")
+
+	if err := s.Delete(t.Context(), &session.DeleteRequest{AppName: "app", UserID: "user", SessionID: "session"}); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := s.Get(t.Context(), &session.GetRequest{AppName: "app", UserID: "user", SessionID: "session"}); err == nil {
+		t.Errorf("Get() of a deleted session succeeded, want error")
+	}
+	if err := s.AppendEvent(t.Context(), sess, newEvent("e2", "user", base.Add(time.Second), "hi")); err == nil {
+		t.Errorf("AppendEvent() to a deleted session succeeded, want error")
+	}
+	resp, err := s.List(t.Context(), &session.ListRequest{AppName: "app", UserID: "user"})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	var ids []string
+	for _, sess := range resp.Sessions {
+		ids = append(ids, sess.ID())
+	}
+	if diff := cmp.Diff([]string{"kept"}, ids); diff != "" {
+		t.Errorf("List() after Delete() mismatch (-want +got):\n%s", diff)
+	}
+
+	// The events are deleted with the session.
+	mustCreate(t, s, "app", "user", "session")
+	if got := mustGet(t, s, "app", "user", "session"); got.Events().Len() != 0 {
+		t.Errorf("Get() of a recreated session returned %d events, want 0", got.Events().Len())
+	}
+
+	if err := s.Delete(t.Context(), &session.DeleteRequest{AppName: "app", UserID: "user"}); err == nil {
+		t.Errorf("Delete() without session ID succeeded, want error")
+	}
+}
+
+func testConcurrentAppends(t *testing.T, s session.Service) {
+	const n = 10
+	var sessions []session.Session
+	for i := range n {
+		sessions = append(sessions, mustCreate(t, s, "app", "user", fmt.Sprint("s", i)))
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, n)
+	for i, sess := range sessions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			event := newEvent(fmt.Sprint("e", i), "user", base.Add(time.Duration(i)*time.Millisecond), "hi")
+			event.Actions.StateDelta = map[string]any{
+				fmt.Sprint("app:k", i):  "app",
+				fmt.Sprint("user:k", i): "user",
+				"k":                     fmt.Sprint(i),
+			}
+			errs[i] = s.AppendEvent(t.Context(), sess, event)
+		}()
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("AppendEvent(s%d) error = %v", i, err)
+		}
+	}
+	for i := range n {
+		got := mustGet(t, s, "app", "user", fmt.Sprint("s", i))
+		if diff := cmp.Diff([]string{fmt.Sprint("e", i)}, eventIDs(got)); diff != "" {
+			t.Errorf("Get(s%d) events mismatch (-want +got):\n%s", i, diff)
+		}
+		state := stateOf(got)
+		if state["k"] != fmt.Sprint(i) {
+			t.Errorf("Get(s%d) state k = %v, want the delta of its own event %q", i, state["k"], fmt.Sprint(i))
+		}
+		for j := range n {
+			for _, key := range []string{fmt.Sprint("app:k", j), fmt.Sprint("user:k", j)} {
+				if _, ok := state[key]; !ok {
+					t.Errorf("Get(s%d) state is missing %q", i, key)
+				}
+			}
+		}
+	}
+}
+
+func testConcurrentAppendsToOneSession(t *testing.T, s session.Service) {
+	sess := mustCreate(t, s, "app", "user", "session")
+
+	const n = 10
+	var wg sync.WaitGroup
+	succeeded := make([]bool, n)
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			event := newEvent(fmt.Sprint("e", i), "user", base.Add(time.Duration(i)*time.Millisecond), "hi")
+			event.Actions.StateDelta = map[string]any{fmt.Sprint("k", i): float64(i)}
+			succeeded[i] = s.AppendEvent(t.Context(), sess, event) == nil
+		}()
+	}
+	wg.Wait()
+
+	got := mustGet(t, s, "app", "user", "session")
+	wantIDs := map[string]bool{}
+	wantState := map[string]any{}
+	for i, ok := range succeeded {
+		if ok {
+			wantIDs[fmt.Sprint("e", i)] = true
+			wantState[fmt.Sprint("k", i)] = float64(i)
+		}
+	}
+	if len(wantIDs) == 0 {
+		t.Fatalf("AppendEvent() failed for all the events")
+	}
+	gotIDs := map[string]bool{}
+	for _, id := range eventIDs(got) {
+		if gotIDs[id] {
+			t.Errorf("Get() returned event %q twice", id)
+		}
+		gotIDs[id] = true
+	}
+	if diff := cmp.Diff(wantIDs, gotIDs); diff != "" {
+		t.Errorf("Get() events mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(wantState, stateOf(got)); diff != "" {
+		t.Errorf("Get() state mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func testConcurrentModification(t *testing.T, s session.Service) {
+	if vc, ok := s.(session.VersionChecker); !ok || !vc.VersionChecking() {
+		t.Skip("the service doesn't check the versions of the sessions")
+	}
+	mustCreate(t, s, "app", "user", "session")
+	first := mustGet(t, s, "app", "user", "session")
+	second := mustGet(t, s, "app", "user", "session")
+
+	mustAppend(t, s, first, newEvent("e1", "user", base, "hi"))
+	event := newEvent("e2", "user", base.Add(time.Second), "hi")
+	event.Actions.StateDelta = map[string]any{"k": "v"}
+	if err := s.AppendEvent(t.Context(), second, event); !errors.Is(err, session.ErrConcurrentModification) {
+		t.Fatalf("AppendEvent() to an outdated session error = %v, want %v", err, session.ErrConcurrentModification)
+	}
+	got := mustGet(t, s, "app", "user", "session")
+	if diff := cmp.Diff([]string{"e1"}, eventIDs(got)); diff != "" {
+		t.Errorf("Get() events after the rejected append mismatch (-want +got):\n%s", diff)
+	}
+	if _, ok := stateOf(got)["k"]; ok {
+		t.Errorf("Get() state has the delta of the rejected event")
+	}
+
+	// The session got again is up to date.
+	mustAppend(t, s, got, event)
+	got = mustGet(t, s, "app", "user", "session")
+	if diff := cmp.Diff([]string{"e1", "e2"}, eventIDs(got)); diff != "" {
+		t.Errorf("Get() events after the retry mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string]any{"k": "v"}, stateOf(got)); diff != "" {
+		t.Errorf("Get() state after the retry mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func testDeleteUserData(t *testing.T, s session.Service) {
+	deleter, ok := s.(session.UserDataDeleter)
+	if !ok {
+		t.Skip("the service doesn't delete the data of users")
+	}
+	ctx := t.Context()
+	// More sessions than the database services delete per transaction.
+	numSessions := session.DeleteUserDataBatchSize + 1
+	for i := range numSessions {
+		sess := mustCreate(t, s, "app", "user", fmt.Sprint("session", i))
+		if i < 2 {
+			event := newEvent(fmt.Sprint("e", i), "user", base.Add(time.Duration(i)*time.Second), "hi")
+			event.Actions.StateDelta = map[string]any{"user:u": "user", "app:a": "app"}
+			mustAppend(t, s, sess, event)
+		}
+	}
+	for _, other := range []struct{ appName, userID string }{{"app", "other"}, {"other", "user"}} {
+		sess := mustCreate(t, s, other.appName, other.userID, "session0")
+		event := newEvent("e0", "user", base, "hi")
+		event.Actions.StateDelta = map[string]any{"user:u": "user"}
+		mustAppend(t, s, sess, event)
+	}
+
+	resp, err := deleter.DeleteUserData(ctx, &session.DeleteUserDataRequest{AppName: "app", UserID: "user"})
+	if err != nil {
+		t.Fatalf("DeleteUserData() error = %v", err)
+	}
+	want := &session.DeleteUserDataResponse{DeletedSessions: numSessions, DeletedEvents: 2, DeletedUserState: true}
+	if diff := cmp.Diff(want, resp); diff != "" {
+		t.Errorf("DeleteUserData() mismatch (-want +got):\n%s", diff)
+	}
+	list, err := s.List(ctx, &session.ListRequest{AppName: "app", UserID: "user"})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(list.Sessions) != 0 {
+		t.Errorf("List() after DeleteUserData() returned %d sessions, want none", len(list.Sessions))
+	}
+
+	// Deleting again finds nothing.
+	resp, err = deleter.DeleteUserData(ctx, &session.DeleteUserDataRequest{AppName: "app", UserID: "user"})
+	if err != nil {
+		t.Fatalf("DeleteUserData() again error = %v", err)
+	}
+	if diff := cmp.Diff(&session.DeleteUserDataResponse{}, resp); diff != "" {
+		t.Errorf("DeleteUserData() again mismatch (-want +got):\n%s", diff)
+	}
+
+	// The user starts over, with the app state only.
+	recreated := mustCreate(t, s, "app", "user", "session0")
+	if diff := cmp.Diff(map[string]any{"app:a": "app"}, stateOf(recreated)); diff != "" {
+		t.Errorf("Create() state after DeleteUserData() mismatch (-want +got):\n%s", diff)
+	}
+	if got := mustGet(t, s, "app", "user", "session0"); got.Events().Len() != 0 {
+		t.Errorf("Get() of the recreated session returned %d events, want none", got.Events().Len())
+	}
+	for _, other := range []struct{ appName, userID string }{{"app", "other"}, {"other", "user"}} {
+		got := mustGet(t, s, other.appName, other.userID, "session0")
+		if diff := cmp.Diff([]string{"e0"}, eventIDs(got)); diff != "" {
+			t.Errorf("Get(%s/%s) events mismatch (-want +got):\n%s", other.appName, other.userID, diff)
+		}
+		if got := stateOf(got)["user:u"]; got != "user" {
+			t.Errorf("Get(%s/%s) user state = %v, want %q", other.appName, other.userID, got, "user")
+		}
+	}
+}
+
+func testSearch(t *testing.T, s session.Service) {
+	searcher, ok := s.(session.Searcher)
+	if !ok {
+		t.Skip("the service doesn't search sessions")
+	}
+	ctx := t.Context()
+	long := strings.Repeat("blah ", 30) + "Paris " + strings.Repeat("blah ", 30)
+	s1 := mustCreate(t, s, "app", "user", "s1")
+	mustAppend(t, s, s1,
+		newEvent("e1", "user", base, "Book a flight to Paris"),
+		newEvent("e2", "agent", base.Add(time.Second), "Which dates?"),
+		newEvent("e3", "agent", base.Add(2*time.Second), long))
+	s2, err := s.Create(ctx, &session.CreateRequest{
+		AppName:   "app",
+		UserID:    "user",
+		SessionID: "s2",
+		State:     map[string]any{"destination": "paris, France", "nights": 3.0, "user:home": "Paris"},
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	mustAppend(t, s, s2.Session, newEvent("e4", "user", base.Add(3*time.Second), "Hotels in PARIS near the flight terminal"))
+	// The sessions of the other users and apps aren't searched.
+	mustAppend(t, s, mustCreate(t, s, "app", "other", "s1"), newEvent("e1", "user", base, "Paris"))
+	mustAppend(t, s, mustCreate(t, s, "other", "user", "s1"), newEvent("e1", "user", base, "Paris"))
+
+	type result struct {
+		SessionID, EventID, StateKey, Snippet string
+	}
+	search := func(t *testing.T, query string, opts session.ListOptions) ([]result, string) {
+		t.Helper()
+		resp, err := searcher.Search(ctx, &session.SearchRequest{AppName: "app", UserID: "user", Query: query, ListOptions: opts})
+		if err != nil {
+			t.Fatalf("Search(%q) error = %v", query, err)
+		}
+		var got []result
+		for _, r := range resp.Results {
+			if r.Timestamp.IsZero() {
+				t.Errorf("Search(%q) result %+v has no timestamp", query, r)
+			}
+			got = append(got, result{r.SessionID, r.EventID, r.StateKey, r.Snippet})
+		}
+		return got, resp.NextPageToken
+	}
+
+	longSnippet := "…" + strings.Repeat("blah ", 8) + "Paris" + strings.Repeat(" blah", 15) + "…"
+	all := []result{
+		// The state matches at the last update time of the session, the
+		// timestamp of its last event, and sorts before it.
+		{"s2", "", "destination", "paris, France"},
+		{"s2", "e4", "", "Hotels in PARIS near the flight terminal"},
+		{"s1", "e3", "", longSnippet},
+		{"s1", "e1", "", "Book a flight to Paris"},
+	}
+	got, next := search(t, "paris", session.ListOptions{})
+	if diff := cmp.Diff(all, got); diff != "" {
+		t.Errorf("Search() mismatch (-want +got):\n%s", diff)
+	}
+	if next != "" {
+		t.Errorf("Search() NextPageToken = %q, want none", next)
+	}
+
+	got, _ = search(t, "FLIGHT  paris", session.ListOptions{})
+	if diff := cmp.Diff([]result{all[1], all[3]}, got); diff != "" {
+		t.Errorf("Search() with keywords mismatch (-want +got):\n%s", diff)
+	}
+	got, _ = search(t, "tokyo", session.ListOptions{})
+	if len(got) != 0 {
+		t.Errorf("Search() without matches returned %v, want none", got)
+	}
+
+	var paged []result
+	opts := session.ListOptions{PageSize: 3}
+	for range len(all) {
+		got, next := search(t, "paris", opts)
+		paged = append(paged, got...)
+		if next == "" {
+			break
+		}
+		opts.PageToken = next
+	}
+	if diff := cmp.Diff(all, paged); diff != "" {
+		t.Errorf("Search() pages mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := searcher.Search(ctx, &session.SearchRequest{AppName: "app", UserID: "user", Query: "  "}); err == nil {
+		t.Errorf("Search() with an empty query succeeded, want error")
+	}
+}
+
+func newEvent(id, author string, timestamp time.Time, text string) *session.Event {
+	var role genai.Role = genai.RoleModel
+	if author == "user" {
+		role = genai.RoleUser
+	}
+	return &session.Event{
+		ID:           id,
+		InvocationID: "inv_" + id,
+		Author:       author,
+		Timestamp:    timestamp,
+		LLMResponse:  model.LLMResponse{Content: genai.NewContentFromText(text, role)},
+	}
+}
+
+func mustCreate(t *testing.T, s session.Service, appName, userID, sessionID string) session.Session {
+	t.Helper()
+	resp, err := s.Create(t.Context(), &session.CreateRequest{AppName: appName, UserID: userID, SessionID: sessionID})
+	if err != nil {
+		t.Fatalf("Create(%s/%s/%s) error = %v", appName, userID, sessionID, err)
+	}
+	return resp.Session
+}
+
+func mustGet(t *testing.T, s session.Service, appName, userID, sessionID string) session.Session {
+	t.Helper()
+	resp, err := s.Get(t.Context(), &session.GetRequest{AppName: appName, UserID: userID, SessionID: sessionID})
+	if err != nil {
+		t.Fatalf("Get(%s/%s/%s) error = %v", appName, userID, sessionID, err)
+	}
+	return resp.Session
+}
+
+func mustAppend(t *testing.T, s session.Service, sess session.Session, events ...*session.Event) {
+	t.Helper()
+	for _, event := range events {
+		if err := s.AppendEvent(t.Context(), sess, event); err != nil {
+			t.Fatalf("AppendEvent(%s) error = %v", event.ID, err)
+		}
+	}
+}
+
+func checkSession(t *testing.T, op string, sess session.Session, appName, userID, sessionID string) {
+	t.Helper()
+	if sess.AppName() != appName || sess.UserID() != userID || sess.ID() != sessionID {
+		t.Errorf("%s = session %s/%s/%s, want %s/%s/%s", op, sess.AppName(), sess.UserID(), sess.ID(), appName, userID, sessionID)
+	}
+}
+
+func stateOf(sess session.Session) map[string]any {
+	state := map[string]any{}
+	for k, v := range sess.State().All() {
+		state[k] = v
+	}
+	return state
+}
+
+func eventIDs(sess session.Session) []string {
+	var ids []string
+	for event := range sess.Events().All() {
+		ids = append(ids, event.ID)
+	}
+	return ids
+}
@@ -0,0 +1,117 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"google.golang.org/adk/internal/sessionsql"
+	"google.golang.org/adk/session"
+)
+
+// Compact replaces runs of old events with summaries, implements
+// session.Compactor. The summary of a run takes the place of its first
+// event in the order of the events. Archived events are moved to the
+// archived_events table.
+func (s *sqliteService) Compact(ctx context.Context, req *session.CompactRequest, cfg session.CompactionConfig) (*session.CompactResponse, error) {
+	getReq := &session.GetRequest{AppName: req.AppName, UserID: req.UserID, SessionID: req.SessionID}
+	resp, err := s.Get(ctx, getReq)
+	if err != nil {
+		return nil, err
+	}
+	events := make([]*session.Event, 0, resp.Session.Events().Len())
+	for event := range resp.Session.Events().All() {
+		events = append(events, event)
+	}
+
+	// The summaries are written outside of the transaction, since they may
+	// take long.
+	compactions, err := cfg.Compactions(ctx, events)
+	if err != nil {
+		return nil, err
+	}
+	if len(compactions) == 0 {
+		return &session.CompactResponse{Session: resp.Session}, nil
+	}
+
+	compacted := 0
+	err = s.inTx(ctx, func(tx *sql.Tx) error {
+		for _, c := range compactions {
+			if err := compactRun(ctx, tx, req, c, cfg.Archive); err != nil {
+				return err
+			}
+			compacted += len(c.Events)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if resp, err = s.Get(ctx, getReq); err != nil {
+		return nil, err
+	}
+	return &session.CompactResponse{Session: resp.Session, CompactedEvents: compacted}, nil
+}
+
+// compactRun replaces the events of the compaction with its summary, which
+// takes the sequence number of the first event.
+func compactRun(ctx context.Context, tx *sql.Tx, req *session.CompactRequest, c session.Compaction, archive bool) error {
+	const where = " WHERE app_name = $1 AND user_id = $2 AND session_id = $3 AND id = $4"
+	var seq int64
+	err := tx.QueryRowContext(ctx, "SELECT seq FROM events"+where,
+		req.AppName, req.UserID, req.SessionID, c.Events[0].ID).Scan(&seq)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: event %q was removed", session.ErrConcurrentModification, c.Events[0].ID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to compact events: %w", err)
+	}
+
+	const columns = "seq, app_name, user_id, session_id, " + eventColumns
+	for _, event := range c.Events {
+		if archive {
+			if _, err := tx.ExecContext(ctx,
+				"INSERT INTO archived_events ("+columns+") SELECT "+columns+" FROM events"+where,
+				req.AppName, req.UserID, req.SessionID, event.ID); err != nil {
+				return fmt.Errorf("failed to archive event %q: %w", event.ID, err)
+			}
+		}
+		res, err := tx.ExecContext(ctx, "DELETE FROM events"+where, req.AppName, req.UserID, req.SessionID, event.ID)
+		if err != nil {
+			return fmt.Errorf("failed to compact events: %w", err)
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return fmt.Errorf("failed to compact events: %w", err)
+		} else if n == 0 {
+			return fmt.Errorf("%w: event %q was removed", session.ErrConcurrentModification, event.ID)
+		}
+	}
+
+	row, err := sessionsql.NewEventRow(c.Summary)
+	if err != nil {
+		return fmt.Errorf("failed to map event to storage model: %w", err)
+	}
+	args := append([]any{seq, req.AppName, req.UserID, req.SessionID}, eventValues(row)...)
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO events ("+columns+") VALUES ("+sessionsql.Placeholders(len(args))+")",
+		args...); err != nil {
+		return fmt.Errorf("failed to save summary event: %w", err)
+	}
+	return nil
+}
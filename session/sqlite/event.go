@@ -0,0 +1,59 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"time"
+
+	"google.golang.org/adk/internal/sessionsql"
+)
+
+// eventColumns are the columns of the events table, in the order of
+// [eventValues] and [eventScanDest].
+const eventColumns = `id, invocation_id, author, branch, timestamp, content, actions,
+	long_running_tool_ids, grounding_metadata, citation_metadata, usage_metadata, custom_metadata,
+	partial, turn_complete, interrupted, error_code, error_message`
+
+// eventValues returns the values of the row, in the order of [eventColumns].
+func eventValues(r *sessionsql.EventRow) []any {
+	return []any{
+		r.ID, r.InvocationID, r.Author, r.Branch, r.Timestamp.UnixMicro(), jsonText(r.Content), jsonText(r.Actions),
+		jsonText(r.LongRunningToolIDs), jsonText(r.GroundingMetadata), jsonText(r.CitationMetadata), jsonText(r.UsageMetadata), jsonText(r.CustomMetadata),
+		r.Partial, r.TurnComplete, r.Interrupted, r.ErrorCode, r.ErrorMessage,
+	}
+}
+
+// eventScanDest returns the scan destinations of the row, in the order of
+// [eventColumns]. The timestamp is scanned into timestamp, see [timeFromMicros].
+func eventScanDest(r *sessionsql.EventRow, timestamp *int64) []any {
+	return []any{
+		&r.ID, &r.InvocationID, &r.Author, &r.Branch, timestamp, &r.Content, &r.Actions,
+		&r.LongRunningToolIDs, &r.GroundingMetadata, &r.CitationMetadata, &r.UsageMetadata, &r.CustomMetadata,
+		&r.Partial, &r.TurnComplete, &r.Interrupted, &r.ErrorCode, &r.ErrorMessage,
+	}
+}
+
+// jsonText stores JSON as TEXT, and empty JSON as NULL.
+func jsonText(data []byte) any {
+	if data == nil {
+		return nil
+	}
+	return string(data)
+}
+
+// timeFromMicros converts a timestamp column back to a time.
+func timeFromMicros(micros int64) time.Time {
+	return time.UnixMicro(micros)
+}
@@ -0,0 +1,57 @@
+-- The timestamps are microseconds since the Unix epoch, and the states and
+-- the event fields are JSON.
+
+CREATE TABLE sessions (
+	app_name    TEXT    NOT NULL,
+	user_id     TEXT    NOT NULL,
+	id          TEXT    NOT NULL,
+	state       TEXT    NOT NULL DEFAULT '{}',
+	create_time INTEGER NOT NULL,
+	update_time INTEGER NOT NULL,
+	PRIMARY KEY (app_name, user_id, id)
+);
+
+CREATE INDEX sessions_app_name_update_time_idx ON sessions (app_name, update_time DESC, id);
+
+CREATE TABLE events (
+	-- seq orders the events of a session as they were appended.
+	seq                   INTEGER PRIMARY KEY AUTOINCREMENT,
+	app_name              TEXT    NOT NULL,
+	user_id               TEXT    NOT NULL,
+	session_id            TEXT    NOT NULL,
+	id                    TEXT    NOT NULL,
+	invocation_id         TEXT    NOT NULL,
+	author                TEXT    NOT NULL,
+	branch                TEXT    NOT NULL DEFAULT '',
+	timestamp             INTEGER NOT NULL,
+	content               TEXT,
+	actions               TEXT    NOT NULL DEFAULT '{}',
+	long_running_tool_ids TEXT,
+	grounding_metadata    TEXT,
+	citation_metadata     TEXT,
+	usage_metadata        TEXT,
+	custom_metadata       TEXT,
+	partial               INTEGER NOT NULL DEFAULT 0,
+	turn_complete         INTEGER NOT NULL DEFAULT 0,
+	interrupted           INTEGER NOT NULL DEFAULT 0,
+	error_code            TEXT    NOT NULL DEFAULT '',
+	error_message         TEXT    NOT NULL DEFAULT '',
+	UNIQUE (app_name, user_id, session_id, id),
+	FOREIGN KEY (app_name, user_id, session_id) REFERENCES sessions (app_name, user_id, id) ON DELETE CASCADE
+);
+
+CREATE INDEX events_session_seq_idx ON events (app_name, user_id, session_id, seq);
+
+CREATE TABLE app_states (
+	app_name    TEXT    NOT NULL PRIMARY KEY,
+	state       TEXT    NOT NULL DEFAULT '{}',
+	update_time INTEGER NOT NULL
+);
+
+CREATE TABLE user_states (
+	app_name    TEXT    NOT NULL,
+	user_id     TEXT    NOT NULL,
+	state       TEXT    NOT NULL DEFAULT '{}',
+	update_time INTEGER NOT NULL,
+	PRIMARY KEY (app_name, user_id)
+);
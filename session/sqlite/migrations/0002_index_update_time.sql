@@ -0,0 +1,4 @@
+-- Sweeping the expired sessions selects them by their update time across
+-- all the apps.
+
+CREATE INDEX sessions_update_time_idx ON sessions (update_time);
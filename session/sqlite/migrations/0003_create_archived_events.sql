@@ -0,0 +1,29 @@
+-- archived_events keeps the events compacted with the archive option, with
+-- the columns of events.
+
+CREATE TABLE archived_events (
+	seq                   INTEGER NOT NULL PRIMARY KEY,
+	app_name              TEXT    NOT NULL,
+	user_id               TEXT    NOT NULL,
+	session_id            TEXT    NOT NULL,
+	id                    TEXT    NOT NULL,
+	invocation_id         TEXT    NOT NULL,
+	author                TEXT    NOT NULL,
+	branch                TEXT    NOT NULL DEFAULT '',
+	timestamp             INTEGER NOT NULL,
+	content               TEXT,
+	actions               TEXT    NOT NULL DEFAULT '{}',
+	long_running_tool_ids TEXT,
+	grounding_metadata    TEXT,
+	citation_metadata     TEXT,
+	usage_metadata        TEXT,
+	custom_metadata       TEXT,
+	partial               INTEGER NOT NULL DEFAULT 0,
+	turn_complete         INTEGER NOT NULL DEFAULT 0,
+	interrupted           INTEGER NOT NULL DEFAULT 0,
+	error_code            TEXT    NOT NULL DEFAULT '',
+	error_message         TEXT    NOT NULL DEFAULT '',
+	FOREIGN KEY (app_name, user_id, session_id) REFERENCES sessions (app_name, user_id, id) ON DELETE CASCADE
+);
+
+CREATE INDEX archived_events_session_seq_idx ON archived_events (app_name, user_id, session_id, seq);
@@ -0,0 +1,95 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/internal/sessionsql"
+	"google.golang.org/adk/session"
+)
+
+// eventText extracts the text of the parts of the content of an event,
+// joined by newlines.
+const eventText = `(SELECT group_concat(json_extract(p.value, '$.text'), char(10)) FROM json_each(e.content, '$.parts') AS p)`
+
+// Search searches the sessions of the user, implements session.Searcher.
+// The events and the sessions are preselected with LIKE, which ignores the
+// case of the ASCII letters only: the keywords with other letters only
+// match the text in lower case.
+func (s *sqliteService) Search(ctx context.Context, req *session.SearchRequest) (*session.SearchResponse, error) {
+	appName, userID := req.AppName, req.UserID
+	if appName == "" || userID == "" {
+		return nil, fmt.Errorf("app_name and user_id are required, got app_name: %q, user_id: %q", appName, userID)
+	}
+	matcher, err := req.Matcher()
+	if err != nil {
+		return nil, err
+	}
+	keywords := matcher.Keywords()
+	var results []session.SearchResult
+
+	conds, args := sessionsql.LikeConditions("text", "LIKE", keywords, 4)
+	rows, err := s.db.QueryContext(ctx, `WITH texts AS (
+	SELECT e.session_id, e.id, e.timestamp, e.content, `+eventText+` AS text
+	FROM events AS e JOIN sessions AS s ON s.app_name = e.app_name AND s.user_id = e.user_id AND s.id = e.session_id
+	WHERE e.app_name = $1 AND e.user_id = $2 AND s.update_time >= $3
+)
+SELECT session_id, id, timestamp, content FROM texts WHERE `+conds,
+		append([]any{appName, userID, s.cutoff()}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("database error while searching events: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			sessionID string
+			event     session.Event
+			timestamp int64
+			content   []byte
+		)
+		if err := rows.Scan(&sessionID, &event.ID, &timestamp, &content); err != nil {
+			return nil, fmt.Errorf("database error while searching events: %w", err)
+		}
+		event.Timestamp = timeFromMicros(timestamp)
+		event.Content = &genai.Content{}
+		if err := json.Unmarshal(content, event.Content); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal content of event %q: %w", event.ID, err)
+		}
+		if result, ok := matcher.MatchEvent(sessionID, &event); ok {
+			results = append(results, result)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("database error while searching events: %w", err)
+	}
+
+	conds, args = sessionsql.LikeConditions("j.value", "LIKE", keywords, 4)
+	sessions, err := s.fetchSessions(ctx, appName, `SELECT user_id, id, state, update_time FROM sessions
+WHERE app_name = $1 AND user_id = $2 AND update_time >= $3
+	AND EXISTS (SELECT 1 FROM json_each(state) AS j WHERE j.type = 'text' AND `+conds+`)`,
+		append([]any{appName, userID, s.cutoff()}, args...))
+	if err != nil {
+		return nil, err
+	}
+	for _, sess := range sessions {
+		results = append(results, matcher.MatchState(sess.ID(), sess.LastUpdateTime(), sess.StateMap())...)
+	}
+	return req.Page(results)
+}
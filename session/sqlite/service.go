@@ -0,0 +1,760 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sqlite provides a [session.Service] which stores the sessions in a
+// SQLite database file, for local development and command line runs which
+// need persistence without a database server.
+//
+// It uses the pure Go driver of github.com/glebarez/go-sqlite, a fork of
+// modernc.org/sqlite, so it doesn't need cgo. It is the driver of the
+// SQLite dialect of the database package too, so both services can be
+// linked in the same binary. The driver registers itself as "sqlite", so
+// it can't be linked together with modernc.org/sqlite, which registers the
+// same name.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	_ "github.com/glebarez/go-sqlite" // Registers the "sqlite" database/sql driver.
+	"github.com/google/uuid"
+
+	"google.golang.org/adk/internal/sessioninternal"
+	"google.golang.org/adk/internal/sessionsql"
+	"google.golang.org/adk/internal/sessionutils"
+	"google.golang.org/adk/session"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// MemoryPath is the [Config.Path] of a database which lives in memory and
+// is lost once the service is closed, e.g. for tests.
+const MemoryPath = ":memory:"
+
+// busyTimeout is how long a write waits for the writes of other processes
+// sharing the database file.
+const busyTimeout = 5 * time.Second
+
+// Config is the configuration of the SQLite session service.
+type Config struct {
+	// Path is the path of the database file, which is created if it doesn't
+	// exist. [MemoryPath] keeps the database in memory.
+	Path string
+	// Expiration configures the expiration of the sessions, which the
+	// service sweeps once [session.Sweeper.Start] is called.
+	// Optional: if zero, the sessions don't expire.
+	Expiration session.ExpirationConfig
+}
+
+// sqliteService is a SQLite implementation of session.Service.
+type sqliteService struct {
+	db *sql.DB
+	// mu serializes the write transactions, since SQLite allows a single
+	// writer at a time.
+	mu         sync.Mutex
+	expiration session.ExpirationConfig
+}
+
+// NewSessionService opens the database file, migrates its schema and
+// returns a new [session.Service] which stores the sessions in it. Write
+// ahead logging is enabled, so that the reads don't wait for the writes.
+//
+// The service implements [io.Closer], which closes the database,
+// [session.Sweeper] and [session.Compactor].
+func NewSessionService(cfg Config) (session.Service, error) {
+	if cfg.Path == "" {
+		return nil, fmt.Errorf("path is required")
+	}
+	expiration, err := cfg.Expiration.WithDefaults()
+	if err != nil {
+		return nil, err
+	}
+	query := url.Values{}
+	query.Add("_pragma", "foreign_keys(1)")
+	query.Add("_pragma", "busy_timeout("+strconv.Itoa(int(busyTimeout.Milliseconds()))+")")
+	query.Add("_pragma", "journal_mode(WAL)")
+	// Take the write lock when the transactions begin, so that concurrent
+	// transactions wait for it instead of failing when they upgrade their
+	// read lock.
+	query.Set("_txlock", "immediate")
+	db, err := sql.Open("sqlite", cfg.Path+"?"+query.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("error creating sqlite session service: %w", err)
+	}
+	if cfg.Path == MemoryPath {
+		// Every connection to :memory: opens a different database.
+		db.SetMaxOpenConns(1)
+		db.SetConnMaxLifetime(0)
+		db.SetConnMaxIdleTime(0)
+	}
+
+	s := &sqliteService{db: db, expiration: expiration}
+	if err := s.migrate(context.Background()); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error creating sqlite session service: %w", err)
+	}
+	return s, nil
+}
+
+// Close closes the database.
+func (s *sqliteService) Close() error {
+	return s.db.Close()
+}
+
+// migrate applies the migrations which weren't applied yet.
+func (s *sqliteService) migrate(ctx context.Context) error {
+	migrations, err := sessionsql.LoadMigrations(migrationFiles, "migrations")
+	if err != nil {
+		return err
+	}
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		return sessionsql.ApplyMigrations(ctx, tx, migrations)
+	})
+}
+
+// querier is implemented by both [*sql.DB] and [*sql.Tx].
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// Create inserts a new session, implements session.Service.
+// It fails if the session already exists.
+func (s *sqliteService) Create(ctx context.Context, req *session.CreateRequest) (*session.CreateResponse, error) {
+	if req.AppName == "" || req.UserID == "" {
+		return nil, fmt.Errorf("app_name and user_id are required")
+	}
+
+	sessionID := req.SessionID
+	if sessionID == "" {
+		sessionID = uuid.NewString()
+	}
+	now := s.expiration.Now().Truncate(time.Microsecond)
+	appDelta, userDelta, sessionState := sessionutils.ExtractStateDeltas(req.State)
+
+	var state map[string]any
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		var exists bool
+		err := tx.QueryRowContext(ctx,
+			"SELECT EXISTS (SELECT 1 FROM sessions WHERE app_name = $1 AND user_id = $2 AND id = $3)",
+			req.AppName, req.UserID, sessionID).Scan(&exists)
+		if err != nil {
+			return fmt.Errorf("error creating session on database: %w", err)
+		}
+		if exists {
+			return fmt.Errorf("session %q already exists", sessionID)
+		}
+
+		appState, err := mergeAppState(ctx, tx, req.AppName, appDelta, now)
+		if err != nil {
+			return err
+		}
+		userState, err := mergeUserState(ctx, tx, req.AppName, req.UserID, userDelta, now)
+		if err != nil {
+			return err
+		}
+		data, err := sessionsql.MarshalState(sessionState)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx,
+			"INSERT INTO sessions (app_name, user_id, id, state, create_time, update_time) VALUES ($1, $2, $3, $4, $5, $5)",
+			req.AppName, req.UserID, sessionID, string(data), now.UnixMicro()); err != nil {
+			return fmt.Errorf("error creating session on database: %w", err)
+		}
+		state = sessionutils.MergeStates(appState, userState, sessionState)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &session.CreateResponse{
+		Session: sessioninternal.NewStoredSession(sessioninternal.StoredSessionConfig{
+			AppName:   req.AppName,
+			UserID:    req.UserID,
+			SessionID: sessionID,
+			State:     state,
+			UpdatedAt: now,
+		}),
+	}, nil
+}
+
+// Get retrieves a session with its events, implements session.Service.
+func (s *sqliteService) Get(ctx context.Context, req *session.GetRequest) (*session.GetResponse, error) {
+	appName, userID, sessionID := req.AppName, req.UserID, req.SessionID
+	if appName == "" || userID == "" || sessionID == "" {
+		return nil, fmt.Errorf("app_name, user_id, session_id are required, got app_name: %q, user_id: %q, session_id: %q", appName, userID, sessionID)
+	}
+
+	var (
+		stateData []byte
+		updatedAt int64
+	)
+	err := s.db.QueryRowContext(ctx,
+		"SELECT state, update_time FROM sessions WHERE app_name = $1 AND user_id = $2 AND id = $3 AND update_time >= $4",
+		appName, userID, sessionID, s.cutoff()).Scan(&stateData, &updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("session %q not found", sessionID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("database error while fetching session: %w", err)
+	}
+	sessionState, err := sessionsql.UnmarshalState(stateData)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := s.fetchEvents(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	appState, err := fetchAppState(ctx, s.db, appName)
+	if err != nil {
+		return nil, fmt.Errorf("error on get session: %w", err)
+	}
+	userState, err := fetchUserState(ctx, s.db, appName, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error on get session: %w", err)
+	}
+
+	return &session.GetResponse{
+		Session: sessioninternal.NewStoredSession(sessioninternal.StoredSessionConfig{
+			AppName:   appName,
+			UserID:    userID,
+			SessionID: sessionID,
+			Events:    events,
+			State:     sessionutils.MergeStates(appState, userState, sessionState),
+			UpdatedAt: timeFromMicros(updatedAt),
+		}),
+	}, nil
+}
+
+// fetchEvents returns the events of the session selected by the request, in
+// the order they were appended.
+func (s *sqliteService) fetchEvents(ctx context.Context, req *session.GetRequest) ([]*session.Event, error) {
+	var query strings.Builder
+	query.WriteString("SELECT " + eventColumns + " FROM events WHERE app_name = $1 AND user_id = $2 AND session_id = $3")
+	args := []any{req.AppName, req.UserID, req.SessionID}
+	if !req.After.IsZero() {
+		args = append(args, req.After.UnixMicro())
+		query.WriteString(" AND timestamp >= $" + strconv.Itoa(len(args)))
+	}
+	// Order the most recent events first, so that LIMIT keeps them.
+	query.WriteString(" ORDER BY seq DESC")
+	if req.NumRecentEvents > 0 {
+		args = append(args, req.NumRecentEvents)
+		query.WriteString(" LIMIT $" + strconv.Itoa(len(args)))
+	}
+
+	rows, err := s.db.QueryContext(ctx, query.String(), args...)
+	if err != nil {
+		return nil, fmt.Errorf("database error while fetching events: %w", err)
+	}
+	defer rows.Close()
+	var events []*session.Event
+	for rows.Next() {
+		var (
+			row       sessionsql.EventRow
+			timestamp int64
+		)
+		if err := rows.Scan(eventScanDest(&row, &timestamp)...); err != nil {
+			return nil, fmt.Errorf("database error while fetching events: %w", err)
+		}
+		row.Timestamp = timeFromMicros(timestamp)
+		event, err := row.Event()
+		if err != nil {
+			return nil, fmt.Errorf("failed to map event %q: %w", row.ID, err)
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("database error while fetching events: %w", err)
+	}
+	// Restore the chronological order.
+	slices.Reverse(events)
+	return events, nil
+}
+
+// List retrieves the sessions of an app and optionally of a user, implements
+// session.Service.
+func (s *sqliteService) List(ctx context.Context, req *session.ListRequest) (*session.ListResponse, error) {
+	appName, userID := req.AppName, req.UserID
+	if appName == "" {
+		return nil, fmt.Errorf("app_name is required, got app_name: %q", req.AppName)
+	}
+	offset, err := sessionutils.DecodePageToken(req.PageToken)
+	if err != nil {
+		return nil, err
+	}
+
+	var query strings.Builder
+	query.WriteString("SELECT user_id, id, state, update_time FROM sessions WHERE app_name = $1 AND update_time >= $2")
+	args := []any{appName, s.cutoff()}
+	if userID != "" {
+		args = append(args, userID)
+		query.WriteString(" AND user_id = $" + strconv.Itoa(len(args)))
+	}
+	query.WriteString(" ORDER BY update_time DESC, id ASC")
+	// SQLite requires a LIMIT before an OFFSET, -1 is no limit.
+	limit := -1
+	if req.PageSize > 0 {
+		// Fetch one more session to know whether there is a next page.
+		limit = req.PageSize + 1
+	}
+	args = append(args, limit, offset)
+	query.WriteString(" LIMIT $" + strconv.Itoa(len(args)-1) + " OFFSET $" + strconv.Itoa(len(args)))
+
+	sessions, err := s.fetchSessions(ctx, appName, query.String(), args)
+	if err != nil {
+		return nil, err
+	}
+
+	nextPageToken := ""
+	if req.PageSize > 0 && len(sessions) > req.PageSize {
+		sessions = sessions[:req.PageSize]
+		nextPageToken = sessionutils.EncodePageToken(offset + req.PageSize)
+	}
+
+	appState, err := fetchAppState(ctx, s.db, appName)
+	if err != nil {
+		return nil, fmt.Errorf("error on list sessions: %w", err)
+	}
+	userStates := make(map[string]map[string]any)
+	if userID != "" {
+		userState, err := fetchUserState(ctx, s.db, appName, userID)
+		if err != nil {
+			return nil, fmt.Errorf("error on list sessions: %w", err)
+		}
+		userStates[userID] = userState
+	} else if userStates, err = fetchAllUserStates(ctx, s.db, appName); err != nil {
+		return nil, fmt.Errorf("error on list sessions: %w", err)
+	}
+
+	responseSessions := make([]session.Session, 0, len(sessions))
+	for _, sess := range sessions {
+		sess.SetState(sessionutils.MergeStates(appState, userStates[sess.UserID()], sess.StateMap()))
+		responseSessions = append(responseSessions, sess)
+	}
+	return &session.ListResponse{
+		Sessions:      responseSessions,
+		NextPageToken: nextPageToken,
+	}, nil
+}
+
+// fetchSessions runs a query of the user_id, id, state and update_time
+// columns of sessions, and returns the sessions without their events.
+func (s *sqliteService) fetchSessions(ctx context.Context, appName, query string, args []any) ([]*sessioninternal.StoredSession, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("database error while fetching sessions: %w", err)
+	}
+	defer rows.Close()
+	var sessions []*sessioninternal.StoredSession
+	for rows.Next() {
+		cfg := sessioninternal.StoredSessionConfig{AppName: appName}
+		var (
+			stateData []byte
+			updatedAt int64
+		)
+		if err := rows.Scan(&cfg.UserID, &cfg.SessionID, &stateData, &updatedAt); err != nil {
+			return nil, fmt.Errorf("database error while fetching sessions: %w", err)
+		}
+		cfg.UpdatedAt = timeFromMicros(updatedAt)
+		if cfg.State, err = sessionsql.UnmarshalState(stateData); err != nil {
+			return nil, fmt.Errorf("failed to map session %q: %w", cfg.SessionID, err)
+		}
+		sessions = append(sessions, sessioninternal.NewStoredSession(cfg))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("database error while fetching sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// Ping checks that the database is reachable.
+func (s *sqliteService) Ping(ctx context.Context) error {
+	if err := s.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+	return nil
+}
+
+// cutoff returns the update time, in microseconds, before which the sessions
+// are expired.
+func (s *sqliteService) cutoff() int64 {
+	cutoff := s.expiration.Cutoff()
+	if cutoff.IsZero() {
+		return math.MinInt64
+	}
+	return cutoff.UnixMicro()
+}
+
+// Start starts sweeping the expired sessions, implements session.Sweeper.
+func (s *sqliteService) Start(ctx context.Context) {
+	if s.expiration.ExpireAfter == 0 {
+		return
+	}
+	sessionutils.StartSweeper(ctx, s.expiration.SweepInterval, s.Sweep)
+}
+
+// Sweep deletes the expired sessions and their events, implements
+// session.Sweeper.
+func (s *sqliteService) Sweep(ctx context.Context) error {
+	if s.expiration.ExpireAfter == 0 {
+		return nil
+	}
+	var expired []session.ExpiredSession
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		// The events are deleted by the cascade of their foreign key.
+		rows, err := tx.QueryContext(ctx,
+			"DELETE FROM sessions WHERE update_time < $1 RETURNING app_name, user_id, id, update_time",
+			s.cutoff())
+		if err != nil {
+			return fmt.Errorf("failed to delete expired sessions: %w", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var (
+				e         session.ExpiredSession
+				updatedAt int64
+			)
+			if err := rows.Scan(&e.AppName, &e.UserID, &e.SessionID, &updatedAt); err != nil {
+				return fmt.Errorf("failed to delete expired sessions: %w", err)
+			}
+			e.LastUpdateTime = timeFromMicros(updatedAt)
+			expired = append(expired, e)
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to delete expired sessions: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if s.expiration.OnSessionExpired != nil {
+		for _, e := range expired {
+			s.expiration.OnSessionExpired(ctx, e)
+		}
+	}
+	return nil
+}
+
+// VersionChecking implements session.VersionChecker: AppendEvent rejects
+// the sessions older than the stored ones.
+func (s *sqliteService) VersionChecking() bool {
+	return true
+}
+
+// GetAppState returns the state of the app, implements session.StateService.
+func (s *sqliteService) GetAppState(ctx context.Context, req *session.GetAppStateRequest) (*session.GetAppStateResponse, error) {
+	if req.AppName == "" {
+		return nil, fmt.Errorf("app_name is required, got app_name: %q", req.AppName)
+	}
+	state, err := fetchAppState(ctx, s.db, req.AppName)
+	if err != nil {
+		return nil, err
+	}
+	return &session.GetAppStateResponse{State: state}, nil
+}
+
+// UpdateAppState merges the delta into the state of the app, implements session.StateService.
+func (s *sqliteService) UpdateAppState(ctx context.Context, req *session.UpdateAppStateRequest) error {
+	if req.AppName == "" {
+		return fmt.Errorf("app_name is required, got app_name: %q", req.AppName)
+	}
+	now := time.Now().Truncate(time.Microsecond)
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		_, err := mergeAppState(ctx, tx, req.AppName, req.Delta, now)
+		return err
+	})
+}
+
+// GetUserState returns the state of the user, implements session.StateService.
+func (s *sqliteService) GetUserState(ctx context.Context, req *session.GetUserStateRequest) (*session.GetUserStateResponse, error) {
+	if req.AppName == "" || req.UserID == "" {
+		return nil, fmt.Errorf("app_name and user_id are required, got app_name: %q, user_id: %q", req.AppName, req.UserID)
+	}
+	state, err := fetchUserState(ctx, s.db, req.AppName, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return &session.GetUserStateResponse{State: state}, nil
+}
+
+// UpdateUserState merges the delta into the state of the user, implements session.StateService.
+func (s *sqliteService) UpdateUserState(ctx context.Context, req *session.UpdateUserStateRequest) error {
+	if req.AppName == "" || req.UserID == "" {
+		return fmt.Errorf("app_name and user_id are required, got app_name: %q, user_id: %q", req.AppName, req.UserID)
+	}
+	now := time.Now().Truncate(time.Microsecond)
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		_, err := mergeUserState(ctx, tx, req.AppName, req.UserID, req.Delta, now)
+		return err
+	})
+}
+
+// Delete deletes a session and its events, implements session.Service.
+func (s *sqliteService) Delete(ctx context.Context, req *session.DeleteRequest) error {
+	appName, userID, sessionID := req.AppName, req.UserID, req.SessionID
+	if appName == "" || userID == "" || sessionID == "" {
+		return fmt.Errorf("app_name, user_id, session_id are required, got app_name: %q, user_id: %q, session_id: %q", appName, userID, sessionID)
+	}
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		// The events are deleted by the cascade of their foreign key.
+		if _, err := tx.ExecContext(ctx,
+			"DELETE FROM sessions WHERE app_name = $1 AND user_id = $2 AND id = $3",
+			appName, userID, sessionID); err != nil {
+			return fmt.Errorf("database error during session deletion: %w", err)
+		}
+		return nil
+	})
+}
+
+// AppendEvent appends the event to the session and persists it together with
+// its state delta, implements session.Service.
+func (s *sqliteService) AppendEvent(ctx context.Context, curSession session.Session, event *session.Event) error {
+	if curSession == nil {
+		return fmt.Errorf("session is nil")
+	}
+	if event == nil {
+		return fmt.Errorf("event is nil")
+	}
+	// ignore partial events, unless the caller opts in
+	if event.Partial && !session.SavesPartialEvents(ctx) {
+		return nil
+	}
+	sess, ok := curSession.(*sessioninternal.StoredSession)
+	if !ok {
+		return fmt.Errorf("unexpected session type %T", curSession)
+	}
+
+	// Truncate timestamp to microsecond precision to match database precision and prevent rounding errors.
+	event.Timestamp = event.Timestamp.Truncate(time.Microsecond)
+
+	if err := sess.AppendEvent(ctx, event); err != nil {
+		return err
+	}
+	// Trim temp state before persisting
+	event = sessioninternal.TrimTempDeltaState(event)
+	if err := s.applyEvent(ctx, sess, event); err != nil {
+		return err
+	}
+
+	sess.SetLastUpdateTime(event.Timestamp)
+	return nil
+}
+
+// applyEvent saves the event and merges its state delta in a single
+// transaction.
+func (s *sqliteService) applyEvent(ctx context.Context, sess *sessioninternal.StoredSession, event *session.Event) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		var (
+			stateData         []byte
+			storageUpdateTime int64
+		)
+		err := tx.QueryRowContext(ctx,
+			"SELECT state, update_time FROM sessions WHERE app_name = $1 AND user_id = $2 AND id = $3 AND update_time >= $4",
+			sess.AppName(), sess.UserID(), sess.ID(), s.cutoff()).Scan(&stateData, &storageUpdateTime)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("session not found, cannot apply event")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get session: %w", err)
+		}
+
+		// Ensure the session object is not stale.
+		sessionUpdateTime := sess.LastUpdateTime()
+		if storageUpdateTime > sessionUpdateTime.UnixMicro() {
+			return fmt.Errorf(
+				"%w: last update time from request (%s) is older than in database (%s)",
+				session.ErrConcurrentModification,
+				sessionUpdateTime.Format(time.RFC3339Nano),
+				timeFromMicros(storageUpdateTime).Format(time.RFC3339Nano),
+			)
+		}
+
+		appDelta, userDelta, sessionDelta := sessionutils.ExtractStateDeltas(event.Actions.StateDelta)
+		if len(appDelta) > 0 {
+			if _, err := mergeAppState(ctx, tx, sess.AppName(), appDelta, event.Timestamp); err != nil {
+				return err
+			}
+		}
+		if len(userDelta) > 0 {
+			if _, err := mergeUserState(ctx, tx, sess.AppName(), sess.UserID(), userDelta, event.Timestamp); err != nil {
+				return err
+			}
+		}
+
+		row, err := sessionsql.NewEventRow(event)
+		if err != nil {
+			return fmt.Errorf("failed to map event to storage model: %w", err)
+		}
+		args := append([]any{sess.AppName(), sess.UserID(), sess.ID()}, eventValues(row)...)
+		if _, err := tx.ExecContext(ctx,
+			"INSERT INTO events (app_name, user_id, session_id, "+eventColumns+") VALUES ("+sessionsql.Placeholders(len(args))+")",
+			args...); err != nil {
+			return fmt.Errorf("failed to save event: %w", err)
+		}
+
+		state, err := sessionsql.UnmarshalState(stateData)
+		if err != nil {
+			return err
+		}
+		maps.Copy(state, sessionDelta)
+		if stateData, err = sessionsql.MarshalState(state); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx,
+			"UPDATE sessions SET state = $4, update_time = $5 WHERE app_name = $1 AND user_id = $2 AND id = $3",
+			sess.AppName(), sess.UserID(), sess.ID(), string(stateData), event.Timestamp.UnixMicro()); err != nil {
+			return fmt.Errorf("failed to save session state: %w", err)
+		}
+		return nil
+	})
+}
+
+// inTx runs fn in a write transaction, which is committed if fn succeeds and
+// rolled back otherwise.
+func (s *sqliteService) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// mergeAppState merges the delta into the state of the app and returns the
+// merged state. An empty delta leaves the state untouched.
+func mergeAppState(ctx context.Context, q querier, appName string, delta map[string]any, now time.Time) (map[string]any, error) {
+	state, err := fetchAppState(ctx, q, appName)
+	if err != nil || len(delta) == 0 {
+		return state, err
+	}
+	maps.Copy(state, delta)
+	data, err := sessionsql.MarshalState(state)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := q.ExecContext(ctx, `INSERT INTO app_states (app_name, state, update_time) VALUES ($1, $2, $3)
+ON CONFLICT (app_name) DO UPDATE SET state = excluded.state, update_time = excluded.update_time`,
+		appName, string(data), now.UnixMicro()); err != nil {
+		return nil, fmt.Errorf("failed to save app state: %w", err)
+	}
+	return state, nil
+}
+
+// mergeUserState merges the delta into the state of the user and returns the
+// merged state. An empty delta leaves the state untouched.
+func mergeUserState(ctx context.Context, q querier, appName, userID string, delta map[string]any, now time.Time) (map[string]any, error) {
+	state, err := fetchUserState(ctx, q, appName, userID)
+	if err != nil || len(delta) == 0 {
+		return state, err
+	}
+	maps.Copy(state, delta)
+	data, err := sessionsql.MarshalState(state)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := q.ExecContext(ctx, `INSERT INTO user_states (app_name, user_id, state, update_time) VALUES ($1, $2, $3, $4)
+ON CONFLICT (app_name, user_id) DO UPDATE SET state = excluded.state, update_time = excluded.update_time`,
+		appName, userID, string(data), now.UnixMicro()); err != nil {
+		return nil, fmt.Errorf("failed to save user state: %w", err)
+	}
+	return state, nil
+}
+
+func fetchAppState(ctx context.Context, q querier, appName string) (map[string]any, error) {
+	var data []byte
+	err := q.QueryRowContext(ctx, "SELECT state FROM app_states WHERE app_name = $1", appName).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return make(map[string]any), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch app state: %w", err)
+	}
+	return sessionsql.UnmarshalState(data)
+}
+
+func fetchUserState(ctx context.Context, q querier, appName, userID string) (map[string]any, error) {
+	var data []byte
+	err := q.QueryRowContext(ctx, "SELECT state FROM user_states WHERE app_name = $1 AND user_id = $2", appName, userID).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return make(map[string]any), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user state: %w", err)
+	}
+	return sessionsql.UnmarshalState(data)
+}
+
+func fetchAllUserStates(ctx context.Context, q querier, appName string) (map[string]map[string]any, error) {
+	rows, err := q.QueryContext(ctx, "SELECT user_id, state FROM user_states WHERE app_name = $1", appName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user states: %w", err)
+	}
+	defer rows.Close()
+	states := make(map[string]map[string]any)
+	for rows.Next() {
+		var (
+			userID string
+			data   []byte
+		)
+		if err := rows.Scan(&userID, &data); err != nil {
+			return nil, fmt.Errorf("failed to fetch user states: %w", err)
+		}
+		if states[userID], err = sessionsql.UnmarshalState(data); err != nil {
+			return nil, err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch user states: %w", err)
+	}
+	return states, nil
+}
+
+var (
+	_ session.Service         = (*sqliteService)(nil)
+	_ session.StateService    = (*sqliteService)(nil)
+	_ session.VersionChecker  = (*sqliteService)(nil)
+	_ session.Sweeper         = (*sqliteService)(nil)
+	_ session.Compactor       = (*sqliteService)(nil)
+	_ session.UserDataDeleter = (*sqliteService)(nil)
+	_ session.Searcher        = (*sqliteService)(nil)
+)
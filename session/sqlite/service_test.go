@@ -0,0 +1,261 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	gormsqlite "github.com/glebarez/sqlite"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/session/database"
+	"google.golang.org/adk/session/sessiontest"
+)
+
+func newTestService(t *testing.T, path string) session.Service {
+	t.Helper()
+	s, err := NewSessionService(Config{Path: path})
+	if err != nil {
+		t.Fatalf("NewSessionService() error = %v", err)
+	}
+	t.Cleanup(func() {
+		if err := s.(io.Closer).Close(); err != nil {
+			t.Errorf("Close() error = %v", err)
+		}
+	})
+	return s
+}
+
+func TestSQLiteService_Conformance(t *testing.T) {
+	t.Run("memory", func(t *testing.T) {
+		sessiontest.RunConformance(t, func(t *testing.T) session.Service {
+			return newTestService(t, MemoryPath)
+		})
+	})
+	t.Run("file", func(t *testing.T) {
+		sessiontest.RunConformance(t, func(t *testing.T) session.Service {
+			return newTestService(t, filepath.Join(t.TempDir(), "sessions.db"))
+		})
+	})
+}
+
+func TestSQLiteService_Expiration(t *testing.T) {
+	sessiontest.RunExpiration(t, func(t *testing.T, cfg session.ExpirationConfig) session.Service {
+		s, err := NewSessionService(Config{Path: filepath.Join(t.TempDir(), "sessions.db"), Expiration: cfg})
+		if err != nil {
+			t.Fatalf("NewSessionService() error = %v", err)
+		}
+		t.Cleanup(func() {
+			if err := s.(io.Closer).Close(); err != nil {
+				t.Errorf("Close() error = %v", err)
+			}
+		})
+		return s
+	})
+}
+
+func TestSQLiteService_Compaction(t *testing.T) {
+	sessiontest.RunCompaction(t, func(t *testing.T) session.Service {
+		return newTestService(t, MemoryPath)
+	})
+}
+
+func TestSQLiteService_CompactArchive(t *testing.T) {
+	for _, archive := range []bool{false, true} {
+		t.Run(fmt.Sprint("archive=", archive), func(t *testing.T) {
+			s := newTestService(t, MemoryPath)
+			created, err := s.Create(t.Context(), &session.CreateRequest{AppName: "app", UserID: "user", SessionID: "session"})
+			if err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+			for i := range 3 {
+				event := &session.Event{ID: fmt.Sprint("e", i), Author: "user", Timestamp: time.Now().Add(time.Duration(i) * time.Second)}
+				if err := s.AppendEvent(t.Context(), created.Session, event); err != nil {
+					t.Fatalf("AppendEvent() error = %v", err)
+				}
+			}
+			_, err = s.(session.Compactor).Compact(t.Context(),
+				&session.CompactRequest{AppName: "app", UserID: "user", SessionID: "session"},
+				session.CompactionConfig{
+					Summarize: func(ctx context.Context, events []*session.Event) (*genai.Content, error) {
+						return genai.NewContentFromText("summary", genai.RoleUser), nil
+					},
+					KeepRecent: 1,
+					Archive:    archive,
+				})
+			if err != nil {
+				t.Fatalf("Compact() error = %v", err)
+			}
+
+			var ids []string
+			rows, err := s.(*sqliteService).db.QueryContext(t.Context(), "SELECT id FROM archived_events ORDER BY seq")
+			if err != nil {
+				t.Fatalf("query archived events: %v", err)
+			}
+			defer rows.Close()
+			for rows.Next() {
+				var id string
+				if err := rows.Scan(&id); err != nil {
+					t.Fatalf("scan archived event: %v", err)
+				}
+				ids = append(ids, id)
+			}
+			var want []string
+			if archive {
+				want = []string{"e0", "e1"}
+			}
+			if diff := cmp.Diff(want, ids); diff != "" {
+				t.Errorf("archived events mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNewSessionService(t *testing.T) {
+	if _, err := NewSessionService(Config{}); err == nil {
+		t.Errorf("NewSessionService() without path succeeded, want error")
+	}
+	if _, err := NewSessionService(Config{Path: filepath.Join(t.TempDir(), "missing", "sessions.db")}); err == nil {
+		t.Errorf("NewSessionService() in a missing directory succeeded, want error")
+	}
+	if _, err := NewSessionService(Config{Path: MemoryPath, Expiration: session.ExpirationConfig{ExpireAfter: -time.Hour}}); err == nil {
+		t.Errorf("NewSessionService() with a negative expiration succeeded, want error")
+	}
+}
+
+// TestSQLiteService_WithDatabaseService checks that the service can be linked
+// with the SQLite dialect of the database package, which registers the same
+// driver.
+func TestSQLiteService_WithDatabaseService(t *testing.T) {
+	ctx := t.Context()
+	gormService, err := database.NewSessionService(gormsqlite.Open("file::memory:"))
+	if err != nil {
+		t.Fatalf("database.NewSessionService() error = %v", err)
+	}
+	if err := database.AutoMigrate(gormService); err != nil {
+		t.Fatalf("database.AutoMigrate() error = %v", err)
+	}
+	for name, s := range map[string]session.Service{"database": gormService, "sqlite": newTestService(t, MemoryPath)} {
+		if _, err := s.Create(ctx, &session.CreateRequest{AppName: "app", UserID: "user", SessionID: "session"}); err != nil {
+			t.Errorf("%s: Create() error = %v", name, err)
+		}
+	}
+}
+
+func TestSQLiteService_Persistence(t *testing.T) {
+	ctx := t.Context()
+	path := filepath.Join(t.TempDir(), "sessions.db")
+
+	s, err := NewSessionService(Config{Path: path})
+	if err != nil {
+		t.Fatalf("NewSessionService() error = %v", err)
+	}
+	resp, err := s.Create(ctx, &session.CreateRequest{AppName: "app", UserID: "user", SessionID: "session", State: map[string]any{"app:a": "app"}})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	event := &session.Event{
+		ID:        "e1",
+		Author:    "user",
+		Timestamp: time.Now(),
+		Actions:   session.EventActions{StateDelta: map[string]any{"k": "v"}},
+	}
+	if err := s.AppendEvent(ctx, resp.Session, event); err != nil {
+		t.Fatalf("AppendEvent() error = %v", err)
+	}
+	var mode string
+	if err := s.(*sqliteService).db.QueryRowContext(ctx, "PRAGMA journal_mode").Scan(&mode); err != nil {
+		t.Fatalf("PRAGMA journal_mode error = %v", err)
+	}
+	if mode != "wal" {
+		t.Errorf("journal_mode = %q, want wal", mode)
+	}
+	if err := s.(io.Closer).Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	// Reopening the file keeps the sessions, and doesn't migrate it again.
+	s = newTestService(t, path)
+	got, err := s.Get(ctx, &session.GetRequest{AppName: "app", UserID: "user", SessionID: "session"})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.Session.Events().Len() != 1 {
+		t.Errorf("Get() returned %d events, want 1", got.Session.Events().Len())
+	}
+	state := map[string]any{}
+	for k, v := range got.Session.State().All() {
+		state[k] = v
+	}
+	if diff := cmp.Diff(map[string]any{"app:a": "app", "k": "v"}, state); diff != "" {
+		t.Errorf("Get() state mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSQLiteService_ConcurrentAppends(t *testing.T) {
+	ctx := t.Context()
+	s := newTestService(t, filepath.Join(t.TempDir(), "sessions.db"))
+
+	const n = 20
+	var sessions []session.Session
+	for i := range n {
+		resp, err := s.Create(ctx, &session.CreateRequest{AppName: "app", UserID: "user", SessionID: fmt.Sprint("s", i)})
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		sessions = append(sessions, resp.Session)
+	}
+
+	// All the appends update the same user state, so the writes must not
+	// interleave.
+	var wg sync.WaitGroup
+	errs := make([]error, n)
+	for i, sess := range sessions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = s.AppendEvent(ctx, sess, &session.Event{
+				ID:        "e",
+				Author:    "user",
+				Timestamp: time.Now().Add(time.Minute),
+				Actions:   session.EventActions{StateDelta: map[string]any{fmt.Sprint("user:k", i): "v"}},
+			})
+		}()
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("AppendEvent(s%d) error = %v", i, err)
+		}
+	}
+
+	got, err := s.Get(ctx, &session.GetRequest{AppName: "app", UserID: "user", SessionID: "s0"})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	for i := range n {
+		if _, err := got.Session.State().Get(fmt.Sprint("user:k", i)); err != nil {
+			t.Errorf("Get() state is missing user:k%d", i)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"google.golang.org/adk/session"
+)
+
+// userSessionsBatch selects the IDs of the next batch of sessions of a user
+// to delete, the parameters being the app name, the user ID and the batch
+// size.
+const userSessionsBatch = "SELECT id FROM sessions WHERE app_name = $1 AND user_id = $2 ORDER BY id LIMIT $3"
+
+// DeleteUserData deletes the sessions of the user in batches of
+// session.DeleteUserDataBatchSize, one transaction per batch, then the user
+// state, implements session.UserDataDeleter.
+func (s *sqliteService) DeleteUserData(ctx context.Context, req *session.DeleteUserDataRequest) (*session.DeleteUserDataResponse, error) {
+	appName, userID := req.AppName, req.UserID
+	if appName == "" || userID == "" {
+		return nil, fmt.Errorf("app_name and user_id are required, got app_name: %q, user_id: %q", appName, userID)
+	}
+	resp := &session.DeleteUserDataResponse{}
+	for {
+		var deletedSessions int
+		err := s.inTx(ctx, func(tx *sql.Tx) error {
+			// The events would be deleted by the cascade of their foreign
+			// key too, but then they couldn't be counted.
+			for _, table := range []string{"events", "archived_events"} {
+				n, err := execCount(ctx, tx,
+					"DELETE FROM "+table+" WHERE app_name = $1 AND user_id = $2 AND session_id IN ("+userSessionsBatch+")",
+					appName, userID, session.DeleteUserDataBatchSize)
+				if err != nil {
+					return fmt.Errorf("database error during %s deletion: %w", table, err)
+				}
+				resp.DeletedEvents += n
+			}
+			n, err := execCount(ctx, tx,
+				"DELETE FROM sessions WHERE app_name = $1 AND user_id = $2 AND id IN ("+userSessionsBatch+")",
+				appName, userID, session.DeleteUserDataBatchSize)
+			if err != nil {
+				return fmt.Errorf("database error during session deletion: %w", err)
+			}
+			deletedSessions = n
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		resp.DeletedSessions += deletedSessions
+		if deletedSessions < session.DeleteUserDataBatchSize {
+			break
+		}
+	}
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		n, err := execCount(ctx, tx, "DELETE FROM user_states WHERE app_name = $1 AND user_id = $2", appName, userID)
+		if err != nil {
+			return fmt.Errorf("database error during user state deletion: %w", err)
+		}
+		resp.DeletedUserState = n > 0
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// execCount executes the statement and returns the number of affected rows.
+func execCount(ctx context.Context, tx *sql.Tx, query string, args ...any) (int, error) {
+	res, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
@@ -0,0 +1,121 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"google.golang.org/adk/model"
+)
+
+// KeyInvocationUsage is the key of the state of a session which records the
+// token usage of its last invocation, a map with the "invocation_id" key of
+// the invocation, the token counts of [Usage.StateValue] and the "models"
+// key of the token counts by model name. The runner records it in the state
+// delta of the events of the model responses, so that it sums the usage of
+// the invocation so far.
+const KeyInvocationUsage = "invocation_usage"
+
+// Usage is a sum of the usage metadata of model responses.
+type Usage struct {
+	// Responses is the number of summed model responses.
+	Responses        int
+	PromptTokens     int64
+	CandidatesTokens int64
+	CachedTokens     int64
+	ThoughtsTokens   int64
+	ToolUseTokens    int64
+	TotalTokens      int64
+}
+
+// StateValue returns the token counts, as a value of the state of a
+// session.
+func (u *Usage) StateValue() map[string]any {
+	return map[string]any{
+		"responses":         u.Responses,
+		"prompt_tokens":     u.PromptTokens,
+		"candidates_tokens": u.CandidatesTokens,
+		"cached_tokens":     u.CachedTokens,
+		"thoughts_tokens":   u.ThoughtsTokens,
+		"tool_use_tokens":   u.ToolUseTokens,
+		"total_tokens":      u.TotalTokens,
+	}
+}
+
+// InvocationUsage sums the token usage of the events of an invocation, in
+// total and by model.
+//
+// Only the non-partial events are counted: the usage of a streamed model
+// call is reported by exactly one of its non-partial events, the partial
+// ones are not committed to the session.
+type InvocationUsage struct {
+	InvocationID string
+	Total        Usage
+	// Models is the usage by model name, as recorded by the
+	// [model.ModelMetadataKey] custom metadata of the events. The usage of
+	// the events without the metadata is recorded under the empty name.
+	Models map[string]*Usage
+}
+
+// NewInvocationUsage returns the usage of the events of the invocation.
+func NewInvocationUsage(invocationID string, events Events) *InvocationUsage {
+	u := &InvocationUsage{InvocationID: invocationID}
+	if events == nil {
+		return u
+	}
+	for event := range events.All() {
+		if event.InvocationID == invocationID {
+			u.Add(event)
+		}
+	}
+	return u
+}
+
+// Add adds the usage metadata of the event, unless it is partial.
+func (u *InvocationUsage) Add(event *Event) {
+	if event == nil || event.Partial || event.UsageMetadata == nil {
+		return
+	}
+	name, _ := event.CustomMetadata[model.ModelMetadataKey].(string)
+	if u.Models == nil {
+		u.Models = make(map[string]*Usage)
+	}
+	modelUsage, ok := u.Models[name]
+	if !ok {
+		modelUsage = &Usage{}
+		u.Models[name] = modelUsage
+	}
+	for _, usage := range []*Usage{&u.Total, modelUsage} {
+		metadata := event.UsageMetadata
+		usage.Responses++
+		usage.PromptTokens += int64(metadata.PromptTokenCount)
+		usage.CandidatesTokens += int64(metadata.CandidatesTokenCount)
+		usage.CachedTokens += int64(metadata.CachedContentTokenCount)
+		usage.ThoughtsTokens += int64(metadata.ThoughtsTokenCount)
+		usage.ToolUseTokens += int64(metadata.ToolUsePromptTokenCount)
+		usage.TotalTokens += int64(metadata.TotalTokenCount)
+	}
+}
+
+// StateValue returns the usage as the value of the [KeyInvocationUsage]
+// state of a session.
+func (u *InvocationUsage) StateValue() map[string]any {
+	value := u.Total.StateValue()
+	value["invocation_id"] = u.InvocationID
+	models := make(map[string]any, len(u.Models))
+	for name, usage := range u.Models {
+		models[name] = usage.StateValue()
+	}
+	value["models"] = models
+	return value
+}
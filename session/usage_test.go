@@ -0,0 +1,116 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+)
+
+func TestNewInvocationUsage(t *testing.T) {
+	event := func(invocationID, modelName string, partial bool, usage *genai.GenerateContentResponseUsageMetadata) *Event {
+		ev := NewEvent(invocationID)
+		ev.Partial = partial
+		ev.UsageMetadata = usage
+		if modelName != "" {
+			ev.CustomMetadata = map[string]any{model.ModelMetadataKey: modelName}
+		}
+		return ev
+	}
+	evs := events{
+		event("inv-1", "", false, nil),
+		event("inv-1", "m1", true, &genai.GenerateContentResponseUsageMetadata{PromptTokenCount: 100, TotalTokenCount: 100}),
+		event("inv-1", "m1", false, &genai.GenerateContentResponseUsageMetadata{
+			PromptTokenCount:        100,
+			CandidatesTokenCount:    20,
+			CachedContentTokenCount: 50,
+			ThoughtsTokenCount:      7,
+			ToolUsePromptTokenCount: 3,
+			TotalTokenCount:         130,
+		}),
+		event("inv-1", "m2", false, &genai.GenerateContentResponseUsageMetadata{PromptTokenCount: 10, CandidatesTokenCount: 1, TotalTokenCount: 11}),
+		event("inv-1", "", false, &genai.GenerateContentResponseUsageMetadata{PromptTokenCount: 1, TotalTokenCount: 1}),
+		event("inv-2", "m1", false, &genai.GenerateContentResponseUsageMetadata{PromptTokenCount: 1000, TotalTokenCount: 1000}),
+	}
+
+	tests := []struct {
+		name         string
+		invocationID string
+		events       Events
+		want         *InvocationUsage
+	}{
+		{
+			name:         "usage",
+			invocationID: "inv-1",
+			events:       evs,
+			want: &InvocationUsage{
+				InvocationID: "inv-1",
+				Total:        Usage{Responses: 3, PromptTokens: 111, CandidatesTokens: 21, CachedTokens: 50, ThoughtsTokens: 7, ToolUseTokens: 3, TotalTokens: 142},
+				Models: map[string]*Usage{
+					"m1": {Responses: 1, PromptTokens: 100, CandidatesTokens: 20, CachedTokens: 50, ThoughtsTokens: 7, ToolUseTokens: 3, TotalTokens: 130},
+					"m2": {Responses: 1, PromptTokens: 10, CandidatesTokens: 1, TotalTokens: 11},
+					"":   {Responses: 1, PromptTokens: 1, TotalTokens: 1},
+				},
+			},
+		},
+		{
+			name:         "no events",
+			invocationID: "inv-3",
+			events:       evs,
+			want:         &InvocationUsage{InvocationID: "inv-3"},
+		},
+		{
+			name:         "nil events",
+			invocationID: "inv-1",
+			want:         &InvocationUsage{InvocationID: "inv-1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewInvocationUsage(tt.invocationID, tt.events)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("NewInvocationUsage() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestInvocationUsage_StateValue(t *testing.T) {
+	u := &InvocationUsage{
+		InvocationID: "inv-1",
+		Total:        Usage{Responses: 1, PromptTokens: 10, TotalTokens: 10},
+		Models:       map[string]*Usage{"m1": {Responses: 1, PromptTokens: 10, TotalTokens: 10}},
+	}
+	usage := map[string]any{
+		"responses":         1,
+		"prompt_tokens":     int64(10),
+		"candidates_tokens": int64(0),
+		"cached_tokens":     int64(0),
+		"thoughts_tokens":   int64(0),
+		"tool_use_tokens":   int64(0),
+		"total_tokens":      int64(10),
+	}
+	want := map[string]any{"invocation_id": "inv-1", "models": map[string]any{"m1": usage}}
+	for k, v := range usage {
+		want[k] = v
+	}
+	if diff := cmp.Diff(want, u.StateValue()); diff != "" {
+		t.Errorf("StateValue() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import "context"
+
+// DeleteUserDataBatchSize is the number of sessions which the database
+// services delete per transaction in [UserDataDeleter.DeleteUserData], so
+// that erasing a user with many sessions doesn't hold a giant transaction.
+const DeleteUserDataBatchSize = 100
+
+// DeleteUserDataRequest represents a request to delete all the data of a
+// user of an app.
+type DeleteUserDataRequest struct {
+	AppName string
+	UserID  string
+}
+
+// DeleteUserDataResponse represents a response from
+// [UserDataDeleter.DeleteUserData].
+type DeleteUserDataResponse struct {
+	// DeletedSessions is the number of deleted sessions.
+	DeletedSessions int
+	// DeletedEvents is the number of deleted events, including the archived
+	// ones.
+	DeletedEvents int
+	// DeletedUserState reports whether the user had a user-scoped state,
+	// which was deleted.
+	DeletedUserState bool
+}
+
+// UserDataDeleter is implemented by the services which can delete all the
+// sessions, events, and the user-scoped state of a user at once, e.g. to
+// satisfy a data-deletion request. The app-scoped state is kept.
+//
+// DeleteUserData is idempotent: deleting the data of a user who has none
+// succeeds, with zero counts. If it fails midway, the data deleted so far
+// stays deleted, and calling it again deletes the rest.
+type UserDataDeleter interface {
+	DeleteUserData(ctx context.Context, req *DeleteUserDataRequest) (*DeleteUserDataResponse, error)
+}
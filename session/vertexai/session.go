@@ -68,11 +68,9 @@ func (s *localSession) LastUpdateTime() time.Time {
 	return s.updatedAt
 }
 
+// appendEvent appends the event, the partial events having been filtered by
+// the service.
 func (s *localSession) appendEvent(event *session.Event) error {
-	if event.Partial {
-		return nil
-	}
-
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -108,11 +108,11 @@ func (s *vertexAiService) List(ctx context.Context, req *session.ListRequest) (*
 	if req.AppName == "" {
 		return nil, fmt.Errorf("app_name is required, got app_name: %q", req.AppName)
 	}
-	sessions, err := s.client.listSessions(ctx, req)
+	sessions, nextPageToken, err := s.client.listSessions(ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to request sessions list: %w", err)
 	}
-	return &session.ListResponse{Sessions: sessions}, nil
+	return &session.ListResponse{Sessions: sessions, NextPageToken: nextPageToken}, nil
 }
 
 func (s *vertexAiService) Delete(ctx context.Context, req *session.DeleteRequest) error {
@@ -130,6 +130,10 @@ func (s *vertexAiService) AppendEvent(ctx context.Context, sess session.Session,
 	if sess.ID() == "" || event == nil {
 		return fmt.Errorf("session_id and event are required, got session_id: %q, event_id: %t", sess.ID(), event == nil)
 	}
+	// ignore partial events, unless the caller opts in
+	if event.Partial && !session.SavesPartialEvents(ctx) {
+		return nil
+	}
 	err := s.client.appendEvent(ctx, sess.AppName(), sess.ID(), event)
 	if err != nil {
 		return fmt.Errorf("failed to append event: %w", err)
@@ -164,12 +164,12 @@ func (c *vertexAiClient) getSession(ctx context.Context, req *session.GetRequest
 	}, nil
 }
 
-func (c *vertexAiClient) listSessions(ctx context.Context, req *session.ListRequest) ([]session.Session, error) {
+func (c *vertexAiClient) listSessions(ctx context.Context, req *session.ListRequest) ([]session.Session, string, error) {
 	sessions := make([]session.Session, 0)
 
 	reasoningEngine, err := c.getReasoningEngineID(req.AppName)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	rpcReq := &aiplatformpb.ListSessionsRequest{
 		Parent: fmt.Sprintf(engineResourceTemplate, c.projectID, c.location, reasoningEngine),
@@ -177,18 +177,37 @@ func (c *vertexAiClient) listSessions(ctx context.Context, req *session.ListRequ
 	if req.UserID != "" {
 		rpcReq.Filter = fmt.Sprintf("userId=\"%s\"", req.UserID)
 	}
+	paginated := req.PageSize > 0 || req.PageToken != ""
+	if paginated {
+		rpcReq.OrderBy = "update_time desc"
+	}
 	it := c.rpcClient.ListSessions(ctx, rpcReq)
-	for {
-		rpcResp, err := it.Next()
-		if err == iterator.Done {
-			break
-		}
+
+	var rpcSessions []*aiplatformpb.Session
+	nextPageToken := ""
+	if paginated {
+		// The page tokens of the service are passed through to the caller.
+		nextPageToken, err = iterator.NewPager(it, req.PageSize, req.PageToken).NextPage(&rpcSessions)
 		if err != nil {
-			return nil, fmt.Errorf("error creating session list: %w", err)
+			return nil, "", fmt.Errorf("error creating session list: %w", err)
+		}
+	} else {
+		for {
+			rpcResp, err := it.Next()
+			if err == iterator.Done {
+				break
+			}
+			if err != nil {
+				return nil, "", fmt.Errorf("error creating session list: %w", err)
+			}
+			rpcSessions = append(rpcSessions, rpcResp)
 		}
+	}
+
+	for _, rpcResp := range rpcSessions {
 		id, err := sessionIdBySessionName(rpcResp.Name)
 		if err != nil {
-			return nil, fmt.Errorf("error creating session list: %w", err)
+			return nil, "", fmt.Errorf("error creating session list: %w", err)
 		}
 		session := &localSession{
 			appName:   req.AppName,
@@ -199,7 +218,7 @@ func (c *vertexAiClient) listSessions(ctx context.Context, req *session.ListRequ
 		}
 		sessions = append(sessions, session)
 	}
-	return sessions, nil
+	return sessions, nextPageToken, nil
 }
 
 func filterNilValues(originalMap map[string]any) map[string]any {
@@ -231,8 +250,8 @@ func (c *vertexAiClient) deleteSession(ctx context.Context, req *session.DeleteR
 }
 
 func (c *vertexAiClient) appendEvent(ctx context.Context, appName, sessionID string, event *session.Event) error {
-	// ignore partial events
-	if event.Partial {
+	// ignore partial events, unless the caller opts in
+	if event.Partial && !session.SavesPartialEvents(ctx) {
 		return nil
 	}
 
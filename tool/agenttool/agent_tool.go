@@ -27,6 +27,7 @@ import (
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/artifact"
 	"google.golang.org/adk/internal/llminternal"
+	"google.golang.org/adk/internal/toolinternal"
 	"google.golang.org/adk/internal/utils"
 	"google.golang.org/adk/memory"
 	"google.golang.org/adk/model"
@@ -39,6 +40,7 @@ import (
 type agentTool struct {
 	agent             agent.Agent
 	skipSummarization bool
+	suppressEvents    bool
 }
 
 // Config holds the configuration for an agent tool.
@@ -46,10 +48,21 @@ type Config struct {
 	// SkipSummarization, if true, will cause the agent to skip summarization
 	// after the sub-agent finishes execution.
 	SkipSummarization bool
+	// SuppressEvents, if true, stops forwarding the events of the sub-agent
+	// to the invocation calling the tool, e.g. for noisy sub-agents.
+	SuppressEvents bool
 }
 
 // New creates a new agent tool.
 // If cfg is nil, skipSummarization defaults to false.
+//
+// While the sub-agent runs, its events are forwarded to the event stream of
+// the invocation calling the tool, unless SuppressEvents is set. The
+// forwarded events keep the author of the sub-agent, and their branch is
+// that of the sub-agent under the calling agent. They have the
+// [ForwardedMetadataKey] custom metadata, and are streamed, but not saved in
+// the session of the invocation. The function response of the call is still
+// the final reply of the sub-agent.
 func New(agent agent.Agent, cfg *Config) tool.Tool {
 	if cfg == nil {
 		return &agentTool{
@@ -60,6 +73,7 @@ func New(agent agent.Agent, cfg *Config) tool.Tool {
 	return &agentTool{
 		agent:             agent,
 		skipSummarization: cfg.SkipSummarization,
+		suppressEvents:    cfg.SuppressEvents,
 	}
 }
 
@@ -201,11 +215,32 @@ func (t *agentTool) Run(toolCtx tool.Context, args any) (map[string]any, error)
 		StreamingMode: agent.StreamingModeSSE,
 	})
 
+	forward := !t.suppressEvents && toolinternal.ForwardsEvents(toolCtx)
+	var branch string
+	if forward {
+		branch = toolCtx.AgentName()
+		if toolCtx.Branch() != "" {
+			branch = toolCtx.Branch() + "." + branch
+		}
+	}
 	var lastEvent *session.Event
 	for event, err := range eventCh {
 		if err != nil {
 			return nil, fmt.Errorf("error during execution of sub-agent %s: %w", t.agent.Name(), err)
 		}
+		if forward {
+			fwd := *event
+			// The events of the sub-agents of the sub-agent, e.g. those
+			// forwarded by its own agent tools, keep their branch under it.
+			if fwd.Branch == "" {
+				fwd.Branch = t.agent.Name()
+			}
+			fwd.Branch = branch + "." + fwd.Branch
+			toolinternal.ForwardEvent(toolCtx, &fwd)
+		}
+		if toolinternal.IsForwarded(event) {
+			continue
+		}
 		if event.ErrorCode != "" || event.ErrorMessage != "" {
 			return nil, fmt.Errorf("error from sub-agent %q (code: %q, message: %q)", t.agent.Name(), event.ErrorCode, event.ErrorMessage)
 		}
@@ -214,6 +249,11 @@ func (t *agentTool) Run(toolCtx tool.Context, args any) (map[string]any, error)
 		}
 	}
 
+	// The cancellation of the invocation cancels the run of the sub-agent.
+	if err := toolCtx.Err(); err != nil {
+		return nil, fmt.Errorf("execution of sub-agent %s was cancelled: %w", t.agent.Name(), err)
+	}
+
 	if lastEvent == nil {
 		return map[string]any{}, nil
 	}
@@ -249,6 +289,11 @@ func (t *agentTool) Run(toolCtx tool.Context, args any) (map[string]any, error)
 	return map[string]any{"result": outputText}, nil
 }
 
+// ForwardedMetadataKey is the custom metadata key of the events of the
+// sub-agents forwarded by the agent tools, whose value is the ID of the
+// function call of the tool.
+const ForwardedMetadataKey = toolinternal.ForwardedEventMetadataKey
+
 // ProcessRequest adds the agent tool's function declaration to the LLM request.
 func (t *agentTool) ProcessRequest(ctx tool.Context, req *model.LLMRequest) error {
 	// TODO extract this function somewhere else, simillar operations are done for
@@ -15,23 +15,32 @@
 package agenttool_test
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
 	"log"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"google.golang.org/genai"
 
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/agent/workflowagents/loopagent"
 	icontext "google.golang.org/adk/internal/context"
 	"google.golang.org/adk/internal/sessioninternal"
 	"google.golang.org/adk/internal/testutil"
 	"google.golang.org/adk/internal/toolinternal"
 	"google.golang.org/adk/model"
 	"google.golang.org/adk/model/gemini"
+	"google.golang.org/adk/runner"
 	"google.golang.org/adk/session"
 	"google.golang.org/adk/tool"
 	"google.golang.org/adk/tool/agenttool"
+	"google.golang.org/adk/tool/exitlooptool"
 )
 
 func TestAgentTool_Declaration(t *testing.T) {
@@ -309,6 +318,192 @@ func TestAgentTool_Run_SkipSummarization(t *testing.T) {
 	}
 }
 
+func TestAgentTool_ForwardsEvents(t *testing.T) {
+	for _, suppress := range []bool{false, true} {
+		t.Run(fmt.Sprintf("suppress=%v", suppress), func(t *testing.T) {
+			subAgent, err := llmagent.New(llmagent.Config{
+				Name:        "sub_agent",
+				Description: "answers questions",
+				Model: &testutil.MockModel{Responses: []*genai.Content{
+					genai.NewContentFromText("sub answer", genai.RoleModel),
+				}},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			parent, err := llmagent.New(llmagent.Config{
+				Name: "parent_agent",
+				Model: &testutil.MockModel{Responses: []*genai.Content{
+					genai.NewContentFromFunctionCall("sub_agent", map[string]any{"request": "question"}, genai.RoleModel),
+					genai.NewContentFromText("parent answer", genai.RoleModel),
+				}},
+				Tools: []tool.Tool{agenttool.New(subAgent, &agenttool.Config{SuppressEvents: suppress})},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			sessionService := session.InMemoryService()
+			r, err := runner.New(runner.Config{AppName: "app", Agent: parent, SessionService: sessionService})
+			if err != nil {
+				t.Fatal(err)
+			}
+			created, err := sessionService.Create(t.Context(), &session.CreateRequest{AppName: "app", UserID: "user"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			events, err := testutil.CollectEvents(r.Run(t.Context(), "user", created.Session.ID(), genai.NewContentFromText("Hi", genai.RoleUser), agent.RunConfig{}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			type summary struct {
+				Author, Branch, Text string
+				Partial, Forwarded   bool
+				Response             map[string]any
+			}
+			var got []summary
+			for _, ev := range events {
+				_, forwarded := ev.CustomMetadata[agenttool.ForwardedMetadataKey]
+				s := summary{Author: ev.Author, Branch: ev.Branch, Partial: ev.Partial, Forwarded: forwarded}
+				for _, part := range ev.Content.Parts {
+					s.Text += part.Text
+					if part.FunctionResponse != nil {
+						s.Response = part.FunctionResponse.Response
+					}
+				}
+				if ev.InvocationID != events[0].InvocationID {
+					t.Errorf("forwarded event of invocation %q, want %q", ev.InvocationID, events[0].InvocationID)
+				}
+				got = append(got, s)
+			}
+			want := []summary{
+				{Author: "parent_agent"},
+				// The sub-agent streams its reply.
+				{Author: "sub_agent", Branch: "parent_agent.sub_agent", Text: "sub answer", Partial: true, Forwarded: true},
+				{Author: "sub_agent", Branch: "parent_agent.sub_agent", Text: "sub answer", Forwarded: true},
+				{Author: "parent_agent", Response: map[string]any{"result": "sub answer"}},
+				{Author: "parent_agent", Text: "parent answer"},
+			}
+			if suppress {
+				want = slices.Delete(want, 1, 3)
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("events mismatch (-want +got):\n%s", diff)
+			}
+			// The forwarded events aren't saved in the session.
+			stored, err := sessionService.Get(t.Context(), &session.GetRequest{AppName: "app", UserID: "user", SessionID: created.Session.ID()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for ev := range stored.Session.Events().All() {
+				if ev.Author == "sub_agent" {
+					t.Errorf("session has the forwarded event %+v", ev)
+				}
+			}
+		})
+	}
+}
+
+func TestAgentTool_ForwardedEventsDontEscalate(t *testing.T) {
+	exitLoop, err := exitlooptool.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	subAgent, err := llmagent.New(llmagent.Config{
+		Name:        "sub_agent",
+		Description: "decides whether to stop",
+		Model: &testutil.MockModel{Responses: []*genai.Content{
+			genai.NewContentFromFunctionCall("exit_loop", map[string]any{}, genai.RoleModel),
+			genai.NewContentFromFunctionCall("exit_loop", map[string]any{}, genai.RoleModel),
+		}},
+		Tools: []tool.Tool{exitLoop},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent, err := llmagent.New(llmagent.Config{
+		Name: "parent_agent",
+		Model: &testutil.MockModel{Responses: []*genai.Content{
+			genai.NewContentFromFunctionCall("sub_agent", map[string]any{"request": "stop?"}, genai.RoleModel),
+			genai.NewContentFromText("answer 1", genai.RoleModel),
+			genai.NewContentFromFunctionCall("sub_agent", map[string]any{"request": "stop?"}, genai.RoleModel),
+			genai.NewContentFromText("answer 2", genai.RoleModel),
+		}},
+		Tools: []tool.Tool{agenttool.New(subAgent, nil)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	loop, err := loopagent.New(loopagent.Config{
+		AgentConfig:   agent.Config{Name: "loop", SubAgents: []agent.Agent{parent}},
+		MaxIterations: 2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sessionService := session.InMemoryService()
+	r, err := runner.New(runner.Config{AppName: "app", Agent: loop, SessionService: sessionService})
+	if err != nil {
+		t.Fatal(err)
+	}
+	created, err := sessionService.Create(t.Context(), &session.CreateRequest{AppName: "app", UserID: "user"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The escalation of the sub-agent ends its own invocation, not the loop
+	// of the caller.
+	var answers []string
+	var exit any
+	for ev, err := range r.Run(t.Context(), "user", created.Session.ID(), genai.NewContentFromText("Hi", genai.RoleUser), agent.RunConfig{}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value, ok := ev.CustomMetadata[loopagent.ExitMetadataKey]; ok {
+			exit = value.(map[string]any)["cause"]
+		}
+		if _, forwarded := ev.CustomMetadata[agenttool.ForwardedMetadataKey]; forwarded {
+			if ev.Actions.Escalate {
+				t.Errorf("forwarded event %+v escalates", ev)
+			}
+			continue
+		}
+		if ev.Author == "parent_agent" && ev.Content != nil && ev.Content.Parts[0].Text != "" {
+			answers = append(answers, ev.Content.Parts[0].Text)
+		}
+	}
+	if diff := cmp.Diff([]string{"answer 1", "answer 2"}, answers); diff != "" {
+		t.Errorf("answers mismatch (-want +got):\n%s", diff)
+	}
+	if exit != loopagent.ExitMaxIterations {
+		t.Errorf("loop exit cause = %v, want %q", exit, loopagent.ExitMaxIterations)
+	}
+}
+
+// hangingModel is a model whose calls last until they are cancelled.
+type hangingModel struct{}
+
+func (hangingModel) Name() string { return "hanging" }
+
+func (hangingModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		<-ctx.Done()
+		yield(nil, ctx.Err())
+	}
+}
+
+func TestAgentTool_Run_Cancelled(t *testing.T) {
+	subAgent := createAgentWithModel(t, nil, nil, hangingModel{})
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+	toolCtx := createToolContextWithContext(t, ctx)
+
+	toolImpl := agenttool.New(subAgent, nil).(toolinternal.FunctionTool)
+	if _, err := toolImpl.Run(toolCtx, map[string]any{"request": "question"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Run() error = %v, want the cancellation of the parent", err)
+	}
+}
+
 func createAgent(t *testing.T, inputSchema, outputSchema *genai.Schema) agent.Agent {
 	t.Helper()
 
@@ -350,6 +545,11 @@ func createAgentWithModel(t *testing.T, inputSchema, outputSchema *genai.Schema,
 
 func createToolContext(t *testing.T, testAgent agent.Agent) tool.Context {
 	t.Helper()
+	return createToolContextWithContext(t, t.Context())
+}
+
+func createToolContextWithContext(t *testing.T, parent context.Context) tool.Context {
+	t.Helper()
 
 	sessionService := session.InMemoryService()
 	createResponse, err := sessionService.Create(t.Context(), &session.CreateRequest{
@@ -363,7 +563,7 @@ func createToolContext(t *testing.T, testAgent agent.Agent) tool.Context {
 	s := createResponse.Session
 	sessionImpl := sessioninternal.NewMutableSession(sessionService, s)
 
-	ctx := icontext.NewInvocationContext(t.Context(), icontext.InvocationContextParams{
+	ctx := icontext.NewInvocationContext(parent, icontext.InvocationContextParams{
 		Session: sessionImpl,
 	})
 
@@ -0,0 +1,340 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package artifacttoolset provides a tool set listing, reading and writing
+// the artifacts of the session.
+package artifacttoolset
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+// DefaultMaxReadBytes is the default of [Config.MaxReadBytes].
+const DefaultMaxReadBytes = 64 << 10
+
+// previewBytes is the size of the preview of the binary artifacts.
+const previewBytes = 48
+
+// maxTrackedInvocations bounds the number of invocations whose writes are
+// counted for the quota; the oldest ones are forgotten first.
+const maxTrackedInvocations = 256
+
+// Config provides initial configuration for the artifact ToolSet.
+type Config struct {
+	// Allow and Deny are path.Match patterns on the names of the artifacts,
+	// including their "user:" prefix. The tools only see and write the
+	// artifacts matching one of the Allow patterns, or any if Allow is
+	// empty, and none of the Deny patterns.
+	Allow []string
+	Deny  []string
+
+	// MaxReadBytes is the size of the largest content returned by
+	// read_artifact, the longer texts are truncated. Defaults to
+	// DefaultMaxReadBytes.
+	MaxReadBytes int
+
+	// MaxWritesPerInvocation is the number of artifacts write_artifact can
+	// save in one invocation. Zero means no limit.
+	MaxWritesPerInvocation int
+}
+
+// New returns an artifact ToolSet.
+// The ToolSet has three tools operating on the artifacts of the tool
+// context, so that the names with the "user:" prefix are scoped to the user
+// and the others to the session:
+//   - list_artifacts returns the names, latest versions and sizes of the
+//     artifacts;
+//   - read_artifact returns the content of a text artifact, truncated to
+//     Config.MaxReadBytes, or the metadata and a preview of a binary one;
+//   - write_artifact creates an artifact, or a new version of it, and returns
+//     the version.
+//
+// Example:
+//
+//	artifacts, err := artifacttoolset.New(artifacttoolset.Config{
+//		Deny:                   []string{"user:*"},
+//		MaxWritesPerInvocation: 5,
+//	})
+//	...
+//	llmagent.New(llmagent.Config{
+//		Name:     "agent_name",
+//		Model:    model,
+//		Toolsets: []tool.Toolset{artifacts},
+//	})
+func New(cfg Config) (tool.Toolset, error) {
+	for _, pattern := range append(append([]string{}, cfg.Allow...), cfg.Deny...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid artifact name pattern %q: %w", pattern, err)
+		}
+	}
+	if cfg.MaxReadBytes <= 0 {
+		cfg.MaxReadBytes = DefaultMaxReadBytes
+	}
+	s := &set{cfg: cfg, writes: map[string]int{}}
+
+	list, err := functiontool.New(functiontool.Config{
+		Name:        "list_artifacts",
+		Description: "Lists the artifacts available, with their latest version and size in bytes.",
+	}, s.list)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create list_artifacts tool: %w", err)
+	}
+	read, err := functiontool.New(functiontool.Config{
+		Name:        "read_artifact",
+		Description: "Reads an artifact. Returns the content of text artifacts, truncated if they are too large, and the metadata and a base64 preview of binary ones.",
+	}, s.read)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create read_artifact tool: %w", err)
+	}
+	write, err := functiontool.New(functiontool.Config{
+		Name:        "write_artifact",
+		Description: "Writes a text artifact, creating it or adding a new version of it. Returns the version written. Prefix the name with \"user:\" to share the artifact with the other sessions of the user.",
+	}, s.write)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create write_artifact tool: %w", err)
+	}
+	s.tools = []tool.Tool{list, read, write}
+	return s, nil
+}
+
+type set struct {
+	cfg   Config
+	tools []tool.Tool
+
+	mu     sync.Mutex
+	writes map[string]int // by invocation ID
+	order  []string       // invocation IDs of writes, oldest first
+}
+
+func (*set) Name() string {
+	return "artifact_tool_set"
+}
+
+// Tools returns the list_artifacts, read_artifact and write_artifact tools.
+func (s *set) Tools(ctx agent.ReadonlyContext) ([]tool.Tool, error) {
+	return s.tools, nil
+}
+
+type listArgs struct{}
+
+type listResult struct {
+	Artifacts []artifactInfo `json:"artifacts"`
+}
+
+type artifactInfo struct {
+	Name     string `json:"name"`
+	Version  int64  `json:"version,omitempty"`
+	Size     int    `json:"size"`
+	MIMEType string `json:"mime_type,omitempty"`
+}
+
+func (s *set) list(ctx tool.Context, _ listArgs) (listResult, error) {
+	resp, err := ctx.Artifacts().List(ctx)
+	if err != nil {
+		return listResult{}, fmt.Errorf("failed to list artifacts: %w", err)
+	}
+	versioner, _ := ctx.Artifacts().(agent.ArtifactVersioner)
+	result := listResult{Artifacts: []artifactInfo{}}
+	for _, name := range resp.FileNames {
+		if !s.allowed(name) {
+			continue
+		}
+		load, err := ctx.Artifacts().Load(ctx, name)
+		if err != nil {
+			return listResult{}, fmt.Errorf("failed to load artifact %q: %w", name, err)
+		}
+		data, mimeType := partData(load.Part)
+		info := artifactInfo{Name: name, Size: len(data), MIMEType: mimeType}
+		if versioner != nil {
+			versions, err := versioner.Versions(ctx, name)
+			switch {
+			case errors.Is(err, errors.ErrUnsupported):
+			case err != nil:
+				return listResult{}, fmt.Errorf("failed to list versions of artifact %q: %w", name, err)
+			default:
+				for _, v := range versions.Versions {
+					info.Version = max(info.Version, v)
+				}
+			}
+		}
+		result.Artifacts = append(result.Artifacts, info)
+	}
+	return result, nil
+}
+
+type readArgs struct {
+	Name    string `json:"name" jsonschema:"description=name of the artifact"`
+	Version int    `json:"version,omitempty" jsonschema:"description=version to read; the latest if omitted"`
+}
+
+type readResult struct {
+	Name      string            `json:"name"`
+	MIMEType  string            `json:"mime_type,omitempty"`
+	Size      int               `json:"size"`
+	Content   string            `json:"content,omitempty"`
+	Truncated bool              `json:"truncated,omitempty"`
+	Preview   string            `json:"preview_base64,omitempty"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+}
+
+func (s *set) read(ctx tool.Context, args readArgs) (readResult, error) {
+	if !s.allowed(args.Name) {
+		return readResult{}, fmt.Errorf("artifact %q is not accessible", args.Name)
+	}
+	load, err := ctx.Artifacts().LoadVersion(ctx, args.Name, args.Version)
+	if err != nil {
+		return readResult{}, fmt.Errorf("failed to load artifact %q: %w", args.Name, err)
+	}
+	data, mimeType := partData(load.Part)
+	result := readResult{Name: args.Name, MIMEType: mimeType, Size: len(data), Metadata: load.Metadata}
+	if !isText(load.Part, data) {
+		result.Preview = base64.StdEncoding.EncodeToString(data[:min(len(data), previewBytes)])
+		return result, nil
+	}
+	if len(data) > s.cfg.MaxReadBytes {
+		data = truncateUTF8(data, s.cfg.MaxReadBytes)
+		result.Truncated = true
+	}
+	result.Content = string(data)
+	return result, nil
+}
+
+type writeArgs struct {
+	Name     string `json:"name" jsonschema:"description=name of the artifact"`
+	Content  string `json:"content" jsonschema:"description=text content of the artifact"`
+	MIMEType string `json:"mime_type,omitempty" jsonschema:"description=MIME type of the content; text/plain if omitted"`
+}
+
+type writeResult struct {
+	Name    string `json:"name"`
+	Version int64  `json:"version"`
+}
+
+func (s *set) write(ctx tool.Context, args writeArgs) (writeResult, error) {
+	if args.Name == "" {
+		return writeResult{}, fmt.Errorf("artifact name is required")
+	}
+	if !s.allowed(args.Name) {
+		return writeResult{}, fmt.Errorf("artifact %q is not accessible", args.Name)
+	}
+	if err := s.countWrite(ctx.InvocationID()); err != nil {
+		return writeResult{}, err
+	}
+	mimeType := args.MIMEType
+	if mimeType == "" {
+		mimeType = "text/plain"
+	}
+	resp, err := ctx.Artifacts().Save(ctx, args.Name, genai.NewPartFromBytes([]byte(args.Content), mimeType))
+	if err != nil {
+		return writeResult{}, fmt.Errorf("failed to save artifact %q: %w", args.Name, err)
+	}
+	return writeResult{Name: args.Name, Version: resp.Version}, nil
+}
+
+// allowed reports whether the tools can access the artifact name.
+func (s *set) allowed(name string) bool {
+	match := func(pattern string) bool {
+		ok, _ := path.Match(pattern, name)
+		return ok
+	}
+	for _, pattern := range s.cfg.Deny {
+		if match(pattern) {
+			return false
+		}
+	}
+	if len(s.cfg.Allow) == 0 {
+		return true
+	}
+	for _, pattern := range s.cfg.Allow {
+		if match(pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// countWrite counts a write of the invocation, failing if the invocation has
+// already written MaxWritesPerInvocation artifacts.
+func (s *set) countWrite(invocationID string) error {
+	if s.cfg.MaxWritesPerInvocation <= 0 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n, ok := s.writes[invocationID]
+	if n >= s.cfg.MaxWritesPerInvocation {
+		return fmt.Errorf("quota of %d artifact writes per invocation exceeded", s.cfg.MaxWritesPerInvocation)
+	}
+	if !ok {
+		s.order = append(s.order, invocationID)
+		if len(s.order) > maxTrackedInvocations {
+			delete(s.writes, s.order[0])
+			s.order = s.order[1:]
+		}
+	}
+	s.writes[invocationID] = n + 1
+	return nil
+}
+
+// partData returns the content of the part and its MIME type.
+func partData(part *genai.Part) ([]byte, string) {
+	switch {
+	case part == nil:
+		return nil, ""
+	case part.InlineData != nil:
+		return part.InlineData.Data, part.InlineData.MIMEType
+	case part.Text != "":
+		return []byte(part.Text), "text/plain"
+	}
+	return nil, ""
+}
+
+// isText reports whether the content of the part can be returned as text.
+func isText(part *genai.Part, data []byte) bool {
+	if part == nil || part.InlineData == nil {
+		return true
+	}
+	mimeType, _, _ := strings.Cut(part.InlineData.MIMEType, ";")
+	switch mimeType = strings.TrimSpace(mimeType); {
+	case mimeType == "",
+		strings.HasPrefix(mimeType, "text/"),
+		mimeType == "application/json",
+		mimeType == "application/xml",
+		mimeType == "application/yaml",
+		strings.HasSuffix(mimeType, "+json"),
+		strings.HasSuffix(mimeType, "+xml"):
+		return utf8.Valid(data)
+	}
+	return false
+}
+
+// truncateUTF8 truncates data to at most n bytes without splitting a rune.
+func truncateUTF8(data []byte, n int) []byte {
+	for n > 0 && !utf8.RuneStart(data[n]) {
+		n--
+	}
+	return data[:n]
+}
@@ -0,0 +1,168 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package artifacttoolset_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/artifact"
+	artifactinternal "google.golang.org/adk/internal/artifact"
+	icontext "google.golang.org/adk/internal/context"
+	"google.golang.org/adk/internal/toolinternal"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/artifacttoolset"
+)
+
+func TestArtifactToolset(t *testing.T) {
+	tests := []struct {
+		name  string
+		cfg   artifacttoolset.Config
+		setup map[string]*genai.Part
+		calls []call
+	}{
+		{
+			name: "write, list and read",
+			calls: []call{
+				{tool: "write_artifact", args: map[string]any{"name": "notes.txt", "content": "hello"}, want: map[string]any{"name": "notes.txt", "version": 1.0}},
+				{tool: "write_artifact", args: map[string]any{"name": "notes.txt", "content": "hello, world"}, want: map[string]any{"name": "notes.txt", "version": 2.0}},
+				{tool: "write_artifact", args: map[string]any{"name": "user:profile.json", "content": `{}`, "mime_type": "application/json"}, want: map[string]any{"name": "user:profile.json", "version": 1.0}},
+				{tool: "list_artifacts", args: map[string]any{}, want: map[string]any{"artifacts": []any{
+					map[string]any{"name": "notes.txt", "version": 2.0, "size": 12.0, "mime_type": "text/plain"},
+					map[string]any{"name": "user:profile.json", "version": 1.0, "size": 2.0, "mime_type": "application/json"},
+				}}},
+				{tool: "read_artifact", args: map[string]any{"name": "notes.txt"}, want: map[string]any{"name": "notes.txt", "mime_type": "text/plain", "size": 12.0, "content": "hello, world"}},
+				{tool: "read_artifact", args: map[string]any{"name": "notes.txt", "version": 1}, want: map[string]any{"name": "notes.txt", "mime_type": "text/plain", "size": 5.0, "content": "hello"}},
+				{tool: "read_artifact", args: map[string]any{"name": "missing.txt"}, wantErr: "failed to load artifact"},
+			},
+		},
+		{
+			name: "oversized and binary",
+			cfg:  artifacttoolset.Config{MaxReadBytes: 4},
+			setup: map[string]*genai.Part{
+				"text.md":   genai.NewPartFromText("abcé"),
+				"image.png": genai.NewPartFromBytes([]byte{0x89, 'P', 'N', 'G'}, "image/png"),
+			},
+			calls: []call{
+				{tool: "read_artifact", args: map[string]any{"name": "text.md"}, want: map[string]any{"name": "text.md", "mime_type": "text/plain", "size": 5.0, "content": "abc", "truncated": true}},
+				{tool: "read_artifact", args: map[string]any{"name": "image.png"}, want: map[string]any{"name": "image.png", "mime_type": "image/png", "size": 4.0, "preview_base64": "iVBORw=="}},
+			},
+		},
+		{
+			name: "allow and deny",
+			cfg:  artifacttoolset.Config{Allow: []string{"*.txt", "user:*"}, Deny: []string{"user:secret*"}},
+			setup: map[string]*genai.Part{
+				"a.txt":           genai.NewPartFromText("a"),
+				"b.csv":           genai.NewPartFromText("b"),
+				"user:secret.txt": genai.NewPartFromText("s"),
+				"user:public.txt": genai.NewPartFromText("p"),
+			},
+			calls: []call{
+				{tool: "list_artifacts", args: map[string]any{}, want: map[string]any{"artifacts": []any{
+					map[string]any{"name": "a.txt", "version": 1.0, "size": 1.0, "mime_type": "text/plain"},
+					map[string]any{"name": "user:public.txt", "version": 1.0, "size": 1.0, "mime_type": "text/plain"},
+				}}},
+				{tool: "read_artifact", args: map[string]any{"name": "b.csv"}, wantErr: "not accessible"},
+				{tool: "read_artifact", args: map[string]any{"name": "user:secret.txt"}, wantErr: "not accessible"},
+				{tool: "write_artifact", args: map[string]any{"name": "user:secret.txt", "content": "x"}, wantErr: "not accessible"},
+			},
+		},
+		{
+			name: "write quota",
+			cfg:  artifacttoolset.Config{MaxWritesPerInvocation: 2},
+			calls: []call{
+				{tool: "write_artifact", args: map[string]any{"name": "a.txt", "content": "a"}, want: map[string]any{"name": "a.txt", "version": 1.0}},
+				{tool: "write_artifact", args: map[string]any{"name": "b.txt", "content": "b"}, want: map[string]any{"name": "b.txt", "version": 1.0}},
+				{tool: "write_artifact", args: map[string]any{"name": "c.txt", "content": "c"}, wantErr: "quota of 2 artifact writes"},
+				{tool: "write_artifact", args: map[string]any{"name": "c.txt", "content": "c"}, invocation: "other", want: map[string]any{"name": "c.txt", "version": 1.0}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set, err := artifacttoolset.New(tt.cfg)
+			if err != nil {
+				t.Fatalf("New() failed: %v", err)
+			}
+			tools, err := set.Tools(nil)
+			if err != nil {
+				t.Fatalf("Tools() failed: %v", err)
+			}
+			byName := map[string]toolinternal.FunctionTool{}
+			for _, tl := range tools {
+				byName[tl.Name()] = tl.(toolinternal.FunctionTool)
+			}
+
+			service := artifact.InMemoryService()
+			for name, part := range tt.setup {
+				if _, err := service.Save(t.Context(), &artifact.SaveRequest{AppName: "app", UserID: "user", SessionID: "session", FileName: name, Part: part}); err != nil {
+					t.Fatalf("Save(%q) failed: %v", name, err)
+				}
+			}
+
+			for i, c := range tt.calls {
+				invocation := c.invocation
+				if invocation == "" {
+					invocation = "invocation"
+				}
+				got, err := byName[c.tool].Run(createToolContext(t, service, invocation), c.args)
+				if c.wantErr != "" {
+					if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+						t.Errorf("call %d: %s() error = %v, want containing %q", i, c.tool, err, c.wantErr)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("call %d: %s() failed: %v", i, c.tool, err)
+				}
+				if diff := cmp.Diff(c.want, got); diff != "" {
+					t.Errorf("call %d: %s() mismatch (-want +got):\n%s", i, c.tool, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestNew_InvalidPattern(t *testing.T) {
+	if _, err := artifacttoolset.New(artifacttoolset.Config{Deny: []string{"["}}); err == nil {
+		t.Error("New() succeeded, want an error for the invalid pattern")
+	}
+}
+
+type call struct {
+	tool       string
+	args       map[string]any
+	invocation string
+	want       map[string]any
+	wantErr    string
+}
+
+func createToolContext(t *testing.T, service artifact.Service, invocationID string) tool.Context {
+	t.Helper()
+	ctx := icontext.NewInvocationContext(t.Context(), icontext.InvocationContextParams{
+		Artifacts: &artifactinternal.Artifacts{
+			Service:   service,
+			AppName:   "app",
+			UserID:    "user",
+			SessionID: "session",
+		},
+		InvocationID: invocationID,
+	})
+	return toolinternal.NewToolContext(ctx, "", nil, nil)
+}
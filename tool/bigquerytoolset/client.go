@@ -0,0 +1,298 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerytoolset
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	bigquery "google.golang.org/api/bigquery/v2"
+	"google.golang.org/api/option"
+)
+
+// Client is the access to BigQuery of the tools. The tests inject a fake
+// client, the others use the client of [NewClient].
+type Client interface {
+	// ListDatasets returns the IDs of the datasets of the project.
+	ListDatasets(ctx context.Context, projectID string) ([]string, error)
+	// ListTables returns the IDs of the tables of the dataset.
+	ListTables(ctx context.Context, projectID, datasetID string) ([]string, error)
+	// GetTable returns the metadata and the schema of the table.
+	GetTable(ctx context.Context, projectID, datasetID, tableID string) (*Table, error)
+	// DryRun validates the query without running it.
+	DryRun(ctx context.Context, q Query) (*DryRunResult, error)
+	// Query runs the query and returns its first MaxRows rows.
+	Query(ctx context.Context, q Query) (*QueryResult, error)
+}
+
+// Query is a GoogleSQL query run by a [Client].
+type Query struct {
+	ProjectID string
+	SQL       string
+	// Location is the location of the job, if set.
+	Location string
+	// MaxBytesBilled fails the query if it would bill more bytes, if not
+	// zero.
+	MaxBytesBilled int64
+	// MaxRows is the number of rows returned by Query.
+	MaxRows int
+	// Timeout bounds the duration of the query.
+	Timeout time.Duration
+}
+
+// DryRunResult is the result of a dry run.
+type DryRunResult struct {
+	// StatementType is the type of the statement of the query, such as
+	// "SELECT", "INSERT" or "CREATE_TABLE", "SCRIPT" for the scripts.
+	StatementType       string
+	TotalBytesProcessed int64
+}
+
+// QueryResult is the result of a query.
+type QueryResult struct {
+	Schema []Field
+	// Rows are the rows returned, by column name, with the INT64, FLOAT64,
+	// BOOL and TIMESTAMP values converted to JSON numbers, booleans and
+	// RFC 3339 strings.
+	Rows                []map[string]any
+	TotalRows           uint64
+	TotalBytesProcessed int64
+	AffectedRows        int64
+}
+
+// Table is the metadata of a table.
+type Table struct {
+	ID          string  `json:"id"`
+	Type        string  `json:"type,omitempty"`
+	Description string  `json:"description,omitempty"`
+	NumRows     uint64  `json:"num_rows"`
+	Schema      []Field `json:"schema"`
+}
+
+// Field is a column of a table, or a field of a RECORD column.
+type Field struct {
+	Name        string  `json:"name"`
+	Type        string  `json:"type"`
+	Mode        string  `json:"mode,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Fields      []Field `json:"fields,omitempty"`
+}
+
+// NewClient returns a Client calling the BigQuery API with the opts, with
+// the application default credentials if they don't set any.
+func NewClient(ctx context.Context, opts ...option.ClientOption) (Client, error) {
+	service, err := bigquery.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create BigQuery client: %w", err)
+	}
+	return &client{service: service}, nil
+}
+
+type client struct {
+	service *bigquery.Service
+}
+
+func (c *client) ListDatasets(ctx context.Context, projectID string) ([]string, error) {
+	var ids []string
+	err := c.service.Datasets.List(projectID).Pages(ctx, func(list *bigquery.DatasetList) error {
+		for _, d := range list.Datasets {
+			ids = append(ids, d.DatasetReference.DatasetId)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list datasets of project %q: %w", projectID, err)
+	}
+	return ids, nil
+}
+
+func (c *client) ListTables(ctx context.Context, projectID, datasetID string) ([]string, error) {
+	var ids []string
+	err := c.service.Tables.List(projectID, datasetID).Pages(ctx, func(list *bigquery.TableList) error {
+		for _, t := range list.Tables {
+			ids = append(ids, t.TableReference.TableId)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables of dataset %s.%s: %w", projectID, datasetID, err)
+	}
+	return ids, nil
+}
+
+func (c *client) GetTable(ctx context.Context, projectID, datasetID, tableID string) (*Table, error) {
+	t, err := c.service.Tables.Get(projectID, datasetID, tableID).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get table %s.%s.%s: %w", projectID, datasetID, tableID, err)
+	}
+	table := &Table{ID: tableID, Type: t.Type, Description: t.Description, NumRows: t.NumRows}
+	if t.Schema != nil {
+		table.Schema = fields(t.Schema.Fields)
+	}
+	return table, nil
+}
+
+func (c *client) DryRun(ctx context.Context, q Query) (*DryRunResult, error) {
+	job := &bigquery.Job{
+		JobReference: &bigquery.JobReference{ProjectId: q.ProjectID, Location: q.Location},
+		Configuration: &bigquery.JobConfiguration{
+			DryRun: true,
+			Query:  &bigquery.JobConfigurationQuery{Query: q.SQL, UseLegacySql: new(bool)},
+		},
+	}
+	job, err := c.service.Jobs.Insert(q.ProjectID, job).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("dry run of the query failed: %w", err)
+	}
+	result := &DryRunResult{}
+	if stats := job.Statistics; stats != nil && stats.Query != nil {
+		result.StatementType = stats.Query.StatementType
+		result.TotalBytesProcessed = stats.Query.TotalBytesProcessed
+	}
+	return result, nil
+}
+
+func (c *client) Query(ctx context.Context, q Query) (*QueryResult, error) {
+	resp, err := c.service.Jobs.Query(q.ProjectID, &bigquery.QueryRequest{
+		Query:              q.SQL,
+		UseLegacySql:       new(bool),
+		Location:           q.Location,
+		MaximumBytesBilled: q.MaxBytesBilled,
+		MaxResults:         int64(q.MaxRows),
+		TimeoutMs:          q.Timeout.Milliseconds(),
+	}).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	result := &QueryResult{
+		TotalRows:           resp.TotalRows,
+		TotalBytesProcessed: resp.TotalBytesProcessed,
+		AffectedRows:        resp.NumDmlAffectedRows,
+	}
+	schema, rows := resp.Schema, resp.Rows
+	// The query times out of jobs.query before the job does: wait for it.
+	for !resp.JobComplete {
+		if resp.JobReference == nil {
+			return nil, fmt.Errorf("query did not complete")
+		}
+		ref := resp.JobReference
+		results, err := c.service.Jobs.GetQueryResults(ref.ProjectId, ref.JobId).
+			Location(ref.Location).
+			MaxResults(int64(q.MaxRows)).
+			TimeoutMs(q.Timeout.Milliseconds()).
+			Context(ctx).Do()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the results of job %s: %w", ref.JobId, err)
+		}
+		if !results.JobComplete {
+			if err := ctx.Err(); err != nil {
+				return nil, fmt.Errorf("query did not complete: %w", err)
+			}
+			continue
+		}
+		schema, rows = results.Schema, results.Rows
+		result.TotalRows = results.TotalRows
+		result.TotalBytesProcessed = results.TotalBytesProcessed
+		result.AffectedRows = results.NumDmlAffectedRows
+		break
+	}
+	if schema != nil {
+		result.Schema = fields(schema.Fields)
+	}
+	for _, row := range rows {
+		if len(result.Rows) == q.MaxRows {
+			break
+		}
+		result.Rows = append(result.Rows, record(result.Schema, row.F))
+	}
+	return result, nil
+}
+
+func fields(schema []*bigquery.TableFieldSchema) []Field {
+	var fs []Field
+	for _, f := range schema {
+		fs = append(fs, Field{
+			Name:        f.Name,
+			Type:        f.Type,
+			Mode:        f.Mode,
+			Description: f.Description,
+			Fields:      fields(f.Fields),
+		})
+	}
+	return fs
+}
+
+// record converts the cells of a row, or of a RECORD value, to a map by
+// field name.
+func record(schema []Field, cells []*bigquery.TableCell) map[string]any {
+	m := make(map[string]any, len(cells))
+	for i, cell := range cells {
+		if i < len(schema) {
+			m[schema[i].Name] = value(schema[i], cell.V)
+		}
+	}
+	return m
+}
+
+// value converts a value of the REST API, where the scalars are strings, the
+// repeated values lists of {"v": value} and the records {"f": [cells]}.
+func value(f Field, v any) any {
+	if list, ok := v.([]any); ok && f.Mode == "REPEATED" {
+		values := make([]any, 0, len(list))
+		for _, item := range list {
+			cell, _ := item.(map[string]any)
+			values = append(values, value(Field{Name: f.Name, Type: f.Type, Fields: f.Fields}, cell["v"]))
+		}
+		return values
+	}
+	switch v := v.(type) {
+	case map[string]any:
+		list, _ := v["f"].([]any)
+		cells := make([]*bigquery.TableCell, 0, len(list))
+		for _, item := range list {
+			cell, _ := item.(map[string]any)
+			cells = append(cells, &bigquery.TableCell{V: cell["v"]})
+		}
+		return record(f.Fields, cells)
+	case string:
+		return scalar(f.Type, v)
+	}
+	return v
+}
+
+func scalar(typ, s string) any {
+	switch typ {
+	case "INTEGER", "INT64":
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return n
+		}
+	case "FLOAT", "FLOAT64":
+		if x, err := strconv.ParseFloat(s, 64); err == nil {
+			return x
+		}
+	case "BOOLEAN", "BOOL":
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b
+		}
+	case "TIMESTAMP":
+		if x, err := strconv.ParseFloat(s, 64); err == nil {
+			sec, frac := int64(x), x-float64(int64(x))
+			return time.Unix(sec, int64(frac*1e9)).UTC().Format(time.RFC3339Nano)
+		}
+	}
+	return s
+}
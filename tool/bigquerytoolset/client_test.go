@@ -0,0 +1,123 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerytoolset_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/api/option"
+
+	"google.golang.org/adk/tool/bigquerytoolset"
+)
+
+func TestClient(t *testing.T) {
+	schema := `{"fields": [
+		{"name": "name", "type": "STRING"},
+		{"name": "n", "type": "INTEGER"},
+		{"name": "score", "type": "FLOAT"},
+		{"name": "ok", "type": "BOOLEAN"},
+		{"name": "at", "type": "TIMESTAMP"},
+		{"name": "tags", "type": "STRING", "mode": "REPEATED"},
+		{"name": "owner", "type": "RECORD", "fields": [{"name": "id", "type": "INTEGER"}]}
+	]}`
+	var requests []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /bigquery/v2/projects/p/jobs", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, string(body))
+		io.WriteString(w, `{"statistics": {"query": {"statementType": "SELECT", "totalBytesProcessed": "42"}}}`)
+	})
+	mux.HandleFunc("POST /bigquery/v2/projects/p/queries", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, string(body))
+		io.WriteString(w, `{"jobComplete": false, "jobReference": {"projectId": "p", "jobId": "j", "location": "EU"}}`)
+	})
+	mux.HandleFunc("GET /bigquery/v2/projects/p/queries/j", func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Query().Get("location")+" "+r.URL.Query().Get("maxResults"))
+		io.WriteString(w, `{"jobComplete": true, "totalRows": "5", "totalBytesProcessed": "42", "schema": `+schema+`, "rows": [
+			{"f": [{"v": "a"}, {"v": "1"}, {"v": "1.5"}, {"v": "true"}, {"v": "1.7e9"}, {"v": [{"v": "x"}, {"v": "y"}]}, {"v": {"f": [{"v": "7"}]}}]},
+			{"f": [{"v": null}, {"v": "2"}, {"v": null}, {"v": "false"}, {"v": null}, {"v": []}, {"v": null}]}
+		]}`)
+	})
+	mux.HandleFunc("GET /bigquery/v2/projects/p/datasets/d/tables/t", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"type": "TABLE", "numRows": "5", "schema": `+schema+`}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := bigquerytoolset.NewClient(t.Context(), option.WithEndpoint(server.URL+"/bigquery/v2/"), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatalf("NewClient() failed: %v", err)
+	}
+	q := bigquerytoolset.Query{ProjectID: "p", SQL: "SELECT 1", MaxBytesBilled: 100, MaxRows: 10}
+
+	dryRun, err := client.DryRun(t.Context(), q)
+	if err != nil {
+		t.Fatalf("DryRun() failed: %v", err)
+	}
+	if diff := cmp.Diff(&bigquerytoolset.DryRunResult{StatementType: "SELECT", TotalBytesProcessed: 42}, dryRun); diff != "" {
+		t.Errorf("DryRun() mismatch (-want +got):\n%s", diff)
+	}
+
+	got, err := client.Query(t.Context(), q)
+	if err != nil {
+		t.Fatalf("Query() failed: %v", err)
+	}
+	wantRows := []map[string]any{
+		{"name": "a", "n": int64(1), "score": 1.5, "ok": true, "at": "2023-11-14T22:13:20Z", "tags": []any{"x", "y"}, "owner": map[string]any{"id": int64(7)}},
+		{"name": nil, "n": int64(2), "score": nil, "ok": false, "at": nil, "tags": []any{}, "owner": nil},
+	}
+	if diff := cmp.Diff(wantRows, got.Rows); diff != "" {
+		t.Errorf("Query() rows mismatch (-want +got):\n%s", diff)
+	}
+	if got.TotalRows != 5 || got.TotalBytesProcessed != 42 {
+		t.Errorf("Query() = %d rows, %d bytes, want 5 rows, 42 bytes", got.TotalRows, got.TotalBytesProcessed)
+	}
+
+	table, err := client.GetTable(t.Context(), "p", "d", "t")
+	if err != nil {
+		t.Fatalf("GetTable() failed: %v", err)
+	}
+	if diff := cmp.Diff(bigquerytoolset.Field{Name: "owner", Type: "RECORD", Fields: []bigquerytoolset.Field{{Name: "id", Type: "INTEGER"}}}, table.Schema[6]); diff != "" {
+		t.Errorf("GetTable() schema mismatch (-want +got):\n%s", diff)
+	}
+
+	var dryRunReq, queryReq map[string]any
+	if err := json.Unmarshal([]byte(requests[0]), &dryRunReq); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(requests[1]), &queryReq); err != nil {
+		t.Fatal(err)
+	}
+	wantDryRun := map[string]any{
+		"configuration": map[string]any{"dryRun": true, "query": map[string]any{"query": "SELECT 1", "useLegacySql": false}},
+		"jobReference":  map[string]any{"projectId": "p"},
+	}
+	if diff := cmp.Diff(wantDryRun, dryRunReq); diff != "" {
+		t.Errorf("dry run request mismatch (-want +got):\n%s", diff)
+	}
+	wantQuery := map[string]any{"query": "SELECT 1", "useLegacySql": false, "maximumBytesBilled": "100", "maxResults": 10.0}
+	if diff := cmp.Diff(wantQuery, queryReq); diff != "" {
+		t.Errorf("query request mismatch (-want +got):\n%s", diff)
+	}
+	if requests[2] != "EU 10" {
+		t.Errorf("getQueryResults request = %q, want location EU and 10 results", requests[2])
+	}
+}
@@ -0,0 +1,291 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bigquerytoolset provides a tool set inspecting the schemas of
+// BigQuery datasets and running guarded queries on them.
+package bigquerytoolset
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"google.golang.org/api/option"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+// Defaults of the Config fields.
+const (
+	DefaultMaxBytesBilled = 1 << 30
+	DefaultMaxRows        = 100
+	DefaultMaxResultBytes = 64 << 10
+	DefaultQueryTimeout   = time.Minute
+)
+
+// Config provides initial configuration for the BigQuery ToolSet.
+type Config struct {
+	// ProjectID is the project the queries run in, and the default project
+	// of the datasets and tables.
+	ProjectID string
+	// Location is the location of the query jobs.
+	// Optional: if empty, BigQuery infers it from the tables of the queries.
+	Location string
+	// Client is the access to BigQuery.
+	// Optional: if nil, New creates one with its ClientOptions, see
+	// [NewClient].
+	Client Client
+
+	// MaxBytesBilled is the number of bytes a query can bill: the queries
+	// whose dry run reports they process more are rejected before they run.
+	// Defaults to DefaultMaxBytesBilled, negative means no limit.
+	MaxBytesBilled int64
+	// MaxRows and MaxResultBytes bound the rows returned to the model, and
+	// the size of their JSON. Default to DefaultMaxRows and
+	// DefaultMaxResultBytes.
+	MaxRows        int
+	MaxResultBytes int
+	// QueryTimeout bounds the duration of the queries. Defaults to
+	// DefaultQueryTimeout.
+	QueryTimeout time.Duration
+
+	// AllowWrites allows execute_query to run the statements which aren't
+	// SELECT queries: DML, DDL and scripts.
+	AllowWrites bool
+}
+
+// New returns a BigQuery ToolSet with the tools
+//   - list_datasets, listing the datasets of a project;
+//   - list_tables, listing the tables of a dataset;
+//   - get_table_schema, returning the metadata and schema of a table;
+//   - execute_query, running a GoogleSQL query and returning its rows as
+//     JSON objects.
+//
+// Every query is validated by a dry run first, which rejects the queries
+// whose statements aren't SELECT unless Config.AllowWrites is set, and the
+// ones processing more than Config.MaxBytesBilled, which also bounds the
+// bytes billed by the query run.
+//
+// Example:
+//
+//	bq, err := bigquerytoolset.New(ctx, bigquerytoolset.Config{
+//		ProjectID: "my-project",
+//	})
+//	...
+//	llmagent.New(llmagent.Config{
+//		Name:     "analyst",
+//		Model:    model,
+//		Toolsets: []tool.Toolset{bq},
+//	})
+func New(ctx context.Context, cfg Config, opts ...option.ClientOption) (tool.Toolset, error) {
+	if cfg.ProjectID == "" {
+		return nil, fmt.Errorf("ProjectID is required")
+	}
+	if cfg.Client == nil {
+		var err error
+		if cfg.Client, err = NewClient(ctx, opts...); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.MaxBytesBilled == 0 {
+		cfg.MaxBytesBilled = DefaultMaxBytesBilled
+	}
+	if cfg.MaxRows <= 0 {
+		cfg.MaxRows = DefaultMaxRows
+	}
+	if cfg.MaxResultBytes <= 0 {
+		cfg.MaxResultBytes = DefaultMaxResultBytes
+	}
+	if cfg.QueryTimeout <= 0 {
+		cfg.QueryTimeout = DefaultQueryTimeout
+	}
+	s := &set{cfg: cfg}
+
+	listDatasets, err := functiontool.New(functiontool.Config{
+		Name:        "list_datasets",
+		Description: "Lists the IDs of the BigQuery datasets of a project.",
+	}, s.listDatasets)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create list_datasets tool: %w", err)
+	}
+	listTables, err := functiontool.New(functiontool.Config{
+		Name:        "list_tables",
+		Description: "Lists the IDs of the tables of a BigQuery dataset.",
+	}, s.listTables)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create list_tables tool: %w", err)
+	}
+	getTableSchema, err := functiontool.New(functiontool.Config{
+		Name:        "get_table_schema",
+		Description: "Returns the description, number of rows and schema of a BigQuery table.",
+		// The schema of the RECORD fields is recursive, which the inferred
+		// schemas can't be.
+		OutputSchema: &jsonschema.Schema{Type: "object"},
+	}, s.getTableSchema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create get_table_schema tool: %w", err)
+	}
+	executeQuery, err := functiontool.New(functiontool.Config{
+		Name:        "execute_query",
+		Description: "Runs a GoogleSQL query in BigQuery and returns its rows as JSON objects. Qualify the tables with their dataset, and select only the columns and rows needed: the results are truncated.",
+	}, s.executeQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create execute_query tool: %w", err)
+	}
+	s.tools = []tool.Tool{listDatasets, listTables, getTableSchema, executeQuery}
+	return s, nil
+}
+
+type set struct {
+	cfg   Config
+	tools []tool.Tool
+}
+
+func (*set) Name() string {
+	return "bigquery_tool_set"
+}
+
+// Tools returns the list_datasets, list_tables, get_table_schema and
+// execute_query tools.
+func (s *set) Tools(ctx agent.ReadonlyContext) ([]tool.Tool, error) {
+	return s.tools, nil
+}
+
+type listDatasetsArgs struct {
+	ProjectID string `json:"project_id,omitempty" jsonschema:"description=ID of the project; the project of the tool set if omitted"`
+}
+
+type listDatasetsResult struct {
+	Datasets []string `json:"datasets"`
+}
+
+func (s *set) listDatasets(ctx tool.Context, args listDatasetsArgs) (listDatasetsResult, error) {
+	ids, err := s.cfg.Client.ListDatasets(ctx, s.project(args.ProjectID))
+	if err != nil {
+		return listDatasetsResult{}, err
+	}
+	return listDatasetsResult{Datasets: nonNil(ids)}, nil
+}
+
+type listTablesArgs struct {
+	ProjectID string `json:"project_id,omitempty" jsonschema:"description=ID of the project; the project of the tool set if omitted"`
+	DatasetID string `json:"dataset_id" jsonschema:"description=ID of the dataset"`
+}
+
+type listTablesResult struct {
+	Tables []string `json:"tables"`
+}
+
+func (s *set) listTables(ctx tool.Context, args listTablesArgs) (listTablesResult, error) {
+	ids, err := s.cfg.Client.ListTables(ctx, s.project(args.ProjectID), args.DatasetID)
+	if err != nil {
+		return listTablesResult{}, err
+	}
+	return listTablesResult{Tables: nonNil(ids)}, nil
+}
+
+type getTableSchemaArgs struct {
+	ProjectID string `json:"project_id,omitempty" jsonschema:"description=ID of the project; the project of the tool set if omitted"`
+	DatasetID string `json:"dataset_id" jsonschema:"description=ID of the dataset"`
+	TableID   string `json:"table_id" jsonschema:"description=ID of the table"`
+}
+
+func (s *set) getTableSchema(ctx tool.Context, args getTableSchemaArgs) (*Table, error) {
+	return s.cfg.Client.GetTable(ctx, s.project(args.ProjectID), args.DatasetID, args.TableID)
+}
+
+type executeQueryArgs struct {
+	SQL string `json:"sql" jsonschema:"description=GoogleSQL query"`
+}
+
+type executeQueryResult struct {
+	Rows                []map[string]any `json:"rows"`
+	TotalRows           uint64           `json:"total_rows"`
+	TotalBytesProcessed int64            `json:"total_bytes_processed"`
+	AffectedRows        int64            `json:"affected_rows,omitempty"`
+	Truncated           bool             `json:"truncated,omitempty"`
+	Notice              string           `json:"notice,omitempty"`
+}
+
+func (s *set) executeQuery(ctx tool.Context, args executeQueryArgs) (executeQueryResult, error) {
+	q := Query{
+		ProjectID: s.cfg.ProjectID,
+		SQL:       args.SQL,
+		Location:  s.cfg.Location,
+		MaxRows:   s.cfg.MaxRows,
+		Timeout:   s.cfg.QueryTimeout,
+	}
+	if s.cfg.MaxBytesBilled > 0 {
+		q.MaxBytesBilled = s.cfg.MaxBytesBilled
+	}
+	queryCtx, cancel := context.WithTimeout(ctx, s.cfg.QueryTimeout)
+	defer cancel()
+
+	dryRun, err := s.cfg.Client.DryRun(queryCtx, q)
+	if err != nil {
+		return executeQueryResult{}, err
+	}
+	if !s.cfg.AllowWrites && dryRun.StatementType != "SELECT" {
+		return executeQueryResult{}, fmt.Errorf("only SELECT queries are allowed, the statement of the query is %s", dryRun.StatementType)
+	}
+	if q.MaxBytesBilled > 0 && dryRun.TotalBytesProcessed > q.MaxBytesBilled {
+		return executeQueryResult{}, fmt.Errorf("query would process %d bytes, more than the limit of %d bytes: select fewer columns or filter on the partitions", dryRun.TotalBytesProcessed, q.MaxBytesBilled)
+	}
+
+	res, err := s.cfg.Client.Query(queryCtx, q)
+	if err != nil {
+		return executeQueryResult{}, err
+	}
+	result := executeQueryResult{
+		Rows:                []map[string]any{},
+		TotalRows:           res.TotalRows,
+		TotalBytesProcessed: res.TotalBytesProcessed,
+		AffectedRows:        res.AffectedRows,
+	}
+	size := 0
+	for _, row := range res.Rows {
+		data, err := json.Marshal(row)
+		if err != nil {
+			return executeQueryResult{}, fmt.Errorf("failed to encode row: %w", err)
+		}
+		if len(result.Rows) == s.cfg.MaxRows || size+len(data) > s.cfg.MaxResultBytes {
+			break
+		}
+		size += len(data)
+		result.Rows = append(result.Rows, row)
+	}
+	if uint64(len(result.Rows)) < res.TotalRows {
+		result.Truncated = true
+		result.Notice = fmt.Sprintf("Only the first %d of the %d rows are returned: aggregate or filter the rows, or add a LIMIT clause, to see the others.", len(result.Rows), res.TotalRows)
+	}
+	return result, nil
+}
+
+func (s *set) project(projectID string) string {
+	if projectID == "" {
+		return s.cfg.ProjectID
+	}
+	return projectID
+}
+
+func nonNil(ids []string) []string {
+	if ids == nil {
+		return []string{}
+	}
+	return ids
+}
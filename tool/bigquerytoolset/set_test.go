@@ -0,0 +1,259 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquerytoolset_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	icontext "google.golang.org/adk/internal/context"
+	"google.golang.org/adk/internal/toolinternal"
+	"google.golang.org/adk/tool/bigquerytoolset"
+)
+
+func TestBigQueryToolset(t *testing.T) {
+	rows := []map[string]any{{"name": "a", "n": 1}, {"name": "b", "n": 2}, {"name": "c", "n": 3}}
+	tests := []struct {
+		name      string
+		cfg       bigquerytoolset.Config
+		tool      string
+		args      map[string]any
+		fake      fakeClient
+		want      map[string]any
+		wantErr   string
+		wantQuery bool
+	}{
+		{
+			name: "list datasets",
+			tool: "list_datasets",
+			args: map[string]any{},
+			want: map[string]any{"datasets": []any{"my-project.sales", "my-project.web"}},
+		},
+		{
+			name: "list tables of another project",
+			tool: "list_tables",
+			args: map[string]any{"project_id": "other", "dataset_id": "sales"},
+			want: map[string]any{"tables": []any{"other.sales.orders"}},
+		},
+		{
+			name: "table schema",
+			tool: "get_table_schema",
+			args: map[string]any{"dataset_id": "sales", "table_id": "orders"},
+			want: map[string]any{
+				"id":       "orders",
+				"num_rows": 3.0,
+				"schema": []any{
+					map[string]any{"name": "name", "type": "STRING"},
+					map[string]any{"name": "n", "type": "INTEGER", "mode": "NULLABLE"},
+				},
+			},
+		},
+		{
+			name:      "select",
+			tool:      "execute_query",
+			args:      map[string]any{"sql": "SELECT name, n FROM sales.orders"},
+			fake:      fakeClient{statementType: "SELECT", bytes: 100, rows: rows},
+			wantQuery: true,
+			want: map[string]any{
+				"rows": []any{
+					map[string]any{"name": "a", "n": 1.0},
+					map[string]any{"name": "b", "n": 2.0},
+					map[string]any{"name": "c", "n": 3.0},
+				},
+				"total_rows":            3.0,
+				"total_bytes_processed": 100.0,
+			},
+		},
+		{
+			name:      "row limit",
+			cfg:       bigquerytoolset.Config{MaxRows: 2},
+			tool:      "execute_query",
+			args:      map[string]any{"sql": "SELECT name, n FROM sales.orders"},
+			fake:      fakeClient{statementType: "SELECT", rows: rows},
+			wantQuery: true,
+			want: map[string]any{
+				"rows": []any{
+					map[string]any{"name": "a", "n": 1.0},
+					map[string]any{"name": "b", "n": 2.0},
+				},
+				"total_rows":            3.0,
+				"total_bytes_processed": 0.0,
+				"truncated":             true,
+				"notice":                "Only the first 2 of the 3 rows are returned: aggregate or filter the rows, or add a LIMIT clause, to see the others.",
+			},
+		},
+		{
+			name:      "size limit",
+			cfg:       bigquerytoolset.Config{MaxResultBytes: 30},
+			tool:      "execute_query",
+			args:      map[string]any{"sql": "SELECT name, n FROM sales.orders"},
+			fake:      fakeClient{statementType: "SELECT", rows: rows},
+			wantQuery: true,
+			want: map[string]any{
+				"rows": []any{
+					map[string]any{"name": "a", "n": 1.0},
+				},
+				"total_rows":            3.0,
+				"total_bytes_processed": 0.0,
+				"truncated":             true,
+				"notice":                "Only the first 1 of the 3 rows are returned: aggregate or filter the rows, or add a LIMIT clause, to see the others.",
+			},
+		},
+		{
+			name:    "write rejected",
+			tool:    "execute_query",
+			args:    map[string]any{"sql": "DELETE FROM sales.orders WHERE true"},
+			fake:    fakeClient{statementType: "DELETE"},
+			wantErr: "only SELECT queries are allowed",
+		},
+		{
+			name:    "script rejected",
+			tool:    "execute_query",
+			args:    map[string]any{"sql": "SELECT 1; DROP TABLE sales.orders"},
+			fake:    fakeClient{statementType: "SCRIPT"},
+			wantErr: "only SELECT queries are allowed",
+		},
+		{
+			name:      "write allowed",
+			cfg:       bigquerytoolset.Config{AllowWrites: true},
+			tool:      "execute_query",
+			args:      map[string]any{"sql": "DELETE FROM sales.orders WHERE true"},
+			fake:      fakeClient{statementType: "DELETE", affected: 3},
+			wantQuery: true,
+			want: map[string]any{
+				"rows":                  []any{},
+				"total_rows":            0.0,
+				"total_bytes_processed": 0.0,
+				"affected_rows":         3.0,
+			},
+		},
+		{
+			name:    "too many bytes",
+			cfg:     bigquerytoolset.Config{MaxBytesBilled: 1000},
+			tool:    "execute_query",
+			args:    map[string]any{"sql": "SELECT * FROM sales.orders"},
+			fake:    fakeClient{statementType: "SELECT", bytes: 1001},
+			wantErr: "would process 1001 bytes",
+		},
+		{
+			name:    "invalid query",
+			tool:    "execute_query",
+			args:    map[string]any{"sql": "SELEC 1"},
+			fake:    fakeClient{dryRunErr: fmt.Errorf("syntax error")},
+			wantErr: "syntax error",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := tt.fake
+			tt.cfg.ProjectID = "my-project"
+			tt.cfg.Client = &fake
+			set, err := bigquerytoolset.New(t.Context(), tt.cfg)
+			if err != nil {
+				t.Fatalf("New() failed: %v", err)
+			}
+			tools, err := set.Tools(nil)
+			if err != nil {
+				t.Fatalf("Tools() failed: %v", err)
+			}
+			var ft toolinternal.FunctionTool
+			for _, tl := range tools {
+				if tl.Name() == tt.tool {
+					ft = tl.(toolinternal.FunctionTool)
+				}
+			}
+			ctx := toolinternal.NewToolContext(icontext.NewInvocationContext(t.Context(), icontext.InvocationContextParams{}), "", nil, nil)
+			got, err := ft.Run(ctx, tt.args)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Run() error = %v, want containing %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("Run() failed: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Run() mismatch (-want +got):\n%s", diff)
+			}
+			if fake.queried != tt.wantQuery {
+				t.Errorf("query run = %v, want %v", fake.queried, tt.wantQuery)
+			}
+			if tt.tool == "execute_query" && fake.query.MaxBytesBilled == 0 {
+				t.Errorf("query MaxBytesBilled is not set")
+			}
+		})
+	}
+}
+
+func TestNew_RequiresProject(t *testing.T) {
+	if _, err := bigquerytoolset.New(t.Context(), bigquerytoolset.Config{Client: &fakeClient{}}); err == nil {
+		t.Error("New() succeeded, want an error without ProjectID")
+	}
+}
+
+type fakeClient struct {
+	statementType string
+	bytes         int64
+	rows          []map[string]any
+	affected      int64
+	dryRunErr     error
+
+	query   bigquerytoolset.Query
+	queried bool
+}
+
+func (f *fakeClient) ListDatasets(ctx context.Context, projectID string) ([]string, error) {
+	return []string{projectID + ".sales", projectID + ".web"}, nil
+}
+
+func (f *fakeClient) ListTables(ctx context.Context, projectID, datasetID string) ([]string, error) {
+	return []string{projectID + "." + datasetID + ".orders"}, nil
+}
+
+func (f *fakeClient) GetTable(ctx context.Context, projectID, datasetID, tableID string) (*bigquerytoolset.Table, error) {
+	return &bigquerytoolset.Table{
+		ID:      tableID,
+		NumRows: 3,
+		Schema: []bigquerytoolset.Field{
+			{Name: "name", Type: "STRING"},
+			{Name: "n", Type: "INTEGER", Mode: "NULLABLE"},
+		},
+	}, nil
+}
+
+func (f *fakeClient) DryRun(ctx context.Context, q bigquerytoolset.Query) (*bigquerytoolset.DryRunResult, error) {
+	f.query = q
+	if f.dryRunErr != nil {
+		return nil, f.dryRunErr
+	}
+	return &bigquerytoolset.DryRunResult{StatementType: f.statementType, TotalBytesProcessed: f.bytes}, nil
+}
+
+func (f *fakeClient) Query(ctx context.Context, q bigquerytoolset.Query) (*bigquerytoolset.QueryResult, error) {
+	f.queried = true
+	rows := f.rows
+	if len(rows) > q.MaxRows {
+		rows = rows[:q.MaxRows]
+	}
+	return &bigquerytoolset.QueryResult{
+		Rows:                rows,
+		TotalRows:           uint64(len(f.rows)),
+		TotalBytesProcessed: f.bytes,
+		AffectedRows:        f.affected,
+	}, nil
+}
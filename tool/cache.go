@@ -0,0 +1,70 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tool
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/adk/internal/lru"
+)
+
+// CacheableTool is implemented by the tools whose results can be cached.
+// When the agent calling them has a [ResultCache], the results of their
+// successful calls are cached, by their arguments, and the calls with the
+// same arguments are served from the cache without running the tool.
+//
+// The cached tools must be deterministic and free of side effects on the
+// context of their calls, e.g. on the state, which aren't replayed with
+// the cached results. The tools which change what the cached tools return
+// clear the cache, see [Context.ClearToolCache].
+type CacheableTool interface {
+	Tool
+	// CacheTTL is the duration for which the results of the tool are
+	// cached. Zero or negative means they aren't.
+	CacheTTL() time.Duration
+}
+
+// ResultCache stores the serialized results of the [CacheableTool]s by the
+// key of their calls. Implementations must be safe for concurrent use, and
+// may be backed by an external store, e.g. Redis with GET and SET with an
+// expiration.
+type ResultCache interface {
+	// Get returns the value of the key, and whether it was found.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores the value of the key. A positive ttl is the duration after
+	// which the value may be dropped.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// NewLRUResultCache returns an in-memory [ResultCache] which drops the least
+// recently used results to keep the total size of the cached results under
+// maxBytes. The results larger than maxBytes are not cached.
+func NewLRUResultCache(maxBytes int) ResultCache {
+	return lru.New(maxBytes)
+}
+
+// CacheScope is the scope of the results of a [ResultCache]: the calls are
+// only served the results of the calls of the same scope.
+type CacheScope string
+
+const (
+	// CacheScopeSession shares the results between the calls of a session.
+	CacheScopeSession CacheScope = "session"
+	// CacheScopeApp shares the results between all the calls of the app,
+	// whatever their user: the results of the tools must not depend on the
+	// user.
+	CacheScopeApp CacheScope = "app"
+)
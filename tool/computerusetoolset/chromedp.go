@@ -0,0 +1,149 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package computerusetoolset
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/chromedp/cdproto/input"
+	"github.com/chromedp/chromedp"
+)
+
+// ChromeConfig provides initial configuration for the Chrome driver.
+type ChromeConfig struct {
+	// RemoteURL is the DevTools WebSocket URL of a running browser, e.g.
+	// "ws://127.0.0.1:9222". A headless Chrome is started if it's empty.
+	RemoteURL string
+	// ExecPath is the path of the Chrome executable started, found in the
+	// PATH if it's empty.
+	ExecPath string
+	// ShowBrowser makes the Chrome started show its window, rather than run
+	// headless.
+	ShowBrowser bool
+	// Width and Height are the size of the viewport, 1280x800 by default.
+	Width, Height int
+}
+
+// ChromeDriver is a [Driver] operating a tab of Chrome with the DevTools
+// protocol. It must be closed to release the browser.
+type ChromeDriver struct {
+	ctx            context.Context
+	cancel         context.CancelFunc
+	cancelAllocate context.CancelFunc
+}
+
+// NewChromeDriver starts Chrome, or connects to the browser of
+// ChromeConfig.RemoteURL, and opens the tab of the driver.
+func NewChromeDriver(cfg ChromeConfig) (*ChromeDriver, error) {
+	if cfg.Width <= 0 {
+		cfg.Width = 1280
+	}
+	if cfg.Height <= 0 {
+		cfg.Height = 800
+	}
+	var allocCtx context.Context
+	var cancelAllocate context.CancelFunc
+	if cfg.RemoteURL != "" {
+		allocCtx, cancelAllocate = chromedp.NewRemoteAllocator(context.Background(), cfg.RemoteURL)
+	} else {
+		opts := append(chromedp.DefaultExecAllocatorOptions[:], chromedp.WindowSize(cfg.Width, cfg.Height))
+		if cfg.ExecPath != "" {
+			opts = append(opts, chromedp.ExecPath(cfg.ExecPath))
+		}
+		if cfg.ShowBrowser {
+			opts = append(opts, chromedp.Flag("headless", false))
+		}
+		allocCtx, cancelAllocate = chromedp.NewExecAllocator(context.Background(), opts...)
+	}
+	ctx, cancel := chromedp.NewContext(allocCtx)
+	d := &ChromeDriver{ctx: ctx, cancel: cancel, cancelAllocate: cancelAllocate}
+	// The first run starts the browser, and must be bound to the context of
+	// the driver rather than to a shorter one.
+	if err := chromedp.Run(ctx, chromedp.EmulateViewport(int64(cfg.Width), int64(cfg.Height))); err != nil {
+		d.Close()
+		return nil, fmt.Errorf("failed to start the browser: %w", err)
+	}
+	return d, nil
+}
+
+// Close closes the tab and the browser started.
+func (d *ChromeDriver) Close() error {
+	err := chromedp.Cancel(d.ctx)
+	d.cancel()
+	d.cancelAllocate()
+	return err
+}
+
+func (d *ChromeDriver) Navigate(ctx context.Context, url string) error {
+	if err := d.run(ctx, chromedp.Navigate(url)); err != nil {
+		if strings.Contains(err.Error(), "net::ERR_") {
+			return &Error{Code: CodeNavigationFailed, Message: err.Error(), Retryable: true}
+		}
+		return err
+	}
+	return nil
+}
+
+func (d *ChromeDriver) Click(ctx context.Context, x, y int) error {
+	return d.run(ctx, chromedp.MouseClickXY(float64(x), float64(y)))
+}
+
+func (d *ChromeDriver) Type(ctx context.Context, text string) error {
+	return d.run(ctx, chromedp.KeyEvent(text))
+}
+
+func (d *ChromeDriver) Scroll(ctx context.Context, x, y, deltaX, deltaY int) error {
+	return d.run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		return input.DispatchMouseEvent(input.MouseWheel, float64(x), float64(y)).
+			WithDeltaX(float64(deltaX)).
+			WithDeltaY(float64(deltaY)).
+			Do(ctx)
+	}))
+}
+
+func (d *ChromeDriver) Screenshot(ctx context.Context) ([]byte, error) {
+	var data []byte
+	if err := d.run(ctx, chromedp.CaptureScreenshot(&data)); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (d *ChromeDriver) URL(ctx context.Context) (string, error) {
+	var url string
+	if err := d.run(ctx, chromedp.Location(&url)); err != nil {
+		return "", err
+	}
+	return url, nil
+}
+
+// run runs the actions in the tab, until ctx is done.
+func (d *ChromeDriver) run(ctx context.Context, actions ...chromedp.Action) error {
+	runCtx, cancel := context.WithCancelCause(d.ctx)
+	defer cancel(nil)
+	stop := context.AfterFunc(ctx, func() { cancel(context.Cause(ctx)) })
+	defer stop()
+	if err := chromedp.Run(runCtx, actions...); err != nil {
+		if cause := context.Cause(runCtx); cause != nil {
+			return cause
+		}
+		return err
+	}
+	return nil
+}
+
+var _ Driver = (*ChromeDriver)(nil)
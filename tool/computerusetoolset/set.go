@@ -0,0 +1,455 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package computerusetoolset provides a tool set operating a browser, for
+// agents to navigate pages, click, type, scroll and see the screenshots of
+// the pages.
+//
+// The actions are run by a [Driver], e.g. the Chrome driver of
+// [NewChromeDriver], and guarded by an allowlist of domains, a budget of
+// actions per invocation and a dry-run mode.
+package computerusetoolset
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"strings"
+	"sync"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/internal/toolinternal"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+// DefaultMaxInlineScreenshotBytes is the default of
+// [Config.MaxInlineScreenshotBytes].
+const DefaultMaxInlineScreenshotBytes = 1 << 20
+
+// DefaultScrollAmount is the number of pixels scrolled when the model doesn't
+// give an amount.
+const DefaultScrollAmount = 500
+
+// maxTrackedInvocations bounds the number of invocations whose actions are
+// counted for the budget; the oldest ones are forgotten first.
+const maxTrackedInvocations = 256
+
+// Driver runs the actions in a browser. The coordinates are in CSS pixels of
+// the viewport.
+//
+// The drivers may return an [*Error] to give the code of their failures, the
+// other errors are reported to the model with the CodeDriverError code.
+type Driver interface {
+	// Navigate loads the URL in the page.
+	Navigate(ctx context.Context, url string) error
+	// Click clicks at the coordinates.
+	Click(ctx context.Context, x, y int) error
+	// Type types the text with the keyboard, in the focused element.
+	Type(ctx context.Context, text string) error
+	// Scroll scrolls by the deltas the element at the coordinates.
+	Scroll(ctx context.Context, x, y, deltaX, deltaY int) error
+	// Screenshot returns a PNG image of the viewport.
+	Screenshot(ctx context.Context) ([]byte, error)
+	// URL returns the URL of the page.
+	URL(ctx context.Context) (string, error)
+}
+
+// ErrorCode is the code of an [Error], for the model to decide how to react to
+// the failure of an action.
+type ErrorCode string
+
+const (
+	// CodeInvalidArgument is the code of the actions with invalid arguments.
+	CodeInvalidArgument ErrorCode = "invalid_argument"
+	// CodeDomainNotAllowed is the code of the actions on the pages outside of
+	// Config.AllowedDomains.
+	CodeDomainNotAllowed ErrorCode = "domain_not_allowed"
+	// CodeBudgetExceeded is the code of the actions over
+	// Config.MaxActionsPerInvocation.
+	CodeBudgetExceeded ErrorCode = "action_budget_exceeded"
+	// CodeNavigationFailed is the code of the pages which failed to load.
+	CodeNavigationFailed ErrorCode = "navigation_failed"
+	// CodeTimeout is the code of the actions which didn't complete in time.
+	CodeTimeout ErrorCode = "timeout"
+	// CodeDriverError is the code of the other failures of the driver.
+	CodeDriverError ErrorCode = "driver_error"
+)
+
+// Error is the failure of an action, returned to the model in the "error"
+// field of the result of the tool.
+type Error struct {
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+	// Retryable reports whether the action may succeed if retried.
+	Retryable bool `json:"retryable,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// Config provides initial configuration for the computer use ToolSet.
+type Config struct {
+	// Driver runs the actions. It can be nil in dry-run mode.
+	Driver Driver
+
+	// AllowedDomains are the domains of the pages the tools can navigate to
+	// and act on, including their subdomains, e.g. "example.com" allows
+	// "www.example.com". Empty means any domain.
+	AllowedDomains []string
+
+	// MaxActionsPerInvocation is the number of actions, screenshots included,
+	// the tools can run in one invocation. Zero means no limit.
+	MaxActionsPerInvocation int
+
+	// DryRun makes the tools log their actions rather than running them.
+	DryRun bool
+
+	// MaxInlineScreenshotBytes is the size of the largest screenshot returned
+	// in the function response: the larger ones are saved as artifacts, whose
+	// names are returned. Defaults to DefaultMaxInlineScreenshotBytes.
+	MaxInlineScreenshotBytes int
+}
+
+// New returns a computer use ToolSet.
+// The ToolSet has five tools, returning the URL of the page after their
+// action or an error with a code:
+//   - navigate loads a URL;
+//   - click_at clicks at coordinates of the page;
+//   - type_text types text in the focused element;
+//   - scroll scrolls the page, or the element at coordinates;
+//   - take_screenshot returns a screenshot of the page, as an image part of
+//     the function response for the multimodal models, or as an artifact if
+//     it's larger than Config.MaxInlineScreenshotBytes.
+//
+// The tools run one at a time, and the ToolSet closes the driver when it's
+// closed if the driver implements io.Closer.
+//
+// Example:
+//
+//	driver, err := computerusetoolset.NewChromeDriver(computerusetoolset.ChromeConfig{})
+//	...
+//	browser, err := computerusetoolset.New(computerusetoolset.Config{
+//		Driver:                  driver,
+//		AllowedDomains:          []string{"example.com"},
+//		MaxActionsPerInvocation: 50,
+//	})
+//	...
+//	llmagent.New(llmagent.Config{
+//		Name:     "agent_name",
+//		Model:    model,
+//		Toolsets: []tool.Toolset{browser},
+//	})
+func New(cfg Config) (tool.Toolset, error) {
+	if cfg.Driver == nil && !cfg.DryRun {
+		return nil, fmt.Errorf("driver is required")
+	}
+	var domains []string
+	for _, domain := range cfg.AllowedDomains {
+		normalized := strings.ToLower(strings.Trim(domain, "."))
+		if normalized == "" {
+			return nil, fmt.Errorf("invalid allowed domain %q", domain)
+		}
+		domains = append(domains, normalized)
+	}
+	cfg.AllowedDomains = domains
+	if cfg.MaxInlineScreenshotBytes <= 0 {
+		cfg.MaxInlineScreenshotBytes = DefaultMaxInlineScreenshotBytes
+	}
+	s := &set{cfg: cfg, actions: map[string]int{}}
+
+	for _, t := range []struct {
+		name, description string
+		new               func(functiontool.Config) (tool.Tool, error)
+	}{
+		{"navigate", "Loads a URL in the browser. Returns the URL of the page loaded.", newTool(s.navigate)},
+		{"click_at", "Clicks at the coordinates of the page, in pixels from its top left corner. Returns the URL of the page.", newTool(s.click)},
+		{"type_text", "Types text with the keyboard in the focused element, e.g. after clicking on a text field. Returns the URL of the page.", newTool(s.typeText)},
+		{"scroll", "Scrolls the page, or the element at the coordinates if given, in a direction. Returns the URL of the page.", newTool(s.scroll)},
+		{"take_screenshot", "Takes a screenshot of the page, to see its content and the coordinates of its elements. Returns the URL of the page.", newTool(s.screenshot)},
+	} {
+		tl, err := t.new(functiontool.Config{Name: t.name, Description: t.description, Sequential: true})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s tool: %w", t.name, err)
+		}
+		s.tools = append(s.tools, tl)
+	}
+	return s, nil
+}
+
+func newTool[TArgs any](handler functiontool.Func[TArgs, result]) func(functiontool.Config) (tool.Tool, error) {
+	return func(cfg functiontool.Config) (tool.Tool, error) {
+		return functiontool.New(cfg, handler)
+	}
+}
+
+type set struct {
+	cfg   Config
+	tools []tool.Tool
+
+	// driverMu serializes the actions of the invocations sharing the driver.
+	driverMu sync.Mutex
+
+	mu      sync.Mutex
+	actions map[string]int // by invocation ID
+	order   []string       // invocation IDs of actions, oldest first
+}
+
+func (*set) Name() string {
+	return "computer_use_tool_set"
+}
+
+// Tools returns the navigate, click_at, type_text, scroll and take_screenshot
+// tools.
+func (s *set) Tools(ctx agent.ReadonlyContext) ([]tool.Tool, error) {
+	return s.tools, nil
+}
+
+// Close closes the driver, if it implements io.Closer.
+func (s *set) Close(ctx context.Context) error {
+	if c, ok := s.cfg.Driver.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+type result struct {
+	URL        string      `json:"url,omitempty"`
+	DryRun     bool        `json:"dry_run,omitempty"`
+	Screenshot *screenshot `json:"screenshot,omitempty"`
+	Error      *Error      `json:"error,omitempty"`
+}
+
+type screenshot struct {
+	MIMEType string `json:"mime_type"`
+	Size     int    `json:"size"`
+	// Artifact and Version are the name and version of the artifact of the
+	// screenshots too large to be inlined.
+	Artifact string `json:"artifact,omitempty"`
+	Version  int64  `json:"version,omitempty"`
+}
+
+type navigateArgs struct {
+	URL string `json:"url" jsonschema:"description=absolute http or https URL to load"`
+}
+
+func (s *set) navigate(ctx tool.Context, args navigateArgs) (result, error) {
+	u, err := url.Parse(args.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errorResult(&Error{Code: CodeInvalidArgument, Message: fmt.Sprintf("%q isn't an absolute http or https URL", args.URL)}), nil
+	}
+	if !s.allowed(u) {
+		return errorResult(domainNotAllowed(u)), nil
+	}
+	return s.run(ctx, fmt.Sprintf("navigate to %s", args.URL), false, func(ctx context.Context, d Driver) error {
+		return d.Navigate(ctx, args.URL)
+	})
+}
+
+type clickArgs struct {
+	X int `json:"x" jsonschema:"description=horizontal coordinate in pixels"`
+	Y int `json:"y" jsonschema:"description=vertical coordinate in pixels"`
+}
+
+func (s *set) click(ctx tool.Context, args clickArgs) (result, error) {
+	if args.X < 0 || args.Y < 0 {
+		return errorResult(&Error{Code: CodeInvalidArgument, Message: "coordinates must be positive"}), nil
+	}
+	return s.run(ctx, fmt.Sprintf("click at (%d, %d)", args.X, args.Y), true, func(ctx context.Context, d Driver) error {
+		return d.Click(ctx, args.X, args.Y)
+	})
+}
+
+type typeArgs struct {
+	Text string `json:"text" jsonschema:"description=text to type"`
+}
+
+func (s *set) typeText(ctx tool.Context, args typeArgs) (result, error) {
+	if args.Text == "" {
+		return errorResult(&Error{Code: CodeInvalidArgument, Message: "text is required"}), nil
+	}
+	return s.run(ctx, fmt.Sprintf("type %q", args.Text), true, func(ctx context.Context, d Driver) error {
+		return d.Type(ctx, args.Text)
+	})
+}
+
+type scrollArgs struct {
+	Direction string `json:"direction" jsonschema:"description=up, down, left or right"`
+	Amount    int    `json:"amount,omitempty" jsonschema:"description=number of pixels to scroll; 500 if omitted"`
+	X         int    `json:"x,omitempty" jsonschema:"description=horizontal coordinate of the element to scroll; the page if omitted"`
+	Y         int    `json:"y,omitempty" jsonschema:"description=vertical coordinate of the element to scroll; the page if omitted"`
+}
+
+func (s *set) scroll(ctx tool.Context, args scrollArgs) (result, error) {
+	amount := args.Amount
+	if amount == 0 {
+		amount = DefaultScrollAmount
+	}
+	if amount < 0 || args.X < 0 || args.Y < 0 {
+		return errorResult(&Error{Code: CodeInvalidArgument, Message: "amount and coordinates must be positive"}), nil
+	}
+	var dx, dy int
+	switch args.Direction {
+	case "up":
+		dy = -amount
+	case "down":
+		dy = amount
+	case "left":
+		dx = -amount
+	case "right":
+		dx = amount
+	default:
+		return errorResult(&Error{Code: CodeInvalidArgument, Message: fmt.Sprintf("direction %q isn't up, down, left or right", args.Direction)}), nil
+	}
+	return s.run(ctx, fmt.Sprintf("scroll %s by %d at (%d, %d)", args.Direction, amount, args.X, args.Y), true, func(ctx context.Context, d Driver) error {
+		return d.Scroll(ctx, args.X, args.Y, dx, dy)
+	})
+}
+
+type screenshotArgs struct{}
+
+func (s *set) screenshot(ctx tool.Context, _ screenshotArgs) (result, error) {
+	var data []byte
+	res, err := s.run(ctx, "take a screenshot", true, func(rctx context.Context, d Driver) error {
+		var err error
+		data, err = d.Screenshot(rctx)
+		return err
+	})
+	if err != nil || res.Error != nil || res.DryRun {
+		return res, err
+	}
+	res.Screenshot = &screenshot{MIMEType: "image/png", Size: len(data)}
+	if len(data) <= s.cfg.MaxInlineScreenshotBytes {
+		toolinternal.AddResponseParts(ctx, &genai.FunctionResponsePart{
+			InlineData: &genai.FunctionResponseBlob{MIMEType: "image/png", Data: data},
+		})
+		return res, nil
+	}
+	name := "screenshot_" + ctx.FunctionCallID() + ".png"
+	resp, err := ctx.Artifacts().Save(ctx, name, genai.NewPartFromBytes(data, "image/png"))
+	if err != nil {
+		return result{}, fmt.Errorf("failed to save screenshot artifact %q: %w", name, err)
+	}
+	res.Screenshot.Artifact, res.Screenshot.Version = name, resp.Version
+	return res, nil
+}
+
+// run runs the action with the driver, after counting it in the budget of the
+// invocation and, if onPage, checking that the page is in an allowed domain.
+// The errors of the action are returned in the result.
+func (s *set) run(ctx tool.Context, description string, onPage bool, action func(context.Context, Driver) error) (result, error) {
+	if err := s.countAction(ctx.InvocationID()); err != nil {
+		return errorResult(err), nil
+	}
+	if s.cfg.DryRun {
+		log.Printf("Computer use dry run, invocation %s: %s", ctx.InvocationID(), description)
+		return result{DryRun: true}, nil
+	}
+
+	s.driverMu.Lock()
+	defer s.driverMu.Unlock()
+	if onPage {
+		if err := s.checkPage(ctx); err != nil {
+			return errorResult(err), nil
+		}
+	}
+	if err := action(ctx, s.cfg.Driver); err != nil {
+		return errorResult(err), nil
+	}
+	pageURL, err := s.cfg.Driver.URL(ctx)
+	if err != nil {
+		return errorResult(err), nil
+	}
+	return result{URL: pageURL}, nil
+}
+
+// checkPage fails if the page of the driver isn't in an allowed domain, e.g.
+// after a click on a link to another site.
+func (s *set) checkPage(ctx context.Context) error {
+	if len(s.cfg.AllowedDomains) == 0 {
+		return nil
+	}
+	pageURL, err := s.cfg.Driver.URL(ctx)
+	if err != nil {
+		return err
+	}
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return &Error{Code: CodeDriverError, Message: fmt.Sprintf("invalid page URL %q", pageURL)}
+	}
+	if pageURL == "" || u.Scheme == "about" || s.allowed(u) {
+		return nil
+	}
+	return domainNotAllowed(u)
+}
+
+// allowed reports whether the URL is in an allowed domain.
+func (s *set) allowed(u *url.URL) bool {
+	if len(s.cfg.AllowedDomains) == 0 {
+		return true
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	for _, domain := range s.cfg.AllowedDomains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// countAction counts an action of the invocation, failing if the invocation
+// has already run MaxActionsPerInvocation actions.
+func (s *set) countAction(invocationID string) error {
+	if s.cfg.MaxActionsPerInvocation <= 0 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n, ok := s.actions[invocationID]
+	if n >= s.cfg.MaxActionsPerInvocation {
+		return &Error{Code: CodeBudgetExceeded, Message: fmt.Sprintf("budget of %d actions per invocation exceeded", s.cfg.MaxActionsPerInvocation)}
+	}
+	if !ok {
+		s.order = append(s.order, invocationID)
+		if len(s.order) > maxTrackedInvocations {
+			delete(s.actions, s.order[0])
+			s.order = s.order[1:]
+		}
+	}
+	s.actions[invocationID] = n + 1
+	return nil
+}
+
+func domainNotAllowed(u *url.URL) *Error {
+	return &Error{Code: CodeDomainNotAllowed, Message: fmt.Sprintf("domain %q isn't allowed", u.Hostname())}
+}
+
+// errorResult returns the result of an action failing with err.
+func errorResult(err error) result {
+	var e *Error
+	switch {
+	case errors.As(err, &e):
+	case errors.Is(err, context.DeadlineExceeded):
+		e = &Error{Code: CodeTimeout, Message: err.Error(), Retryable: true}
+	default:
+		e = &Error{Code: CodeDriverError, Message: err.Error()}
+	}
+	return result{Error: e}
+}
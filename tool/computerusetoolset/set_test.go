@@ -0,0 +1,359 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package computerusetoolset_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/artifact"
+	artifactinternal "google.golang.org/adk/internal/artifact"
+	icontext "google.golang.org/adk/internal/context"
+	"google.golang.org/adk/internal/testutil"
+	"google.golang.org/adk/internal/toolinternal"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/computerusetoolset"
+)
+
+// fakeDriver records the actions it runs on a page.
+type fakeDriver struct {
+	url        string
+	screenshot []byte
+	err        error
+	actions    []string
+	closed     bool
+}
+
+func (d *fakeDriver) Navigate(_ context.Context, url string) error {
+	d.actions = append(d.actions, "navigate "+url)
+	if d.err != nil {
+		return d.err
+	}
+	d.url = url
+	return nil
+}
+
+func (d *fakeDriver) Click(_ context.Context, x, y int) error {
+	d.actions = append(d.actions, fmt.Sprintf("click %d %d", x, y))
+	return d.err
+}
+
+func (d *fakeDriver) Type(_ context.Context, text string) error {
+	d.actions = append(d.actions, "type "+text)
+	return d.err
+}
+
+func (d *fakeDriver) Scroll(_ context.Context, x, y, deltaX, deltaY int) error {
+	d.actions = append(d.actions, fmt.Sprintf("scroll %d %d %d %d", x, y, deltaX, deltaY))
+	return d.err
+}
+
+func (d *fakeDriver) Screenshot(context.Context) ([]byte, error) {
+	d.actions = append(d.actions, "screenshot")
+	return d.screenshot, d.err
+}
+
+func (d *fakeDriver) URL(context.Context) (string, error) {
+	return d.url, nil
+}
+
+func (d *fakeDriver) Close() error {
+	d.closed = true
+	return nil
+}
+
+func TestComputerUseToolset(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         computerusetoolset.Config
+		driver      fakeDriver
+		calls       []call
+		wantActions []string
+	}{
+		{
+			name: "actions",
+			cfg:  computerusetoolset.Config{AllowedDomains: []string{"example.com"}},
+			calls: []call{
+				{tool: "navigate", args: map[string]any{"url": "https://www.example.com/"}, want: map[string]any{"url": "https://www.example.com/"}},
+				{tool: "click_at", args: map[string]any{"x": 10, "y": 20}, want: map[string]any{"url": "https://www.example.com/"}},
+				{tool: "type_text", args: map[string]any{"text": "shoes"}, want: map[string]any{"url": "https://www.example.com/"}},
+				{tool: "scroll", args: map[string]any{"direction": "down"}, want: map[string]any{"url": "https://www.example.com/"}},
+				{tool: "scroll", args: map[string]any{"direction": "left", "amount": 100, "x": 5, "y": 6}, want: map[string]any{"url": "https://www.example.com/"}},
+			},
+			wantActions: []string{"navigate https://www.example.com/", "click 10 20", "type shoes", "scroll 0 0 0 500", "scroll 5 6 -100 0"},
+		},
+		{
+			name: "invalid arguments",
+			calls: []call{
+				{tool: "navigate", args: map[string]any{"url": "file:///etc/passwd"}, want: errorResult("invalid_argument", `"file:///etc/passwd" isn't an absolute http or https URL`, false)},
+				{tool: "click_at", args: map[string]any{"x": -1, "y": 0}, want: errorResult("invalid_argument", "coordinates must be positive", false)},
+				{tool: "type_text", args: map[string]any{"text": ""}, want: errorResult("invalid_argument", "text is required", false)},
+				{tool: "scroll", args: map[string]any{"direction": "sideways"}, want: errorResult("invalid_argument", `direction "sideways" isn't up, down, left or right`, false)},
+			},
+		},
+		{
+			name:   "domain allowlist",
+			cfg:    computerusetoolset.Config{AllowedDomains: []string{"Example.com."}},
+			driver: fakeDriver{url: "https://evil.com/"},
+			calls: []call{
+				{tool: "navigate", args: map[string]any{"url": "https://notexample.com/"}, want: errorResult("domain_not_allowed", `domain "notexample.com" isn't allowed`, false)},
+				{tool: "click_at", args: map[string]any{"x": 1, "y": 1}, want: errorResult("domain_not_allowed", `domain "evil.com" isn't allowed`, false)},
+				{tool: "take_screenshot", args: map[string]any{}, want: errorResult("domain_not_allowed", `domain "evil.com" isn't allowed`, false)},
+				{tool: "navigate", args: map[string]any{"url": "https://example.com/a"}, want: map[string]any{"url": "https://example.com/a"}},
+			},
+			wantActions: []string{"navigate https://example.com/a"},
+		},
+		{
+			name: "action budget",
+			cfg:  computerusetoolset.Config{MaxActionsPerInvocation: 2},
+			calls: []call{
+				{tool: "click_at", args: map[string]any{"x": 1, "y": 1}, want: map[string]any{}},
+				{tool: "take_screenshot", args: map[string]any{}, want: map[string]any{"screenshot": map[string]any{"mime_type": "image/png", "size": 0.0}}},
+				{tool: "click_at", args: map[string]any{"x": 2, "y": 2}, want: errorResult("action_budget_exceeded", "budget of 2 actions per invocation exceeded", false)},
+				{tool: "click_at", args: map[string]any{"x": 3, "y": 3}, invocation: "other", want: map[string]any{}},
+			},
+			wantActions: []string{"click 1 1", "screenshot", "click 3 3"},
+		},
+		{
+			name: "dry run",
+			cfg:  computerusetoolset.Config{DryRun: true, MaxActionsPerInvocation: 1},
+			calls: []call{
+				{tool: "navigate", args: map[string]any{"url": "https://example.com/"}, want: map[string]any{"dry_run": true}},
+				{tool: "take_screenshot", args: map[string]any{}, want: errorResult("action_budget_exceeded", "budget of 1 actions per invocation exceeded", false)},
+			},
+		},
+		{
+			name:   "driver errors",
+			driver: fakeDriver{err: &computerusetoolset.Error{Code: computerusetoolset.CodeNavigationFailed, Message: "net::ERR_NAME_NOT_RESOLVED", Retryable: true}},
+			calls: []call{
+				{tool: "navigate", args: map[string]any{"url": "https://example.invalid/"}, want: errorResult("navigation_failed", "net::ERR_NAME_NOT_RESOLVED", true)},
+			},
+			wantActions: []string{"navigate https://example.invalid/"},
+		},
+		{
+			name:   "driver timeout",
+			driver: fakeDriver{err: fmt.Errorf("click: %w", context.DeadlineExceeded)},
+			calls: []call{
+				{tool: "click_at", args: map[string]any{"x": 1, "y": 1}, want: errorResult("timeout", "click: context deadline exceeded", true)},
+			},
+			wantActions: []string{"click 1 1"},
+		},
+		{
+			name:   "other driver error",
+			driver: fakeDriver{err: errors.New("tab crashed")},
+			calls: []call{
+				{tool: "type_text", args: map[string]any{"text": "a"}, want: errorResult("driver_error", "tab crashed", false)},
+			},
+			wantActions: []string{"type a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver := tt.driver
+			if !tt.cfg.DryRun {
+				tt.cfg.Driver = &driver
+			}
+			byName := newTools(t, tt.cfg)
+			for i, c := range tt.calls {
+				invocation := c.invocation
+				if invocation == "" {
+					invocation = "invocation"
+				}
+				got, err := byName[c.tool].Run(createToolContext(t, artifact.InMemoryService(), invocation), c.args)
+				if err != nil {
+					t.Fatalf("call %d: %s() failed: %v", i, c.tool, err)
+				}
+				if diff := cmp.Diff(c.want, got); diff != "" {
+					t.Errorf("call %d: %s() mismatch (-want +got):\n%s", i, c.tool, diff)
+				}
+			}
+			if diff := cmp.Diff(tt.wantActions, driver.actions); diff != "" {
+				t.Errorf("driver actions mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestComputerUseToolset_Screenshot(t *testing.T) {
+	tests := []struct {
+		name         string
+		screenshot   []byte
+		want         map[string]any
+		wantParts    []*genai.FunctionResponsePart
+		wantArtifact bool
+	}{
+		{
+			name:       "inline",
+			screenshot: []byte("small"),
+			want:       map[string]any{"url": "https://example.com/", "screenshot": map[string]any{"mime_type": "image/png", "size": 5.0}},
+			wantParts: []*genai.FunctionResponsePart{
+				{InlineData: &genai.FunctionResponseBlob{MIMEType: "image/png", Data: []byte("small")}},
+			},
+		},
+		{
+			name:       "artifact",
+			screenshot: []byte("large screenshot"),
+			want: map[string]any{"url": "https://example.com/", "screenshot": map[string]any{
+				"mime_type": "image/png",
+				"size":      16.0,
+				"artifact":  "screenshot_call.png",
+				"version":   1.0,
+			}},
+			wantArtifact: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver := &fakeDriver{url: "https://example.com/", screenshot: tt.screenshot}
+			byName := newTools(t, computerusetoolset.Config{Driver: driver, MaxInlineScreenshotBytes: 8})
+			service := artifact.InMemoryService()
+			ctx := createToolContext(t, service, "invocation")
+			got, err := byName["take_screenshot"].Run(ctx, map[string]any{})
+			if err != nil {
+				t.Fatalf("take_screenshot() failed: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("take_screenshot() mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantParts, toolinternal.ResponseParts(ctx)); diff != "" {
+				t.Errorf("response parts mismatch (-want +got):\n%s", diff)
+			}
+			load, err := service.Load(t.Context(), &artifact.LoadRequest{AppName: "app", UserID: "user", SessionID: "session", FileName: "screenshot_call.png"})
+			if !tt.wantArtifact {
+				if err == nil {
+					t.Errorf("Load() succeeded, want no screenshot artifact")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() failed: %v", err)
+			}
+			if diff := cmp.Diff(genai.NewPartFromBytes(tt.screenshot, "image/png"), load.Part); diff != "" {
+				t.Errorf("artifact mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestComputerUseToolset_Agent(t *testing.T) {
+	browser, err := computerusetoolset.New(computerusetoolset.Config{Driver: &fakeDriver{url: "https://example.com/", screenshot: []byte("png")}})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	llm := &testutil.MockModel{Responses: []*genai.Content{
+		genai.NewContentFromFunctionCall("take_screenshot", map[string]any{}, genai.RoleModel),
+		genai.NewContentFromText("The page is blank.", genai.RoleModel),
+	}}
+	a, err := llmagent.New(llmagent.Config{Name: "browser", Model: llm, Toolsets: []tool.Toolset{browser}})
+	if err != nil {
+		t.Fatalf("llmagent.New() failed: %v", err)
+	}
+	var response *genai.FunctionResponse
+	for ev, err := range testutil.NewTestAgentRunner(t, a).Run(t, "session", "What's on the page?") {
+		if err != nil {
+			t.Fatalf("Run() failed: %v", err)
+		}
+		if ev.Content != nil && ev.Content.Parts[0].FunctionResponse != nil {
+			response = ev.Content.Parts[0].FunctionResponse
+		}
+	}
+	if response == nil {
+		t.Fatalf("no function response")
+	}
+	wantParts := []*genai.FunctionResponsePart{
+		{InlineData: &genai.FunctionResponseBlob{MIMEType: "image/png", Data: []byte("png")}},
+	}
+	if diff := cmp.Diff(wantParts, response.Parts); diff != "" {
+		t.Errorf("function response parts mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestComputerUseToolset_Close(t *testing.T) {
+	driver := &fakeDriver{}
+	set, err := computerusetoolset.New(computerusetoolset.Config{Driver: driver})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	if err := tool.CloseToolset(t.Context(), set); err != nil {
+		t.Fatalf("CloseToolset() failed: %v", err)
+	}
+	if !driver.closed {
+		t.Error("CloseToolset() didn't close the driver")
+	}
+}
+
+func TestNew_Errors(t *testing.T) {
+	for _, cfg := range []computerusetoolset.Config{
+		{},
+		{Driver: &fakeDriver{}, AllowedDomains: []string{"."}},
+	} {
+		if _, err := computerusetoolset.New(cfg); err == nil {
+			t.Errorf("New(%+v) succeeded, want an error", cfg)
+		}
+	}
+}
+
+type call struct {
+	tool       string
+	args       map[string]any
+	invocation string
+	want       map[string]any
+}
+
+func errorResult(code, message string, retryable bool) map[string]any {
+	err := map[string]any{"code": code, "message": message}
+	if retryable {
+		err["retryable"] = true
+	}
+	return map[string]any{"error": err}
+}
+
+func newTools(t *testing.T, cfg computerusetoolset.Config) map[string]toolinternal.FunctionTool {
+	t.Helper()
+	set, err := computerusetoolset.New(cfg)
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	tools, err := set.Tools(nil)
+	if err != nil {
+		t.Fatalf("Tools() failed: %v", err)
+	}
+	byName := map[string]toolinternal.FunctionTool{}
+	for _, tl := range tools {
+		byName[tl.Name()] = tl.(toolinternal.FunctionTool)
+	}
+	return byName
+}
+
+func createToolContext(t *testing.T, service artifact.Service, invocationID string) tool.Context {
+	t.Helper()
+	ctx := icontext.NewInvocationContext(t.Context(), icontext.InvocationContextParams{
+		Artifacts: &artifactinternal.Artifacts{
+			Service:   service,
+			AppName:   "app",
+			UserID:    "user",
+			SessionID: "session",
+		},
+		InvocationID: invocationID,
+	})
+	return toolinternal.NewToolContext(ctx, "call", nil, nil)
+}
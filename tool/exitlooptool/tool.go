@@ -23,15 +23,33 @@ import (
 )
 
 // EmptyArgs is an empty struct used as an argument for the exitLoop tool.
+//
+// Deprecated: the tool takes [Args].
 type EmptyArgs struct{}
 
-func exitLoop(ctx tool.Context, myArgs EmptyArgs) (map[string]string, error) {
+// Args are the arguments of the exitLoop tool.
+type Args struct {
+	Reason         string `json:"reason,omitempty" jsonschema:"description=why the loop is done"`
+	EscalateToRoot bool   `json:"escalate_to_root,omitempty" jsonschema:"description=whether to exit all the enclosing loops instead of the innermost one"`
+}
+
+func exitLoop(ctx tool.Context, args Args) (map[string]string, error) {
 	ctx.Actions().Escalate = true
+	ctx.Actions().EscalateToRoot = args.EscalateToRoot
 	ctx.Actions().SkipSummarization = true
-	return map[string]string{}, nil
+	result := map[string]string{}
+	if args.Reason != "" {
+		// The loop agent reports the reason in the event ending the loop.
+		result["reason"] = args.Reason
+	}
+	return result, nil
 }
 
 // New creates an instance of an exitLoop tool.
+//
+// The tool escalates the call, which ends the innermost loop agent running the
+// agent calling it, or all the enclosing loop agents if its escalate_to_root
+// argument is set.
 func New() (tool.Tool, error) {
 	exitLoopTool, err := functiontool.New(functiontool.Config{
 		Name:        "exit_loop",
@@ -55,6 +55,8 @@ func TestExitLoopToolExitsLoopAgent(t *testing.T) {
 				genai.NewContentFromFunctionCall("exit_loop", map[string]any{}, "model"),
 				// Result from the tool execution
 				genai.NewContentFromFunctionResponse("exit_loop", map[string]any{}, "user"),
+				// The exit of the loop
+				nil,
 			},
 		},
 		{
@@ -71,6 +73,7 @@ func TestExitLoopToolExitsLoopAgent(t *testing.T) {
 			want: []*genai.Content{
 				genai.NewContentFromText("iteration 1 response", "model"),
 				genai.NewContentFromText("iteration 2 response", "model"),
+				nil,
 			},
 		},
 		{
@@ -84,6 +87,8 @@ func TestExitLoopToolExitsLoopAgent(t *testing.T) {
 			want: []*genai.Content{
 				genai.NewContentFromFunctionCall("exit_loop", map[string]any{}, "model"),
 				genai.NewContentFromFunctionResponse("exit_loop", map[string]any{}, "user"),
+				// The exit of the loop
+				nil,
 			},
 		},
 	}
@@ -149,3 +154,74 @@ func TestExitLoopToolExitsLoopAgent(t *testing.T) {
 		})
 	}
 }
+
+func TestExitLoopToolNestedLoops(t *testing.T) {
+	testCases := []struct {
+		name string
+		args map[string]any
+		want []map[string]any
+	}{
+		{
+			name: "ExitsInnermostLoop",
+			args: map[string]any{"reason": "draft approved"},
+			want: []map[string]any{
+				{"cause": loopagent.ExitEscalated, "iterations": 1, "reason": "draft approved"},
+				{"cause": loopagent.ExitEscalated, "iterations": 1, "reason": "draft approved"},
+				{"cause": loopagent.ExitMaxIterations, "iterations": 2},
+			},
+		},
+		{
+			name: "EscalatesToRoot",
+			args: map[string]any{"reason": "nothing left to do", "escalate_to_root": true},
+			want: []map[string]any{
+				{"cause": loopagent.ExitEscalated, "iterations": 1, "reason": "nothing left to do"},
+				{"cause": loopagent.ExitEscalated, "iterations": 1, "reason": "nothing left to do"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			exitLoopTool, err := exitlooptool.New()
+			if err != nil {
+				t.Fatalf("failed to create exit tool: %v", err)
+			}
+			call := genai.NewContentFromFunctionCall("exit_loop", tc.args, "model")
+			mockModel := &testutil.MockModel{Responses: []*genai.Content{call, call}}
+			a, err := llmagent.New(llmagent.Config{
+				Name:  "critic",
+				Model: mockModel,
+				Tools: []tool.Tool{exitLoopTool},
+			})
+			if err != nil {
+				t.Fatalf("failed to create llm agent: %v", err)
+			}
+			inner, err := loopagent.New(loopagent.Config{
+				AgentConfig:   agent.Config{Name: "inner", SubAgents: []agent.Agent{a}},
+				MaxIterations: 3,
+			})
+			if err != nil {
+				t.Fatalf("failed to create loop agent: %v", err)
+			}
+			outer, err := loopagent.New(loopagent.Config{
+				AgentConfig:   agent.Config{Name: "outer", SubAgents: []agent.Agent{inner}},
+				MaxIterations: 2,
+			})
+			if err != nil {
+				t.Fatalf("failed to create loop agent: %v", err)
+			}
+
+			var got []map[string]any
+			for ev, err := range testutil.NewTestAgentRunner(t, outer).Run(t, "id", "message") {
+				if err != nil {
+					t.Fatalf("runner returned unexpected error: %v", err)
+				}
+				if exit, ok := ev.CustomMetadata[loopagent.ExitMetadataKey].(map[string]any); ok {
+					got = append(got, exit)
+				}
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("loop exits mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
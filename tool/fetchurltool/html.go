@@ -0,0 +1,243 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetchurltool
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// page is the readable content of an HTML page.
+type page struct {
+	title string
+	text  string
+}
+
+// skipped are the elements whose content isn't part of the readable text.
+var skipped = map[atom.Atom]bool{
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Noscript: true,
+	atom.Template: true,
+	atom.Nav:      true,
+	atom.Svg:      true,
+	atom.Iframe:   true,
+	atom.Title:    true,
+	atom.Form:     true,
+	atom.Button:   true,
+}
+
+// blocks are the elements whose content starts on a new line.
+var blocks = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Section: true, atom.Article: true,
+	atom.Main: true, atom.Header: true, atom.Footer: true, atom.Aside: true,
+	atom.Blockquote: true, atom.Ul: true, atom.Ol: true,
+	atom.Table: true, atom.Tr: true, atom.Dl: true, atom.Dt: true, atom.Dd: true,
+	atom.Pre: true, atom.Figure: true, atom.Figcaption: true, atom.Hr: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+}
+
+var headingLevels = map[atom.Atom]int{
+	atom.H1: 1, atom.H2: 2, atom.H3: 3, atom.H4: 4, atom.H5: 5, atom.H6: 6,
+}
+
+// htmlToText extracts the readable text of an HTML page, in a Markdown-like
+// format keeping its headings, list items and links, whose URLs are resolved
+// against base.
+func htmlToText(data []byte, base *url.URL) (page, error) {
+	doc, err := html.Parse(bytes.NewReader(data))
+	if err != nil {
+		return page{}, err
+	}
+	w := &textWriter{base: base}
+	w.walk(doc)
+	return page{title: w.title, text: w.String()}, nil
+}
+
+type textWriter struct {
+	base  *url.URL
+	title string
+	sb    strings.Builder
+	// space is whether a space is pending before the next word, newlines the
+	// number of newlines pending before the next block.
+	space    bool
+	newlines int
+	pre      int
+}
+
+func (w *textWriter) walk(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		w.text(n.Data)
+		return
+	case html.ElementNode:
+		if n.DataAtom == atom.Title && w.title == "" {
+			w.title = strings.Join(strings.Fields(nodeText(n)), " ")
+		}
+		if skipped[n.DataAtom] || hasAttr(n, "hidden") || attr(n, "aria-hidden") == "true" {
+			return
+		}
+		switch n.DataAtom {
+		case atom.Br:
+			w.breakLine(1)
+			return
+		case atom.A:
+			w.link(n)
+			return
+		case atom.Img:
+			if alt := strings.TrimSpace(attr(n, "alt")); alt != "" {
+				w.text("[" + alt + "]")
+			}
+			return
+		}
+	}
+
+	if blocks[n.DataAtom] {
+		w.breakLine(2)
+		if level := headingLevels[n.DataAtom]; level > 0 {
+			w.raw(strings.Repeat("#", level) + " ")
+		}
+	}
+	if n.DataAtom == atom.Li {
+		w.breakLine(1)
+		w.raw("- ")
+	}
+	if n.DataAtom == atom.Td || n.DataAtom == atom.Th {
+		w.space = true
+	}
+	if n.DataAtom == atom.Pre {
+		w.pre++
+		defer func() { w.pre-- }()
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		w.walk(c)
+	}
+	if blocks[n.DataAtom] {
+		w.breakLine(2)
+	} else if n.DataAtom == atom.Li {
+		w.breakLine(1)
+	}
+}
+
+// link writes the text of the link followed by its URL, Markdown style.
+func (w *textWriter) link(n *html.Node) {
+	text := strings.Join(strings.Fields(nodeText(n)), " ")
+	href := strings.TrimSpace(attr(n, "href"))
+	u, err := url.Parse(href)
+	if href == "" || strings.HasPrefix(href, "#") || err != nil {
+		w.text(text)
+		return
+	}
+	if w.base != nil {
+		u = w.base.ResolveReference(u)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		w.text(text)
+		return
+	}
+	if text == "" {
+		text = u.String()
+	}
+	w.text("[" + text + "](" + u.String() + ")")
+}
+
+func (w *textWriter) text(s string) {
+	if w.pre > 0 {
+		w.flush()
+		w.sb.WriteString(s)
+		return
+	}
+	if s != "" && isSpace(s[0]) {
+		w.space = true
+	}
+	for i, word := range strings.Fields(s) {
+		if i > 0 {
+			w.space = true
+		}
+		w.raw(word)
+	}
+	if s != "" && isSpace(s[len(s)-1]) {
+		w.space = true
+	}
+}
+
+// raw writes s after the pending separators.
+func (w *textWriter) raw(s string) {
+	w.flush()
+	w.sb.WriteString(s)
+}
+
+func (w *textWriter) flush() {
+	switch {
+	case w.sb.Len() == 0:
+	case w.newlines > 0:
+		w.sb.WriteString(strings.Repeat("\n", w.newlines))
+	case w.space:
+		w.sb.WriteByte(' ')
+	}
+	w.space, w.newlines = false, 0
+}
+
+func (w *textWriter) breakLine(n int) {
+	w.newlines = max(w.newlines, n)
+}
+
+func (w *textWriter) String() string {
+	return w.sb.String()
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r' || b == '\f'
+}
+
+func hasAttr(n *html.Node, key string) bool {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// nodeText returns the text of the node and its descendants.
+func nodeText(n *html.Node) string {
+	var sb strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode || !skipped[c.DataAtom] {
+				walk(c)
+			}
+		}
+	}
+	walk(n)
+	return sb.String()
+}
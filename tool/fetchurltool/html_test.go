@@ -0,0 +1,71 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetchurltool
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestHTMLToText(t *testing.T) {
+	base, _ := url.Parse("https://example.com/docs/")
+	tests := []struct {
+		name      string
+		html      string
+		wantTitle string
+		wantText  string
+	}{
+		{
+			name: "headings, paragraphs and links",
+			html: `<html><head><title> The  Page </title><style>p{}</style></head><body>
+<nav><a href="/">Home</a></nav>
+<h1>Intro</h1>
+<p>Some <b>bold</b>   text
+ with a <a href="guide.html">guide</a>.</p>
+<script>alert(1)</script>
+<h2>List</h2>
+<ul><li>one</li><li>two</li></ul>
+</body></html>`,
+			wantTitle: "The Page",
+			wantText:  "# Intro\n\nSome bold text with a [guide](https://example.com/docs/guide.html).\n\n## List\n\n- one\n- two",
+		},
+		{
+			name:     "pre and breaks",
+			html:     "<p>a<br>b</p><pre>x  y\n z</pre>",
+			wantText: "a\nb\n\nx  y\n z",
+		},
+		{
+			name:     "non http links and images",
+			html:     `<p><a href="javascript:x()">run</a> <a href="#top">top</a> <img alt="logo"></p><div hidden>secret</div>`,
+			wantText: "run top [logo]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := htmlToText([]byte(tt.html), base)
+			if err != nil {
+				t.Fatalf("htmlToText() failed: %v", err)
+			}
+			if got.title != tt.wantTitle {
+				t.Errorf("htmlToText() title = %q, want %q", got.title, tt.wantTitle)
+			}
+			if diff := cmp.Diff(tt.wantText, got.text); diff != "" {
+				t.Errorf("htmlToText() text mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
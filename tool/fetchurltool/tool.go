@@ -0,0 +1,331 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fetchurltool provides a tool fetching web pages for the model.
+// The tool returns the readable text of the HTML pages, and saves the
+// responses which aren't text, such as PDFs and images, as artifacts.
+//
+// The tool only fetches HTTP(S) URLs of public hosts: the addresses of the
+// hosts are checked once resolved, on each connection, so that neither DNS
+// records nor redirects can make it reach a private or link-local address.
+package fetchurltool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"path"
+	"strings"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/internal/netinternal"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+// Defaults of the Config fields.
+const (
+	DefaultMaxBytes  = 5 << 20
+	DefaultMaxChars  = 20_000
+	DefaultTimeout   = 30 * time.Second
+	maxRedirects     = 10
+	truncationMarker = "\n[… truncated]"
+)
+
+// ErrBlocked is wrapped by the errors of the fetches the tool refuses to make
+// or follow.
+var ErrBlocked = errors.New("URL is blocked")
+
+// Config is the configuration of the tool created by [New].
+type Config struct {
+	// AllowedHosts restricts the fetches, including the redirects, to the
+	// given hosts. A "*." prefix also allows the subdomains of the host, e.g.
+	// "*.example.com" allows "example.com" and "docs.example.com". If empty,
+	// all the public hosts are allowed.
+	AllowedHosts []string
+	// AllowPrivateNetworks allows fetching the loopback, private and
+	// link-local addresses. It must only be set when the model can be trusted
+	// with the network of the agent, e.g. in tests.
+	AllowPrivateNetworks bool
+
+	// MaxBytes is the size of the largest response body read. The longer
+	// text bodies are truncated, the longer binary ones fail. Defaults to
+	// DefaultMaxBytes.
+	MaxBytes int64
+	// MaxChars is the number of characters of text returned to the model,
+	// the longer texts are truncated with a marker. Defaults to
+	// DefaultMaxChars.
+	MaxChars int
+	// Timeout bounds the duration of a fetch, redirects included. Defaults
+	// to DefaultTimeout.
+	Timeout time.Duration
+
+	// Transport is the base of the transport used for the fetches. Its
+	// proxy and dialer are replaced so that the addresses are checked.
+	// Optional: if nil, http.DefaultTransport is used.
+	Transport *http.Transport
+	// UserAgent is sent with the requests, if set.
+	UserAgent string
+}
+
+// New creates a fetch_url tool configured by cfg.
+func New(cfg Config) (tool.Tool, error) {
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = DefaultMaxBytes
+	}
+	if cfg.MaxChars <= 0 {
+		cfg.MaxChars = DefaultMaxChars
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	for _, host := range cfg.AllowedHosts {
+		if strings.TrimPrefix(host, "*.") == "" {
+			return nil, fmt.Errorf("invalid allowed host %q", host)
+		}
+	}
+	f := &fetcher{cfg: cfg}
+	f.client = &http.Client{
+		Transport: f.transport(),
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			return f.checkURL(req.URL)
+		},
+	}
+	return functiontool.New(functiontool.Config{
+		Name:        "fetch_url",
+		Description: "Fetches a web page with a GET request and returns its readable text, or saves the response as an artifact if it is not text, such as a PDF or an image.",
+	}, f.fetch)
+}
+
+type fetcher struct {
+	cfg    Config
+	client *http.Client
+}
+
+type args struct {
+	URL string `json:"url" jsonschema:"description=absolute http or https URL to fetch"`
+}
+
+type result struct {
+	URL         string `json:"url"`
+	FinalURL    string `json:"final_url"`
+	StatusCode  int    `json:"status_code"`
+	ContentType string `json:"content_type,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Content     string `json:"content,omitempty"`
+	Truncated   bool   `json:"truncated,omitempty"`
+	// Artifact and ArtifactVersion reference the artifact the response was
+	// saved to, if it isn't text.
+	Artifact        string `json:"artifact,omitempty"`
+	ArtifactVersion int64  `json:"artifact_version,omitempty"`
+	Size            int    `json:"size,omitempty"`
+}
+
+func (f *fetcher) fetch(ctx tool.Context, args args) (result, error) {
+	u, err := url.Parse(args.URL)
+	if err != nil {
+		return result{}, fmt.Errorf("invalid URL %q: %w", args.URL, err)
+	}
+	if err := f.checkURL(u); err != nil {
+		return result{}, err
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx, f.cfg.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return result{}, fmt.Errorf("failed to create request for %q: %w", args.URL, err)
+	}
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,text/plain;q=0.9,*/*;q=0.8")
+	if f.cfg.UserAgent != "" {
+		req.Header.Set("User-Agent", f.cfg.UserAgent)
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return result{}, fmt.Errorf("failed to fetch %q: %w", args.URL, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, f.cfg.MaxBytes+1))
+	if err != nil {
+		return result{}, fmt.Errorf("failed to read the response of %q: %w", args.URL, err)
+	}
+	oversized := int64(len(body)) > f.cfg.MaxBytes
+	if oversized {
+		body = body[:f.cfg.MaxBytes]
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(body)
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	res := result{
+		URL:         args.URL,
+		FinalURL:    resp.Request.URL.String(),
+		StatusCode:  resp.StatusCode,
+		ContentType: contentType,
+	}
+
+	switch {
+	case mediaType == "text/html" || mediaType == "application/xhtml+xml":
+		page, err := htmlToText(body, resp.Request.URL)
+		if err != nil {
+			return result{}, fmt.Errorf("failed to parse the HTML of %q: %w", args.URL, err)
+		}
+		res.Title = page.title
+		res.Content, res.Truncated = f.truncate(page.text)
+	case isText(mediaType) && utf8.Valid(trimPartialRune(body)):
+		res.Content, res.Truncated = f.truncate(string(trimPartialRune(body)))
+	default:
+		if oversized {
+			return result{}, fmt.Errorf("response of %q is larger than %d bytes", args.URL, f.cfg.MaxBytes)
+		}
+		if ctx.Artifacts() == nil {
+			return result{}, fmt.Errorf("response of %q is %s, which can't be saved without an artifact service", args.URL, mediaType)
+		}
+		name := artifactName(resp.Request.URL)
+		saved, err := ctx.Artifacts().Save(ctx, name, genai.NewPartFromBytes(body, mediaType))
+		if err != nil {
+			return result{}, fmt.Errorf("failed to save the response of %q as artifact %q: %w", args.URL, name, err)
+		}
+		res.Artifact, res.ArtifactVersion, res.Size = name, saved.Version, len(body)
+		return res, nil
+	}
+	res.Truncated = res.Truncated || oversized
+	return res, nil
+}
+
+// truncate truncates text to the MaxChars.
+func (f *fetcher) truncate(text string) (string, bool) {
+	n := 0
+	for i := range text {
+		if n == f.cfg.MaxChars {
+			return text[:i] + truncationMarker, true
+		}
+		n++
+	}
+	return text, false
+}
+
+// checkURL fails if the scheme or the host of u isn't allowed.
+func (f *fetcher) checkURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: scheme %q is not http or https", ErrBlocked, u.Scheme)
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "" {
+		return fmt.Errorf("%w: URL %q has no host", ErrBlocked, u.Redacted())
+	}
+	if len(f.cfg.AllowedHosts) == 0 {
+		return nil
+	}
+	for _, allowed := range f.cfg.AllowedHosts {
+		allowed = strings.ToLower(allowed)
+		if domain, ok := strings.CutPrefix(allowed, "*."); ok {
+			if host == domain || strings.HasSuffix(host, "."+domain) {
+				return nil
+			}
+		} else if host == allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: host %q is not allowed", ErrBlocked, host)
+}
+
+// transport returns the transport of the fetches, which checks the
+// addresses it connects to and never uses a proxy, which would connect to
+// the hosts on its behalf.
+func (f *fetcher) transport() *http.Transport {
+	base := f.cfg.Transport
+	if base == nil {
+		base = http.DefaultTransport.(*http.Transport)
+	}
+	t := base.Clone()
+	t.Proxy = nil
+	dialer := &net.Dialer{
+		Timeout: 30 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addr, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return fmt.Errorf("%w: invalid address %q: %v", ErrBlocked, address, err)
+			}
+			if !f.cfg.AllowPrivateNetworks && netinternal.IsPrivate(addr.Addr()) {
+				return fmt.Errorf("%w: address %s is not public", ErrBlocked, addr.Addr())
+			}
+			return nil
+		},
+	}
+	t.DialContext = dialer.DialContext
+	t.DialTLSContext = nil
+	return t
+}
+
+// isText reports whether the media type is text which can be returned as is.
+func isText(mediaType string) bool {
+	switch {
+	case strings.HasPrefix(mediaType, "text/"),
+		mediaType == "application/json",
+		mediaType == "application/xml",
+		mediaType == "application/javascript",
+		mediaType == "application/yaml",
+		strings.HasSuffix(mediaType, "+json"),
+		strings.HasSuffix(mediaType, "+xml"):
+		return true
+	}
+	return false
+}
+
+// trimPartialRune drops the incomplete rune a truncated body may end with.
+func trimPartialRune(data []byte) []byte {
+	for i := 1; i < utf8.UTFMax && i <= len(data); i++ {
+		if utf8.RuneStart(data[len(data)-i]) {
+			if !utf8.FullRune(data[len(data)-i:]) {
+				return data[:len(data)-i]
+			}
+			break
+		}
+	}
+	return data
+}
+
+// artifactName returns the name of the artifact the response of u is saved
+// to: the last element of its path, or its host.
+func artifactName(u *url.URL) string {
+	name := path.Base(u.Path)
+	if name == "/" || name == "." {
+		name = u.Hostname()
+	}
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, name)
+	return "fetched_" + name
+}
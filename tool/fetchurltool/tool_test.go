@@ -0,0 +1,208 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetchurltool_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"google.golang.org/adk/artifact"
+	artifactinternal "google.golang.org/adk/internal/artifact"
+	icontext "google.golang.org/adk/internal/context"
+	"google.golang.org/adk/internal/toolinternal"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/fetchurltool"
+)
+
+func TestFetchURL(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(`<title>Doc</title><h1>Hello</h1><p>See <a href="/other">other</a>.</p>`))
+	})
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/page", http.StatusFound)
+	})
+	mux.HandleFunc("/long.txt", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(strings.Repeat("é", 20)))
+	})
+	mux.HandleFunc("/report.pdf", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Write([]byte("%PDF-1.4"))
+	})
+	mux.HandleFunc("/escape", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "file:///etc/passwd", http.StatusFound)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		cfg     fetchurltool.Config
+		url     string
+		want    map[string]any
+		wantErr error
+	}{
+		{
+			name: "html page after redirect",
+			cfg:  fetchurltool.Config{AllowPrivateNetworks: true},
+			url:  server.URL + "/moved",
+			want: map[string]any{
+				"url":          server.URL + "/moved",
+				"final_url":    server.URL + "/page",
+				"status_code":  200.0,
+				"content_type": "text/html; charset=utf-8",
+				"title":        "Doc",
+				"content":      "# Hello\n\nSee [other](" + server.URL + "/other).",
+			},
+		},
+		{
+			name: "truncated text",
+			cfg:  fetchurltool.Config{AllowPrivateNetworks: true, MaxChars: 5},
+			url:  server.URL + "/long.txt",
+			want: map[string]any{
+				"url":          server.URL + "/long.txt",
+				"final_url":    server.URL + "/long.txt",
+				"status_code":  200.0,
+				"content_type": "text/plain",
+				"content":      "ééééé\n[… truncated]",
+				"truncated":    true,
+			},
+		},
+		{
+			name: "body cap",
+			cfg:  fetchurltool.Config{AllowPrivateNetworks: true, MaxBytes: 7},
+			url:  server.URL + "/long.txt",
+			want: map[string]any{
+				"url":          server.URL + "/long.txt",
+				"final_url":    server.URL + "/long.txt",
+				"status_code":  200.0,
+				"content_type": "text/plain",
+				"content":      "ééé",
+				"truncated":    true,
+			},
+		},
+		{
+			name: "binary saved as artifact",
+			cfg:  fetchurltool.Config{AllowPrivateNetworks: true},
+			url:  server.URL + "/report.pdf",
+			want: map[string]any{
+				"url":              server.URL + "/report.pdf",
+				"final_url":        server.URL + "/report.pdf",
+				"status_code":      200.0,
+				"content_type":     "application/pdf",
+				"artifact":         "fetched_report.pdf",
+				"artifact_version": 1.0,
+				"size":             8.0,
+			},
+		},
+		{
+			name:    "loopback address",
+			url:     server.URL + "/page",
+			wantErr: fetchurltool.ErrBlocked,
+		},
+		{
+			name:    "private address",
+			url:     "http://10.0.0.1/",
+			wantErr: fetchurltool.ErrBlocked,
+		},
+		{
+			name:    "shared address space",
+			url:     "http://100.64.0.1/",
+			wantErr: fetchurltool.ErrBlocked,
+		},
+		{
+			name:    "multicast address",
+			url:     "http://224.0.0.1/",
+			wantErr: fetchurltool.ErrBlocked,
+		},
+		{
+			name:    "link-local metadata address",
+			url:     "http://169.254.169.254/latest/meta-data",
+			wantErr: fetchurltool.ErrBlocked,
+		},
+		{
+			name:    "file scheme",
+			url:     "file:///etc/passwd",
+			wantErr: fetchurltool.ErrBlocked,
+		},
+		{
+			name:    "redirect to file scheme",
+			cfg:     fetchurltool.Config{AllowPrivateNetworks: true},
+			url:     server.URL + "/escape",
+			wantErr: fetchurltool.ErrBlocked,
+		},
+		{
+			name:    "host not allowed",
+			cfg:     fetchurltool.Config{AllowPrivateNetworks: true, AllowedHosts: []string{"*.example.com"}},
+			url:     server.URL + "/page",
+			wantErr: fetchurltool.ErrBlocked,
+		},
+		{
+			name: "host allowed",
+			cfg:  fetchurltool.Config{AllowPrivateNetworks: true, AllowedHosts: []string{"127.0.0.1"}},
+			url:  server.URL + "/report.pdf",
+			want: map[string]any{
+				"url":              server.URL + "/report.pdf",
+				"final_url":        server.URL + "/report.pdf",
+				"status_code":      200.0,
+				"content_type":     "application/pdf",
+				"artifact":         "fetched_report.pdf",
+				"artifact_version": 1.0,
+				"size":             8.0,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetchTool, err := fetchurltool.New(tt.cfg)
+			if err != nil {
+				t.Fatalf("New() failed: %v", err)
+			}
+			got, err := fetchTool.(toolinternal.FunctionTool).Run(createToolContext(t), map[string]any{"url": tt.url})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Run() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Run() failed: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Run() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func createToolContext(t *testing.T) tool.Context {
+	t.Helper()
+	ctx := icontext.NewInvocationContext(t.Context(), icontext.InvocationContextParams{
+		Artifacts: &artifactinternal.Artifacts{
+			Service:   artifact.InMemoryService(),
+			AppName:   "app",
+			UserID:    "user",
+			SessionID: "session",
+		},
+	})
+	return toolinternal.NewToolContext(ctx, "", nil, nil)
+}
@@ -0,0 +1,141 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package forgetmemorytool provides a tool that deletes memories of the
+// current user, e.g. when the user asks to be forgotten. The memory service
+// of the agent must implement [memory.Forgetter].
+package forgetmemorytool
+
+import (
+	"errors"
+	"fmt"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/internal/toolinternal"
+	"google.golang.org/adk/internal/toolinternal/toolutils"
+	"google.golang.org/adk/internal/utils"
+	"google.golang.org/adk/memory"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/tool"
+)
+
+const forgetInstructions = `You can forget memories. If the user asks you to forget something, or a memory
+is no longer true, call forget_memory with the text to forget, the IDs of the
+memories, or to forget the current conversation.`
+
+type forgetMemoryTool struct {
+	name        string
+	description string
+}
+
+// New creates a new forget_memory tool.
+func New() toolinternal.FunctionTool {
+	return &forgetMemoryTool{
+		name:        "forget_memory",
+		description: "Deletes memories of the current user which match all the given criteria.",
+	}
+}
+
+func (t *forgetMemoryTool) Name() string {
+	return t.name
+}
+
+func (t *forgetMemoryTool) Description() string {
+	return t.description
+}
+
+func (t *forgetMemoryTool) IsLongRunning() bool {
+	return false
+}
+
+func (t *forgetMemoryTool) Declaration() *genai.FunctionDeclaration {
+	return &genai.FunctionDeclaration{
+		Name:        t.name,
+		Description: t.description,
+		Parameters: &genai.Schema{
+			Type: "OBJECT",
+			Properties: map[string]*genai.Schema{
+				"text": {
+					Type:        "STRING",
+					Description: "Forgets the memories containing this text.",
+				},
+				"memory_ids": {
+					Type:        "ARRAY",
+					Items:       &genai.Schema{Type: "STRING"},
+					Description: "Forgets the memories of these IDs, as returned by load_memory.",
+				},
+				"current_session": {
+					Type:        "BOOLEAN",
+					Description: "Forgets the memories of the current conversation.",
+				},
+			},
+		},
+	}
+}
+
+func (t *forgetMemoryTool) Run(toolCtx tool.Context, args any) (map[string]any, error) {
+	m, ok := args.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("unexpected args type, got: %T", args)
+	}
+
+	req := &memory.ForgetRequest{}
+	if raw, exists := m["text"]; exists {
+		text, ok := raw.(string)
+		if !ok {
+			return nil, fmt.Errorf("text must be a string, got: %T", raw)
+		}
+		req.Text = text
+	}
+	if raw, exists := m["memory_ids"]; exists {
+		ids, ok := raw.([]any)
+		if !ok {
+			return nil, fmt.Errorf("memory_ids must be an array, got: %T", raw)
+		}
+		for _, idRaw := range ids {
+			id, ok := idRaw.(string)
+			if !ok {
+				return nil, fmt.Errorf("memory_ids must be strings, got: %T", idRaw)
+			}
+			req.IDs = append(req.IDs, id)
+		}
+	}
+	if raw, exists := m["current_session"]; exists {
+		current, ok := raw.(bool)
+		if !ok {
+			return nil, fmt.Errorf("current_session must be a boolean, got: %T", raw)
+		}
+		if current {
+			req.SessionID = toolCtx.SessionID()
+		}
+	}
+
+	resp, err := toolCtx.ForgetMemory(toolCtx, req)
+	if errors.Is(err, memory.ErrNoForgetCriteria) {
+		return nil, fmt.Errorf("nothing to forget: set text, memory_ids or current_session")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to forget memory: %w", err)
+	}
+	return map[string]any{"forgotten": resp.Forgotten}, nil
+}
+
+func (t *forgetMemoryTool) ProcessRequest(ctx tool.Context, req *model.LLMRequest) error {
+	if err := toolutils.PackTool(req, t); err != nil {
+		return err
+	}
+	utils.AppendInstructions(req, forgetInstructions)
+	return nil
+}
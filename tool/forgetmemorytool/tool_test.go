@@ -0,0 +1,250 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forgetmemorytool_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	icontext "google.golang.org/adk/internal/context"
+	imemory "google.golang.org/adk/internal/memory"
+	"google.golang.org/adk/internal/sessioninternal"
+	"google.golang.org/adk/internal/toolinternal"
+	"google.golang.org/adk/memory"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/forgetmemorytool"
+)
+
+func TestForgetMemoryTool_BasicProperties(t *testing.T) {
+	tool := forgetmemorytool.New()
+
+	if got := tool.Name(); got != "forget_memory" {
+		t.Errorf("Name() = %v, want forget_memory", got)
+	}
+	if got := tool.IsLongRunning(); got != false {
+		t.Errorf("IsLongRunning() = %v, want false", got)
+	}
+}
+
+func TestForgetMemoryTool_Run(t *testing.T) {
+	tests := []struct {
+		name string
+		args map[string]any
+		// wantForgotten is the number of forgotten memories.
+		wantForgotten int
+		// wantKept are the texts of the memories kept.
+		wantKept []string
+		wantErr  bool
+	}{
+		{
+			name:          "text",
+			args:          map[string]any{"text": "flight"},
+			wantForgotten: 2,
+			wantKept:      []string{"hotel in paris"},
+		},
+		{
+			name:          "memory ids",
+			args:          map[string]any{"memory_ids": []any{"sess1/e1", "sess2/e3"}},
+			wantForgotten: 2,
+			wantKept:      []string{"hotel in paris"},
+		},
+		{
+			name:          "current session",
+			args:          map[string]any{"current_session": true},
+			wantForgotten: 2,
+			wantKept:      []string{"flight to rome"},
+		},
+		{
+			name:          "all criteria",
+			args:          map[string]any{"current_session": true, "text": "paris"},
+			wantForgotten: 1,
+			wantKept:      []string{"flight to rome", "flight tomorrow"},
+		},
+		{
+			name:    "no criteria",
+			args:    map[string]any{"current_session": false},
+			wantErr: true,
+		},
+		{
+			name:    "invalid text type",
+			args:    map[string]any{"text": 123},
+			wantErr: true,
+		},
+		{
+			name:    "invalid memory ids type",
+			args:    map[string]any{"memory_ids": "sess1/e1"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid memory id type",
+			args:    map[string]any{"memory_ids": []any{1}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid current session type",
+			args:    map[string]any{"current_session": "yes"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mem := memory.InMemoryService()
+			tc := createToolContext(t, mem)
+
+			result, err := forgetmemorytool.New().Run(tc, tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := result["forgotten"]; got != tt.wantForgotten {
+				t.Errorf("Run() forgotten = %v, want %d", got, tt.wantForgotten)
+			}
+
+			res, err := mem.Search(t.Context(), &memory.SearchRequest{AppName: "app", UserID: "user", Query: "flight hotel"})
+			if err != nil {
+				t.Fatalf("Search() failed: %v", err)
+			}
+			var kept []string
+			for _, m := range res.Memories {
+				kept = append(kept, m.Content.Parts[0].Text)
+			}
+			slices.Sort(kept)
+			if diff := cmp.Diff(tt.wantKept, kept); diff != "" {
+				t.Errorf("kept memories mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestForgetMemoryTool_Run_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		memory  agent.Memory
+		wantErr error
+	}{
+		{
+			name:    "no memory service",
+			wantErr: tool.ErrMemoryNotSet,
+		},
+		{
+			name:    "memory service can't forget",
+			memory:  searchOnlyMemory{},
+			wantErr: memory.ErrForgetUnsupported,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := icontext.NewInvocationContext(t.Context(), icontext.InvocationContextParams{Memory: tt.memory})
+			tc := toolinternal.NewToolContext(ctx, "", nil, nil)
+
+			_, err := forgetmemorytool.New().Run(tc, map[string]any{"text": "flight"})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Run() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestForgetMemoryTool_ProcessRequest(t *testing.T) {
+	tool := forgetmemorytool.New()
+	tc := createToolContext(t, memory.InMemoryService())
+	llmRequest := &model.LLMRequest{}
+
+	requestProcessor, ok := tool.(toolinternal.RequestProcessor)
+	if !ok {
+		t.Fatal("forgetMemoryTool does not implement RequestProcessor")
+	}
+	if err := requestProcessor.ProcessRequest(tc, llmRequest); err != nil {
+		t.Fatalf("ProcessRequest failed: %v", err)
+	}
+
+	if llmRequest.Config == nil || llmRequest.Config.SystemInstruction == nil {
+		t.Fatal("ProcessRequest did not set SystemInstruction")
+	}
+	if instruction := llmRequest.Config.SystemInstruction.Parts[0].Text; !strings.Contains(instruction, "forget_memory") {
+		t.Errorf("Instruction should contain 'forget_memory', got: %v", instruction)
+	}
+	if _, ok := llmRequest.Tools["forget_memory"]; !ok {
+		t.Errorf("ProcessRequest did not add the tool, got: %v", llmRequest.Tools)
+	}
+}
+
+// createToolContext returns the context of a tool called in the session
+// sess1 of the user, whose memories are those of sess1 and sess2.
+func createToolContext(t *testing.T, mem memory.Service) tool.Context {
+	t.Helper()
+
+	sessions := session.InMemoryService()
+	var current session.Session
+	for _, s := range []struct {
+		id     string
+		events map[string]string
+	}{
+		{id: "sess1", events: map[string]string{"e1": "flight tomorrow", "e2": "hotel in paris"}},
+		{id: "sess2", events: map[string]string{"e3": "flight to rome"}},
+	} {
+		created, err := sessions.Create(t.Context(), &session.CreateRequest{AppName: "app", UserID: "user", SessionID: s.id})
+		if err != nil {
+			t.Fatalf("Failed to create session: %v", err)
+		}
+		for id, text := range s.events {
+			if err := sessions.AppendEvent(t.Context(), created.Session, &session.Event{
+				ID:          id,
+				Author:      "user",
+				Timestamp:   time.Now(),
+				LLMResponse: model.LLMResponse{Content: genai.NewContentFromText(text, genai.RoleUser)},
+			}); err != nil {
+				t.Fatalf("Failed to append event: %v", err)
+			}
+		}
+		if err := mem.AddSession(t.Context(), sessioninternal.NewMutableSession(sessions, created.Session)); err != nil {
+			t.Fatalf("AddSession failed: %v", err)
+		}
+		if current == nil {
+			current = created.Session
+		}
+	}
+
+	ctx := icontext.NewInvocationContext(t.Context(), icontext.InvocationContextParams{
+		Session: current,
+		Memory:  &imemory.Memory{Service: mem, SessionID: "sess1", AppName: "app", UserID: "user"},
+	})
+	return toolinternal.NewToolContext(ctx, "", nil, nil)
+}
+
+// searchOnlyMemory is a memory which can't forget.
+type searchOnlyMemory struct{}
+
+func (searchOnlyMemory) AddSession(ctx context.Context, s session.Session) error {
+	return nil
+}
+
+func (searchOnlyMemory) Search(ctx context.Context, query string) (*memory.SearchResponse, error) {
+	return &memory.SearchResponse{}, nil
+}
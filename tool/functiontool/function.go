@@ -20,6 +20,8 @@ import (
 	"fmt"
 	"reflect"
 	"runtime/debug"
+	"slices"
+	"time"
 
 	"github.com/google/jsonschema-go/jsonschema"
 	"google.golang.org/genai"
@@ -28,6 +30,7 @@ import (
 	"google.golang.org/adk/internal/typeutil"
 	"google.golang.org/adk/model"
 	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/toolconfirmation"
 )
 
 // FunctionTool: borrow implementation from MCP go.
@@ -46,6 +49,21 @@ type Config struct {
 	OutputSchema *jsonschema.Schema
 	// IsLongRunning makes a FunctionTool a long-running operation.
 	IsLongRunning bool
+	// Timeout bounds the duration of the calls of the tool, overriding the
+	// ToolTimeout of the agent. Zero means the timeout of the agent is used,
+	// and a negative duration no timeout. See [tool.TimeoutTool].
+	Timeout time.Duration
+	// Sequential makes the calls of the tool run alone, rather than
+	// concurrently with the other function calls of a model response, e.g.
+	// for handlers which aren't safe for concurrent use. See
+	// [tool.ParallelSafeTool].
+	Sequential bool
+	// CacheTTL makes the results of the successful calls of the tool cached
+	// for the duration, when the agent has a tool cache: the calls with the
+	// same arguments are then served from the cache. Only the deterministic
+	// handlers without side effects should be cached. See
+	// [tool.CacheableTool].
+	CacheTTL time.Duration
 
 	// RequireConfirmation flags whether this tool must always ask for user confirmation
 	// before execution. If set to true, the ADK framework will automatically initiate
@@ -74,7 +92,16 @@ type Func[TArgs, TResults any] func(tool.Context, TArgs) (TResults, error)
 var ErrInvalidArgument = errors.New("invalid argument")
 
 // New creates a new tool with a name, description, and the provided handler.
-// Input schema is automatically inferred from the input and output types.
+// Input schema is automatically inferred from the input and output types:
+// the properties of the structs are named by their json tags and described by
+// their jsonschema tags, which can also list the allowed values of the
+// properties and make them required or optional, e.g.
+//
+//	Unit string `json:"unit,omitempty" jsonschema:"description=unit of the temperature,enum=celsius,enum=fahrenheit"`
+//
+// Pointers and the fields omitted if empty are optional. The types which
+// can't be encoded as JSON, e.g. channels and functions, fail with the path
+// of the field.
 func New[TArgs, TResults any](cfg Config, handler Func[TArgs, TResults]) (tool.Tool, error) {
 	// TODO: How can we improve UX for functions that does not require an argument, returns a simple type value, or returns a no result?
 	// https://github.com/modelcontextprotocol/go-sdk/discussions/37
@@ -133,6 +160,34 @@ type functionTool[TArgs, TResults any] struct {
 	requireConfirmation bool
 
 	requireConfirmationProvider func(TArgs) bool
+
+	// pending reports whether the responses of the tool are pending, see
+	// [NewLongRunning].
+	pending bool
+}
+
+// PendingStatus is the "status" of the responses of the tools created with
+// [NewLongRunning].
+const PendingStatus = "pending"
+
+// NewLongRunning creates a long-running tool, whose handler starts an
+// operation completing after the invocation, e.g. a job, and returns its
+// initial result, to which the tool adds a "status" of [PendingStatus].
+//
+// The invocation ends after the calls of the tool, without a final answer:
+// the function response events of the calls list them in their
+// LongRunningToolIDs, until their results are submitted. The final result
+// of a call is submitted as a function response of the user with the ID of
+// the call, e.g. with the submitToolResult endpoint of the REST server, which
+// resumes the flow: the model is given the result to produce its answer.
+func NewLongRunning[TArgs, TResults any](cfg Config, handler Func[TArgs, TResults]) (tool.Tool, error) {
+	cfg.IsLongRunning = true
+	t, err := New(cfg, handler)
+	if err != nil {
+		return nil, err
+	}
+	t.(*functionTool[TArgs, TResults]).pending = true
+	return t, nil
 }
 
 // Description implements tool.Tool.
@@ -150,6 +205,26 @@ func (f *functionTool[TArgs, TResults]) IsLongRunning() bool {
 	return f.cfg.IsLongRunning
 }
 
+// Timeout implements tool.TimeoutTool.
+func (f *functionTool[TArgs, TResults]) Timeout() time.Duration {
+	return f.cfg.Timeout
+}
+
+// ParallelSafe implements tool.ParallelSafeTool.
+func (f *functionTool[TArgs, TResults]) ParallelSafe() bool {
+	return !f.cfg.Sequential
+}
+
+// CacheTTL implements tool.CacheableTool.
+func (f *functionTool[TArgs, TResults]) CacheTTL() time.Duration {
+	return f.cfg.CacheTTL
+}
+
+// PausesInvocation implements toolinternal.PendingTool.
+func (f *functionTool[TArgs, TResults]) PausesInvocation() bool {
+	return f.pending
+}
+
 // ProcessRequest packs the function tool's declaration into the LLM request.
 func (f *functionTool[TArgs, TResults]) ProcessRequest(ctx tool.Context, req *model.LLMRequest) error {
 	return toolutils.PackTool(req, f)
@@ -165,7 +240,7 @@ func (f *functionTool[TArgs, TResults]) Declaration() *genai.FunctionDeclaration
 		decl.ParametersJsonSchema = f.inputSchema.Schema()
 	}
 	if f.outputSchema != nil {
-		decl.ResponseJsonSchema = f.outputSchema.Schema()
+		decl.ResponseJsonSchema = responseSchema(f.outputSchema.Schema())
 	}
 
 	if f.cfg.IsLongRunning {
@@ -180,6 +255,20 @@ func (f *functionTool[TArgs, TResults]) Declaration() *genai.FunctionDeclaration
 	return decl
 }
 
+// responseSchema returns the schema of the responses of the function whose
+// results have the schema: the results which aren't objects are wrapped in
+// the "result" property of the responses, see [functionTool.Run].
+func responseSchema(results *jsonschema.Schema) *jsonschema.Schema {
+	if results.Type == "object" || slices.Contains(results.Types, "object") || results.Type == "" && results.Types == nil {
+		return results
+	}
+	return &jsonschema.Schema{
+		Type:       "object",
+		Properties: map[string]*jsonschema.Schema{"result": results},
+		Required:   []string{"result"},
+	}
+}
+
 // Run executes the tool with the provided context and yields events.
 func (f *functionTool[TArgs, TResults]) Run(ctx tool.Context, args any) (result map[string]any, err error) {
 	// TODO: Handle function call request from tc.InvocationContext.
@@ -200,7 +289,7 @@ func (f *functionTool[TArgs, TResults]) Run(ctx tool.Context, args any) (result
 
 	if confirmation := ctx.ToolConfirmation(); confirmation != nil {
 		if !confirmation.Confirmed {
-			return nil, fmt.Errorf("error tool %q call is rejected", f.Name())
+			return toolconfirmation.Rejection(f.Name(), confirmation), nil
 		}
 	} else {
 		requireConfirmation := f.requireConfirmation
@@ -227,6 +316,22 @@ func (f *functionTool[TArgs, TResults]) Run(ctx tool.Context, args any) (result
 	if err != nil {
 		return nil, err
 	}
+	resp, err := f.convertOutput(output)
+	if err != nil {
+		return resp, err
+	}
+	if f.pending {
+		if resp == nil {
+			resp = map[string]any{}
+		}
+		if _, ok := resp["status"]; !ok {
+			resp["status"] = PendingStatus
+		}
+	}
+	return resp, nil
+}
+
+func (f *functionTool[TArgs, TResults]) convertOutput(output TResults) (map[string]any, error) {
 	resp, err := typeutil.ConvertToWithJSONSchema[TResults, map[string]any](output, f.outputSchema)
 	if err == nil { // all good
 		return resp, nil
@@ -268,7 +373,7 @@ func resolvedSchema[T any](override *jsonschema.Schema) (*jsonschema.Resolved, e
 	if override != nil {
 		return override.Resolve(nil)
 	}
-	schema, err := jsonschema.For[T](nil)
+	schema, err := inferSchema(reflect.TypeFor[T]())
 	if err != nil {
 		return nil, err
 	}
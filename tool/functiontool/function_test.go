@@ -646,7 +646,8 @@ func TestToolConfirmation(t *testing.T) {
 					"error": errors.New("error tool \"test_tool\" requires confirmation, please approve or reject"),
 				}, "user"),
 				genai.NewContentFromFunctionResponse("test_tool", map[string]any{
-					"error": errors.New("error tool \"test_tool\" call is rejected"),
+					"status": "rejected",
+					"error":  "the user rejected the call of tool \"test_tool\"",
 				}, "user"),
 			},
 		},
@@ -741,7 +742,8 @@ func TestToolConfirmation(t *testing.T) {
 					"error": errors.New("error tool \"test_tool\" requires confirmation, please approve or reject"),
 				}, "user"),
 				genai.NewContentFromFunctionResponse("test_tool", map[string]any{
-					"error": errors.New("error tool \"test_tool\" call is rejected"),
+					"status": "rejected",
+					"error":  "the user rejected the call of tool \"test_tool\"",
 				}, "user"),
 			},
 		},
@@ -323,3 +323,71 @@ func TestLongRunningToolIDsAreSet(t *testing.T) {
 			functionCallEvent.LLMResponse.Content.Parts[0].FunctionCall.ID)
 	}
 }
+
+func TestNewLongRunning(t *testing.T) {
+	mockModel := &testutil.MockModel{Responses: []*genai.Content{
+		genai.NewContentFromFunctionCall("render", map[string]any{}, "model"),
+		genai.NewContentFromText("rendered", "model"),
+	}}
+	render, err := functiontool.NewLongRunning(functiontool.Config{
+		Name:        "render",
+		Description: "renders a video",
+	}, func(ctx tool.Context, x IncArgs) (map[string]string, error) {
+		return map[string]string{"job": "job-1"}, nil
+	})
+	if err != nil {
+		t.Fatalf("failed to create long-running tool: %v", err)
+	}
+	if !render.IsLongRunning() {
+		t.Errorf("IsLongRunning() = false, want true")
+	}
+	a, err := llmagent.New(llmagent.Config{
+		Name:  "render_agent",
+		Model: mockModel,
+		Tools: []tool.Tool{render},
+	})
+	if err != nil {
+		t.Fatalf("failed to create llm agent: %v", err)
+	}
+	runner := testutil.NewTestAgentRunner(t, a)
+
+	events, err := testutil.CollectEvents(runner.Run(t, "test_session", "render"))
+	if err != nil {
+		t.Fatalf("failed to collect events: %v", err)
+	}
+	// The invocation ends with the pending response, without calling the
+	// model again.
+	if len(events) != 2 || len(mockModel.Requests) != 1 {
+		eventsJSON, _ := json.MarshalIndent(events, "", "  ")
+		t.Fatalf("got %d events and %d requests, want 2 and 1;\n- events:\n%s", len(events), len(mockModel.Requests), eventsJSON)
+	}
+	callID := events[0].Content.Parts[0].FunctionCall.ID
+	if diff := cmp.Diff([]string{callID}, events[1].LongRunningToolIDs); diff != "" {
+		t.Errorf("LongRunningToolIDs of the function response event mismatch (-want +got):\n%s", diff)
+	}
+	wantResponse := map[string]any{"job": "job-1", "status": functiontool.PendingStatus}
+	if diff := cmp.Diff(wantResponse, events[1].Content.Parts[0].FunctionResponse.Response); diff != "" {
+		t.Errorf("pending response mismatch (-want +got):\n%s", diff)
+	}
+	if !events[1].IsFinalResponse() {
+		t.Errorf("IsFinalResponse() of the pending response = false, want true")
+	}
+
+	// Submitting the result resumes the flow.
+	result := NewContentFromFunctionResponseWithID("render", map[string]any{"url": "gs://videos/1"}, callID, "user")
+	parts, err := testutil.CollectParts(runner.RunContent(t, "test_session", result))
+	if err != nil {
+		t.Fatalf("failed to collect events: %v", err)
+	}
+	if diff := cmp.Diff([]*genai.Part{genai.NewPartFromText("rendered")}, parts); diff != "" {
+		t.Errorf("event parts mismatch (-want +got):\n%s", diff)
+	}
+	wantContents := []*genai.Content{
+		genai.NewContentFromText("render", "user"),
+		genai.NewContentFromFunctionCall("render", map[string]any{}, "model"),
+		genai.NewContentFromFunctionResponse("render", map[string]any{"url": "gs://videos/1"}, "user"),
+	}
+	if diff := cmp.Diff(wantContents, mockModel.Requests[1].Contents); diff != "" {
+		t.Errorf("LLMRequest.Contents mismatch (-want +got):\n%s", diff)
+	}
+}
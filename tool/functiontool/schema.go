@@ -0,0 +1,291 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package functiontool
+
+import (
+	"fmt"
+	"log/slog"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// stringTypes are the types, other than strings, whose values are encoded as
+// JSON strings.
+var stringTypes = map[reflect.Type]bool{
+	reflect.TypeFor[time.Time]():  true,
+	reflect.TypeFor[slog.Level](): true,
+	reflect.TypeFor[big.Int]():    true,
+	reflect.TypeFor[big.Rat]():    true,
+	reflect.TypeFor[big.Float]():  true,
+}
+
+// inferSchema returns the JSON schema of the values of the type, e.g. of the
+// arguments or results of a function:
+//   - the properties of the structs are named by their json tags, and
+//     described by their jsonschema tags, see [parseSchemaTag];
+//   - the properties are required, unless their json tag has omitempty or
+//     omitzero, or they are pointers, which can also be null;
+//   - slices and arrays are arrays, and maps with string keys are objects.
+//
+// The types which can't be encoded as JSON, e.g. channels and functions, fail
+// with the path of the field of the type.
+func inferSchema(t reflect.Type) (*jsonschema.Schema, error) {
+	return (&schemaInferrer{seen: map[reflect.Type]bool{}}).schema(t, t.String())
+}
+
+type schemaInferrer struct {
+	// seen are the named types being inferred, to fail on recursive types.
+	seen map[reflect.Type]bool
+}
+
+func (i *schemaInferrer) schema(t reflect.Type, path string) (*jsonschema.Schema, error) {
+	nullable := false
+	for t.Kind() == reflect.Pointer {
+		nullable = true
+		t = t.Elem()
+	}
+	if t.Name() != "" {
+		if i.seen[t] {
+			return nil, fmt.Errorf("%s: recursive type %v is not supported", path, t)
+		}
+		i.seen[t] = true
+		defer delete(i.seen, t)
+	}
+
+	s := &jsonschema.Schema{}
+	switch {
+	case stringTypes[t]:
+		s.Type = "string"
+	case t.Kind() == reflect.Bool:
+		s.Type = "boolean"
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uintptr:
+		s.Type = "integer"
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		s.Type = "number"
+	case t.Kind() == reflect.String:
+		s.Type = "string"
+	case t.Kind() == reflect.Interface:
+		// Any value.
+	case t.Kind() == reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("%s: map key type %v is not supported, the keys must be strings", path, t.Key())
+		}
+		items, err := i.schema(t.Elem(), path+"[]")
+		if err != nil {
+			return nil, err
+		}
+		s.Type = "object"
+		s.AdditionalProperties = items
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		items, err := i.schema(t.Elem(), path+"[]")
+		if err != nil {
+			return nil, err
+		}
+		s.Type = "array"
+		s.Items = items
+		if t.Kind() == reflect.Array {
+			s.MinItems = jsonschema.Ptr(t.Len())
+			s.MaxItems = jsonschema.Ptr(t.Len())
+		}
+	case t.Kind() == reflect.Struct:
+		if err := i.structSchema(s, t, path); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%s: type %v is not supported", path, t)
+	}
+	if nullable && s.Type != "" {
+		s.Types = []string{"null", s.Type}
+		s.Type = ""
+	}
+	return s, nil
+}
+
+func (i *schemaInferrer) structSchema(s *jsonschema.Schema, t reflect.Type, path string) error {
+	s.Type = "object"
+	s.AdditionalProperties = &jsonschema.Schema{Not: &jsonschema.Schema{}}
+	for _, field := range reflect.VisibleFields(t) {
+		if field.Anonymous || !field.IsExported() {
+			continue
+		}
+		name, optional, omit := jsonField(field)
+		if omit {
+			continue
+		}
+		fieldPath := path + "." + field.Name
+		fs, err := i.schema(field.Type, fieldPath)
+		if err != nil {
+			return err
+		}
+		if tag, ok := field.Tag.Lookup("jsonschema"); ok {
+			required, err := parseSchemaTag(fs, tag)
+			if err != nil {
+				return fmt.Errorf("%s: invalid jsonschema tag: %w", fieldPath, err)
+			}
+			if required != nil {
+				optional = !*required
+			}
+		}
+		if s.Properties == nil {
+			s.Properties = make(map[string]*jsonschema.Schema)
+		}
+		s.Properties[name] = fs
+		if !optional {
+			s.Required = append(s.Required, name)
+		}
+	}
+	return nil
+}
+
+// jsonField returns the JSON name of the field, whether it is optional,
+// i.e. a pointer or omitted if empty, and whether it is not encoded.
+func jsonField(field reflect.StructField) (name string, optional, omit bool) {
+	name = field.Name
+	optional = field.Type.Kind() == reflect.Pointer
+	tag, ok := field.Tag.Lookup("json")
+	if !ok {
+		return name, optional, false
+	}
+	tagName, options, _ := strings.Cut(tag, ",")
+	if tag == "-" {
+		return "", false, true
+	}
+	if tagName != "" {
+		name = tagName
+	}
+	for _, option := range strings.Split(options, ",") {
+		if option == "omitempty" || option == "omitzero" {
+			optional = true
+		}
+	}
+	return name, optional, false
+}
+
+// schemaTagKeys are the keys of the jsonschema tags.
+var schemaTagKeys = []string{"description=", "enum=", "format=", "minimum=", "maximum=", "required", "optional"}
+
+// parseSchemaTag applies the jsonschema tag of a field to its schema, and
+// returns whether the tag makes the field required or optional, if it does.
+//
+// The tag is either the description of the field, as for
+// github.com/google/jsonschema-go, or a comma-separated list of:
+//   - description=TEXT, which can have commas;
+//   - enum=VALUE, once per allowed value;
+//   - format=FORMAT, e.g. date-time;
+//   - minimum=NUMBER and maximum=NUMBER;
+//   - required or optional, overriding the json tag.
+//
+// For example:
+//
+//	Unit string `json:"unit,omitempty" jsonschema:"description=unit of the temperature,enum=celsius,enum=fahrenheit"`
+func parseSchemaTag(s *jsonschema.Schema, tag string) (required *bool, err error) {
+	if tag == "" {
+		return nil, fmt.Errorf("empty tag")
+	}
+	if first, _, _ := strings.Cut(tag, ","); !hasSchemaTagKey(first) {
+		s.Description = tag
+		return nil, nil
+	}
+	// Split the tag at the commas followed by a key, so that the
+	// descriptions can have commas.
+	var items []string
+	for _, item := range strings.Split(tag, ",") {
+		if !hasSchemaTagKey(item) {
+			if len(items) == 0 || !strings.HasPrefix(items[len(items)-1], "description=") {
+				return nil, fmt.Errorf("unknown item %q, want one of %s", item, strings.Join(schemaTagKeys, " "))
+			}
+			items[len(items)-1] += "," + item
+			continue
+		}
+		items = append(items, item)
+	}
+	for _, item := range items {
+		key, value, _ := strings.Cut(item, "=")
+		switch key {
+		case "description":
+			s.Description = value
+		case "enum":
+			v, err := enumValue(s, value)
+			if err != nil {
+				return nil, err
+			}
+			s.Enum = append(s.Enum, v)
+		case "format":
+			s.Format = value
+		case "minimum", "maximum":
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s %q", key, value)
+			}
+			if key == "minimum" {
+				s.Minimum = &f
+			} else {
+				s.Maximum = &f
+			}
+		case "required", "optional":
+			r := key == "required"
+			required = &r
+		default:
+			return nil, fmt.Errorf("unknown key %q, want one of %s", key, strings.Join(schemaTagKeys, " "))
+		}
+	}
+	return required, nil
+}
+
+func hasSchemaTagKey(item string) bool {
+	for _, key := range schemaTagKeys {
+		if item == key || strings.HasSuffix(key, "=") && strings.HasPrefix(item, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// enumValue returns the value of an enum of the schema, of its type.
+func enumValue(s *jsonschema.Schema, value string) (any, error) {
+	t := s.Type
+	if t == "" && len(s.Types) > 0 {
+		t = s.Types[len(s.Types)-1]
+	}
+	switch t {
+	case "integer":
+		v, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer enum value %q", value)
+		}
+		return v, nil
+	case "number":
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number enum value %q", value)
+		}
+		return v, nil
+	case "boolean":
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid boolean enum value %q", value)
+		}
+		return v, nil
+	case "string":
+		return value, nil
+	default:
+		return nil, fmt.Errorf("enum values are not supported for type %q", t)
+	}
+}
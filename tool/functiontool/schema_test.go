@@ -0,0 +1,208 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package functiontool
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+type address struct {
+	Street string `json:"street" jsonschema:"the street, with the number"`
+	City   string `json:"city"`
+}
+
+type Person struct {
+	Name     string            `json:"name" jsonschema:"description=full name, as on the ID"`
+	Age      int               `json:"age,omitempty" jsonschema:"minimum=0,maximum=150"`
+	Unit     string            `json:"unit" jsonschema:"enum=metric,enum=imperial,optional"`
+	Level    int               `json:"level,omitempty" jsonschema:"enum=1,enum=2,required"`
+	Nickname *string           `json:"nickname"`
+	Born     time.Time         `json:"born" jsonschema:"format=date-time"`
+	Address  address           `json:"address"`
+	Previous []*address        `json:"previous,omitempty"`
+	Tags     map[string]string `json:"tags,omitempty"`
+	Extra    any               `json:"extra,omitempty"`
+	Ignored  string            `json:"-"`
+	internal string
+}
+
+func TestInferSchema(t *testing.T) {
+	falseSchema := &jsonschema.Schema{Not: &jsonschema.Schema{}}
+	addressSchema := &jsonschema.Schema{
+		Type:                 "object",
+		AdditionalProperties: falseSchema,
+		Properties: map[string]*jsonschema.Schema{
+			"street": {Type: "string", Description: "the street, with the number"},
+			"city":   {Type: "string"},
+		},
+		Required: []string{"street", "city"},
+	}
+	nullableAddress := *addressSchema
+	nullableAddress.Type, nullableAddress.Types = "", []string{"null", "object"}
+
+	tests := []struct {
+		name string
+		typ  reflect.Type
+		want *jsonschema.Schema
+	}{
+		{
+			name: "struct",
+			typ:  reflect.TypeFor[Person](),
+			want: &jsonschema.Schema{
+				Type:                 "object",
+				AdditionalProperties: falseSchema,
+				Properties: map[string]*jsonschema.Schema{
+					"name":     {Type: "string", Description: "full name, as on the ID"},
+					"age":      {Type: "integer", Minimum: jsonschema.Ptr(0.0), Maximum: jsonschema.Ptr(150.0)},
+					"unit":     {Type: "string", Enum: []any{"metric", "imperial"}},
+					"level":    {Type: "integer", Enum: []any{int64(1), int64(2)}},
+					"nickname": {Types: []string{"null", "string"}},
+					"born":     {Type: "string", Format: "date-time"},
+					"address":  addressSchema,
+					"previous": {Type: "array", Items: &nullableAddress},
+					"tags":     {Type: "object", AdditionalProperties: &jsonschema.Schema{Type: "string"}},
+					"extra":    {},
+				},
+				Required: []string{"name", "level", "born", "address"},
+			},
+		},
+		{
+			name: "pointer",
+			typ:  reflect.TypeFor[*address](),
+			want: &nullableAddress,
+		},
+		{
+			name: "array",
+			typ:  reflect.TypeFor[[2]float64](),
+			want: &jsonschema.Schema{Type: "array", Items: &jsonschema.Schema{Type: "number"}, MinItems: jsonschema.Ptr(2), MaxItems: jsonschema.Ptr(2)},
+		},
+		{
+			name: "string",
+			typ:  reflect.TypeFor[string](),
+			want: &jsonschema.Schema{Type: "string"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := inferSchema(tt.typ)
+			if err != nil {
+				t.Fatalf("inferSchema() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("inferSchema() mismatch (-want +got):\n%s", diff)
+			}
+			if _, err := got.Resolve(nil); err != nil {
+				t.Errorf("Resolve() error = %v", err)
+			}
+		})
+	}
+}
+
+type node struct {
+	Children []node `json:"children"`
+}
+
+func TestInferSchema_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		typ  reflect.Type
+		want string
+	}{
+		{
+			name: "channel",
+			typ: reflect.TypeFor[struct {
+				Updates chan int `json:"updates"`
+			}](),
+			want: ".Updates: type chan int is not supported",
+		},
+		{
+			name: "nested func",
+			typ: reflect.TypeFor[struct {
+				Options struct {
+					Callback func() `json:"callback"`
+				} `json:"options"`
+			}](),
+			want: ".Options.Callback: type func() is not supported",
+		},
+		{
+			name: "map key",
+			typ: reflect.TypeFor[struct {
+				Counts map[int]int `json:"counts"`
+			}](),
+			want: ".Counts: map key type int is not supported",
+		},
+		{
+			name: "recursive",
+			typ:  reflect.TypeFor[node](),
+			want: "functiontool.node.Children[]: recursive type functiontool.node is not supported",
+		},
+		{
+			name: "enum value",
+			typ: reflect.TypeFor[struct {
+				Level int `json:"level" jsonschema:"enum=high"`
+			}](),
+			want: `.Level: invalid jsonschema tag: invalid integer enum value "high"`,
+		},
+		{
+			name: "unknown item",
+			typ: reflect.TypeFor[struct {
+				Level int `json:"level" jsonschema:"required,default=1"`
+			}](),
+			want: `.Level: invalid jsonschema tag: unknown item "default=1"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := inferSchema(tt.typ)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("inferSchema() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestResponseSchema(t *testing.T) {
+	object := &jsonschema.Schema{Type: "object"}
+	tests := []struct {
+		name    string
+		results *jsonschema.Schema
+		want    *jsonschema.Schema
+	}{
+		{name: "object", results: object, want: object},
+		{name: "any", results: &jsonschema.Schema{}, want: &jsonschema.Schema{}},
+		{
+			name:    "string",
+			results: &jsonschema.Schema{Type: "string"},
+			want: &jsonschema.Schema{
+				Type:       "object",
+				Properties: map[string]*jsonschema.Schema{"result": {Type: "string"}},
+				Required:   []string{"result"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, responseSchema(tt.results)); diff != "" {
+				t.Errorf("responseSchema() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
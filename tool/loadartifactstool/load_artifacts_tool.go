@@ -20,29 +20,63 @@ package loadartifactstool
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"time"
 
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/genai"
 
 	"google.golang.org/adk/agent"
+	"google.golang.org/adk/artifact"
 	"google.golang.org/adk/internal/toolinternal/toolutils"
 	"google.golang.org/adk/internal/utils"
 	"google.golang.org/adk/model"
 	"google.golang.org/adk/tool"
 )
 
+// MaxInlineSize is the size in bytes of the largest artifact whose content is
+// added to the requests. The content of the larger ones is replaced by a
+// note.
+const MaxInlineSize = 20 << 20
+
+// DefaultSignedURLTTL is the default of [Config.SignedURLTTL].
+const DefaultSignedURLTTL = time.Hour
+
+// Config is the configuration of the tool created by [NewWithConfig].
+type Config struct {
+	// SignedURLs makes the tool give the model time-limited URLs downloading
+	// the artifacts instead of their content, so that it can pass links to
+	// the users. The content is still added if the artifact service doesn't
+	// implement [artifact.URLSigner].
+	SignedURLs bool
+	// SignedURLTTL is how long the signed URLs are valid. Defaults to
+	// DefaultSignedURLTTL.
+	SignedURLTTL time.Duration
+}
+
 // artifactsTool is a tool that loads artifacts and adds them to the session.
 type artifactsTool struct {
 	name        string
 	description string
+	cfg         Config
 }
 
 // New creates a new loadArtifactsTool.
 func New() tool.Tool {
+	return NewWithConfig(Config{})
+}
+
+// NewWithConfig creates a new loadArtifactsTool configured by cfg.
+func NewWithConfig(cfg Config) tool.Tool {
+	if cfg.SignedURLTTL == 0 {
+		cfg.SignedURLTTL = DefaultSignedURLTTL
+	}
 	return &artifactsTool{
 		name:        "load_artifacts",
 		description: "Loads the artifacts and adds them to the session.",
+		cfg:         cfg,
 	}
 }
 
@@ -206,15 +240,94 @@ func (t *artifactsTool) processLoadArtifactsFunctionCall(ctx tool.Context, req *
 }
 
 func (t *artifactsTool) loadIndividualArtifact(ctx context.Context, artifactsService agent.Artifacts, artifactName string) (*genai.Content, error) {
-	resp, err := artifactsService.Load(ctx, artifactName)
+	var part *genai.Part
+	var err error
+	if t.cfg.SignedURLs {
+		part, err = signedURLPart(ctx, artifactsService, artifactName, t.cfg.SignedURLTTL)
+	}
+	if part == nil && err == nil {
+		part, err = loadArtifactPart(ctx, artifactsService, artifactName)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to load artifact %s: %w", artifactName, err)
 	}
 	return &genai.Content{
 		Parts: []*genai.Part{
 			genai.NewPartFromText("Artifact " + artifactName + " is:"),
-			resp.Part,
+			part,
 		},
 		Role: genai.RoleUser,
 	}, nil
 }
+
+// signedURLPart returns a text part with a signed URL downloading the latest
+// version of an artifact, or nil if artifactsService doesn't sign URLs.
+func signedURLPart(ctx context.Context, artifactsService agent.Artifacts, artifactName string, ttl time.Duration) (*genai.Part, error) {
+	signer, ok := artifactsService.(agent.ArtifactURLSigner)
+	if !ok {
+		return nil, nil
+	}
+	resp, err := signer.SignURL(ctx, artifactName, ttl)
+	if errors.Is(err, errors.ErrUnsupported) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return genai.NewPartFromText(fmt.Sprintf("(version %d, download it from %s until %s)", resp.Version, resp.URL, resp.Expires.UTC().Format(time.RFC3339))), nil
+}
+
+// loadArtifactPart loads the content of an artifact, which is streamed if
+// possible so that the artifacts larger than MaxInlineSize are never held in
+// memory. Those are replaced by a note.
+func loadArtifactPart(ctx context.Context, artifactsService agent.Artifacts, artifactName string) (_ *genai.Part, err error) {
+	if streamer, ok := artifactsService.(agent.ArtifactStreamer); ok {
+		resp, err := streamer.LoadStream(ctx, artifactName)
+		if err == nil {
+			return readArtifactPart(resp)
+		}
+		if !errors.Is(err, errors.ErrUnsupported) {
+			return nil, err
+		}
+	}
+
+	resp, err := artifactsService.Load(ctx, artifactName)
+	if err != nil {
+		return nil, err
+	}
+	size := len(resp.Part.Text)
+	if resp.Part.InlineData != nil {
+		size = len(resp.Part.InlineData.Data)
+	}
+	if size > MaxInlineSize {
+		return tooLargePart(), nil
+	}
+	return resp.Part, nil
+}
+
+// readArtifactPart reads the content of resp, up to MaxInlineSize bytes.
+func readArtifactPart(resp *artifact.LoadStreamResponse) (_ *genai.Part, err error) {
+	defer func() {
+		if closeErr := resp.Content.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to close artifact content: %w", closeErr)
+		}
+	}()
+	if resp.Size > MaxInlineSize {
+		return tooLargePart(), nil
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Content, MaxInlineSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read artifact content: %w", err)
+	}
+	if len(data) > MaxInlineSize {
+		return tooLargePart(), nil
+	}
+	if resp.Text {
+		return genai.NewPartFromText(string(data)), nil
+	}
+	return genai.NewPartFromBytes(data, resp.MIMEType), nil
+}
+
+func tooLargePart() *genai.Part {
+	return genai.NewPartFromText(fmt.Sprintf("(not loaded, the artifact is larger than %d bytes)", MaxInlineSize))
+}
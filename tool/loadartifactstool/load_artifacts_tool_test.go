@@ -15,8 +15,11 @@
 package loadartifactstool_test
 
 import (
+	"context"
+	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"google.golang.org/genai"
@@ -277,11 +280,131 @@ func TestLoadArtifactsTool_ProcessRequest_Artifacts_OtherFunctionCall(t *testing
 	}
 }
 
+func TestLoadArtifactsTool_ProcessRequest_Sizes(t *testing.T) {
+	tooLarge := genai.NewPartFromText(fmt.Sprintf("(not loaded, the artifact is larger than %d bytes)", loadartifactstool.MaxInlineSize))
+	for _, tc := range []struct {
+		name string
+		part *genai.Part
+		want *genai.Part
+	}{
+		{"image", genai.NewPartFromBytes([]byte("png"), "image/png"), genai.NewPartFromBytes([]byte("png"), "image/png")},
+		{"text", genai.NewPartFromText("notes"), genai.NewPartFromText("notes")},
+		{"too large", genai.NewPartFromBytes(make([]byte, loadartifactstool.MaxInlineSize+1), "video/mp4"), tooLarge},
+		{"too large text", genai.NewPartFromText(strings.Repeat("a", loadartifactstool.MaxInlineSize+1)), tooLarge},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc2 := createToolContext(t)
+			if _, err := tc2.Artifacts().Save(t.Context(), "file", tc.part); err != nil {
+				t.Fatalf("Failed to save artifact: %v", err)
+			}
+			llmRequest := &model.LLMRequest{
+				Contents: []*genai.Content{{
+					Role: "model",
+					Parts: []*genai.Part{
+						genai.NewPartFromFunctionResponse("load_artifacts", map[string]any{"artifact_names": []string{"file"}}),
+					},
+				}},
+			}
+			requestProcessor := loadartifactstool.New().(toolinternal.RequestProcessor)
+			if err := requestProcessor.ProcessRequest(tc2, llmRequest); err != nil {
+				t.Fatalf("ProcessRequest failed: %v", err)
+			}
+			if len(llmRequest.Contents) != 2 || len(llmRequest.Contents[1].Parts) != 2 {
+				t.Fatalf("Expected an appended content of 2 parts, but got: %v", llmRequest.Contents)
+			}
+			if diff := cmp.Diff(tc.want, llmRequest.Contents[1].Parts[1]); diff != "" {
+				t.Errorf("Loaded part mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// signingService signs fake URLs of the artifacts of the wrapped service.
+type signingService struct {
+	artifact.Service
+}
+
+func (s signingService) SignURL(ctx context.Context, req *artifact.SignURLRequest) (*artifact.SignURLResponse, error) {
+	version, err := artifact.SignedVersion(ctx, s, req)
+	if err != nil {
+		return nil, err
+	}
+	return &artifact.SignURLResponse{
+		Version: version,
+		URL:     fmt.Sprintf("https://storage.example.com/%s/%d", req.FileName, version),
+		Expires: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC).Add(req.TTL),
+	}, nil
+}
+
+func TestLoadArtifactsTool_ProcessRequest_SignedURLs(t *testing.T) {
+	png := genai.NewPartFromBytes([]byte("png"), "image/png")
+	for _, tc := range []struct {
+		name    string
+		cfg     loadartifactstool.Config
+		service artifact.Service
+		want    *genai.Part
+	}{
+		{
+			name:    "signed",
+			cfg:     loadartifactstool.Config{SignedURLs: true},
+			service: signingService{artifact.InMemoryService()},
+			want:    genai.NewPartFromText("(version 1, download it from https://storage.example.com/file/1 until 2025-01-01T01:00:00Z)"),
+		},
+		{
+			name:    "ttl",
+			cfg:     loadartifactstool.Config{SignedURLs: true, SignedURLTTL: 5 * time.Minute},
+			service: signingService{artifact.InMemoryService()},
+			want:    genai.NewPartFromText("(version 1, download it from https://storage.example.com/file/1 until 2025-01-01T00:05:00Z)"),
+		},
+		{
+			name:    "not signed by default",
+			service: signingService{artifact.InMemoryService()},
+			want:    png,
+		},
+		{
+			name:    "unsupported",
+			cfg:     loadartifactstool.Config{SignedURLs: true},
+			service: artifact.InMemoryService(),
+			want:    png,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			toolCtx := createToolContextWithService(t, tc.service)
+			if _, err := toolCtx.Artifacts().Save(t.Context(), "file", png); err != nil {
+				t.Fatalf("Failed to save artifact: %v", err)
+			}
+			llmRequest := &model.LLMRequest{
+				Contents: []*genai.Content{{
+					Role: "model",
+					Parts: []*genai.Part{
+						genai.NewPartFromFunctionResponse("load_artifacts", map[string]any{"artifact_names": []string{"file"}}),
+					},
+				}},
+			}
+			requestProcessor := loadartifactstool.NewWithConfig(tc.cfg).(toolinternal.RequestProcessor)
+			if err := requestProcessor.ProcessRequest(toolCtx, llmRequest); err != nil {
+				t.Fatalf("ProcessRequest failed: %v", err)
+			}
+			if len(llmRequest.Contents) != 2 || len(llmRequest.Contents[1].Parts) != 2 {
+				t.Fatalf("Expected an appended content of 2 parts, but got: %v", llmRequest.Contents)
+			}
+			if diff := cmp.Diff(tc.want, llmRequest.Contents[1].Parts[1]); diff != "" {
+				t.Errorf("Loaded part mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
 func createToolContext(t *testing.T) tool.Context {
 	t.Helper()
+	return createToolContextWithService(t, artifact.InMemoryService())
+}
+
+func createToolContextWithService(t *testing.T, service artifact.Service) tool.Context {
+	t.Helper()
 
 	artifacts := &artifactinternal.Artifacts{
-		Service:   artifact.InMemoryService(),
+		Service:   service,
 		AppName:   "app",
 		UserID:    "user",
 		SessionID: "session",
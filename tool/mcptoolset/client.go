@@ -43,8 +43,12 @@ type connectionRefresher struct {
 
 	mu      sync.Mutex
 	session *mcp.ClientSession
+	closed  bool
 }
 
+// errClosed is the error of the operations of a closed connectionRefresher.
+var errClosed = errors.New("MCP toolset is closed")
+
 // refreshableErrors is a list of errors that should trigger a connection refresh.
 var refreshableErrors = []error{
 	mcp.ErrConnectionClosed,
@@ -153,6 +157,10 @@ func (c *connectionRefresher) getSession(ctx context.Context) (*mcp.ClientSessio
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	if c.closed {
+		return nil, errClosed
+	}
+
 	if c.session != nil {
 		return c.session, nil
 	}
@@ -170,6 +178,10 @@ func (c *connectionRefresher) refreshConnection(ctx context.Context) (*mcp.Clien
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	if c.closed {
+		return nil, errClosed
+	}
+
 	// Ping to verify the connection is actually dead before reconnecting.
 	// This handles the case where another goroutine already reconnected.
 	if c.session != nil {
@@ -191,4 +203,21 @@ func (c *connectionRefresher) refreshConnection(ctx context.Context) (*mcp.Clien
 	return c.session, nil
 }
 
+// Close closes the MCP session, if any. The following operations fail.
+func (c *connectionRefresher) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+	if c.session == nil {
+		return nil
+	}
+	err := c.session.Close()
+	c.session = nil
+	if err != nil {
+		return fmt.Errorf("failed to close MCP session: %w", err)
+	}
+	return nil
+}
+
 var _ MCPClient = (*connectionRefresher)(nil)
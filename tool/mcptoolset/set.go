@@ -17,6 +17,8 @@ package mcptoolset
 
 import (
 	"fmt"
+	"io"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
@@ -28,7 +30,10 @@ import (
 // MCP ToolSet connects to a MCP Server, retrieves MCP Tools into ADK Tools and
 // passes them to the LLM.
 // It uses https://github.com/modelcontextprotocol/go-sdk for MCP communication.
-// MCP session is created lazily on the first request to LLM.
+// MCP session is created lazily on the first request to LLM, and recreated
+// if the connection fails. The returned toolset implements [io.Closer],
+// which closes the MCP session: the toolset can't be used after it is
+// closed.
 //
 // Usage: create MCP ToolSet with mcptoolset.New() and provide it to the
 // LLMAgent in the llmagent.Config.
@@ -49,6 +54,7 @@ import (
 func New(cfg Config) (tool.Toolset, error) {
 	return &set{
 		mcpClient:                   newConnectionRefresher(cfg.Client, cfg.Transport),
+		callTimeout:                 cfg.CallTimeout,
 		toolFilter:                  cfg.ToolFilter,
 		requireConfirmation:         cfg.RequireConfirmation,
 		requireConfirmationProvider: cfg.RequireConfirmationProvider,
@@ -67,6 +73,10 @@ type Config struct {
 	// tool.StringPredicate can be convenient if there's a known fixed list of tool names.
 	ToolFilter tool.Predicate
 
+	// CallTimeout bounds the duration of the calls of the tools.
+	// Optional: if zero, the calls are only bounded by their context.
+	CallTimeout time.Duration
+
 	// RequireConfirmation flags whether the tools from this toolset must always ask for user confirmation
 	// before execution. If set to true, the ADK framework will automatically initiate
 	// a Human-in-the-Loop (HITL) confirmation request when a tool is invoked.
@@ -87,6 +97,7 @@ type Config struct {
 
 type set struct {
 	mcpClient                   MCPClient
+	callTimeout                 time.Duration
 	toolFilter                  tool.Predicate
 	requireConfirmation         bool
 	requireConfirmationProvider ConfirmationProvider
@@ -113,7 +124,7 @@ func (s *set) Tools(ctx agent.ReadonlyContext) ([]tool.Tool, error) {
 
 	var adkTools []tool.Tool
 	for _, mcpTool := range mcpTools {
-		t, err := convertTool(mcpTool, s.mcpClient, s.requireConfirmation, s.requireConfirmationProvider)
+		t, err := convertTool(mcpTool, s.mcpClient, s.callTimeout, s.requireConfirmation, s.requireConfirmationProvider)
 		if err != nil {
 			return nil, fmt.Errorf("failed to convert MCP tool %q to adk tool: %w", mcpTool.Name, err)
 		}
@@ -128,6 +139,14 @@ func (s *set) Tools(ctx agent.ReadonlyContext) ([]tool.Tool, error) {
 	return adkTools, nil
 }
 
+// Close closes the MCP session of the toolset, if its client can be closed.
+func (s *set) Close() error {
+	if c, ok := s.mcpClient.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
 // ConfirmationProvider defines a function that dynamically determines whether
 // a specific tool execution requires user confirmation.
 //
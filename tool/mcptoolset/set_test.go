@@ -105,6 +105,13 @@ func TestMCPToolSet(t *testing.T) {
 		if err != nil {
 			t.Fatal(err)
 		}
+		// The durations of the function calls vary.
+		if _, ok := event.CustomMetadata[llmagent.ToolCallDurationsMetadataKey]; ok {
+			delete(event.CustomMetadata, llmagent.ToolCallDurationsMetadataKey)
+			if len(event.CustomMetadata) == 0 {
+				event.CustomMetadata = nil
+			}
+		}
 		gotEvents = append(gotEvents, event)
 	}
 
@@ -123,6 +130,7 @@ func TestMCPToolSet(t *testing.T) {
 					},
 					Role: genai.RoleModel,
 				},
+				CustomMetadata: map[string]any{model.ModelMetadataKey: modelName},
 			},
 		},
 		{
@@ -154,6 +162,7 @@ func TestMCPToolSet(t *testing.T) {
 					},
 					Role: genai.RoleModel,
 				},
+				CustomMetadata: map[string]any{model.ModelMetadataKey: modelName},
 			},
 		},
 	}
@@ -476,7 +485,8 @@ func TestMCPToolSetConfirmation(t *testing.T) {
 					"error": errors.New("error tool \"get_weather\" requires confirmation, please approve or reject"),
 				}, "user"),
 				genai.NewContentFromFunctionResponse(toolName, map[string]any{
-					"error": errors.New("error tool \"get_weather\" call is rejected"),
+					"status": "rejected",
+					"error":  "the user rejected the call of tool \"get_weather\"",
 				}, "user"),
 				genai.NewContentFromText("I am sorry, I cannot get the weather in Lisbon.", "model"),
 			},
@@ -606,7 +616,8 @@ func TestMCPToolSetConfirmation(t *testing.T) {
 					"error": errors.New("error tool \"get_weather\" requires confirmation, please approve or reject"),
 				}, "user"),
 				genai.NewContentFromFunctionResponse(toolName, map[string]any{
-					"error": errors.New("error tool \"get_weather\" call is rejected"),
+					"status": "rejected",
+					"error":  "the user rejected the call of tool \"get_weather\"",
 				}, "user"),
 				genai.NewContentFromText("I am sorry, I cannot get the weather in Lisbon for you. The tool is not working at the moment.", "model"),
 			},
@@ -15,9 +15,11 @@
 package mcptoolset
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"google.golang.org/genai"
@@ -26,9 +28,10 @@ import (
 	"google.golang.org/adk/internal/toolinternal/toolutils"
 	"google.golang.org/adk/model"
 	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/toolconfirmation"
 )
 
-func convertTool(t *mcp.Tool, client MCPClient, requireConfirmation bool, requireConfirmationProvider ConfirmationProvider) (tool.Tool, error) {
+func convertTool(t *mcp.Tool, client MCPClient, callTimeout time.Duration, requireConfirmation bool, requireConfirmationProvider ConfirmationProvider) (tool.Tool, error) {
 	mcp := &mcpTool{
 		name:        t.Name,
 		description: t.Description,
@@ -37,6 +40,7 @@ func convertTool(t *mcp.Tool, client MCPClient, requireConfirmation bool, requir
 			Description: t.Description,
 		},
 		mcpClient:                   client,
+		callTimeout:                 callTimeout,
 		requireConfirmation:         requireConfirmation,
 		requireConfirmationProvider: requireConfirmationProvider,
 	}
@@ -60,7 +64,8 @@ type mcpTool struct {
 	description     string
 	funcDeclaration *genai.FunctionDeclaration
 
-	mcpClient MCPClient
+	mcpClient   MCPClient
+	callTimeout time.Duration
 
 	requireConfirmation bool
 
@@ -93,7 +98,7 @@ func (t *mcpTool) Declaration() *genai.FunctionDeclaration {
 func (t *mcpTool) Run(ctx tool.Context, args any) (map[string]any, error) {
 	if confirmation := ctx.ToolConfirmation(); confirmation != nil {
 		if !confirmation.Confirmed {
-			return nil, fmt.Errorf("error tool %q call is rejected", t.Name())
+			return toolconfirmation.Rejection(t.Name(), confirmation), nil
 		}
 	} else {
 		requireConfirmation := t.requireConfirmation
@@ -117,11 +122,20 @@ func (t *mcpTool) Run(ctx tool.Context, args any) (map[string]any, error) {
 	}
 
 	// TODO: add auth
-	res, err := t.mcpClient.CallTool(ctx, &mcp.CallToolParams{
+	callCtx := context.Context(ctx)
+	if t.callTimeout > 0 {
+		var cancel context.CancelFunc
+		callCtx, cancel = context.WithTimeout(ctx, t.callTimeout)
+		defer cancel()
+	}
+	res, err := t.mcpClient.CallTool(callCtx, &mcp.CallToolParams{
 		Name:      t.name,
 		Arguments: args,
 	})
 	if err != nil {
+		if ctx.Err() == nil && errors.Is(callCtx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("MCP tool %q timed out after %v: %w", t.name, t.callTimeout, err)
+		}
 		return nil, fmt.Errorf("failed to call MCP tool %q with err: %w", t.name, err)
 	}
 
@@ -145,32 +159,62 @@ func (t *mcpTool) Run(ctx tool.Context, args any) (map[string]any, error) {
 		return nil, errors.New(errMsg)
 	}
 
-	if res.StructuredContent != nil {
-		return map[string]any{
-			"output": res.StructuredContent,
-		}, nil
+	text, content := convertContent(res.Content)
+	result := map[string]any{}
+	switch {
+	case res.StructuredContent != nil:
+		result["output"] = res.StructuredContent
+	case text != "":
+		result["output"] = text
+	case len(content) == 0:
+		return nil, errors.New("no content in tool response")
 	}
-
-	textResponse := strings.Builder{}
-
-	for _, c := range res.Content {
-		textContent, ok := c.(*mcp.TextContent)
-		if !ok {
-			continue
-		}
-
-		if _, err := textResponse.WriteString(textContent.Text); err != nil {
-			return nil, fmt.Errorf("failed to write text response: %w", err)
-		}
+	if len(content) > 0 {
+		result["content"] = content
 	}
+	return result, nil
+}
 
-	if textResponse.Len() == 0 {
-		return nil, errors.New("no text content in tool response")
+// convertContent returns the concatenated text of the MCP content blocks,
+// and the other blocks: images, audio, resource links and embedded
+// resources, converted to maps with their "type".
+func convertContent(blocks []mcp.Content) (string, []any) {
+	var text strings.Builder
+	var content []any
+	for _, c := range blocks {
+		switch c := c.(type) {
+		case *mcp.TextContent:
+			text.WriteString(c.Text)
+		case *mcp.ImageContent:
+			content = append(content, map[string]any{"type": "image", "mimeType": c.MIMEType, "data": c.Data})
+		case *mcp.AudioContent:
+			content = append(content, map[string]any{"type": "audio", "mimeType": c.MIMEType, "data": c.Data})
+		case *mcp.ResourceLink:
+			link := map[string]any{"type": "resource_link", "uri": c.URI, "name": c.Name}
+			if c.MIMEType != "" {
+				link["mimeType"] = c.MIMEType
+			}
+			if c.Description != "" {
+				link["description"] = c.Description
+			}
+			content = append(content, link)
+		case *mcp.EmbeddedResource:
+			if c.Resource == nil {
+				continue
+			}
+			resource := map[string]any{"type": "resource", "uri": c.Resource.URI}
+			if c.Resource.MIMEType != "" {
+				resource["mimeType"] = c.Resource.MIMEType
+			}
+			if c.Resource.Blob != nil {
+				resource["data"] = c.Resource.Blob
+			} else {
+				resource["text"] = c.Resource.Text
+			}
+			content = append(content, resource)
+		}
 	}
-
-	return map[string]any{
-		"output": textResponse.String(),
-	}, nil
+	return text.String(), content
 }
 
 var (
@@ -0,0 +1,202 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcptoolset_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	icontext "google.golang.org/adk/internal/context"
+	"google.golang.org/adk/internal/toolinternal"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/mcptoolset"
+)
+
+var objectSchema = &jsonschema.Schema{
+	Type:       "object",
+	Properties: map[string]*jsonschema.Schema{"query": {Type: "string", Description: "search query"}},
+	Required:   []string{"query"},
+}
+
+// newTestToolSet returns the toolset of an in-process MCP server with a tool
+// named "tool" with the handler.
+func newTestToolSet(t *testing.T, cfg mcptoolset.Config, handler mcp.ToolHandler) tool.Toolset {
+	t.Helper()
+	server := mcp.NewServer(&mcp.Implementation{Name: "test_server", Version: "v1.0.0"}, nil)
+	server.AddTool(&mcp.Tool{Name: "tool", Description: "a test tool", InputSchema: objectSchema}, handler)
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(t.Context(), serverTransport, nil); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Transport = clientTransport
+	ts, err := mcptoolset.New(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create MCP tool set: %v", err)
+	}
+	return ts
+}
+
+// runTestTool lists the tools of the toolset and runs the first one.
+func runTestTool(t *testing.T, ts tool.Toolset) (toolinternal.FunctionTool, map[string]any, error) {
+	t.Helper()
+	invCtx := icontext.NewInvocationContext(t.Context(), icontext.InvocationContextParams{})
+	tools, err := ts.Tools(icontext.NewReadonlyContext(invCtx))
+	if err != nil {
+		t.Fatalf("Tools() error = %v", err)
+	}
+	fnTool := tools[0].(toolinternal.FunctionTool)
+	result, err := fnTool.Run(toolinternal.NewToolContext(invCtx, "", nil, nil), map[string]any{"query": "q"})
+	return fnTool, result, err
+}
+
+func TestMCPTool_Declaration(t *testing.T) {
+	ts := newTestToolSet(t, mcptoolset.Config{}, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "ok"}}}, nil
+	})
+	fnTool, _, err := runTestTool(t, ts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decl := fnTool.Declaration()
+	if decl.Name != "tool" || decl.Description != "a test tool" {
+		t.Errorf("Declaration() = %+v, want the name and the description of the MCP tool", decl)
+	}
+	if diff := cmp.Diff(objectSchema, decl.ParametersJsonSchema); diff != "" {
+		t.Errorf("Declaration() parameters mismatch (-want +got):\n%s", diff)
+	}
+	if decl.ResponseJsonSchema != nil {
+		t.Errorf("Declaration() response schema = %v, want nil", decl.ResponseJsonSchema)
+	}
+}
+
+func TestMCPTool_Content(t *testing.T) {
+	tests := []struct {
+		name    string
+		result  *mcp.CallToolResult
+		want    map[string]any
+		wantErr string
+	}{
+		{
+			name:   "text",
+			result: &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "a"}, &mcp.TextContent{Text: "b"}}},
+			want:   map[string]any{"output": "ab"},
+		},
+		{
+			name: "image_and_text",
+			result: &mcp.CallToolResult{Content: []mcp.Content{
+				&mcp.TextContent{Text: "a chart"},
+				&mcp.ImageContent{MIMEType: "image/png", Data: []byte("png")},
+			}},
+			want: map[string]any{
+				"output":  "a chart",
+				"content": []any{map[string]any{"type": "image", "mimeType": "image/png", "data": []byte("png")}},
+			},
+		},
+		{
+			name: "resources",
+			result: &mcp.CallToolResult{Content: []mcp.Content{
+				&mcp.ResourceLink{URI: "file:///a.txt", Name: "a.txt", MIMEType: "text/plain"},
+				&mcp.EmbeddedResource{Resource: &mcp.ResourceContents{URI: "file:///b.txt", MIMEType: "text/plain", Text: "b"}},
+				&mcp.EmbeddedResource{Resource: &mcp.ResourceContents{URI: "file:///c.bin", Blob: []byte("c")}},
+			}},
+			want: map[string]any{"content": []any{
+				map[string]any{"type": "resource_link", "uri": "file:///a.txt", "name": "a.txt", "mimeType": "text/plain"},
+				map[string]any{"type": "resource", "uri": "file:///b.txt", "mimeType": "text/plain", "text": "b"},
+				map[string]any{"type": "resource", "uri": "file:///c.bin", "data": []byte("c")},
+			}},
+		},
+		{
+			name: "structured",
+			result: &mcp.CallToolResult{
+				Content:           []mcp.Content{&mcp.TextContent{Text: `{"n":1}`}},
+				StructuredContent: map[string]any{"n": 1.0},
+			},
+			want: map[string]any{"output": map[string]any{"n": 1.0}},
+		},
+		{
+			name:    "empty",
+			result:  &mcp.CallToolResult{},
+			wantErr: "no content in tool response",
+		},
+		{
+			name:    "tool_error",
+			result:  &mcp.CallToolResult{IsError: true, Content: []mcp.Content{&mcp.TextContent{Text: "quota exceeded"}}},
+			wantErr: "Tool execution failed. Details: quota exceeded",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestToolSet(t, mcptoolset.Config{}, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return tt.result, nil
+			})
+			_, got, err := runTestTool(t, ts)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("Run() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Run() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMCPTool_CallTimeout(t *testing.T) {
+	ts := newTestToolSet(t, mcptoolset.Config{CallTimeout: 10 * time.Millisecond}, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Second):
+		}
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "late"}}}, nil
+	})
+	_, _, err := runTestTool(t, ts)
+	if err == nil || !strings.Contains(err.Error(), `MCP tool "tool" timed out after 10ms`) {
+		t.Errorf("Run() error = %v, want a timeout", err)
+	}
+}
+
+func TestMCPToolSet_Close(t *testing.T) {
+	ts := newTestToolSet(t, mcptoolset.Config{}, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "ok"}}}, nil
+	})
+	if _, _, err := runTestTool(t, ts); err != nil {
+		t.Fatal(err)
+	}
+	closer, ok := ts.(interface{ Close() error })
+	if !ok {
+		t.Fatal("toolset doesn't implement io.Closer")
+	}
+	if err := closer.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	invCtx := icontext.NewInvocationContext(t.Context(), icontext.InvocationContextParams{})
+	if _, err := ts.Tools(icontext.NewReadonlyContext(invCtx)); err == nil {
+		t.Error("Tools() after Close() succeeded, want error")
+	}
+	if err := closer.Close(); err != nil {
+		t.Errorf("second Close() error = %v", err)
+	}
+}
@@ -0,0 +1,253 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package openapitoolset provides a tool set generating REST tools from an
+// OpenAPI spec.
+package openapitoolset
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/tool"
+)
+
+// New returns an OpenAPI ToolSet.
+// OpenAPI ToolSet parses an OpenAPI 3 spec, in JSON or YAML, and creates one
+// tool per operation of the spec, whose arguments are the parameters of the
+// operation and its request body, in the "body" argument. The tools call the
+// operations on the server of the spec, or on Config.BaseURL, and return the
+// JSON or text of the responses in their "output".
+//
+// The spec is parsed, and its references resolved, by New: a malformed spec
+// fails with the JSON pointer of the offending part of the spec.
+//
+// Example:
+//
+//	petstore, err := openapitoolset.New(ctx, openapitoolset.Config{
+//		SpecURL:     "https://petstore3.swagger.io/api/v3/openapi.json",
+//		BearerToken: token,
+//		Tags:        []string{"pet"},
+//	})
+//	...
+//	llmagent.New(llmagent.Config{
+//		Name:     "agent_name",
+//		Model:    model,
+//		Toolsets: []tool.Toolset{petstore},
+//	})
+func New(ctx context.Context, cfg Config) (tool.Toolset, error) {
+	client := cfg.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	data := cfg.Spec
+	if data == nil {
+		if cfg.SpecURL == "" {
+			return nil, fmt.Errorf("one of Spec or SpecURL is required")
+		}
+		var err error
+		if data, err = fetchSpec(ctx, client, cfg.SpecURL); err != nil {
+			return nil, err
+		}
+	}
+	s, err := parseSpec(data)
+	if err != nil {
+		return nil, err
+	}
+
+	baseURL, err := resolveBaseURL(s, cfg)
+	if err != nil {
+		return nil, err
+	}
+	auth, err := newAuth(s, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	ops, err := s.operations()
+	if err != nil {
+		return nil, err
+	}
+	var tools []tool.Tool
+	names := map[string]string{}
+	for _, op := range ops {
+		if !selected(op, cfg) {
+			continue
+		}
+		t := newTool(op, baseURL, client, auth)
+		if other, ok := names[t.name]; ok {
+			return nil, specError(op.pointer, "tool name %q is already used by the operation at %s", t.name, other)
+		}
+		names[t.name] = op.pointer
+		tools = append(tools, t)
+	}
+	return &set{tools: tools}, nil
+}
+
+// Config provides initial configuration for the OpenAPI ToolSet.
+type Config struct {
+	// Spec is the OpenAPI 3 spec, in JSON or YAML.
+	Spec []byte
+	// SpecURL is the URL of the spec, fetched by New if Spec is nil.
+	SpecURL string
+
+	// BaseURL is the URL of the server of the operations.
+	// Optional: if empty, the URL of the first server of the spec is used,
+	// relative to SpecURL.
+	BaseURL string
+	// HTTPClient is used to fetch the spec and call the operations.
+	// Optional: if nil, http.DefaultClient is used.
+	HTTPClient *http.Client
+
+	// APIKey authenticates the calls with an API key.
+	APIKey *APIKeyAuth
+	// BearerToken authenticates the calls with a bearer token in their
+	// Authorization header.
+	BearerToken string
+
+	// Tags selects the operations with one of the tags.
+	// OperationIDs selects the operations with one of the IDs.
+	// If both are empty, all the operations are selected.
+	Tags         []string
+	OperationIDs []string
+}
+
+// APIKeyAuth is an API key sent with the calls of the operations.
+type APIKeyAuth struct {
+	// Key is the API key.
+	Key string
+	// Name is the name of the header, query parameter or cookie of the key.
+	// In is "header" (the default), "query" or "cookie".
+	// Optional: if Name is empty, the name and location of the first apiKey
+	// security scheme of the spec are used.
+	Name string
+	In   string
+}
+
+type set struct {
+	tools []tool.Tool
+}
+
+func (*set) Name() string {
+	return "openapi_tool_set"
+}
+
+func (*set) Description() string {
+	return "Calls the operations of a REST API described by an OpenAPI spec."
+}
+
+func (*set) IsLongRunning() bool {
+	return false
+}
+
+// Tools returns the tools of the selected operations of the spec.
+func (s *set) Tools(ctx agent.ReadonlyContext) ([]tool.Tool, error) {
+	return s.tools, nil
+}
+
+func selected(op *operation, cfg Config) bool {
+	if len(cfg.Tags) == 0 && len(cfg.OperationIDs) == 0 {
+		return true
+	}
+	if op.id != "" && slices.Contains(cfg.OperationIDs, op.id) {
+		return true
+	}
+	return slices.ContainsFunc(op.tags, func(tag string) bool { return slices.Contains(cfg.Tags, tag) })
+}
+
+// maxSpecSize bounds the size of the specs fetched from their URL.
+const maxSpecSize = 32 << 20
+
+func fetchSpec(ctx context.Context, client *http.Client, specURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, specURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request for OpenAPI spec %q: %w", specURL, err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch OpenAPI spec %q: %w", specURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch OpenAPI spec %q: status %s", specURL, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSpecSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OpenAPI spec %q: %w", specURL, err)
+	}
+	return data, nil
+}
+
+func resolveBaseURL(s *spec, cfg Config) (string, error) {
+	base := cfg.BaseURL
+	if base == "" {
+		var err error
+		if base, err = s.serverURL(); err != nil {
+			return "", err
+		}
+		if cfg.SpecURL != "" {
+			specURL, err := url.Parse(cfg.SpecURL)
+			if err != nil {
+				return "", fmt.Errorf("invalid SpecURL %q: %w", cfg.SpecURL, err)
+			}
+			ref, err := url.Parse(base)
+			if err != nil {
+				return "", specError("#/servers/0/url", "invalid URL %q: %v", base, err)
+			}
+			base = specURL.ResolveReference(ref).String()
+		}
+	}
+	u, err := url.Parse(base)
+	if err != nil || !u.IsAbs() {
+		return "", fmt.Errorf("base URL %q of the operations must be an absolute URL: set BaseURL, or the servers of the spec", base)
+	}
+	return strings.TrimSuffix(base, "/"), nil
+}
+
+func newAuth(s *spec, cfg Config) (*auth, error) {
+	a := &auth{bearerToken: cfg.BearerToken}
+	if cfg.APIKey == nil {
+		return a, nil
+	}
+	key := *cfg.APIKey
+	if key.Name == "" {
+		schemes := s.securitySchemes()
+		for _, name := range slices.Sorted(maps.Keys(schemes)) {
+			if scheme := schemes[name]; scheme["type"] == "apiKey" {
+				key.Name, _ = scheme["name"].(string)
+				key.In, _ = scheme["in"].(string)
+				break
+			}
+		}
+		if key.Name == "" {
+			return nil, fmt.Errorf("APIKey.Name is required: the spec has no apiKey security scheme")
+		}
+	}
+	switch key.In {
+	case "":
+		key.In = "header"
+	case "header", "query", "cookie":
+	default:
+		return nil, fmt.Errorf("invalid APIKey.In %q, want header, query or cookie", key.In)
+	}
+	a.apiKey = &key
+	return a, nil
+}
@@ -0,0 +1,314 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openapitoolset_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	icontext "google.golang.org/adk/internal/context"
+	"google.golang.org/adk/internal/toolinternal"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/openapitoolset"
+)
+
+const spec = `{
+  "openapi": "3.0.0",
+  "servers": [{"url": "/api"}],
+  "components": {
+    "securitySchemes": {"key": {"type": "apiKey", "in": "query", "name": "api_key"}}
+  },
+  "paths": {
+    "/pets/{petId}": {
+      "get": {
+        "operationId": "getPet",
+        "tags": ["pet"],
+        "parameters": [
+          {"name": "petId", "in": "path", "schema": {"type": "string"}},
+          {"name": "fields", "in": "query", "schema": {"type": "array", "items": {"type": "string"}}},
+          {"name": "X-Trace", "in": "header", "schema": {"type": "string"}}
+        ]
+      }
+    },
+    "/pets": {
+      "post": {
+        "operationId": "createPet",
+        "tags": ["pet"],
+        "requestBody": {"required": true, "content": {"application/json": {"schema": {"type": "object"}}}}
+      }
+    },
+    "/notes": {
+      "post": {
+        "operationId": "addNote",
+        "tags": ["note"],
+        "requestBody": {"content": {"text/plain": {"schema": {"type": "string"}}}}
+      }
+    }
+  }
+}`
+
+// request is a request received by the test server.
+type request struct {
+	Method        string
+	Path          string
+	Query         string
+	Trace         string
+	Authorization string
+	ContentType   string
+	Body          string
+}
+
+func newServer(t *testing.T) (*httptest.Server, *[]request) {
+	t.Helper()
+	var requests []request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/openapi.json" {
+			io.WriteString(w, spec)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, request{
+			Method:        r.Method,
+			Path:          r.URL.Path,
+			Query:         r.URL.RawQuery,
+			Trace:         r.Header.Get("X-Trace"),
+			Authorization: r.Header.Get("Authorization"),
+			ContentType:   r.Header.Get("Content-Type"),
+			Body:          string(body),
+		})
+		switch r.URL.Path {
+		case "/api/pets/404":
+			http.Error(w, "no such pet", http.StatusNotFound)
+		case "/api/notes":
+			w.Header().Set("Content-Type", "text/plain")
+			io.WriteString(w, "noted")
+		default:
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			json.NewEncoder(w).Encode(map[string]any{"name": "Rex"})
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func tools(t *testing.T, ts tool.Toolset) map[string]toolinternal.FunctionTool {
+	t.Helper()
+	invCtx := icontext.NewInvocationContext(t.Context(), icontext.InvocationContextParams{})
+	list, err := ts.Tools(icontext.NewReadonlyContext(invCtx))
+	if err != nil {
+		t.Fatalf("Tools() error = %v", err)
+	}
+	result := map[string]toolinternal.FunctionTool{}
+	for _, tl := range list {
+		result[tl.Name()] = tl.(toolinternal.FunctionTool)
+	}
+	return result
+}
+
+func run(t *testing.T, fnTool toolinternal.FunctionTool, args map[string]any) (map[string]any, error) {
+	t.Helper()
+	invCtx := icontext.NewInvocationContext(t.Context(), icontext.InvocationContextParams{})
+	return fnTool.Run(toolinternal.NewToolContext(invCtx, "", nil, nil), args)
+}
+
+func TestToolset_Run(t *testing.T) {
+	server, requests := newServer(t)
+	ts, err := openapitoolset.New(t.Context(), openapitoolset.Config{
+		SpecURL:     server.URL + "/openapi.json",
+		BearerToken: "token",
+		APIKey:      &openapitoolset.APIKeyAuth{Key: "secret"},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	tls := tools(t, ts)
+
+	tests := []struct {
+		name    string
+		tool    string
+		args    map[string]any
+		want    map[string]any
+		wantErr string
+		wantReq *request
+	}{
+		{
+			name: "path, query and header",
+			tool: "getPet",
+			args: map[string]any{"petId": "a b", "fields": []any{"name", "tag"}, "X-Trace": "t1"},
+			want: map[string]any{"output": map[string]any{"name": "Rex"}},
+			wantReq: &request{
+				Method:        "GET",
+				Path:          "/api/pets/a b",
+				Query:         "api_key=secret&fields=name&fields=tag",
+				Trace:         "t1",
+				Authorization: "Bearer token",
+			},
+		},
+		{
+			name: "JSON body",
+			tool: "createPet",
+			args: map[string]any{"body": map[string]any{"name": "Rex", "age": 3.0}},
+			want: map[string]any{"output": map[string]any{"name": "Rex"}},
+			wantReq: &request{
+				Method:        "POST",
+				Path:          "/api/pets",
+				Query:         "api_key=secret",
+				Authorization: "Bearer token",
+				ContentType:   "application/json",
+				Body:          `{"age":3,"name":"Rex"}`,
+			},
+		},
+		{
+			name: "text body and response",
+			tool: "addNote",
+			args: map[string]any{"body": "hello"},
+			want: map[string]any{"output": "noted"},
+			wantReq: &request{
+				Method:        "POST",
+				Path:          "/api/notes",
+				Query:         "api_key=secret",
+				Authorization: "Bearer token",
+				ContentType:   "text/plain",
+				Body:          "hello",
+			},
+		},
+		{
+			name:    "error status",
+			tool:    "getPet",
+			args:    map[string]any{"petId": "404"},
+			wantErr: "failed with status 404 Not Found: no such pet",
+		},
+		{
+			name:    "missing required argument",
+			tool:    "createPet",
+			args:    map[string]any{},
+			wantErr: `missing required argument "body"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			*requests = nil
+			got, err := run(t, tls[tt.tool], tt.args)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Run() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Run() mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff([]request{*tt.wantReq}, *requests); diff != "" {
+				t.Errorf("requests mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestToolset_Filter(t *testing.T) {
+	tests := []struct {
+		name         string
+		tags         []string
+		operationIDs []string
+		want         []string
+	}{
+		{name: "all", want: []string{"addNote", "createPet", "getPet"}},
+		{name: "tag", tags: []string{"pet"}, want: []string{"createPet", "getPet"}},
+		{name: "operation ID", operationIDs: []string{"addNote"}, want: []string{"addNote"}},
+		{name: "tag or operation ID", tags: []string{"note"}, operationIDs: []string{"getPet"}, want: []string{"addNote", "getPet"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts, err := openapitoolset.New(t.Context(), openapitoolset.Config{
+				Spec:         []byte(spec),
+				BaseURL:      "https://example.com",
+				Tags:         tt.tags,
+				OperationIDs: tt.operationIDs,
+			})
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			var got []string
+			for name := range tools(t, ts) {
+				got = append(got, name)
+			}
+			if diff := cmp.Diff(tt.want, got, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+				t.Errorf("tools mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNew_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  openapitoolset.Config
+		want string
+	}{
+		{
+			name: "no spec",
+			cfg:  openapitoolset.Config{},
+			want: "one of Spec or SpecURL is required",
+		},
+		{
+			name: "no base URL",
+			cfg:  openapitoolset.Config{Spec: []byte(`{"openapi": "3.0.0", "paths": {}}`)},
+			want: "must be an absolute URL",
+		},
+		{
+			name: "no API key name",
+			cfg: openapitoolset.Config{
+				Spec:    []byte(`{"openapi": "3.0.0", "paths": {}}`),
+				BaseURL: "https://example.com",
+				APIKey:  &openapitoolset.APIKeyAuth{Key: "secret"},
+			},
+			want: "APIKey.Name is required",
+		},
+		{
+			name: "malformed spec",
+			cfg: openapitoolset.Config{
+				Spec:    []byte(`{"openapi": "3.0.0", "paths": {"/a": {"get": {"parameters": {}}}}}`),
+				BaseURL: "https://example.com",
+			},
+			want: "invalid OpenAPI spec at #/paths/~1a/get/parameters: must be an array",
+		},
+		{
+			name: "duplicate tool names",
+			cfg: openapitoolset.Config{
+				Spec:    []byte(`{"openapi": "3.0.0", "paths": {"/a": {"get": {"operationId": "op"}, "post": {"operationId": "op"}}}}`),
+				BaseURL: "https://example.com",
+			},
+			want: `invalid OpenAPI spec at #/paths/~1a/post: tool name "op" is already used by the operation at #/paths/~1a/get`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := openapitoolset.New(t.Context(), tt.cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("New() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
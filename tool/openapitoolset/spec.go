@@ -0,0 +1,504 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openapitoolset
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// methods are the HTTP methods of the operations, in the order of the
+// tools of an OpenAPI path.
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// schemaKeywords are the JSON schema keywords kept in the function
+// declarations, with the same value as in the spec.
+var schemaKeywords = []string{
+	"type", "format", "title", "description", "enum", "const", "default",
+	"minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum", "multipleOf",
+	"minLength", "maxLength", "pattern",
+	"minItems", "maxItems", "uniqueItems",
+	"required", "minProperties", "maxProperties",
+}
+
+// maxRefDepth bounds the chains of references, e.g. of a reference to a
+// reference.
+const maxRefDepth = 32
+
+// spec is a parsed OpenAPI 3 spec.
+type spec struct {
+	doc map[string]any
+}
+
+// operation is an operation of a spec, with its references resolved.
+type operation struct {
+	id          string
+	method      string
+	path        string
+	summary     string
+	description string
+	tags        []string
+	params      []parameter
+	body        *requestBody
+	// pointer is the JSON pointer of the operation in the spec.
+	pointer string
+}
+
+// parameter is a parameter of an operation.
+type parameter struct {
+	name string
+	// arg is the name of the argument of the function declaration, which
+	// is the name of the parameter unless it is used by another parameter.
+	arg         string
+	in          string
+	description string
+	required    bool
+	schema      map[string]any
+}
+
+// requestBody is the request body of an operation.
+type requestBody struct {
+	mediaType   string
+	description string
+	required    bool
+	schema      map[string]any
+}
+
+// parseSpec parses an OpenAPI 3 spec, in JSON or YAML.
+func parseSpec(data []byte) (*spec, error) {
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI spec: %w", err)
+	}
+	m, ok := normalize(doc).(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("OpenAPI spec must be an object")
+	}
+	if version, _ := m["openapi"].(string); !strings.HasPrefix(version, "3.") {
+		return nil, specError("#/openapi", "unsupported OpenAPI version %q, want 3.x", m["openapi"])
+	}
+	return &spec{doc: m}, nil
+}
+
+// normalize converts the maps decoded from YAML, whose keys may not be
+// strings, e.g. the status codes of the responses, to map[string]any.
+func normalize(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, e := range v {
+			v[k] = normalize(e)
+		}
+		return v
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for k, e := range v {
+			m[fmt.Sprint(k)] = normalize(e)
+		}
+		return m
+	case []any:
+		for i, e := range v {
+			v[i] = normalize(e)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func specError(pointer, format string, args ...any) error {
+	return fmt.Errorf("invalid OpenAPI spec at %s: %s", pointer, fmt.Sprintf(format, args...))
+}
+
+// pointer returns the JSON pointer of the tokens below the base pointer.
+func pointer(base string, tokens ...string) string {
+	var sb strings.Builder
+	sb.WriteString(base)
+	for _, token := range tokens {
+		sb.WriteByte('/')
+		sb.WriteString(strings.NewReplacer("~", "~0", "/", "~1").Replace(token))
+	}
+	return sb.String()
+}
+
+// lookup returns the value of the document at the JSON pointer.
+func (s *spec) lookup(ref string) (any, bool) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, false
+	}
+	var v any = s.doc
+	if ref == "#" {
+		return v, true
+	}
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, false
+	}
+	for _, token := range strings.Split(ref[2:], "/") {
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		switch e := v.(type) {
+		case map[string]any:
+			var ok bool
+			if v, ok = e[token]; !ok {
+				return nil, false
+			}
+		case []any:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(e) {
+				return nil, false
+			}
+			v = e[i]
+		default:
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// resolve returns the object at the pointer, or the object it refers to
+// with its $ref, and the pointer of the returned object.
+func (s *spec) resolve(v any, at string) (map[string]any, string, error) {
+	for range maxRefDepth {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil, at, specError(at, "must be an object")
+		}
+		ref, ok := m["$ref"]
+		if !ok {
+			return m, at, nil
+		}
+		refStr, ok := ref.(string)
+		if !ok {
+			return nil, at, specError(at, "$ref must be a string")
+		}
+		if v, ok = s.lookup(refStr); !ok {
+			return nil, at, specError(at, "unresolved $ref %q, only references within the spec are supported", refStr)
+		}
+		at = refStr
+	}
+	return nil, at, specError(at, "too many nested references")
+}
+
+// serverURL returns the URL of the first server of the spec, with the
+// default values of its variables.
+func (s *spec) serverURL() (string, error) {
+	servers, _ := s.doc["servers"].([]any)
+	if len(servers) == 0 {
+		return "", nil
+	}
+	server, ok := servers[0].(map[string]any)
+	if !ok {
+		return "", specError("#/servers/0", "must be an object")
+	}
+	u, ok := server["url"].(string)
+	if !ok {
+		return "", specError("#/servers/0/url", "must be a string")
+	}
+	vars, _ := server["variables"].(map[string]any)
+	for name, v := range vars {
+		variable, _ := v.(map[string]any)
+		def, ok := variable["default"].(string)
+		if !ok {
+			return "", specError(pointer("#/servers/0/variables", name), "must have a default value")
+		}
+		u = strings.ReplaceAll(u, "{"+name+"}", def)
+	}
+	return u, nil
+}
+
+// securitySchemes returns the security schemes of the spec, by name.
+func (s *spec) securitySchemes() map[string]map[string]any {
+	components, _ := s.doc["components"].(map[string]any)
+	schemes, _ := components["securitySchemes"].(map[string]any)
+	result := make(map[string]map[string]any, len(schemes))
+	for name, v := range schemes {
+		if scheme, _, err := s.resolve(v, pointer("#/components/securitySchemes", name)); err == nil {
+			result[name] = scheme
+		}
+	}
+	return result
+}
+
+// operations returns the operations of the spec, in the order of their
+// paths and methods.
+func (s *spec) operations() ([]*operation, error) {
+	paths, ok := s.doc["paths"].(map[string]any)
+	if !ok {
+		if _, exists := s.doc["paths"]; exists {
+			return nil, specError("#/paths", "must be an object")
+		}
+		return nil, nil
+	}
+	var ops []*operation
+	for _, path := range slices.Sorted(maps.Keys(paths)) {
+		item, itemPointer, err := s.resolve(paths[path], pointer("#/paths", path))
+		if err != nil {
+			return nil, err
+		}
+		pathParams, err := s.parameters(item["parameters"], pointer(itemPointer, "parameters"))
+		if err != nil {
+			return nil, err
+		}
+		for _, method := range methods {
+			v, ok := item[method]
+			if !ok {
+				continue
+			}
+			op, err := s.operation(v, pointer(itemPointer, method), method, path, pathParams)
+			if err != nil {
+				return nil, err
+			}
+			ops = append(ops, op)
+		}
+	}
+	return ops, nil
+}
+
+func (s *spec) operation(v any, at, method, path string, pathParams []parameter) (*operation, error) {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, specError(at, "must be an object")
+	}
+	op := &operation{method: strings.ToUpper(method), path: path, pointer: at}
+	op.id, _ = m["operationId"].(string)
+	op.summary, _ = m["summary"].(string)
+	op.description, _ = m["description"].(string)
+	if tags, ok := m["tags"].([]any); ok {
+		for _, tag := range tags {
+			if tag, ok := tag.(string); ok {
+				op.tags = append(op.tags, tag)
+			}
+		}
+	}
+
+	params, err := s.parameters(m["parameters"], pointer(at, "parameters"))
+	if err != nil {
+		return nil, err
+	}
+	// The parameters of the operation override those of the path.
+	for _, p := range pathParams {
+		if !slices.ContainsFunc(params, func(q parameter) bool { return q.name == p.name && q.in == p.in }) {
+			params = append(params, p)
+		}
+	}
+	args := map[string]bool{"body": m["requestBody"] != nil}
+	for i := range params {
+		params[i].arg = params[i].name
+		if args[params[i].arg] {
+			params[i].arg = params[i].in + "_" + params[i].name
+		}
+		args[params[i].arg] = true
+	}
+	for _, match := range pathTemplate.FindAllStringSubmatch(path, -1) {
+		if !slices.ContainsFunc(params, func(p parameter) bool { return p.name == match[1] && p.in == "path" }) {
+			return nil, specError(at, "missing path parameter %q", match[1])
+		}
+	}
+	op.params = params
+
+	if v, ok := m["requestBody"]; ok {
+		if op.body, err = s.requestBody(v, pointer(at, "requestBody")); err != nil {
+			return nil, err
+		}
+	}
+	return op, nil
+}
+
+// pathTemplate matches the parameters of the path templates.
+var pathTemplate = regexp.MustCompile(`\{([^}/]+)\}`)
+
+func (s *spec) parameters(v any, at string) ([]parameter, error) {
+	if v == nil {
+		return nil, nil
+	}
+	list, ok := v.([]any)
+	if !ok {
+		return nil, specError(at, "must be an array")
+	}
+	var params []parameter
+	for i, e := range list {
+		m, paramPointer, err := s.resolve(e, pointer(at, strconv.Itoa(i)))
+		if err != nil {
+			return nil, err
+		}
+		p := parameter{}
+		if p.name, _ = m["name"].(string); p.name == "" {
+			return nil, specError(paramPointer, "missing parameter name")
+		}
+		p.in, _ = m["in"].(string)
+		switch p.in {
+		case "path":
+			p.required = true
+		case "query", "header", "cookie":
+			p.required, _ = m["required"].(bool)
+		default:
+			return nil, specError(paramPointer, "invalid parameter location %q", m["in"])
+		}
+		p.description, _ = m["description"].(string)
+
+		var schema any = map[string]any{"type": "string"}
+		schemaPointer := pointer(paramPointer, "schema")
+		if v, ok := m["schema"]; ok {
+			schema = v
+		} else if content, ok := m["content"].(map[string]any); ok {
+			for mediaType, v := range content {
+				mt, _ := v.(map[string]any)
+				schema, schemaPointer = mt["schema"], pointer(paramPointer, "content", mediaType, "schema")
+				break
+			}
+		}
+		if p.schema, err = s.convertSchema(schema, schemaPointer, nil); err != nil {
+			return nil, err
+		}
+		params = append(params, p)
+	}
+	return params, nil
+}
+
+// bodyMediaTypes are the supported media types of the request bodies, by
+// preference.
+var bodyMediaTypes = []string{"application/json", "application/x-www-form-urlencoded", "text/plain"}
+
+func (s *spec) requestBody(v any, at string) (*requestBody, error) {
+	m, bodyPointer, err := s.resolve(v, at)
+	if err != nil {
+		return nil, err
+	}
+	content, ok := m["content"].(map[string]any)
+	if !ok || len(content) == 0 {
+		return nil, specError(bodyPointer, "missing request body content")
+	}
+	body := &requestBody{}
+	body.required, _ = m["required"].(bool)
+	body.description, _ = m["description"].(string)
+	for _, mediaType := range slices.Sorted(maps.Keys(content)) {
+		if body.mediaType == "" || mediaTypeRank(mediaType) < mediaTypeRank(body.mediaType) {
+			body.mediaType = mediaType
+		}
+	}
+	mt, ok := content[body.mediaType].(map[string]any)
+	if !ok {
+		return nil, specError(pointer(bodyPointer, "content", body.mediaType), "must be an object")
+	}
+	var schema any = map[string]any{}
+	if v, ok := mt["schema"]; ok {
+		schema = v
+	}
+	if body.schema, err = s.convertSchema(schema, pointer(bodyPointer, "content", body.mediaType, "schema"), nil); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// mediaTypeRank ranks the media types of the request bodies, the JSON types
+// first.
+func mediaTypeRank(mediaType string) int {
+	if strings.HasSuffix(mediaType, "+json") {
+		return 0
+	}
+	if i := slices.Index(bodyMediaTypes, mediaType); i >= 0 {
+		return i
+	}
+	return len(bodyMediaTypes)
+}
+
+// convertSchema converts an OpenAPI schema to a JSON schema, with its
+// references resolved. The recursive references are replaced with an
+// object schema. The references being resolved are in seen.
+func (s *spec) convertSchema(v any, at string, seen []string) (map[string]any, error) {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, specError(at, "schema must be an object")
+	}
+	if ref, ok := m["$ref"].(string); ok {
+		if slices.Contains(seen, ref) {
+			return map[string]any{"type": "object"}, nil
+		}
+		target, targetPointer, err := s.resolve(m, at)
+		if err != nil {
+			return nil, err
+		}
+		return s.convertSchema(target, targetPointer, append(seen, ref))
+	}
+
+	out := make(map[string]any)
+	for _, keyword := range schemaKeywords {
+		if v, ok := m[keyword]; ok {
+			out[keyword] = v
+		}
+	}
+	if nullable, _ := m["nullable"].(bool); nullable {
+		if t, ok := out["type"].(string); ok {
+			out["type"] = []any{t, "null"}
+		}
+	}
+	if props, ok := m["properties"]; ok {
+		propsMap, ok := props.(map[string]any)
+		if !ok {
+			return nil, specError(pointer(at, "properties"), "must be an object")
+		}
+		converted := make(map[string]any, len(propsMap))
+		for name, prop := range propsMap {
+			c, err := s.convertSchema(prop, pointer(at, "properties", name), seen)
+			if err != nil {
+				return nil, err
+			}
+			converted[name] = c
+		}
+		out["properties"] = converted
+	}
+	if items, ok := m["items"]; ok {
+		c, err := s.convertSchema(items, pointer(at, "items"), seen)
+		if err != nil {
+			return nil, err
+		}
+		out["items"] = c
+	}
+	switch additional := m["additionalProperties"].(type) {
+	case nil:
+	case bool:
+		out["additionalProperties"] = additional
+	default:
+		c, err := s.convertSchema(additional, pointer(at, "additionalProperties"), seen)
+		if err != nil {
+			return nil, err
+		}
+		out["additionalProperties"] = c
+	}
+	for _, keyword := range []string{"allOf", "anyOf", "oneOf"} {
+		v, ok := m[keyword]
+		if !ok {
+			continue
+		}
+		list, ok := v.([]any)
+		if !ok {
+			return nil, specError(pointer(at, keyword), "must be an array")
+		}
+		converted := make([]any, len(list))
+		for i, e := range list {
+			c, err := s.convertSchema(e, pointer(at, keyword, strconv.Itoa(i)), seen)
+			if err != nil {
+				return nil, err
+			}
+			converted[i] = c
+		}
+		out[keyword] = converted
+	}
+	return out, nil
+}
@@ -0,0 +1,245 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openapitoolset
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const petstoreYAML = `
+openapi: 3.0.3
+info:
+  title: Petstore
+  version: "1"
+servers:
+  - url: https://{region}.example.com/v1
+    variables:
+      region:
+        default: eu
+paths:
+  /pets/{petId}:
+    parameters:
+      - $ref: '#/components/parameters/PetId'
+    get:
+      operationId: getPet
+      summary: Gets a pet.
+      tags: [pet]
+      responses:
+        200:
+          description: The pet.
+    put:
+      operationId: updatePet
+      tags: [pet]
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Pet'
+      responses:
+        200:
+          description: The pet.
+components:
+  parameters:
+    PetId:
+      name: petId
+      in: path
+      description: ID of the pet.
+      schema:
+        type: integer
+  schemas:
+    Pet:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+        tag:
+          type: string
+          nullable: true
+        owner:
+          $ref: '#/components/schemas/Owner'
+    Owner:
+      type: object
+      properties:
+        name:
+          type: string
+        pets:
+          type: array
+          items:
+            $ref: '#/components/schemas/Pet'
+`
+
+func TestSpec_Operations(t *testing.T) {
+	s, err := parseSpec([]byte(petstoreYAML))
+	if err != nil {
+		t.Fatalf("parseSpec() error = %v", err)
+	}
+	ops, err := s.operations()
+	if err != nil {
+		t.Fatalf("operations() error = %v", err)
+	}
+	var got []map[string]any
+	for _, op := range ops {
+		got = append(got, map[string]any{"id": op.id, "method": op.method, "schema": parametersSchema(op)})
+	}
+	petID := map[string]any{"type": "integer", "description": "ID of the pet."}
+	owner := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"name": map[string]any{"type": "string"},
+			// The recursive reference to Pet is replaced with an object.
+			"pets": map[string]any{"type": "array", "items": map[string]any{"type": "object"}},
+		},
+	}
+	want := []map[string]any{
+		{
+			"id":     "getPet",
+			"method": "GET",
+			"schema": map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"petId": petID},
+				"required":   []any{"petId"},
+			},
+		},
+		{
+			"id":     "updatePet",
+			"method": "PUT",
+			"schema": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"petId": petID,
+					"body": map[string]any{
+						"type":     "object",
+						"required": []any{"name"},
+						"properties": map[string]any{
+							"name":  map[string]any{"type": "string"},
+							"tag":   map[string]any{"type": []any{"string", "null"}},
+							"owner": owner,
+						},
+					},
+				},
+				"required": []any{"petId", "body"},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("operations() mismatch (-want +got):\n%s", diff)
+	}
+
+	if got, err := s.serverURL(); err != nil || got != "https://eu.example.com/v1" {
+		t.Errorf("serverURL() = %q, %v, want %q", got, err, "https://eu.example.com/v1")
+	}
+}
+
+func TestSpec_JSON(t *testing.T) {
+	s, err := parseSpec([]byte(`{"openapi": "3.1.0", "paths": {"/health": {"get": {"operationId": "health"}}}}`))
+	if err != nil {
+		t.Fatalf("parseSpec() error = %v", err)
+	}
+	ops, err := s.operations()
+	if err != nil {
+		t.Fatalf("operations() error = %v", err)
+	}
+	if len(ops) != 1 || ops[0].id != "health" || ops[0].pointer != "#/paths/~1health/get" {
+		t.Errorf("operations() = %+v, want the health operation", ops)
+	}
+}
+
+func TestSpec_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+		want string
+	}{
+		{
+			name: "not a spec",
+			spec: `[1, 2]`,
+			want: "OpenAPI spec must be an object",
+		},
+		{
+			name: "swagger 2",
+			spec: `{"swagger": "2.0"}`,
+			want: "invalid OpenAPI spec at #/openapi",
+		},
+		{
+			name: "syntax",
+			spec: `{"openapi": `,
+			want: "failed to parse OpenAPI spec",
+		},
+		{
+			name: "unresolved ref",
+			spec: `{"openapi": "3.0.0", "paths": {"/a": {"get": {"parameters": [{"$ref": "#/components/parameters/Missing"}]}}}}`,
+			want: `invalid OpenAPI spec at #/paths/~1a/get/parameters/0: unresolved $ref "#/components/parameters/Missing"`,
+		},
+		{
+			name: "external ref",
+			spec: `{"openapi": "3.0.0", "paths": {"/a": {"post": {"requestBody": {"$ref": "other.yaml#/Body"}}}}}`,
+			want: "invalid OpenAPI spec at #/paths/~1a/post/requestBody: unresolved $ref",
+		},
+		{
+			name: "invalid location",
+			spec: `{"openapi": "3.0.0", "paths": {"/a": {"get": {"parameters": [{"name": "x", "in": "body"}]}}}}`,
+			want: `invalid OpenAPI spec at #/paths/~1a/get/parameters/0: invalid parameter location "body"`,
+		},
+		{
+			name: "missing path parameter",
+			spec: `{"openapi": "3.0.0", "paths": {"/a/{id}": {"get": {}}}}`,
+			want: `invalid OpenAPI spec at #/paths/~1a~1{id}/get: missing path parameter "id"`,
+		},
+		{
+			name: "nested schema",
+			spec: `{"openapi": "3.0.0", "paths": {"/a": {"post": {"requestBody": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/A"}}}}}}},
+				"components": {"schemas": {"A": {"properties": {"b": {"items": 1}}}}}}`,
+			want: "invalid OpenAPI spec at #/components/schemas/A/properties/b/items: schema must be an object",
+		},
+		{
+			name: "cyclic ref",
+			spec: `{"openapi": "3.0.0", "paths": {"/a": {"$ref": "#/paths/~1a"}}}`,
+			want: "too many nested references",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := parseSpec([]byte(tt.spec))
+			if err == nil {
+				_, err = s.operations()
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestToolName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "getPet", want: "getPet"},
+		{name: "get_/pets/{petId}", want: "get__pets__petId_"},
+		{name: "pets.list-all", want: "pets_list_all"},
+		{name: strings.Repeat("a", 70), want: strings.Repeat("a", 64)},
+	}
+	for _, tt := range tests {
+		if got := toolName(tt.name); got != tt.want {
+			t.Errorf("toolName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,362 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openapitoolset
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/internal/toolinternal/toolutils"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/tool"
+)
+
+const (
+	// maxNameLength is the maximum length of the names of the function
+	// declarations.
+	maxNameLength = 64
+	// maxResponseSize bounds the size of the responses read by the tools.
+	maxResponseSize = 10 << 20
+	// maxErrorBodyLength bounds the length of the bodies of the error
+	// responses included in the errors of the tools.
+	maxErrorBodyLength = 512
+)
+
+type openapiTool struct {
+	name            string
+	description     string
+	funcDeclaration *genai.FunctionDeclaration
+
+	op      *operation
+	baseURL string
+	client  *http.Client
+	auth    *auth
+}
+
+func newTool(op *operation, baseURL string, client *http.Client, auth *auth) *openapiTool {
+	name := op.id
+	if name == "" {
+		name = strings.ToLower(op.method) + "_" + op.path
+	}
+	name = toolName(name)
+	description := op.summary
+	if op.description != "" {
+		if description != "" {
+			description += "\n\n"
+		}
+		description += op.description
+	}
+	if description == "" {
+		description = op.method + " " + op.path
+	}
+	return &openapiTool{
+		name:        name,
+		description: description,
+		funcDeclaration: &genai.FunctionDeclaration{
+			Name:                 name,
+			Description:          description,
+			ParametersJsonSchema: parametersSchema(op),
+		},
+		op:      op,
+		baseURL: baseURL,
+		client:  client,
+		auth:    auth,
+	}
+}
+
+// toolName returns the name made of the letters, digits and underscores of
+// the name, the other characters being replaced with underscores.
+func toolName(name string) string {
+	b := []byte(name)
+	for i, c := range b {
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '_') {
+			b[i] = '_'
+		}
+	}
+	if len(b) > maxNameLength {
+		b = b[:maxNameLength]
+	}
+	return string(b)
+}
+
+// parametersSchema returns the schema of the arguments of the tool of the
+// operation: an object of its parameters and its request body.
+func parametersSchema(op *operation) map[string]any {
+	props := map[string]any{}
+	var required []any
+	for _, p := range op.params {
+		schema := p.schema
+		if p.description != "" {
+			schema = withDescription(schema, p.description)
+		}
+		props[p.arg] = schema
+		if p.required {
+			required = append(required, p.arg)
+		}
+	}
+	if op.body != nil {
+		schema := op.body.schema
+		if op.body.description != "" {
+			schema = withDescription(schema, op.body.description)
+		}
+		props["body"] = schema
+		if op.body.required {
+			required = append(required, "body")
+		}
+	}
+	schema := map[string]any{"type": "object", "properties": props}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func withDescription(schema map[string]any, description string) map[string]any {
+	s := make(map[string]any, len(schema)+1)
+	for k, v := range schema {
+		s[k] = v
+	}
+	if _, ok := s["description"]; !ok {
+		s["description"] = description
+	}
+	return s
+}
+
+// Name implements the tool.Tool.
+func (t *openapiTool) Name() string {
+	return t.name
+}
+
+// Description implements the tool.Tool.
+func (t *openapiTool) Description() string {
+	return t.description
+}
+
+// IsLongRunning implements the tool.Tool.
+func (t *openapiTool) IsLongRunning() bool {
+	return false
+}
+
+func (t *openapiTool) ProcessRequest(ctx tool.Context, req *model.LLMRequest) error {
+	return toolutils.PackTool(req, t)
+}
+
+func (t *openapiTool) Declaration() *genai.FunctionDeclaration {
+	return t.funcDeclaration
+}
+
+// Run calls the operation of the tool with the arguments, and returns the
+// decoded JSON, or the text, of the response in its "output".
+func (t *openapiTool) Run(ctx tool.Context, args any) (map[string]any, error) {
+	argsMap, err := toArgs(args)
+	if err != nil {
+		return nil, fmt.Errorf("invalid arguments of tool %q: %w", t.name, err)
+	}
+	req, err := t.newRequest(ctx, argsMap)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call operation %s %s: %w", t.op.method, t.op.path, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response of operation %s %s: %w", t.op.method, t.op.path, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body := string(data)
+		if len(body) > maxErrorBodyLength {
+			body = body[:maxErrorBodyLength] + "..."
+		}
+		return nil, fmt.Errorf("operation %s %s failed with status %s: %s", t.op.method, t.op.path, resp.Status, body)
+	}
+	return map[string]any{"output": decodeResponse(resp.Header.Get("Content-Type"), data)}, nil
+}
+
+func toArgs(args any) (map[string]any, error) {
+	switch args := args.(type) {
+	case nil:
+		return map[string]any{}, nil
+	case map[string]any:
+		return args, nil
+	}
+	data, err := json.Marshal(args)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (t *openapiTool) newRequest(ctx tool.Context, args map[string]any) (*http.Request, error) {
+	path := t.op.path
+	query := url.Values{}
+	header := http.Header{}
+	var cookies []*http.Cookie
+	for _, p := range t.op.params {
+		v, ok := args[p.arg]
+		if !ok || v == nil {
+			if p.required {
+				return nil, fmt.Errorf("missing required argument %q of tool %q", p.arg, t.name)
+			}
+			continue
+		}
+		switch p.in {
+		case "path":
+			path = strings.ReplaceAll(path, "{"+p.name+"}", url.PathEscape(formatValue(v)))
+		case "query":
+			if list, ok := v.([]any); ok {
+				for _, e := range list {
+					query.Add(p.name, formatValue(e))
+				}
+			} else {
+				query.Add(p.name, formatValue(v))
+			}
+		case "header":
+			header.Set(p.name, formatValue(v))
+		case "cookie":
+			cookies = append(cookies, &http.Cookie{Name: p.name, Value: formatValue(v)})
+		}
+	}
+
+	var body io.Reader
+	if t.op.body != nil {
+		v, ok := args["body"]
+		if ok && v != nil {
+			data, err := encodeBody(t.op.body.mediaType, v)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode body of tool %q: %w", t.name, err)
+			}
+			body = bytes.NewReader(data)
+			header.Set("Content-Type", t.op.body.mediaType)
+		} else if t.op.body.required {
+			return nil, fmt.Errorf("missing required argument %q of tool %q", "body", t.name)
+		}
+	}
+
+	t.auth.apply(query, header, &cookies)
+	u := t.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, t.op.method, u, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request of tool %q: %w", t.name, err)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "application/json, */*;q=0.8")
+	}
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	return req, nil
+}
+
+// formatValue formats the value of a parameter: the objects and arrays are
+// formatted as JSON.
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool, int, int64, json.Number:
+		return fmt.Sprint(v)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	}
+}
+
+func encodeBody(mediaType string, v any) ([]byte, error) {
+	switch {
+	case mediaType == "application/x-www-form-urlencoded":
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("form body must be an object, got %T", v)
+		}
+		form := url.Values{}
+		for k, e := range m {
+			if list, ok := e.([]any); ok {
+				for _, item := range list {
+					form.Add(k, formatValue(item))
+				}
+			} else {
+				form.Add(k, formatValue(e))
+			}
+		}
+		return []byte(form.Encode()), nil
+	case strings.HasPrefix(mediaType, "text/"):
+		return []byte(formatValue(v)), nil
+	default:
+		return json.Marshal(v)
+	}
+}
+
+// decodeResponse returns the decoded JSON of the JSON responses, and the
+// text of the others.
+func decodeResponse(contentType string, data []byte) any {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") {
+		var v any
+		if err := json.Unmarshal(data, &v); err == nil {
+			return v
+		}
+	}
+	return string(data)
+}
+
+// auth authenticates the calls of the tools.
+type auth struct {
+	apiKey      *APIKeyAuth
+	bearerToken string
+}
+
+func (a *auth) apply(query url.Values, header http.Header, cookies *[]*http.Cookie) {
+	if a.bearerToken != "" {
+		header.Set("Authorization", "Bearer "+a.bearerToken)
+	}
+	if a.apiKey == nil {
+		return
+	}
+	switch a.apiKey.In {
+	case "query":
+		query.Set(a.apiKey.Name, a.apiKey.Key)
+	case "cookie":
+		*cookies = append(*cookies, &http.Cookie{Name: a.apiKey.Name, Value: a.apiKey.Key})
+	default:
+		header.Set(a.apiKey.Name, a.apiKey.Key)
+	}
+}
@@ -22,6 +22,7 @@
 package preloadmemorytool
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -38,19 +39,49 @@ They may be useful for answering the user's current query.
 %s
 </PAST_CONVERSATIONS>`
 
+// MetadataKey is the key of the custom metadata of the model response events
+// which lists the memories injected in the request.
+const MetadataKey = "adk_preloaded_memories"
+
+// DefaultMaxTokens is the default token budget of the injected memories.
+const DefaultMaxTokens = 2000
+
+// Config is the configuration of the preload memory tool.
+type Config struct {
+	// TopK is the maximum number of memories to inject. All the memories
+	// found are injected if it is zero.
+	TopK int
+	// MinScore excludes the memories scored at or below it. The memories
+	// without a score, see [memory.Entry.Score], are never excluded.
+	MinScore float64
+	// MaxTokens is the approximate token budget of the injected memories.
+	// Defaults to DefaultMaxTokens.
+	MaxTokens int
+}
+
 // preloadMemoryTool is a tool that preloads the memory for the current user.
 // It is automatically executed for each LLM request and will not be called
 // directly by the model.
 type preloadMemoryTool struct {
 	name        string
 	description string
+	cfg         Config
 }
 
 // New creates a new preloadMemoryTool.
 func New() *preloadMemoryTool {
+	return NewWithConfig(Config{})
+}
+
+// NewWithConfig creates a new preloadMemoryTool with the given configuration.
+func NewWithConfig(cfg Config) *preloadMemoryTool {
+	if cfg.MaxTokens <= 0 {
+		cfg.MaxTokens = DefaultMaxTokens
+	}
 	return &preloadMemoryTool{
 		name:        "preload_memory",
 		description: "Preloads relevant memory for the current user.",
+		cfg:         cfg,
 	}
 }
 
@@ -71,6 +102,8 @@ func (t *preloadMemoryTool) IsLongRunning() bool {
 
 // ProcessRequest processes the LLM request by searching memory using the user's
 // current query and injecting relevant past conversations into system instructions.
+// The injected memories are listed in the custom metadata of the model response
+// event under MetadataKey. It does nothing when the agent has no memory service.
 func (t *preloadMemoryTool) ProcessRequest(ctx tool.Context, req *model.LLMRequest) error {
 	userContent := ctx.UserContent()
 	if userContent == nil || len(userContent.Parts) == 0 ||
@@ -80,6 +113,9 @@ func (t *preloadMemoryTool) ProcessRequest(ctx tool.Context, req *model.LLMReque
 	userQuery := userContent.Parts[0].Text
 
 	searchResponse, err := ctx.SearchMemory(ctx, userQuery)
+	if errors.Is(err, tool.ErrMemoryNotSet) {
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("preload memory search failed: %v", err)
 	}
@@ -88,32 +124,95 @@ func (t *preloadMemoryTool) ProcessRequest(ctx tool.Context, req *model.LLMReque
 		return nil
 	}
 
-	memoryText := formatMemories(searchResponse.Memories)
+	memoryText, injected := t.formatMemories(searchResponse.Memories)
 	if memoryText == "" {
 		return nil
 	}
 
 	utils.AppendInstructions(req, fmt.Sprintf(preloadInstructions, memoryText))
+	if req.CustomMetadata == nil {
+		req.CustomMetadata = make(map[string]any)
+	}
+	req.CustomMetadata[MetadataKey] = injected
 	return nil
 }
 
-func formatMemories(memories []memory.Entry) string {
-	var lines []string
+// formatMemories formats the memories within the limits of the configuration.
+// It returns the formatted text and the description of the memories included.
+func (t *preloadMemoryTool) formatMemories(memories []memory.Entry) (string, []any) {
+	var (
+		lines    []string
+		injected []any
+		tokens   int
+	)
 	for _, mem := range memories {
+		if t.cfg.TopK > 0 && len(injected) >= t.cfg.TopK {
+			break
+		}
+		if mem.Score != 0 && mem.Score <= t.cfg.MinScore {
+			continue
+		}
 		memText := extractText(mem)
 		if memText == "" {
 			continue
 		}
 
+		var entry []string
 		if !mem.Timestamp.IsZero() {
-			lines = append(lines, fmt.Sprintf("Time: %s", mem.Timestamp.Format(time.RFC3339)))
+			entry = append(entry, fmt.Sprintf("Time: %s", mem.Timestamp.Format(time.RFC3339)))
+		}
+		if mem.SessionID != "" {
+			entry = append(entry, fmt.Sprintf("Source: session %s", mem.SessionID))
 		}
 		if mem.Author != "" {
-			memText = fmt.Sprintf("%s: %s", mem.Author, memText)
+			entry = append(entry, fmt.Sprintf("%s: %s", mem.Author, memText))
+		} else {
+			entry = append(entry, memText)
 		}
-		lines = append(lines, memText)
+
+		// The memories are sorted by relevance: the ones over the budget are
+		// dropped rather than the budget being filled with less relevant ones.
+		entryTokens := estimateTokens(entry)
+		if tokens+entryTokens > t.cfg.MaxTokens {
+			break
+		}
+		tokens += entryTokens
+		lines = append(lines, entry...)
+		injected = append(injected, describe(mem, memText))
+	}
+	return strings.Join(lines, "\n"), injected
+}
+
+// estimateTokens approximates the number of tokens of lines, at about four
+// characters per token.
+func estimateTokens(lines []string) int {
+	n := 0
+	for _, l := range lines {
+		n += len(l) + 1
+	}
+	return (n + 3) / 4
+}
+
+// describe returns the description of an injected memory recorded in the
+// event metadata.
+func describe(mem memory.Entry, text string) map[string]any {
+	d := map[string]any{"text": text}
+	if mem.Author != "" {
+		d["author"] = mem.Author
+	}
+	if !mem.Timestamp.IsZero() {
+		d["timestamp"] = mem.Timestamp.Format(time.RFC3339)
+	}
+	if mem.SessionID != "" {
+		d["sessionId"] = mem.SessionID
+	}
+	if mem.EventID != "" {
+		d["eventId"] = mem.EventID
+	}
+	if mem.Score != 0 {
+		d["score"] = mem.Score
 	}
-	return strings.Join(lines, "\n")
+	return d
 }
 
 func extractText(mem memory.Entry) string {
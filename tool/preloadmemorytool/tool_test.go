@@ -21,8 +21,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"google.golang.org/genai"
 
+	"google.golang.org/adk/agent"
 	icontext "google.golang.org/adk/internal/context"
 	"google.golang.org/adk/internal/toolinternal"
 	"google.golang.org/adk/memory"
@@ -64,13 +66,17 @@ func TestPreloadMemoryTool_BasicProperties(t *testing.T) {
 
 func TestPreloadMemoryTool_ProcessRequest(t *testing.T) {
 	tests := []struct {
-		name             string
-		userContent      *genai.Content
-		memories         []memory.Entry
-		searchErr        error
-		wantErr          bool
-		wantInstruction  bool
-		wantTextContains []string
+		name                string
+		cfg                 preloadmemorytool.Config
+		noMemory            bool
+		userContent         *genai.Content
+		memories            []memory.Entry
+		searchErr           error
+		wantErr             bool
+		wantInstruction     bool
+		wantTextContains    []string
+		wantTextNotContains []string
+		wantMetadata        []any
 	}{
 		{
 			name:            "nil user content",
@@ -160,6 +166,89 @@ func TestPreloadMemoryTool_ProcessRequest(t *testing.T) {
 			wantInstruction:  true,
 			wantTextContains: []string{"user: No timestamp"},
 		},
+		{
+			name:            "no memory service",
+			noMemory:        true,
+			userContent:     genai.NewContentFromText("test query", genai.RoleUser),
+			wantInstruction: false,
+		},
+		{
+			name:        "source label and metadata",
+			userContent: genai.NewContentFromText("test", genai.RoleUser),
+			memories: []memory.Entry{
+				{
+					Author:    "user",
+					Timestamp: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+					Content:   genai.NewContentFromText("Hello", genai.RoleUser),
+					SessionID: "s1",
+					EventID:   "e1",
+					Score:     0.9,
+				},
+			},
+			wantInstruction:  true,
+			wantTextContains: []string{"Source: session s1", "user: Hello"},
+			wantMetadata: []any{
+				map[string]any{
+					"author":    "user",
+					"timestamp": "2025-01-01T12:00:00Z",
+					"sessionId": "s1",
+					"eventId":   "e1",
+					"score":     0.9,
+					"text":      "Hello",
+				},
+			},
+		},
+		{
+			name:        "top k",
+			cfg:         preloadmemorytool.Config{TopK: 1},
+			userContent: genai.NewContentFromText("test", genai.RoleUser),
+			memories: []memory.Entry{
+				{Content: genai.NewContentFromText("First memory", genai.RoleUser)},
+				{Content: genai.NewContentFromText("Second memory", genai.RoleUser)},
+			},
+			wantInstruction:     true,
+			wantTextContains:    []string{"First memory"},
+			wantTextNotContains: []string{"Second memory"},
+			wantMetadata:        []any{map[string]any{"text": "First memory"}},
+		},
+		{
+			name:        "min score",
+			cfg:         preloadmemorytool.Config{MinScore: 0.5},
+			userContent: genai.NewContentFromText("test", genai.RoleUser),
+			memories: []memory.Entry{
+				{Content: genai.NewContentFromText("Relevant memory", genai.RoleUser), Score: 0.8},
+				{Content: genai.NewContentFromText("Irrelevant memory", genai.RoleUser), Score: 0.5},
+				{Content: genai.NewContentFromText("Unscored memory", genai.RoleUser)},
+			},
+			wantInstruction:     true,
+			wantTextContains:    []string{"Relevant memory", "Unscored memory"},
+			wantTextNotContains: []string{"Irrelevant memory"},
+			wantMetadata: []any{
+				map[string]any{"text": "Relevant memory", "score": 0.8},
+				map[string]any{"text": "Unscored memory"},
+			},
+		},
+		{
+			name:        "token budget",
+			cfg:         preloadmemorytool.Config{MaxTokens: 5},
+			userContent: genai.NewContentFromText("test", genai.RoleUser),
+			memories: []memory.Entry{
+				{Content: genai.NewContentFromText("Short memory", genai.RoleUser)},
+				{Content: genai.NewContentFromText("Memory over the budget", genai.RoleUser)},
+				{Content: genai.NewContentFromText("Tiny", genai.RoleUser)},
+			},
+			wantInstruction:     true,
+			wantTextContains:    []string{"Short memory"},
+			wantTextNotContains: []string{"Memory over the budget", "Tiny"},
+			wantMetadata:        []any{map[string]any{"text": "Short memory"}},
+		},
+		{
+			name:            "no memory within the budget",
+			cfg:             preloadmemorytool.Config{MaxTokens: 1},
+			userContent:     genai.NewContentFromText("test", genai.RoleUser),
+			memories:        []memory.Entry{{Content: genai.NewContentFromText("Short memory", genai.RoleUser)}},
+			wantInstruction: false,
+		},
 		{
 			name:        "memory entry with empty content",
 			userContent: genai.NewContentFromText("test", genai.RoleUser),
@@ -176,10 +265,14 @@ func TestPreloadMemoryTool_ProcessRequest(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			tc := createToolContext(t, &mockMemory{memories: tt.memories, err: tt.searchErr}, tt.userContent)
+			var mem agent.Memory
+			if !tt.noMemory {
+				mem = &mockMemory{memories: tt.memories, err: tt.searchErr}
+			}
+			tc := createToolContext(t, mem, tt.userContent)
 			llmRequest := &model.LLMRequest{}
 
-			pmt := preloadmemorytool.New()
+			pmt := preloadmemorytool.NewWithConfig(tt.cfg)
 
 			err := pmt.ProcessRequest(tc, llmRequest)
 			if tt.wantErr {
@@ -202,12 +295,25 @@ func TestPreloadMemoryTool_ProcessRequest(t *testing.T) {
 						t.Errorf("Instruction should contain %q, got: %v", want, instruction)
 					}
 				}
+				for _, notWant := range tt.wantTextNotContains {
+					if strings.Contains(instruction, notWant) {
+						t.Errorf("Instruction should not contain %q, got: %v", notWant, instruction)
+					}
+				}
+			}
+
+			if tt.wantMetadata != nil {
+				if diff := cmp.Diff(tt.wantMetadata, llmRequest.CustomMetadata[preloadmemorytool.MetadataKey]); diff != "" {
+					t.Errorf("CustomMetadata[%q] mismatch (-want +got):\n%s", preloadmemorytool.MetadataKey, diff)
+				}
+			} else if !tt.wantInstruction && llmRequest.CustomMetadata != nil {
+				t.Errorf("CustomMetadata = %v, want nil", llmRequest.CustomMetadata)
 			}
 		})
 	}
 }
 
-func createToolContext(t *testing.T, mem *mockMemory, userContent *genai.Content) tool.Context {
+func createToolContext(t *testing.T, mem agent.Memory, userContent *genai.Content) tool.Context {
 	t.Helper()
 
 	ctx := icontext.NewInvocationContext(t.Context(), icontext.InvocationContextParams{
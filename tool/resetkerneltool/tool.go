@@ -0,0 +1,65 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package resetkerneltool provides a tool that allows an agent to reset the
+// kernel of its stateful code executions.
+package resetkerneltool
+
+import (
+	"fmt"
+
+	"google.golang.org/adk/codeexecutor"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+// Name is the name of the tool.
+const Name = "reset_code_kernel"
+
+type resetArgs struct{}
+
+// New creates an instance of a reset_code_kernel tool, resetting the kernels
+// of the executor, which must be the code executor of the agent.
+//
+// The tool stops the kernel of the session, whose next code execution starts
+// from a blank state.
+func New(executor codeexecutor.KernelResetter) (tool.Tool, error) {
+	if executor == nil {
+		return nil, fmt.Errorf("executor is required")
+	}
+	reset := func(ctx tool.Context, _ resetArgs) (map[string]any, error) {
+		v, err := ctx.State().Get(codeexecutor.KernelStateKey)
+		kernelID, _ := v.(string)
+		if err != nil || kernelID == "" {
+			return map[string]any{"reset": false, "message": "There is no kernel to reset."}, nil
+		}
+		if err := executor.ResetKernel(ctx, kernelID); err != nil {
+			return nil, fmt.Errorf("failed to reset kernel %q: %w", kernelID, err)
+		}
+		// Forget the kernel, for the next execution not to report it lost.
+		if err := ctx.State().Set(codeexecutor.KernelStateKey, ""); err != nil {
+			return nil, err
+		}
+		return map[string]any{"reset": true}, nil
+	}
+	t, err := functiontool.New(functiontool.Config{
+		Name:        Name,
+		Description: "Resets the kernel running the code, clearing the variables, imports and files of the previous code executions.",
+		Sequential:  true,
+	}, reset)
+	if err != nil {
+		return nil, fmt.Errorf("error creating reset code kernel tool: %w", err)
+	}
+	return t, nil
+}
@@ -0,0 +1,90 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resetkerneltool_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"google.golang.org/adk/codeexecutor"
+	icontext "google.golang.org/adk/internal/context"
+	"google.golang.org/adk/internal/toolinternal"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool/resetkerneltool"
+)
+
+type fakeResetter struct {
+	reset []string
+}
+
+func (r *fakeResetter) ResetKernel(_ context.Context, kernelID string) error {
+	r.reset = append(r.reset, kernelID)
+	return nil
+}
+
+func TestResetKernelTool(t *testing.T) {
+	tests := []struct {
+		name      string
+		state     map[string]any
+		want      map[string]any
+		wantReset []string
+	}{
+		{
+			name:      "kernel",
+			state:     map[string]any{codeexecutor.KernelStateKey: "k1"},
+			want:      map[string]any{"reset": true},
+			wantReset: []string{"k1"},
+		},
+		{
+			name: "no kernel",
+			want: map[string]any{"reset": false, "message": "There is no kernel to reset."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetter := &fakeResetter{}
+			reset, err := resetkerneltool.New(resetter)
+			if err != nil {
+				t.Fatalf("New() failed: %v", err)
+			}
+			sess, err := session.InMemoryService().Create(t.Context(), &session.CreateRequest{AppName: "app", UserID: "user", State: tt.state})
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx := toolinternal.NewToolContext(icontext.NewInvocationContext(t.Context(), icontext.InvocationContextParams{Session: sess.Session}), "call", nil, nil)
+			got, err := reset.(toolinternal.FunctionTool).Run(ctx, map[string]any{})
+			if err != nil {
+				t.Fatalf("Run() failed: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Run() mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantReset, resetter.reset); diff != "" {
+				t.Errorf("reset kernels mismatch (-want +got):\n%s", diff)
+			}
+			if len(tt.wantReset) > 0 && ctx.Actions().StateDelta[codeexecutor.KernelStateKey] != "" {
+				t.Errorf("state delta = %v, want the kernel forgotten", ctx.Actions().StateDelta)
+			}
+		})
+	}
+}
+
+func TestNew_NilExecutor(t *testing.T) {
+	if _, err := resetkerneltool.New(nil); err == nil {
+		t.Error("New(nil) succeeded, want an error")
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package retrievaltool provides a tool retrieving the chunks of a corpus
+// relevant to a query, e.g. from a Vertex AI Search datastore or a Vertex AI
+// RAG corpus, to ground the answers of the model.
+package retrievaltool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/internal/toolinternal"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+// Chunk is a chunk of a document of the corpus.
+type Chunk struct {
+	// Text is the content of the chunk.
+	Text string
+	// SourceURI is the URI of the document of the chunk.
+	SourceURI string
+	// Title is the title of the document of the chunk.
+	Title string
+	// Score is the relevance of the chunk to the query, higher for more
+	// relevant chunks.
+	Score float64
+}
+
+// Retriever retrieves the chunks of a corpus relevant to a query.
+type Retriever interface {
+	// Retrieve returns at most maxResults chunks relevant to the query.
+	Retrieve(ctx context.Context, query string, maxResults int) ([]Chunk, error)
+}
+
+// DefaultMaxResults is the default MaxResults of the tool.
+const DefaultMaxResults = 5
+
+// Config is the configuration of the tool.
+type Config struct {
+	// Name of the tool, "retrieve" by default.
+	Name string
+	// Description of the tool, which tells the model what the corpus is
+	// about. By default, the tool retrieves "documents".
+	Description string
+	// Retriever retrieves the chunks, e.g. the one of
+	// [NewVertexAISearchRetriever] or [NewVertexRAGRetriever].
+	Retriever Retriever
+	// MaxResults is the maximum number of chunks given to the model.
+	// DefaultMaxResults if zero.
+	MaxResults int
+	// SimilarityThreshold is the minimum Score of the chunks given to the
+	// model.
+	SimilarityThreshold float64
+}
+
+// Args are the arguments of the tool.
+type Args struct {
+	Query string `json:"query" jsonschema:"description=query of the documents to retrieve"`
+}
+
+// Result is the result of the tool.
+type Result struct {
+	// Chunks are the retrieved chunks, the most relevant first.
+	Chunks []ResultChunk `json:"chunks"`
+}
+
+// ResultChunk is a retrieved chunk, as given to the model.
+type ResultChunk struct {
+	// Rank is the rank of the chunk, from 1.
+	Rank      int     `json:"rank"`
+	Text      string  `json:"text"`
+	SourceURI string  `json:"source_uri,omitempty"`
+	Title     string  `json:"title,omitempty"`
+	Score     float64 `json:"score"`
+}
+
+// New creates a retrieval tool.
+//
+// The function response events of its calls have the grounding metadata of
+// the retrieved chunks, for UIs to render their sources as citations.
+func New(cfg Config) (tool.Tool, error) {
+	if cfg.Retriever == nil {
+		return nil, errors.New("retriever is required")
+	}
+	if cfg.MaxResults < 0 {
+		return nil, fmt.Errorf("invalid max results %d", cfg.MaxResults)
+	}
+	if cfg.MaxResults == 0 {
+		cfg.MaxResults = DefaultMaxResults
+	}
+	if cfg.Name == "" {
+		cfg.Name = "retrieve"
+	}
+	if cfg.Description == "" {
+		cfg.Description = "Retrieves the passages of the documents relevant to the query, the most relevant first, with their sources."
+	}
+	t, err := functiontool.New(functiontool.Config{
+		Name:        cfg.Name,
+		Description: cfg.Description,
+	}, func(ctx tool.Context, args Args) (*Result, error) {
+		return retrieve(ctx, cfg, args.Query)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating retrieval tool: %w", err)
+	}
+	return t, nil
+}
+
+func retrieve(ctx tool.Context, cfg Config, query string) (*Result, error) {
+	if query == "" {
+		return nil, errors.New("query is required")
+	}
+	chunks, err := cfg.Retriever.Retrieve(ctx, query, cfg.MaxResults)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve chunks: %w", err)
+	}
+	sort.SliceStable(chunks, func(i, j int) bool { return chunks[i].Score > chunks[j].Score })
+
+	result := &Result{Chunks: []ResultChunk{}}
+	md := &genai.GroundingMetadata{RetrievalQueries: []string{query}}
+	for _, c := range chunks {
+		if c.Score < cfg.SimilarityThreshold || len(result.Chunks) == cfg.MaxResults {
+			break
+		}
+		result.Chunks = append(result.Chunks, ResultChunk{
+			Rank:      len(result.Chunks) + 1,
+			Text:      c.Text,
+			SourceURI: c.SourceURI,
+			Title:     c.Title,
+			Score:     c.Score,
+		})
+		md.GroundingChunks = append(md.GroundingChunks, &genai.GroundingChunk{
+			RetrievedContext: &genai.GroundingChunkRetrievedContext{
+				URI:   c.SourceURI,
+				Title: c.Title,
+				Text:  c.Text,
+			},
+		})
+	}
+	toolinternal.SetGroundingMetadata(ctx, md)
+	return result, nil
+}
@@ -0,0 +1,109 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retrievaltool_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/internal/testutil"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/retrievaltool"
+)
+
+type fakeRetriever struct {
+	chunks     []retrievaltool.Chunk
+	query      string
+	maxResults int
+}
+
+func (r *fakeRetriever) Retrieve(_ context.Context, query string, maxResults int) ([]retrievaltool.Chunk, error) {
+	r.query, r.maxResults = query, maxResults
+	return r.chunks, nil
+}
+
+func TestRetrievalTool(t *testing.T) {
+	retriever := &fakeRetriever{chunks: []retrievaltool.Chunk{
+		{Text: "Refunds take 5 days.", SourceURI: "gs://docs/refunds.pdf", Title: "Refunds", Score: 0.7},
+		{Text: "Returns are free.", SourceURI: "gs://docs/returns.pdf", Title: "Returns", Score: 0.9},
+		{Text: "Shipping is fast.", SourceURI: "gs://docs/shipping.pdf", Title: "Shipping", Score: 0.2},
+		{Text: "Refunds are in euros.", SourceURI: "gs://docs/refunds.pdf", Title: "Refunds", Score: 0.6},
+	}}
+	retrieve, err := retrievaltool.New(retrievaltool.Config{
+		Retriever:           retriever,
+		MaxResults:          2,
+		SimilarityThreshold: 0.5,
+	})
+	if err != nil {
+		t.Fatalf("retrievaltool.New() failed: %v", err)
+	}
+	llm := &testutil.MockModel{Responses: []*genai.Content{
+		genai.NewContentFromFunctionCall("retrieve", map[string]any{"query": "refund policy"}, genai.RoleModel),
+		genai.NewContentFromText("Refunds take 5 days.", genai.RoleModel),
+	}}
+	a, err := llmagent.New(llmagent.Config{Name: "support", Model: llm, Tools: []tool.Tool{retrieve}})
+	if err != nil {
+		t.Fatalf("llmagent.New() failed: %v", err)
+	}
+
+	var response *genai.FunctionResponse
+	var grounding *genai.GroundingMetadata
+	for ev, err := range testutil.NewTestAgentRunner(t, a).Run(t, "session", "How do refunds work?") {
+		if err != nil {
+			t.Fatalf("Run() failed: %v", err)
+		}
+		if ev.Content != nil && ev.Content.Parts[0].FunctionResponse != nil {
+			response, grounding = ev.Content.Parts[0].FunctionResponse, ev.GroundingMetadata
+		}
+	}
+	if retriever.query != "refund policy" || retriever.maxResults != 2 {
+		t.Errorf("Retrieve() called with (%q, %d), want (%q, 2)", retriever.query, retriever.maxResults, "refund policy")
+	}
+	if response == nil {
+		t.Fatalf("no function response")
+	}
+	wantResponse := map[string]any{"chunks": []any{
+		map[string]any{"rank": 1.0, "text": "Returns are free.", "source_uri": "gs://docs/returns.pdf", "title": "Returns", "score": 0.9},
+		map[string]any{"rank": 2.0, "text": "Refunds take 5 days.", "source_uri": "gs://docs/refunds.pdf", "title": "Refunds", "score": 0.7},
+	}}
+	if diff := cmp.Diff(wantResponse, response.Response); diff != "" {
+		t.Errorf("function response mismatch (-want +got):\n%s", diff)
+	}
+	wantGrounding := &genai.GroundingMetadata{
+		RetrievalQueries: []string{"refund policy"},
+		GroundingChunks: []*genai.GroundingChunk{
+			{RetrievedContext: &genai.GroundingChunkRetrievedContext{URI: "gs://docs/returns.pdf", Title: "Returns", Text: "Returns are free."}},
+			{RetrievedContext: &genai.GroundingChunkRetrievedContext{URI: "gs://docs/refunds.pdf", Title: "Refunds", Text: "Refunds take 5 days."}},
+		},
+	}
+	if diff := cmp.Diff(wantGrounding, grounding); diff != "" {
+		t.Errorf("grounding metadata mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestNew_Errors(t *testing.T) {
+	for _, cfg := range []retrievaltool.Config{
+		{},
+		{Retriever: &fakeRetriever{}, MaxResults: -1},
+	} {
+		if _, err := retrievaltool.New(cfg); err == nil {
+			t.Errorf("New(%+v) succeeded, want an error", cfg)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retrievaltool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	discoveryengine "google.golang.org/api/discoveryengine/v1"
+	"google.golang.org/api/option"
+)
+
+// VertexAISearchConfig is the configuration of the retriever of a Vertex AI
+// Search datastore.
+type VertexAISearchConfig struct {
+	// DataStore is the resource name of the datastore, e.g.
+	// "projects/p/locations/global/collections/default_collection/dataStores/d",
+	// or of a search engine, e.g.
+	// "projects/p/locations/global/collections/default_collection/engines/e".
+	DataStore string
+	// Chunks retrieves the chunks of the documents, for the datastores with
+	// chunking enabled, instead of the extractive segments of the documents.
+	Chunks bool
+}
+
+var locationPattern = regexp.MustCompile(`/locations/([^/]+)/`)
+
+// NewVertexAISearchRetriever returns a retriever searching a Vertex AI Search
+// datastore with its default serving config. The chunks are scored with the
+// relevance scores of the search.
+//
+// The client uses the regional endpoint of the location of the datastore,
+// unless the options have another endpoint.
+func NewVertexAISearchRetriever(ctx context.Context, cfg VertexAISearchConfig, opts ...option.ClientOption) (Retriever, error) {
+	m := locationPattern.FindStringSubmatch(cfg.DataStore + "/")
+	if m == nil {
+		return nil, fmt.Errorf("invalid datastore %q", cfg.DataStore)
+	}
+	if location := m[1]; location != "global" {
+		opts = append([]option.ClientOption{option.WithEndpoint("https://" + location + "-discoveryengine.googleapis.com/")}, opts...)
+	}
+	service, err := discoveryengine.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Vertex AI Search client: %w", err)
+	}
+	return &vertexAISearchRetriever{service: service, cfg: cfg}, nil
+}
+
+type vertexAISearchRetriever struct {
+	service *discoveryengine.Service
+	cfg     VertexAISearchConfig
+}
+
+func (r *vertexAISearchRetriever) Retrieve(ctx context.Context, query string, maxResults int) ([]Chunk, error) {
+	req := &discoveryengine.GoogleCloudDiscoveryengineV1SearchRequest{
+		Query:              query,
+		PageSize:           int64(maxResults),
+		RelevanceScoreSpec: &discoveryengine.GoogleCloudDiscoveryengineV1SearchRequestRelevanceScoreSpec{ReturnRelevanceScore: true},
+	}
+	if r.cfg.Chunks {
+		req.ContentSearchSpec = &discoveryengine.GoogleCloudDiscoveryengineV1SearchRequestContentSearchSpec{SearchResultMode: "CHUNKS"}
+	} else {
+		req.ContentSearchSpec = &discoveryengine.GoogleCloudDiscoveryengineV1SearchRequestContentSearchSpec{
+			ExtractiveContentSpec: &discoveryengine.GoogleCloudDiscoveryengineV1SearchRequestContentSearchSpecExtractiveContentSpec{
+				MaxExtractiveSegmentCount:    1,
+				ReturnExtractiveSegmentScore: true,
+			},
+			SnippetSpec: &discoveryengine.GoogleCloudDiscoveryengineV1SearchRequestContentSearchSpecSnippetSpec{ReturnSnippet: true},
+		}
+	}
+	// The serving configs of the engines have the same API as the ones of
+	// the datastores.
+	servingConfig := r.cfg.DataStore + "/servingConfigs/default_search"
+	resp, err := r.service.Projects.Locations.Collections.DataStores.ServingConfigs.Search(servingConfig, req).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to search %q: %w", r.cfg.DataStore, err)
+	}
+	var chunks []Chunk
+	for _, res := range resp.Results {
+		chunk, err := searchResultChunk(res)
+		if err != nil {
+			return nil, err
+		}
+		if chunk.Text != "" {
+			chunks = append(chunks, chunk)
+		}
+	}
+	return chunks, nil
+}
+
+// derivedDocument is the data derived by Vertex AI Search from the
+// unstructured documents.
+type derivedDocument struct {
+	Link               string `json:"link"`
+	Title              string `json:"title"`
+	ExtractiveSegments []struct {
+		Content        string  `json:"content"`
+		RelevanceScore float64 `json:"relevanceScore"`
+	} `json:"extractive_segments"`
+	Snippets []struct {
+		Snippet string `json:"snippet"`
+	} `json:"snippets"`
+}
+
+func searchResultChunk(res *discoveryengine.GoogleCloudDiscoveryengineV1SearchResponseSearchResult) (Chunk, error) {
+	var chunk Chunk
+	if scores, ok := res.ModelScores["relevance_score"]; ok && len(scores.Values) > 0 {
+		chunk.Score = scores.Values[0]
+	}
+	if c := res.Chunk; c != nil {
+		chunk.Text = c.Content
+		if c.RelevanceScore != 0 {
+			chunk.Score = c.RelevanceScore
+		}
+		if md := c.DocumentMetadata; md != nil {
+			chunk.SourceURI, chunk.Title = md.Uri, md.Title
+		}
+		return chunk, nil
+	}
+	if res.Document == nil || len(res.Document.DerivedStructData) == 0 {
+		return chunk, nil
+	}
+	var doc derivedDocument
+	if err := json.Unmarshal(res.Document.DerivedStructData, &doc); err != nil {
+		return chunk, fmt.Errorf("failed to decode search result %q: %w", res.Id, err)
+	}
+	chunk.SourceURI, chunk.Title = doc.Link, doc.Title
+	switch {
+	case len(doc.ExtractiveSegments) > 0:
+		chunk.Text = doc.ExtractiveSegments[0].Content
+		if chunk.Score == 0 {
+			chunk.Score = doc.ExtractiveSegments[0].RelevanceScore
+		}
+	case len(doc.Snippets) > 0:
+		chunk.Text = doc.Snippets[0].Snippet
+	}
+	return chunk, nil
+}
@@ -0,0 +1,120 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retrievaltool_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/api/option"
+
+	"google.golang.org/adk/tool/retrievaltool"
+)
+
+func TestVertexAISearchRetriever(t *testing.T) {
+	const dataStore = "projects/p/locations/global/collections/default_collection/dataStores/d"
+	tests := []struct {
+		name     string
+		chunks   bool
+		results  []map[string]any
+		wantMode string
+		want     []retrievaltool.Chunk
+	}{
+		{
+			name: "documents",
+			results: []map[string]any{
+				{
+					"id":          "1",
+					"modelScores": map[string]any{"relevance_score": map[string]any{"values": []float64{0.8}}},
+					"document": map[string]any{"derivedStructData": map[string]any{
+						"link":                "gs://docs/refunds.pdf",
+						"title":               "Refunds",
+						"extractive_segments": []any{map[string]any{"content": "Refunds take 5 days.", "relevanceScore": 0.5}},
+					}},
+				},
+				{
+					"id": "2",
+					"document": map[string]any{"derivedStructData": map[string]any{
+						"link":     "https://example.com/returns",
+						"snippets": []any{map[string]any{"snippet": "Returns are free."}},
+					}},
+				},
+				{"id": "3", "document": map[string]any{}},
+			},
+			want: []retrievaltool.Chunk{
+				{Text: "Refunds take 5 days.", SourceURI: "gs://docs/refunds.pdf", Title: "Refunds", Score: 0.8},
+				{Text: "Returns are free.", SourceURI: "https://example.com/returns"},
+			},
+		},
+		{
+			name:   "chunks",
+			chunks: true,
+			results: []map[string]any{{
+				"id": "1",
+				"chunk": map[string]any{
+					"content":          "Refunds take 5 days.",
+					"relevanceScore":   0.6,
+					"documentMetadata": map[string]any{"uri": "gs://docs/refunds.pdf", "title": "Refunds"},
+				},
+			}},
+			wantMode: "CHUNKS",
+			want:     []retrievaltool.Chunk{{Text: "Refunds take 5 days.", SourceURI: "gs://docs/refunds.pdf", Title: "Refunds", Score: 0.6}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got struct {
+				Query             string `json:"query"`
+				PageSize          int    `json:"pageSize"`
+				ContentSearchSpec struct {
+					SearchResultMode string `json:"searchResultMode"`
+				} `json:"contentSearchSpec"`
+			}
+			mux := http.NewServeMux()
+			mux.HandleFunc("POST /v1/"+dataStore+"/servingConfigs/default_search:search", func(w http.ResponseWriter, r *http.Request) {
+				if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+					t.Errorf("failed to decode request: %v", err)
+				}
+				json.NewEncoder(w).Encode(map[string]any{"results": tt.results})
+			})
+			server := httptest.NewServer(mux)
+			t.Cleanup(server.Close)
+
+			retriever, err := retrievaltool.NewVertexAISearchRetriever(t.Context(),
+				retrievaltool.VertexAISearchConfig{DataStore: dataStore, Chunks: tt.chunks},
+				option.WithEndpoint(server.URL+"/"), option.WithoutAuthentication())
+			if err != nil {
+				t.Fatalf("NewVertexAISearchRetriever() failed: %v", err)
+			}
+			chunks, err := retriever.Retrieve(t.Context(), "refunds", 3)
+			if err != nil {
+				t.Fatalf("Retrieve() failed: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, chunks); diff != "" {
+				t.Errorf("Retrieve() mismatch (-want +got):\n%s", diff)
+			}
+			if got.Query != "refunds" || got.PageSize != 3 || got.ContentSearchSpec.SearchResultMode != tt.wantMode {
+				t.Errorf("search request = %+v, want the query, page size and search result mode %q", got, tt.wantMode)
+			}
+		})
+	}
+
+	if _, err := retrievaltool.NewVertexAISearchRetriever(t.Context(), retrievaltool.VertexAISearchConfig{DataStore: "d"}); err == nil {
+		t.Errorf("NewVertexAISearchRetriever() with an invalid datastore succeeded, want an error")
+	}
+}
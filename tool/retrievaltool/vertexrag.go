@@ -0,0 +1,88 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retrievaltool
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	aiplatform "cloud.google.com/go/aiplatform/apiv1"
+	"cloud.google.com/go/aiplatform/apiv1/aiplatformpb"
+	"google.golang.org/api/option"
+)
+
+// NewVertexRAGRetriever returns a retriever of the contexts of a Vertex AI
+// RAG corpus, e.g. "projects/p/locations/us-central1/ragCorpora/c".
+//
+// The chunks are scored with 1 minus the vector distance of the contexts, for
+// the corpora using the default cosine distance.
+//
+// The client uses the regional endpoint of the location of the corpus,
+// unless the options have another endpoint.
+func NewVertexRAGRetriever(ctx context.Context, corpus string, opts ...option.ClientOption) (Retriever, error) {
+	m := corpusPattern.FindStringSubmatch(corpus)
+	if m == nil {
+		return nil, fmt.Errorf("invalid RAG corpus %q", corpus)
+	}
+	parent, location := m[1], m[2]
+	opts = append([]option.ClientOption{option.WithEndpoint(location + "-aiplatform.googleapis.com:443")}, opts...)
+	client, err := aiplatform.NewVertexRagClient(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Vertex AI RAG client: %w", err)
+	}
+	return &vertexRAGRetriever{client: client, parent: parent, corpus: corpus}, nil
+}
+
+// corpusPattern matches the resource names of the RAG corpora, whose parent
+// is their location.
+var corpusPattern = regexp.MustCompile(`^(projects/[^/]+/locations/([^/]+))/ragCorpora/[^/]+$`)
+
+type vertexRAGRetriever struct {
+	client *aiplatform.VertexRagClient
+	parent string
+	corpus string
+}
+
+func (r *vertexRAGRetriever) Retrieve(ctx context.Context, query string, maxResults int) ([]Chunk, error) {
+	resp, err := r.client.RetrieveContexts(ctx, &aiplatformpb.RetrieveContextsRequest{
+		Parent: r.parent,
+		DataSource: &aiplatformpb.RetrieveContextsRequest_VertexRagStore_{
+			VertexRagStore: &aiplatformpb.RetrieveContextsRequest_VertexRagStore{
+				RagResources: []*aiplatformpb.RetrieveContextsRequest_VertexRagStore_RagResource{{RagCorpus: r.corpus}},
+			},
+		},
+		Query: &aiplatformpb.RagQuery{
+			Query:              &aiplatformpb.RagQuery_Text{Text: query},
+			RagRetrievalConfig: &aiplatformpb.RagRetrievalConfig{TopK: int32(maxResults)},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve the contexts of %q: %w", r.corpus, err)
+	}
+	return ragChunks(resp), nil
+}
+
+func ragChunks(resp *aiplatformpb.RetrieveContextsResponse) []Chunk {
+	var chunks []Chunk
+	for _, c := range resp.GetContexts().GetContexts() {
+		chunk := Chunk{Text: c.GetText(), SourceURI: c.GetSourceUri(), Title: c.GetSourceDisplayName()}
+		if c.Score != nil {
+			chunk.Score = 1 - c.GetScore()
+		}
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
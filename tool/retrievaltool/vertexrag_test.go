@@ -0,0 +1,47 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retrievaltool
+
+import (
+	"testing"
+
+	"cloud.google.com/go/aiplatform/apiv1/aiplatformpb"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestRAGChunks(t *testing.T) {
+	resp := &aiplatformpb.RetrieveContextsResponse{Contexts: &aiplatformpb.RagContexts{Contexts: []*aiplatformpb.RagContexts_Context{
+		{Text: "Refunds take 5 days.", SourceUri: "gs://docs/refunds.pdf", SourceDisplayName: "refunds.pdf", Score: proto.Float64(0.25)},
+		{Text: "Returns are free.", SourceUri: "gs://docs/returns.pdf"},
+	}}}
+	want := []Chunk{
+		{Text: "Refunds take 5 days.", SourceURI: "gs://docs/refunds.pdf", Title: "refunds.pdf", Score: 0.75},
+		{Text: "Returns are free.", SourceURI: "gs://docs/returns.pdf"},
+	}
+	if diff := cmp.Diff(want, ragChunks(resp)); diff != "" {
+		t.Errorf("ragChunks() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCorpusPattern(t *testing.T) {
+	m := corpusPattern.FindStringSubmatch("projects/p/locations/us-central1/ragCorpora/c")
+	if diff := cmp.Diff([]string{"projects/p/locations/us-central1/ragCorpora/c", "projects/p/locations/us-central1", "us-central1"}, m); diff != "" {
+		t.Errorf("corpusPattern mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := NewVertexRAGRetriever(t.Context(), "ragCorpora/c"); err == nil {
+		t.Errorf("NewVertexRAGRetriever() with an invalid corpus succeeded, want an error")
+	}
+}
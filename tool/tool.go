@@ -19,6 +19,9 @@ package tool
 
 import (
 	"context"
+	"errors"
+	"io"
+	"time"
 
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/memory"
@@ -26,6 +29,10 @@ import (
 	"google.golang.org/adk/tool/toolconfirmation"
 )
 
+// ErrMemoryNotSet is returned by [Context.SearchMemory] when the agent runs
+// without a memory service.
+var ErrMemoryNotSet = errors.New("memory service is not set")
+
 // Tool defines the interface for a callable tool.
 type Tool interface {
 	// Name returns the name of the tool.
@@ -37,9 +44,35 @@ type Tool interface {
 	IsLongRunning() bool
 }
 
+// TimeoutTool is implemented by the tools with their own timeout, which
+// overrides the ToolTimeout of the agents calling them.
+type TimeoutTool interface {
+	Tool
+	// Timeout bounds the duration of the calls of the tool. Zero means the
+	// timeout of the agent is used, and a negative duration no timeout.
+	Timeout() time.Duration
+}
+
+// ParallelSafeTool is implemented by the tools which declare whether they
+// can be called concurrently. When a model response has several function
+// calls, the agents run them concurrently, except for the calls of the tools
+// which aren't parallel-safe, which run alone. The tools which don't
+// implement the interface are parallel-safe.
+type ParallelSafeTool interface {
+	Tool
+	// ParallelSafe reports whether the tool can be called concurrently with
+	// other tools, including itself.
+	ParallelSafe() bool
+}
+
 // Context defines the interface for the context passed to a tool when it's
 // called. It provides access to invocation-specific information and allows
 // the tool to interact with the agent's state and memory.
+//
+// The calls of tools with a timeout, see [TimeoutTool], run under a
+// deadline: the Deadline of the context reports it, and the context is done
+// when the call times out, so the tools should pass the context to their
+// outgoing requests.
 type Context interface {
 	agent.CallbackContext
 	// FunctionCallID returns the unique identifier of the function call
@@ -50,8 +83,21 @@ type Context interface {
 	// used by the tool to modify the agent's state, transfer to another
 	// agent, or perform other actions.
 	Actions() *session.EventActions
-	// SearchMemory performs a semantic search on the agent's memory.
+	// SearchMemory performs a semantic search on the agent's memory. It
+	// returns [ErrMemoryNotSet] if the agent runs without a memory service.
 	SearchMemory(context.Context, string) (*memory.SearchResponse, error)
+	// ForgetMemory deletes memories of the current user, see
+	// [memory.Forgetter]. The app and user of the request are those of the
+	// agent. It returns [ErrMemoryNotSet] if the agent runs without a memory
+	// service, and [memory.ErrForgetUnsupported] if the memory service can't
+	// forget.
+	ForgetMemory(context.Context, *memory.ForgetRequest) (*memory.ForgetResponse, error)
+	// ClearToolCache clears the cached results of the named tools, or of all
+	// the tools if none is named, in the cache scope of the call, see
+	// [CacheableTool]. The tools changing what cached tools return call it
+	// so that the next calls run them again. It does nothing if the agent
+	// has no [ResultCache].
+	ClearToolCache(ctx context.Context, toolNames ...string) error
 
 	// ToolConfirmation returns a handler for checking the Human-in-the-Loop
 	// confirmation status for the current tool context. This should be used within a tool's logic
@@ -100,6 +146,28 @@ type Toolset interface {
 	Tools(ctx agent.ReadonlyContext) ([]Tool, error)
 }
 
+// ClosableToolset is implemented by the toolsets holding resources, e.g. the
+// connections of the toolsets of MCP servers or databases, which are
+// released when the runner of their agents is closed.
+type ClosableToolset interface {
+	Toolset
+	// Close releases the resources of the toolset. The tools of the toolset
+	// can't be called afterwards.
+	Close(ctx context.Context) error
+}
+
+// CloseToolset closes the toolset if it holds resources: if it implements
+// [ClosableToolset], or [io.Closer] like the MCP toolsets.
+func CloseToolset(ctx context.Context, ts Toolset) error {
+	switch ts := ts.(type) {
+	case ClosableToolset:
+		return ts.Close(ctx)
+	case io.Closer:
+		return ts.Close()
+	}
+	return nil
+}
+
 // Predicate is a function which decides whether a tool should be exposed to LLM.
 type Predicate func(ctx agent.ReadonlyContext, tool Tool) bool
 
@@ -116,7 +184,10 @@ func StringPredicate(allowedTools []string) Predicate {
 }
 
 // FilterToolset returns a Toolset that filters the tools in the given Toolset
-// using the given predicate.
+// using the given predicate. The predicate is called with the tools of each
+// model request, and its context gives access to the state of the session
+// and to the user, e.g. to only expose some tools to the admins. Closing
+// the returned Toolset closes the given one, see [CloseToolset].
 func FilterToolset(toolset Toolset, predicate Predicate) Toolset {
 	if toolset == nil {
 		panic("toolset must not be nil")
@@ -140,6 +211,11 @@ func (f *filteredToolset) Name() string {
 	return f.toolset.Name()
 }
 
+// Close closes the filtered toolset, see [CloseToolset].
+func (f *filteredToolset) Close(ctx context.Context) error {
+	return CloseToolset(ctx, f.toolset)
+}
+
 func (f *filteredToolset) Tools(ctx agent.ReadonlyContext) ([]Tool, error) {
 	tools, err := f.toolset.Tools(ctx)
 	if err != nil {
@@ -17,6 +17,7 @@ package tool_test
 import (
 	"testing"
 
+	"google.golang.org/adk/agent"
 	"google.golang.org/adk/internal/toolinternal"
 	"google.golang.org/adk/tool"
 	"google.golang.org/adk/tool/agenttool"
@@ -103,3 +104,39 @@ func TestTypes(t *testing.T) {
 		})
 	}
 }
+
+type closerToolset struct {
+	closed bool
+}
+
+func (*closerToolset) Name() string { return "closer" }
+
+func (*closerToolset) Tools(agent.ReadonlyContext) ([]tool.Tool, error) { return nil, nil }
+
+func (ts *closerToolset) Close() error {
+	ts.closed = true
+	return nil
+}
+
+func TestCloseToolset(t *testing.T) {
+	inner := &closerToolset{}
+	filtered := tool.FilterToolset(inner, tool.StringPredicate([]string{"a"}))
+	if _, ok := filtered.(tool.ClosableToolset); !ok {
+		t.Fatalf("FilterToolset() = %T, want a ClosableToolset", filtered)
+	}
+	if err := tool.CloseToolset(t.Context(), filtered); err != nil {
+		t.Fatalf("CloseToolset() error = %v", err)
+	}
+	if !inner.closed {
+		t.Errorf("CloseToolset() of the filtered toolset didn't close the io.Closer it filters")
+	}
+	if err := tool.CloseToolset(t.Context(), tool.FilterToolset(&nopToolset{}, tool.StringPredicate(nil))); err != nil {
+		t.Errorf("CloseToolset() of a toolset without resources error = %v", err)
+	}
+}
+
+type nopToolset struct{}
+
+func (*nopToolset) Name() string { return "nop" }
+
+func (*nopToolset) Tools(agent.ReadonlyContext) ([]tool.Tool, error) { return nil, nil }
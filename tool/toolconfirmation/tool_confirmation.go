@@ -40,9 +40,12 @@ import (
 //   - Have the same 'id' as the received "adk_request_confirmation" FunctionCall.
 //   - Have the name set to "adk_request_confirmation".
 //   - Include a response payload, typically a map like {"confirmed": bool}.
+//     An approval may also have edited "arguments" of the call, and a denial
+//     a "reason".
 //
 // Based on the boolean value in "confirmed", the ADK will either proceed to execute
-// the 'originalFunctionCall' or block it and return an error.
+// the 'originalFunctionCall', with the edited arguments if any, or block it and
+// give the model a refusal, see [Rejection].
 const FunctionCallName = "adk_request_confirmation"
 
 // ToolConfirmation represents the state and details of a user confirmation request
@@ -61,6 +64,28 @@ type ToolConfirmation struct {
 	// Payload contains any additional data or context related to the confirmation request.
 	// The structure of the Payload is application-specific.
 	Payload any
+
+	// Arguments are the arguments of the call edited by the user who approved
+	// it. If nil, the call is executed with the arguments of the model.
+	Arguments map[string]any `json:",omitempty"`
+
+	// Reason is the reason given by the user who denied the call, for the
+	// model.
+	Reason string `json:",omitempty"`
+}
+
+// Rejection returns the structured refusal given to the model as the
+// response of a call of the tool which the user denied, so that the model can
+// choose an alternative.
+func Rejection(toolName string, confirmation *ToolConfirmation) map[string]any {
+	result := map[string]any{
+		"status": "rejected",
+		"error":  fmt.Sprintf("the user rejected the call of tool %q", toolName),
+	}
+	if confirmation != nil && confirmation.Reason != "" {
+		result["reason"] = confirmation.Reason
+	}
+	return result
 }
 
 // OriginalCallFrom retrieves the underlying, original function call from a tool confirmation wrapper.
@@ -0,0 +1,195 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package userchoicetool provides a long-running tool asking the user to
+// choose among options, e.g. among the flights found by the agent.
+package userchoicetool
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/internal/toolinternal"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+// Name is the name of the tool.
+const Name = "get_user_choice"
+
+// TimeoutStatus is the "status" of the results of the calls which the user
+// didn't answer before the Timeout of the tool.
+const TimeoutStatus = "timeout"
+
+// ErrInvalidSelection is returned for the selections which aren't among the
+// options of a choice which isn't freeform.
+var ErrInvalidSelection = errors.New("the selection is not one of the options")
+
+// Config is the configuration of the tool.
+type Config struct {
+	// Freeform accepts the selections which aren't among the options of the
+	// calls. By default, they are rejected.
+	Freeform bool
+	// Timeout is the duration after which the calls which the user didn't
+	// answer get a result of TimeoutStatus. Zero means no timeout.
+	Timeout time.Duration
+}
+
+// Args are the arguments of the tool.
+type Args struct {
+	Options []string `json:"options" jsonschema:"description=options offered to the user"`
+	Prompt  string   `json:"prompt,omitempty" jsonschema:"description=question asked to the user"`
+}
+
+// Choice is the choice offered to the user, in the pending response of a call
+// of the tool, for the UI to render.
+type Choice struct {
+	Prompt   string   `json:"prompt,omitempty"`
+	Options  []string `json:"options"`
+	Freeform bool     `json:"freeform,omitempty"`
+	// ExpiresAt is the time after which the call gets a timeout result, if
+	// the tool has a timeout.
+	ExpiresAt time.Time `json:"expires_at,omitzero"`
+}
+
+// New creates the get_user_choice tool.
+//
+// The tool is long-running: its calls end the invocation with a pending
+// response, whose Choice is decoded by [ChoiceOf]. The selection of the user
+// is submitted with the [Choice.Result] as the function response of the
+// call, e.g. with the submitChoice endpoint of the REST server, which resumes
+// the flow.
+//
+// If the tool has a Timeout, the model is given a timeout result for the
+// calls which the user didn't answer in time, e.g. when the user moves on
+// with a new message.
+func New(cfg Config) (tool.Tool, error) {
+	if cfg.Timeout < 0 {
+		return nil, fmt.Errorf("invalid timeout %v", cfg.Timeout)
+	}
+	t, err := functiontool.NewLongRunning(functiontool.Config{
+		Name: Name,
+		Description: "Asks the user to choose one of the options, e.g. to pick one of the search results. " +
+			"The user answers later: the result of the call is the selection of the user.",
+	}, func(_ tool.Context, args Args) (*Choice, error) {
+		if len(args.Options) == 0 {
+			return nil, errors.New("options are required")
+		}
+		choice := &Choice{Prompt: args.Prompt, Options: args.Options, Freeform: cfg.Freeform}
+		if cfg.Timeout > 0 {
+			choice.ExpiresAt = time.Now().Add(cfg.Timeout).UTC()
+		}
+		return choice, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating user choice tool: %w", err)
+	}
+	return &userChoiceTool{FunctionTool: t.(toolinternal.FunctionTool), timeout: cfg.Timeout}, nil
+}
+
+// ChoiceOf returns the choice of the pending response of a call of the tool.
+func ChoiceOf(response map[string]any) (*Choice, error) {
+	data, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode response: %w", err)
+	}
+	var choice Choice
+	if err := json.Unmarshal(data, &choice); err != nil {
+		return nil, fmt.Errorf("failed to decode choice: %w", err)
+	}
+	if len(choice.Options) == 0 {
+		return nil, errors.New("the response has no options")
+	}
+	return &choice, nil
+}
+
+// Result returns the result of the call for the selection of the user at
+// the given time: the "selection", or a result of TimeoutStatus if the choice
+// expired. It returns ErrInvalidSelection if the choice isn't freeform and the
+// selection isn't one of its options.
+func (c *Choice) Result(selection string, now time.Time) (map[string]any, error) {
+	if c.expired(now) {
+		return timeoutResult(), nil
+	}
+	if !c.Freeform && !slices.Contains(c.Options, selection) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidSelection, selection)
+	}
+	return map[string]any{"selection": selection}, nil
+}
+
+func (c *Choice) expired(now time.Time) bool {
+	return !c.ExpiresAt.IsZero() && now.After(c.ExpiresAt)
+}
+
+func timeoutResult() map[string]any {
+	return map[string]any{"status": TimeoutStatus, "message": "The user didn't make a choice in time."}
+}
+
+type userChoiceTool struct {
+	toolinternal.FunctionTool
+	timeout time.Duration
+}
+
+// PausesInvocation implements toolinternal.PendingTool.
+func (t *userChoiceTool) PausesInvocation() bool {
+	return true
+}
+
+// ProcessRequest packs the declaration of the tool into the request, and
+// replaces the pending responses of the expired calls with timeout results.
+func (t *userChoiceTool) ProcessRequest(ctx tool.Context, req *model.LLMRequest) error {
+	if err := t.FunctionTool.(toolinternal.RequestProcessor).ProcessRequest(ctx, req); err != nil {
+		return err
+	}
+	if t.timeout <= 0 {
+		return nil
+	}
+
+	answered := map[string]bool{}
+	for _, content := range req.Contents {
+		for _, part := range content.Parts {
+			if fr := part.FunctionResponse; fr != nil && fr.Name == Name && fr.Response["status"] != functiontool.PendingStatus {
+				answered[fr.ID] = true
+			}
+		}
+	}
+	now := time.Now()
+	for i, content := range req.Contents {
+		var parts []*genai.Part
+		for j, part := range content.Parts {
+			fr := part.FunctionResponse
+			if fr == nil || fr.Name != Name || answered[fr.ID] || fr.Response["status"] != functiontool.PendingStatus {
+				continue
+			}
+			if choice, err := ChoiceOf(fr.Response); err != nil || !choice.expired(now) {
+				continue
+			}
+			// The parts are cloned, not to change the events of the session.
+			if parts == nil {
+				parts = slices.Clone(content.Parts)
+			}
+			parts[j] = &genai.Part{FunctionResponse: &genai.FunctionResponse{ID: fr.ID, Name: fr.Name, Response: timeoutResult()}}
+		}
+		if parts != nil {
+			req.Contents[i] = &genai.Content{Role: content.Role, Parts: parts}
+		}
+	}
+	return nil
+}
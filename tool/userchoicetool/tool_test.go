@@ -0,0 +1,154 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package userchoicetool_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/internal/testutil"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+	"google.golang.org/adk/tool/userchoicetool"
+)
+
+func TestChoiceResult(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	options := []string{"AF123", "LH456"}
+	tests := []struct {
+		name      string
+		choice    userchoicetool.Choice
+		selection string
+		want      map[string]any
+		wantErr   error
+	}{
+		{
+			name:      "option",
+			choice:    userchoicetool.Choice{Options: options},
+			selection: "LH456",
+			want:      map[string]any{"selection": "LH456"},
+		},
+		{
+			name:      "rejected",
+			choice:    userchoicetool.Choice{Options: options},
+			selection: "BA789",
+			wantErr:   userchoicetool.ErrInvalidSelection,
+		},
+		{
+			name:      "freeform",
+			choice:    userchoicetool.Choice{Options: options, Freeform: true},
+			selection: "the cheapest one",
+			want:      map[string]any{"selection": "the cheapest one"},
+		},
+		{
+			name:      "not expired",
+			choice:    userchoicetool.Choice{Options: options, ExpiresAt: now.Add(time.Minute)},
+			selection: "AF123",
+			want:      map[string]any{"selection": "AF123"},
+		},
+		{
+			name:      "expired",
+			choice:    userchoicetool.Choice{Options: options, ExpiresAt: now.Add(-time.Minute)},
+			selection: "AF123",
+			want:      map[string]any{"status": userchoicetool.TimeoutStatus, "message": "The user didn't make a choice in time."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.choice.Result(tt.selection, now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Result() error = %v, want %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Result() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestUserChoiceTool(t *testing.T) {
+	choiceTool, err := userchoicetool.New(userchoicetool.Config{Timeout: time.Nanosecond})
+	if err != nil {
+		t.Fatalf("userchoicetool.New() failed: %v", err)
+	}
+	llm := &testutil.MockModel{Responses: []*genai.Content{
+		genai.NewContentFromFunctionCall(userchoicetool.Name, map[string]any{
+			"prompt":  "Which flight?",
+			"options": []any{"AF123", "LH456"},
+		}, genai.RoleModel),
+		genai.NewContentFromText("You didn't pick a flight.", genai.RoleModel),
+	}}
+	a, err := llmagent.New(llmagent.Config{Name: "travel", Model: llm, Tools: []tool.Tool{choiceTool}})
+	if err != nil {
+		t.Fatalf("llmagent.New() failed: %v", err)
+	}
+	runner := testutil.NewTestAgentRunner(t, a)
+
+	var pending *genai.FunctionResponse
+	for ev, err := range runner.Run(t, "session", "Book me a flight to Paris.") {
+		if err != nil {
+			t.Fatalf("Run() failed: %v", err)
+		}
+		if len(ev.LongRunningToolIDs) > 0 {
+			pending = ev.Content.Parts[0].FunctionResponse
+		}
+	}
+	if pending == nil {
+		t.Fatalf("the invocation didn't end with a pending response")
+	}
+	if pending.Response["status"] != functiontool.PendingStatus {
+		t.Errorf("pending response = %v, want a status of %q", pending.Response, functiontool.PendingStatus)
+	}
+	choice, err := userchoicetool.ChoiceOf(pending.Response)
+	if err != nil {
+		t.Fatalf("ChoiceOf() failed: %v", err)
+	}
+	want := &userchoicetool.Choice{Prompt: "Which flight?", Options: []string{"AF123", "LH456"}, ExpiresAt: choice.ExpiresAt}
+	if diff := cmp.Diff(want, choice); diff != "" {
+		t.Errorf("ChoiceOf() mismatch (-want +got):\n%s", diff)
+	}
+	if choice.ExpiresAt.IsZero() {
+		t.Errorf("ChoiceOf() = %+v, want an expiration time", choice)
+	}
+
+	// The user moves on without answering: the model is given a timeout
+	// result.
+	if _, err := testutil.CollectEvents(runner.Run(t, "session", "Never mind.")); err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+	if len(llm.Requests) != 2 {
+		t.Fatalf("model called %d times, want 2", len(llm.Requests))
+	}
+	var got []map[string]any
+	for _, c := range llm.Requests[1].Contents {
+		for _, p := range c.Parts {
+			if p.FunctionResponse != nil {
+				got = append(got, p.FunctionResponse.Response)
+			}
+		}
+	}
+	wantResponses := []map[string]any{{"status": userchoicetool.TimeoutStatus, "message": "The user didn't make a choice in time."}}
+	if diff := cmp.Diff(wantResponses, got); diff != "" {
+		t.Errorf("function responses given to the model mismatch (-want +got):\n%s", diff)
+	}
+	if pending.Response["status"] != functiontool.PendingStatus {
+		t.Errorf("the pending response of the session was changed to %v", pending.Response)
+	}
+}
@@ -31,6 +31,8 @@ import (
 //     treated as a literal.
 //   - {artifact.key_name} can be used to insert the text content of the
 //     artifact named key_name.
+//   - Placeholders in double braces, like {{key_name}}, are escaped: they
+//     are replaced with the literal {key_name}.
 //
 // If the state variable or artifact does not exist, the agent will raise an
 // error. If you want to ignore the error, you can append a ? to the